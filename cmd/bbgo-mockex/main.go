@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/mockex"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "bbgo-mockex",
+	Short: "bbgo-mockex is a mock exchange server speaking the MAX API for integration tests",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		listen, err := cmd.Flags().GetString("listen")
+		if err != nil {
+			return err
+		}
+
+		debug, err := cmd.Flags().GetBool("debug")
+		if err != nil {
+			return err
+		}
+
+		if debug {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		config, err := mockex.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+
+		server := mockex.NewServer(mockex.NewEngine(config))
+
+		log.Infof("mock exchange is listening on %s, rest api: http://%s/api/v2, websocket: ws://%s/ws", listen, listen, listen)
+		return http.ListenAndServe(listen, server)
+	},
+}
+
+func init() {
+	rootCmd.Flags().String("config", "config/mockex.yaml", "mock exchange config file")
+	rootCmd.Flags().String("listen", "localhost:8090", "the address to listen on")
+	rootCmd.Flags().Bool("debug", false, "debug flag")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
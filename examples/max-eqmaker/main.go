@@ -107,7 +107,7 @@ var rootCmd = &cobra.Command{
 		streambook.BindStream(stream)
 
 		cancelSideOrders := func(symbol string, side string) {
-			if err := maxRest.OrderService.CancelAll(side, symbol); err != nil {
+			if err := maxRest.OrderService.CancelAll(ctx, side, symbol); err != nil {
 				log.WithError(err).Error("cancel all error")
 			}
 
@@ -146,7 +146,7 @@ var rootCmd = &cobra.Command{
 			}
 			log.Infof("submitting %d orders", len(orders))
 
-			retOrders, err := maxRest.OrderService.CreateMulti(symbol, orders)
+			retOrders, err := maxRest.OrderService.CreateMulti(ctx, symbol, orders)
 			if err != nil {
 				log.WithError(err).Error("create multi error")
 			}
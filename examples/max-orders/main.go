@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -14,7 +15,7 @@ func main() {
 	maxRest := maxapi.NewRestClient(maxapi.ProductionAPIURL)
 	maxRest.Auth(key, secret)
 
-	orders, err := maxRest.OrderService.All("maxusdt", 100, 1, maxapi.OrderStateDone)
+	orders, err := maxRest.OrderService.All(context.Background(), "maxusdt", 100, 1, maxapi.OrderStateDone)
 	if err != nil {
 		log.Fatal(err)
 	}
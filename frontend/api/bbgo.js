@@ -99,4 +99,19 @@ export function queryTradingVolume(params, cb) {
         });
 }
 
+export function queryDashboard(cb) {
+    return axios.get(baseURL + '/api/dashboard', {})
+        .then(response => {
+            cb(response.data.dashboard)
+        });
+}
 
+// connectDashboard opens the dashboard websocket, the snapshot and the stream events are passed to the callback
+export function connectDashboard(cb) {
+    const httpURL = baseURL || window.location.origin
+    const ws = new WebSocket(httpURL.replace(/^http/, 'ws') + '/api/dashboard/ws')
+    ws.onmessage = (message) => {
+        cb(JSON.parse(message.data))
+    }
+    return ws
+}
@@ -70,6 +70,14 @@ export default function SideBar() {
                     <ListItemText primary="Dashboard"/>
                 </ListItem>
             </Link>
+            <Link href={"/realtime"}>
+                <ListItem button>
+                    <ListItemIcon>
+                        <TrendingUpIcon/>
+                    </ListItemIcon>
+                    <ListItemText primary="Realtime"/>
+                </ListItem>
+            </Link>
         </List>
         <Divider/>
         <List>
@@ -0,0 +1,185 @@
+import React, {useEffect, useState} from 'react';
+
+import {makeStyles} from '@material-ui/core/styles';
+import Typography from '@material-ui/core/Typography';
+import Paper from '@material-ui/core/Paper';
+import Table from '@material-ui/core/Table';
+import TableBody from '@material-ui/core/TableBody';
+import TableCell from '@material-ui/core/TableCell';
+import TableHead from '@material-ui/core/TableHead';
+import TableRow from '@material-ui/core/TableRow';
+
+import {connectDashboard} from '../api/bbgo';
+import DashboardLayout from '../layouts/DashboardLayout';
+
+// maxRecentTrades is the number of the recent trades kept on the page
+const maxRecentTrades = 50;
+
+const useStyles = makeStyles((theme) => ({
+    paper: {
+        margin: theme.spacing(2),
+        padding: theme.spacing(2),
+    },
+}));
+
+function SimpleTable({columns, rows}) {
+    return <Table size="small">
+        <TableHead>
+            <TableRow>
+                {columns.map((column) => <TableCell key={column.field}>{column.headerName}</TableCell>)}
+            </TableRow>
+        </TableHead>
+        <TableBody>
+            {rows.map((row, i) => <TableRow key={i}>
+                {columns.map((column) => <TableCell key={column.field}>{String(row[column.field] ?? '')}</TableCell>)}
+            </TableRow>)}
+        </TableBody>
+    </Table>
+}
+
+function balanceRows(session) {
+    return Object.values(session.balances || {})
+        .filter((b) => Number(b.available) > 0 || Number(b.locked) > 0)
+        .map((b) => ({session: session.name, ...b}));
+}
+
+function openOrderRows(session) {
+    return Object.values(session.openOrders || {})
+        .flat()
+        .map((o) => ({session: session.name, ...o}));
+}
+
+function profitRows(session) {
+    return Object.values(session.profitStats || {}).map((stats) => {
+        const position = (session.positions || {})[stats.symbol] || {};
+        return {
+            session: session.name,
+            base: position.base,
+            averageCost: position.averageCost,
+            lastPrice: (session.lastPrices || {})[stats.symbol],
+            ...stats,
+        }
+    });
+}
+
+export default function Realtime() {
+    const classes = useStyles();
+
+    const [snapshot, setSnapshot] = useState(null)
+    const [trades, setTrades] = useState([])
+
+    useEffect(() => {
+        const ws = connectDashboard((event) => {
+            switch (event.type) {
+                case "snapshot":
+                    setSnapshot(event.data);
+                    break;
+
+                case "trade":
+                    setTrades((trades) => [{session: event.session, ...event.data}, ...trades].slice(0, maxRecentTrades));
+                    break;
+            }
+        });
+
+        return () => ws.close();
+    }, [])
+
+    if (!snapshot) {
+        return (
+            <DashboardLayout>
+                <Paper className={classes.paper}>
+                    <Typography variant="h4" gutterBottom>
+                        Connecting
+                    </Typography>
+                </Paper>
+            </DashboardLayout>
+        );
+    }
+
+    return (
+        <DashboardLayout>
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Strategies
+                </Typography>
+                <SimpleTable rows={snapshot.strategies} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'id', headerName: 'Strategy'},
+                    {field: 'status', headerName: 'Status'},
+                ]}/>
+            </Paper>
+
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Realized PnL
+                </Typography>
+                <SimpleTable rows={snapshot.sessions.flatMap(profitRows)} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'symbol', headerName: 'Symbol'},
+                    {field: 'base', headerName: 'Position'},
+                    {field: 'averageCost', headerName: 'Average Cost'},
+                    {field: 'lastPrice', headerName: 'Last Price'},
+                    {field: 'numTrades', headerName: 'Trades'},
+                    {field: 'realizedProfit', headerName: 'Realized Profit'},
+                    {field: 'quoteCurrency', headerName: 'Currency'},
+                ]}/>
+            </Paper>
+
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Balances
+                </Typography>
+                <SimpleTable rows={snapshot.sessions.flatMap(balanceRows)} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'currency', headerName: 'Currency'},
+                    {field: 'available', headerName: 'Available'},
+                    {field: 'locked', headerName: 'Locked'},
+                ]}/>
+            </Paper>
+
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Open Orders
+                </Typography>
+                <SimpleTable rows={snapshot.sessions.flatMap(openOrderRows)} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'symbol', headerName: 'Symbol'},
+                    {field: 'side', headerName: 'Side'},
+                    {field: 'orderType', headerName: 'Type'},
+                    {field: 'price', headerName: 'Price'},
+                    {field: 'quantity', headerName: 'Quantity'},
+                    {field: 'executedQuantity', headerName: 'Executed'},
+                    {field: 'status', headerName: 'Status'},
+                ]}/>
+            </Paper>
+
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Indicators
+                </Typography>
+                <SimpleTable rows={snapshot.indicators} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'symbol', headerName: 'Symbol'},
+                    {field: 'interval', headerName: 'Interval'},
+                    {field: 'window', headerName: 'Window'},
+                    {field: 'sma', headerName: 'SMA'},
+                    {field: 'ewma', headerName: 'EWMA'},
+                ]}/>
+            </Paper>
+
+            <Paper className={classes.paper}>
+                <Typography variant="h4" gutterBottom>
+                    Recent Trades
+                </Typography>
+                <SimpleTable rows={trades} columns={[
+                    {field: 'session', headerName: 'Session'},
+                    {field: 'symbol', headerName: 'Symbol'},
+                    {field: 'side', headerName: 'Side'},
+                    {field: 'price', headerName: 'Price'},
+                    {field: 'quantity', headerName: 'Quantity'},
+                    {field: 'tradedAt', headerName: 'Trade Time'},
+                ]}/>
+            </Paper>
+        </DashboardLayout>
+    );
+}
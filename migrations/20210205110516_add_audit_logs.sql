@@ -0,0 +1,19 @@
+-- +up
+CREATE TABLE `audit_logs`
+(
+    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `exchange` VARCHAR(24)     NOT NULL DEFAULT '',
+    `session`  VARCHAR(32)     NOT NULL DEFAULT '',
+    `action`   VARCHAR(32)     NOT NULL,
+    `params`   TEXT            NOT NULL,
+    `result`   TEXT            NOT NULL,
+    `error`    TEXT            NOT NULL,
+    `time`     DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `audit_logs_time` (`time`)
+);
+
+-- +down
+DROP TABLE `audit_logs`;
@@ -0,0 +1,12 @@
+-- +up
+CREATE TABLE `persistence`
+(
+    `id`         VARCHAR(255) NOT NULL,
+    `data`       MEDIUMTEXT   NOT NULL,
+    `updated_at` DATETIME(3)  NOT NULL,
+
+    PRIMARY KEY (`id`)
+);
+
+-- +down
+DROP TABLE `persistence`;
@@ -0,0 +1,10 @@
+-- +up
+-- +begin
+CREATE INDEX trades_exchange_order_id ON trades (exchange, order_id);
+-- +end
+
+-- +down
+
+-- +begin
+DROP INDEX trades_exchange_order_id ON trades
+-- +end
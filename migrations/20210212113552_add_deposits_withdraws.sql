@@ -0,0 +1,41 @@
+-- +up
+CREATE TABLE `deposits`
+(
+    `gid`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `exchange`    VARCHAR(24)     NOT NULL,
+    `asset`       VARCHAR(10)     NOT NULL,
+    `address`     VARCHAR(128)    NOT NULL DEFAULT '',
+    `address_tag` VARCHAR(128)    NOT NULL DEFAULT '',
+    `amount`      DECIMAL(16, 8)  NOT NULL,
+    `txn_id`      VARCHAR(256)    NOT NULL DEFAULT '',
+    `status`      VARCHAR(32)     NOT NULL DEFAULT '',
+    `time`        DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `deposits_txn_id` (`exchange`, `asset`, `txn_id`, `time`),
+    INDEX `deposits_time` (`exchange`, `time`)
+);
+
+CREATE TABLE `withdraws`
+(
+    `gid`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `exchange`          VARCHAR(24)     NOT NULL,
+    `asset`             VARCHAR(10)     NOT NULL,
+    `address`           VARCHAR(128)    NOT NULL DEFAULT '',
+    `address_tag`       VARCHAR(128)    NOT NULL DEFAULT '',
+    `network`           VARCHAR(32)     NOT NULL DEFAULT '',
+    `amount`            DECIMAL(16, 8)  NOT NULL,
+    `txn_fee`           DECIMAL(16, 8)  NOT NULL DEFAULT 0,
+    `txn_id`            VARCHAR(256)    NOT NULL DEFAULT '',
+    `withdraw_order_id` VARCHAR(64)     NOT NULL DEFAULT '',
+    `status`            VARCHAR(32)     NOT NULL DEFAULT '',
+    `time`              DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `withdraws_txn_id` (`exchange`, `asset`, `txn_id`, `time`),
+    INDEX `withdraws_time` (`exchange`, `time`)
+);
+
+-- +down
+DROP TABLE `deposits`;
+DROP TABLE `withdraws`;
@@ -0,0 +1,34 @@
+-- +up
+CREATE TABLE `account_value_snapshots`
+(
+    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`        VARCHAR(32)     NOT NULL,
+    `exchange`       VARCHAR(24)     NOT NULL,
+    `quote_currency` VARCHAR(10)     NOT NULL,
+    `net_value`      DECIMAL(20, 8)  NOT NULL,
+    `time`           DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `account_value_snapshots_time` (`session`, `time`)
+);
+
+CREATE TABLE `balance_snapshots`
+(
+    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`        VARCHAR(32)     NOT NULL,
+    `exchange`       VARCHAR(24)     NOT NULL,
+    `currency`       VARCHAR(10)     NOT NULL,
+    `total`          DECIMAL(20, 8)  NOT NULL,
+    `borrowed`       DECIMAL(20, 8)  NOT NULL DEFAULT 0,
+    `price`          DECIMAL(20, 8)  NOT NULL,
+    `value`          DECIMAL(20, 8)  NOT NULL,
+    `quote_currency` VARCHAR(10)     NOT NULL,
+    `time`           DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `balance_snapshots_time` (`session`, `time`)
+);
+
+-- +down
+DROP TABLE `account_value_snapshots`;
+DROP TABLE `balance_snapshots`;
@@ -0,0 +1,25 @@
+-- +up
+CREATE TABLE `strategy_orders`
+(
+    `gid`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `session`         VARCHAR(32)     NOT NULL,
+    `exchange`        VARCHAR(24)     NOT NULL,
+    `local_id`        BIGINT UNSIGNED NOT NULL,
+    `strategy`        VARCHAR(64)     NOT NULL,
+    `order_id`        BIGINT UNSIGNED NOT NULL,
+    `client_order_id` VARCHAR(64)     NOT NULL DEFAULT '',
+    `symbol`          VARCHAR(20)     NOT NULL,
+    `side`            VARCHAR(4)      NOT NULL,
+    `order_type`      VARCHAR(16)     NOT NULL,
+    `price`           DECIMAL(16, 8)  NOT NULL DEFAULT 0,
+    `quantity`        DECIMAL(16, 8)  NOT NULL,
+    `created_at`      DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `strategy_orders_local_id` (`session`, `local_id`),
+    INDEX `strategy_orders_strategy` (`strategy`, `created_at`),
+    INDEX `strategy_orders_order_id` (`exchange`, `order_id`)
+);
+
+-- +down
+DROP TABLE `strategy_orders`;
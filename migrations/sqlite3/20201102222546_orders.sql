@@ -0,0 +1,25 @@
+-- +up
+CREATE TABLE `orders`
+(
+    `gid`               INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    `exchange`          VARCHAR(24)    NOT NULL DEFAULT '',
+    -- order_id is the order id returned from the exchange
+    `order_id`          INTEGER        NOT NULL,
+    `client_order_id`   VARCHAR(42)    NOT NULL DEFAULT '',
+    `order_type`        VARCHAR(16)    NOT NULL,
+    `symbol`            VARCHAR(8)     NOT NULL,
+    `status`            VARCHAR(12)    NOT NULL,
+    `time_in_force`     VARCHAR(4)     NOT NULL,
+    `price`             DECIMAL(16, 8) NOT NULL,
+    `stop_price`        DECIMAL(16, 8) NOT NULL,
+    `quantity`          DECIMAL(16, 8) NOT NULL,
+    `executed_quantity` DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `side`              VARCHAR(4)     NOT NULL DEFAULT '',
+    `is_working`        BOOLEAN        NOT NULL DEFAULT FALSE,
+    `created_at`        DATETIME       NOT NULL,
+    `updated_at`        DATETIME       NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +down
+DROP TABLE `orders`;
@@ -0,0 +1,5 @@
+-- +up
+ALTER TABLE `trades` ADD COLUMN `order_id` INTEGER NOT NULL DEFAULT 0;
+
+-- +down
+ALTER TABLE `trades` DROP COLUMN `order_id`;
@@ -0,0 +1,79 @@
+-- +up
+CREATE TABLE `klines`
+(
+    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`      VARCHAR(10)    NOT NULL,
+    `start_time`    DATETIME       NOT NULL,
+    `end_time`      DATETIME       NOT NULL,
+    `interval`      VARCHAR(3)     NOT NULL,
+    `symbol`        VARCHAR(7)     NOT NULL,
+    `open`          DECIMAL(16, 8) NOT NULL,
+    `high`          DECIMAL(16, 8) NOT NULL,
+    `low`           DECIMAL(16, 8) NOT NULL,
+    `close`         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `volume`        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `closed`        BOOLEAN        NOT NULL DEFAULT TRUE,
+    `last_trade_id` INTEGER        NOT NULL DEFAULT 0,
+    `num_trades`    INTEGER        NOT NULL DEFAULT 0
+);
+CREATE INDEX `klines_end_time_symbol_interval` ON `klines` (`end_time`, `symbol`, `interval`);
+CREATE TABLE `okex_klines`
+(
+    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`      VARCHAR(10)    NOT NULL,
+    `start_time`    DATETIME       NOT NULL,
+    `end_time`      DATETIME       NOT NULL,
+    `interval`      VARCHAR(3)     NOT NULL,
+    `symbol`        VARCHAR(7)     NOT NULL,
+    `open`          DECIMAL(16, 8) NOT NULL,
+    `high`          DECIMAL(16, 8) NOT NULL,
+    `low`           DECIMAL(16, 8) NOT NULL,
+    `close`         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `volume`        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `closed`        BOOLEAN        NOT NULL DEFAULT TRUE,
+    `last_trade_id` INTEGER        NOT NULL DEFAULT 0,
+    `num_trades`    INTEGER        NOT NULL DEFAULT 0
+);
+CREATE INDEX `okex_klines_end_time_symbol_interval` ON `okex_klines` (`end_time`, `symbol`, `interval`);
+CREATE TABLE `binance_klines`
+(
+    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`      VARCHAR(10)    NOT NULL,
+    `start_time`    DATETIME       NOT NULL,
+    `end_time`      DATETIME       NOT NULL,
+    `interval`      VARCHAR(3)     NOT NULL,
+    `symbol`        VARCHAR(7)     NOT NULL,
+    `open`          DECIMAL(16, 8) NOT NULL,
+    `high`          DECIMAL(16, 8) NOT NULL,
+    `low`           DECIMAL(16, 8) NOT NULL,
+    `close`         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `volume`        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `closed`        BOOLEAN        NOT NULL DEFAULT TRUE,
+    `last_trade_id` INTEGER        NOT NULL DEFAULT 0,
+    `num_trades`    INTEGER        NOT NULL DEFAULT 0
+);
+CREATE INDEX `binance_klines_end_time_symbol_interval` ON `binance_klines` (`end_time`, `symbol`, `interval`);
+CREATE TABLE `max_klines`
+(
+    `gid`           INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`      VARCHAR(10)    NOT NULL,
+    `start_time`    DATETIME       NOT NULL,
+    `end_time`      DATETIME       NOT NULL,
+    `interval`      VARCHAR(3)     NOT NULL,
+    `symbol`        VARCHAR(7)     NOT NULL,
+    `open`          DECIMAL(16, 8) NOT NULL,
+    `high`          DECIMAL(16, 8) NOT NULL,
+    `low`           DECIMAL(16, 8) NOT NULL,
+    `close`         DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `volume`        DECIMAL(16, 8) NOT NULL DEFAULT 0.0,
+    `closed`        BOOLEAN        NOT NULL DEFAULT TRUE,
+    `last_trade_id` INTEGER        NOT NULL DEFAULT 0,
+    `num_trades`    INTEGER        NOT NULL DEFAULT 0
+);
+CREATE INDEX `max_klines_end_time_symbol_interval` ON `max_klines` (`end_time`, `symbol`, `interval`);
+
+-- +down
+DROP TABLE `max_klines`;
+DROP TABLE `binance_klines`;
+DROP TABLE `okex_klines`;
+DROP TABLE `klines`;
@@ -0,0 +1,4 @@
+-- +up
+-- SQLite doesn't enforce the length of VARCHAR, there is nothing to modify
+
+-- +down
@@ -0,0 +1,7 @@
+-- +up
+DROP INDEX `trades_id`;
+CREATE UNIQUE INDEX `trades_id` ON `trades` (`exchange`, `symbol`, `side`, `id`);
+
+-- +down
+DROP INDEX `trades_id`;
+CREATE UNIQUE INDEX `trades_id` ON `trades` (`id`);
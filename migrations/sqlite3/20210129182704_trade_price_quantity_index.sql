@@ -0,0 +1,5 @@
+-- +up
+CREATE INDEX trades_price_quantity ON trades (order_id, price, quantity);
+
+-- +down
+DROP INDEX trades_price_quantity;
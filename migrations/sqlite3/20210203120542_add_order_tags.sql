@@ -0,0 +1,7 @@
+-- +up
+ALTER TABLE `orders` ADD COLUMN `tags` VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE `trades` ADD COLUMN `tags` VARCHAR(255) NOT NULL DEFAULT '';
+
+-- +down
+ALTER TABLE `orders` DROP COLUMN `tags`;
+ALTER TABLE `trades` DROP COLUMN `tags`;
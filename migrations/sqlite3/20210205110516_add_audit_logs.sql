@@ -0,0 +1,17 @@
+-- +up
+CREATE TABLE `audit_logs`
+(
+    `gid`      INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    `exchange` VARCHAR(24) NOT NULL DEFAULT '',
+    `session`  VARCHAR(32) NOT NULL DEFAULT '',
+    `action`   VARCHAR(32) NOT NULL,
+    `params`   TEXT        NOT NULL,
+    `result`   TEXT        NOT NULL,
+    `error`    TEXT        NOT NULL,
+    `time`     DATETIME    NOT NULL
+);
+CREATE INDEX `audit_logs_time` ON `audit_logs` (`time`);
+
+-- +down
+DROP TABLE `audit_logs`;
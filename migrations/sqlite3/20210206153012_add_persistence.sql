@@ -0,0 +1,10 @@
+-- +up
+CREATE TABLE `persistence`
+(
+    `id`         VARCHAR(255) NOT NULL PRIMARY KEY,
+    `data`       TEXT         NOT NULL,
+    `updated_at` DATETIME     NOT NULL
+);
+
+-- +down
+DROP TABLE `persistence`;
@@ -0,0 +1,5 @@
+-- +up
+CREATE INDEX trades_exchange_order_id ON trades (exchange, order_id);
+
+-- +down
+DROP INDEX trades_exchange_order_id;
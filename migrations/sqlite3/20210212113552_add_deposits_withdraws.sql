@@ -0,0 +1,36 @@
+-- +up
+CREATE TABLE `deposits`
+(
+    `gid`         INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`    VARCHAR(24)    NOT NULL,
+    `asset`       VARCHAR(10)    NOT NULL,
+    `address`     VARCHAR(128)   NOT NULL DEFAULT '',
+    `address_tag` VARCHAR(128)   NOT NULL DEFAULT '',
+    `amount`      DECIMAL(16, 8) NOT NULL,
+    `txn_id`      VARCHAR(256)   NOT NULL DEFAULT '',
+    `status`      VARCHAR(32)    NOT NULL DEFAULT '',
+    `time`        DATETIME       NOT NULL
+);
+CREATE UNIQUE INDEX `deposits_txn_id` ON `deposits` (`exchange`, `asset`, `txn_id`, `time`);
+CREATE INDEX `deposits_time` ON `deposits` (`exchange`, `time`);
+CREATE TABLE `withdraws`
+(
+    `gid`               INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`          VARCHAR(24)    NOT NULL,
+    `asset`             VARCHAR(10)    NOT NULL,
+    `address`           VARCHAR(128)   NOT NULL DEFAULT '',
+    `address_tag`       VARCHAR(128)   NOT NULL DEFAULT '',
+    `network`           VARCHAR(32)    NOT NULL DEFAULT '',
+    `amount`            DECIMAL(16, 8) NOT NULL,
+    `txn_fee`           DECIMAL(16, 8) NOT NULL DEFAULT 0,
+    `txn_id`            VARCHAR(256)   NOT NULL DEFAULT '',
+    `withdraw_order_id` VARCHAR(64)    NOT NULL DEFAULT '',
+    `status`            VARCHAR(32)    NOT NULL DEFAULT '',
+    `time`              DATETIME       NOT NULL
+);
+CREATE UNIQUE INDEX `withdraws_txn_id` ON `withdraws` (`exchange`, `asset`, `txn_id`, `time`);
+CREATE INDEX `withdraws_time` ON `withdraws` (`exchange`, `time`);
+
+-- +down
+DROP TABLE `deposits`;
+DROP TABLE `withdraws`;
@@ -0,0 +1,29 @@
+-- +up
+CREATE TABLE `account_value_snapshots`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`        VARCHAR(32)    NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL,
+    `quote_currency` VARCHAR(10)    NOT NULL,
+    `net_value`      DECIMAL(20, 8) NOT NULL,
+    `time`           DATETIME       NOT NULL
+);
+CREATE INDEX `account_value_snapshots_time` ON `account_value_snapshots` (`session`, `time`);
+CREATE TABLE `balance_snapshots`
+(
+    `gid`            INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`        VARCHAR(32)    NOT NULL,
+    `exchange`       VARCHAR(24)    NOT NULL,
+    `currency`       VARCHAR(10)    NOT NULL,
+    `total`          DECIMAL(20, 8) NOT NULL,
+    `borrowed`       DECIMAL(20, 8) NOT NULL DEFAULT 0,
+    `price`          DECIMAL(20, 8) NOT NULL,
+    `value`          DECIMAL(20, 8) NOT NULL,
+    `quote_currency` VARCHAR(10)    NOT NULL,
+    `time`           DATETIME       NOT NULL
+);
+CREATE INDEX `balance_snapshots_time` ON `balance_snapshots` (`session`, `time`);
+
+-- +down
+DROP TABLE `account_value_snapshots`;
+DROP TABLE `balance_snapshots`;
@@ -0,0 +1,23 @@
+-- +up
+CREATE TABLE `strategy_orders`
+(
+    `gid`             INTEGER PRIMARY KEY AUTOINCREMENT,
+    `session`         VARCHAR(32)    NOT NULL,
+    `exchange`        VARCHAR(24)    NOT NULL,
+    `local_id`        INTEGER        NOT NULL,
+    `strategy`        VARCHAR(64)    NOT NULL,
+    `order_id`        INTEGER        NOT NULL,
+    `client_order_id` VARCHAR(64)    NOT NULL DEFAULT '',
+    `symbol`          VARCHAR(20)    NOT NULL,
+    `side`            VARCHAR(4)     NOT NULL,
+    `order_type`      VARCHAR(16)    NOT NULL,
+    `price`           DECIMAL(16, 8) NOT NULL DEFAULT 0,
+    `quantity`        DECIMAL(16, 8) NOT NULL,
+    `created_at`      DATETIME       NOT NULL
+);
+CREATE UNIQUE INDEX `strategy_orders_local_id` ON `strategy_orders` (`session`, `local_id`);
+CREATE INDEX `strategy_orders_strategy` ON `strategy_orders` (`strategy`, `created_at`);
+CREATE INDEX `strategy_orders_order_id` ON `strategy_orders` (`exchange`, `order_id`);
+
+-- +down
+DROP TABLE `strategy_orders`;
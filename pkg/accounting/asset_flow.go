@@ -0,0 +1,169 @@
+package accounting
+
+import (
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AssetFlow is the change of an asset split into the transfers and the trades
+type AssetFlow struct {
+	Asset string
+
+	Deposits     float64
+	Withdraws    float64
+	WithdrawFees float64
+
+	// TradingChange is the change of the asset from the trades, the trading fees paid in the asset are included
+	TradingChange float64
+	TradingFees   float64
+
+	// Balance is the current balance of the asset, it's set by AssetFlowReport.Reconcile
+	Balance float64
+}
+
+// NetFlow is the net amount transferred into the account
+func (f AssetFlow) NetFlow() float64 {
+	return f.Deposits - f.Withdraws - f.WithdrawFees
+}
+
+// Unreconciled is the balance not explained by the transfers and the trades,
+// it's only meaningful when the report covers the whole account history.
+func (f AssetFlow) Unreconciled() float64 {
+	return round(f.Balance - f.NetFlow() - f.TradingChange)
+}
+
+// AssetFlowReport reconciles the net asset flows (deposits and withdraws) with the trading changes of the assets
+type AssetFlowReport struct {
+	Since time.Time
+	Until time.Time
+	Flows map[string]*AssetFlow
+}
+
+func NewAssetFlowReport(since, until time.Time) *AssetFlowReport {
+	return &AssetFlowReport{
+		Since: since,
+		Until: until,
+		Flows: map[string]*AssetFlow{},
+	}
+}
+
+func (r *AssetFlowReport) flow(asset string) *AssetFlow {
+	flow, ok := r.Flows[asset]
+	if !ok {
+		flow = &AssetFlow{Asset: asset}
+		r.Flows[asset] = flow
+	}
+
+	return flow
+}
+
+// AddDeposits adds the succeeded deposits, the pending and the rejected deposits are ignored
+func (r *AssetFlowReport) AddDeposits(deposits []types.Deposit) {
+	for _, deposit := range deposits {
+		switch deposit.Status {
+		case types.DepositSuccess, types.DepositCredited:
+			r.flow(deposit.Asset).Deposits += deposit.Amount
+		}
+	}
+}
+
+// AddWithdraws adds the completed withdraws and their transaction fees
+func (r *AssetFlowReport) AddWithdraws(withdraws []types.Withdraw) {
+	for _, withdraw := range withdraws {
+		if withdraw.Status != types.WithdrawCompleted {
+			continue
+		}
+
+		flow := r.flow(withdraw.Asset)
+		flow.Withdraws += withdraw.Amount
+		flow.WithdrawFees += withdraw.TransactionFee
+	}
+}
+
+// AddTrades adds the base and the quote changes of the trades, the trades of the unknown markets are skipped
+func (r *AssetFlowReport) AddTrades(markets types.MarketMap, trades []types.Trade) {
+	for _, trade := range trades {
+		market, ok := markets[trade.Symbol]
+		if !ok {
+			log.Warnf("market %s not found, skipping trade %d", trade.Symbol, trade.ID)
+			continue
+		}
+
+		quoteQuantity := trade.QuoteQuantity
+		if quoteQuantity == 0 {
+			quoteQuantity = trade.Price * trade.Quantity
+		}
+
+		if trade.IsBuyer {
+			r.flow(market.BaseCurrency).TradingChange += trade.Quantity
+			r.flow(market.QuoteCurrency).TradingChange -= quoteQuantity
+		} else {
+			r.flow(market.BaseCurrency).TradingChange -= trade.Quantity
+			r.flow(market.QuoteCurrency).TradingChange += quoteQuantity
+		}
+
+		if trade.Fee > 0 && len(trade.FeeCurrency) > 0 {
+			flow := r.flow(trade.FeeCurrency)
+			flow.TradingChange -= trade.Fee
+			flow.TradingFees += trade.Fee
+		}
+	}
+}
+
+// Reconcile sets the current balances of the assets
+func (r *AssetFlowReport) Reconcile(balances types.BalanceMap) {
+	for currency, balance := range balances {
+		r.flow(currency).Balance = balance.Available.Float64() + balance.Locked.Float64()
+	}
+}
+
+// Values returns the net flow value and the trading value of the assets in the quote currency,
+// the prices are the prices of the assets in the quote currency, the assets without the price are not counted.
+func (r *AssetFlowReport) Values(quoteCurrency string, prices map[string]float64) (netFlowValue, tradingValue float64) {
+	for asset, flow := range r.Flows {
+		price := 1.0
+		if asset != quoteCurrency {
+			var ok bool
+			if price, ok = prices[asset]; !ok {
+				continue
+			}
+		}
+
+		netFlowValue += flow.NetFlow() * price
+		tradingValue += flow.TradingChange * price
+	}
+
+	return netFlowValue, tradingValue
+}
+
+// Assets returns the assets of the report in the alphabetical order
+func (r *AssetFlowReport) Assets() []string {
+	var assets []string
+	for asset := range r.Flows {
+		assets = append(assets, asset)
+	}
+
+	sort.Strings(assets)
+	return assets
+}
+
+func (r *AssetFlowReport) Print() {
+	log.Infof("ASSET FLOWS SINCE %v UNTIL %v", r.Since, r.Until)
+	for _, asset := range r.Assets() {
+		flow := r.Flows[asset]
+		log.Infof("%s: DEPOSITS %f WITHDRAWS %f (FEE %f) NET FLOW %f TRADING %f (FEE %f) BALANCE %f UNRECONCILED %f",
+			asset,
+			flow.Deposits,
+			flow.Withdraws,
+			flow.WithdrawFees,
+			flow.NetFlow(),
+			flow.TradingChange,
+			flow.TradingFees,
+			flow.Balance,
+			flow.Unreconciled())
+	}
+}
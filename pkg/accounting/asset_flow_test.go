@@ -0,0 +1,53 @@
+package accounting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAssetFlowReport(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}
+
+	report := NewAssetFlowReport(time.Unix(0, 0), time.Now())
+	report.AddDeposits([]types.Deposit{
+		{Asset: "USDT", Amount: 10000.0, Status: types.DepositSuccess},
+		{Asset: "USDT", Amount: 5000.0, Status: types.DepositPending},
+	})
+	report.AddWithdraws([]types.Withdraw{
+		{Asset: "BTC", Amount: 0.2, TransactionFee: 0.0005, Status: types.WithdrawCompleted},
+		{Asset: "BTC", Amount: 0.1, Status: "rejected"},
+	})
+	report.AddTrades(markets, []types.Trade{
+		{Symbol: "BTCUSDT", Price: 10000.0, Quantity: 0.5, QuoteQuantity: 5000.0, IsBuyer: true, Fee: 0.0005, FeeCurrency: "BTC"},
+		{Symbol: "BTCUSDT", Price: 12000.0, Quantity: 0.1, IsBuyer: false, Fee: 1.2, FeeCurrency: "USDT"},
+		{Symbol: "ETHUSDT", Price: 1000.0, Quantity: 1.0, IsBuyer: true},
+	})
+	report.Reconcile(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.199)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(6198.8)},
+	})
+
+	assert.Equal(t, []string{"BTC", "USDT"}, report.Assets())
+
+	btc := report.Flows["BTC"]
+	assert.InDelta(t, -0.2005, btc.NetFlow(), 1e-8)
+	assert.InDelta(t, 0.3995, btc.TradingChange, 1e-8)
+	assert.InDelta(t, 0.0, btc.Unreconciled(), 1e-8)
+
+	usdt := report.Flows["USDT"]
+	assert.InDelta(t, 10000.0, usdt.NetFlow(), 1e-8)
+	assert.InDelta(t, -3801.2, usdt.TradingChange, 1e-8)
+	assert.InDelta(t, 1.2, usdt.TradingFees, 1e-8)
+	assert.InDelta(t, 0.0, usdt.Unreconciled(), 1e-8)
+
+	netFlowValue, tradingValue := report.Values("USDT", map[string]float64{"BTC": 10000.0})
+	assert.InDelta(t, 10000.0-2005.0, netFlowValue, 1e-8)
+	assert.InDelta(t, 3995.0-3801.2, tradingValue, 1e-8)
+}
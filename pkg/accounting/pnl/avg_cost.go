@@ -1,111 +1,152 @@
 package pnl
 
 import (
+	"sort"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
+
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+// defaultDustQuantity is the remaining stock considered as closed when the market is not given
+const defaultDustQuantity = 1e-8
+
 type AverageCostCalculator struct {
 	TradingFeeCurrency string
+
+	// Market is the market of the symbol, the min quantity is used as the dust quantity of closing a round trip
+	Market types.Market
+
+	// FeePrices are the prices of the trading fee currencies in the quote currency, e.g., BNB: 300.0 for BTCUSDT,
+	// the fees paid in the currencies without the price are not counted in the profit.
+	FeePrices map[string]float64
 }
 
-func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, currentPrice float64) *AverageCostPnlReport {
-	// copy trades, so that we can truncate it.
-	var bidVolume = 0.0
-	var bidAmount = 0.0
+func (c *AverageCostCalculator) dustQuantity() float64 {
+	if c.Market.MinQuantity > 0 {
+		return c.Market.MinQuantity
+	}
+
+	return defaultDustQuantity
+}
+
+// feeInQuote returns the fee of the trade in the quote currency, the second returned value is the fee deducted from the received base quantity
+func (c *AverageCostCalculator) feeInQuote(symbol string, trade types.Trade) (quoteFee float64, baseFee float64, ok bool) {
+	switch {
+	case trade.Fee == 0:
+		return 0, 0, true
+
+	case c.Market.BaseCurrency == trade.FeeCurrency || (c.Market.BaseCurrency == "" && strings.HasPrefix(symbol, trade.FeeCurrency)):
+		return trade.Fee * trade.Price, trade.Fee, true
+
+	case c.Market.QuoteCurrency == trade.FeeCurrency || (c.Market.QuoteCurrency == "" && strings.HasSuffix(symbol, trade.FeeCurrency)):
+		return trade.Fee, 0, true
 
-	var askVolume = 0.0
+	case c.Market.QuoteCurrency != "" && types.EquivalentCurrencies(c.Market.QuoteCurrency, trade.FeeCurrency):
+		// the fee paid in the equivalent currency of the quote currency, e.g., BUSD fee of the USDT market
+		return trade.Fee, 0, true
+	}
+
+	if price, ok := c.FeePrices[trade.FeeCurrency]; ok {
+		return trade.Fee * price, 0, true
+	}
 
-	var feeUSD = 0.0
-	var bidFeeUSD = 0.0
-	var feeRate = 0.0015
+	return 0, 0, false
+}
+
+// Calculate computes the profit of the trades with the average cost, the trades are processed in the time order:
+// the buy trades (including the fees) update the average cost of the stock, and the sell trades realize the profit
+// against the average cost. A round trip is counted when the stock is closed by the sell trades.
+// The trades of the other symbols paying the fees in the trading fee currency reduce the stock of the fee currency symbol.
+func (c *AverageCostCalculator) Calculate(symbol string, trades []types.Trade, currentPrice float64) *AverageCostPnlReport {
+	report := &AverageCostPnlReport{
+		Symbol:       symbol,
+		CurrentPrice: currentPrice,
+		Market:       c.Market,
+		NumTrades:    len(trades),
+		CurrencyFees: map[string]float64{},
+	}
 
 	if len(trades) == 0 {
-		return &AverageCostPnlReport{
-			Symbol:       symbol,
-			CurrentPrice: currentPrice,
-			NumTrades:    0,
-			BuyVolume:    bidVolume,
-			SellVolume:   askVolume,
-			FeeInUSD:     feeUSD,
-		}
+		return report
 	}
 
-	var currencyFees = map[string]float64{}
+	// copy trades, so that we can sort it
+	trades = append([]types.Trade(nil), trades...)
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
 
+	report.StartTime = trades[0].Time
+	report.EndTime = trades[len(trades)-1].Time
+
+	var stock, cost float64
+	var unpricedFees = map[string]struct{}{}
 	for _, trade := range trades {
-		if trade.Symbol == symbol {
-			if trade.IsBuyer {
-				bidVolume += trade.Quantity
-				bidAmount += trade.Price * trade.Quantity
-			}
+		if trade.Fee > 0 {
+			report.CurrencyFees[trade.FeeCurrency] += trade.Fee
+		}
 
-			// since we use USDT as the quote currency, we simply check if it matches the currency symbol
-			if strings.HasPrefix(trade.Symbol, trade.FeeCurrency) {
-				bidVolume -= trade.Fee
-				feeUSD += trade.Price * trade.Fee
-				if trade.IsBuyer {
-					bidFeeUSD += trade.Price * trade.Fee
-				}
-			} else if trade.FeeCurrency == "USDT" {
-				feeUSD += trade.Fee
-				if trade.IsBuyer {
-					bidFeeUSD += trade.Fee
-				}
+		if trade.Symbol != symbol {
+			// the fee of the other symbols paid in the base currency of this symbol, e.g., BNB of BNBUSDT
+			if trade.FeeCurrency == c.TradingFeeCurrency && strings.HasPrefix(symbol, trade.FeeCurrency) {
+				stock -= trade.Fee
 			}
 
-		} else {
-			if trade.FeeCurrency == c.TradingFeeCurrency {
-				bidVolume -= trade.Fee
-			}
+			continue
 		}
 
-		if _, ok := currencyFees[trade.FeeCurrency]; !ok {
-			currencyFees[trade.FeeCurrency] = 0.0
+		quoteFee, baseFee, ok := c.feeInQuote(symbol, trade)
+		if !ok {
+			unpricedFees[trade.FeeCurrency] = struct{}{}
 		}
-		currencyFees[trade.FeeCurrency] += trade.Fee
-	}
 
-	profit := 0.0
-	averageCost := (bidAmount + bidFeeUSD) / bidVolume
+		report.FeeInUSD += quoteFee
 
-	for _, t := range trades {
-		if t.Symbol != symbol {
+		if trade.IsBuyer {
+			quantity := trade.Quantity - baseFee
+			report.BuyVolume += quantity
+			stock += quantity
+			cost += trade.Price*trade.Quantity + quoteFee - baseFee*trade.Price
 			continue
 		}
 
-		if t.IsBuyer {
-			continue
+		report.SellVolume += trade.Quantity
+
+		// the stock held before the first trade is unknown, only the matched quantity realizes the profit
+		matched := trade.Quantity
+		if matched > stock {
+			matched = stock
 		}
 
-		profit += (t.Price - averageCost) * t.Quantity
-		askVolume += t.Quantity
-	}
+		if matched > 0 {
+			averageCost := cost / stock
+			report.Profit += (trade.Price - averageCost) * matched
+			cost -= averageCost * matched
+			stock -= matched
+		}
 
-	profit -= feeUSD
-	unrealizedProfit := profit
+		report.Profit -= quoteFee
 
-	stock := bidVolume - askVolume
-	if stock > 0 {
-		stockFee := currentPrice * stock * feeRate
-		unrealizedProfit += (currentPrice-averageCost)*stock - stockFee
-	}
+		if stock <= c.dustQuantity() {
+			if matched > 0 {
+				report.NumRoundTrips++
+			}
 
-	return &AverageCostPnlReport{
-		Symbol:       symbol,
-		CurrentPrice: currentPrice,
-		NumTrades:    len(trades),
-		StartTime:    trades[0].Time,
+			stock, cost = 0, 0
+		}
+	}
 
-		BuyVolume:  bidVolume,
-		SellVolume: askVolume,
+	for currency := range unpricedFees {
+		log.Warnf("the price of the fee currency %s is not given, the fees paid in %s are not counted in the profit", currency, currency)
+	}
 
-		Stock:            stock,
-		Profit:           profit,
-		UnrealizedProfit: unrealizedProfit,
-		AverageBidCost:   averageCost,
-		FeeInUSD:         feeUSD,
-		CurrencyFees:     currencyFees,
+	if stock > 0 {
+		report.AverageBidCost = cost / stock
+		report.UnrealizedProfit = (currentPrice - report.AverageBidCost) * stock
 	}
+
+	report.Stock = stock
+	return report
 }
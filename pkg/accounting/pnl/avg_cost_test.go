@@ -0,0 +1,77 @@
+package pnl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAverageCostCalculator_Calculate(t *testing.T) {
+	market := types.Market{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		MinQuantity:   0.0001,
+	}
+
+	now := time.Now()
+
+	t.Run("round trips with the quote and base fees", func(t *testing.T) {
+		trades := []types.Trade{
+			// sorted by the trade time
+			{Symbol: "BTCUSDT", Price: 12000.0, Quantity: 1.0, IsBuyer: false, Fee: 12.0, FeeCurrency: "USDT", Time: now.Add(time.Minute)},
+			{Symbol: "BTCUSDT", Price: 10000.0, Quantity: 1.0, IsBuyer: true, Fee: 10.0, FeeCurrency: "USDT", Time: now},
+			{Symbol: "BTCUSDT", Price: 11000.0, Quantity: 0.5, IsBuyer: true, Fee: 0.001, FeeCurrency: "BTC", Time: now.Add(2 * time.Minute)},
+		}
+
+		calculator := &AverageCostCalculator{TradingFeeCurrency: "BNB", Market: market}
+		report := calculator.Calculate("BTCUSDT", trades, 12000.0)
+
+		assert.Equal(t, 3, report.NumTrades)
+		assert.Equal(t, 1, report.NumRoundTrips)
+		assert.Equal(t, now, report.StartTime)
+		assert.Equal(t, now.Add(2*time.Minute), report.EndTime)
+		// (12000 - 10010) * 1.0 - 12
+		assert.InDelta(t, 1978.0, report.Profit, 1e-8)
+		assert.InDelta(t, 0.499, report.Stock, 1e-8)
+		assert.InDelta(t, 11000.0*0.5/0.499, report.AverageBidCost, 1e-8)
+		assert.InDelta(t, 10.0+12.0+11.0, report.FeeInUSD, 1e-8)
+		assert.InDelta(t, 22.0, report.CurrencyFees["USDT"], 1e-8)
+	})
+
+	t.Run("the fees paid in the platform fee currency", func(t *testing.T) {
+		trades := []types.Trade{
+			{Symbol: "BTCUSDT", Price: 10000.0, Quantity: 1.0, IsBuyer: true, Fee: 0.1, FeeCurrency: "BNB", Time: now},
+			{Symbol: "BTCUSDT", Price: 11000.0, Quantity: 1.0, IsBuyer: false, Fee: 0.1, FeeCurrency: "BNB", Time: now.Add(time.Minute)},
+		}
+
+		calculator := &AverageCostCalculator{
+			TradingFeeCurrency: "BNB",
+			Market:             market,
+			FeePrices:          map[string]float64{"BNB": 300.0},
+		}
+		report := calculator.Calculate("BTCUSDT", trades, 11000.0)
+
+		assert.Equal(t, 1, report.NumRoundTrips)
+		assert.InDelta(t, 1000.0-60.0, report.Profit, 1e-8)
+		assert.InDelta(t, 60.0, report.FeeInUSD, 1e-8)
+		assert.InDelta(t, 0.2, report.CurrencyFees["BNB"], 1e-8)
+		assert.Equal(t, 0.0, report.Stock)
+	})
+
+	t.Run("the sells without the known stock", func(t *testing.T) {
+		trades := []types.Trade{
+			{Symbol: "BTCUSDT", Price: 11000.0, Quantity: 1.0, IsBuyer: false, Time: now},
+		}
+
+		calculator := &AverageCostCalculator{TradingFeeCurrency: "BNB", Market: market}
+		report := calculator.Calculate("BTCUSDT", trades, 11000.0)
+
+		assert.Equal(t, 0, report.NumRoundTrips)
+		assert.Equal(t, 0.0, report.Profit)
+		assert.Equal(t, 1.0, report.SellVolume)
+	})
+}
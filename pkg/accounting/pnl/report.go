@@ -14,10 +14,12 @@ import (
 type AverageCostPnlReport struct {
 	CurrentPrice float64
 	StartTime    time.Time
+	EndTime      time.Time
 	Symbol       string
 	Market       types.Market
 
 	NumTrades        int
+	NumRoundTrips    int
 	Profit           float64
 	UnrealizedProfit float64
 	AverageBidCost   float64
@@ -30,7 +32,9 @@ type AverageCostPnlReport struct {
 
 func (report AverageCostPnlReport) Print() {
 	log.Infof("TRADES SINCE: %v", report.StartTime)
+	log.Infof("TRADES UNTIL: %v", report.EndTime)
 	log.Infof("NUMBER OF TRADES: %d", report.NumTrades)
+	log.Infof("NUMBER OF ROUND TRIPS: %d", report.NumRoundTrips)
 	log.Infof("AVERAGE COST: %s", types.USD.FormatMoneyFloat64(report.AverageBidCost))
 	log.Infof("TOTAL BUY VOLUME: %f", report.BuyVolume)
 	log.Infof("TOTAL SELL VOLUME: %f", report.SellVolume)
@@ -66,6 +70,7 @@ func (report AverageCostPnlReport) SlackAttachment() slack.Attachment {
 			{Title: "Fee (USD)", Value: types.USD.FormatMoney(report.FeeInUSD), Short: true},
 			{Title: "Stock", Value: strconv.FormatFloat(report.Stock, 'f', 8, 64), Short: true},
 			{Title: "Number of Trades", Value: strconv.Itoa(report.NumTrades), Short: true},
+			{Title: "Number of Round Trips", Value: strconv.Itoa(report.NumRoundTrips), Short: true},
 		},
 		Footer:     report.StartTime.Format(time.RFC822),
 		FooterIcon: "",
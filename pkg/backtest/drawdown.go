@@ -0,0 +1,91 @@
+package backtest
+
+import (
+	"math"
+	"time"
+)
+
+// DrawdownRecorder records the equity curve during the backtest and tracks the max drawdown
+type DrawdownRecorder struct {
+	Peak float64
+
+	// MaxDrawdown is the max drawdown ratio from the peak equity, e.g., 0.1 means 10%
+	MaxDrawdown float64
+
+	Last float64
+
+	// DailyEquity is the last equity of each day recorded by RecordAt, it's used for the Sharpe ratio
+	DailyEquity []float64
+
+	lastDay time.Time
+}
+
+func (r *DrawdownRecorder) Record(equity float64) {
+	r.Last = equity
+
+	if equity > r.Peak {
+		r.Peak = equity
+		return
+	}
+
+	if r.Peak <= 0 {
+		return
+	}
+
+	if drawdown := (r.Peak - equity) / r.Peak; drawdown > r.MaxDrawdown {
+		r.MaxDrawdown = drawdown
+	}
+}
+
+// RecordAt records the equity at the time, and samples the last equity of each UTC day
+func (r *DrawdownRecorder) RecordAt(t time.Time, equity float64) {
+	r.Record(equity)
+
+	day := t.UTC().Truncate(24 * time.Hour)
+	if len(r.DailyEquity) == 0 || day.After(r.lastDay) {
+		r.DailyEquity = append(r.DailyEquity, equity)
+		r.lastDay = day
+		return
+	}
+
+	r.DailyEquity[len(r.DailyEquity)-1] = equity
+}
+
+// SharpeRatio returns the annualized Sharpe ratio of the daily returns with the zero risk-free rate,
+// it's zero if there are less than two daily returns or the returns do not vary.
+func (r *DrawdownRecorder) SharpeRatio() float64 {
+	var returns []float64
+	for i := 1; i < len(r.DailyEquity); i++ {
+		if r.DailyEquity[i-1] <= 0 {
+			continue
+		}
+
+		returns = append(returns, r.DailyEquity[i]/r.DailyEquity[i-1]-1.0)
+	}
+
+	return SharpeRatio(returns, 365)
+}
+
+// SharpeRatio returns the annualized Sharpe ratio of the period returns, periods is the number of the periods per year
+func SharpeRatio(returns []float64, periods float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(returns)-1))
+	if stddev == 0 {
+		return 0
+	}
+
+	return mean / stddev * math.Sqrt(periods)
+}
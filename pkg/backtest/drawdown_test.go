@@ -0,0 +1,45 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawdownRecorder_RecordAt(t *testing.T) {
+	recorder := &DrawdownRecorder{}
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	recorder.RecordAt(startTime, 1000)
+	recorder.RecordAt(startTime.Add(12*time.Hour), 1100)
+	recorder.RecordAt(startTime.Add(24*time.Hour), 990)
+	recorder.RecordAt(startTime.Add(48*time.Hour), 1050)
+
+	assert.Equal(t, []float64{1100, 990, 1050}, recorder.DailyEquity)
+	assert.Equal(t, 1100.0, recorder.Peak)
+	assert.InDelta(t, 0.1, recorder.MaxDrawdown, 1e-9)
+	assert.Equal(t, 1050.0, recorder.Last)
+}
+
+func TestSharpeRatio(t *testing.T) {
+	assert.Equal(t, 0.0, SharpeRatio([]float64{0.01}, 365))
+	assert.Equal(t, 0.0, SharpeRatio([]float64{0.01, 0.01, 0.01}, 365))
+
+	// mean 0.01, sample stddev 0.01
+	assert.InDelta(t, math.Sqrt(365), SharpeRatio([]float64{0.0, 0.01, 0.02}, 365), 1e-9)
+}
+
+func TestSummaryReport(t *testing.T) {
+	report := SummaryReport{
+		Symbols: []SymbolReport{
+			{Symbol: "BTCUSDT", Profit: 100, UnrealizedProfit: -20, MaxDrawdown: 0.05, Sharpe: 1.5},
+			{Symbol: "ETHUSDT", Profit: 50, MaxDrawdown: 0.12, Sharpe: 0.5},
+		},
+	}
+
+	assert.Equal(t, 130.0, report.TotalProfit())
+	assert.Equal(t, 0.12, report.MaxDrawdown())
+	assert.Equal(t, 1.0, report.Sharpe())
+}
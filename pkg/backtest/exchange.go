@@ -9,6 +9,10 @@ import (
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex"
+	"github.com/c9s/bbgo/pkg/exchange/coinbase"
+	"github.com/c9s/bbgo/pkg/exchange/kraken"
+	"github.com/c9s/bbgo/pkg/exchange/kucoin"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
@@ -106,6 +110,8 @@ func (e *Exchange) NewStream() types.Stream {
 			Market:          market,
 			MakerCommission: e.config.Account.MakerCommission,
 			TakerCommission: e.config.Account.TakerCommission,
+			Slippage:        e.config.Slippage,
+			FillVolumeRatio: e.config.FillVolumeRatio,
 		}
 		matching.OnTradeUpdate(e.stream.EmitTradeUpdate)
 		matching.OnOrderUpdate(e.stream.EmitOrderUpdate)
@@ -124,7 +130,8 @@ func (e Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder)
 			return nil, fmt.Errorf("matching engine is not initialized for symbol %s", symbol)
 		}
 
-		createdOrder, trade, err := matching.PlaceOrder(order)
+		// the trades of the filled orders are emitted by the matching engine through the stream
+		createdOrder, _, err := matching.PlaceOrder(order)
 		if err != nil {
 			return nil, err
 		}
@@ -140,10 +147,6 @@ func (e Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder)
 
 			e.stream.EmitOrderUpdate(*createdOrder)
 		}
-
-		if trade != nil {
-			e.stream.EmitTradeUpdate(*trade)
-		}
 	}
 
 	return createdOrders, nil
@@ -212,6 +215,12 @@ func (e Exchange) Name() types.ExchangeName {
 	return e.publicExchange.Name()
 }
 
+// Capabilities returns the capabilities simulated by the backtest exchange,
+// the post only orders crossing the last price are rejected by the matching engine.
+func (e Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(types.CapabilityUserDataStream, types.CapabilityPostOnly, types.CapabilityStopOrder)
+}
+
 func (e Exchange) PlatformFeeCurrency() string {
 	return e.publicExchange.PlatformFeeCurrency()
 }
@@ -234,6 +243,14 @@ func newPublicExchange(sourceExchange types.ExchangeName) (types.Exchange, error
 		return binance.New("", ""), nil
 	case types.ExchangeMax:
 		return max.New("", ""), nil
+	case types.ExchangeKucoin:
+		return kucoin.New("", "", ""), nil
+	case types.ExchangeCoinbase:
+		return coinbase.New("", ""), nil
+	case types.ExchangeBitfinex:
+		return bitfinex.New("", ""), nil
+	case types.ExchangeKraken:
+		return kraken.New("", ""), nil
 	}
 
 	return nil, fmt.Errorf("exchange %s is not supported", sourceExchange)
@@ -0,0 +1,250 @@
+package backtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BookSnapshot is the order book snapshot captured at the given time
+type BookSnapshot struct {
+	Time time.Time       `json:"time"`
+	Book types.OrderBook `json:"book"`
+}
+
+// Fixture is the backtest dataset of one symbol converted from the stream recordings.
+// The klines, the book snapshots and the private fills are trimmed to the time range covered by the 1m klines,
+// since the 1m klines drive the clock of the backtest matching engine.
+type Fixture struct {
+	Exchange  string    `json:"exchange"`
+	Symbol    string    `json:"symbol"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	KLines        []types.KLine  `json:"klines"`
+	BookSnapshots []BookSnapshot `json:"bookSnapshots"`
+	Fills         []types.Trade  `json:"fills"`
+}
+
+// fixtureKey groups the recorded events by the exchange and the symbol
+type fixtureKey struct {
+	exchange, symbol string
+}
+
+type klineKey struct {
+	interval  types.Interval
+	startTime time.Time
+}
+
+// NewFixtures converts the recorded events into the backtest fixtures, one fixture for each exchange and symbol.
+// The klines of the intervals that are not recorded are aggregated from the recorded 1m klines,
+// only the complete klines are aggregated.
+func NewFixtures(events []types.RecordedEvent) (fixtures []*Fixture) {
+	var keys []fixtureKey
+	var groups = make(map[fixtureKey][]types.RecordedEvent)
+	for _, event := range events {
+		key := fixtureKey{exchange: event.Exchange, symbol: event.Symbol}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		groups[key] = append(groups[key], event)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].exchange == keys[j].exchange {
+			return keys[i].symbol < keys[j].symbol
+		}
+
+		return keys[i].exchange < keys[j].exchange
+	})
+
+	for _, key := range keys {
+		if fixture := newFixture(key.exchange, key.symbol, groups[key]); fixture != nil {
+			fixtures = append(fixtures, fixture)
+		}
+	}
+
+	return fixtures
+}
+
+func newFixture(exchange, symbol string, events []types.RecordedEvent) *Fixture {
+	var klines = make(map[klineKey]types.KLine)
+	var fills = make(map[int64]types.Trade)
+	var books []BookSnapshot
+
+	for _, event := range events {
+		switch event.Type {
+		case types.RecordEventKLine:
+			if event.KLine != nil {
+				// the exchange name is required by the backtest database
+				k := *event.KLine
+				if len(k.Exchange) == 0 {
+					k.Exchange = exchange
+				}
+
+				// the later kline of the same start time replaces the earlier one
+				klines[klineKey{interval: k.Interval, startTime: k.StartTime}] = k
+			}
+
+		case types.RecordEventBook:
+			if event.Book != nil {
+				books = append(books, BookSnapshot{Time: event.EventTime(), Book: event.Book.Copy()})
+			}
+
+		case types.RecordEventTrade:
+			if event.Trade != nil {
+				fills[event.Trade.ID] = *event.Trade
+			}
+		}
+	}
+
+	var minuteKLines []types.KLine
+	var recordedIntervals = make(map[types.Interval]struct{})
+	for key, k := range klines {
+		recordedIntervals[key.interval] = struct{}{}
+		if key.interval == types.Interval1m {
+			minuteKLines = append(minuteKLines, k)
+		}
+	}
+
+	// without the 1m klines, the backtest clock can not be driven
+	if len(minuteKLines) == 0 {
+		return nil
+	}
+
+	sortKLines(minuteKLines)
+
+	fixture := &Fixture{
+		Exchange:  exchange,
+		Symbol:    symbol,
+		StartTime: minuteKLines[0].StartTime,
+		EndTime:   minuteKLines[len(minuteKLines)-1].EndTime,
+	}
+
+	for _, k := range klines {
+		fixture.KLines = append(fixture.KLines, k)
+	}
+
+	for interval := range types.SupportedIntervals {
+		if _, ok := recordedIntervals[interval]; ok {
+			continue
+		}
+
+		fixture.KLines = append(fixture.KLines, AggregateKLines(minuteKLines, interval)...)
+	}
+
+	sortKLines(fixture.KLines)
+
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].Time.Before(books[j].Time)
+	})
+
+	for _, b := range books {
+		if fixture.covers(b.Time) {
+			fixture.BookSnapshots = append(fixture.BookSnapshots, b)
+		}
+	}
+
+	for _, t := range fills {
+		if fixture.covers(t.Time) {
+			fixture.Fills = append(fixture.Fills, t)
+		}
+	}
+
+	sort.Slice(fixture.Fills, func(i, j int) bool {
+		if fixture.Fills[i].Time.Equal(fixture.Fills[j].Time) {
+			return fixture.Fills[i].ID < fixture.Fills[j].ID
+		}
+
+		return fixture.Fills[i].Time.Before(fixture.Fills[j].Time)
+	})
+
+	return fixture
+}
+
+func (f *Fixture) covers(t time.Time) bool {
+	return !t.Before(f.StartTime) && !t.After(f.EndTime)
+}
+
+// WriteFile writes the fixture as a JSON file
+func (f *Fixture) WriteFile(filename string) error {
+	out, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, out, 0666)
+}
+
+// sortKLines sorts the klines by the start time, and then by the interval
+func sortKLines(klines []types.KLine) {
+	sort.Slice(klines, func(i, j int) bool {
+		if klines[i].StartTime.Equal(klines[j].StartTime) {
+			return klines[i].Interval.Minutes() < klines[j].Interval.Minutes()
+		}
+
+		return klines[i].StartTime.Before(klines[j].StartTime)
+	})
+}
+
+// AggregateKLines aggregates the sorted 1m klines into the klines of the given interval,
+// the incomplete klines (the klines with missing minutes) are dropped.
+func AggregateKLines(minuteKLines []types.KLine, interval types.Interval) (klines []types.KLine) {
+	var minutes = interval.Minutes()
+	if minutes == 0 {
+		return nil
+	}
+
+	var current *types.KLine
+	var count int
+
+	flush := func() {
+		if current != nil && count == minutes {
+			current.Closed = true
+			klines = append(klines, *current)
+		}
+
+		current = nil
+		count = 0
+	}
+
+	for _, k := range minuteKLines {
+		startTime := k.StartTime.Truncate(interval.Duration())
+		if current != nil && !current.StartTime.Equal(startTime) {
+			flush()
+		}
+
+		if current == nil {
+			current = &types.KLine{
+				Exchange:  k.Exchange,
+				Symbol:    k.Symbol,
+				StartTime: startTime,
+				Interval:  interval,
+				Open:      k.Open,
+				High:      k.High,
+				Low:       k.Low,
+			}
+		}
+
+		current.EndTime = k.EndTime
+		current.Close = k.Close
+		if k.High > current.High {
+			current.High = k.High
+		}
+		if k.Low < current.Low {
+			current.Low = k.Low
+		}
+		current.Volume += k.Volume
+		current.QuoteVolume += k.QuoteVolume
+		current.NumberOfTrades += k.NumberOfTrades
+		current.LastTradeID = k.LastTradeID
+		count++
+	}
+
+	flush()
+	return klines
+}
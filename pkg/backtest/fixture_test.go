@@ -0,0 +1,116 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMinuteKLine(startTime time.Time, open, high, low, close float64) types.KLine {
+	return types.KLine{
+		Symbol:    "BTCUSDT",
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Minute - time.Millisecond),
+		Interval:  types.Interval1m,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    1.0,
+		Closed:    true,
+	}
+}
+
+func TestAggregateKLines(t *testing.T) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var minuteKLines []types.KLine
+	for i := 0; i < 7; i++ {
+		price := 100.0 + float64(i)
+		minuteKLines = append(minuteKLines, newMinuteKLine(startTime.Add(time.Duration(i)*time.Minute), price, price+2, price-1, price+1))
+	}
+
+	klines := AggregateKLines(minuteKLines, types.Interval5m)
+
+	// the second 5m kline only has 2 minutes, it's dropped
+	if assert.Len(t, klines, 1) {
+		k := klines[0]
+		assert.Equal(t, startTime, k.StartTime)
+		assert.Equal(t, minuteKLines[4].EndTime, k.EndTime)
+		assert.Equal(t, 100.0, k.Open)
+		assert.Equal(t, 105.0, k.Close)
+		assert.Equal(t, 106.0, k.High)
+		assert.Equal(t, 99.0, k.Low)
+		assert.Equal(t, 5.0, k.Volume)
+		assert.True(t, k.Closed)
+	}
+}
+
+func TestNewFixtures(t *testing.T) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var events []types.RecordedEvent
+	for i := 0; i < 5; i++ {
+		k := newMinuteKLine(startTime.Add(time.Duration(i)*time.Minute), 100, 101, 99, 100)
+		events = append(events, types.RecordedEvent{
+			Time:     k.EndTime,
+			Exchange: "max",
+			Symbol:   "BTCUSDT",
+			Type:     types.RecordEventKLine,
+			KLine:    &k,
+		})
+	}
+
+	book := types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(99), Volume: fixedpoint.NewFromFloat(1)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(101), Volume: fixedpoint.NewFromFloat(1)}},
+	}
+
+	events = append(events,
+		// the book snapshot before the first kline is trimmed
+		types.RecordedEvent{Time: startTime.Add(-time.Minute), Exchange: "max", Symbol: "BTCUSDT", Type: types.RecordEventBook, Book: &book},
+		types.RecordedEvent{Time: startTime.Add(90 * time.Second), Exchange: "max", Symbol: "BTCUSDT", Type: types.RecordEventBook, Book: &book},
+	)
+
+	fill := types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 100, Quantity: 0.1, Time: startTime.Add(2 * time.Minute)}
+	for i := 0; i < 2; i++ {
+		// the duplicated fill is collected once
+		events = append(events, types.RecordedEvent{Time: fill.Time, Exchange: "max", Symbol: "BTCUSDT", Type: types.RecordEventTrade, Trade: &fill})
+	}
+
+	// the symbol without the 1m klines is skipped
+	events = append(events, types.RecordedEvent{Time: startTime, Exchange: "max", Symbol: "ETHUSDT", Type: types.RecordEventTrade, Trade: &types.Trade{ID: 2, Symbol: "ETHUSDT", Time: startTime}})
+
+	fixtures := NewFixtures(events)
+	if !assert.Len(t, fixtures, 1) {
+		return
+	}
+
+	fixture := fixtures[0]
+	assert.Equal(t, "max", fixture.Exchange)
+	assert.Equal(t, "BTCUSDT", fixture.Symbol)
+	assert.Equal(t, startTime, fixture.StartTime)
+	assert.Len(t, fixture.BookSnapshots, 1)
+	assert.Len(t, fixture.Fills, 1)
+
+	var numOfMinuteKLines, numOf5mKLines int
+	for _, k := range fixture.KLines {
+		assert.Equal(t, "max", k.Exchange)
+
+		switch k.Interval {
+		case types.Interval1m:
+			numOfMinuteKLines++
+		case types.Interval5m:
+			numOf5mKLines++
+		}
+	}
+
+	assert.Equal(t, 5, numOfMinuteKLines)
+	assert.Equal(t, 1, numOf5mKLines)
+	assert.Len(t, fixture.KLines, 6)
+}
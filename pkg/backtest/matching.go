@@ -2,6 +2,7 @@ package backtest
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,6 +20,9 @@ import (
 // MAX uses 0.050% for maker and 0.15% for taker
 const DefaultFeeRate = 0.15 * 0.001
 
+// fillQuantityEpsilon is the remaining quantity treated as fully filled, for the float rounding errors of the partial fills
+const fillQuantityEpsilon = 1e-12
+
 var orderID uint64 = 1
 var tradeID uint64 = 1
 
@@ -48,6 +52,17 @@ type SimplePriceMatching struct {
 	MakerCommission int `json:"makerCommission"`
 	TakerCommission int `json:"takerCommission"`
 
+	// Slippage is the price slippage ratio applied to the market orders
+	Slippage fixedpoint.Value `json:"slippage"`
+
+	// FillVolumeRatio caps the quantity filled by the resting limit orders in a kline to the ratio of the kline volume,
+	// the orders beyond the cap are partially filled. Zero fills the touched orders fully.
+	FillVolumeRatio fixedpoint.Value `json:"fillVolumeRatio"`
+
+	// fillCapacity is the remaining quantity that can be filled in the current kline when capacityLimited is set
+	fillCapacity    float64
+	capacityLimited bool
+
 	tradeUpdateCallbacks   []func(trade types.Trade)
 	orderUpdateCallbacks   []func(order types.Order)
 	balanceUpdateCallbacks []func(balances types.BalanceMap)
@@ -63,7 +78,9 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.bidOrders {
 			if o.OrderID == order.OrderID {
+				// use the order in the book, which has the executed quantity of the partial fills
 				found = true
+				o = order
 				continue
 			}
 			orders = append(orders, order)
@@ -76,7 +93,9 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		var orders []types.Order
 		for _, order := range m.askOrders {
 			if o.OrderID == order.OrderID {
+				// use the order in the book, which has the executed quantity of the partial fills
 				found = true
+				o = order
 				continue
 			}
 			orders = append(orders, order)
@@ -92,14 +111,15 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		return o, fmt.Errorf("cancel order failed, order %d not found: %+v", o.OrderID, o)
 	}
 
+	remaining := o.Quantity - o.ExecutedQuantity
 	switch o.Side {
 	case types.SideTypeBuy:
-		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(o.Price*o.Quantity)); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(o.Price*remaining)); err != nil {
 			return o, err
 		}
 
 	case types.SideTypeSell:
-		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(o.Quantity)); err != nil {
+		if err := m.Account.UnlockBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(remaining)); err != nil {
 			return o, err
 		}
 	}
@@ -111,12 +131,17 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 }
 
 func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (closedOrders *types.Order, trades *types.Trade, err error) {
+	// the limit order crossing the last price is filled immediately as a taker order
+	taker := o.Type == types.OrderTypeLimit && m.crossesLastPrice(o.Side, o.Price)
+	if taker && o.PostOnly {
+		return nil, nil, fmt.Errorf("post only %s order at %f would be filled immediately at the last price %f", o.Side, o.Price, m.LastPrice.Float64())
+	}
 
 	// price for checking account balance
 	price := o.Price
 	switch o.Type {
 	case types.OrderTypeMarket:
-		price = m.LastPrice.Float64()
+		price = m.marketOrderPrice(o.Side)
 	case types.OrderTypeLimit:
 		price = o.Price
 	}
@@ -142,6 +167,8 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (closedOrders *typ
 	order := m.newOrder(o, orderID)
 
 	if o.Type == types.OrderTypeMarket {
+		// market orders are filled at the last price with the slippage
+		order.Price = price
 		m.EmitOrderUpdate(order)
 
 		// emit trade before we publish order
@@ -151,12 +178,32 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (closedOrders *typ
 		// update the order status
 		order.Status = types.OrderStatusFilled
 		order.ExecutedQuantity = order.Quantity
-		order.Price = price
 		m.EmitOrderUpdate(order)
 		m.EmitBalanceUpdate(m.Account.Balances())
 		return &order, &trade, nil
 	}
 
+	if taker {
+		m.EmitOrderUpdate(order)
+
+		// the taker order is filled at the last price (with the slippage) but not worse than the limit price
+		fillPrice := m.takerPrice(order.Side, order.Price)
+		if order.Side == types.SideTypeBuy && fillPrice < order.Price {
+			// release the quote locked by the better price
+			if err := m.Account.UnlockBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat((order.Price-fillPrice)*order.Quantity)); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		trade := m.newTrade(order, fillPrice, order.Quantity, false)
+		m.executeTrade(trade)
+
+		order.Status = types.OrderStatusFilled
+		order.ExecutedQuantity = order.Quantity
+		m.EmitOrderUpdate(order)
+		return &order, &trade, nil
+	}
+
 	// for limit maker orders
 	switch o.Side {
 
@@ -176,17 +223,113 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (closedOrders *typ
 	return &order, nil, nil
 }
 
+// marketOrderPrice returns the fill price of the market order, the slippage makes the price worse for the taker
+func (m *SimplePriceMatching) marketOrderPrice(side types.SideType) float64 {
+	price := m.LastPrice.Float64()
+	slippage := m.Slippage.Float64()
+
+	switch side {
+	case types.SideTypeBuy:
+		return price * (1.0 + slippage)
+	case types.SideTypeSell:
+		return price * (1.0 - slippage)
+	}
+
+	return price
+}
+
+// crossesLastPrice returns true if the limit price would be matched by the opposite side at the last price
+func (m *SimplePriceMatching) crossesLastPrice(side types.SideType, price float64) bool {
+	lastPrice := m.LastPrice.Float64()
+	if lastPrice <= 0 {
+		return false
+	}
+
+	switch side {
+	case types.SideTypeBuy:
+		return price >= lastPrice
+	case types.SideTypeSell:
+		return price <= lastPrice
+	}
+
+	return false
+}
+
+// takerPrice returns the fill price of the marketable limit order
+func (m *SimplePriceMatching) takerPrice(side types.SideType, limitPrice float64) float64 {
+	price := m.marketOrderPrice(side)
+	switch side {
+	case types.SideTypeBuy:
+		return math.Min(price, limitPrice)
+	case types.SideTypeSell:
+		return math.Max(price, limitPrice)
+	}
+
+	return price
+}
+
+// resetFillCapacity sets the fill capacity of the resting limit orders by the kline volume
+func (m *SimplePriceMatching) resetFillCapacity(kline types.KLine) {
+	m.capacityLimited = m.FillVolumeRatio > 0
+	m.fillCapacity = kline.Volume * m.FillVolumeRatio.Float64()
+}
+
+// fillQuantity returns the quantity of the order that can be filled within the fill capacity
+func (m *SimplePriceMatching) fillQuantity(o types.Order) float64 {
+	remaining := o.Quantity - o.ExecutedQuantity
+	if !m.capacityLimited || remaining <= m.fillCapacity {
+		return remaining
+	}
+
+	quantity := m.Market.TruncateQuantity(m.fillCapacity)
+	if quantity < m.Market.MinQuantity {
+		return 0
+	}
+
+	return quantity
+}
+
+// fillLimitOrder fills the resting limit order at the order price, the order is partially filled if the fill capacity
+// of the kline is not enough, the trade is nil if nothing is filled.
+func (m *SimplePriceMatching) fillLimitOrder(o types.Order) (types.Order, *types.Trade) {
+	quantity := m.fillQuantity(o)
+	if quantity <= 0 {
+		return o, nil
+	}
+
+	if m.capacityLimited {
+		m.fillCapacity -= quantity
+	}
+
+	trade := m.newTrade(o, o.Price, quantity, true)
+	m.executeTrade(trade)
+
+	o.ExecutedQuantity += quantity
+	o.UpdateTime = m.CurrentTime
+	if o.Quantity-o.ExecutedQuantity <= fillQuantityEpsilon {
+		o.ExecutedQuantity = o.Quantity
+		o.Status = types.OrderStatusFilled
+	} else {
+		o.Status = types.OrderStatusPartiallyFilled
+	}
+
+	m.EmitOrderUpdate(o)
+	return o, &trade
+}
+
 func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
 	var err error
 	// execute trade, update account balances
 	if trade.IsBuyer {
 		err = m.Account.UseLockedBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(trade.Price*trade.Quantity))
 
-		_ = m.Account.AddBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(trade.Quantity))
+		// the fee of the buy trade is deducted from the base asset
+		_ = m.Account.AddBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(trade.Quantity-trade.Fee))
 	} else {
 		err = m.Account.UseLockedBalance(m.Market.BaseCurrency, fixedpoint.NewFromFloat(trade.Quantity))
 
-		_ = m.Account.AddBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(trade.Quantity*trade.Price))
+		// the fee of the sell trade is deducted from the quote asset
+		_ = m.Account.AddBalance(m.Market.QuoteCurrency, fixedpoint.NewFromFloat(trade.Quantity*trade.Price-trade.Fee))
 	}
 
 	if err != nil {
@@ -198,27 +341,44 @@ func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
 	return
 }
 
-func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool) types.Trade {
+// feeRate returns the maker or the taker fee rate, the commissions are in basis points, e.g., binance uses 10~15,
+// the commissions of the matching engine are used first, then the commissions of the account.
+func (m *SimplePriceMatching) feeRate(isMaker bool) float64 {
+	commission, accountCommission := m.TakerCommission, m.Account.TakerCommission
+	if isMaker {
+		commission, accountCommission = m.MakerCommission, m.Account.MakerCommission
+	}
+
+	if commission <= 0 {
+		commission = accountCommission
+	}
+
 	// BINANCE uses 0.1% for both maker and taker
 	// MAX uses 0.050% for maker and 0.15% for taker
-	var commission = DefaultFeeRate
-	if isMaker && m.Account.MakerCommission > 0 {
-		commission = 0.0001 * float64(m.Account.MakerCommission) // binance uses 10~15
-	} else if m.Account.TakerCommission > 0 {
-		commission = 0.0001 * float64(m.Account.TakerCommission) // binance uses 10~15
+	if commission <= 0 {
+		return DefaultFeeRate
 	}
 
+	return 0.0001 * float64(commission)
+}
+
+func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool) types.Trade {
+	return m.newTrade(order, order.Price, order.Quantity, isMaker)
+}
+
+func (m *SimplePriceMatching) newTrade(order types.Order, price, quantity float64, isMaker bool) types.Trade {
+	var commission = m.feeRate(isMaker)
 	var fee float64
 	var feeCurrency string
 
 	switch order.Side {
 
 	case types.SideTypeBuy:
-		fee = order.Quantity * commission
+		fee = quantity * commission
 		feeCurrency = m.Market.BaseCurrency
 
 	case types.SideTypeSell:
-		fee = order.Quantity * order.Price * commission
+		fee = quantity * price * commission
 		feeCurrency = m.Market.QuoteCurrency
 
 	}
@@ -228,9 +388,9 @@ func (m *SimplePriceMatching) newTradeFromOrder(order types.Order, isMaker bool)
 		ID:            int64(id),
 		OrderID:       order.OrderID,
 		Exchange:      "backtest",
-		Price:         order.Price,
-		Quantity:      order.Quantity,
-		QuoteQuantity: order.Quantity * order.Price,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quantity * price,
 		Symbol:        order.Symbol,
 		Side:          order.Side,
 		IsBuyer:       order.Side == types.SideTypeBuy,
@@ -297,16 +457,16 @@ func (m *SimplePriceMatching) BuyToPrice(price fixedpoint.Value) (closedOrders [
 
 		case types.OrderTypeLimit:
 			if priceF >= o.Price {
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-
-				trade := m.newTradeFromOrder(o, true)
-				m.executeTrade(trade)
-
-				trades = append(trades, trade)
+				filled, trade := m.fillLimitOrder(o)
+				if trade != nil {
+					trades = append(trades, *trade)
+				}
 
-				m.EmitOrderUpdate(o)
+				if filled.Status == types.OrderStatusFilled {
+					closedOrders = append(closedOrders, filled)
+				} else {
+					askOrders = append(askOrders, filled)
+				}
 			} else {
 				askOrders = append(askOrders, o)
 			}
@@ -372,16 +532,16 @@ func (m *SimplePriceMatching) SellToPrice(price fixedpoint.Value) (closedOrders
 
 		case types.OrderTypeLimit:
 			if sellPrice <= o.Price {
-				o.ExecutedQuantity = o.Quantity
-				o.Status = types.OrderStatusFilled
-				closedOrders = append(closedOrders, o)
-
-				trade := m.newTradeFromOrder(o, true)
-				m.executeTrade(trade)
-
-				trades = append(trades, trade)
+				filled, trade := m.fillLimitOrder(o)
+				if trade != nil {
+					trades = append(trades, *trade)
+				}
 
-				m.EmitOrderUpdate(o)
+				if filled.Status == types.OrderStatusFilled {
+					closedOrders = append(closedOrders, filled)
+				} else {
+					bidOrders = append(bidOrders, filled)
+				}
 			} else {
 				bidOrders = append(bidOrders, o)
 			}
@@ -397,8 +557,11 @@ func (m *SimplePriceMatching) SellToPrice(price fixedpoint.Value) (closedOrders
 	return closedOrders, trades
 }
 
+// processKLine moves the price along the kline (open, high/low, low/high, close), the resting limit orders touched by
+// the high or the low are filled as the maker orders at their prices, within the fill capacity of the kline volume.
 func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 	m.CurrentTime = kline.EndTime
+	m.resetFillCapacity(kline)
 
 	switch kline.Direction() {
 	case types.DirectionDown:
@@ -406,7 +569,7 @@ func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 			m.BuyToPrice(fixedpoint.NewFromFloat(kline.High))
 		}
 
-		if kline.Low > kline.Close {
+		if kline.Low < kline.Close {
 			m.SellToPrice(fixedpoint.NewFromFloat(kline.Low))
 			m.BuyToPrice(fixedpoint.NewFromFloat(kline.Close))
 		} else {
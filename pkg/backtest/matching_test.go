@@ -101,3 +101,145 @@ func TestSimplePriceMatching_LimitOrder(t *testing.T) {
 	assert.Len(t, closedOrders, 4)
 	assert.Len(t, trades, 4)
 }
+
+func TestSimplePriceMatching_MarketOrderSlippage(t *testing.T) {
+	account := &types.Account{
+		MakerCommission: 15,
+		TakerCommission: 15,
+	}
+
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(100.0)},
+	})
+
+	engine := &SimplePriceMatching{
+		CurrentTime: time.Now(),
+		Account:     account,
+		Market: types.Market{
+			Symbol:        "BTCUSDT",
+			QuoteCurrency: "USDT",
+			BaseCurrency:  "BTC",
+		},
+		LastPrice: fixedpoint.NewFromFloat(10000.0),
+		Slippage:  fixedpoint.NewFromFloat(0.01),
+	}
+
+	order, trade, err := engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: 1.0,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 10100.0, order.Price, 0.0001)
+	assert.InDelta(t, 10100.0, trade.Price, 0.0001)
+
+	_, trade, err = engine.PlaceOrder(types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: 1.0,
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 9900.0, trade.Price, 0.0001)
+}
+
+func TestDrawdownRecorder(t *testing.T) {
+	var recorder DrawdownRecorder
+	for _, equity := range []float64{100.0, 120.0, 90.0, 110.0, 130.0, 117.0} {
+		recorder.Record(equity)
+	}
+
+	assert.Equal(t, 130.0, recorder.Peak)
+	assert.InDelta(t, 0.25, recorder.MaxDrawdown, 0.0001)
+	assert.Equal(t, 117.0, recorder.Last)
+}
+
+func newTestMatching(account *types.Account) *SimplePriceMatching {
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(100.0)},
+	})
+
+	return &SimplePriceMatching{
+		CurrentTime: time.Now(),
+		Account:     account,
+		Market: types.Market{
+			Symbol:        "BTCUSDT",
+			QuoteCurrency: "USDT",
+			BaseCurrency:  "BTC",
+			StepSize:      0.001,
+			MinQuantity:   0.001,
+		},
+		LastPrice:       fixedpoint.NewFromFloat(10000.0),
+		MakerCommission: 10,
+		TakerCommission: 20,
+	}
+}
+
+func TestSimplePriceMatching_TakerLimitOrder(t *testing.T) {
+	account := &types.Account{}
+	engine := newTestMatching(account)
+
+	// the post only order crossing the last price is rejected
+	postOnly := newLimitOrder("BTCUSDT", types.SideTypeBuy, 10100.0, 1.0)
+	postOnly.PostOnly = true
+	_, _, err := engine.PlaceOrder(postOnly)
+	assert.Error(t, err)
+
+	// the limit order crossing the last price is filled at the last price as a taker order
+	order, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 10100.0, 1.0))
+	if assert.NoError(t, err) && assert.NotNil(t, trade) {
+		assert.Equal(t, types.OrderStatusFilled, order.Status)
+		assert.False(t, trade.IsMaker)
+		assert.InDelta(t, 10000.0, trade.Price, 0.0001)
+		assert.InDelta(t, 0.002, trade.Fee, 1e-9)
+	}
+
+	balances := account.Balances()
+	assert.InDelta(t, 990000.0, balances["USDT"].Available.Float64(), 0.0001)
+	assert.InDelta(t, 0.0, balances["USDT"].Locked.Float64(), 0.0001)
+	assert.InDelta(t, 100.998, balances["BTC"].Available.Float64(), 1e-6)
+
+	// the resting limit order is filled as a maker order at the order price
+	_, trade, err = engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 9900.0, 1.0))
+	assert.NoError(t, err)
+	assert.Nil(t, trade)
+
+	_, trades := engine.SellToPrice(fixedpoint.NewFromFloat(9900.0))
+	if assert.Len(t, trades, 1) {
+		assert.True(t, trades[0].IsMaker)
+		assert.InDelta(t, 9900.0, trades[0].Price, 0.0001)
+		assert.InDelta(t, 0.001, trades[0].Fee, 1e-9)
+	}
+}
+
+func TestSimplePriceMatching_PartialFill(t *testing.T) {
+	account := &types.Account{}
+	engine := newTestMatching(account)
+	engine.FillVolumeRatio = fixedpoint.NewFromFloat(0.1)
+
+	var trades []types.Trade
+	engine.OnTradeUpdate(func(trade types.Trade) { trades = append(trades, trade) })
+
+	_, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 9850.0, 1.5))
+	assert.NoError(t, err)
+
+	kline := types.KLine{Symbol: "BTCUSDT", Open: 10000.0, High: 10000.0, Low: 9800.0, Close: 9900.0, Volume: 10.0}
+
+	// the fill is capped at 10% of the kline volume
+	engine.processKLine(kline)
+	if assert.Len(t, trades, 1) && assert.Len(t, engine.bidOrders, 1) {
+		assert.InDelta(t, 1.0, trades[0].Quantity, 1e-9)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, engine.bidOrders[0].Status)
+		assert.InDelta(t, 1.0, engine.bidOrders[0].ExecutedQuantity, 1e-9)
+	}
+
+	engine.processKLine(kline)
+	if assert.Len(t, trades, 2) {
+		assert.InDelta(t, 0.5, trades[1].Quantity, 1e-9)
+	}
+	assert.Len(t, engine.bidOrders, 0)
+	assert.InDelta(t, 0.0, account.Balances()["USDT"].Locked.Float64(), 0.0001)
+}
@@ -0,0 +1,91 @@
+package backtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// SymbolReport is the backtest result of a symbol, the equity is the account value in the quote currency of the market
+type SymbolReport struct {
+	Symbol string `json:"symbol"`
+
+	NumTrades        int     `json:"numTrades"`
+	Profit           float64 `json:"profit"`
+	UnrealizedProfit float64 `json:"unrealizedProfit"`
+
+	InitialEquity float64 `json:"initialEquity"`
+	FinalEquity   float64 `json:"finalEquity"`
+	PeakEquity    float64 `json:"peakEquity"`
+
+	// MaxDrawdown is the max drawdown ratio from the peak equity
+	MaxDrawdown float64 `json:"maxDrawdown"`
+
+	// Sharpe is the annualized Sharpe ratio of the daily equity returns
+	Sharpe float64 `json:"sharpe"`
+}
+
+// SummaryReport is the machine-readable result of a backtest run, it's written by the backtest command with --report
+type SummaryReport struct {
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	Symbols []SymbolReport `json:"symbols"`
+}
+
+// TotalProfit returns the sum of the realized and the unrealized profit of the symbols
+func (r SummaryReport) TotalProfit() (profit float64) {
+	for _, s := range r.Symbols {
+		profit += s.Profit + s.UnrealizedProfit
+	}
+
+	return profit
+}
+
+// MaxDrawdown returns the largest max drawdown of the symbols
+func (r SummaryReport) MaxDrawdown() (drawdown float64) {
+	for _, s := range r.Symbols {
+		if s.MaxDrawdown > drawdown {
+			drawdown = s.MaxDrawdown
+		}
+	}
+
+	return drawdown
+}
+
+// Sharpe returns the average Sharpe ratio of the symbols
+func (r SummaryReport) Sharpe() float64 {
+	if len(r.Symbols) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range r.Symbols {
+		sum += s.Sharpe
+	}
+
+	return sum / float64(len(r.Symbols))
+}
+
+func (r SummaryReport) WriteFile(filename string) error {
+	out, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, out, 0644)
+}
+
+func ReadSummaryReport(filename string) (*SummaryReport, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var report SummaryReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const DefaultAccountSnapshotTimeout = 10 * time.Second
+
+// SessionAccountSnapshot is the balances and the open orders of a session queried from the exchange
+type SessionAccountSnapshot struct {
+	Session  string `json:"session"`
+	Exchange string `json:"exchange"`
+
+	Balances   types.BalanceMap `json:"balances"`
+	OpenOrders []types.Order    `json:"openOrders"`
+
+	// Latency is the time spent on querying the session, it is encoded in nanoseconds
+	Latency time.Duration `json:"latency"`
+
+	// Error is set when the query failed or timed out, the balances and the open orders may be incomplete
+	Error string `json:"error,omitempty"`
+}
+
+// AccountSnapshot is the balances and the open orders of the sessions queried concurrently at the same time
+type AccountSnapshot struct {
+	// Time is the time when the queries are sent
+	Time time.Time `json:"time"`
+
+	// Partial is true when any of the sessions failed, the failed sessions are flagged with the error
+	Partial bool `json:"partial"`
+
+	Sessions []SessionAccountSnapshot `json:"sessions"`
+}
+
+// QueryAccountSnapshot queries the balances and the open orders of the sessions concurrently within the timeout,
+// the open orders are queried for the given symbols, or the subscribed symbols of each session if no symbol is given.
+// The public only sessions are skipped.
+func QueryAccountSnapshot(ctx context.Context, sessions map[string]*ExchangeSession, symbols []string, timeout time.Duration) AccountSnapshot {
+	if timeout == 0 {
+		timeout = DefaultAccountSnapshotTimeout
+	}
+
+	snapshot := AccountSnapshot{
+		Time:     time.Now(),
+		Sessions: []SessionAccountSnapshot{},
+	}
+
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var pending = make(map[string]*ExchangeSession)
+	var results = make(chan SessionAccountSnapshot, len(sessions))
+	for name, session := range sessions {
+		if session.PublicOnly {
+			continue
+		}
+
+		pending[name] = session
+
+		go func(name string, session *ExchangeSession) {
+			results <- querySessionAccountSnapshot(queryCtx, name, session, symbols)
+		}(name, session)
+	}
+
+collect:
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.Session)
+			snapshot.Sessions = append(snapshot.Sessions, result)
+
+		case <-queryCtx.Done():
+			// the exchange clients that don't respect the context are left behind
+			break collect
+		}
+	}
+
+	for name, session := range pending {
+		snapshot.Sessions = append(snapshot.Sessions, SessionAccountSnapshot{
+			Session:  name,
+			Exchange: session.ExchangeName,
+			Latency:  time.Since(snapshot.Time),
+			Error:    fmt.Sprintf("query timeout after %s", timeout),
+		})
+	}
+
+	sort.Slice(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].Session < snapshot.Sessions[j].Session
+	})
+
+	for _, s := range snapshot.Sessions {
+		if len(s.Error) > 0 {
+			snapshot.Partial = true
+		}
+	}
+
+	return snapshot
+}
+
+func querySessionAccountSnapshot(ctx context.Context, name string, session *ExchangeSession, symbols []string) SessionAccountSnapshot {
+	startTime := time.Now()
+	result := SessionAccountSnapshot{
+		Session:    name,
+		Exchange:   session.ExchangeName,
+		OpenOrders: []types.Order{},
+	}
+
+	var errs []string
+
+	balances, err := session.Exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("balance query error: %v", err))
+	} else {
+		result.Balances = balances
+	}
+
+	if len(symbols) == 0 {
+		for symbol := range session.usedSymbols {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+	}
+
+	for _, symbol := range symbols {
+		if _, ok := session.Market(symbol); !ok && len(session.Markets()) > 0 {
+			continue
+		}
+
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s open order query error: %v", symbol, err))
+			continue
+		}
+
+		result.OpenOrders = append(result.OpenOrders, openOrders...)
+	}
+
+	if len(errs) > 0 {
+		result.Error = strings.Join(errs, "; ")
+	} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Error = "query timeout"
+	}
+
+	result.Latency = time.Since(startTime)
+	return result
+}
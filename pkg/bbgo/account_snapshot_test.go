@@ -0,0 +1,77 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type snapshotTestExchange struct {
+	types.Exchange
+
+	balances   types.BalanceMap
+	openOrders map[string][]types.Order
+	err        error
+	delay      time.Duration
+}
+
+func (e *snapshotTestExchange) NewStream() types.Stream {
+	return &recoveryTestStream{}
+}
+
+func (e *snapshotTestExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if e.delay > 0 {
+		time.Sleep(e.delay)
+	}
+
+	return e.balances, e.err
+}
+
+func (e *snapshotTestExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	return e.openOrders[symbol], nil
+}
+
+func TestQueryAccountSnapshot(t *testing.T) {
+	order := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 1}
+
+	healthy := NewExchangeSession("binance", &snapshotTestExchange{
+		balances:   types.BalanceMap{"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)}},
+		openOrders: map[string][]types.Order{"BTCUSDT": {order}},
+	})
+	healthy.usedSymbols["BTCUSDT"] = struct{}{}
+
+	failed := NewExchangeSession("max", &snapshotTestExchange{err: errors.New("connection refused")})
+
+	// the exchange client that doesn't respect the context
+	slow := NewExchangeSession("kraken", &snapshotTestExchange{delay: time.Second})
+
+	public := NewExchangeSession("public", &snapshotTestExchange{})
+	public.PublicOnly = true
+
+	snapshot := QueryAccountSnapshot(context.Background(), map[string]*ExchangeSession{
+		"binance": healthy,
+		"max":     failed,
+		"kraken":  slow,
+		"public":  public,
+	}, nil, 100*time.Millisecond)
+
+	assert.True(t, snapshot.Partial)
+	if assert.Len(t, snapshot.Sessions, 3) {
+		assert.Equal(t, "binance", snapshot.Sessions[0].Session)
+		assert.Empty(t, snapshot.Sessions[0].Error)
+		assert.Equal(t, 1.0, snapshot.Sessions[0].Balances["BTC"].Available.Float64())
+		assert.Equal(t, []types.Order{order}, snapshot.Sessions[0].OpenOrders)
+
+		assert.Equal(t, "kraken", snapshot.Sessions[1].Session)
+		assert.Contains(t, snapshot.Sessions[1].Error, "timeout")
+
+		assert.Equal(t, "max", snapshot.Sessions[2].Session)
+		assert.Contains(t, snapshot.Sessions[2].Error, "connection refused")
+	}
+}
@@ -0,0 +1,179 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AccountValueConfig sends the net value reports of the sessions through the notifiers by the cron specs of When, e.g., "@hourly"
+type AccountValueConfig struct {
+	// QuoteCurrency is the currency of the account value, defaults to USDT
+	QuoteCurrency string `json:"quoteCurrency,omitempty" yaml:"quoteCurrency,omitempty"`
+
+	// Sessions are the sessions to report, all the authenticated sessions are reported if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	When datatype.StringSlice `json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+// AccountValueCalculator converts all the balances of the session to the quote currency,
+// the assets are valued with the last prices of the session, and the prices of the assets without
+// the last price are queried from the latest klines of the exchange.
+type AccountValueCalculator struct {
+	Session       *ExchangeSession
+	QuoteCurrency string
+}
+
+func NewAccountValueCalculator(session *ExchangeSession, quoteCurrency string) *AccountValueCalculator {
+	return &AccountValueCalculator{
+		Session:       session,
+		QuoteCurrency: quoteCurrency,
+	}
+}
+
+// priceSymbols returns the market symbols that can price the currency in the quote currency
+func (c *AccountValueCalculator) priceSymbols(currency string) []string {
+	var symbols []string
+	for _, symbol := range []string{currency + c.QuoteCurrency, c.QuoteCurrency + currency, currency + "USDT", "USDT" + currency, c.QuoteCurrency + "USDT", "USDT" + c.QuoteCurrency} {
+		if _, ok := c.Session.Market(symbol); ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+// prices returns the last prices of the session, and queries the missing prices of the balance currencies
+func (c *AccountValueCalculator) prices(ctx context.Context, balances types.BalanceMap) map[string]float64 {
+	prices := make(map[string]float64)
+	for symbol, price := range c.Session.LastPrices() {
+		prices[symbol] = price
+	}
+
+	for currency := range balances {
+		if _, ok := types.QuotePrice(currency, c.QuoteCurrency, prices); ok {
+			continue
+		}
+
+		for _, symbol := range c.priceSymbols(currency) {
+			if _, ok := prices[symbol]; ok {
+				continue
+			}
+
+			price, err := c.queryPrice(ctx, symbol)
+			if err != nil {
+				log.WithError(err).Warnf("can not query the price of %s", symbol)
+				continue
+			}
+
+			prices[symbol] = price
+		}
+	}
+
+	return prices
+}
+
+func (c *AccountValueCalculator) queryPrice(ctx context.Context, symbol string) (float64, error) {
+	now := time.Now()
+	kLines, err := c.Session.Exchange.QueryKLines(ctx, symbol, types.Interval1m, types.KLineQueryOptions{
+		Limit:   1,
+		EndTime: &now,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(kLines) == 0 {
+		return 0, fmt.Errorf("no kline data for the price of %s", symbol)
+	}
+
+	return kLines[len(kLines)-1].Close, nil
+}
+
+// Calculate returns the net value of the session account in the quote currency with the per-asset breakdown,
+// the assets that can not be priced are listed in the unpriced currencies.
+func (c *AccountValueCalculator) Calculate(ctx context.Context) types.AccountValue {
+	balances := c.Session.Account.Balances()
+	value := balances.Value(c.QuoteCurrency, c.prices(ctx, balances))
+	if len(value.Unpriced) > 0 {
+		c.Session.logger.Warnf("assets %v can not be priced in %s, they are not counted in the account value", value.Unpriced, c.QuoteCurrency)
+	}
+
+	return value
+}
+
+// NetValue returns the net value of the session account in the quote currency
+func (c *AccountValueCalculator) NetValue(ctx context.Context) float64 {
+	return c.Calculate(ctx).NetValue
+}
+
+// reportAccountValues sends the account value of the sessions through the notifiers
+func (environ *Environment) reportAccountValues(conf *AccountValueConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	quoteCurrency := conf.QuoteCurrency
+	if len(quoteCurrency) == 0 {
+		quoteCurrency = "USDT"
+	}
+
+	for _, session := range environ.accountSessions("account value report", conf.Sessions) {
+		value := NewAccountValueCalculator(session, quoteCurrency).Calculate(ctx)
+		environ.Notify(":moneybag: %s %s", session.Name, value.PlainText(), value)
+	}
+}
+
+// accountSessions returns the initialized private sessions of the names, all of them are returned if names is empty
+func (environ *Environment) accountSessions(purpose string, names []string) []*ExchangeSession {
+	var sessions []*ExchangeSession
+	if len(names) > 0 {
+		for _, name := range names {
+			session, ok := environ.sessions[name]
+			if !ok {
+				log.Errorf("%s: session %s not found", purpose, name)
+				continue
+			}
+
+			sessions = append(sessions, session)
+		}
+	} else {
+		for _, session := range environ.sessions {
+			sessions = append(sessions, session)
+		}
+	}
+
+	var accountSessions []*ExchangeSession
+	for _, session := range sessions {
+		if session.PublicOnly || !session.IsInitialized {
+			continue
+		}
+
+		accountSessions = append(accountSessions, session)
+	}
+
+	return accountSessions
+}
+
+// scheduleAccountValueReport starts the cron jobs of the account value report
+func (environ *Environment) scheduleAccountValueReport(conf *AccountValueConfig) error {
+	if len(conf.When) == 0 {
+		return nil
+	}
+
+	c := cron.New()
+	for _, spec := range conf.When {
+		if _, err := c.AddFunc(spec, func() { environ.reportAccountValues(conf) }); err != nil {
+			return fmt.Errorf("invalid account value report spec %q: %w", spec, err)
+		}
+	}
+
+	c.Start()
+	return nil
+}
@@ -1,24 +1,42 @@
 package bbgo
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+const defaultCancelTimeout = 30 * time.Second
+
 // LocalActiveOrderBook manages the local active order books.
 //go:generate callbackgen -type LocalActiveOrderBook
 type LocalActiveOrderBook struct {
 	Bids *types.SyncOrderMap
 	Asks *types.SyncOrderMap
 
-	filledCallbacks []func(o types.Order)
+	// CancelTimeout is the duration to wait for the canceled order update before sending the cancel request again
+	CancelTimeout time.Duration
+
+	mu sync.Mutex
+
+	// pendingCancel keeps the orders that we sent the cancel request but haven't received the canceled order update,
+	// the value is the time of the last cancel request.
+	pendingCancel map[uint64]time.Time
+
+	filledCallbacks   []func(o types.Order)
+	canceledCallbacks []func(o types.Order)
 }
 
 func NewLocalActiveOrderBook() *LocalActiveOrderBook {
 	return &LocalActiveOrderBook{
-		Bids: types.NewSyncOrderMap(),
-		Asks: types.NewSyncOrderMap(),
+		Bids:          types.NewSyncOrderMap(),
+		Asks:          types.NewSyncOrderMap(),
+		CancelTimeout: defaultCancelTimeout,
+		pendingCancel: make(map[uint64]time.Time),
 	}
 }
 
@@ -39,12 +57,103 @@ func (b *LocalActiveOrderBook) orderUpdateHandler(order types.Order) {
 	case types.OrderStatusPartiallyFilled, types.OrderStatusNew:
 		b.Update(order)
 
-	case types.OrderStatusCanceled, types.OrderStatusRejected:
+	case types.OrderStatusCanceled:
+		log.Debugf("[LocalActiveOrderBook] order status %s, removing %d...", order.Status, order.OrderID)
+		if b.Remove(order) {
+			b.EmitCanceled(order)
+		}
+
+	case types.OrderStatusRejected:
 		log.Debugf("[LocalActiveOrderBook] order status %s, removing %d...", order.Status, order.OrderID)
 		b.Remove(order)
 	}
 }
 
+// Cancel sends the cancel request of the orders and marks them as pending cancel,
+// the orders are kept in the book until the canceled order updates are received.
+func (b *LocalActiveOrderBook) Cancel(ctx context.Context, ex types.Exchange, orders ...types.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	b.mu.Lock()
+	for _, o := range orders {
+		b.pendingCancel[o.OrderID] = now
+	}
+	b.mu.Unlock()
+
+	return types.ExchangeBatchProcessor{Exchange: ex}.BatchCancelOrders(ctx, orders...)
+}
+
+// IsPendingCancel returns true if the cancel request of the order is sent but the order is still in the book
+func (b *LocalActiveOrderBook) IsPendingCancel(order types.Order) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, ok := b.pendingCancel[order.OrderID]
+	return ok
+}
+
+// PendingCancelOrders returns the orders that are waiting for the canceled order updates
+func (b *LocalActiveOrderBook) PendingCancelOrders() (orders types.OrderSlice) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, o := range b.Orders() {
+		if _, ok := b.pendingCancel[o.OrderID]; ok {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders
+}
+
+// ReCancel sends the cancel request again for the pending cancel orders that are not canceled within the cancel timeout.
+func (b *LocalActiveOrderBook) ReCancel(ctx context.Context, ex types.Exchange) error {
+	now := time.Now()
+
+	var orders []types.Order
+	b.mu.Lock()
+	for _, o := range b.Orders() {
+		if t, ok := b.pendingCancel[o.OrderID]; ok && now.Sub(t) >= b.CancelTimeout {
+			b.pendingCancel[o.OrderID] = now
+			orders = append(orders, o)
+		}
+	}
+	b.mu.Unlock()
+
+	if len(orders) == 0 {
+		return nil
+	}
+
+	log.Warnf("[LocalActiveOrderBook] %d orders are not canceled in %s, canceling again...", len(orders), b.CancelTimeout)
+	return types.ExchangeBatchProcessor{Exchange: ex}.BatchCancelOrders(ctx, orders...)
+}
+
+// RunReCancelWorker checks the pending cancel orders periodically and re-cancels the timed out orders until the context is done.
+func (b *LocalActiveOrderBook) RunReCancelWorker(ctx context.Context, ex types.Exchange) {
+	interval := b.CancelTimeout / 2
+	if interval <= 0 {
+		interval = defaultCancelTimeout / 2
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := b.ReCancel(ctx, ex); err != nil {
+				log.WithError(err).Error("[LocalActiveOrderBook] re-cancel error")
+			}
+		}
+	}
+}
+
 func (b *LocalActiveOrderBook) Print() {
 	for _, o := range b.Bids.Orders() {
 		log.Infof("bid order: %d -> %s", o.OrderID, o.Status)
@@ -90,6 +199,10 @@ func (b *LocalActiveOrderBook) NumOfAsks() int {
 }
 
 func (b *LocalActiveOrderBook) Remove(order types.Order) bool {
+	b.mu.Lock()
+	delete(b.pendingCancel, order.OrderID)
+	b.mu.Unlock()
+
 	switch order.Side {
 	case types.SideTypeBuy:
 		return b.Bids.Remove(order.OrderID)
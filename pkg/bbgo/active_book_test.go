@@ -0,0 +1,77 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type cancelTestExchange struct {
+	types.Exchange
+
+	canceled []types.Order
+}
+
+func (e *cancelTestExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.canceled = append(e.canceled, orders...)
+	return nil
+}
+
+func TestLocalActiveOrderBook_Callbacks(t *testing.T) {
+	book := NewLocalActiveOrderBook()
+
+	var filled, canceled []types.Order
+	book.OnFilled(func(o types.Order) { filled = append(filled, o) })
+	book.OnCanceled(func(o types.Order) { canceled = append(canceled, o) })
+
+	bid := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 1, Status: types.OrderStatusNew}
+	ask := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell}, OrderID: 2, Status: types.OrderStatusNew}
+	book.Add(bid, ask)
+
+	filledBid := bid
+	filledBid.Status = types.OrderStatusFilled
+	book.orderUpdateHandler(filledBid)
+
+	canceledAsk := ask
+	canceledAsk.Status = types.OrderStatusCanceled
+	book.orderUpdateHandler(canceledAsk)
+
+	// the order update of the removed order does not trigger the callbacks again
+	book.orderUpdateHandler(canceledAsk)
+
+	assert.Equal(t, []types.Order{filledBid}, filled)
+	assert.Equal(t, []types.Order{canceledAsk}, canceled)
+	assert.Empty(t, book.Orders())
+}
+
+func TestLocalActiveOrderBook_PendingCancel(t *testing.T) {
+	ctx := context.Background()
+	exchange := &cancelTestExchange{}
+
+	book := NewLocalActiveOrderBook()
+	book.CancelTimeout = time.Millisecond
+
+	bid := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy}, OrderID: 1, Status: types.OrderStatusNew}
+	ask := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell}, OrderID: 2, Status: types.OrderStatusNew}
+	book.Add(bid, ask)
+
+	assert.NoError(t, book.Cancel(ctx, exchange, bid, ask))
+	assert.True(t, book.IsPendingCancel(bid))
+	assert.Len(t, book.PendingCancelOrders(), 2)
+
+	// the bid is canceled, the ask is not
+	canceledBid := bid
+	canceledBid.Status = types.OrderStatusCanceled
+	book.orderUpdateHandler(canceledBid)
+	assert.False(t, book.IsPendingCancel(bid))
+
+	time.Sleep(5 * time.Millisecond)
+	exchange.canceled = nil
+	assert.NoError(t, book.ReCancel(ctx, exchange))
+	assert.Equal(t, []types.Order{ask}, exchange.canceled)
+	assert.True(t, book.IsPendingCancel(ask))
+}
@@ -0,0 +1,70 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AmendOrder moves the resting limit order to the new price and quantity, the quantity is the new unfilled quantity,
+// the zero price or quantity keeps the current one. The order is amended in place if the session supports the amendOrder
+// capability, so that the order is never off the book. Otherwise the order is canceled and replaced: the replacement is
+// submitted through the order executor only after the cancellation succeeds, so that the order is never doubled when it's
+// filled before the cancellation. The amended order or the replacement order is returned.
+func (session *ExchangeSession) AmendOrder(ctx context.Context, orderExecutor OrderExecutor, order types.Order, price, quantity float64) (*types.Order, error) {
+	if order.Type != types.OrderTypeLimit {
+		return nil, fmt.Errorf("can not amend the %s order %d, only the limit orders can be amended", order.Type, order.OrderID)
+	}
+
+	if price <= 0 {
+		price = order.Price
+	}
+
+	if quantity <= 0 {
+		quantity = order.Quantity - order.ExecutedQuantity
+	}
+
+	market, ok := session.Market(order.Symbol)
+	if ok {
+		price = market.TruncatePrice(price)
+		quantity = market.TruncateQuantity(quantity)
+	}
+
+	if session.Capabilities().Has(types.CapabilityAmendOrder) {
+		if amendExchange, ok := session.Exchange.(types.AmendOrderExchange); ok {
+			amended, err := amendExchange.AmendOrder(ctx, order, price, quantity)
+			session.audit(service.AuditActionAmendOrder, map[string]interface{}{
+				"orderID":  order.OrderID,
+				"price":    price,
+				"quantity": quantity,
+			}, amended, err)
+			return amended, err
+		}
+	}
+
+	if err := session.Exchange.CancelOrders(ctx, order); err != nil {
+		return nil, fmt.Errorf("can not cancel order %d for replacing: %w", order.OrderID, err)
+	}
+
+	submitOrder := order.SubmitOrder
+	submitOrder.Market = market
+	submitOrder.ClientOrderID = ""
+	submitOrder.Price = price
+	submitOrder.PriceString = ""
+	submitOrder.Quantity = quantity
+	submitOrder.QuantityString = ""
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, submitOrder)
+	if err != nil {
+		return nil, fmt.Errorf("order %d is canceled but the replacement is not submitted: %w", order.OrderID, err)
+	}
+
+	if len(createdOrders) == 0 {
+		return nil, fmt.Errorf("order %d is canceled but the replacement is not created", order.OrderID)
+	}
+
+	session.logger.Infof("order %d is replaced by order %d at %f", order.OrderID, createdOrders[0].OrderID, price)
+	return &createdOrders[0], nil
+}
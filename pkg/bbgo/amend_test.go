@@ -0,0 +1,76 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type amendOrderExchange struct {
+	*mock.Exchange
+
+	amended []types.Order
+}
+
+func (e *amendOrderExchange) AmendOrder(ctx context.Context, order types.Order, price, quantity float64) (*types.Order, error) {
+	order.Price = price
+	order.Quantity = order.ExecutedQuantity + quantity
+	e.amended = append(e.amended, order)
+	return &order, nil
+}
+
+func TestExchangeSession_AmendOrder(t *testing.T) {
+	ctx := context.Background()
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	createdOrders, err := exchange.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.1, Price: 9000.0, Market: markets["BTCUSDT"],
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 1) {
+		return
+	}
+
+	// the exchange without the native amendment: the order is canceled and replaced
+	session := &ExchangeSession{Name: "binance", Exchange: exchange, markets: markets, logger: log.WithField("session", "binance")}
+	replaced, err := session.AmendOrder(ctx, &directOrderExecutor{exchange: exchange}, createdOrders[0], 9100.005, 0)
+	if assert.NoError(t, err) {
+		assert.NotEqual(t, createdOrders[0].OrderID, replaced.OrderID)
+		assert.Equal(t, 9100.0, replaced.Price)
+		assert.Equal(t, 0.1, replaced.Quantity)
+
+		openOrders := exchange.OpenOrders("BTCUSDT")
+		if assert.Len(t, openOrders, 1) {
+			assert.Equal(t, replaced.OrderID, openOrders[0].OrderID)
+		}
+	}
+
+	// the canceled order can not be replaced, so no replacement is submitted
+	_, err = session.AmendOrder(ctx, &directOrderExecutor{exchange: exchange}, createdOrders[0], 9200.0, 0)
+	assert.Error(t, err)
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 1)
+
+	// the exchange with the native amendment: the order is amended in place
+	amendExchange := &amendOrderExchange{Exchange: exchange}
+	session.Exchange = amendExchange
+	amended, err := session.AmendOrder(ctx, &directOrderExecutor{exchange: exchange}, *replaced, 9300.0, 0.2)
+	if assert.NoError(t, err) {
+		assert.Equal(t, replaced.OrderID, amended.OrderID)
+		assert.Equal(t, 9300.0, amended.Price)
+		assert.Len(t, amendExchange.amended, 1)
+	}
+
+	_, err = session.AmendOrder(ctx, &directOrderExecutor{exchange: exchange}, types.Order{SubmitOrder: types.SubmitOrder{Type: types.OrderTypeMarket}}, 9300.0, 0.2)
+	assert.Error(t, err)
+}
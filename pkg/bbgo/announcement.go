@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultAnnouncementInterval is the default polling interval of the exchange announcement feeds
+const DefaultAnnouncementInterval = 5 * time.Minute
+
+// AnnouncementMonitor polls the exchange announcement feed, notifies the new announcements,
+// and adds the currencies of the delisting announcements to the session blacklist.
+type AnnouncementMonitor struct {
+	Session  *ExchangeSession
+	Provider types.AnnouncementProvider
+	Interval time.Duration
+
+	seen map[string]struct{}
+}
+
+func NewAnnouncementMonitor(session *ExchangeSession, provider types.AnnouncementProvider) *AnnouncementMonitor {
+	interval := DefaultAnnouncementInterval
+	if session.AnnouncementInterval > 0 {
+		interval = session.AnnouncementInterval.Duration()
+	}
+
+	return &AnnouncementMonitor{
+		Session:  session,
+		Provider: provider,
+		Interval: interval,
+		seen:     make(map[string]struct{}),
+	}
+}
+
+func (m *AnnouncementMonitor) Run(ctx context.Context) {
+	// only the announcements released after the start are reported
+	since := time.Now()
+
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			announcements, err := m.Provider.QueryAnnouncements(ctx, since)
+			if err != nil {
+				m.Session.logger.WithError(err).Warn("can not query the exchange announcements")
+				continue
+			}
+
+			for _, announcement := range announcements {
+				if _, ok := m.seen[announcement.ID]; ok {
+					continue
+				}
+
+				m.seen[announcement.ID] = struct{}{}
+				m.handle(announcement)
+			}
+		}
+	}
+}
+
+func (m *AnnouncementMonitor) handle(announcement types.Announcement) {
+	m.Session.logger.Infof("exchange announcement: %s", announcement.String())
+
+	if announcement.Type == types.AnnouncementTypeDelisting {
+		for _, currency := range announcement.Currencies {
+			m.Session.logger.Warnf("adding %s to the blacklist: %s", currency, announcement.Title)
+			m.Session.Blacklist.Add(currency, announcement.Title)
+		}
+	}
+
+	// pass the announcement as an interface object, so that it can be routed by the object router
+	if channel, ok := m.Session.RouteObject(&announcement); ok {
+		m.Session.NotifyTo(channel, ":loudspeaker: %s", announcement.PlainText(), &announcement)
+	} else {
+		m.Session.Notify(":loudspeaker: %s", announcement.PlainText(), &announcement)
+	}
+}
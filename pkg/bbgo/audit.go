@@ -0,0 +1,98 @@
+package bbgo
+
+import (
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Auditor records the authenticated API calls of a session that mutate the account state into the audit log table.
+// The calls are recorded by the order executors and the session methods instead of wrapping the exchange,
+// so that the optional interfaces implemented by the exchange are kept as they are.
+type Auditor struct {
+	SessionName  string
+	Exchange     types.ExchangeName
+	AuditService *service.AuditService
+}
+
+func NewAuditor(sessionName string, exchange types.ExchangeName, auditService *service.AuditService) *Auditor {
+	return &Auditor{
+		SessionName:  sessionName,
+		Exchange:     exchange,
+		AuditService: auditService,
+	}
+}
+
+// BindStream records the canceled orders from the order updates,
+// since the orders are canceled by the strategies with the exchange directly.
+func (a *Auditor) BindStream(stream types.Stream) {
+	stream.OnOrderUpdate(func(order types.Order) {
+		if order.Status == types.OrderStatusCanceled {
+			a.Record(service.AuditActionOrderCanceled, nil, order, nil)
+		}
+	})
+}
+
+func (a *Auditor) Record(action string, params, result interface{}, err error) {
+	auditLog := service.AuditLog{
+		Exchange: a.Exchange,
+		Session:  a.SessionName,
+		Action:   action,
+		Params:   encodeAuditData(params),
+		Result:   encodeAuditData(result),
+		Time:     time.Now(),
+	}
+
+	if err != nil {
+		auditLog.Error = err.Error()
+	}
+
+	if err := a.AuditService.Insert(auditLog); err != nil {
+		log.WithError(err).Errorf("can not insert audit log: %+v", auditLog)
+	}
+}
+
+// audit records the API call in the audit log, it does nothing if the audit log is not enabled
+func (session *ExchangeSession) audit(action string, params, result interface{}, err error) {
+	if session.auditor != nil {
+		session.auditor.Record(action, params, result, err)
+	}
+}
+
+// auditSubmitOrderResults records the submitted orders of the results
+func (session *ExchangeSession) auditSubmitOrderResults(results []SubmitOrderResult) {
+	if session.auditor == nil {
+		return
+	}
+
+	for _, r := range results {
+		if r.Error != nil {
+			session.auditor.Record(service.AuditActionSubmitOrders, r.SubmitOrder, nil, r.Error)
+		} else {
+			session.auditor.Record(service.AuditActionSubmitOrders, r.SubmitOrder, r.Order, nil)
+		}
+	}
+}
+
+func encodeAuditData(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+
+	out, err := json.Marshal(data)
+	if err != nil {
+		log.WithError(err).Errorf("can not encode audit data")
+		return ""
+	}
+
+	// the nil pointer result of the failed call
+	if string(out) == "null" {
+		return ""
+	}
+
+	return string(out)
+}
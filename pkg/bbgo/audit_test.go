@@ -0,0 +1,71 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_ConfigureService_audit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-audit")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	environ := NewEnvironment()
+	if !assert.NoError(t, environ.ConfigureDatabaseDriver(context.Background(), service.DriverSQLite3, filepath.Join(dir, "bbgo.sqlite3"))) {
+		return
+	}
+
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	session := NewExchangeSession("binance", exchange)
+	session.markets = markets
+	environ.AddExchangeSession("binance", session)
+
+	if !assert.NoError(t, environ.ConfigureService(&ServiceConfig{Audit: &AuditConfig{Enabled: true}})) {
+		return
+	}
+
+	// the exchange is not wrapped, so the optional interfaces of the exchange are kept
+	assert.Equal(t, types.Exchange(exchange), session.Exchange)
+
+	since := time.Now().Add(-time.Minute)
+	executor := &ExchangeOrderExecutor{Session: session}
+	createdOrders, err := executor.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: 0.01,
+		Price:    9000.0,
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 1) {
+		return
+	}
+
+	logs, err := environ.AuditService.Query(since, time.Now().Add(time.Minute))
+	if assert.NoError(t, err) && assert.Len(t, logs, 1) {
+		assert.Equal(t, service.AuditActionSubmitOrders, logs[0].Action)
+		assert.Equal(t, "binance", logs[0].Session)
+		assert.Equal(t, types.ExchangeBinance, logs[0].Exchange)
+		assert.Contains(t, logs[0].Params, `"symbol":"BTCUSDT"`)
+		assert.Empty(t, logs[0].Error)
+	}
+}
@@ -0,0 +1,62 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+)
+
+// BalanceSnapshotConfig records the net value and the balances of the sessions into the database by the cron specs of When,
+// e.g., "@hourly", the recorded net values are served as the equity curve of the session.
+type BalanceSnapshotConfig struct {
+	// QuoteCurrency is the currency of the net value, defaults to USDT
+	QuoteCurrency string `json:"quoteCurrency,omitempty" yaml:"quoteCurrency,omitempty"`
+
+	// Sessions are the sessions to record, all the authenticated sessions are recorded if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	When datatype.StringSlice `json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+// recordBalanceSnapshots saves the account value of the sessions into the balance snapshot tables
+func (environ *Environment) recordBalanceSnapshots(conf *BalanceSnapshotConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	quoteCurrency := conf.QuoteCurrency
+	if len(quoteCurrency) == 0 {
+		quoteCurrency = "USDT"
+	}
+
+	for _, session := range environ.accountSessions("balance snapshot", conf.Sessions) {
+		value := NewAccountValueCalculator(session, quoteCurrency).Calculate(ctx)
+		if err := environ.BalanceSnapshotService.Insert(session.Name, session.Exchange.Name(), value); err != nil {
+			session.logger.WithError(err).Error("can not record the balance snapshot")
+		}
+	}
+}
+
+// scheduleBalanceSnapshots starts the cron jobs of the balance snapshot recorder
+func (environ *Environment) scheduleBalanceSnapshots(conf *BalanceSnapshotConfig) error {
+	if len(conf.When) == 0 {
+		return nil
+	}
+
+	if environ.BalanceSnapshotService == nil {
+		return fmt.Errorf("service.balanceSnapshot requires the database, please set MYSQL_URL")
+	}
+
+	c := cron.New()
+	for _, spec := range conf.When {
+		if _, err := c.AddFunc(spec, func() { environ.recordBalanceSnapshots(conf) }); err != nil {
+			return fmt.Errorf("invalid balance snapshot spec %q: %w", spec, err)
+		}
+	}
+
+	c.Start()
+	return nil
+}
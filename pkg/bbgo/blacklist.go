@@ -0,0 +1,54 @@
+package bbgo
+
+import (
+	"sync"
+)
+
+// SymbolBlacklist keeps the currencies that should not be bought, e.g., the currencies that are going to be delisted.
+type SymbolBlacklist struct {
+	mu         sync.Mutex
+	currencies map[string]string
+}
+
+func NewSymbolBlacklist() *SymbolBlacklist {
+	return &SymbolBlacklist{
+		currencies: make(map[string]string),
+	}
+}
+
+// Add adds the currency to the blacklist with the reason
+func (b *SymbolBlacklist) Add(currency, reason string) {
+	b.mu.Lock()
+	b.currencies[currency] = reason
+	b.mu.Unlock()
+}
+
+func (b *SymbolBlacklist) Remove(currency string) {
+	b.mu.Lock()
+	delete(b.currencies, currency)
+	b.mu.Unlock()
+}
+
+// Reason returns the reason if the currency is blacklisted, nothing is blacklisted in the nil blacklist
+func (b *SymbolBlacklist) Reason(currency string) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reason, ok := b.currencies[currency]
+	return reason, ok
+}
+
+// IsSymbolBlacklisted checks the base currency of the market,
+// the quote currency is not checked since the other markets of the same quote currency are still tradable.
+func (session *ExchangeSession) IsSymbolBlacklisted(symbol string) (string, bool) {
+	market, ok := session.Market(symbol)
+	if !ok {
+		return "", false
+	}
+
+	return session.Blacklist.Reason(market.BaseCurrency)
+}
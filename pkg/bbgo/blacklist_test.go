@@ -0,0 +1,50 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSymbolBlacklist(t *testing.T) {
+	blacklist := NewSymbolBlacklist()
+	blacklist.Add("LUNA", "delisting")
+
+	reason, ok := blacklist.Reason("LUNA")
+	assert.True(t, ok)
+	assert.Equal(t, "delisting", reason)
+
+	blacklist.Remove("LUNA")
+	_, ok = blacklist.Reason("LUNA")
+	assert.False(t, ok)
+
+	var nilBlacklist *SymbolBlacklist
+	_, ok = nilBlacklist.Reason("LUNA")
+	assert.False(t, ok)
+}
+
+func TestExchangeSession_IsSymbolBlacklisted(t *testing.T) {
+	session := &ExchangeSession{
+		markets: map[string]types.Market{
+			"LUNAUSDT": {Symbol: "LUNAUSDT", BaseCurrency: "LUNA", QuoteCurrency: "USDT"},
+		},
+	}
+
+	// the session is not created by NewExchangeSession, so it has no blacklist
+	_, ok := session.IsSymbolBlacklisted("LUNAUSDT")
+	assert.False(t, ok)
+
+	session.Blacklist = NewSymbolBlacklist()
+	session.Blacklist.Add("LUNA", "delisting")
+
+	reason, ok := session.IsSymbolBlacklisted("LUNAUSDT")
+	assert.True(t, ok)
+	assert.Equal(t, "delisting", reason)
+
+	// the quote currency is still tradable
+	session.Blacklist.Add("USDT", "delisting")
+	_, ok = session.IsSymbolBlacklisted("BTCUSDT")
+	assert.False(t, ok)
+}
@@ -0,0 +1,134 @@
+package bbgo
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CapabilityRequirer is implemented by the single exchange strategies that require the exchange capabilities
+type CapabilityRequirer interface {
+	RequiredCapabilities() []types.Capability
+}
+
+// CrossCapabilityRequirer is implemented by the cross exchange strategies that require the exchange capabilities,
+// the returned map is keyed by the session name.
+type CrossCapabilityRequirer interface {
+	CrossRequiredCapabilities() map[string][]types.Capability
+}
+
+// Capabilities returns the capabilities available in this session.
+// The exchange capabilities are filtered by the session config,
+// e.g., the margin capability is only available when the session is configured as a margin session.
+func (session *ExchangeSession) Capabilities() types.CapabilitySet {
+	set := types.NewCapabilitySet()
+	for c := range types.ExchangeCapabilities(session.Exchange) {
+		// the public only session has no account, only the futures market data is available
+		if session.PublicOnly && c != types.CapabilityFutures {
+			continue
+		}
+
+		switch c {
+		case types.CapabilityMargin:
+			if !session.Margin {
+				continue
+			}
+
+		case types.CapabilityMarginBorrowRepay:
+			// the borrowing of the isolated margin account is not supported by the exchange adapters
+			if !session.Margin || session.IsolatedMargin {
+				continue
+			}
+
+		case types.CapabilityMultiAssetCollateral:
+			// the isolated margin account only uses the assets of the isolated pair as the collateral
+			if !session.Margin || session.IsolatedMargin {
+				continue
+			}
+
+		case types.CapabilityIsolatedMargin:
+			if !session.IsolatedMargin {
+				continue
+			}
+
+		case types.CapabilityOCO:
+			// the native oco orders are only supported in the spot account by the exchange adapters
+			if session.Margin || session.Futures {
+				continue
+			}
+		}
+
+		set[c] = struct{}{}
+	}
+
+	return set
+}
+
+// checkOrderCapabilities checks the order features against the session capabilities, so that the unsupported orders
+// are rejected locally instead of being placed as a different order, e.g., a post only order taking the liquidity.
+func checkOrderCapabilities(set types.CapabilitySet, order types.SubmitOrder) error {
+	if order.PostOnly && !set.Has(types.CapabilityPostOnly) {
+		return fmt.Errorf("post only %s order is not supported by the session: %w", order.Symbol, ErrUnsupportedOrder)
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if !set.Has(types.CapabilityStopOrder) {
+			return fmt.Errorf("%s %s order is not supported by the session: %w", order.Symbol, order.Type, ErrUnsupportedOrder)
+		}
+	}
+
+	return nil
+}
+
+func verifySessionCapabilities(strategyID string, session *ExchangeSession, required []types.Capability) error {
+	set := session.Capabilities()
+	if missing := set.Missing(required...); len(missing) > 0 {
+		return fmt.Errorf("strategy %s requires capabilities %v which are not available in session %s (exchange %s, available: [%s])",
+			strategyID, missing, session.Name, session.ExchangeName, set.String())
+	}
+
+	return nil
+}
+
+// verifyCapabilities checks the required capabilities of the strategies against the sessions,
+// so that we can fail fast before the strategies start running.
+func (trader *Trader) verifyCapabilities() error {
+	for sessionName, strategies := range trader.exchangeStrategies {
+		session, ok := trader.environment.sessions[sessionName]
+		if !ok {
+			return fmt.Errorf("session %s is not defined", sessionName)
+		}
+
+		for _, strategy := range strategies {
+			requirer, ok := strategy.(CapabilityRequirer)
+			if !ok {
+				continue
+			}
+
+			if err := verifySessionCapabilities(strategy.ID(), session, requirer.RequiredCapabilities()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		requirer, ok := strategy.(CrossCapabilityRequirer)
+		if !ok {
+			continue
+		}
+
+		for sessionName, required := range requirer.CrossRequiredCapabilities() {
+			session, ok := trader.environment.sessions[sessionName]
+			if !ok {
+				return fmt.Errorf("strategy %s requires session %s which is not defined", strategy.ID(), sessionName)
+			}
+
+			if err := verifySessionCapabilities(strategy.ID(), session, required); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package bbgo
+
+import (
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// CapitalAllocator shares the budget of each currency among the consumers (e.g., the grids of the symbols) by their weights
+type CapitalAllocator struct {
+	mu sync.Mutex
+
+	// budgets maps the currency to the total budget
+	budgets map[string]fixedpoint.Value
+
+	// weights maps the currency to the weights of the consumers
+	weights map[string]map[string]float64
+}
+
+func NewCapitalAllocator() *CapitalAllocator {
+	return &CapitalAllocator{
+		budgets: make(map[string]fixedpoint.Value),
+		weights: make(map[string]map[string]float64),
+	}
+}
+
+// SetBudget sets the total budget of the currency
+func (a *CapitalAllocator) SetBudget(currency string, budget fixedpoint.Value) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.budgets[currency] = budget
+}
+
+// Budget returns the total budget of the currency
+func (a *CapitalAllocator) Budget(currency string) (fixedpoint.Value, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	budget, ok := a.budgets[currency]
+	return budget, ok
+}
+
+// Register adds the consumer of the currency budget with the weight, the non-positive weight is treated as 1
+func (a *CapitalAllocator) Register(currency, key string, weight float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if weight <= 0 {
+		weight = 1.0
+	}
+
+	weights, ok := a.weights[currency]
+	if !ok {
+		weights = make(map[string]float64)
+		a.weights[currency] = weights
+	}
+
+	weights[key] = weight
+}
+
+// Allocation returns the share of the currency budget allocated to the consumer,
+// it returns zero if the consumer or the budget is not registered.
+func (a *CapitalAllocator) Allocation(currency, key string) fixedpoint.Value {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	budget, ok := a.budgets[currency]
+	if !ok {
+		return 0
+	}
+
+	weights := a.weights[currency]
+	weight, ok := weights[key]
+	if !ok {
+		return 0
+	}
+
+	var totalWeight float64
+	for _, w := range weights {
+		totalWeight += w
+	}
+
+	return budget.MulFloat64(weight / totalWeight)
+}
@@ -0,0 +1,26 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestCapitalAllocator(t *testing.T) {
+	allocator := NewCapitalAllocator()
+	allocator.SetBudget("USDT", fixedpoint.NewFromFloat(3000.0))
+	allocator.Register("USDT", "BTCUSDT", 2.0)
+	allocator.Register("USDT", "ETHUSDT", 0)
+	allocator.Register("TWD", "BTCTWD", 1.0)
+
+	assert.Equal(t, 2000.0, allocator.Allocation("USDT", "BTCUSDT").Float64())
+	assert.Equal(t, 1000.0, allocator.Allocation("USDT", "ETHUSDT").Float64())
+
+	// not registered
+	assert.Equal(t, fixedpoint.Value(0), allocator.Allocation("USDT", "MAXUSDT"))
+
+	// the budget is not set
+	assert.Equal(t, fixedpoint.Value(0), allocator.Allocation("TWD", "BTCTWD"))
+}
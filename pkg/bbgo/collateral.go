@@ -0,0 +1,128 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultCollateralUpdateInterval = time.Minute
+
+func (session *ExchangeSession) collateralUpdateInterval() time.Duration {
+	if session.CollateralUpdateInterval > 0 {
+		return session.CollateralUpdateInterval.Duration()
+	}
+
+	return defaultCollateralUpdateInterval
+}
+
+// RefreshCollateral queries the collateral balances and the index prices of the collateral assets from the exchange.
+// For the sessions with the multi-asset collateral capability, all the assets of the margin account are valued with the index prices,
+// for the other margin sessions, the margin balances are queried since the balance updates of the stream don't carry the borrowed amount.
+func (session *ExchangeSession) RefreshCollateral(ctx context.Context) error {
+	if collateralExchange, ok := session.Exchange.(types.CollateralExchange); ok && session.Capabilities().Has(types.CapabilityMultiAssetCollateral) {
+		balances, err := collateralExchange.QueryCollateralBalances(ctx)
+		if err != nil {
+			return err
+		}
+
+		prices, err := collateralExchange.QueryIndexPrices(ctx, types.CollateralIndexSymbols(balances)...)
+		if err != nil {
+			return err
+		}
+
+		session.collateralMutex.Lock()
+		session.collateralBalances = balances
+		session.collateralPrices = prices
+		session.collateralMutex.Unlock()
+		return nil
+	}
+
+	if balanceService, ok := session.Exchange.(types.MarginBalanceService); ok && session.Margin {
+		balances, err := balanceService.QueryMarginBalances(ctx)
+		if err != nil {
+			return err
+		}
+
+		session.collateralMutex.Lock()
+		session.collateralBalances = balances
+		session.collateralMutex.Unlock()
+	}
+
+	return nil
+}
+
+// UpdateCollateral refreshes the collateral balances and the index prices from the exchange,
+// and returns the collateral valuation.
+func (session *ExchangeSession) UpdateCollateral(ctx context.Context, ratios types.CollateralRatios) (*types.Collateral, error) {
+	if err := session.RefreshCollateral(ctx); err != nil {
+		return nil, err
+	}
+
+	collateral, err := session.Collateral(ratios)
+	if err != nil {
+		return nil, err
+	}
+
+	return &collateral, nil
+}
+
+// Collateral returns the collateral valuation of the session account across all the assets with the last refreshed balances.
+// The assets are valued with the index prices if they are queried, otherwise the last prices of the session are used.
+// An error is returned if any of the assets can not be priced, since the collateral value would be overestimated or the debt would be missed.
+func (session *ExchangeSession) Collateral(ratios types.CollateralRatios) (types.Collateral, error) {
+	session.collateralMutex.RLock()
+	balances := session.collateralBalances
+	prices := session.collateralPrices
+	session.collateralMutex.RUnlock()
+
+	if balances == nil {
+		balances = session.Account.Balances()
+	}
+
+	if prices == nil {
+		prices = session.lastPrices
+	}
+
+	collateral := balances.Collateral(prices, ratios)
+	if len(collateral.Unpriced) > 0 {
+		return collateral, fmt.Errorf("the prices of the collateral assets %v are not found", collateral.Unpriced)
+	}
+
+	return collateral, nil
+}
+
+// CollateralPrices returns the index prices of the collateral assets, the last prices are returned if the index prices are not queried.
+func (session *ExchangeSession) CollateralPrices() map[string]float64 {
+	session.collateralMutex.RLock()
+	defer session.collateralMutex.RUnlock()
+
+	if session.collateralPrices != nil {
+		return session.collateralPrices
+	}
+
+	return session.lastPrices
+}
+
+func (session *ExchangeSession) updateCollateral(ctx context.Context, interval time.Duration) {
+	if err := session.RefreshCollateral(ctx); err != nil {
+		session.logger.WithError(err).Error("can not update the collateral")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := session.RefreshCollateral(ctx); err != nil {
+				session.logger.WithError(err).Error("can not update the collateral")
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"time"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/datatype"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -55,6 +57,19 @@ func (m *ExchangeStrategyMount) Map() (map[string]interface{}, error) {
 type SlackNotification struct {
 	DefaultChannel string `json:"defaultChannel,omitempty"  yaml:"defaultChannel,omitempty"`
 	ErrorChannel   string `json:"errorChannel,omitempty"  yaml:"errorChannel,omitempty"`
+
+	// Confirmation enables the interactive order confirmation, the strategies embedding OrderConfirmation
+	// send the large orders to the channel and wait for the Approve or Reject answer.
+	Confirmation *SlackConfirmation `json:"confirmation,omitempty" yaml:"confirmation,omitempty"`
+}
+
+// SlackConfirmation is the config of the interactive order confirmation,
+// the slack app signing secret is loaded from the SLACK_SIGNING_SECRET env var.
+type SlackConfirmation struct {
+	Channel string `json:"channel" yaml:"channel"`
+
+	// Listen is the address of the interactivity endpoint, the request URL of the slack app should point to /slack/interaction
+	Listen string `json:"listen" yaml:"listen"`
 }
 
 type NotificationRouting struct {
@@ -71,6 +86,12 @@ type NotificationConfig struct {
 	SessionChannels map[string]string `json:"sessionChannels,omitempty" yaml:"sessionChannels,omitempty"`
 
 	Routing *NotificationRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+
+	// Queue buffers the notifications to the disk when the notifiers are unreachable and replays them when they recover
+	Queue *NotificationQueueConfig `json:"queue,omitempty" yaml:"queue,omitempty"`
+
+	// Format customizes the currency amount formatting and the localized messages of the notifications
+	Format *NotificationFormatConfig `json:"format,omitempty" yaml:"format,omitempty"`
 }
 
 type Session struct {
@@ -93,6 +114,19 @@ type Backtest struct {
 
 	Account BacktestAccount `json:"account" yaml:"account"`
 	Symbols []string        `json:"symbols" yaml:"symbols"`
+
+	// Slippage is the price slippage ratio applied to the market orders, e.g., 0.001 means 0.1%
+	Slippage fixedpoint.Value `json:"slippage,omitempty" yaml:"slippage,omitempty"`
+
+	// FillVolumeRatio caps the quantity filled by the resting limit orders in a 1m kline to the ratio of the kline volume,
+	// e.g., 0.1 means at most 10% of the volume, the orders beyond the cap are partially filled. Zero fills the touched orders fully.
+	FillVolumeRatio fixedpoint.Value `json:"fillVolumeRatio,omitempty" yaml:"fillVolumeRatio,omitempty"`
+
+	// RandomSeed seeds the randomized components, so that the runs with the same seed are reproducible
+	RandomSeed int64 `json:"randomSeed,omitempty" yaml:"randomSeed,omitempty"`
+
+	// KLineAggregation builds the klines of these intervals from the 1m klines, so only the 1m klines need to be synced
+	KLineAggregation []types.Interval `json:"klineAggregation,omitempty" yaml:"klineAggregation,omitempty"`
 }
 
 func (t Backtest) ParseEndTime() (time.Time, error) {
@@ -149,6 +183,82 @@ type PersistenceConfig struct {
 	Json  *JsonPersistenceConfig  `json:"json,omitempty" yaml:"json,omitempty"`
 }
 
+type InfluxDBConfig struct {
+	URL    string `json:"url,omitempty" yaml:"url,omitempty" env:"INFLUXDB_URL"`
+	Org    string `json:"org,omitempty" yaml:"org,omitempty" env:"INFLUXDB_ORG"`
+	Bucket string `json:"bucket,omitempty" yaml:"bucket,omitempty" env:"INFLUXDB_BUCKET"`
+	Token  string `json:"token,omitempty" yaml:"token,omitempty" env:"INFLUXDB_TOKEN"`
+}
+
+// DatabaseConfig selects the database of the services and the SQL persistence, the driver is "mysql" (default) or "sqlite3".
+// The DSN of sqlite3 is the path of the database file, e.g., bbgo.sqlite3, so no database server is needed.
+type DatabaseConfig struct {
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+	DSN    string `json:"dsn,omitempty" yaml:"dsn,omitempty"`
+}
+
+func (c *DatabaseConfig) Validate() error {
+	switch c.Driver {
+	case "", service.DriverMySQL, service.DriverSQLite3:
+	default:
+		return fmt.Errorf("unsupported database driver: %s, supported drivers are mysql and sqlite3", c.Driver)
+	}
+
+	if len(c.DSN) == 0 {
+		return fmt.Errorf("database dsn is required")
+	}
+
+	return nil
+}
+
+// AuditConfig enables the audit log of the authenticated API calls that mutate the account state,
+// the audit log is stored in the database, so the database must be configured.
+type AuditConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+// ExecutionReportConfig enables the per-strategy execution report that is sent through the notifiers when the strategies stop,
+// the reports are saved into the persistence store if the persistence is given.
+type ExecutionReportConfig struct {
+	Enabled     bool                 `json:"enabled" yaml:"enabled"`
+	Persistence *PersistenceSelector `json:"persistence,omitempty" yaml:"persistence,omitempty"`
+}
+
+// MetricsConfig enables the Prometheus metrics endpoint, the metrics are served on http://{bind}/metrics
+type MetricsConfig struct {
+	Bind string `json:"bind,omitempty" yaml:"bind,omitempty"`
+}
+
+type ServiceConfig struct {
+	InfluxDB        *InfluxDBConfig        `json:"influxDB,omitempty" yaml:"influxDB,omitempty"`
+	Audit           *AuditConfig           `json:"audit,omitempty" yaml:"audit,omitempty"`
+	ExecutionReport *ExecutionReportConfig `json:"executionReport,omitempty" yaml:"executionReport,omitempty"`
+	PortfolioRisk   *PortfolioRiskConfig   `json:"portfolioRisk,omitempty" yaml:"portfolioRisk,omitempty"`
+	AccountValue    *AccountValueConfig    `json:"accountValue,omitempty" yaml:"accountValue,omitempty"`
+	BalanceSnapshot *BalanceSnapshotConfig `json:"balanceSnapshot,omitempty" yaml:"balanceSnapshot,omitempty"`
+	Recorder        *RecorderConfig        `json:"recorder,omitempty" yaml:"recorder,omitempty"`
+	Metrics         *MetricsConfig         `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// SyncConfig is the config of the sync command, the klines of the symbols are synchronized into the database
+// for the back-test and the long-window indicators, and the trades and the orders are synchronized for the reports.
+type SyncConfig struct {
+	// Sessions are the sessions to sync, all the sessions are synchronized if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Since is the start date of the sync, e.g., 2021-01-01
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+
+	// KLineIntervals are the kline intervals to sync, all the supported intervals are synchronized if it's empty
+	KLineIntervals []types.Interval `json:"klineIntervals,omitempty" yaml:"klineIntervals,omitempty"`
+
+	// TradeSyncInterval pulls the trades and the orders of the symbols into the database periodically
+	// while the strategies are running, it's disabled if it's zero
+	TradeSyncInterval types.Duration `json:"tradeSyncInterval,omitempty" yaml:"tradeSyncInterval,omitempty"`
+}
+
 type BuildTargetConfig struct {
 	Name    string               `json:"name" yaml:"name"`
 	Arch    string               `json:"arch" yaml:"arch"`
@@ -179,19 +289,39 @@ type Config struct {
 	// Deprecated: use BuildConfig instead
 	Imports []string `json:"imports,omitempty" yaml:"imports,omitempty"`
 
+	// Plugins are the go plugin (.so) files of the strategies, they are loaded before the strategies of the config,
+	// the relative paths are resolved from the directory of the config file.
+	Plugins []string `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+
 	Backtest *Backtest `json:"backtest,omitempty" yaml:"backtest,omitempty"`
 
 	Notifications *NotificationConfig `json:"notifications,omitempty" yaml:"notifications,omitempty"`
 
 	Persistence *PersistenceConfig `json:"persistence,omitempty" yaml:"persistence,omitempty"`
 
+	// Database selects the database driver and the DSN, MYSQL_URL is used if it's not set
+	Database *DatabaseConfig `json:"database,omitempty" yaml:"database,omitempty"`
+
+	Service *ServiceConfig `json:"service,omitempty" yaml:"service,omitempty"`
+
 	Sessions map[string]*ExchangeSession `json:"sessions,omitempty" yaml:"sessions,omitempty"`
 
+	Sync *SyncConfig `json:"sync,omitempty" yaml:"sync,omitempty"`
+
 	RiskControls *RiskControls `json:"riskControls,omitempty" yaml:"riskControls,omitempty"`
 
+	// Logging routes the logs of the strategies to the separate files or log levels
+	Logging *LoggingConfig `json:"logging,omitempty" yaml:"logging,omitempty"`
+
+	// CurrencyEquivalence groups the currencies counted 1:1 by the canonical currency, e.g., USDT: [BUSD, USDC]
+	CurrencyEquivalence types.CurrencyEquivalence `json:"currencyEquivalence,omitempty" yaml:"currencyEquivalence,omitempty"`
+
 	ExchangeStrategies      []ExchangeStrategyMount `json:"-" yaml:"-"`
 	CrossExchangeStrategies []CrossExchangeStrategy `json:"-" yaml:"-"`
 
+	// StrategyLifecycles are loaded from the name, the dependsOn and the onSuspend keys of the strategy config entries
+	StrategyLifecycles []StrategyLifecycle `json:"-" yaml:"-"`
+
 	PnLReporters []PnLReporterConfig `json:"reportPnL,omitempty" yaml:"reportPnL,omitempty"`
 }
 
@@ -215,6 +345,7 @@ func (c *Config) Map() (map[string]interface{}, error) {
 			return nil, err
 		}
 
+		c.mapStrategyLifecycle(m.Strategy, params)
 		exchangeStrategies = append(exchangeStrategies, params)
 	}
 
@@ -237,9 +368,12 @@ func (c *Config) Map() (map[string]interface{}, error) {
 			return nil, err
 		}
 
-		crossExchangeStrategies = append(crossExchangeStrategies, map[string]interface{}{
+		entry := map[string]interface{}{
 			strategyID: params,
-		})
+		}
+
+		c.mapStrategyLifecycle(st, entry)
+		crossExchangeStrategies = append(crossExchangeStrategies, entry)
 	}
 
 	if len(crossExchangeStrategies) > 0 {
@@ -333,6 +467,10 @@ func Load(configFile string, loadStrategies bool) (*Config, error) {
 	}
 
 	if loadStrategies {
+		if err := LoadPlugins(filepath.Dir(configFile), config.Plugins); err != nil {
+			return nil, err
+		}
+
 		if err := loadExchangeStrategies(&config, stash); err != nil {
 			return nil, err
 		}
@@ -345,6 +483,96 @@ func Load(configFile string, loadStrategies bool) (*Config, error) {
 	return &config, nil
 }
 
+// mapStrategyLifecycle converts the lifecycle config of the strategy back to the keys of the strategy config entry
+func (c *Config) mapStrategyLifecycle(strategy interface{}, entry map[string]interface{}) {
+	for _, lifecycle := range c.StrategyLifecycles {
+		if lifecycle.Strategy != strategy {
+			continue
+		}
+
+		if len(lifecycle.Name) > 0 {
+			entry["name"] = lifecycle.Name
+		}
+
+		if len(lifecycle.DependsOn) > 0 {
+			entry["dependsOn"] = lifecycle.DependsOn
+		}
+
+		if len(lifecycle.OnSuspend) > 0 {
+			entry["onSuspend"] = lifecycle.OnSuspend
+		}
+	}
+}
+
+// loadStrategyLifecycle loads the name, the dependsOn and the onSuspend keys of the strategy config entry
+func loadStrategyLifecycle(config *Config, configStash Stash, strategy interface{}) error {
+	var lifecycle = StrategyLifecycle{Strategy: strategy}
+	if val, ok := configStash["name"]; ok {
+		name, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("strategy name %+v (%T) is not a string", val, val)
+		}
+
+		lifecycle.Name = name
+	}
+
+	if val, ok := configStash["dependsOn"]; ok {
+		dependsOn, err := stringList(val)
+		if err != nil {
+			return errors.Wrap(err, "unexpected dependsOn type")
+		}
+
+		lifecycle.DependsOn = dependsOn
+	}
+
+	if val, ok := configStash["onSuspend"]; ok {
+		policy, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("strategy onSuspend %+v (%T) is not a string", val, val)
+		}
+
+		lifecycle.OnSuspend = SuspendOrderPolicy(policy)
+		if err := lifecycle.OnSuspend.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(lifecycle.Name) == 0 && len(lifecycle.DependsOn) == 0 && len(lifecycle.OnSuspend) == 0 {
+		return nil
+	}
+
+	config.StrategyLifecycles = append(config.StrategyLifecycles, lifecycle)
+	return nil
+}
+
+// stringList converts the string or the string list config value into a string slice
+func stringList(val interface{}) ([]string, error) {
+	switch tv := val.(type) {
+
+	case []string:
+		return tv, nil
+
+	case string:
+		return []string{tv}, nil
+
+	case []interface{}:
+		var list []string
+		for _, f := range tv {
+			s, ok := f.(string)
+			if !ok {
+				return nil, fmt.Errorf("%+v (%T) is not a string", f, f)
+			}
+
+			list = append(list, s)
+		}
+
+		return list, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected type: %T value: %+v", val, val)
+	}
+}
+
 func loadCrossExchangeStrategies(config *Config, stash Stash) (err error) {
 	exchangeStrategiesConf, ok := stash["crossExchangeStrategies"]
 	if !ok {
@@ -375,6 +603,10 @@ func loadCrossExchangeStrategies(config *Config, stash Stash) (err error) {
 				}
 
 				config.CrossExchangeStrategies = append(config.CrossExchangeStrategies, val.(CrossExchangeStrategy))
+
+				if err := loadStrategyLifecycle(config, configStash, val); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -417,27 +649,12 @@ func loadExchangeStrategies(config *Config, stash Stash) (err error) {
 
 		var mounts []string
 		if val, ok := configStash["on"]; ok {
-			switch tv := val.(type) {
-
-			case []string:
-				mounts = append(mounts, tv...)
-
-			case string:
-				mounts = append(mounts, tv)
-
-			case []interface{}:
-				for _, f := range tv {
-					s, ok := f.(string)
-					if !ok {
-						return fmt.Errorf("%+v (%T) is not a string", f, f)
-					}
-
-					mounts = append(mounts, s)
-				}
-
-			default:
-				return fmt.Errorf("unexpected mount type: %T value: %+v", val, val)
+			list, err := stringList(val)
+			if err != nil {
+				return errors.Wrap(err, "unexpected mount type")
 			}
+
+			mounts = append(mounts, list...)
 		}
 
 		for id, conf := range configStash {
@@ -453,6 +670,10 @@ func loadExchangeStrategies(config *Config, stash Stash) (err error) {
 					Mounts:   mounts,
 					Strategy: st,
 				})
+
+				if err := loadStrategyLifecycle(config, configStash, st); err != nil {
+					return err
+				}
 			}
 		}
 	}
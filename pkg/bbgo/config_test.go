@@ -137,6 +137,34 @@ func TestLoadConfig(t *testing.T) {
 			},
 		},
 
+		{
+			name:    "lifecycle",
+			args:    args{configFile: "testdata/lifecycle.yaml"},
+			wantErr: false,
+			f: func(t *testing.T, config *Config) {
+				assert.Len(t, config.ExchangeStrategies, 2)
+				if assert.Len(t, config.StrategyLifecycles, 2) {
+					assert.Equal(t, "hedger", config.StrategyLifecycles[0].Name)
+					assert.Empty(t, config.StrategyLifecycles[0].DependsOn)
+					assert.Equal(t, config.ExchangeStrategies[0].Strategy, config.StrategyLifecycles[0].Strategy)
+
+					assert.Equal(t, "grid", config.StrategyLifecycles[1].Name)
+					assert.Equal(t, []string{"hedger"}, config.StrategyLifecycles[1].DependsOn)
+					assert.Equal(t, SuspendOrderPolicyCancelAndRestore, config.StrategyLifecycles[1].OnSuspend)
+				}
+
+				m, err := config.Map()
+				assert.NoError(t, err)
+
+				exchangeStrategies, ok := m["exchangeStrategies"].([]map[string]interface{})
+				if assert.True(t, ok) && assert.Len(t, exchangeStrategies, 2) {
+					assert.Equal(t, "grid", exchangeStrategies[1]["name"])
+					assert.Equal(t, []string{"hedger"}, exchangeStrategies[1]["dependsOn"])
+					assert.Equal(t, SuspendOrderPolicyCancelAndRestore, exchangeStrategies[1]["onSuspend"])
+				}
+			},
+		},
+
 		{
 			name:    "persistence",
 			args:    args{configFile: "testdata/persistence.yaml"},
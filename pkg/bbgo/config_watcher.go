@@ -0,0 +1,293 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultConfigWatchInterval = 5 * time.Second
+
+// StrategyConfigReloader is implemented by the strategies that can apply the config changes without a restart,
+// the config is the JSON object of the changed fields only, with the same keys as the YAML config.
+// The fields omitted from the new config are not included and should be kept as they are.
+type StrategyConfigReloader interface {
+	ReloadConfig(ctx context.Context, config []byte) error
+}
+
+// StrategyInstanceIDProvider is implemented by the strategies that can run multiple instances in the same config,
+// e.g., the instances of different symbols. The config watcher matches the instances by their instance IDs.
+type StrategyInstanceIDProvider interface {
+	InstanceID() string
+}
+
+// strategyKey identifies a strategy instance in the config, the instances with the same strategy ID and instance ID
+// are numbered by their order in the config.
+type strategyKey struct {
+	id         string
+	instanceID string
+	n          int
+}
+
+func strategyKeys(strategies []interface{}) []strategyKey {
+	var keys []strategyKey
+	var counts = make(map[strategyKey]int)
+	for _, strategy := range strategies {
+		key := strategyKey{id: strategyID(strategy)}
+		if provider, ok := strategy.(StrategyInstanceIDProvider); ok {
+			key.instanceID = provider.InstanceID()
+		}
+
+		n := counts[key]
+		counts[key] = n + 1
+
+		key.n = n
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func indexStrategies(strategies []interface{}) map[strategyKey]int {
+	var index = make(map[strategyKey]int)
+	for i, key := range strategyKeys(strategies) {
+		index[key] = i
+	}
+
+	return index
+}
+
+func exchangeStrategyList(mounts []ExchangeStrategyMount) (strategies []interface{}) {
+	for _, mount := range mounts {
+		strategies = append(strategies, mount.Strategy)
+	}
+
+	return strategies
+}
+
+func crossExchangeStrategyList(crossStrategies []CrossExchangeStrategy) (strategies []interface{}) {
+	for _, strategy := range crossStrategies {
+		strategies = append(strategies, strategy)
+	}
+
+	return strategies
+}
+
+// ConfigWatcher watches the config file and applies the changed strategy configs to the running strategies,
+// the strategies are matched by their strategy IDs and instance IDs, so adding, removing or re-mounting
+// the strategies requires a restart.
+type ConfigWatcher struct {
+	File     string
+	Interval time.Duration
+
+	// Config is the running config, its strategy instances are the running strategies
+	Config *Config
+
+	Notifiability *Notifiability
+
+	// baseline is the last applied config, the strategy configs are compared with it to detect the changes
+	baseline *Config
+	modTime  time.Time
+}
+
+func NewConfigWatcher(file string, config *Config, notifiability *Notifiability) *ConfigWatcher {
+	return &ConfigWatcher{
+		File:          file,
+		Interval:      defaultConfigWatchInterval,
+		Config:        config,
+		Notifiability: notifiability,
+	}
+}
+
+// Run loads the current config file as the baseline, and then polls the modification time of the config file
+func (w *ConfigWatcher) Run(ctx context.Context) error {
+	stat, err := os.Stat(w.File)
+	if err != nil {
+		return err
+	}
+
+	baseline, err := Load(w.File, true)
+	if err != nil {
+		return err
+	}
+
+	w.baseline = baseline
+	w.modTime = stat.ModTime()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	log.Infof("watching config file %s", w.File)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			stat, err := os.Stat(w.File)
+			if err != nil {
+				log.WithError(err).Errorf("can not stat config file %s", w.File)
+				continue
+			}
+
+			if stat.ModTime().Equal(w.modTime) {
+				continue
+			}
+
+			w.modTime = stat.ModTime()
+			if err := w.Reload(ctx); err != nil {
+				log.WithError(err).Errorf("config reload error")
+			}
+		}
+	}
+}
+
+// Reload loads the config file and applies the changed strategy configs
+func (w *ConfigWatcher) Reload(ctx context.Context) error {
+	newConfig, err := Load(w.File, true)
+	if err != nil {
+		return err
+	}
+
+	running := indexStrategies(exchangeStrategyList(w.Config.ExchangeStrategies))
+	baselines := indexStrategies(exchangeStrategyList(w.baseline.ExchangeStrategies))
+	newKeys := strategyKeys(exchangeStrategyList(newConfig.ExchangeStrategies))
+	w.notifyRemoved(baselines, newKeys)
+
+	for i, entry := range newConfig.ExchangeStrategies {
+		ri, ok := running[newKeys[i]]
+		bi, ok2 := baselines[newKeys[i]]
+		if !ok || !ok2 {
+			w.notify(":warning: new strategy %s is added to the config, please restart bbgo to run it", entry.Strategy.ID())
+			continue
+		}
+
+		baseline := w.baseline.ExchangeStrategies[bi]
+		if !reflect.DeepEqual(baseline.Mounts, entry.Mounts) {
+			w.notify(":warning: strategy %s is re-mounted in the config, please restart bbgo to apply it", entry.Strategy.ID())
+			continue
+		}
+
+		if w.reloadStrategy(ctx, w.Config.ExchangeStrategies[ri].Strategy, baseline.Strategy, entry.Strategy) {
+			w.baseline.ExchangeStrategies[bi] = entry
+		}
+	}
+
+	running = indexStrategies(crossExchangeStrategyList(w.Config.CrossExchangeStrategies))
+	baselines = indexStrategies(crossExchangeStrategyList(w.baseline.CrossExchangeStrategies))
+	newKeys = strategyKeys(crossExchangeStrategyList(newConfig.CrossExchangeStrategies))
+	w.notifyRemoved(baselines, newKeys)
+
+	for i, strategy := range newConfig.CrossExchangeStrategies {
+		ri, ok := running[newKeys[i]]
+		bi, ok2 := baselines[newKeys[i]]
+		if !ok || !ok2 {
+			w.notify(":warning: new strategy %s is added to the config, please restart bbgo to run it", strategy.ID())
+			continue
+		}
+
+		if w.reloadStrategy(ctx, w.Config.CrossExchangeStrategies[ri], w.baseline.CrossExchangeStrategies[bi], strategy) {
+			w.baseline.CrossExchangeStrategies[bi] = strategy
+		}
+	}
+
+	return nil
+}
+
+// notifyRemoved notifies the strategies removed from the config, they keep running until bbgo is restarted
+func (w *ConfigWatcher) notifyRemoved(baselines map[strategyKey]int, newKeys []strategyKey) {
+	var keys = make(map[strategyKey]struct{})
+	for _, key := range newKeys {
+		keys[key] = struct{}{}
+	}
+
+	for key := range baselines {
+		if _, ok := keys[key]; !ok {
+			w.notify(":warning: strategy %s is removed from the config, please restart bbgo to stop it", key.id)
+		}
+	}
+}
+
+// changedFields returns the JSON object of the fields of the new strategy config which are different from the baseline,
+// it returns nil if nothing is changed.
+func changedFields(baseline, newStrategy interface{}) ([]byte, error) {
+	baselineFields, err := strategyFields(baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	newFields, err := strategyFields(newStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed = make(map[string]json.RawMessage)
+	for key, val := range newFields {
+		if old, ok := baselineFields[key]; !ok || !bytes.Equal(old, val) {
+			changed[key] = val
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(changed)
+}
+
+func strategyFields(strategy interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// reloadStrategy applies the new config to the running strategy if the config is changed, it returns true if the config is applied
+func (w *ConfigWatcher) reloadStrategy(ctx context.Context, running, baseline, newStrategy interface{}) bool {
+	newConfig, err := changedFields(baseline, newStrategy)
+	if err != nil {
+		log.WithError(err).Errorf("can not encode strategy config")
+		return false
+	}
+
+	if newConfig == nil {
+		return false
+	}
+
+	id := strategyID(running)
+
+	reloader, ok := running.(StrategyConfigReloader)
+	if !ok {
+		w.notify(":warning: the config of strategy %s is changed, but it does not support reloading, please restart bbgo to apply it", id)
+		return false
+	}
+
+	if err := reloader.ReloadConfig(ctx, newConfig); err != nil {
+		w.notify(":warning: strategy %s config reload error: %v", id, err)
+		return false
+	}
+
+	w.notify(":arrows_counterclockwise: strategy %s config is reloaded", id)
+	return true
+}
+
+func (w *ConfigWatcher) notify(format string, args ...interface{}) {
+	log.Infof(format, args...)
+
+	if w.Notifiability != nil {
+		w.Notifiability.Notify(format, args...)
+	}
+}
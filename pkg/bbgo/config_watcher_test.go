@@ -0,0 +1,166 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterStrategy("reload-test", &ReloadTestStrategy{})
+}
+
+type ReloadTestStrategy struct {
+	Symbol       string  `json:"symbol"`
+	BaseQuantity float64 `json:"baseQuantity"`
+
+	reloaded   int
+	lastConfig string
+}
+
+func (s *ReloadTestStrategy) ID() string {
+	return "reload-test"
+}
+
+func (s *ReloadTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *ReloadTestStrategy) InstanceID() string {
+	return s.Symbol
+}
+
+func (s *ReloadTestStrategy) ReloadConfig(ctx context.Context, config []byte) error {
+	s.reloaded++
+	s.lastConfig = string(config)
+	return json.Unmarshal(config, s)
+}
+
+const reloadTestConfig = `---
+exchangeStrategies:
+- on: ["binance"]
+  reload-test:
+    symbol: "BTCUSDT"
+    baseQuantity: %s
+- on: ["binance"]
+  test:
+    symbol: "BTCUSDT"
+    interval: "1m"
+    baseQuantity: %s
+`
+
+func writeReloadTestConfig(t *testing.T, file, reloadQuantity, testQuantity string) {
+	content := []byte(fmt.Sprintf(reloadTestConfig, reloadQuantity, testQuantity))
+	assert.NoError(t, ioutil.WriteFile(file, content, 0644))
+}
+
+func TestConfigWatcher_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "bbgo.yaml")
+	writeReloadTestConfig(t, file, "0.1", "0.1")
+
+	config, err := Load(file, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	baseline, err := Load(file, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	watcher := NewConfigWatcher(file, config, nil)
+	watcher.baseline = baseline
+
+	reloadStrategy := config.ExchangeStrategies[0].Strategy.(*ReloadTestStrategy)
+	testStrategy := config.ExchangeStrategies[1].Strategy.(*TestStrategy)
+
+	ctx := context.Background()
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.NoError(t, watcher.Reload(ctx))
+		assert.Equal(t, 0, reloadStrategy.reloaded)
+	})
+
+	t.Run("reloader", func(t *testing.T) {
+		writeReloadTestConfig(t, file, "0.2", "0.1")
+		assert.NoError(t, watcher.Reload(ctx))
+		assert.Equal(t, 1, reloadStrategy.reloaded)
+		assert.Equal(t, 0.2, reloadStrategy.BaseQuantity)
+		assert.JSONEq(t, `{"baseQuantity": 0.2}`, reloadStrategy.lastConfig)
+
+		// the baseline is updated, so the same config is not reloaded again
+		assert.NoError(t, watcher.Reload(ctx))
+		assert.Equal(t, 1, reloadStrategy.reloaded)
+	})
+
+	t.Run("not reloader", func(t *testing.T) {
+		writeReloadTestConfig(t, file, "0.2", "0.3")
+		assert.NoError(t, watcher.Reload(ctx))
+		assert.Equal(t, 0.1, testStrategy.BaseQuantity)
+	})
+}
+
+const reorderTestConfig = `---
+exchangeStrategies:
+- on: ["binance"]
+  reload-test:
+    symbol: "%s"
+    baseQuantity: %s
+- on: ["binance"]
+  reload-test:
+    symbol: "%s"
+    baseQuantity: %s
+`
+
+func TestConfigWatcher_ReloadReordered(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "bbgo.yaml")
+	content := fmt.Sprintf(reorderTestConfig, "BTCUSDT", "0.1", "ETHUSDT", "1.0")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+
+	config, err := Load(file, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	baseline, err := Load(file, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	watcher := NewConfigWatcher(file, config, nil)
+	watcher.baseline = baseline
+
+	btc := config.ExchangeStrategies[0].Strategy.(*ReloadTestStrategy)
+	eth := config.ExchangeStrategies[1].Strategy.(*ReloadTestStrategy)
+
+	// the instances are swapped and only the quantity of ETHUSDT is changed
+	content = fmt.Sprintf(reorderTestConfig, "ETHUSDT", "2.0", "BTCUSDT", "0.1")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	assert.NoError(t, watcher.Reload(context.Background()))
+
+	assert.Equal(t, 0, btc.reloaded)
+	assert.Equal(t, "BTCUSDT", btc.Symbol)
+	assert.Equal(t, 0.1, btc.BaseQuantity)
+
+	assert.Equal(t, 1, eth.reloaded)
+	assert.Equal(t, "ETHUSDT", eth.Symbol)
+	assert.Equal(t, 2.0, eth.BaseQuantity)
+}
@@ -3,15 +3,39 @@ package bbgo
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	// register the go migrations
 	_ "github.com/c9s/bbgo/pkg/migrations"
+	sqlite3Migrations "github.com/c9s/bbgo/pkg/migrations/sqlite3"
 
 	"github.com/c9s/rockhopper"
 	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+
+	// register the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/c9s/bbgo/pkg/service"
 )
 
+// sqlite3BusyTimeout is the milliseconds to wait for the lock of the database file before returning the busy error
+const sqlite3BusyTimeout = 5000
+
+// ConnectDB connects the database of the driver, the driver is "mysql" or "sqlite3"
+func ConnectDB(driver, dsn string) (*sqlx.DB, error) {
+	switch driver {
+	case "", service.DriverMySQL:
+		return ConnectMySQL(dsn)
+
+	case service.DriverSQLite3:
+		return ConnectSQLite3(dsn)
+	}
+
+	return nil, fmt.Errorf("unsupported database driver: %s", driver)
+}
+
 func ConnectMySQL(dsn string) (*sqlx.DB, error) {
 	config, err := mysql.ParseDSN(dsn)
 	if err != nil {
@@ -23,16 +47,39 @@ func ConnectMySQL(dsn string) (*sqlx.DB, error) {
 	return sqlx.Connect("mysql", dsn)
 }
 
+// ConnectSQLite3 opens the SQLite database file of the DSN, e.g., bbgo.sqlite3 or file:bbgo.sqlite3?cache=shared,
+// the file is created if it doesn't exist.
+func ConnectSQLite3(dsn string) (*sqlx.DB, error) {
+	// the strategies and the sync service write concurrently, wait for the file lock instead of failing with "database is locked"
+	if !strings.Contains(dsn, "_busy_timeout") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+
+		dsn += fmt.Sprintf("%s_busy_timeout=%d", separator, sqlite3BusyTimeout)
+	}
+
+	return sqlx.Connect(service.DriverSQLite3, dsn)
+}
+
 func upgradeDB(ctx context.Context, driver string, db *sql.DB) error {
 	dialect, err := rockhopper.LoadDialect(driver)
 	if err != nil {
 		return err
 	}
 
-	loader := &rockhopper.GoMigrationLoader{}
-	migrations, err := loader.Load()
-	if err != nil {
-		return err
+	var migrations rockhopper.MigrationSlice
+	switch driver {
+	case service.DriverSQLite3:
+		migrations = sqlite3Migrations.Migrations()
+
+	default:
+		loader := &rockhopper.GoMigrationLoader{}
+		migrations, err = loader.Load()
+		if err != nil {
+			return err
+		}
 	}
 
 	rh := rockhopper.New(driver, dialect, db)
@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEnvironment_ConfigureDatabaseDriver_sqlite3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-sqlite3")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	environ := NewEnvironment()
+	if !assert.NoError(t, environ.ConfigureDatabaseDriver(context.Background(), service.DriverSQLite3, filepath.Join(dir, "bbgo.sqlite3"))) {
+		return
+	}
+
+	assert.Equal(t, service.DriverSQLite3, environ.DatabaseDriver)
+	assert.Empty(t, environ.MysqlURL)
+
+	now := time.Now()
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: 0.01,
+			Price:    9000.0,
+			Tags:     types.OrderTags{"grid_level": "1"},
+		},
+		Exchange:     "binance",
+		OrderID:      1,
+		Status:       types.OrderStatusNew,
+		IsWorking:    true,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+	assert.NoError(t, environ.OrderService.Insert(order))
+
+	// the stored order is updated by the order id, and the tags are kept if the update has no tags
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = 0.01
+	order.IsWorking = false
+	order.Tags = nil
+	assert.NoError(t, environ.OrderService.Insert(order))
+
+	stored, err := environ.OrderService.QueryByOrderID(types.ExchangeBinance, 1)
+	if assert.NoError(t, err) && assert.NotNil(t, stored) {
+		assert.Equal(t, types.OrderStatusFilled, stored.Status)
+		assert.Equal(t, 0.01, stored.ExecutedQuantity)
+		assert.False(t, stored.IsWorking)
+		assert.Equal(t, types.OrderTags{"grid_level": "1"}, stored.Tags)
+	}
+
+	// the duplicated trade is ignored
+	trade := types.Trade{ID: 1, OrderID: 1, Exchange: "binance", Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 9000.0, Quantity: 0.01, QuoteQuantity: 90.0, Time: now, FeeCurrency: "BNB"}
+	assert.NoError(t, environ.TradeService.Insert(trade))
+	assert.NoError(t, environ.TradeService.Insert(trade))
+
+	trades, err := environ.TradeService.QueryByOrderID(types.ExchangeBinance, 1)
+	if assert.NoError(t, err) {
+		assert.Len(t, trades, 1)
+	}
+
+	store := NewSQLPersistenceService(environ.db).NewStore("grid", "BTCUSDT")
+	assert.NoError(t, store.Save(1))
+	assert.NoError(t, store.Save(2))
+
+	var val int
+	if assert.NoError(t, store.Load(&val)) {
+		assert.Equal(t, 2, val)
+	}
+}
@@ -13,9 +13,9 @@ import (
 
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/exchange/paper"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
-	"github.com/c9s/bbgo/pkg/util"
 )
 
 var LoadedExchangeStrategies = make(map[string]SingleExchangeStrategy)
@@ -48,9 +48,42 @@ type Environment struct {
 
 	PersistenceServiceFacade *PersistenceServiceFacade
 
+	// MessageBus is shared by all the strategies for publishing and subscribing the signals
+	MessageBus *MessageBus
+
+	InfluxDB *service.InfluxDBService
+
 	OrderService *service.OrderService
 	TradeService *service.TradeService
 	TradeSync    *service.SyncService
+	AuditService *service.AuditService
+
+	DepositService  *service.DepositService
+	WithdrawService *service.WithdrawService
+
+	// BalanceSnapshotService stores the net value snapshots of the sessions for the equity curve
+	BalanceSnapshotService *service.BalanceSnapshotService
+
+	// StrategyOrderService stores the orders with the strategy and the local serial ID for the audit trail
+	StrategyOrderService *service.StrategyOrderService
+
+	// BacktestService stores the klines synchronized from the exchanges
+	BacktestService *service.BacktestService
+
+	// ExecutionReport is the execution report config of the strategies, the report is disabled if it's nil
+	ExecutionReport *ExecutionReportConfig
+
+	// PortfolioRiskConfig is the portfolio risk config of the futures sessions, the default config is used if it's nil
+	PortfolioRiskConfig *PortfolioRiskConfig
+
+	// OrderConfirmer asks the operator to approve the large orders of the strategies embedding OrderConfirmation
+	OrderConfirmer OrderConfirmer
+
+	// Recorder records the stream events of the sessions for generating the backtest fixtures, the recording is disabled if it's nil
+	Recorder *StreamRecorder
+
+	// Metrics exports the Prometheus metrics of the sessions and the strategies, the metrics are disabled if it's nil
+	Metrics *MetricsExporter
 
 	// startTime is the time of start point (which is used in the backtest)
 	startTime     time.Time
@@ -58,6 +91,12 @@ type Environment struct {
 	sessions      map[string]*ExchangeSession
 
 	MysqlURL string
+
+	// DatabaseDriver and DatabaseDSN are the database configured by ConfigureDatabaseDriver
+	DatabaseDriver string
+	DatabaseDSN    string
+
+	db *sqlx.DB
 }
 
 func NewEnvironment() *Environment {
@@ -66,6 +105,7 @@ func NewEnvironment() *Environment {
 		tradeScanTime: time.Now().AddDate(0, 0, -7), // sync from 7 days ago
 		sessions:      make(map[string]*ExchangeSession),
 		startTime:     time.Now(),
+		MessageBus:    NewMessageBus(),
 	}
 }
 
@@ -78,15 +118,30 @@ func (environ *Environment) Sessions() map[string]*ExchangeSession {
 	return environ.sessions
 }
 
+// ConfigureDatabase configures the MySQL database of the DSN
 func (environ *Environment) ConfigureDatabase(ctx context.Context, dsn string) error {
-	db, err := ConnectMySQL(dsn)
+	return environ.ConfigureDatabaseDriver(ctx, service.DriverMySQL, dsn)
+}
+
+// ConfigureDatabaseDriver connects the database of the driver and upgrades the schema to the latest migration,
+// the driver is "mysql" or "sqlite3".
+func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver, dsn string) error {
+	if len(driver) == 0 {
+		driver = service.DriverMySQL
+	}
+
+	db, err := ConnectDB(driver, dsn)
 	if err != nil {
 		return err
 	}
 
-	environ.MysqlURL = dsn
+	environ.DatabaseDriver = driver
+	environ.DatabaseDSN = dsn
+	if driver == service.DriverMySQL {
+		environ.MysqlURL = dsn
+	}
 
-	if err := upgradeDB(ctx, "mysql", db.DB); err != nil {
+	if err := upgradeDB(ctx, driver, db.DB); err != nil {
 		return err
 	}
 
@@ -95,11 +150,20 @@ func (environ *Environment) ConfigureDatabase(ctx context.Context, dsn string) e
 }
 
 func (environ *Environment) SetDB(db *sqlx.DB) *Environment {
+	environ.db = db
 	environ.OrderService = &service.OrderService{DB: db}
 	environ.TradeService = &service.TradeService{DB: db}
+	environ.AuditService = &service.AuditService{DB: db}
+	environ.BacktestService = &service.BacktestService{DB: db}
+	environ.DepositService = &service.DepositService{DB: db}
+	environ.WithdrawService = &service.WithdrawService{DB: db}
+	environ.BalanceSnapshotService = &service.BalanceSnapshotService{DB: db}
+	environ.StrategyOrderService = &service.StrategyOrderService{DB: db}
 	environ.TradeSync = &service.SyncService{
-		TradeService: environ.TradeService,
-		OrderService: environ.OrderService,
+		TradeService:    environ.TradeService,
+		OrderService:    environ.OrderService,
+		DepositService:  environ.DepositService,
+		WithdrawService: environ.WithdrawService,
 	}
 
 	return environ
@@ -151,8 +215,15 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 
 	var exchange types.Exchange
 
-	if sessionConfig.Key != "" && sessionConfig.Secret != "" {
-		exchange, err = cmdutil.NewExchangeStandard(exchangeName, sessionConfig.Key, sessionConfig.Secret)
+	if sessionConfig.PublicOnly {
+		// the public only session is an observer of the market data, the api credentials are not required
+		if sessionConfig.Margin || sessionConfig.PaperTrade || len(sessionConfig.Leverage) > 0 {
+			return nil, fmt.Errorf("session %s: margin, paper trade and leverage settings can not be used with the public only session", name)
+		}
+
+		exchange, err = cmdutil.NewExchangePublic(exchangeName)
+	} else if sessionConfig.Key != "" && sessionConfig.Secret != "" {
+		exchange, err = cmdutil.NewExchangeStandard(exchangeName, sessionConfig.Key, sessionConfig.Secret, sessionConfig.Passphrase)
 	} else {
 		exchange, err = cmdutil.NewExchangeWithEnvVarPrefix(exchangeName, sessionConfig.EnvVarPrefix)
 	}
@@ -162,6 +233,23 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 	}
 
 	// configure exchange
+	if sessionConfig.Futures {
+		if sessionConfig.Margin {
+			return nil, fmt.Errorf("session %s: futures and margin can not be enabled at the same time", name)
+		}
+
+		futuresExchange, ok := exchange.(types.FuturesExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support futures", exchangeName)
+		}
+
+		futuresExchange.UseFutures()
+	}
+
+	if sessionConfig.AutoBorrow && !sessionConfig.Margin {
+		return nil, fmt.Errorf("session %s: autoBorrow requires the margin session", name)
+	}
+
 	if sessionConfig.Margin {
 		marginExchange, ok := exchange.(types.MarginExchange)
 		if !ok {
@@ -175,19 +263,69 @@ func NewExchangeSessionFromConfig(name string, sessionConfig *ExchangeSession) (
 		}
 	}
 
+	if sessionConfig.PaperTrade {
+		if sessionConfig.Futures {
+			return nil, fmt.Errorf("session %s: paper trade does not support futures", name)
+		}
+
+		log.Infof("session %s is in paper trade mode, orders are simulated with the live market data", name)
+		exchange = paper.New(exchange, sessionConfig.PaperTradeBalances.BalanceMap())
+	}
+
+	if err := ValidateKLineAggregation(sessionConfig.KLineAggregation); err != nil {
+		return nil, fmt.Errorf("session %s: %w", name, err)
+	}
+
+	if sessionConfig.TradingPolicy != nil {
+		if err := sessionConfig.TradingPolicy.Validate(); err != nil {
+			return nil, fmt.Errorf("session %s: %w", name, err)
+		}
+	}
+
 	session := NewExchangeSession(name, exchange)
+	if sessionConfig.EventDispatcher != nil {
+		session.Stream = types.NewDispatchStream(session.Stream, *sessionConfig.EventDispatcher)
+	}
+
+	if sessionConfig.PublicOnly {
+		session.Stream.SetPublicOnly()
+	}
+
 	session.ExchangeName = sessionConfig.ExchangeName
 	session.EnvVarPrefix = sessionConfig.EnvVarPrefix
 	session.Key = sessionConfig.Key
 	session.Secret = sessionConfig.Secret
+	session.Passphrase = sessionConfig.Passphrase
+	session.SubAccount = sessionConfig.SubAccount
 	session.PublicOnly = sessionConfig.PublicOnly
 	session.Margin = sessionConfig.Margin
 	session.IsolatedMargin = sessionConfig.IsolatedMargin
 	session.IsolatedMarginSymbol = sessionConfig.IsolatedMarginSymbol
+	session.AutoBorrow = sessionConfig.AutoBorrow
+	session.AutoRepay = sessionConfig.AutoRepay
+	session.Futures = sessionConfig.Futures
+	session.Leverage = sessionConfig.Leverage
+	session.MarginType = sessionConfig.MarginType
+	session.PaperTrade = sessionConfig.PaperTrade
+	session.PaperTradeBalances = sessionConfig.PaperTradeBalances
+	session.WarmStart = sessionConfig.WarmStart
+	session.WarmStartMaxAge = sessionConfig.WarmStartMaxAge
+	session.MonitorAnnouncements = sessionConfig.MonitorAnnouncements
+	session.AnnouncementInterval = sessionConfig.AnnouncementInterval
+	session.LatencyReportInterval = sessionConfig.LatencyReportInterval
+	session.CollateralUpdateInterval = sessionConfig.CollateralUpdateInterval
+	session.StreamRecovery = sessionConfig.StreamRecovery
+	session.StreamSupervisor = sessionConfig.StreamSupervisor
+	session.RiskLimits = sessionConfig.RiskLimits
+	session.KLineAggregation = sessionConfig.KLineAggregation
+	session.TradingPolicy = sessionConfig.TradingPolicy
+	session.EventDispatcher = sessionConfig.EventDispatcher
 	return session, nil
 }
 
 func (environ *Environment) AddExchangesFromSessionConfig(sessions map[string]*ExchangeSession) error {
+	warnDuplicatedSessionCredentials(sessions)
+
 	for sessionName, sessionConfig := range sessions {
 		session, err := NewExchangeSessionFromConfig(sessionName, sessionConfig)
 		if err != nil {
@@ -200,20 +338,23 @@ func (environ *Environment) AddExchangesFromSessionConfig(sessions map[string]*E
 	return nil
 }
 
-// Init prepares the data that will be used by the strategies
+// Init prepares the data that will be used by the strategies, the sessions are initialized in the order of their InitOrder.
+// The lazy sessions not required by any strategy are skipped, and the optional sessions failed to initialize are marked unavailable.
 func (environ *Environment) Init(ctx context.Context) (err error) {
-	for n := range environ.sessions {
-		var session = environ.sessions[n]
+	for _, session := range sortSessionsByInitOrder(environ.sessions) {
+		if !session.IsRequired() {
+			log.Infof("lazy session %s is not required by any strategy, skipping", session.Name)
+			continue
+		}
 
-		if err := session.Init(ctx, environ); err != nil {
-			// we can skip initialized sessions
-			if err != ErrSessionAlreadyInitialized {
+		if err := session.initWithTimeout(ctx, environ); err != nil {
+			if !session.Optional {
 				return err
 			}
-		}
 
-		if err := session.InitSymbols(ctx, environ); err != nil {
-			return err
+			log.WithError(err).Errorf("optional session %s is not available, the strategies on it will not be started", session.Name)
+			environ.Notify("optional session %s is not available: %v", session.Name, err)
+			session.markUnavailable(err)
 		}
 	}
 
@@ -244,14 +385,108 @@ func (environ *Environment) ConfigurePersistence(conf *PersistenceConfig) error
 		facade.Json = &JsonPersistenceService{Directory: conf.Json.Directory}
 	}
 
+	// the sql persistence uses the configured database
+	if environ.db != nil {
+		facade.SQL = NewSQLPersistenceService(environ.db)
+	}
+
 	environ.PersistenceServiceFacade = facade
 	return nil
 }
 
+// ConfigureService configures the optional external services, e.g., the InfluxDB exporter and the audit log.
+// the sessions must be added before calling this method since the exporter binds the session streams.
+func (environ *Environment) ConfigureService(conf *ServiceConfig) error {
+	if conf.InfluxDB != nil {
+		if err := env.Set(conf.InfluxDB); err != nil {
+			return err
+		}
+
+		if len(conf.InfluxDB.URL) == 0 {
+			return fmt.Errorf("service.influxDB.url can not be empty")
+		}
+
+		environ.InfluxDB = service.NewInfluxDBService(conf.InfluxDB.URL, conf.InfluxDB.Org, conf.InfluxDB.Bucket, conf.InfluxDB.Token)
+
+		exporter := NewInfluxDBExporter(environ.InfluxDB)
+		for _, session := range environ.sessions {
+			exporter.BindSession(session)
+		}
+	}
+
+	if conf.Audit != nil && conf.Audit.Enabled {
+		if environ.AuditService == nil {
+			return fmt.Errorf("service.audit requires the database, please set MYSQL_URL")
+		}
+
+		for name, session := range environ.sessions {
+			session.auditor = NewAuditor(name, session.Exchange.Name(), environ.AuditService)
+			if !session.PublicOnly {
+				session.auditor.BindStream(session.Stream)
+			}
+		}
+
+		if environ.TradeSync != nil {
+			environ.TradeSync.AuditService = environ.AuditService
+		}
+	}
+
+	if conf.Recorder != nil {
+		recorder, err := NewStreamRecorder(conf.Recorder)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range environ.sessions {
+			recorder.BindSession(session)
+		}
+
+		environ.Recorder = recorder
+	}
+
+	if conf.Metrics != nil {
+		exporter := NewMetricsExporter(conf.Metrics)
+		for _, session := range environ.sessions {
+			exporter.BindSession(session)
+		}
+
+		environ.Metrics = exporter
+	}
+
+	if conf.ExecutionReport != nil && conf.ExecutionReport.Enabled {
+		environ.ExecutionReport = conf.ExecutionReport
+	}
+
+	if conf.PortfolioRisk != nil {
+		environ.PortfolioRiskConfig = conf.PortfolioRisk
+		if err := environ.schedulePortfolioRiskReport(conf.PortfolioRisk); err != nil {
+			return err
+		}
+	}
+
+	if conf.AccountValue != nil {
+		if err := environ.scheduleAccountValueReport(conf.AccountValue); err != nil {
+			return err
+		}
+	}
+
+	if conf.BalanceSnapshot != nil {
+		if err := environ.scheduleBalanceSnapshots(conf.BalanceSnapshot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // configure notification rules
 // for symbol-based routes, we should register the same symbol rules for each session.
 // for session-based routes, we should set the fixed callbacks for each session
 func (environ *Environment) ConfigureNotification(conf *NotificationConfig) error {
+	if conf.Format != nil {
+		conf.Format.Apply()
+	}
+
 	// configure routing here
 	if conf.SymbolChannels != nil {
 		environ.SymbolChannelRouter.AddRoute(conf.SymbolChannels)
@@ -267,7 +502,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 
 		case "$session":
 			defaultTradeUpdateHandler := func(trade types.Trade) {
-				text := util.Render(TemplateTradeReport, trade)
+				text := RenderNotificationTemplate(TemplateTradeReport, trade)
 				environ.Notify(text, &trade)
 			}
 			for name := range environ.sessions {
@@ -277,7 +512,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 				channel, ok := environ.SessionChannelRouter.Route(name)
 				if ok {
 					session.Stream.OnTradeUpdate(func(trade types.Trade) {
-						text := util.Render(TemplateTradeReport, trade)
+						text := RenderNotificationTemplate(TemplateTradeReport, trade)
 						environ.NotifyTo(channel, text, &trade)
 					})
 				} else {
@@ -298,7 +533,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 
 			// use same handler for each session
 			handler := func(trade types.Trade) {
-				text := util.Render(TemplateTradeReport, trade)
+				text := RenderNotificationTemplate(TemplateTradeReport, trade)
 				channel, ok := environ.RouteObject(&trade)
 				if ok {
 					environ.NotifyTo(channel, text, &trade)
@@ -317,7 +552,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 
 		case "$session":
 			defaultOrderUpdateHandler := func(order types.Order) {
-				text := util.Render(TemplateOrderReport, order)
+				text := RenderNotificationTemplate(TemplateOrderReport, order)
 				environ.Notify(text, &order)
 			}
 			for name := range environ.sessions {
@@ -327,7 +562,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 				channel, ok := environ.SessionChannelRouter.Route(name)
 				if ok {
 					session.Stream.OnOrderUpdate(func(order types.Order) {
-						text := util.Render(TemplateOrderReport, order)
+						text := RenderNotificationTemplate(TemplateOrderReport, order)
 						environ.NotifyTo(channel, text, &order)
 					})
 				} else {
@@ -348,7 +583,7 @@ func (environ *Environment) ConfigureNotification(conf *NotificationConfig) erro
 
 			// use same handler for each session
 			handler := func(order types.Order) {
-				text := util.Render(TemplateOrderReport, order)
+				text := RenderNotificationTemplate(TemplateOrderReport, order)
 				channel, ok := environ.RouteObject(&order)
 				if ok {
 					environ.NotifyTo(channel, text, &order)
@@ -408,10 +643,19 @@ func (environ *Environment) SyncTradesFrom(t time.Time) *Environment {
 }
 
 func (environ *Environment) Connect(ctx context.Context) error {
-	for n := range environ.sessions {
+	if environ.Metrics != nil {
+		go environ.Metrics.Run(ctx)
+	}
+
+	for _, session := range sortSessionsByInitOrder(environ.sessions) {
+		// the lazy sessions not required and the unavailable optional sessions are not connected
+		if !session.IsAvailable() {
+			continue
+		}
+
 		// avoid using the placeholder variable for the session because we use that in the callbacks
-		var session = environ.sessions[n]
-		var logger = log.WithField("session", n)
+		var session = session
+		var logger = log.WithField("session", session.Name)
 
 		if len(session.Subscriptions) == 0 {
 			logger.Warnf("exchange session %s has no subscriptions", session.Name)
@@ -425,7 +669,34 @@ func (environ *Environment) Connect(ctx context.Context) error {
 
 		logger.Infof("connecting session %s...", session.Name)
 		if err := session.Stream.Connect(ctx); err != nil {
-			return err
+			if !session.Optional {
+				return err
+			}
+
+			logger.WithError(err).Errorf("optional session %s can not be connected", session.Name)
+			environ.Notify("optional session %s can not be connected: %v", session.Name, err)
+			session.markUnavailable(err)
+			continue
+		}
+
+		if session.MonitorAnnouncements {
+			if provider, ok := session.Exchange.(types.AnnouncementProvider); ok {
+				go NewAnnouncementMonitor(session, provider).Run(ctx)
+			} else {
+				logger.Warnf("exchange %s does not provide the announcement feed", session.ExchangeName)
+			}
+		}
+
+		if session.LatencyReportInterval > 0 {
+			go session.reportLatency(ctx, session.LatencyReportInterval.Duration())
+		}
+
+		if session.Margin && !session.PublicOnly {
+			go session.updateCollateral(ctx, session.collateralUpdateInterval())
+		}
+
+		if session.StreamSupervisor != nil && session.streamSupervisor != nil {
+			go session.streamSupervisor.Run(ctx)
 		}
 	}
 
@@ -4,3 +4,11 @@ import "errors"
 
 var ErrSessionAlreadyInitialized = errors.New("session is already initialized")
 
+// ErrSessionPublicOnly is returned when submitting orders to the public only session, which has no api credentials
+var ErrSessionPublicOnly = errors.New("session is public only, orders are not allowed")
+
+// ErrDustQuantity is returned when the truncated order quantity is below the min quantity or the min notional of the market
+var ErrDustQuantity = errors.New("order quantity is below the min quantity or the min notional")
+
+// ErrUnsupportedOrder is returned in the submit order result when the order requires a capability missing in the session
+var ErrUnsupportedOrder = errors.New("unsupported order")
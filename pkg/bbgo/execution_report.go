@@ -0,0 +1,287 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ExecutionReport is the execution summary of the orders submitted by a strategy
+type ExecutionReport struct {
+	StrategyID string    `json:"strategyID"`
+	Session    string    `json:"session"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+
+	NumOrders         int `json:"numOrders"`
+	NumFilledOrders   int `json:"numFilledOrders"`
+	NumCanceledOrders int `json:"numCanceledOrders"`
+	NumRejectedOrders int `json:"numRejectedOrders"`
+	NumTrades         int `json:"numTrades"`
+
+	// FillRate is the ratio of the fully filled orders to the submitted orders
+	FillRate float64 `json:"fillRate"`
+
+	// AverageSlippage is the quantity weighted slippage ratio of the trades against the order price (or the last price
+	// when the market order is submitted), a positive slippage means the trade is executed at a worse price.
+	AverageSlippage float64 `json:"averageSlippage"`
+
+	// Fees is the paid fees by the fee currency
+	Fees map[string]fixedpoint.Value `json:"fees"`
+
+	// NetProfits is the net profit by the quote currency, the open inventory is valued with the last price,
+	// and the fees paid in the quote currency or the base currency are deducted.
+	NetProfits map[string]fixedpoint.Value `json:"netProfits"`
+}
+
+func (r ExecutionReport) PlainText() string {
+	return fmt.Sprintf("%s execution report (%s ~ %s): %d orders, %d filled, %d canceled, %d rejected, %d trades, fill rate %.2f%%, average slippage %.4f%%, fees [%s], net profit [%s]",
+		r.StrategyID,
+		r.StartTime.Format(time.RFC3339), r.EndTime.Format(time.RFC3339),
+		r.NumOrders, r.NumFilledOrders, r.NumCanceledOrders, r.NumRejectedOrders, r.NumTrades,
+		r.FillRate*100.0, r.AverageSlippage*100.0,
+		formatCurrencyAmounts(r.Fees), formatCurrencyAmounts(r.NetProfits))
+}
+
+func formatCurrencyAmounts(amounts map[string]fixedpoint.Value) string {
+	var currencies []string
+	for currency := range amounts {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var texts []string
+	for _, currency := range currencies {
+		texts = append(texts, fmt.Sprintf("%f %s", amounts[currency].Float64(), currency))
+	}
+
+	return strings.Join(texts, ", ")
+}
+
+type recordedOrder struct {
+	types.Order
+
+	// referencePrice is the price for measuring the slippage
+	referencePrice float64
+}
+
+// ExecutionRecorder wraps the order executor of the strategy and records the submitted orders,
+// the order updates and the trades of these orders for generating the execution report.
+type ExecutionRecorder struct {
+	OrderExecutor
+
+	StrategyID string
+	Session    *ExchangeSession
+
+	mu        sync.Mutex
+	startTime time.Time
+	orders    map[uint64]*recordedOrder
+	trades    map[int64]types.Trade
+}
+
+func NewExecutionRecorder(executor OrderExecutor, strategyID string, session *ExchangeSession) *ExecutionRecorder {
+	return &ExecutionRecorder{
+		OrderExecutor: executor,
+		StrategyID:    strategyID,
+		Session:       session,
+		startTime:     time.Now(),
+		orders:        make(map[uint64]*recordedOrder),
+		trades:        make(map[int64]types.Trade),
+	}
+}
+
+func (r *ExecutionRecorder) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(r.handleOrderUpdate)
+	stream.OnTradeUpdate(r.handleTrade)
+}
+
+func (r *ExecutionRecorder) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := r.OrderExecutor.SubmitOrders(ctx, orders...)
+	r.recordOrders(createdOrders...)
+	return createdOrders, err
+}
+
+func (r *ExecutionRecorder) recordOrders(orders ...types.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, o := range orders {
+		referencePrice := o.Price
+		if o.Type == types.OrderTypeMarket || referencePrice == 0 {
+			referencePrice, _ = r.Session.LastPrice(o.Symbol)
+		}
+
+		r.orders[o.OrderID] = &recordedOrder{Order: o, referencePrice: referencePrice}
+	}
+}
+
+func (r *ExecutionRecorder) handleOrderUpdate(order types.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if o, ok := r.orders[order.OrderID]; ok {
+		o.Status = order.Status
+		o.ExecutedQuantity = order.ExecutedQuantity
+	}
+}
+
+func (r *ExecutionRecorder) handleTrade(trade types.Trade) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orders[trade.OrderID]; ok {
+		r.trades[trade.ID] = trade
+	}
+}
+
+// Report generates the execution report of the orders submitted so far
+func (r *ExecutionRecorder) Report() ExecutionReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := ExecutionReport{
+		StrategyID: r.StrategyID,
+		Session:    r.Session.Name,
+		StartTime:  r.startTime,
+		EndTime:    time.Now(),
+		NumOrders:  len(r.orders),
+		NumTrades:  len(r.trades),
+		Fees:       make(map[string]fixedpoint.Value),
+		NetProfits: make(map[string]fixedpoint.Value),
+	}
+
+	for _, o := range r.orders {
+		switch o.Status {
+		case types.OrderStatusFilled:
+			report.NumFilledOrders++
+		case types.OrderStatusCanceled:
+			report.NumCanceledOrders++
+		case types.OrderStatusRejected:
+			report.NumRejectedOrders++
+		}
+	}
+
+	if report.NumOrders > 0 {
+		report.FillRate = float64(report.NumFilledOrders) / float64(report.NumOrders)
+	}
+
+	var slippage, slippageQuantity float64
+	var inventories = make(map[string]float64)
+	var lastTrades = make(map[string]types.Trade)
+	var profits = make(map[string]float64)
+	for _, trade := range r.trades {
+		if o := r.orders[trade.OrderID]; o.referencePrice > 0 {
+			diff := (trade.Price - o.referencePrice) / o.referencePrice
+			if trade.Side == types.SideTypeSell {
+				diff = -diff
+			}
+
+			slippage += diff * trade.Quantity
+			slippageQuantity += trade.Quantity
+		}
+
+		report.Fees[trade.FeeCurrency] += fixedpoint.NewFromFloat(trade.Fee)
+
+		market, ok := r.Session.Market(trade.Symbol)
+		if !ok {
+			continue
+		}
+
+		addTradeCashFlow(trade, market, profits, inventories)
+
+		if last, ok := lastTrades[trade.Symbol]; !ok || trade.Time.After(last.Time) {
+			lastTrades[trade.Symbol] = trade
+		}
+	}
+
+	if slippageQuantity > 0 {
+		report.AverageSlippage = slippage / slippageQuantity
+	}
+
+	for symbol, inventory := range inventories {
+		market, _ := r.Session.Market(symbol)
+		price, ok := r.Session.LastPrice(symbol)
+		if !ok {
+			price = lastTrades[symbol].Price
+		}
+
+		profits[types.CanonicalCurrency(market.QuoteCurrency)] += inventory * price
+	}
+
+	for currency, profit := range profits {
+		report.NetProfits[currency] = fixedpoint.NewFromFloat(profit)
+	}
+
+	return report
+}
+
+// addTradeCashFlow adds the quote quantity of the trade to the profit of the quote currency and the base quantity
+// to the inventory of the symbol, the fees paid in the quote currency or the base currency are deducted.
+// The profits of the equivalent quote currencies are added up to the canonical currency of the equivalence group.
+func addTradeCashFlow(trade types.Trade, market types.Market, profits, inventories map[string]float64) {
+	quoteCurrency := types.CanonicalCurrency(market.QuoteCurrency)
+
+	quoteQuantity := trade.QuoteQuantity
+	if quoteQuantity == 0 {
+		quoteQuantity = trade.Price * trade.Quantity
+	}
+
+	if trade.Side == types.SideTypeBuy {
+		profits[quoteCurrency] -= quoteQuantity
+		inventories[trade.Symbol] += trade.Quantity
+	} else {
+		profits[quoteCurrency] += quoteQuantity
+		inventories[trade.Symbol] -= trade.Quantity
+	}
+
+	switch {
+	case trade.FeeCurrency == market.BaseCurrency:
+		inventories[trade.Symbol] -= trade.Fee
+	case types.EquivalentCurrencies(trade.FeeCurrency, market.QuoteCurrency):
+		profits[quoteCurrency] -= trade.Fee
+	}
+}
+
+// reportExecutions sends the execution reports of the strategies through the notifiers,
+// and saves the reports into the persistence store if it's configured.
+func (trader *Trader) reportExecutions() {
+	conf := trader.environment.ExecutionReport
+	for _, recorder := range trader.executionRecorders {
+		report := recorder.Report()
+
+		if channel, ok := trader.environment.RouteSession(report.Session); ok {
+			trader.environment.NotifyTo(channel, ":bar_chart: %s", report.PlainText(), &report)
+		} else {
+			trader.environment.Notify(":bar_chart: %s", report.PlainText(), &report)
+		}
+
+		if conf == nil || conf.Persistence == nil || trader.environment.PersistenceServiceFacade == nil {
+			continue
+		}
+
+		persistence := &Persistence{
+			PersistenceSelector: conf.Persistence,
+			Facade:              trader.environment.PersistenceServiceFacade,
+		}
+
+		if err := persistence.Save(&report, "execution-report", report.StrategyID, strconv.FormatInt(report.EndTime.Unix(), 10)); err != nil {
+			trader.logger.Errorf("can not save the execution report of strategy %s: %s", report.StrategyID, err.Error())
+		}
+	}
+}
+
+// ExecutionReports generates the execution reports of the running strategies on demand
+func (trader *Trader) ExecutionReports() (reports []ExecutionReport) {
+	for _, recorder := range trader.executionRecorders {
+		reports = append(reports, recorder.Report())
+	}
+
+	return reports
+}
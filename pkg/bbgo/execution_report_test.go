@@ -0,0 +1,59 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExecutionRecorder_Report(t *testing.T) {
+	session := &ExchangeSession{
+		Name: "binance",
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 11000.0},
+	}
+
+	recorder := NewExecutionRecorder(nil, "grid", session)
+	recorder.recordOrders(
+		types.Order{OrderID: 1, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 1.0}},
+		types.Order{OrderID: 2, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 0.5}},
+		types.Order{OrderID: 3, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9000.0, Quantity: 1.0}},
+	)
+
+	recorder.handleOrderUpdate(types.Order{OrderID: 1, Status: types.OrderStatusFilled, ExecutedQuantity: 1.0})
+	recorder.handleOrderUpdate(types.Order{OrderID: 2, Status: types.OrderStatusFilled, ExecutedQuantity: 0.5})
+	recorder.handleOrderUpdate(types.Order{OrderID: 3, Status: types.OrderStatusCanceled})
+
+	// the order not submitted by the strategy is ignored
+	recorder.handleOrderUpdate(types.Order{OrderID: 4, Status: types.OrderStatusFilled})
+	recorder.handleTrade(types.Trade{ID: 4, OrderID: 4, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 1.0})
+
+	now := time.Now()
+	recorder.handleTrade(types.Trade{ID: 1, OrderID: 1, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 1.0, QuoteQuantity: 10000.0, Fee: 10.0, FeeCurrency: "USDT", Time: now})
+	recorder.handleTrade(types.Trade{ID: 2, OrderID: 2, Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 10890.0, Quantity: 0.5, QuoteQuantity: 5445.0, Fee: 0.001, FeeCurrency: "BNB", Time: now})
+
+	// the duplicated trade update is counted once
+	recorder.handleTrade(types.Trade{ID: 2, OrderID: 2, Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 10890.0, Quantity: 0.5, QuoteQuantity: 5445.0, Fee: 0.001, FeeCurrency: "BNB", Time: now})
+
+	report := recorder.Report()
+	assert.Equal(t, "grid", report.StrategyID)
+	assert.Equal(t, 3, report.NumOrders)
+	assert.Equal(t, 2, report.NumFilledOrders)
+	assert.Equal(t, 1, report.NumCanceledOrders)
+	assert.Equal(t, 2, report.NumTrades)
+	assert.InDelta(t, 2.0/3.0, report.FillRate, 1e-9)
+
+	// the market sell order is executed 1% below the last price 11000
+	assert.InDelta(t, 0.01*0.5/1.5, report.AverageSlippage, 1e-9)
+
+	assert.InDelta(t, 10.0, report.Fees["USDT"].Float64(), 1e-6)
+	assert.InDelta(t, 0.001, report.Fees["BNB"].Float64(), 1e-6)
+
+	// -10000 + 5445 - 10 + 0.5 * 11000
+	assert.InDelta(t, 935.0, report.NetProfits["USDT"].Float64(), 1e-6)
+}
@@ -0,0 +1,183 @@
+package exit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("component", "exit")
+
+// TrailingStop is the trailing stop exit of a long position, embed it in the strategy config and bind it to the strategy position.
+// It tracks the high-water mark of the price after the entry, and sells the position by a market order
+// when the price retraces CallbackRate from the high-water mark, e.g., callbackRate: 0.02 sells the position
+// when the price drops 2% from the highest price since the entry.
+type TrailingStop struct {
+	// CallbackRate is the retracement ratio from the high-water mark
+	CallbackRate fixedpoint.Value `json:"callbackRate" yaml:"callbackRate"`
+
+	// ActivationRatio is the min profit ratio of the high-water mark over the average cost to arm the trailing stop,
+	// zero arms the trailing stop right after the entry
+	ActivationRatio fixedpoint.Value `json:"activationRatio,omitempty" yaml:"activationRatio,omitempty"`
+
+	// Interval is the kline interval of the price updates, defaults to 1m
+	Interval types.Interval `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	Symbol        string             `json:"-" yaml:"-"`
+	Market        types.Market       `json:"-" yaml:"-"`
+	Position      *bbgo.Position     `json:"-" yaml:"-"`
+	OrderExecutor bbgo.OrderExecutor `json:"-" yaml:"-"`
+
+	mu        sync.Mutex
+	highPrice float64
+
+	// triggered is set after the sell order is submitted, it's reset when the position is closed
+	triggered bool
+
+	triggeredCallbacks []func(price, highPrice float64)
+}
+
+func (s *TrailingStop) interval() types.Interval {
+	if s.Interval == "" {
+		return types.Interval1m
+	}
+
+	return s.Interval
+}
+
+// Subscribe subscribes the kline of the price updates, call it in the Subscribe method of the strategy
+func (s *TrailingStop) Subscribe(session *bbgo.ExchangeSession, symbol string) {
+	session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: string(s.interval())})
+}
+
+// Bind attaches the trailing stop to the position, the sell orders are submitted through the order executor
+func (s *TrailingStop) Bind(session *bbgo.ExchangeSession, position *bbgo.Position, orderExecutor bbgo.OrderExecutor) error {
+	if s.CallbackRate <= 0 {
+		return fmt.Errorf("trailing stop callbackRate should be greater than 0")
+	}
+
+	if position == nil {
+		return fmt.Errorf("trailing stop requires the position")
+	}
+
+	market, ok := session.Market(position.Symbol)
+	if !ok {
+		return fmt.Errorf("market %s is not defined", position.Symbol)
+	}
+
+	s.Symbol = position.Symbol
+	s.Market = market
+	s.Position = position
+	s.OrderExecutor = orderExecutor
+	s.BindStream(session.Stream)
+	return nil
+}
+
+func (s *TrailingStop) BindStream(stream types.Stream) {
+	stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != s.interval() {
+			return
+		}
+
+		s.Update(context.Background(), kline.Close)
+	})
+}
+
+// OnTriggered registers the callback called after the sell order is submitted
+func (s *TrailingStop) OnTriggered(cb func(price, highPrice float64)) {
+	s.triggeredCallbacks = append(s.triggeredCallbacks, cb)
+}
+
+func (s *TrailingStop) emitTriggered(price, highPrice float64) {
+	for _, cb := range s.triggeredCallbacks {
+		cb(price, highPrice)
+	}
+}
+
+// HighPrice returns the high-water mark since the entry, it's zero when there is no long position
+func (s *TrailingStop) HighPrice() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.highPrice
+}
+
+// Check updates the high-water mark with the price, returns true if the price retraces the callback rate from it
+func (s *TrailingStop) Check(price float64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := s.Position.Base.Float64()
+	cost := s.Position.AverageCost.Float64()
+
+	// the high-water mark is reset after the position is closed, and tracked again from the next entry
+	if base <= 0 || cost <= 0 {
+		s.highPrice = 0
+		s.triggered = false
+		return false
+	}
+
+	// the sell order is submitted, wait for the position to be closed
+	if s.triggered {
+		return false
+	}
+
+	if price > s.highPrice {
+		s.highPrice = price
+	}
+
+	if s.highPrice < cost*(1.0+s.ActivationRatio.Float64()) {
+		return false
+	}
+
+	return (s.highPrice-price)/s.highPrice >= s.CallbackRate.Float64()
+}
+
+// Update checks the price and sells the position if the trailing stop is triggered
+func (s *TrailingStop) Update(ctx context.Context, price float64) {
+	if !s.Check(price) {
+		return
+	}
+
+	s.mu.Lock()
+	s.triggered = true
+	highPrice := s.highPrice
+	s.mu.Unlock()
+
+	log.Warnf("%s trailing stop triggered at price %f, high price %f, position base %f average cost %f",
+		s.Symbol, price, highPrice, s.Position.Base.Float64(), s.Position.AverageCost.Float64())
+
+	if err := s.closePosition(ctx); err != nil {
+		log.WithError(err).Errorf("can not close the %s position", s.Symbol)
+
+		// retry on the next price update
+		s.mu.Lock()
+		s.triggered = false
+		s.mu.Unlock()
+		return
+	}
+
+	s.emitTriggered(price, highPrice)
+}
+
+func (s *TrailingStop) closePosition(ctx context.Context) error {
+	quantity := s.Market.RoundDownQuantity(s.Position.Base.Float64())
+	if quantity < s.Market.MinQuantity {
+		return fmt.Errorf("position quantity %f is less than the min quantity %f", quantity, s.Market.MinQuantity)
+	}
+
+	_, err := s.OrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Market:   s.Market,
+		Quantity: quantity,
+		Tags:     types.OrderTags{"reason": "trailingStop"},
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+package exit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testOrderExecutor struct {
+	bbgo.OrderExecutor
+
+	orders []types.SubmitOrder
+}
+
+func (e *testOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	e.orders = append(e.orders, orders...)
+	return nil, nil
+}
+
+func newTestTrailingStop(callbackRate, activationRatio float64, base, cost float64) (*TrailingStop, *testOrderExecutor) {
+	executor := &testOrderExecutor{}
+	return &TrailingStop{
+		CallbackRate:    fixedpoint.NewFromFloat(callbackRate),
+		ActivationRatio: fixedpoint.NewFromFloat(activationRatio),
+		Symbol:          "BTCUSDT",
+		Market:          types.Market{Symbol: "BTCUSDT", VolumePrecision: 8, StepSize: 0.00000001},
+		Position: &bbgo.Position{
+			Symbol:      "BTCUSDT",
+			Base:        fixedpoint.NewFromFloat(base),
+			AverageCost: fixedpoint.NewFromFloat(cost),
+		},
+		OrderExecutor: executor,
+	}, executor
+}
+
+func TestTrailingStop_Check(t *testing.T) {
+	t.Run("retrace from the high-water mark", func(t *testing.T) {
+		s, _ := newTestTrailingStop(0.02, 0, 1.0, 100.0)
+
+		assert.False(t, s.Check(101.0))
+		assert.False(t, s.Check(110.0))
+		assert.Equal(t, 110.0, s.HighPrice())
+
+		assert.False(t, s.Check(108.0))
+		assert.True(t, s.Check(107.0))
+	})
+
+	t.Run("activation ratio", func(t *testing.T) {
+		s, _ := newTestTrailingStop(0.02, 0.1, 1.0, 100.0)
+
+		// the high-water mark is below the activation price
+		assert.False(t, s.Check(105.0))
+		assert.False(t, s.Check(95.0))
+
+		assert.False(t, s.Check(120.0))
+		assert.True(t, s.Check(117.0))
+	})
+
+	t.Run("no position", func(t *testing.T) {
+		s, _ := newTestTrailingStop(0.02, 0, 0, 0)
+		assert.False(t, s.Check(100.0))
+		assert.False(t, s.Check(50.0))
+		assert.Equal(t, 0.0, s.HighPrice())
+	})
+}
+
+func TestTrailingStop_Update(t *testing.T) {
+	s, executor := newTestTrailingStop(0.05, 0, 0.5, 100.0)
+
+	var triggeredPrice, triggeredHighPrice float64
+	s.OnTriggered(func(price, highPrice float64) {
+		triggeredPrice, triggeredHighPrice = price, highPrice
+	})
+
+	s.Update(context.Background(), 120.0)
+	s.Update(context.Background(), 113.0)
+	if assert.Len(t, executor.orders, 1) {
+		assert.Equal(t, types.SideTypeSell, executor.orders[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, executor.orders[0].Type)
+		assert.Equal(t, 0.5, executor.orders[0].Quantity)
+	}
+
+	assert.Equal(t, 113.0, triggeredPrice)
+	assert.Equal(t, 120.0, triggeredHighPrice)
+
+	// the sell order is not submitted again before the position is closed
+	s.Update(context.Background(), 110.0)
+	assert.Len(t, executor.orders, 1)
+
+	// tracked again from the next entry
+	s.Position.Base = 0
+	s.Update(context.Background(), 110.0)
+	s.Position.Base = fixedpoint.NewFromFloat(1.0)
+	s.Update(context.Background(), 105.0)
+	assert.Equal(t, 105.0, s.HighPrice())
+}
@@ -0,0 +1,47 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownTestStrategy struct {
+	canceled bool
+}
+
+func (s *shutdownTestStrategy) Shutdown(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	s.canceled = ctx.Err() == nil
+}
+
+func TestGraceful_Shutdown(t *testing.T) {
+	t.Run("strategy shutdown", func(t *testing.T) {
+		var graceful Graceful
+		var strategy StrategyShutdown = &shutdownTestStrategy{}
+		graceful.OnShutdown(strategy.Shutdown)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		graceful.Shutdown(ctx)
+		assert.True(t, strategy.(*shutdownTestStrategy).canceled)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		var graceful Graceful
+		graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+			// never calls wg.Done()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		startTime := time.Now()
+		graceful.Shutdown(ctx)
+		assert.Less(t, int64(time.Since(startTime)), int64(time.Second))
+	})
+}
@@ -0,0 +1,253 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultHaltTimeout is the timeout of canceling the orders and closing the positions of all the sessions
+const DefaultHaltTimeout = time.Minute
+
+// HaltOptions are the options of the emergency halt
+type HaltOptions struct {
+	// ClosePositions submits the market orders to close the open positions after the orders are canceled
+	ClosePositions bool `json:"closePositions"`
+
+	// Reason is recorded in the audit log and the notification
+	Reason string `json:"reason,omitempty"`
+
+	// Source is where the halt is requested from, e.g., api or telegram
+	Source string `json:"source,omitempty"`
+}
+
+// HaltReport is the result of the emergency halt, the failures of the sessions are collected in Errors
+// instead of stopping the halt, so that the other sessions are still halted.
+type HaltReport struct {
+	Time time.Time `json:"time"`
+
+	SuspendedStrategies []string `json:"suspendedStrategies"`
+
+	// CanceledOrders is the number of the canceled orders of each session
+	CanceledOrders map[string]int `json:"canceledOrders"`
+
+	// ClosePositionOrders are the market orders submitted for closing the positions
+	ClosePositionOrders []types.Order `json:"closePositionOrders,omitempty"`
+
+	Errors []string `json:"errors,omitempty"`
+}
+
+func (r *HaltReport) addError(format string, args ...interface{}) {
+	err := fmt.Sprintf(format, args...)
+	log.Error("halt: " + err)
+	r.Errors = append(r.Errors, err)
+}
+
+// String formats the report for the notification and the command replies
+func (r *HaltReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("suspended strategies: %s\n", strings.Join(r.SuspendedStrategies, ", ")))
+
+	var sessions []string
+	for session := range r.CanceledOrders {
+		sessions = append(sessions, session)
+	}
+	sort.Strings(sessions)
+
+	for _, session := range sessions {
+		sb.WriteString(fmt.Sprintf("%s: %d orders canceled\n", session, r.CanceledOrders[session]))
+	}
+
+	for _, order := range r.ClosePositionOrders {
+		sb.WriteString(fmt.Sprintf("%s: position closed by %s %s %f\n", order.Exchange, order.Side, order.Symbol, order.Quantity))
+	}
+
+	for _, err := range r.Errors {
+		sb.WriteString("error: " + err + "\n")
+	}
+
+	return sb.String()
+}
+
+// Halt is the emergency kill switch, it suspends all the running strategies, so that their orders are rejected,
+// cancels all the open orders of the authenticated sessions, and closes the open positions with the market orders if
+// ClosePositions is set. The orders are submitted to the exchanges directly, bypassing the risk controls of the strategies.
+func (trader *Trader) Halt(ctx context.Context, options HaltOptions) *HaltReport {
+	report := &HaltReport{
+		Time:           time.Now(),
+		CanceledOrders: make(map[string]int),
+	}
+
+	for _, info := range trader.lifecycle.Strategies() {
+		if info.State != StrategyStateRunning {
+			continue
+		}
+
+		// the state is suspended even if the Suspend method of the strategy fails, so its orders are still rejected.
+		// the open orders are canceled below anyway, they should not be restored on resume.
+		if _, err := trader.lifecycle.SuspendWithPolicy(ctx, info.Name, SuspendOrderPolicyCancel); err != nil {
+			report.addError("%v", err)
+		}
+
+		report.SuspendedStrategies = append(report.SuspendedStrategies, info.Name)
+	}
+
+	environ := trader.environment
+
+	var sessionNames []string
+	for name := range environ.Sessions() {
+		sessionNames = append(sessionNames, name)
+	}
+	sort.Strings(sessionNames)
+
+	for _, name := range sessionNames {
+		session := environ.sessions[name]
+		if session.PublicOnly || !session.IsAvailable() {
+			continue
+		}
+
+		haltSession(ctx, session, options, report)
+	}
+
+	environ.Notify(":rotating_light: bbgo is halted by %s (%s)\n%s", options.Source, options.Reason, report.String())
+
+	if environ.AuditService != nil {
+		if err := environ.AuditService.Insert(service.AuditLog{
+			Action: service.AuditActionHalt,
+			Params: encodeAuditData(options),
+			Result: encodeAuditData(report),
+			Error:  strings.Join(report.Errors, "; "),
+			Time:   report.Time,
+		}); err != nil {
+			log.WithError(err).Error("can not insert the audit log of the halt")
+		}
+	}
+
+	return report
+}
+
+// haltSession cancels the open orders of the session and closes the positions if it's enabled
+func haltSession(ctx context.Context, session *ExchangeSession, options HaltOptions, report *HaltReport) {
+	var symbols []string
+	for symbol := range session.OrderStores() {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		openOrders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+		if err != nil {
+			report.addError("%s %s: can not query the open orders: %v", session.Name, symbol, err)
+			continue
+		}
+
+		if len(openOrders) == 0 {
+			continue
+		}
+
+		batch := types.ExchangeBatchProcessor{Exchange: session.Exchange}
+		if err := batch.BatchCancelOrders(ctx, openOrders...); err != nil {
+			report.addError("%s %s: can not cancel the open orders: %v", session.Name, symbol, err)
+			continue
+		}
+
+		report.CanceledOrders[session.Name] += len(openOrders)
+	}
+
+	if !options.ClosePositions {
+		return
+	}
+
+	closeOrders, err := session.closePositionOrders(ctx)
+	if err != nil {
+		report.addError("%s: %v", session.Name, err)
+		return
+	}
+
+	for _, order := range closeOrders {
+		createdOrders, err := session.Exchange.SubmitOrders(ctx, order)
+		session.audit(service.AuditActionSubmitOrders, order, createdOrders, err)
+		if err != nil {
+			report.addError("%s %s: can not close the position: %v", session.Name, order.Symbol, err)
+			continue
+		}
+
+		report.ClosePositionOrders = append(report.ClosePositionOrders, createdOrders...)
+	}
+}
+
+// closePositionOrders returns the market orders for closing the positions of the session, the futures positions are queried
+// from the exchange and closed with the reduce-only orders (the long and the short positions of the hedge mode are netted),
+// otherwise the positions of the traded symbols are closed. The dust positions below the min quantity are skipped.
+func (session *ExchangeSession) closePositionOrders(ctx context.Context) ([]types.SubmitOrder, error) {
+	var quantities = make(map[string]float64)
+	if session.Futures {
+		futuresExchange, ok := session.Exchange.(types.FuturesExchange)
+		if !ok {
+			return nil, fmt.Errorf("exchange %s does not support futures", session.ExchangeName)
+		}
+
+		positions, err := futuresExchange.QueryPositions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("can not query the futures positions: %w", err)
+		}
+
+		for _, p := range positions {
+			quantity := p.Quantity.Float64()
+			if p.PositionSide == types.PositionSideShort && quantity > 0 {
+				quantity = -quantity
+			}
+
+			quantities[p.Symbol] += quantity
+		}
+	} else {
+		for symbol, p := range session.Positions() {
+			quantities[symbol] = p.Base.Float64()
+		}
+	}
+
+	var symbols []string
+	for symbol := range quantities {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var orders []types.SubmitOrder
+	for _, symbol := range symbols {
+		base := quantities[symbol]
+		market, ok := session.Market(symbol)
+		if !ok {
+			continue
+		}
+
+		price, _ := session.LastPrice(symbol)
+		quantity := market.TruncateQuantity(math.Abs(base))
+		if market.IsDustQuantity(quantity, price) {
+			continue
+		}
+
+		side := types.SideTypeSell
+		if base < 0 {
+			side = types.SideTypeBuy
+		}
+
+		orders = append(orders, types.SubmitOrder{
+			Symbol:     symbol,
+			Market:     market,
+			Side:       side,
+			Type:       types.OrderTypeMarket,
+			Quantity:   quantity,
+			ReduceOnly: session.Futures,
+		})
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,59 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeSession_closePositionOrders(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001},
+		"ETHUSDT": {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", MinQuantity: 0.01, StepSize: 0.01},
+		"BNBUSDT": {Symbol: "BNBUSDT", BaseCurrency: "BNB", QuoteCurrency: "USDT", MinQuantity: 0.1, StepSize: 0.1},
+	}
+
+	session := &ExchangeSession{
+		Name:    "binance",
+		markets: markets,
+		positions: map[string]*Position{
+			"BTCUSDT": {Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(0.0125)},
+			"ETHUSDT": {Symbol: "ETHUSDT", Base: fixedpoint.NewFromFloat(-1.5)},
+			// dust position
+			"BNBUSDT": {Symbol: "BNBUSDT", Base: fixedpoint.NewFromFloat(0.05)},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 30000.0, "ETHUSDT": 1000.0, "BNBUSDT": 40.0},
+	}
+
+	orders, err := session.closePositionOrders(context.Background())
+	if !assert.NoError(t, err) || !assert.Len(t, orders, 2) {
+		return
+	}
+
+	assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+	assert.Equal(t, types.SideTypeSell, orders[0].Side)
+	assert.Equal(t, types.OrderTypeMarket, orders[0].Type)
+	assert.InDelta(t, 0.012, orders[0].Quantity, 1e-9)
+	assert.False(t, orders[0].ReduceOnly)
+
+	assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+	assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+	assert.InDelta(t, 1.5, orders[1].Quantity, 1e-9)
+}
+
+func TestHaltReport_String(t *testing.T) {
+	report := &HaltReport{
+		SuspendedStrategies: []string{"grid", "xarb"},
+		CanceledOrders:      map[string]int{"max": 2, "binance": 3},
+		Errors:              []string{"max BTCUSDT: can not cancel the open orders: timeout"},
+	}
+
+	assert.Equal(t, "suspended strategies: grid, xarb\n"+
+		"binance: 3 orders canceled\n"+
+		"max: 2 orders canceled\n"+
+		"error: max BTCUSDT: can not cancel the open orders: timeout\n", report.String())
+}
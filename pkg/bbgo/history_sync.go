@@ -0,0 +1,124 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultHistorySyncPeriod is the start time of the history sync when there is no stored record and no since date
+const defaultHistorySyncPeriod = 7 * 24 * time.Hour
+
+// historySyncSessions returns the authenticated sessions of the history sync
+func (environ *Environment) historySyncSessions(conf *SyncConfig) ([]*ExchangeSession, error) {
+	var names = conf.Sessions
+	if len(names) == 0 {
+		for name := range environ.sessions {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+	}
+
+	var sessions []*ExchangeSession
+	for _, name := range names {
+		session, ok := environ.sessions[name]
+		if !ok {
+			return nil, fmt.Errorf("session %s not found", name)
+		}
+
+		// the public only session and the paper trade session have no account history
+		if session.PublicOnly || session.PaperTrade {
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// historySyncSymbols returns the symbols of the history sync, defaults to the symbols traded in the session
+func historySyncSymbols(conf *SyncConfig, session *ExchangeSession) []string {
+	if session.IsolatedMargin {
+		return []string{session.IsolatedMarginSymbol}
+	}
+
+	if len(conf.Symbols) > 0 {
+		return conf.Symbols
+	}
+
+	var symbols []string
+	for symbol := range session.OrderStores() {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// SyncHistory pulls the trades and the orders of the sync config symbols, and the deposits and the withdraws
+// from the sessions into the database, the records are synchronized from the last stored records, and the
+// duplicated records are ignored (trades) or updated (orders, deposits and withdraws) by the unique indexes.
+func (environ *Environment) SyncHistory(ctx context.Context, conf *SyncConfig) error {
+	if environ.TradeSync == nil {
+		return errors.New("database is not configured")
+	}
+
+	startTime := time.Now().Add(-defaultHistorySyncPeriod)
+	if len(conf.Since) > 0 {
+		var err error
+		startTime, err = time.ParseInLocation("2006-01-02", conf.Since, time.Local)
+		if err != nil {
+			return err
+		}
+	}
+
+	sessions, err := environ.historySyncSessions(conf)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		for _, symbol := range historySyncSymbols(conf, session) {
+			if err := environ.TradeSync.Sync(ctx, session.Exchange, symbol, startTime); err != nil {
+				return fmt.Errorf("can not sync the %s trades and orders from session %s: %w", symbol, session.Name, err)
+			}
+		}
+
+		if err := environ.TradeSync.SyncTransfers(ctx, session.Exchange, startTime); err != nil {
+			return fmt.Errorf("can not sync the deposits and withdraws from session %s: %w", session.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// StartHistorySync runs the history sync with the trade sync interval of the sync config until the context is canceled
+func (environ *Environment) StartHistorySync(ctx context.Context, conf *SyncConfig) {
+	interval := conf.TradeSyncInterval.Duration()
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := environ.SyncHistory(ctx, conf); err != nil {
+				log.WithError(err).Errorf("history sync error")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
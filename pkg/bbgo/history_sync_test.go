@@ -0,0 +1,24 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistorySyncSymbols(t *testing.T) {
+	session := &ExchangeSession{
+		Name: "binance",
+		orderStores: map[string]*OrderStore{
+			"ETHUSDT": NewOrderStore("ETHUSDT"),
+			"BTCUSDT": NewOrderStore("BTCUSDT"),
+		},
+	}
+
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, historySyncSymbols(&SyncConfig{}, session))
+	assert.Equal(t, []string{"LINKUSDT"}, historySyncSymbols(&SyncConfig{Symbols: []string{"LINKUSDT"}}, session))
+
+	session.IsolatedMargin = true
+	session.IsolatedMarginSymbol = "BNBUSDT"
+	assert.Equal(t, []string{"BNBUSDT"}, historySyncSymbols(&SyncConfig{Symbols: []string{"LINKUSDT"}}, session))
+}
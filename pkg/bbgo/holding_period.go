@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrMinHoldingPeriod = errors.New("the order closes the position lots held shorter than the min holding period")
+
+// HoldingPeriod is the min holding period config, embed it in the strategy struct to enable the constraint,
+// the trader rejects the orders of the strategy that close the position lots held shorter than the period,
+// i.e., selling the asset bought within the period, or buying back the asset sold short within the period.
+// It's for the jurisdictions with the wash-sale-like rules or the exchanges penalizing the rapid self-churn.
+type HoldingPeriod struct {
+	MinHoldingPeriod types.Duration `json:"minHoldingPeriod,omitempty" yaml:"minHoldingPeriod,omitempty"`
+}
+
+func (h HoldingPeriod) HoldingPeriodSettings() HoldingPeriod {
+	return h
+}
+
+func (h HoldingPeriod) IsEnabled() bool {
+	return h.MinHoldingPeriod > 0
+}
+
+// HoldingPeriodStrategy is implemented by the strategies embedding the HoldingPeriod struct
+type HoldingPeriodStrategy interface {
+	HoldingPeriodSettings() HoldingPeriod
+}
+
+// HoldingPeriodOrderExecutor checks the orders against the lots of the position, the lots are closed in the FIFO order,
+// so an order is allowed only if its quantity can be covered by the lots held longer than the period.
+// The current time is the last kline time so that it works in the back-test as well, it falls back to the wall clock.
+type HoldingPeriodOrderExecutor struct {
+	OrderExecutor
+
+	Period   time.Duration
+	Position *Position
+
+	mu           sync.Mutex
+	lastDataTime time.Time
+}
+
+func NewHoldingPeriodOrderExecutor(executor OrderExecutor, period time.Duration, position *Position) *HoldingPeriodOrderExecutor {
+	return &HoldingPeriodOrderExecutor{
+		OrderExecutor: executor,
+		Period:        period,
+		Position:      position,
+	}
+}
+
+func (e *HoldingPeriodOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != e.Position.Symbol {
+			return
+		}
+
+		e.mu.Lock()
+		if kline.EndTime.After(e.lastDataTime) {
+			e.lastDataTime = kline.EndTime
+		}
+		e.mu.Unlock()
+	})
+}
+
+func (e *HoldingPeriodOrderExecutor) now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastDataTime.IsZero() {
+		return time.Now()
+	}
+
+	return e.lastDataTime
+}
+
+// Check returns an error if any of the orders closes the lots held shorter than the period,
+// the closing orders in the same batch are accumulated.
+func (e *HoldingPeriodOrderExecutor) Check(orders ...types.SubmitOrder) error {
+	var closingSide types.SideType
+	switch {
+	case e.Position.Base > 0:
+		closingSide = types.SideTypeSell
+	case e.Position.Base < 0:
+		closingSide = types.SideTypeBuy
+	default:
+		return nil
+	}
+
+	heldQuantity := e.Position.HeldQuantity(e.Period, e.now())
+
+	var closingQuantity fixedpoint.Value
+	for _, order := range orders {
+		if order.Symbol != e.Position.Symbol || order.Side != closingSide {
+			continue
+		}
+
+		closingQuantity += fixedpoint.NewFromFloat(order.Quantity)
+		if closingQuantity > heldQuantity {
+			return fmt.Errorf("%w: %s %s %f, only %f %s is held longer than %s",
+				ErrMinHoldingPeriod, order.Symbol, order.Side, order.Quantity, heldQuantity.Float64(), e.Position.BaseCurrency, e.Period)
+		}
+	}
+
+	return nil
+}
+
+func (e *HoldingPeriodOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if err := e.Check(orders...); err != nil {
+		return nil, err
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, orders...)
+}
+
+// wrapHoldingPeriodOrderExecutor wraps the order executor with the min holding period constraint if the strategy enables it
+func wrapHoldingPeriodOrderExecutor(strategy SingleExchangeStrategy, session *ExchangeSession, executor OrderExecutor) OrderExecutor {
+	holdingPeriodStrategy, ok := strategy.(HoldingPeriodStrategy)
+	if !ok {
+		return executor
+	}
+
+	settings := holdingPeriodStrategy.HoldingPeriodSettings()
+	if !settings.IsEnabled() {
+		return executor
+	}
+
+	var symbol string
+	if rs := reflect.ValueOf(strategy); rs.Kind() == reflect.Ptr && rs.Elem().Kind() == reflect.Struct {
+		symbol, _ = isSymbolBasedStrategy(rs.Elem())
+	}
+
+	position, ok := session.Position(symbol)
+	if !ok {
+		log.Warnf("strategy %s enables the min holding period, but the position of symbol %q is not found, the constraint is disabled", strategy.ID(), symbol)
+		return executor
+	}
+
+	gate := NewHoldingPeriodOrderExecutor(executor, settings.MinHoldingPeriod.Duration(), position)
+	gate.BindStream(session.Stream)
+
+	log.Infof("strategy %s is constrained by the min holding period %s", strategy.ID(), settings.MinHoldingPeriod.Duration())
+	return gate
+}
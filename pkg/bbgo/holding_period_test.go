@@ -0,0 +1,56 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestHoldingPeriodOrderExecutor(t *testing.T) {
+	now := time.Now()
+	position := &Position{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1000.0, Quantity: 1.0, QuoteQuantity: 1000.0, Time: now.Add(-time.Hour)})
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1100.0, Quantity: 2.0, QuoteQuantity: 2200.0, Time: now.Add(-5 * time.Minute)})
+
+	executor := NewHoldingPeriodOrderExecutor(&recordingOrderExecutor{}, 30*time.Minute, position)
+
+	sell := func(quantity float64) types.SubmitOrder {
+		return types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Quantity: quantity}
+	}
+
+	// the first lot is held for an hour
+	_, err := executor.SubmitOrders(context.Background(), sell(1.0))
+	assert.NoError(t, err)
+
+	// the second lot is bought 5 minutes ago
+	_, err = executor.SubmitOrders(context.Background(), sell(1.5))
+	assert.True(t, errors.Is(err, ErrMinHoldingPeriod))
+
+	// the closing orders in the same batch are accumulated
+	_, err = executor.SubmitOrders(context.Background(), sell(0.5), sell(0.6))
+	assert.True(t, errors.Is(err, ErrMinHoldingPeriod))
+
+	// adding to the position is always allowed
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 1.0})
+	assert.NoError(t, err)
+
+	// the first lot is closed, the rest are held for 5 minutes
+	position.AddTrade(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 1200.0, Quantity: 1.5, QuoteQuantity: 1800.0, Time: now})
+	if assert.Len(t, position.Lots, 1) {
+		assert.Equal(t, fixedpoint.NewFromFloat(1.5), position.Lots[0].Quantity)
+	}
+
+	_, err = executor.SubmitOrders(context.Background(), sell(0.1))
+	assert.True(t, errors.Is(err, ErrMinHoldingPeriod))
+
+	// the kline time is used as the current time
+	executor.lastDataTime = now.Add(30 * time.Minute)
+	_, err = executor.SubmitOrders(context.Background(), sell(1.5))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+package bbgo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// InfluxDBExporter exports the market prices, the standard indicator values, the positions and the realized profits of the sessions
+// into InfluxDB, so that you can build your own dashboard from the line-protocol data.
+type InfluxDBExporter struct {
+	Service *service.InfluxDBService
+}
+
+func NewInfluxDBExporter(s *service.InfluxDBService) *InfluxDBExporter {
+	return &InfluxDBExporter{Service: s}
+}
+
+func (e *InfluxDBExporter) BindSession(session *ExchangeSession) {
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		points := e.collectPoints(session, kline)
+		go e.write(points...)
+	})
+}
+
+func (e *InfluxDBExporter) write(points ...service.Point) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := e.Service.Write(ctx, points...); err != nil {
+		log.WithError(err).Errorf("influxdb write error")
+	}
+}
+
+func (e *InfluxDBExporter) collectPoints(session *ExchangeSession, kline types.KLine) (points []service.Point) {
+	tags := map[string]string{
+		"exchange": session.Exchange.Name().String(),
+		"session":  session.Name,
+		"symbol":   kline.Symbol,
+	}
+
+	points = append(points, service.Point{
+		Measurement: "kline",
+		Tags:        withTags(tags, map[string]string{"interval": string(kline.Interval)}),
+		Fields: map[string]interface{}{
+			"open":   kline.Open,
+			"high":   kline.High,
+			"low":    kline.Low,
+			"close":  kline.Close,
+			"volume": kline.Volume,
+		},
+		Time: kline.EndTime,
+	})
+
+	if set, ok := session.StandardIndicatorSet(kline.Symbol); ok {
+		for iw, inc := range set.sma {
+			if iw.Interval != kline.Interval {
+				continue
+			}
+
+			if value, ok := inc.Last(); ok {
+				points = append(points, indicatorPoint(tags, "sma", iw, value, kline.EndTime))
+			}
+		}
+
+		for iw, inc := range set.ewma {
+			if iw.Interval != kline.Interval {
+				continue
+			}
+
+			if value, ok := inc.Last(); ok {
+				points = append(points, indicatorPoint(tags, "ewma", iw, value, kline.EndTime))
+			}
+		}
+	}
+
+	// positions are only updated on the minimal interval to avoid duplicated points
+	if kline.Interval != types.Interval1m {
+		return points
+	}
+
+	if position, ok := session.Position(kline.Symbol); ok {
+		base := position.Base.Float64()
+		averageCost := position.AverageCost.Float64()
+		fields := map[string]interface{}{
+			"base":             base,
+			"quote":            position.Quote.Float64(),
+			"averageCost":      averageCost,
+			"unrealizedProfit": (kline.Close - averageCost) * base,
+		}
+
+		if stats, ok := session.ProfitStats(kline.Symbol); ok {
+			fields["realizedProfit"] = stats.RealizedProfit.Float64()
+			fields["grossProfit"] = stats.GrossProfit.Float64()
+			fields["grossLoss"] = stats.GrossLoss.Float64()
+		}
+
+		points = append(points, service.Point{
+			Measurement: "position",
+			Tags:        tags,
+			Fields:      fields,
+			Time:        kline.EndTime,
+		})
+	}
+
+	return points
+}
+
+func indicatorPoint(tags map[string]string, name string, iw types.IntervalWindow, value float64, t time.Time) service.Point {
+	return service.Point{
+		Measurement: "indicator",
+		Tags: withTags(tags, map[string]string{
+			"indicator": name,
+			"interval":  string(iw.Interval),
+			"window":    strconv.Itoa(iw.Window),
+		}),
+		Fields: map[string]interface{}{
+			"value": value,
+		},
+		Time: t,
+	}
+}
+
+func withTags(tags map[string]string, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(tags)+len(extra))
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	for k, v := range extra {
+		merged[k] = v
+	}
+
+	return merged
+}
@@ -0,0 +1,128 @@
+package bbgo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultInventoryTargetRatio = 0.5
+
+// InventorySkew skews the quotes of a market by the inventory imbalance against the target base value ratio,
+// so that the market-making strategies sell more and buy less when they hold too much base asset, and vice versa.
+//
+// The imbalance is normalized to [-1, 1]: 1 means all the value is in the base asset, -1 means all the value is in the quote asset,
+// and 0 means the base value ratio is at the target.
+type InventorySkew struct {
+	// TargetRatio is the target ratio of the base asset value to the total value of the market, defaults to 0.5
+	TargetRatio fixedpoint.Value `json:"targetRatio,omitempty" yaml:"targetRatio,omitempty"`
+
+	// QuantitySkew is the quantity bias at the max imbalance, with 1.0 and the imbalance 0.5,
+	// the bid quantity is reduced by 50% and the ask quantity is increased by 50%
+	QuantitySkew fixedpoint.Value `json:"quantitySkew,omitempty" yaml:"quantitySkew,omitempty"`
+
+	// PriceSkew is the price offset ratio at the max imbalance, both the bid price and the ask price are shifted by
+	// -PriceSkew * imbalance, e.g., 0.001 lowers the quotes by 0.1% when all the value is in the base asset
+	PriceSkew fixedpoint.Value `json:"priceSkew,omitempty" yaml:"priceSkew,omitempty"`
+}
+
+func (s *InventorySkew) Validate() error {
+	if s.TargetRatio < 0 || s.TargetRatio >= fixedpoint.NewFromFloat(1.0) {
+		return fmt.Errorf("inventory target ratio %f should be in (0, 1), or zero for the default 0.5", s.TargetRatio.Float64())
+	}
+
+	if s.QuantitySkew < 0 || s.PriceSkew < 0 {
+		return fmt.Errorf("inventory skews should not be negative")
+	}
+
+	return nil
+}
+
+func (s *InventorySkew) targetRatio() float64 {
+	if s.TargetRatio > 0 {
+		return s.TargetRatio.Float64()
+	}
+	return defaultInventoryTargetRatio
+}
+
+// Imbalance returns the normalized inventory imbalance of the market at the given price,
+// the locked balances are counted since they are still in the inventory until the orders are filled.
+func (s *InventorySkew) Imbalance(market types.Market, balances types.BalanceMap, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+
+	var baseValue, quoteValue float64
+	if b, ok := balances[market.BaseCurrency]; ok {
+		baseValue = (b.Available + b.Locked).Float64() * price
+	}
+
+	if b, ok := balances[market.QuoteCurrency]; ok {
+		quoteValue = (b.Available + b.Locked).Float64()
+	}
+
+	total := baseValue + quoteValue
+	if total <= 0 {
+		return 0
+	}
+
+	target := s.targetRatio()
+	ratio := baseValue / total
+	if ratio > target {
+		return (ratio - target) / (1.0 - target)
+	}
+
+	return (ratio - target) / target
+}
+
+// Quote returns the quote skew of the imbalance
+func (s *InventorySkew) Quote(imbalance float64) QuoteSkew {
+	imbalance = math.Max(-1.0, math.Min(1.0, imbalance))
+	quantityBias := math.Max(-1.0, math.Min(1.0, s.QuantitySkew.Float64()*imbalance))
+
+	return QuoteSkew{
+		Imbalance:        imbalance,
+		BidQuantityScale: 1.0 - quantityBias,
+		AskQuantityScale: 1.0 + quantityBias,
+		PriceOffsetRatio: -s.PriceSkew.Float64() * imbalance,
+	}
+}
+
+// QuoteOf is a shortcut of Quote(Imbalance(market, balances, price))
+func (s *InventorySkew) QuoteOf(market types.Market, balances types.BalanceMap, price float64) QuoteSkew {
+	return s.Quote(s.Imbalance(market, balances, price))
+}
+
+// QuoteSkew is the quantity scales and the price offset of the quotes, the zero value is not a valid skew, use NoSkew instead
+type QuoteSkew struct {
+	Imbalance float64
+
+	// BidQuantityScale and AskQuantityScale are in [0, 2]
+	BidQuantityScale float64
+	AskQuantityScale float64
+
+	// PriceOffsetRatio is the ratio added to both the bid price and the ask price
+	PriceOffsetRatio float64
+}
+
+// NoSkew keeps the quotes unchanged
+var NoSkew = QuoteSkew{BidQuantityScale: 1.0, AskQuantityScale: 1.0}
+
+// Quantity scales the quantity of the side
+func (q QuoteSkew) Quantity(side types.SideType, quantity float64) float64 {
+	switch side {
+	case types.SideTypeBuy:
+		return quantity * q.BidQuantityScale
+	case types.SideTypeSell:
+		return quantity * q.AskQuantityScale
+	}
+
+	return quantity
+}
+
+// Price shifts the quote price by the price offset ratio
+func (q QuoteSkew) Price(price float64) float64 {
+	return price * (1.0 + q.PriceOffsetRatio)
+}
@@ -0,0 +1,46 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestInventorySkew(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	skew := &InventorySkew{
+		QuantitySkew: fixedpoint.NewFromFloat(1.0),
+		PriceSkew:    fixedpoint.NewFromFloat(0.01),
+	}
+	assert.NoError(t, skew.Validate())
+
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5), Locked: fixedpoint.NewFromFloat(0.5)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	}
+
+	// balanced at the target ratio
+	q := skew.QuoteOf(market, balances, 10000.0)
+	assert.InDelta(t, 0.0, q.Imbalance, 1e-9)
+	assert.InDelta(t, 1.0, q.Quantity(types.SideTypeBuy, 1.0), 1e-9)
+	assert.InDelta(t, 100.0, q.Price(100.0), 1e-9)
+
+	// 75% in the base asset: the imbalance is half way to all in the base asset
+	q = skew.QuoteOf(market, balances, 30000.0)
+	assert.InDelta(t, 0.5, q.Imbalance, 1e-9)
+	assert.InDelta(t, 0.5, q.Quantity(types.SideTypeBuy, 1.0), 1e-9)
+	assert.InDelta(t, 1.5, q.Quantity(types.SideTypeSell, 1.0), 1e-9)
+	assert.InDelta(t, 99.5, q.Price(100.0), 1e-9)
+
+	// all in the quote asset
+	q = skew.QuoteOf(market, types.BalanceMap{"USDT": balances["USDT"]}, 10000.0)
+	assert.InDelta(t, -1.0, q.Imbalance, 1e-9)
+	assert.InDelta(t, 2.0, q.Quantity(types.SideTypeBuy, 1.0), 1e-9)
+	assert.InDelta(t, 0.0, q.Quantity(types.SideTypeSell, 1.0), 1e-9)
+	assert.InDelta(t, 101.0, q.Price(100.0), 1e-9)
+
+	assert.Error(t, (&InventorySkew{TargetRatio: fixedpoint.NewFromFloat(1.0)}).Validate())
+}
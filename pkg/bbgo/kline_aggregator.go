@@ -0,0 +1,98 @@
+package bbgo
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// KLineAggregator builds the klines of a higher interval from the 1m klines, for the exchanges or the back-test data
+// providing only the 1m klines. The buckets are aligned to the UTC time, e.g., the 4h klines start at 00:00, 04:00, ...
+type KLineAggregator struct {
+	Interval types.Interval
+
+	pending *types.KLine
+}
+
+func NewKLineAggregator(interval types.Interval) *KLineAggregator {
+	return &KLineAggregator{Interval: interval}
+}
+
+// Add adds the closed 1m kline, and returns the aggregated klines closed by it. The aggregated kline is closed by the
+// last 1m kline of its bucket, and the pending kline is flushed when the 1m kline of the next bucket arrives,
+// so that the klines with the missing 1m data are still emitted.
+func (a *KLineAggregator) Add(kline types.KLine) (closed []types.KLine) {
+	if kline.Interval != types.Interval1m {
+		return nil
+	}
+
+	duration := a.Interval.Duration()
+	startTime := kline.StartTime.Truncate(duration)
+
+	if a.pending != nil {
+		if kline.StartTime.Before(a.pending.StartTime) {
+			// the out-of-order kline is ignored
+			return nil
+		}
+
+		if !startTime.Equal(a.pending.StartTime) {
+			closed = append(closed, a.flush())
+		}
+	}
+
+	if a.pending == nil {
+		a.pending = &types.KLine{
+			Exchange:  kline.Exchange,
+			Symbol:    kline.Symbol,
+			StartTime: startTime,
+			Interval:  a.Interval,
+			Open:      kline.Open,
+			High:      kline.High,
+			Low:       kline.Low,
+		}
+	}
+
+	k := a.pending
+	k.EndTime = kline.EndTime
+	k.Close = kline.Close
+	k.High = math.Max(k.High, kline.High)
+	k.Low = math.Min(k.Low, kline.Low)
+	k.Volume += kline.Volume
+	k.QuoteVolume += kline.QuoteVolume
+	k.NumberOfTrades += kline.NumberOfTrades
+	if kline.LastTradeID > 0 {
+		k.LastTradeID = kline.LastTradeID
+	}
+
+	// the 1m kline is the last one of the bucket
+	if !kline.StartTime.Add(kline.Interval.Duration()).Before(startTime.Add(duration)) {
+		closed = append(closed, a.flush())
+	}
+
+	return closed
+}
+
+func (a *KLineAggregator) flush() types.KLine {
+	k := *a.pending
+	k.Closed = true
+	a.pending = nil
+	return k
+}
+
+// ValidateKLineAggregation checks that the intervals can be aggregated from the 1m klines
+func ValidateKLineAggregation(intervals []types.Interval) error {
+	for _, interval := range intervals {
+		minutes, ok := types.SupportedIntervals[interval]
+		if !ok {
+			return fmt.Errorf("kline aggregation interval %s is not supported", interval)
+		}
+
+		if minutes <= 1 || time.Duration(minutes)*time.Minute > 24*time.Hour {
+			return fmt.Errorf("kline aggregation interval %s should be longer than 1m and at most 1d", interval)
+		}
+	}
+
+	return nil
+}
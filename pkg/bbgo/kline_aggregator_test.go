@@ -0,0 +1,99 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func minuteKLine(startTime time.Time, open, high, low, close, volume float64) types.KLine {
+	return types.KLine{
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1m,
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Minute - time.Millisecond),
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Closed:    true,
+	}
+}
+
+func TestKLineAggregator_Add(t *testing.T) {
+	aggregator := NewKLineAggregator(types.Interval5m)
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var closed []types.KLine
+	for i := 0; i < 5; i++ {
+		price := float64(100 + i)
+		closed = append(closed, aggregator.Add(minuteKLine(startTime.Add(time.Duration(i)*time.Minute), price, price+2, price-1, price+1, 1.0))...)
+	}
+
+	if assert.Len(t, closed, 1) {
+		k := closed[0]
+		assert.Equal(t, types.Interval5m, k.Interval)
+		assert.Equal(t, startTime, k.StartTime)
+		assert.Equal(t, startTime.Add(5*time.Minute-time.Millisecond), k.EndTime)
+		assert.Equal(t, 100.0, k.Open)
+		assert.Equal(t, 106.0, k.High)
+		assert.Equal(t, 99.0, k.Low)
+		assert.Equal(t, 105.0, k.Close)
+		assert.Equal(t, 5.0, k.Volume)
+		assert.True(t, k.Closed)
+	}
+
+	// the 00:08 and 00:09 klines are missing, the pending kline is flushed by the next bucket
+	closed = nil
+	for _, minute := range []int{5, 6, 7, 10} {
+		closed = append(closed, aggregator.Add(minuteKLine(startTime.Add(time.Duration(minute)*time.Minute), 100, 101, 99, 100, 1.0))...)
+	}
+
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, startTime.Add(5*time.Minute), closed[0].StartTime)
+		assert.Equal(t, 3.0, closed[0].Volume)
+	}
+
+	// the klines of the other intervals are ignored
+	assert.Len(t, aggregator.Add(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval5m}), 0)
+}
+
+func TestMarketDataStore_AggregateKLines(t *testing.T) {
+	store := NewMarketDataStore("BTCUSDT")
+	store.AggregateKLines(types.Interval15m, types.Interval5m)
+
+	var intervals []types.Interval
+	store.OnKLineClosed(func(kline types.KLine) {
+		intervals = append(intervals, kline.Interval)
+	})
+
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 15; i++ {
+		store.AddKLine(minuteKLine(startTime.Add(time.Duration(i)*time.Minute), 100, 101, 99, 100, 1.0))
+	}
+
+	// the native klines of the aggregated intervals are skipped
+	store.handleKLineClosed(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval5m, StartTime: startTime})
+
+	window, ok := store.KLinesOfInterval(types.Interval5m)
+	if assert.True(t, ok) {
+		assert.Len(t, window, 3)
+	}
+
+	window, ok = store.KLinesOfInterval(types.Interval15m)
+	if assert.True(t, ok) {
+		assert.Len(t, window, 1)
+		assert.Equal(t, 15.0, window.Last().Volume)
+	}
+
+	// the lower interval is emitted first
+	assert.Equal(t, []types.Interval{types.Interval5m, types.Interval15m}, intervals[len(intervals)-2:])
+
+	assert.Error(t, ValidateKLineAggregation([]types.Interval{types.Interval1m}))
+	assert.Error(t, ValidateKLineAggregation([]types.Interval{types.Interval3d}))
+	assert.NoError(t, ValidateKLineAggregation([]types.Interval{types.Interval5m, types.Interval4h, types.Interval1d}))
+}
@@ -0,0 +1,35 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// LogLatencyReport logs the event latency percentiles of each channel of the session stream
+func (session *ExchangeSession) LogLatencyReport() {
+	provider, ok := session.Stream.(types.LatencyProvider)
+	if !ok {
+		return
+	}
+
+	for _, report := range provider.Latency().Report() {
+		session.logger.Infof("latency: %s", report.String())
+	}
+}
+
+func (session *ExchangeSession) reportLatency(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			session.LogLatencyReport()
+		}
+	}
+}
@@ -0,0 +1,797 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultHealthCheckInterval = 30 * time.Second
+
+// stopStrategyTimeout is the max waiting time of stopping a dependent strategy
+const stopStrategyTimeout = 30 * time.Second
+
+// DefaultSuspendTimeout is the max waiting time of suspending or resuming a strategy from the control APIs,
+// e.g., the grid cancels or restores its orders
+const DefaultSuspendTimeout = 30 * time.Second
+
+var ErrStrategyStopped = errors.New("strategy is stopped by the lifecycle manager")
+
+var ErrStrategySuspended = errors.New("strategy is suspended")
+
+var ErrStrategyPaused = errors.New("strategy is paused")
+
+// StrategyLifecycle is the lifecycle config of a strategy instance, set by the name and the dependsOn keys of the strategy config entry
+type StrategyLifecycle struct {
+	// Name is the instance name referenced by the dependsOn of the other strategies, defaults to the strategy ID
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// DependsOn are the names of the strategies that must be running before this strategy starts,
+	// this strategy is stopped when any of them fails.
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+
+	// OnSuspend is the policy of the open orders when the strategy is suspended, defaults to "cancel"
+	OnSuspend SuspendOrderPolicy `json:"onSuspend,omitempty" yaml:"onSuspend,omitempty"`
+
+	Strategy interface{} `json:"-" yaml:"-"`
+}
+
+// HealthChecker is implemented by the strategies that can check their own health, e.g., the hedger checks the hedging session,
+// the strategy is marked as failed when the check returns an error, and the strategies depending on it are stopped.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// StoppableStrategy is implemented by the strategies that can be stopped individually, e.g., cancel the open orders.
+// The orders submitted by a stopped strategy are always rejected, no matter it implements this interface or not.
+type StoppableStrategy interface {
+	Stop(ctx context.Context) error
+}
+
+// SuspendOrderPolicy decides what happens to the open orders of the strategy when it's suspended
+type SuspendOrderPolicy string
+
+const (
+	// SuspendOrderPolicyKeep keeps the open orders on the order book, the strategy stops placing the new orders
+	SuspendOrderPolicyKeep SuspendOrderPolicy = "keep"
+
+	// SuspendOrderPolicyCancel cancels the open orders, the strategy places its orders from the current price on resume
+	SuspendOrderPolicyCancel SuspendOrderPolicy = "cancel"
+
+	// SuspendOrderPolicyCancelAndRestore cancels the open orders and submits the same orders again on resume
+	SuspendOrderPolicyCancelAndRestore SuspendOrderPolicy = "cancelAndRestore"
+)
+
+func (p SuspendOrderPolicy) Validate() error {
+	switch p {
+	case "", SuspendOrderPolicyKeep, SuspendOrderPolicyCancel, SuspendOrderPolicyCancelAndRestore:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported suspend order policy %q, it should be one of keep, cancel or cancelAndRestore", p)
+}
+
+// SuspendableStrategy is implemented by the strategies that can be suspended and resumed at runtime, e.g., from the gRPC API.
+// The orders submitted by a suspended strategy are always rejected, no matter it implements this interface or not,
+// and the open orders are handled by the strategy with the given policy.
+type SuspendableStrategy interface {
+	Suspend(ctx context.Context, policy SuspendOrderPolicy) error
+	Resume(ctx context.Context) error
+}
+
+// RuntimeParameterUpdater is implemented by the strategies that accept the parameter changes at runtime,
+// the parameters are a JSON object with the same keys as the strategy config, e.g., {"gridNumber": 20}
+type RuntimeParameterUpdater interface {
+	UpdateParameters(ctx context.Context, parameters []byte) error
+}
+
+type StrategyState string
+
+const (
+	StrategyStatePending StrategyState = "pending"
+	StrategyStateRunning StrategyState = "running"
+	StrategyStateFailed  StrategyState = "failed"
+	StrategyStateStopped StrategyState = "stopped"
+
+	StrategyStateSuspended StrategyState = "suspended"
+)
+
+// StrategyInfo is the lifecycle state of a declared strategy
+type StrategyInfo struct {
+	Name      string
+	ID        string
+	DependsOn []string
+	State     StrategyState
+	Error     error
+	Strategy  interface{}
+
+	// Paused is true if the orders of the strategy are blocked by the pause flag
+	Paused bool
+
+	// SuspendOrderPolicy is the policy of the open orders when the strategy is suspended
+	SuspendOrderPolicy SuspendOrderPolicy
+}
+
+// Parameters returns the current strategy config in JSON
+func (info StrategyInfo) Parameters() ([]byte, error) {
+	return json.Marshal(info.Strategy)
+}
+
+type strategyNode struct {
+	name      string
+	strategy  interface{}
+	dependsOn []string
+
+	state StrategyState
+	err   error
+
+	// paused blocks the orders of the strategy, it's orthogonal to the state and persisted across restarts
+	paused bool
+
+	suspendPolicy SuspendOrderPolicy
+}
+
+func (node *strategyNode) info() StrategyInfo {
+	return StrategyInfo{
+		Name:      node.name,
+		ID:        strategyID(node.strategy),
+		DependsOn: node.dependsOn,
+		State:     node.state,
+		Error:     node.err,
+		Strategy:  node.strategy,
+		Paused:    node.paused,
+
+		SuspendOrderPolicy: node.suspendPolicy,
+	}
+}
+
+// LifecycleManager starts the strategies in the dependency order, checks the health of the running strategies,
+// and stops the dependents (and their dependents) when a strategy fails.
+type LifecycleManager struct {
+	Notifiability *Notifiability
+
+	HealthCheckInterval time.Duration
+
+	mu         sync.Mutex
+	nodes      []*strategyNode
+	byStrategy map[interface{}]*strategyNode
+
+	// pauseStore persists the pause flags, the flags are kept in memory only if it's nil
+	pauseStore Store
+
+	// pausedNames are the names of the paused strategies, including the ones not declared yet
+	pausedNames map[string]bool
+}
+
+func NewLifecycleManager(notifiability *Notifiability) *LifecycleManager {
+	return &LifecycleManager{
+		Notifiability:       notifiability,
+		HealthCheckInterval: defaultHealthCheckInterval,
+		byStrategy:          make(map[interface{}]*strategyNode),
+		pausedNames:         make(map[string]bool),
+	}
+}
+
+// Declare names the strategy instance and declares the strategies it depends on, the name defaults to the strategy ID
+func (m *LifecycleManager) Declare(name string, strategy interface{}, dependsOn ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(name) == 0 {
+		name = strategyID(strategy)
+	}
+
+	if node, ok := m.byStrategy[strategy]; ok {
+		node.name = name
+		node.dependsOn = dependsOn
+		node.paused = m.pausedNames[name]
+		return
+	}
+
+	node := &strategyNode{
+		name:          name,
+		strategy:      strategy,
+		dependsOn:     dependsOn,
+		state:         StrategyStatePending,
+		paused:        m.pausedNames[name],
+		suspendPolicy: SuspendOrderPolicyCancel,
+	}
+
+	m.nodes = append(m.nodes, node)
+	m.byStrategy[strategy] = node
+}
+
+// SetSuspendOrderPolicy sets the policy of the open orders when the declared strategy is suspended
+func (m *LifecycleManager) SetSuspendOrderPolicy(strategy interface{}, policy SuspendOrderPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.byStrategy[strategy]
+	if !ok {
+		return fmt.Errorf("strategy %T is not declared", strategy)
+	}
+
+	if len(policy) > 0 {
+		node.suspendPolicy = policy
+	}
+
+	return nil
+}
+
+func (m *LifecycleManager) declared(strategy interface{}) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.byStrategy[strategy]
+	return ok
+}
+
+// nameOf returns the declared name of the strategy instance, it defaults to the strategy ID
+func (m *LifecycleManager) nameOf(strategy interface{}) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if node, ok := m.byStrategy[strategy]; ok {
+		return node.name
+	}
+
+	return strategyID(strategy)
+}
+
+// Sort validates the dependencies and returns the strategies in the start order,
+// the strategies without the dependencies keep their original order.
+func (m *LifecycleManager) Sort(strategies []interface{}) ([]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var byName = make(map[string][]*strategyNode)
+	for _, node := range m.nodes {
+		byName[node.name] = append(byName[node.name], node)
+	}
+
+	for _, node := range m.nodes {
+		for _, dep := range node.dependsOn {
+			switch len(byName[dep]) {
+			case 0:
+				return nil, fmt.Errorf("strategy %s depends on %s, but %s is not found", node.name, dep, dep)
+			case 1:
+			default:
+				return nil, fmt.Errorf("strategy %s depends on %s, but there are %d strategies named %s, please set the name of the strategies", node.name, dep, len(byName[dep]), dep)
+			}
+		}
+	}
+
+	var sorted []interface{}
+	var visited = make(map[*strategyNode]bool)
+	var visiting = make(map[*strategyNode]bool)
+
+	var visit func(node *strategyNode, path []string) error
+	visit = func(node *strategyNode, path []string) error {
+		if visited[node] {
+			return nil
+		}
+
+		path = append(path, node.name)
+		if visiting[node] {
+			return fmt.Errorf("circular strategy dependency: %s", strings.Join(path, " -> "))
+		}
+
+		visiting[node] = true
+		for _, dep := range node.dependsOn {
+			if err := visit(byName[dep][0], path); err != nil {
+				return err
+			}
+		}
+		visiting[node] = false
+
+		visited[node] = true
+		sorted = append(sorted, node.strategy)
+		return nil
+	}
+
+	for _, strategy := range strategies {
+		node, ok := m.byStrategy[strategy]
+		if !ok {
+			return nil, fmt.Errorf("strategy %T is not declared", strategy)
+		}
+
+		if err := visit(node, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// CanStart returns an error if any of the dependencies is not running
+func (m *LifecycleManager) CanStart(strategy interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.byStrategy[strategy]
+	if !ok {
+		return nil
+	}
+
+	for _, dep := range node.dependsOn {
+		for _, depNode := range m.nodes {
+			if depNode.name == dep && depNode.state != StrategyStateRunning {
+				return fmt.Errorf("strategy %s can not start, the dependency %s is %s", node.name, dep, depNode.state)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Started marks the strategy as running after its Run method returns without error
+func (m *LifecycleManager) Started(strategy interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if node, ok := m.byStrategy[strategy]; ok && node.state == StrategyStatePending {
+		node.state = StrategyStateRunning
+	}
+}
+
+// IsStopped returns true if the strategy is failed or stopped
+func (m *LifecycleManager) IsStopped(strategy interface{}) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.byStrategy[strategy]
+	return ok && (node.state == StrategyStateFailed || node.state == StrategyStateStopped)
+}
+
+// checkOrderSubmission returns the error for rejecting the orders of the stopped and the suspended strategies
+func (m *LifecycleManager) checkOrderSubmission(strategy interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.byStrategy[strategy]
+	if !ok {
+		return nil
+	}
+
+	switch node.state {
+	case StrategyStateFailed, StrategyStateStopped:
+		return ErrStrategyStopped
+	case StrategyStateSuspended:
+		return ErrStrategySuspended
+	}
+
+	if node.paused {
+		return ErrStrategyPaused
+	}
+
+	return nil
+}
+
+// States returns the states of the declared strategies keyed by the strategy name
+func (m *LifecycleManager) States() map[string]StrategyState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	states := make(map[string]StrategyState, len(m.nodes))
+	for _, node := range m.nodes {
+		states[node.name] = node.state
+	}
+
+	return states
+}
+
+// Strategies returns the declared strategies in the declaration order
+func (m *LifecycleManager) Strategies() []StrategyInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var infos []StrategyInfo
+	for _, node := range m.nodes {
+		infos = append(infos, node.info())
+	}
+
+	return infos
+}
+
+// Lookup finds the declared strategy by its name
+func (m *LifecycleManager) Lookup(name string) (StrategyInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, err := m.lookup(name)
+	if err != nil {
+		return StrategyInfo{}, err
+	}
+
+	return node.info(), nil
+}
+
+func (m *LifecycleManager) lookup(name string) (*strategyNode, error) {
+	var found *strategyNode
+	for _, node := range m.nodes {
+		if node.name != name {
+			continue
+		}
+
+		if found != nil {
+			return nil, fmt.Errorf("there are more than one strategies named %s, please set the name of the strategies", name)
+		}
+
+		found = node
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("strategy %s is not found", name)
+	}
+
+	return found, nil
+}
+
+// Suspend rejects the orders of the running strategy until it's resumed, the strategies implementing SuspendableStrategy
+// are suspended as well with the suspend order policy of the strategy, e.g., the grid cancels its orders by default.
+func (m *LifecycleManager) Suspend(ctx context.Context, name string) (StrategyInfo, error) {
+	return m.SuspendWithPolicy(ctx, name, "")
+}
+
+// SuspendWithPolicy suspends the strategy with the given suspend order policy instead of the policy of the strategy,
+// the policy of the strategy is used if the given policy is empty.
+func (m *LifecycleManager) SuspendWithPolicy(ctx context.Context, name string, policy SuspendOrderPolicy) (StrategyInfo, error) {
+	if err := policy.Validate(); err != nil {
+		return StrategyInfo{}, err
+	}
+
+	m.mu.Lock()
+	node, err := m.lookup(name)
+	if err != nil {
+		m.mu.Unlock()
+		return StrategyInfo{}, err
+	}
+
+	if node.state != StrategyStateRunning {
+		m.mu.Unlock()
+		return node.info(), fmt.Errorf("strategy %s can not be suspended, it's %s", name, node.state)
+	}
+
+	if len(policy) == 0 {
+		policy = node.suspendPolicy
+	}
+
+	node.state = StrategyStateSuspended
+	info := node.info()
+	m.mu.Unlock()
+
+	m.notify(":double_vertical_bar: strategy %s is suspended, the open orders policy: %s", name, policy)
+
+	if suspendable, ok := node.strategy.(SuspendableStrategy); ok {
+		if err := suspendable.Suspend(ctx, policy); err != nil {
+			return info, fmt.Errorf("strategy %s suspend error: %w", name, err)
+		}
+	}
+
+	return info, nil
+}
+
+// Resume resumes the suspended strategy, the strategy is still suspended if its Resume method returns an error
+func (m *LifecycleManager) Resume(ctx context.Context, name string) (StrategyInfo, error) {
+	m.mu.Lock()
+	node, err := m.lookup(name)
+	if err != nil {
+		m.mu.Unlock()
+		return StrategyInfo{}, err
+	}
+
+	if node.state != StrategyStateSuspended {
+		m.mu.Unlock()
+		return node.info(), fmt.Errorf("strategy %s is not suspended, it's %s", name, node.state)
+	}
+
+	// the strategy submits its orders on resume, so the state is updated before calling Resume
+	node.state = StrategyStateRunning
+	m.mu.Unlock()
+
+	if suspendable, ok := node.strategy.(SuspendableStrategy); ok {
+		if err := suspendable.Resume(ctx); err != nil {
+			m.mu.Lock()
+			if node.state == StrategyStateRunning {
+				node.state = StrategyStateSuspended
+			}
+			info := node.info()
+			m.mu.Unlock()
+			return info, fmt.Errorf("strategy %s resume error: %w", name, err)
+		}
+	}
+
+	m.notify(":arrow_forward: strategy %s is resumed", name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return node.info(), nil
+}
+
+// SetPauseStore loads the pause flags from the store and persists the later changes to it,
+// the strategies paused before the restart are paused again when they are declared.
+func (m *LifecycleManager) SetPauseStore(store Store) error {
+	pausedNames, err := LoadPauseFlags(store)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pauseStore = store
+	m.pausedNames = pausedNames
+	for _, node := range m.nodes {
+		node.paused = pausedNames[node.name]
+	}
+
+	return nil
+}
+
+// Pause blocks the orders of the strategy until it's unpaused, unlike Suspend, the strategy is not notified,
+// it keeps its state and subscriptions, and the pause flag is persisted across restarts.
+func (m *LifecycleManager) Pause(name string) (StrategyInfo, error) {
+	return m.setPaused(name, true)
+}
+
+// Unpause clears the pause flag of the strategy, the orders of the strategy are accepted again
+func (m *LifecycleManager) Unpause(name string) (StrategyInfo, error) {
+	return m.setPaused(name, false)
+}
+
+func (m *LifecycleManager) setPaused(name string, paused bool) (StrategyInfo, error) {
+	m.mu.Lock()
+	node, err := m.lookup(name)
+	if err != nil {
+		m.mu.Unlock()
+		return StrategyInfo{}, err
+	}
+
+	if node.paused == paused {
+		m.mu.Unlock()
+		if paused {
+			return node.info(), fmt.Errorf("strategy %s is already paused", name)
+		}
+
+		return node.info(), fmt.Errorf("strategy %s is not paused", name)
+	}
+
+	if m.pauseStore != nil {
+		if err := SetPauseFlag(m.pauseStore, name, paused); err != nil {
+			m.mu.Unlock()
+			return node.info(), fmt.Errorf("can not save the pause flag of strategy %s: %w", name, err)
+		}
+	}
+
+	node.paused = paused
+	if paused {
+		m.pausedNames[name] = true
+	} else {
+		delete(m.pausedNames, name)
+	}
+
+	info := node.info()
+	m.mu.Unlock()
+
+	if paused {
+		m.notify(":pause_button: strategy %s is paused, its orders are blocked", name)
+	} else {
+		m.notify(":arrow_forward: strategy %s is unpaused", name)
+	}
+
+	return info, nil
+}
+
+// UpdateParameters applies the parameter changes to the strategy implementing RuntimeParameterUpdater
+func (m *LifecycleManager) UpdateParameters(ctx context.Context, name string, parameters []byte) (StrategyInfo, error) {
+	m.mu.Lock()
+	node, err := m.lookup(name)
+	if err != nil {
+		m.mu.Unlock()
+		return StrategyInfo{}, err
+	}
+	m.mu.Unlock()
+
+	updater, ok := node.strategy.(RuntimeParameterUpdater)
+	if !ok {
+		return StrategyInfo{}, fmt.Errorf("strategy %s does not support updating the parameters at runtime", name)
+	}
+
+	if err := updater.UpdateParameters(ctx, parameters); err != nil {
+		return StrategyInfo{}, fmt.Errorf("strategy %s parameter update error: %w", name, err)
+	}
+
+	m.notify(":gear: strategy %s parameters are updated: %s", name, parameters)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return node.info(), nil
+}
+
+// Fail marks the strategy as failed, and stops the strategies depending on it
+func (m *LifecycleManager) Fail(ctx context.Context, strategy interface{}, err error) {
+	m.mu.Lock()
+	node, ok := m.byStrategy[strategy]
+	if !ok || node.state == StrategyStateFailed || node.state == StrategyStateStopped {
+		m.mu.Unlock()
+		return
+	}
+
+	node.state = StrategyStateFailed
+	node.err = err
+
+	dependents := m.dependentsOf(node)
+	for _, dependent := range dependents {
+		dependent.state = StrategyStateStopped
+		dependent.err = fmt.Errorf("dependency %s failed: %w", node.name, err)
+	}
+	m.mu.Unlock()
+
+	m.notify(":rotating_light: strategy %s failed: %v", node.name, err)
+
+	for _, dependent := range dependents {
+		m.stop(ctx, dependent)
+	}
+}
+
+// dependentsOf returns the strategies depending on the node directly or indirectly, the stopped ones are excluded
+func (m *LifecycleManager) dependentsOf(node *strategyNode) (dependents []*strategyNode) {
+	var found = map[*strategyNode]bool{node: true}
+	var queue = []*strategyNode{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, n := range m.nodes {
+			if found[n] || n.state == StrategyStateFailed || n.state == StrategyStateStopped {
+				continue
+			}
+
+			for _, dep := range n.dependsOn {
+				if dep == current.name {
+					found[n] = true
+					dependents = append(dependents, n)
+					queue = append(queue, n)
+					break
+				}
+			}
+		}
+	}
+
+	return dependents
+}
+
+func (m *LifecycleManager) stop(ctx context.Context, node *strategyNode) {
+	m.notify(":octagonal_sign: stopping strategy %s: %v", node.name, node.err)
+
+	stoppable, ok := node.strategy.(StoppableStrategy)
+	if !ok {
+		return
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, stopStrategyTimeout)
+	defer cancel()
+
+	if err := stoppable.Stop(stopCtx); err != nil {
+		log.WithError(err).Errorf("can not stop strategy %s", node.name)
+	}
+}
+
+// Run checks the health of the running strategies periodically until the context is done
+func (m *LifecycleManager) Run(ctx context.Context) {
+	interval := m.HealthCheckInterval
+	if interval == 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			m.checkHealth(ctx)
+		}
+	}
+}
+
+func (m *LifecycleManager) checkHealth(ctx context.Context) {
+	m.mu.Lock()
+	var checkers []interface{}
+	for _, node := range m.nodes {
+		if _, ok := node.strategy.(HealthChecker); ok && node.state == StrategyStateRunning {
+			checkers = append(checkers, node.strategy)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, strategy := range checkers {
+		if err := strategy.(HealthChecker).HealthCheck(ctx); err != nil {
+			m.Fail(ctx, strategy, err)
+		}
+	}
+}
+
+// hasHealthCheckers returns true if any declared strategy implements HealthChecker
+func (m *LifecycleManager) hasHealthCheckers() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, node := range m.nodes {
+		if _, ok := node.strategy.(HealthChecker); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *LifecycleManager) notify(format string, args ...interface{}) {
+	log.Warnf(format, args...)
+
+	if m.Notifiability != nil {
+		m.Notifiability.Notify(format, args...)
+	}
+}
+
+func strategyID(strategy interface{}) string {
+	switch s := strategy.(type) {
+	case SingleExchangeStrategy:
+		return s.ID()
+	case CrossExchangeStrategy:
+		return s.ID()
+	}
+
+	return fmt.Sprintf("%T", strategy)
+}
+
+// LifecycleOrderExecutor rejects the orders of the strategy after it's stopped or suspended by the lifecycle manager
+type LifecycleOrderExecutor struct {
+	OrderExecutor
+
+	manager  *LifecycleManager
+	strategy interface{}
+}
+
+func (e *LifecycleOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if err := e.manager.checkOrderSubmission(e.strategy); err != nil {
+		return nil, err
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, orders...)
+}
+
+// LifecycleOrderExecutionRouter rejects the orders of the cross exchange strategy after it's stopped or suspended by the lifecycle manager
+type LifecycleOrderExecutionRouter struct {
+	OrderExecutionRouter
+
+	manager  *LifecycleManager
+	strategy interface{}
+}
+
+func (r *LifecycleOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, session string, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if err := r.manager.checkOrderSubmission(r.strategy); err != nil {
+		return nil, err
+	}
+
+	return r.OrderExecutionRouter.SubmitOrdersTo(ctx, session, orders...)
+}
+
+// wrapLifecycleOrderExecutor gates the order executor of the strategy with its lifecycle state
+func wrapLifecycleOrderExecutor(manager *LifecycleManager, strategy interface{}, executor OrderExecutor) OrderExecutor {
+	return &LifecycleOrderExecutor{OrderExecutor: executor, manager: manager, strategy: strategy}
+}
@@ -0,0 +1,238 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type lifecycleTestStrategy struct {
+	id        string
+	stopped   bool
+	suspended bool
+
+	suspendPolicy SuspendOrderPolicy
+
+	GridNum int `json:"gridNumber"`
+}
+
+func (s *lifecycleTestStrategy) ID() string {
+	return s.id
+}
+
+func (s *lifecycleTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Stop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Suspend(ctx context.Context, policy SuspendOrderPolicy) error {
+	s.suspended = true
+	s.suspendPolicy = policy
+	return nil
+}
+
+func (s *lifecycleTestStrategy) Resume(ctx context.Context) error {
+	s.suspended = false
+	return nil
+}
+
+func (s *lifecycleTestStrategy) UpdateParameters(ctx context.Context, parameters []byte) error {
+	return json.Unmarshal(parameters, s)
+}
+
+func TestLifecycleManager_Sort(t *testing.T) {
+	hedger := &lifecycleTestStrategy{id: "xhedge"}
+	grid := &lifecycleTestStrategy{id: "grid"}
+	other := &lifecycleTestStrategy{id: "other"}
+
+	m := NewLifecycleManager(nil)
+	m.Declare("grid", grid, "hedger")
+	m.Declare("hedger", hedger)
+	m.Declare("", other)
+
+	sorted, err := m.Sort([]interface{}{grid, other, hedger})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{hedger, grid, other}, sorted)
+
+	t.Run("unknown dependency", func(t *testing.T) {
+		m := NewLifecycleManager(nil)
+		m.Declare("grid", grid, "hedger")
+		_, err := m.Sort([]interface{}{grid})
+		assert.Error(t, err)
+	})
+
+	t.Run("circular dependency", func(t *testing.T) {
+		m := NewLifecycleManager(nil)
+		m.Declare("grid", grid, "hedger")
+		m.Declare("hedger", hedger, "grid")
+		_, err := m.Sort([]interface{}{grid, hedger})
+		assert.Error(t, err)
+	})
+
+	t.Run("ambiguous dependency", func(t *testing.T) {
+		m := NewLifecycleManager(nil)
+		m.Declare("grid", grid, "xhedge")
+		m.Declare("", hedger)
+		m.Declare("", &lifecycleTestStrategy{id: "xhedge"})
+		_, err := m.Sort([]interface{}{grid, hedger})
+		assert.Error(t, err)
+	})
+}
+
+func TestLifecycleManager_Fail(t *testing.T) {
+	hedger := &lifecycleTestStrategy{id: "xhedge"}
+	grid := &lifecycleTestStrategy{id: "grid"}
+	follower := &lifecycleTestStrategy{id: "follower"}
+	other := &lifecycleTestStrategy{id: "other"}
+
+	m := NewLifecycleManager(nil)
+	m.Declare("hedger", hedger)
+	m.Declare("grid", grid, "hedger")
+	m.Declare("follower", follower, "grid")
+	m.Declare("other", other)
+
+	for _, s := range []interface{}{hedger, grid, follower, other} {
+		assert.NoError(t, m.CanStart(s))
+		m.Started(s)
+	}
+
+	executor := wrapLifecycleOrderExecutor(m, grid, &recordingOrderExecutor{})
+	_, err := executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+
+	m.Fail(context.Background(), hedger, errors.New("hedging session disconnected"))
+
+	assert.Equal(t, map[string]StrategyState{
+		"hedger":   StrategyStateFailed,
+		"grid":     StrategyStateStopped,
+		"follower": StrategyStateStopped,
+		"other":    StrategyStateRunning,
+	}, m.States())
+
+	assert.True(t, grid.stopped)
+	assert.True(t, follower.stopped)
+	assert.False(t, other.stopped)
+
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrStrategyStopped, err)
+
+	// the dependents can't start again until the dependency is running
+	assert.Error(t, m.CanStart(grid))
+}
+
+func TestLifecycleManager_Suspend(t *testing.T) {
+	grid := &lifecycleTestStrategy{id: "grid"}
+
+	m := NewLifecycleManager(nil)
+	m.Declare("grid", grid)
+
+	_, err := m.Suspend(context.Background(), "grid")
+	assert.Error(t, err, "pending strategy can not be suspended")
+
+	m.Started(grid)
+
+	executor := wrapLifecycleOrderExecutor(m, grid, &recordingOrderExecutor{})
+
+	info, err := m.Suspend(context.Background(), "grid")
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyStateSuspended, info.State)
+	assert.True(t, grid.suspended)
+	assert.Equal(t, SuspendOrderPolicyCancel, grid.suspendPolicy)
+
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrStrategySuspended, err)
+
+	info, err = m.Resume(context.Background(), "grid")
+	assert.NoError(t, err)
+	assert.Equal(t, StrategyStateRunning, info.State)
+	assert.False(t, grid.suspended)
+
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+
+	// the open orders are handled with the policy of the strategy unless the policy is given
+	assert.Error(t, m.SetSuspendOrderPolicy(grid, "close"))
+	assert.NoError(t, m.SetSuspendOrderPolicy(grid, SuspendOrderPolicyCancelAndRestore))
+
+	info, err = m.Suspend(context.Background(), "grid")
+	assert.NoError(t, err)
+	assert.Equal(t, SuspendOrderPolicyCancelAndRestore, grid.suspendPolicy)
+	assert.Equal(t, SuspendOrderPolicyCancelAndRestore, info.SuspendOrderPolicy)
+
+	_, err = m.Resume(context.Background(), "grid")
+	assert.NoError(t, err)
+
+	_, err = m.SuspendWithPolicy(context.Background(), "grid", SuspendOrderPolicyKeep)
+	assert.NoError(t, err)
+	assert.Equal(t, SuspendOrderPolicyKeep, grid.suspendPolicy)
+
+	_, err = m.Resume(context.Background(), "grid")
+	assert.NoError(t, err)
+
+	info, err = m.UpdateParameters(context.Background(), "grid", []byte(`{"gridNumber": 20}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 20, grid.GridNum)
+
+	parameters, err := info.Parameters()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"gridNumber": 20}`, string(parameters))
+
+	_, err = m.Lookup("hedger")
+	assert.Error(t, err)
+}
+
+func TestLifecycleManager_Pause(t *testing.T) {
+	store := NewMemoryService().NewStore("bbgo", pauseStoreID)
+
+	grid := &lifecycleTestStrategy{id: "grid"}
+
+	m := NewLifecycleManager(nil)
+	assert.NoError(t, m.SetPauseStore(store))
+	m.Declare("grid", grid)
+	m.Started(grid)
+
+	executor := wrapLifecycleOrderExecutor(m, grid, &recordingOrderExecutor{})
+
+	info, err := m.Pause("grid")
+	assert.NoError(t, err)
+	assert.True(t, info.Paused)
+	assert.Equal(t, StrategyStateRunning, info.State)
+	assert.False(t, grid.suspended, "the paused strategy is not notified")
+
+	_, err = executor.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrStrategyPaused, err)
+
+	_, err = m.Pause("grid")
+	assert.Error(t, err, "already paused")
+
+	// the pause flag is restored after the restart
+	restarted := &lifecycleTestStrategy{id: "grid"}
+	m2 := NewLifecycleManager(nil)
+	assert.NoError(t, m2.SetPauseStore(store))
+	m2.Declare("grid", restarted)
+	m2.Started(restarted)
+
+	info, err = m2.Lookup("grid")
+	assert.NoError(t, err)
+	assert.True(t, info.Paused)
+
+	info, err = m2.Unpause("grid")
+	assert.NoError(t, err)
+	assert.False(t, info.Paused)
+
+	_, err = wrapLifecycleOrderExecutor(m2, restarted, &recordingOrderExecutor{}).SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+
+	flags, err := LoadPauseFlags(store)
+	assert.NoError(t, err)
+	assert.Empty(t, flags)
+}
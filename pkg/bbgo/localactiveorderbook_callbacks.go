@@ -15,3 +15,13 @@ func (b *LocalActiveOrderBook) EmitFilled(o types.Order) {
 		cb(o)
 	}
 }
+
+func (b *LocalActiveOrderBook) OnCanceled(cb func(o types.Order)) {
+	b.canceledCallbacks = append(b.canceledCallbacks, cb)
+}
+
+func (b *LocalActiveOrderBook) EmitCanceled(o types.Order) {
+	for _, cb := range b.canceledCallbacks {
+		cb(o)
+	}
+}
@@ -0,0 +1,273 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrMarginBorrowLimit = errors.New("the borrow amount exceeds the max borrowable amount")
+
+// marginShortfalls returns the amount of each currency to borrow for the orders, i.e., the base currency of the sell orders
+// beyond the available balance (short) and the quote currency of the buy orders beyond the available balance (leverage).
+// The market buy orders are valued with the last price. The orders with the margin side effect are skipped,
+// since the exchange borrows the assets for them.
+func marginShortfalls(orders []types.SubmitOrder, balances types.BalanceMap, lastPrice func(symbol string) (float64, bool)) (map[string]fixedpoint.Value, error) {
+	var required = make(map[string]fixedpoint.Value)
+	for _, order := range orders {
+		if len(order.MarginSideEffect) > 0 && order.MarginSideEffect != types.SideEffectTypeNoSideEffect {
+			continue
+		}
+
+		switch order.Side {
+		case types.SideTypeSell:
+			required[order.Market.BaseCurrency] += fixedpoint.NewFromFloat(order.Quantity)
+
+		case types.SideTypeBuy:
+			price := order.Price
+			if order.Type == types.OrderTypeMarket || price == 0 {
+				var ok bool
+				price, ok = lastPrice(order.Symbol)
+				if !ok {
+					return nil, fmt.Errorf("the last price of %s is not available", order.Symbol)
+				}
+			}
+
+			required[order.Market.QuoteCurrency] += fixedpoint.NewFromFloat(order.Quantity * price)
+		}
+	}
+
+	var shortfalls = make(map[string]fixedpoint.Value)
+	for currency, amount := range required {
+		var available fixedpoint.Value
+		if balance, ok := balances[currency]; ok {
+			available = balance.Available
+		}
+
+		if amount > available {
+			shortfalls[currency] = amount - available
+		}
+	}
+
+	return shortfalls, nil
+}
+
+// marginRepayments returns the amount of each currency that can be repaid with the available balance
+func marginRepayments(balances types.BalanceMap, currencies ...string) map[string]fixedpoint.Value {
+	var repayments = make(map[string]fixedpoint.Value)
+	for _, currency := range currencies {
+		balance, ok := balances[currency]
+		if !ok || balance.Borrowed <= 0 || balance.Available <= 0 {
+			continue
+		}
+
+		amount := balance.Borrowed
+		if balance.Available < amount {
+			amount = balance.Available
+		}
+
+		repayments[currency] = amount
+	}
+
+	return repayments
+}
+
+// MarginOrderExecutor borrows the shortfall of the orders from the margin account before submitting them,
+// so that the strategies can short (sell the base currency they don't hold) or leverage (buy with the borrowed quote currency).
+// With AutoRepay, the borrowed assets of the order currencies are repaid with the available balances after the orders are filled.
+type MarginOrderExecutor struct {
+	OrderExecutor
+
+	Session  *ExchangeSession
+	Service  types.MarginBorrowRepayService
+	Balances types.MarginBalanceService
+
+	AutoRepay bool
+
+	mu sync.Mutex
+
+	// orders are the symbols of the submitted orders, the filled orders trigger the auto repay
+	orders map[uint64]string
+}
+
+func NewMarginOrderExecutor(executor OrderExecutor, session *ExchangeSession, autoRepay bool) (*MarginOrderExecutor, error) {
+	if !session.Capabilities().Has(types.CapabilityMarginBorrowRepay) {
+		return nil, fmt.Errorf("margin borrowing is not available in session %s (exchange %s)", session.Name, session.ExchangeName)
+	}
+
+	service, ok := session.Exchange.(types.MarginBorrowRepayService)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support margin borrowing", session.ExchangeName)
+	}
+
+	balances, ok := session.Exchange.(types.MarginBalanceService)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support querying the margin balances", session.ExchangeName)
+	}
+
+	return &MarginOrderExecutor{
+		OrderExecutor: executor,
+		Session:       session,
+		Service:       service,
+		Balances:      balances,
+		AutoRepay:     autoRepay,
+		orders:        make(map[uint64]string),
+	}, nil
+}
+
+func (e *MarginOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(func(order types.Order) {
+		if !e.AutoRepay || order.Status != types.OrderStatusFilled {
+			return
+		}
+
+		e.mu.Lock()
+		symbol, ok := e.orders[order.OrderID]
+		delete(e.orders, order.OrderID)
+		e.mu.Unlock()
+
+		if !ok {
+			return
+		}
+
+		market, ok := e.Session.Market(symbol)
+		if !ok {
+			return
+		}
+
+		go func() {
+			if err := e.Repay(context.Background(), market.BaseCurrency, market.QuoteCurrency); err != nil {
+				log.WithError(err).Errorf("margin auto repay error")
+			}
+		}()
+	})
+}
+
+// Borrow borrows the shortfall of the orders, the shortfall exceeding the max borrowable amount returns ErrMarginBorrowLimit
+func (e *MarginOrderExecutor) Borrow(ctx context.Context, orders ...types.SubmitOrder) error {
+	balances, err := e.Balances.QueryMarginBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	shortfalls, err := marginShortfalls(orders, balances, e.Session.LastPrice)
+	if err != nil {
+		return err
+	}
+
+	var currencies []string
+	for currency := range shortfalls {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	for _, currency := range currencies {
+		amount := shortfalls[currency]
+		maxBorrowable, err := e.Service.QueryMarginAssetMaxBorrowable(ctx, currency)
+		if err != nil {
+			return err
+		}
+
+		if amount > maxBorrowable {
+			return fmt.Errorf("%w: borrowing %f %s, max borrowable %f", ErrMarginBorrowLimit, amount.Float64(), currency, maxBorrowable.Float64())
+		}
+
+		err = e.Service.BorrowMarginAsset(ctx, currency, amount)
+		e.Session.audit(service.AuditActionBorrowMarginAsset, map[string]interface{}{"asset": currency, "amount": amount.Float64()}, nil, err)
+		if err != nil {
+			return err
+		}
+
+		e.Session.Notify("borrowed %f %s in the margin account of session %s", amount.Float64(), currency, e.Session.Name)
+	}
+
+	if len(shortfalls) > 0 {
+		return e.updateAccountBalances(ctx)
+	}
+
+	return nil
+}
+
+// Repay repays the borrowed assets of the currencies with the available balances
+func (e *MarginOrderExecutor) Repay(ctx context.Context, currencies ...string) error {
+	balances, err := e.Balances.QueryMarginBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	repayments := marginRepayments(balances, currencies...)
+	for _, currency := range currencies {
+		amount, ok := repayments[currency]
+		if !ok {
+			continue
+		}
+
+		err := e.Service.RepayMarginAsset(ctx, currency, amount)
+		e.Session.audit(service.AuditActionRepayMarginAsset, map[string]interface{}{"asset": currency, "amount": amount.Float64()}, nil, err)
+		if err != nil {
+			return err
+		}
+
+		e.Session.Notify("repaid %f %s in the margin account of session %s", amount.Float64(), currency, e.Session.Name)
+	}
+
+	if len(repayments) > 0 {
+		return e.updateAccountBalances(ctx)
+	}
+
+	return nil
+}
+
+// updateAccountBalances updates the session account with the balances after borrowing or repaying,
+// since not every exchange pushes the margin balance updates through the user data stream.
+func (e *MarginOrderExecutor) updateAccountBalances(ctx context.Context) error {
+	balances, err := e.Balances.QueryMarginBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.Session.Account.UpdateBalances(balances)
+	return nil
+}
+
+func (e *MarginOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if err := e.Borrow(ctx, orders...); err != nil {
+		return nil, err
+	}
+
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, orders...)
+
+	if e.AutoRepay {
+		e.mu.Lock()
+		for _, o := range createdOrders {
+			e.orders[o.OrderID] = o.Symbol
+		}
+		e.mu.Unlock()
+	}
+
+	return createdOrders, err
+}
+
+// wrapMarginOrderExecutor wraps the order executor with the margin borrowing if the session enables the auto borrow
+func wrapMarginOrderExecutor(session *ExchangeSession, executor OrderExecutor) OrderExecutor {
+	if !session.Margin || !session.AutoBorrow {
+		return executor
+	}
+
+	marginExecutor, err := NewMarginOrderExecutor(executor, session, session.AutoRepay)
+	if err != nil {
+		log.WithError(err).Warnf("session %s enables the auto borrow, but the margin order executor can not be created", session.Name)
+		return executor
+	}
+
+	marginExecutor.BindStream(session.Stream)
+	return marginExecutor
+}
@@ -0,0 +1,77 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarginShortfalls(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+	}
+
+	lastPrice := func(symbol string) (float64, bool) {
+		return 20000.0, symbol == "BTCUSDT"
+	}
+
+	t.Run("short", func(t *testing.T) {
+		shortfalls, err := marginShortfalls([]types.SubmitOrder{
+			{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Market: market, Quantity: 0.3, Price: 21000.0},
+			{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Market: market, Quantity: 0.4, Price: 22000.0},
+		}, balances, lastPrice)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]fixedpoint.Value{"BTC": fixedpoint.NewFromFloat(0.2)}, shortfalls)
+	})
+
+	t.Run("leverage with the market order", func(t *testing.T) {
+		shortfalls, err := marginShortfalls([]types.SubmitOrder{
+			{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Market: market, Quantity: 0.1},
+		}, balances, lastPrice)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]fixedpoint.Value{"USDT": fixedpoint.NewFromFloat(1000.0)}, shortfalls)
+	})
+
+	t.Run("covered by the balances", func(t *testing.T) {
+		shortfalls, err := marginShortfalls([]types.SubmitOrder{
+			{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Market: market, Quantity: 0.5, Price: 21000.0},
+			{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Market: market, Quantity: 0.05, Price: 19000.0},
+		}, balances, lastPrice)
+		assert.NoError(t, err)
+		assert.Empty(t, shortfalls)
+	})
+
+	t.Run("margin side effect", func(t *testing.T) {
+		shortfalls, err := marginShortfalls([]types.SubmitOrder{
+			{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Market: market, Quantity: 1.0, Price: 21000.0, MarginSideEffect: types.SideEffectTypeMarginBuy},
+		}, balances, lastPrice)
+		assert.NoError(t, err)
+		assert.Empty(t, shortfalls)
+	})
+
+	t.Run("no last price", func(t *testing.T) {
+		_, err := marginShortfalls([]types.SubmitOrder{
+			{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Market: market, Quantity: 1.0},
+		}, balances, lastPrice)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarginRepayments(t *testing.T) {
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.5), Borrowed: fixedpoint.NewFromFloat(0.2)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.0), Borrowed: fixedpoint.NewFromFloat(300.0)},
+		"ETH":  {Currency: "ETH", Available: fixedpoint.NewFromFloat(1.0)},
+	}
+
+	repayments := marginRepayments(balances, "BTC", "USDT", "ETH", "MAX")
+	assert.Equal(t, map[string]fixedpoint.Value{
+		"BTC":  fixedpoint.NewFromFloat(0.2),
+		"USDT": fixedpoint.NewFromFloat(100.0),
+	}, repayments)
+}
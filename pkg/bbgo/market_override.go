@@ -0,0 +1,90 @@
+package bbgo
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarketOverride overrides the market filters reported by the exchange, for the exchanges whose API reports stale filters.
+// The zero values (and the nil precisions) are not overridden.
+type MarketOverride struct {
+	PricePrecision  *int `json:"pricePrecision,omitempty" yaml:"pricePrecision,omitempty"`
+	VolumePrecision *int `json:"volumePrecision,omitempty" yaml:"volumePrecision,omitempty"`
+
+	TickSize float64 `json:"tickSize,omitempty" yaml:"tickSize,omitempty"`
+	MinPrice float64 `json:"minPrice,omitempty" yaml:"minPrice,omitempty"`
+
+	StepSize    float64 `json:"stepSize,omitempty" yaml:"stepSize,omitempty"`
+	MinLot      float64 `json:"minLot,omitempty" yaml:"minLot,omitempty"`
+	MinQuantity float64 `json:"minQuantity,omitempty" yaml:"minQuantity,omitempty"`
+
+	MinNotional float64 `json:"minNotional,omitempty" yaml:"minNotional,omitempty"`
+	MinAmount   float64 `json:"minAmount,omitempty" yaml:"minAmount,omitempty"`
+}
+
+// Apply returns the market with the overridden filters, warn is called for each filter that diverges from the exchange info
+func (o MarketOverride) Apply(market types.Market, warn func(field string, exchangeValue, overrideValue float64)) types.Market {
+	overrideInt := func(field string, target *int, value *int) {
+		if value == nil {
+			return
+		}
+
+		if *target != *value {
+			warn(field, float64(*target), float64(*value))
+		}
+
+		*target = *value
+	}
+
+	overrideFloat := func(field string, target *float64, value float64) {
+		if value == 0 {
+			return
+		}
+
+		if *target != value {
+			warn(field, *target, value)
+		}
+
+		*target = value
+	}
+
+	overrideInt("pricePrecision", &market.PricePrecision, o.PricePrecision)
+	overrideInt("volumePrecision", &market.VolumePrecision, o.VolumePrecision)
+	overrideFloat("tickSize", &market.TickSize, o.TickSize)
+	overrideFloat("minPrice", &market.MinPrice, o.MinPrice)
+	overrideFloat("stepSize", &market.StepSize, o.StepSize)
+	overrideFloat("minLot", &market.MinLot, o.MinLot)
+	overrideFloat("minQuantity", &market.MinQuantity, o.MinQuantity)
+	overrideFloat("minNotional", &market.MinNotional, o.MinNotional)
+	overrideFloat("minAmount", &market.MinAmount, o.MinAmount)
+	return market
+}
+
+// ApplyMarketOverrides returns a copy of the markets with the overrides applied,
+// the markets returned by the exchange (or the market cache) are not modified.
+func ApplyMarketOverrides(markets types.MarketMap, overrides map[string]MarketOverride, logger log.FieldLogger) types.MarketMap {
+	if len(overrides) == 0 {
+		return markets
+	}
+
+	var newMarkets = make(types.MarketMap, len(markets))
+	for symbol, market := range markets {
+		newMarkets[symbol] = market
+	}
+
+	for symbol, override := range overrides {
+		market, ok := newMarkets[symbol]
+		if !ok {
+			logger.Warnf("market override of %s is ignored, the market is not found in the exchange info", symbol)
+			continue
+		}
+
+		newMarkets[symbol] = override.Apply(market, func(field string, exchangeValue, overrideValue float64) {
+			logger.Warnf("market override of %s diverges from the exchange info: %s %v (exchange) -> %v (override)",
+				symbol, field, exchangeValue, overrideValue)
+		})
+	}
+
+	return newMarkets
+}
@@ -0,0 +1,53 @@
+package bbgo
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestApplyMarketOverrides(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {
+			Symbol:         "BTCUSDT",
+			PricePrecision: 2,
+			TickSize:       0.01,
+			MinPrice:       0.01,
+			MinNotional:    10.0,
+			MinLot:         0.000001,
+		},
+	}
+
+	pricePrecision := 1
+	logger, hook := test.NewNullLogger()
+	newMarkets := ApplyMarketOverrides(markets, map[string]MarketOverride{
+		"BTCUSDT": {
+			PricePrecision: &pricePrecision,
+			TickSize:       0.1,
+			MinPrice:       0.1,
+			// the same value as the exchange info
+			MinNotional: 10.0,
+		},
+		"ETHUSDT": {TickSize: 0.01},
+	}, logger)
+
+	market := newMarkets["BTCUSDT"]
+	assert.Equal(t, 1, market.PricePrecision)
+	assert.Equal(t, 0.1, market.TickSize)
+	assert.Equal(t, 10.0, market.MinNotional)
+	// the fields without the override are kept
+	assert.Equal(t, 0.000001, market.MinLot)
+
+	// the original markets are not modified
+	assert.Equal(t, 0.01, markets["BTCUSDT"].TickSize)
+
+	// 3 diverged fields and 1 unknown market
+	assert.Len(t, hook.AllEntries(), 4)
+	for _, entry := range hook.AllEntries() {
+		assert.Equal(t, log.WarnLevel, entry.Level)
+	}
+}
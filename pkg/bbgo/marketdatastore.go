@@ -1,6 +1,17 @@
 package bbgo
 
-import "github.com/c9s/bbgo/pkg/types"
+import (
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// defaultDepthLevels is the default number of the aggregated levels of the book depth updates
+const defaultDepthLevels = 5
+
+// defaultMarketTradeBufferSize is the default number of the recent public trades kept in the store
+const defaultMarketTradeBufferSize = 1000
 
 // MarketDataStore receives and maintain the public market data
 //go:generate callbackgen -type MarketDataStore
@@ -12,16 +23,43 @@ type MarketDataStore struct {
 
 	kLineWindowUpdateCallbacks []func(interval types.Interval, kline types.KLineWindow)
 
-	orderBook *types.StreamOrderBook
+	// kLineClosedCallbacks are called after the kline window is updated, so that the indicators bound to the store
+	// are already updated with the closed kline.
+	kLineClosedCallbacks []func(kline types.KLine)
+
+	// kLineAggregators build the klines of the higher intervals from the 1m klines, sorted by the interval
+	kLineAggregators []*KLineAggregator
+
+	// orderBook validates the update IDs of the depth updates and resyncs the book on the gaps
+	orderBook *types.OrderBookManager
+
+	orderBookUpdateCallbacks []func(orderBook *types.OrderBookManager)
+
+	// DepthLevels is the number of the aggregated levels of the book depth updates, defaults to 5
+	DepthLevels int
+
+	bookDepthUpdateCallbacks []func(depth types.BookDepth)
 
-	orderBookUpdateCallbacks []func(orderBook *types.StreamOrderBook)
+	// marketTrades is the ring buffer of the recent public trades, marketTradeHead points to the oldest trade
+	// once the buffer is full.
+	marketTrades    []types.Trade
+	marketTradeHead int
+
+	// MarketTradeBufferSize is the number of the recent public trades kept in the store, defaults to 1000
+	MarketTradeBufferSize int
+
+	marketTradeCallbacks []func(trade types.Trade)
 }
 
 func NewMarketDataStore(symbol string) *MarketDataStore {
 	return &MarketDataStore{
 		Symbol: symbol,
 
-		orderBook: types.NewStreamBook(symbol),
+		orderBook: types.NewOrderBookManager(symbol),
+
+		DepthLevels: defaultDepthLevels,
+
+		MarketTradeBufferSize: defaultMarketTradeBufferSize,
 
 		// KLineWindows stores all loaded klines per interval
 		KLineWindows: make(map[types.Interval]types.KLineWindow, len(types.SupportedIntervals)), // 12 interval, 1m,5m,15m,30m,1h,2h,4h,6h,12h,1d,3d,1w
@@ -32,8 +70,15 @@ func (store *MarketDataStore) SetKLineWindows(windows map[types.Interval]types.K
 	store.KLineWindows = windows
 }
 
-func (store *MarketDataStore) OrderBook() types.OrderBook {
-	return store.orderBook.Copy()
+// OrderBook returns a copy of the order book, ok is false if the book is not loaded yet or it's out of sync,
+// the strategies quoting from the book should not use the book when ok is false.
+func (store *MarketDataStore) OrderBook() (types.OrderBook, bool) {
+	return store.orderBook.Get()
+}
+
+// OrderBookStats returns the consistency metrics of the order book
+func (store *MarketDataStore) OrderBookStats() types.OrderBookStats {
+	return store.orderBook.Stats()
 }
 
 // KLinesOfInterval returns the kline window of the given interval
@@ -47,9 +92,12 @@ func (store *MarketDataStore) handleOrderBookUpdate(book types.OrderBook) {
 		return
 	}
 
-	store.orderBook.Update(book)
+	if err := store.orderBook.Update(book); err != nil {
+		return
+	}
 
 	store.EmitOrderBookUpdate(store.orderBook)
+	store.emitBookDepthUpdate()
 }
 
 func (store *MarketDataStore) handleOrderBookSnapshot(book types.OrderBook) {
@@ -58,14 +106,37 @@ func (store *MarketDataStore) handleOrderBookSnapshot(book types.OrderBook) {
 	}
 
 	store.orderBook.Load(book)
+
+	store.EmitOrderBookUpdate(store.orderBook)
+	store.emitBookDepthUpdate()
+}
+
+// Depth returns the aggregated depth of the top levels of the order book, ok is false if either side of the book is empty
+// or the book is out of sync
+func (store *MarketDataStore) Depth(levels int) (types.BookDepth, bool) {
+	return store.orderBook.Depth(levels)
+}
+
+func (store *MarketDataStore) emitBookDepthUpdate() {
+	// skip the aggregation if nobody subscribes the depth updates
+	if len(store.bookDepthUpdateCallbacks) == 0 {
+		return
+	}
+
+	if depth, ok := store.Depth(store.DepthLevels); ok {
+		store.EmitBookDepthUpdate(depth)
+	}
 }
 
 func (store *MarketDataStore) BindStream(stream types.Stream) {
 	stream.OnKLineClosed(store.handleKLineClosed)
 	stream.OnBookSnapshot(store.handleOrderBookSnapshot)
 	stream.OnBookUpdate(store.handleOrderBookUpdate)
+	stream.OnMarketTrade(store.handleMarketTrade)
 
-	store.orderBook.BindStream(stream)
+	if resyncer, ok := stream.(types.BookResyncer); ok {
+		store.orderBook.SetResyncer(resyncer)
+	}
 }
 
 func (store *MarketDataStore) handleKLineClosed(kline types.KLine) {
@@ -73,9 +144,40 @@ func (store *MarketDataStore) handleKLineClosed(kline types.KLine) {
 		return
 	}
 
+	// the klines of the aggregated intervals are built from the 1m klines, skip the ones from the stream
+	if store.isAggregatedInterval(kline.Interval) {
+		return
+	}
+
 	store.AddKLine(kline)
 }
 
+// AggregateKLines builds the klines of the given intervals from the 1m klines added to the store, the aggregated klines
+// are added to the kline windows and emitted like the klines from the stream, so the indicators can be bound to them.
+func (store *MarketDataStore) AggregateKLines(intervals ...types.Interval) {
+	for _, interval := range intervals {
+		if interval == types.Interval1m || store.isAggregatedInterval(interval) {
+			continue
+		}
+
+		store.kLineAggregators = append(store.kLineAggregators, NewKLineAggregator(interval))
+	}
+
+	sort.Slice(store.kLineAggregators, func(i, j int) bool {
+		return store.kLineAggregators[i].Interval.Minutes() < store.kLineAggregators[j].Interval.Minutes()
+	})
+}
+
+func (store *MarketDataStore) isAggregatedInterval(interval types.Interval) bool {
+	for _, aggregator := range store.kLineAggregators {
+		if aggregator.Interval == interval {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (store *MarketDataStore) AddKLine(kline types.KLine) {
 	window, ok := store.KLineWindows[kline.Interval]
 	if !ok {
@@ -85,4 +187,62 @@ func (store *MarketDataStore) AddKLine(kline types.KLine) {
 	}
 	store.KLineWindows[kline.Interval] = window
 	store.EmitKLineWindowUpdate(kline.Interval, window)
+	store.EmitKLineClosed(kline)
+
+	if kline.Interval != types.Interval1m {
+		return
+	}
+
+	for _, aggregator := range store.kLineAggregators {
+		for _, k := range aggregator.Add(kline) {
+			store.AddKLine(k)
+		}
+	}
+}
+
+func (store *MarketDataStore) handleMarketTrade(trade types.Trade) {
+	if trade.Symbol != store.Symbol {
+		return
+	}
+
+	store.AddMarketTrade(trade)
+}
+
+// AddMarketTrade pushes the public trade into the ring buffer, the oldest trade is overwritten when the buffer is full
+func (store *MarketDataStore) AddMarketTrade(trade types.Trade) {
+	size := store.MarketTradeBufferSize
+	if size <= 0 {
+		size = defaultMarketTradeBufferSize
+	}
+
+	if len(store.marketTrades) < size {
+		store.marketTrades = append(store.marketTrades, trade)
+	} else {
+		store.marketTrades[store.marketTradeHead] = trade
+		store.marketTradeHead = (store.marketTradeHead + 1) % len(store.marketTrades)
+	}
+
+	store.EmitMarketTrade(trade)
+}
+
+// MarketTrades returns a copy of the buffered public trades in the time order, the oldest trade comes first
+func (store *MarketDataStore) MarketTrades() []types.Trade {
+	trades := make([]types.Trade, 0, len(store.marketTrades))
+	trades = append(trades, store.marketTrades[store.marketTradeHead:]...)
+	trades = append(trades, store.marketTrades[:store.marketTradeHead]...)
+	return trades
+}
+
+// MarketTradesSince returns the buffered public trades at or after the given time
+func (store *MarketDataStore) MarketTradesSince(since time.Time) []types.Trade {
+	var trades []types.Trade
+	for _, trade := range store.MarketTrades() {
+		if trade.Time.Before(since) {
+			continue
+		}
+
+		trades = append(trades, trade)
+	}
+
+	return trades
 }
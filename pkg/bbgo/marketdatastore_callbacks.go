@@ -16,12 +16,42 @@ func (store *MarketDataStore) EmitKLineWindowUpdate(interval types.Interval, kli
 	}
 }
 
-func (store *MarketDataStore) OnOrderBookUpdate(cb func(orderBook *types.StreamOrderBook)) {
+func (store *MarketDataStore) OnKLineClosed(cb func(kline types.KLine)) {
+	store.kLineClosedCallbacks = append(store.kLineClosedCallbacks, cb)
+}
+
+func (store *MarketDataStore) EmitKLineClosed(kline types.KLine) {
+	for _, cb := range store.kLineClosedCallbacks {
+		cb(kline)
+	}
+}
+
+func (store *MarketDataStore) OnOrderBookUpdate(cb func(orderBook *types.OrderBookManager)) {
 	store.orderBookUpdateCallbacks = append(store.orderBookUpdateCallbacks, cb)
 }
 
-func (store *MarketDataStore) EmitOrderBookUpdate(orderBook *types.StreamOrderBook) {
+func (store *MarketDataStore) EmitOrderBookUpdate(orderBook *types.OrderBookManager) {
 	for _, cb := range store.orderBookUpdateCallbacks {
 		cb(orderBook)
 	}
 }
+
+func (store *MarketDataStore) OnBookDepthUpdate(cb func(depth types.BookDepth)) {
+	store.bookDepthUpdateCallbacks = append(store.bookDepthUpdateCallbacks, cb)
+}
+
+func (store *MarketDataStore) EmitBookDepthUpdate(depth types.BookDepth) {
+	for _, cb := range store.bookDepthUpdateCallbacks {
+		cb(depth)
+	}
+}
+
+func (store *MarketDataStore) OnMarketTrade(cb func(trade types.Trade)) {
+	store.marketTradeCallbacks = append(store.marketTradeCallbacks, cb)
+}
+
+func (store *MarketDataStore) EmitMarketTrade(trade types.Trade) {
+	for _, cb := range store.marketTradeCallbacks {
+		cb(trade)
+	}
+}
@@ -0,0 +1,64 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarketDataStore_KLineClosed(t *testing.T) {
+	store := NewMarketDataStore("BTCUSDT")
+
+	sma := &indicator.SMA{IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 3}}
+	sma.Bind(store)
+
+	var updates []float64
+	sma.OnUpdate(func(value float64) {
+		updates = append(updates, value)
+	})
+
+	var closedSMA []float64
+	store.OnKLineClosed(func(kline types.KLine) {
+		// the indicator is already updated with the closed kline
+		value, _ := sma.Last()
+		closedSMA = append(closedSMA, value)
+	})
+
+	for _, price := range []float64{1.0, 2.0, 3.0, 4.0} {
+		store.AddKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: price})
+	}
+
+	assert.Equal(t, []float64{2.0, 3.0}, updates)
+	assert.Equal(t, []float64{0.0, 0.0, 2.0, 3.0}, closedSMA)
+}
+
+func TestMarketDataStore_MarketTrades(t *testing.T) {
+	store := NewMarketDataStore("BTCUSDT")
+	store.MarketTradeBufferSize = 3
+
+	var received int
+	store.OnMarketTrade(func(trade types.Trade) {
+		received++
+	})
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		store.AddMarketTrade(types.Trade{ID: int64(i), Symbol: "BTCUSDT", Time: now.Add(time.Duration(i) * time.Second)})
+	}
+
+	assert.Equal(t, 5, received)
+
+	var ids []int64
+	for _, trade := range store.MarketTrades() {
+		ids = append(ids, trade.ID)
+	}
+	assert.Equal(t, []int64{2, 3, 4}, ids)
+
+	trades := store.MarketTradesSince(now.Add(3 * time.Second))
+	assert.Len(t, trades, 2)
+	assert.Equal(t, int64(3), trades[0].ID)
+}
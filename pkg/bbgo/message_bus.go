@@ -0,0 +1,144 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	// TopicTrades carries the TradeEvent of the trades of all the sessions, the source is the session name
+	TopicTrades = "trades"
+
+	// TopicPositions carries the Position updated by the trades, the source is the session name
+	// or the ID of the strategy publishing its own position
+	TopicPositions = "positions"
+
+	// TopicAll subscribes the signals of all the topics, e.g., for the risk modules and the loggers
+	TopicAll = "*"
+)
+
+// Signal is a message published by a strategy, e.g., a regime classifier publishes "trending" on the "regime" topic
+type Signal struct {
+	Topic  string      `json:"topic"`
+	Source string      `json:"source"`
+	Value  interface{} `json:"value"`
+	Time   time.Time   `json:"time"`
+}
+
+// TradeEvent is the value of the signals on TopicTrades
+type TradeEvent struct {
+	Session string      `json:"session"`
+	Trade   types.Trade `json:"trade"`
+}
+
+type subscriber struct {
+	id int
+	cb func(signal Signal)
+}
+
+// MessageBus is an in-process pub/sub bus for the strategies to coordinate with each other by topics,
+// so that the strategies don't need to import each other.
+// The MessageBus field of the strategies will be injected automatically.
+type MessageBus struct {
+	mu          sync.Mutex
+	lastID      int
+	subscribers map[string][]subscriber
+	lastSignals map[string]Signal
+}
+
+func NewMessageBus() *MessageBus {
+	return &MessageBus{
+		subscribers: make(map[string][]subscriber),
+		lastSignals: make(map[string]Signal),
+	}
+}
+
+// Subscribe registers the callback of the topic, the callback is called synchronously in the publisher goroutine.
+// The returned function removes the subscription.
+func (b *MessageBus) Subscribe(topic string, cb func(signal Signal)) (unsubscribe func()) {
+	b.mu.Lock()
+	b.lastID++
+	id := b.lastID
+	b.subscribers[topic] = append(b.subscribers[topic], subscriber{id: id, cb: cb})
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		subscribers := b.subscribers[topic]
+		for i, s := range subscribers {
+			if s.id == id {
+				b.subscribers[topic] = append(subscribers[:i:i], subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish publishes the value to the subscribers of the topic, the source is the ID of the publisher strategy.
+func (b *MessageBus) Publish(topic, source string, value interface{}) {
+	signal := Signal{
+		Topic:  topic,
+		Source: source,
+		Value:  value,
+		Time:   time.Now(),
+	}
+
+	b.mu.Lock()
+	b.lastSignals[topic] = signal
+	var callbacks []func(signal Signal)
+	for _, s := range b.subscribers[topic] {
+		callbacks = append(callbacks, s.cb)
+	}
+	for _, s := range b.subscribers[TopicAll] {
+		callbacks = append(callbacks, s.cb)
+	}
+	b.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(signal)
+	}
+}
+
+// Last returns the last published signal of the topic,
+// the strategies started after the publisher can use this to get the current state.
+func (b *MessageBus) Last(topic string) (Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	signal, ok := b.lastSignals[topic]
+	return signal, ok
+}
+
+// PublishTrade publishes the trade of the session on TopicTrades
+func (b *MessageBus) PublishTrade(session string, trade types.Trade) {
+	b.Publish(TopicTrades, session, TradeEvent{Session: session, Trade: trade})
+}
+
+// SubscribeTrades registers the callback of the trades published on TopicTrades
+func (b *MessageBus) SubscribeTrades(cb func(session string, trade types.Trade)) (unsubscribe func()) {
+	return b.Subscribe(TopicTrades, func(signal Signal) {
+		if event, ok := signal.Value.(TradeEvent); ok {
+			cb(event.Session, event.Trade)
+		}
+	})
+}
+
+// PublishPosition publishes a copy of the position on TopicPositions, so that the subscribers can keep it
+func (b *MessageBus) PublishPosition(source string, position *Position) {
+	p := *position
+	p.Lots = append([]PositionLot(nil), position.Lots...)
+	b.Publish(TopicPositions, source, p)
+}
+
+// SubscribePositions registers the callback of the positions published on TopicPositions
+func (b *MessageBus) SubscribePositions(cb func(source string, position Position)) (unsubscribe func()) {
+	return b.Subscribe(TopicPositions, func(signal Signal) {
+		if position, ok := signal.Value.(Position); ok {
+			cb(signal.Source, position)
+		}
+	})
+}
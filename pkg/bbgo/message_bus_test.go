@@ -0,0 +1,81 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMessageBus(t *testing.T) {
+	bus := NewMessageBus()
+
+	_, ok := bus.Last("regime")
+	assert.False(t, ok)
+
+	var received []Signal
+	bus.Subscribe("regime", func(signal Signal) {
+		received = append(received, signal)
+	})
+
+	bus.Publish("regime", "trend", "trending")
+	bus.Publish("other", "trend", "ignored")
+
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, "trend", received[0].Source)
+		assert.Equal(t, "trending", received[0].Value)
+	}
+
+	last, ok := bus.Last("regime")
+	assert.True(t, ok)
+	assert.Equal(t, "trending", last.Value)
+}
+
+func TestMessageBus_Unsubscribe(t *testing.T) {
+	bus := NewMessageBus()
+
+	var count, allCount int
+	unsubscribe := bus.Subscribe("regime", func(signal Signal) { count++ })
+	bus.Subscribe(TopicAll, func(signal Signal) { allCount++ })
+
+	bus.Publish("regime", "trend", "trending")
+	unsubscribe()
+	bus.Publish("regime", "trend", "ranging")
+	bus.Publish("other", "trend", "ignored")
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 3, allCount)
+}
+
+func TestMessageBus_TypedTopics(t *testing.T) {
+	bus := NewMessageBus()
+
+	var trades []types.Trade
+	bus.SubscribeTrades(func(session string, trade types.Trade) {
+		assert.Equal(t, "binance", session)
+		trades = append(trades, trade)
+	})
+
+	var positions []Position
+	bus.SubscribePositions(func(source string, position Position) {
+		positions = append(positions, position)
+	})
+
+	bus.PublishTrade("binance", types.Trade{ID: 1, Symbol: "BTCUSDT", Price: 30000, Quantity: 0.1})
+
+	position := &Position{Symbol: "BTCUSDT", Lots: []PositionLot{{Quantity: fixedpoint.NewFromFloat(0.1)}}}
+	bus.PublishPosition("binance", position)
+
+	// the published position is a copy
+	position.Lots[0].Quantity = fixedpoint.NewFromFloat(0.2)
+
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, int64(1), trades[0].ID)
+	}
+
+	if assert.Len(t, positions, 1) {
+		assert.Equal(t, 0.1, positions[0].Lots[0].Quantity.Float64())
+	}
+}
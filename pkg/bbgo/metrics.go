@@ -0,0 +1,367 @@
+package bbgo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMetricsBind = "localhost:9090"
+
+var (
+	metricsOrderSubmits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bbgo_order_submits_total",
+		Help: "The number of the submitted orders",
+	}, []string{"exchange", "session", "result"})
+
+	metricsOrderSubmitLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bbgo_order_submit_duration_seconds",
+		Help:    "The latency of the order submission API calls",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"exchange", "session"})
+
+	metricsOrderCancels = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bbgo_order_cancels_total",
+		Help: "The number of the canceled orders",
+	}, []string{"exchange", "session", "result"})
+
+	metricsOrderCancelLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bbgo_order_cancel_duration_seconds",
+		Help:    "The latency of the order cancellation API calls",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"exchange", "session"})
+
+	metricsStreamReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bbgo_stream_reconnects_total",
+		Help: "The number of the websocket reconnections",
+	}, []string{"exchange", "session"})
+
+	metricsActiveOrders = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bbgo_active_orders",
+		Help: "The number of the active orders of the strategy",
+	}, []string{"strategy", "session", "symbol"})
+
+	metricsBalances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bbgo_balance",
+		Help: "The available and the locked balances of the session",
+	}, []string{"exchange", "session", "currency", "type"})
+
+	metricsIndicators = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bbgo_indicator",
+		Help: "The last values of the standard indicators",
+	}, []string{"session", "symbol", "indicator", "interval", "window"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		metricsOrderSubmits,
+		metricsOrderSubmitLatency,
+		metricsOrderCancels,
+		metricsOrderCancelLatency,
+		metricsStreamReconnects,
+		metricsActiveOrders,
+		metricsBalances,
+		metricsIndicators,
+	)
+}
+
+// MetricsExporter exports the order, the stream, the balance and the indicator metrics of the sessions
+// through the Prometheus /metrics endpoint.
+type MetricsExporter struct {
+	Bind string
+}
+
+func NewMetricsExporter(conf *MetricsConfig) *MetricsExporter {
+	bind := conf.Bind
+	if len(bind) == 0 {
+		bind = defaultMetricsBind
+	}
+
+	return &MetricsExporter{Bind: bind}
+}
+
+// BindSession wraps the exchange of the session for the order metrics and binds the session stream,
+// it should be called before the session is connected.
+func (e *MetricsExporter) BindSession(session *ExchangeSession) {
+	session.Exchange = NewMeteredExchange(session.Name, session.Exchange)
+
+	exchangeName := session.ExchangeName
+
+	var connected bool
+	session.Stream.OnConnect(func() {
+		if connected {
+			metricsStreamReconnects.WithLabelValues(exchangeName, session.Name).Inc()
+		}
+		connected = true
+	})
+
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		updateIndicatorMetrics(session, kline)
+	})
+
+	if dispatcher, ok := session.Stream.(*types.DispatchStream); ok {
+		registerDispatcherMetrics(exchangeName, session.Name, dispatcher)
+	}
+
+	if session.PublicOnly {
+		return
+	}
+
+	updateBalanceMetrics(exchangeName, session.Name, session.Account.Balances())
+
+	session.Stream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		updateBalanceMetrics(exchangeName, session.Name, balances)
+	})
+
+	session.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		updateBalanceMetrics(exchangeName, session.Name, balances)
+	})
+}
+
+// Run serves the /metrics endpoint until the context is done
+func (e *MetricsExporter) Run(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{Addr: e.Bind, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Errorf("metrics server shutdown error")
+		}
+	}()
+
+	log.Infof("serving metrics on http://%s/metrics", e.Bind)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.WithError(err).Errorf("metrics server error")
+	}
+}
+
+// registerDispatcherMetrics registers the back-pressure metrics of the session event dispatcher
+func registerDispatcherMetrics(exchangeName, sessionName string, dispatcher *types.DispatchStream) {
+	labels := prometheus.Labels{"exchange": exchangeName, "session": sessionName}
+
+	collectors := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "bbgo_stream_dispatch_queue_length",
+			Help:        "The number of the stream events waiting in the dispatcher queues",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(dispatcher.Stats().QueueLength)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "bbgo_stream_dispatched_events_total",
+			Help:        "The number of the stream events passed to the callbacks",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(dispatcher.Stats().Dispatched)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "bbgo_stream_dispatch_blocked_total",
+			Help:        "The number of the stream events that waited for a full dispatcher queue",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(dispatcher.Stats().Blocked)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "bbgo_stream_dispatch_blocked_seconds_total",
+			Help:        "The total time spent on waiting for the full dispatcher queues",
+			ConstLabels: labels,
+		}, func() float64 {
+			return dispatcher.Stats().BlockedTime.Seconds()
+		}),
+	}
+
+	for _, collector := range collectors {
+		if err := prometheus.Register(collector); err != nil {
+			log.WithError(err).Errorf("can not register the dispatcher metrics of session %s", sessionName)
+		}
+	}
+}
+
+func updateBalanceMetrics(exchangeName, sessionName string, balances types.BalanceMap) {
+	for currency, balance := range balances {
+		metricsBalances.WithLabelValues(exchangeName, sessionName, currency, "available").Set(balance.Available.Float64())
+		metricsBalances.WithLabelValues(exchangeName, sessionName, currency, "locked").Set(balance.Locked.Float64())
+	}
+}
+
+func updateIndicatorMetrics(session *ExchangeSession, kline types.KLine) {
+	set, ok := session.StandardIndicatorSet(kline.Symbol)
+	if !ok {
+		return
+	}
+
+	for iw, inc := range set.sma {
+		if iw.Interval != kline.Interval {
+			continue
+		}
+
+		if value, ok := inc.Last(); ok {
+			metricsIndicators.WithLabelValues(session.Name, kline.Symbol, "sma", string(iw.Interval), strconv.Itoa(iw.Window)).Set(value)
+		}
+	}
+
+	for iw, inc := range set.ewma {
+		if iw.Interval != kline.Interval {
+			continue
+		}
+
+		if value, ok := inc.Last(); ok {
+			metricsIndicators.WithLabelValues(session.Name, kline.Symbol, "ewma", string(iw.Interval), strconv.Itoa(iw.Window)).Set(value)
+		}
+	}
+}
+
+// MeteredExchange wraps the exchange and measures the order submission and the order cancellation API calls
+type MeteredExchange struct {
+	types.Exchange
+
+	SessionName string
+}
+
+// meteredMarginExchange keeps the margin interface of the wrapped exchange
+type meteredMarginExchange struct {
+	*MeteredExchange
+	types.MarginExchange
+}
+
+// NewMeteredExchange wraps the given exchange with the order metrics,
+// the margin interface is preserved if the wrapped exchange supports margin.
+func NewMeteredExchange(sessionName string, exchange types.Exchange) types.Exchange {
+	metered := &MeteredExchange{
+		Exchange:    exchange,
+		SessionName: sessionName,
+	}
+
+	if marginExchange, ok := exchange.(types.MarginExchange); ok {
+		return &meteredMarginExchange{
+			MeteredExchange: metered,
+			MarginExchange:  marginExchange,
+		}
+	}
+
+	return metered
+}
+
+// Capabilities returns the capabilities of the wrapped exchange
+func (e *MeteredExchange) Capabilities() types.CapabilitySet {
+	return types.ExchangeCapabilities(e.Exchange)
+}
+
+func (e *MeteredExchange) MaxOrdersPerBatch() int {
+	return types.MaxOrdersPerBatch(e.Exchange)
+}
+
+func (e *MeteredExchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	startTime := time.Now()
+	createdOrders, err := e.Exchange.SubmitOrders(ctx, orders...)
+
+	exchangeName := e.Exchange.Name().String()
+	metricsOrderSubmitLatency.WithLabelValues(exchangeName, e.SessionName).Observe(time.Since(startTime).Seconds())
+	metricsOrderSubmits.WithLabelValues(exchangeName, e.SessionName, "success").Add(float64(len(createdOrders)))
+	if numFailed := len(orders) - len(createdOrders); numFailed > 0 {
+		metricsOrderSubmits.WithLabelValues(exchangeName, e.SessionName, "error").Add(float64(numFailed))
+	}
+
+	return createdOrders, err
+}
+
+func (e *MeteredExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	startTime := time.Now()
+	err := e.Exchange.CancelOrders(ctx, orders...)
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	exchangeName := e.Exchange.Name().String()
+	metricsOrderCancelLatency.WithLabelValues(exchangeName, e.SessionName).Observe(time.Since(startTime).Seconds())
+	metricsOrderCancels.WithLabelValues(exchangeName, e.SessionName, result).Add(float64(len(orders)))
+	return err
+}
+
+// MetricsOrderExecutor wraps the order executor of the strategy and counts the active orders of the strategy by symbol
+type MetricsOrderExecutor struct {
+	OrderExecutor
+
+	StrategyID  string
+	SessionName string
+
+	mu     sync.Mutex
+	orders map[uint64]string
+}
+
+func NewMetricsOrderExecutor(executor OrderExecutor, strategyID, sessionName string) *MetricsOrderExecutor {
+	return &MetricsOrderExecutor{
+		OrderExecutor: executor,
+		StrategyID:    strategyID,
+		SessionName:   sessionName,
+		orders:        make(map[uint64]string),
+	}
+}
+
+func (e *MetricsOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(e.handleOrderUpdate)
+}
+
+func (e *MetricsOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, orders...)
+
+	e.mu.Lock()
+	for _, o := range createdOrders {
+		if isActiveOrderStatus(o.Status) {
+			e.orders[o.OrderID] = o.Symbol
+		}
+	}
+
+	for _, o := range createdOrders {
+		e.updateGauge(o.Symbol)
+	}
+	e.mu.Unlock()
+
+	return createdOrders, err
+}
+
+func (e *MetricsOrderExecutor) handleOrderUpdate(order types.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	symbol, ok := e.orders[order.OrderID]
+	if !ok || isActiveOrderStatus(order.Status) {
+		return
+	}
+
+	delete(e.orders, order.OrderID)
+	e.updateGauge(symbol)
+}
+
+// updateGauge sets the active order count of the symbol, the caller should hold the lock
+func (e *MetricsOrderExecutor) updateGauge(symbol string) {
+	var count int
+	for _, s := range e.orders {
+		if s == symbol {
+			count++
+		}
+	}
+
+	metricsActiveOrders.WithLabelValues(e.StrategyID, e.SessionName, symbol).Set(float64(count))
+}
+
+func isActiveOrderStatus(status types.OrderStatus) bool {
+	return status == "" || status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled
+}
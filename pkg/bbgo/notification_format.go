@@ -0,0 +1,45 @@
+package bbgo
+
+import (
+	"text/template"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// NotificationFormatConfig customizes the display of the amounts and the messages in the notifications
+type NotificationFormatConfig struct {
+	// Currencies overrides the display format of the currency amounts, e.g., the decimal places of JPY and TWD
+	Currencies map[string]types.CurrencyFormat `json:"currencies,omitempty" yaml:"currencies,omitempty"`
+
+	// Messages maps the English notification messages to the localized ones
+	Messages map[string]string `json:"messages,omitempty" yaml:"messages,omitempty"`
+}
+
+func (c *NotificationFormatConfig) Apply() {
+	for currency, format := range c.Currencies {
+		types.SetCurrencyFormat(currency, format)
+	}
+
+	if len(c.Messages) > 0 {
+		types.SetTranslations(c.Messages)
+	}
+}
+
+// NotificationTemplateFuncs are the functions available in the notification templates:
+//
+//	{{ t "Trade Execution" }} - the localized message
+//	{{ price .Symbol .Price }} - the price formatted with the quote currency of the symbol
+//	{{ amount "TWD" .Amount }} - the amount formatted with the currency
+var NotificationTemplateFuncs = template.FuncMap{
+	"t": types.Translate,
+	"price": func(symbol string, val float64) string {
+		return types.FormatCurrencyAmount(types.QuoteCurrencyOf(symbol), val)
+	},
+	"amount": types.FormatCurrencyAmount,
+}
+
+// RenderNotificationTemplate renders the notification template with NotificationTemplateFuncs
+func RenderNotificationTemplate(tpl string, args interface{}) string {
+	return util.RenderWithFuncs(tpl, NotificationTemplateFuncs, args)
+}
@@ -0,0 +1,25 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRenderNotificationTemplate(t *testing.T) {
+	conf := &NotificationFormatConfig{
+		Currencies: map[string]types.CurrencyFormat{
+			"JPY": {Symbol: "JPY ", Precision: 1},
+		},
+	}
+	conf.Apply()
+	defer types.SetCurrencyFormat("JPY", types.CurrencyFormat{Symbol: "¥ ", Precision: 0})
+
+	trade := types.Trade{Symbol: "BTCJPY", Side: types.SideTypeSell, Price: 4567890.12}
+	assert.Equal(t, ":handshake: BTCJPY SELL Trade Execution @ JPY 4,567,890.1", RenderNotificationTemplate(TemplateTradeReport, trade))
+
+	order := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCTWD", Side: types.SideTypeBuy, Price: 1234567.0}}
+	assert.Equal(t, ":handshake: BTCTWD BUY Order Update @ NT$ 1,234,567.00", RenderNotificationTemplate(TemplateOrderReport, order))
+}
@@ -0,0 +1,285 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultNotificationRetryInterval = time.Minute
+
+const defaultNotificationQueueSize = 1000
+
+// ReliableNotifier is the notifier that reports the delivery errors, the undelivered messages can be queued and replayed later.
+type ReliableNotifier interface {
+	Notifier
+	TryNotifyTo(channel, format string, args ...interface{}) error
+}
+
+// NotificationQueueConfig enables the persistent queue of the notifications that can not be delivered,
+// the queued notifications are replayed as a digest when the notifier is reachable again.
+type NotificationQueueConfig struct {
+	// Dir is the directory of the queue files, defaults to ~/.bbgo/notifications
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+
+	// RetryInterval is the interval of the replay attempts, defaults to 1 minute
+	RetryInterval types.Duration `json:"retryInterval,omitempty" yaml:"retryInterval,omitempty"`
+
+	// MaxMessages is the max number of the queued messages, the oldest messages are dropped when the queue is full, defaults to 1000
+	MaxMessages int `json:"maxMessages,omitempty" yaml:"maxMessages,omitempty"`
+}
+
+type QueuedNotification struct {
+	Channel string    `json:"channel,omitempty"`
+	Text    string    `json:"text"`
+	Time    time.Time `json:"time"`
+}
+
+// QueuedNotifier delivers the notifications through the underlying notifier, the notifications that fail to be delivered
+// are saved to the queue file and replayed with the digest collapsing when the notifier recovers.
+// The new notifications are queued while the queue is not empty, to keep the notification order.
+type QueuedNotifier struct {
+	notifier    ReliableNotifier
+	file        string
+	maxMessages int
+
+	mu    sync.Mutex
+	queue []QueuedNotification
+}
+
+// NewQueuedNotifier creates the queued notifier, the notifications queued before the restart are loaded from the file
+func NewQueuedNotifier(notifier ReliableNotifier, file string, maxMessages int) (*QueuedNotifier, error) {
+	if maxMessages <= 0 {
+		maxMessages = defaultNotificationQueueSize
+	}
+
+	n := &QueuedNotifier{
+		notifier:    notifier,
+		file:        file,
+		maxMessages: maxMessages,
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return n, nil
+		}
+
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &n.queue); err != nil {
+			return nil, fmt.Errorf("can not load the notification queue %s: %w", file, err)
+		}
+	}
+
+	return n, nil
+}
+
+// Len returns the number of the queued notifications
+func (n *QueuedNotifier) Len() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.queue)
+}
+
+func (n *QueuedNotifier) Notify(format string, args ...interface{}) {
+	n.NotifyTo("", format, args...)
+}
+
+func (n *QueuedNotifier) NotifyTo(channel, format string, args ...interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.queue) == 0 {
+		err := n.notifier.TryNotifyTo(channel, format, args...)
+		if err == nil {
+			return
+		}
+
+		log.WithError(err).Warnf("notification delivery failed, queueing the notifications until the notifier recovers")
+	}
+
+	n.enqueue(QueuedNotification{
+		Channel: channel,
+		Text:    renderNotification(format, args...),
+		Time:    time.Now(),
+	})
+}
+
+func (n *QueuedNotifier) enqueue(notification QueuedNotification) {
+	n.queue = append(n.queue, notification)
+	if len(n.queue) > n.maxMessages {
+		n.queue = n.queue[len(n.queue)-n.maxMessages:]
+	}
+
+	if err := n.save(); err != nil {
+		log.WithError(err).Errorf("can not save the notification queue %s", n.file)
+	}
+}
+
+func (n *QueuedNotifier) save() error {
+	data, err := json.Marshal(n.queue)
+	if err != nil {
+		return err
+	}
+
+	// write to the temporary file and rename it, so that the queue file is never half written
+	tmp := n.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, n.file)
+}
+
+// Replay sends the queued notifications as one digest message per channel,
+// the delivered notifications are removed from the queue.
+func (n *QueuedNotifier) Replay() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.queue) == 0 {
+		return nil
+	}
+
+	var channels []string
+	var groups = make(map[string][]QueuedNotification)
+	for _, notification := range n.queue {
+		if _, ok := groups[notification.Channel]; !ok {
+			channels = append(channels, notification.Channel)
+		}
+
+		groups[notification.Channel] = append(groups[notification.Channel], notification)
+	}
+
+	var err error
+	var remaining []QueuedNotification
+	for _, channel := range channels {
+		notifications := groups[channel]
+		if err != nil {
+			remaining = append(remaining, notifications...)
+			continue
+		}
+
+		if err = n.notifier.TryNotifyTo(channel, "%s", NotificationDigest(notifications)); err != nil {
+			remaining = append(remaining, notifications...)
+		}
+	}
+
+	n.queue = remaining
+	if err2 := n.save(); err2 != nil {
+		log.WithError(err2).Errorf("can not save the notification queue %s", n.file)
+	}
+
+	return err
+}
+
+// Run replays the queued notifications periodically until the context is done
+func (n *QueuedNotifier) Run(ctx context.Context, interval time.Duration) {
+	if interval == 0 {
+		interval = defaultNotificationRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if n.Len() == 0 {
+				continue
+			}
+
+			if err := n.Replay(); err != nil {
+				log.WithError(err).Warnf("notifier is still unreachable, %d notifications are queued", n.Len())
+				continue
+			}
+
+			log.Infof("queued notifications are replayed")
+		}
+	}
+}
+
+// NotificationDigest collapses the repeated notifications into one line with the repeat count and the time of the first occurrence
+func NotificationDigest(notifications []QueuedNotification) string {
+	if len(notifications) == 0 {
+		return ""
+	}
+
+	var texts []string
+	var counts = make(map[string]int)
+	var firstSeen = make(map[string]time.Time)
+	for _, notification := range notifications {
+		if _, ok := counts[notification.Text]; !ok {
+			texts = append(texts, notification.Text)
+			firstSeen[notification.Text] = notification.Time
+		}
+
+		counts[notification.Text]++
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d notifications were queued during the notifier outage since %s:\n",
+		len(notifications), notifications[0].Time.Format(time.RFC3339)))
+
+	for _, text := range texts {
+		sb.WriteString(fmt.Sprintf("[%s] %s", firstSeen[text].Format("15:04:05"), text))
+		if count := counts[text]; count > 1 {
+			sb.WriteString(fmt.Sprintf(" (x%d)", count))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renderNotification renders the notification as the plain text, the arguments after the first types.PlainText are appended as lines
+func renderNotification(format string, args ...interface{}) string {
+	var texts []string
+	var textArgsOffset = -1
+	for idx, arg := range args {
+		if a, ok := arg.(types.PlainText); ok {
+			if textArgsOffset == -1 {
+				textArgsOffset = idx
+			}
+
+			texts = append(texts, a.PlainText())
+		}
+	}
+
+	var simpleArgs = args
+	if textArgsOffset > -1 {
+		simpleArgs = args[:textArgsOffset]
+	}
+
+	return strings.Join(append([]string{fmt.Sprintf(format, simpleArgs...)}, texts...), "\n")
+}
+
+// NotificationQueueFile returns the queue file of the notifier in the directory, the directory is created if it doesn't exist
+func NotificationQueueFile(dir, name string) (string, error) {
+	if dir == "" {
+		dir = filepath.Join(HomeDir(), "notifications")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name+".json"), nil
+}
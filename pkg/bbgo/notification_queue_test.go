@@ -0,0 +1,89 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyNotifier struct {
+	down     bool
+	messages []string
+}
+
+func (n *flakyNotifier) Notify(format string, args ...interface{}) {
+	_ = n.TryNotifyTo("", format, args...)
+}
+
+func (n *flakyNotifier) NotifyTo(channel, format string, args ...interface{}) {
+	_ = n.TryNotifyTo(channel, format, args...)
+}
+
+func (n *flakyNotifier) TryNotifyTo(channel, format string, args ...interface{}) error {
+	if n.down {
+		return errors.New("notifier is unreachable")
+	}
+
+	n.messages = append(n.messages, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func TestQueuedNotifier(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.json")
+
+	notifier := &flakyNotifier{}
+	queued, err := NewQueuedNotifier(notifier, file, 10)
+	assert.NoError(t, err)
+
+	queued.Notify("hello")
+	assert.Equal(t, []string{"hello"}, notifier.messages)
+	assert.Equal(t, 0, queued.Len())
+
+	// the notifications are queued during the outage
+	notifier.down = true
+	queued.Notify("connection lost")
+	queued.Notify("connection lost")
+	queued.Notify("order %d filled", 1)
+	assert.Equal(t, 3, queued.Len())
+	assert.Error(t, queued.Replay())
+
+	// the queue survives the restart
+	restored, err := NewQueuedNotifier(notifier, file, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored.Len())
+
+	// the notifications are still queued after the recovery to keep the order, until they are replayed
+	notifier.down = false
+	restored.Notify("recovered")
+	assert.Equal(t, 4, restored.Len())
+
+	assert.NoError(t, restored.Replay())
+	assert.Equal(t, 0, restored.Len())
+	if assert.Len(t, notifier.messages, 2) {
+		digest := notifier.messages[1]
+		assert.Contains(t, digest, "4 notifications were queued")
+		assert.Contains(t, digest, "connection lost (x2)")
+		assert.Contains(t, digest, "order 1 filled")
+		assert.True(t, strings.Index(digest, "order 1 filled") < strings.Index(digest, "recovered"))
+	}
+}
+
+func TestQueuedNotifier_MaxMessages(t *testing.T) {
+	notifier := &flakyNotifier{down: true}
+	queued, err := NewQueuedNotifier(notifier, filepath.Join(t.TempDir(), "test.json"), 2)
+	assert.NoError(t, err)
+
+	queued.Notify("message 1")
+	queued.Notify("message 2")
+	queued.Notify("message 3")
+	assert.Equal(t, 2, queued.Len())
+
+	notifier.down = false
+	assert.NoError(t, queued.Replay())
+	assert.NotContains(t, notifier.messages[0], "message 1")
+	assert.Contains(t, notifier.messages[0], "message 3")
+}
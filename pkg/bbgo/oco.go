@@ -0,0 +1,232 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// emulatedOCOPair is the oco order pair emulated locally, only the take-profit limit order is placed on the exchange,
+// and the stop-loss order is submitted when the price crosses the stop price.
+type emulatedOCOPair struct {
+	ctx   context.Context
+	order types.SubmitOCOOrder
+
+	limitOrder types.Order
+
+	// submitted is set when the take-profit limit order is created
+	submitted bool
+}
+
+// stopTriggered checks if the price crosses the stop price of the pair
+func (p *emulatedOCOPair) stopTriggered(price float64) bool {
+	if p.order.Side == types.SideTypeSell {
+		return price <= p.order.StopPrice
+	}
+
+	return price >= p.order.StopPrice
+}
+
+// OCOOrderExecutor submits the one-cancels-other order pairs. The native oco orders are used if the session supports them,
+// otherwise the pair is emulated: the take-profit limit order is submitted through the wrapped executor, and when the price
+// crosses the stop price, the limit order is canceled and the stop-loss order is submitted with the remaining quantity.
+// The limit order is not canceled if it's partially filled, the stop-loss order is dropped instead, same as the native oco orders.
+// The emulation only holds the balance for one leg, so the stop-loss order is not on the exchange order book while bbgo is not running.
+type OCOOrderExecutor struct {
+	OrderExecutor
+
+	Session *ExchangeSession
+
+	// Emulate emulates the pairs locally even if the exchange supports the native oco orders
+	Emulate bool
+
+	mu sync.Mutex
+
+	// pairs are the emulated pairs waiting for the fill or the stop price, keyed by the client order id of the limit order
+	pairs map[string]*emulatedOCOPair
+}
+
+func NewOCOOrderExecutor(executor OrderExecutor, session *ExchangeSession) *OCOOrderExecutor {
+	return &OCOOrderExecutor{
+		OrderExecutor: executor,
+		Session:       session,
+		pairs:         make(map[string]*emulatedOCOPair),
+	}
+}
+
+// BindStream binds the order updates and the price updates of the stream, the stop prices of the emulated pairs
+// are checked with the kline updates and the public trades of the subscribed symbols.
+func (e *OCOOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(e.handleOrderUpdate)
+
+	stream.OnKLine(func(kline types.KLine) {
+		e.handlePrice(kline.Symbol, kline.Close)
+	})
+
+	stream.OnKLineClosed(func(kline types.KLine) {
+		e.handlePrice(kline.Symbol, kline.Close)
+	})
+
+	stream.OnMarketTrade(func(trade types.Trade) {
+		e.handlePrice(trade.Symbol, trade.Price)
+	})
+}
+
+func (e *OCOOrderExecutor) native() (types.OCOExchange, bool) {
+	if e.Emulate || !e.Session.Capabilities().Has(types.CapabilityOCO) {
+		return nil, false
+	}
+
+	exchange, ok := e.Session.Exchange.(types.OCOExchange)
+	return exchange, ok
+}
+
+// SubmitOCOOrders submits the order pairs, the created orders of each pair are returned, for the emulated pair,
+// only the take-profit limit order is created.
+func (e *OCOOrderExecutor) SubmitOCOOrders(ctx context.Context, orders ...types.SubmitOCOOrder) (ocoOrders []types.OCOOrder, err error) {
+	for _, order := range orders {
+		market, ok := e.Session.Market(order.Symbol)
+		if !ok {
+			return ocoOrders, fmt.Errorf("market is not defined: %s", order.Symbol)
+		}
+
+		order.Market = market
+		if err := order.Validate(); err != nil {
+			return ocoOrders, err
+		}
+
+		var ocoOrder *types.OCOOrder
+		if exchange, ok := e.native(); ok {
+			ocoOrder, err = exchange.SubmitOCOOrder(ctx, order)
+			e.Session.audit(service.AuditActionSubmitOCOOrder, order, ocoOrder, err)
+		} else {
+			ocoOrder, err = e.submitEmulated(ctx, order)
+		}
+
+		if err != nil {
+			return ocoOrders, err
+		}
+
+		ocoOrders = append(ocoOrders, *ocoOrder)
+	}
+
+	return ocoOrders, nil
+}
+
+func (e *OCOOrderExecutor) submitEmulated(ctx context.Context, order types.SubmitOCOOrder) (*types.OCOOrder, error) {
+	if len(order.ClientOrderID) == 0 {
+		order.ClientOrderID = uuid.New().String()
+	}
+
+	limitOrder := order.LimitOrder()
+	limitOrder.ClientOrderID = order.ClientOrderID
+
+	pair := &emulatedOCOPair{ctx: ctx, order: order}
+
+	// watch the pair before submitting, so that the order updates arriving before the response are not missed
+	e.mu.Lock()
+	e.pairs[limitOrder.ClientOrderID] = pair
+	e.mu.Unlock()
+
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, limitOrder)
+	if err != nil || len(createdOrders) == 0 {
+		e.mu.Lock()
+		delete(e.pairs, limitOrder.ClientOrderID)
+		e.mu.Unlock()
+
+		if err == nil {
+			err = fmt.Errorf("the take-profit order of the oco order %s is not created", order.ClientOrderID)
+		}
+
+		return nil, err
+	}
+
+	e.mu.Lock()
+	if pair.limitOrder.OrderID == 0 {
+		pair.limitOrder = createdOrders[0]
+	}
+	pair.submitted = true
+	e.mu.Unlock()
+
+	log.Infof("emulating %s %s oco order %s: take profit at %f, stop at %f", order.Symbol, order.Side, order.ClientOrderID, order.Price, order.StopPrice)
+
+	return &types.OCOOrder{
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Orders:        createdOrders,
+	}, nil
+}
+
+func (e *OCOOrderExecutor) handleOrderUpdate(order types.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	pair, ok := e.pairs[order.ClientOrderID]
+	if !ok {
+		return
+	}
+
+	switch order.Status {
+	case types.OrderStatusPartiallyFilled, types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		// the take-profit leg is (partially) filled, or it's closed without the stop-loss, drop the stop-loss leg
+		delete(e.pairs, order.ClientOrderID)
+
+	default:
+		pair.limitOrder = order
+	}
+}
+
+func (e *OCOOrderExecutor) handlePrice(symbol string, price float64) {
+	var triggered []*emulatedOCOPair
+
+	e.mu.Lock()
+	for clientOrderID, pair := range e.pairs {
+		if pair.order.Symbol != symbol || !pair.submitted || !pair.stopTriggered(price) {
+			continue
+		}
+
+		delete(e.pairs, clientOrderID)
+		triggered = append(triggered, pair)
+	}
+	e.mu.Unlock()
+
+	for _, pair := range triggered {
+		go e.triggerStop(pair, price)
+	}
+}
+
+// triggerStop cancels the take-profit limit order and submits the stop-loss order
+func (e *OCOOrderExecutor) triggerStop(pair *emulatedOCOPair, price float64) {
+	order := pair.order
+	log.Infof("the stop price %f of the %s %s oco order %s is crossed at %f, canceling the take-profit order", order.StopPrice, order.Symbol, order.Side, order.ClientOrderID, price)
+
+	if err := e.Session.Exchange.CancelOrders(pair.ctx, pair.limitOrder); err != nil {
+		log.WithError(err).Errorf("can not cancel the take-profit order of the oco order %s, the stop-loss order is not submitted", order.ClientOrderID)
+		return
+	}
+
+	// the pair is dropped once the limit order is partially filled, so the stop-loss order takes the full quantity
+	stopOrder := types.SubmitOrder{
+		Symbol:   order.Symbol,
+		Side:     order.Side,
+		Type:     types.OrderTypeMarket,
+		Quantity: order.Quantity,
+		Market:   order.Market,
+	}
+
+	// the stop price is already crossed, so the stop limit order is submitted as the limit order
+	if order.StopLimitPrice > 0 {
+		stopOrder.Type = types.OrderTypeLimit
+		stopOrder.Price = order.StopLimitPrice
+	}
+
+	if _, err := e.OrderExecutor.SubmitOrders(pair.ctx, stopOrder); err != nil {
+		log.WithError(err).Errorf("can not submit the stop-loss order of the oco order %s", order.ClientOrderID)
+	}
+}
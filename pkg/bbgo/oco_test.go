@@ -0,0 +1,80 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// directOrderExecutor submits the orders to the exchange without formatting
+type directOrderExecutor struct {
+	exchange types.Exchange
+}
+
+func (e *directOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	return e.exchange.SubmitOrders(ctx, orders...)
+}
+
+func (e *directOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *directOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+func TestOCOOrderExecutor_Emulated(t *testing.T) {
+	ctx := context.Background()
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(2.0)},
+	})
+
+	session := &ExchangeSession{Exchange: exchange, markets: markets}
+	executor := NewOCOOrderExecutor(&directOrderExecutor{exchange: exchange}, session)
+	executor.BindStream(exchange.NewStream())
+
+	// the take-profit price should be above the stop price for selling
+	_, err := executor.SubmitOCOOrders(ctx, types.SubmitOCOOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 1.0, Price: 9000.0, StopPrice: 9500.0})
+	assert.Error(t, err)
+
+	ocoOrders, err := executor.SubmitOCOOrders(ctx, types.SubmitOCOOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 1.0, Price: 11000.0, StopPrice: 9000.0})
+	if !assert.NoError(t, err) || !assert.Len(t, ocoOrders, 1) {
+		return
+	}
+
+	// only the take-profit order is placed
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 1)
+
+	exchange.PushKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: 10000.0, EndTime: time.Now()})
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 1)
+
+	// the stop price is crossed, the take-profit order is canceled and the stop-loss order is filled at the market price
+	exchange.PushKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: 8900.0, EndTime: time.Now(), Closed: true})
+	assert.Eventually(t, func() bool {
+		return len(exchange.Trades("BTCUSDT")) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 0)
+	if trades := exchange.Trades("BTCUSDT"); assert.Len(t, trades, 1) {
+		assert.Equal(t, 8900.0, trades[0].Price)
+	}
+
+	// the partially filled take-profit order drops the stop-loss leg
+	ocoOrders, err = executor.SubmitOCOOrders(ctx, types.SubmitOCOOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 1.0, Price: 11000.0, StopPrice: 9000.0})
+	if !assert.NoError(t, err) || !assert.Len(t, ocoOrders, 1) {
+		return
+	}
+
+	_, err = exchange.Fill(ocoOrders[0].Orders[0].OrderID, 0.5)
+	assert.NoError(t, err)
+
+	exchange.PushKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, Close: 8000.0, EndTime: time.Now(), Closed: true})
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 1)
+	assert.Len(t, exchange.SubmittedOrders(), 3)
+}
@@ -0,0 +1,190 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultOrderSubmitWorkers is the number of the concurrent order submissions of a batch
+const DefaultOrderSubmitWorkers = 4
+
+// SubmitOrderResult is the submission result of an order in a batch, either Order or Error is set
+type SubmitOrderResult struct {
+	SubmitOrder types.SubmitOrder
+	Order       types.Order
+	Error       error
+}
+
+func (r SubmitOrderResult) Success() bool {
+	return r.Error == nil
+}
+
+// BatchSubmitError is returned by SubmitOrders when some of the orders are rejected,
+// the created orders of the batch are still returned with the error.
+type BatchSubmitError struct {
+	Results []SubmitOrderResult
+}
+
+// Failed returns the results of the rejected orders
+func (e *BatchSubmitError) Failed() (failed []SubmitOrderResult) {
+	for _, r := range e.Results {
+		if r.Error != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+func (e *BatchSubmitError) Error() string {
+	failed := e.Failed()
+	if len(failed) == 0 {
+		return "batch order submission failed"
+	}
+
+	return fmt.Sprintf("%d of %d orders failed: %s %s %s order: %v", len(failed), len(e.Results),
+		failed[0].SubmitOrder.Symbol, failed[0].SubmitOrder.Type, failed[0].SubmitOrder.Side, failed[0].Error)
+}
+
+// Unwrap returns the error of the first rejected order
+func (e *BatchSubmitError) Unwrap() error {
+	for _, r := range e.Results {
+		if r.Error != nil {
+			return r.Error
+		}
+	}
+	return nil
+}
+
+// submitOrdersConcurrently submits the orders with the batch api of the exchange if it's supported,
+// otherwise the orders are submitted one per request with a bounded worker pool.
+// The results are in the same order of the given orders.
+func submitOrdersConcurrently(ctx context.Context, exchange types.Exchange, workers int, orders []types.SubmitOrder) []SubmitOrderResult {
+	results := make([]SubmitOrderResult, len(orders))
+	if len(orders) == 0 {
+		return results
+	}
+
+	if submitter, ok := exchange.(types.BatchOrderSubmitter); ok {
+		return submitOrdersInBatches(ctx, submitter, types.MaxOrdersPerBatch(exchange), orders)
+	}
+
+	if workers <= 0 {
+		workers = DefaultOrderSubmitWorkers
+	}
+
+	if workers > len(orders) {
+		workers = len(orders)
+	}
+
+	indexC := make(chan int, len(orders))
+	for i := range orders {
+		indexC <- i
+	}
+	close(indexC)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexC {
+				results[i] = submitOrder(ctx, exchange, orders[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// submitOrdersInBatches submits the orders in the batch requests of the max orders per batch,
+// the orders of a failed batch request are all failed with the error of the request.
+func submitOrdersInBatches(ctx context.Context, submitter types.BatchOrderSubmitter, size int, orders []types.SubmitOrder) []SubmitOrderResult {
+	results := make([]SubmitOrderResult, len(orders))
+	if size <= 0 {
+		size = len(orders)
+	}
+
+	for start := 0; start < len(orders); start += size {
+		end := start + size
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		batch := orders[start:end]
+		createdOrders, errs, err := submitter.BatchSubmitOrders(ctx, batch...)
+
+		for i, order := range batch {
+			result := SubmitOrderResult{SubmitOrder: order}
+			switch {
+			case i < len(errs) && errs[i] != nil:
+				result.Error = errs[i]
+
+			case i < len(createdOrders) && createdOrders[i].OrderID > 0:
+				result.Order = createdOrders[i]
+				if len(order.Tags) > 0 {
+					result.Order.Tags = order.Tags
+				}
+
+			case err != nil:
+				result.Error = err
+
+			default:
+				result.Error = fmt.Errorf("no order is created for %s", order.String())
+			}
+
+			results[start+i] = result
+		}
+	}
+
+	return results
+}
+
+func submitOrder(ctx context.Context, exchange types.Exchange, order types.SubmitOrder) SubmitOrderResult {
+	result := SubmitOrderResult{SubmitOrder: order}
+
+	if err := ctx.Err(); err != nil {
+		result.Error = err
+		return result
+	}
+
+	createdOrders, err := exchange.SubmitOrders(ctx, order)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	if len(createdOrders) == 0 {
+		result.Error = fmt.Errorf("no order is created for %s", order.String())
+		return result
+	}
+
+	result.Order = createdOrders[0]
+	if len(order.Tags) > 0 {
+		result.Order.Tags = order.Tags
+	}
+
+	return result
+}
+
+// collectSubmitOrderResults returns the created orders of the results, and a *BatchSubmitError if any of the orders is rejected
+func collectSubmitOrderResults(results []SubmitOrderResult) (createdOrders types.OrderSlice, err error) {
+	var failed bool
+	for _, r := range results {
+		if r.Error != nil {
+			failed = true
+			continue
+		}
+
+		createdOrders = append(createdOrders, r.Order)
+	}
+
+	if failed {
+		return createdOrders, &BatchSubmitError{Results: results}
+	}
+
+	return createdOrders, nil
+}
@@ -0,0 +1,200 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeOrderExecutor_SubmitOrders_partialFailure(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, MinLot: 0.001, StepSize: 0.001, MinPrice: 0.01, TickSize: 0.01},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	// the session literal has no blacklist, the buy orders are not filtered
+	session := &ExchangeSession{Name: "binance", Exchange: exchange, markets: markets, orderTags: NewOrderTagMap()}
+	executor := &ExchangeOrderExecutor{Session: session}
+
+	orders := []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 9000.0, Tags: types.OrderTags{"grid_level": "1"}},
+		// the stop limit order is rejected by the mock exchange
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeStopLimit, Quantity: 0.01, Price: 8950.0, StopPrice: 8960.0},
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 8900.0, Tags: types.OrderTags{"grid_level": "2"}},
+	}
+
+	results, err := executor.SubmitOrdersWithResults(context.Background(), orders...)
+	if !assert.NoError(t, err) || !assert.Len(t, results, 3) {
+		return
+	}
+
+	// the results are in the order of the submitted orders
+	assert.True(t, results[0].Success())
+	assert.Equal(t, 9000.0, results[0].Order.Price)
+	assert.Equal(t, "9000.00", results[0].Order.PriceString)
+	assert.Equal(t, "0.010", results[0].Order.QuantityString)
+	assert.Equal(t, types.OrderTags{"grid_level": "1"}, results[0].Order.Tags)
+
+	assert.False(t, results[1].Success())
+	assert.Equal(t, types.OrderTypeStopLimit, results[1].SubmitOrder.Type)
+
+	assert.True(t, results[2].Success())
+	assert.Equal(t, 8900.0, results[2].Order.Price)
+
+	tags, ok := session.OrderTags(results[2].Order.OrderID)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderTags{"grid_level": "2"}, tags)
+
+	// the rejected order doesn't abort the rest of the batch
+	createdOrders, err := executor.SubmitOrders(context.Background(), orders...)
+	assert.Len(t, createdOrders, 2)
+
+	var batchErr *BatchSubmitError
+	if assert.True(t, errors.As(err, &batchErr)) {
+		failed := batchErr.Failed()
+		if assert.Len(t, failed, 1) {
+			assert.Equal(t, 8950.0, failed[0].SubmitOrder.Price)
+			assert.Equal(t, failed[0].Error, errors.Unwrap(err))
+		}
+	}
+}
+
+func TestSubmitOrdersConcurrently_sequential(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	var orders []types.SubmitOrder
+	for _, price := range []float64{9000.0, 8900.0, 8800.0, 8700.0} {
+		orders = append(orders, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: price})
+	}
+
+	// a single worker submits the orders in order
+	results := submitOrdersConcurrently(context.Background(), exchange, 1, orders)
+	for i, r := range results {
+		if assert.NoError(t, r.Error) {
+			assert.Equal(t, orders[i].Price, r.Order.Price)
+		}
+	}
+
+	submitted := exchange.SubmittedOrders()
+	if assert.Len(t, submitted, 4) {
+		for i, o := range submitted {
+			assert.Equal(t, orders[i].Price, o.Price)
+		}
+	}
+}
+
+type limitOnlyExchange struct {
+	*mock.Exchange
+}
+
+func (e *limitOnlyExchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(types.CapabilityUserDataStream)
+}
+
+func TestExchangeOrderExecutor_SubmitOrders_unsupportedOrder(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, MinLot: 0.001, StepSize: 0.001, MinPrice: 0.01, TickSize: 0.01},
+	}
+
+	exchange := &limitOnlyExchange{Exchange: mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})}
+
+	session := &ExchangeSession{Name: "binance", Exchange: exchange, markets: markets, orderTags: NewOrderTagMap()}
+	executor := &ExchangeOrderExecutor{Session: session}
+
+	results, err := executor.SubmitOrdersWithResults(context.Background(),
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 9000.0, PostOnly: true},
+		types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 8900.0},
+	)
+	if !assert.NoError(t, err) || !assert.Len(t, results, 2) {
+		return
+	}
+
+	// the post only order is rejected locally without being sent to the exchange
+	assert.True(t, errors.Is(results[0].Error, ErrUnsupportedOrder))
+	assert.True(t, results[1].Success())
+	assert.Len(t, exchange.SubmittedOrders(), 1)
+}
+
+// batchSubmitExchange creates the orders with the batch api of at most 2 orders per batch
+type batchSubmitExchange struct {
+	*mock.Exchange
+
+	batches [][]types.SubmitOrder
+}
+
+func (e *batchSubmitExchange) MaxOrdersPerBatch() int {
+	return 2
+}
+
+func (e *batchSubmitExchange) BatchSubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders []types.Order, errs []error, err error) {
+	e.batches = append(e.batches, orders)
+
+	createdOrders = make([]types.Order, len(orders))
+	errs = make([]error, len(orders))
+	for i, order := range orders {
+		created, err := e.Exchange.SubmitOrders(ctx, order)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		createdOrders[i] = created[0]
+	}
+
+	return createdOrders, errs, nil
+}
+
+func TestSubmitOrdersConcurrently_batch(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01},
+	}
+
+	exchange := &batchSubmitExchange{Exchange: mock.New(types.ExchangeMax, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})}
+
+	orders := []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 9000.0, Tags: types.OrderTags{"grid_level": "1"}},
+		// the stop limit order is rejected by the mock exchange
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeStopLimit, Quantity: 0.01, Price: 8950.0, StopPrice: 8960.0},
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 8900.0},
+	}
+
+	results := submitOrdersConcurrently(context.Background(), exchange, 4, orders)
+	if !assert.Len(t, results, 3) {
+		return
+	}
+
+	assert.True(t, results[0].Success())
+	assert.Equal(t, 9000.0, results[0].Order.Price)
+	assert.Equal(t, types.OrderTags{"grid_level": "1"}, results[0].Order.Tags)
+
+	assert.False(t, results[1].Success())
+	assert.Equal(t, 8950.0, results[1].SubmitOrder.Price)
+
+	assert.True(t, results[2].Success())
+	assert.Equal(t, 8900.0, results[2].Order.Price)
+
+	// the orders are split by the max orders per batch
+	if assert.Len(t, exchange.batches, 2) {
+		assert.Len(t, exchange.batches[0], 2)
+		assert.Len(t, exchange.batches[1], 1)
+	}
+}
@@ -0,0 +1,154 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultConfirmTimeout = 5 * time.Minute
+
+var ErrOrderRejected = errors.New("orders are rejected by the operator")
+
+var ErrConfirmationTimeout = errors.New("order confirmation timeout")
+
+// OrderConfirmer asks the operator to approve or reject the orders,
+// RequestConfirmation blocks until the operator answers or the context is done.
+type OrderConfirmer interface {
+	RequestConfirmation(ctx context.Context, orders []types.SubmitOrder) (approved bool, err error)
+}
+
+// OrderConfirmation is the order confirmation config, embed it in the strategy struct to enable the confirmation,
+// the orders with the quote amount larger than ConfirmOrderAmount are held until the operator approves them.
+type OrderConfirmation struct {
+	// ConfirmOrderAmount is the min quote amount of the orders that require the confirmation
+	ConfirmOrderAmount fixedpoint.Value `json:"confirmOrderAmount,omitempty" yaml:"confirmOrderAmount,omitempty"`
+
+	// ConfirmTimeout is the max waiting time of the confirmation, the orders are rejected after the timeout, defaults to 5 minutes
+	ConfirmTimeout types.Duration `json:"confirmTimeout,omitempty" yaml:"confirmTimeout,omitempty"`
+}
+
+func (c OrderConfirmation) OrderConfirmationSettings() OrderConfirmation {
+	return c
+}
+
+func (c OrderConfirmation) IsEnabled() bool {
+	return c.ConfirmOrderAmount > 0
+}
+
+// OrderConfirmationStrategy is implemented by the strategies embedding the OrderConfirmation struct
+type OrderConfirmationStrategy interface {
+	OrderConfirmationSettings() OrderConfirmation
+}
+
+// ConfirmationOrderExecutor holds the large orders until they are approved by the order confirmer,
+// the other orders are submitted directly.
+type ConfirmationOrderExecutor struct {
+	OrderExecutor
+
+	Confirmer OrderConfirmer
+
+	// MinAmount is the min quote amount of the orders that require the confirmation, all the orders require the confirmation if it's zero
+	MinAmount float64
+
+	Timeout time.Duration
+}
+
+// RequireConfirmation wraps the order executor, the orders with the quote amount larger than minAmount are submitted
+// only after they are approved, SubmitOrders blocks until the answer or the timeout.
+func RequireConfirmation(executor OrderExecutor, confirmer OrderConfirmer, minAmount float64, timeout time.Duration) *ConfirmationOrderExecutor {
+	if timeout == 0 {
+		timeout = defaultConfirmTimeout
+	}
+
+	return &ConfirmationOrderExecutor{
+		OrderExecutor: executor,
+		Confirmer:     confirmer,
+		MinAmount:     minAmount,
+		Timeout:       timeout,
+	}
+}
+
+// requiresConfirmation checks the quote amount of the order, the market order without the price always requires the confirmation
+func (e *ConfirmationOrderExecutor) requiresConfirmation(order types.SubmitOrder) bool {
+	if order.Price == 0 {
+		return true
+	}
+
+	return order.Price*order.Quantity >= e.MinAmount
+}
+
+func (e *ConfirmationOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var directOrders, heldOrders []types.SubmitOrder
+	for _, order := range orders {
+		if e.requiresConfirmation(order) {
+			heldOrders = append(heldOrders, order)
+		} else {
+			directOrders = append(directOrders, order)
+		}
+	}
+
+	var createdOrders types.OrderSlice
+	if len(directOrders) > 0 {
+		created, err := e.OrderExecutor.SubmitOrders(ctx, directOrders...)
+		createdOrders = append(createdOrders, created...)
+		if err != nil {
+			return createdOrders, err
+		}
+	}
+
+	if len(heldOrders) == 0 {
+		return createdOrders, nil
+	}
+
+	if err := e.confirm(ctx, heldOrders); err != nil {
+		return createdOrders, err
+	}
+
+	created, err := e.OrderExecutor.SubmitOrders(ctx, heldOrders...)
+	createdOrders = append(createdOrders, created...)
+	return createdOrders, err
+}
+
+func (e *ConfirmationOrderExecutor) confirm(ctx context.Context, orders []types.SubmitOrder) error {
+	confirmCtx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	approved, err := e.Confirmer.RequestConfirmation(confirmCtx, orders)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrConfirmationTimeout
+		}
+
+		return fmt.Errorf("order confirmation error: %w", err)
+	}
+
+	if !approved {
+		return ErrOrderRejected
+	}
+
+	return nil
+}
+
+// wrapConfirmationOrderExecutor wraps the order executor with the order confirmation if the strategy enables it
+func wrapConfirmationOrderExecutor(strategy SingleExchangeStrategy, confirmer OrderConfirmer, executor OrderExecutor) (OrderExecutor, error) {
+	confirmationStrategy, ok := strategy.(OrderConfirmationStrategy)
+	if !ok {
+		return executor, nil
+	}
+
+	conf := confirmationStrategy.OrderConfirmationSettings()
+	if !conf.IsEnabled() {
+		return executor, nil
+	}
+
+	if confirmer == nil {
+		return nil, fmt.Errorf("strategy %s requires the order confirmation, but the order confirmer is not configured", strategy.ID())
+	}
+
+	return RequireConfirmation(executor, confirmer, conf.ConfirmOrderAmount.Float64(), conf.ConfirmTimeout.Duration()), nil
+}
@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type recordingOrderExecutor struct {
+	submitted []types.SubmitOrder
+}
+
+func (e *recordingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	e.submitted = append(e.submitted, orders...)
+	for _, o := range orders {
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *recordingOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *recordingOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+type staticOrderConfirmer struct {
+	approved bool
+	requests int
+}
+
+func (c *staticOrderConfirmer) RequestConfirmation(ctx context.Context, orders []types.SubmitOrder) (bool, error) {
+	c.requests++
+	return c.approved, nil
+}
+
+type blockingOrderConfirmer struct{}
+
+func (c *blockingOrderConfirmer) RequestConfirmation(ctx context.Context, orders []types.SubmitOrder) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestConfirmationOrderExecutor(t *testing.T) {
+	small := types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 30000, Quantity: 0.01}
+	large := types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit, Price: 30000, Quantity: 1}
+	market := types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: 0.01}
+
+	t.Run("approved", func(t *testing.T) {
+		executor := &recordingOrderExecutor{}
+		confirmer := &staticOrderConfirmer{approved: true}
+		e := RequireConfirmation(executor, confirmer, 1000, time.Second)
+
+		createdOrders, err := e.SubmitOrders(context.Background(), small, large, market)
+		assert.NoError(t, err)
+		assert.Len(t, createdOrders, 3)
+		assert.Equal(t, 1, confirmer.requests)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		executor := &recordingOrderExecutor{}
+		confirmer := &staticOrderConfirmer{approved: false}
+		e := RequireConfirmation(executor, confirmer, 1000, time.Second)
+
+		// the small order is submitted without the confirmation
+		createdOrders, err := e.SubmitOrders(context.Background(), small, large)
+		assert.Equal(t, ErrOrderRejected, err)
+		assert.Len(t, createdOrders, 1)
+		assert.Equal(t, []types.SubmitOrder{small}, executor.submitted)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		executor := &recordingOrderExecutor{}
+		e := RequireConfirmation(executor, &blockingOrderConfirmer{}, 1000, 10*time.Millisecond)
+
+		createdOrders, err := e.SubmitOrders(context.Background(), large)
+		assert.Equal(t, ErrConfirmationTimeout, err)
+		assert.Empty(t, createdOrders)
+		assert.Empty(t, executor.submitted)
+	})
+}
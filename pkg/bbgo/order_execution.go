@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -36,12 +37,26 @@ func (e *ExchangeOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessi
 		return nil, fmt.Errorf("exchange Session %s not found", session)
 	}
 
+	orders = filterBlacklistedOrders(es, orders)
+
 	formattedOrders, err := formatOrders(es, orders)
 	if err != nil {
 		return nil, err
 	}
 
-	return es.Exchange.SubmitOrders(ctx, formattedOrders...)
+	formattedOrders = es.orderTags.Register(formattedOrders)
+	results := submitOrdersConcurrently(ctx, es.Exchange, es.OrderSubmitWorkers, formattedOrders)
+	for _, r := range results {
+		if r.Error != nil {
+			es.orderTags.Unregister(r.SubmitOrder)
+		}
+	}
+
+	es.auditSubmitOrderResults(results)
+
+	createdOrders, err := collectSubmitOrderResults(results)
+	es.orderTags.Add(createdOrders...)
+	return createdOrders, err
 }
 
 // ExchangeOrderExecutor is an order executor wrapper for single exchange instance.
@@ -70,7 +85,32 @@ func (e *ExchangeOrderExecutor) notifySubmitOrders(orders ...types.SubmitOrder)
 	}
 }
 
+// SubmitOrders submits the orders concurrently, the orders rejected by the exchange don't abort the others of the batch.
+// The created orders are returned with a *BatchSubmitError if any of the orders is rejected.
 func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	results, err := e.SubmitOrdersWithResults(ctx, orders...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectSubmitOrderResults(results)
+}
+
+// SubmitOrdersWithResults submits the orders concurrently and returns the result of each submitted order, the
+// orders skipped by the session filters (e.g., the dust orders) have no result. The error is only returned
+// if the batch can not be submitted at all.
+func (e *ExchangeOrderExecutor) SubmitOrdersWithResults(ctx context.Context, orders ...types.SubmitOrder) ([]SubmitOrderResult, error) {
+	if e.Session.PublicOnly {
+		return nil, ErrSessionPublicOnly
+	}
+
+	orders = filterBlacklistedOrders(e.Session, orders)
+
+	orders, err := filterTradingPolicyOrders(e, orders, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	formattedOrders, err := formatOrders(e.Session, orders)
 	if err != nil {
 		return nil, err
@@ -90,7 +130,40 @@ func (e *ExchangeOrderExecutor) SubmitOrders(ctx context.Context, orders ...type
 
 	e.notifySubmitOrders(formattedOrders...)
 
-	return e.Session.Exchange.SubmitOrders(ctx, formattedOrders...)
+	results := make([]SubmitOrderResult, len(formattedOrders))
+	capabilities := e.Session.Capabilities()
+
+	var indexes []int
+	var supportedOrders []types.SubmitOrder
+	for i, order := range formattedOrders {
+		if err := checkOrderCapabilities(capabilities, order); err != nil {
+			results[i] = SubmitOrderResult{SubmitOrder: order, Error: err}
+			continue
+		}
+
+		indexes = append(indexes, i)
+		supportedOrders = append(supportedOrders, order)
+	}
+
+	// register the tags by the client order ID, so that the order updates arriving before the submission returns are tagged
+	supportedOrders = e.Session.orderTags.Register(supportedOrders)
+	for i, r := range submitOrdersConcurrently(ctx, e.Session.Exchange, e.Session.OrderSubmitWorkers, supportedOrders) {
+		results[indexes[i]] = r
+	}
+
+	e.Session.auditSubmitOrderResults(results)
+
+	for _, r := range results {
+		if r.Error != nil {
+			logrus.WithError(r.Error).Errorf("order submission failed: %s", r.SubmitOrder.String())
+			e.Session.orderTags.Unregister(r.SubmitOrder)
+			continue
+		}
+
+		e.Session.orderTags.Add(r.Order)
+	}
+
+	return results, nil
 }
 
 type BasicRiskController struct {
@@ -100,6 +173,37 @@ type BasicRiskController struct {
 	MinQuoteBalance     fixedpoint.Value `json:"minQuoteBalance,omitempty"`
 	MaxBaseAssetBalance fixedpoint.Value `json:"maxBaseAssetBalance,omitempty"`
 	MinBaseAssetBalance fixedpoint.Value `json:"minBaseAssetBalance,omitempty"`
+
+	// UseCollateral checks the buy orders against the available margin of all the collateral assets,
+	// instead of the quote balance, this is used by the multi-asset collateral margin accounts.
+	UseCollateral bool `json:"useCollateral,omitempty"`
+
+	// MinAvailableMargin is the available margin (in USD) that should be kept when UseCollateral is enabled
+	MinAvailableMargin fixedpoint.Value `json:"minAvailableMargin,omitempty"`
+
+	// CollateralRatios is the collateral ratio table of the assets, the default ratio is 1.0
+	CollateralRatios types.CollateralRatios `json:"collateralRatios,omitempty"`
+}
+
+// collateralQuoteQuota returns the quote amount that can be used by the buy orders from the available margin
+func (c *BasicRiskController) collateralQuoteQuota(session *ExchangeSession, quoteCurrency string) (float64, error) {
+	quotePrice, ok := types.USDPrice(quoteCurrency, session.CollateralPrices())
+	if !ok {
+		return 0, fmt.Errorf("can not place buy order, the USD price of %s is not found", quoteCurrency)
+	}
+
+	collateral, err := session.Collateral(c.CollateralRatios)
+	if err != nil {
+		return 0, errors.Wrap(err, "can not place buy order")
+	}
+
+	if collateral.AvailableMargin < c.MinAvailableMargin.Float64() {
+		return 0, errors.Wrapf(ErrQuoteBalanceLevelTooLow, "can not place buy order, available margin is too low: %s < %s",
+			types.USD.FormatMoneyFloat64(collateral.AvailableMargin),
+			types.USD.FormatMoneyFloat64(c.MinAvailableMargin.Float64()))
+	}
+
+	return (collateral.AvailableMargin - c.MinAvailableMargin.Float64()) / quotePrice, nil
 }
 
 // ProcessOrders filters and modifies the submit order objects by:
@@ -137,6 +241,35 @@ func (c *BasicRiskController) ProcessOrders(session *ExchangeSession, orders ...
 
 		switch order.Side {
 		case types.SideTypeBuy:
+			if c.UseCollateral {
+				quoteAssetQuota, err := c.collateralQuoteQuota(session, market.QuoteCurrency)
+				if err != nil {
+					addError(err)
+					continue
+				}
+
+				quantity = adjustQuantityByMinAmount(quantity, price, market.MinAmount*1.01)
+				if c.MaxOrderAmount > 0 {
+					quantity = adjustQuantityByMaxAmount(quantity, price, c.MaxOrderAmount.Float64())
+				}
+
+				quantity = adjustQuantityByMaxAmount(quantity, price, quoteAssetQuota-accumulativeQuoteAmount)
+
+				notional := quantity * lastPrice
+				if notional < market.MinAmount {
+					addError(
+						fmt.Errorf(
+							"can not place buy order, insufficient available margin: notional %f < min amount %f, order: %s",
+							notional,
+							market.MinAmount,
+							order.String()))
+					continue
+				}
+
+				accumulativeQuoteAmount += notional
+				break
+			}
+
 			// Critical conditions for placing buy orders
 			quoteBalance, ok := balances[market.QuoteCurrency]
 			if !ok {
@@ -279,10 +412,31 @@ func (c *BasicRiskController) ProcessOrders(session *ExchangeSession, orders ...
 	return outOrders, nil
 }
 
+// filterBlacklistedOrders removes the buy orders of the blacklisted symbols,
+// the sell orders are kept so that the strategies can still close the positions.
+func filterBlacklistedOrders(session *ExchangeSession, orders []types.SubmitOrder) (filtered []types.SubmitOrder) {
+	for _, order := range orders {
+		if order.Side == types.SideTypeBuy {
+			if reason, ok := session.IsSymbolBlacklisted(order.Symbol); ok {
+				logrus.Warnf("skipping buy order of the blacklisted symbol %s (%s): %s", order.Symbol, reason, order.String())
+				continue
+			}
+		}
+
+		filtered = append(filtered, order)
+	}
+
+	return filtered
+}
+
 func formatOrders(session *ExchangeSession, orders []types.SubmitOrder) (formattedOrders []types.SubmitOrder, err error) {
 	for _, order := range orders {
 		o, err := session.FormatOrder(order)
-		if err != nil {
+		if errors.Is(err, ErrDustQuantity) {
+			// skip the dust order instead of failing the whole batch
+			logrus.WithError(err).Warnf("skipping the dust order: %s", order.String())
+			continue
+		} else if err != nil {
 			return formattedOrders, err
 		}
 		formattedOrders = append(formattedOrders, o)
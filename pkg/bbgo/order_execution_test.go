@@ -0,0 +1,49 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestFormatOrders(t *testing.T) {
+	session := &ExchangeSession{
+		markets: map[string]types.Market{
+			"BTCUSDT": {
+				Symbol:      "BTCUSDT",
+				MinPrice:    0.01,
+				MinLot:      0.0001,
+				MinQuantity: 0.0001,
+				StepSize:    0.0001,
+				TickSize:    0.01,
+				MinNotional: 10.0,
+			},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 10000.0},
+	}
+
+	order, err := session.FormatOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9999.999, Quantity: 0.12345})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 9999.99, order.Price)
+		assert.Equal(t, "9999.99", order.PriceString)
+		assert.Equal(t, 0.1234, order.Quantity)
+		assert.Equal(t, "0.1234", order.QuantityString)
+	}
+
+	// the market order is checked with the last price
+	_, err = session.FormatOrder(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 0.0005})
+	assert.True(t, errors.Is(err, ErrDustQuantity))
+
+	// the dust order is skipped without failing the batch
+	orders, err := formatOrders(session, []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 0.00005},
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 0.01},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, 0.01, orders[0].Quantity)
+	}
+}
@@ -0,0 +1,408 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type SlicingMethod string
+
+const (
+	// SlicingMethodTWAP submits the child orders of the even quantity at the even interval of the time window
+	SlicingMethodTWAP SlicingMethod = "twap"
+
+	// SlicingMethodIceberg submits the next child order of the visible quantity after the previous one is filled
+	SlicingMethodIceberg SlicingMethod = "iceberg"
+)
+
+// SlicingSettings is the config of the order slicing, e.g.,
+//
+//	slicing:
+//	  method: twap
+//	  minQuantity: 1.0
+//	  duration: 10m
+//	  numSlices: 10
+//	  maxSlippage: 0.005
+type SlicingSettings struct {
+	Method SlicingMethod `json:"method" yaml:"method"`
+
+	// MinQuantity is the quantity threshold of the sliced orders, the smaller orders are submitted directly
+	MinQuantity fixedpoint.Value `json:"minQuantity,omitempty" yaml:"minQuantity,omitempty"`
+
+	// Duration is the time window of the child orders
+	Duration types.Duration `json:"duration" yaml:"duration"`
+
+	// NumSlices is the number of the TWAP child orders
+	NumSlices int `json:"numSlices,omitempty" yaml:"numSlices,omitempty"`
+
+	// SliceQuantity is the visible quantity of the iceberg child orders
+	SliceQuantity fixedpoint.Value `json:"sliceQuantity,omitempty" yaml:"sliceQuantity,omitempty"`
+
+	// MaxSlippage is the max price deviation ratio from the last price when the market order is submitted, e.g., 0.005 for 0.5%.
+	// The limit order uses its own price as the price limit. The remaining child orders are dropped once the last price
+	// moves beyond the price limit.
+	MaxSlippage fixedpoint.Value `json:"maxSlippage,omitempty" yaml:"maxSlippage,omitempty"`
+}
+
+func (s *SlicingSettings) Validate() error {
+	if s.Duration <= 0 {
+		return fmt.Errorf("slicing duration is required")
+	}
+
+	switch s.Method {
+	case SlicingMethodTWAP:
+		if s.NumSlices < 2 {
+			return fmt.Errorf("twap slicing requires numSlices >= 2, got %d", s.NumSlices)
+		}
+
+	case SlicingMethodIceberg:
+		if s.SliceQuantity <= 0 {
+			return fmt.Errorf("iceberg slicing requires a positive sliceQuantity")
+		}
+
+	default:
+		return fmt.Errorf("unsupported slicing method %q, valid methods are %q and %q", s.Method, SlicingMethodTWAP, SlicingMethodIceberg)
+	}
+
+	if s.MaxSlippage < 0 {
+		return fmt.Errorf("slicing maxSlippage can not be negative")
+	}
+
+	return nil
+}
+
+// OrderSlicing is the smart order routing config, embed it in the strategy struct and set `slicing` in the strategy config
+// to slice the large orders of the strategy into the TWAP or the iceberg child orders, so that closing a big position
+// doesn't move the market.
+type OrderSlicing struct {
+	Slicing *SlicingSettings `json:"slicing,omitempty" yaml:"slicing,omitempty"`
+}
+
+func (s OrderSlicing) OrderSlicingSettings() *SlicingSettings {
+	return s.Slicing
+}
+
+// OrderSlicingStrategy is implemented by the strategies embedding the OrderSlicing struct
+type OrderSlicingStrategy interface {
+	OrderSlicingSettings() *SlicingSettings
+}
+
+// SlicingOrderExecutor submits the orders below the min quantity directly, and executes the larger orders as the child
+// orders in the background. SubmitOrders returns the created direct orders only, the child orders are delivered by the
+// order updates of the stream.
+type SlicingOrderExecutor struct {
+	OrderExecutor
+
+	Settings SlicingSettings
+	Session  *ExchangeSession
+
+	mu sync.Mutex
+
+	// watches are the iceberg child orders waiting for the final status, keyed by the client order id
+	watches map[string]chan types.OrderStatus
+}
+
+func NewSlicingOrderExecutor(executor OrderExecutor, session *ExchangeSession, settings SlicingSettings) *SlicingOrderExecutor {
+	return &SlicingOrderExecutor{
+		OrderExecutor: executor,
+		Settings:      settings,
+		Session:       session,
+		watches:       make(map[string]chan types.OrderStatus),
+	}
+}
+
+func (e *SlicingOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(e.handleOrderUpdate)
+}
+
+func (e *SlicingOrderExecutor) handleOrderUpdate(order types.Order) {
+	switch order.Status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+	default:
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if c, ok := e.watches[order.ClientOrderID]; ok {
+		delete(e.watches, order.ClientOrderID)
+		c <- order.Status
+	}
+}
+
+func (e *SlicingOrderExecutor) watch(clientOrderID string) chan types.OrderStatus {
+	c := make(chan types.OrderStatus, 1)
+
+	e.mu.Lock()
+	e.watches[clientOrderID] = c
+	e.mu.Unlock()
+	return c
+}
+
+func (e *SlicingOrderExecutor) unwatch(clientOrderID string) {
+	e.mu.Lock()
+	delete(e.watches, clientOrderID)
+	e.mu.Unlock()
+}
+
+func (e *SlicingOrderExecutor) shouldSlice(order types.SubmitOrder) bool {
+	switch order.Type {
+	case types.OrderTypeMarket, types.OrderTypeLimit:
+	default:
+		return false
+	}
+
+	return order.Quantity >= e.Settings.MinQuantity.Float64()
+}
+
+func (e *SlicingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var directOrders, slicedOrders []types.SubmitOrder
+	for _, order := range orders {
+		if e.shouldSlice(order) {
+			slicedOrders = append(slicedOrders, order)
+		} else {
+			directOrders = append(directOrders, order)
+		}
+	}
+
+	var createdOrders types.OrderSlice
+	if len(directOrders) > 0 {
+		var err error
+		createdOrders, err = e.OrderExecutor.SubmitOrders(ctx, directOrders...)
+		if err != nil {
+			return createdOrders, err
+		}
+	}
+
+	for _, order := range slicedOrders {
+		log.Infof("slicing %s %s order %f by %s in %s", order.Symbol, order.Side, order.Quantity, e.Settings.Method, e.Settings.Duration.Duration())
+		go e.execute(ctx, order)
+	}
+
+	return createdOrders, nil
+}
+
+// priceLimit returns the worst price of the child orders, zero means no limit
+func (e *SlicingOrderExecutor) priceLimit(order types.SubmitOrder) float64 {
+	if order.Type == types.OrderTypeLimit {
+		return order.Price
+	}
+
+	if e.Settings.MaxSlippage <= 0 {
+		return 0
+	}
+
+	lastPrice, ok := e.Session.LastPrice(order.Symbol)
+	if !ok {
+		return 0
+	}
+
+	if order.Side == types.SideTypeBuy {
+		return lastPrice * (1.0 + e.Settings.MaxSlippage.Float64())
+	}
+
+	return lastPrice * (1.0 - e.Settings.MaxSlippage.Float64())
+}
+
+// withinPriceLimit checks the last price against the price limit of the order
+func (e *SlicingOrderExecutor) withinPriceLimit(order types.SubmitOrder, limit float64) bool {
+	if limit == 0 {
+		return true
+	}
+
+	lastPrice, ok := e.Session.LastPrice(order.Symbol)
+	if !ok {
+		return true
+	}
+
+	if order.Side == types.SideTypeBuy {
+		return lastPrice <= limit
+	}
+
+	return lastPrice >= limit
+}
+
+func (e *SlicingOrderExecutor) execute(ctx context.Context, order types.SubmitOrder) {
+	market, ok := e.Session.Market(order.Symbol)
+	if !ok {
+		market = order.Market
+	}
+
+	limit := e.priceLimit(order)
+
+	var executed float64
+	var err error
+	switch e.Settings.Method {
+	case SlicingMethodTWAP:
+		quantities := splitQuantity(market, order.Quantity, order.Quantity/float64(e.Settings.NumSlices))
+		executed, err = e.executeTWAP(ctx, order, quantities, limit)
+
+	case SlicingMethodIceberg:
+		quantities := splitQuantity(market, order.Quantity, e.Settings.SliceQuantity.Float64())
+		executed, err = e.executeIceberg(ctx, order, quantities, limit)
+	}
+
+	if err != nil {
+		log.WithError(err).Errorf("sliced %s %s order stopped, %f of %f submitted", order.Symbol, order.Side, executed, order.Quantity)
+		return
+	}
+
+	log.Infof("sliced %s %s order is done, %f of %f submitted", order.Symbol, order.Side, executed, order.Quantity)
+}
+
+func (e *SlicingOrderExecutor) childOrder(order types.SubmitOrder, quantity float64) types.SubmitOrder {
+	child := order
+	child.ClientOrderID = uuid.New().String()
+	child.Quantity = quantity
+	child.QuantityString = ""
+	return child
+}
+
+func (e *SlicingOrderExecutor) executeTWAP(ctx context.Context, order types.SubmitOrder, quantities []float64, limit float64) (float64, error) {
+	interval := e.Settings.Duration.Duration() / time.Duration(len(quantities))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var submitted float64
+	for i, quantity := range quantities {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return submitted, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+
+		if !e.withinPriceLimit(order, limit) {
+			return submitted, fmt.Errorf("the last price moves beyond the price limit %f", limit)
+		}
+
+		if _, err := e.OrderExecutor.SubmitOrders(ctx, e.childOrder(order, quantity)); err != nil {
+			return submitted, err
+		}
+
+		submitted += quantity
+	}
+
+	return submitted, nil
+}
+
+func (e *SlicingOrderExecutor) executeIceberg(ctx context.Context, order types.SubmitOrder, quantities []float64, limit float64) (float64, error) {
+	// the iceberg child orders are limit orders at the price limit, the market orders without the price limit are
+	// submitted as the market child orders.
+	if limit > 0 {
+		order.Type = types.OrderTypeLimit
+		order.Price = limit
+		order.PriceString = ""
+	}
+
+	deadline := time.NewTimer(e.Settings.Duration.Duration())
+	defer deadline.Stop()
+
+	var filled float64
+	for _, quantity := range quantities {
+		if !e.withinPriceLimit(order, limit) {
+			return filled, fmt.Errorf("the last price moves beyond the price limit %f", limit)
+		}
+
+		child := e.childOrder(order, quantity)
+		done := e.watch(child.ClientOrderID)
+
+		createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, child)
+		if err != nil {
+			e.unwatch(child.ClientOrderID)
+			return filled, err
+		}
+
+		select {
+		case status := <-done:
+			if status != types.OrderStatusFilled {
+				return filled, fmt.Errorf("the iceberg child order is %s", status)
+			}
+
+		case <-ctx.Done():
+			e.unwatch(child.ClientOrderID)
+			e.cancel(createdOrders)
+			return filled, ctx.Err()
+
+		case <-deadline.C:
+			e.unwatch(child.ClientOrderID)
+			e.cancel(createdOrders)
+			return filled, fmt.Errorf("the iceberg order is not filled in %s", e.Settings.Duration.Duration())
+		}
+
+		filled += quantity
+	}
+
+	return filled, nil
+}
+
+func (e *SlicingOrderExecutor) cancel(orders types.OrderSlice) {
+	if len(orders) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := e.Session.Exchange.CancelOrders(ctx, orders...); err != nil {
+		log.WithError(err).Errorf("can not cancel the sliced child orders")
+	}
+}
+
+// splitQuantity splits the quantity into the slices rounded down to the quantity step, the slice is at least the
+// min quantity of the market, and the remaining dust is merged into the last slice.
+func splitQuantity(market types.Market, quantity, sliceQuantity float64) []float64 {
+	slice := market.RoundDownQuantity(sliceQuantity)
+	if slice < market.MinQuantity {
+		slice = market.RoundUpQuantity(market.MinQuantity)
+	}
+
+	if slice <= 0 || slice >= quantity {
+		return []float64{quantity}
+	}
+
+	dust := math.Max(market.MinQuantity, market.QuantityStep())
+
+	var quantities []float64
+	for remaining := quantity; ; remaining -= slice {
+		if market.RoundQuantity(remaining-slice) < dust {
+			return append(quantities, market.RoundQuantity(remaining))
+		}
+
+		quantities = append(quantities, slice)
+	}
+}
+
+// wrapSlicingOrderExecutor slices the large orders of the strategy if the strategy enables the order slicing
+func wrapSlicingOrderExecutor(strategy SingleExchangeStrategy, session *ExchangeSession, executor OrderExecutor) (OrderExecutor, error) {
+	slicingStrategy, ok := strategy.(OrderSlicingStrategy)
+	if !ok {
+		return executor, nil
+	}
+
+	settings := slicingStrategy.OrderSlicingSettings()
+	if settings == nil {
+		return executor, nil
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("strategy %s: %w", strategy.ID(), err)
+	}
+
+	slicer := NewSlicingOrderExecutor(executor, session, *settings)
+	slicer.BindStream(session.Stream)
+
+	log.Infof("strategy %s slices the orders by %s", strategy.ID(), settings.Method)
+	return slicer, nil
+}
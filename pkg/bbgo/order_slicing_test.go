@@ -0,0 +1,111 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// fillingOrderExecutor fills the submitted orders immediately through the order update handler
+type fillingOrderExecutor struct {
+	recordingOrderExecutor
+
+	handleOrderUpdate func(order types.Order)
+}
+
+func (e *fillingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := e.recordingOrderExecutor.SubmitOrders(ctx, orders...)
+	for _, o := range createdOrders {
+		o.Status = types.OrderStatusFilled
+		e.handleOrderUpdate(o)
+	}
+
+	return createdOrders, err
+}
+
+func TestSplitQuantity(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", MinQuantity: 0.01, StepSize: 0.01}
+
+	assert.InDeltaSlice(t, []float64{0.3, 0.3, 0.3, 0.1}, splitQuantity(market, 1.0, 0.3), 1e-9)
+
+	// the slice is at least the min quantity
+	assert.InDeltaSlice(t, []float64{0.01, 0.01, 0.01, 0.01, 0.01}, splitQuantity(market, 0.05, 0.001), 1e-9)
+
+	assert.Equal(t, []float64{0.2}, splitQuantity(market, 0.2, 0.5))
+
+	// the dust below the min quantity is merged into the last slice
+	market = types.Market{Symbol: "BTCUSDT", MinQuantity: 0.01, StepSize: 0.001}
+	assert.InDeltaSlice(t, []float64{0.3, 0.3, 0.305}, splitQuantity(market, 0.905, 0.3), 1e-9)
+}
+
+func TestSlicingOrderExecutor_TWAP(t *testing.T) {
+	session := &ExchangeSession{
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", MinQuantity: 0.001, StepSize: 0.001},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 10000.0},
+	}
+
+	executor := &recordingOrderExecutor{}
+	slicer := NewSlicingOrderExecutor(executor, session, SlicingSettings{
+		Method:      SlicingMethodTWAP,
+		MinQuantity: fixedpoint.NewFromFloat(1.0),
+		Duration:    types.Duration(30 * time.Millisecond),
+		NumSlices:   3,
+	})
+
+	// the small order is submitted directly
+	createdOrders, err := slicer.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 0.5})
+	assert.NoError(t, err)
+	assert.Len(t, createdOrders, 1)
+
+	slicer.execute(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 9900.0, Quantity: 3.0})
+	if assert.Len(t, executor.submitted, 4) {
+		for _, child := range executor.submitted[1:] {
+			assert.Equal(t, 1.0, child.Quantity)
+			assert.Equal(t, 9900.0, child.Price)
+			assert.NotEmpty(t, child.ClientOrderID)
+		}
+	}
+
+	// the last price moves beyond the price limit of the limit order
+	executor.submitted = nil
+	session.lastPrices["BTCUSDT"] = 9800.0
+	slicer.execute(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 9900.0, Quantity: 3.0})
+	assert.Len(t, executor.submitted, 0)
+}
+
+func TestSlicingOrderExecutor_Iceberg(t *testing.T) {
+	session := &ExchangeSession{
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", MinQuantity: 0.001, StepSize: 0.001},
+		},
+		lastPrices: map[string]float64{"BTCUSDT": 10000.0},
+	}
+
+	executor := &fillingOrderExecutor{}
+	slicer := NewSlicingOrderExecutor(executor, session, SlicingSettings{
+		Method:        SlicingMethodIceberg,
+		Duration:      types.Duration(time.Minute),
+		SliceQuantity: fixedpoint.NewFromFloat(0.4),
+		MaxSlippage:   fixedpoint.NewFromFloat(0.01),
+	})
+	executor.handleOrderUpdate = slicer.handleOrderUpdate
+
+	slicer.execute(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 1.0})
+	if assert.Len(t, executor.submitted, 3) {
+		assert.InDelta(t, 0.2, executor.submitted[2].Quantity, 1e-9)
+		for _, child := range executor.submitted {
+			// the market order is converted to the limit child orders at the price limit
+			assert.Equal(t, types.OrderTypeLimit, child.Type)
+			assert.InDelta(t, 10100.0, child.Price, 1e-9)
+		}
+	}
+
+	assert.Len(t, slicer.watches, 0)
+}
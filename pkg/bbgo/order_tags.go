@@ -0,0 +1,134 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// orderTagRetention is how long the tags of a closed order are kept,
+// the trade updates of the last fill may arrive after the closed order update.
+const orderTagRetention = time.Minute
+
+// OrderTagMap keeps the tags of the submitted orders by the order ID,
+// since the order updates and the trade updates from the exchange stream do not carry the tags.
+//
+// The tags are registered by the client order ID before the orders are submitted, so that the order updates
+// arriving before the submission returns can still be tagged, and they are removed once the order is closed.
+type OrderTagMap struct {
+	mu      sync.Mutex
+	tags    map[uint64]types.OrderTags
+	pending map[string]types.OrderTags
+}
+
+func NewOrderTagMap() *OrderTagMap {
+	return &OrderTagMap{
+		tags:    make(map[uint64]types.OrderTags),
+		pending: make(map[string]types.OrderTags),
+	}
+}
+
+// Register records the tags of the orders to be submitted by the client order ID,
+// a client order ID is generated for the tagged orders without one.
+func (m *OrderTagMap) Register(orders []types.SubmitOrder) []types.SubmitOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, o := range orders {
+		if len(o.Tags) == 0 {
+			continue
+		}
+
+		if len(o.ClientOrderID) == 0 {
+			orders[i].ClientOrderID = uuid.New().String()
+		}
+
+		m.pending[orders[i].ClientOrderID] = o.Tags
+	}
+
+	return orders
+}
+
+// Unregister removes the registered tags of the orders that are not submitted
+func (m *OrderTagMap) Unregister(orders ...types.SubmitOrder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range orders {
+		if len(o.ClientOrderID) > 0 {
+			delete(m.pending, o.ClientOrderID)
+		}
+	}
+}
+
+// Add records the tags of the given orders by the order ID, orders without tags are ignored.
+func (m *OrderTagMap) Add(orders ...types.Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range orders {
+		tags := o.Tags
+		if len(o.ClientOrderID) > 0 {
+			if pending, ok := m.pending[o.ClientOrderID]; ok {
+				delete(m.pending, o.ClientOrderID)
+				if len(tags) == 0 {
+					tags = pending
+				}
+			}
+		}
+
+		if len(tags) == 0 {
+			continue
+		}
+
+		m.tags[o.OrderID] = tags
+	}
+}
+
+func (m *OrderTagMap) Get(orderID uint64) (tags types.OrderTags, ok bool) {
+	m.mu.Lock()
+	tags, ok = m.tags[orderID]
+	m.mu.Unlock()
+	return tags, ok
+}
+
+// GetByOrder returns the tags of the order update, the tags registered by the client order ID
+// are bound to the order ID once the order update is received.
+func (m *OrderTagMap) GetByOrder(order types.Order) (tags types.OrderTags, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tags, ok = m.tags[order.OrderID]; ok {
+		return tags, ok
+	}
+
+	if len(order.ClientOrderID) == 0 {
+		return nil, false
+	}
+
+	if tags, ok = m.pending[order.ClientOrderID]; ok {
+		delete(m.pending, order.ClientOrderID)
+		m.tags[order.OrderID] = tags
+	}
+
+	return tags, ok
+}
+
+// Update removes the tags of the filled, canceled or rejected order after orderTagRetention
+func (m *OrderTagMap) Update(order types.Order) {
+	switch order.Status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		time.AfterFunc(orderTagRetention, func() {
+			m.Remove(order.OrderID)
+		})
+	}
+}
+
+func (m *OrderTagMap) Remove(orderID uint64) {
+	m.mu.Lock()
+	delete(m.tags, orderID)
+	m.mu.Unlock()
+}
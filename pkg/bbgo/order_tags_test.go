@@ -0,0 +1,43 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestOrderTagMap_Register(t *testing.T) {
+	m := NewOrderTagMap()
+
+	orders := m.Register([]types.SubmitOrder{
+		{Symbol: "BTCUSDT", ClientOrderID: "grid-1", Tags: types.OrderTags{"grid_level": "1"}},
+		{Symbol: "BTCUSDT", Tags: types.OrderTags{"grid_level": "2"}},
+		{Symbol: "BTCUSDT"},
+	})
+
+	assert.Equal(t, "grid-1", orders[0].ClientOrderID)
+	assert.NotEmpty(t, orders[1].ClientOrderID)
+	assert.Empty(t, orders[2].ClientOrderID)
+
+	// the order update arrives before the submission returns
+	tags, ok := m.GetByOrder(types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: "grid-1"}, OrderID: 1})
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderTags{"grid_level": "1"}, tags)
+
+	tags, ok = m.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderTags{"grid_level": "1"}, tags)
+
+	m.Add(types.Order{SubmitOrder: types.SubmitOrder{ClientOrderID: orders[1].ClientOrderID}, OrderID: 2})
+	tags, ok = m.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, types.OrderTags{"grid_level": "2"}, tags)
+
+	assert.Empty(t, m.pending)
+
+	m.Remove(1)
+	_, ok = m.Get(1)
+	assert.False(t, ok)
+}
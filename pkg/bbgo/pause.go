@@ -0,0 +1,53 @@
+package bbgo
+
+// pauseStoreID is the persistence key of the pause flags of the strategies
+const pauseStoreID = "paused-strategies"
+
+// NewPauseStore returns the store of the strategy pause flags, the redis persistence is preferred over the json persistence,
+// it returns nil if neither of them is configured, the pause flags are kept in memory only in that case.
+func NewPauseStore(facade *PersistenceServiceFacade) Store {
+	if facade == nil {
+		return nil
+	}
+
+	if facade.Redis != nil {
+		return facade.Redis.NewStore("bbgo", pauseStoreID)
+	}
+
+	if facade.Json != nil {
+		return facade.Json.NewStore("bbgo", pauseStoreID)
+	}
+
+	return nil
+}
+
+// LoadPauseFlags loads the names of the paused strategies from the store
+func LoadPauseFlags(store Store) (map[string]bool, error) {
+	var paused = make(map[string]bool)
+	if err := store.Load(&paused); err != nil && err != ErrPersistenceNotExists {
+		return nil, err
+	}
+
+	if paused == nil {
+		paused = make(map[string]bool)
+	}
+
+	return paused, nil
+}
+
+// SetPauseFlag sets the pause flag of the strategy in the store, the flags of the other strategies are kept,
+// it can be used for pausing the strategy before bbgo starts.
+func SetPauseFlag(store Store, name string, paused bool) error {
+	flags, err := LoadPauseFlags(store)
+	if err != nil {
+		return err
+	}
+
+	if paused {
+		flags[name] = true
+	} else {
+		delete(flags, name)
+	}
+
+	return store.Save(&flags)
+}
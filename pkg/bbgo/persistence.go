@@ -1,6 +1,9 @@
 package bbgo
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+)
 
 type PersistenceSelector struct {
 	// StoreID is the store you want to use.
@@ -15,6 +18,10 @@ type Persistence struct {
 	PersistenceSelector *PersistenceSelector `json:"persistence,omitempty" yaml:"persistence,omitempty"`
 
 	Facade *PersistenceServiceFacade `json:"-" yaml:"-"`
+
+	// strategyID and symbol are injected by the trader, they are used as the key prefix of the strategy state
+	strategyID string
+	symbol     string
 }
 
 func (p *Persistence) backendService(t string) (service PersistenceService, err error) {
@@ -28,10 +35,17 @@ func (p *Persistence) backendService(t string) (service PersistenceService, err
 	case "memory":
 		service = p.Facade.Memory
 
+	case "sql":
+		service = p.Facade.SQL
+
 	default:
 		err = fmt.Errorf("unsupported persistent type %s", t)
 	}
 
+	if err == nil && reflect.ValueOf(service).IsNil() {
+		err = fmt.Errorf("persistent type %s is not configured", t)
+	}
+
 	return service, err
 }
 
@@ -63,8 +77,41 @@ func (p *Persistence) Save(val interface{}, subIDs ...string) error {
 	return store.Save(val)
 }
 
+// stateIDs returns the sub IDs of the strategy state, which are prefixed by the strategy ID and the symbol
+func (p *Persistence) stateIDs(subIDs []string) []string {
+	var ids []string
+	if p.strategyID != "" {
+		ids = append(ids, p.strategyID)
+	}
+
+	if p.symbol != "" {
+		ids = append(ids, p.symbol)
+	}
+
+	return append(ids, subIDs...)
+}
+
+// WithSymbol returns a copy of the persistence keyed by the given symbol,
+// it's used by the strategies running multiple symbols to keep the state of each symbol separately.
+func (p *Persistence) WithSymbol(symbol string) *Persistence {
+	persistence := *p
+	persistence.symbol = symbol
+	return &persistence
+}
+
+// LoadState loads the strategy state keyed by the strategy ID, the symbol and the given sub IDs
+func (p *Persistence) LoadState(val interface{}, subIDs ...string) error {
+	return p.Load(val, p.stateIDs(subIDs)...)
+}
+
+// SaveState saves the strategy state keyed by the strategy ID, the symbol and the given sub IDs
+func (p *Persistence) SaveState(val interface{}, subIDs ...string) error {
+	return p.Save(val, p.stateIDs(subIDs)...)
+}
+
 type PersistenceServiceFacade struct {
-	Redis *RedisPersistenceService
-	Json  *JsonPersistenceService
+	Redis  *RedisPersistenceService
+	Json   *JsonPersistenceService
 	Memory *MemoryService
+	SQL    *SQLPersistenceService
 }
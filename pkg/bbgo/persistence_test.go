@@ -0,0 +1,52 @@
+package bbgo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistence_State(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bbgo-persistence")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	facade := &PersistenceServiceFacade{
+		Memory: NewMemoryService(),
+		Json:   &JsonPersistenceService{Directory: dir},
+	}
+
+	for _, persistenceType := range []string{"memory", "json"} {
+		t.Run(persistenceType, func(t *testing.T) {
+			p := &Persistence{
+				PersistenceSelector: &PersistenceSelector{StoreID: "default", Type: persistenceType},
+				Facade:              facade,
+				strategyID:          "grid",
+				symbol:              "BTCUSDT",
+			}
+
+			var j int
+			assert.Equal(t, ErrPersistenceNotExists, p.LoadState(&j, "state"))
+
+			i := 3
+			assert.NoError(t, p.SaveState(&i, "state"))
+			assert.NoError(t, p.LoadState(&j, "state"))
+			assert.Equal(t, i, j)
+
+			// the state is stored with the strategy ID and the symbol prefix
+			assert.NoError(t, p.Load(&j, "grid", "BTCUSDT", "state"))
+		})
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		p := &Persistence{
+			PersistenceSelector: &PersistenceSelector{StoreID: "default", Type: "redis"},
+			Facade:              facade,
+		}
+
+		var j int
+		assert.Error(t, p.LoadState(&j, "state"))
+	})
+}
@@ -0,0 +1,32 @@
+package bbgo
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LoadPlugins opens the go plugins of the strategies, the relative paths are resolved from the base directory.
+// The plugin registers its strategies by calling RegisterStrategy in its init function, so the strategies can be used
+// in the config without importing them into the bbgo binary. The plugin must be built with the same go version and
+// the same bbgo module version as the binary:
+//
+//	go build -buildmode=plugin -o mystrategy.so ./mystrategy
+func LoadPlugins(baseDir string, paths []string) error {
+	for _, p := range paths {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(baseDir, p)
+		}
+
+		// plugin.Open returns the loaded plugin if the path is already opened, so it's safe for the config reload
+		if _, err := plugin.Open(p); err != nil {
+			return fmt.Errorf("can not load the strategy plugin %s: %w", p, err)
+		}
+
+		log.Infof("strategy plugin %s is loaded", p)
+	}
+
+	return nil
+}
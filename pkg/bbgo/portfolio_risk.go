@@ -0,0 +1,108 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/datatype"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PortfolioRiskConfig configures the portfolio risk view of the futures sessions,
+// the risk report is sent through the notifiers by the cron specs of When, e.g., "@daily".
+type PortfolioRiskConfig struct {
+	// Shocks are the price moves of the risk scenarios, defaults to -10%, -5%, +5% and +10%
+	Shocks []float64 `json:"shocks,omitempty" yaml:"shocks,omitempty"`
+
+	// MaintenanceMarginRate is the maintenance margin rate of the positions, defaults to 1%
+	MaintenanceMarginRate float64 `json:"maintenanceMarginRate,omitempty" yaml:"maintenanceMarginRate,omitempty"`
+
+	// CollateralRatios is the collateral ratio table of the assets, the default ratio is 1.0
+	CollateralRatios types.CollateralRatios `json:"collateralRatios,omitempty" yaml:"collateralRatios,omitempty"`
+
+	When datatype.StringSlice `json:"when,omitempty" yaml:"when,omitempty"`
+}
+
+// PortfolioRisk queries the positions and the collateral of the futures sessions and aggregates them into one risk view,
+// the config of the environment is used if conf is nil.
+func (environ *Environment) PortfolioRisk(ctx context.Context, conf *PortfolioRiskConfig) (*types.PortfolioRisk, error) {
+	if conf == nil {
+		conf = environ.PortfolioRiskConfig
+	}
+
+	if conf == nil {
+		conf = &PortfolioRiskConfig{}
+	}
+
+	var positions []types.PortfolioPosition
+	var collaterals []types.Collateral
+
+	for name, session := range environ.sessions {
+		if !session.Futures || session.PublicOnly {
+			continue
+		}
+
+		futuresExchange, ok := session.Exchange.(types.FuturesExchange)
+		if !ok {
+			return nil, fmt.Errorf("session %s exchange %s does not support futures", name, session.ExchangeName)
+		}
+
+		sessionPositions, err := futuresExchange.QueryPositions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: can not query the positions: %w", name, err)
+		}
+
+		for _, p := range sessionPositions {
+			if p.Quantity == 0 {
+				continue
+			}
+
+			positions = append(positions, types.PortfolioPosition{Session: name, FuturesPosition: p})
+		}
+
+		collateral, err := session.UpdateCollateral(ctx, conf.CollateralRatios)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: can not update the collateral: %w", name, err)
+		}
+
+		collaterals = append(collaterals, *collateral)
+	}
+
+	risk := types.NewPortfolioRisk(positions, types.MergeCollaterals(collaterals...), conf.MaintenanceMarginRate, conf.Shocks)
+	return &risk, nil
+}
+
+// reportPortfolioRisk sends the portfolio risk report through the notifiers
+func (environ *Environment) reportPortfolioRisk(conf *PortfolioRiskConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	risk, err := environ.PortfolioRisk(ctx, conf)
+	if err != nil {
+		log.WithError(err).Error("can not generate the portfolio risk report")
+		return
+	}
+
+	environ.Notify(":warning: %s", risk.PlainText(), risk)
+}
+
+// schedulePortfolioRiskReport starts the cron jobs of the portfolio risk report
+func (environ *Environment) schedulePortfolioRiskReport(conf *PortfolioRiskConfig) error {
+	if len(conf.When) == 0 {
+		return nil
+	}
+
+	c := cron.New()
+	for _, spec := range conf.When {
+		if _, err := c.AddFunc(spec, func() { environ.reportPortfolioRisk(conf) }); err != nil {
+			return fmt.Errorf("invalid portfolio risk report spec %q: %w", spec, err)
+		}
+	}
+
+	c.Start()
+	return nil
+}
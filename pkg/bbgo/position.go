@@ -1,10 +1,20 @@
 package bbgo
 
 import (
+	"time"
+
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+// PositionLot is an open lot of the position, the lots are closed in the FIFO order
+type PositionLot struct {
+	// Quantity is the open quantity of the lot, it's always positive, the lot direction follows the position
+	Quantity fixedpoint.Value `json:"quantity"`
+	Price    fixedpoint.Value `json:"price"`
+	Time     time.Time        `json:"time"`
+}
+
 type Position struct {
 	Symbol        string `json:"symbol"`
 	BaseCurrency  string `json:"baseCurrency"`
@@ -13,6 +23,16 @@ type Position struct {
 	Base        fixedpoint.Value `json:"base"`
 	Quote       fixedpoint.Value `json:"quote"`
 	AverageCost fixedpoint.Value `json:"averageCost"`
+
+	// Lots are the open lots of the position ordered by the trade time,
+	// they are the long lots when the base is positive, or the short lots when the base is negative.
+	Lots []PositionLot `json:"lots,omitempty"`
+}
+
+// UnrealizedProfit returns the unrealized profit of the position with the given price,
+// the short position (negative base) makes profit when the price goes down.
+func (p *Position) UnrealizedProfit(price fixedpoint.Value) fixedpoint.Value {
+	return (price - p.AverageCost).Mul(p.Base)
 }
 
 func (p *Position) BindStream(stream types.Stream) {
@@ -50,6 +70,8 @@ func (p *Position) AddTrade(t types.Trade) (fixedpoint.Value, bool) {
 
 	}
 
+	p.updateLots(t.Side, quantity, price, t.Time)
+
 	// Base > 0 means we're in long position
 	// Base < 0  means we're in short position
 	switch t.Side {
@@ -103,3 +125,41 @@ func (p *Position) AddTrade(t types.Trade) (fixedpoint.Value, bool) {
 
 	return 0, false
 }
+
+// updateLots opens a lot when the trade increases the position, or closes the lots in the FIFO order when the trade reduces the position,
+// the rest quantity opens a lot of the opposite direction if the position is reversed.
+func (p *Position) updateLots(side types.SideType, quantity, price fixedpoint.Value, t time.Time) {
+	increasing := (side == types.SideTypeBuy && p.Base >= 0) || (side == types.SideTypeSell && p.Base <= 0)
+	if !increasing {
+		for quantity > 0 && len(p.Lots) > 0 {
+			lot := &p.Lots[0]
+			if lot.Quantity > quantity {
+				lot.Quantity -= quantity
+				quantity = 0
+				break
+			}
+
+			quantity -= lot.Quantity
+			p.Lots = p.Lots[1:]
+		}
+	}
+
+	if quantity > 0 {
+		p.Lots = append(p.Lots, PositionLot{Quantity: quantity, Price: price, Time: t})
+	}
+}
+
+// HeldQuantity returns the open quantity of the lots held for at least the given period at the given time,
+// the lots are closed in the FIFO order, so it's the max quantity that can be closed without closing a younger lot.
+func (p *Position) HeldQuantity(period time.Duration, now time.Time) fixedpoint.Value {
+	var quantity fixedpoint.Value
+	for _, lot := range p.Lots {
+		if now.Sub(lot.Time) < period {
+			break
+		}
+
+		quantity += lot.Quantity
+	}
+
+	return quantity
+}
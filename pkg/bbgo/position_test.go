@@ -2,6 +2,7 @@ package bbgo
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -174,3 +175,64 @@ func TestPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestProfitStats(t *testing.T) {
+	position := &Position{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+	}
+
+	stats := &ProfitStats{
+		Symbol:        "BTCUSDT",
+		QuoteCurrency: "USDT",
+	}
+
+	trades := []types.Trade{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 1000.0, Quantity: 0.02, QuoteQuantity: 1000.0 * 0.02},
+		{Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 1100.0, Quantity: 0.01, QuoteQuantity: 1100.0 * 0.01},
+		{Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 900.0, Quantity: 0.005, QuoteQuantity: 900.0 * 0.005},
+	}
+
+	for _, trade := range trades {
+		profit, realized := position.AddTrade(trade)
+		stats.AddTrade(trade, profit, realized)
+	}
+
+	// trades of the other symbols are ignored
+	stats.AddTrade(types.Trade{Symbol: "ETHUSDT", Side: types.SideTypeSell, Price: 100.0, Quantity: 1.0}, 10.0, true)
+
+	assert.Equal(t, 3, stats.NumTrades)
+	assert.Equal(t, 1, stats.NumProfitTrades)
+	assert.Equal(t, 1, stats.NumLossTrades)
+	assert.InDelta(t, 1.0, stats.GrossProfit.Float64(), 1e-6)
+	assert.InDelta(t, -0.5, stats.GrossLoss.Float64(), 1e-6)
+	assert.InDelta(t, 0.5, stats.RealizedProfit.Float64(), 1e-6)
+
+	// 0.005 BTC left with the average cost 1000
+	assert.InDelta(t, 0.5, position.UnrealizedProfit(fixedpoint.NewFromFloat(1100.0)).Float64(), 1e-6)
+}
+
+func TestPosition_Lots(t *testing.T) {
+	now := time.Now()
+	position := &Position{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	position.AddTrades([]types.Trade{
+		{Side: types.SideTypeBuy, Price: 1000.0, Quantity: 1.0, QuoteQuantity: 1000.0, Time: now.Add(-2 * time.Hour)},
+		{Side: types.SideTypeBuy, Price: 1100.0, Quantity: 1.0, QuoteQuantity: 1100.0, Time: now.Add(-time.Hour)},
+		{Side: types.SideTypeSell, Price: 1200.0, Quantity: 1.5, QuoteQuantity: 1800.0, Time: now},
+	})
+
+	assert.Equal(t, []PositionLot{
+		{Quantity: fixedpoint.NewFromFloat(0.5), Price: fixedpoint.NewFromFloat(1100.0), Time: now.Add(-time.Hour)},
+	}, position.Lots)
+
+	assert.Equal(t, fixedpoint.NewFromFloat(0.5), position.HeldQuantity(30*time.Minute, now))
+	assert.Equal(t, fixedpoint.Value(0), position.HeldQuantity(2*time.Hour, now))
+
+	// reversing the position opens a short lot with the rest quantity
+	position.AddTrade(types.Trade{Side: types.SideTypeSell, Price: 1200.0, Quantity: 1.5, QuoteQuantity: 1800.0, Time: now})
+	assert.Equal(t, []PositionLot{
+		{Quantity: fixedpoint.NewFromFloat(1.0), Price: fixedpoint.NewFromFloat(1200.0), Time: now},
+	}, position.Lots)
+	assert.Equal(t, fixedpoint.NewFromFloat(-1.0), position.Base)
+}
@@ -0,0 +1,55 @@
+package bbgo
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ProfitStats aggregates the realized profit of a symbol,
+// the profit is realized when a trade closes (part of) the position.
+type ProfitStats struct {
+	Symbol        string `json:"symbol"`
+	QuoteCurrency string `json:"quoteCurrency"`
+
+	NumTrades int `json:"numTrades"`
+
+	// NumProfitTrades and NumLossTrades count the position closing trades
+	NumProfitTrades int `json:"numProfitTrades"`
+	NumLossTrades   int `json:"numLossTrades"`
+
+	RealizedProfit fixedpoint.Value `json:"realizedProfit"`
+	GrossProfit    fixedpoint.Value `json:"grossProfit"`
+	GrossLoss      fixedpoint.Value `json:"grossLoss"`
+}
+
+// AddTrade counts the trade, and adds the realized profit if the trade closes the position
+func (s *ProfitStats) AddTrade(trade types.Trade, profit fixedpoint.Value, realized bool) {
+	if trade.Symbol != s.Symbol {
+		return
+	}
+
+	s.NumTrades++
+
+	if !realized {
+		return
+	}
+
+	s.RealizedProfit += profit
+	if profit > 0 {
+		s.NumProfitTrades++
+		s.GrossProfit += profit
+	} else if profit < 0 {
+		s.NumLossTrades++
+		s.GrossLoss += profit
+	}
+}
+
+func (s *ProfitStats) PlainText() string {
+	return fmt.Sprintf("%s profit stats: realized profit %f %s (gross profit %f, gross loss %f), %d trades, %d profit / %d loss",
+		s.Symbol,
+		s.RealizedProfit.Float64(), s.QuoteCurrency,
+		s.GrossProfit.Float64(), s.GrossLoss.Float64(),
+		s.NumTrades, s.NumProfitTrades, s.NumLossTrades)
+}
@@ -0,0 +1,100 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrInsufficientBudget = errors.New("insufficient budget for the min order size")
+
+// QuantityCalculator sizes the orders from a percentage of the available balance instead of a fixed quantity,
+// the budget is split evenly among the orders and the quantity is adjusted to the lot size and the min notional of the market.
+type QuantityCalculator struct {
+	// BudgetPercentage is the percentage of the available balance used by the orders, e.g., 0.5 means 50%,
+	// the quote balance is used for the buy orders and the base balance is used for the sell orders.
+	BudgetPercentage fixedpoint.Value `json:"budgetPercentage" yaml:"budgetPercentage"`
+}
+
+func (c *QuantityCalculator) Validate() error {
+	if c.BudgetPercentage <= 0 || c.BudgetPercentage > fixedpoint.NewFromFloat(1.0) {
+		return fmt.Errorf("budget percentage %f should be in (0, 1]", c.BudgetPercentage.Float64())
+	}
+
+	return nil
+}
+
+// Budget returns the total budget of the side, it's in the quote currency for buy and in the base currency for sell
+func (c *QuantityCalculator) Budget(market types.Market, balances types.BalanceMap, side types.SideType) float64 {
+	currency := market.QuoteCurrency
+	if side == types.SideTypeSell {
+		currency = market.BaseCurrency
+	}
+
+	balance, ok := balances[currency]
+	if !ok {
+		return 0
+	}
+
+	return balance.Available.Mul(c.BudgetPercentage).Float64()
+}
+
+// NetValueBudget returns the quote budget as the percentage of the account net value instead of the quote balance,
+// the account value must be in the quote currency of the market or its equivalent currency,
+// and the budget is capped by the available quote balance.
+func (c *QuantityCalculator) NetValueBudget(market types.Market, balances types.BalanceMap, value types.AccountValue) (float64, error) {
+	if !types.EquivalentCurrencies(value.QuoteCurrency, market.QuoteCurrency) {
+		return 0, fmt.Errorf("account value currency %s does not match the quote currency %s", value.QuoteCurrency, market.QuoteCurrency)
+	}
+
+	budget := value.NetValue * c.BudgetPercentage.Float64()
+	if balance, ok := balances[market.QuoteCurrency]; ok {
+		budget = math.Min(budget, balance.Available.Float64())
+	} else {
+		budget = 0
+	}
+
+	return budget, nil
+}
+
+// Quantity returns the quantity of one of the numOfOrders orders placed at the given price, the quantity is rounded down to the lot size,
+// ErrInsufficientBudget is returned if the budget of the order can not meet the min quantity or the min notional of the market.
+func (c *QuantityCalculator) Quantity(market types.Market, balances types.BalanceMap, side types.SideType, price float64, numOfOrders int) (float64, error) {
+	if price <= 0 {
+		return 0, fmt.Errorf("invalid order price %f", price)
+	}
+
+	if numOfOrders <= 0 {
+		numOfOrders = 1
+	}
+
+	budget := c.Budget(market, balances, side) / float64(numOfOrders)
+
+	// the budget of the buy orders is in the quote currency
+	maxQuantity := budget
+	if side == types.SideTypeBuy {
+		maxQuantity = budget / price
+	}
+
+	quantity := market.RoundDownQuantity(maxQuantity)
+	if minQuantity := minOrderQuantity(market, price); quantity < minQuantity {
+		return 0, fmt.Errorf("%w: %s %s order quantity %f is less than the min quantity %f", ErrInsufficientBudget, market.Symbol, side, quantity, minQuantity)
+	}
+
+	return quantity, nil
+}
+
+// minOrderQuantity returns the min quantity of the order at the given price, which satisfies both the lot size and the min notional
+func minOrderQuantity(market types.Market, price float64) float64 {
+	minQuantity := market.MinQuantity
+
+	minNotional := math.Max(market.MinNotional, market.MinAmount)
+	if minNotional > 0 {
+		minQuantity = math.Max(minQuantity, minNotional/price)
+	}
+
+	return market.RoundUpQuantity(minQuantity)
+}
@@ -0,0 +1,86 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestQuantityCalculator_Quantity(t *testing.T) {
+	market := types.Market{
+		Symbol:        "BTCUSDT",
+		BaseCurrency:  "BTC",
+		QuoteCurrency: "USDT",
+		MinNotional:   10.0,
+		MinQuantity:   0.0001,
+		MinLot:        0.0001,
+		StepSize:      0.0001,
+	}
+
+	balances := types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+	}
+
+	calculator := &QuantityCalculator{BudgetPercentage: fixedpoint.NewFromFloat(0.5)}
+	assert.NoError(t, calculator.Validate())
+
+	t.Run("buy", func(t *testing.T) {
+		// 1000 * 0.5 / 5 orders = 100 USDT per order
+		quantity, err := calculator.Quantity(market, balances, types.SideTypeBuy, 30000.0, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0033, quantity)
+	})
+
+	t.Run("sell", func(t *testing.T) {
+		// 0.1 * 0.5 / 4 orders = 0.0125 BTC per order
+		quantity, err := calculator.Quantity(market, balances, types.SideTypeSell, 30000.0, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0125, quantity)
+	})
+
+	t.Run("min notional", func(t *testing.T) {
+		// 500 USDT for 40 orders = 12.5 USDT per order, which is rounded down to 0.0004 BTC (12 USDT)
+		quantity, err := calculator.Quantity(market, balances, types.SideTypeBuy, 30000.0, 40)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0004, quantity)
+		assert.GreaterOrEqual(t, quantity*30000.0, market.MinNotional)
+	})
+
+	t.Run("insufficient budget", func(t *testing.T) {
+		// 5 USDT per order is less than the min notional
+		_, err := calculator.Quantity(market, balances, types.SideTypeBuy, 30000.0, 100)
+		assert.True(t, errors.Is(err, ErrInsufficientBudget))
+	})
+
+	t.Run("invalid percentage", func(t *testing.T) {
+		assert.Error(t, (&QuantityCalculator{BudgetPercentage: fixedpoint.NewFromFloat(1.5)}).Validate())
+	})
+}
+
+func TestQuantityCalculator_NetValueBudget(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	balances := types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+	}
+
+	calculator := &QuantityCalculator{BudgetPercentage: fixedpoint.NewFromFloat(0.1)}
+
+	// 4000 * 0.1 = 400 USDT
+	budget, err := calculator.NetValueBudget(market, balances, types.AccountValue{QuoteCurrency: "USDT", NetValue: 4000.0})
+	assert.NoError(t, err)
+	assert.InDelta(t, 400.0, budget, 1e-9)
+
+	// capped by the available quote balance
+	budget, err = calculator.NetValueBudget(market, balances, types.AccountValue{QuoteCurrency: "USDT", NetValue: 40000.0})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000.0, budget, 1e-9)
+
+	_, err = calculator.NetValueBudget(market, balances, types.AccountValue{QuoteCurrency: "TWD", NetValue: 4000.0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+package bbgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultRecordBookInterval = 10 * time.Second
+
+const defaultRecordBlockInterval = 10 * time.Minute
+
+// RecorderConfig enables the stream recorder, the closed klines, the book snapshots and the private fills of the sessions
+// are written into the directory as JSON lines, which can be converted into the backtest fixtures by the backtest-fixture command.
+type RecorderConfig struct {
+	Directory string `json:"directory" yaml:"directory"`
+
+	// BookInterval is the min interval between the recorded book snapshots of a symbol, defaults to 10 seconds
+	BookInterval types.Duration `json:"bookInterval,omitempty" yaml:"bookInterval,omitempty"`
+
+	// Compress writes the gzip compressed recordings (.jsonl.gz) with the index files for seeking,
+	// the books are recorded as the deltas of the changed price levels, and the full snapshots are written at each block.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+
+	// BlockInterval is the interval of the compressed blocks, which is also the seeking granularity, defaults to 10 minutes
+	BlockInterval types.Duration `json:"blockInterval,omitempty" yaml:"blockInterval,omitempty"`
+}
+
+// recordingWriter writes the recorded events into the recording file
+type recordingWriter interface {
+	Name() string
+	Write(event types.RecordedEvent) error
+	Close() error
+}
+
+// jsonLinesWriter writes the recorded events as the plain JSON lines
+type jsonLinesWriter struct {
+	file *os.File
+}
+
+func (w *jsonLinesWriter) Name() string {
+	return w.file.Name()
+}
+
+func (w *jsonLinesWriter) Write(event types.RecordedEvent) error {
+	return types.WriteRecordedEvent(w.file, event)
+}
+
+func (w *jsonLinesWriter) Close() error {
+	return w.file.Close()
+}
+
+// StreamRecorder captures the stream events of the sessions, the events are written into one file per session and day.
+type StreamRecorder struct {
+	Directory     string
+	BookInterval  time.Duration
+	Compress      bool
+	BlockInterval time.Duration
+
+	mu      sync.Mutex
+	writers map[string]recordingWriter
+
+	// books are the last recorded books of the sessions for the delta encoding, session name -> symbol -> book
+	books map[string]map[string]types.OrderBook
+}
+
+func NewStreamRecorder(conf *RecorderConfig) (*StreamRecorder, error) {
+	if len(conf.Directory) == 0 {
+		return nil, fmt.Errorf("recorder directory can not be empty")
+	}
+
+	if err := os.MkdirAll(conf.Directory, 0777); err != nil {
+		return nil, err
+	}
+
+	bookInterval := conf.BookInterval.Duration()
+	if bookInterval == 0 {
+		bookInterval = defaultRecordBookInterval
+	}
+
+	blockInterval := conf.BlockInterval.Duration()
+	if blockInterval == 0 {
+		blockInterval = defaultRecordBlockInterval
+	}
+
+	return &StreamRecorder{
+		Directory:     conf.Directory,
+		BookInterval:  bookInterval,
+		Compress:      conf.Compress,
+		BlockInterval: blockInterval,
+		writers:       make(map[string]recordingWriter),
+		books:         make(map[string]map[string]types.OrderBook),
+	}, nil
+}
+
+func (r *StreamRecorder) BindSession(session *ExchangeSession) {
+	exchangeName := session.Exchange.Name().String()
+
+	newEvent := func(symbol string, eventType types.RecordEventType) types.RecordedEvent {
+		return types.RecordedEvent{
+			Time:     time.Now(),
+			Session:  session.Name,
+			Exchange: exchangeName,
+			Symbol:   symbol,
+			Type:     eventType,
+		}
+	}
+
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		event := newEvent(kline.Symbol, types.RecordEventKLine)
+		event.KLine = &kline
+		r.record(event)
+	})
+
+	session.Stream.OnTradeUpdate(func(trade types.Trade) {
+		event := newEvent(trade.Symbol, types.RecordEventTrade)
+		event.Trade = &trade
+		r.record(event)
+	})
+
+	// the book updates are applied to the local books, and the snapshots are recorded by the book interval
+	var booksMu sync.Mutex
+	var books = make(map[string]*types.MutexOrderBook)
+	var lastRecordTimes = make(map[string]time.Time)
+
+	handleBook := func(update types.OrderBook, snapshot bool) {
+		booksMu.Lock()
+		book, ok := books[update.Symbol]
+		if !ok {
+			book = types.NewMutexOrderBook(update.Symbol)
+			books[update.Symbol] = book
+		}
+
+		if snapshot {
+			book.Load(update)
+		} else {
+			book.Update(update)
+		}
+
+		now := time.Now()
+		if now.Sub(lastRecordTimes[update.Symbol]) < r.BookInterval {
+			booksMu.Unlock()
+			return
+		}
+
+		lastRecordTimes[update.Symbol] = now
+		booksMu.Unlock()
+
+		current := book.Get()
+		event := newEvent(update.Symbol, types.RecordEventBook)
+		event.Book = &current
+		r.record(event)
+	}
+
+	session.Stream.OnBookSnapshot(func(book types.OrderBook) {
+		handleBook(book, true)
+	})
+
+	session.Stream.OnBookUpdate(func(book types.OrderBook) {
+		handleBook(book, false)
+	})
+}
+
+func (r *StreamRecorder) record(event types.RecordedEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, err := r.writer(event.Session, event.Exchange, event.Time)
+	if err != nil {
+		log.WithError(err).Errorf("can not open the recording file of session %s", event.Session)
+		return
+	}
+
+	if r.Compress && event.Type == types.RecordEventBook {
+		books := r.books[event.Session]
+		prev, ok := books[event.Symbol]
+		books[event.Symbol] = *event.Book
+
+		// the books are written as the key frames at the beginning of the block, and the deltas are idempotent,
+		// so the delta can be recorded even if the event starts a new block.
+		if ok {
+			delta := types.DiffOrderBook(prev, *event.Book)
+			if len(delta.Bids) == 0 && len(delta.Asks) == 0 {
+				return
+			}
+
+			event.Type = types.RecordEventBookDelta
+			event.Book = &delta
+		}
+	}
+
+	if err := w.Write(event); err != nil {
+		log.WithError(err).Errorf("can not write the recorded event")
+	}
+}
+
+// writer returns the recording writer of the session and the day, the writer of the previous day is closed
+func (r *StreamRecorder) writer(sessionName, exchangeName string, t time.Time) (recordingWriter, error) {
+	filename := filepath.Join(r.Directory, fmt.Sprintf("%s-%s.jsonl", sessionName, t.UTC().Format("20060102")))
+	if r.Compress {
+		filename += ".gz"
+	}
+
+	if w, ok := r.writers[sessionName]; ok {
+		if w.Name() == filename {
+			return w, nil
+		}
+
+		if err := w.Close(); err != nil {
+			log.WithError(err).Errorf("can not close the recording file %s", w.Name())
+		}
+	}
+
+	var w recordingWriter
+	if r.Compress {
+		if _, ok := r.books[sessionName]; !ok {
+			r.books[sessionName] = make(map[string]types.OrderBook)
+		}
+
+		rw, err := types.NewRecordingWriter(filename, r.BlockInterval, func(t time.Time) []types.RecordedEvent {
+			return r.keyFrames(sessionName, exchangeName, t)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		w = rw
+	} else {
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+		if err != nil {
+			return nil, err
+		}
+
+		w = &jsonLinesWriter{file: f}
+	}
+
+	r.writers[sessionName] = w
+	return w, nil
+}
+
+// keyFrames returns the full snapshots of the last recorded books of the session, it's called with the lock held
+func (r *StreamRecorder) keyFrames(sessionName, exchangeName string, t time.Time) (events []types.RecordedEvent) {
+	for symbol, book := range r.books[sessionName] {
+		book := book
+		events = append(events, types.RecordedEvent{
+			Time:     t,
+			Session:  sessionName,
+			Exchange: exchangeName,
+			Symbol:   symbol,
+			Type:     types.RecordEventBook,
+			Book:     &book,
+		})
+	}
+
+	return events
+}
+
+// Close closes the recording files
+func (r *StreamRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for sessionName, w := range r.writers {
+		if err := w.Close(); err != nil {
+			return err
+		}
+
+		delete(r.writers, sessionName)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package bbgo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStreamRecorder_Compress(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewStreamRecorder(&RecorderConfig{Directory: dir, Compress: true})
+	assert.NoError(t, err)
+
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	recordBook := func(offset time.Duration, bidVolume float64) {
+		book := types.OrderBook{
+			Symbol: "BTCUSDT",
+			Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(bidVolume)}},
+			Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.0)}},
+		}
+
+		recorder.record(types.RecordedEvent{
+			Time:     startTime.Add(offset),
+			Session:  "binance",
+			Exchange: "binance",
+			Symbol:   "BTCUSDT",
+			Type:     types.RecordEventBook,
+			Book:     &book,
+		})
+	}
+
+	recordBook(0, 1.0)
+	recordBook(time.Second, 2.0)
+	// the unchanged book is not recorded
+	recordBook(2*time.Second, 2.0)
+	// starts the new block with the key frame
+	recordBook(11*time.Minute, 3.0)
+	assert.NoError(t, recorder.Close())
+
+	events, err := types.ReadRecordingFile(filepath.Join(dir, "binance-20210601.jsonl.gz"), time.Time{})
+	assert.NoError(t, err)
+
+	var volumes []float64
+	for _, event := range events {
+		volumes = append(volumes, event.Book.Bids[0].Volume.Float64())
+	}
+
+	// each block starts with the key frame of the latest book, followed by the recorded book event
+	assert.Equal(t, []float64{1.0, 1.0, 2.0, 3.0, 3.0}, volumes)
+}
@@ -147,6 +147,6 @@ type TradeReporter struct {
 	*Notifiability
 }
 
-const TemplateTradeReport = `:handshake: {{ .Symbol }} {{ .Side }} Trade Execution @ {{ .Price  }}`
+const TemplateTradeReport = `:handshake: {{ .Symbol }} {{ .Side }} {{ t "Trade Execution" }} @ {{ price .Symbol .Price }}`
 
-const TemplateOrderReport = `:handshake: {{ .Symbol }} {{ .Side }} Order Update @ {{ .Price  }}`
+const TemplateOrderReport = `:handshake: {{ .Symbol }} {{ .Side }} {{ t "Order Update" }} @ {{ price .Symbol .Price }}`
@@ -0,0 +1,247 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("component", "risk")
+
+type Reason string
+
+const (
+	ReasonStopLoss     = Reason("stopLoss")
+	ReasonTakeProfit   = Reason("takeProfit")
+	ReasonTrailingStop = Reason("trailingStop")
+)
+
+// Config is the stop-loss, trailing stop and take-profit config, the ratios are relative to the position average cost,
+// e.g., stopLoss: 0.05 closes the long position when the price drops 5% below the average cost.
+type Config struct {
+	StopLoss   fixedpoint.Value `json:"stopLoss,omitempty" yaml:"stopLoss,omitempty"`
+	TakeProfit fixedpoint.Value `json:"takeProfit,omitempty" yaml:"takeProfit,omitempty"`
+
+	// TrailingActivation is the profit ratio to activate the trailing stop,
+	// once activated, the position is closed when the price pulls back TrailingCallback from the best price.
+	TrailingActivation fixedpoint.Value `json:"trailingActivation,omitempty" yaml:"trailingActivation,omitempty"`
+	TrailingCallback   fixedpoint.Value `json:"trailingCallback,omitempty" yaml:"trailingCallback,omitempty"`
+
+	// KeepPosition only fires the triggered callbacks (e.g., cancels the grid orders) without closing the position
+	KeepPosition bool `json:"keepPosition,omitempty" yaml:"keepPosition,omitempty"`
+}
+
+// Controller watches the price stream and closes the position when the stop-loss, the trailing stop or the take-profit is triggered.
+// After triggered, the controller halts, the order executor wrapped by the controller rejects the new orders.
+type Controller struct {
+	*Config
+
+	Symbol string
+	Market types.Market
+
+	// Position is the position of the strategy's own orders, only this quantity is closed when the controls are triggered,
+	// it should not be the session position shared by the strategies of the same symbol.
+	Position *bbgo.Position
+
+	OrderExecutor bbgo.OrderExecutor
+
+	// OrderStore is the order store of the strategy, the closing orders are added to it,
+	// so that the trades of the closing orders are counted in the strategy's position.
+	OrderStore *bbgo.OrderStore
+
+	mu        sync.Mutex
+	halted    bool
+	bestPrice float64
+
+	triggeredCallbacks []func(reason Reason, price float64)
+}
+
+func NewController(config *Config, market types.Market, position *bbgo.Position, orderExecutor bbgo.OrderExecutor) *Controller {
+	return &Controller{
+		Config:        config,
+		Symbol:        market.Symbol,
+		Market:        market,
+		Position:      position,
+		OrderExecutor: orderExecutor,
+	}
+}
+
+// OnTriggered registers the callback called before the position is closed, e.g., the grid strategy cancels its orders,
+// so that the balances locked by the orders are released for closing the position.
+func (c *Controller) OnTriggered(cb func(reason Reason, price float64)) {
+	c.triggeredCallbacks = append(c.triggeredCallbacks, cb)
+}
+
+func (c *Controller) emitTriggered(reason Reason, price float64) {
+	for _, cb := range c.triggeredCallbacks {
+		cb(reason, price)
+	}
+}
+
+func (c *Controller) Halted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.halted
+}
+
+// Resume resumes the halted controller, e.g., the strategy re-opens the position manually
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	c.halted = false
+	c.bestPrice = 0
+	c.mu.Unlock()
+}
+
+func (c *Controller) BindStream(stream types.Stream) {
+	handler := func(kline types.KLine) {
+		if kline.Symbol != c.Symbol || kline.Interval != types.Interval1m {
+			return
+		}
+
+		c.Update(context.Background(), kline.Close)
+	}
+
+	stream.OnKLine(handler)
+	stream.OnKLineClosed(handler)
+}
+
+// Check checks the price against the position, returns the triggered reason
+func (c *Controller) Check(price float64) (Reason, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.halted {
+		return "", false
+	}
+
+	base := c.Position.Base.Float64()
+	cost := c.Position.AverageCost.Float64()
+	if base == 0 || cost <= 0 {
+		c.bestPrice = 0
+		return "", false
+	}
+
+	// direction is 1 for the long position, -1 for the short position
+	direction := 1.0
+	if base < 0 {
+		direction = -1.0
+	}
+
+	profitRatio := direction * (price - cost) / cost
+
+	if c.StopLoss > 0 && profitRatio <= -c.StopLoss.Float64() {
+		return ReasonStopLoss, true
+	}
+
+	if c.TakeProfit > 0 && profitRatio >= c.TakeProfit.Float64() {
+		return ReasonTakeProfit, true
+	}
+
+	if c.TrailingCallback > 0 {
+		if c.bestPrice == 0 || direction*(price-c.bestPrice) > 0 {
+			c.bestPrice = price
+		}
+
+		bestProfitRatio := direction * (c.bestPrice - cost) / cost
+		pullback := direction * (c.bestPrice - price) / c.bestPrice
+		if bestProfitRatio >= c.TrailingActivation.Float64() && pullback >= c.TrailingCallback.Float64() {
+			return ReasonTrailingStop, true
+		}
+	}
+
+	return "", false
+}
+
+// Update checks the price and closes the position if any of the controls is triggered
+func (c *Controller) Update(ctx context.Context, price float64) {
+	reason, triggered := c.Check(price)
+	if !triggered {
+		return
+	}
+
+	c.mu.Lock()
+	c.halted = true
+	c.mu.Unlock()
+
+	log.Warnf("%s %s triggered at price %f, position base %f average cost %f",
+		c.Symbol, reason, price, c.Position.Base.Float64(), c.Position.AverageCost.Float64())
+
+	c.emitTriggered(reason, price)
+
+	if !c.KeepPosition {
+		if err := c.closePosition(ctx); err != nil {
+			log.WithError(err).Errorf("can not close the %s position", c.Symbol)
+		}
+	}
+}
+
+func (c *Controller) closePosition(ctx context.Context) error {
+	base := c.Position.Base.Float64()
+	side := types.SideTypeSell
+	if base < 0 {
+		side = types.SideTypeBuy
+	}
+
+	quantity := c.Market.RoundDownQuantity(math.Abs(base))
+	if quantity < c.Market.MinQuantity {
+		return fmt.Errorf("position quantity %f is less than the min quantity %f", quantity, c.Market.MinQuantity)
+	}
+
+	createdOrders, err := c.OrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   c.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Market:   c.Market,
+		Quantity: quantity,
+		Tags:     types.OrderTags{"reason": "risk"},
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.OrderStore != nil {
+		c.OrderStore.Add(createdOrders...)
+	}
+
+	return nil
+}
+
+// Wrap wraps the order executor, the wrapped executor rejects the orders of the symbol after the controller is halted
+func (c *Controller) Wrap(executor bbgo.OrderExecutor) bbgo.OrderExecutor {
+	return &OrderExecutor{OrderExecutor: executor, controller: c}
+}
+
+type OrderExecutor struct {
+	bbgo.OrderExecutor
+
+	controller *Controller
+}
+
+func (e *OrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if !e.controller.Halted() {
+		return e.OrderExecutor.SubmitOrders(ctx, orders...)
+	}
+
+	var accepted []types.SubmitOrder
+	for _, order := range orders {
+		if order.Symbol == e.controller.Symbol {
+			log.Warnf("%s risk control is halted, rejecting order: %s", order.Symbol, order.String())
+			continue
+		}
+
+		accepted = append(accepted, order)
+	}
+
+	if len(accepted) == 0 {
+		return nil, nil
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, accepted...)
+}
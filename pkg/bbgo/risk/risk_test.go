@@ -0,0 +1,134 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type recordingOrderExecutor struct {
+	submitted []types.SubmitOrder
+}
+
+func (e *recordingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	e.submitted = append(e.submitted, orders...)
+	for _, o := range orders {
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, OrderID: uint64(len(e.submitted))})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *recordingOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *recordingOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+func newTestController(config *Config, base, cost float64) *Controller {
+	position := &bbgo.Position{
+		Symbol:      "BTCUSDT",
+		Base:        fixedpoint.NewFromFloat(base),
+		AverageCost: fixedpoint.NewFromFloat(cost),
+	}
+
+	return NewController(config, types.Market{Symbol: "BTCUSDT"}, position, nil)
+}
+
+func TestController_Check(t *testing.T) {
+	t.Run("long stop loss and take profit", func(t *testing.T) {
+		c := newTestController(&Config{
+			StopLoss:   fixedpoint.NewFromFloat(0.05),
+			TakeProfit: fixedpoint.NewFromFloat(0.1),
+		}, 1.0, 100.0)
+
+		_, triggered := c.Check(96.0)
+		assert.False(t, triggered)
+
+		reason, triggered := c.Check(95.0)
+		assert.True(t, triggered)
+		assert.Equal(t, ReasonStopLoss, reason)
+
+		reason, triggered = c.Check(110.0)
+		assert.True(t, triggered)
+		assert.Equal(t, ReasonTakeProfit, reason)
+	})
+
+	t.Run("short stop loss", func(t *testing.T) {
+		c := newTestController(&Config{StopLoss: fixedpoint.NewFromFloat(0.05)}, -1.0, 100.0)
+
+		_, triggered := c.Check(95.0)
+		assert.False(t, triggered)
+
+		reason, triggered := c.Check(105.0)
+		assert.True(t, triggered)
+		assert.Equal(t, ReasonStopLoss, reason)
+	})
+
+	t.Run("trailing stop", func(t *testing.T) {
+		c := newTestController(&Config{
+			TrailingActivation: fixedpoint.NewFromFloat(0.1),
+			TrailingCallback:   fixedpoint.NewFromFloat(0.02),
+		}, 1.0, 100.0)
+
+		// not activated yet
+		_, triggered := c.Check(105.0)
+		assert.False(t, triggered)
+		_, triggered = c.Check(102.0)
+		assert.False(t, triggered)
+
+		// activated at 120, pull back less than 2%
+		_, triggered = c.Check(120.0)
+		assert.False(t, triggered)
+		_, triggered = c.Check(118.0)
+		assert.False(t, triggered)
+
+		reason, triggered := c.Check(117.0)
+		assert.True(t, triggered)
+		assert.Equal(t, ReasonTrailingStop, reason)
+	})
+
+	t.Run("no position", func(t *testing.T) {
+		c := newTestController(&Config{StopLoss: fixedpoint.NewFromFloat(0.05)}, 0, 0)
+		_, triggered := c.Check(10.0)
+		assert.False(t, triggered)
+	})
+}
+
+func TestController_Update(t *testing.T) {
+	executor := &recordingOrderExecutor{}
+	c := newTestController(&Config{StopLoss: fixedpoint.NewFromFloat(0.05)}, 0.5, 100.0)
+	c.Market = types.Market{Symbol: "BTCUSDT", MinQuantity: 0.001, StepSize: 0.001}
+	c.OrderExecutor = executor
+	c.OrderStore = bbgo.NewOrderStore("BTCUSDT")
+
+	// the strategy orders are canceled before the position is closed
+	var submittedBeforeTriggered int
+	c.OnTriggered(func(reason Reason, price float64) {
+		submittedBeforeTriggered = len(executor.submitted)
+	})
+
+	c.Update(context.Background(), 96.0)
+	assert.Empty(t, executor.submitted)
+	assert.False(t, c.Halted())
+
+	c.Update(context.Background(), 94.0)
+	assert.True(t, c.Halted())
+	assert.Equal(t, 0, submittedBeforeTriggered)
+
+	// only the quantity of the strategy position is closed
+	if assert.Len(t, executor.submitted, 1) {
+		assert.Equal(t, types.SideTypeSell, executor.submitted[0].Side)
+		assert.Equal(t, types.OrderTypeMarket, executor.submitted[0].Type)
+		assert.Equal(t, 0.5, executor.submitted[0].Quantity)
+	}
+
+	assert.True(t, c.OrderStore.Exists(1))
+
+	// the halted controller doesn't close the position again
+	c.Update(context.Background(), 90.0)
+	assert.Len(t, executor.submitted, 1)
+}
@@ -38,11 +38,10 @@ func (e *RiskControlOrderExecutor) SubmitOrders(ctx context.Context, orders ...t
 		}
 
 		retOrders2, err := e.ExchangeOrderExecutor.SubmitOrders(ctx, formattedOrders...)
+		retOrders = append(retOrders, retOrders2...)
 		if err != nil {
 			return retOrders, err
 		}
-
-		retOrders = append(retOrders, retOrders2...)
 	}
 
 	return
@@ -71,4 +70,10 @@ func groupSubmitOrdersBySymbol(orders []types.SubmitOrder) map[string][]types.Su
 
 type RiskControls struct {
 	SessionBasedRiskControl map[string]*SessionBasedRiskControl `json:"sessionBased,omitempty" yaml:"sessionBased,omitempty"`
+
+	// GlobalLimits are the risk limits checked against the positions of all sessions
+	GlobalLimits *RiskLimits `json:"globalLimits,omitempty" yaml:"globalLimits,omitempty"`
+
+	// SymbolLimits are the caps of each symbol, e.g., BTCUSDT: {maxOrderNotional: 1000}, checked against the positions of each session
+	SymbolLimits map[string]*SymbolRiskLimits `json:"symbolLimits,omitempty" yaml:"symbolLimits,omitempty"`
 }
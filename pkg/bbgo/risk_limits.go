@@ -0,0 +1,408 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RiskLimits are the hard limits checked right before the orders are submitted,
+// the per-session limits are set in the session config, and the global limits (across all sessions) are set in the riskControls config.
+type RiskLimits struct {
+	// MaxOrderValue is the max quote value of a single order
+	MaxOrderValue fixedpoint.Value `json:"maxOrderValue,omitempty" yaml:"maxOrderValue,omitempty"`
+
+	// MaxPosition is the max absolute base position of each symbol, e.g., BTCUSDT: 0.5
+	MaxPosition map[string]fixedpoint.Value `json:"maxPosition,omitempty" yaml:"maxPosition,omitempty"`
+
+	// MaxExposure is the max total value (in USD) of the open positions
+	MaxExposure fixedpoint.Value `json:"maxExposure,omitempty" yaml:"maxExposure,omitempty"`
+
+	// Truncate reduces the order quantity to fit in the limits instead of rejecting the order
+	Truncate bool `json:"truncate,omitempty" yaml:"truncate,omitempty"`
+}
+
+// SymbolRiskLimits are the caps of a single symbol configured in the riskControls config,
+// unlike RiskLimits, the orders breaking the caps are always rejected.
+type SymbolRiskLimits struct {
+	// MaxOrderNotional is the max quote value of a single order
+	MaxOrderNotional fixedpoint.Value `json:"maxOrderNotional,omitempty" yaml:"maxOrderNotional,omitempty"`
+
+	// MaxPositionNotional is the max quote value of the absolute position after the order is filled
+	MaxPositionNotional fixedpoint.Value `json:"maxPositionNotional,omitempty" yaml:"maxPositionNotional,omitempty"`
+
+	// MaxLeverage is the max ratio of the position value to the session equity (the collateral value minus the debt)
+	MaxLeverage fixedpoint.Value `json:"maxLeverage,omitempty" yaml:"maxLeverage,omitempty"`
+}
+
+// Check returns the reasons why the order breaks the caps, position is the current base position of the symbol,
+// and equity is the session equity in USD. The position and leverage caps don't block the orders reducing the position,
+// and the order without the price is rejected.
+func (l *SymbolRiskLimits) Check(order types.SubmitOrder, price, usdPrice, position, equity float64) (reasons []string) {
+	if price <= 0 {
+		return []string{fmt.Sprintf("the price of %s is not found", order.Symbol)}
+	}
+
+	if maxOrderNotional := l.MaxOrderNotional.Float64(); maxOrderNotional > 0 && order.Quantity*price > maxOrderNotional {
+		reasons = append(reasons, fmt.Sprintf("order notional %f exceeds the max order notional %f", order.Quantity*price, maxOrderNotional))
+	}
+
+	delta := order.Quantity
+	if order.Side == types.SideTypeSell {
+		delta = -delta
+	}
+
+	newPosition := math.Abs(position + delta)
+	if newPosition <= math.Abs(position) {
+		return reasons
+	}
+
+	if maxPositionNotional := l.MaxPositionNotional.Float64(); maxPositionNotional > 0 && newPosition*price > maxPositionNotional {
+		reasons = append(reasons, fmt.Sprintf("position notional %f exceeds the max position notional %f", newPosition*price, maxPositionNotional))
+	}
+
+	if maxLeverage := l.MaxLeverage.Float64(); maxLeverage > 0 && usdPrice > 0 {
+		if equity <= 0 {
+			reasons = append(reasons, "leverage can not be checked without the equity")
+		} else if leverage := newPosition * price * usdPrice / equity; leverage > maxLeverage {
+			reasons = append(reasons, fmt.Sprintf("leverage %f exceeds the max leverage %f", leverage, maxLeverage))
+		}
+	}
+
+	return reasons
+}
+
+// RiskRejection is emitted when an order is rejected by the risk limits
+type RiskRejection struct {
+	Session string
+	Order   types.SubmitOrder
+
+	// Price is the price used for checking the limits, it's the last price for the market order
+	Price float64
+
+	Reasons []string
+}
+
+// RiskRejectionHandler is implemented by the strategies that want to handle the orders rejected by the risk limits,
+// e.g., re-submit the order with a smaller quantity.
+type RiskRejectionHandler interface {
+	HandleRiskRejection(rejection RiskRejection)
+}
+
+// RiskExposure is the position snapshot that the limits are checked against
+type RiskExposure struct {
+	// Positions is the base position of each symbol
+	Positions map[string]float64
+
+	// Exposure is the total value (in USD) of the open positions
+	Exposure float64
+}
+
+// Apply updates the snapshot with the accepted order, so that the next order of the same batch is checked with it
+func (r *RiskExposure) Apply(order types.SubmitOrder, price, usdPrice float64) {
+	position := r.Positions[order.Symbol]
+	delta := order.Quantity
+	if order.Side == types.SideTypeSell {
+		delta = -delta
+	}
+
+	r.Positions[order.Symbol] = position + delta
+	r.Exposure += (math.Abs(position+delta) - math.Abs(position)) * price * usdPrice
+}
+
+// LimitQuantity returns the max quantity of the order allowed by the limits, and the reasons why the quantity is limited.
+// price is the order price (or the last price for the market order) and usdPrice is the USD price of the quote currency.
+// The order without the price is rejected since the limits can not be checked.
+func (l *RiskLimits) LimitQuantity(order types.SubmitOrder, price, usdPrice float64, exposure RiskExposure) (quantity float64, reasons []string) {
+	if price <= 0 {
+		return 0, []string{fmt.Sprintf("the price of %s is not found", order.Symbol)}
+	}
+
+	quantity = order.Quantity
+
+	if maxOrderValue := l.MaxOrderValue.Float64(); maxOrderValue > 0 && quantity*price > maxOrderValue {
+		reasons = append(reasons, fmt.Sprintf("order value %f exceeds the max order value %f", quantity*price, maxOrderValue))
+		quantity = maxOrderValue / price
+	}
+
+	position := exposure.Positions[order.Symbol]
+	if order.Side == types.SideTypeSell {
+		// mirror the position so that the sell order is checked like a buy order
+		position = -position
+	}
+
+	if maxPosition, ok := l.MaxPosition[order.Symbol]; ok {
+		if allowed := math.Max(maxPosition.Float64()-position, 0); quantity > allowed {
+			reasons = append(reasons, fmt.Sprintf("position %f exceeds the max position %f", position+quantity, maxPosition.Float64()))
+			quantity = allowed
+		}
+	}
+
+	if maxExposure := l.MaxExposure.Float64(); maxExposure > 0 && usdPrice > 0 {
+		// the part that closes the opposite position doesn't increase the exposure
+		closing := math.Min(math.Max(-position, 0), quantity)
+		increase := (quantity - closing) * price * usdPrice
+		if remaining := math.Max(maxExposure-exposure.Exposure, 0); increase > remaining {
+			reasons = append(reasons, fmt.Sprintf("exposure %f exceeds the max exposure %f", exposure.Exposure+increase, maxExposure))
+			quantity = closing + remaining/(price*usdPrice)
+		}
+	}
+
+	return quantity, reasons
+}
+
+// RiskLimitOrderExecutor checks the orders with the per-session limits, the global limits and the symbol caps,
+// the orders exceeding the limits are rejected, or truncated if the limits allow.
+//go:generate callbackgen -type RiskLimitOrderExecutor
+type RiskLimitOrderExecutor struct {
+	OrderExecutor
+
+	Notifiability
+
+	Session *ExchangeSession
+
+	SessionLimits *RiskLimits
+	GlobalLimits  *RiskLimits
+
+	// SymbolLimits are the caps of each symbol, checked against the positions of this session
+	SymbolLimits map[string]*SymbolRiskLimits
+
+	// sessions are all the sessions counted by the global limits
+	sessions map[string]*ExchangeSession
+
+	rejectedCallbacks []func(rejection RiskRejection)
+}
+
+func NewRiskLimitOrderExecutor(executor OrderExecutor, session *ExchangeSession, sessions map[string]*ExchangeSession, sessionLimits, globalLimits *RiskLimits, symbolLimits map[string]*SymbolRiskLimits) *RiskLimitOrderExecutor {
+	return &RiskLimitOrderExecutor{
+		OrderExecutor: executor,
+		Notifiability: session.Notifiability,
+		Session:       session,
+		SessionLimits: sessionLimits,
+		GlobalLimits:  globalLimits,
+		SymbolLimits:  symbolLimits,
+		sessions:      sessions,
+	}
+}
+
+func (e *RiskLimitOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var sessionExposure, globalExposure RiskExposure
+	if e.SessionLimits != nil || len(e.SymbolLimits) > 0 {
+		sessionExposure = riskExposureOf(e.Session)
+	}
+
+	var equity float64
+	if len(e.SymbolLimits) > 0 {
+		collateral := e.Session.Account.Balances().Collateral(e.Session.LastPrices(), nil)
+		equity = collateral.TotalValue - collateral.TotalDebt
+	}
+
+	if e.GlobalLimits != nil {
+		var sessions []*ExchangeSession
+		for _, session := range e.sessions {
+			sessions = append(sessions, session)
+		}
+
+		globalExposure = riskExposureOf(sessions...)
+	}
+
+	var accepted []types.SubmitOrder
+	for _, order := range orders {
+		price := order.Price
+		if order.Type == types.OrderTypeMarket || price == 0 {
+			price, _ = e.Session.LastPrice(order.Symbol)
+		}
+
+		market, ok := e.Session.Market(order.Symbol)
+		if !ok {
+			market = order.Market
+		}
+
+		usdPrice, _ := types.USDPrice(market.QuoteCurrency, e.Session.LastPrices())
+
+		if symbolLimits, ok := e.SymbolLimits[order.Symbol]; ok {
+			if reasons := symbolLimits.Check(order, price, usdPrice, sessionExposure.Positions[order.Symbol], equity); len(reasons) > 0 {
+				e.reject(order, price, reasons)
+				continue
+			}
+		}
+
+		quantity := order.Quantity
+		var reasons []string
+		var truncate = true
+		for _, check := range []struct {
+			limits   *RiskLimits
+			exposure RiskExposure
+		}{
+			{e.SessionLimits, sessionExposure},
+			{e.GlobalLimits, globalExposure},
+		} {
+			if check.limits == nil {
+				continue
+			}
+
+			limited := order
+			limited.Quantity = quantity
+
+			q, r := check.limits.LimitQuantity(limited, price, usdPrice, check.exposure)
+			if len(r) > 0 {
+				quantity = q
+				reasons = append(reasons, r...)
+				truncate = truncate && check.limits.Truncate
+			}
+		}
+
+		if len(reasons) > 0 {
+			quantity = market.RoundDownQuantity(quantity)
+			if !truncate || quantity <= 0 || quantity < market.MinQuantity || quantity*price < market.MinNotional {
+				e.reject(order, price, reasons)
+				continue
+			}
+
+			e.notifyRiskLimit(order, ":warning: Truncated %s %s %s order quantity from %f to %f at price %f: %s",
+				order.Symbol, order.Type, order.Side, order.Quantity, quantity, price, strings.Join(reasons, ", "))
+			order.Quantity = quantity
+		}
+
+		if e.SessionLimits != nil || len(e.SymbolLimits) > 0 {
+			sessionExposure.Apply(order, price, usdPrice)
+		}
+
+		if e.GlobalLimits != nil {
+			globalExposure.Apply(order, price, usdPrice)
+		}
+
+		accepted = append(accepted, order)
+	}
+
+	if len(accepted) == 0 {
+		return nil, nil
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, accepted...)
+}
+
+func (e *RiskLimitOrderExecutor) reject(order types.SubmitOrder, price float64, reasons []string) {
+	e.notifyRiskLimit(order, ":no_entry: Rejected %s %s %s order with quantity %f at price %f: %s",
+		order.Symbol, order.Type, order.Side, order.Quantity, price, strings.Join(reasons, ", "))
+
+	e.EmitRejected(RiskRejection{
+		Session: e.Session.Name,
+		Order:   order,
+		Price:   price,
+		Reasons: reasons,
+	})
+}
+
+func (e *RiskLimitOrderExecutor) notifyRiskLimit(order types.SubmitOrder, format string, args ...interface{}) {
+	log.Warnf(format, args...)
+
+	if channel, ok := e.RouteObject(&order); ok {
+		e.NotifyTo(channel, format, args...)
+	} else {
+		e.Notify(format, args...)
+	}
+}
+
+// riskExposureOf sums the positions of the given sessions, the exposure is valued with the last prices in USD.
+// The active orders are counted as the worst case: the exposure of a symbol is the larger one of
+// the position after all the active buy orders are filled and the position after all the active sell orders are filled.
+func riskExposureOf(sessions ...*ExchangeSession) RiskExposure {
+	var exposure = RiskExposure{
+		Positions: make(map[string]float64),
+	}
+
+	for _, session := range sessions {
+		prices := session.LastPrices()
+
+		bases := make(map[string]float64)
+		for symbol, position := range session.Positions() {
+			if base := position.Base.Float64(); base != 0 {
+				bases[symbol] = base
+			}
+		}
+
+		buys := make(map[string]float64)
+		sells := make(map[string]float64)
+		for symbol, store := range session.OrderStores() {
+			buys[symbol], sells[symbol] = activeOrderQuantities(store)
+			if _, ok := bases[symbol]; !ok && (buys[symbol] > 0 || sells[symbol] > 0) {
+				bases[symbol] = 0
+			}
+		}
+
+		for symbol, base := range bases {
+			exposure.Positions[symbol] += base
+
+			price, ok := prices[symbol]
+			if !ok {
+				continue
+			}
+
+			market, ok := session.Market(symbol)
+			if !ok {
+				continue
+			}
+
+			if usdPrice, ok := types.USDPrice(market.QuoteCurrency, prices); ok {
+				worst := math.Max(math.Abs(base+buys[symbol]), math.Abs(base-sells[symbol]))
+				exposure.Exposure += worst * price * usdPrice
+			}
+		}
+	}
+
+	return exposure
+}
+
+// activeOrderQuantities sums the remaining quantities of the active buy orders and sell orders in the order store
+func activeOrderQuantities(store *OrderStore) (buy, sell float64) {
+	for _, order := range store.Orders() {
+		switch order.Status {
+		case types.OrderStatusNew, types.OrderStatusPartiallyFilled:
+		default:
+			continue
+		}
+
+		remaining := order.Quantity - order.ExecutedQuantity
+		if remaining <= 0 {
+			continue
+		}
+
+		switch order.Side {
+		case types.SideTypeBuy:
+			buy += remaining
+		case types.SideTypeSell:
+			sell += remaining
+		}
+	}
+
+	return buy, sell
+}
+
+// wrapRiskLimitOrderExecutor wraps the order executor with the risk limits if the session limits, the global limits or the symbol caps are configured,
+// the rejections are delivered to the strategy if it implements RiskRejectionHandler.
+func wrapRiskLimitOrderExecutor(strategy SingleExchangeStrategy, session *ExchangeSession, sessions map[string]*ExchangeSession, riskControls *RiskControls, executor OrderExecutor) OrderExecutor {
+	var globalLimits *RiskLimits
+	var symbolLimits map[string]*SymbolRiskLimits
+	if riskControls != nil {
+		globalLimits = riskControls.GlobalLimits
+		symbolLimits = riskControls.SymbolLimits
+	}
+
+	if session.RiskLimits == nil && globalLimits == nil && len(symbolLimits) == 0 {
+		return executor
+	}
+
+	riskLimitExecutor := NewRiskLimitOrderExecutor(executor, session, sessions, session.RiskLimits, globalLimits, symbolLimits)
+	if handler, ok := strategy.(RiskRejectionHandler); ok {
+		riskLimitExecutor.OnRejected(handler.HandleRiskRejection)
+	}
+
+	return riskLimitExecutor
+}
@@ -0,0 +1,147 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRiskLimits_LimitQuantity(t *testing.T) {
+	limits := &RiskLimits{
+		MaxOrderValue: fixedpoint.NewFromFloat(1000.0),
+		MaxPosition: map[string]fixedpoint.Value{
+			"BTCUSDT": fixedpoint.NewFromFloat(0.1),
+		},
+		MaxExposure: fixedpoint.NewFromFloat(5000.0),
+	}
+
+	buy := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.01}
+	sell := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 0.01}
+
+	t.Run("within limits", func(t *testing.T) {
+		quantity, reasons := limits.LimitQuantity(buy, 10000.0, 1.0, RiskExposure{Positions: map[string]float64{}})
+		assert.Empty(t, reasons)
+		assert.Equal(t, 0.01, quantity)
+	})
+
+	t.Run("max order value", func(t *testing.T) {
+		order := buy
+		order.Quantity = 0.2
+		quantity, reasons := limits.LimitQuantity(order, 10000.0, 1.0, RiskExposure{Positions: map[string]float64{}})
+		assert.Len(t, reasons, 1)
+		assert.InDelta(t, 0.1, quantity, 1e-9)
+	})
+
+	t.Run("max position", func(t *testing.T) {
+		exposure := RiskExposure{Positions: map[string]float64{"BTCUSDT": 0.095}}
+		quantity, reasons := limits.LimitQuantity(buy, 100.0, 1.0, exposure)
+		assert.Len(t, reasons, 1)
+		assert.InDelta(t, 0.005, quantity, 1e-9)
+
+		// reducing the position is always allowed
+		quantity, reasons = limits.LimitQuantity(sell, 100.0, 1.0, exposure)
+		assert.Empty(t, reasons)
+		assert.Equal(t, 0.01, quantity)
+
+		// the short position is limited as well
+		exposure = RiskExposure{Positions: map[string]float64{"BTCUSDT": -0.1}}
+		quantity, reasons = limits.LimitQuantity(sell, 100.0, 1.0, exposure)
+		assert.Len(t, reasons, 1)
+		assert.Equal(t, 0.0, quantity)
+	})
+
+	t.Run("no price", func(t *testing.T) {
+		quantity, reasons := limits.LimitQuantity(buy, 0, 1.0, RiskExposure{Positions: map[string]float64{}})
+		assert.Len(t, reasons, 1)
+		assert.Equal(t, 0.0, quantity)
+	})
+
+	t.Run("max exposure", func(t *testing.T) {
+		exposure := RiskExposure{Positions: map[string]float64{}, Exposure: 4950.0}
+		quantity, reasons := limits.LimitQuantity(buy, 10000.0, 1.0, exposure)
+		assert.Len(t, reasons, 1)
+		assert.InDelta(t, 0.005, quantity, 1e-9)
+
+		// closing the short position doesn't increase the exposure
+		exposure = RiskExposure{Positions: map[string]float64{"BTCUSDT": -0.01}, Exposure: 5000.0}
+		quantity, reasons = limits.LimitQuantity(buy, 10000.0, 1.0, exposure)
+		assert.Empty(t, reasons)
+		assert.Equal(t, 0.01, quantity)
+	})
+}
+
+func TestRiskExposure_Apply(t *testing.T) {
+	exposure := RiskExposure{Positions: map[string]float64{"BTCUSDT": -0.01}, Exposure: 100.0}
+
+	exposure.Apply(types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.03}, 10000.0, 1.0)
+	assert.InDelta(t, 0.02, exposure.Positions["BTCUSDT"], 1e-9)
+	assert.InDelta(t, 200.0, exposure.Exposure, 1e-9)
+}
+
+func TestRiskExposureOf(t *testing.T) {
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+	session := NewExchangeSession("binance", mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": market}, nil))
+	session.markets["BTCUSDT"] = market
+	session.lastPrices["BTCUSDT"] = 10000.0
+	session.positions["BTCUSDT"] = &Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(0.1)}
+
+	store := NewOrderStore("BTCUSDT")
+	store.Add(
+		types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.2}, OrderID: 1, Status: types.OrderStatusPartiallyFilled, ExecutedQuantity: 0.05},
+		types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 0.1}, OrderID: 2, Status: types.OrderStatusNew},
+		types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 1.0}, OrderID: 3, Status: types.OrderStatusFilled, ExecutedQuantity: 1.0},
+	)
+	session.orderStores["BTCUSDT"] = store
+
+	exposure := riskExposureOf(session)
+	assert.InDelta(t, 0.1, exposure.Positions["BTCUSDT"], 1e-9)
+
+	// the worst case is all the active buy orders are filled: (0.1 + 0.15) * 10000
+	assert.InDelta(t, 2500.0, exposure.Exposure, 1e-9)
+}
+
+func TestSymbolRiskLimits_Check(t *testing.T) {
+	limits := &SymbolRiskLimits{
+		MaxOrderNotional:    fixedpoint.NewFromFloat(1000.0),
+		MaxPositionNotional: fixedpoint.NewFromFloat(3000.0),
+		MaxLeverage:         fixedpoint.NewFromFloat(2.0),
+	}
+
+	buy := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Quantity: 0.05}
+	sell := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Quantity: 0.05}
+
+	t.Run("within limits", func(t *testing.T) {
+		assert.Empty(t, limits.Check(buy, 10000.0, 1.0, 0.1, 10000.0))
+	})
+
+	t.Run("no price", func(t *testing.T) {
+		assert.Len(t, limits.Check(buy, 0, 1.0, 0.1, 10000.0), 1)
+	})
+
+	t.Run("max order notional", func(t *testing.T) {
+		order := buy
+		order.Quantity = 0.2
+		assert.Len(t, limits.Check(order, 10000.0, 1.0, 0, 10000.0), 1)
+	})
+
+	t.Run("max position notional", func(t *testing.T) {
+		assert.Len(t, limits.Check(buy, 10000.0, 1.0, 0.3, 100000.0), 1)
+
+		// reducing the position is always allowed
+		assert.Empty(t, limits.Check(sell, 10000.0, 1.0, 0.3, 100000.0))
+	})
+
+	t.Run("max leverage", func(t *testing.T) {
+		assert.Len(t, limits.Check(buy, 10000.0, 1.0, 0.1, 500.0), 1)
+
+		// the leverage can't be checked without the equity
+		assert.Len(t, limits.Check(buy, 10000.0, 1.0, 0.1, 0), 1)
+
+		// reducing the position is always allowed
+		assert.Empty(t, limits.Check(sell, 10000.0, 1.0, 0.1, 500.0))
+	})
+}
@@ -0,0 +1,143 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Scale maps the domain value to the range value, e.g., maps the grid level to the order quantity
+type Scale interface {
+	Solve() error
+	Formula() string
+	Call(x float64) (y float64)
+}
+
+// LinearScale maps the domain to the range linearly: y = a * x + b
+type LinearScale struct {
+	Domain [2]float64 `json:"domain"`
+	Range  [2]float64 `json:"range"`
+
+	a, b float64
+}
+
+func (s *LinearScale) Solve() error {
+	if s.Domain[0] == s.Domain[1] {
+		return errors.New("linear scale: the domain start and the domain end can not be the same")
+	}
+
+	s.a = (s.Range[1] - s.Range[0]) / (s.Domain[1] - s.Domain[0])
+	s.b = s.Range[0] - s.a*s.Domain[0]
+	return nil
+}
+
+func (s *LinearScale) Formula() string {
+	return fmt.Sprintf("f(x) = %f * x + %f", s.a, s.b)
+}
+
+func (s *LinearScale) Call(x float64) float64 {
+	return s.a*x + s.b
+}
+
+// ExponentialScale maps the domain to the range exponentially: y = a * exp(b * (x - domain start))
+type ExponentialScale struct {
+	Domain [2]float64 `json:"domain"`
+	Range  [2]float64 `json:"range"`
+
+	a, b float64
+}
+
+func (s *ExponentialScale) Solve() error {
+	if s.Domain[0] == s.Domain[1] {
+		return errors.New("exponential scale: the domain start and the domain end can not be the same")
+	}
+
+	if s.Range[0] <= 0 || s.Range[1] <= 0 {
+		return errors.New("exponential scale: the range should be positive")
+	}
+
+	s.a = s.Range[0]
+	s.b = math.Log(s.Range[1]/s.Range[0]) / (s.Domain[1] - s.Domain[0])
+	return nil
+}
+
+func (s *ExponentialScale) Formula() string {
+	return fmt.Sprintf("f(x) = %f * exp(%f * (x - %f))", s.a, s.b, s.Domain[0])
+}
+
+func (s *ExponentialScale) Call(x float64) float64 {
+	return s.a * math.Exp(s.b*(x-s.Domain[0]))
+}
+
+// LogarithmicScale maps the domain to the range logarithmically: y = a * ln(x / domain start) + b
+type LogarithmicScale struct {
+	Domain [2]float64 `json:"domain"`
+	Range  [2]float64 `json:"range"`
+
+	a, b float64
+}
+
+func (s *LogarithmicScale) Solve() error {
+	if s.Domain[0] <= 0 || s.Domain[1] <= 0 {
+		return errors.New("logarithmic scale: the domain should be positive")
+	}
+
+	if s.Domain[0] == s.Domain[1] {
+		return errors.New("logarithmic scale: the domain start and the domain end can not be the same")
+	}
+
+	s.a = (s.Range[1] - s.Range[0]) / math.Log(s.Domain[1]/s.Domain[0])
+	s.b = s.Range[0]
+	return nil
+}
+
+func (s *LogarithmicScale) Formula() string {
+	return fmt.Sprintf("f(x) = %f * ln(x / %f) + %f", s.a, s.Domain[0], s.b)
+}
+
+func (s *LogarithmicScale) Call(x float64) float64 {
+	return s.a*math.Log(x/s.Domain[0]) + s.b
+}
+
+// SlideRule is the config struct of the scales, only one of the "linear", "exp" and "log" scales should be defined.
+type SlideRule struct {
+	LinearScale      *LinearScale      `json:"linear,omitempty" yaml:"linear,omitempty"`
+	ExponentialScale *ExponentialScale `json:"exp,omitempty" yaml:"exp,omitempty"`
+	LogarithmicScale *LogarithmicScale `json:"log,omitempty" yaml:"log,omitempty"`
+}
+
+// Scale returns the defined scale
+func (rule *SlideRule) Scale() (Scale, error) {
+	switch {
+	case rule.LinearScale != nil:
+		return rule.LinearScale, nil
+
+	case rule.ExponentialScale != nil:
+		return rule.ExponentialScale, nil
+
+	case rule.LogarithmicScale != nil:
+		return rule.LogarithmicScale, nil
+	}
+
+	return nil, errors.New("no scale is defined in the slide rule")
+}
+
+// Solve solves the parameters of the defined scale
+func (rule *SlideRule) Solve() error {
+	scale, err := rule.Scale()
+	if err != nil {
+		return err
+	}
+
+	return scale.Solve()
+}
+
+// Call calls the defined scale, the slide rule should be solved before calling this method
+func (rule *SlideRule) Call(x float64) float64 {
+	scale, err := rule.Scale()
+	if err != nil {
+		return 0
+	}
+
+	return scale.Call(x)
+}
@@ -0,0 +1,42 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearScale(t *testing.T) {
+	scale := &LinearScale{Domain: [2]float64{1, 11}, Range: [2]float64{0.01, 0.11}}
+	assert.NoError(t, scale.Solve())
+	assert.InDelta(t, 0.01, scale.Call(1), 1e-9)
+	assert.InDelta(t, 0.06, scale.Call(6), 1e-9)
+	assert.InDelta(t, 0.11, scale.Call(11), 1e-9)
+}
+
+func TestExponentialScale(t *testing.T) {
+	scale := &ExponentialScale{Domain: [2]float64{1, 3}, Range: [2]float64{1, 4}}
+	assert.NoError(t, scale.Solve())
+	assert.InDelta(t, 1.0, scale.Call(1), 1e-9)
+	assert.InDelta(t, 2.0, scale.Call(2), 1e-9)
+	assert.InDelta(t, 4.0, scale.Call(3), 1e-9)
+
+	assert.Error(t, (&ExponentialScale{Domain: [2]float64{1, 3}, Range: [2]float64{0, 4}}).Solve())
+}
+
+func TestLogarithmicScale(t *testing.T) {
+	scale := &LogarithmicScale{Domain: [2]float64{1, 100}, Range: [2]float64{1, 3}}
+	assert.NoError(t, scale.Solve())
+	assert.InDelta(t, 1.0, scale.Call(1), 1e-9)
+	assert.InDelta(t, 2.0, scale.Call(10), 1e-9)
+	assert.InDelta(t, 3.0, scale.Call(100), 1e-9)
+}
+
+func TestSlideRule(t *testing.T) {
+	rule := &SlideRule{}
+	assert.Error(t, rule.Solve())
+
+	rule.LinearScale = &LinearScale{Domain: [2]float64{0, 1}, Range: [2]float64{0, 10}}
+	assert.NoError(t, rule.Solve())
+	assert.InDelta(t, 5.0, rule.Call(0.5), 1e-9)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
@@ -20,16 +23,40 @@ type StandardIndicatorSet struct {
 	sma  map[types.IntervalWindow]*indicator.SMA
 	ewma map[types.IntervalWindow]*indicator.EWMA
 	boll map[types.IntervalWindow]*indicator.BOLL
+	rsi  map[types.IntervalWindow]*indicator.RSI
+	macd map[macdKey]*indicator.MACD
+	atr  map[types.IntervalWindow]*indicator.ATR
+	vwap map[types.IntervalWindow]*indicator.VWAP
+
+	// custom stores the registered custom indicators
+	custom map[customIndicatorKey]indicator.Indicator
 
 	store *MarketDataStore
 }
 
+// customIndicatorKey is the key of the custom indicators, the name is the registered name of the indicator
+type customIndicatorKey struct {
+	Name string
+	types.IntervalWindow
+}
+
+// macdKey is the key of the MACD indicators, the window of the interval window is the signal period
+type macdKey struct {
+	types.IntervalWindow
+	ShortPeriod, LongPeriod int
+}
+
 func NewStandardIndicatorSet(symbol string, store *MarketDataStore) *StandardIndicatorSet {
 	set := &StandardIndicatorSet{
 		Symbol: symbol,
 		sma:    make(map[types.IntervalWindow]*indicator.SMA),
 		ewma:   make(map[types.IntervalWindow]*indicator.EWMA),
 		boll:   make(map[types.IntervalWindow]*indicator.BOLL),
+		rsi:    make(map[types.IntervalWindow]*indicator.RSI),
+		macd:   make(map[macdKey]*indicator.MACD),
+		atr:    make(map[types.IntervalWindow]*indicator.ATR),
+		vwap:   make(map[types.IntervalWindow]*indicator.VWAP),
+		custom: make(map[customIndicatorKey]indicator.Indicator),
 		store:  store,
 	}
 
@@ -55,12 +82,22 @@ func NewStandardIndicatorSet(symbol string, store *MarketDataStore) *StandardInd
 	return set
 }
 
+// normalizeIntervalWindow makes the default price source share the same indicator key with the close price source
+func normalizeIntervalWindow(iw types.IntervalWindow) types.IntervalWindow {
+	if iw.PriceSource == types.PriceSourceClose {
+		iw.PriceSource = ""
+	}
+
+	return iw
+}
+
 // BOLL returns the bollinger band indicator of the given interval and the window,
 // Please note that the K for std dev is fixed and defaults to 2.0
 func (set *StandardIndicatorSet) BOLL(iw types.IntervalWindow, bandWidth float64) *indicator.BOLL {
+	iw = normalizeIntervalWindow(iw)
 	inc, ok := set.boll[iw]
 	if !ok {
-		inc := &indicator.BOLL{IntervalWindow: iw, K: bandWidth}
+		inc = &indicator.BOLL{IntervalWindow: iw, K: bandWidth}
 		inc.Bind(set.store)
 		set.boll[iw] = inc
 	}
@@ -69,10 +106,12 @@ func (set *StandardIndicatorSet) BOLL(iw types.IntervalWindow, bandWidth float64
 }
 
 // SMA returns the simple moving average indicator of the given interval and the window size.
+// The price source of the interval window selects the kline price used as the input, defaults to the close price.
 func (set *StandardIndicatorSet) SMA(iw types.IntervalWindow) *indicator.SMA {
+	iw = normalizeIntervalWindow(iw)
 	inc, ok := set.sma[iw]
 	if !ok {
-		inc := &indicator.SMA{IntervalWindow: iw}
+		inc = &indicator.SMA{IntervalWindow: iw}
 		inc.Bind(set.store)
 		set.sma[iw] = inc
 	}
@@ -82,9 +121,10 @@ func (set *StandardIndicatorSet) SMA(iw types.IntervalWindow) *indicator.SMA {
 
 // GetEWMA returns the exponential weighed moving average indicator of the given interval and the window size.
 func (set *StandardIndicatorSet) EWMA(iw types.IntervalWindow) *indicator.EWMA {
+	iw = normalizeIntervalWindow(iw)
 	inc, ok := set.ewma[iw]
 	if !ok {
-		inc := &indicator.EWMA{IntervalWindow: iw}
+		inc = &indicator.EWMA{IntervalWindow: iw}
 		inc.Bind(set.store)
 		set.ewma[iw] = inc
 	}
@@ -92,6 +132,121 @@ func (set *StandardIndicatorSet) EWMA(iw types.IntervalWindow) *indicator.EWMA {
 	return inc
 }
 
+// RSI returns the relative strength index indicator of the given interval and the window size, e.g., 14.
+func (set *StandardIndicatorSet) RSI(iw types.IntervalWindow) *indicator.RSI {
+	iw = normalizeIntervalWindow(iw)
+	inc, ok := set.rsi[iw]
+	if !ok {
+		inc = &indicator.RSI{IntervalWindow: iw}
+		inc.Bind(set.store)
+		set.rsi[iw] = inc
+	}
+
+	return inc
+}
+
+// MACD returns the MACD indicator of the given interval, the window of the interval window is the signal period,
+// the short period and the long period are the EMA periods of the MACD line, generally they're 9, 12 and 26.
+func (set *StandardIndicatorSet) MACD(iw types.IntervalWindow, shortPeriod, longPeriod int) *indicator.MACD {
+	key := macdKey{IntervalWindow: normalizeIntervalWindow(iw), ShortPeriod: shortPeriod, LongPeriod: longPeriod}
+	inc, ok := set.macd[key]
+	if !ok {
+		inc = &indicator.MACD{IntervalWindow: key.IntervalWindow, ShortPeriod: shortPeriod, LongPeriod: longPeriod}
+		inc.Bind(set.store)
+		set.macd[key] = inc
+	}
+
+	return inc
+}
+
+// ATR returns the average true range indicator of the given interval and the window size, the price source is not used.
+func (set *StandardIndicatorSet) ATR(iw types.IntervalWindow) *indicator.ATR {
+	iw = normalizeIntervalWindow(iw)
+	iw.PriceSource = ""
+	inc, ok := set.atr[iw]
+	if !ok {
+		inc = &indicator.ATR{IntervalWindow: iw}
+		inc.Bind(set.store)
+		set.atr[iw] = inc
+	}
+
+	return inc
+}
+
+// VWAP returns the rolling volume weighted average price indicator of the given interval and the window size,
+// the typical price of the klines is weighted by the volume, the price source is not used.
+func (set *StandardIndicatorSet) VWAP(iw types.IntervalWindow) *indicator.VWAP {
+	iw = normalizeIntervalWindow(iw)
+	iw.PriceSource = ""
+	inc, ok := set.vwap[iw]
+	if !ok {
+		inc = &indicator.VWAP{IntervalWindow: iw}
+		inc.Bind(set.store)
+		set.vwap[iw] = inc
+	}
+
+	return inc
+}
+
+// Indicator returns the custom indicator registered by indicator.Register of the given interval window,
+// like the built-in indicators, it's created and bound to the market data store once, and shared by the strategies.
+func (set *StandardIndicatorSet) Indicator(name string, iw types.IntervalWindow) (indicator.Indicator, error) {
+	key := customIndicatorKey{Name: name, IntervalWindow: normalizeIntervalWindow(iw)}
+	inc, ok := set.custom[key]
+	if !ok {
+		var err error
+		inc, err = indicator.New(name, key.IntervalWindow)
+		if err != nil {
+			return nil, err
+		}
+
+		inc.Bind(set.store)
+		set.custom[key] = inc
+	}
+
+	return inc, nil
+}
+
+// OnKLineClosed registers the callback of the closed kline, the callback is called after the indicators of the set are updated.
+func (set *StandardIndicatorSet) OnKLineClosed(cb func(kline types.KLine)) {
+	set.store.OnKLineClosed(cb)
+}
+
+// OnBOLLUpdate registers the update callback of the bollinger band indicator, the indicator is created if it doesn't exist.
+func (set *StandardIndicatorSet) OnBOLLUpdate(iw types.IntervalWindow, bandWidth float64, cb func(sma, upBand, downBand float64)) {
+	set.BOLL(iw, bandWidth).OnUpdate(cb)
+}
+
+// OnSMAUpdate registers the update callback of the simple moving average indicator
+func (set *StandardIndicatorSet) OnSMAUpdate(iw types.IntervalWindow, cb func(value float64)) {
+	set.SMA(iw).OnUpdate(cb)
+}
+
+// OnEWMAUpdate registers the update callback of the exponential weighed moving average indicator
+func (set *StandardIndicatorSet) OnEWMAUpdate(iw types.IntervalWindow, cb func(value float64)) {
+	set.EWMA(iw).OnUpdate(cb)
+}
+
+// OnRSIUpdate registers the update callback of the relative strength index indicator
+func (set *StandardIndicatorSet) OnRSIUpdate(iw types.IntervalWindow, cb func(value float64)) {
+	set.RSI(iw).OnUpdate(cb)
+}
+
+// OnMACDUpdate registers the update callback of the MACD indicator
+func (set *StandardIndicatorSet) OnMACDUpdate(iw types.IntervalWindow, shortPeriod, longPeriod int, cb func(macd, signal, histogram float64)) {
+	set.MACD(iw, shortPeriod, longPeriod).OnUpdate(cb)
+}
+
+// OnATRUpdate registers the update callback of the average true range indicator
+func (set *StandardIndicatorSet) OnATRUpdate(iw types.IntervalWindow, cb func(value float64)) {
+	set.ATR(iw).OnUpdate(cb)
+}
+
+// OnVWAPUpdate registers the update callback of the volume weighted average price indicator
+func (set *StandardIndicatorSet) OnVWAPUpdate(iw types.IntervalWindow, cb func(value float64)) {
+	set.VWAP(iw).OnUpdate(cb)
+}
+
 // ExchangeSession presents the exchange connection Session
 // It also maintains and collects the data returned from the stream.
 type ExchangeSession struct {
@@ -109,12 +264,109 @@ type ExchangeSession struct {
 	EnvVarPrefix string `json:"envVarPrefix" yaml:"envVarPrefix"`
 	Key          string `json:"key,omitempty" yaml:"key,omitempty"`
 	Secret       string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	Passphrase   string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+
+	// SubAccount is the sub-account id (the email for binance) of the api key, it's empty for the master account.
+	// It's used for moving the assets between the sessions of the same exchange, see Environment.TransferBetweenSessions.
+	SubAccount string `json:"subAccount,omitempty" yaml:"subAccount,omitempty"`
 
 	PublicOnly           bool   `json:"publicOnly,omitempty" yaml:"publicOnly"`
 	Margin               bool   `json:"margin,omitempty" yaml:"margin"`
 	IsolatedMargin       bool   `json:"isolatedMargin,omitempty" yaml:"isolatedMargin,omitempty"`
 	IsolatedMarginSymbol string `json:"isolatedMarginSymbol,omitempty" yaml:"isolatedMarginSymbol,omitempty"`
 
+	// AutoBorrow borrows the shortfall of the orders from the margin account before submitting them,
+	// so that the strategies of the margin session can short or leverage
+	AutoBorrow bool `json:"autoBorrow,omitempty" yaml:"autoBorrow,omitempty"`
+
+	// AutoRepay repays the borrowed assets with the available balances after the orders are filled
+	AutoRepay bool `json:"autoRepay,omitempty" yaml:"autoRepay,omitempty"`
+
+	// Futures switches the session to the futures (perpetual contract) market of the exchange
+	Futures bool `json:"futures,omitempty" yaml:"futures,omitempty"`
+
+	// Leverage is the initial leverage of each futures symbol, it's applied when the session is initialized
+	Leverage map[string]int `json:"leverage,omitempty" yaml:"leverage,omitempty"`
+
+	// MarginType is the margin mode (ISOLATED or CROSSED) of the futures symbols listed in the leverage settings
+	MarginType types.MarginType `json:"marginType,omitempty" yaml:"marginType,omitempty"`
+
+	// PaperTrade simulates the orders against the live order book of the exchange, no order is sent to the exchange
+	PaperTrade bool `json:"paperTrade,omitempty" yaml:"paperTrade,omitempty"`
+
+	// PaperTradeBalances is the initial balances of the paper trade account, the real account balances are used if it's not set
+	PaperTradeBalances BacktestAccountBalanceMap `json:"paperTradeBalances,omitempty" yaml:"paperTradeBalances,omitempty"`
+
+	// WarmStart caches the market data on shutdown and loads the cached market data on startup,
+	// so that the indicators don't need to wait for a full window of the live data.
+	WarmStart bool `json:"warmStart,omitempty" yaml:"warmStart,omitempty"`
+
+	// WarmStartMaxAge is the max age of the cached market data, defaults to 1 hour
+	WarmStartMaxAge types.Duration `json:"warmStartMaxAge,omitempty" yaml:"warmStartMaxAge,omitempty"`
+
+	// MonitorAnnouncements polls the exchange announcement feed (new listings, delistings and maintenance),
+	// the currencies of the delisting announcements are added to the session blacklist.
+	MonitorAnnouncements bool `json:"monitorAnnouncements,omitempty" yaml:"monitorAnnouncements,omitempty"`
+
+	// AnnouncementInterval is the polling interval of the announcement feed, defaults to 5 minutes
+	AnnouncementInterval types.Duration `json:"announcementInterval,omitempty" yaml:"announcementInterval,omitempty"`
+
+	// LatencyReportInterval logs the event latency percentiles (network and processing) of each stream channel periodically
+	LatencyReportInterval types.Duration `json:"latencyReportInterval,omitempty" yaml:"latencyReportInterval,omitempty"`
+
+	// CollateralUpdateInterval is the interval of updating the collateral balances and the index prices of the margin session,
+	// defaults to 1 minute
+	CollateralUpdateInterval types.Duration `json:"collateralUpdateInterval,omitempty" yaml:"collateralUpdateInterval,omitempty"`
+
+	// StreamRecovery queries the orders and the trades via the REST API when the user data stream is re-connected,
+	// and emits the order updates and the trade updates missed while the stream was disconnected.
+	StreamRecovery bool `json:"streamRecovery,omitempty" yaml:"streamRecovery,omitempty"`
+
+	// StreamSupervisor monitors the last message age of the stream, marks the stream degraded when it's silent,
+	// and drops the silent connection for reconnecting, see StreamSupervisorConfig
+	StreamSupervisor *StreamSupervisorConfig `json:"streamSupervisor,omitempty" yaml:"streamSupervisor,omitempty"`
+
+	// MakerFeeRate and TakerFeeRate are the trading fee rates of the session, e.g., 0.001 for 0.1%,
+	// they are queried from the exchange account when both of them are not configured.
+	MakerFeeRate fixedpoint.Value `json:"makerFeeRate,omitempty" yaml:"makerFeeRate,omitempty"`
+	TakerFeeRate fixedpoint.Value `json:"takerFeeRate,omitempty" yaml:"takerFeeRate,omitempty"`
+
+	// RiskLimits rejects or truncates the orders exceeding the max order value, the max position or the max exposure of this session
+	RiskLimits *RiskLimits `json:"riskLimits,omitempty" yaml:"riskLimits,omitempty"`
+
+	// KLineAggregation builds the klines of these intervals from the 1m klines instead of subscribing them from the exchange,
+	// for the exchanges providing only the 1m klines, e.g., ["5m", "1h"]
+	KLineAggregation []types.Interval `json:"klineAggregation,omitempty" yaml:"klineAggregation,omitempty"`
+
+	// TradingPolicy restricts the symbols and the trading hours of the orders submitted to this session
+	TradingPolicy *TradingPolicy `json:"tradingPolicy,omitempty" yaml:"tradingPolicy,omitempty"`
+
+	// OrderSubmitWorkers is the number of the orders of a batch submitted concurrently, defaults to 4,
+	// set it to 1 to submit the orders one by one in order.
+	OrderSubmitWorkers int `json:"orderSubmitWorkers,omitempty" yaml:"orderSubmitWorkers,omitempty"`
+
+	// EventDispatcher runs the stream callbacks in a bounded worker pool instead of the stream read loop,
+	// so that the slow callbacks don't block the websocket reads under bursty market data.
+	EventDispatcher *types.DispatcherOptions `json:"eventDispatcher,omitempty" yaml:"eventDispatcher,omitempty"`
+
+	// MarketOverrides overrides the precisions and the min notional filters reported by the exchange by symbol,
+	// the overrides diverging from the exchange info are logged as warnings.
+	MarketOverrides map[string]MarketOverride `json:"marketOverrides,omitempty" yaml:"marketOverrides,omitempty"`
+
+	// Lazy initializes and connects the session only when a strategy is attached to it or subscribes its market data
+	Lazy bool `json:"lazy,omitempty" yaml:"lazy,omitempty"`
+
+	// InitOrder is the initialization order of the session, the sessions of the lower orders are initialized first,
+	// and the sessions of the same order are initialized in the order of their names.
+	InitOrder int `json:"initOrder,omitempty" yaml:"initOrder,omitempty"`
+
+	// InitTimeout is the timeout of loading the markets, the balances and the symbol data of the session
+	InitTimeout types.Duration `json:"initTimeout,omitempty" yaml:"initTimeout,omitempty"`
+
+	// Optional keeps the bot running when the session can not be initialized or connected,
+	// the strategies on the session are skipped while the strategies on the other sessions are started as usual.
+	Optional bool `json:"optional,omitempty" yaml:"optional,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -122,6 +374,9 @@ type ExchangeSession struct {
 	// The exchange account states
 	Account *types.Account `json:"-" yaml:"-"`
 
+	// Blacklist contains the currencies that should not be bought in this session
+	Blacklist *SymbolBlacklist `json:"-" yaml:"-"`
+
 	IsInitialized bool `json:"-" yaml:"-"`
 
 	// Stream is the connection stream of the exchange
@@ -149,16 +404,50 @@ type ExchangeSession struct {
 
 	positions map[string]*Position
 
+	profitStats map[string]*ProfitStats
+
+	// collateralBalances is the last queried collateral balances of the margin account (with the borrowed amount and the interest),
+	// and collateralPrices is the last queried index prices of the collateral assets.
+	collateralMutex    sync.RWMutex
+	collateralBalances types.BalanceMap
+	collateralPrices   map[string]float64
+
+	// accountFees is the fee tier of the account queried from the exchange, it's nil if the fee rates are configured
+	accountFees *types.AccountFees
+
+	// tradingFeeRates are the fee rates of the used symbols queried from the exchange
+	tradingFeeRates types.TradingFeeRateMap
+
 	// standard indicators of each market
 	standardIndicatorSets map[string]*StandardIndicatorSet
 
 	orderStores map[string]*OrderStore
 
+	// orderTags keeps the tags of the orders submitted from this session
+	orderTags *OrderTagMap
+
+	// auditor records the account state mutations of this session, it's nil if the audit log is not enabled
+	auditor *Auditor
+
+	// lastLocalOrderID is the last serial ID of the strategy orders submitted from this session
+	lastLocalOrderID uint64
+
 	orderExecutor *ExchangeOrderExecutor
 
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
+	// attached is set when a single exchange strategy is attached to the session
+	attached bool
+
+	// subscribeCount is the number of the Subscribe calls, it's used for finding the sessions subscribed by a cross exchange strategy
+	subscribeCount int
+
+	// unavailableError is the reason why the optional session is not available
+	unavailableError error
+
+	streamSupervisor *StreamSupervisor
+
 	logger *log.Entry
 }
 
@@ -175,15 +464,18 @@ func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 		Stream:        exchange.NewStream(),
 		Subscriptions: make(map[types.Subscription]types.Subscription),
 		Account:       &types.Account{},
+		Blacklist:     NewSymbolBlacklist(),
 		Trades:        make(map[string]*types.TradeSlice),
 
 		markets:               make(map[string]types.Market),
 		startPrices:           make(map[string]float64),
 		lastPrices:            make(map[string]float64),
 		positions:             make(map[string]*Position),
+		profitStats:           make(map[string]*ProfitStats),
 		marketDataStores:      make(map[string]*MarketDataStore),
 		standardIndicatorSets: make(map[string]*StandardIndicatorSet),
 		orderStores:           make(map[string]*OrderStore),
+		orderTags:             NewOrderTagMap(),
 		usedSymbols:           make(map[string]struct{}),
 		initializedSymbols:    make(map[string]struct{}),
 		logger:                log.WithField("session", name),
@@ -207,19 +499,44 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		return fmt.Errorf("market config should not be empty")
 	}
 
-	session.markets = markets
+	session.markets = ApplyMarketOverrides(markets, session.MarketOverrides, log)
 
-	// query and initialize the balances
-	log.Infof("querying balances from session %s...", session.Name)
-	balances, err := session.Exchange.QueryAccountBalances(ctx)
-	if err != nil {
-		return err
+	// the public only session has no account, so we skip the futures settings and the balances
+	if session.Futures && !session.PublicOnly {
+		if err := session.initFutures(ctx); err != nil {
+			return err
+		}
 	}
 
-	log.Infof("%s account", session.Name)
-	balances.Print()
+	if session.PublicOnly {
+		log.Infof("session %s is public only, only the public market data is consumed", session.Name)
+	} else {
+		// query and initialize the balances
+		log.Infof("querying balances from session %s...", session.Name)
+		balances, err := session.Exchange.QueryAccountBalances(ctx)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%s account", session.Name)
+		balances.Print()
+
+		session.Account.UpdateBalances(balances)
+
+		if session.MakerFeeRate == 0 && session.TakerFeeRate == 0 {
+			session.queryFeeRates(ctx)
+		}
+	}
 
-	session.Account.UpdateBalances(balances)
+	// continue the serial of the local order IDs from the stored strategy orders
+	if environ.StrategyOrderService != nil && !session.PublicOnly {
+		lastLocalOrderID, err := environ.StrategyOrderService.LastLocalID(session.Name)
+		if err != nil {
+			return err
+		}
+
+		session.lastLocalOrderID = lastLocalOrderID
+	}
 
 	var orderExecutor = &ExchangeOrderExecutor{
 		// copy the notification system so that we can route
@@ -234,15 +551,60 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 
 	session.Account.BindStream(session.Stream)
 
-	// insert trade into db right before everything
-	if environ.TradeService != nil {
+	var supervisorConfig StreamSupervisorConfig
+	if session.StreamSupervisor != nil {
+		supervisorConfig = *session.StreamSupervisor
+	}
+
+	session.streamSupervisor = NewStreamSupervisor(session.Stream, supervisorConfig)
+	session.streamSupervisor.logger = log
+
+	if session.StreamRecovery && !session.PublicOnly && !session.PaperTrade {
+		if emitter, ok := session.Stream.(StreamEventEmitter); ok {
+			NewStreamRecovery(session, emitter).BindStream(session.Stream)
+		} else {
+			log.Warnf("the stream of exchange %s does not support the stream recovery", session.ExchangeName)
+		}
+	}
+
+	// insert trade into db right before everything, the simulated trades of the paper trade session are not stored
+	if environ.TradeService != nil && !session.PaperTrade {
 		session.Stream.OnTradeUpdate(func(trade types.Trade) {
+			if tags, ok := session.orderTags.Get(trade.OrderID); ok {
+				trade.Tags = tags
+			}
+
 			if err := environ.TradeService.Insert(trade); err != nil {
 				log.WithError(err).Errorf("trade insert error: %+v", trade)
 			}
 		})
 	}
 
+	// publish the trades on the message bus for the strategies and the services subscribing TopicTrades
+	if environ.MessageBus != nil {
+		session.Stream.OnTradeUpdate(func(trade types.Trade) {
+			environ.MessageBus.PublishTrade(session.Name, trade)
+		})
+	}
+
+	// only the tagged orders are stored here, other orders are synced from the exchange by the sync service
+	if environ.OrderService != nil && !session.PaperTrade {
+		session.Stream.OnOrderUpdate(func(order types.Order) {
+			tags, ok := session.orderTags.GetByOrder(order)
+			if !ok {
+				return
+			}
+
+			order.Tags = tags
+			if err := environ.OrderService.Insert(order); err != nil {
+				log.WithError(err).Errorf("order insert error: %+v", order)
+			}
+		})
+	}
+
+	// the tags of the closed orders are removed after the order updates and the trade updates are stored
+	session.Stream.OnOrderUpdate(session.orderTags.Update)
+
 	session.Stream.OnKLineClosed(func(kline types.KLine) {
 		log.Infof("kline closed: %+v", kline)
 	})
@@ -260,8 +622,73 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 	return nil
 }
 
+// initFutures applies the leverage and the margin type of the futures symbols
+func (session *ExchangeSession) initFutures(ctx context.Context) error {
+	futuresExchange, ok := session.Exchange.(types.FuturesExchange)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support futures", session.ExchangeName)
+	}
+
+	for symbol, leverage := range session.Leverage {
+		if _, ok := session.markets[symbol]; !ok {
+			return fmt.Errorf("futures market %s is not defined", symbol)
+		}
+
+		if len(session.MarginType) > 0 {
+			if err := futuresExchange.SetMarginType(ctx, symbol, session.MarginType); err != nil {
+				return errors.Wrapf(err, "can not set the margin type of %s to %s", symbol, session.MarginType)
+			}
+		}
+
+		if leverage > 0 {
+			if err := futuresExchange.SetLeverage(ctx, symbol, leverage); err != nil {
+				return errors.Wrapf(err, "can not set the leverage of %s to %d", symbol, leverage)
+			}
+		}
+	}
+
+	return nil
+}
+
+// queryFeeRates sets the fee rates from the account fees (after the discount) of the exchange implementing
+// types.ExchangeFeeService, or from the commissions (in basis points) of the exchange account,
+// the fee rates are left unset if the exchange does not report the commissions.
+func (session *ExchangeSession) queryFeeRates(ctx context.Context) {
+	if service, ok := session.Exchange.(types.ExchangeFeeService); ok {
+		fees, err := service.QueryAccountFees(ctx)
+		if err == nil {
+			session.accountFees = fees
+			session.MakerFeeRate = fees.Discount(fees.MakerFeeRate)
+			session.TakerFeeRate = fees.Discount(fees.TakerFeeRate)
+			log.Infof("session %s fee rates: maker %f, taker %f, tier: %q, %s discount enabled: %v",
+				session.Name, session.MakerFeeRate.Float64(), session.TakerFeeRate.Float64(), fees.Tier, fees.DiscountCurrency, fees.DiscountEnabled)
+			return
+		}
+
+		log.WithError(err).Warnf("can not query the account fees of session %s, using the account commissions", session.Name)
+	}
+
+	account, err := session.Exchange.QueryAccount(ctx)
+	if err != nil {
+		log.WithError(err).Warnf("can not query the fee rates of session %s", session.Name)
+		return
+	}
+
+	if account.MakerCommission > 0 {
+		session.MakerFeeRate = fixedpoint.NewFromFloat(float64(account.MakerCommission) * 0.0001)
+	}
+
+	if account.TakerCommission > 0 {
+		session.TakerFeeRate = fixedpoint.NewFromFloat(float64(account.TakerCommission) * 0.0001)
+	}
+
+	log.Infof("session %s fee rates: maker %f, taker %f", session.Name, session.MakerFeeRate.Float64(), session.TakerFeeRate.Float64())
+}
+
 // InitSymbols uses usedSymbols to initialize the related data structure
 func (session *ExchangeSession) InitSymbols(ctx context.Context, environ *Environment) error {
+	session.queryTradingFeeRates(ctx)
+
 	for symbol := range session.usedSymbols {
 		// skip initialized symbols
 		if _, ok := session.initializedSymbols[symbol]; ok {
@@ -276,6 +703,41 @@ func (session *ExchangeSession) InitSymbols(ctx context.Context, environ *Enviro
 	return nil
 }
 
+// queryTradingFeeRates queries the fee rates of the used symbols which are not initialized yet,
+// the symbol fee rates are only queried when the fee rates of the session are queried from the exchange.
+func (session *ExchangeSession) queryTradingFeeRates(ctx context.Context) {
+	service, ok := session.Exchange.(types.ExchangeFeeService)
+	if !ok || session.accountFees == nil {
+		return
+	}
+
+	var symbols []string
+	for symbol := range session.usedSymbols {
+		if _, ok := session.initializedSymbols[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	if len(symbols) == 0 {
+		return
+	}
+
+	rates, err := service.QueryTradingFeeRates(ctx, symbols...)
+	if err != nil {
+		log.WithError(err).Warnf("can not query the trading fee rates of session %s, using the session fee rates", session.Name)
+		return
+	}
+
+	if session.tradingFeeRates == nil {
+		session.tradingFeeRates = make(types.TradingFeeRateMap)
+	}
+
+	for symbol, rate := range rates {
+		session.tradingFeeRates[symbol] = rate
+		log.Infof("session %s %s fee rates: maker %f, taker %f", session.Name, symbol, rate.MakerFeeRate.Float64(), rate.TakerFeeRate.Float64())
+	}
+}
+
 // InitSymbol loads trades for the symbol, bind stream callbacks, init positions, market data store.
 // please note, InitSymbol can not be called for the same symbol for twice
 func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environment, symbol string) error {
@@ -290,7 +752,7 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 
 	var err error
 	var trades []types.Trade
-	if environ.TradeSync != nil {
+	if environ.TradeSync != nil && !session.PaperTrade && !session.PublicOnly {
 		log.Infof("syncing trades from %s for symbol %s...", session.Exchange.Name(), symbol)
 		if err := environ.TradeSync.SyncTrades(ctx, session.Exchange, symbol, environ.tradeScanTime); err != nil {
 			return err
@@ -323,9 +785,31 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 		BaseCurrency:  market.BaseCurrency,
 		QuoteCurrency: market.QuoteCurrency,
 	}
-	position.AddTrades(trades)
-	position.BindStream(session.Stream)
+	profitStats := &ProfitStats{
+		Symbol:        symbol,
+		QuoteCurrency: market.QuoteCurrency,
+	}
+
+	for _, trade := range trades {
+		profit, realized := position.AddTrade(trade)
+		profitStats.AddTrade(trade, profit, realized)
+	}
+
+	session.Stream.OnTradeUpdate(func(trade types.Trade) {
+		if trade.Symbol != symbol {
+			return
+		}
+
+		profit, realized := position.AddTrade(trade)
+		profitStats.AddTrade(trade, profit, realized)
+
+		if environ.MessageBus != nil {
+			environ.MessageBus.PublishPosition(session.Name, position)
+		}
+	})
+
 	session.positions[symbol] = position
+	session.profitStats[symbol] = profitStats
 
 	orderStore := NewOrderStore(symbol)
 	orderStore.AddOrderUpdate = true
@@ -334,6 +818,7 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 	session.orderStores[symbol] = orderStore
 
 	marketDataStore := NewMarketDataStore(symbol)
+	marketDataStore.AggregateKLines(session.KLineAggregation...)
 	marketDataStore.BindStream(session.Stream)
 	session.marketDataStores[symbol] = marketDataStore
 
@@ -352,14 +837,30 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 		}
 	}
 
+	var snapshot *MarketDataSnapshot
+	if session.WarmStart {
+		snapshot, err = session.loadMarketDataSnapshot(symbol, environ.startTime)
+		if err != nil {
+			return err
+		}
+
+		if snapshot != nil && snapshot.IsFresh(environ.startTime, orderBookSnapshotMaxAge) {
+			marketDataStore.handleOrderBookSnapshot(snapshot.OrderBook)
+		}
+	}
+
 	var lastPriceTime time.Time
 	for interval := range usedKLineIntervals {
 		// avoid querying the last unclosed kline
 		endTime := environ.startTime.Add(- interval.Duration())
-		kLines, err := session.Exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
-			EndTime: &endTime,
-			Limit:   1000, // indicators need at least 100
-		})
+
+		var kLines []types.KLine
+		if snapshot != nil {
+			kLines, err = session.queryKLinesFromSnapshot(ctx, snapshot, interval, endTime)
+		} else {
+			kLines, err = session.queryWarmUpKLines(ctx, symbol, interval, endTime)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -391,6 +892,16 @@ func (session *ExchangeSession) InitSymbol(ctx context.Context, environ *Environ
 	return nil
 }
 
+// warmUpKLineLimit is the number of the klines loaded for each interval on startup, indicators need at least 100
+const warmUpKLineLimit = 1000
+
+// queryWarmUpKLines queries the klines before the end time for warming up the indicators,
+// the klines are fetched in batches for the exchanges with a smaller kline query limit.
+func (session *ExchangeSession) queryWarmUpKLines(ctx context.Context, symbol string, interval types.Interval, endTime time.Time) ([]types.KLine, error) {
+	startTime := endTime.Add(-time.Duration(warmUpKLineLimit) * interval.Duration())
+	return types.NewKLineFetcher(session.Exchange).FetchAll(ctx, symbol, interval, startTime, endTime)
+}
+
 func (session *ExchangeSession) StandardIndicatorSet(symbol string) (*StandardIndicatorSet, bool) {
 	set, ok := session.standardIndicatorSets[symbol]
 	return set, ok
@@ -405,6 +916,17 @@ func (session *ExchangeSession) Positions() map[string]*Position {
 	return session.positions
 }
 
+// ConnectionSupervisor returns the connection health supervisor of the session stream, it's nil before the session is initialized.
+// The degraded events are only emitted when the streamSupervisor config of the session is set.
+func (session *ExchangeSession) ConnectionSupervisor() *StreamSupervisor {
+	return session.streamSupervisor
+}
+
+func (session *ExchangeSession) ProfitStats(symbol string) (stats *ProfitStats, ok bool) {
+	stats, ok = session.profitStats[symbol]
+	return stats, ok
+}
+
 // MarketDataStore returns the market data store of a symbol
 func (session *ExchangeSession) MarketDataStore(symbol string) (s *MarketDataStore, ok bool) {
 	s, ok = session.marketDataStores[symbol]
@@ -425,6 +947,26 @@ func (session *ExchangeSession) LastPrices() map[string]float64 {
 	return session.lastPrices
 }
 
+// TradingFeeRate returns the fee rates of the symbol after the discount, the fee rates queried for the symbol
+// are preferred, e.g., the VIP tier rates, otherwise the MakerFeeRate and the TakerFeeRate of the session are returned.
+func (session *ExchangeSession) TradingFeeRate(symbol string) types.TradingFeeRate {
+	if rate, ok := session.tradingFeeRates[symbol]; ok && session.accountFees != nil {
+		return session.accountFees.DiscountTradingFeeRate(rate)
+	}
+
+	return types.TradingFeeRate{
+		Symbol:       symbol,
+		MakerFeeRate: session.MakerFeeRate,
+		TakerFeeRate: session.TakerFeeRate,
+	}
+}
+
+// AccountFees returns the fee tier of the account queried from the exchange,
+// ok is false if the fee rates are configured or the exchange does not support querying the fees.
+func (session *ExchangeSession) AccountFees() (fees *types.AccountFees, ok bool) {
+	return session.accountFees, session.accountFees != nil
+}
+
 func (session *ExchangeSession) Market(symbol string) (market types.Market, ok bool) {
 	market, ok = session.markets[symbol]
 	return market, ok
@@ -443,8 +985,18 @@ func (session *ExchangeSession) OrderStores() map[string]*OrderStore {
 	return session.orderStores
 }
 
+// OrderTags returns the tags of the order submitted from this session
+func (session *ExchangeSession) OrderTags(orderID uint64) (types.OrderTags, bool) {
+	return session.orderTags.Get(orderID)
+}
+
 // Subscribe save the subscription info, later it will be assigned to the stream
 func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string, options types.SubscribeOptions) *ExchangeSession {
+	// the klines of the aggregated intervals are built from the 1m klines
+	if channel == types.KLineChannel && session.isAggregatedInterval(types.Interval(options.Interval)) {
+		options.Interval = string(types.Interval1m)
+	}
+
 	sub := types.Subscription{
 		Channel: channel,
 		Symbol:  symbol,
@@ -454,9 +1006,20 @@ func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string,
 	// add to the loaded symbol table
 	session.usedSymbols[symbol] = struct{}{}
 	session.Subscriptions[sub] = sub
+	session.subscribeCount++
 	return session
 }
 
+func (session *ExchangeSession) isAggregatedInterval(interval types.Interval) bool {
+	for _, i := range session.KLineAggregation {
+		if i == interval {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.SubmitOrder, error) {
 	market, ok := session.Market(order.Symbol)
 	if !ok {
@@ -465,8 +1028,13 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 
 	order.Market = market
 
+	if err := order.ValidateTimeInForce(); err != nil {
+		return order, err
+	}
+
 	switch order.Type {
 	case types.OrderTypeStopMarket, types.OrderTypeStopLimit:
+		order.StopPrice = market.TruncatePrice(order.StopPrice)
 		order.StopPriceString = market.FormatPrice(order.StopPrice)
 
 	}
@@ -477,10 +1045,24 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 		order.PriceString = ""
 
 	default:
+		order.Price = market.TruncatePrice(order.Price)
 		order.PriceString = market.FormatPrice(order.Price)
 
 	}
 
+	// check the lot size and the min notional filters locally, so that the order is not rejected by the exchange
+	order.Quantity = market.TruncateQuantity(order.Quantity)
+
+	price := order.Price
+	if price == 0 {
+		price, _ = session.LastPrice(order.Symbol)
+	}
+
+	if market.IsDustQuantity(order.Quantity, price) {
+		return order, fmt.Errorf("%w: %s %s quantity %f at price %f (min quantity %f, min notional %f)",
+			ErrDustQuantity, order.Symbol, order.Side, order.Quantity, price, market.MinQuantity, market.MinNotionalValue())
+	}
+
 	order.QuantityString = market.FormatQuantity(order.Quantity)
 	return order, nil
 }
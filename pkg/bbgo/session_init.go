@@ -0,0 +1,63 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// sortSessionsByInitOrder sorts the sessions by the InitOrder, the sessions of the same order are sorted by their names
+func sortSessionsByInitOrder(sessions map[string]*ExchangeSession) []*ExchangeSession {
+	var sorted = make([]*ExchangeSession, 0, len(sessions))
+	for _, session := range sessions {
+		sorted = append(sorted, session)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].InitOrder != sorted[j].InitOrder {
+			return sorted[i].InitOrder < sorted[j].InitOrder
+		}
+
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+// IsRequired returns false if the session is lazy and no strategy is attached to it or subscribes its market data
+func (session *ExchangeSession) IsRequired() bool {
+	return !session.Lazy || session.attached || len(session.Subscriptions) > 0
+}
+
+// IsAvailable returns true if the session is initialized and it's not marked unavailable
+func (session *ExchangeSession) IsAvailable() bool {
+	return session.IsInitialized && session.unavailableError == nil
+}
+
+// markUnavailable marks the optional session unavailable, the strategies on it are not started
+func (session *ExchangeSession) markUnavailable(err error) {
+	session.unavailableError = err
+}
+
+// initWithTimeout initializes the session and its symbols within the InitTimeout
+func (session *ExchangeSession) initWithTimeout(ctx context.Context, environ *Environment) error {
+	if session.InitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, session.InitTimeout.Duration())
+		defer cancel()
+	}
+
+	if err := session.Init(ctx, environ); err != nil {
+		// we can skip initialized sessions
+		if err != ErrSessionAlreadyInitialized {
+			return errors.Wrapf(err, "session %s initialization error", session.Name)
+		}
+	}
+
+	if err := session.InitSymbols(ctx, environ); err != nil {
+		return errors.Wrapf(err, "session %s symbol initialization error", session.Name)
+	}
+
+	return nil
+}
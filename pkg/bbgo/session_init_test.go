@@ -0,0 +1,53 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSortSessionsByInitOrder(t *testing.T) {
+	sessions := map[string]*ExchangeSession{
+		"ftx":     {Name: "ftx", InitOrder: 2},
+		"max":     {Name: "max"},
+		"binance": {Name: "binance"},
+		"okex":    {Name: "okex", InitOrder: -1},
+	}
+
+	var names []string
+	for _, session := range sortSessionsByInitOrder(sessions) {
+		names = append(names, session.Name)
+	}
+
+	assert.Equal(t, []string{"okex", "binance", "max", "ftx"}, names)
+}
+
+func TestExchangeSession_IsRequired(t *testing.T) {
+	session := &ExchangeSession{Name: "max", Subscriptions: map[types.Subscription]types.Subscription{}}
+	assert.True(t, session.IsRequired())
+
+	session.Lazy = true
+	assert.False(t, session.IsRequired())
+
+	session.attached = true
+	assert.True(t, session.IsRequired())
+
+	session.attached = false
+	sub := types.Subscription{Channel: types.BookChannel, Symbol: "BTCUSDT"}
+	session.Subscriptions[sub] = sub
+	assert.True(t, session.IsRequired())
+}
+
+func TestExchangeSession_IsAvailable(t *testing.T) {
+	session := &ExchangeSession{Name: "max", Optional: true}
+	assert.False(t, session.IsAvailable())
+
+	session.IsInitialized = true
+	assert.True(t, session.IsAvailable())
+
+	session.markUnavailable(errors.New("connection refused"))
+	assert.False(t, session.IsAvailable())
+}
@@ -0,0 +1,77 @@
+package bbgo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// SQLPersistenceService stores the persistent data in the persistence table of the configured database
+type SQLPersistenceService struct {
+	DB *sqlx.DB
+}
+
+func NewSQLPersistenceService(db *sqlx.DB) *SQLPersistenceService {
+	return &SQLPersistenceService{DB: db}
+}
+
+func (s *SQLPersistenceService) NewStore(id string, subIDs ...string) Store {
+	if len(subIDs) > 0 {
+		id += ":" + strings.Join(subIDs, ":")
+	}
+
+	return &SQLStore{
+		DB: s.DB,
+		ID: id,
+	}
+}
+
+type SQLStore struct {
+	DB *sqlx.DB
+
+	ID string
+}
+
+func (store *SQLStore) Load(val interface{}) error {
+	var data string
+	err := store.DB.Get(&data, `SELECT data FROM persistence WHERE id = ?`, store.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPersistenceNotExists
+		}
+
+		return err
+	}
+
+	if len(data) == 0 {
+		return ErrPersistenceNotExists
+	}
+
+	return json.Unmarshal([]byte(data), val)
+}
+
+func (store *SQLStore) Save(val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	upsert := `ON DUPLICATE KEY UPDATE data = VALUES(data), updated_at = VALUES(updated_at)`
+	if store.DB.DriverName() == service.DriverSQLite3 {
+		upsert = `ON CONFLICT (id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`
+	}
+
+	_, err = store.DB.Exec(`INSERT INTO persistence (id, data, updated_at) VALUES (?, ?, ?) `+upsert,
+		store.ID, string(data), time.Now())
+	return err
+}
+
+func (store *SQLStore) Reset() error {
+	_, err := store.DB.Exec(`DELETE FROM persistence WHERE id = ?`, store.ID)
+	return err
+}
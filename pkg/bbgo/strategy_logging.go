@@ -0,0 +1,199 @@
+package bbgo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StrategyLogger is embedded by the strategies for the strategy-scoped logger, the Log field is injected by the trader
+// with the strategy, the instance, the session and the symbol fields before the strategy subscribes the market data.
+type StrategyLogger struct {
+	Log log.FieldLogger `json:"-" yaml:"-"`
+}
+
+// SetDefaultLogger sets the logger with the strategy ID and the symbol fields if the logger is not injected,
+// e.g., when the strategy runs without the trader in the tests, so that the strategy can always log with the Log field.
+func (l *StrategyLogger) SetDefaultLogger(id, symbol string) {
+	if l.Log != nil {
+		return
+	}
+
+	fields := log.Fields{"strategy": id}
+	if len(symbol) > 0 {
+		fields["symbol"] = symbol
+	}
+
+	l.Log = log.WithFields(fields)
+}
+
+// StrategyLoggingConfig routes the logs of a strategy instance
+type StrategyLoggingConfig struct {
+	// Level is the log level of the strategy, e.g., debug, info, warn or error, it defaults to the global log level
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// File is the log file of the strategy, the logs are appended to the file instead of the standard output
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// LoggingConfig is the logging section of the config file
+type LoggingConfig struct {
+	// Strategies are the logging configs keyed by the strategy instance name or the strategy ID,
+	// the instance name takes precedence when both are defined
+	Strategies map[string]*StrategyLoggingConfig `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
+
+func (c *LoggingConfig) strategyConfig(name, id string) *StrategyLoggingConfig {
+	if c == nil {
+		return nil
+	}
+
+	if config, ok := c.Strategies[name]; ok {
+		return config
+	}
+
+	return c.Strategies[id]
+}
+
+// Validate checks the log levels, so that the typos are reported before the strategies start
+func (c *LoggingConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for name, config := range c.Strategies {
+		if config == nil || len(config.Level) == 0 {
+			continue
+		}
+
+		if _, err := log.ParseLevel(config.Level); err != nil {
+			return fmt.Errorf("invalid log level of strategy %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// strategyLogFiles shares the opened log files, so that the strategies routed to the same file don't truncate each other
+type strategyLogFiles struct {
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+func (f *strategyLogFiles) open(path string) (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if file, ok := f.files[path]; ok {
+		return file, nil
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.files == nil {
+		f.files = make(map[string]*os.File)
+	}
+
+	f.files[path] = file
+	return file, nil
+}
+
+// SetLoggingConfig sets the log routing of the strategies, it should be called before Subscribe
+func (trader *Trader) SetLoggingConfig(config *LoggingConfig) {
+	trader.loggingConfig = config
+}
+
+// newStrategyLogger creates the logger of the strategy instance with the strategy ID, the instance name,
+// the session and the symbol fields. A dedicated logger is created if the strategy has a logging config,
+// otherwise the fields are attached to the standard logger.
+func (trader *Trader) newStrategyLogger(strategy interface{}, sessionName string) (log.FieldLogger, error) {
+	id := strategyID(strategy)
+	name := trader.lifecycle.nameOf(strategy)
+
+	fields := log.Fields{"strategy": id}
+	if name != id {
+		fields["instance"] = name
+	}
+
+	if len(sessionName) > 0 {
+		fields["session"] = sessionName
+	}
+
+	if rs := reflect.ValueOf(strategy); rs.Kind() == reflect.Ptr && rs.Elem().Kind() == reflect.Struct {
+		if symbol, ok := isSymbolBasedStrategy(rs.Elem()); ok && len(symbol) > 0 {
+			fields["symbol"] = symbol
+		}
+	}
+
+	standardLogger := log.StandardLogger()
+
+	config := trader.loggingConfig.strategyConfig(name, id)
+	if config == nil {
+		return standardLogger.WithFields(fields), nil
+	}
+
+	logger := log.New()
+	logger.SetFormatter(standardLogger.Formatter)
+	logger.SetLevel(standardLogger.GetLevel())
+	logger.SetOutput(standardLogger.Out)
+	logger.ReplaceHooks(standardLogger.Hooks)
+
+	if len(config.Level) > 0 {
+		level, err := log.ParseLevel(config.Level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of strategy %s: %w", name, err)
+		}
+
+		logger.SetLevel(level)
+	}
+
+	if len(config.File) > 0 {
+		file, err := trader.logFiles.open(config.File)
+		if err != nil {
+			return nil, fmt.Errorf("can not open the log file of strategy %s: %w", name, err)
+		}
+
+		logger.SetOutput(file)
+		logger.SetFormatter(&log.TextFormatter{DisableColors: true, FullTimestamp: true})
+	}
+
+	return logger.WithFields(fields), nil
+}
+
+// injectStrategyLogger injects the strategy-scoped logger into the Log field of the strategy
+func (trader *Trader) injectStrategyLogger(strategy interface{}, sessionName string) {
+	rs := reflect.ValueOf(strategy)
+	if rs.Kind() != reflect.Ptr || rs.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	rs = rs.Elem()
+
+	field, ok := hasField(rs, "Log")
+	if !ok || field.Kind() != reflect.Interface || !field.IsNil() {
+		return
+	}
+
+	logger, err := trader.newStrategyLogger(strategy, sessionName)
+	if err != nil {
+		log.WithError(err).Errorf("can not create the logger of strategy %s, using the standard logger", strategyID(strategy))
+		logger = log.WithField("strategy", strategyID(strategy))
+	}
+
+	if err := injectField(rs, "Log", logger, false); err != nil {
+		log.WithError(err).Errorf("strategy Log injection failed")
+	}
+}
@@ -0,0 +1,116 @@
+package bbgo
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type loggingTestStrategy struct {
+	StrategyLogger `json:"-"`
+
+	Symbol string `json:"symbol"`
+}
+
+func (s *loggingTestStrategy) ID() string {
+	return "logging"
+}
+
+func (s *loggingTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func TestTrader_injectStrategyLogger(t *testing.T) {
+	trader := NewTrader(NewEnvironment())
+
+	strategy := &loggingTestStrategy{Symbol: "BTCUSDT"}
+	trader.DeclareStrategy("btc-grid", strategy)
+	trader.injectStrategyLogger(strategy, "binance")
+
+	entry, ok := strategy.Log.(*logrus.Entry)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, logrus.StandardLogger(), entry.Logger)
+	assert.Equal(t, logrus.Fields{
+		"strategy": "logging",
+		"instance": "btc-grid",
+		"session":  "binance",
+		"symbol":   "BTCUSDT",
+	}, entry.Data)
+
+	// the logger set by the strategy itself is kept
+	trader.injectStrategyLogger(strategy, "max")
+	assert.Equal(t, entry, strategy.Log)
+}
+
+func TestStrategyLogger_SetDefaultLogger(t *testing.T) {
+	strategy := &loggingTestStrategy{Symbol: "BTCUSDT"}
+	strategy.SetDefaultLogger("logging", strategy.Symbol)
+
+	entry, ok := strategy.Log.(*logrus.Entry)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, logrus.Fields{"strategy": "logging", "symbol": "BTCUSDT"}, entry.Data)
+
+	// the injected logger is kept
+	injected := logrus.WithField("instance", "btc-grid")
+	strategy.Log = injected
+	strategy.SetDefaultLogger("logging", strategy.Symbol)
+	assert.Equal(t, injected, strategy.Log)
+}
+
+func TestTrader_newStrategyLogger_routing(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log", "btc-grid.log")
+
+	trader := NewTrader(NewEnvironment())
+	trader.SetLoggingConfig(&LoggingConfig{
+		Strategies: map[string]*StrategyLoggingConfig{
+			"btc-grid": {Level: "debug", File: logFile},
+			"logging":  {Level: "error"},
+		},
+	})
+
+	named := &loggingTestStrategy{Symbol: "BTCUSDT"}
+	trader.DeclareStrategy("btc-grid", named)
+
+	logger, err := trader.newStrategyLogger(named, "binance")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	logger.Debugf("grid levels are placed")
+
+	data, err := ioutil.ReadFile(logFile)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), "grid levels are placed")
+		assert.Contains(t, string(data), "instance=btc-grid")
+		assert.Contains(t, string(data), "symbol=BTCUSDT")
+	}
+
+	// the instance without the named config falls back to the config of the strategy ID
+	unnamed := &loggingTestStrategy{Symbol: "ETHUSDT"}
+	logger, err = trader.newStrategyLogger(unnamed, "binance")
+	if assert.NoError(t, err) {
+		assert.Equal(t, logrus.ErrorLevel, logger.(*logrus.Entry).Logger.GetLevel())
+	}
+}
+
+func TestLoggingConfig_Validate(t *testing.T) {
+	config := &LoggingConfig{
+		Strategies: map[string]*StrategyLoggingConfig{
+			"grid": {Level: "verbose"},
+		},
+	}
+	assert.Error(t, config.Validate())
+
+	config.Strategies["grid"].Level = "warn"
+	assert.NoError(t, config.Validate())
+}
@@ -0,0 +1,113 @@
+package bbgo
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	// OrderTagStrategy is the order tag of the ID of the strategy submitting the order
+	OrderTagStrategy = "strategy"
+
+	// OrderTagLocalID is the order tag of the serial ID of the order in the session
+	OrderTagLocalID = "local_id"
+)
+
+// NextLocalOrderID returns the next serial ID of the orders submitted from this session, the serial starts from
+// the last local ID stored in the database, so it keeps increasing after restarting if the database is configured.
+func (session *ExchangeSession) NextLocalOrderID() uint64 {
+	return atomic.AddUint64(&session.lastLocalOrderID, 1)
+}
+
+// tagStrategyOrders returns the copies of the orders tagged with the strategy ID and the local IDs of the session
+func tagStrategyOrders(session *ExchangeSession, strategyID string, orders []types.SubmitOrder) []types.SubmitOrder {
+	taggedOrders := make([]types.SubmitOrder, len(orders))
+	for i, order := range orders {
+		order.Tags = order.Tags.Merge(types.OrderTags{
+			OrderTagStrategy: strategyID,
+			OrderTagLocalID:  strconv.FormatUint(session.NextLocalOrderID(), 10),
+		})
+		taggedOrders[i] = order
+	}
+
+	return taggedOrders
+}
+
+// recordStrategyOrders stores the created orders with the strategy tags into the strategy_orders table
+func recordStrategyOrders(strategyOrderService *service.StrategyOrderService, session *ExchangeSession, createdOrders types.OrderSlice) {
+	if strategyOrderService == nil || session.PaperTrade {
+		return
+	}
+
+	for _, order := range createdOrders {
+		localID, err := strconv.ParseUint(order.Tags[OrderTagLocalID], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		createdAt := order.CreationTime
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		if err := strategyOrderService.Insert(service.StrategyOrder{
+			Session:       session.Name,
+			Exchange:      session.Exchange.Name(),
+			LocalID:       localID,
+			Strategy:      order.Tags[OrderTagStrategy],
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			Side:          order.Side,
+			Type:          order.Type,
+			Price:         order.Price,
+			Quantity:      order.Quantity,
+			CreatedAt:     createdAt,
+		}); err != nil {
+			log.WithError(err).Errorf("strategy order insert error: %+v", order)
+		}
+	}
+}
+
+// StrategyOrderExecutor tags the orders of the strategy with the strategy ID and the local serial ID of the session,
+// the created orders are recorded into the database for auditing which strategy created which order.
+type StrategyOrderExecutor struct {
+	OrderExecutor
+
+	StrategyID string
+	Session    *ExchangeSession
+	Service    *service.StrategyOrderService
+}
+
+func (e *StrategyOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	createdOrders, err := e.OrderExecutor.SubmitOrders(ctx, tagStrategyOrders(e.Session, e.StrategyID, orders)...)
+	recordStrategyOrders(e.Service, e.Session, createdOrders)
+	return createdOrders, err
+}
+
+// StrategyOrderExecutionRouter tags and records the orders of the cross exchange strategy like StrategyOrderExecutor
+type StrategyOrderExecutionRouter struct {
+	OrderExecutionRouter
+
+	StrategyID string
+	Sessions   map[string]*ExchangeSession
+	Service    *service.StrategyOrderService
+}
+
+func (r *StrategyOrderExecutionRouter) SubmitOrdersTo(ctx context.Context, sessionName string, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	session, ok := r.Sessions[sessionName]
+	if !ok {
+		return r.OrderExecutionRouter.SubmitOrdersTo(ctx, sessionName, orders...)
+	}
+
+	createdOrders, err := r.OrderExecutionRouter.SubmitOrdersTo(ctx, sessionName, tagStrategyOrders(session, r.StrategyID, orders)...)
+	recordStrategyOrders(r.Service, session, createdOrders)
+	return createdOrders, err
+}
@@ -0,0 +1,46 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStrategyOrderExecutor(t *testing.T) {
+	markets := types.MarketMap{
+		"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001},
+	}
+
+	exchange := mock.New(types.ExchangeBinance, markets, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+
+	session := &ExchangeSession{Name: "binance", Exchange: exchange, markets: markets, lastLocalOrderID: 41}
+	executor := &StrategyOrderExecutor{
+		OrderExecutor: &directOrderExecutor{exchange: exchange},
+		StrategyID:    "grid",
+		Session:       session,
+	}
+
+	orders := []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 9000.0, Tags: types.OrderTags{"grid_level": "1"}},
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Quantity: 0.01, Price: 8900.0},
+	}
+
+	createdOrders, err := executor.SubmitOrders(context.Background(), orders...)
+	if !assert.NoError(t, err) || !assert.Len(t, createdOrders, 2) {
+		return
+	}
+
+	assert.Equal(t, types.OrderTags{"grid_level": "1", OrderTagStrategy: "grid", OrderTagLocalID: "42"}, createdOrders[0].Tags)
+	assert.Equal(t, types.OrderTags{OrderTagStrategy: "grid", OrderTagLocalID: "43"}, createdOrders[1].Tags)
+
+	// the given orders are not modified
+	assert.Equal(t, types.OrderTags{"grid_level": "1"}, orders[0].Tags)
+	assert.Equal(t, uint64(44), session.NextLocalOrderID())
+}
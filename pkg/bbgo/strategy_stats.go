@@ -0,0 +1,119 @@
+package bbgo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MaxStrategyStatsBuckets is the max number of the buckets of the strategy stats, the interval is enlarged to fit the time range
+const MaxStrategyStatsBuckets = 10000
+
+// StrategyStatsBucket is the execution stats of a strategy in a time bucket
+type StrategyStatsBucket struct {
+	// Time is the start time of the bucket
+	Time time.Time `json:"time"`
+
+	// PnL is the cumulative net profit at the end of the bucket, the open inventory is valued with the last trade price,
+	// the profits of different quote currencies are summed up.
+	PnL float64 `json:"pnl"`
+
+	// NumFills is the number of the trades executed in the bucket
+	NumFills int `json:"numFills"`
+
+	// Exposure is the absolute quote value of the open inventory at the end of the bucket
+	Exposure float64 `json:"exposure"`
+}
+
+// StrategyStats is the time-bucketed execution stats of a strategy running on a session
+type StrategyStats struct {
+	StrategyID string                `json:"strategyID"`
+	Session    string                `json:"session"`
+	Interval   time.Duration         `json:"interval"`
+	Buckets    []StrategyStatsBucket `json:"buckets"`
+}
+
+// Stats aggregates the trades of the recorded orders into the time buckets of the interval between from and to
+func (r *ExecutionRecorder) Stats(from, to time.Time, interval time.Duration) (StrategyStats, error) {
+	if interval <= 0 {
+		return StrategyStats{}, fmt.Errorf("invalid stats interval %s", interval)
+	}
+
+	if n := to.Sub(from) / interval; n > MaxStrategyStatsBuckets {
+		interval = to.Sub(from) / MaxStrategyStatsBuckets
+	}
+
+	r.mu.Lock()
+	var trades = make([]types.Trade, 0, len(r.trades))
+	for _, trade := range r.trades {
+		trades = append(trades, trade)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	stats := StrategyStats{
+		StrategyID: r.StrategyID,
+		Session:    r.Session.Name,
+		Interval:   interval,
+	}
+
+	var profits = make(map[string]float64)
+	var inventories = make(map[string]float64)
+	var lastPrices = make(map[string]float64)
+	var next = 0
+
+	for start := from.Truncate(interval); start.Before(to); start = start.Add(interval) {
+		end := start.Add(interval)
+		bucket := StrategyStatsBucket{Time: start}
+
+		for ; next < len(trades) && trades[next].Time.Before(end); next++ {
+			trade := trades[next]
+			market, ok := r.Session.Market(trade.Symbol)
+			if !ok {
+				continue
+			}
+
+			addTradeCashFlow(trade, market, profits, inventories)
+			lastPrices[trade.Symbol] = trade.Price
+
+			if !trade.Time.Before(start) {
+				bucket.NumFills++
+			}
+		}
+
+		for _, profit := range profits {
+			bucket.PnL += profit
+		}
+
+		for symbol, inventory := range inventories {
+			value := inventory * lastPrices[symbol]
+			bucket.PnL += value
+			bucket.Exposure += math.Abs(value)
+		}
+
+		stats.Buckets = append(stats.Buckets, bucket)
+	}
+
+	return stats, nil
+}
+
+// StrategyStats returns the time-bucketed execution stats of the running strategies,
+// the stats are only available when the execution report is enabled.
+func (trader *Trader) StrategyStats(from, to time.Time, interval time.Duration) (stats []StrategyStats, err error) {
+	for _, recorder := range trader.executionRecorders {
+		s, err := recorder.Stats(from, to, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
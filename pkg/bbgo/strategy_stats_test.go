@@ -0,0 +1,62 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExecutionRecorder_Stats(t *testing.T) {
+	session := &ExchangeSession{
+		Name: "binance",
+		markets: map[string]types.Market{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+	}
+
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	recorder := NewExecutionRecorder(nil, "grid", session)
+	recorder.recordOrders(
+		types.Order{OrderID: 1, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 10000.0, Quantity: 1.0}},
+		types.Order{OrderID: 2, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 11000.0, Quantity: 0.5}},
+	)
+
+	// the trade before the time range is counted into the pnl but not the fills
+	recorder.handleTrade(types.Trade{ID: 1, OrderID: 1, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.5, Time: startTime.Add(-time.Minute)})
+	recorder.handleTrade(types.Trade{ID: 2, OrderID: 1, Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: 10000.0, Quantity: 0.5, Fee: 5.0, FeeCurrency: "USDT", Time: startTime.Add(10 * time.Minute)})
+	recorder.handleTrade(types.Trade{ID: 3, OrderID: 2, Symbol: "BTCUSDT", Side: types.SideTypeSell, Price: 11000.0, Quantity: 0.5, Time: startTime.Add(2*time.Hour + time.Minute)})
+
+	stats, err := recorder.Stats(startTime, startTime.Add(3*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "grid", stats.StrategyID)
+	assert.Equal(t, "binance", stats.Session)
+
+	if assert.Len(t, stats.Buckets, 3) {
+		assert.Equal(t, startTime, stats.Buckets[0].Time)
+		assert.Equal(t, 1, stats.Buckets[0].NumFills)
+		assert.InDelta(t, -5.0, stats.Buckets[0].PnL, 1e-9)
+		assert.InDelta(t, 10000.0, stats.Buckets[0].Exposure, 1e-9)
+
+		// no trade in the second hour
+		assert.Equal(t, 0, stats.Buckets[1].NumFills)
+		assert.InDelta(t, -5.0, stats.Buckets[1].PnL, 1e-9)
+
+		// -10000 - 5 + 5500 + 0.5 * 11000
+		assert.Equal(t, 1, stats.Buckets[2].NumFills)
+		assert.InDelta(t, 995.0, stats.Buckets[2].PnL, 1e-9)
+		assert.InDelta(t, 5500.0, stats.Buckets[2].Exposure, 1e-9)
+	}
+
+	// the interval is enlarged to fit the max number of the buckets
+	stats, err = recorder.Stats(startTime, startTime.Add(MaxStrategyStatsBuckets*2*time.Second), time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, stats.Interval)
+	assert.Len(t, stats.Buckets, MaxStrategyStatsBuckets)
+
+	_, err = recorder.Stats(startTime, startTime.Add(time.Hour), 0)
+	assert.Error(t, err)
+}
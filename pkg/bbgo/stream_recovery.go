@@ -0,0 +1,308 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// streamRecoveryTimeout is the timeout of the REST queries for reconciling the missed updates
+const streamRecoveryTimeout = 30 * time.Second
+
+// streamRecoveryTimeMargin extends the query time range backward to cover the clock skew between the exchange and us
+const streamRecoveryTimeMargin = 30 * time.Second
+
+// StreamEventEmitter is implemented by the streams that embed types.StandardStream
+type StreamEventEmitter interface {
+	EmitOrderUpdate(order types.Order)
+	EmitTradeUpdate(trade types.Trade)
+}
+
+type tradeKey struct {
+	Symbol string
+	ID     int64
+}
+
+// StreamRecovery reconciles the order updates and the trade updates missed while the user data stream is disconnected.
+//
+// It keeps the last seen order states and trades of the session stream, when the stream is re-connected,
+// it queries the open orders, the closed orders and the trades via the REST API since the last seen update,
+// and emits the missed order updates and trade updates through the stream as if they were received from the websocket.
+type StreamRecovery struct {
+	Session *ExchangeSession
+
+	emitter StreamEventEmitter
+
+	// recoverMu serializes the recoveries of the consecutive reconnects, and wg tracks the running recovery
+	recoverMu sync.Mutex
+	wg        sync.WaitGroup
+
+	mu             sync.Mutex
+	connected      bool
+	lastUpdateTime time.Time
+	orders         map[uint64]types.Order
+	trades         map[tradeKey]time.Time
+}
+
+func NewStreamRecovery(session *ExchangeSession, emitter StreamEventEmitter) *StreamRecovery {
+	return &StreamRecovery{
+		Session: session,
+		emitter: emitter,
+		orders:  make(map[uint64]types.Order),
+		trades:  make(map[tradeKey]time.Time),
+	}
+}
+
+func (r *StreamRecovery) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnOrderUpdate(r.handleOrderUpdate)
+	stream.OnTradeUpdate(r.handleTradeUpdate)
+	stream.OnConnect(r.handleConnect)
+}
+
+func (r *StreamRecovery) handleOrderUpdate(order types.Order) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.orders[order.OrderID] = order
+	r.touch(order.UpdateTime)
+}
+
+func (r *StreamRecovery) handleTradeUpdate(trade types.Trade) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trades[tradeKey{Symbol: trade.Symbol, ID: trade.ID}] = trade.Time
+	r.touch(trade.Time)
+}
+
+// touch updates the last update time, the local time is used if the event time is not given
+func (r *StreamRecovery) touch(t time.Time) {
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	if t.After(r.lastUpdateTime) {
+		r.lastUpdateTime = t
+	}
+}
+
+func (r *StreamRecovery) handleConnect() {
+	r.mu.Lock()
+	firstConnect := !r.connected
+	r.connected = true
+	if firstConnect {
+		r.lastUpdateTime = time.Now()
+	}
+	r.mu.Unlock()
+
+	// nothing was missed on the first connect
+	if firstConnect {
+		return
+	}
+
+	// the callback is called from the stream reader, the REST queries are done in another goroutine
+	// so that the websocket messages (and the pings) are still read while recovering.
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		r.recoverMu.Lock()
+		defer r.recoverMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), streamRecoveryTimeout)
+		defer cancel()
+
+		if err := r.Recover(ctx); err != nil {
+			r.Session.logger.WithError(err).Errorf("stream recovery error")
+		}
+	}()
+}
+
+// wait waits for the running recovery
+func (r *StreamRecovery) wait() {
+	r.wg.Wait()
+}
+
+// symbols returns the symbols used by the session and the symbols of the tracked orders
+func (r *StreamRecovery) symbols() []string {
+	var set = make(map[string]struct{})
+	for symbol := range r.Session.usedSymbols {
+		set[symbol] = struct{}{}
+	}
+
+	r.mu.Lock()
+	for _, o := range r.orders {
+		set[o.Symbol] = struct{}{}
+	}
+	r.mu.Unlock()
+
+	var symbols []string
+	for symbol := range set {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Recover queries the order states and the trades since the last seen update and emits the missed updates
+func (r *StreamRecovery) Recover(ctx context.Context) error {
+	r.mu.Lock()
+	since := r.lastUpdateTime.Add(-streamRecoveryTimeMargin)
+	r.mu.Unlock()
+
+	until := time.Now()
+
+	var numTrades, numOrders int
+	var errs []error
+	for _, symbol := range r.symbols() {
+		trades, orders, err := r.recoverSymbol(ctx, symbol, since, until)
+		numTrades += trades
+		numOrders += orders
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+		}
+	}
+
+	r.prune(since)
+
+	if numTrades > 0 || numOrders > 0 {
+		r.Session.logger.Warnf("stream recovered: %d missed trades and %d missed order updates since %s", numTrades, numOrders, since)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("stream recovery errors: %v", errs)
+	}
+
+	return nil
+}
+
+func (r *StreamRecovery) recoverSymbol(ctx context.Context, symbol string, since, until time.Time) (numTrades, numOrders int, err error) {
+	ex := r.Session.Exchange
+
+	trades, err := ex.QueryTrades(ctx, symbol, &types.TradeQueryOptions{StartTime: &since})
+	if err != nil {
+		return numTrades, numOrders, err
+	}
+
+	// the trades are emitted before the order updates, so that the positions are updated when the orders are filled
+	for _, trade := range trades {
+		if r.isTradeSeen(trade) {
+			continue
+		}
+
+		r.emitter.EmitTradeUpdate(trade)
+		numTrades++
+	}
+
+	openOrders, err := ex.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return numTrades, numOrders, err
+	}
+
+	// the closed orders are usually queried by the creation time, so the query range is extended
+	// to cover the tracked active orders that are no longer open on the exchange.
+	closedSince := since
+	for _, o := range r.missingActiveOrders(symbol, openOrders) {
+		if !o.CreationTime.IsZero() && o.CreationTime.Before(closedSince) {
+			closedSince = o.CreationTime.Add(-streamRecoveryTimeMargin)
+		}
+	}
+
+	closedOrders, err := ex.QueryClosedOrders(ctx, symbol, closedSince, until, 0)
+	if err != nil {
+		return numTrades, numOrders, err
+	}
+
+	for _, order := range append(closedOrders, openOrders...) {
+		if !r.isOrderChanged(order, since) {
+			continue
+		}
+
+		// the emitted order update is recorded by the order update handler, so it won't be emitted twice
+		r.emitter.EmitOrderUpdate(order)
+		numOrders++
+	}
+
+	return numTrades, numOrders, nil
+}
+
+// missingActiveOrders returns the tracked active orders of the symbol that are not in the open orders from the exchange
+func (r *StreamRecovery) missingActiveOrders(symbol string, openOrders []types.Order) (orders []types.Order) {
+	var openOrderIDs = make(map[uint64]struct{}, len(openOrders))
+	for _, o := range openOrders {
+		openOrderIDs[o.OrderID] = struct{}{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, o := range r.orders {
+		if o.Symbol != symbol {
+			continue
+		}
+
+		if o.Status != types.OrderStatusNew && o.Status != types.OrderStatusPartiallyFilled {
+			continue
+		}
+
+		if _, ok := openOrderIDs[id]; !ok {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders
+}
+
+func (r *StreamRecovery) isTradeSeen(trade types.Trade) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.trades[tradeKey{Symbol: trade.Symbol, ID: trade.ID}]
+	return ok
+}
+
+// isOrderChanged checks the queried order against the last seen order state
+func (r *StreamRecovery) isOrderChanged(order types.Order, since time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last, ok := r.orders[order.OrderID]
+	if !ok {
+		// the unknown orders are only emitted if they were updated while the stream was disconnected,
+		// so that the orders placed before the session started are not emitted.
+		// The creation time is used if the exchange doesn't return the update time, the order without both is skipped.
+		updateTime := order.UpdateTime
+		if updateTime.IsZero() {
+			updateTime = order.CreationTime
+		}
+
+		return !updateTime.IsZero() && !updateTime.Before(since)
+	}
+
+	return last.Status != order.Status || last.ExecutedQuantity != order.ExecutedQuantity
+}
+
+// prune removes the closed orders and the trades that are older than the recovered time range
+func (r *StreamRecovery) prune(before time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, o := range r.orders {
+		switch o.Status {
+		case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+			if o.UpdateTime.Before(before) {
+				delete(r.orders, id)
+			}
+		}
+	}
+
+	for key, t := range r.trades {
+		if t.Before(before) {
+			delete(r.trades, key)
+		}
+	}
+}
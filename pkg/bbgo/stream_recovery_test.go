@@ -0,0 +1,103 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type recoveryTestStream struct {
+	types.StandardStream
+}
+
+func (s *recoveryTestStream) SetPublicOnly()                    {}
+func (s *recoveryTestStream) Connect(ctx context.Context) error { return nil }
+func (s *recoveryTestStream) Close() error                      { return nil }
+
+type recoveryTestExchange struct {
+	types.Exchange
+
+	stream       *recoveryTestStream
+	trades       []types.Trade
+	openOrders   []types.Order
+	closedOrders []types.Order
+}
+
+func (e *recoveryTestExchange) NewStream() types.Stream {
+	return e.stream
+}
+
+func (e *recoveryTestExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	return e.trades, nil
+}
+
+func (e *recoveryTestExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	return e.openOrders, nil
+}
+
+func (e *recoveryTestExchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) ([]types.Order, error) {
+	return e.closedOrders, nil
+}
+
+func TestStreamRecovery_Reconnect(t *testing.T) {
+	stream := &recoveryTestStream{}
+	exchange := &recoveryTestExchange{stream: stream}
+	session := NewExchangeSession("binance", exchange)
+	session.usedSymbols["BTCUSDT"] = struct{}{}
+
+	recovery := NewStreamRecovery(session, stream)
+	recovery.BindStream(stream)
+
+	var orderUpdates []types.Order
+	var tradeUpdates []types.Trade
+	stream.OnOrderUpdate(func(order types.Order) { orderUpdates = append(orderUpdates, order) })
+	stream.OnTradeUpdate(func(trade types.Trade) { tradeUpdates = append(tradeUpdates, trade) })
+
+	stream.EmitConnect()
+
+	now := time.Now()
+	order1 := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 1, Status: types.OrderStatusNew, CreationTime: now.Add(-time.Hour), UpdateTime: now}
+	order2 := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 2, Status: types.OrderStatusNew, CreationTime: now, UpdateTime: now}
+	trade1 := types.Trade{ID: 1, OrderID: 1, Symbol: "BTCUSDT", Time: now}
+	stream.EmitOrderUpdate(order1)
+	stream.EmitOrderUpdate(order2)
+	stream.EmitTradeUpdate(trade1)
+
+	// order 1 is filled and a new order 3 is placed while the stream is disconnected
+	filledOrder1 := order1
+	filledOrder1.Status = types.OrderStatusFilled
+	filledOrder1.UpdateTime = now.Add(time.Second)
+
+	order3 := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 3, Status: types.OrderStatusNew, CreationTime: now.Add(time.Second), UpdateTime: now.Add(time.Second)}
+
+	// the order placed before the session started should not be emitted
+	oldOrder := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 4, Status: types.OrderStatusNew, CreationTime: now.Add(-24 * time.Hour), UpdateTime: now.Add(-24 * time.Hour)}
+
+	// the unknown order without the update time and the creation time can not be checked, it's skipped
+	untimedOrder := types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 5, Status: types.OrderStatusNew}
+
+	trade2 := types.Trade{ID: 2, OrderID: 1, Symbol: "BTCUSDT", Time: now.Add(time.Second)}
+	exchange.trades = []types.Trade{trade1, trade2}
+	exchange.openOrders = []types.Order{order2, order3, oldOrder, untimedOrder}
+	exchange.closedOrders = []types.Order{filledOrder1}
+
+	orderUpdates = nil
+	tradeUpdates = nil
+	stream.EmitConnect()
+	recovery.wait()
+
+	assert.Equal(t, []types.Trade{trade2}, tradeUpdates)
+	assert.Equal(t, []types.Order{filledOrder1, order3}, orderUpdates)
+
+	// nothing is emitted again on the next reconnect
+	orderUpdates = nil
+	tradeUpdates = nil
+	stream.EmitConnect()
+	recovery.wait()
+	assert.Empty(t, tradeUpdates)
+	assert.Empty(t, orderUpdates)
+}
@@ -0,0 +1,244 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StreamHealth is the connection state of the session stream
+type StreamHealth string
+
+const (
+	StreamHealthConnected    = StreamHealth("connected")
+	StreamHealthDegraded     = StreamHealth("degraded")
+	StreamHealthDisconnected = StreamHealth("disconnected")
+)
+
+const (
+	defaultSupervisorMaxMessageAge = time.Minute
+	defaultSupervisorCheckInterval = 5 * time.Second
+)
+
+// StreamSupervisorConfig is the session config of the stream supervisor
+type StreamSupervisorConfig struct {
+	// MaxMessageAge marks the stream degraded when no message is received within the age, defaults to 1 minute
+	MaxMessageAge types.Duration `json:"maxMessageAge,omitempty" yaml:"maxMessageAge,omitempty"`
+
+	// ReconnectAfter drops the connection when the stream stays degraded for the duration,
+	// the stream then reconnects itself with the exponential backoff. Zero disables the forced reconnect.
+	ReconnectAfter types.Duration `json:"reconnectAfter,omitempty" yaml:"reconnectAfter,omitempty"`
+
+	// CheckInterval is the interval of checking the last message age, defaults to 5 seconds
+	CheckInterval types.Duration `json:"checkInterval,omitempty" yaml:"checkInterval,omitempty"`
+}
+
+func (c StreamSupervisorConfig) maxMessageAge() time.Duration {
+	if c.MaxMessageAge > 0 {
+		return c.MaxMessageAge.Duration()
+	}
+	return defaultSupervisorMaxMessageAge
+}
+
+func (c StreamSupervisorConfig) checkInterval() time.Duration {
+	if c.CheckInterval > 0 {
+		return c.CheckInterval.Duration()
+	}
+	return defaultSupervisorCheckInterval
+}
+
+// StreamSupervisor watches the connection health of the session stream.
+//
+// The connect and disconnect events are forwarded from the stream, the stream is marked degraded when it's connected
+// but no message (including the heartbeats of the streams tracking the raw messages) is received within MaxMessageAge,
+// and it's dropped for reconnecting if it stays degraded longer than ReconnectAfter.
+// The strategies can pause the order placement between OnDisconnect/OnDegraded and OnConnect/OnRecover.
+//
+//go:generate callbackgen -type StreamSupervisor
+type StreamSupervisor struct {
+	Config StreamSupervisorConfig
+
+	stream types.Stream
+	logger log.FieldLogger
+
+	mu            sync.Mutex
+	health        StreamHealth
+	lastEventTime time.Time
+	degradedSince time.Time
+
+	connectCallbacks    []func()
+	disconnectCallbacks []func()
+	degradedCallbacks   []func(age time.Duration)
+	recoverCallbacks    []func()
+}
+
+func NewStreamSupervisor(stream types.Stream, config StreamSupervisorConfig) *StreamSupervisor {
+	s := &StreamSupervisor{
+		Config: config,
+		stream: stream,
+		logger: log.StandardLogger(),
+		health: StreamHealthDisconnected,
+	}
+
+	stream.OnConnect(s.handleConnect)
+	stream.OnDisconnect(s.handleDisconnect)
+
+	// the dispatched events are the liveness of the streams which don't track the raw messages
+	stream.OnKLine(func(kline types.KLine) { s.touch() })
+	stream.OnBookSnapshot(func(book types.OrderBook) { s.touch() })
+	stream.OnBookUpdate(func(book types.OrderBook) { s.touch() })
+	stream.OnMarketTrade(func(trade types.Trade) { s.touch() })
+	stream.OnTradeUpdate(func(trade types.Trade) { s.touch() })
+	stream.OnOrderUpdate(func(order types.Order) { s.touch() })
+	stream.OnBalanceSnapshot(func(balances types.BalanceMap) { s.touch() })
+	stream.OnBalanceUpdate(func(balances types.BalanceMap) { s.touch() })
+	return s
+}
+
+// Health returns the current connection state of the stream
+func (s *StreamSupervisor) Health() StreamHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// IsHealthy returns true if the stream is connected and not degraded
+func (s *StreamSupervisor) IsHealthy() bool {
+	return s.Health() == StreamHealthConnected
+}
+
+// LastMessageTime returns the time of the last message, either the raw message tracked by the stream or the last event
+func (s *StreamSupervisor) LastMessageTime() time.Time {
+	s.mu.Lock()
+	lastTime := s.lastEventTime
+	s.mu.Unlock()
+
+	if provider, ok := s.stream.(types.LivenessProvider); ok {
+		if t := provider.LastMessageTime(); t.After(lastTime) {
+			lastTime = t
+		}
+	}
+
+	return lastTime
+}
+
+// Run checks the last message age periodically until the context is done
+func (s *StreamSupervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Config.checkInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			s.check(now)
+		}
+	}
+}
+
+func (s *StreamSupervisor) handleConnect() {
+	s.mu.Lock()
+	s.health = StreamHealthConnected
+	s.lastEventTime = time.Now()
+	s.degradedSince = time.Time{}
+	s.mu.Unlock()
+
+	s.EmitConnect()
+}
+
+func (s *StreamSupervisor) handleDisconnect() {
+	s.mu.Lock()
+	if s.health == StreamHealthDisconnected {
+		s.mu.Unlock()
+		return
+	}
+
+	s.health = StreamHealthDisconnected
+	s.degradedSince = time.Time{}
+	s.mu.Unlock()
+
+	s.logger.Warnf("stream is disconnected, waiting for reconnect")
+	s.EmitDisconnect()
+}
+
+func (s *StreamSupervisor) touch() {
+	s.mu.Lock()
+	s.lastEventTime = time.Now()
+	recovered := s.markRecovered()
+	s.mu.Unlock()
+
+	if recovered {
+		s.EmitRecover()
+	}
+}
+
+// markRecovered moves the degraded stream back to the connected state, it must be called with the lock held
+func (s *StreamSupervisor) markRecovered() bool {
+	if s.health != StreamHealthDegraded {
+		return false
+	}
+
+	s.health = StreamHealthConnected
+	s.degradedSince = time.Time{}
+	return true
+}
+
+func (s *StreamSupervisor) check(now time.Time) {
+	age := now.Sub(s.LastMessageTime())
+
+	s.mu.Lock()
+	if s.health == StreamHealthDisconnected {
+		s.mu.Unlock()
+		return
+	}
+
+	if age <= s.Config.maxMessageAge() {
+		recovered := s.markRecovered()
+		s.mu.Unlock()
+
+		if recovered {
+			s.logger.Infof("stream is recovered")
+			s.EmitRecover()
+		}
+		return
+	}
+
+	degraded := false
+	if s.health == StreamHealthConnected {
+		s.health = StreamHealthDegraded
+		s.degradedSince = now
+		degraded = true
+	}
+
+	reconnect := false
+	if s.Config.ReconnectAfter > 0 && now.Sub(s.degradedSince) >= s.Config.ReconnectAfter.Duration() {
+		// wait for another period before the next forced reconnect
+		s.degradedSince = now
+		reconnect = true
+	}
+	s.mu.Unlock()
+
+	if degraded {
+		s.logger.Warnf("stream is degraded, no message is received for %s", age)
+		s.EmitDegraded(age)
+	}
+
+	if reconnect {
+		reconnector, ok := s.stream.(types.Reconnector)
+		if !ok {
+			s.logger.Warnf("stream %T does not support the reconnect", s.stream)
+			return
+		}
+
+		s.logger.Warnf("stream is degraded for %s, reconnecting...", s.Config.ReconnectAfter.Duration())
+		if err := reconnector.Reconnect(); err != nil {
+			s.logger.WithError(err).Errorf("stream reconnect error")
+		}
+	}
+}
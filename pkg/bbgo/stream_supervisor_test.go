@@ -0,0 +1,80 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type supervisorTestStream struct {
+	types.StandardStream
+
+	reconnects int
+}
+
+func (s *supervisorTestStream) SetPublicOnly()                    {}
+func (s *supervisorTestStream) Connect(ctx context.Context) error { return nil }
+func (s *supervisorTestStream) Close() error                      { return nil }
+
+func (s *supervisorTestStream) Reconnect() error {
+	s.reconnects++
+	return nil
+}
+
+func TestStreamSupervisor(t *testing.T) {
+	stream := &supervisorTestStream{}
+	supervisor := NewStreamSupervisor(stream, StreamSupervisorConfig{
+		MaxMessageAge:  types.Duration(time.Minute),
+		ReconnectAfter: types.Duration(2 * time.Minute),
+	})
+
+	var events []string
+	supervisor.OnConnect(func() { events = append(events, "connect") })
+	supervisor.OnDisconnect(func() { events = append(events, "disconnect") })
+	supervisor.OnDegraded(func(age time.Duration) { events = append(events, "degraded") })
+	supervisor.OnRecover(func() { events = append(events, "recover") })
+
+	assert.Equal(t, StreamHealthDisconnected, supervisor.Health())
+
+	stream.EmitConnect()
+	assert.True(t, supervisor.IsHealthy())
+
+	now := time.Now()
+	supervisor.check(now)
+	assert.True(t, supervisor.IsHealthy())
+
+	// no message for more than the max message age
+	supervisor.check(now.Add(90 * time.Second))
+	assert.Equal(t, StreamHealthDegraded, supervisor.Health())
+
+	// the degraded event is emitted once
+	supervisor.check(now.Add(2 * time.Minute))
+	assert.Equal(t, 0, stream.reconnects)
+
+	// the connection is dropped after being degraded for the reconnect duration
+	supervisor.check(now.Add(210 * time.Second))
+	assert.Equal(t, 1, stream.reconnects)
+
+	// the raw message tracked by the stream recovers the stream
+	stream.SetLastMessageTime(now.Add(220 * time.Second))
+	supervisor.check(now.Add(230 * time.Second))
+	assert.True(t, supervisor.IsHealthy())
+
+	stream.EmitDisconnect()
+	stream.EmitDisconnect()
+	assert.Equal(t, StreamHealthDisconnected, supervisor.Health())
+
+	// the disconnected stream is not marked degraded
+	supervisor.check(now.Add(time.Hour))
+	assert.Equal(t, StreamHealthDisconnected, supervisor.Health())
+
+	stream.EmitConnect()
+	stream.EmitKLine(types.KLine{Symbol: "BTCUSDT"})
+	assert.True(t, supervisor.IsHealthy())
+
+	assert.Equal(t, []string{"connect", "degraded", "recover", "disconnect", "connect"}, events)
+}
@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StuckOrderDetector checks the locally maintained active orders against the open orders from the exchange REST API.
+//
+// It flags the orders that have been resting longer than MaxAge, and the orders that are in the ambiguous states,
+// e.g., the exchange says the order is closed but the local active order book still keeps it (the order update was lost).
+// The ambiguous orders are reconciled by querying the closed orders, so that the filled callbacks are still triggered.
+type StuckOrderDetector struct {
+	*Notifiability
+
+	Symbol   string
+	Exchange types.Exchange
+
+	// MaxAge is the maximum resting duration of an active order, 0 means no limit
+	MaxAge time.Duration
+
+	// Interval is the checking interval, defaults to 1 minute
+	Interval time.Duration
+
+	activeOrders *LocalActiveOrderBook
+
+	// notified keeps the order IDs that we have notified as stale, so that we won't notify the same order twice.
+	notified map[uint64]struct{}
+}
+
+func NewStuckOrderDetector(symbol string, exchange types.Exchange, activeOrders *LocalActiveOrderBook) *StuckOrderDetector {
+	return &StuckOrderDetector{
+		Symbol:       symbol,
+		Exchange:     exchange,
+		Interval:     time.Minute,
+		activeOrders: activeOrders,
+		notified:     make(map[uint64]struct{}),
+	}
+}
+
+func (d *StuckOrderDetector) notify(format string, args ...interface{}) {
+	log.Warnf(format, args...)
+	if d.Notifiability != nil {
+		d.Notify(format, args...)
+	}
+}
+
+func (d *StuckOrderDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := d.Check(ctx); err != nil {
+				log.WithError(err).Errorf("%s stuck order check error", d.Symbol)
+			}
+		}
+	}
+}
+
+// Check compares the local active orders with the open orders from the exchange and reconciles the differences.
+func (d *StuckOrderDetector) Check(ctx context.Context) error {
+	localOrders := d.activeOrders.Orders()
+	if len(localOrders) == 0 {
+		return nil
+	}
+
+	openOrders, err := d.Exchange.QueryOpenOrders(ctx, d.Symbol)
+	if err != nil {
+		return err
+	}
+
+	remoteOrders := make(types.OrderMap, len(openOrders))
+	for _, o := range openOrders {
+		remoteOrders[o.OrderID] = o
+	}
+
+	now := time.Now()
+	var missingOrders []types.Order
+	for _, o := range localOrders {
+		remoteOrder, ok := remoteOrders[o.OrderID]
+		if !ok {
+			missingOrders = append(missingOrders, o)
+			continue
+		}
+
+		// the order update could be missed, update the local order with the remote order
+		if remoteOrder.Status != o.Status || remoteOrder.ExecutedQuantity != o.ExecutedQuantity {
+			d.notify("%s order %d status mismatch: local %s %f, exchange %s %f", d.Symbol, o.OrderID,
+				o.Status, o.ExecutedQuantity,
+				remoteOrder.Status, remoteOrder.ExecutedQuantity)
+			d.activeOrders.Update(remoteOrder)
+		}
+
+		if d.MaxAge > 0 && !o.CreationTime.IsZero() && now.Sub(o.CreationTime) > d.MaxAge {
+			if _, notified := d.notified[o.OrderID]; !notified {
+				d.notified[o.OrderID] = struct{}{}
+				d.notify("%s order %d has been resting for %s: %s", d.Symbol, o.OrderID, now.Sub(o.CreationTime).Round(time.Second), o.String())
+			}
+		}
+	}
+
+	if len(missingOrders) == 0 {
+		return nil
+	}
+
+	return d.reconcile(ctx, missingOrders)
+}
+
+// reconcile queries the closed orders to find out the final status of the orders that are not open on the exchange.
+func (d *StuckOrderDetector) reconcile(ctx context.Context, orders []types.Order) error {
+	since := time.Now()
+	for _, o := range orders {
+		if !o.CreationTime.IsZero() && o.CreationTime.Before(since) {
+			since = o.CreationTime
+		}
+	}
+
+	closedOrders, err := d.Exchange.QueryClosedOrders(ctx, d.Symbol, since.Add(-time.Minute), time.Now(), 0)
+	if err != nil {
+		return err
+	}
+
+	closedOrderMap := make(types.OrderMap, len(closedOrders))
+	for _, o := range closedOrders {
+		closedOrderMap[o.OrderID] = o
+	}
+
+	for _, o := range orders {
+		closedOrder, ok := closedOrderMap[o.OrderID]
+		if !ok {
+			d.notify("%s order %d is not found on the exchange, removing it from the active orders: %s", d.Symbol, o.OrderID, o.String())
+			d.activeOrders.Remove(o)
+			continue
+		}
+
+		d.notify("%s order %d is %s on the exchange but still active locally, reconciling...", d.Symbol, o.OrderID, closedOrder.Status)
+
+		// feed the closed order to the handler, the filled order will trigger the filled callbacks
+		d.activeOrders.orderUpdateHandler(closedOrder)
+		delete(d.notified, o.OrderID)
+	}
+
+	return nil
+}
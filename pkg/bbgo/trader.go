@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -34,18 +35,38 @@ type CrossExchangeStrategy interface {
 	CrossRun(ctx context.Context, orderExecutionRouter OrderExecutionRouter, sessions map[string]*ExchangeSession) error
 }
 
+// DefaultShutdownTimeout is the deadline of the graceful shutdown after the signal is received
+const DefaultShutdownTimeout = 30 * time.Second
+
+// StrategyShutdown is implemented by the strategies that need to cancel the open orders or flush the state on shutdown,
+// the trader calls Shutdown once per strategy instance with the shutdown deadline, and the strategy must call wg.Done() when it's done.
+type StrategyShutdown interface {
+	Shutdown(ctx context.Context, wg *sync.WaitGroup)
+}
+
 //go:generate callbackgen -type Graceful
 type Graceful struct {
 	shutdownCallbacks []func(ctx context.Context, wg *sync.WaitGroup)
 }
 
+// Shutdown calls the shutdown callbacks and waits for them until the context is done
 func (g *Graceful) Shutdown(ctx context.Context) {
 	var wg sync.WaitGroup
 	wg.Add(len(g.shutdownCallbacks))
 
 	go g.EmitShutdown(ctx, &wg)
 
-	wg.Wait()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.WithError(ctx.Err()).Warnf("graceful shutdown is not finished before the deadline")
+	}
 }
 
 type Logging interface {
@@ -59,6 +80,12 @@ type Logger interface {
 	Infof(message string, args ...interface{})
 }
 
+// StatusReporter is implemented by the strategies that can report their running status,
+// e.g., the status is sent to the owner by the telegram /status command.
+type StatusReporter interface {
+	Status() string
+}
+
 type SilentLogger struct{}
 
 func (logger *SilentLogger) Infof(message string, args ...interface{})  {}
@@ -73,16 +100,31 @@ type Trader struct {
 	crossExchangeStrategies []CrossExchangeStrategy
 	exchangeStrategies      map[string][]SingleExchangeStrategy
 
+	// crossExchangeSessions are the names of the sessions subscribed by each cross exchange strategy
+	crossExchangeSessions map[CrossExchangeStrategy][]string
+
 	logger Logger
 
+	// loggingConfig routes the logs of the strategies, the strategy loggers are injected in Subscribe
+	loggingConfig *LoggingConfig
+	logFiles      strategyLogFiles
+
+	// executionRecorders records the executions of the single exchange strategies for the execution reports
+	executionRecorders []*ExecutionRecorder
+
+	// lifecycle starts the strategies in the dependency order and stops the dependents of the failed strategies
+	lifecycle *LifecycleManager
+
 	Graceful Graceful
 }
 
 func NewTrader(environ *Environment) *Trader {
 	return &Trader{
-		environment:        environ,
-		exchangeStrategies: make(map[string][]SingleExchangeStrategy),
-		logger:             log.StandardLogger(),
+		environment:           environ,
+		exchangeStrategies:    make(map[string][]SingleExchangeStrategy),
+		crossExchangeSessions: make(map[CrossExchangeStrategy][]string),
+		logger:                log.StandardLogger(),
+		lifecycle:             NewLifecycleManager(&environ.Notifiability),
 	}
 }
 
@@ -94,6 +136,32 @@ func (trader *Trader) DisableLogging() {
 	trader.logger = &SilentLogger{}
 }
 
+// Environment returns the environment of the trader
+func (trader *Trader) Environment() *Environment {
+	return trader.environment
+}
+
+// ExchangeStrategies returns the attached single exchange strategies keyed by the session name
+func (trader *Trader) ExchangeStrategies() map[string][]SingleExchangeStrategy {
+	return trader.exchangeStrategies
+}
+
+// CrossExchangeStrategies returns the attached cross exchange strategies
+func (trader *Trader) CrossExchangeStrategies() []CrossExchangeStrategy {
+	return trader.crossExchangeStrategies
+}
+
+// Lifecycle returns the lifecycle manager of the strategies
+func (trader *Trader) Lifecycle() *LifecycleManager {
+	return trader.lifecycle
+}
+
+// DeclareStrategy names the attached strategy and declares the names of the strategies it depends on,
+// the strategy starts after its dependencies are running, and it's stopped when any of them fails.
+func (trader *Trader) DeclareStrategy(name string, strategy interface{}, dependsOn ...string) {
+	trader.lifecycle.Declare(name, strategy, dependsOn...)
+}
+
 // AttachStrategyOn attaches the single exchange strategy on an exchange Session.
 // Single exchange strategy is the default behavior.
 func (trader *Trader) AttachStrategyOn(session string, strategies ...SingleExchangeStrategy) error {
@@ -114,6 +182,9 @@ func (trader *Trader) AttachStrategyOn(session string, strategies ...SingleExcha
 		trader.exchangeStrategies[session] = append(trader.exchangeStrategies[session], s)
 	}
 
+	// the lazy session is required once a strategy is attached
+	trader.environment.sessions[session].attached = true
+
 	return nil
 }
 
@@ -134,6 +205,9 @@ func (trader *Trader) Subscribe() {
 	for sessionName, strategies := range trader.exchangeStrategies {
 		session := trader.environment.sessions[sessionName]
 		for _, strategy := range strategies {
+			// the logger is injected before Subscribe, so that the strategy can log with its own fields from the start
+			trader.injectStrategyLogger(strategy, sessionName)
+
 			if subscriber, ok := strategy.(ExchangeSessionSubscriber); ok {
 				subscriber.Subscribe(session)
 			}
@@ -141,169 +215,444 @@ func (trader *Trader) Subscribe() {
 	}
 
 	for _, strategy := range trader.crossExchangeStrategies {
+		trader.injectStrategyLogger(strategy, "")
+
 		if subscriber, ok := strategy.(CrossExchangeSessionSubscriber); ok {
+			var subscribeCounts = make(map[string]int, len(trader.environment.sessions))
+			for name, session := range trader.environment.sessions {
+				subscribeCounts[name] = session.subscribeCount
+			}
+
 			subscriber.CrossSubscribe(trader.environment.sessions)
+
+			for name, session := range trader.environment.sessions {
+				if session.subscribeCount > subscribeCounts[name] {
+					trader.crossExchangeSessions[strategy] = append(trader.crossExchangeSessions[strategy], name)
+				}
+			}
 		}
 	}
 }
 
 func (trader *Trader) Run(ctx context.Context) error {
+	if err := trader.verifyCapabilities(); err != nil {
+		return err
+	}
+
 	if err := trader.environment.Init(ctx); err != nil {
 		return err
 	}
 
-	// load and run Session strategies
-	for sessionName, strategies := range trader.exchangeStrategies {
-		var session = trader.environment.sessions[sessionName]
+	// the pause flags are loaded before the strategies start, so that the paused strategies can not submit orders on startup
+	if store := NewPauseStore(trader.environment.PersistenceServiceFacade); store != nil {
+		if err := trader.lifecycle.SetPauseStore(store); err != nil {
+			return err
+		}
+	}
 
-		// default to base order executor
-		var orderExecutor OrderExecutor = session.orderExecutor
+	var strategies []interface{}
+	var runners = make(map[interface{}][]func() error)
 
-		// Since the risk controls are loaded from the config file
-		if riskControls := trader.riskControls; riskControls != nil {
-			if trader.riskControls.SessionBasedRiskControl != nil {
-				control, ok := trader.riskControls.SessionBasedRiskControl[sessionName]
-				if ok {
-					control.SetBaseOrderExecutor(session.orderExecutor)
+	// the strategies on the unavailable optional sessions are skipped
+	var skipped = make(map[interface{}]struct{})
 
-					// pick the order executor
-					if control.OrderExecutor != nil {
-						orderExecutor = control.OrderExecutor
-					}
-				}
+	// load Session strategies
+	for sessionName, sessionStrategies := range trader.exchangeStrategies {
+		var session = trader.environment.sessions[sessionName]
+		if !session.IsAvailable() {
+			log.Warnf("session %s is not available, skipping %d strategies", sessionName, len(sessionStrategies))
+			for _, strategy := range sessionStrategies {
+				skipped[strategy] = struct{}{}
 			}
+			continue
 		}
 
-		for _, strategy := range strategies {
-			rs := reflect.ValueOf(strategy)
-			if rs.Elem().Kind() == reflect.Struct {
-				// get the struct element
-				rs = rs.Elem()
-
-				if err := injectField(rs, "Graceful", &trader.Graceful, true); err != nil {
-					log.WithError(err).Errorf("strategy Graceful injection failed")
-					return err
-				}
+		var orderExecutor = trader.sessionOrderExecutor(sessionName, session)
 
-				if err := injectField(rs, "Logger", &trader.logger, false); err != nil {
-					log.WithError(err).Errorf("strategy Logger injection failed")
-					return err
-				}
+		for _, strategy := range sessionStrategies {
+			strategy := strategy
+			if _, ok := runners[strategy]; !ok {
+				strategies = append(strategies, strategy)
+			}
 
-				if err := injectField(rs, "Notifiability", &trader.environment.Notifiability, false); err != nil {
-					log.WithError(err).Errorf("strategy Notifiability injection failed")
-					return err
-				}
+			runners[strategy] = append(runners[strategy], func() error {
+				return trader.runSingleExchangeStrategy(ctx, strategy, session, orderExecutor)
+			})
+		}
+	}
 
-				if err := injectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
-					log.WithError(err).Errorf("strategy OrderExecutor injection failed")
-					return err
-				}
+	router := &ExchangeOrderExecutionRouter{
+		Notifiability: trader.environment.Notifiability,
+		sessions:      trader.environment.sessions,
+	}
 
-				if symbol, ok := isSymbolBasedStrategy(rs); ok {
-					log.Infof("found symbol based strategy from %s", rs.Type())
-					if _, ok := hasField(rs, "Market"); ok {
-						if market, ok := session.Market(symbol); ok {
-							// let's make the market object passed by pointer
-							if err := injectField(rs, "Market", &market, false); err != nil {
-								log.WithError(err).Errorf("strategy %T Market injection failed", strategy)
-								return err
-							}
-						}
-					}
+	for _, strategy := range trader.crossExchangeStrategies {
+		strategy := strategy
+		if name, ok := trader.unavailableCrossExchangeSession(strategy); ok {
+			log.Warnf("session %s is not available, skipping cross exchange strategy %s", name, strategy.ID())
+			skipped[strategy] = struct{}{}
+			continue
+		}
 
-					// StandardIndicatorSet
-					if _, ok := hasField(rs, "StandardIndicatorSet"); ok {
-						if indicatorSet, ok := session.StandardIndicatorSet(symbol); ok {
-							if err := injectField(rs, "StandardIndicatorSet", indicatorSet, true); err != nil {
-								log.WithError(err).Errorf("strategy %T StandardIndicatorSet injection failed", strategy)
-								return err
-							}
-						}
-					}
+		strategies = append(strategies, strategy)
+		runners[strategy] = append(runners[strategy], func() error {
+			return trader.runCrossExchangeStrategy(ctx, strategy, router)
+		})
+	}
 
-					if _, ok := hasField(rs, "MarketDataStore"); ok {
-						if store, ok := session.MarketDataStore(symbol); ok {
-							if err := injectField(rs, "MarketDataStore", store, true); err != nil {
-								log.WithError(err).Errorf("strategy %T MarketDataStore injection failed", strategy)
-								return err
-							}
-						}
-					}
-				}
+	// the strategies attached without the lifecycle config are named by their IDs
+	for _, strategy := range strategies {
+		if !trader.lifecycle.declared(strategy) {
+			trader.lifecycle.Declare("", strategy)
+		}
+	}
+
+	// run the strategies in the dependency order, a strategy starts only when all its dependencies are running
+	sortedStrategies, err := trader.lifecycle.Sort(strategies)
+	if err != nil {
+		return err
+	}
+
+	for _, strategy := range sortedStrategies {
+		if len(runners[strategy]) == 0 {
+			continue
+		}
+
+		if err := trader.lifecycle.CanStart(strategy); err != nil {
+			// the dependencies could be the skipped strategies of the unavailable sessions
+			if len(skipped) > 0 {
+				log.WithError(err).Warnf("skipping strategy %T", strategy)
+				skipped[strategy] = struct{}{}
+				continue
 			}
 
-			err := strategy.Run(ctx, orderExecutor, session)
-			if err != nil {
+			return err
+		}
+
+		for _, run := range runners[strategy] {
+			if err := run(); err != nil {
 				return err
 			}
 		}
+
+		trader.lifecycle.Started(strategy)
 	}
 
-	router := &ExchangeOrderExecutionRouter{
-		Notifiability: trader.environment.Notifiability,
-		sessions:      trader.environment.sessions,
+	// the strategy shutdown handlers run before the execution report
+	for _, strategy := range strategies {
+		if _, ok := skipped[strategy]; ok {
+			continue
+		}
+
+		if s, ok := strategy.(StrategyShutdown); ok {
+			trader.Graceful.OnShutdown(s.Shutdown)
+		}
 	}
 
-	for _, strategy := range trader.crossExchangeStrategies {
-		rs := reflect.ValueOf(strategy)
-		if rs.Elem().Kind() == reflect.Struct {
-			// get the struct element
-			rs = rs.Elem()
-
-			if field, ok := hasField(rs, "Persistence"); ok {
-				if trader.environment.PersistenceServiceFacade == nil {
-					log.Warnf("strategy has Persistence field but persistence service is not defined")
-				} else {
-					log.Infof("found Persistence field, injecting...")
-					if field.IsNil() {
-						field.Set(reflect.ValueOf(&Persistence{
-							PersistenceSelector: &PersistenceSelector{
-								StoreID: "default",
-								Type:    "memory",
-							},
-							Facade: trader.environment.PersistenceServiceFacade,
-						}))
-					} else {
-						elem := field.Elem()
-						if elem.Kind() != reflect.Struct {
-							return fmt.Errorf("the field Persistence is not a struct element")
-						}
-
-						if err := injectField(elem, "Facade", trader.environment.PersistenceServiceFacade, true); err != nil {
-							log.WithError(err).Errorf("strategy Persistence injection failed")
-							return err
-						}
-					}
+	if len(trader.executionRecorders) > 0 {
+		trader.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+			defer wg.Done()
+			trader.reportExecutions()
+		})
+	}
+
+	if trader.lifecycle.hasHealthCheckers() {
+		go trader.lifecycle.Run(ctx)
+	}
+
+	return trader.environment.Connect(ctx)
+}
+
+// unavailableCrossExchangeSession returns the name of the unavailable session subscribed by the cross exchange strategy
+func (trader *Trader) unavailableCrossExchangeSession(strategy CrossExchangeStrategy) (string, bool) {
+	for _, name := range trader.crossExchangeSessions[strategy] {
+		if session, ok := trader.environment.sessions[name]; ok && !session.IsAvailable() {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// injectHistoryServices injects the TradeService and the OrderService for querying the historical trades and orders,
+// they are only available when the database is configured.
+func (trader *Trader) injectHistoryServices(rs reflect.Value) error {
+	if trader.environment.TradeService != nil {
+		if err := injectField(rs, "TradeService", trader.environment.TradeService, true); err != nil {
+			log.WithError(err).Errorf("strategy TradeService injection failed")
+			return err
+		}
+	}
+
+	if trader.environment.OrderService != nil {
+		if err := injectField(rs, "OrderService", trader.environment.OrderService, true); err != nil {
+			log.WithError(err).Errorf("strategy OrderService injection failed")
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sessionOrderExecutor returns the base order executor of the session, or the order executor of the session based risk control
+func (trader *Trader) sessionOrderExecutor(sessionName string, session *ExchangeSession) OrderExecutor {
+	// default to base order executor
+	var orderExecutor OrderExecutor = session.orderExecutor
+
+	// Since the risk controls are loaded from the config file
+	if riskControls := trader.riskControls; riskControls != nil {
+		if trader.riskControls.SessionBasedRiskControl != nil {
+			control, ok := trader.riskControls.SessionBasedRiskControl[sessionName]
+			if ok {
+				control.SetBaseOrderExecutor(session.orderExecutor)
+
+				// pick the order executor
+				if control.OrderExecutor != nil {
+					orderExecutor = control.OrderExecutor
 				}
 			}
+		}
+	}
+
+	return orderExecutor
+}
+
+func (trader *Trader) runSingleExchangeStrategy(ctx context.Context, strategy SingleExchangeStrategy, session *ExchangeSession, orderExecutor OrderExecutor) error {
+	// tag the orders with the strategy ID and the local serial ID of the session
+	orderExecutor = &StrategyOrderExecutor{
+		OrderExecutor: orderExecutor,
+		StrategyID:    strategy.ID(),
+		Session:       session,
+		Service:       trader.environment.StrategyOrderService,
+	}
+
+	// borrow the shortfall of the orders right before they are submitted to the margin account
+	orderExecutor = wrapMarginOrderExecutor(session, orderExecutor)
+
+	// check the orders with the risk limits, the rejections are delivered to the strategy
+	orderExecutor = wrapRiskLimitOrderExecutor(strategy, session, trader.environment.sessions, trader.riskControls, orderExecutor)
+
+	// reject the orders after the strategy is stopped because of the failed dependency or suspended
+	orderExecutor = wrapLifecycleOrderExecutor(trader.lifecycle, strategy, orderExecutor)
+
+	// gate the order submission until the strategy is warmed up
+	orderExecutor = wrapWarmUpOrderExecutor(strategy, session, orderExecutor)
+
+	// reject the orders closing the position lots held shorter than the min holding period
+	orderExecutor = wrapHoldingPeriodOrderExecutor(strategy, session, orderExecutor)
+
+	// slice the large orders into the TWAP or the iceberg child orders, each child order goes through the checks above
+	orderExecutor, err := wrapSlicingOrderExecutor(strategy, session, orderExecutor)
+	if err != nil {
+		return err
+	}
+
+	// hold the large orders until they are approved by the operator
+	orderExecutor, err = wrapConfirmationOrderExecutor(strategy, trader.environment.OrderConfirmer, orderExecutor)
+	if err != nil {
+		return err
+	}
+
+	// count the active orders of the strategy
+	if trader.environment.Metrics != nil {
+		metricsOrderExecutor := NewMetricsOrderExecutor(orderExecutor, strategy.ID(), session.Name)
+		metricsOrderExecutor.BindStream(session.Stream)
+		orderExecutor = metricsOrderExecutor
+	}
+
+	var executionRecorder *ExecutionRecorder
+	if trader.environment.ExecutionReport != nil {
+		executionRecorder = NewExecutionRecorder(orderExecutor, strategy.ID(), session)
+		executionRecorder.BindStream(session.Stream)
+		trader.executionRecorders = append(trader.executionRecorders, executionRecorder)
+		orderExecutor = executionRecorder
+	}
+
+	rs := reflect.ValueOf(strategy)
+	if rs.Elem().Kind() == reflect.Struct {
+		// get the struct element
+		rs = rs.Elem()
+
+		if err := trader.injectPersistence(rs, strategy.ID()); err != nil {
+			return err
+		}
 
-			if err := injectField(rs, "Graceful", &trader.Graceful, true); err != nil {
-				log.WithError(err).Errorf("strategy Graceful injection failed")
+		if err := injectField(rs, "Graceful", &trader.Graceful, true); err != nil {
+			log.WithError(err).Errorf("strategy Graceful injection failed")
+			return err
+		}
+
+		if err := injectField(rs, "Logger", &trader.logger, false); err != nil {
+			log.WithError(err).Errorf("strategy Logger injection failed")
+			return err
+		}
+
+		if err := injectField(rs, "Notifiability", &trader.environment.Notifiability, false); err != nil {
+			log.WithError(err).Errorf("strategy Notifiability injection failed")
+			return err
+		}
+
+		if err := injectField(rs, "MessageBus", trader.environment.MessageBus, true); err != nil {
+			log.WithError(err).Errorf("strategy MessageBus injection failed")
+			return err
+		}
+
+		if err := trader.injectHistoryServices(rs); err != nil {
+			return err
+		}
+
+		if err := injectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
+			log.WithError(err).Errorf("strategy OrderExecutor injection failed")
+			return err
+		}
+
+		if executionRecorder != nil {
+			if err := injectField(rs, "ExecutionRecorder", executionRecorder, true); err != nil {
+				log.WithError(err).Errorf("strategy ExecutionRecorder injection failed")
 				return err
 			}
+		}
 
-			if err := injectField(rs, "Logger", &trader.logger, false); err != nil {
-				log.WithError(err).Errorf("strategy Logger injection failed")
-				return err
+		if symbol, ok := isSymbolBasedStrategy(rs); ok {
+			log.Infof("found symbol based strategy from %s", rs.Type())
+			if _, ok := hasField(rs, "Market"); ok {
+				if market, ok := session.Market(symbol); ok {
+					// let's make the market object passed by pointer
+					if err := injectField(rs, "Market", &market, false); err != nil {
+						log.WithError(err).Errorf("strategy %T Market injection failed", strategy)
+						return err
+					}
+				}
 			}
 
-			if err := injectField(rs, "Notifiability", &trader.environment.Notifiability, false); err != nil {
-				log.WithError(err).Errorf("strategy Notifiability injection failed")
-				return err
+			// StandardIndicatorSet
+			if _, ok := hasField(rs, "StandardIndicatorSet"); ok {
+				if indicatorSet, ok := session.StandardIndicatorSet(symbol); ok {
+					if err := injectField(rs, "StandardIndicatorSet", indicatorSet, true); err != nil {
+						log.WithError(err).Errorf("strategy %T StandardIndicatorSet injection failed", strategy)
+						return err
+					}
+				}
 			}
 
+			if _, ok := hasField(rs, "MarketDataStore"); ok {
+				if store, ok := session.MarketDataStore(symbol); ok {
+					if err := injectField(rs, "MarketDataStore", store, true); err != nil {
+						log.WithError(err).Errorf("strategy %T MarketDataStore injection failed", strategy)
+						return err
+					}
+				}
+			}
+
+			if _, ok := hasField(rs, "Position"); ok {
+				if position, ok := session.Position(symbol); ok {
+					if err := injectField(rs, "Position", position, true); err != nil {
+						log.WithError(err).Errorf("strategy %T Position injection failed", strategy)
+						return err
+					}
+				}
+			}
+
+			if _, ok := hasField(rs, "ProfitStats"); ok {
+				if profitStats, ok := session.ProfitStats(symbol); ok {
+					if err := injectField(rs, "ProfitStats", profitStats, true); err != nil {
+						log.WithError(err).Errorf("strategy %T ProfitStats injection failed", strategy)
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return strategy.Run(ctx, orderExecutor, session)
+}
+
+func (trader *Trader) runCrossExchangeStrategy(ctx context.Context, strategy CrossExchangeStrategy, router OrderExecutionRouter) error {
+	router = &StrategyOrderExecutionRouter{
+		OrderExecutionRouter: router,
+		StrategyID:           strategy.ID(),
+		Sessions:             trader.environment.sessions,
+		Service:              trader.environment.StrategyOrderService,
+	}
+	router = &LifecycleOrderExecutionRouter{OrderExecutionRouter: router, manager: trader.lifecycle, strategy: strategy}
+
+	rs := reflect.ValueOf(strategy)
+	if rs.Elem().Kind() == reflect.Struct {
+		// get the struct element
+		rs = rs.Elem()
+
+		if err := trader.injectPersistence(rs, strategy.ID()); err != nil {
+			return err
+		}
+
+		if err := injectField(rs, "Graceful", &trader.Graceful, true); err != nil {
+			log.WithError(err).Errorf("strategy Graceful injection failed")
+			return err
 		}
 
-		if err := strategy.CrossRun(ctx, router, trader.environment.sessions); err != nil {
+		if err := injectField(rs, "Logger", &trader.logger, false); err != nil {
+			log.WithError(err).Errorf("strategy Logger injection failed")
 			return err
 		}
+
+		if err := injectField(rs, "Notifiability", &trader.environment.Notifiability, false); err != nil {
+			log.WithError(err).Errorf("strategy Notifiability injection failed")
+			return err
+		}
+
+		if err := injectField(rs, "MessageBus", trader.environment.MessageBus, true); err != nil {
+			log.WithError(err).Errorf("strategy MessageBus injection failed")
+			return err
+		}
+
+		if err := trader.injectHistoryServices(rs); err != nil {
+			return err
+		}
+
 	}
 
-	return trader.environment.Connect(ctx)
+	return strategy.CrossRun(ctx, router, trader.environment.sessions)
 }
 
 // ReportPnL configure and set the PnLReporter with the given notifier
 func (trader *Trader) ReportPnL() *PnLReporterManager {
 	return NewPnLReporter(&trader.environment.Notifiability)
 }
+
+// injectPersistence injects the persistence facade into the Persistence field of the strategy,
+// the memory store is used if the strategy does not configure the persistence selector.
+// the strategy ID and the symbol are used as the key prefix of the strategy state.
+func (trader *Trader) injectPersistence(rs reflect.Value, strategyID string) error {
+	field, ok := hasField(rs, "Persistence")
+	if !ok {
+		return nil
+	}
+
+	if trader.environment.PersistenceServiceFacade == nil {
+		log.Warnf("strategy has Persistence field but persistence service is not defined")
+		return nil
+	}
+
+	log.Infof("found Persistence field, injecting...")
+	if field.IsNil() {
+		field.Set(reflect.ValueOf(&Persistence{
+			PersistenceSelector: &PersistenceSelector{
+				StoreID: "default",
+				Type:    "memory",
+			},
+		}))
+	}
+
+	persistence, ok := field.Interface().(*Persistence)
+	if !ok {
+		return fmt.Errorf("the field Persistence is not a *bbgo.Persistence")
+	}
+
+	persistence.Facade = trader.environment.PersistenceServiceFacade
+	persistence.strategyID = strategyID
+	if symbol, ok := isSymbolBasedStrategy(rs); ok {
+		persistence.symbol = symbol
+	}
+
+	return nil
+}
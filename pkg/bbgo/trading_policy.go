@@ -0,0 +1,214 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrTradingPolicy = errors.New("the orders are rejected by the trading policy of the session")
+
+// TradingHours is a daily time window in the "15:04" format, the window crossing the midnight (e.g., 22:00 - 02:00) is supported.
+type TradingHours struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+
+	// Weekdays limits the window to the given weekdays, e.g., ["Mon", "Tue"], the weekday of the window start is used
+	// for the window crossing the midnight. The window applies to every day if it's empty.
+	Weekdays []string `json:"weekdays,omitempty" yaml:"weekdays,omitempty"`
+
+	from, to time.Duration
+	weekdays map[time.Weekday]struct{}
+}
+
+func (h *TradingHours) Validate() (err error) {
+	if h.from, err = parseClock(h.From); err != nil {
+		return err
+	}
+
+	if h.to, err = parseClock(h.To); err != nil {
+		return err
+	}
+
+	if h.from == h.to {
+		return fmt.Errorf("trading hours %s - %s is empty", h.From, h.To)
+	}
+
+	h.weekdays = nil
+	for _, s := range h.Weekdays {
+		weekday, ok := parseWeekday(s)
+		if !ok {
+			return fmt.Errorf("invalid weekday %q of the trading hours", s)
+		}
+
+		if h.weekdays == nil {
+			h.weekdays = make(map[time.Weekday]struct{})
+		}
+		h.weekdays[weekday] = struct{}{}
+	}
+
+	return nil
+}
+
+func (h *TradingHours) allowsWeekday(weekday time.Weekday) bool {
+	if len(h.weekdays) == 0 {
+		return true
+	}
+
+	_, ok := h.weekdays[weekday]
+	return ok
+}
+
+// Contains checks if the time is in the window, the time should be in the time zone of the policy
+func (h *TradingHours) Contains(t time.Time) bool {
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	if h.from < h.to {
+		return clock >= h.from && clock < h.to && h.allowsWeekday(t.Weekday())
+	}
+
+	// the window crosses the midnight
+	if clock >= h.from {
+		return h.allowsWeekday(t.Weekday())
+	}
+
+	return clock < h.to && h.allowsWeekday(t.AddDate(0, 0, -1).Weekday())
+}
+
+func (h *TradingHours) String() string {
+	if len(h.Weekdays) > 0 {
+		return fmt.Sprintf("%s-%s %s", h.From, h.To, strings.Join(h.Weekdays, ","))
+	}
+
+	return h.From + "-" + h.To
+}
+
+// TradingPolicy restricts the symbols that the strategies of the session can trade and the time windows
+// that the orders can be submitted in, e.g., to avoid the low-liquidity hours. The orders breaking the policy are rejected
+// by the session order executor, so it's a safety net for the misconfigured strategies.
+type TradingPolicy struct {
+	// Symbols is the whitelist of the symbols, all the symbols are allowed if it's empty
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+
+	// TradingHours are the time windows that the orders can be submitted in, the orders can be submitted anytime if it's empty
+	TradingHours []TradingHours `json:"tradingHours,omitempty" yaml:"tradingHours,omitempty"`
+
+	// TimeZone is the time zone of the trading hours, e.g., "Asia/Taipei", defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+
+	location *time.Location
+	symbols  map[string]struct{}
+}
+
+func (p *TradingPolicy) Validate() error {
+	p.location = time.UTC
+	if len(p.TimeZone) > 0 {
+		location, err := time.LoadLocation(p.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid time zone %q of the trading policy: %w", p.TimeZone, err)
+		}
+
+		p.location = location
+	}
+
+	p.symbols = nil
+	for _, symbol := range p.Symbols {
+		if p.symbols == nil {
+			p.symbols = make(map[string]struct{})
+		}
+		p.symbols[strings.ToUpper(symbol)] = struct{}{}
+	}
+
+	for i := range p.TradingHours {
+		if err := p.TradingHours[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check returns the reason why the order breaks the policy at the given time, an empty string is returned if the order is allowed
+func (p *TradingPolicy) Check(order types.SubmitOrder, now time.Time) string {
+	if len(p.symbols) > 0 {
+		if _, ok := p.symbols[order.Symbol]; !ok {
+			return fmt.Sprintf("symbol %s is not in the whitelist", order.Symbol)
+		}
+	}
+
+	if len(p.TradingHours) == 0 {
+		return ""
+	}
+
+	location := p.location
+	if location == nil {
+		location = time.UTC
+	}
+
+	now = now.In(location)
+	for i := range p.TradingHours {
+		if p.TradingHours[i].Contains(now) {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("%s is outside of the trading hours", now.Format("Mon 15:04 MST"))
+}
+
+// filterTradingPolicyOrders removes the orders breaking the trading policy of the session and notifies the rejections,
+// ErrTradingPolicy is returned if all the orders are rejected.
+func filterTradingPolicyOrders(e *ExchangeOrderExecutor, orders []types.SubmitOrder, now time.Time) ([]types.SubmitOrder, error) {
+	policy := e.Session.TradingPolicy
+	if policy == nil || len(orders) == 0 {
+		return orders, nil
+	}
+
+	var filtered []types.SubmitOrder
+	for _, order := range orders {
+		reason := policy.Check(order, now)
+		if len(reason) == 0 {
+			filtered = append(filtered, order)
+			continue
+		}
+
+		logrus.Warnf("session %s rejected the order by the trading policy (%s): %s", e.Session.Name, reason, order.String())
+
+		if channel, ok := e.RouteObject(&order); ok {
+			e.NotifyTo(channel, ":no_entry: Rejected %s %s %s order with quantity %f by the trading policy: %s", order.Symbol, order.Type, order.Side, order.Quantity, reason)
+		} else {
+			e.Notify(":no_entry: Rejected %s %s %s order with quantity %f by the trading policy: %s", order.Symbol, order.Type, order.Side, order.Quantity, reason)
+		}
+	}
+
+	if len(filtered) == 0 {
+		return nil, ErrTradingPolicy
+	}
+
+	return filtered, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q of the trading hours, expecting the 15:04 format", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
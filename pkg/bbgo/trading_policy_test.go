@@ -0,0 +1,83 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTradingPolicy_Check(t *testing.T) {
+	policy := &TradingPolicy{
+		Symbols: []string{"btcusdt", "ETHUSDT"},
+		TradingHours: []TradingHours{
+			{From: "09:00", To: "17:00", Weekdays: []string{"Mon", "Tuesday"}},
+			{From: "22:00", To: "02:00", Weekdays: []string{"Fri"}},
+		},
+	}
+	if !assert.NoError(t, policy.Validate()) {
+		return
+	}
+
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 1.0}
+
+	// 2021-06-07 is Monday
+	assert.Empty(t, policy.Check(order, time.Date(2021, 6, 7, 10, 0, 0, 0, time.UTC)))
+	assert.NotEmpty(t, policy.Check(order, time.Date(2021, 6, 7, 17, 0, 0, 0, time.UTC)))
+	assert.NotEmpty(t, policy.Check(order, time.Date(2021, 6, 9, 10, 0, 0, 0, time.UTC)))
+
+	// the window crossing the midnight starts on Friday
+	assert.Empty(t, policy.Check(order, time.Date(2021, 6, 11, 23, 0, 0, 0, time.UTC)))
+	assert.Empty(t, policy.Check(order, time.Date(2021, 6, 12, 1, 59, 0, 0, time.UTC)))
+	assert.NotEmpty(t, policy.Check(order, time.Date(2021, 6, 12, 23, 0, 0, 0, time.UTC)))
+
+	order.Symbol = "DOGEUSDT"
+	assert.NotEmpty(t, policy.Check(order, time.Date(2021, 6, 7, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestTradingPolicy_TimeZone(t *testing.T) {
+	policy := &TradingPolicy{
+		TradingHours: []TradingHours{{From: "09:00", To: "17:00"}},
+		TimeZone:     "Asia/Taipei",
+	}
+	if !assert.NoError(t, policy.Validate()) {
+		return
+	}
+
+	order := types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0}
+	assert.Empty(t, policy.Check(order, time.Date(2021, 6, 7, 2, 0, 0, 0, time.UTC)))
+	assert.NotEmpty(t, policy.Check(order, time.Date(2021, 6, 7, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestTradingPolicy_Validate(t *testing.T) {
+	assert.Error(t, (&TradingPolicy{TradingHours: []TradingHours{{From: "9am", To: "17:00"}}}).Validate())
+	assert.Error(t, (&TradingPolicy{TradingHours: []TradingHours{{From: "09:00", To: "09:00"}}}).Validate())
+	assert.Error(t, (&TradingPolicy{TradingHours: []TradingHours{{From: "09:00", To: "17:00", Weekdays: []string{"Funday"}}}}).Validate())
+	assert.Error(t, (&TradingPolicy{TimeZone: "Mars/Olympus"}).Validate())
+}
+
+func TestFilterTradingPolicyOrders(t *testing.T) {
+	policy := &TradingPolicy{Symbols: []string{"BTCUSDT"}}
+	if !assert.NoError(t, policy.Validate()) {
+		return
+	}
+
+	executor := &ExchangeOrderExecutor{Session: &ExchangeSession{Name: "binance", TradingPolicy: policy}}
+
+	orders, err := filterTradingPolicyOrders(executor, []types.SubmitOrder{
+		{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 1.0},
+		{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 1.0},
+	}, time.Now())
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "BTCUSDT", orders[0].Symbol)
+	}
+
+	_, err = filterTradingPolicyOrders(executor, []types.SubmitOrder{
+		{Symbol: "ETHUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeMarket, Quantity: 1.0},
+	}, time.Now())
+	assert.True(t, errors.Is(err, ErrTradingPolicy))
+}
@@ -0,0 +1,153 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TransferInternal moves the asset between the wallets of the session account, e.g., moving the collateral from the spot wallet
+// to the futures wallet. The transfer is recorded in the audit log when the audit log is enabled.
+func (session *ExchangeSession) TransferInternal(ctx context.Context, from, to types.WalletType, asset string, amount fixedpoint.Value) (*types.InternalTransfer, error) {
+	if !session.Capabilities().Has(types.CapabilityTransfer) {
+		return nil, fmt.Errorf("session %s (exchange %s) does not support internal transfer", session.Name, session.ExchangeName)
+	}
+
+	transferExchange, ok := session.Exchange.(types.TransferExchange)
+	if !ok {
+		return nil, fmt.Errorf("session %s (exchange %s) does not support internal transfer", session.Name, session.ExchangeName)
+	}
+
+	if from == to {
+		return nil, fmt.Errorf("can not transfer %s from the %s wallet to itself", asset, from)
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid transfer amount %f %s", amount.Float64(), asset)
+	}
+
+	transfer, err := transferExchange.TransferInternal(ctx, from, to, asset, amount)
+	session.audit(service.AuditActionTransferInternal, map[string]interface{}{
+		"from":   from,
+		"to":     to,
+		"asset":  asset,
+		"amount": amount.Float64(),
+	}, transfer, err)
+	if err != nil {
+		return nil, err
+	}
+
+	session.logger.Infof("transferred %f %s from the %s wallet to the %s wallet", amount.Float64(), asset, from, to)
+	return transfer, nil
+}
+
+// masterSession finds the session of the master account of the exchange, which holds the api key for the sub-account transfers
+func (environ *Environment) masterSession(exchangeName types.ExchangeName) (*ExchangeSession, error) {
+	for _, session := range environ.sessions {
+		if session.Exchange.Name() != exchangeName || session.PublicOnly || session.PaperTrade || len(session.SubAccount) > 0 {
+			continue
+		}
+
+		if !session.Capabilities().Has(types.CapabilitySubAccountTransfer) {
+			continue
+		}
+
+		return session, nil
+	}
+
+	return nil, fmt.Errorf("no master account session of exchange %s supports the sub-account transfer", exchangeName)
+}
+
+// TransferBetweenSessions moves the asset between the accounts of two sessions of the same exchange, e.g., from the master
+// account session to a sub-account session. The transfer is made with the master account session of the exchange.
+func (environ *Environment) TransferBetweenSessions(ctx context.Context, fromSession, toSession string, asset string, amount fixedpoint.Value) (*types.SubAccountTransfer, error) {
+	from, ok := environ.Session(fromSession)
+	if !ok {
+		return nil, fmt.Errorf("session %s is not defined", fromSession)
+	}
+
+	to, ok := environ.Session(toSession)
+	if !ok {
+		return nil, fmt.Errorf("session %s is not defined", toSession)
+	}
+
+	if from.Exchange.Name() != to.Exchange.Name() {
+		return nil, fmt.Errorf("can not transfer %s between session %s (exchange %s) and session %s (exchange %s)", asset, from.Name, from.ExchangeName, to.Name, to.ExchangeName)
+	}
+
+	if from.SubAccount == to.SubAccount {
+		return nil, fmt.Errorf("session %s and session %s are on the same account", from.Name, to.Name)
+	}
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid transfer amount %f %s", amount.Float64(), asset)
+	}
+
+	master, err := environ.masterSession(from.Exchange.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	transferExchange, ok := master.Exchange.(types.SubAccountTransferExchange)
+	if !ok {
+		return nil, fmt.Errorf("session %s (exchange %s) does not support the sub-account transfer", master.Name, master.ExchangeName)
+	}
+
+	transfer, err := transferExchange.TransferSubAccount(ctx, from.SubAccount, to.SubAccount, asset, amount)
+	master.audit(service.AuditActionTransferSubAccount, map[string]interface{}{
+		"fromAccount": from.SubAccount,
+		"toAccount":   to.SubAccount,
+		"asset":       asset,
+		"amount":      amount.Float64(),
+	}, transfer, err)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("transferred %f %s from session %s to session %s via session %s", amount.Float64(), asset, from.Name, to.Name, master.Name)
+	return transfer, nil
+}
+
+// warnDuplicatedSessionCredentials warns about the sessions of the same exchange using the same api key in the same
+// trading mode, their balances, orders and trades are the same account, so the strategies on them may step on each other.
+func warnDuplicatedSessionCredentials(sessions map[string]*ExchangeSession) {
+	seen := make(map[string]string)
+
+	var names []string
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		session := sessions[name]
+		if session.PublicOnly || session.PaperTrade {
+			continue
+		}
+
+		credential := session.Key
+		if len(credential) == 0 {
+			prefix := session.EnvVarPrefix
+			if len(prefix) == 0 {
+				prefix = session.ExchangeName
+			}
+
+			credential = "env:" + strings.ToUpper(prefix)
+		}
+
+		key := fmt.Sprintf("%s/%s/margin=%v/%s/futures=%v", session.ExchangeName, credential, session.Margin, session.IsolatedMarginSymbol, session.Futures)
+		if other, ok := seen[key]; ok {
+			log.Warnf("session %s and session %s use the same api key of exchange %s, they are trading on the same account", other, name, session.ExchangeName)
+			continue
+		}
+
+		seen[key] = name
+	}
+}
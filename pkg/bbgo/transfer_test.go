@@ -0,0 +1,59 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type subAccountExchange struct {
+	*mock.Exchange
+
+	transfers []types.SubAccountTransfer
+}
+
+func (e *subAccountExchange) TransferSubAccount(ctx context.Context, fromAccount, toAccount string, asset string, amount fixedpoint.Value) (*types.SubAccountTransfer, error) {
+	transfer := types.SubAccountTransfer{
+		Exchange:    e.Name(),
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Asset:       asset,
+		Amount:      amount,
+	}
+	e.transfers = append(e.transfers, transfer)
+	return &transfer, nil
+}
+
+func TestEnvironment_TransferBetweenSessions(t *testing.T) {
+	ctx := context.Background()
+	master := &subAccountExchange{Exchange: mock.New(types.ExchangeBinance, types.MarketMap{}, types.BalanceMap{})}
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance_main", &ExchangeSession{Name: "binance_main", ExchangeName: "binance", Exchange: master})
+	environ.AddExchangeSession("binance_sub1", &ExchangeSession{Name: "binance_sub1", ExchangeName: "binance", Exchange: master, SubAccount: "sub1@example.com"})
+	environ.AddExchangeSession("max", &ExchangeSession{Name: "max", ExchangeName: "max", Exchange: mock.New(types.ExchangeMax, types.MarketMap{}, types.BalanceMap{})})
+
+	transfer, err := environ.TransferBetweenSessions(ctx, "binance_main", "binance_sub1", "USDT", fixedpoint.NewFromFloat(100.0))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", transfer.FromAccount)
+		assert.Equal(t, "sub1@example.com", transfer.ToAccount)
+		assert.Len(t, master.transfers, 1)
+	}
+
+	_, err = environ.TransferBetweenSessions(ctx, "binance_main", "max", "USDT", fixedpoint.NewFromFloat(100.0))
+	assert.Error(t, err)
+
+	_, err = environ.TransferBetweenSessions(ctx, "binance_sub1", "binance_sub1", "USDT", fixedpoint.NewFromFloat(100.0))
+	assert.Error(t, err)
+
+	_, err = environ.TransferBetweenSessions(ctx, "binance_main", "binance_sub2", "USDT", fixedpoint.NewFromFloat(100.0))
+	assert.Error(t, err)
+
+	_, err = environ.TransferBetweenSessions(ctx, "binance_main", "binance_sub1", "USDT", 0)
+	assert.Error(t, err)
+}
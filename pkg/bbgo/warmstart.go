@@ -0,0 +1,168 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultWarmStartMaxAge is the default max age of the market data snapshot,
+// the snapshot older than this will be ignored.
+const DefaultWarmStartMaxAge = time.Hour
+
+// orderBookSnapshotMaxAge is the max age of the cached order book,
+// the order book changes fast, so we only load it when the bot is restarted immediately.
+const orderBookSnapshotMaxAge = time.Minute
+
+// MarketDataSnapshot is the cached market data of a symbol,
+// the indicator states are rebuilt from the cached klines since the standard indicators are updated by the kline windows.
+type MarketDataSnapshot struct {
+	Symbol       string                               `json:"symbol"`
+	Time         time.Time                            `json:"time"`
+	KLineWindows map[types.Interval]types.KLineWindow `json:"klineWindows"`
+	OrderBook    types.OrderBook                      `json:"orderBook"`
+}
+
+func (s *MarketDataSnapshot) IsFresh(now time.Time, maxAge time.Duration) bool {
+	return now.Sub(s.Time) <= maxAge
+}
+
+func (store *MarketDataStore) Snapshot() *MarketDataSnapshot {
+	snapshot := &MarketDataSnapshot{
+		Symbol:       store.Symbol,
+		Time:         time.Now(),
+		KLineWindows: store.KLineWindows,
+	}
+
+	// the out of sync book is not cached
+	if book, ok := store.OrderBook(); ok {
+		snapshot.OrderBook = book
+	}
+
+	return snapshot
+}
+
+func marketDataSnapshotFile(sessionName, symbol string) string {
+	return path.Join(CacheDir(), "market-data-"+sessionName+"-"+symbol+".json")
+}
+
+// SaveMarketDataSnapshot writes the market data snapshot of the given symbol to the cache directory
+func SaveMarketDataSnapshot(sessionName string, store *MarketDataStore) error {
+	out, err := json.Marshal(store.Snapshot())
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(marketDataSnapshotFile(sessionName, store.Symbol), out, 0666)
+}
+
+// LoadMarketDataSnapshot loads the market data snapshot of the given symbol from the cache directory,
+// nil is returned if the snapshot does not exist.
+func LoadMarketDataSnapshot(sessionName, symbol string) (*MarketDataSnapshot, error) {
+	data, err := ioutil.ReadFile(marketDataSnapshotFile(sessionName, symbol))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var snapshot MarketDataSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// loadMarketDataSnapshot loads the cached market data snapshot of the symbol,
+// nil is returned if there is no snapshot or the snapshot is stale.
+func (session *ExchangeSession) loadMarketDataSnapshot(symbol string, now time.Time) (*MarketDataSnapshot, error) {
+	snapshot, err := LoadMarketDataSnapshot(session.Name, symbol)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+
+	maxAge := DefaultWarmStartMaxAge
+	if session.WarmStartMaxAge > 0 {
+		maxAge = session.WarmStartMaxAge.Duration()
+	}
+
+	if snapshot.Symbol != symbol || !snapshot.IsFresh(now, maxAge) {
+		log.Warnf("%s market data snapshot of %s is stale (saved at %s), ignoring", session.Name, symbol, snapshot.Time)
+		return nil, nil
+	}
+
+	log.Infof("%s market data snapshot of %s loaded (saved at %s)", session.Name, symbol, snapshot.Time)
+	return snapshot, nil
+}
+
+// queryKLinesFromSnapshot returns the cached klines of the interval with the missing klines queried from the exchange,
+// if the cached klines can not be connected with the queried klines, all the klines are queried from the exchange.
+func (session *ExchangeSession) queryKLinesFromSnapshot(ctx context.Context, snapshot *MarketDataSnapshot, interval types.Interval, endTime time.Time) ([]types.KLine, error) {
+	queryAll := func() ([]types.KLine, error) {
+		return session.queryWarmUpKLines(ctx, snapshot.Symbol, interval, endTime)
+	}
+
+	cachedKLines, ok := snapshot.KLineWindows[interval]
+	if !ok || len(cachedKLines) == 0 {
+		return queryAll()
+	}
+
+	lastKLine := cachedKLines.Last()
+	if !lastKLine.EndTime.Before(endTime) {
+		return cachedKLines, nil
+	}
+
+	// the cached klines are useless if the missing range is longer than the warm-up window
+	startTime := lastKLine.EndTime
+	if endTime.Sub(startTime) >= time.Duration(warmUpKLineLimit)*interval.Duration() {
+		return queryAll()
+	}
+
+	kLines, err := types.NewKLineFetcher(session.Exchange).FetchAll(ctx, snapshot.Symbol, interval, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first queried kline must be right after the last cached kline, otherwise there is a gap.
+	if len(kLines) > 0 && kLines[0].StartTime.After(lastKLine.EndTime.Add(interval.Duration())) {
+		log.Warnf("%s %s kline gap found between the cached klines and the queried klines, querying all klines", snapshot.Symbol, interval)
+		return queryAll()
+	}
+
+	for _, k := range kLines {
+		if k.StartTime.After(lastKLine.StartTime) {
+			cachedKLines = append(cachedKLines, k)
+		}
+	}
+
+	return cachedKLines, nil
+}
+
+// SaveMarketDataSnapshots saves the market data snapshots of the sessions with warm start enabled
+func (environ *Environment) SaveMarketDataSnapshots() error {
+	for _, session := range environ.sessions {
+		if !session.WarmStart {
+			continue
+		}
+
+		for symbol, store := range session.marketDataStores {
+			if err := SaveMarketDataSnapshot(session.Name, store); err != nil {
+				return err
+			}
+
+			log.Infof("%s market data snapshot of %s saved", session.Name, symbol)
+		}
+	}
+
+	return nil
+}
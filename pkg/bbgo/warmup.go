@@ -0,0 +1,144 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrWarmingUp = errors.New("strategy is warming up, orders are not allowed yet")
+
+// WarmUp is the warm-up gate config, embed it in the strategy struct to enable the gate,
+// the trader rejects the orders of the strategy until enough market data is received,
+// so that the indicators are fully formed before the first order.
+type WarmUp struct {
+	// WarmupDuration is the market data time span (by the kline time) required before submitting orders
+	WarmupDuration types.Duration `json:"warmupDuration,omitempty" yaml:"warmupDuration,omitempty"`
+
+	// WarmupKLines is the number of the closed klines required before submitting orders
+	WarmupKLines int `json:"warmupKLines,omitempty" yaml:"warmupKLines,omitempty"`
+
+	// WarmupInterval is the kline interval used by the warm-up gate, defaults to 1m
+	WarmupInterval types.Interval `json:"warmupInterval,omitempty" yaml:"warmupInterval,omitempty"`
+}
+
+func (w WarmUp) WarmUpSettings() WarmUp {
+	return w
+}
+
+func (w WarmUp) IsEnabled() bool {
+	return w.WarmupDuration > 0 || w.WarmupKLines > 0
+}
+
+// WarmUpStrategy is implemented by the strategies embedding the WarmUp struct
+type WarmUpStrategy interface {
+	WarmUpSettings() WarmUp
+}
+
+// WarmUpOrderExecutor rejects the orders until the warm-up condition is satisfied,
+// the time span is measured by the kline time so that it works in the back-test as well.
+type WarmUpOrderExecutor struct {
+	OrderExecutor
+
+	WarmUp WarmUp
+	Symbol string
+
+	mu        sync.Mutex
+	numKLines int
+	firstTime time.Time
+	lastTime  time.Time
+	warmedUp  bool
+}
+
+func NewWarmUpOrderExecutor(executor OrderExecutor, warmUp WarmUp, symbol string) *WarmUpOrderExecutor {
+	if warmUp.WarmupInterval == "" {
+		warmUp.WarmupInterval = types.Interval1m
+	}
+
+	return &WarmUpOrderExecutor{
+		OrderExecutor: executor,
+		WarmUp:        warmUp,
+		Symbol:        symbol,
+	}
+}
+
+func (e *WarmUpOrderExecutor) BindStream(stream types.StandardStreamEventHub) {
+	stream.OnKLineClosed(func(kline types.KLine) {
+		if (e.Symbol != "" && kline.Symbol != e.Symbol) || kline.Interval != e.WarmUp.WarmupInterval {
+			return
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		e.numKLines++
+		if e.firstTime.IsZero() {
+			e.firstTime = kline.StartTime
+		}
+
+		e.lastTime = kline.EndTime
+	})
+}
+
+// IsWarmedUp checks the warm-up condition, once it's satisfied, it stays warmed up
+func (e *WarmUpOrderExecutor) IsWarmedUp() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.warmedUp {
+		return true
+	}
+
+	if e.numKLines < e.WarmUp.WarmupKLines {
+		return false
+	}
+
+	if e.WarmUp.WarmupDuration > 0 && (e.firstTime.IsZero() || e.lastTime.Sub(e.firstTime) < e.WarmUp.WarmupDuration.Duration()) {
+		return false
+	}
+
+	e.warmedUp = true
+	return true
+}
+
+func (e *WarmUpOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (types.OrderSlice, error) {
+	if !e.IsWarmedUp() {
+		return nil, ErrWarmingUp
+	}
+
+	return e.OrderExecutor.SubmitOrders(ctx, orders...)
+}
+
+// wrapWarmUpOrderExecutor wraps the order executor with the warm-up gate if the strategy enables it
+func wrapWarmUpOrderExecutor(strategy SingleExchangeStrategy, session *ExchangeSession, executor OrderExecutor) OrderExecutor {
+	warmUpStrategy, ok := strategy.(WarmUpStrategy)
+	if !ok {
+		return executor
+	}
+
+	warmUp := warmUpStrategy.WarmUpSettings()
+	if !warmUp.IsEnabled() {
+		return executor
+	}
+
+	var symbol string
+	if rs := reflect.ValueOf(strategy); rs.Kind() == reflect.Ptr && rs.Elem().Kind() == reflect.Struct {
+		symbol, _ = isSymbolBasedStrategy(rs.Elem())
+	}
+
+	gate := NewWarmUpOrderExecutor(executor, warmUp, symbol)
+	if symbol != "" {
+		session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: string(gate.WarmUp.WarmupInterval)})
+	}
+
+	gate.BindStream(session.Stream)
+
+	log.Infof("strategy %s is gated by the warm-up: %d klines, %s", strategy.ID(), warmUp.WarmupKLines, warmUp.WarmupDuration.Duration())
+	return gate
+}
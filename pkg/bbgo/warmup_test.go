@@ -0,0 +1,50 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestWarmUpOrderExecutor(t *testing.T) {
+	stream := &types.StandardStream{}
+	gate := NewWarmUpOrderExecutor(nil, WarmUp{
+		WarmupKLines:   3,
+		WarmupDuration: types.Duration(5 * time.Minute),
+	}, "BTCUSDT")
+	gate.BindStream(stream)
+
+	_, err := gate.SubmitOrders(context.Background(), types.SubmitOrder{Symbol: "BTCUSDT"})
+	assert.Equal(t, ErrWarmingUp, err)
+
+	startTime := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	emit := func(symbol string, i int) {
+		stream.EmitKLineClosed(types.KLine{
+			Symbol:    symbol,
+			Interval:  types.Interval1m,
+			StartTime: startTime.Add(time.Duration(i) * time.Minute),
+			EndTime:   startTime.Add(time.Duration(i+1) * time.Minute),
+		})
+	}
+
+	// the klines of the other symbols are ignored
+	for i := 0; i < 10; i++ {
+		emit("ETHUSDT", i)
+	}
+	assert.False(t, gate.IsWarmedUp())
+
+	for i := 0; i < 3; i++ {
+		emit("BTCUSDT", i)
+	}
+
+	// enough klines, but the time span is not long enough
+	assert.False(t, gate.IsWarmedUp())
+
+	emit("BTCUSDT", 3)
+	emit("BTCUSDT", 4)
+	assert.True(t, gate.IsWarmedUp())
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/accounting"
+	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	AssetFlowCmd.Flags().String("exchange", "", "target exchange")
+	AssetFlowCmd.Flags().String("since", "", "the start date of the synced records, e.g., 2021-01-01")
+	AssetFlowCmd.Flags().String("until", "", "the end date (exclusive) of the synced records, e.g., 2021-02-01")
+	AssetFlowCmd.Flags().String("quote", "USDT", "the quote currency of the asset values")
+	RootCmd.AddCommand(AssetFlowCmd)
+}
+
+// AssetFlowCmd reconciles the synced deposits and withdraws with the synced trades,
+// the current balances are compared with the asset changes when the time range is not limited.
+var AssetFlowCmd = &cobra.Command{
+	Use:          "asset-flow",
+	Short:        "reconcile the net asset flows with the trading changes",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		exchangeNameStr, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := types.ValidExchangeName(exchangeNameStr)
+		if err != nil {
+			return err
+		}
+
+		quoteCurrency, err := cmd.Flags().GetString("quote")
+		if err != nil {
+			return err
+		}
+
+		// default to all the synced records
+		var since = time.Unix(0, 0)
+		var until = time.Now()
+
+		sinceStr, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		untilStr, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if len(sinceStr) > 0 || len(untilStr) > 0 {
+			loc, err := time.LoadLocation("Asia/Taipei")
+			if err != nil {
+				return err
+			}
+
+			if len(sinceStr) > 0 {
+				since, err = time.ParseInLocation("2006-01-02", sinceStr, loc)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(untilStr) > 0 {
+				until, err = time.ParseInLocation("2006-01-02", untilStr, loc)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		exchange, err := cmdutil.NewExchange(exchangeName)
+		if err != nil {
+			return err
+		}
+
+		markets, err := exchange.QueryMarkets(ctx)
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		depositService := &service.DepositService{DB: db}
+		withdrawService := &service.WithdrawService{DB: db}
+		tradeService := &service.TradeService{DB: db}
+
+		deposits, err := depositService.QueryByTimeRange(exchange.Name(), since, until)
+		if err != nil {
+			return err
+		}
+
+		withdraws, err := withdrawService.QueryByTimeRange(exchange.Name(), since, until)
+		if err != nil {
+			return err
+		}
+
+		trades, err := tradeService.QueryAllByTimeRange(exchange.Name(), since, until)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("%d deposits, %d withdraws and %d trades loaded", len(deposits), len(withdraws), len(trades))
+
+		report := accounting.NewAssetFlowReport(since, until)
+		report.AddDeposits(deposits)
+		report.AddWithdraws(withdraws)
+		report.AddTrades(markets, trades)
+
+		// the balances can only be reconciled with the whole account history
+		if len(sinceStr) == 0 && len(untilStr) == 0 {
+			balances, err := exchange.QueryAccountBalances(ctx)
+			if err != nil {
+				return err
+			}
+
+			report.Reconcile(balances)
+		}
+
+		report.Print()
+
+		var prices = map[string]float64{}
+		for _, asset := range report.Assets() {
+			symbol := asset + quoteCurrency
+			if _, ok := markets[symbol]; !ok {
+				continue
+			}
+
+			price, err := queryLastPrice(ctx, exchange, symbol)
+			if err != nil {
+				return err
+			}
+
+			prices[asset] = price
+		}
+
+		netFlowValue, tradingValue := report.Values(quoteCurrency, prices)
+		log.Infof("NET FLOW VALUE (%s): %f", quoteCurrency, netFlowValue)
+		log.Infof("TRADING PNL (%s): %f", quoteCurrency, tradingValue)
+		return nil
+	},
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func init() {
+	AuditExportCmd.Flags().String("since", "", "export the audit logs since the given date (YYYY-MM-DD), defaults to 30 days ago")
+	AuditExportCmd.Flags().String("until", "", "export the audit logs until the end of the given date (YYYY-MM-DD), defaults to now")
+	AuditExportCmd.Flags().String("output", "", "the output CSV file, defaults to stdout")
+	AuditCmd.AddCommand(AuditExportCmd)
+	RootCmd.AddCommand(AuditCmd)
+}
+
+var AuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "audit log of the account state mutations",
+}
+
+var AuditExportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "export the audit logs to CSV",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		until := time.Now()
+		since := until.AddDate(0, 0, -30)
+
+		if s, err := cmd.Flags().GetString("since"); err == nil && len(s) > 0 {
+			since, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		if s, err := cmd.Flags().GetString("until"); err == nil && len(s) > 0 {
+			until, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+
+			// the until date is inclusive, the logs of the whole day are exported
+			until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		auditService := &service.AuditService{DB: db}
+		logs, err := auditService.Query(since, until)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if output, err := cmd.Flags().GetString("output"); err == nil && len(output) > 0 {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		return service.WriteAuditLogsCSV(w, logs)
+	},
+}
@@ -8,14 +8,13 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
 	"github.com/c9s/bbgo/pkg/backtest"
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
-	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 func init() {
@@ -24,6 +23,8 @@ func init() {
 	BacktestCmd.Flags().Bool("sync-only", false, "sync backtest data only, do not run backtest")
 	BacktestCmd.Flags().String("sync-from", time.Now().AddDate(0, -6, 0).Format(types.DateFormat), "sync backtest data from the given time")
 	BacktestCmd.Flags().Bool("base-asset-baseline", false, "use base asset performance as the competitive baseline performance")
+	BacktestCmd.Flags().String("report", "", "write the summary report of the backtest to the given JSON file")
+	BacktestCmd.Flags().Int64("random-seed", 0, "random seed of the randomized components, overrides the randomSeed of the backtest config")
 	BacktestCmd.Flags().CountP("verbose", "v", "verbose level")
 	BacktestCmd.Flags().String("config", "config/bbgo.yaml", "strategy config file")
 	RootCmd.AddCommand(BacktestCmd)
@@ -53,6 +54,11 @@ var BacktestCmd = &cobra.Command{
 			return err
 		}
 
+		reportFile, err := cmd.Flags().GetString("report")
+		if err != nil {
+			return err
+		}
+
 		wantSync, err := cmd.Flags().GetBool("sync")
 		if err != nil {
 			return err
@@ -96,13 +102,20 @@ var BacktestCmd = &cobra.Command{
 			return err
 		}
 
-		db, err := bbgo.ConnectMySQL(viper.GetString("mysql-url"))
-		if err != nil {
+		if userConfig.Backtest == nil {
+			return errors.New("backtest config is not defined")
+		}
+
+		if err := bbgo.ValidateKLineAggregation(userConfig.Backtest.KLineAggregation); err != nil {
 			return err
 		}
 
-		if userConfig.Backtest == nil {
-			return errors.New("backtest config is not defined")
+		if userConfig.CurrencyEquivalence != nil {
+			if err := userConfig.CurrencyEquivalence.Validate(); err != nil {
+				return err
+			}
+
+			types.SetCurrencyEquivalence(userConfig.CurrencyEquivalence)
 		}
 
 		// set default start time to the past 6 months
@@ -115,15 +128,24 @@ var BacktestCmd = &cobra.Command{
 			return err
 		}
 
+		if seed, err := cmd.Flags().GetInt64("random-seed"); err == nil && seed != 0 {
+			userConfig.Backtest.RandomSeed = seed
+		}
+
+		if userConfig.Backtest.RandomSeed != 0 {
+			log.Infof("using random seed %d", userConfig.Backtest.RandomSeed)
+			util.SetRandomSeed(userConfig.Backtest.RandomSeed)
+		}
+
 		environ := bbgo.NewEnvironment()
-		if viper.IsSet("mysql-url") {
-			dsn := viper.GetString("mysql-url")
-			if err := environ.ConfigureDatabase(ctx, dsn); err != nil {
-				return err
-			}
+		if err := configureDatabase(ctx, environ, userConfig); err != nil {
+			return err
 		}
 
-		backtestService := &service.BacktestService{DB: db}
+		backtestService := environ.BacktestService
+		if backtestService == nil {
+			return errors.New("backtest requires the database, please set the database config or MYSQL_URL")
+		}
 
 		if wantSync {
 			log.Info("starting synchronization...")
@@ -187,7 +209,11 @@ var BacktestCmd = &cobra.Command{
 		backtestExchange := backtest.NewExchange(exchangeName, backtestService, userConfig.Backtest)
 
 		environ.SetStartTime(startTime)
-		environ.AddExchange(exchangeName.String(), backtestExchange)
+		backtestSession := environ.AddExchange(exchangeName.String(), backtestExchange)
+		backtestSession.KLineAggregation = userConfig.Backtest.KLineAggregation
+
+		// the orders are matched in the submission order, so that the backtest results are reproducible
+		backtestSession.OrderSubmitWorkers = 1
 
 		environ.Notifiability = bbgo.Notifiability{
 			SymbolChannelRouter:  bbgo.NewPatternChannelRouter(nil),
@@ -212,6 +238,14 @@ var BacktestCmd = &cobra.Command{
 			trader.SetRiskControls(userConfig.RiskControls)
 		}
 
+		if userConfig.Logging != nil {
+			if err := userConfig.Logging.Validate(); err != nil {
+				return err
+			}
+
+			trader.SetLoggingConfig(userConfig.Logging)
+		}
+
 		for _, entry := range userConfig.ExchangeStrategies {
 			log.Infof("attaching strategy %T on %s instead of %v", entry.Strategy, exchangeName.String(), entry.Mounts)
 			trader.AttachStrategyOn(exchangeName.String(), entry.Strategy)
@@ -223,6 +257,33 @@ var BacktestCmd = &cobra.Command{
 
 		trader.Subscribe()
 
+		// record the equity curve of each symbol in the quote currency for the drawdown report
+		drawdownRecorders := make(map[string]*backtest.DrawdownRecorder)
+		for _, session := range environ.Sessions() {
+			session := session
+			for _, symbol := range userConfig.Backtest.Symbols {
+				drawdownRecorders[symbol] = &backtest.DrawdownRecorder{}
+			}
+
+			session.Stream.OnKLineClosed(func(kline types.KLine) {
+				if kline.Interval != types.Interval1m {
+					return
+				}
+
+				recorder, ok := drawdownRecorders[kline.Symbol]
+				if !ok {
+					return
+				}
+
+				market, ok := session.Market(kline.Symbol)
+				if !ok {
+					return
+				}
+
+				recorder.RecordAt(kline.EndTime, InQuoteAsset(session.Account.Balances(), market, kline.Close))
+			})
+		}
+
 		if err := trader.Run(ctx); err != nil {
 			return err
 		}
@@ -236,6 +297,10 @@ var BacktestCmd = &cobra.Command{
 
 		// put the logger back to print the pnl
 		log.SetLevel(log.InfoLevel)
+
+		summaryReport := backtest.SummaryReport{StartTime: startTime}
+		summaryReport.EndTime, _ = userConfig.Backtest.ParseEndTime()
+
 		for _, session := range environ.Sessions() {
 
 			calculator := &pnl.AverageCostCalculator{
@@ -272,6 +337,28 @@ var BacktestCmd = &cobra.Command{
 				log.Infof("FINAL BALANCES:")
 				finalBalances.Print()
 
+				symbolReport := backtest.SymbolReport{
+					Symbol:           symbol,
+					NumTrades:        report.NumTrades,
+					Profit:           report.Profit,
+					UnrealizedProfit: report.UnrealizedProfit,
+					InitialEquity:    InQuoteAsset(initBalances, market, startPrice),
+				}
+
+				if recorder, ok := drawdownRecorders[symbol]; ok {
+					symbolReport.FinalEquity = recorder.Last
+					symbolReport.PeakEquity = recorder.Peak
+					symbolReport.MaxDrawdown = recorder.MaxDrawdown
+					symbolReport.Sharpe = recorder.SharpeRatio()
+
+					log.Infof("INITIAL EQUITY ~= %s %s", market.FormatPrice(symbolReport.InitialEquity), market.QuoteCurrency)
+					log.Infof("FINAL EQUITY ~= %s %s (PEAK %s %s)", market.FormatPrice(recorder.Last), market.QuoteCurrency, market.FormatPrice(recorder.Peak), market.QuoteCurrency)
+					log.Infof("MAX DRAWDOWN: %.2f%%", recorder.MaxDrawdown*100.0)
+					log.Infof("SHARPE RATIO: %.2f", symbolReport.Sharpe)
+				}
+
+				summaryReport.Symbols = append(summaryReport.Symbols, symbolReport)
+
 				if wantBaseAssetBaseline {
 					initBaseAsset := InBaseAsset(initBalances, market, startPrice)
 					finalBaseAsset := InBaseAsset(finalBalances, market, lastPrice)
@@ -284,10 +371,22 @@ var BacktestCmd = &cobra.Command{
 			}
 		}
 
+		if len(reportFile) > 0 {
+			if err := summaryReport.WriteFile(reportFile); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	},
 }
 
+func InQuoteAsset(balances types.BalanceMap, market types.Market, price float64) float64 {
+	quote := balances[market.QuoteCurrency]
+	base := balances[market.BaseCurrency]
+	return (quote.Locked.Float64() + quote.Available.Float64()) + ((base.Locked.Float64() + base.Available.Float64()) * price)
+}
+
 func InBaseAsset(balances types.BalanceMap, market types.Market, price float64) float64 {
 	quote := balances[market.QuoteCurrency]
 	base := balances[market.BaseCurrency]
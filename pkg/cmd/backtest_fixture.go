@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	BacktestFixtureCmd.Flags().String("output", "fixtures", "the output directory of the fixture files")
+	BacktestFixtureCmd.Flags().String("since", "", "skip the events before the given time (RFC3339), the compressed recordings are seeked by the index")
+	BacktestFixtureCmd.Flags().Bool("import", false, "import the klines into the backtest database, so that they can be used by the backtest command")
+	RootCmd.AddCommand(BacktestFixtureCmd)
+}
+
+// BacktestFixtureCmd converts the stream recordings into the backtest fixtures
+var BacktestFixtureCmd = &cobra.Command{
+	Use:          "backtest-fixture [recording files...]",
+	Short:        "convert the stream recordings into the backtest fixtures",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputDir, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		wantImport, err := cmd.Flags().GetBool("import")
+		if err != nil {
+			return err
+		}
+
+		var since time.Time
+		if s, err := cmd.Flags().GetString("since"); err == nil && len(s) > 0 {
+			since, err = time.Parse(time.RFC3339, s)
+			if err != nil {
+				return err
+			}
+		}
+
+		var events []types.RecordedEvent
+		for _, filename := range args {
+			fileEvents, err := types.ReadRecordingFile(filename, since)
+			if err != nil {
+				return errors.Wrapf(err, "can not read the recording file %s", filename)
+			}
+
+			log.Infof("%s: %d events loaded", filename, len(fileEvents))
+			events = append(events, fileEvents...)
+		}
+
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].EventTime().Before(events[j].EventTime())
+		})
+
+		fixtures := backtest.NewFixtures(events)
+		if len(fixtures) == 0 {
+			return fmt.Errorf("no fixture is generated, the 1m klines are required, please subscribe the 1m kline when recording")
+		}
+
+		if err := os.MkdirAll(outputDir, 0777); err != nil {
+			return err
+		}
+
+		var backtestService *service.BacktestService
+		if wantImport {
+			userConfig, err := loadUserConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			db, err := connectDatabase(userConfig)
+			if err != nil {
+				return err
+			}
+
+			backtestService = &service.BacktestService{DB: db}
+		}
+
+		for _, fixture := range fixtures {
+			filename := filepath.Join(outputDir, fmt.Sprintf("%s-%s-%s.json",
+				fixture.Exchange, fixture.Symbol, fixture.StartTime.UTC().Format("20060102T150405")))
+
+			if err := fixture.WriteFile(filename); err != nil {
+				return err
+			}
+
+			log.Infof("fixture %s written: %s ~ %s, %d klines, %d book snapshots, %d fills",
+				filename, fixture.StartTime, fixture.EndTime,
+				len(fixture.KLines), len(fixture.BookSnapshots), len(fixture.Fills))
+
+			if backtestService == nil {
+				continue
+			}
+
+			for _, k := range fixture.KLines {
+				if err := backtestService.Insert(k); err != nil {
+					return errors.Wrapf(err, "can not import the %s kline of %s", k.Interval, k.Symbol)
+				}
+			}
+
+			log.Infof("%d klines of %s imported into the %s backtest database", len(fixture.KLines), fixture.Symbol, fixture.Exchange)
+		}
+
+		return nil
+	},
+}
@@ -3,12 +3,19 @@ package cmd
 // import built-in strategies
 import (
 	_ "github.com/c9s/bbgo/pkg/strategy/bollgrid"
+	_ "github.com/c9s/bbgo/pkg/strategy/bollmaker"
 	_ "github.com/c9s/bbgo/pkg/strategy/buyandhold"
+	_ "github.com/c9s/bbgo/pkg/strategy/dca"
+	_ "github.com/c9s/bbgo/pkg/strategy/external"
 	_ "github.com/c9s/bbgo/pkg/strategy/flashcrash"
+	_ "github.com/c9s/bbgo/pkg/strategy/fundingrate"
 	_ "github.com/c9s/bbgo/pkg/strategy/grid"
 	_ "github.com/c9s/bbgo/pkg/strategy/mirrormaker"
 	_ "github.com/c9s/bbgo/pkg/strategy/pricealert"
+	_ "github.com/c9s/bbgo/pkg/strategy/rebalance"
 	_ "github.com/c9s/bbgo/pkg/strategy/swing"
 	_ "github.com/c9s/bbgo/pkg/strategy/trailingstop"
+	_ "github.com/c9s/bbgo/pkg/strategy/triarb"
+	_ "github.com/c9s/bbgo/pkg/strategy/xarb"
 	_ "github.com/c9s/bbgo/pkg/strategy/xpuremaker"
 )
@@ -7,7 +7,6 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/types"
@@ -16,13 +15,17 @@ import (
 type advancedOrderCancelApi interface {
 	CancelAllOrders(ctx context.Context) ([]types.Order, error)
 	CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error)
-	CancelOrdersByGroupID(ctx context.Context, groupID int64) ([]types.Order, error)
+}
+
+type clientOrderIDCancelApi interface {
+	CancelOrdersByClientOrderID(ctx context.Context, clientOrderIDs ...string) ([]types.Order, error)
 }
 
 func init() {
 	CancelCmd.Flags().String("session", "", "session to execute cancel orders")
 	CancelCmd.Flags().String("symbol", "", "symbol to cancel orders")
 	CancelCmd.Flags().Int64("group-id", 0, "groupID to cancel orders")
+	CancelCmd.Flags().StringSlice("client-order-id", nil, "client order IDs to cancel orders")
 	RootCmd.AddCommand(CancelCmd)
 }
 
@@ -48,6 +51,11 @@ var CancelCmd = &cobra.Command{
 			return err
 		}
 
+		clientOrderIDs, err := cmd.Flags().GetStringSlice("client-order-id")
+		if err != nil {
+			return err
+		}
+
 		configFile, err := cmd.Flags().GetString("config")
 		if err != nil {
 			return err
@@ -64,8 +72,10 @@ var CancelCmd = &cobra.Command{
 
 		environ := bbgo.NewEnvironment()
 
-		if viper.IsSet("mysql-url") {
-			db, err := bbgo.ConnectMySQL(viper.GetString("mysql-url"))
+		if conf, err := databaseConfig(userConfig); err != nil {
+			return err
+		} else if conf != nil {
+			db, err := bbgo.ConnectDB(conf.Driver, conf.DSN)
 			if err != nil {
 				return err
 			}
@@ -96,11 +106,35 @@ var CancelCmd = &cobra.Command{
 		for sessionID, session := range sessions {
 			var log = logrus.WithField("session", sessionID)
 
+			if session.PublicOnly {
+				continue
+			}
+
+			if len(clientOrderIDs) > 0 {
+				e, ok := session.Exchange.(clientOrderIDCancelApi)
+				if !ok {
+					return fmt.Errorf("session %s does not support canceling orders by client order id", sessionID)
+				}
+
+				log.Infof("canceling orders by client order ids: %v", clientOrderIDs)
+
+				orders, err := e.CancelOrdersByClientOrderID(ctx, clientOrderIDs...)
+				for _, o := range orders {
+					log.Info("CANCELED ", o.String())
+				}
+
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
 			e, ok := session.Exchange.(advancedOrderCancelApi)
-			if ok && groupID > 0 {
+			if canceler, isGroupCanceler := session.Exchange.(types.GroupOrderCanceler); isGroupCanceler && groupID > 0 {
 				log.Infof("canceling orders by group id: %d", groupID)
 
-				orders, err := e.CancelOrdersByGroupID(ctx, groupID)
+				orders, err := canceler.CancelOrdersByGroupID(ctx, groupID)
 				if err != nil {
 					return err
 				}
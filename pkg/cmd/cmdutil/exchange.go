@@ -8,11 +8,16 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance"
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex"
+	"github.com/c9s/bbgo/pkg/exchange/coinbase"
+	"github.com/c9s/bbgo/pkg/exchange/kraken"
+	"github.com/c9s/bbgo/pkg/exchange/kucoin"
 	"github.com/c9s/bbgo/pkg/exchange/max"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
-func NewExchangeStandard(n types.ExchangeName, key, secret string) (types.Exchange, error) {
+// NewExchangeStandard creates the exchange with the api key and secret, the passphrase is only required by kucoin.
+func NewExchangeStandard(n types.ExchangeName, key, secret, passphrase string) (types.Exchange, error) {
 	if len(key) == 0 || len(secret) == 0 {
 		return nil, errors.New("binance: empty key or secret")
 	}
@@ -25,6 +30,50 @@ func NewExchangeStandard(n types.ExchangeName, key, secret string) (types.Exchan
 	case types.ExchangeMax:
 		return max.New(key, secret), nil
 
+	case types.ExchangeKucoin:
+		if len(passphrase) == 0 {
+			return nil, errors.New("kucoin: empty passphrase")
+		}
+
+		return kucoin.New(key, secret, passphrase), nil
+
+	case types.ExchangeCoinbase:
+		return coinbase.New(key, secret), nil
+
+	case types.ExchangeBitfinex:
+		return bitfinex.New(key, secret), nil
+
+	case types.ExchangeKraken:
+		return kraken.New(key, secret), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported exchange: %v", n)
+
+	}
+}
+
+// NewExchangePublic creates the exchange without the api credentials, only the public market data apis can be used.
+func NewExchangePublic(n types.ExchangeName) (types.Exchange, error) {
+	switch n {
+
+	case types.ExchangeBinance:
+		return binance.New("", ""), nil
+
+	case types.ExchangeMax:
+		return max.New("", ""), nil
+
+	case types.ExchangeKucoin:
+		return kucoin.New("", "", ""), nil
+
+	case types.ExchangeCoinbase:
+		return coinbase.New("", ""), nil
+
+	case types.ExchangeBitfinex:
+		return bitfinex.New("", ""), nil
+
+	case types.ExchangeKraken:
+		return kraken.New("", ""), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported exchange: %v", n)
 
@@ -44,7 +93,8 @@ func NewExchangeWithEnvVarPrefix(n types.ExchangeName, varPrefix string) (types.
 		return nil, fmt.Errorf("%s: empty key or secret, env var prefix: %s", n, varPrefix)
 	}
 
-	return NewExchangeStandard(n, key, secret)
+	passphrase := os.Getenv(varPrefix + "_API_PASSPHRASE")
+	return NewExchangeStandard(n, key, secret, passphrase)
 }
 
 // NewExchange constructor exchange object from viper config.
@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	ImportCmd.Flags().String("exchange", "", "the exchange of the imported data, the klines are imported into the backtest database of the exchange")
+	ImportCmd.Flags().String("type", "kline", "the data type of the files, kline or trade")
+	ImportCmd.Flags().String("symbol", "", "the symbol of the data if the files have no symbol column")
+	ImportCmd.Flags().String("interval", "", "the kline interval if the files have no interval column")
+	ImportCmd.Flags().String("format", "", "the file format, defaults to the file extension (.csv or .csv.gz)")
+	RootCmd.AddCommand(ImportCmd)
+
+	ExportCmd.Flags().String("exchange", "", "the exchange of the exported data")
+	ExportCmd.Flags().String("type", "kline", "the data type to export, kline or trade")
+	ExportCmd.Flags().String("symbol", "", "the symbol to export")
+	ExportCmd.Flags().String("interval", "1m", "the kline interval to export")
+	ExportCmd.Flags().String("since", "", "export the data since the given date (YYYY-MM-DD), defaults to 30 days ago")
+	ExportCmd.Flags().String("until", "", "export the data until the given date (YYYY-MM-DD), defaults to now")
+	ExportCmd.Flags().String("output", "", "the output file, the data is compressed if it ends with .gz, defaults to stdout")
+	ExportCmd.Flags().String("format", "", "the file format, defaults to the output file extension or csv")
+	RootCmd.AddCommand(ExportCmd)
+}
+
+// dataFileFormat returns the format of the data file, only CSV is supported,
+// the parquet dumps should be converted to CSV (e.g., with pandas or duckdb) before importing.
+func dataFileFormat(filename, format string) (string, error) {
+	if len(format) == 0 {
+		format = strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(filename, ".gz")), ".")
+	}
+
+	switch strings.ToLower(format) {
+	case "csv", "":
+		return "csv", nil
+	case "parquet":
+		return "", errors.New("the parquet format is not supported, please convert the file to CSV")
+	}
+
+	return "", fmt.Errorf("unsupported data file format: %s", format)
+}
+
+func openDataFile(filename string) (io.ReadCloser, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(filename, ".gz") {
+		return f, nil
+	}
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{Reader: r, Closer: f}, nil
+}
+
+var ImportCmd = &cobra.Command{
+	Use:          "import [files]",
+	Short:        "import the klines or the trades from the CSV dumps into the database",
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchangeNameStr, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := types.ValidExchangeName(exchangeNameStr)
+		if err != nil {
+			return err
+		}
+
+		dataType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		interval, err := cmd.Flags().GetString("interval")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		backtestService := &service.BacktestService{DB: db}
+		tradeService := &service.TradeService{DB: db}
+
+		for _, filename := range args {
+			if _, err := dataFileFormat(filename, format); err != nil {
+				return err
+			}
+
+			f, err := openDataFile(filename)
+			if err != nil {
+				return err
+			}
+
+			switch dataType {
+			case "kline", "klines":
+				// the klines are grouped by the symbol and the interval, so that the stored klines are skipped in one query per group
+				var groups = map[string][]types.KLine{}
+				err = service.ReadKLinesCSV(f, types.KLine{
+					Exchange: exchangeName.String(),
+					Symbol:   strings.ToUpper(symbol),
+					Interval: types.Interval(interval),
+				}, func(kline types.KLine) error {
+					key := kline.Symbol + "." + string(kline.Interval)
+					groups[key] = append(groups[key], kline)
+					return nil
+				})
+				if err != nil {
+					break
+				}
+
+				for key, klines := range groups {
+					count, importErr := backtestService.Import(klines)
+					if importErr != nil {
+						err = errors.Wrapf(importErr, "can not import the %s klines", key)
+						break
+					}
+
+					log.Infof("%d of %d %s klines imported from %s", count, len(klines), key, filename)
+				}
+
+			case "trade", "trades":
+				var count int
+				err = service.ReadTradesCSV(f, types.Trade{
+					Exchange: exchangeName.String(),
+					Symbol:   strings.ToUpper(symbol),
+				}, func(trade types.Trade) error {
+					count++
+					return tradeService.Insert(trade)
+				})
+				if err == nil {
+					log.Infof("%d trades imported from %s, the existing trades are ignored", count, filename)
+				}
+
+			default:
+				err = fmt.Errorf("unsupported data type: %s", dataType)
+			}
+
+			f.Close()
+			if err != nil {
+				return errors.Wrapf(err, "can not import %s", filename)
+			}
+		}
+
+		return nil
+	},
+}
+
+var ExportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "export the synced klines or trades to CSV",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchangeNameStr, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := types.ValidExchangeName(exchangeNameStr)
+		if err != nil {
+			return err
+		}
+
+		dataType, err := cmd.Flags().GetString("type")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbol) == 0 {
+			return errors.New("--symbol is required")
+		}
+
+		symbol = strings.ToUpper(symbol)
+
+		interval, err := cmd.Flags().GetString("interval")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		if _, err := dataFileFormat(output, format); err != nil {
+			return err
+		}
+
+		until := time.Now()
+		since := until.AddDate(0, 0, -30)
+
+		if s, err := cmd.Flags().GetString("since"); err == nil && len(s) > 0 {
+			since, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		if s, err := cmd.Flags().GetString("until"); err == nil && len(s) > 0 {
+			until, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if len(output) > 0 {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+
+			if strings.HasSuffix(output, ".gz") {
+				gw := gzip.NewWriter(f)
+				defer gw.Close()
+				w = gw
+			}
+		}
+
+		switch dataType {
+		case "kline", "klines":
+			backtestService := &service.BacktestService{DB: db}
+			klines, err := backtestService.QueryKLinesByTimeRange(exchangeName, symbol, types.Interval(interval), since, until)
+			if err != nil {
+				return err
+			}
+
+			return service.WriteKLinesCSV(w, klines)
+
+		case "trade", "trades":
+			tradeService := &service.TradeService{DB: db}
+			trades, err := tradeService.QueryByTimeRange(exchangeName, symbol, since, until)
+			if err != nil {
+				return err
+			}
+
+			return service.WriteTradesCSV(w, trades)
+		}
+
+		return fmt.Errorf("unsupported data type: %s", dataType)
+	},
+}
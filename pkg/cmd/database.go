@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// loadUserConfig loads the config file of the --config option for the database section,
+// it returns nil if the config file doesn't exist, so that the database can still be set by the options.
+func loadUserConfig(cmd *cobra.Command) (*bbgo.Config, error) {
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil || len(configFile) == 0 {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return bbgo.Load(configFile, false)
+}
+
+// lookupSetting looks up the option with viper, which reads the flags, the config and the environment variables,
+// the environment variable is looked up directly as the fallback since bbgo-desktop doesn't enable the viper env binding.
+func lookupSetting(key string) (string, bool) {
+	if viper.IsSet(key) {
+		return viper.GetString(key), true
+	}
+
+	return os.LookupEnv(strings.ToUpper(strings.ReplaceAll(key, "-", "_")))
+}
+
+// databaseConfig returns the database of the commands, the database section of the user config is used if it's set,
+// then the db-driver and db-dsn options, and the MySQL database of the mysql-url option at last.
+// It returns nil if no database is configured.
+func databaseConfig(userConfig *bbgo.Config) (*bbgo.DatabaseConfig, error) {
+	var conf *bbgo.DatabaseConfig
+	if userConfig != nil && userConfig.Database != nil {
+		conf = userConfig.Database
+	} else if dsn, ok := lookupSetting("db-dsn"); ok {
+		driver, _ := lookupSetting("db-driver")
+		conf = &bbgo.DatabaseConfig{Driver: driver, DSN: dsn}
+	} else if dsn, ok := lookupSetting("mysql-url"); ok {
+		conf = &bbgo.DatabaseConfig{Driver: service.DriverMySQL, DSN: dsn}
+	} else {
+		return nil, nil
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// connectDatabase connects the database of databaseConfig without upgrading the schema
+func connectDatabase(userConfig *bbgo.Config) (*sqlx.DB, error) {
+	conf, err := databaseConfig(userConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf == nil {
+		dsn, _ := lookupSetting("mysql-url")
+		return bbgo.ConnectMySQL(dsn)
+	}
+
+	return bbgo.ConnectDB(conf.Driver, conf.DSN)
+}
+
+// configureDatabase configures the database of databaseConfig into the environment, it does nothing if no database is configured
+func configureDatabase(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config) error {
+	conf, err := databaseConfig(userConfig)
+	if err != nil || conf == nil {
+		return err
+	}
+
+	return environ.ConfigureDatabaseDriver(ctx, conf.Driver, conf.DSN)
+}
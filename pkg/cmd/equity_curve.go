@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func init() {
+	EquityCurveCmd.Flags().String("session", "", "the session of the equity curve")
+	EquityCurveCmd.Flags().String("since", "", "export the net values since the given date (YYYY-MM-DD), defaults to 30 days ago")
+	EquityCurveCmd.Flags().String("until", "", "export the net values until the given date (YYYY-MM-DD), defaults to now")
+	EquityCurveCmd.Flags().String("output", "", "the output CSV file, defaults to stdout")
+	RootCmd.AddCommand(EquityCurveCmd)
+}
+
+var EquityCurveCmd = &cobra.Command{
+	Use:          "equity-curve",
+	Short:        "export the recorded net values of the session to CSV",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		if len(sessionName) == 0 {
+			return errors.New("--session is required")
+		}
+
+		until := time.Now()
+		since := until.AddDate(0, 0, -30)
+
+		if s, err := cmd.Flags().GetString("since"); err == nil && len(s) > 0 {
+			since, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		if s, err := cmd.Flags().GetString("until"); err == nil && len(s) > 0 {
+			until, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		snapshotService := &service.BalanceSnapshotService{DB: db}
+		snapshots, err := snapshotService.QueryEquityCurve(sessionName, since, until)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if output, err := cmd.Flags().GetString("output"); err == nil && len(output) > 0 {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		return service.WriteEquityCurveCSV(w, snapshots)
+	},
+}
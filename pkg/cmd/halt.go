@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	HaltCmd.Flags().String("api", "", "the web server address of the running bbgo, e.g., http://localhost:8080")
+	HaltCmd.Flags().Bool("close-positions", false, "close the open positions with the market orders after canceling the orders")
+	HaltCmd.Flags().String("reason", "", "the reason of the halt, it's recorded in the audit log")
+	RootCmd.AddCommand(HaltCmd)
+}
+
+var HaltCmd = &cobra.Command{
+	Use:   "halt",
+	Short: "suspend all the strategies and cancel all the open orders of the running bbgo",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		api, err := cmd.Flags().GetString("api")
+		if err != nil {
+			return err
+		}
+
+		if len(api) == 0 {
+			return errors.New("--api is required, use the cancel command to cancel the orders if bbgo is not running")
+		}
+
+		closePositions, err := cmd.Flags().GetBool("close-positions")
+		if err != nil {
+			return err
+		}
+
+		reason, err := cmd.Flags().GetString("reason")
+		if err != nil {
+			return err
+		}
+
+		body, err := json.Marshal(bbgo.HaltOptions{ClosePositions: closePositions, Reason: reason})
+		if err != nil {
+			return err
+		}
+
+		// the halt waits for the order cancellation of all the sessions
+		client := &http.Client{Timeout: bbgo.DefaultHaltTimeout + pauseAPITimeout}
+		resp, err := client.Post(strings.TrimRight(api, "/")+"/api/halt", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		var result struct {
+			Error  string           `json:"error"`
+			Report *bbgo.HaltReport `json:"report"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return errors.Wrapf(err, "unexpected response status %s", resp.Status)
+		}
+
+		if resp.StatusCode != http.StatusOK || result.Report == nil {
+			return fmt.Errorf("can not halt bbgo: %s", result.Error)
+		}
+
+		fmt.Print(result.Report.String())
+
+		if len(result.Report.Errors) > 0 {
+			return fmt.Errorf("bbgo is halted with %d errors, please check the open orders", len(result.Report.Errors))
+		}
+
+		log.Info("bbgo is halted, the strategies can be resumed individually")
+		return nil
+	},
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/optimizer"
+)
+
+func init() {
+	OptimizeCmd.Flags().String("config", "config/bbgo.yaml", "strategy config file")
+	OptimizeCmd.Flags().String("optimizer-config", "config/optimizer.yaml", "optimizer config file")
+	OptimizeCmd.Flags().String("exchange", "", "target exchange of the backtests")
+	OptimizeCmd.Flags().String("output", "", "the output YAML file of the ranked results, defaults to stdout")
+	OptimizeCmd.Flags().String("output-dir", "", "write the configs of the best results to the directory")
+	RootCmd.AddCommand(OptimizeCmd)
+}
+
+// optimizeResult is the YAML output of a ranked result
+type optimizeResult struct {
+	Rank        int                    `yaml:"rank"`
+	Params      map[string]interface{} `yaml:"params"`
+	Sharpe      float64                `yaml:"sharpe"`
+	TotalProfit float64                `yaml:"totalProfit"`
+	MaxDrawdown float64                `yaml:"maxDrawdown"`
+}
+
+func newOptimizeResult(rank int, result optimizer.Result) optimizeResult {
+	params := make(map[string]interface{})
+	for _, param := range result.Params {
+		params[param.Label] = param.Value
+	}
+
+	return optimizeResult{
+		Rank:        rank,
+		Params:      params,
+		Sharpe:      result.Report.Sharpe(),
+		TotalProfit: result.Report.TotalProfit(),
+		MaxDrawdown: result.Report.MaxDrawdown(),
+	}
+}
+
+// walkForwardResult is the YAML output of a walk-forward window
+type walkForwardResult struct {
+	TrainStart string         `yaml:"trainStart"`
+	TestStart  string         `yaml:"testStart"`
+	TestEnd    string         `yaml:"testEnd"`
+	Train      optimizeResult `yaml:"train"`
+	Test       optimizeResult `yaml:"test"`
+}
+
+var OptimizeCmd = &cobra.Command{
+	Use:          "optimize",
+	Short:        "search the strategy parameters by running the backtests of the parameter matrix",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		optimizerConfigFile, err := cmd.Flags().GetString("optimizer-config")
+		if err != nil {
+			return err
+		}
+
+		exchangeName, err := cmd.Flags().GetString("exchange")
+		if err != nil {
+			return err
+		}
+
+		outputDir, err := cmd.Flags().GetString("output-dir")
+		if err != nil {
+			return err
+		}
+
+		configContent, err := ioutil.ReadFile(configFile)
+		if err != nil {
+			return err
+		}
+
+		optimizerConfig, err := optimizer.LoadConfig(optimizerConfigFile)
+		if err != nil {
+			return err
+		}
+
+		executor := &optimizer.LocalProcessExecutor{
+			ConfigDir: filepath.Dir(configFile),
+		}
+
+		if len(exchangeName) > 0 {
+			executor.Args = append(executor.Args, "--exchange", exchangeName)
+		}
+
+		gridOptimizer := &optimizer.GridOptimizer{
+			Config:   optimizerConfig,
+			Executor: executor,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var output interface{}
+		var best []optimizer.Result
+
+		if optimizerConfig.WalkForward != nil {
+			windows, err := gridOptimizer.WalkForward(ctx, configContent)
+			if err != nil {
+				return err
+			}
+
+			var results []walkForwardResult
+			for _, window := range windows {
+				results = append(results, walkForwardResult{
+					TrainStart: window.TrainStart.Format("2006-01-02"),
+					TestStart:  window.TestStart.Format("2006-01-02"),
+					TestEnd:    window.TestEnd.Format("2006-01-02"),
+					Train:      newOptimizeResult(1, window.Train),
+					Test:       newOptimizeResult(1, window.Test),
+				})
+			}
+
+			// the parameters of the latest window are the best for the live trading
+			best = []optimizer.Result{windows[len(windows)-1].Train}
+			output = results
+		} else {
+			ranked, err := gridOptimizer.Run(ctx, configContent)
+			if err != nil {
+				return err
+			}
+
+			ranked = optimizerConfig.TopResults(ranked)
+
+			var results []optimizeResult
+			for i, result := range ranked {
+				results = append(results, newOptimizeResult(i+1, result))
+			}
+
+			best = ranked
+			output = results
+		}
+
+		if len(outputDir) > 0 {
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return err
+			}
+
+			for i, result := range best {
+				content, err := optimizer.ApplyParams(configContent, result.Params)
+				if err != nil {
+					return err
+				}
+
+				if err := ioutil.WriteFile(filepath.Join(outputDir, fmt.Sprintf("best-%d.yaml", i+1)), content, 0644); err != nil {
+					return err
+				}
+			}
+		}
+
+		var w io.Writer = os.Stdout
+		if outputFile, err := cmd.Flags().GetString("output"); err == nil && len(outputFile) > 0 {
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+			w = f
+		}
+
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(output); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	},
+}
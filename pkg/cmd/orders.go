@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	OrdersCmd.Flags().String("strategy", "", "list the orders of the strategy ID, e.g., grid")
+	OrdersCmd.Flags().String("session", "", "list the orders of the session")
+	OrdersCmd.Flags().String("since", "", "list the orders since the given date (YYYY-MM-DD), defaults to 30 days ago")
+	OrdersCmd.Flags().Bool("working", false, "list the working orders only, the status is synced from the orders table")
+	RootCmd.AddCommand(OrdersCmd)
+}
+
+var OrdersCmd = &cobra.Command{
+	Use:          "orders",
+	Short:        "list the orders submitted by the strategies with their local IDs",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		strategyID, err := cmd.Flags().GetString("strategy")
+		if err != nil {
+			return err
+		}
+
+		sessionName, err := cmd.Flags().GetString("session")
+		if err != nil {
+			return err
+		}
+
+		workingOnly, err := cmd.Flags().GetBool("working")
+		if err != nil {
+			return err
+		}
+
+		since := time.Now().AddDate(0, 0, -30)
+		if s, err := cmd.Flags().GetString("since"); err == nil && len(s) > 0 {
+			since, err = time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return err
+			}
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
+		if err != nil {
+			return err
+		}
+
+		strategyOrderService := &service.StrategyOrderService{DB: db}
+		orders, err := strategyOrderService.Query(service.QueryStrategyOrdersOptions{
+			Strategy: strategyID,
+			Session:  sessionName,
+			Since:    since,
+		})
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SESSION\tLOCAL ID\tSTRATEGY\tORDER ID\tSYMBOL\tSIDE\tTYPE\tPRICE\tQUANTITY\tSTATUS\tCREATED AT")
+		for _, order := range orders {
+			if workingOnly && order.Status != types.OrderStatusNew && order.Status != types.OrderStatusPartiallyFilled {
+				continue
+			}
+
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%s\t%s\t%s\t%f\t%f\t%s\t%s\n",
+				order.Session, order.LocalID, order.Strategy, order.OrderID, order.Symbol, order.Side, order.Type,
+				order.Price, order.Quantity, order.Status, order.CreatedAt.Format(time.RFC3339))
+		}
+
+		return w.Flush()
+	},
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// pauseAPITimeout is the timeout of the pause request to the web server of the running bbgo
+const pauseAPITimeout = 10 * time.Second
+
+func init() {
+	PauseCmd.Flags().String("api", "", "the web server address of the running bbgo, e.g., http://localhost:8080, the persisted pause flag is updated directly if it's not given")
+	UnpauseCmd.Flags().String("api", "", "the web server address of the running bbgo, e.g., http://localhost:8080, the persisted pause flag is updated directly if it's not given")
+	RootCmd.AddCommand(PauseCmd)
+	RootCmd.AddCommand(UnpauseCmd)
+}
+
+var PauseCmd = &cobra.Command{
+	Use:   "pause [strategy name]",
+	Short: "block the orders of the strategy, the pause flag is persisted across restarts",
+	Args:  cobra.ExactArgs(1),
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStrategyPaused(cmd, args[0], true)
+	},
+}
+
+var UnpauseCmd = &cobra.Command{
+	Use:   "unpause [strategy name]",
+	Short: "clear the pause flag of the strategy",
+	Args:  cobra.ExactArgs(1),
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setStrategyPaused(cmd, args[0], false)
+	},
+}
+
+func setStrategyPaused(cmd *cobra.Command, name string, paused bool) error {
+	api, err := cmd.Flags().GetString("api")
+	if err != nil {
+		return err
+	}
+
+	if len(api) > 0 {
+		return requestStrategyPause(api, name, paused)
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+
+	if len(configFile) == 0 {
+		return errors.New("--config option is required")
+	}
+
+	userConfig, err := bbgo.Load(configFile, false)
+	if err != nil {
+		return err
+	}
+
+	if userConfig.Persistence == nil {
+		return errors.New("persistence is not configured, the pause flag can not be saved")
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := environ.ConfigurePersistence(userConfig.Persistence); err != nil {
+		return err
+	}
+
+	store := bbgo.NewPauseStore(environ.PersistenceServiceFacade)
+	if store == nil {
+		return errors.New("redis or json persistence is required for saving the pause flag")
+	}
+
+	if err := bbgo.SetPauseFlag(store, name, paused); err != nil {
+		return err
+	}
+
+	log.Infof("the pause flag of strategy %s is set to %v, it takes effect on the next start, use --api for the running bbgo", name, paused)
+	return nil
+}
+
+// requestStrategyPause pauses or unpauses the strategy through the web server API of the running bbgo
+func requestStrategyPause(api, name string, paused bool) error {
+	action := "unpause"
+	if paused {
+		action = "pause"
+	}
+
+	endpoint := fmt.Sprintf("%s/api/strategies/%s/%s", strings.TrimRight(api, "/"), url.PathEscape(name), action)
+
+	client := &http.Client{Timeout: pauseAPITimeout}
+	resp, err := client.Post(endpoint, "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Error  string `json:"error"`
+		State  string `json:"state"`
+		Paused bool   `json:"paused"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrapf(err, "unexpected response status %s", resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("can not %s strategy %s: %s", action, name, result.Error)
+	}
+
+	log.Infof("strategy %s is %sd, state: %s", name, action, result.State)
+	return nil
+}
@@ -2,17 +2,16 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/c9s/bbgo/pkg/accounting"
 	"github.com/c9s/bbgo/pkg/accounting/pnl"
-	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
@@ -21,6 +20,8 @@ import (
 func init() {
 	PnLCmd.Flags().String("exchange", "", "target exchange")
 	PnLCmd.Flags().String("symbol", "BTCUSDT", "trading symbol")
+	PnLCmd.Flags().String("since", "", "calculate the pnl of the trades since the date, e.g., 2021-01-01")
+	PnLCmd.Flags().String("until", "", "calculate the pnl of the trades until the date (exclusive), e.g., 2021-02-01")
 	RootCmd.AddCommand(PnLCmd)
 }
 
@@ -46,12 +47,62 @@ var PnLCmd = &cobra.Command{
 			return err
 		}
 
+		// default to all the synced trades
+		var since = time.Unix(0, 0)
+		var until = time.Now()
+
+		sinceStr, err := cmd.Flags().GetString("since")
+		if err != nil {
+			return err
+		}
+
+		untilStr, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		if len(sinceStr) > 0 || len(untilStr) > 0 {
+			loc, err := time.LoadLocation("Asia/Taipei")
+			if err != nil {
+				return err
+			}
+
+			if len(sinceStr) > 0 {
+				since, err = time.ParseInLocation("2006-01-02", sinceStr, loc)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(untilStr) > 0 {
+				until, err = time.ParseInLocation("2006-01-02", untilStr, loc)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
 		exchange, err := cmdutil.NewExchange(exchangeName)
 		if err != nil {
 			return err
 		}
 
-		db, err := bbgo.ConnectMySQL(viper.GetString("mysql-url"))
+		markets, err := exchange.QueryMarkets(ctx)
+		if err != nil {
+			return err
+		}
+
+		market, ok := markets[symbol]
+		if !ok {
+			return fmt.Errorf("market %s is not defined", symbol)
+		}
+
+		userConfig, err := loadUserConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		db, err := connectDatabase(userConfig)
 		if err != nil {
 			return err
 		}
@@ -62,12 +113,9 @@ var PnLCmd = &cobra.Command{
 		tradingFeeCurrency := exchange.PlatformFeeCurrency()
 		if strings.HasPrefix(symbol, tradingFeeCurrency) {
 			log.Infof("loading all trading fee currency related trades: %s", symbol)
-			trades, err = tradeService.QueryForTradingFeeCurrency(exchange.Name(), symbol, tradingFeeCurrency)
+			trades, err = tradeService.QueryForTradingFeeCurrencyByTimeRange(exchange.Name(), symbol, tradingFeeCurrency, since, until)
 		} else {
-			trades, err = tradeService.Query(service.QueryTradesOptions{
-				Exchange: exchange.Name(),
-				Symbol:   symbol,
-			})
+			trades, err = tradeService.QueryByTimeRange(exchange.Name(), symbol, since, until)
 		}
 
 		if err != nil {
@@ -89,19 +137,29 @@ var PnLCmd = &cobra.Command{
 		log.Infof("found checkpoints: %+v", checkpoints)
 		log.Infof("stock: %f", stockManager.Stocks.Quantity())
 
-		now := time.Now()
-		kLines, err := exchange.QueryKLines(ctx, symbol, types.Interval1m, types.KLineQueryOptions{
-			Limit:   100,
-			EndTime: &now,
-		})
+		currentPrice, err := queryLastPrice(ctx, exchange, symbol)
+		if err != nil {
+			return err
+		}
 
-		if len(kLines) == 0 {
-			return errors.New("no kline data for current price")
+		// the fees paid in the platform fee currency (e.g., BNB or MAX) are converted with its current price in the quote currency
+		feePrices := map[string]float64{}
+		if tradingFeeCurrency != market.BaseCurrency && tradingFeeCurrency != market.QuoteCurrency {
+			feeSymbol := tradingFeeCurrency + market.QuoteCurrency
+			if _, ok := markets[feeSymbol]; ok {
+				feePrice, err := queryLastPrice(ctx, exchange, feeSymbol)
+				if err != nil {
+					return err
+				}
+
+				feePrices[tradingFeeCurrency] = feePrice
+			}
 		}
 
-		currentPrice := kLines[len(kLines)-1].Close
 		calculator := &pnl.AverageCostCalculator{
 			TradingFeeCurrency: tradingFeeCurrency,
+			Market:             market,
+			FeePrices:          feePrices,
 		}
 
 		report := calculator.Calculate(symbol, trades, currentPrice)
@@ -109,3 +167,21 @@ var PnLCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// queryLastPrice returns the close price of the latest 1m kline of the symbol
+func queryLastPrice(ctx context.Context, exchange types.Exchange, symbol string) (float64, error) {
+	now := time.Now()
+	kLines, err := exchange.QueryKLines(ctx, symbol, types.Interval1m, types.KLineQueryOptions{
+		Limit:   100,
+		EndTime: &now,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(kLines) == 0 {
+		return 0, errors.Errorf("no kline data for the current price of %s", symbol)
+	}
+
+	return kLines[len(kLines)-1].Close, nil
+}
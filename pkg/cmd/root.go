@@ -38,6 +38,7 @@ func init() {
 	RootCmd.PersistentFlags().String("slack-token", "", "slack token")
 	RootCmd.PersistentFlags().String("slack-channel", "dev-bbgo", "slack trading channel")
 	RootCmd.PersistentFlags().String("slack-error-channel", "bbgo-error", "slack error channel")
+	RootCmd.PersistentFlags().String("slack-signing-secret", "", "slack app signing secret for verifying the interaction requests")
 
 	RootCmd.PersistentFlags().String("telegram-bot-token", "", "telegram bot token from bot father")
 	RootCmd.PersistentFlags().String("telegram-auth-token", "", "telegram auth token")
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"image/png"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,11 +25,13 @@ import (
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/cmd/cmdutil"
+	"github.com/c9s/bbgo/pkg/grpc"
 	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
 	"github.com/c9s/bbgo/pkg/notifier/telegramnotifier"
 	"github.com/c9s/bbgo/pkg/server"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/slack/slacklog"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
@@ -38,7 +41,13 @@ func init() {
 	RunCmd.Flags().String("totp-issuer", "", "")
 	RunCmd.Flags().String("totp-account-name", "", "")
 	RunCmd.Flags().Bool("enable-web-server", false, "enable web server")
+	RunCmd.Flags().Bool("enable-web", false, "enable the web dashboard and the api server, the same as --enable-web-server")
+	RunCmd.Flags().Bool("enable-grpc", false, "enable the grpc server for the external strategy control")
+	RunCmd.Flags().String("grpc-bind", grpc.DefaultBind, "the listen address of the grpc server")
+	RunCmd.Flags().Bool("watch-config", false, "watch the config file and reload the changed strategy configs without a restart")
 	RunCmd.Flags().Bool("setup", false, "use setup mode")
+	RunCmd.Flags().Bool("supervise", false, "run the strategies in a child process, and restart it with backoff when it crashes")
+	RunCmd.Flags().Duration("supervise-max-backoff", defaultSuperviseMaxBackoff, "the max backoff of restarting the crashed child process")
 
 	RunCmd.Flags().Bool("no-dotenv", false, "disable built-in dotenv")
 	RunCmd.Flags().String("dotenv", ".env.local", "the dotenv file you want to load")
@@ -87,19 +96,35 @@ func runSetup(baseCtx context.Context, userConfig *bbgo.Config, enableApiServer
 	cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	cancelTrading()
 
-	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
+	// the trading context is canceled, the shutdown context is derived from the background context so that
+	// the strategies can still cancel their orders before the deadline
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), bbgo.DefaultShutdownTimeout)
 
 	log.Infof("shutting down...")
 	trader.Graceful.Shutdown(shutdownCtx)
 	cancelShutdown()
+
+	if err := environ.SaveMarketDataSnapshots(); err != nil {
+		log.WithError(err).Errorf("can not save the market data snapshots")
+	}
 	return nil
 }
 
+// telegramInteraction is the telegram bot interaction set up by BootstrapEnvironment,
+// the trader is bound to it after the strategies are running so that the control commands can be used.
+var telegramInteraction *telegramnotifier.Interaction
+
 func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config) error {
-	if dsn, ok := os.LookupEnv("MYSQL_URL"); ok {
-		if err := environ.ConfigureDatabase(ctx, dsn); err != nil {
+	if userConfig.CurrencyEquivalence != nil {
+		if err := userConfig.CurrencyEquivalence.Validate(); err != nil {
 			return err
 		}
+
+		types.SetCurrencyEquivalence(userConfig.CurrencyEquivalence)
+	}
+
+	if err := configureDatabase(ctx, environ, userConfig); err != nil {
+		return err
 	}
 
 	if err := environ.AddExchangesFromConfig(userConfig); err != nil {
@@ -112,6 +137,12 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 		}
 	}
 
+	if userConfig.Service != nil {
+		if err := environ.ConfigureService(userConfig.Service); err != nil {
+			return err
+		}
+	}
+
 	notification := bbgo.Notifiability{
 		SymbolChannelRouter:  bbgo.NewPatternChannelRouter(nil),
 		SessionChannelRouter: bbgo.NewPatternChannelRouter(nil),
@@ -129,7 +160,15 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 
 			log.Infof("adding slack notifier with default channel: %s", conf.DefaultChannel)
 			var notifier = slacknotifier.New(slackToken, conf.DefaultChannel)
-			notification.AddNotifier(notifier)
+			if err := addReliableNotifier(ctx, &notification, userConfig.Notifications, "slack", notifier); err != nil {
+				return err
+			}
+
+			if confirmation := conf.Confirmation; confirmation != nil {
+				if err := setupSlackOrderConfirmer(environ, slackToken, confirmation); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -211,9 +250,12 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 		}
 
 		go interaction.Start(session)
+		telegramInteraction = interaction
 
 		var notifier = telegramnotifier.New(interaction)
-		notification.AddNotifier(notifier)
+		if err := addReliableNotifier(ctx, &notification, userConfig.Notifications, "telegram", notifier); err != nil {
+			return err
+		}
 	}
 
 	environ.Notifiability = notification
@@ -227,11 +269,74 @@ func BootstrapEnvironment(ctx context.Context, environ *bbgo.Environment, userCo
 	return nil
 }
 
+// addReliableNotifier adds the notifier to the notification,
+// the notifier is wrapped by the persistent notification queue if the queue is configured.
+func addReliableNotifier(ctx context.Context, notification *bbgo.Notifiability, conf *bbgo.NotificationConfig, name string, notifier bbgo.ReliableNotifier) error {
+	if conf == nil || conf.Queue == nil {
+		notification.AddNotifier(notifier)
+		return nil
+	}
+
+	file, err := bbgo.NotificationQueueFile(conf.Queue.Dir, name)
+	if err != nil {
+		return err
+	}
+
+	queued, err := bbgo.NewQueuedNotifier(notifier, file, conf.Queue.MaxMessages)
+	if err != nil {
+		return err
+	}
+
+	if queued.Len() > 0 {
+		log.Infof("found %d queued %s notifications, they will be replayed when %s is reachable", queued.Len(), name, name)
+	}
+
+	go queued.Run(ctx, conf.Queue.RetryInterval.Duration())
+
+	notification.AddNotifier(queued)
+	return nil
+}
+
+// setupSlackOrderConfirmer starts the slack interactivity endpoint and sets the order confirmer of the environment
+func setupSlackOrderConfirmer(environ *bbgo.Environment, slackToken string, conf *bbgo.SlackConfirmation) error {
+	signingSecret := viper.GetString("slack-signing-secret")
+	if len(signingSecret) == 0 {
+		return fmt.Errorf("slack order confirmation requires the slack signing secret, please set SLACK_SIGNING_SECRET")
+	}
+
+	if len(conf.Channel) == 0 || len(conf.Listen) == 0 {
+		return fmt.Errorf("slack order confirmation requires the channel and the listen address")
+	}
+
+	confirmer := slacknotifier.NewOrderConfirmer(slackToken, conf.Channel, signingSecret)
+
+	mux := http.NewServeMux()
+	mux.Handle("/slack/interaction", confirmer)
+
+	go func() {
+		log.Infof("slack interactivity endpoint is listening on %s", conf.Listen)
+		if err := http.ListenAndServe(conf.Listen, mux); err != nil {
+			log.WithError(err).Errorf("slack interactivity endpoint error")
+		}
+	}()
+
+	environ.OrderConfirmer = confirmer
+	return nil
+}
+
 func ConfigureTrader(trader *bbgo.Trader, userConfig *bbgo.Config) error {
 	if userConfig.RiskControls != nil {
 		trader.SetRiskControls(userConfig.RiskControls)
 	}
 
+	if userConfig.Logging != nil {
+		if err := userConfig.Logging.Validate(); err != nil {
+			return err
+		}
+
+		trader.SetLoggingConfig(userConfig.Logging)
+	}
+
 	for _, entry := range userConfig.ExchangeStrategies {
 		for _, mount := range entry.Mounts {
 			log.Infof("attaching strategy %T on %s...", entry.Strategy, mount)
@@ -244,6 +349,13 @@ func ConfigureTrader(trader *bbgo.Trader, userConfig *bbgo.Config) error {
 		trader.AttachCrossExchangeStrategy(strategy)
 	}
 
+	for _, lifecycle := range userConfig.StrategyLifecycles {
+		trader.DeclareStrategy(lifecycle.Name, lifecycle.Strategy, lifecycle.DependsOn...)
+		if err := trader.Lifecycle().SetSuspendOrderPolicy(lifecycle.Strategy, lifecycle.OnSuspend); err != nil {
+			return err
+		}
+	}
+
 	for _, report := range userConfig.PnLReporters {
 		if len(report.AverageCostBySymbols) > 0 {
 
@@ -261,7 +373,7 @@ func ConfigureTrader(trader *bbgo.Trader, userConfig *bbgo.Config) error {
 	return nil
 }
 
-func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer bool) error {
+func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer bool, grpcBind string, watchConfigFile string) error {
 	ctx, cancelTrading := context.WithCancel(basectx)
 	defer cancelTrading()
 
@@ -281,6 +393,15 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer
 		return err
 	}
 
+	// pull the trades and the orders missed by the user data stream periodically
+	if userConfig.Sync != nil && environ.TradeSync != nil {
+		environ.StartHistorySync(ctx, userConfig.Sync)
+	}
+
+	if telegramInteraction != nil {
+		telegramInteraction.SetTrader(trader)
+	}
+
 	if enableApiServer {
 		go func() {
 			s := &server.Server{
@@ -295,15 +416,44 @@ func runConfig(basectx context.Context, userConfig *bbgo.Config, enableApiServer
 		}()
 	}
 
+	// grpcBind is empty when the grpc server is not enabled
+	if len(grpcBind) > 0 {
+		go func() {
+			s := &grpc.Server{
+				Environ: environ,
+				Trader:  trader,
+			}
+
+			if err := s.Run(ctx, grpcBind); err != nil {
+				log.WithError(err).Errorf("grpc server error")
+			}
+		}()
+	}
+
+	if len(watchConfigFile) > 0 {
+		watcher := bbgo.NewConfigWatcher(watchConfigFile, userConfig, &environ.Notifiability)
+		go func() {
+			if err := watcher.Run(ctx); err != nil {
+				log.WithError(err).Errorf("config watcher error")
+			}
+		}()
+	}
+
 	cmdutil.WaitForSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 
 	cancelTrading()
 
-	shutdownCtx, cancelShutdown := context.WithDeadline(ctx, time.Now().Add(30*time.Second))
+	// the trading context is canceled, the shutdown context is derived from the background context so that
+	// the strategies can still cancel their orders before the deadline
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), bbgo.DefaultShutdownTimeout)
 
 	log.Infof("shutting down...")
 	trader.Graceful.Shutdown(shutdownCtx)
 	cancelShutdown()
+
+	if err := environ.SaveMarketDataSnapshots(); err != nil {
+		log.WithError(err).Errorf("can not save the market data snapshots")
+	}
 	return nil
 }
 
@@ -336,6 +486,31 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	enableWeb, err := cmd.Flags().GetBool("enable-web")
+	if err != nil {
+		return err
+	}
+
+	enableApiServer = enableApiServer || enableWeb
+
+	enableGrpc, err := cmd.Flags().GetBool("enable-grpc")
+	if err != nil {
+		return err
+	}
+
+	var grpcBind string
+	if enableGrpc {
+		grpcBind, err = cmd.Flags().GetString("grpc-bind")
+		if err != nil {
+			return err
+		}
+	}
+
+	watchConfig, err := cmd.Flags().GetBool("watch-config")
+	if err != nil {
+		return err
+	}
+
 	noCompile, err := cmd.Flags().GetBool("no-compile")
 	if err != nil {
 		return err
@@ -370,6 +545,21 @@ func run(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	supervise, err := cmd.Flags().GetBool("supervise")
+	if err != nil {
+		return err
+	}
+
+	// the supervisor runs the same command in the child process, and the child runs the strategies as usual
+	if supervise && !isSupervised() {
+		maxBackoff, err := cmd.Flags().GetDuration("supervise-max-backoff")
+		if err != nil {
+			return err
+		}
+
+		return runSupervisor(ctx, userConfig, maxBackoff)
+	}
+
 	// for wrapper binary, we can just run the strategies
 	if bbgo.IsWrapperBinary || (userConfig.Build != nil && len(userConfig.Build.Imports) == 0) || noCompile {
 		if bbgo.IsWrapperBinary {
@@ -385,7 +575,12 @@ func run(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		return runConfig(ctx, userConfig, enableApiServer)
+		var watchConfigFile string
+		if watchConfig {
+			watchConfigFile = configFile
+		}
+
+		return runConfig(ctx, userConfig, enableApiServer, grpcBind, watchConfigFile)
 	}
 
 	return runWrapperBinary(ctx, userConfig, cmd, args)
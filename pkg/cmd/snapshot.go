@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	AccountSnapshotCmd.Flags().String("session", "", "the session to query, all sessions are queried if it's not given")
+	AccountSnapshotCmd.Flags().StringSlice("symbol", nil, "the symbols to query the open orders")
+	AccountSnapshotCmd.Flags().Duration("timeout", bbgo.DefaultAccountSnapshotTimeout, "the query timeout, the sessions not responding in time are flagged")
+	AccountSnapshotCmd.Flags().Bool("json", false, "print the snapshot in JSON")
+	RootCmd.AddCommand(AccountSnapshotCmd)
+}
+
+var AccountSnapshotCmd = &cobra.Command{
+	Use:   "account-snapshot",
+	Short: "query the balances and the open orders of all sessions at the same time",
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		configFile, err := cmd.Flags().GetString("config")
+		if err != nil {
+			return err
+		}
+
+		if len(configFile) == 0 {
+			return errors.New("--config option is required")
+		}
+
+		symbols, err := cmd.Flags().GetStringSlice("symbol")
+		if err != nil {
+			return err
+		}
+
+		timeout, err := cmd.Flags().GetDuration("timeout")
+		if err != nil {
+			return err
+		}
+
+		printJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		userConfig, err := bbgo.Load(configFile, false)
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.AddExchangesFromConfig(userConfig); err != nil {
+			return err
+		}
+
+		var sessions = environ.Sessions()
+		if n, err := cmd.Flags().GetString("session"); err == nil && len(n) > 0 {
+			session, ok := sessions[n]
+			if !ok {
+				return fmt.Errorf("session %s not found", n)
+			}
+
+			sessions = map[string]*bbgo.ExchangeSession{n: session}
+		}
+
+		snapshot := bbgo.QueryAccountSnapshot(ctx, sessions, symbols, timeout)
+
+		if printJSON {
+			out, err := json.MarshalIndent(snapshot, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(out))
+		} else {
+			printAccountSnapshot(snapshot)
+		}
+
+		if snapshot.Partial {
+			fmt.Fprintln(os.Stderr, "WARNING: the snapshot is partial, some sessions failed")
+		}
+
+		return nil
+	},
+}
+
+func printAccountSnapshot(snapshot bbgo.AccountSnapshot) {
+	fmt.Printf("ACCOUNT SNAPSHOT AT %s\n", snapshot.Time.Format("2006-01-02 15:04:05.000"))
+
+	for _, s := range snapshot.Sessions {
+		fmt.Printf("\nSESSION %s (%s) in %s\n", s.Session, s.Exchange, s.Latency)
+		if len(s.Error) > 0 {
+			fmt.Printf("  ERROR: %s\n", s.Error)
+		}
+
+		var currencies []string
+		for currency, balance := range s.Balances {
+			if balance.Available > 0 || balance.Locked > 0 {
+				currencies = append(currencies, currency)
+			}
+		}
+		sort.Strings(currencies)
+
+		for _, currency := range currencies {
+			balance := s.Balances[currency]
+			fmt.Printf("  %-8s available %f locked %f\n", currency, balance.Available.Float64(), balance.Locked.Float64())
+		}
+
+		for _, order := range s.OpenOrders {
+			fmt.Printf("  %s\n", order.String())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
+)
+
+// supervisedEnvVar is set in the environment of the child process, so that the child runs the trading logic directly
+const supervisedEnvVar = "BBGO_SUPERVISED"
+
+const (
+	defaultSuperviseMinBackoff = time.Second
+	defaultSuperviseMaxBackoff = 5 * time.Minute
+
+	// superviseStableDuration resets the restart backoff if the child process has been running longer than it
+	superviseStableDuration = 10 * time.Minute
+
+	// maxCrashReportSize is the max size of the tail of the child stderr kept for the crash report
+	maxCrashReportSize = 16 * 1024
+)
+
+// isSupervised returns true if the current process is the child process of the supervisor
+func isSupervised() bool {
+	return os.Getenv(supervisedEnvVar) == "1"
+}
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	mu   sync.Mutex
+	buf  []byte
+	size int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.size {
+		b.buf = b.buf[len(b.buf)-b.size:]
+	}
+
+	return len(p), nil
+}
+
+func (b *tailBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf...)
+}
+
+// crashReport extracts the panic (or the fatal error) and the stack trace from the stderr tail,
+// the whole tail is returned if there is no panic found.
+func crashReport(output []byte) string {
+	for _, marker := range [][]byte{[]byte("panic: "), []byte("fatal error: ")} {
+		if i := bytes.LastIndex(output, append([]byte("\n"), marker...)); i >= 0 {
+			return string(bytes.TrimSpace(output[i:]))
+		} else if bytes.HasPrefix(output, marker) {
+			return string(bytes.TrimSpace(output))
+		}
+	}
+
+	return string(bytes.TrimSpace(output))
+}
+
+// supervisorNotifier returns the slack notifier for the crash reports, the telegram bot is left to the child process
+// since a bot can only be polled by one process.
+func supervisorNotifier(userConfig *bbgo.Config) bbgo.Notifier {
+	slackToken := viper.GetString("slack-token")
+	if len(slackToken) == 0 || userConfig.Notifications == nil || userConfig.Notifications.Slack == nil {
+		return &bbgo.NullNotifier{}
+	}
+
+	conf := userConfig.Notifications.Slack
+	channel := conf.ErrorChannel
+	if len(channel) == 0 {
+		channel = conf.DefaultChannel
+	}
+
+	return slacknotifier.New(slackToken, channel)
+}
+
+// runSupervisor runs the same command in a child process and restarts it with the exponential backoff when it crashes.
+// The child exits normally (e.g., after the shutdown signal) stops the supervisor. The persisted states are loaded
+// by the restarted child as usual, and the signals are forwarded to the child for the graceful shutdown.
+func runSupervisor(ctx context.Context, userConfig *bbgo.Config, maxBackoff time.Duration) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	notifier := supervisorNotifier(userConfig)
+
+	var sigC = make(chan os.Signal, 1)
+	signal.Notify(sigC, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigC)
+
+	backoff := defaultSuperviseMinBackoff
+	for restarts := 0; ; restarts++ {
+		output := &tailBuffer{size: maxCrashReportSize}
+
+		child := exec.Command(executable, os.Args[1:]...)
+		child.Env = append(os.Environ(), supervisedEnvVar+"=1")
+		child.Stdout = os.Stdout
+
+		// the child runs in its own process group, so that the terminal signals are only forwarded once by the supervisor
+		child.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		child.Stderr = io.MultiWriter(os.Stderr, output)
+
+		startTime := time.Now()
+		if err := child.Start(); err != nil {
+			return err
+		}
+
+		log.Infof("supervisor started the child process %d (restarts: %d)", child.Process.Pid, restarts)
+
+		var done = make(chan error, 1)
+		go func() {
+			done <- child.Wait()
+		}()
+
+		select {
+		case sig := <-sigC:
+			log.Infof("supervisor received %v, forwarding it to the child process...", sig)
+			if err := child.Process.Signal(sig); err != nil {
+				return err
+			}
+
+			return <-done
+
+		case <-ctx.Done():
+			_ = child.Process.Signal(syscall.SIGTERM)
+			return <-done
+
+		case err := <-done:
+			if err == nil {
+				log.Infof("child process exited normally, supervisor is stopping")
+				return nil
+			}
+
+			if time.Since(startTime) > superviseStableDuration {
+				backoff = defaultSuperviseMinBackoff
+			}
+
+			log.WithError(err).Errorf("child process crashed after %s, restarting in %s...", time.Since(startTime), backoff)
+			notifier.Notify("bbgo crashed (%v) after running %s, restarting in %s\n```\n%s\n```",
+				err, time.Since(startTime).Round(time.Second), backoff, crashReport(output.Bytes()))
+		}
+
+		select {
+		case <-sigC:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
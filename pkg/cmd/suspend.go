@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	SuspendCmd.Flags().String("api", "", "the web server address of the running bbgo, e.g., http://localhost:8080")
+	SuspendCmd.Flags().String("policy", "", "the open orders policy overriding the onSuspend config of the strategy: keep, cancel or cancelAndRestore")
+	ResumeCmd.Flags().String("api", "", "the web server address of the running bbgo, e.g., http://localhost:8080")
+	RootCmd.AddCommand(SuspendCmd)
+	RootCmd.AddCommand(ResumeCmd)
+}
+
+var SuspendCmd = &cobra.Command{
+	Use:   "suspend [strategy name]",
+	Short: "suspend the running strategy, the open orders are kept or canceled by the suspend order policy",
+	Args:  cobra.ExactArgs(1),
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policy, err := cmd.Flags().GetString("policy")
+		if err != nil {
+			return err
+		}
+
+		if err := bbgo.SuspendOrderPolicy(policy).Validate(); err != nil {
+			return err
+		}
+
+		return requestStrategyControl(cmd, args[0], "suspend", map[string]string{"policy": policy})
+	},
+}
+
+var ResumeCmd = &cobra.Command{
+	Use:   "resume [strategy name]",
+	Short: "resume the suspended strategy",
+	Args:  cobra.ExactArgs(1),
+
+	// SilenceUsage is an option to silence usage when an error occurs.
+	SilenceUsage: true,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return requestStrategyControl(cmd, args[0], "resume", nil)
+	},
+}
+
+// requestStrategyControl suspends or resumes the strategy through the web server API of the running bbgo
+func requestStrategyControl(cmd *cobra.Command, name, action string, payload interface{}) error {
+	api, err := cmd.Flags().GetString("api")
+	if err != nil {
+		return err
+	}
+
+	if len(api) == 0 {
+		return fmt.Errorf("--api is required to %s the strategy of the running bbgo", action)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/api/strategies/%s/%s", strings.TrimRight(api, "/"), url.PathEscape(name), action)
+
+	// the request waits for the strategy canceling or restoring its orders
+	client := &http.Client{Timeout: bbgo.DefaultSuspendTimeout + pauseAPITimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Error              string `json:"error"`
+		State              string `json:"state"`
+		SuspendOrderPolicy string `json:"suspendOrderPolicy"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrapf(err, "unexpected response status %s", resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("can not %s strategy %s: %s", action, name, result.Error)
+	}
+
+	log.Infof("strategy %s is %s, the open orders policy: %s", name, result.State, result.SuspendOrderPolicy)
+	return nil
+}
@@ -4,26 +4,29 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func init() {
 	SyncCmd.Flags().String("session", "", "the exchange session name for sync")
 	SyncCmd.Flags().String("symbol", "BTCUSDT", "trading symbol")
 	SyncCmd.Flags().String("since", "", "sync from time")
+	SyncCmd.Flags().Bool("klines", false, "sync the klines of the symbols defined in the sync config (or the --symbol option) instead of the trades and orders")
+	SyncCmd.Flags().Duration("watch", 0, "keep syncing the klines incrementally with the given period, e.g., --watch 1m")
 	RootCmd.AddCommand(SyncCmd)
 }
 
 var SyncCmd = &cobra.Command{
 	Use:          "sync",
-	Short:        "sync trades, orders",
+	Short:        "sync trades, orders, deposits, withdraws or klines",
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -53,17 +56,18 @@ var SyncCmd = &cobra.Command{
 
 		environ := bbgo.NewEnvironment()
 
-		if viper.IsSet("mysql-url") {
-			dsn := viper.GetString("mysql-url")
-			if err := environ.ConfigureDatabase(ctx, dsn); err != nil {
-				return err
-			}
+		if err := configureDatabase(ctx, environ, userConfig); err != nil {
+			return err
 		}
 
 		if err := environ.AddExchangesFromConfig(userConfig); err != nil {
 			return err
 		}
 
+		if len(since) == 0 && userConfig.Sync != nil {
+			since = userConfig.Sync.Since
+		}
+
 		var (
 			// default start time
 			startTime = time.Now().AddDate(0, -3, 0)
@@ -91,17 +95,71 @@ var SyncCmd = &cobra.Command{
 			return err
 		}
 
+		wantKLines, err := cmd.Flags().GetBool("klines")
+		if err != nil {
+			return err
+		}
+
+		if wantKLines {
+			if environ.BacktestService == nil {
+				return errors.New("database is not configured, please set the mysql-url option")
+			}
+
+			watch, err := cmd.Flags().GetDuration("watch")
+			if err != nil {
+				return err
+			}
+
+			sessions, symbols, intervals, err := klineSyncTargets(environ, userConfig.Sync, sessionName, symbol)
+			if err != nil {
+				return err
+			}
+
+			return syncKLines(ctx, environ, sessions, symbols, intervals, startTime, watch)
+		}
+
+		if environ.TradeSync == nil {
+			return errors.New("database is not configured, please set the mysql-url option")
+		}
+
+		// the symbols of the sync config are used if the symbol option is not given
+		var symbols = []string{symbol}
+		if !cmd.Flags().Changed("symbol") && userConfig.Sync != nil && len(userConfig.Sync.Symbols) > 0 {
+			symbols = userConfig.Sync.Symbols
+		}
+
 		if len(sessionName) > 0 {
 			session, ok := environ.Session(sessionName)
 			if !ok {
 				return fmt.Errorf("session %s not found", sessionName)
 			}
 
-			return syncSession(ctx, environ, session, symbol, startTime)
+			for _, symbol := range symbols {
+				if err := syncSession(ctx, environ, session, symbol, startTime); err != nil {
+					return err
+				}
+			}
+
+			if err := syncTransfers(ctx, environ, session, startTime); err != nil {
+				return err
+			}
+
+			return nil
 		}
 
 		for _, session := range environ.Sessions() {
-			if err := syncSession(ctx, environ, session, symbol, startTime); err != nil {
+			// the public only session has no trades to sync
+			if session.PublicOnly {
+				continue
+			}
+
+			for _, symbol := range symbols {
+				if err := syncSession(ctx, environ, session, symbol, startTime); err != nil {
+					return err
+				}
+			}
+
+			if err := syncTransfers(ctx, environ, session, startTime); err != nil {
 				return err
 			}
 		}
@@ -132,3 +190,93 @@ func syncSession(ctx context.Context, environ *bbgo.Environment, session *bbgo.E
 
 	return nil
 }
+
+// syncTransfers synchronizes the deposits and the withdraws of the session,
+// the sessions of the same exchange share the records by the unique index.
+func syncTransfers(ctx context.Context, environ *bbgo.Environment, session *bbgo.ExchangeSession, startTime time.Time) error {
+	log.Infof("syncing deposits and withdraws from exchange session %s...", session.Name)
+	return environ.TradeSync.SyncTransfers(ctx, session.Exchange, startTime)
+}
+
+// klineSyncTargets returns the sessions, the symbols and the intervals of the kline sync,
+// the session and the symbol options override the sync config.
+func klineSyncTargets(environ *bbgo.Environment, conf *bbgo.SyncConfig, sessionName, symbol string) (sessions []*bbgo.ExchangeSession, symbols []string, intervals []types.Interval, err error) {
+	if conf == nil {
+		conf = &bbgo.SyncConfig{}
+	}
+
+	var sessionNames = conf.Sessions
+	if len(sessionName) > 0 {
+		sessionNames = []string{sessionName}
+	}
+
+	if len(sessionNames) == 0 {
+		for name := range environ.Sessions() {
+			sessionNames = append(sessionNames, name)
+		}
+
+		sort.Strings(sessionNames)
+	}
+
+	for _, name := range sessionNames {
+		session, ok := environ.Session(name)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("session %s not found", name)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	symbols = conf.Symbols
+	if len(symbols) == 0 {
+		symbols = []string{symbol}
+	}
+
+	intervals = conf.KLineIntervals
+	if len(intervals) == 0 {
+		for interval := range types.SupportedIntervals {
+			intervals = append(intervals, interval)
+		}
+
+		sort.Slice(intervals, func(i, j int) bool {
+			return intervals[i].Minutes() < intervals[j].Minutes()
+		})
+	}
+
+	for _, interval := range intervals {
+		if _, ok := types.SupportedIntervals[interval]; !ok {
+			return nil, nil, nil, fmt.Errorf("kline interval %s is not supported", interval)
+		}
+	}
+
+	return sessions, symbols, intervals, nil
+}
+
+// syncKLines downloads the missing klines of the symbols into the database,
+// and repeats the sync with the watch period until the context is canceled if the watch period is given.
+func syncKLines(ctx context.Context, environ *bbgo.Environment, sessions []*bbgo.ExchangeSession, symbols []string, intervals []types.Interval, startTime time.Time, watch time.Duration) error {
+	for {
+		for _, session := range sessions {
+			for _, symbol := range symbols {
+				for _, interval := range intervals {
+					if err := environ.BacktestService.SyncKLines(ctx, session.Exchange, symbol, interval, startTime, time.Now()); err != nil {
+						return errors.Wrapf(err, "can not sync the %s %s klines from session %s", symbol, interval, session.Name)
+					}
+				}
+			}
+		}
+
+		if watch == 0 {
+			log.Infof("kline synchronization done")
+			return nil
+		}
+
+		log.Infof("kline synchronization done, next sync in %s", watch)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watch):
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const announcementBaseURL = "https://www.binance.com"
+
+// the catalog IDs of the binance support center
+const (
+	announcementCatalogNewListing = 48
+	announcementCatalogDelisting  = 161
+	announcementCatalogLatestNews = 49
+)
+
+var announcementHttpClient = &http.Client{Timeout: 15 * time.Second}
+
+type announcementArticle struct {
+	ID          int64  `json:"id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	ReleaseDate int64  `json:"releaseDate"`
+}
+
+type announcementResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Data    struct {
+		Articles []announcementArticle `json:"articles"`
+	} `json:"data"`
+}
+
+func queryAnnouncementArticles(ctx context.Context, catalogID int) ([]announcementArticle, error) {
+	url := fmt.Sprintf("%s/bapi/composite/v1/public/cms/article/catalog/list/query?catalogId=%d&pageNo=1&pageSize=20", announcementBaseURL, catalogID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := announcementHttpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected announcement response status: %s", resp.Status)
+	}
+
+	var apiResp announcementResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, err
+	}
+
+	return apiResp.Data.Articles, nil
+}
+
+// QueryAnnouncements queries the new listing, delisting and latest news (maintenance) announcements
+func (e *Exchange) QueryAnnouncements(ctx context.Context, since time.Time) (announcements []types.Announcement, err error) {
+	for _, catalogID := range []int{announcementCatalogNewListing, announcementCatalogDelisting, announcementCatalogLatestNews} {
+		articles, err := queryAnnouncementArticles(ctx, catalogID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, article := range articles {
+			releaseTime := time.Unix(0, article.ReleaseDate*int64(time.Millisecond))
+			if releaseTime.Before(since) {
+				continue
+			}
+
+			announcementType := types.ParseAnnouncementType(article.Title)
+			switch catalogID {
+			case announcementCatalogNewListing:
+				announcementType = types.AnnouncementTypeListing
+			case announcementCatalogDelisting:
+				announcementType = types.AnnouncementTypeDelisting
+			}
+
+			announcements = append(announcements, types.Announcement{
+				ID:         strconv.FormatInt(article.ID, 10),
+				Exchange:   types.ExchangeBinance,
+				Type:       announcementType,
+				Title:      article.Title,
+				URL:        announcementBaseURL + "/en/support/announcement/" + article.Code,
+				Currencies: types.ParseAnnouncementCurrencies(article.Title),
+				Time:       releaseTime,
+			})
+		}
+	}
+
+	return announcements, nil
+}
@@ -3,15 +3,21 @@ package binance
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/pkg/errors"
 
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
 )
 
+// futuresTimeInForceGTX is the "good till crossing" time in force of binance futures, i.e., the post only order
+const futuresTimeInForceGTX = futures.TimeInForceType("GTX")
+
 func toLocalOrderType(orderType types.OrderType) (binance.OrderType, error) {
 	switch orderType {
 	case types.OrderTypeLimit:
@@ -52,7 +58,8 @@ func ToGlobalOrder(binanceOrder *binance.Order, isMargin bool) (*types.Order, er
 			Type:          toGlobalOrderType(binanceOrder.Type),
 			Quantity:      util.MustParseFloat(binanceOrder.OrigQuantity),
 			Price:         util.MustParseFloat(binanceOrder.Price),
-			TimeInForce:   string(binanceOrder.TimeInForce),
+			TimeInForce:   types.TimeInForce(binanceOrder.TimeInForce),
+			PostOnly:      binanceOrder.Type == binance.OrderTypeLimitMaker,
 		},
 		Exchange:         types.ExchangeBinance.String(),
 		IsWorking:        binanceOrder.IsWorking,
@@ -191,3 +198,176 @@ func ConvertTrades(remoteTrades []*binance.TradeV3) (trades []types.Trade, err e
 
 	return trades, err
 }
+
+func toLocalFuturesOrderType(orderType types.OrderType) (futures.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		return futures.OrderTypeLimit, nil
+
+	case types.OrderTypeStopLimit:
+		return futures.OrderTypeStop, nil
+
+	case types.OrderTypeStopMarket:
+		return futures.OrderTypeStopMarket, nil
+
+	case types.OrderTypeMarket:
+		return futures.OrderTypeMarket, nil
+	}
+
+	return "", fmt.Errorf("futures order type %s not supported", orderType)
+}
+
+func toGlobalFuturesOrderType(orderType futures.OrderType) types.OrderType {
+	switch orderType {
+
+	case futures.OrderTypeLimit, futures.OrderTypeTakeProfit:
+		return types.OrderTypeLimit
+
+	case futures.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case futures.OrderTypeStop:
+		return types.OrderTypeStopLimit
+
+	case futures.OrderTypeStopMarket, futures.OrderTypeTakeProfitMarket:
+		return types.OrderTypeStopMarket
+
+	default:
+		log.Errorf("unsupported futures order type: %v", orderType)
+		return ""
+	}
+}
+
+func toGlobalFuturesOrders(futuresOrders []*futures.Order) (orders []types.Order, err error) {
+	for _, futuresOrder := range futuresOrders {
+		order, err := toGlobalFuturesOrder(futuresOrder)
+		if err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, *order)
+	}
+
+	return orders, err
+}
+
+func toGlobalFuturesOrder(futuresOrder *futures.Order) (*types.Order, error) {
+	status := toGlobalOrderStatus(binance.OrderStatusType(futuresOrder.Status))
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: futuresOrder.ClientOrderID,
+			Symbol:        futuresOrder.Symbol,
+			Side:          toGlobalSideType(binance.SideType(futuresOrder.Side)),
+			Type:          toGlobalFuturesOrderType(futuresOrder.Type),
+			Quantity:      util.MustParseFloat(futuresOrder.OrigQuantity),
+			Price:         util.MustParseFloat(futuresOrder.Price),
+			TimeInForce:   types.TimeInForce(futuresOrder.TimeInForce),
+			PostOnly:      futuresOrder.TimeInForce == futuresTimeInForceGTX,
+			ReduceOnly:    futuresOrder.ReduceOnly,
+		},
+		Exchange:         types.ExchangeBinance.String(),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		OrderID:          uint64(futuresOrder.OrderID),
+		Status:           status,
+		ExecutedQuantity: util.MustParseFloat(futuresOrder.ExecutedQuantity),
+		CreationTime:     millisecondTime(futuresOrder.Time),
+		UpdateTime:       millisecondTime(futuresOrder.UpdateTime),
+	}, nil
+}
+
+func toGlobalFuturesTrade(t *futures.AccountTrade) (*types.Trade, error) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "price parse error, price: %+v", t.Price)
+	}
+
+	quantity, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quantity parse error, quantity: %+v", t.Quantity)
+	}
+
+	quoteQuantity, err := strconv.ParseFloat(t.QuoteQuantity, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "quote quantity parse error, quoteQuantity: %+v", t.QuoteQuantity)
+	}
+
+	fee, err := strconv.ParseFloat(t.Commission, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "commission parse error, commission: %+v", t.Commission)
+	}
+
+	return &types.Trade{
+		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
+		Price:         price,
+		Symbol:        t.Symbol,
+		Exchange:      "binance",
+		Quantity:      quantity,
+		Side:          toGlobalSideType(binance.SideType(t.Side)),
+		IsBuyer:       t.Buyer,
+		IsMaker:       t.Maker,
+		Fee:           fee,
+		FeeCurrency:   t.CommissionAsset,
+		QuoteQuantity: quoteQuantity,
+		Time:          millisecondTime(t.Time),
+	}, nil
+}
+
+func toGlobalFuturesPosition(risk *futures.PositionRisk) (*types.FuturesPosition, error) {
+	leverage, err := strconv.Atoi(risk.Leverage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "leverage parse error, leverage: %+v", risk.Leverage)
+	}
+
+	var values = map[string]fixedpoint.Value{}
+	for name, s := range map[string]string{
+		"positionAmt":      risk.PositionAmt,
+		"entryPrice":       risk.EntryPrice,
+		"markPrice":        risk.MarkPrice,
+		"liquidationPrice": risk.LiquidationPrice,
+		"unRealizedProfit": risk.UnRealizedProfit,
+		"isolatedMargin":   risk.IsolatedMargin,
+	} {
+		if len(s) == 0 {
+			continue
+		}
+
+		v, err := fixedpoint.NewFromString(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s parse error, %s: %+v", name, name, s)
+		}
+
+		values[name] = v
+	}
+
+	return &types.FuturesPosition{
+		Symbol:           risk.Symbol,
+		PositionSide:     toGlobalPositionSide(risk.PositionSide),
+		MarginType:       toGlobalMarginType(risk.MarginType),
+		Leverage:         leverage,
+		Quantity:         values["positionAmt"],
+		EntryPrice:       values["entryPrice"],
+		MarkPrice:        values["markPrice"],
+		LiquidationPrice: values["liquidationPrice"],
+		UnrealizedProfit: values["unRealizedProfit"],
+		IsolatedMargin:   values["isolatedMargin"],
+	}, nil
+}
+
+// toGlobalMarginType converts the margin type of the futures API, it's in lower case in the position risk and the user data stream,
+// e.g., "isolated" and "cross", but in upper case in the margin call event.
+func toGlobalMarginType(marginType string) types.MarginType {
+	if strings.EqualFold(marginType, string(types.MarginTypeIsolated)) {
+		return types.MarginTypeIsolated
+	}
+
+	return types.MarginTypeCrossed
+}
+
+func toGlobalPositionSide(positionSide string) types.PositionSide {
+	if len(positionSide) == 0 {
+		return types.PositionSideBoth
+	}
+
+	return types.PositionSide(positionSide)
+}
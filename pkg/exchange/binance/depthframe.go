@@ -2,11 +2,13 @@ package binance
 
 import (
 	"context"
-	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 //go:generate callbackgen -type DepthFrame
@@ -57,10 +59,11 @@ func (f *DepthFrame) loadDepthSnapshot() {
 		return
 	}
 
-	// filter the events by the event IDs
+	// filter the events by the event IDs, the events older than the snapshot are dropped,
+	// the first kept event may overlap the snapshot
 	var events []DepthEvent
 	for _, e := range f.BufEvents {
-		if e.FirstUpdateID <= depth.FinalUpdateID || e.FinalUpdateID <= depth.FinalUpdateID {
+		if e.FinalUpdateID <= depth.FinalUpdateID {
 			continue
 		}
 
@@ -82,11 +85,18 @@ func (f *DepthFrame) loadDepthSnapshot() {
 		}
 	}
 
+	snapshot := *depth
+
+	// the buffered events are emitted with the snapshot, so the next event continues from the last buffered event
+	if len(events) > 0 {
+		depth.FinalUpdateID = events[len(events)-1].FinalUpdateID
+	}
+
 	f.SnapshotDepth = depth
 	f.BufEvents = nil
 	f.mu.Unlock()
 
-	f.EmitReady(*depth, events)
+	f.EmitReady(snapshot, events)
 }
 
 func (f *DepthFrame) PushEvent(e DepthEvent) {
@@ -106,7 +116,7 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 				log.Infof("starting depth snapshot updater for %s market", f.Symbol)
 			}
 
-			ticker := time.NewTicker(30*time.Minute + time.Duration(rand.Intn(10))*time.Millisecond)
+			ticker := time.NewTicker(30*time.Minute + time.Duration(util.RandIntn(10))*time.Millisecond)
 			defer ticker.Stop()
 			for {
 				select {
@@ -121,8 +131,8 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 	} else {
 		// if we have the snapshot, we could use that final update ID filter the events
 
-		// drop any update ID < the final update ID
-		if e.FinalUpdateID < f.SnapshotDepth.FinalUpdateID {
+		// drop any update ID <= the final update ID
+		if e.FinalUpdateID <= f.SnapshotDepth.FinalUpdateID {
 			if debugBinanceDepth {
 				log.Warnf("event final update id %d < depth final update id %d, skip", e.FinalUpdateID, f.SnapshotDepth.FinalUpdateID)
 			}
@@ -132,7 +142,8 @@ func (f *DepthFrame) PushEvent(e DepthEvent) {
 		}
 
 		// if the first update ID > final update ID + 1, it means something is missing, we need to reload.
-		if e.FirstUpdateID > f.SnapshotDepth.FinalUpdateID+1 {
+		// the futures events are chained by the previous update ID instead.
+		if e.FirstUpdateID > f.SnapshotDepth.FinalUpdateID+1 && e.PreviousUpdateID != f.SnapshotDepth.FinalUpdateID {
 			if debugBinanceDepth {
 				log.Warnf("event first update id %d > final update id + 1 (%d), resetting snapshot", e.FirstUpdateID, f.SnapshotDepth.FirstUpdateID+1)
 			}
@@ -167,11 +178,31 @@ func (f *DepthFrame) fetch(ctx context.Context) (*DepthEvent, error) {
 	}
 
 	for _, entry := range response.Bids {
-		event.Bids = append(event.Bids, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		price, err := fixedpoint.NewFromString(entry.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		quantity, err := fixedpoint.NewFromString(entry.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Bids = append(event.Bids, DepthEntry{PriceLevel: price, Quantity: quantity})
 	}
 
 	for _, entry := range response.Asks {
-		event.Asks = append(event.Asks, DepthEntry{PriceLevel: entry.Price, Quantity: entry.Quantity})
+		price, err := fixedpoint.NewFromString(entry.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		quantity, err := fixedpoint.NewFromString(entry.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Asks = append(event.Asks, DepthEntry{PriceLevel: price, Quantity: quantity})
 	}
 
 	return &event, nil
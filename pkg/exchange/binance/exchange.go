@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
@@ -25,6 +26,13 @@ var log = logrus.WithFields(logrus.Fields{
 func init() {
 	_ = types.Exchange(&Exchange{})
 	_ = types.MarginExchange(&Exchange{})
+	_ = types.FuturesExchange(&Exchange{})
+	_ = types.TransferExchange(&Exchange{})
+	_ = types.MarginBorrowRepayService(&Exchange{})
+	_ = types.MarginBalanceService(&Exchange{})
+	_ = types.OCOExchange(&Exchange{})
+	_ = types.SubAccountTransferExchange(&Exchange{})
+	_ = types.ExchangeFeeService(&Exchange{})
 
 	if ok, _ := strconv.ParseBool(os.Getenv("DEBUG_BINANCE_STREAM")); ok {
 		log.Level = logrus.DebugLevel
@@ -33,14 +41,19 @@ func init() {
 
 type Exchange struct {
 	types.MarginSettings
+	types.FuturesSettings
 
 	Client *binance.Client
+
+	// FuturesClient is used for the USDT-M futures api when the futures mode is enabled
+	FuturesClient *futures.Client
 }
 
 func New(key, secret string) *Exchange {
 	var client = binance.NewClient(key, secret)
 	return &Exchange{
-		Client: client,
+		Client:        client,
+		FuturesClient: futures.NewClient(key, secret),
 	}
 }
 
@@ -48,9 +61,29 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBinance
 }
 
+func (e *Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(
+		types.CapabilityMargin,
+		types.CapabilityIsolatedMargin,
+		types.CapabilityMarginBorrowRepay,
+		types.CapabilityFutures,
+		types.CapabilityUserDataStream,
+		types.CapabilityMultiAssetCollateral,
+		types.CapabilityTransfer,
+		types.CapabilitySubAccountTransfer,
+		types.CapabilityOCO,
+		types.CapabilityPostOnly,
+		types.CapabilityStopOrder,
+	)
+}
+
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	log.Info("querying market info...")
 
+	if e.IsFutures {
+		return e.queryFuturesMarkets(ctx)
+	}
+
 	exchangeInfo, err := e.Client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -80,7 +113,8 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 			market.MinLot = util.MustParseFloat(f.MinQuantity)
 			market.MinQuantity = util.MustParseFloat(f.MinQuantity)
 			market.MaxQuantity = util.MustParseFloat(f.MaxQuantity)
-			// market.StepSize = util.MustParseFloat(f.StepSize)
+			// binance uses minQty as the min lot, the quantity increment is defined by the stepSize separately
+			market.StepSize = util.MustParseFloat(f.StepSize)
 		}
 
 		if f := symbol.PriceFilter(); f != nil {
@@ -107,6 +141,8 @@ func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float6
 func (e *Exchange) NewStream() types.Stream {
 	stream := NewStream(e.Client)
 	stream.MarginSettings = e.MarginSettings
+	stream.FuturesSettings = e.FuturesSettings
+	stream.FuturesClient = e.FuturesClient
 	return stream
 }
 
@@ -123,6 +159,44 @@ func (e *Exchange) QueryIsolatedMarginAccount(ctx context.Context, symbols ...st
 	return req.Do(ctx)
 }
 
+// QueryCollateralBalances queries the cross margin account balances with the borrowed amount,
+// all the assets in the cross margin account are counted as the collateral.
+func (e *Exchange) QueryCollateralBalances(ctx context.Context) (types.BalanceMap, error) {
+	account, err := e.QueryMarginAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := types.BalanceMap{}
+	for _, userAsset := range account.UserAssets {
+		borrowed := fixedpoint.Must(fixedpoint.NewFromString(userAsset.Borrowed))
+		interest := fixedpoint.Must(fixedpoint.NewFromString(userAsset.Interest))
+		balances[userAsset.Asset] = types.Balance{
+			Currency:  userAsset.Asset,
+			Available: fixedpoint.Must(fixedpoint.NewFromString(userAsset.Free)),
+			Locked:    fixedpoint.Must(fixedpoint.NewFromString(userAsset.Locked)),
+			Borrowed:  borrowed + interest,
+		}
+	}
+
+	return balances, nil
+}
+
+// QueryIndexPrices queries the margin price index of the given symbols, the index prices are used to value the collateral
+func (e *Exchange) QueryIndexPrices(ctx context.Context, symbols ...string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(symbols))
+	for _, symbol := range symbols {
+		index, err := e.Client.NewGetMarginPriceIndexService().Symbol(symbol).Do(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "can not query the price index of %s", symbol)
+		}
+
+		prices[symbol] = util.MustParseFloat(index.Price)
+	}
+
+	return prices, nil
+}
+
 func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
 
 	startTime := since
@@ -177,6 +251,7 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 
 			txIDs[d.TxID] = struct{}{}
 			allWithdraws = append(allWithdraws, types.Withdraw{
+				Exchange:        types.ExchangeBinance,
 				ApplyTime:       time.Unix(0, d.ApplyTime*int64(time.Millisecond)),
 				Asset:           d.Asset,
 				Amount:          d.Amount,
@@ -241,6 +316,7 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 
 			txIDs[d.TxID] = struct{}{}
 			allDeposits = append(allDeposits, types.Deposit{
+				Exchange:      types.ExchangeBinance,
 				Time:          time.Unix(0, d.InsertTime*int64(time.Millisecond)),
 				Asset:         d.Asset,
 				Amount:        d.Amount,
@@ -272,6 +348,14 @@ func (e *Exchange) PlatformFeeCurrency() string {
 }
 
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsFutures {
+		return e.queryFuturesAccount(ctx)
+	}
+
+	if e.IsMargin {
+		return e.queryMarginAccount(ctx)
+	}
+
 	account, err := e.Client.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return nil, err
@@ -294,7 +378,23 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 	return a, nil
 }
 
+// queryMarginAccount returns the account with the balances of the margin account, the borrowed amounts are included
+func (e *Exchange) queryMarginAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryMarginBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{}
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if e.IsFutures {
+		return e.queryFuturesOpenOrders(ctx, symbol)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOpenOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -322,6 +422,10 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 	log.Infof("querying closed orders %s from %s <=> %s ...", symbol, since, until)
 
+	if e.IsFutures {
+		return e.queryFuturesClosedOrders(ctx, symbol, since, until, lastOrderID)
+	}
+
 	if e.IsMargin {
 		req := e.Client.NewListMarginOrdersService().Symbol(symbol)
 		req.IsIsolated(e.IsIsolatedMargin)
@@ -361,6 +465,15 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
 	for _, o := range orders {
+		if e.IsFutures {
+			if err := e.cancelFuturesOrder(ctx, o); err != nil {
+				log.WithError(err).Errorf("futures order cancel error")
+				err2 = err
+			}
+
+			continue
+		}
+
 		var req = e.Client.NewCancelOrderService()
 
 		// Mandatory
@@ -369,7 +482,7 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 		if o.OrderID > 0 {
 			req.OrderID(int64(o.OrderID))
 		} else if len(o.ClientOrderID) > 0 {
-			req.NewClientOrderID(o.ClientOrderID)
+			req.OrigClientOrderID(o.ClientOrderID)
 		}
 
 		_, err := req.Do(ctx)
@@ -388,6 +501,11 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 		return nil, err
 	}
 
+	// the post only order is the LIMIT_MAKER order, which is rejected if it would immediately match
+	if order.PostOnly {
+		orderType = binance.OrderTypeLimitMaker
+	}
+
 	clientOrderID := uuid.New().String()
 	if len(order.ClientOrderID) > 0 {
 		clientOrderID = order.ClientOrderID
@@ -435,9 +553,8 @@ func (e *Exchange) submitMarginOrder(ctx context.Context, order types.SubmitOrde
 		req.StopPrice(order.StopPriceString)
 	}
 
-	// could be IOC or FOK
-	if len(order.TimeInForce) > 0 {
-		// TODO: check the TimeInForce value
+	// could be IOC or FOK, the LIMIT_MAKER order doesn't accept the time in force
+	if len(order.TimeInForce) > 0 && !order.PostOnly {
 		req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
 	}
 
@@ -474,6 +591,11 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		return nil, err
 	}
 
+	// the post only order is the LIMIT_MAKER order, which is rejected if it would immediately match
+	if order.PostOnly {
+		orderType = binance.OrderTypeLimitMaker
+	}
+
 	clientOrderID := uuid.New().String()
 	if len(order.ClientOrderID) > 0 {
 		clientOrderID = order.ClientOrderID
@@ -500,8 +622,8 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		req.StopPrice(order.StopPriceString)
 	}
 
-	if len(order.TimeInForce) > 0 {
-		// TODO: check the TimeInForce value
+	// the LIMIT_MAKER order doesn't accept the time in force
+	if len(order.TimeInForce) > 0 && !order.PostOnly {
 		req.TimeInForce(binance.TimeInForceType(order.TimeInForce))
 	}
 
@@ -540,7 +662,9 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 	for _, order := range orders {
 		var createdOrder *types.Order
 
-		if e.IsMargin {
+		if e.IsFutures {
+			createdOrder, err = e.submitFuturesOrder(ctx, order)
+		} else if e.IsMargin {
 			createdOrder, err = e.submitMarginOrder(ctx, order)
 		} else {
 			createdOrder, err = e.submitSpotOrder(ctx, order)
@@ -562,6 +686,9 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 
 // QueryKLines queries the Kline/candlestick bars for a symbol. Klines are uniquely identified by their open time.
 func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	if e.IsFutures {
+		return e.queryFuturesKLines(ctx, symbol, interval, options)
+	}
 
 	var limit = 500
 	if options.Limit > 0 {
@@ -612,6 +739,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	if e.IsFutures {
+		return e.queryFuturesTrades(ctx, symbol, options)
+	}
+
 	var remoteTrades []*binance.TradeV3
 
 	if e.IsMargin {
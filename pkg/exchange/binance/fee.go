@@ -0,0 +1,104 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// bnbFeeDiscountRate is the discount of the spot trading fees paid with BNB
+const bnbFeeDiscountRate = 0.25
+
+type tradeFeeResponse struct {
+	Symbol          string `json:"symbol"`
+	MakerCommission string `json:"makerCommission"`
+	TakerCommission string `json:"takerCommission"`
+}
+
+type bnbBurnResponse struct {
+	SpotBNBBurn     bool `json:"spotBNBBurn"`
+	InterestBNBBurn bool `json:"interestBNBBurn"`
+}
+
+// QueryAccountFees returns the spot fee rates of the account and whether the fees are paid with BNB for the discount
+func (e *Exchange) QueryAccountFees(ctx context.Context) (*types.AccountFees, error) {
+	if e.IsFutures {
+		return nil, errors.New("querying the account fees of the futures account is not supported")
+	}
+
+	account, err := e.Client.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+
+	var burn bnbBurnResponse
+	if err := e.doSignedRequest(ctx, http.MethodGet, "/sapi/v1/bnbBurn", params, &burn); err != nil {
+		return nil, err
+	}
+
+	// the commissions are in basis points
+	return &types.AccountFees{
+		MakerFeeRate:     fixedpoint.NewFromFloat(float64(account.MakerCommission) * 0.0001),
+		TakerFeeRate:     fixedpoint.NewFromFloat(float64(account.TakerCommission) * 0.0001),
+		DiscountCurrency: "BNB",
+		DiscountRate:     fixedpoint.NewFromFloat(bnbFeeDiscountRate),
+		DiscountEnabled:  burn.SpotBNBBurn,
+	}, nil
+}
+
+// QueryTradingFeeRates returns the spot trading fee rates of the symbols, the rates reflect the VIP tier of the account
+func (e *Exchange) QueryTradingFeeRates(ctx context.Context, symbols ...string) (types.TradingFeeRateMap, error) {
+	if e.IsFutures {
+		return nil, errors.New("querying the trading fee rates of the futures account is not supported")
+	}
+
+	params := url.Values{}
+	if len(symbols) == 1 {
+		params.Set("symbol", symbols[0])
+	}
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+
+	var fees []tradeFeeResponse
+	if err := e.doSignedRequest(ctx, http.MethodGet, "/sapi/v1/asset/tradeFee", params, &fees); err != nil {
+		return nil, err
+	}
+
+	var wanted = make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = struct{}{}
+	}
+
+	rates := make(types.TradingFeeRateMap)
+	for _, fee := range fees {
+		if _, ok := wanted[fee.Symbol]; len(wanted) > 0 && !ok {
+			continue
+		}
+
+		makerFeeRate, err := fixedpoint.NewFromString(fee.MakerCommission)
+		if err != nil {
+			return nil, err
+		}
+
+		takerFeeRate, err := fixedpoint.NewFromString(fee.TakerCommission)
+		if err != nil {
+			return nil, err
+		}
+
+		rates[fee.Symbol] = types.TradingFeeRate{
+			Symbol:       fee.Symbol,
+			MakerFeeRate: makerFeeRate,
+			TakerFeeRate: takerFeeRate,
+		}
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,387 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/google/uuid"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// errCodeNoNeedToChangeMarginType is returned when the symbol is already in the requested margin type
+const errCodeNoNeedToChangeMarginType = -4046
+
+func (e *Exchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	resp, err := e.FuturesClient.NewChangeLeverageService().
+		Symbol(symbol).
+		Leverage(leverage).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("futures leverage of %s is set to %dx", resp.Symbol, resp.Leverage)
+	return nil
+}
+
+func (e *Exchange) SetMarginType(ctx context.Context, symbol string, marginType types.MarginType) error {
+	err := e.FuturesClient.NewChangeMarginTypeService().
+		Symbol(symbol).
+		MarginType(futures.MarginType(marginType)).
+		Do(ctx)
+
+	if apiErr, ok := err.(*common.APIError); ok && apiErr.Code == errCodeNoNeedToChangeMarginType {
+		return nil
+	}
+
+	return err
+}
+
+func (e *Exchange) QueryPositions(ctx context.Context, symbols ...string) ([]types.FuturesPosition, error) {
+	// the position risk API returns the positions of all symbols, they are filtered below
+	risks, err := e.FuturesClient.NewGetPositionRiskService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter = map[string]struct{}{}
+	for _, symbol := range symbols {
+		filter[symbol] = struct{}{}
+	}
+
+	var positions []types.FuturesPosition
+	for _, risk := range risks {
+		if len(filter) > 0 {
+			if _, ok := filter[risk.Symbol]; !ok {
+				continue
+			}
+		}
+
+		position, err := toGlobalFuturesPosition(risk)
+		if err != nil {
+			return nil, err
+		}
+
+		// skip the empty positions of the symbols that are not traded
+		if position.Quantity == 0 {
+			continue
+		}
+
+		positions = append(positions, *position)
+	}
+
+	return positions, nil
+}
+
+func (e *Exchange) QueryFundingRate(ctx context.Context, symbol string) (*types.FundingRate, error) {
+	index, err := e.FuturesClient.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if index.Symbol != symbol {
+		return nil, fmt.Errorf("funding rate of %s not found", symbol)
+	}
+
+	rate, err := fixedpoint.NewFromString(index.LastFundingRate)
+	if err != nil {
+		return nil, err
+	}
+
+	markPrice, err := fixedpoint.NewFromString(index.MarkPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FundingRate{
+		Symbol:          index.Symbol,
+		FundingRate:     rate,
+		MarkPrice:       markPrice,
+		NextFundingTime: millisecondTime(index.NextFundingTime),
+		Time:            millisecondTime(index.Time),
+	}, nil
+}
+
+func (e *Exchange) queryFuturesMarkets(ctx context.Context) (types.MarketMap, error) {
+	exchangeInfo, err := e.FuturesClient.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, symbol := range exchangeInfo.Symbols {
+		market := types.Market{
+			Symbol:          symbol.Symbol,
+			PricePrecision:  symbol.PricePrecision,
+			VolumePrecision: symbol.QuantityPrecision,
+			QuoteCurrency:   symbol.QuoteAsset,
+			BaseCurrency:    symbol.BaseAsset,
+		}
+
+		for _, filter := range symbol.Filters {
+			switch filter["filterType"] {
+			case "LOT_SIZE":
+				market.MinLot = filterFloat(filter, "minQty")
+				market.MinQuantity = filterFloat(filter, "minQty")
+				market.MaxQuantity = filterFloat(filter, "maxQty")
+				market.StepSize = filterFloat(filter, "stepSize")
+
+			case "PRICE_FILTER":
+				market.MaxPrice = filterFloat(filter, "maxPrice")
+				market.MinPrice = filterFloat(filter, "minPrice")
+				market.TickSize = filterFloat(filter, "tickSize")
+
+			case "MIN_NOTIONAL":
+				market.MinNotional = filterFloat(filter, "notional")
+				market.MinAmount = filterFloat(filter, "notional")
+			}
+		}
+
+		markets[symbol.Symbol] = market
+	}
+
+	return markets, nil
+}
+
+// filterFloat parses the numeric string field of the exchange info filter
+func filterFloat(filter map[string]interface{}, key string) float64 {
+	s, ok := filter[key].(string)
+	if !ok {
+		return 0
+	}
+
+	return util.MustParseFloat(s)
+}
+
+func (e *Exchange) queryFuturesAccount(ctx context.Context) (*types.Account, error) {
+	futuresBalances, err := e.FuturesClient.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = map[string]types.Balance{}
+	for _, b := range futuresBalances {
+		balance := fixedpoint.Must(fixedpoint.NewFromString(b.Balance))
+		available := fixedpoint.Must(fixedpoint.NewFromString(b.AvailableBalance))
+		balances[b.Asset] = types.Balance{
+			Currency:  b.Asset,
+			Available: available,
+			Locked:    balance - available,
+		}
+	}
+
+	a := &types.Account{}
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+func (e *Exchange) queryFuturesOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	futuresOrders, err := e.FuturesClient.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	return toGlobalFuturesOrders(futuresOrders)
+}
+
+func (e *Exchange) queryFuturesClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	req := e.FuturesClient.NewListOrdersService().Symbol(symbol)
+
+	if lastOrderID > 0 {
+		req.OrderID(int64(lastOrderID))
+	} else {
+		req.StartTime(since.UnixNano() / int64(time.Millisecond)).
+			EndTime(until.UnixNano() / int64(time.Millisecond))
+	}
+
+	futuresOrders, err := req.Do(ctx)
+	if err != nil {
+		return orders, err
+	}
+
+	return toGlobalFuturesOrders(futuresOrders)
+}
+
+func (e *Exchange) cancelFuturesOrder(ctx context.Context, o types.Order) error {
+	req := e.FuturesClient.NewCancelOrderService().Symbol(o.Symbol)
+
+	if o.OrderID > 0 {
+		req.OrderID(int64(o.OrderID))
+	} else if len(o.ClientOrderID) > 0 {
+		req.OrigClientOrderID(o.ClientOrderID)
+	}
+
+	_, err := req.Do(ctx)
+	return err
+}
+
+func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	orderType, err := toLocalFuturesOrderType(order.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOrderID := uuid.New().String()
+	if len(order.ClientOrderID) > 0 {
+		clientOrderID = order.ClientOrderID
+	}
+
+	req := e.FuturesClient.NewCreateOrderService().
+		Symbol(order.Symbol).
+		Type(orderType).
+		Side(futures.SideType(order.Side)).
+		NewClientOrderID(clientOrderID)
+
+	if order.ReduceOnly {
+		req.ReduceOnly(true)
+	}
+
+	if len(order.QuantityString) > 0 {
+		req.Quantity(order.QuantityString)
+	} else if order.Market.Symbol != "" {
+		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+	} else {
+		req.Quantity(strconv.FormatFloat(order.Quantity, 'f', 8, 64))
+	}
+
+	switch order.Type {
+	case types.OrderTypeLimit, types.OrderTypeStopLimit:
+		if len(order.PriceString) > 0 {
+			req.Price(order.PriceString)
+		} else if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price))
+		} else {
+			req.Price(strconv.FormatFloat(order.Price, 'f', 8, 64))
+		}
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if len(order.StopPriceString) == 0 {
+			return nil, fmt.Errorf("stop price string can not be empty")
+		}
+
+		req.StopPrice(order.StopPriceString)
+	}
+
+	// the limit orders of binance futures require the time in force, the post only order is GTX (good till crossing)
+	if order.PostOnly {
+		req.TimeInForce(futuresTimeInForceGTX)
+	} else if len(order.TimeInForce) > 0 {
+		req.TimeInForce(futures.TimeInForceType(order.TimeInForce))
+	} else if order.Type == types.OrderTypeLimit || order.Type == types.OrderTypeStopLimit {
+		req.TimeInForce(futures.TimeInForceTypeGTC)
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("futures order creation response: %+v", response)
+
+	return toGlobalFuturesOrder(&futures.Order{
+		Symbol:           response.Symbol,
+		OrderID:          response.OrderID,
+		ClientOrderID:    response.ClientOrderID,
+		Price:            response.Price,
+		ReduceOnly:       response.ReduceOnly,
+		OrigQuantity:     response.OrigQuantity,
+		ExecutedQuantity: response.ExecutedQuantity,
+		Status:           response.Status,
+		TimeInForce:      response.TimeInForce,
+		Type:             response.Type,
+		Side:             response.Side,
+		StopPrice:        response.StopPrice,
+		Time:             response.UpdateTime,
+		UpdateTime:       response.UpdateTime,
+	})
+}
+
+func (e *Exchange) queryFuturesTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	req := e.FuturesClient.NewListAccountTradeService().Symbol(symbol)
+
+	if options.Limit > 0 {
+		req.Limit(int(options.Limit))
+	}
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+	if options.LastTradeID > 0 {
+		req.FromID(options.LastTradeID)
+	}
+
+	remoteTrades, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range remoteTrades {
+		localTrade, err := toGlobalFuturesTrade(t)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert binance futures trade: %+v", t)
+			continue
+		}
+
+		trades = append(trades, *localTrade)
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) queryFuturesKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	var limit = 500
+	if options.Limit > 0 {
+		limit = options.Limit
+	}
+
+	req := e.FuturesClient.NewKlinesService().
+		Symbol(symbol).
+		Interval(string(interval)).
+		Limit(limit)
+
+	if options.StartTime != nil {
+		req.StartTime(options.StartTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	if options.EndTime != nil {
+		req.EndTime(options.EndTime.UnixNano() / int64(time.Millisecond))
+	}
+
+	resp, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, k := range resp {
+		kLines = append(kLines, types.KLine{
+			Exchange:       "binance",
+			Symbol:         symbol,
+			Interval:       interval,
+			StartTime:      time.Unix(0, k.OpenTime*int64(time.Millisecond)),
+			EndTime:        time.Unix(0, k.CloseTime*int64(time.Millisecond)),
+			Open:           util.MustParseFloat(k.Open),
+			Close:          util.MustParseFloat(k.Close),
+			High:           util.MustParseFloat(k.High),
+			Low:            util.MustParseFloat(k.Low),
+			Volume:         util.MustParseFloat(k.Volume),
+			QuoteVolume:    util.MustParseFloat(k.QuoteAssetVolume),
+			NumberOfTrades: uint64(k.TradeNum),
+			Closed:         true,
+		})
+	}
+
+	return kLines, nil
+}
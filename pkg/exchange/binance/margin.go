@@ -0,0 +1,87 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/adshao/go-binance/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BorrowMarginAsset borrows the asset in the cross margin account,
+// the isolated margin account is not supported since the loan api does not accept the isolated symbol.
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if e.IsIsolatedMargin {
+		return fmt.Errorf("borrowing in the isolated margin account is not supported")
+	}
+
+	resp, err := e.Client.NewMarginLoanService().
+		Asset(asset).
+		Amount(strconv.FormatFloat(amount.Float64(), 'f', -1, 64)).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("borrowed %f %s, transaction id %d", amount.Float64(), asset, resp.TranID)
+	return nil
+}
+
+// RepayMarginAsset repays the borrowed asset of the cross margin account
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if e.IsIsolatedMargin {
+		return fmt.Errorf("repaying in the isolated margin account is not supported")
+	}
+
+	resp, err := e.Client.NewMarginRepayService().
+		Asset(asset).
+		Amount(strconv.FormatFloat(amount.Float64(), 'f', -1, 64)).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("repaid %f %s, transaction id %d", amount.Float64(), asset, resp.TranID)
+	return nil
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	resp, err := e.Client.NewGetMaxBorrowableService().Asset(asset).Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return fixedpoint.NewFromString(resp.Amount)
+}
+
+// QueryMarginBalances queries the balances of the cross margin account,
+// or the balances of the isolated margin symbol if the isolated margin is used.
+func (e *Exchange) QueryMarginBalances(ctx context.Context) (types.BalanceMap, error) {
+	if !e.IsIsolatedMargin {
+		return e.QueryCollateralBalances(ctx)
+	}
+
+	account, err := e.QueryIsolatedMarginAccount(ctx, e.IsolatedMarginSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := types.BalanceMap{}
+	for _, asset := range account.Assets {
+		for _, userAsset := range []binance.IsolatedUserAsset{asset.BaseAsset, asset.QuoteAsset} {
+			borrowed := fixedpoint.Must(fixedpoint.NewFromString(userAsset.Borrowed))
+			interest := fixedpoint.Must(fixedpoint.NewFromString(userAsset.Interest))
+			balances[userAsset.Asset] = types.Balance{
+				Currency:  userAsset.Asset,
+				Available: fixedpoint.Must(fixedpoint.NewFromString(userAsset.Free)),
+				Locked:    fixedpoint.Must(fixedpoint.NewFromString(userAsset.Locked)),
+				Borrowed:  borrowed + interest,
+			}
+		}
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,89 @@
+package binance
+
+import (
+	"context"
+	"errors"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/google/uuid"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SubmitOCOOrder submits the native oco order list, the stop-loss leg is a STOP_LOSS_LIMIT order if the stop limit price is set,
+// otherwise it's a STOP_LOSS order. The oco orders of the margin and the futures accounts are not supported.
+//
+// The list client order id can't be set with the binance client, so the client order id of the order list
+// is used for the take-profit order, and the stop-loss order gets its own client order id.
+func (e *Exchange) SubmitOCOOrder(ctx context.Context, order types.SubmitOCOOrder) (*types.OCOOrder, error) {
+	if e.IsMargin || e.IsFutures {
+		return nil, errors.New("binance oco orders are only supported in the spot account")
+	}
+
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	clientOrderID := uuid.New().String()
+	if len(order.ClientOrderID) > 0 {
+		clientOrderID = order.ClientOrderID
+	}
+
+	response, err := e.newCreateOCOService(order, clientOrderID, uuid.New().String()).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("oco order creation response: %+v", response)
+
+	ocoOrder := &types.OCOOrder{
+		ClientOrderID: response.ListClientOrderID,
+		OrderListID:   uint64(response.OrderListID),
+		Symbol:        response.Symbol,
+	}
+
+	for _, report := range response.OrderReports {
+		createdOrder, err := ToGlobalOrder(&binance.Order{
+			Symbol:                   report.Symbol,
+			OrderID:                  report.OrderID,
+			ClientOrderID:            report.ClientOrderID,
+			Price:                    report.Price,
+			OrigQuantity:             report.OrigQuantity,
+			ExecutedQuantity:         report.ExecutedQuantity,
+			CummulativeQuoteQuantity: report.CummulativeQuoteQuantity,
+			Status:                   report.Status,
+			TimeInForce:              report.TimeInForce,
+			Type:                     report.Type,
+			Side:                     report.Side,
+			StopPrice:                report.StopPrice,
+			Time:                     report.TransactionTime,
+			UpdateTime:               report.TransactionTime,
+			IsWorking:                true,
+		}, false)
+		if err != nil {
+			return ocoOrder, err
+		}
+
+		ocoOrder.Orders = append(ocoOrder.Orders, *createdOrder)
+	}
+
+	return ocoOrder, nil
+}
+
+func (e *Exchange) newCreateOCOService(order types.SubmitOCOOrder, limitClientOrderID, stopClientOrderID string) *binance.CreateOCOService {
+	req := e.Client.NewCreateOCOService().
+		Symbol(order.Symbol).
+		Side(binance.SideType(order.Side)).
+		Quantity(order.Market.FormatQuantity(order.Quantity)).
+		Price(order.Market.FormatPrice(order.Price)).
+		StopPrice(order.Market.FormatPrice(order.StopPrice)).
+		LimitClientOrderID(limitClientOrderID).
+		StopClientOrderID(stopClientOrderID)
+
+	if order.StopLimitPrice > 0 {
+		req.StopLimitPrice(order.Market.FormatPrice(order.StopLimitPrice)).
+			StopLimitTimeInForce(binance.TimeInForceTypeGTC)
+	}
+
+	return req
+}
@@ -0,0 +1,112 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchange_SubmitOCOOrder(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/order/oco", r.URL.Path)
+		assert.NoError(t, r.ParseForm())
+		form = r.PostForm
+
+		_, _ = w.Write([]byte(`{
+		  "orderListId": 1,
+		  "contingencyType": "OCO",
+		  "listStatusType": "EXEC_STARTED",
+		  "listOrderStatus": "EXECUTING",
+		  "listClientOrderId": "JYVpp3F0f5CAG15DhtrqLp",
+		  "transactionTime": 1563417480525,
+		  "symbol": "BTCUSDT",
+		  "orderReports": [
+		    {
+		      "symbol": "BTCUSDT",
+		      "orderId": 2,
+		      "orderListId": 1,
+		      "clientOrderId": "stop",
+		      "transactTime": 1563417480525,
+		      "price": "9000.00",
+		      "origQty": "0.1234",
+		      "executedQty": "0.0000",
+		      "cummulativeQuoteQty": "0.00",
+		      "status": "NEW",
+		      "timeInForce": "GTC",
+		      "type": "STOP_LOSS_LIMIT",
+		      "side": "SELL",
+		      "stopPrice": "9100.00"
+		    },
+		    {
+		      "symbol": "BTCUSDT",
+		      "orderId": 3,
+		      "orderListId": 1,
+		      "clientOrderId": "take-profit",
+		      "transactTime": 1563417480525,
+		      "price": "11000.00",
+		      "origQty": "0.1234",
+		      "executedQty": "0.0000",
+		      "cummulativeQuoteQty": "0.00",
+		      "status": "NEW",
+		      "timeInForce": "GTC",
+		      "type": "LIMIT_MAKER",
+		      "side": "SELL"
+		    }
+		  ]
+		}`))
+	}))
+	defer server.Close()
+
+	e := New("key", "secret")
+	e.Client.BaseURL = server.URL
+
+	ocoOrder, err := e.SubmitOCOOrder(context.Background(), types.SubmitOCOOrder{
+		ClientOrderID:  "take-profit",
+		Symbol:         "BTCUSDT",
+		Side:           types.SideTypeSell,
+		Quantity:       0.12345,
+		Price:          11000.0,
+		StopPrice:      9100.0,
+		StopLimitPrice: 9000.0,
+		Market:         types.Market{Symbol: "BTCUSDT", MinLot: 0.0001, MinPrice: 0.01},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "BTCUSDT", form.Get("symbol"))
+	assert.Equal(t, "SELL", form.Get("side"))
+	assert.Equal(t, "0.1234", form.Get("quantity"))
+	assert.Equal(t, "11000.00", form.Get("price"))
+	assert.Equal(t, "9100.00", form.Get("stopPrice"))
+	assert.Equal(t, "9000.00", form.Get("stopLimitPrice"))
+	assert.Equal(t, "GTC", form.Get("stopLimitTimeInForce"))
+	assert.Equal(t, "take-profit", form.Get("limitClientOrderId"))
+	assert.NotEmpty(t, form.Get("stopClientOrderId"))
+	assert.NotEqual(t, form.Get("limitClientOrderId"), form.Get("stopClientOrderId"))
+
+	assert.Equal(t, "JYVpp3F0f5CAG15DhtrqLp", ocoOrder.ClientOrderID)
+	assert.Equal(t, uint64(1), ocoOrder.OrderListID)
+	assert.Len(t, ocoOrder.Orders, 2)
+}
+
+func TestExchange_SubmitOCOOrder_margin(t *testing.T) {
+	e := New("key", "secret")
+	e.IsMargin = true
+
+	_, err := e.SubmitOCOOrder(context.Background(), types.SubmitOCOOrder{
+		Symbol:    "BTCUSDT",
+		Side:      types.SideTypeSell,
+		Quantity:  0.1,
+		Price:     11000.0,
+		StopPrice: 9100.0,
+	})
+	assert.Error(t, err)
+}
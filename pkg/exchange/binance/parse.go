@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/valyala/fastjson"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -53,6 +54,8 @@ executionReport
   "Q": "0.00000000"              // Quote Order Qty
 }
 */
+// ExecutionReportEvent is the spot and margin order update, the keys that only differ in case are all declared
+// since encoding/json matches the keys case-insensitively, e.g., "Q" would overwrite "q" if it's not declared.
 type ExecutionReportEvent struct {
 	EventBase
 
@@ -62,12 +65,19 @@ type ExecutionReportEvent struct {
 	OrderType     string `json:"o"`
 	TimeInForce   string `json:"f"`
 
-	OrderQuantity string `json:"q"`
-	OrderPrice    string `json:"p"`
-	StopPrice     string `json:"P"`
+	// OriginalClientOrderID is the client order ID of the canceled order,
+	// the ClientOrderID of the cancel update is the client order ID of the cancel request.
+	OriginalClientOrderID string `json:"C"`
+
+	OrderQuantity      string `json:"q"`
+	OrderPrice         string `json:"p"`
+	StopPrice          string `json:"P"`
+	IcebergQuantity    string `json:"F"`
+	QuoteOrderQuantity string `json:"Q"`
 
 	IsOnBook bool `json:"w"`
 	IsMaker  bool `json:"m"`
+	Ignore   bool `json:"M"`
 
 	CommissionAmount string `json:"n"`
 	CommissionAsset  string `json:"N"`
@@ -86,6 +96,8 @@ type ExecutionReportEvent struct {
 	LastExecutedPrice                string `json:"L"`
 	LastQuoteAssetTransactedQuantity string `json:"Y"`
 
+	CumulativeQuoteAssetTransactedQuantity string `json:"Z"`
+
 	OrderCreationTime int64 `json:"O"`
 }
 
@@ -98,16 +110,22 @@ func (e *ExecutionReportEvent) Order() (*types.Order, error) {
 		return nil, errors.New("execution report type is not for order")
 	}
 
+	clientOrderID := e.ClientOrderID
+	if e.CurrentExecutionType == "CANCELED" && len(e.OriginalClientOrderID) > 0 {
+		clientOrderID = e.OriginalClientOrderID
+	}
+
 	orderCreationTime := time.Unix(0, e.OrderCreationTime*int64(time.Millisecond))
 	return &types.Order{
 		SubmitOrder: types.SubmitOrder{
 			Symbol:        e.Symbol,
-			ClientOrderID: e.ClientOrderID,
+			ClientOrderID: clientOrderID,
 			Side:          toGlobalSideType(binance.SideType(e.Side)),
 			Type:          toGlobalOrderType(binance.OrderType(e.OrderType)),
 			Quantity:      util.MustParseFloat(e.OrderQuantity),
 			Price:         util.MustParseFloat(e.OrderPrice),
-			TimeInForce:   e.TimeInForce,
+			TimeInForce:   types.TimeInForce(e.TimeInForce),
+			PostOnly:      binance.OrderType(e.OrderType) == binance.OrderTypeLimitMaker,
 		},
 		OrderID:          uint64(e.OrderID),
 		Status:           toGlobalOrderStatus(binance.OrderStatusType(e.CurrentOrderStatus)),
@@ -236,13 +254,292 @@ type OutboundAccountInfoEvent struct {
 	Permissions []string  `json:"P,omitempty"`
 }
 
+/*
+
+ORDER_TRADE_UPDATE (futures)
+
+{
+  "e": "ORDER_TRADE_UPDATE",     // Event Type
+  "E": 1568879465651,            // Event Time
+  "T": 1568879465650,            // Transaction Time
+  "o": {
+    "s": "BTCUSDT",              // Symbol
+    "c": "TEST",                 // Client Order Id
+    "S": "SELL",                 // Side
+    "o": "LIMIT",                // Order Type
+    "f": "GTC",                  // Time in Force
+    "q": "0.001",                // Original Quantity
+    "p": "9000",                 // Original Price
+    "ap": "0",                   // Average Price
+    "sp": "0",                   // Stop Price
+    "x": "NEW",                  // Execution Type
+    "X": "NEW",                  // Order Status
+    "i": 8886774,                // Order Id
+    "l": "0",                    // Order Last Filled Quantity
+    "z": "0",                    // Order Filled Accumulated Quantity
+    "L": "0",                    // Last Filled Price
+    "N": "USDT",                 // Commission Asset
+    "n": "0",                    // Commission
+    "T": 1568879465651,          // Order Trade Time
+    "t": 0,                      // Trade Id
+    "m": false,                  // Is this trade the maker side?
+    "R": false,                  // Is this reduce only
+    "ps": "BOTH",                // Position Side
+    "rp": "0"                    // Realized Profit of the trade
+  }
+}
+
+*/
+type OrderTradeUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64        `json:"T"`
+	Order           FuturesOrder `json:"o"`
+}
+
+// FuturesOrder is the order payload of the futures order update,
+// the keys that only differ in case are all declared since encoding/json matches the keys case-insensitively.
+type FuturesOrder struct {
+	Symbol        string `json:"s"`
+	ClientOrderID string `json:"c"`
+	Side          string `json:"S"`
+	OrderType     string `json:"o"`
+	TimeInForce   string `json:"f"`
+
+	OrderQuantity string `json:"q"`
+	OrderPrice    string `json:"p"`
+	AveragePrice  string `json:"ap"`
+	StopPrice     string `json:"sp"`
+	ActivatePrice string `json:"AP"`
+
+	CurrentExecutionType string `json:"x"`
+	CurrentOrderStatus   string `json:"X"`
+
+	OrderID int64 `json:"i"`
+
+	LastExecutedQuantity     string `json:"l"`
+	CumulativeFilledQuantity string `json:"z"`
+	LastExecutedPrice        string `json:"L"`
+
+	CommissionAsset  string `json:"N"`
+	CommissionAmount string `json:"n"`
+
+	OrderTradeTime int64 `json:"T"`
+	TradeID        int64 `json:"t"`
+
+	IsMaker      bool   `json:"m"`
+	IsReduceOnly bool   `json:"R"`
+	PositionSide string `json:"ps"`
+	RealizedPnL  string `json:"rp"`
+}
+
+func (e *OrderTradeUpdateEvent) OrderFromEvent() (*types.Order, error) {
+	o := e.Order
+	status := toGlobalOrderStatus(binance.OrderStatusType(o.CurrentOrderStatus))
+	return &types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:        o.Symbol,
+			ClientOrderID: o.ClientOrderID,
+			Side:          toGlobalSideType(binance.SideType(o.Side)),
+			Type:          toGlobalFuturesOrderType(futures.OrderType(o.OrderType)),
+			Quantity:      util.MustParseFloat(o.OrderQuantity),
+			Price:         util.MustParseFloat(o.OrderPrice),
+			TimeInForce:   types.TimeInForce(o.TimeInForce),
+			PostOnly:      o.TimeInForce == string(futuresTimeInForceGTX),
+			ReduceOnly:    o.IsReduceOnly,
+		},
+		Exchange:         types.ExchangeBinance.String(),
+		OrderID:          uint64(o.OrderID),
+		Status:           status,
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		ExecutedQuantity: util.MustParseFloat(o.CumulativeFilledQuantity),
+		UpdateTime:       millisecondTime(o.OrderTradeTime),
+	}, nil
+}
+
+func (e *OrderTradeUpdateEvent) TradeFromEvent() (*types.Trade, error) {
+	o := e.Order
+	if o.CurrentExecutionType != "TRADE" {
+		return nil, errors.New("futures order update is not a trade")
+	}
+
+	price := util.MustParseFloat(o.LastExecutedPrice)
+	quantity := util.MustParseFloat(o.LastExecutedQuantity)
+	return &types.Trade{
+		ID:            o.TradeID,
+		Exchange:      types.ExchangeBinance.String(),
+		Symbol:        o.Symbol,
+		OrderID:       uint64(o.OrderID),
+		Side:          toGlobalSideType(binance.SideType(o.Side)),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		IsBuyer:       o.Side == "BUY",
+		IsMaker:       o.IsMaker,
+		Time:          millisecondTime(o.OrderTradeTime),
+		Fee:           util.MustParseFloat(o.CommissionAmount),
+		FeeCurrency:   o.CommissionAsset,
+	}, nil
+}
+
+/*
+
+ACCOUNT_UPDATE (futures)
+
+{
+  "e": "ACCOUNT_UPDATE",         // Event Type
+  "E": 1564745798939,            // Event Time
+  "T": 1564745798938,            // Transaction
+  "a": {
+    "m": "ORDER",                // Event reason type
+    "B": [                       // Balances
+      {
+        "a": "USDT",             // Asset
+        "wb": "122624.12345678", // Wallet Balance
+        "cw": "100.12345678"     // Cross Wallet Balance
+      }
+    ],
+    "P": [                       // Positions
+      {
+        "s": "BTCUSDT",          // Symbol
+        "pa": "0",               // Position Amount
+        "ep": "0.00000",         // Entry Price
+        "cr": "200",             // (Pre-fee) Accumulated Realized
+        "up": "0",               // Unrealized PnL
+        "mt": "isolated",        // Margin Type
+        "iw": "0.00000000",      // Isolated Wallet (if isolated position)
+        "ps": "BOTH"             // Position Side
+      }
+    ]
+  }
+}
+
+*/
+type AccountUpdateEvent struct {
+	EventBase
+
+	TransactionTime int64 `json:"T"`
+
+	AccountUpdate struct {
+		EventReasonType string `json:"m"`
+
+		Balances []struct {
+			Asset              string `json:"a"`
+			WalletBalance      string `json:"wb"`
+			CrossWalletBalance string `json:"cw"`
+		} `json:"B"`
+
+		Positions []struct {
+			Symbol                 string `json:"s"`
+			PositionAmount         string `json:"pa"`
+			EntryPrice             string `json:"ep"`
+			AccumulatedRealizedPnL string `json:"cr"`
+			UnrealizedPnL          string `json:"up"`
+			MarginType             string `json:"mt"`
+			IsolatedWallet         string `json:"iw"`
+			PositionSide           string `json:"ps"`
+		} `json:"P"`
+	} `json:"a"`
+}
+
+// Positions converts the position updates of the event, the position amount is negative for the short position in the one-way mode
+func (e *AccountUpdateEvent) Positions() []types.FuturesPosition {
+	var positions []types.FuturesPosition
+	for _, p := range e.AccountUpdate.Positions {
+		positions = append(positions, types.FuturesPosition{
+			Symbol:           p.Symbol,
+			PositionSide:     toGlobalPositionSide(p.PositionSide),
+			MarginType:       toGlobalMarginType(p.MarginType),
+			Quantity:         fixedpoint.NewFromFloat(util.MustParseFloat(p.PositionAmount)),
+			EntryPrice:       fixedpoint.NewFromFloat(util.MustParseFloat(p.EntryPrice)),
+			UnrealizedProfit: fixedpoint.NewFromFloat(util.MustParseFloat(p.UnrealizedPnL)),
+			IsolatedMargin:   fixedpoint.NewFromFloat(util.MustParseFloat(p.IsolatedWallet)),
+		})
+	}
+
+	return positions
+}
+
+/*
+
+MARGIN_CALL (futures)
+
+{
+  "e": "MARGIN_CALL",            // Event Type
+  "E": 1587727187525,            // Event Time
+  "cw": "3.16812045",            // Cross Wallet Balance. Only pushed with crossed position margin call
+  "p": [                         // Position(s) of Margin Call
+    {
+      "s": "ETHUSDT",            // Symbol
+      "ps": "LONG",              // Position Side
+      "pa": "1.327",             // Position Amount
+      "mt": "CROSSED",           // Margin Type
+      "iw": "0",                 // Isolated Wallet (if isolated position)
+      "mp": "187.17127",         // Mark Price
+      "up": "-1.166074",         // Unrealized PnL
+      "mm": "1.614445"           // Maintenance Margin Required
+    }
+  ]
+}
+
+*/
+type MarginCallEvent struct {
+	EventBase
+
+	CrossWalletBalance string `json:"cw"`
+
+	Positions []struct {
+		Symbol            string `json:"s"`
+		PositionSide      string `json:"ps"`
+		PositionAmount    string `json:"pa"`
+		MarginType        string `json:"mt"`
+		IsolatedWallet    string `json:"iw"`
+		MarkPrice         string `json:"mp"`
+		UnrealizedPnL     string `json:"up"`
+		MaintenanceMargin string `json:"mm"`
+	} `json:"p"`
+}
+
+// MarginCalls converts the positions of the margin call event, the cross wallet balance is zero for the isolated positions
+func (e *MarginCallEvent) MarginCalls() []types.MarginCall {
+	var crossWalletBalance fixedpoint.Value
+	if len(e.CrossWalletBalance) > 0 {
+		crossWalletBalance = fixedpoint.NewFromFloat(util.MustParseFloat(e.CrossWalletBalance))
+	}
+
+	var marginCalls []types.MarginCall
+	for _, p := range e.Positions {
+		marginCalls = append(marginCalls, types.MarginCall{
+			Symbol:             p.Symbol,
+			PositionSide:       toGlobalPositionSide(p.PositionSide),
+			MarginType:         toGlobalMarginType(p.MarginType),
+			Quantity:           fixedpoint.NewFromFloat(util.MustParseFloat(p.PositionAmount)),
+			MarkPrice:          fixedpoint.NewFromFloat(util.MustParseFloat(p.MarkPrice)),
+			UnrealizedProfit:   fixedpoint.NewFromFloat(util.MustParseFloat(p.UnrealizedPnL)),
+			IsolatedMargin:     fixedpoint.NewFromFloat(util.MustParseFloat(p.IsolatedWallet)),
+			MaintenanceMargin:  fixedpoint.NewFromFloat(util.MustParseFloat(p.MaintenanceMargin)),
+			CrossWalletBalance: crossWalletBalance,
+			Time:               e.EventTime(),
+		})
+	}
+
+	return marginCalls
+}
+
 type ResultEvent struct {
 	Result interface{} `json:"result,omitempty"`
 	ID     int         `json:"id"`
 }
 
+// parserPool reuses the fastjson parsers across the websocket messages, the parsed values refer to the
+// buffer of the parser, hence the events must be fully decoded before the parser is put back to the pool.
+var parserPool fastjson.ParserPool
+
 func ParseEvent(message string) (interface{}, error) {
-	val, err := fastjson.Parse(message)
+	parser := parserPool.Get()
+	defer parserPool.Put(parser)
+
+	val, err := parser.Parse(message)
 	if err != nil {
 		return nil, err
 	}
@@ -251,9 +548,7 @@ func ParseEvent(message string) (interface{}, error) {
 
 	switch eventType {
 	case "kline":
-		var event KLineEvent
-		err := json.Unmarshal([]byte(message), &event)
-		return &event, err
+		return parseKLineEvent(val), nil
 
 	case "outboundAccountPosition":
 		var event OutboundAccountPositionEvent
@@ -271,13 +566,32 @@ func ParseEvent(message string) (interface{}, error) {
 		return &event, err
 
 	case "executionReport":
-		var event ExecutionReportEvent
-		err := json.Unmarshal([]byte(message), &event)
-		return &event, err
+		return parseExecutionReportEvent(val), nil
 
 	case "depthUpdate":
 		return parseDepthEvent(val)
 
+	case "aggTrade":
+		return parseAggTradeEvent(val), nil
+
+	case "markPriceUpdate":
+		return parseMarkPriceUpdateEvent(val), nil
+
+	case "ORDER_TRADE_UPDATE":
+		var event OrderTradeUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
+	case "ACCOUNT_UPDATE":
+		var event AccountUpdateEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
+	case "MARGIN_CALL":
+		var event MarginCallEvent
+		err := json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	default:
 		id := val.GetInt("id")
 		if id > 0 {
@@ -288,9 +602,104 @@ func ParseEvent(message string) (interface{}, error) {
 	return nil, fmt.Errorf("unsupported message: %s", message)
 }
 
+func parseEventBase(val *fastjson.Value) EventBase {
+	return EventBase{
+		Event: string(val.GetStringBytes("e")),
+		Time:  val.GetInt64("E"),
+	}
+}
+
+// parseFixedPoint parses the decimal string bytes with the fixed-point parser,
+// so that the 8-decimal prices and quantities are not rounded by the float conversion.
+func parseFixedPoint(data []byte) (fixedpoint.Value, error) {
+	return fixedpoint.NewFromString(string(data))
+}
+
+func parseKLineEvent(val *fastjson.Value) *KLineEvent {
+	k := val.Get("k")
+	return &KLineEvent{
+		EventBase: parseEventBase(val),
+		Symbol:    string(val.GetStringBytes("s")),
+		KLine: KLine{
+			StartTime:      k.GetInt64("t"),
+			EndTime:        k.GetInt64("T"),
+			Symbol:         string(k.GetStringBytes("s")),
+			Interval:       string(k.GetStringBytes("i")),
+			Open:           string(k.GetStringBytes("o")),
+			Close:          string(k.GetStringBytes("c")),
+			High:           string(k.GetStringBytes("h")),
+			Low:            string(k.GetStringBytes("l")),
+			Volume:         string(k.GetStringBytes("V")),
+			QuoteVolume:    string(k.GetStringBytes("Q")),
+			LastTradeID:    k.GetInt("L"),
+			NumberOfTrades: k.GetInt64("n"),
+			Closed:         k.GetBool("x"),
+		},
+	}
+}
+
+func parseAggTradeEvent(val *fastjson.Value) *AggTradeEvent {
+	return &AggTradeEvent{
+		EventBase:    parseEventBase(val),
+		Symbol:       string(val.GetStringBytes("s")),
+		AggTradeID:   val.GetInt64("a"),
+		Price:        string(val.GetStringBytes("p")),
+		Quantity:     string(val.GetStringBytes("q")),
+		FirstTradeID: val.GetInt64("f"),
+		LastTradeID:  val.GetInt64("l"),
+		TradeTime:    val.GetInt64("T"),
+		IsBuyerMaker: val.GetBool("m"),
+	}
+}
+
+func parseMarkPriceUpdateEvent(val *fastjson.Value) *MarkPriceUpdateEvent {
+	return &MarkPriceUpdateEvent{
+		EventBase:       parseEventBase(val),
+		Symbol:          string(val.GetStringBytes("s")),
+		MarkPrice:       string(val.GetStringBytes("p")),
+		IndexPrice:      string(val.GetStringBytes("i")),
+		FundingRate:     string(val.GetStringBytes("r")),
+		NextFundingTime: val.GetInt64("T"),
+	}
+}
+
+func parseExecutionReportEvent(val *fastjson.Value) *ExecutionReportEvent {
+	return &ExecutionReportEvent{
+		EventBase:                              parseEventBase(val),
+		Symbol:                                 string(val.GetStringBytes("s")),
+		ClientOrderID:                          string(val.GetStringBytes("c")),
+		Side:                                   string(val.GetStringBytes("S")),
+		OrderType:                              string(val.GetStringBytes("o")),
+		TimeInForce:                            string(val.GetStringBytes("f")),
+		OriginalClientOrderID:                  string(val.GetStringBytes("C")),
+		OrderQuantity:                          string(val.GetStringBytes("q")),
+		OrderPrice:                             string(val.GetStringBytes("p")),
+		StopPrice:                              string(val.GetStringBytes("P")),
+		IcebergQuantity:                        string(val.GetStringBytes("F")),
+		QuoteOrderQuantity:                     string(val.GetStringBytes("Q")),
+		IsOnBook:                               val.GetBool("w"),
+		IsMaker:                                val.GetBool("m"),
+		Ignore:                                 val.GetBool("M"),
+		CommissionAmount:                       string(val.GetStringBytes("n")),
+		CommissionAsset:                        string(val.GetStringBytes("N")),
+		CurrentExecutionType:                   string(val.GetStringBytes("x")),
+		CurrentOrderStatus:                     string(val.GetStringBytes("X")),
+		OrderID:                                val.GetInt64("i"),
+		Ignored:                                val.GetInt64("I"),
+		TradeID:                                val.GetInt64("t"),
+		TransactionTime:                        val.GetInt64("T"),
+		LastExecutedQuantity:                   string(val.GetStringBytes("l")),
+		CumulativeFilledQuantity:               string(val.GetStringBytes("z")),
+		LastExecutedPrice:                      string(val.GetStringBytes("L")),
+		LastQuoteAssetTransactedQuantity:       string(val.GetStringBytes("Y")),
+		CumulativeQuoteAssetTransactedQuantity: string(val.GetStringBytes("Z")),
+		OrderCreationTime:                      val.GetInt64("O"),
+	}
+}
+
 type DepthEntry struct {
-	PriceLevel string
-	Quantity   string
+	PriceLevel fixedpoint.Value
+	Quantity   fixedpoint.Value
 }
 
 type DepthEvent struct {
@@ -300,100 +709,86 @@ type DepthEvent struct {
 	FirstUpdateID int64  `json:"U"`
 	FinalUpdateID int64  `json:"u"`
 
+	// PreviousUpdateID is the final update ID of the previous event, it's only sent by the futures stream
+	PreviousUpdateID int64 `json:"pu"`
+
 	Bids []DepthEntry
 	Asks []DepthEntry
 }
 
 func (e *DepthEvent) OrderBook() (book types.OrderBook, err error) {
 	book.Symbol = e.Symbol
+	book.FirstUpdateID = e.FirstUpdateID
+	book.FinalUpdateID = e.FinalUpdateID
+	book.PreviousUpdateID = e.PreviousUpdateID
 
 	for _, entry := range e.Bids {
-		quantity, err := fixedpoint.NewFromString(entry.Quantity)
-		if err != nil {
-			continue
-		}
-
-		price, err := fixedpoint.NewFromString(entry.PriceLevel)
-		if err != nil {
-			continue
-		}
-
-		pv := types.PriceVolume{
-			Price:  price,
-			Volume: quantity,
-		}
-
-		book.Bids = book.Bids.Upsert(pv, true)
+		book.Bids = book.Bids.Upsert(types.PriceVolume{
+			Price:  entry.PriceLevel,
+			Volume: entry.Quantity,
+		}, true)
 	}
 
 	for _, entry := range e.Asks {
-		quantity, err := fixedpoint.NewFromString(entry.Quantity)
-		if err != nil {
-			continue
-		}
-
-		price, err := fixedpoint.NewFromString(entry.PriceLevel)
-		if err != nil {
-			continue
-		}
-
-		pv := types.PriceVolume{
-			Price:  price,
-			Volume: quantity,
-		}
-
-		book.Asks = book.Asks.Upsert(pv, false)
+		book.Asks = book.Asks.Upsert(types.PriceVolume{
+			Price:  entry.PriceLevel,
+			Volume: entry.Quantity,
+		}, false)
 	}
 
 	return
 }
 
-func parseDepthEntry(val *fastjson.Value) (*DepthEntry, error) {
+func parseDepthEntry(val *fastjson.Value) (entry DepthEntry, err error) {
 	arr, err := val.Array()
 	if err != nil {
-		return nil, err
+		return entry, err
 	}
 
 	if len(arr) < 2 {
-		return nil, errors.New("incorrect depth entry element length")
+		return entry, errors.New("incorrect depth entry element length")
 	}
 
-	return &DepthEntry{
-		PriceLevel: string(arr[0].GetStringBytes()),
-		Quantity:   string(arr[1].GetStringBytes()),
-	}, nil
-}
-
-func parseDepthEvent(val *fastjson.Value) (*DepthEvent, error) {
-	var err error
-	var depth = &DepthEvent{
-		EventBase: EventBase{
-			Event: string(val.GetStringBytes("e")),
-			Time:  val.GetInt64("E"),
-		},
-		Symbol:        string(val.GetStringBytes("s")),
-		FirstUpdateID: val.GetInt64("U"),
-		FinalUpdateID: val.GetInt64("u"),
+	entry.PriceLevel, err = parseFixedPoint(arr[0].GetStringBytes())
+	if err != nil {
+		return entry, err
 	}
 
-	for _, ev := range val.GetArray("b") {
+	entry.Quantity, err = parseFixedPoint(arr[1].GetStringBytes())
+	return entry, err
+}
+
+func parseDepthEntries(values []*fastjson.Value) (entries []DepthEntry, err error) {
+	entries = make([]DepthEntry, 0, len(values))
+	for _, ev := range values {
 		entry, err2 := parseDepthEntry(ev)
 		if err2 != nil {
 			err = err2
 			continue
 		}
 
-		depth.Bids = append(depth.Bids, *entry)
+		entries = append(entries, entry)
 	}
 
-	for _, ev := range val.GetArray("a") {
-		entry, err2 := parseDepthEntry(ev)
-		if err2 != nil {
-			err = err2
-			continue
-		}
+	return entries, err
+}
+
+func parseDepthEvent(val *fastjson.Value) (*DepthEvent, error) {
+	var depth = &DepthEvent{
+		EventBase:     parseEventBase(val),
+		Symbol:        string(val.GetStringBytes("s")),
+		FirstUpdateID:    val.GetInt64("U"),
+		FinalUpdateID:    val.GetInt64("u"),
+		PreviousUpdateID: val.GetInt64("pu"),
+	}
+
+	bids, err := parseDepthEntries(val.GetArray("b"))
+	depth.Bids = bids
 
-		depth.Asks = append(depth.Asks, *entry)
+	asks, err2 := parseDepthEntries(val.GetArray("a"))
+	depth.Asks = asks
+	if err2 != nil {
+		err = err2
 	}
 
 	return depth, err
@@ -419,6 +814,63 @@ type KLine struct {
 	Closed         bool  `json:"x"`
 }
 
+// AggTradeEvent is the aggregated public trade event of the "<symbol>@aggTrade" stream
+type AggTradeEvent struct {
+	EventBase
+	Symbol       string `json:"s"`
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	FirstTradeID int64  `json:"f"`
+	LastTradeID  int64  `json:"l"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+}
+
+// Trade converts the aggregated trade to the public market trade, the side is the taker side
+func (e *AggTradeEvent) Trade() types.Trade {
+	price := util.MustParseFloat(e.Price)
+	quantity := util.MustParseFloat(e.Quantity)
+
+	side := types.SideTypeBuy
+	if e.IsBuyerMaker {
+		side = types.SideTypeSell
+	}
+
+	return types.Trade{
+		ID:            e.AggTradeID,
+		Exchange:      types.ExchangeBinance.String(),
+		Symbol:        e.Symbol,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          time.Unix(0, e.TradeTime*int64(time.Millisecond)),
+	}
+}
+
+// MarkPriceUpdateEvent is the mark price and the funding rate event of the futures "<symbol>@markPrice" stream
+type MarkPriceUpdateEvent struct {
+	EventBase
+	Symbol          string `json:"s"`
+	MarkPrice       string `json:"p"`
+	IndexPrice      string `json:"i"`
+	FundingRate     string `json:"r"`
+	NextFundingTime int64  `json:"T"`
+}
+
+// ToFundingRate converts the event to the predicted funding rate of the next funding time
+func (e *MarkPriceUpdateEvent) ToFundingRate() types.FundingRate {
+	return types.FundingRate{
+		Symbol:          e.Symbol,
+		FundingRate:     fixedpoint.NewFromFloat(util.MustParseFloat(e.FundingRate)),
+		MarkPrice:       fixedpoint.NewFromFloat(util.MustParseFloat(e.MarkPrice)),
+		NextFundingTime: millisecondTime(e.NextFundingTime),
+		Time:            e.EventTime(),
+	}
+}
+
 type KLineEvent struct {
 	EventBase
 	Symbol string `json:"s"`
@@ -478,3 +930,11 @@ type EventBase struct {
 	Event string `json:"e"` // event
 	Time  int64  `json:"E"`
 }
+
+func (e *EventBase) eventBase() *EventBase {
+	return e
+}
+
+func (e *EventBase) EventTime() time.Time {
+	return time.Unix(0, e.Time*int64(time.Millisecond))
+}
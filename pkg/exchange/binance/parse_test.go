@@ -3,8 +3,12 @@ package binance
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var jsCommentTrimmer = regexp.MustCompile("(?m)//.*$")
@@ -183,3 +187,286 @@ func TestParseOrderUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
 }
+
+func TestParseOrderUpdate_Canceled(t *testing.T) {
+	payload := `{
+  "e": "executionReport",
+  "E": 1499405658658,
+  "s": "ETHBTC",
+  "c": "cancel_request_client_order_id",
+  "S": "BUY",
+  "o": "LIMIT",
+  "f": "GTC",
+  "q": "1.00000000",
+  "p": "0.10264410",
+  "P": "0.00000000",
+  "F": "0.00000000",
+  "g": -1,
+  "C": "grid_level_7",
+  "x": "CANCELED",
+  "X": "CANCELED",
+  "r": "NONE",
+  "i": 4293153,
+  "l": "0.00000000",
+  "z": "0.20000000",
+  "L": "0.00000000",
+  "n": "0",
+  "N": null,
+  "T": 1499405658657,
+  "t": -1,
+  "I": 8641984,
+  "w": false,
+  "m": false,
+  "M": false,
+  "O": 1499405658657,
+  "Z": "0.02052882",
+  "Y": "0.00000000",
+  "Q": "0.00000000"
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	executionReport, ok := event.(*ExecutionReportEvent)
+	assert.True(t, ok)
+
+	orderUpdate, err := executionReport.Order()
+	assert.NoError(t, err)
+
+	// the canceled order carries the client order id of the original order
+	assert.Equal(t, "grid_level_7", orderUpdate.ClientOrderID)
+	assert.Equal(t, types.OrderStatusCanceled, orderUpdate.Status)
+
+	// the upper case keys should not overwrite the lower case keys
+	assert.Equal(t, 1.0, orderUpdate.Quantity)
+	assert.Equal(t, 0.2, orderUpdate.ExecutedQuantity)
+}
+
+const depthUpdatePayload = `{
+  "e": "depthUpdate",
+  "E": 123456789,
+  "s": "BNBBTC",
+  "U": 157,
+  "u": 160,
+  "b": [["0.0024", "10"], ["0.0023", "0.5"]],
+  "a": [["0.0026", "100"]]
+}`
+
+func TestParseDepthEvent(t *testing.T) {
+	event, err := ParseEvent(depthUpdatePayload)
+	assert.NoError(t, err)
+
+	depth, ok := event.(*DepthEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "BNBBTC", depth.Symbol)
+	assert.Equal(t, int64(157), depth.FirstUpdateID)
+	assert.Equal(t, int64(160), depth.FinalUpdateID)
+	assert.Equal(t, []DepthEntry{
+		{PriceLevel: fixedpoint.NewFromFloat(0.0024), Quantity: fixedpoint.NewFromFloat(10)},
+		{PriceLevel: fixedpoint.NewFromFloat(0.0023), Quantity: fixedpoint.NewFromFloat(0.5)},
+	}, depth.Bids)
+	assert.Equal(t, []DepthEntry{
+		{PriceLevel: fixedpoint.NewFromFloat(0.0026), Quantity: fixedpoint.NewFromFloat(100)},
+	}, depth.Asks)
+
+	book, err := depth.OrderBook()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0024, book.Bids[0].Price.Float64())
+	assert.Equal(t, 0.0026, book.Asks[0].Price.Float64())
+	assert.Equal(t, int64(157), book.FirstUpdateID)
+	assert.Equal(t, int64(160), book.FinalUpdateID)
+	assert.Equal(t, int64(0), book.PreviousUpdateID)
+}
+
+func TestParseFixedPoint(t *testing.T) {
+	v, err := parseFixedPoint([]byte("0.12345678"))
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(12345678), v)
+
+	v, err = parseFixedPoint([]byte("19999.99999999"))
+	assert.NoError(t, err)
+	assert.Equal(t, fixedpoint.Value(1999999999999), v)
+
+	_, err = parseFixedPoint([]byte("abc"))
+	assert.Error(t, err)
+}
+
+func TestParseKLineEvent(t *testing.T) {
+	payload := `{
+  "e": "kline",
+  "E": 123456789,
+  "s": "BNBBTC",
+  "k": {
+    "t": 123400000,
+    "T": 123460000,
+    "s": "BNBBTC",
+    "i": "1m",
+    "f": 100,
+    "L": 200,
+    "o": "0.0010",
+    "c": "0.0020",
+    "h": "0.0025",
+    "l": "0.0015",
+    "v": "1000",
+    "n": 100,
+    "x": true,
+    "q": "1.0000",
+    "V": "500",
+    "Q": "0.500",
+    "B": "123456"
+  }
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	klineEvent, ok := event.(*KLineEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "BNBBTC", klineEvent.Symbol)
+
+	kline := klineEvent.KLine.KLine()
+	assert.Equal(t, types.Interval1m, kline.Interval)
+	assert.Equal(t, 0.001, kline.Open)
+	assert.Equal(t, 0.002, kline.Close)
+	assert.Equal(t, 0.0025, kline.High)
+	assert.Equal(t, 0.0015, kline.Low)
+	assert.Equal(t, 500.0, kline.Volume)
+	assert.Equal(t, uint64(200), kline.LastTradeID)
+	assert.Equal(t, uint64(100), kline.NumberOfTrades)
+	assert.True(t, kline.Closed)
+}
+
+func BenchmarkParseDepthEvent(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseEvent(depthUpdatePayload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParseAggTradeEvent(t *testing.T) {
+	payload := `{
+  "e": "aggTrade",
+  "E": 123456789,
+  "s": "BNBBTC",
+  "a": 12345,
+  "p": "0.001",
+  "q": "100",
+  "f": 100,
+  "l": 105,
+  "T": 123456785,
+  "m": true,
+  "M": true
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	aggTradeEvent, ok := event.(*AggTradeEvent)
+	assert.True(t, ok)
+	assert.Equal(t, "BNBBTC", aggTradeEvent.Symbol)
+
+	trade := aggTradeEvent.Trade()
+	assert.Equal(t, int64(12345), trade.ID)
+	assert.Equal(t, 0.001, trade.Price)
+	assert.Equal(t, 100.0, trade.Quantity)
+	// the buyer is the maker, so the taker side is sell
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.Equal(t, int64(123456785), trade.Time.UnixNano()/int64(time.Millisecond))
+}
+
+func TestParseMarkPriceUpdateEvent(t *testing.T) {
+	payload := `{
+  "e": "markPriceUpdate",
+  "E": 1562305380000,
+  "s": "BTCUSDT",
+  "p": "11794.15000000",
+  "i": "11784.62659091",
+  "P": "11784.25641265",
+  "r": "0.00038167",
+  "T": 1562306400000
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	markPriceEvent, ok := event.(*MarkPriceUpdateEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	rate := markPriceEvent.ToFundingRate()
+	assert.Equal(t, "BTCUSDT", rate.Symbol)
+	assert.Equal(t, 0.00038167, rate.FundingRate.Float64())
+	assert.Equal(t, 11794.15, rate.MarkPrice.Float64())
+	assert.Equal(t, int64(1562306400000), rate.NextFundingTime.UnixNano()/int64(time.Millisecond))
+}
+
+func TestParseAccountUpdateEvent_Positions(t *testing.T) {
+	payload := `{
+  "e": "ACCOUNT_UPDATE",
+  "E": 1564745798939,
+  "T": 1564745798938,
+  "a": {
+    "m": "ORDER",
+    "B": [{"a": "USDT", "wb": "122624.12345678", "cw": "100.12345678"}],
+    "P": [
+      {"s": "BTCUSDT", "pa": "-0.5", "ep": "9000.5", "cr": "200", "up": "-12.5", "mt": "isolated", "iw": "450.25", "ps": "BOTH"}
+    ]
+  }
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	accountUpdateEvent, ok := event.(*AccountUpdateEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	positions := accountUpdateEvent.Positions()
+	if assert.Len(t, positions, 1) {
+		position := positions[0]
+		assert.Equal(t, "BTCUSDT", position.Symbol)
+		assert.Equal(t, types.PositionSideBoth, position.PositionSide)
+		assert.Equal(t, types.MarginTypeIsolated, position.MarginType)
+		assert.Equal(t, -0.5, position.Quantity.Float64())
+		assert.Equal(t, 9000.5, position.EntryPrice.Float64())
+		assert.Equal(t, -12.5, position.UnrealizedProfit.Float64())
+		assert.Equal(t, 450.25, position.IsolatedMargin.Float64())
+		assert.True(t, position.IsShort())
+	}
+}
+
+func TestParseMarginCallEvent(t *testing.T) {
+	payload := `{
+  "e": "MARGIN_CALL",
+  "E": 1587727187525,
+  "cw": "3.16812045",
+  "p": [
+    {"s": "ETHUSDT", "ps": "LONG", "pa": "1.327", "mt": "CROSSED", "iw": "0", "mp": "187.17127", "up": "-1.166074", "mm": "1.614445"}
+  ]
+}`
+
+	event, err := ParseEvent(payload)
+	assert.NoError(t, err)
+
+	marginCallEvent, ok := event.(*MarginCallEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	marginCalls := marginCallEvent.MarginCalls()
+	if assert.Len(t, marginCalls, 1) {
+		marginCall := marginCalls[0]
+		assert.Equal(t, "ETHUSDT", marginCall.Symbol)
+		assert.Equal(t, types.PositionSideLong, marginCall.PositionSide)
+		assert.Equal(t, types.MarginTypeCrossed, marginCall.MarginType)
+		assert.Equal(t, 1.327, marginCall.Quantity.Float64())
+		assert.Equal(t, 187.17127, marginCall.MarkPrice.Float64())
+		assert.Equal(t, 1.614445, marginCall.MaintenanceMargin.Float64())
+		assert.Equal(t, 3.16812045, marginCall.CrossWalletBalance.Float64())
+		assert.Equal(t, int64(1587727187525), marginCall.Time.UnixNano()/int64(time.Millisecond))
+	}
+}
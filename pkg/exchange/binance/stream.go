@@ -3,7 +3,6 @@ package binance
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"os"
 	"strconv"
 	"strings"
@@ -11,6 +10,7 @@ import (
 	"time"
 
 	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gorilla/websocket"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -21,9 +21,6 @@ import (
 var debugBinanceDepth bool
 
 func init() {
-	// randomize pulling
-	rand.Seed(time.Now().UnixNano())
-
 	if s := os.Getenv("BINANCE_DEBUG_DEPTH"); len(s) > 0 {
 		v, err := strconv.ParseBool(s)
 		if err != nil {
@@ -47,13 +44,15 @@ type StreamRequest struct {
 //go:generate callbackgen -type Stream -interface
 type Stream struct {
 	types.MarginSettings
+	types.FuturesSettings
 
 	types.StandardStream
 
-	Client    *binance.Client
-	ListenKey string
-	Conn      *websocket.Conn
-	connLock  sync.Mutex
+	Client        *binance.Client
+	FuturesClient *futures.Client
+	ListenKey     string
+	Conn          *websocket.Conn
+	connLock      sync.Mutex
 
 	publicOnly bool
 
@@ -61,13 +60,21 @@ type Stream struct {
 	depthEventCallbacks       []func(e *DepthEvent)
 	kLineEventCallbacks       []func(e *KLineEvent)
 	kLineClosedEventCallbacks []func(e *KLineEvent)
+	aggTradeEventCallbacks    []func(e *AggTradeEvent)
+
+	markPriceUpdateEventCallbacks []func(e *MarkPriceUpdateEvent)
 
 	balanceUpdateEventCallbacks           []func(event *BalanceUpdateEvent)
 	outboundAccountInfoEventCallbacks     []func(event *OutboundAccountInfoEvent)
 	outboundAccountPositionEventCallbacks []func(event *OutboundAccountPositionEvent)
 	executionReportEventCallbacks         []func(event *ExecutionReportEvent)
 
-	depthFrames map[string]*DepthFrame
+	orderTradeUpdateEventCallbacks []func(event *OrderTradeUpdateEvent)
+	accountUpdateEventCallbacks    []func(event *AccountUpdateEvent)
+	marginCallEventCallbacks       []func(event *MarginCallEvent)
+
+	depthFrames     map[string]*DepthFrame
+	depthFramesLock sync.Mutex
 }
 
 func NewStream(client *binance.Client) *Stream {
@@ -77,6 +84,7 @@ func NewStream(client *binance.Client) *Stream {
 	}
 
 	stream.OnDepthEvent(func(e *DepthEvent) {
+		stream.depthFramesLock.Lock()
 		f, ok := stream.depthFrames[e.Symbol]
 		if !ok {
 			f = &DepthFrame{
@@ -120,9 +128,19 @@ func NewStream(client *binance.Client) *Stream {
 
 				stream.EmitBookUpdate(book)
 			})
-		} else {
-			f.PushEvent(*e)
 		}
+		stream.depthFramesLock.Unlock()
+
+		// the first event starts loading the snapshot
+		f.PushEvent(*e)
+	})
+
+	stream.OnAggTradeEvent(func(e *AggTradeEvent) {
+		stream.EmitMarketTrade(e.Trade())
+	})
+
+	stream.OnMarkPriceUpdateEvent(func(e *MarkPriceUpdateEvent) {
+		stream.EmitFundingRate(e.ToFundingRate())
 	})
 
 	stream.OnOutboundAccountPositionEvent(func(e *OutboundAccountPositionEvent) {
@@ -172,15 +190,86 @@ func NewStream(client *binance.Client) *Stream {
 		}
 	})
 
+	stream.OnOrderTradeUpdateEvent(func(e *OrderTradeUpdateEvent) {
+		switch e.Order.CurrentExecutionType {
+
+		case "NEW", "CANCELED", "EXPIRED", "AMENDMENT":
+			order, err := e.OrderFromEvent()
+			if err != nil {
+				log.WithError(err).Error("futures order convert error")
+				return
+			}
+
+			stream.EmitOrderUpdate(*order)
+
+		case "TRADE":
+			trade, err := e.TradeFromEvent()
+			if err != nil {
+				log.WithError(err).Error("futures trade convert error")
+				return
+			}
+
+			stream.EmitTradeUpdate(*trade)
+
+			// the order status is only updated with the trade event
+			order, err := e.OrderFromEvent()
+			if err != nil {
+				log.WithError(err).Error("futures order convert error")
+				return
+			}
+
+			stream.EmitOrderUpdate(*order)
+		}
+	})
+
+	stream.OnAccountUpdateEvent(func(e *AccountUpdateEvent) {
+		// the futures account update only carries the wallet balance, the margin locked by the orders is not included
+		balances := types.BalanceMap{}
+		for _, b := range e.AccountUpdate.Balances {
+			balances[b.Asset] = types.Balance{
+				Currency:  b.Asset,
+				Available: fixedpoint.Must(fixedpoint.NewFromString(b.WalletBalance)),
+			}
+		}
+
+		if len(balances) > 0 {
+			stream.EmitBalanceUpdate(balances)
+		}
+
+		for _, position := range e.Positions() {
+			stream.EmitPositionUpdate(position)
+		}
+	})
+
+	stream.OnMarginCallEvent(func(e *MarginCallEvent) {
+		for _, marginCall := range e.MarginCalls() {
+			stream.EmitMarginCall(marginCall)
+		}
+	})
+
 	stream.OnConnect(func() {
-		// reset the previous frames
+		// reset the previous frames, the lock is not held while loading the snapshots
+		// since the book callbacks may resync the book
+		var frames []*DepthFrame
+		stream.depthFramesLock.Lock()
 		for _, f := range stream.depthFrames {
+			frames = append(frames, f)
+		}
+		stream.depthFramesLock.Unlock()
+
+		for _, f := range frames {
 			f.reset()
 			f.loadDepthSnapshot()
 		}
 
 		var params []string
 		for _, subscription := range stream.Subscriptions {
+			// the funding rates are only published on the futures stream
+			if subscription.Channel == types.FundingRateChannel && !stream.IsFutures {
+				log.Warnf("funding rate of %s is only available on the futures stream, skipping", subscription.Symbol)
+				continue
+			}
+
 			params = append(params, convertSubscription(subscription))
 		}
 
@@ -203,16 +292,33 @@ func NewStream(client *binance.Client) *Stream {
 	return stream
 }
 
+// ResyncBook resets the depth frame of the symbol, the snapshot is reloaded on the next depth event
+// and the buffered events after the snapshot are emitted as the book updates.
+func (s *Stream) ResyncBook(symbol string) error {
+	s.depthFramesLock.Lock()
+	f, ok := s.depthFrames[symbol]
+	s.depthFramesLock.Unlock()
+
+	if !ok {
+		return fmt.Errorf("depth frame of %s is not found", symbol)
+	}
+
+	f.reset()
+	return nil
+}
+
 func (s *Stream) SetPublicOnly() {
 	s.publicOnly = true
 }
 
 func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
-	var url string
-	if s.publicOnly {
-		url = "wss://stream.binance.com:9443/ws"
-	} else {
-		url = "wss://stream.binance.com:9443/ws/" + listenKey
+	var url = "wss://stream.binance.com:9443/ws"
+	if s.IsFutures {
+		url = "wss://fstream.binance.com/ws"
+	}
+
+	if !s.publicOnly {
+		url += "/" + listenKey
 	}
 
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
@@ -224,6 +330,11 @@ func (s *Stream) dial(listenKey string) (*websocket.Conn, error) {
 }
 
 func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
+	if s.IsFutures {
+		log.Infof("futures mode is enabled, requesting futures user stream listen key...")
+		return s.FuturesClient.NewStartUserStreamService().Do(ctx)
+	}
+
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			log.Infof("isolated margin %s is enabled, requesting margin user stream listen key...", s.IsolatedMarginSymbol)
@@ -241,6 +352,10 @@ func (s *Stream) fetchListenKey(ctx context.Context) (string, error) {
 }
 
 func (s *Stream) keepaliveListenKey(ctx context.Context, listenKey string) error {
+	if s.IsFutures {
+		return s.FuturesClient.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx)
+	}
+
 	if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewKeepaliveIsolatedMarginUserStreamService().ListenKey(listenKey)
@@ -277,6 +392,12 @@ func (s *Stream) connect(ctx context.Context) error {
 
 	log.Infof("websocket connected")
 
+	// the pongs of the heartbeat pings keep the stream alive even if there is no data message
+	conn.SetPongHandler(func(string) error {
+		s.SetLastMessageTime(time.Now())
+		return nil
+	})
+
 	s.connLock.Lock()
 	s.Conn = conn
 	s.connLock.Unlock()
@@ -289,12 +410,20 @@ func convertSubscription(s types.Subscription) string {
 	// binance uses lower case symbol name,
 	// for kline, it's "<symbol>@kline_<interval>"
 	// for depth, it's "<symbol>@depth OR <symbol>@depth@100ms"
+	// for the public trades, it's "<symbol>@aggTrade"
+	// for the funding rates, it's "<symbol>@markPrice" of the futures stream
 	switch s.Channel {
 	case types.KLineChannel:
 		return fmt.Sprintf("%s@%s_%s", strings.ToLower(s.Symbol), s.Channel, s.Options.String())
 
 	case types.BookChannel:
 		return fmt.Sprintf("%s@depth", strings.ToLower(s.Symbol))
+
+	case types.MarketTradeChannel:
+		return fmt.Sprintf("%s@aggTrade", strings.ToLower(s.Symbol))
+
+	case types.FundingRateChannel:
+		return fmt.Sprintf("%s@markPrice", strings.ToLower(s.Symbol))
 	}
 
 	return fmt.Sprintf("%s@%s", strings.ToLower(s.Symbol), s.Channel)
@@ -356,6 +485,7 @@ func (s *Stream) read(ctx context.Context) {
 			}
 
 			mt, message, err := s.Conn.ReadMessage()
+			receivedTime := time.Now()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
 					log.WithError(err).Errorf("read error: %s", err.Error())
@@ -363,7 +493,10 @@ func (s *Stream) read(ctx context.Context) {
 					log.Info("websocket connection closed, going away")
 				}
 
-				// reconnect
+				s.EmitDisconnect()
+
+				// reconnect with the exponential backoff
+				backoff := types.NewReconnectBackoff()
 				for err != nil {
 					select {
 					case <-ctx.Done():
@@ -377,13 +510,19 @@ func (s *Stream) read(ctx context.Context) {
 						}
 
 						err = s.connect(ctx)
-						time.Sleep(5 * time.Second)
+						if err != nil {
+							delay := backoff.Next()
+							log.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+							time.Sleep(delay)
+						}
 					}
 				}
 
 				continue
 			}
 
+			s.SetLastMessageTime(receivedTime)
+
 			// skip non-text messages
 			if mt != websocket.TextMessage {
 				continue
@@ -418,9 +557,32 @@ func (s *Stream) read(ctx context.Context) {
 			case *DepthEvent:
 				s.EmitDepthEvent(e)
 
+			case *AggTradeEvent:
+				s.EmitAggTradeEvent(e)
+
+			case *MarkPriceUpdateEvent:
+				s.EmitMarkPriceUpdateEvent(e)
+
 			case *ExecutionReportEvent:
 				log.Info(e.Event, " ", e)
 				s.EmitExecutionReportEvent(e)
+
+			case *OrderTradeUpdateEvent:
+				log.Info(e.Event, " ", e.Order)
+				s.EmitOrderTradeUpdateEvent(e)
+
+			case *AccountUpdateEvent:
+				log.Info(e.Event, " ", e.AccountUpdate.EventReasonType)
+				s.EmitAccountUpdateEvent(e)
+
+			case *MarginCallEvent:
+				log.Warn(e.Event, " ", e.Positions)
+				s.EmitMarginCallEvent(e)
+			}
+
+			if eb, ok := e.(interface{ eventBase() *EventBase }); ok {
+				base := eb.eventBase()
+				s.Latency().Record(base.Event, base.EventTime(), receivedTime, time.Now())
 			}
 		}
 	}
@@ -430,7 +592,9 @@ func (s *Stream) invalidateListenKey(ctx context.Context, listenKey string) (err
 	// should use background context to invalidate the user stream
 	log.Info("closing listen key")
 
-	if s.IsMargin {
+	if s.IsFutures {
+		err = s.FuturesClient.NewCloseUserStreamService().ListenKey(listenKey).Do(ctx)
+	} else if s.IsMargin {
 		if s.IsIsolatedMargin {
 			req := s.Client.NewCloseIsolatedMarginUserStreamService().ListenKey(listenKey)
 			req.Symbol(s.IsolatedMarginSymbol)
@@ -468,6 +632,20 @@ func (s *Stream) Close() error {
 	return s.Conn.Close()
 }
 
+// Reconnect closes the current connection, the read loop reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	log.Infof("reconnecting user data stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
+
 func maskListenKey(listenKey string) string {
 	maskKey := listenKey[0:5]
 	return maskKey + strings.Repeat("*", len(listenKey)-1-5)
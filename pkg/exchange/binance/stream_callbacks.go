@@ -34,6 +34,26 @@ func (s *Stream) EmitKLineClosedEvent(e *KLineEvent) {
 	}
 }
 
+func (s *Stream) OnAggTradeEvent(cb func(e *AggTradeEvent)) {
+	s.aggTradeEventCallbacks = append(s.aggTradeEventCallbacks, cb)
+}
+
+func (s *Stream) EmitAggTradeEvent(e *AggTradeEvent) {
+	for _, cb := range s.aggTradeEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnMarkPriceUpdateEvent(cb func(e *MarkPriceUpdateEvent)) {
+	s.markPriceUpdateEventCallbacks = append(s.markPriceUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitMarkPriceUpdateEvent(e *MarkPriceUpdateEvent) {
+	for _, cb := range s.markPriceUpdateEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *Stream) OnBalanceUpdateEvent(cb func(event *BalanceUpdateEvent)) {
 	s.balanceUpdateEventCallbacks = append(s.balanceUpdateEventCallbacks, cb)
 }
@@ -74,6 +94,36 @@ func (s *Stream) EmitExecutionReportEvent(event *ExecutionReportEvent) {
 	}
 }
 
+func (s *Stream) OnOrderTradeUpdateEvent(cb func(event *OrderTradeUpdateEvent)) {
+	s.orderTradeUpdateEventCallbacks = append(s.orderTradeUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitOrderTradeUpdateEvent(event *OrderTradeUpdateEvent) {
+	for _, cb := range s.orderTradeUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
+func (s *Stream) OnAccountUpdateEvent(cb func(event *AccountUpdateEvent)) {
+	s.accountUpdateEventCallbacks = append(s.accountUpdateEventCallbacks, cb)
+}
+
+func (s *Stream) EmitAccountUpdateEvent(event *AccountUpdateEvent) {
+	for _, cb := range s.accountUpdateEventCallbacks {
+		cb(event)
+	}
+}
+
+func (s *Stream) OnMarginCallEvent(cb func(event *MarginCallEvent)) {
+	s.marginCallEventCallbacks = append(s.marginCallEventCallbacks, cb)
+}
+
+func (s *Stream) EmitMarginCallEvent(event *MarginCallEvent) {
+	for _, cb := range s.marginCallEventCallbacks {
+		cb(event)
+	}
+}
+
 type StreamEventHub interface {
 	OnDepthEvent(cb func(e *DepthEvent))
 
@@ -81,6 +131,10 @@ type StreamEventHub interface {
 
 	OnKLineClosedEvent(cb func(e *KLineEvent))
 
+	OnAggTradeEvent(cb func(e *AggTradeEvent))
+
+	OnMarkPriceUpdateEvent(cb func(e *MarkPriceUpdateEvent))
+
 	OnBalanceUpdateEvent(cb func(event *BalanceUpdateEvent))
 
 	OnOutboundAccountInfoEvent(cb func(event *OutboundAccountInfoEvent))
@@ -88,4 +142,10 @@ type StreamEventHub interface {
 	OnOutboundAccountPositionEvent(cb func(event *OutboundAccountPositionEvent))
 
 	OnExecutionReportEvent(cb func(event *ExecutionReportEvent))
+
+	OnOrderTradeUpdateEvent(cb func(event *OrderTradeUpdateEvent))
+
+	OnAccountUpdateEvent(cb func(event *AccountUpdateEvent))
+
+	OnMarginCallEvent(cb func(event *MarginCallEvent))
 }
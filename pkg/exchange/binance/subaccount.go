@@ -0,0 +1,95 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type universalTransferResponse struct {
+	TranID int64 `json:"tranId"`
+}
+
+type apiErrorResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// TransferSubAccount moves the asset between the spot wallets of the master account and the sub-accounts with the universal transfer api,
+// the accounts are the emails of the sub-accounts, and the empty account means the master account.
+// The session must use the api key of the master account with the sub-account transfer permission.
+func (e *Exchange) TransferSubAccount(ctx context.Context, fromAccount, toAccount string, asset string, amount fixedpoint.Value) (*types.SubAccountTransfer, error) {
+	if fromAccount == toAccount {
+		return nil, fmt.Errorf("can not transfer %s from the account %q to itself", asset, fromAccount)
+	}
+
+	params := url.Values{}
+	if len(fromAccount) > 0 {
+		params.Set("fromEmail", fromAccount)
+	}
+
+	if len(toAccount) > 0 {
+		params.Set("toEmail", toAccount)
+	}
+
+	params.Set("fromAccountType", "SPOT")
+	params.Set("toAccountType", "SPOT")
+	params.Set("asset", asset)
+	params.Set("amount", strconv.FormatFloat(amount.Float64(), 'f', -1, 64))
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+
+	var resp universalTransferResponse
+	if err := e.doSignedRequest(ctx, http.MethodPost, "/sapi/v1/sub-account/universalTransfer", params, &resp); err != nil {
+		return nil, err
+	}
+
+	return &types.SubAccountTransfer{
+		Exchange:    types.ExchangeBinance,
+		TransferID:  strconv.FormatInt(resp.TranID, 10),
+		FromAccount: fromAccount,
+		ToAccount:   toAccount,
+		Asset:       asset,
+		Amount:      amount,
+		Time:        time.Now(),
+	}, nil
+}
+
+// doSignedRequest sends the signed request of the api which is not covered by the binance client library
+func (e *Exchange) doSignedRequest(ctx context.Context, method, path string, params url.Values, result interface{}) error {
+	query := params.Encode()
+	mac := hmac.New(sha256.New, []byte(e.Client.SecretKey))
+	mac.Write([]byte(query))
+	query += "&signature=" + hex.EncodeToString(mac.Sum(nil))
+
+	req, err := http.NewRequestWithContext(ctx, method, e.Client.BaseURL+path+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-MBX-APIKEY", e.Client.APIKey)
+
+	resp, err := e.Client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiErrorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("binance api %s error: %s (code %d, status %s)", path, apiErr.Msg, apiErr.Code, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
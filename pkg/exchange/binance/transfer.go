@@ -0,0 +1,71 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TransferInternal moves the asset between the spot wallet and the cross margin wallet or the USDT-M futures wallet,
+// the transfer between the margin wallet and the futures wallet should go through the spot wallet.
+func (e *Exchange) TransferInternal(ctx context.Context, from, to types.WalletType, asset string, amount fixedpoint.Value) (*types.InternalTransfer, error) {
+	var amountString = strconv.FormatFloat(amount.Float64(), 'f', -1, 64)
+	var tranID int64
+
+	switch {
+	case from == types.WalletTypeSpot && to == types.WalletTypeMargin,
+		from == types.WalletTypeMargin && to == types.WalletTypeSpot:
+		transferType := binance.MarginTransferTypeToMargin
+		if to == types.WalletTypeSpot {
+			transferType = binance.MarginTransferTypeToMain
+		}
+
+		resp, err := e.Client.NewMarginTransferService().
+			Asset(asset).
+			Amount(amountString).
+			Type(transferType).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tranID = resp.TranID
+
+	case from == types.WalletTypeSpot && to == types.WalletTypeFutures,
+		from == types.WalletTypeFutures && to == types.WalletTypeSpot:
+		transferType := binance.FuturesTransferTypeToFutures
+		if to == types.WalletTypeSpot {
+			transferType = binance.FuturesTransferTypeToMain
+		}
+
+		resp, err := e.Client.NewFuturesTransferService().
+			Asset(asset).
+			Amount(amountString).
+			Type(transferType).
+			Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tranID = resp.TranID
+
+	default:
+		return nil, fmt.Errorf("binance does not support the transfer from the %s wallet to the %s wallet", from, to)
+	}
+
+	return &types.InternalTransfer{
+		Exchange:   types.ExchangeBinance,
+		TransferID: strconv.FormatInt(tranID, 10),
+		From:       from,
+		To:         to,
+		Asset:      asset,
+		Amount:     amount,
+		Time:       time.Now(),
+	}, nil
+}
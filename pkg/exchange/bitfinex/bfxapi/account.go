@@ -0,0 +1,164 @@
+package bfxapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type WalletType string
+
+const (
+	WalletTypeExchange WalletType = "exchange"
+	WalletTypeMargin   WalletType = "margin"
+	WalletTypeFunding  WalletType = "funding"
+)
+
+type AccountService struct {
+	client *RestClient
+}
+
+type Wallet struct {
+	Type              WalletType
+	Currency          string
+	Balance           fixedpoint.Value
+	UnsettledInterest fixedpoint.Value
+	AvailableBalance  fixedpoint.Value
+
+	// HasAvailableBalance is false if the available balance is not calculated yet
+	HasAvailableBalance bool
+}
+
+// UnmarshalJSON parses [WALLET_TYPE, CURRENCY, BALANCE, UNSETTLED_INTEREST, AVAILABLE_BALANCE, ...],
+// the available balance is null in the websocket updates until it's calculated.
+func (w *Wallet) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*w = Wallet{
+		Type:              WalletType(r.String(0)),
+		Currency:          r.String(1),
+		Balance:           r.Value(2),
+		UnsettledInterest: r.Value(3),
+		AvailableBalance:  r.Value(4),
+
+		HasAvailableBalance: r.Has(4),
+	}
+	return nil
+}
+
+func (s *AccountService) Wallets(ctx context.Context) ([]Wallet, error) {
+	var wallets []Wallet
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/wallets", nil, &wallets)
+	return wallets, err
+}
+
+// Movement is the deposit or the withdrawal, the amount is negative for the withdrawals
+type Movement struct {
+	ID            int64
+	Currency      string
+	StartedTime   time.Time
+	UpdatedTime   time.Time
+	Status        string
+	Amount        fixedpoint.Value
+	Fees          fixedpoint.Value
+	Address       string
+	TransactionID string
+}
+
+// UnmarshalJSON parses [ID, CURRENCY, CURRENCY_NAME, _, _, MTS_STARTED, MTS_UPDATED, _, _, STATUS, _, _, AMOUNT, FEES, _, _, DESTINATION_ADDRESS, _, _, _, TRANSACTION_ID, ...]
+func (m *Movement) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*m = Movement{
+		ID:            r.Int64(0),
+		Currency:      r.String(1),
+		StartedTime:   r.Time(5),
+		UpdatedTime:   r.Time(6),
+		Status:        r.String(9),
+		Amount:        r.Value(12),
+		Fees:          r.Value(13),
+		Address:       r.String(16),
+		TransactionID: r.String(20),
+	}
+	return nil
+}
+
+// Movements queries the deposits and the withdrawals of the currency in the time range, the movements of all the currencies are returned if the currency is empty
+func (s *AccountService) Movements(ctx context.Context, currency string, startTime, endTime time.Time) ([]Movement, error) {
+	path := "auth/r/movements/hist"
+	if len(currency) > 0 {
+		path = "auth/r/movements/" + url.PathEscape(currency) + "/hist"
+	}
+
+	var movements []Movement
+	err := s.client.sendAuthenticatedRequest(ctx, path, timeRangePayload(startTime, endTime, 1000), &movements)
+	return movements, err
+}
+
+// timeRangePayload creates the payload of the history endpoints, the time is in milliseconds and the zero time is not sent
+func timeRangePayload(startTime, endTime time.Time, limit int) map[string]interface{} {
+	payload := map[string]interface{}{
+		"limit": limit,
+	}
+
+	if !startTime.IsZero() {
+		payload["start"] = startTime.UnixNano() / int64(time.Millisecond)
+	}
+
+	if !endTime.IsZero() {
+		payload["end"] = endTime.UnixNano() / int64(time.Millisecond)
+	}
+
+	return payload
+}
+
+// Transfer is the movement between the wallets of the account
+type Transfer struct {
+	UpdatedTime time.Time
+	WalletFrom  WalletType
+	WalletTo    WalletType
+	Currency    string
+	CurrencyTo  string
+	Amount      fixedpoint.Value
+}
+
+// UnmarshalJSON parses [MTS_UPDATED, WALLET_FROM, WALLET_TO, _, CURRENCY, CURRENCY_TO, _, AMOUNT]
+func (t *Transfer) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*t = Transfer{
+		UpdatedTime: r.Time(0),
+		WalletFrom:  WalletType(r.String(1)),
+		WalletTo:    WalletType(r.String(2)),
+		Currency:    r.String(4),
+		CurrencyTo:  r.String(5),
+		Amount:      r.Value(7),
+	}
+	return nil
+}
+
+type TransferRequest struct {
+	From     WalletType `json:"from"`
+	To       WalletType `json:"to"`
+	Currency string     `json:"currency"`
+	Amount   string     `json:"amount"`
+}
+
+// Transfer moves the currency between the wallets, the currency is not converted
+func (s *AccountService) Transfer(ctx context.Context, req TransferRequest) (*Transfer, error) {
+	var transfer Transfer
+	err := s.client.sendNotificationRequest(ctx, "auth/w/transfer", req, &transfer)
+	return &transfer, err
+}
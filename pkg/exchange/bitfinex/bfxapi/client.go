@@ -0,0 +1,326 @@
+package bfxapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+const (
+	// ProductionAPIURL is the official Bitfinex API endpoint, both the public and the authenticated endpoints are under /v2
+	ProductionAPIURL = "https://api.bitfinex.com"
+
+	UserAgent = "bbgo/1.0"
+
+	defaultHTTPTimeout = time.Second * 15
+)
+
+var log = logrus.WithField("exchange", "bitfinex")
+
+type RestClient struct {
+	client *http.Client
+
+	BaseURL *url.URL
+
+	// Authentication
+	Key    string
+	Secret string
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+
+	AccountService *AccountService
+	MarketService  *MarketService
+	TradeService   *TradeService
+	FundingService *FundingService
+}
+
+func NewRestClient(baseURL string) *RestClient {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	client := &RestClient{
+		client:  &http.Client{Timeout: defaultHTTPTimeout},
+		BaseURL: u,
+	}
+
+	client.AccountService = &AccountService{client}
+	client.MarketService = &MarketService{client}
+	client.TradeService = &TradeService{client}
+	client.FundingService = &FundingService{client}
+	return client
+}
+
+// Auth sets the api key and secret for the authenticated endpoints.
+func (c *RestClient) Auth(key, secret string) *RestClient {
+	c.Key = key
+	c.Secret = secret
+	return c
+}
+
+// ErrorResponse is returned when the response is the error array: ["error", code, message]
+type ErrorResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Code       int64
+	Message    string
+}
+
+func (r *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s: %d %d %s", r.Method, r.URL, r.StatusCode, r.Code, r.Message)
+}
+
+// nonce returns the increasing nonce in microseconds, the requests with the non-increasing nonce are rejected
+func (c *RestClient) nonce() string {
+	c.nonceMu.Lock()
+	defer c.nonceMu.Unlock()
+
+	n := time.Now().UnixNano() / int64(time.Microsecond)
+	if n <= c.lastNonce {
+		n = c.lastNonce + 1
+	}
+
+	c.lastNonce = n
+	return strconv.FormatInt(n, 10)
+}
+
+func (c *RestClient) newRequest(ctx context.Context, method, refURL string, params url.Values, body []byte) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	u := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", UserAgent)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// newAuthenticatedRequest creates the POST request of the authenticated endpoint, e.g., auth/r/wallets,
+// the signature is hex(hmac_sha384("/api/v2/" + path + nonce + body)).
+func (c *RestClient) newAuthenticatedRequest(ctx context.Context, path string, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 || len(c.Secret) == 0 {
+		return nil, errors.New("empty api key or secret")
+	}
+
+	var body = []byte("{}")
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/v2/"+path, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := c.nonce()
+
+	req.Header.Add("bfx-nonce", nonce)
+	req.Header.Add("bfx-apikey", c.Key)
+	req.Header.Add("bfx-signature", Sign(c.Secret, "/api/v2/"+path+nonce+string(body)))
+	return req, nil
+}
+
+// Sign returns the hex encoded hmac sha384 signature of the payload, it's used by both the rest api and the websocket authentication
+func Sign(secret, payload string) string {
+	var sig = hmac.New(sha512.New384, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sig.Sum(nil))
+}
+
+// sendRequest sends the request and decodes the response body into the given object
+func (c *RestClient) sendRequest(req *http.Request, data interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errResponse := &ErrorResponse{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+		}
+
+		var r Row
+		if err := json.Unmarshal(body, &r); err == nil && r.String(0) == "error" {
+			errResponse.Code = r.Int64(1)
+			errResponse.Message = r.String(2)
+		}
+
+		return errResponse
+	}
+
+	if data == nil || len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, data); err != nil {
+		return errors.Wrapf(err, "failed to decode the response: %s", body)
+	}
+
+	return nil
+}
+
+func (c *RestClient) get(ctx context.Context, refURL string, params url.Values, data interface{}) error {
+	req, err := c.newRequest(ctx, "GET", refURL, params, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+func (c *RestClient) sendAuthenticatedRequest(ctx context.Context, path string, payload interface{}, data interface{}) error {
+	req, err := c.newAuthenticatedRequest(ctx, path, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+// Row is the array of the response fields, the fields of Bitfinex are positional and null for the placeholders
+type Row []json.RawMessage
+
+func (r Row) field(i int) json.RawMessage {
+	if i >= len(r) {
+		return nil
+	}
+
+	return r[i]
+}
+
+// Has returns true if the field is present and not null
+func (r Row) Has(i int) bool {
+	f := r.field(i)
+	return len(f) > 0 && string(f) != "null"
+}
+
+func (r Row) String(i int) string {
+	var s string
+	_ = json.Unmarshal(r.field(i), &s)
+	return s
+}
+
+func (r Row) Int64(i int) int64 {
+	var n json.Number
+	if err := json.Unmarshal(r.field(i), &n); err != nil {
+		return 0
+	}
+
+	if v, err := n.Int64(); err == nil {
+		return v
+	}
+
+	f, _ := n.Float64()
+	return int64(f)
+}
+
+func (r Row) Value(i int) fixedpoint.Value {
+	var v fixedpoint.Value
+	_ = json.Unmarshal(r.field(i), &v)
+	return v
+}
+
+// Time parses the millisecond timestamp field
+func (r Row) Time(i int) time.Time {
+	ms := r.Int64(i)
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// Row returns the nested array field
+func (r Row) Row(i int) Row {
+	var nested Row
+	_ = json.Unmarshal(r.field(i), &nested)
+	return nested
+}
+
+// Notification is the response of the write endpoints: [MTS, TYPE, MESSAGE_ID, null, DATA, CODE, STATUS, TEXT]
+type Notification struct {
+	Time   time.Time
+	Type   string
+	Data   json.RawMessage
+	Status string
+	Text   string
+}
+
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	n.Time = r.Time(0)
+	n.Type = r.String(1)
+	n.Data = r.field(4)
+	n.Status = r.String(6)
+	n.Text = r.String(7)
+	return nil
+}
+
+// sendNotificationRequest sends the request of the write endpoint and decodes the data of the successful notification
+func (c *RestClient) sendNotificationRequest(ctx context.Context, path string, payload interface{}, data interface{}) error {
+	var n Notification
+	if err := c.sendAuthenticatedRequest(ctx, path, payload, &n); err != nil {
+		return err
+	}
+
+	if n.Status != "SUCCESS" {
+		return fmt.Errorf("%s %s: %s", n.Type, n.Status, n.Text)
+	}
+
+	if data == nil || len(n.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(n.Data, data)
+}
@@ -0,0 +1,204 @@
+package bfxapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// FundingService is the margin funding (lending) market, the funding symbol is the currency with the "f" prefix, e.g., fUSD,
+// the rates are the daily rates and the periods are in days.
+type FundingService struct {
+	client *RestClient
+}
+
+type FundingTicker struct {
+	FlashReturnRate fixedpoint.Value
+	Bid             fixedpoint.Value
+	BidPeriod       int
+	BidSize         fixedpoint.Value
+	Ask             fixedpoint.Value
+	AskPeriod       int
+	AskSize         fixedpoint.Value
+	LastPrice       fixedpoint.Value
+	Volume          fixedpoint.Value
+	High            fixedpoint.Value
+	Low             fixedpoint.Value
+}
+
+// UnmarshalJSON parses [FRR, BID, BID_PERIOD, BID_SIZE, ASK, ASK_PERIOD, ASK_SIZE, DAILY_CHANGE, DAILY_CHANGE_PERC, LAST_PRICE, VOLUME, HIGH, LOW, ...]
+func (t *FundingTicker) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*t = FundingTicker{
+		FlashReturnRate: r.Value(0),
+		Bid:             r.Value(1),
+		BidPeriod:       int(r.Int64(2)),
+		BidSize:         r.Value(3),
+		Ask:             r.Value(4),
+		AskPeriod:       int(r.Int64(5)),
+		AskSize:         r.Value(6),
+		LastPrice:       r.Value(9),
+		Volume:          r.Value(10),
+		High:            r.Value(11),
+		Low:             r.Value(12),
+	}
+	return nil
+}
+
+func (s *FundingService) Ticker(ctx context.Context, symbol string) (*FundingTicker, error) {
+	var ticker FundingTicker
+	err := s.client.get(ctx, "/v2/ticker/"+url.PathEscape(symbol), nil, &ticker)
+	return &ticker, err
+}
+
+// FundingBookEntry is the aggregated offers of the rate and the period, the amount is negative for the bids
+type FundingBookEntry struct {
+	Rate   fixedpoint.Value
+	Period int
+	Count  int
+	Amount fixedpoint.Value
+}
+
+// UnmarshalJSON parses [RATE, PERIOD, COUNT, AMOUNT]
+func (e *FundingBookEntry) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*e = FundingBookEntry{
+		Rate:   r.Value(0),
+		Period: int(r.Int64(1)),
+		Count:  int(r.Int64(2)),
+		Amount: r.Value(3),
+	}
+	return nil
+}
+
+// Book returns the aggregated funding book of the symbol, the depth is one of 1, 25 and 100
+func (s *FundingService) Book(ctx context.Context, symbol string, depth int) ([]FundingBookEntry, error) {
+	var entries []FundingBookEntry
+	err := s.client.get(ctx, "/v2/book/"+url.PathEscape(symbol)+"/P0", url.Values{"len": []string{strconv.Itoa(depth)}}, &entries)
+	return entries, err
+}
+
+// Offer is the funding offer row, the amount is the remaining amount that is not taken yet,
+// the status is ACTIVE, PARTIALLY FILLED, EXECUTED or CANCELED.
+type Offer struct {
+	ID          int64
+	Symbol      string
+	CreatedTime time.Time
+	UpdatedTime time.Time
+	Amount      fixedpoint.Value
+	AmountOrig  fixedpoint.Value
+	Type        string
+	Status      string
+	Rate        fixedpoint.Value
+	Period      int
+}
+
+// UnmarshalJSON parses [ID, SYMBOL, MTS_CREATED, MTS_UPDATED, AMOUNT, AMOUNT_ORIG, OFFER_TYPE, _, _, FLAGS, OFFER_STATUS, _, _, _, RATE, PERIOD, ...]
+func (o *Offer) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*o = Offer{
+		ID:          r.Int64(0),
+		Symbol:      r.String(1),
+		CreatedTime: r.Time(2),
+		UpdatedTime: r.Time(3),
+		Amount:      r.Value(4),
+		AmountOrig:  r.Value(5),
+		Type:        r.String(6),
+		Status:      r.String(10),
+		Rate:        r.Value(14),
+		Period:      int(r.Int64(15)),
+	}
+	return nil
+}
+
+// SubmitOfferRequest is the payload of the funding offer, the period is between 2 and 120 days
+type SubmitOfferRequest struct {
+	Type   string `json:"type"`
+	Symbol string `json:"symbol"`
+	Amount string `json:"amount"`
+	Rate   string `json:"rate"`
+	Period int    `json:"period"`
+	Flags  int    `json:"flags"`
+}
+
+func (s *FundingService) SubmitOffer(ctx context.Context, req SubmitOfferRequest) (*Offer, error) {
+	if len(req.Type) == 0 {
+		req.Type = "LIMIT"
+	}
+
+	var offer Offer
+	err := s.client.sendNotificationRequest(ctx, "auth/w/funding/offer/submit", req, &offer)
+	return &offer, err
+}
+
+func (s *FundingService) CancelOffer(ctx context.Context, offerID int64) error {
+	return s.client.sendNotificationRequest(ctx, "auth/w/funding/offer/cancel", map[string]interface{}{"id": offerID}, nil)
+}
+
+// ActiveOffers returns the offers of the symbol that are not taken yet
+func (s *FundingService) ActiveOffers(ctx context.Context, symbol string) ([]Offer, error) {
+	var offers []Offer
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/funding/offers/"+url.PathEscape(symbol), nil, &offers)
+	return offers, err
+}
+
+// Credit is the funds lent out and used by the margin positions
+type Credit struct {
+	ID             int64
+	Symbol         string
+	Side           int64
+	CreatedTime    time.Time
+	UpdatedTime    time.Time
+	Amount         fixedpoint.Value
+	Status         string
+	Rate           fixedpoint.Value
+	Period         int
+	OpeningTime    time.Time
+	LastPayoutTime time.Time
+}
+
+// UnmarshalJSON parses [ID, SYMBOL, SIDE, MTS_CREATE, MTS_UPDATE, AMOUNT, FLAGS, STATUS, RATE_TYPE, _, _, RATE, PERIOD, MTS_OPENING, MTS_LAST_PAYOUT, ...]
+func (c *Credit) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*c = Credit{
+		ID:             r.Int64(0),
+		Symbol:         r.String(1),
+		Side:           r.Int64(2),
+		CreatedTime:    r.Time(3),
+		UpdatedTime:    r.Time(4),
+		Amount:         r.Value(5),
+		Status:         r.String(7),
+		Rate:           r.Value(11),
+		Period:         int(r.Int64(12)),
+		OpeningTime:    r.Time(13),
+		LastPayoutTime: r.Time(14),
+	}
+	return nil
+}
+
+// Credits returns the active credits of the symbol that are lent out
+func (s *FundingService) Credits(ctx context.Context, symbol string) ([]Credit, error) {
+	var credits []Credit
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/funding/credits/"+url.PathEscape(symbol), nil, &credits)
+	return credits, err
+}
@@ -0,0 +1,125 @@
+package bfxapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarketService struct {
+	client *RestClient
+}
+
+// Pair is the trading pair config, the pair is the symbol without the "t" prefix, e.g., BTCUSD or AVAX:USD
+type Pair struct {
+	Pair         string
+	MinOrderSize fixedpoint.Value
+	MaxOrderSize fixedpoint.Value
+}
+
+// Pairs returns the configs of the trading pairs: [[[PAIR, [_, _, _, MIN_ORDER_SIZE, MAX_ORDER_SIZE, ...]], ...]]
+func (s *MarketService) Pairs(ctx context.Context) ([]Pair, error) {
+	var resp []Row
+	if err := s.client.get(ctx, "/v2/conf/pub:info:pair", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp) == 0 {
+		return nil, nil
+	}
+
+	// the list of the pairs is the first element of the response
+	var pairs []Pair
+	for i := range resp[0] {
+		r := resp[0].Row(i)
+		info := r.Row(1)
+		pairs = append(pairs, Pair{
+			Pair:         r.String(0),
+			MinOrderSize: info.Value(3),
+			MaxOrderSize: info.Value(4),
+		})
+	}
+
+	return pairs, nil
+}
+
+type Ticker struct {
+	Bid       fixedpoint.Value
+	BidSize   fixedpoint.Value
+	Ask       fixedpoint.Value
+	AskSize   fixedpoint.Value
+	LastPrice fixedpoint.Value
+	Volume    fixedpoint.Value
+	High      fixedpoint.Value
+	Low       fixedpoint.Value
+}
+
+// UnmarshalJSON parses [BID, BID_SIZE, ASK, ASK_SIZE, DAILY_CHANGE, DAILY_CHANGE_RELATIVE, LAST_PRICE, VOLUME, HIGH, LOW]
+func (t *Ticker) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*t = Ticker{
+		Bid:       r.Value(0),
+		BidSize:   r.Value(1),
+		Ask:       r.Value(2),
+		AskSize:   r.Value(3),
+		LastPrice: r.Value(6),
+		Volume:    r.Value(7),
+		High:      r.Value(8),
+		Low:       r.Value(9),
+	}
+	return nil
+}
+
+func (s *MarketService) Ticker(ctx context.Context, symbol string) (*Ticker, error) {
+	var ticker Ticker
+	err := s.client.get(ctx, "/v2/ticker/"+url.PathEscape(symbol), nil, &ticker)
+	return &ticker, err
+}
+
+type Candle struct {
+	Time   time.Time
+	Open   fixedpoint.Value
+	Close  fixedpoint.Value
+	High   fixedpoint.Value
+	Low    fixedpoint.Value
+	Volume fixedpoint.Value
+}
+
+// UnmarshalJSON parses [MTS, OPEN, CLOSE, HIGH, LOW, VOLUME]
+func (c *Candle) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*c = Candle{
+		Time:   r.Time(0),
+		Open:   r.Value(1),
+		Close:  r.Value(2),
+		High:   r.Value(3),
+		Low:    r.Value(4),
+		Volume: r.Value(5),
+	}
+	return nil
+}
+
+// Candles returns the klines in the time range in the ascending order, at most 10000 klines per request.
+func (s *MarketService) Candles(ctx context.Context, symbol, timeFrame string, startTime, endTime time.Time, limit int) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("sort", "1")
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("start", strconv.FormatInt(startTime.UnixNano()/int64(time.Millisecond), 10))
+	params.Set("end", strconv.FormatInt(endTime.UnixNano()/int64(time.Millisecond), 10))
+
+	var candles []Candle
+	err := s.client.get(ctx, "/v2/candles/trade:"+timeFrame+":"+symbol+"/hist", params, &candles)
+	return candles, err
+}
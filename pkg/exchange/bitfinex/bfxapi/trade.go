@@ -0,0 +1,194 @@
+package bfxapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type OrderType string
+
+// the order types of the exchange wallet, the order types without the EXCHANGE prefix are the margin orders
+const (
+	OrderTypeExchangeLimit     OrderType = "EXCHANGE LIMIT"
+	OrderTypeExchangeMarket    OrderType = "EXCHANGE MARKET"
+	OrderTypeExchangeStop      OrderType = "EXCHANGE STOP"
+	OrderTypeExchangeStopLimit OrderType = "EXCHANGE STOP LIMIT"
+)
+
+type TradeService struct {
+	client *RestClient
+}
+
+// Order is the order row, the amount is positive for the buy orders and negative for the sell orders,
+// the status is a text with the detail, e.g., "EXECUTED @ 107.6(-0.2)" or "CANCELED was: PARTIALLY FILLED @ 107.6(-0.2)".
+type Order struct {
+	ID            int64
+	GroupID       int64
+	ClientOrderID int64
+	Symbol        string
+	CreatedTime   time.Time
+	UpdatedTime   time.Time
+	Amount        fixedpoint.Value
+	AmountOrig    fixedpoint.Value
+	Type          OrderType
+	Status        string
+	Price         fixedpoint.Value
+	PriceAverage  fixedpoint.Value
+	PriceAuxLimit fixedpoint.Value
+}
+
+// UnmarshalJSON parses [ID, GID, CID, SYMBOL, MTS_CREATE, MTS_UPDATE, AMOUNT, AMOUNT_ORIG, ORDER_TYPE, TYPE_PREV, MTS_TIF, _, FLAGS, ORDER_STATUS, _, _, PRICE, PRICE_AVG, PRICE_TRAILING, PRICE_AUX_LIMIT, ...]
+func (o *Order) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*o = Order{
+		ID:            r.Int64(0),
+		GroupID:       r.Int64(1),
+		ClientOrderID: r.Int64(2),
+		Symbol:        r.String(3),
+		CreatedTime:   r.Time(4),
+		UpdatedTime:   r.Time(5),
+		Amount:        r.Value(6),
+		AmountOrig:    r.Value(7),
+		Type:          OrderType(r.String(8)),
+		Status:        r.String(13),
+		Price:         r.Value(16),
+		PriceAverage:  r.Value(17),
+		PriceAuxLimit: r.Value(19),
+	}
+	return nil
+}
+
+// StatusCode returns the status without the detail, e.g., EXECUTED, PARTIALLY FILLED or CANCELED
+func (o Order) StatusCode() string {
+	status := o.Status
+	if idx := strings.Index(status, " @"); idx >= 0 {
+		status = status[:idx]
+	}
+
+	if idx := strings.Index(status, " was:"); idx >= 0 {
+		status = status[:idx]
+	}
+
+	return strings.TrimSpace(status)
+}
+
+// SubmitOrderRequest is the payload of the order creation, the numbers are sent as strings.
+// For the stop limit order, the price is the stop price and the price aux limit is the limit price.
+type SubmitOrderRequest struct {
+	Type          OrderType `json:"type"`
+	Symbol        string    `json:"symbol"`
+	Amount        string    `json:"amount"`
+	Price         string    `json:"price,omitempty"`
+	PriceAuxLimit string    `json:"price_aux_limit,omitempty"`
+	ClientOrderID int64     `json:"cid,omitempty"`
+	GroupID       int64     `json:"gid,omitempty"`
+}
+
+func (s *TradeService) SubmitOrder(ctx context.Context, req SubmitOrderRequest) ([]Order, error) {
+	var orders []Order
+	err := s.client.sendNotificationRequest(ctx, "auth/w/order/submit", req, &orders)
+	return orders, err
+}
+
+// UpdateOrderRequest is the payload of the order update, the amount is the new remaining amount of the order,
+// the empty fields are not changed.
+type UpdateOrderRequest struct {
+	ID     int64  `json:"id"`
+	Amount string `json:"amount,omitempty"`
+	Price  string `json:"price,omitempty"`
+}
+
+// UpdateOrder modifies the active order in place, the order keeps its position in the book if only the amount is reduced
+func (s *TradeService) UpdateOrder(ctx context.Context, req UpdateOrderRequest) (*Order, error) {
+	var order Order
+	if err := s.client.sendNotificationRequest(ctx, "auth/w/order/update", req, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (s *TradeService) CancelOrder(ctx context.Context, orderID int64) error {
+	return s.client.sendNotificationRequest(ctx, "auth/w/order/cancel", map[string]interface{}{"id": orderID}, nil)
+}
+
+// CancelOrderByClientOrderID cancels the order by the client order ID and the UTC date of the order creation
+func (s *TradeService) CancelOrderByClientOrderID(ctx context.Context, clientOrderID int64, createdTime time.Time) error {
+	return s.client.sendNotificationRequest(ctx, "auth/w/order/cancel", map[string]interface{}{
+		"cid":      clientOrderID,
+		"cid_date": createdTime.UTC().Format("2006-01-02"),
+	}, nil)
+}
+
+// ActiveOrders returns the active orders of the symbol
+func (s *TradeService) ActiveOrders(ctx context.Context, symbol string) ([]Order, error) {
+	var orders []Order
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/orders/"+url.PathEscape(symbol), nil, &orders)
+	return orders, err
+}
+
+// OrderHistory returns the closed orders of the symbol in the time range, only the orders of the last 2 weeks are kept
+func (s *TradeService) OrderHistory(ctx context.Context, symbol string, startTime, endTime time.Time) ([]Order, error) {
+	var orders []Order
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/orders/"+url.PathEscape(symbol)+"/hist", timeRangePayload(startTime, endTime, 2500), &orders)
+	return orders, err
+}
+
+// Trade is the trade row of the order, the exec amount is negative for the sell trades and the fee is negative
+type Trade struct {
+	ID            int64
+	Symbol        string
+	CreatedTime   time.Time
+	OrderID       int64
+	ExecAmount    fixedpoint.Value
+	ExecPrice     fixedpoint.Value
+	OrderType     OrderType
+	OrderPrice    fixedpoint.Value
+	Maker         bool
+	Fee           fixedpoint.Value
+	FeeCurrency   string
+	ClientOrderID int64
+}
+
+// UnmarshalJSON parses [ID, SYMBOL, MTS_CREATE, ORDER_ID, EXEC_AMOUNT, EXEC_PRICE, ORDER_TYPE, ORDER_PRICE, MAKER, FEE, FEE_CURRENCY, CID]
+func (t *Trade) UnmarshalJSON(data []byte) error {
+	var r Row
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+
+	*t = Trade{
+		ID:            r.Int64(0),
+		Symbol:        r.String(1),
+		CreatedTime:   r.Time(2),
+		OrderID:       r.Int64(3),
+		ExecAmount:    r.Value(4),
+		ExecPrice:     r.Value(5),
+		OrderType:     OrderType(r.String(6)),
+		OrderPrice:    r.Value(7),
+		Maker:         r.Int64(8) == 1,
+		Fee:           r.Value(9),
+		FeeCurrency:   r.String(10),
+		ClientOrderID: r.Int64(11),
+	}
+	return nil
+}
+
+// Trades returns the trades of the symbol in the time range in the ascending order
+func (s *TradeService) Trades(ctx context.Context, symbol string, startTime, endTime time.Time, limit int) ([]Trade, error) {
+	payload := timeRangePayload(startTime, endTime, limit)
+	payload["sort"] = 1
+
+	var trades []Trade
+	err := s.client.sendAuthenticatedRequest(ctx, "auth/r/trades/"+url.PathEscape(symbol)+"/hist", payload, &trades)
+	return trades, err
+}
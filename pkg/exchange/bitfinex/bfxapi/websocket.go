@@ -0,0 +1,137 @@
+package bfxapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	// PublicWebSocketURL is the endpoint of the public channels
+	PublicWebSocketURL = "wss://api-pub.bitfinex.com/ws/2"
+
+	// WebSocketURL is the endpoint that accepts the authentication, the public channels are available as well
+	WebSocketURL = "wss://api.bitfinex.com/ws/2"
+)
+
+const ChannelCandles = "candles"
+
+// the message types of the account channel (channel 0)
+const (
+	MessageTypeHeartbeat      = "hb"
+	MessageTypeOrderSnapshot  = "os"
+	MessageTypeOrderNew       = "on"
+	MessageTypeOrderUpdate    = "ou"
+	MessageTypeOrderCancel    = "oc"
+	MessageTypeTradeExecuted  = "te"
+	MessageTypeTradeUpdate    = "tu"
+	MessageTypeWalletSnapshot = "ws"
+	MessageTypeWalletUpdate   = "wu"
+)
+
+type WebSocketCommand struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel,omitempty"`
+	Key     string `json:"key,omitempty"`
+	CID     int64  `json:"cid,omitempty"`
+
+	// the fields of the authentication
+	APIKey      string   `json:"apiKey,omitempty"`
+	AuthSig     string   `json:"authSig,omitempty"`
+	AuthPayload string   `json:"authPayload,omitempty"`
+	AuthNonce   string   `json:"authNonce,omitempty"`
+	Filter      []string `json:"filter,omitempty"`
+}
+
+// NewCandleSubscribeCommand subscribes the candles of the key, e.g., trade:1m:tBTCUSD
+func NewCandleSubscribeCommand(key string) WebSocketCommand {
+	return WebSocketCommand{
+		Event:   "subscribe",
+		Channel: ChannelCandles,
+		Key:     key,
+	}
+}
+
+// NewAuthCommand authenticates the connection, the signature is hex(hmac_sha384("AUTH" + nonce)),
+// the filter limits the account messages to the trading and the wallet messages.
+func NewAuthCommand(key, secret string) WebSocketCommand {
+	nonce := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+	payload := "AUTH" + nonce
+	return WebSocketCommand{
+		Event:       "auth",
+		APIKey:      key,
+		AuthSig:     Sign(secret, payload),
+		AuthPayload: payload,
+		AuthNonce:   nonce,
+		Filter:      []string{"trading", "wallet"},
+	}
+}
+
+func NewPingCommand() WebSocketCommand {
+	return WebSocketCommand{
+		Event: "ping",
+		CID:   time.Now().UnixNano() / int64(time.Millisecond),
+	}
+}
+
+// WebSocketEvent is the object message of the info, subscribed, auth and error events
+type WebSocketEvent struct {
+	Event     string `json:"event"`
+	Channel   string `json:"channel"`
+	ChannelID int64  `json:"chanId"`
+	Key       string `json:"key"`
+	Status    string `json:"status"`
+	Code      int64  `json:"code"`
+	Message   string `json:"msg"`
+}
+
+// ChannelMessage is the array message of the channels: [CHAN_ID, DATA] for the public channels,
+// [0, TYPE, DATA] for the account channel and [CHAN_ID, "hb"] for the heartbeats.
+type ChannelMessage struct {
+	ChannelID int64
+	Type      string
+	Data      json.RawMessage
+}
+
+// ParseMessage parses the message into *WebSocketEvent or *ChannelMessage
+func ParseMessage(message []byte) (interface{}, error) {
+	if len(message) > 0 && message[0] == '{' {
+		var e WebSocketEvent
+		err := json.Unmarshal(message, &e)
+		return &e, err
+	}
+
+	var r Row
+	if err := json.Unmarshal(message, &r); err != nil {
+		return nil, err
+	}
+
+	m := &ChannelMessage{ChannelID: r.Int64(0)}
+	if t := r.String(1); len(t) > 0 {
+		m.Type = t
+		m.Data = r.field(2)
+	} else {
+		m.Data = r.field(1)
+	}
+
+	return m, nil
+}
+
+// ParseCandles parses the candle channel data, the snapshot is the array of the candles and the update is one candle
+func ParseCandles(data json.RawMessage) ([]Candle, error) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+
+	// the update is a flat array of the numbers
+	if len(rows) > 0 && rows[0][0] != '[' {
+		var c Candle
+		err := json.Unmarshal(data, &c)
+		return []Candle{c}, err
+	}
+
+	var candles []Candle
+	err := json.Unmarshal(data, &candles)
+	return candles, err
+}
@@ -0,0 +1,377 @@
+package bitfinex
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// localCurrencies is the currencies that are renamed on Bitfinex
+var localCurrencies = map[string]string{
+	"USDT": "UST",
+	"USDC": "UDC",
+	"DASH": "DSH",
+	"IOTA": "IOT",
+	"QTUM": "QTM",
+}
+
+func toGlobalCurrency(currency string) string {
+	currency = strings.ToUpper(currency)
+	for global, local := range localCurrencies {
+		if local == currency {
+			return global
+		}
+	}
+
+	return currency
+}
+
+func toLocalCurrency(currency string) string {
+	currency = strings.ToUpper(currency)
+	if local, ok := localCurrencies[currency]; ok {
+		return local
+	}
+
+	return currency
+}
+
+// toLocalPair joins the local currencies, the currencies are separated by a colon if any of them is longer than 3 letters
+func toLocalPair(base, quote string) string {
+	base, quote = toLocalCurrency(base), toLocalCurrency(quote)
+	if len(base) > 3 || len(quote) > 3 {
+		return base + ":" + quote
+	}
+
+	return base + quote
+}
+
+// toLocalSymbol converts the symbol to the trading symbol of Bitfinex, e.g., BTCUSDT => tBTCUST, AVAXUSD => tAVAX:USD,
+// the market map is used for finding the base currency of the symbol.
+func toLocalSymbol(symbol string, markets types.MarketMap) string {
+	if market, ok := markets[symbol]; ok {
+		return "t" + toLocalPair(market.BaseCurrency, market.QuoteCurrency)
+	}
+
+	for _, quote := range []string{"USDT", "USDC", "USD", "EUR", "GBP", "JPY", "BTC", "ETH"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return "t" + toLocalPair(symbol[:len(symbol)-len(quote)], quote)
+		}
+	}
+
+	return "t" + symbol
+}
+
+// splitPair splits the pair without the "t" prefix into the global base and quote currencies
+func splitPair(pair string) (base, quote string) {
+	if idx := strings.Index(pair, ":"); idx >= 0 {
+		return toGlobalCurrency(pair[:idx]), toGlobalCurrency(pair[idx+1:])
+	}
+
+	if len(pair) == 6 {
+		return toGlobalCurrency(pair[:3]), toGlobalCurrency(pair[3:])
+	}
+
+	return pair, ""
+}
+
+func toGlobalSymbol(symbol string) string {
+	base, quote := splitPair(strings.TrimPrefix(symbol, "t"))
+	return base + quote
+}
+
+// toFundingSymbol converts the currency to the funding symbol, e.g., USD => fUSD
+func toFundingSymbol(currency string) string {
+	return "f" + toLocalCurrency(currency)
+}
+
+var localIntervals = map[types.Interval]string{
+	types.Interval1m:  "1m",
+	types.Interval5m:  "5m",
+	types.Interval15m: "15m",
+	types.Interval30m: "30m",
+	types.Interval1h:  "1h",
+	types.Interval6h:  "6h",
+	types.Interval12h: "12h",
+	types.Interval1d:  "1D",
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	if s, ok := localIntervals[interval]; ok {
+		return s, nil
+	}
+
+	return "", fmt.Errorf("bitfinex does not support the interval %s", interval)
+}
+
+func toGlobalInterval(timeFrame string) types.Interval {
+	for i, s := range localIntervals {
+		if s == timeFrame {
+			return i
+		}
+	}
+
+	return types.Interval(timeFrame)
+}
+
+// toLocalClientOrderID converts the client order ID to the integer cid of Bitfinex,
+// the numeric client order ID is used as it is, otherwise the hashed ID is used.
+func toLocalClientOrderID(clientOrderID string) int64 {
+	if id, err := strconv.ParseInt(clientOrderID, 10, 64); err == nil && id > 0 {
+		return id
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(clientOrderID))
+
+	// cid is limited to 45 bits
+	return int64(h.Sum64() & (1<<45 - 1))
+}
+
+func toLocalOrderType(orderType types.OrderType) (bfxapi.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		return bfxapi.OrderTypeExchangeLimit, nil
+
+	case types.OrderTypeMarket:
+		return bfxapi.OrderTypeExchangeMarket, nil
+
+	case types.OrderTypeStopLimit:
+		return bfxapi.OrderTypeExchangeStopLimit, nil
+
+	case types.OrderTypeStopMarket:
+		return bfxapi.OrderTypeExchangeStop, nil
+	}
+
+	return "", fmt.Errorf("order type %s not supported", orderType)
+}
+
+func toGlobalOrderType(orderType bfxapi.OrderType) types.OrderType {
+	switch strings.TrimPrefix(string(orderType), "EXCHANGE ") {
+	case "LIMIT":
+		return types.OrderTypeLimit
+
+	case "MARKET":
+		return types.OrderTypeMarket
+
+	case "STOP LIMIT":
+		return types.OrderTypeStopLimit
+
+	case "STOP":
+		return types.OrderTypeStopMarket
+	}
+
+	log.Errorf("unknown order type: %v", orderType)
+	return types.OrderType(orderType)
+}
+
+// toLocalAmount converts the quantity to the signed amount, the amount is negative for selling
+func toLocalAmount(side types.SideType, quantity string) string {
+	if side == types.SideTypeSell && !strings.HasPrefix(quantity, "-") {
+		return "-" + quantity
+	}
+
+	return quantity
+}
+
+// formatPrice formats the price with 5 significant digits, which is the price precision of Bitfinex
+func formatPrice(price float64) string {
+	if price == 0 {
+		return "0"
+	}
+
+	digits := 5 - int(math.Floor(math.Log10(math.Abs(price)))) - 1
+	scale := math.Pow(10, float64(digits))
+	return strconv.FormatFloat(math.Round(price*scale)/scale, 'f', -1, 64)
+}
+
+func toGlobalOrderStatus(o bfxapi.Order) types.OrderStatus {
+	status := o.StatusCode()
+	switch {
+	case status == "ACTIVE":
+		if o.Amount != o.AmountOrig {
+			return types.OrderStatusPartiallyFilled
+		}
+
+		return types.OrderStatusNew
+
+	case status == "PARTIALLY FILLED":
+		return types.OrderStatusPartiallyFilled
+
+	case status == "EXECUTED":
+		return types.OrderStatusFilled
+
+	case strings.HasSuffix(status, "CANCELED"):
+		return types.OrderStatusCanceled
+	}
+
+	// INSUFFICIENT MARGIN, INSUFFICIENT BALANCE and the RSN_ statuses
+	return types.OrderStatusRejected
+}
+
+func isWorkingOrder(o bfxapi.Order) bool {
+	status := o.StatusCode()
+	return status == "ACTIVE" || status == "PARTIALLY FILLED"
+}
+
+func toGlobalOrder(o bfxapi.Order) types.Order {
+	side := types.SideTypeBuy
+	if o.AmountOrig < 0 {
+		side = types.SideTypeSell
+	}
+
+	orderType := toGlobalOrderType(o.Type)
+
+	// the price of the stop orders is the stop price, the limit price of the stop limit order is the price aux limit
+	price, stopPrice := o.Price, fixedpoint.Value(0)
+	switch orderType {
+	case types.OrderTypeStopLimit:
+		price, stopPrice = o.PriceAuxLimit, o.Price
+
+	case types.OrderTypeStopMarket:
+		price, stopPrice = 0, o.Price
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: strconv.FormatInt(o.ClientOrderID, 10),
+			Symbol:        toGlobalSymbol(o.Symbol),
+			Side:          side,
+			Type:          orderType,
+			Quantity:      math.Abs(o.AmountOrig.Float64()),
+			Price:         price.Float64(),
+			StopPrice:     stopPrice.Float64(),
+			TimeInForce:   "GTC",
+			GroupID:       o.GroupID,
+		},
+		Exchange:         types.ExchangeBitfinex.String(),
+		OrderID:          uint64(o.ID),
+		Status:           toGlobalOrderStatus(o),
+		ExecutedQuantity: math.Abs((o.AmountOrig - o.Amount).Float64()),
+		IsWorking:        isWorkingOrder(o),
+		CreationTime:     o.CreatedTime,
+		UpdateTime:       o.UpdatedTime,
+	}
+}
+
+// toGlobalTrade converts the trade, the fee of Bitfinex is negative
+func toGlobalTrade(t bfxapi.Trade) types.Trade {
+	side := types.SideTypeBuy
+	if t.ExecAmount < 0 {
+		side = types.SideTypeSell
+	}
+
+	quantity := math.Abs(t.ExecAmount.Float64())
+	return types.Trade{
+		ID:            t.ID,
+		OrderID:       uint64(t.OrderID),
+		Exchange:      types.ExchangeBitfinex.String(),
+		Price:         t.ExecPrice.Float64(),
+		Quantity:      quantity,
+		QuoteQuantity: t.ExecPrice.Float64() * quantity,
+		Symbol:        toGlobalSymbol(t.Symbol),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       t.Maker,
+		Time:          t.CreatedTime,
+		Fee:           math.Abs(t.Fee.Float64()),
+		FeeCurrency:   toGlobalCurrency(t.FeeCurrency),
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, candle bfxapi.Candle) types.KLine {
+	return types.KLine{
+		Exchange:    types.ExchangeBitfinex.String(),
+		Symbol:      symbol,
+		StartTime:   candle.Time,
+		EndTime:     candle.Time.Add(interval.Duration() - time.Millisecond),
+		Interval:    interval,
+		Open:        candle.Open.Float64(),
+		Close:       candle.Close.Float64(),
+		High:        candle.High.Float64(),
+		Low:         candle.Low.Float64(),
+		Volume:      candle.Volume.Float64(),
+		QuoteVolume: candle.Volume.Float64() * candle.Close.Float64(),
+	}
+}
+
+func toGlobalDepositStatus(status string) types.DepositStatus {
+	switch status {
+	case "COMPLETED":
+		return types.DepositSuccess
+
+	case "PROCESSING", "PENDING", "UNCONFIRMED":
+		return types.DepositPending
+
+	case "CANCELED":
+		return types.DepositCancelled
+	}
+
+	return types.DepositStatus(strings.ToLower(status))
+}
+
+func toGlobalWithdrawStatus(status string) string {
+	switch status {
+	case "COMPLETED":
+		return "completed" // make it compatible with binance
+
+	case "PROCESSING", "PENDING", "SENDING":
+		return "processing"
+
+	case "CANCELED":
+		return "cancelled"
+	}
+
+	return strings.ToLower(status)
+}
+
+func toGlobalLendingOfferStatus(status string) types.LendingOfferStatus {
+	switch {
+	case strings.HasPrefix(status, "ACTIVE"):
+		return types.LendingOfferStatusActive
+
+	case strings.HasPrefix(status, "PARTIALLY FILLED"):
+		return types.LendingOfferStatusPartiallyFilled
+
+	case strings.HasPrefix(status, "EXECUTED"):
+		return types.LendingOfferStatusExecuted
+	}
+
+	return types.LendingOfferStatusCanceled
+}
+
+func toGlobalLendingOffer(o bfxapi.Offer) types.LendingOffer {
+	return types.LendingOffer{
+		SubmitLendingOffer: types.SubmitLendingOffer{
+			Currency: toGlobalCurrency(strings.TrimPrefix(o.Symbol, "f")),
+			Amount:   o.AmountOrig,
+			Rate:     o.Rate,
+			Period:   o.Period,
+		},
+		Exchange:        types.ExchangeBitfinex,
+		OfferID:         uint64(o.ID),
+		Status:          toGlobalLendingOfferStatus(o.Status),
+		RemainingAmount: o.Amount,
+		CreationTime:    o.CreatedTime,
+		UpdateTime:      o.UpdatedTime,
+	}
+}
+
+func toGlobalLendingCredit(c bfxapi.Credit) types.LendingCredit {
+	return types.LendingCredit{
+		Exchange:       types.ExchangeBitfinex,
+		CreditID:       uint64(c.ID),
+		Currency:       toGlobalCurrency(strings.TrimPrefix(c.Symbol, "f")),
+		Amount:         c.Amount,
+		Rate:           c.Rate,
+		Period:         c.Period,
+		OpeningTime:    c.OpeningTime,
+		LastPayoutTime: c.LastPayoutTime,
+	}
+}
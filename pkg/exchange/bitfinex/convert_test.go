@@ -0,0 +1,81 @@
+package bitfinex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSymbolConversion(t *testing.T) {
+	markets := types.MarketMap{
+		"AVAXUSDT": {Symbol: "AVAXUSDT", BaseCurrency: "AVAX", QuoteCurrency: "USDT"},
+	}
+
+	assert.Equal(t, "tAVAX:UST", toLocalSymbol("AVAXUSDT", markets))
+	assert.Equal(t, "tBTCUST", toLocalSymbol("BTCUSDT", nil))
+	assert.Equal(t, "tETHBTC", toLocalSymbol("ETHBTC", nil))
+	assert.Equal(t, "BTCUSDT", toGlobalSymbol("tBTCUST"))
+	assert.Equal(t, "AVAXUSD", toGlobalSymbol("tAVAX:USD"))
+	assert.Equal(t, "fUST", toFundingSymbol("USDT"))
+}
+
+func TestFormatPrice(t *testing.T) {
+	assert.Equal(t, "34568", formatPrice(34567.89))
+	assert.Equal(t, "1.2346", formatPrice(1.23456))
+	assert.Equal(t, "0.00012346", formatPrice(0.000123456))
+}
+
+func TestToLocalClientOrderID(t *testing.T) {
+	assert.Equal(t, int64(12345), toLocalClientOrderID("12345"))
+
+	id := toLocalClientOrderID("c1a7a1b4-1a63-4b0e-b6ad-5e3a1bd0e3f6")
+	assert.True(t, id > 0 && id < 1<<45)
+	assert.Equal(t, id, toLocalClientOrderID("c1a7a1b4-1a63-4b0e-b6ad-5e3a1bd0e3f6"))
+}
+
+func TestToGlobalOrder(t *testing.T) {
+	var o bfxapi.Order
+	err := json.Unmarshal([]byte(`[41238905042,null,1630479893000,"tBTCUST",1630479893000,1630479894000,-0.3,-0.5,"EXCHANGE STOP LIMIT",null,null,null,0,"PARTIALLY FILLED @ 47000.0(-0.2)",null,null,47100,47000,0,47050,null,null,null,0,0,null,null,null,"API>BFX",null,null,null]`), &o)
+	assert.NoError(t, err)
+
+	order := toGlobalOrder(o)
+	assert.Equal(t, "BTCUSDT", order.Symbol)
+	assert.Equal(t, types.SideTypeSell, order.Side)
+	assert.Equal(t, types.OrderTypeStopLimit, order.Type)
+	assert.Equal(t, 0.5, order.Quantity)
+	assert.Equal(t, 47050.0, order.Price)
+	assert.Equal(t, 47100.0, order.StopPrice)
+	assert.InDelta(t, 0.2, order.ExecutedQuantity, 1e-9)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.True(t, order.IsWorking)
+	assert.Equal(t, uint64(41238905042), order.OrderID)
+}
+
+func TestToGlobalTrade(t *testing.T) {
+	var tr bfxapi.Trade
+	err := json.Unmarshal([]byte(`[402088407,"tETHUST",1574963975602,34938060782,-0.2,153.57,"EXCHANGE MARKET",0,-1,-0.061668,"UST",1574963975496]`), &tr)
+	assert.NoError(t, err)
+
+	trade := toGlobalTrade(tr)
+	assert.Equal(t, "ETHUSDT", trade.Symbol)
+	assert.Equal(t, types.SideTypeSell, trade.Side)
+	assert.Equal(t, 0.2, trade.Quantity)
+	assert.Equal(t, 0.061668, trade.Fee)
+	assert.Equal(t, "USDT", trade.FeeCurrency)
+	assert.False(t, trade.IsMaker)
+}
+
+func TestParseCandles(t *testing.T) {
+	candles, err := bfxapi.ParseCandles(json.RawMessage(`[1574698260000,7379.785503,7383.8,7388.3,7379.785503,1.68829482]`))
+	assert.NoError(t, err)
+	assert.Len(t, candles, 1)
+	assert.Equal(t, 7383.8, candles[0].Close.Float64())
+
+	candles, err = bfxapi.ParseCandles(json.RawMessage(`[[1574698260000,7379.785503,7383.8,7388.3,7379.785503,1.68829482],[1574698200000,7399.9,7379.7,7399.9,7371.8,41.63633658]]`))
+	assert.NoError(t, err)
+	assert.Len(t, candles, 2)
+}
@@ -0,0 +1,468 @@
+package bitfinex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "bitfinex")
+
+// maxCandles is the max number of the candles returned by one candle request
+const maxCandles = 10000
+
+// maxHistoryRecords is the max number of the records returned by one history request
+const maxHistoryRecords = 2500
+
+// pricePrecision is the decimal precision of the market, the prices are rounded to 5 significant digits when the orders are submitted
+const pricePrecision = 8
+
+type Exchange struct {
+	client      *bfxapi.RestClient
+	key, secret string
+
+	marketsMu sync.Mutex
+	markets   types.MarketMap
+}
+
+func New(key, secret string) *Exchange {
+	baseURL := bfxapi.ProductionAPIURL
+	if override := os.Getenv("BITFINEX_API_BASE_URL"); len(override) > 0 {
+		baseURL = override
+	}
+
+	client := bfxapi.NewRestClient(baseURL)
+	client.Auth(key, secret)
+	return &Exchange{
+		client: client,
+		key:    key,
+		secret: secret,
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeBitfinex
+}
+
+func (e *Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(types.CapabilityUserDataStream, types.CapabilityLending, types.CapabilityTransfer, types.CapabilityAmendOrder, types.CapabilityStopOrder)
+}
+
+// PlatformFeeCurrency returns empty since the fees of bitfinex are not paid by the platform token
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e)
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	log.Info("querying market info...")
+
+	pairs, err := e.client.MarketService.Pairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, p := range pairs {
+		// skip the paper trading pairs
+		if strings.HasPrefix(p.Pair, "TEST") {
+			continue
+		}
+
+		base, quote := splitPair(p.Pair)
+		if len(quote) == 0 {
+			continue
+		}
+
+		market := types.Market{
+			Symbol:          base + quote,
+			PricePrecision:  pricePrecision,
+			VolumePrecision: 8,
+			QuoteCurrency:   quote,
+			BaseCurrency:    base,
+			MinLot:          1e-8,
+			StepSize:        1e-8,
+			MinQuantity:     p.MinOrderSize.Float64(),
+			MaxQuantity:     p.MaxOrderSize.Float64(),
+			MinPrice:        1e-8,
+			TickSize:        1e-8,
+		}
+		markets[market.Symbol] = market
+	}
+
+	e.marketsMu.Lock()
+	e.markets = markets
+	e.marketsMu.Unlock()
+	return markets, nil
+}
+
+// localSymbol converts the global symbol with the queried markets, the markets are queried if they are not loaded yet
+func (e *Exchange) localSymbol(ctx context.Context, symbol string) string {
+	e.marketsMu.Lock()
+	markets := e.markets
+	e.marketsMu.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.QueryMarkets(ctx)
+		if err != nil {
+			log.WithError(err).Error("market query error")
+		}
+	}
+
+	return toLocalSymbol(symbol, markets)
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{
+		MakerCommission: 10, // 0.1%
+		TakerCommission: 20, // 0.2%
+	}
+
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+// QueryAccountBalances returns the balances of the exchange wallet, the margin and the funding wallets can not be used for the spot trading
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	wallets, err := e.client.AccountService.Wallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = make(types.BalanceMap)
+	for _, w := range wallets {
+		if w.Type != bfxapi.WalletTypeExchange {
+			continue
+		}
+
+		currency := toGlobalCurrency(w.Currency)
+		balances[currency] = types.Balance{
+			Currency:  currency,
+			Available: w.AvailableBalance,
+			Locked:    w.Balance - w.AvailableBalance,
+		}
+	}
+
+	return balances, nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	timeFrame, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit = maxCandles
+	if options.Limit > 0 && options.Limit < limit {
+		limit = options.Limit
+	}
+
+	var endTime = time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	// the time range is cut by the limit, so that the klines are queried forward from the start time
+	var window = time.Duration(limit) * interval.Duration()
+	var startTime = endTime.Add(-window)
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+		if startTime.Add(window).Before(endTime) {
+			endTime = startTime.Add(window)
+		}
+	}
+
+	log.Infof("querying kline %s %s %+v", symbol, interval, options)
+
+	candles, err := e.client.MarketService.Candles(ctx, e.localSymbol(ctx, symbol), timeFrame, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, candle := range candles {
+		kline := toGlobalKLine(symbol, interval, candle)
+		kline.Closed = kline.EndTime.Before(time.Now())
+		kLines = append(kLines, kline)
+	}
+
+	return kLines, nil
+}
+
+// QueryTrades queries the trades in the time range, the last trade ID option is not supported by the trade history api.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var startTime, endTime time.Time
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+	}
+
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	var limit = maxHistoryRecords
+	if options.Limit > 0 && options.Limit < int64(limit) {
+		limit = int(options.Limit)
+	}
+
+	remoteTrades, err := e.client.TradeService.Trades(ctx, e.localSymbol(ctx, symbol), startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range remoteTrades {
+		trades = append(trades, toGlobalTrade(t))
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	return trades, nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		orderType, err := toLocalOrderType(order.Type)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		clientOrderID := order.ClientOrderID
+		if len(clientOrderID) == 0 {
+			clientOrderID = uuid.New().String()
+		}
+
+		req := bfxapi.SubmitOrderRequest{
+			Type:          orderType,
+			Symbol:        e.localSymbol(ctx, order.Symbol),
+			Amount:        toLocalAmount(order.Side, order.QuantityString),
+			ClientOrderID: toLocalClientOrderID(clientOrderID),
+			GroupID:       order.GroupID,
+		}
+
+		switch order.Type {
+		case types.OrderTypeLimit:
+			req.Price = formatPrice(orderPrice(order))
+
+		case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+			if len(order.StopPriceString) == 0 {
+				return createdOrders, fmt.Errorf("stop price string can not be empty")
+			}
+
+			// the price of the stop orders is the stop price
+			stopPrice, err := strconv.ParseFloat(order.StopPriceString, 64)
+			if err != nil {
+				return createdOrders, err
+			}
+
+			req.Price = formatPrice(stopPrice)
+			if order.Type == types.OrderTypeStopLimit {
+				req.PriceAuxLimit = formatPrice(orderPrice(order))
+			}
+		}
+
+		remoteOrders, err := e.client.TradeService.SubmitOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		if len(remoteOrders) == 0 {
+			return createdOrders, errors.New("returned empty order")
+		}
+
+		createdOrders = append(createdOrders, toGlobalOrder(remoteOrders[0]))
+	}
+
+	return createdOrders, err
+}
+
+// orderPrice returns the price of the order, the price string is preferred since it's formatted by the market
+func orderPrice(order types.SubmitOrder) float64 {
+	if len(order.PriceString) > 0 {
+		if price, err := strconv.ParseFloat(order.PriceString, 64); err == nil {
+			return price
+		}
+	}
+
+	return order.Price
+}
+
+// AmendOrder updates the price and the remaining amount of the active order, the order is identified by the order id
+func (e *Exchange) AmendOrder(ctx context.Context, order types.Order, price, quantity float64) (*types.Order, error) {
+	if order.OrderID == 0 {
+		return nil, fmt.Errorf("order id is required for amending the order, order=%+v", order)
+	}
+
+	req := bfxapi.UpdateOrderRequest{ID: int64(order.OrderID)}
+	if price > 0 {
+		req.Price = formatPrice(price)
+	}
+
+	if quantity > 0 {
+		req.Amount = toLocalAmount(order.Side, strconv.FormatFloat(quantity, 'f', -1, 64))
+	}
+
+	remoteOrder, err := e.client.TradeService.UpdateOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	amended := toGlobalOrder(*remoteOrder)
+	return &amended, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	remoteOrders, err := e.client.TradeService.ActiveOrders(ctx, e.localSymbol(ctx, symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range remoteOrders {
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	return orders, nil
+}
+
+// QueryClosedOrders queries the closed orders in the time range, lastOrderID is not supported on bitfinex
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	remoteOrders, err := e.client.TradeService.OrderHistory(ctx, e.localSymbol(ctx, symbol), since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range remoteOrders {
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime)
+	})
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	for _, o := range orders {
+		var err error
+		if o.OrderID > 0 {
+			err = e.client.TradeService.CancelOrder(ctx, int64(o.OrderID))
+		} else if len(o.ClientOrderID) > 0 {
+			err = e.client.TradeService.CancelOrderByClientOrderID(ctx, toLocalClientOrderID(o.ClientOrderID), o.CreationTime)
+		} else {
+			return fmt.Errorf("order id or client order id is not defined, order=%+v", o)
+		}
+
+		if err != nil {
+			log.WithError(err).Errorf("order cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) queryMovements(ctx context.Context, asset string, since, until time.Time) ([]bfxapi.Movement, error) {
+	var currency string
+	if len(asset) > 0 {
+		currency = toLocalCurrency(asset)
+	}
+
+	return e.client.AccountService.Movements(ctx, currency, since, until)
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	log.Infof("querying deposit history %s: %s <=> %s", asset, since, until)
+
+	movements, err := e.queryMovements(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range movements {
+		// the amount of the deposit is positive
+		if m.Amount <= 0 {
+			continue
+		}
+
+		allDeposits = append(allDeposits, types.Deposit{
+			Time:          m.StartedTime,
+			Amount:        m.Amount.Float64(),
+			Asset:         toGlobalCurrency(m.Currency),
+			Address:       m.Address,
+			TransactionID: m.TransactionID,
+			Status:        toGlobalDepositStatus(m.Status),
+		})
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time)
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	log.Infof("querying withdraw %s: %s <=> %s", asset, since, until)
+
+	movements, err := e.queryMovements(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range movements {
+		// the amount of the withdrawal is negative
+		if m.Amount >= 0 {
+			continue
+		}
+
+		allWithdraws = append(allWithdraws, types.Withdraw{
+			ID:             strconv.FormatInt(m.ID, 10),
+			ApplyTime:      m.StartedTime,
+			Asset:          toGlobalCurrency(m.Currency),
+			Amount:         math.Abs(m.Amount.Float64()),
+			Address:        m.Address,
+			TransactionID:  m.TransactionID,
+			TransactionFee: math.Abs(m.Fees.Float64()),
+			Status:         toGlobalWithdrawStatus(m.Status),
+		})
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime)
+	})
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
+	ticker, err := e.client.MarketService.Ticker(ctx, e.localSymbol(ctx, symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	return (ticker.Bid.Float64() + ticker.Ask.Float64()) / 2, nil
+}
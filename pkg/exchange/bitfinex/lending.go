@@ -0,0 +1,129 @@
+package bitfinex
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// lendingBookDepth is the number of the price levels of the funding book
+const lendingBookDepth = 100
+
+// minLendingPeriod and maxLendingPeriod is the range of the funding offer period in days
+const (
+	minLendingPeriod = 2
+	maxLendingPeriod = 120
+)
+
+func (e *Exchange) QueryLendingTicker(ctx context.Context, currency string) (*types.LendingTicker, error) {
+	ticker, err := e.client.FundingService.Ticker(ctx, toFundingSymbol(currency))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LendingTicker{
+		Currency:        currency,
+		FlashReturnRate: ticker.FlashReturnRate,
+		BidRate:         ticker.Bid,
+		BidPeriod:       ticker.BidPeriod,
+		BidAmount:       ticker.BidSize,
+		AskRate:         ticker.Ask,
+		AskPeriod:       ticker.AskPeriod,
+		AskAmount:       ticker.AskSize,
+		LastRate:        ticker.LastPrice,
+		Volume:          ticker.Volume,
+		HighRate:        ticker.High,
+		LowRate:         ticker.Low,
+	}, nil
+}
+
+// QueryLendingBook queries the funding book, the entries with the positive amount are the offers (asks),
+// and the entries with the negative amount are the bids of the borrowers.
+func (e *Exchange) QueryLendingBook(ctx context.Context, currency string) (*types.LendingBook, error) {
+	entries, err := e.client.FundingService.Book(ctx, toFundingSymbol(currency), lendingBookDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &types.LendingBook{Currency: currency}
+	for _, entry := range entries {
+		bookEntry := types.LendingBookEntry{
+			Rate:   entry.Rate,
+			Period: entry.Period,
+			Count:  entry.Count,
+			Amount: entry.Amount,
+		}
+
+		if entry.Amount > 0 {
+			book.Asks = append(book.Asks, bookEntry)
+		} else {
+			bookEntry.Amount = -entry.Amount
+			book.Bids = append(book.Bids, bookEntry)
+		}
+	}
+
+	return book, nil
+}
+
+func (e *Exchange) SubmitLendingOffer(ctx context.Context, offer types.SubmitLendingOffer) (*types.LendingOffer, error) {
+	if offer.Period < minLendingPeriod || offer.Period > maxLendingPeriod {
+		return nil, fmt.Errorf("lending period %d is out of the range %d ~ %d days", offer.Period, minLendingPeriod, maxLendingPeriod)
+	}
+
+	if offer.Amount <= 0 || offer.Rate <= 0 {
+		return nil, fmt.Errorf("invalid lending offer amount %f or rate %f", offer.Amount.Float64(), offer.Rate.Float64())
+	}
+
+	remoteOffer, err := e.client.FundingService.SubmitOffer(ctx, bfxapi.SubmitOfferRequest{
+		Symbol: toFundingSymbol(offer.Currency),
+		Amount: strconv.FormatFloat(offer.Amount.Float64(), 'f', -1, 64),
+		Rate:   strconv.FormatFloat(offer.Rate.Float64(), 'f', -1, 64),
+		Period: offer.Period,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lendingOffer := toGlobalLendingOffer(*remoteOffer)
+	return &lendingOffer, nil
+}
+
+func (e *Exchange) CancelLendingOffers(ctx context.Context, offers ...types.LendingOffer) (err2 error) {
+	for _, o := range offers {
+		if err := e.client.FundingService.CancelOffer(ctx, int64(o.OfferID)); err != nil {
+			log.WithError(err).Errorf("lending offer cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) QueryLendingOffers(ctx context.Context, currency string) (offers []types.LendingOffer, err error) {
+	remoteOffers, err := e.client.FundingService.ActiveOffers(ctx, toFundingSymbol(currency))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range remoteOffers {
+		offers = append(offers, toGlobalLendingOffer(o))
+	}
+
+	return offers, nil
+}
+
+func (e *Exchange) QueryLendingCredits(ctx context.Context, currency string) (credits []types.LendingCredit, err error) {
+	remoteCredits, err := e.client.FundingService.Credits(ctx, toFundingSymbol(currency))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range remoteCredits {
+		credits = append(credits, toGlobalLendingCredit(c))
+	}
+
+	return credits, nil
+}
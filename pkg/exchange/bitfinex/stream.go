@@ -0,0 +1,381 @@
+package bitfinex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	Conn     *websocket.Conn
+	connLock sync.Mutex
+
+	publicOnly bool
+
+	// channelKeys maps the channel ID of the subscribed candle channel to the candle key, e.g., trade:1m:tBTCUSD
+	channelKeys map[int64]string
+
+	// candles is the last candle of each candle key,
+	// bitfinex doesn't push the closed candle, so the last candle is closed when the candle of the next period is pushed.
+	candles map[string]types.KLine
+}
+
+func NewStream(exchange *Exchange) *Stream {
+	return &Stream{
+		exchange:    exchange,
+		channelKeys: make(map[int64]string),
+		candles:     make(map[string]types.KLine),
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	go s.read(ctx)
+	return nil
+}
+
+func (s *Stream) connect(ctx context.Context) error {
+	url := bfxapi.WebSocketURL
+	if s.publicOnly {
+		log.Infof("stream is set to public only mode")
+		url = bfxapi.PublicWebSocketURL
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected")
+
+	s.connLock.Lock()
+	s.Conn = conn
+	s.channelKeys = make(map[int64]string)
+	s.connLock.Unlock()
+
+	s.EmitConnect()
+	return nil
+}
+
+func (s *Stream) writeCommand(command bfxapi.WebSocketCommand) error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.Conn.WriteJSON(command)
+}
+
+// subscribe authenticates the connection and sends the subscriptions, it's called when the info event is received
+func (s *Stream) subscribe(ctx context.Context) {
+	if !s.publicOnly {
+		if err := s.writeCommand(bfxapi.NewAuthCommand(s.exchange.key, s.exchange.secret)); err != nil {
+			log.WithError(err).Error("auth error")
+		}
+	}
+
+	for _, subscription := range s.Subscriptions {
+		key, err := s.convertSubscription(ctx, subscription)
+		if err != nil {
+			log.WithError(err).Errorf("subscription error: %+v", subscription)
+			continue
+		}
+
+		if err := s.writeCommand(bfxapi.NewCandleSubscribeCommand(key)); err != nil {
+			log.WithError(err).Errorf("subscribe error: %s", key)
+		}
+	}
+}
+
+// convertSubscription converts the subscription to the candle key, e.g., trade:1m:tBTCUSD
+func (s *Stream) convertSubscription(ctx context.Context, subscription types.Subscription) (string, error) {
+	switch subscription.Channel {
+	case types.KLineChannel:
+		timeFrame, err := toLocalInterval(types.Interval(subscription.Options.Interval))
+		if err != nil {
+			return "", err
+		}
+
+		return "trade:" + timeFrame + ":" + s.exchange.localSymbol(ctx, subscription.Symbol), nil
+	}
+
+	return "", fmt.Errorf("bitfinex stream does not support the channel %s", subscription.Channel)
+}
+
+func (s *Stream) read(ctx context.Context) {
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		default:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.SetReadDeadline(time.Now().Add(1 * time.Minute)); err != nil {
+				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+			}
+
+			mt, message, err := conn.ReadMessage()
+			receivedTime := time.Now()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+					log.WithError(err).Errorf("read error: %s", err.Error())
+				} else {
+					log.Info("websocket connection closed, going away")
+				}
+
+				s.EmitDisconnect()
+
+				// reconnect with the exponential backoff
+				backoff := types.NewReconnectBackoff()
+				for err != nil {
+					select {
+					case <-ctx.Done():
+						return
+
+					default:
+						err = s.connect(ctx)
+						if err != nil {
+							delay := backoff.Next()
+							log.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+							time.Sleep(delay)
+						}
+					}
+				}
+
+				continue
+			}
+
+			s.SetLastMessageTime(receivedTime)
+
+			// skip non-text messages
+			if mt != websocket.TextMessage {
+				continue
+			}
+
+			log.Debug(string(message))
+
+			m, err := bfxapi.ParseMessage(message)
+			if err != nil {
+				log.WithError(err).Errorf("[bitfinex] message parse error: %s", message)
+				continue
+			}
+
+			switch m := m.(type) {
+
+			case *bfxapi.WebSocketEvent:
+				s.handleEvent(ctx, m)
+
+			case *bfxapi.ChannelMessage:
+				if eventTime, ok := s.dispatchMessage(m); ok {
+					channel := bfxapi.ChannelCandles
+					if m.ChannelID == 0 {
+						channel = "account"
+					}
+
+					s.Latency().Record(channel, eventTime, receivedTime, time.Now())
+				}
+			}
+		}
+	}
+}
+
+func (s *Stream) handleEvent(ctx context.Context, e *bfxapi.WebSocketEvent) {
+	switch e.Event {
+
+	case "info":
+		s.subscribe(ctx)
+
+	case "subscribed":
+		s.channelKeys[e.ChannelID] = e.Key
+
+	case "auth":
+		if e.Status != "OK" {
+			log.Errorf("[bitfinex] websocket auth error: %d %s", e.Code, e.Message)
+		}
+
+	case "error":
+		log.Errorf("[bitfinex] websocket error: %d %s", e.Code, e.Message)
+	}
+}
+
+// dispatchMessage emits the channel message and returns the event time
+func (s *Stream) dispatchMessage(m *bfxapi.ChannelMessage) (time.Time, bool) {
+	if m.Type == bfxapi.MessageTypeHeartbeat {
+		return time.Time{}, false
+	}
+
+	if m.ChannelID != 0 {
+		return s.handleCandles(m)
+	}
+
+	switch m.Type {
+
+	case bfxapi.MessageTypeOrderNew, bfxapi.MessageTypeOrderUpdate, bfxapi.MessageTypeOrderCancel:
+		var order bfxapi.Order
+		if err := json.Unmarshal(m.Data, &order); err != nil {
+			log.WithError(err).Error("order parse error")
+			return time.Time{}, false
+		}
+
+		s.EmitOrderUpdate(toGlobalOrder(order))
+		return order.UpdatedTime, true
+
+	// the trade executed message (te) doesn't carry the fee, the trade is emitted on the trade update message (tu)
+	case bfxapi.MessageTypeTradeUpdate:
+		var trade bfxapi.Trade
+		if err := json.Unmarshal(m.Data, &trade); err != nil {
+			log.WithError(err).Error("trade parse error")
+			return time.Time{}, false
+		}
+
+		s.EmitTradeUpdate(toGlobalTrade(trade))
+		return trade.CreatedTime, true
+
+	case bfxapi.MessageTypeWalletSnapshot:
+		var wallets []bfxapi.Wallet
+		if err := json.Unmarshal(m.Data, &wallets); err != nil {
+			log.WithError(err).Error("wallet snapshot parse error")
+			return time.Time{}, false
+		}
+
+		if balances, ok := s.toGlobalBalances(wallets); ok {
+			s.EmitBalanceSnapshot(balances)
+		}
+
+	case bfxapi.MessageTypeWalletUpdate:
+		var wallet bfxapi.Wallet
+		if err := json.Unmarshal(m.Data, &wallet); err != nil {
+			log.WithError(err).Error("wallet parse error")
+			return time.Time{}, false
+		}
+
+		if balances, ok := s.toGlobalBalances([]bfxapi.Wallet{wallet}); ok {
+			s.EmitBalanceUpdate(balances)
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (s *Stream) handleCandles(m *bfxapi.ChannelMessage) (time.Time, bool) {
+	key, ok := s.channelKeys[m.ChannelID]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	// the key is trade:<time frame>:<symbol>
+	parts := strings.Split(key, ":")
+	if len(parts) < 3 {
+		return time.Time{}, false
+	}
+
+	interval := toGlobalInterval(parts[1])
+	symbol := toGlobalSymbol(strings.Join(parts[2:], ":"))
+
+	candles, err := bfxapi.ParseCandles(m.Data)
+	if err != nil {
+		log.WithError(err).Error("candle parse error")
+		return time.Time{}, false
+	}
+
+	// the snapshot carries the history candles with the latest candle first, only the latest candle is kept
+	if len(candles) > 1 {
+		if _, ok := s.candles[key]; !ok {
+			s.candles[key] = toGlobalKLine(symbol, interval, candles[0])
+		}
+
+		return time.Time{}, false
+	}
+
+	if len(candles) == 0 {
+		return time.Time{}, false
+	}
+
+	kline := toGlobalKLine(symbol, interval, candles[0])
+	if last, ok := s.candles[key]; ok && kline.StartTime.After(last.StartTime) {
+		last.Closed = true
+		s.EmitKLine(last)
+		s.EmitKLineClosed(last)
+	}
+
+	s.candles[key] = kline
+	s.EmitKLine(kline)
+	return kline.StartTime, true
+}
+
+// toGlobalBalances converts the exchange wallets to the balances, the available balance of the wallet message is null until it's calculated,
+// in that case, the balances are queried from the rest api.
+func (s *Stream) toGlobalBalances(wallets []bfxapi.Wallet) (types.BalanceMap, bool) {
+	balances := types.BalanceMap{}
+	for _, w := range wallets {
+		if w.Type != bfxapi.WalletTypeExchange {
+			continue
+		}
+
+		if !w.HasAvailableBalance {
+			balances, err := s.exchange.QueryAccountBalances(context.Background())
+			if err != nil {
+				log.WithError(err).Error("balance query error")
+				return nil, false
+			}
+
+			return balances, true
+		}
+
+		currency := toGlobalCurrency(w.Currency)
+		balances[currency] = types.Balance{
+			Currency:  currency,
+			Available: w.AvailableBalance,
+			Locked:    w.Balance - w.AvailableBalance,
+		}
+	}
+
+	return balances, len(balances) > 0
+}
+
+func (s *Stream) Close() error {
+	log.Infof("closing bitfinex stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
+
+// Reconnect closes the current connection, the read loop reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	log.Infof("reconnecting bitfinex stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
@@ -0,0 +1,57 @@
+package bitfinex
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/exchange/bitfinex/bfxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var localWalletTypes = map[types.WalletType]bfxapi.WalletType{
+	types.WalletTypeSpot:    bfxapi.WalletTypeExchange,
+	types.WalletTypeMargin:  bfxapi.WalletTypeMargin,
+	types.WalletTypeFunding: bfxapi.WalletTypeFunding,
+}
+
+func toLocalWalletType(wallet types.WalletType) (bfxapi.WalletType, error) {
+	if local, ok := localWalletTypes[wallet]; ok {
+		return local, nil
+	}
+
+	return "", fmt.Errorf("bitfinex does not support the %s wallet", wallet)
+}
+
+// TransferInternal moves the currency between the exchange, margin and funding wallets
+func (e *Exchange) TransferInternal(ctx context.Context, from, to types.WalletType, asset string, amount fixedpoint.Value) (*types.InternalTransfer, error) {
+	localFrom, err := toLocalWalletType(from)
+	if err != nil {
+		return nil, err
+	}
+
+	localTo, err := toLocalWalletType(to)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := e.client.AccountService.Transfer(ctx, bfxapi.TransferRequest{
+		From:     localFrom,
+		To:       localTo,
+		Currency: toLocalCurrency(asset),
+		Amount:   strconv.FormatFloat(amount.Float64(), 'f', -1, 64),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InternalTransfer{
+		Exchange: types.ExchangeBitfinex,
+		From:     from,
+		To:       to,
+		Asset:    toGlobalCurrency(transfer.Currency),
+		Amount:   transfer.Amount,
+		Time:     transfer.UpdatedTime,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package coinbaseapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type AccountService struct {
+	client *RestClient
+}
+
+type Amount struct {
+	Value    fixedpoint.Value `json:"value"`
+	Currency string           `json:"currency"`
+}
+
+// Account is the wallet of one currency, the available balance can be used for trading
+type Account struct {
+	UUID             string `json:"uuid"`
+	Name             string `json:"name"`
+	Currency         string `json:"currency"`
+	AvailableBalance Amount `json:"available_balance"`
+	Hold             Amount `json:"hold"`
+	Active           bool   `json:"active"`
+	Type             string `json:"type"`
+}
+
+func (s *AccountService) Accounts(ctx context.Context) (accounts []Account, err error) {
+	params := url.Values{}
+	params.Set("limit", "250")
+
+	err = s.client.queryPages(ctx, "/api/v3/brokerage/accounts", params, func(body json.RawMessage) (*Page, error) {
+		var resp struct {
+			Page
+			Accounts []Account `json:"accounts"`
+		}
+
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, resp.Accounts...)
+		return &resp.Page, nil
+	})
+
+	return accounts, err
+}
+
+type TransactionType string
+
+const (
+	// TransactionTypeSend is the crypto transfer, the amount is negative for the withdrawals and positive for the deposits
+	TransactionTypeSend           TransactionType = "send"
+	TransactionTypeFiatDeposit    TransactionType = "fiat_deposit"
+	TransactionTypeFiatWithdrawal TransactionType = "fiat_withdrawal"
+)
+
+type TransactionAmount struct {
+	Amount   fixedpoint.Value `json:"amount"`
+	Currency string           `json:"currency"`
+}
+
+// Transaction is the wallet transaction of the v2 api, the deposits and the withdrawals are not available in the advanced trade api
+type Transaction struct {
+	ID        string            `json:"id"`
+	Type      TransactionType   `json:"type"`
+	Status    string            `json:"status"`
+	Amount    TransactionAmount `json:"amount"`
+	CreatedAt time.Time         `json:"created_at"`
+	Network   struct {
+		Status         string            `json:"status"`
+		Hash           string            `json:"hash"`
+		Name           string            `json:"name"`
+		TransactionFee TransactionAmount `json:"transaction_fee"`
+	} `json:"network"`
+	To struct {
+		Resource       string `json:"resource"`
+		Address        string `json:"address"`
+		DestinationTag string `json:"destination_tag"`
+	} `json:"to"`
+}
+
+// Transactions queries the transactions of the account created after the given time, the latest transaction is returned first
+func (s *AccountService) Transactions(ctx context.Context, accountID string, since time.Time) (transactions []Transaction, err error) {
+	refURL := "/v2/accounts/" + url.PathEscape(accountID) + "/transactions?limit=100&order=desc"
+	for len(refURL) > 0 {
+		var resp struct {
+			Pagination struct {
+				NextURI string `json:"next_uri"`
+			} `json:"pagination"`
+			Data []Transaction `json:"data"`
+		}
+
+		// the next uri carries the query, so the query is not passed as the params
+		if err := s.client.sendAuthenticatedRequest(ctx, "GET", refURL, nil, nil, &resp); err != nil {
+			return transactions, err
+		}
+
+		for _, t := range resp.Data {
+			if t.CreatedAt.Before(since) {
+				return transactions, nil
+			}
+
+			transactions = append(transactions, t)
+		}
+
+		refURL = resp.Pagination.NextURI
+	}
+
+	return transactions, nil
+}
+
+// IsDeposit returns true if the transaction moves the funds into the account
+func (t Transaction) IsDeposit() bool {
+	return t.Type == TransactionTypeFiatDeposit || (t.Type == TransactionTypeSend && t.Amount.Amount > 0)
+}
+
+// IsWithdrawal returns true if the transaction moves the funds out of the account
+func (t Transaction) IsWithdrawal() bool {
+	return t.Type == TransactionTypeFiatWithdrawal || (t.Type == TransactionTypeSend && t.Amount.Amount < 0)
+}
+
+// Currency returns the upper case currency of the transaction
+func (t Transaction) Currency() string {
+	return strings.ToUpper(t.Amount.Currency)
+}
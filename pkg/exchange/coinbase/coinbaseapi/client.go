@@ -0,0 +1,275 @@
+package coinbaseapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProductionAPIURL is the official Coinbase API endpoint, the Advanced Trade API is under /api/v3/brokerage
+	ProductionAPIURL = "https://api.coinbase.com"
+
+	UserAgent = "bbgo/1.0"
+
+	// APIVersion is the version date of the v2 (wallet) api
+	APIVersion = "2021-10-05"
+
+	defaultHTTPTimeout = time.Second * 15
+)
+
+var log = logrus.WithField("exchange", "coinbase")
+
+type RestClient struct {
+	client *http.Client
+
+	BaseURL *url.URL
+
+	// Authentication
+	Key    string
+	Secret string
+
+	AccountService *AccountService
+	MarketService  *MarketService
+	TradeService   *TradeService
+}
+
+func NewRestClient(baseURL string) *RestClient {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	client := &RestClient{
+		client:  &http.Client{Timeout: defaultHTTPTimeout},
+		BaseURL: u,
+	}
+
+	client.AccountService = &AccountService{client}
+	client.MarketService = &MarketService{client}
+	client.TradeService = &TradeService{client}
+	return client
+}
+
+// Auth sets the api key and secret for the private endpoints.
+func (c *RestClient) Auth(key, secret string) *RestClient {
+	c.Key = key
+	c.Secret = secret
+	return c
+}
+
+// ErrorResponse is returned when the response status is not 2xx,
+// the advanced trade api returns the error in the error and message fields, the v2 api returns the errors array.
+type ErrorResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Code       string `json:"error"`
+	Message    string `json:"message"`
+
+	Errors []struct {
+		ID      string `json:"id"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func (r *ErrorResponse) Error() string {
+	code, message := r.Code, r.Message
+	if len(r.Errors) > 0 {
+		code, message = r.Errors[0].ID, r.Errors[0].Message
+	}
+
+	return fmt.Sprintf("%s %s: %d %s %s", r.Method, r.URL, r.StatusCode, code, message)
+}
+
+func (c *RestClient) newRequest(ctx context.Context, method, refURL string, params url.Values, body []byte) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	u := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", UserAgent)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// newAuthenticatedRequest signs the request with the api key scheme of coinbase:
+// the signature is hex(hmac_sha256(timestamp + method + request path + body)) and the timestamp is in seconds.
+// The request path of the advanced trade api is signed without the query, while the v2 api signs the query as well.
+func (c *RestClient) newAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 || len(c.Secret) == 0 {
+		return nil, errors.New("empty api key or secret")
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.newRequest(ctx, method, refURL, params, body)
+	if err != nil {
+		return nil, err
+	}
+
+	path := req.URL.Path
+	if strings.HasPrefix(path, "/v2/") && len(req.URL.RawQuery) > 0 {
+		path += "?" + req.URL.RawQuery
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Add("CB-ACCESS-KEY", c.Key)
+	req.Header.Add("CB-ACCESS-SIGN", Sign(c.Secret, timestamp+method+path+string(body)))
+	req.Header.Add("CB-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Add("CB-VERSION", APIVersion)
+	return req, nil
+}
+
+// Sign returns the hex encoded hmac sha256 signature of the payload, it's used by both the rest api and the websocket subscriptions
+func Sign(secret, payload string) string {
+	var sig = hmac.New(sha256.New, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(sig.Sum(nil))
+}
+
+// sendRequest sends the request and decodes the response body into the given object
+func (c *RestClient) sendRequest(req *http.Request, data interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errResponse := &ErrorResponse{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+		}
+
+		if err := json.Unmarshal(body, errResponse); err != nil {
+			errResponse.Message = string(body)
+		}
+
+		return errResponse
+	}
+
+	if data == nil || len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, data); err != nil {
+		return errors.Wrapf(err, "failed to decode the response: %s", body)
+	}
+
+	return nil
+}
+
+func (c *RestClient) get(ctx context.Context, refURL string, params url.Values, data interface{}) error {
+	req, err := c.newRequest(ctx, "GET", refURL, params, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+func (c *RestClient) sendAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}, data interface{}) error {
+	req, err := c.newAuthenticatedRequest(ctx, method, refURL, params, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+// Page is the cursor pagination of the advanced trade list endpoints
+type Page struct {
+	HasNext bool   `json:"has_next"`
+	Cursor  string `json:"cursor"`
+}
+
+// queryPages queries all the pages of a cursor paginated endpoint, the handler decodes the page and returns the pagination
+func (c *RestClient) queryPages(ctx context.Context, refURL string, params url.Values, handler func(body json.RawMessage) (*Page, error)) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	for {
+		var body json.RawMessage
+		if err := c.sendAuthenticatedRequest(ctx, "GET", refURL, params, nil, &body); err != nil {
+			return err
+		}
+
+		page, err := handler(body)
+		if err != nil {
+			return err
+		}
+
+		if page == nil || !page.HasNext || len(page.Cursor) == 0 {
+			return nil
+		}
+
+		params.Set("cursor", page.Cursor)
+	}
+}
+
+// UnixTimestamp parses the timestamp in seconds, which is encoded as a string
+type UnixTimestamp time.Time
+
+func (t *UnixTimestamp) UnmarshalJSON(data []byte) error {
+	var s json.Number
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	sec, err := s.Int64()
+	if err != nil {
+		return err
+	}
+
+	*t = UnixTimestamp(time.Unix(sec, 0))
+	return nil
+}
+
+func (t UnixTimestamp) Time() time.Time {
+	return time.Time(t)
+}
@@ -0,0 +1,78 @@
+package coinbaseapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarketService struct {
+	client *RestClient
+}
+
+// Product is the trading pair of coinbase, the product ID is dash separated, e.g., BTC-USD
+type Product struct {
+	ProductID       string           `json:"product_id"`
+	Price           fixedpoint.Value `json:"price"`
+	BaseCurrencyID  string           `json:"base_currency_id"`
+	QuoteCurrencyID string           `json:"quote_currency_id"`
+	BaseIncrement   fixedpoint.Value `json:"base_increment"`
+	QuoteIncrement  fixedpoint.Value `json:"quote_increment"`
+	PriceIncrement  fixedpoint.Value `json:"price_increment"`
+	BaseMinSize     fixedpoint.Value `json:"base_min_size"`
+	BaseMaxSize     fixedpoint.Value `json:"base_max_size"`
+	QuoteMinSize    fixedpoint.Value `json:"quote_min_size"`
+	QuoteMaxSize    fixedpoint.Value `json:"quote_max_size"`
+	Status          string           `json:"status"`
+	TradingDisabled bool             `json:"trading_disabled"`
+	IsDisabled      bool             `json:"is_disabled"`
+	ProductType     string           `json:"product_type"`
+}
+
+// Products returns the spot products from the public market endpoint
+func (s *MarketService) Products(ctx context.Context) ([]Product, error) {
+	var resp struct {
+		Products []Product `json:"products"`
+	}
+
+	err := s.client.get(ctx, "/api/v3/brokerage/market/products", url.Values{"product_type": []string{"SPOT"}}, &resp)
+	return resp.Products, err
+}
+
+type Ticker struct {
+	BestBid fixedpoint.Value `json:"best_bid"`
+	BestAsk fixedpoint.Value `json:"best_ask"`
+}
+
+func (s *MarketService) Ticker(ctx context.Context, productID string) (*Ticker, error) {
+	var ticker Ticker
+	err := s.client.get(ctx, "/api/v3/brokerage/market/products/"+url.PathEscape(productID)+"/ticker", url.Values{"limit": []string{"1"}}, &ticker)
+	return &ticker, err
+}
+
+type Candle struct {
+	Start  UnixTimestamp    `json:"start"`
+	Low    fixedpoint.Value `json:"low"`
+	High   fixedpoint.Value `json:"high"`
+	Open   fixedpoint.Value `json:"open"`
+	Close  fixedpoint.Value `json:"close"`
+	Volume fixedpoint.Value `json:"volume"`
+}
+
+// Candles returns the klines in the time range, coinbase returns the latest kline first, and at most 300 klines per request.
+func (s *MarketService) Candles(ctx context.Context, productID, granularity string, startTime, endTime time.Time) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("granularity", granularity)
+	params.Set("start", strconv.FormatInt(startTime.Unix(), 10))
+	params.Set("end", strconv.FormatInt(endTime.Unix(), 10))
+
+	var resp struct {
+		Candles []Candle `json:"candles"`
+	}
+
+	err := s.client.get(ctx, "/api/v3/brokerage/market/products/"+url.PathEscape(productID)+"/candles", params, &resp)
+	return resp.Candles, err
+}
@@ -0,0 +1,251 @@
+package coinbaseapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type SideType string
+
+const (
+	SideTypeBuy  SideType = "BUY"
+	SideTypeSell SideType = "SELL"
+)
+
+type OrderType string
+
+const (
+	OrderTypeLimit     OrderType = "LIMIT"
+	OrderTypeMarket    OrderType = "MARKET"
+	OrderTypeStop      OrderType = "STOP"
+	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending      OrderStatus = "PENDING"
+	OrderStatusOpen         OrderStatus = "OPEN"
+	OrderStatusFilled       OrderStatus = "FILLED"
+	OrderStatusCancelled    OrderStatus = "CANCELLED"
+	OrderStatusExpired      OrderStatus = "EXPIRED"
+	OrderStatusFailed       OrderStatus = "FAILED"
+	OrderStatusCancelQueued OrderStatus = "CANCEL_QUEUED"
+)
+
+type StopDirection string
+
+const (
+	// StopDirectionUp triggers when the last price >= the stop price
+	StopDirectionUp StopDirection = "STOP_DIRECTION_STOP_UP"
+
+	// StopDirectionDown triggers when the last price <= the stop price
+	StopDirectionDown StopDirection = "STOP_DIRECTION_STOP_DOWN"
+)
+
+type TradeService struct {
+	client *RestClient
+}
+
+type MarketIOC struct {
+	QuoteSize string `json:"quote_size,omitempty"`
+	BaseSize  string `json:"base_size,omitempty"`
+}
+
+type LimitGTC struct {
+	BaseSize   string `json:"base_size"`
+	LimitPrice string `json:"limit_price"`
+	PostOnly   bool   `json:"post_only"`
+}
+
+type StopLimitGTC struct {
+	BaseSize      string        `json:"base_size"`
+	LimitPrice    string        `json:"limit_price"`
+	StopPrice     string        `json:"stop_price"`
+	StopDirection StopDirection `json:"stop_direction"`
+}
+
+// OrderConfiguration is the order type of coinbase, only one of the configurations is set
+type OrderConfiguration struct {
+	MarketIOC    *MarketIOC    `json:"market_market_ioc,omitempty"`
+	LimitGTC     *LimitGTC     `json:"limit_limit_gtc,omitempty"`
+	StopLimitGTC *StopLimitGTC `json:"stop_limit_stop_limit_gtc,omitempty"`
+}
+
+// CreateOrderRequest is the payload of the order creation, the numbers are sent as strings.
+type CreateOrderRequest struct {
+	ClientOrderID      string             `json:"client_order_id"`
+	ProductID          string             `json:"product_id"`
+	Side               SideType           `json:"side"`
+	OrderConfiguration OrderConfiguration `json:"order_configuration"`
+}
+
+type CreateOrderResponse struct {
+	Success         bool   `json:"success"`
+	FailureReason   string `json:"failure_reason"`
+	OrderID         string `json:"order_id"`
+	SuccessResponse struct {
+		OrderID       string `json:"order_id"`
+		ProductID     string `json:"product_id"`
+		Side          string `json:"side"`
+		ClientOrderID string `json:"client_order_id"`
+	} `json:"success_response"`
+	ErrorResponse struct {
+		Error        string `json:"error"`
+		Message      string `json:"message"`
+		ErrorDetails string `json:"error_details"`
+	} `json:"error_response"`
+}
+
+// CreateOrder creates the order, the rejected order is returned as an error
+func (s *TradeService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*CreateOrderResponse, error) {
+	var resp CreateOrderResponse
+	if err := s.client.sendAuthenticatedRequest(ctx, "POST", "/api/v3/brokerage/orders", nil, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return &resp, fmt.Errorf("order rejected: %s %s %s", resp.FailureReason, resp.ErrorResponse.Error, resp.ErrorResponse.Message)
+	}
+
+	return &resp, nil
+}
+
+type CancelOrderResult struct {
+	Success       bool   `json:"success"`
+	FailureReason string `json:"failure_reason"`
+	OrderID       string `json:"order_id"`
+}
+
+func (s *TradeService) CancelOrders(ctx context.Context, orderIDs ...string) ([]CancelOrderResult, error) {
+	var payload = struct {
+		OrderIDs []string `json:"order_ids"`
+	}{OrderIDs: orderIDs}
+
+	var resp struct {
+		Results []CancelOrderResult `json:"results"`
+	}
+
+	err := s.client.sendAuthenticatedRequest(ctx, "POST", "/api/v3/brokerage/orders/batch_cancel", nil, payload, &resp)
+	return resp.Results, err
+}
+
+type Order struct {
+	OrderID            string             `json:"order_id"`
+	ProductID          string             `json:"product_id"`
+	ClientOrderID      string             `json:"client_order_id"`
+	Side               SideType           `json:"side"`
+	OrderType          OrderType          `json:"order_type"`
+	OrderConfiguration OrderConfiguration `json:"order_configuration"`
+	Status             OrderStatus        `json:"status"`
+	TimeInForce        string             `json:"time_in_force"`
+	CreatedTime        time.Time          `json:"created_time"`
+	LastFillTime       *time.Time         `json:"last_fill_time"`
+	FilledSize         fixedpoint.Value   `json:"filled_size"`
+	FilledValue        fixedpoint.Value   `json:"filled_value"`
+	AverageFilledPrice fixedpoint.Value   `json:"average_filled_price"`
+	TotalFees          fixedpoint.Value   `json:"total_fees"`
+	NumberOfFills      fixedpoint.Value   `json:"number_of_fills"`
+}
+
+func (s *TradeService) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	var resp struct {
+		Order Order `json:"order"`
+	}
+
+	err := s.client.sendAuthenticatedRequest(ctx, "GET", "/api/v3/brokerage/orders/historical/"+url.PathEscape(orderID), nil, nil, &resp)
+	return &resp.Order, err
+}
+
+// ListOrders queries the orders of the product with the given statuses in the time range, the zero time is not sent
+func (s *TradeService) ListOrders(ctx context.Context, productID string, statuses []OrderStatus, startTime, endTime time.Time) (orders []Order, err error) {
+	params := timeRangeParams("start_date", "end_date", startTime, endTime)
+	params.Set("limit", "1000")
+	if len(productID) > 0 {
+		params.Set("product_id", productID)
+	}
+
+	for _, status := range statuses {
+		params.Add("order_status", string(status))
+	}
+
+	err = s.client.queryPages(ctx, "/api/v3/brokerage/orders/historical/batch", params, func(body json.RawMessage) (*Page, error) {
+		var resp struct {
+			Page
+			Orders []Order `json:"orders"`
+		}
+
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, resp.Orders...)
+		return &resp.Page, nil
+	})
+
+	return orders, err
+}
+
+type Fill struct {
+	EntryID            string           `json:"entry_id"`
+	TradeID            string           `json:"trade_id"`
+	OrderID            string           `json:"order_id"`
+	TradeTime          time.Time        `json:"trade_time"`
+	TradeType          string           `json:"trade_type"`
+	Price              fixedpoint.Value `json:"price"`
+	Size               fixedpoint.Value `json:"size"`
+	Commission         fixedpoint.Value `json:"commission"`
+	ProductID          string           `json:"product_id"`
+	LiquidityIndicator string           `json:"liquidity_indicator"`
+	SizeInQuote        bool             `json:"size_in_quote"`
+	Side               SideType         `json:"side"`
+}
+
+// ListFills queries the fills of the product or the order in the time range, the zero time is not sent
+func (s *TradeService) ListFills(ctx context.Context, productID, orderID string, startTime, endTime time.Time) (fills []Fill, err error) {
+	params := timeRangeParams("start_sequence_timestamp", "end_sequence_timestamp", startTime, endTime)
+	params.Set("limit", "1000")
+	if len(productID) > 0 {
+		params.Set("product_id", productID)
+	}
+
+	if len(orderID) > 0 {
+		params.Set("order_id", orderID)
+	}
+
+	err = s.client.queryPages(ctx, "/api/v3/brokerage/orders/historical/fills", params, func(body json.RawMessage) (*Page, error) {
+		var resp struct {
+			Cursor string `json:"cursor"`
+			Fills  []Fill `json:"fills"`
+		}
+
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		fills = append(fills, resp.Fills...)
+
+		// the fill endpoint doesn't return has_next, the cursor is empty on the last page
+		return &Page{HasNext: len(resp.Fills) > 0, Cursor: resp.Cursor}, nil
+	})
+
+	return fills, err
+}
+
+func timeRangeParams(startKey, endKey string, startTime, endTime time.Time) url.Values {
+	params := url.Values{}
+	if !startTime.IsZero() {
+		params.Set(startKey, startTime.UTC().Format(time.RFC3339))
+	}
+
+	if !endTime.IsZero() {
+		params.Set(endKey, endTime.UTC().Format(time.RFC3339))
+	}
+
+	return params
+}
@@ -0,0 +1,119 @@
+package coinbaseapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// WebSocketURL is the endpoint of the advanced trade websocket feed
+const WebSocketURL = "wss://advanced-trade-ws.coinbase.com"
+
+const (
+	ChannelMarketTrades  = "market_trades"
+	ChannelUser          = "user"
+	ChannelHeartbeats    = "heartbeats"
+	ChannelSubscriptions = "subscriptions"
+)
+
+const (
+	EventTypeSnapshot = "snapshot"
+	EventTypeUpdate   = "update"
+)
+
+// WebSocketCommand subscribes one channel of the products, each subscription is signed with
+// hex(hmac_sha256(timestamp + channel + comma separated product ids)).
+type WebSocketCommand struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Channel    string   `json:"channel"`
+	APIKey     string   `json:"api_key,omitempty"`
+	Timestamp  string   `json:"timestamp,omitempty"`
+	Signature  string   `json:"signature,omitempty"`
+}
+
+// NewSubscribeCommand creates the subscription, the command is signed when the key and the secret are given
+func NewSubscribeCommand(channel string, productIDs []string, key, secret string) WebSocketCommand {
+	command := WebSocketCommand{
+		Type:       "subscribe",
+		ProductIDs: productIDs,
+		Channel:    channel,
+	}
+
+	if len(key) > 0 && len(secret) > 0 {
+		command.APIKey = key
+		command.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+		command.Signature = Sign(secret, command.Timestamp+channel+strings.Join(productIDs, ","))
+	}
+
+	return command
+}
+
+type WebSocketMessage struct {
+	Type        string          `json:"type"`
+	Channel     string          `json:"channel"`
+	Timestamp   time.Time       `json:"timestamp"`
+	SequenceNum int64           `json:"sequence_num"`
+	Events      json.RawMessage `json:"events"`
+
+	// Message is set in the error message
+	Message string `json:"message"`
+}
+
+type MarketTrade struct {
+	TradeID   string           `json:"trade_id"`
+	ProductID string           `json:"product_id"`
+	Price     fixedpoint.Value `json:"price"`
+	Size      fixedpoint.Value `json:"size"`
+	Side      SideType         `json:"side"`
+	Time      time.Time        `json:"time"`
+}
+
+// MarketTradeEvent carries the public trades, the snapshot event carries the recent trades before the subscription
+type MarketTradeEvent struct {
+	Type   string        `json:"type"`
+	Trades []MarketTrade `json:"trades"`
+}
+
+// UserOrder is the order update of the user channel, the fills are not pushed,
+// the fill is detected by the increase of the cumulative quantity.
+type UserOrder struct {
+	OrderID            string           `json:"order_id"`
+	ClientOrderID      string           `json:"client_order_id"`
+	CumulativeQuantity fixedpoint.Value `json:"cumulative_quantity"`
+	LeavesQuantity     fixedpoint.Value `json:"leaves_quantity"`
+	AveragePrice       fixedpoint.Value `json:"avg_price"`
+	TotalFees          fixedpoint.Value `json:"total_fees"`
+	Status             OrderStatus      `json:"status"`
+	ProductID          string           `json:"product_id"`
+	CreationTime       time.Time        `json:"creation_time"`
+	OrderSide          SideType         `json:"order_side"`
+	OrderType          OrderType        `json:"order_type"`
+}
+
+type UserEvent struct {
+	Type   string      `json:"type"`
+	Orders []UserOrder `json:"orders"`
+}
+
+// ParseEvents parses the events of the message by the channel, nil is returned for the unsupported channels
+func ParseEvents(m *WebSocketMessage) (interface{}, error) {
+	switch m.Channel {
+
+	case ChannelMarketTrades:
+		var events []MarketTradeEvent
+		err := json.Unmarshal(m.Events, &events)
+		return events, err
+
+	case ChannelUser:
+		var events []UserEvent
+		err := json.Unmarshal(m.Events, &events)
+		return events, err
+
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,341 @@
+package coinbase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+func toGlobalCurrency(currency string) string {
+	return strings.ToUpper(currency)
+}
+
+func toLocalCurrency(currency string) string {
+	return strings.ToUpper(currency)
+}
+
+// toLocalSymbol converts the symbol to the product ID of Coinbase, e.g., BTCUSD => BTC-USD,
+// the market map is used for finding the base currency of the symbol.
+func toLocalSymbol(symbol string, markets types.MarketMap) string {
+	if market, ok := markets[symbol]; ok {
+		return market.BaseCurrency + "-" + market.QuoteCurrency
+	}
+
+	for _, quote := range []string{"USDC", "USDT", "USD", "EUR", "GBP", "BTC", "ETH", "DAI"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return symbol[:len(symbol)-len(quote)] + "-" + quote
+		}
+	}
+
+	return symbol
+}
+
+func toGlobalSymbol(productID string) string {
+	return strings.ToUpper(strings.Replace(productID, "-", "", -1))
+}
+
+func toLocalSideType(side types.SideType) coinbaseapi.SideType {
+	return coinbaseapi.SideType(strings.ToUpper(string(side)))
+}
+
+func toGlobalSideType(side coinbaseapi.SideType) types.SideType {
+	switch side {
+	case coinbaseapi.SideTypeBuy:
+		return types.SideTypeBuy
+
+	case coinbaseapi.SideTypeSell:
+		return types.SideTypeSell
+	}
+
+	return types.SideType(side)
+}
+
+var localIntervals = map[types.Interval]string{
+	types.Interval1m:  "ONE_MINUTE",
+	types.Interval5m:  "FIVE_MINUTE",
+	types.Interval15m: "FIFTEEN_MINUTE",
+	types.Interval30m: "THIRTY_MINUTE",
+	types.Interval1h:  "ONE_HOUR",
+	types.Interval2h:  "TWO_HOUR",
+	types.Interval6h:  "SIX_HOUR",
+	types.Interval1d:  "ONE_DAY",
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	if s, ok := localIntervals[interval]; ok {
+		return s, nil
+	}
+
+	return "", fmt.Errorf("coinbase does not support the interval %s", interval)
+}
+
+// hashID converts the UUID of Coinbase to the integer ID, the orders and the trades are linked by the hashed order ID.
+func hashID(id string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+
+	// keep it in the int64 range, so that it can be stored as the trade ID
+	return h.Sum64() & math.MaxInt64
+}
+
+func toGlobalMarket(p coinbaseapi.Product) types.Market {
+	tickSize := p.PriceIncrement
+	if tickSize == 0 {
+		tickSize = p.QuoteIncrement
+	}
+
+	return types.Market{
+		Symbol:          toGlobalSymbol(p.ProductID),
+		PricePrecision:  util.StepPrecision(tickSize.Float64()),
+		VolumePrecision: util.StepPrecision(p.BaseIncrement.Float64()),
+		QuoteCurrency:   toGlobalCurrency(p.QuoteCurrencyID),
+		BaseCurrency:    toGlobalCurrency(p.BaseCurrencyID),
+		MinNotional:     p.QuoteMinSize.Float64(),
+		MinAmount:       p.QuoteMinSize.Float64(),
+		MinLot:          p.BaseIncrement.Float64(),
+		StepSize:        p.BaseIncrement.Float64(),
+		MinQuantity:     p.BaseMinSize.Float64(),
+		MaxQuantity:     p.BaseMaxSize.Float64(),
+		MinPrice:        tickSize.Float64(),
+		MaxPrice:        p.QuoteMaxSize.Float64(),
+		TickSize:        tickSize.Float64(),
+	}
+}
+
+func toGlobalOrderType(orderType coinbaseapi.OrderType) types.OrderType {
+	switch orderType {
+	case coinbaseapi.OrderTypeLimit:
+		return types.OrderTypeLimit
+
+	case coinbaseapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case coinbaseapi.OrderTypeStopLimit:
+		return types.OrderTypeStopLimit
+
+	case coinbaseapi.OrderTypeStop:
+		return types.OrderTypeStopMarket
+	}
+
+	log.Errorf("unknown order type: %v", orderType)
+	return types.OrderType(orderType)
+}
+
+// toLocalOrderConfiguration converts the order to the order configuration, the limit orders are good till canceled,
+// the buy stop is triggered when the price goes up, and the sell stop is triggered when the price goes down.
+func toLocalOrderConfiguration(order types.SubmitOrder) (coinbaseapi.OrderConfiguration, error) {
+	switch order.Type {
+	case types.OrderTypeLimit:
+		return coinbaseapi.OrderConfiguration{
+			LimitGTC: &coinbaseapi.LimitGTC{
+				BaseSize:   order.QuantityString,
+				LimitPrice: order.PriceString,
+				PostOnly:   order.PostOnly,
+			},
+		}, nil
+
+	case types.OrderTypeMarket:
+		return coinbaseapi.OrderConfiguration{
+			MarketIOC: &coinbaseapi.MarketIOC{
+				BaseSize: order.QuantityString,
+			},
+		}, nil
+
+	case types.OrderTypeStopLimit:
+		if len(order.StopPriceString) == 0 {
+			return coinbaseapi.OrderConfiguration{}, fmt.Errorf("stop price string can not be empty")
+		}
+
+		direction := coinbaseapi.StopDirectionDown
+		if order.Side == types.SideTypeBuy {
+			direction = coinbaseapi.StopDirectionUp
+		}
+
+		return coinbaseapi.OrderConfiguration{
+			StopLimitGTC: &coinbaseapi.StopLimitGTC{
+				BaseSize:      order.QuantityString,
+				LimitPrice:    order.PriceString,
+				StopPrice:     order.StopPriceString,
+				StopDirection: direction,
+			},
+		}, nil
+	}
+
+	return coinbaseapi.OrderConfiguration{}, fmt.Errorf("order type %s not supported", order.Type)
+}
+
+func toGlobalOrderStatus(status coinbaseapi.OrderStatus, filledSize fixedpoint.Value) types.OrderStatus {
+	switch status {
+	case coinbaseapi.OrderStatusOpen, coinbaseapi.OrderStatusCancelQueued:
+		if filledSize > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+
+		return types.OrderStatusNew
+
+	case coinbaseapi.OrderStatusPending:
+		return types.OrderStatusNew
+
+	case coinbaseapi.OrderStatusFilled:
+		return types.OrderStatusFilled
+
+	case coinbaseapi.OrderStatusCancelled, coinbaseapi.OrderStatusExpired:
+		return types.OrderStatusCanceled
+
+	case coinbaseapi.OrderStatusFailed:
+		return types.OrderStatusRejected
+	}
+
+	return types.OrderStatus(status)
+}
+
+func isWorkingOrderStatus(status coinbaseapi.OrderStatus) bool {
+	switch status {
+	case coinbaseapi.OrderStatusOpen, coinbaseapi.OrderStatusPending, coinbaseapi.OrderStatusCancelQueued:
+		return true
+	}
+
+	return false
+}
+
+func toGlobalOrder(o coinbaseapi.Order) types.Order {
+	var quantity, price, stopPrice string
+	switch c := o.OrderConfiguration; {
+	case c.LimitGTC != nil:
+		quantity, price = c.LimitGTC.BaseSize, c.LimitGTC.LimitPrice
+
+	case c.StopLimitGTC != nil:
+		quantity, price, stopPrice = c.StopLimitGTC.BaseSize, c.StopLimitGTC.LimitPrice, c.StopLimitGTC.StopPrice
+
+	case c.MarketIOC != nil:
+		quantity = c.MarketIOC.BaseSize
+	}
+
+	updateTime := o.CreatedTime
+	if o.LastFillTime != nil {
+		updateTime = *o.LastFillTime
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.ProductID),
+			Side:          toGlobalSideType(o.Side),
+			Type:          toGlobalOrderType(o.OrderType),
+			Quantity:      parseFloat(quantity),
+			Price:         parseFloat(price),
+			StopPrice:     parseFloat(stopPrice),
+			TimeInForce:   types.TimeInForce(o.TimeInForce),
+		},
+		Exchange:         types.ExchangeCoinbase.String(),
+		OrderID:          hashID(o.OrderID),
+		Status:           toGlobalOrderStatus(o.Status, o.FilledSize),
+		ExecutedQuantity: o.FilledSize.Float64(),
+		IsWorking:        isWorkingOrderStatus(o.Status),
+		CreationTime:     o.CreatedTime,
+		UpdateTime:       updateTime,
+	}
+}
+
+// parseFloat parses the optional number string of the order configuration, the empty string is parsed as zero
+func parseFloat(s string) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	return util.MustParseFloat(s)
+}
+
+// toGlobalTrade converts the fill, the commission of coinbase is charged in the quote currency
+func toGlobalTrade(fill coinbaseapi.Fill, feeCurrency string) types.Trade {
+	side := toGlobalSideType(fill.Side)
+
+	// the size of the fill is in the quote currency if the order is placed by the quote size
+	quantity := fill.Size.Float64()
+	if fill.SizeInQuote && fill.Price > 0 {
+		quantity = fill.Size.Float64() / fill.Price.Float64()
+	}
+
+	return types.Trade{
+		ID:            int64(hashID(fill.TradeID)),
+		OrderID:       hashID(fill.OrderID),
+		Exchange:      types.ExchangeCoinbase.String(),
+		Price:         fill.Price.Float64(),
+		Quantity:      quantity,
+		QuoteQuantity: fill.Price.Float64() * quantity,
+		Symbol:        toGlobalSymbol(fill.ProductID),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       fill.LiquidityIndicator == "MAKER",
+		Time:          fill.TradeTime,
+		Fee:           fill.Commission.Float64(),
+		FeeCurrency:   feeCurrency,
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, candle coinbaseapi.Candle) types.KLine {
+	start := candle.Start.Time()
+	return types.KLine{
+		Exchange:    types.ExchangeCoinbase.String(),
+		Symbol:      symbol,
+		StartTime:   start,
+		EndTime:     start.Add(interval.Duration() - time.Millisecond),
+		Interval:    interval,
+		Open:        candle.Open.Float64(),
+		Close:       candle.Close.Float64(),
+		High:        candle.High.Float64(),
+		Low:         candle.Low.Float64(),
+		Volume:      candle.Volume.Float64(),
+		QuoteVolume: candle.Volume.Float64() * candle.Close.Float64(),
+	}
+}
+
+func toGlobalDepositStatus(status string) types.DepositStatus {
+	switch status {
+	case "completed":
+		return types.DepositSuccess
+
+	case "pending", "waiting_for_clearing", "waiting_for_signature":
+		return types.DepositPending
+
+	case "failed", "expired":
+		return types.DepositRejected
+
+	case "canceled":
+		return types.DepositCancelled
+	}
+
+	return types.DepositStatus(status)
+}
+
+func toGlobalDeposit(t coinbaseapi.Transaction) types.Deposit {
+	return types.Deposit{
+		Time:          t.CreatedAt,
+		Amount:        math.Abs(t.Amount.Amount.Float64()),
+		Asset:         t.Currency(),
+		TransactionID: t.Network.Hash,
+		Status:        toGlobalDepositStatus(t.Status),
+	}
+}
+
+func toGlobalWithdraw(t coinbaseapi.Transaction) types.Withdraw {
+	return types.Withdraw{
+		ID:             t.ID,
+		ApplyTime:      t.CreatedAt,
+		Asset:          t.Currency(),
+		Amount:         math.Abs(t.Amount.Amount.Float64()),
+		Address:        t.To.Address,
+		AddressTag:     t.To.DestinationTag,
+		TransactionID:  t.Network.Hash,
+		TransactionFee: t.Network.TransactionFee.Amount.Float64(),
+		Status:         t.Status, // completed, pending, failed and canceled, same as the status of binance
+		Network:        t.Network.Name,
+	}
+}
@@ -0,0 +1,110 @@
+package coinbase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSymbolConversion(t *testing.T) {
+	markets := types.MarketMap{
+		"USDTUSD": {Symbol: "USDTUSD", BaseCurrency: "USDT", QuoteCurrency: "USD"},
+	}
+
+	assert.Equal(t, "USDT-USD", toLocalSymbol("USDTUSD", markets))
+	assert.Equal(t, "BTC-USD", toLocalSymbol("BTCUSD", nil))
+	assert.Equal(t, "ETH-USDC", toLocalSymbol("ETHUSDC", nil))
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("BTC-USD"))
+}
+
+func TestIntervalConversion(t *testing.T) {
+	s, err := toLocalInterval(types.Interval1h)
+	assert.NoError(t, err)
+	assert.Equal(t, "ONE_HOUR", s)
+
+	_, err = toLocalInterval(types.Interval4h)
+	assert.Error(t, err)
+}
+
+func TestToLocalOrderConfiguration(t *testing.T) {
+	c, err := toLocalOrderConfiguration(types.SubmitOrder{
+		Type:           types.OrderTypeLimit,
+		QuantityString: "0.01",
+		PriceString:    "30000",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &coinbaseapi.LimitGTC{BaseSize: "0.01", LimitPrice: "30000"}, c.LimitGTC)
+	assert.Nil(t, c.MarketIOC)
+
+	c, err = toLocalOrderConfiguration(types.SubmitOrder{
+		Side:            types.SideTypeSell,
+		Type:            types.OrderTypeStopLimit,
+		QuantityString:  "0.01",
+		PriceString:     "29000",
+		StopPriceString: "29500",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, coinbaseapi.StopDirectionDown, c.StopLimitGTC.StopDirection)
+
+	_, err = toLocalOrderConfiguration(types.SubmitOrder{Type: types.OrderTypeStopMarket})
+	assert.Error(t, err)
+}
+
+func TestToGlobalOrder(t *testing.T) {
+	createdTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	order := toGlobalOrder(coinbaseapi.Order{
+		OrderID:   "0000-000000-000000",
+		ProductID: "BTC-USD",
+		Side:      coinbaseapi.SideTypeBuy,
+		OrderType: coinbaseapi.OrderTypeLimit,
+		OrderConfiguration: coinbaseapi.OrderConfiguration{
+			LimitGTC: &coinbaseapi.LimitGTC{BaseSize: "0.01", LimitPrice: "30000"},
+		},
+		Status:      coinbaseapi.OrderStatusOpen,
+		CreatedTime: createdTime,
+		FilledSize:  fixedpoint.NewFromFloat(0.005),
+	})
+
+	assert.Equal(t, "BTCUSD", order.Symbol)
+	assert.Equal(t, types.SideTypeBuy, order.Side)
+	assert.Equal(t, types.OrderTypeLimit, order.Type)
+	assert.Equal(t, 0.01, order.Quantity)
+	assert.Equal(t, 30000.0, order.Price)
+	assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+	assert.Equal(t, hashID("0000-000000-000000"), order.OrderID)
+	assert.True(t, order.IsWorking)
+	assert.Equal(t, createdTime, order.UpdateTime)
+}
+
+func TestToGlobalOrderStatus(t *testing.T) {
+	assert.Equal(t, types.OrderStatusNew, toGlobalOrderStatus(coinbaseapi.OrderStatusOpen, 0))
+	assert.Equal(t, types.OrderStatusFilled, toGlobalOrderStatus(coinbaseapi.OrderStatusFilled, fixedpoint.NewFromFloat(1.0)))
+	assert.Equal(t, types.OrderStatusCanceled, toGlobalOrderStatus(coinbaseapi.OrderStatusExpired, 0))
+	assert.Equal(t, types.OrderStatusRejected, toGlobalOrderStatus(coinbaseapi.OrderStatusFailed, 0))
+}
+
+func TestToGlobalTrade(t *testing.T) {
+	trade := toGlobalTrade(coinbaseapi.Fill{
+		TradeID:            "1111-11111-111111",
+		OrderID:            "0000-000000-000000",
+		Price:              fixedpoint.NewFromFloat(20000),
+		Size:               fixedpoint.NewFromFloat(100),
+		Commission:         fixedpoint.NewFromFloat(0.6),
+		ProductID:          "BTC-USD",
+		LiquidityIndicator: "TAKER",
+		SizeInQuote:        true,
+		Side:               coinbaseapi.SideTypeSell,
+	}, "USD")
+
+	assert.Equal(t, hashID("0000-000000-000000"), trade.OrderID)
+	assert.Equal(t, 0.005, trade.Quantity)
+	assert.Equal(t, 100.0, trade.QuoteQuantity)
+	assert.Equal(t, "USD", trade.FeeCurrency)
+	assert.False(t, trade.IsBuyer)
+	assert.False(t, trade.IsMaker)
+}
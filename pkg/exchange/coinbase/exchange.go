@@ -0,0 +1,502 @@
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "coinbase")
+
+// maxCandles is the max number of the candles returned by one candle request
+const maxCandles = 300
+
+// orderIDMap maps the hashed order ID back to the coinbase order UUID, so that the orders can be canceled by the global order ID
+type orderIDMap struct {
+	mu  sync.Mutex
+	ids map[uint64]string
+}
+
+func (m *orderIDMap) Add(id string) uint64 {
+	hashed := hashID(id)
+
+	m.mu.Lock()
+	m.ids[hashed] = id
+	m.mu.Unlock()
+	return hashed
+}
+
+func (m *orderIDMap) Get(hashed uint64) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := m.ids[hashed]
+	return id, ok
+}
+
+type Exchange struct {
+	client      *coinbaseapi.RestClient
+	key, secret string
+
+	orderIDs *orderIDMap
+
+	marketsMu sync.Mutex
+	markets   types.MarketMap
+}
+
+func New(key, secret string) *Exchange {
+	baseURL := coinbaseapi.ProductionAPIURL
+	if override := os.Getenv("COINBASE_API_BASE_URL"); len(override) > 0 {
+		baseURL = override
+	}
+
+	client := coinbaseapi.NewRestClient(baseURL)
+	client.Auth(key, secret)
+	return &Exchange{
+		client:   client,
+		key:      key,
+		secret:   secret,
+		orderIDs: &orderIDMap{ids: make(map[uint64]string)},
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeCoinbase
+}
+
+// Capabilities returns the capabilities of coinbase, only the stop limit orders are supported by the stop orders
+func (e *Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(types.CapabilityUserDataStream, types.CapabilityPostOnly, types.CapabilityStopOrder, types.CapabilityBatchCancel)
+}
+
+// MaxOrdersPerBatch returns the max number of the order ids of a batch cancel request
+func (e *Exchange) MaxOrdersPerBatch() int {
+	return 100
+}
+
+// PlatformFeeCurrency returns empty since coinbase doesn't have the platform token for the fee discount
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e)
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	log.Info("querying market info...")
+
+	products, err := e.client.MarketService.Products(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, p := range products {
+		if p.TradingDisabled || p.IsDisabled || p.Status != "online" {
+			continue
+		}
+
+		market := toGlobalMarket(p)
+		markets[market.Symbol] = market
+	}
+
+	e.marketsMu.Lock()
+	e.markets = markets
+	e.marketsMu.Unlock()
+	return markets, nil
+}
+
+// loadMarkets returns the queried markets, the markets are queried if they are not loaded yet
+func (e *Exchange) loadMarkets(ctx context.Context) types.MarketMap {
+	e.marketsMu.Lock()
+	markets := e.markets
+	e.marketsMu.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.QueryMarkets(ctx)
+		if err != nil {
+			log.WithError(err).Error("market query error")
+		}
+	}
+
+	return markets
+}
+
+// localSymbol converts the global symbol to the product ID with the queried markets
+func (e *Exchange) localSymbol(ctx context.Context, symbol string) string {
+	return toLocalSymbol(symbol, e.loadMarkets(ctx))
+}
+
+// feeCurrency returns the quote currency of the symbol, coinbase charges the commission in the quote currency
+func (e *Exchange) feeCurrency(ctx context.Context, symbol string) string {
+	if market, ok := e.loadMarkets(ctx)[symbol]; ok {
+		return market.QuoteCurrency
+	}
+
+	return ""
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{
+		MakerCommission: 40, // 0.4%
+		TakerCommission: 60, // 0.6%
+	}
+
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	accounts, err := e.client.AccountService.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = make(types.BalanceMap)
+	for _, a := range accounts {
+		currency := toGlobalCurrency(a.Currency)
+		balance := balances[currency]
+		balance.Currency = currency
+		balance.Available += a.AvailableBalance.Value
+		balance.Locked += a.Hold.Value
+		balances[currency] = balance
+	}
+
+	return balances, nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	granularity, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit = maxCandles
+	if options.Limit > 0 && options.Limit < limit {
+		limit = options.Limit
+	}
+
+	var endTime = time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	// coinbase rejects the time range of more than 300 candles, so the time range is cut by the limit
+	var window = time.Duration(limit) * interval.Duration()
+	var startTime = endTime.Add(-window)
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+		if startTime.Add(window).Before(endTime) {
+			endTime = startTime.Add(window)
+		}
+	}
+
+	log.Infof("querying kline %s %s %+v", symbol, interval, options)
+
+	candles, err := e.client.MarketService.Candles(ctx, e.localSymbol(ctx, symbol), granularity, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, candle := range candles {
+		kline := toGlobalKLine(symbol, interval, candle)
+		kline.Closed = kline.EndTime.Before(time.Now())
+		kLines = append(kLines, kline)
+	}
+
+	// coinbase returns the latest candle first
+	sort.Slice(kLines, func(i, j int) bool {
+		return kLines[i].StartTime.Before(kLines[j].StartTime)
+	})
+
+	if len(kLines) > limit {
+		kLines = kLines[len(kLines)-limit:]
+	}
+
+	return kLines, nil
+}
+
+// QueryTrades queries the fills in the time range, the last trade ID option is not supported since the trade ID of coinbase is not sequential.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var startTime, endTime time.Time
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+	}
+
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	fills, err := e.client.TradeService.ListFills(ctx, e.localSymbol(ctx, symbol), "", startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	feeCurrency := e.feeCurrency(ctx, symbol)
+	for _, fill := range fills {
+		trades = append(trades, toGlobalTrade(fill, feeCurrency))
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		configuration, err := toLocalOrderConfiguration(order)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		req := coinbaseapi.CreateOrderRequest{
+			ClientOrderID:      order.ClientOrderID,
+			ProductID:          e.localSymbol(ctx, order.Symbol),
+			Side:               toLocalSideType(order.Side),
+			OrderConfiguration: configuration,
+		}
+
+		if len(req.ClientOrderID) == 0 {
+			req.ClientOrderID = uuid.New().String()
+		}
+
+		resp, err := e.client.TradeService.CreateOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		orderID := resp.SuccessResponse.OrderID
+		if len(orderID) == 0 {
+			orderID = resp.OrderID
+		}
+
+		if len(orderID) == 0 {
+			return createdOrders, errors.New("returned empty order id")
+		}
+
+		order.ClientOrderID = req.ClientOrderID
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:  order,
+			Exchange:     types.ExchangeCoinbase.String(),
+			OrderID:      e.orderIDs.Add(orderID),
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: time.Now(),
+			UpdateTime:   time.Now(),
+		})
+	}
+
+	return createdOrders, err
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	localOrders, err := e.client.TradeService.ListOrders(ctx, e.localSymbol(ctx, symbol), []coinbaseapi.OrderStatus{coinbaseapi.OrderStatusOpen}, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range localOrders {
+		e.orderIDs.Add(o.OrderID)
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	return orders, nil
+}
+
+// QueryClosedOrders queries the done orders in the time range, lastOrderID is not supported on coinbase
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	localOrders, err := e.client.TradeService.ListOrders(ctx, e.localSymbol(ctx, symbol), []coinbaseapi.OrderStatus{
+		coinbaseapi.OrderStatusFilled,
+		coinbaseapi.OrderStatusCancelled,
+		coinbaseapi.OrderStatusExpired,
+		coinbaseapi.OrderStatusFailed,
+	}, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range localOrders {
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime)
+	})
+
+	return orders, nil
+}
+
+// CancelOrders cancels the orders in one batch, coinbase can not cancel the order by the client order ID,
+// so the order without the known order ID is looked up from the open orders by the client order ID.
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var orderIDs []string
+	for _, o := range orders {
+		if orderID, ok := e.orderIDs.Get(o.OrderID); ok {
+			orderIDs = append(orderIDs, orderID)
+			continue
+		}
+
+		if len(o.ClientOrderID) == 0 {
+			return fmt.Errorf("order id or client order id is not defined, order=%+v", o)
+		}
+
+		orderID, err := e.findOrderIDByClientOrderID(ctx, o.Symbol, o.ClientOrderID)
+		if err != nil {
+			return err
+		}
+
+		orderIDs = append(orderIDs, orderID)
+	}
+
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	results, err := e.client.TradeService.CancelOrders(ctx, orderIDs...)
+	if err != nil {
+		return err
+	}
+
+	var err2 error
+	for _, r := range results {
+		if !r.Success {
+			err2 = fmt.Errorf("order %s cancel error: %s", r.OrderID, r.FailureReason)
+			log.WithError(err2).Errorf("order cancel error")
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) findOrderIDByClientOrderID(ctx context.Context, symbol, clientOrderID string) (string, error) {
+	localOrders, err := e.client.TradeService.ListOrders(ctx, e.localSymbol(ctx, symbol), []coinbaseapi.OrderStatus{coinbaseapi.OrderStatusOpen}, time.Time{}, time.Time{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, o := range localOrders {
+		if o.ClientOrderID == clientOrderID {
+			e.orderIDs.Add(o.OrderID)
+			return o.OrderID, nil
+		}
+	}
+
+	return "", fmt.Errorf("open order of the client order id %s not found", clientOrderID)
+}
+
+func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.CancelOrders(ctx, orders...); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// queryTransactions queries the wallet transactions of the asset in the time range, the transactions of all the assets are returned if the asset is empty
+func (e *Exchange) queryTransactions(ctx context.Context, asset string, since, until time.Time) (transactions []coinbaseapi.Transaction, err error) {
+	accounts, err := e.client.AccountService.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range accounts {
+		if len(asset) > 0 && toGlobalCurrency(a.Currency) != toLocalCurrency(asset) {
+			continue
+		}
+
+		accountTransactions, err := e.client.AccountService.Transactions(ctx, a.UUID, since)
+		if err != nil {
+			return transactions, err
+		}
+
+		for _, t := range accountTransactions {
+			if t.CreatedAt.After(until) {
+				continue
+			}
+
+			transactions = append(transactions, t)
+		}
+	}
+
+	return transactions, nil
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	log.Infof("querying deposit history %s: %s <=> %s", asset, since, until)
+
+	transactions, err := e.queryTransactions(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range transactions {
+		if t.IsDeposit() {
+			allDeposits = append(allDeposits, toGlobalDeposit(t))
+		}
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time)
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	log.Infof("querying withdraw %s: %s <=> %s", asset, since, until)
+
+	transactions, err := e.queryTransactions(ctx, asset, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range transactions {
+		if t.IsWithdrawal() {
+			allWithdraws = append(allWithdraws, toGlobalWithdraw(t))
+		}
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime)
+	})
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
+	ticker, err := e.client.MarketService.Ticker(ctx, e.localSymbol(ctx, symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	return (ticker.BestBid.Float64() + ticker.BestAsk.Float64()) / 2, nil
+}
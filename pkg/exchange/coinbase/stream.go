@@ -0,0 +1,410 @@
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/coinbase/coinbaseapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	Conn     *websocket.Conn
+	connLock sync.Mutex
+
+	publicOnly bool
+
+	// klines is the building kline of each symbol and interval, the candles channel of coinbase only pushes the 5m candles,
+	// so the klines are built from the market trades, the kline is closed when the trade of the next period is received.
+	klines map[string]*types.KLine
+
+	// filledQuantities is the last cumulative quantity of the open orders, the user channel doesn't push the fills,
+	// the fills are queried when the cumulative quantity of the order increases.
+	filledQuantities map[string]fixedpoint.Value
+
+	// tradeIDs is the emitted trade IDs of the open orders, the fills of the order are queried more than once
+	tradeIDs map[string]map[int64]struct{}
+}
+
+func NewStream(exchange *Exchange) *Stream {
+	return &Stream{
+		exchange:         exchange,
+		klines:           make(map[string]*types.KLine),
+		filledQuantities: make(map[string]fixedpoint.Value),
+		tradeIDs:         make(map[string]map[int64]struct{}),
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	go s.read(ctx)
+	return nil
+}
+
+func (s *Stream) connect(ctx context.Context) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, coinbaseapi.WebSocketURL, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected")
+
+	s.connLock.Lock()
+	s.Conn = conn
+	s.connLock.Unlock()
+
+	// coinbase doesn't send the welcome message, the subscriptions are sent right after the connection is established
+	s.subscribe(ctx)
+
+	s.EmitConnect()
+	return nil
+}
+
+func (s *Stream) writeCommand(command coinbaseapi.WebSocketCommand) error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.Conn.WriteJSON(command)
+}
+
+// subscribe sends one subscription per channel, the kline subscriptions are converted to the market trades subscription of the products,
+// the heartbeats channel is subscribed to keep the connection alive when there is no update.
+func (s *Stream) subscribe(ctx context.Context) {
+	var productIDs []string
+	var subscribed = map[string]struct{}{}
+	for _, subscription := range s.Subscriptions {
+		if subscription.Channel != types.KLineChannel {
+			log.Errorf("coinbase stream does not support the channel %s", subscription.Channel)
+			continue
+		}
+
+		if _, err := toLocalInterval(types.Interval(subscription.Options.Interval)); err != nil {
+			log.WithError(err).Errorf("subscription error: %+v", subscription)
+			continue
+		}
+
+		productID := s.exchange.localSymbol(ctx, subscription.Symbol)
+		if _, ok := subscribed[productID]; ok {
+			continue
+		}
+
+		subscribed[productID] = struct{}{}
+		productIDs = append(productIDs, productID)
+	}
+
+	var commands = []coinbaseapi.WebSocketCommand{
+		coinbaseapi.NewSubscribeCommand(coinbaseapi.ChannelHeartbeats, nil, s.exchange.key, s.exchange.secret),
+	}
+
+	if len(productIDs) > 0 {
+		commands = append(commands, coinbaseapi.NewSubscribeCommand(coinbaseapi.ChannelMarketTrades, productIDs, s.exchange.key, s.exchange.secret))
+	}
+
+	if !s.publicOnly {
+		commands = append(commands, coinbaseapi.NewSubscribeCommand(coinbaseapi.ChannelUser, nil, s.exchange.key, s.exchange.secret))
+	}
+
+	for _, command := range commands {
+		if err := s.writeCommand(command); err != nil {
+			log.WithError(err).Errorf("subscribe error: %s", command.Channel)
+		}
+	}
+}
+
+func (s *Stream) read(ctx context.Context) {
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		default:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.SetReadDeadline(time.Now().Add(1 * time.Minute)); err != nil {
+				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+			}
+
+			mt, message, err := conn.ReadMessage()
+			receivedTime := time.Now()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+					log.WithError(err).Errorf("read error: %s", err.Error())
+				} else {
+					log.Info("websocket connection closed, going away")
+				}
+
+				s.EmitDisconnect()
+
+				// reconnect with the exponential backoff
+				backoff := types.NewReconnectBackoff()
+				for err != nil {
+					select {
+					case <-ctx.Done():
+						return
+
+					default:
+						err = s.connect(ctx)
+						if err != nil {
+							delay := backoff.Next()
+							log.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+							time.Sleep(delay)
+						}
+					}
+				}
+
+				continue
+			}
+
+			s.SetLastMessageTime(receivedTime)
+
+			// skip non-text messages
+			if mt != websocket.TextMessage {
+				continue
+			}
+
+			log.Debug(string(message))
+
+			var m coinbaseapi.WebSocketMessage
+			if err := json.Unmarshal(message, &m); err != nil {
+				log.WithError(err).Errorf("[coinbase] message parse error: %s", message)
+				continue
+			}
+
+			if m.Type == "error" {
+				log.Errorf("[coinbase] websocket error: %s", m.Message)
+				continue
+			}
+
+			events, err := coinbaseapi.ParseEvents(&m)
+			if err != nil {
+				log.WithError(err).Errorf("[coinbase] event parse error: %s", message)
+				continue
+			}
+
+			if s.dispatchEvents(ctx, events) {
+				s.Latency().Record(m.Channel, m.Timestamp, receivedTime, time.Now())
+			}
+		}
+	}
+}
+
+// dispatchEvents emits the events, false is returned if the events are not supported
+func (s *Stream) dispatchEvents(ctx context.Context, events interface{}) bool {
+	switch events := events.(type) {
+
+	case []coinbaseapi.MarketTradeEvent:
+		for _, e := range events {
+			// the snapshot carries the trades before the subscription, which can not be put into the current kline
+			if e.Type == coinbaseapi.EventTypeSnapshot {
+				continue
+			}
+
+			s.handleMarketTrades(e.Trades)
+		}
+		return true
+
+	case []coinbaseapi.UserEvent:
+		for _, e := range events {
+			s.handleUserEvent(ctx, e)
+		}
+		return true
+	}
+
+	return false
+}
+
+func (s *Stream) handleMarketTrades(trades []coinbaseapi.MarketTrade) {
+	// the trades of the message are pushed with the latest trade first
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	var updated = map[string]*types.KLine{}
+	for _, trade := range trades {
+		symbol := toGlobalSymbol(trade.ProductID)
+		for _, subscription := range s.Subscriptions {
+			if subscription.Channel != types.KLineChannel || subscription.Symbol != symbol {
+				continue
+			}
+
+			interval := types.Interval(subscription.Options.Interval)
+			key := symbol + "." + string(interval)
+			startTime := trade.Time.Truncate(interval.Duration())
+
+			kline, ok := s.klines[key]
+			if ok && startTime.Before(kline.StartTime) {
+				continue
+			}
+
+			if ok && startTime.After(kline.StartTime) {
+				kline.Closed = true
+				s.EmitKLine(*kline)
+				s.EmitKLineClosed(*kline)
+				delete(updated, key)
+				ok = false
+			}
+
+			if !ok {
+				kline = &types.KLine{
+					Exchange:  types.ExchangeCoinbase.String(),
+					Symbol:    symbol,
+					Interval:  interval,
+					StartTime: startTime,
+					EndTime:   startTime.Add(interval.Duration() - time.Millisecond),
+					Open:      trade.Price.Float64(),
+					High:      trade.Price.Float64(),
+					Low:       trade.Price.Float64(),
+				}
+				s.klines[key] = kline
+			}
+
+			price := trade.Price.Float64()
+			if price > kline.High {
+				kline.High = price
+			}
+
+			if price < kline.Low {
+				kline.Low = price
+			}
+
+			kline.Close = price
+			kline.Volume += trade.Size.Float64()
+			kline.QuoteVolume += trade.Size.Float64() * price
+			kline.NumberOfTrades++
+			kline.LastTradeID = hashID(trade.TradeID)
+			updated[key] = kline
+		}
+	}
+
+	for _, kline := range updated {
+		s.EmitKLine(*kline)
+	}
+}
+
+// handleUserEvent emits the order updates, the order is queried for the price and the quantity since the user channel doesn't push them,
+// and the new fills of the order are queried when the cumulative quantity increases.
+func (s *Stream) handleUserEvent(ctx context.Context, e coinbaseapi.UserEvent) {
+	for _, o := range e.Orders {
+		lastFilledQuantity, known := s.filledQuantities[o.OrderID]
+		s.filledQuantities[o.OrderID] = o.CumulativeQuantity
+
+		// the snapshot carries the open orders at the subscription time
+		if e.Type == coinbaseapi.EventTypeSnapshot {
+			s.exchange.orderIDs.Add(o.OrderID)
+			continue
+		}
+
+		orderID := s.exchange.orderIDs.Add(o.OrderID)
+		remoteOrder, err := s.exchange.client.TradeService.GetOrder(ctx, o.OrderID)
+		if err != nil {
+			log.WithError(err).Errorf("order query error: %s", o.OrderID)
+		} else {
+			order := toGlobalOrder(*remoteOrder)
+			order.OrderID = orderID
+			order.Status = toGlobalOrderStatus(o.Status, o.CumulativeQuantity)
+			order.ExecutedQuantity = o.CumulativeQuantity.Float64()
+			order.IsWorking = isWorkingOrderStatus(o.Status)
+			order.UpdateTime = time.Now()
+			s.EmitOrderUpdate(order)
+		}
+
+		if o.CumulativeQuantity > lastFilledQuantity || (!known && o.CumulativeQuantity > 0) {
+			s.emitFills(ctx, o.OrderID, toGlobalSymbol(o.ProductID))
+		}
+
+		if !isWorkingOrderStatus(o.Status) {
+			delete(s.filledQuantities, o.OrderID)
+			delete(s.tradeIDs, o.OrderID)
+		}
+	}
+}
+
+func (s *Stream) emitFills(ctx context.Context, orderID, symbol string) {
+	fills, err := s.exchange.client.TradeService.ListFills(ctx, "", orderID, time.Time{}, time.Time{})
+	if err != nil {
+		log.WithError(err).Errorf("fill query error: %s", orderID)
+		return
+	}
+
+	sort.Slice(fills, func(i, j int) bool {
+		return fills[i].TradeTime.Before(fills[j].TradeTime)
+	})
+
+	emitted, ok := s.tradeIDs[orderID]
+	if !ok {
+		emitted = make(map[int64]struct{})
+		s.tradeIDs[orderID] = emitted
+	}
+
+	var hasNewTrades = false
+	feeCurrency := s.exchange.feeCurrency(ctx, symbol)
+	for _, fill := range fills {
+		trade := toGlobalTrade(fill, feeCurrency)
+		if _, ok := emitted[trade.ID]; ok {
+			continue
+		}
+
+		emitted[trade.ID] = struct{}{}
+		hasNewTrades = true
+		s.EmitTradeUpdate(trade)
+	}
+
+	if !hasNewTrades {
+		return
+	}
+
+	// coinbase doesn't push the balance updates, the balances are queried after the fills
+	balances, err := s.exchange.QueryAccountBalances(ctx)
+	if err != nil {
+		log.WithError(err).Error("balance query error")
+		return
+	}
+
+	s.EmitBalanceUpdate(balances)
+}
+
+func (s *Stream) Close() error {
+	log.Infof("closing coinbase stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
+
+// Reconnect closes the current connection, the read loop reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	log.Infof("reconnecting coinbase stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
@@ -0,0 +1,477 @@
+package kraken
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/kraken/krakenapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// legacyCurrencies maps the legacy asset names of kraken to the global currencies,
+// the legacy assets are prefixed with X (crypto) or Z (fiat), and the assets listed later are not prefixed.
+var legacyCurrencies = map[string]string{
+	"XXBT": "BTC",
+	"XBT":  "BTC",
+	"XXDG": "DOGE",
+	"XDG":  "DOGE",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XMLN": "MLN",
+	"XREP": "REP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XXRP": "XRP",
+	"XZEC": "ZEC",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZCAD": "CAD",
+	"ZJPY": "JPY",
+	"ZAUD": "AUD",
+}
+
+func toGlobalCurrency(asset string) string {
+	if currency, ok := legacyCurrencies[asset]; ok {
+		return currency
+	}
+
+	return asset
+}
+
+func toLocalCurrency(currency string) string {
+	switch currency {
+	case "BTC":
+		return "XBT"
+
+	case "DOGE":
+		return "XDG"
+	}
+
+	return currency
+}
+
+// toGlobalSymbol converts the normalized symbol of the websocket, e.g., BTC/USD or XBT/USD, to the global symbol
+func toGlobalSymbol(symbol string) string {
+	parts := strings.SplitN(symbol, "/", 2)
+	if len(parts) < 2 {
+		return symbol
+	}
+
+	return toGlobalCurrency(parts[0]) + toGlobalCurrency(parts[1])
+}
+
+// pairMap resolves the symbols between the global symbols and the pair names,
+// the pairs are named irregularly, e.g., XXBTZUSD in the responses and XBTUSD (the alternative name) in the order descriptions.
+type pairMap struct {
+	pairs   map[string]krakenapi.AssetPair
+	symbols map[string]string
+}
+
+func newPairMap(assetPairs []krakenapi.AssetPair) *pairMap {
+	m := &pairMap{
+		pairs:   make(map[string]krakenapi.AssetPair),
+		symbols: make(map[string]string),
+	}
+
+	for _, p := range assetPairs {
+		symbol := toGlobalCurrency(p.Base) + toGlobalCurrency(p.Quote)
+		m.pairs[symbol] = p
+		m.symbols[p.Name] = symbol
+		m.symbols[p.AltName] = symbol
+		if len(p.WSName) > 0 {
+			m.symbols[p.WSName] = symbol
+		}
+	}
+
+	return m
+}
+
+// Pair returns the asset pair of the global symbol
+func (m *pairMap) Pair(symbol string) (krakenapi.AssetPair, bool) {
+	if m == nil {
+		return krakenapi.AssetPair{}, false
+	}
+
+	p, ok := m.pairs[symbol]
+	return p, ok
+}
+
+// LocalSymbol returns the pair name of the global symbol, the symbol is sent as is if the pair is not found
+func (m *pairMap) LocalSymbol(symbol string) string {
+	if p, ok := m.Pair(symbol); ok {
+		return p.Name
+	}
+
+	return symbol
+}
+
+// Symbol returns the global symbol of the pair name, the alternative name or the websocket name
+func (m *pairMap) Symbol(name string) string {
+	if m != nil {
+		if symbol, ok := m.symbols[name]; ok {
+			return symbol
+		}
+	}
+
+	return toGlobalSymbol(name)
+}
+
+// WebSocketSymbol returns the normalized symbol of the websocket api v2, e.g., BTC/USD
+func (m *pairMap) WebSocketSymbol(symbol string) string {
+	if p, ok := m.Pair(symbol); ok {
+		return toGlobalCurrency(p.Base) + "/" + toGlobalCurrency(p.Quote)
+	}
+
+	return symbol
+}
+
+func toGlobalMarket(symbol string, p krakenapi.AssetPair) types.Market {
+	tickSize := p.TickSize.Float64()
+	if tickSize == 0 {
+		tickSize = math.Pow10(-p.PairDecimals)
+	}
+
+	return types.Market{
+		Symbol:          symbol,
+		PricePrecision:  p.PairDecimals,
+		VolumePrecision: p.LotDecimals,
+		QuoteCurrency:   toGlobalCurrency(p.Quote),
+		BaseCurrency:    toGlobalCurrency(p.Base),
+		MinNotional:     p.CostMin.Float64(),
+		MinAmount:       p.CostMin.Float64(),
+		MinLot:          math.Pow10(-p.LotDecimals),
+		MinQuantity:     p.OrderMin.Float64(),
+		MaxQuantity:     math.MaxFloat64,
+		StepSize:        math.Pow10(-p.LotDecimals),
+		MinPrice:        tickSize,
+		MaxPrice:        math.MaxFloat64,
+		TickSize:        tickSize,
+	}
+}
+
+// formatPrice rounds the price to the tick size and formats it with the price decimals of the pair,
+// the orders with more decimals than the pair decimals are rejected.
+func formatPrice(price float64, p krakenapi.AssetPair) string {
+	if tickSize := p.TickSize.Float64(); tickSize > 0 {
+		price = math.Round(price/tickSize) * tickSize
+	}
+
+	return strconv.FormatFloat(price, 'f', p.PairDecimals, 64)
+}
+
+// formatVolume truncates the volume to the lot decimals of the pair, so that the volume never exceeds the balance
+func formatVolume(volume float64, p krakenapi.AssetPair) string {
+	pow := math.Pow10(p.LotDecimals)
+
+	// the epsilon compensates the floating error, e.g., 0.3 * 1e8 = 29999999.999999996
+	return strconv.FormatFloat(math.Floor(volume*pow+1e-6)/pow, 'f', p.LotDecimals, 64)
+}
+
+// intervalMinutes is the kline intervals supported by kraken
+var intervalMinutes = map[types.Interval]int{
+	types.Interval1m:  1,
+	types.Interval5m:  5,
+	types.Interval15m: 15,
+	types.Interval30m: 30,
+	types.Interval1h:  60,
+	types.Interval4h:  240,
+	types.Interval1d:  1440,
+}
+
+func toLocalInterval(interval types.Interval) (int, error) {
+	if minutes, ok := intervalMinutes[interval]; ok {
+		return minutes, nil
+	}
+
+	return 0, fmt.Errorf("interval %s is not supported", interval)
+}
+
+func toGlobalInterval(minutes int) types.Interval {
+	for interval, m := range intervalMinutes {
+		if m == minutes {
+			return interval
+		}
+	}
+
+	return types.Interval(strconv.Itoa(minutes) + "m")
+}
+
+func hashID(id string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+
+	// keep it in the int64 range, so that it can be stored as the trade ID
+	return h.Sum64() & math.MaxInt64
+}
+
+func toLocalSideType(side types.SideType) string {
+	return strings.ToLower(string(side))
+}
+
+func toGlobalSideType(side string) types.SideType {
+	if side == "sell" {
+		return types.SideTypeSell
+	}
+
+	return types.SideTypeBuy
+}
+
+func toLocalOrderType(orderType types.OrderType) (krakenapi.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit:
+		return krakenapi.OrderTypeLimit, nil
+
+	case types.OrderTypeMarket:
+		return krakenapi.OrderTypeMarket, nil
+
+	case types.OrderTypeStopLimit:
+		return krakenapi.OrderTypeStopLossLimit, nil
+
+	case types.OrderTypeStopMarket:
+		return krakenapi.OrderTypeStopLoss, nil
+	}
+
+	return "", fmt.Errorf("order type %s not supported", orderType)
+}
+
+func toGlobalOrderType(orderType krakenapi.OrderType) types.OrderType {
+	switch orderType {
+	case krakenapi.OrderTypeLimit:
+		return types.OrderTypeLimit
+
+	case krakenapi.OrderTypeMarket:
+		return types.OrderTypeMarket
+
+	case krakenapi.OrderTypeStopLossLimit:
+		return types.OrderTypeStopLimit
+
+	case krakenapi.OrderTypeStopLoss:
+		return types.OrderTypeStopMarket
+	}
+
+	log.Errorf("unknown order type: %v", orderType)
+	return types.OrderType(orderType)
+}
+
+func toGlobalOrderStatus(o krakenapi.Order) types.OrderStatus {
+	switch o.Status {
+	case krakenapi.OrderStatusPending, krakenapi.OrderStatusOpen:
+		if o.VolumeExec > 0 {
+			return types.OrderStatusPartiallyFilled
+		}
+
+		return types.OrderStatusNew
+
+	case krakenapi.OrderStatusClosed:
+		return types.OrderStatusFilled
+
+	case krakenapi.OrderStatusCanceled, krakenapi.OrderStatusExpired:
+		return types.OrderStatusCanceled
+	}
+
+	return types.OrderStatusRejected
+}
+
+func toGlobalOrder(o krakenapi.Order, symbol string) types.Order {
+	orderType := toGlobalOrderType(o.Description.OrderType)
+
+	// the price of the stop orders is the trigger price, the limit price of the stop loss limit order is the price2
+	price, stopPrice := o.Description.Price, o.StopPrice
+	switch orderType {
+	case types.OrderTypeStopLimit:
+		price, stopPrice = o.Description.Price2, o.Description.Price
+
+	case types.OrderTypeStopMarket:
+		price, stopPrice = 0, o.Description.Price
+	}
+
+	updateTime := o.CloseTime.Time()
+	if updateTime.IsZero() {
+		updateTime = o.OpenTime.Time()
+	}
+
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        symbol,
+			Side:          toGlobalSideType(o.Description.Type),
+			Type:          orderType,
+			Quantity:      o.Volume.Float64(),
+			Price:         price.Float64(),
+			StopPrice:     stopPrice.Float64(),
+			TimeInForce:   "GTC",
+		},
+		Exchange:         types.ExchangeKraken.String(),
+		OrderID:          hashID(o.ID),
+		Status:           toGlobalOrderStatus(o),
+		ExecutedQuantity: o.VolumeExec.Float64(),
+		IsWorking:        o.Status == krakenapi.OrderStatusPending || o.Status == krakenapi.OrderStatusOpen,
+		CreationTime:     o.OpenTime.Time(),
+		UpdateTime:       updateTime,
+	}
+}
+
+// toGlobalTrade converts the trade, the fee is charged in the quote currency
+func toGlobalTrade(t krakenapi.Trade, symbol, feeCurrency string) types.Trade {
+	side := toGlobalSideType(t.Type)
+	return types.Trade{
+		ID:            int64(hashID(t.ID)),
+		OrderID:       hashID(t.OrderID),
+		Exchange:      types.ExchangeKraken.String(),
+		Price:         t.Price.Float64(),
+		Quantity:      t.Volume.Float64(),
+		QuoteQuantity: t.Cost.Float64(),
+		Symbol:        symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       t.Maker,
+		Time:          t.Time.Time(),
+		Fee:           t.Fee.Float64(),
+		FeeCurrency:   feeCurrency,
+	}
+}
+
+func toGlobalExecutionOrderStatus(status string) types.OrderStatus {
+	switch status {
+	case "pending_new", "new":
+		return types.OrderStatusNew
+
+	case "partially_filled":
+		return types.OrderStatusPartiallyFilled
+
+	case "filled":
+		return types.OrderStatusFilled
+
+	case "canceled", "expired":
+		return types.OrderStatusCanceled
+	}
+
+	return types.OrderStatusRejected
+}
+
+// toGlobalExecutionOrder converts the execution of the websocket to the order, the execution must carry the order details
+func toGlobalExecutionOrder(e krakenapi.Execution) types.Order {
+	orderType := toGlobalOrderType(krakenapi.OrderType(e.OrderType))
+
+	var stopPrice float64
+	if e.Triggers != nil {
+		stopPrice = e.Triggers.Price.Float64()
+	}
+
+	status := toGlobalExecutionOrderStatus(e.OrderStatus)
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: e.ClientOrderID,
+			Symbol:        toGlobalSymbol(e.Symbol),
+			Side:          toGlobalSideType(e.Side),
+			Type:          orderType,
+			Quantity:      e.OrderQty.Float64(),
+			Price:         e.LimitPrice.Float64(),
+			StopPrice:     stopPrice,
+			TimeInForce:   types.TimeInForce(e.TimeInForce),
+		},
+		Exchange:         types.ExchangeKraken.String(),
+		OrderID:          hashID(e.OrderID),
+		Status:           status,
+		ExecutedQuantity: e.CumQty.Float64(),
+		IsWorking:        status == types.OrderStatusNew || status == types.OrderStatusPartiallyFilled,
+		CreationTime:     e.Timestamp,
+		UpdateTime:       e.Timestamp,
+	}
+}
+
+// toGlobalExecutionTrade converts the trade execution of the websocket, the liquidity indicator is m for the maker
+func toGlobalExecutionTrade(e krakenapi.Execution) types.Trade {
+	side := toGlobalSideType(e.Side)
+	trade := types.Trade{
+		ID:            int64(hashID(e.ExecID)),
+		OrderID:       hashID(e.OrderID),
+		Exchange:      types.ExchangeKraken.String(),
+		Price:         e.LastPrice.Float64(),
+		Quantity:      e.LastQty.Float64(),
+		QuoteQuantity: e.Cost.Float64(),
+		Symbol:        toGlobalSymbol(e.Symbol),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       e.LiquidityInd == "m",
+		Time:          e.Timestamp,
+	}
+
+	if len(e.Fees) > 0 {
+		trade.Fee = e.Fees[0].Quantity.Float64()
+		trade.FeeCurrency = toGlobalCurrency(e.Fees[0].Asset)
+	}
+
+	return trade
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, candle krakenapi.Candle) types.KLine {
+	return types.KLine{
+		Exchange:       types.ExchangeKraken.String(),
+		Symbol:         symbol,
+		StartTime:      candle.Time,
+		EndTime:        candle.Time.Add(interval.Duration() - time.Millisecond),
+		Interval:       interval,
+		Open:           candle.Open.Float64(),
+		Close:          candle.Close.Float64(),
+		High:           candle.High.Float64(),
+		Low:            candle.Low.Float64(),
+		Volume:         candle.Volume.Float64(),
+		QuoteVolume:    candle.Volume.Float64() * candle.VWAP.Float64(),
+		NumberOfTrades: uint64(candle.Count),
+	}
+}
+
+func toGlobalOHLCKLine(o krakenapi.OHLC) types.KLine {
+	interval := toGlobalInterval(o.Interval)
+	return types.KLine{
+		Exchange:       types.ExchangeKraken.String(),
+		Symbol:         toGlobalSymbol(o.Symbol),
+		StartTime:      o.IntervalBegin,
+		EndTime:        o.IntervalBegin.Add(interval.Duration() - time.Millisecond),
+		Interval:       interval,
+		Open:           o.Open.Float64(),
+		Close:          o.Close.Float64(),
+		High:           o.High.Float64(),
+		Low:            o.Low.Float64(),
+		Volume:         o.Volume.Float64(),
+		QuoteVolume:    o.Volume.Float64() * o.VWAP.Float64(),
+		NumberOfTrades: uint64(o.Trades),
+	}
+}
+
+func toGlobalDepositStatus(status string) types.DepositStatus {
+	switch status {
+	case "Success", "Settled":
+		return types.DepositSuccess
+
+	case "Initial", "Pending":
+		return types.DepositPending
+
+	case "Failure":
+		return types.DepositRejected
+	}
+
+	return types.DepositStatus(strings.ToLower(status))
+}
+
+func toGlobalWithdrawStatus(status string) string {
+	switch status {
+	case "Success":
+		return "completed" // make it compatible with binance
+
+	case "Initial", "Pending", "Settled":
+		return "processing"
+
+	case "Failure":
+		return "failed"
+	}
+
+	return strings.ToLower(status)
+}
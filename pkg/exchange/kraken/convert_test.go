@@ -0,0 +1,150 @@
+package kraken
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/kraken/krakenapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testPairs = newPairMap([]krakenapi.AssetPair{
+	{Name: "XXBTZUSD", AltName: "XBTUSD", WSName: "XBT/USD", Base: "XXBT", Quote: "ZUSD", PairDecimals: 1, LotDecimals: 8, TickSize: fixedpoint.NewFromFloat(0.1)},
+	{Name: "DOTUSD", AltName: "DOTUSD", WSName: "DOT/USD", Base: "DOT", Quote: "ZUSD", PairDecimals: 4, LotDecimals: 8},
+})
+
+func TestPairMap(t *testing.T) {
+	assert.Equal(t, "XXBTZUSD", testPairs.LocalSymbol("BTCUSD"))
+	assert.Equal(t, "DOTUSD", testPairs.LocalSymbol("DOTUSD"))
+	assert.Equal(t, "BTCUSD", testPairs.Symbol("XXBTZUSD"))
+	assert.Equal(t, "BTCUSD", testPairs.Symbol("XBTUSD"))
+	assert.Equal(t, "BTCUSD", testPairs.Symbol("XBT/USD"))
+	assert.Equal(t, "BTC/USD", testPairs.WebSocketSymbol("BTCUSD"))
+
+	// the websocket api v2 uses the normalized names
+	assert.Equal(t, "DOGEUSD", testPairs.Symbol("DOGE/USD"))
+	assert.Equal(t, "BTCUSD", toGlobalSymbol("BTC/USD"))
+	assert.Equal(t, "XBT", toLocalCurrency("BTC"))
+}
+
+func TestFormatDecimals(t *testing.T) {
+	btc, _ := testPairs.Pair("BTCUSD")
+	assert.Equal(t, "34567.9", formatPrice(34567.89, btc))
+	assert.Equal(t, "0.30000000", formatVolume(0.3, btc))
+	assert.Equal(t, "0.12345678", formatVolume(0.123456789, btc))
+
+	dot, _ := testPairs.Pair("DOTUSD")
+	assert.Equal(t, "6.1235", formatPrice(6.12346, dot))
+
+	market := toGlobalMarket("BTCUSD", btc)
+	assert.Equal(t, "BTC", market.BaseCurrency)
+	assert.Equal(t, "USD", market.QuoteCurrency)
+	assert.Equal(t, 1, market.PricePrecision)
+	assert.InDelta(t, 1e-8, market.StepSize, 1e-12)
+}
+
+func TestToGlobalOrder(t *testing.T) {
+	var orders map[string]krakenapi.Order
+	err := json.Unmarshal([]byte(`{
+		"OQCLML-BW3P3-BUCMWZ": {
+			"refid": null,
+			"userref": 0,
+			"cl_ord_id": "c1a7a1b4-1a63-4b0e-b6ad-5e3a1bd0e3f6",
+			"status": "open",
+			"opentm": 1688666559.8974,
+			"starttm": 0,
+			"expiretm": 0,
+			"descr": {
+				"pair": "XBTUSD",
+				"type": "sell",
+				"ordertype": "stop-loss-limit",
+				"price": "30000.0",
+				"price2": "29900.0",
+				"leverage": "none",
+				"order": "sell 1.25000000 XBTUSD @ stop loss 30000.0 -> limit 29900.0"
+			},
+			"vol": "1.25000000",
+			"vol_exec": "0.37500000",
+			"cost": "11250.00000",
+			"fee": "29.25000",
+			"price": "30000.0",
+			"stopprice": "0.00000",
+			"limitprice": "0.00000"
+		}
+	}`), &orders)
+	assert.NoError(t, err)
+
+	for id, o := range orders {
+		o.ID = id
+		order := toGlobalOrder(o, testPairs.Symbol(o.Description.Pair))
+		assert.Equal(t, "BTCUSD", order.Symbol)
+		assert.Equal(t, types.SideTypeSell, order.Side)
+		assert.Equal(t, types.OrderTypeStopLimit, order.Type)
+		assert.Equal(t, 29900.0, order.Price)
+		assert.Equal(t, 30000.0, order.StopPrice)
+		assert.Equal(t, 1.25, order.Quantity)
+		assert.Equal(t, 0.375, order.ExecutedQuantity)
+		assert.Equal(t, types.OrderStatusPartiallyFilled, order.Status)
+		assert.True(t, order.IsWorking)
+		assert.Equal(t, hashID("OQCLML-BW3P3-BUCMWZ"), order.OrderID)
+		assert.Equal(t, "c1a7a1b4-1a63-4b0e-b6ad-5e3a1bd0e3f6", order.ClientOrderID)
+		assert.Equal(t, int64(1688666559), order.CreationTime.Unix())
+	}
+}
+
+func TestToGlobalTrade(t *testing.T) {
+	var trade krakenapi.Trade
+	err := json.Unmarshal([]byte(`{
+		"ordertxid": "OQCLML-BW3P3-BUCMWZ",
+		"postxid": "TKH2SE-M7IF5-CFI7LT",
+		"pair": "XXBTZUSD",
+		"time": 1688667796.8802,
+		"type": "buy",
+		"ordertype": "limit",
+		"price": "30010.00000",
+		"cost": "600.20000",
+		"fee": "0.00000",
+		"vol": "0.02000000",
+		"margin": "0.00000",
+		"maker": true,
+		"misc": ""
+	}`), &trade)
+	assert.NoError(t, err)
+
+	trade.ID = "TCCCTY-WE2O6-P3NB37"
+	tr := toGlobalTrade(trade, testPairs.Symbol(trade.Pair), "USD")
+	assert.Equal(t, "BTCUSD", tr.Symbol)
+	assert.Equal(t, types.SideTypeBuy, tr.Side)
+	assert.True(t, tr.IsBuyer)
+	assert.True(t, tr.IsMaker)
+	assert.Equal(t, 30010.0, tr.Price)
+	assert.Equal(t, 0.02, tr.Quantity)
+	assert.Equal(t, 600.2, tr.QuoteQuantity)
+	assert.Equal(t, hashID("OQCLML-BW3P3-BUCMWZ"), tr.OrderID)
+	assert.Equal(t, "USD", tr.FeeCurrency)
+	assert.Equal(t, time.Unix(0, 1688667796880200000).Unix(), tr.Time.Unix())
+}
+
+func TestToGlobalKLine(t *testing.T) {
+	var candle krakenapi.Candle
+	err := json.Unmarshal([]byte(`[1688671200,"30306.1","30306.2","30305.7","30305.7","30306.1","3.39243896",23]`), &candle)
+	assert.NoError(t, err)
+
+	kline := toGlobalKLine("BTCUSD", types.Interval1h, candle)
+	assert.Equal(t, time.Unix(1688671200, 0), kline.StartTime)
+	assert.Equal(t, 30306.1, kline.Open)
+	assert.Equal(t, 30305.7, kline.Close)
+	assert.Equal(t, uint64(23), kline.NumberOfTrades)
+
+	minutes, err := toLocalInterval(types.Interval4h)
+	assert.NoError(t, err)
+	assert.Equal(t, 240, minutes)
+	assert.Equal(t, types.Interval1d, toGlobalInterval(1440))
+
+	_, err = toLocalInterval(types.Interval2h)
+	assert.Error(t, err)
+}
@@ -0,0 +1,503 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/kraken/krakenapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "kraken")
+
+// maxCandles is the max number of the candles returned by the OHLC api, only the recent candles can be queried
+const maxCandles = 720
+
+// orderIDMap maps the hashed order ID back to the kraken transaction ID, so that the orders can be canceled by the global order ID
+type orderIDMap struct {
+	mu  sync.Mutex
+	ids map[uint64]string
+}
+
+func (m *orderIDMap) Add(id string) uint64 {
+	hashed := hashID(id)
+
+	m.mu.Lock()
+	m.ids[hashed] = id
+	m.mu.Unlock()
+	return hashed
+}
+
+func (m *orderIDMap) Get(hashed uint64) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := m.ids[hashed]
+	return id, ok
+}
+
+type Exchange struct {
+	client      *krakenapi.RestClient
+	key, secret string
+
+	orderIDs *orderIDMap
+
+	pairsMu sync.Mutex
+	pairs   *pairMap
+}
+
+func New(key, secret string) *Exchange {
+	baseURL := krakenapi.ProductionAPIURL
+	if override := os.Getenv("KRAKEN_API_BASE_URL"); len(override) > 0 {
+		baseURL = override
+	}
+
+	client := krakenapi.NewRestClient(baseURL)
+	client.Auth(key, secret)
+
+	// the call rate counter limit depends on the verification tier of the account
+	switch strings.ToLower(os.Getenv("KRAKEN_API_TIER")) {
+	case "intermediate":
+		client.RateCounter = krakenapi.NewRateCounter(krakenapi.IntermediateTierMaxCounter, krakenapi.IntermediateTierDecayRate)
+	case "pro":
+		client.RateCounter = krakenapi.NewRateCounter(krakenapi.ProTierMaxCounter, krakenapi.ProTierDecayRate)
+	}
+
+	return &Exchange{
+		client:   client,
+		key:      key,
+		secret:   secret,
+		orderIDs: &orderIDMap{ids: make(map[uint64]string)},
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeKraken
+}
+
+// PlatformFeeCurrency returns empty since kraken has no platform token for paying the fees
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e)
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	log.Info("querying market info...")
+
+	assetPairs, err := e.client.MarketService.AssetPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the dark pool pairs (suffixed with .d) can not be traded with the regular orders
+	var tradingPairs []krakenapi.AssetPair
+	for _, p := range assetPairs {
+		if strings.HasSuffix(p.Name, ".d") {
+			continue
+		}
+
+		tradingPairs = append(tradingPairs, p)
+	}
+
+	pairs := newPairMap(tradingPairs)
+
+	markets := types.MarketMap{}
+	for symbol, p := range pairs.pairs {
+		markets[symbol] = toGlobalMarket(symbol, p)
+	}
+
+	e.pairsMu.Lock()
+	e.pairs = pairs
+	e.pairsMu.Unlock()
+	return markets, nil
+}
+
+// loadPairs returns the loaded pairs, the markets are queried if they are not loaded yet
+func (e *Exchange) loadPairs(ctx context.Context) *pairMap {
+	e.pairsMu.Lock()
+	pairs := e.pairs
+	e.pairsMu.Unlock()
+
+	if pairs != nil {
+		return pairs
+	}
+
+	if _, err := e.QueryMarkets(ctx); err != nil {
+		log.WithError(err).Error("market query error")
+		return nil
+	}
+
+	e.pairsMu.Lock()
+	defer e.pairsMu.Unlock()
+	return e.pairs
+}
+
+func (e *Exchange) localSymbol(ctx context.Context, symbol string) string {
+	return e.loadPairs(ctx).LocalSymbol(symbol)
+}
+
+// feeCurrency returns the quote currency of the symbol, which is the fee currency of the trades by default
+func (e *Exchange) feeCurrency(ctx context.Context, symbol string) string {
+	if p, ok := e.loadPairs(ctx).Pair(symbol); ok {
+		return toGlobalCurrency(p.Quote)
+	}
+
+	return ""
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{
+		MakerCommission: 16, // 0.16%
+		TakerCommission: 26, // 0.26%
+	}
+
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+// QueryAccountBalances returns the spot balances, the staked and the opt-in reward balances (e.g., DOT.S, USD.M) are skipped
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	remoteBalances, err := e.client.AccountService.Balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = make(types.BalanceMap)
+	for asset, b := range remoteBalances {
+		if strings.Contains(asset, ".") {
+			continue
+		}
+
+		currency := toGlobalCurrency(asset)
+		balances[currency] = types.Balance{
+			Currency:  currency,
+			Available: b.Balance - b.HoldTrade,
+			Locked:    b.HoldTrade,
+		}
+	}
+
+	return balances, nil
+}
+
+// QueryKLines queries the klines since the start time, only the recent 720 klines of the interval are available on kraken
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	minutes, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit = maxCandles
+	if options.Limit > 0 && options.Limit < limit {
+		limit = options.Limit
+	}
+
+	var since time.Time
+	if options.StartTime != nil {
+		since = *options.StartTime
+	} else if options.EndTime != nil {
+		since = options.EndTime.Add(-time.Duration(limit) * interval.Duration())
+	}
+
+	log.Infof("querying kline %s %s %+v", symbol, interval, options)
+
+	candles, err := e.client.MarketService.Candles(ctx, e.localSymbol(ctx, symbol), minutes, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, candle := range candles {
+		if options.EndTime != nil && candle.Time.After(*options.EndTime) {
+			break
+		}
+
+		kline := toGlobalKLine(symbol, interval, candle)
+		kline.Closed = kline.EndTime.Before(time.Now())
+		kLines = append(kLines, kline)
+
+		if len(kLines) >= limit {
+			break
+		}
+	}
+
+	return kLines, nil
+}
+
+// QueryTrades queries the trades in the time range, the trade history api returns the trades of all the pairs,
+// so the trades of other symbols are filtered out, and the last trade ID option is not supported.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var startTime, endTime time.Time
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+	}
+
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	remoteTrades, err := e.client.TradeService.TradesHistory(ctx, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := e.loadPairs(ctx)
+	feeCurrency := e.feeCurrency(ctx, symbol)
+	for _, t := range remoteTrades {
+		if pairs.Symbol(t.Pair) != symbol {
+			continue
+		}
+
+		trades = append(trades, toGlobalTrade(t, symbol, feeCurrency))
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	if options.Limit > 0 && len(trades) > int(options.Limit) {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, nil
+}
+
+// SubmitOrders submits the orders, the price and the volume are formatted with the decimals of the pair
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		orderType, err := toLocalOrderType(order.Type)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		pair, ok := e.loadPairs(ctx).Pair(order.Symbol)
+		if !ok {
+			return createdOrders, fmt.Errorf("market %s not found", order.Symbol)
+		}
+
+		if len(order.ClientOrderID) == 0 {
+			order.ClientOrderID = uuid.New().String()
+		}
+
+		req := krakenapi.AddOrderRequest{
+			Pair:          pair.Name,
+			Side:          toLocalSideType(order.Side),
+			OrderType:     orderType,
+			Volume:        formatVolume(orderQuantity(order), pair),
+			ClientOrderID: order.ClientOrderID,
+			TimeInForce:   string(order.TimeInForce),
+		}
+
+		switch order.Type {
+		case types.OrderTypeLimit:
+			req.Price = formatPrice(orderPrice(order), pair)
+
+		case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+			if len(order.StopPriceString) == 0 {
+				return createdOrders, fmt.Errorf("stop price string can not be empty")
+			}
+
+			// the price of the stop orders is the trigger price
+			stopPrice, err := strconv.ParseFloat(order.StopPriceString, 64)
+			if err != nil {
+				return createdOrders, err
+			}
+
+			req.Price = formatPrice(stopPrice, pair)
+			if order.Type == types.OrderTypeStopLimit {
+				req.Price2 = formatPrice(orderPrice(order), pair)
+			}
+		}
+
+		txID, err := e.client.TradeService.AddOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:  order,
+			Exchange:     types.ExchangeKraken.String(),
+			OrderID:      e.orderIDs.Add(txID),
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: time.Now(),
+			UpdateTime:   time.Now(),
+		})
+	}
+
+	return createdOrders, err
+}
+
+// orderPrice returns the price of the order, the price string is preferred since it's formatted by the market
+func orderPrice(order types.SubmitOrder) float64 {
+	if len(order.PriceString) > 0 {
+		if price, err := strconv.ParseFloat(order.PriceString, 64); err == nil {
+			return price
+		}
+	}
+
+	return order.Price
+}
+
+// orderQuantity returns the quantity of the order, the quantity string is preferred since it's formatted by the market
+func orderQuantity(order types.SubmitOrder) float64 {
+	if len(order.QuantityString) > 0 {
+		if quantity, err := strconv.ParseFloat(order.QuantityString, 64); err == nil {
+			return quantity
+		}
+	}
+
+	return order.Quantity
+}
+
+// QueryOpenOrders queries the open orders of the symbol, the open orders api returns the orders of all the pairs
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	remoteOrders, err := e.client.TradeService.OpenOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := e.loadPairs(ctx)
+	for _, o := range remoteOrders {
+		if pairs.Symbol(o.Description.Pair) != symbol {
+			continue
+		}
+
+		e.orderIDs.Add(o.ID)
+		orders = append(orders, toGlobalOrder(o, symbol))
+	}
+
+	return orders, nil
+}
+
+// QueryClosedOrders queries the closed orders in the time range, lastOrderID is not supported on kraken
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	remoteOrders, err := e.client.TradeService.ClosedOrders(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := e.loadPairs(ctx)
+	for _, o := range remoteOrders {
+		if pairs.Symbol(o.Description.Pair) != symbol {
+			continue
+		}
+
+		orders = append(orders, toGlobalOrder(o, symbol))
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime)
+	})
+
+	return orders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	for _, o := range orders {
+		var err error
+		if txID, ok := e.orderIDs.Get(o.OrderID); ok {
+			err = e.client.TradeService.CancelOrder(ctx, txID)
+		} else if len(o.ClientOrderID) > 0 {
+			err = e.client.TradeService.CancelOrderByClientOrderID(ctx, o.ClientOrderID)
+		} else {
+			return fmt.Errorf("order id or client order id is not defined, order=%+v", o)
+		}
+
+		if err != nil {
+			log.WithError(err).Errorf("order cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	log.Infof("querying deposit history %s: %s <=> %s", asset, since, until)
+
+	movements, err := e.client.AccountService.Deposits(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range movements {
+		currency := toGlobalCurrency(m.Asset)
+		if len(asset) > 0 && currency != asset {
+			continue
+		}
+
+		allDeposits = append(allDeposits, types.Deposit{
+			Time:          m.Time.Time(),
+			Amount:        m.Amount.Float64(),
+			Asset:         currency,
+			Address:       m.Info,
+			TransactionID: m.TxID,
+			Status:        toGlobalDepositStatus(m.Status),
+		})
+	}
+
+	sort.Slice(allDeposits, func(i, j int) bool {
+		return allDeposits[i].Time.Before(allDeposits[j].Time)
+	})
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	log.Infof("querying withdraw %s: %s <=> %s", asset, since, until)
+
+	movements, err := e.client.AccountService.Withdrawals(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range movements {
+		currency := toGlobalCurrency(m.Asset)
+		if len(asset) > 0 && currency != asset {
+			continue
+		}
+
+		allWithdraws = append(allWithdraws, types.Withdraw{
+			ID:             m.RefID,
+			ApplyTime:      m.Time.Time(),
+			Asset:          currency,
+			Amount:         m.Amount.Float64(),
+			Address:        m.Info,
+			TransactionID:  m.TxID,
+			TransactionFee: m.Fee.Float64(),
+			Status:         toGlobalWithdrawStatus(m.Status),
+		})
+	}
+
+	sort.Slice(allWithdraws, func(i, j int) bool {
+		return allWithdraws[i].ApplyTime.Before(allWithdraws[j].ApplyTime)
+	})
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
+	ticker, err := e.client.MarketService.Ticker(ctx, e.localSymbol(ctx, symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	return (ticker.Bid.Float64() + ticker.Ask.Float64()) / 2, nil
+}
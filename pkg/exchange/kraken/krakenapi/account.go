@@ -0,0 +1,72 @@
+package krakenapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type AccountService struct {
+	client *RestClient
+}
+
+// Balance is the extended balance of the asset, the hold trade amount is locked by the open orders
+type Balance struct {
+	Balance   fixedpoint.Value `json:"balance"`
+	HoldTrade fixedpoint.Value `json:"hold_trade"`
+}
+
+// Balances returns the extended balances of the assets, the staked assets are suffixed, e.g., DOT.S
+func (s *AccountService) Balances(ctx context.Context) (map[string]Balance, error) {
+	var balances map[string]Balance
+	err := s.client.sendAuthenticatedRequest(ctx, "BalanceEx", nil, &balances)
+	return balances, err
+}
+
+// Movement is the deposit or the withdrawal of the funding endpoints
+type Movement struct {
+	Method     string           `json:"method"`
+	AssetClass string           `json:"aclass"`
+	Asset      string           `json:"asset"`
+	RefID      string           `json:"refid"`
+	TxID       string           `json:"txid"`
+	Info       string           `json:"info"`
+	Amount     fixedpoint.Value `json:"amount"`
+	Fee        fixedpoint.Value `json:"fee"`
+	Time       Timestamp        `json:"time"`
+
+	// Status could be Initial, Pending, Settled, Success or Failure
+	Status string `json:"status"`
+}
+
+// Deposits returns the recent deposits in the time range
+func (s *AccountService) Deposits(ctx context.Context, startTime, endTime time.Time) ([]Movement, error) {
+	var movements []Movement
+	err := s.client.sendAuthenticatedRequest(ctx, "DepositStatus", timeRangeParams(startTime, endTime), &movements)
+	return movements, err
+}
+
+// Withdrawals returns the recent withdrawals in the time range
+func (s *AccountService) Withdrawals(ctx context.Context, startTime, endTime time.Time) ([]Movement, error) {
+	var movements []Movement
+	err := s.client.sendAuthenticatedRequest(ctx, "WithdrawStatus", timeRangeParams(startTime, endTime), &movements)
+	return movements, err
+}
+
+type WebSocketToken struct {
+	Token string `json:"token"`
+
+	// Expires is the seconds for establishing the connection, the token doesn't expire once the connection is established
+	Expires int `json:"expires"`
+}
+
+// WebSocketToken returns the token of the authenticated websocket channels
+func (s *AccountService) WebSocketToken(ctx context.Context) (*WebSocketToken, error) {
+	var token WebSocketToken
+	if err := s.client.sendAuthenticatedRequest(ctx, "GetWebSocketsToken", nil, &token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
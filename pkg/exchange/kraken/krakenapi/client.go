@@ -0,0 +1,330 @@
+package krakenapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProductionAPIURL is the official Kraken REST API endpoint, the public and the private endpoints are under /0/public and /0/private
+	ProductionAPIURL = "https://api.kraken.com"
+
+	UserAgent = "bbgo/1.0"
+
+	defaultHTTPTimeout = time.Second * 15
+)
+
+var log = logrus.WithField("exchange", "kraken")
+
+// errRateLimitExceeded is the error returned when the call rate counter exceeds the limit
+const errRateLimitExceeded = "EAPI:Rate limit exceeded"
+
+// privateCallCosts is the counter cost of the private endpoints, the cost is 1 for the endpoints not listed here.
+// The order placement and the cancellation are limited by the matching engine instead of the call rate counter.
+var privateCallCosts = map[string]float64{
+	"Ledgers":       2,
+	"QueryLedgers":  2,
+	"TradesHistory": 2,
+	"QueryTrades":   2,
+	"AddOrder":      0,
+	"CancelOrder":   0,
+	"CancelAll":     0,
+	"EditOrder":     0,
+}
+
+type RestClient struct {
+	client *http.Client
+
+	BaseURL *url.URL
+
+	// Authentication
+	Key    string
+	Secret string
+
+	// RateCounter limits the call rate of the private endpoints, it's shared by all the private calls of the client
+	RateCounter *RateCounter
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+
+	AccountService *AccountService
+	MarketService  *MarketService
+	TradeService   *TradeService
+}
+
+func NewRestClient(baseURL string) *RestClient {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	client := &RestClient{
+		client:      &http.Client{Timeout: defaultHTTPTimeout},
+		BaseURL:     u,
+		RateCounter: NewRateCounter(StarterTierMaxCounter, StarterTierDecayRate),
+	}
+
+	client.AccountService = &AccountService{client}
+	client.MarketService = &MarketService{client}
+	client.TradeService = &TradeService{client}
+	return client
+}
+
+// Auth sets the api key and the base64 encoded secret for the private endpoints.
+func (c *RestClient) Auth(key, secret string) *RestClient {
+	c.Key = key
+	c.Secret = secret
+	return c
+}
+
+// ErrorResponse is returned when the error list of the response is not empty, e.g., ["EOrder:Insufficient funds"]
+type ErrorResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Errors     []string
+}
+
+func (r *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", r.Method, r.URL, r.StatusCode, strings.Join(r.Errors, ", "))
+}
+
+// IsRateLimitExceeded returns true if the call was rejected by the call rate counter
+func (r *ErrorResponse) IsRateLimitExceeded() bool {
+	for _, e := range r.Errors {
+		if e == errRateLimitExceeded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// response is the envelope of all the responses
+type response struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+// nonce returns the increasing nonce in microseconds, the requests with the non-increasing nonce are rejected
+func (c *RestClient) nonce() string {
+	c.nonceMu.Lock()
+	defer c.nonceMu.Unlock()
+
+	n := time.Now().UnixNano() / int64(time.Microsecond)
+	if n <= c.lastNonce {
+		n = c.lastNonce + 1
+	}
+
+	c.lastNonce = n
+	return strconv.FormatInt(n, 10)
+}
+
+func (c *RestClient) newRequest(ctx context.Context, method, refURL string, params url.Values, body []byte) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	u := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", UserAgent)
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// newAuthenticatedRequest creates the POST request of the private endpoint, e.g., AddOrder,
+// the form values are signed with base64(hmac_sha512(path + sha256(nonce + form), base64decode(secret))).
+func (c *RestClient) newAuthenticatedRequest(ctx context.Context, endpoint string, params url.Values) (*http.Request, error) {
+	if len(c.Key) == 0 || len(c.Secret) == 0 {
+		return nil, errors.New("empty api key or secret")
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+
+	nonce := c.nonce()
+	params.Set("nonce", nonce)
+
+	path := "/0/private/" + endpoint
+	form := params.Encode()
+	signature, err := Sign(c.Secret, path, nonce, form)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "POST", path, nil, []byte(form))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("API-Key", c.Key)
+	req.Header.Add("API-Sign", signature)
+	return req, nil
+}
+
+// Sign returns the signature of the private request
+func Sign(secret, path, nonce, form string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", errors.Wrap(err, "the api secret is not base64 encoded")
+	}
+
+	digest := sha256.Sum256([]byte(nonce + form))
+
+	var sig = hmac.New(sha512.New, key)
+	_, err = sig.Write(append([]byte(path), digest[:]...))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sig.Sum(nil)), nil
+}
+
+// sendRequest sends the request and decodes the result of the response into the given object
+func (c *RestClient) sendRequest(req *http.Request, data interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var r response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return &ErrorResponse{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Errors:     []string{string(body)},
+		}
+	}
+
+	if len(r.Error) > 0 || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &ErrorResponse{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Errors:     r.Error,
+		}
+	}
+
+	if data == nil || len(r.Result) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(r.Result, data); err != nil {
+		return errors.Wrapf(err, "failed to decode the response: %s", body)
+	}
+
+	return nil
+}
+
+// get sends the request of the public endpoint, e.g., AssetPairs
+func (c *RestClient) get(ctx context.Context, endpoint string, params url.Values, data interface{}) error {
+	req, err := c.newRequest(ctx, "GET", "/0/public/"+endpoint, params, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+// sendAuthenticatedRequest waits for the call rate counter and sends the request of the private endpoint,
+// the counter is filled up when the server rejects the call, so that the following calls are delayed until the counter decays.
+func (c *RestClient) sendAuthenticatedRequest(ctx context.Context, endpoint string, params url.Values, data interface{}) error {
+	cost, ok := privateCallCosts[endpoint]
+	if !ok {
+		cost = 1
+	}
+
+	if err := c.RateCounter.Wait(ctx, cost); err != nil {
+		return err
+	}
+
+	req, err := c.newAuthenticatedRequest(ctx, endpoint, params)
+	if err != nil {
+		return err
+	}
+
+	err = c.sendRequest(req, data)
+	if errResponse, ok := err.(*ErrorResponse); ok && errResponse.IsRateLimitExceeded() {
+		log.Warnf("%s call rate limit exceeded", endpoint)
+		c.RateCounter.Fill()
+	}
+
+	return err
+}
+
+// Timestamp is the unix timestamp in seconds with the fractional part, it's encoded as either a number or a string
+type Timestamp time.Time
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" || len(s) == 0 {
+		*t = Timestamp{}
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+
+	if f == 0 {
+		*t = Timestamp{}
+		return nil
+	}
+
+	*t = Timestamp(time.Unix(0, int64(f*float64(time.Second))))
+	return nil
+}
+
+func (t Timestamp) Time() time.Time {
+	return time.Time(t)
+}
+
+// timeRangeParams returns the start and the end parameters in seconds, the zero time is not sent
+func timeRangeParams(startTime, endTime time.Time) url.Values {
+	params := url.Values{}
+	if !startTime.IsZero() {
+		params.Set("start", strconv.FormatInt(startTime.Unix(), 10))
+	}
+
+	if !endTime.IsZero() {
+		params.Set("end", strconv.FormatInt(endTime.Unix(), 10))
+	}
+
+	return params
+}
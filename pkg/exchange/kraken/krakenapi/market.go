@@ -0,0 +1,166 @@
+package krakenapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarketService struct {
+	client *RestClient
+}
+
+// AssetPair is the trading pair, the pair name (e.g., XXBTZUSD) is used in the responses,
+// and both the pair name and the alternative name (e.g., XBTUSD) are accepted by the requests.
+type AssetPair struct {
+	Name       string `json:"-"`
+	AltName    string `json:"altname"`
+	WSName     string `json:"wsname"`
+	Base       string `json:"base"`
+	Quote      string `json:"quote"`
+	AssetClass string `json:"aclass_base"`
+
+	// PairDecimals is the max number of the decimals of the price
+	PairDecimals int `json:"pair_decimals"`
+
+	// LotDecimals is the max number of the decimals of the volume
+	LotDecimals int `json:"lot_decimals"`
+
+	// CostDecimals is the max number of the decimals of the cost (price * volume)
+	CostDecimals int `json:"cost_decimals"`
+
+	OrderMin fixedpoint.Value `json:"ordermin"`
+	CostMin  fixedpoint.Value `json:"costmin"`
+	TickSize fixedpoint.Value `json:"tick_size"`
+
+	// Status could be online, cancel_only, post_only, limit_only or reduce_only
+	Status string `json:"status"`
+}
+
+func (s *MarketService) AssetPairs(ctx context.Context) ([]AssetPair, error) {
+	var resp map[string]AssetPair
+	if err := s.client.get(ctx, "AssetPairs", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var pairs []AssetPair
+	for name, pair := range resp {
+		pair.Name = name
+		pairs = append(pairs, pair)
+	}
+
+	return pairs, nil
+}
+
+type Ticker struct {
+	Pair string
+	Ask  fixedpoint.Value
+	Bid  fixedpoint.Value
+	Last fixedpoint.Value
+}
+
+// tickerInfo is the ticker of the response, the fields are arrays: a = [price, whole lot volume, lot volume], c = [price, lot volume]
+type tickerInfo struct {
+	Ask  []fixedpoint.Value `json:"a"`
+	Bid  []fixedpoint.Value `json:"b"`
+	Last []fixedpoint.Value `json:"c"`
+}
+
+func (s *MarketService) Ticker(ctx context.Context, pair string) (*Ticker, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+
+	var resp map[string]tickerInfo
+	if err := s.client.get(ctx, "Ticker", params, &resp); err != nil {
+		return nil, err
+	}
+
+	for name, info := range resp {
+		if len(info.Ask) == 0 || len(info.Bid) == 0 || len(info.Last) == 0 {
+			return nil, fmt.Errorf("invalid ticker of %s", name)
+		}
+
+		return &Ticker{
+			Pair: name,
+			Ask:  info.Ask[0],
+			Bid:  info.Bid[0],
+			Last: info.Last[0],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("ticker of %s not found", pair)
+}
+
+// Candle is the OHLC entry: [time, open, high, low, close, vwap, volume, count]
+type Candle struct {
+	Time   time.Time
+	Open   fixedpoint.Value
+	High   fixedpoint.Value
+	Low    fixedpoint.Value
+	Close  fixedpoint.Value
+	VWAP   fixedpoint.Value
+	Volume fixedpoint.Value
+	Count  int64
+}
+
+func (c *Candle) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	if len(fields) < 8 {
+		return fmt.Errorf("invalid candle: %s", data)
+	}
+
+	var seconds int64
+	if err := json.Unmarshal(fields[0], &seconds); err != nil {
+		return err
+	}
+
+	c.Time = time.Unix(seconds, 0)
+	for i, v := range []*fixedpoint.Value{&c.Open, &c.High, &c.Low, &c.Close, &c.VWAP, &c.Volume} {
+		if err := json.Unmarshal(fields[i+1], v); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(fields[7], &c.Count)
+}
+
+// Candles returns the candles since the given time in the ascending order, the last candle is not closed yet.
+// Only the recent 720 candles of the interval are returned no matter what the since time is.
+func (s *MarketService) Candles(ctx context.Context, pair string, intervalMinutes int, since time.Time) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("pair", pair)
+	params.Set("interval", strconv.Itoa(intervalMinutes))
+	if !since.IsZero() {
+		params.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+
+	// the result is {"<pair name>": [candles...], "last": <time>}
+	var resp map[string]json.RawMessage
+	if err := s.client.get(ctx, "OHLC", params, &resp); err != nil {
+		return nil, err
+	}
+
+	for name, data := range resp {
+		if name == "last" {
+			continue
+		}
+
+		var candles []Candle
+		if err := json.Unmarshal(data, &candles); err != nil {
+			return nil, err
+		}
+
+		return candles, nil
+	}
+
+	return nil, nil
+}
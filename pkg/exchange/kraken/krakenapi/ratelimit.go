@@ -0,0 +1,88 @@
+package krakenapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The call rate counter limits of the verification tiers, see https://docs.kraken.com/rest/#section/Rate-Limits
+const (
+	StarterTierMaxCounter = 15
+	StarterTierDecayRate  = 0.33
+
+	IntermediateTierMaxCounter = 20
+	IntermediateTierDecayRate  = 0.5
+
+	ProTierMaxCounter = 20
+	ProTierDecayRate  = 1.0
+)
+
+// RateCounter is the call rate counter of the private endpoints,
+// each call increases the counter by its cost and the counter decreases by the decay rate per second,
+// the calls are rejected by the server once the counter exceeds the max counter of the tier.
+type RateCounter struct {
+	mu sync.Mutex
+
+	max   float64
+	decay float64
+
+	counter   float64
+	updatedAt time.Time
+}
+
+func NewRateCounter(max, decay float64) *RateCounter {
+	return &RateCounter{
+		max:   max,
+		decay: decay,
+	}
+}
+
+// Wait blocks until the call of the cost can be made without exceeding the max counter, or the context is done
+func (c *RateCounter) Wait(ctx context.Context, cost float64) error {
+	for {
+		delay := c.reserve(cost)
+		if delay == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Fill sets the counter to the max counter, it's used to sync with the server when a call is rejected
+func (c *RateCounter) Fill() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counter = c.max
+	c.updatedAt = time.Now()
+}
+
+// reserve increases the counter by the cost and returns zero if the counter is below the max counter after the call,
+// otherwise the counter is not changed and the time to wait is returned.
+func (c *RateCounter) reserve(cost float64) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if !c.updatedAt.IsZero() {
+		c.counter -= now.Sub(c.updatedAt).Seconds() * c.decay
+		if c.counter < 0 {
+			c.counter = 0
+		}
+	}
+	c.updatedAt = now
+
+	if c.counter+cost <= c.max {
+		c.counter += cost
+		return 0
+	}
+
+	return time.Duration((c.counter + cost - c.max) / c.decay * float64(time.Second))
+}
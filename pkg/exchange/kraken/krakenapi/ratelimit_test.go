@@ -0,0 +1,36 @@
+package krakenapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateCounter_Wait(t *testing.T) {
+	counter := NewRateCounter(2, 100)
+	ctx := context.Background()
+
+	start := time.Now()
+	assert.NoError(t, counter.Wait(ctx, 1))
+	assert.NoError(t, counter.Wait(ctx, 1))
+	assert.True(t, time.Since(start) < 5*time.Millisecond)
+
+	// the counter is full, the call waits for the counter to decay by 1, which takes 10ms
+	assert.NoError(t, counter.Wait(ctx, 1))
+	assert.True(t, time.Since(start) >= 5*time.Millisecond)
+
+	// the zero cost calls are never delayed
+	assert.NoError(t, counter.Wait(ctx, 0))
+}
+
+func TestRateCounter_Fill(t *testing.T) {
+	counter := NewRateCounter(15, 0.33)
+	counter.Fill()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, counter.Wait(ctx, 1))
+}
@@ -0,0 +1,245 @@
+package krakenapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// historyPageSize is the number of the records returned by one page of the history endpoints
+const historyPageSize = 50
+
+type TradeService struct {
+	client *RestClient
+}
+
+type OrderType string
+
+const (
+	OrderTypeMarket        = OrderType("market")
+	OrderTypeLimit         = OrderType("limit")
+	OrderTypeStopLoss      = OrderType("stop-loss")
+	OrderTypeStopLossLimit = OrderType("stop-loss-limit")
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending  = OrderStatus("pending")
+	OrderStatusOpen     = OrderStatus("open")
+	OrderStatusClosed   = OrderStatus("closed")
+	OrderStatusCanceled = OrderStatus("canceled")
+	OrderStatusExpired  = OrderStatus("expired")
+)
+
+// OrderDescription describes the order, the price is the trigger price of the stop orders and the price2 is the limit price
+type OrderDescription struct {
+	Pair      string           `json:"pair"`
+	Type      string           `json:"type"`
+	OrderType OrderType        `json:"ordertype"`
+	Price     fixedpoint.Value `json:"price"`
+	Price2    fixedpoint.Value `json:"price2"`
+	Order     string           `json:"order"`
+}
+
+type Order struct {
+	// ID is the transaction ID of the order, e.g., OQCLML-BW3P3-BUCMWZ
+	ID string `json:"-"`
+
+	ClientOrderID string           `json:"cl_ord_id"`
+	UserRef       int64            `json:"userref"`
+	Status        OrderStatus      `json:"status"`
+	OpenTime      Timestamp        `json:"opentm"`
+	CloseTime     Timestamp        `json:"closetm"`
+	Description   OrderDescription `json:"descr"`
+	Volume        fixedpoint.Value `json:"vol"`
+	VolumeExec    fixedpoint.Value `json:"vol_exec"`
+	Cost          fixedpoint.Value `json:"cost"`
+	Fee           fixedpoint.Value `json:"fee"`
+
+	// AveragePrice is the average price of the executed volume
+	AveragePrice fixedpoint.Value `json:"price"`
+	StopPrice    fixedpoint.Value `json:"stopprice"`
+	LimitPrice   fixedpoint.Value `json:"limitprice"`
+	Reason       string           `json:"reason"`
+}
+
+// ordersOf converts the orders keyed by the transaction ID to the order list
+func ordersOf(m map[string]Order) []Order {
+	var orders []Order
+	for id, o := range m {
+		o.ID = id
+		orders = append(orders, o)
+	}
+
+	return orders
+}
+
+type AddOrderRequest struct {
+	Pair      string
+	Side      string
+	OrderType OrderType
+
+	// Price is the limit price of the limit order and the trigger price of the stop orders
+	Price string
+
+	// Price2 is the limit price of the stop loss limit order
+	Price2 string
+
+	Volume        string
+	ClientOrderID string
+
+	// TimeInForce could be GTC, IOC or GTD
+	TimeInForce string
+}
+
+func (r AddOrderRequest) params() url.Values {
+	params := url.Values{}
+	params.Set("pair", r.Pair)
+	params.Set("type", r.Side)
+	params.Set("ordertype", string(r.OrderType))
+	params.Set("volume", r.Volume)
+
+	if len(r.Price) > 0 {
+		params.Set("price", r.Price)
+	}
+
+	if len(r.Price2) > 0 {
+		params.Set("price2", r.Price2)
+	}
+
+	if len(r.ClientOrderID) > 0 {
+		params.Set("cl_ord_id", r.ClientOrderID)
+	}
+
+	if len(r.TimeInForce) > 0 {
+		params.Set("timeinforce", r.TimeInForce)
+	}
+
+	return params
+}
+
+// AddOrder submits the order and returns the transaction ID of the order
+func (s *TradeService) AddOrder(ctx context.Context, req AddOrderRequest) (string, error) {
+	var resp struct {
+		TxID []string `json:"txid"`
+	}
+
+	if err := s.client.sendAuthenticatedRequest(ctx, "AddOrder", req.params(), &resp); err != nil {
+		return "", err
+	}
+
+	if len(resp.TxID) == 0 {
+		return "", nil
+	}
+
+	return resp.TxID[0], nil
+}
+
+func (s *TradeService) CancelOrder(ctx context.Context, txID string) error {
+	params := url.Values{}
+	params.Set("txid", txID)
+	return s.client.sendAuthenticatedRequest(ctx, "CancelOrder", params, nil)
+}
+
+func (s *TradeService) CancelOrderByClientOrderID(ctx context.Context, clientOrderID string) error {
+	params := url.Values{}
+	params.Set("cl_ord_id", clientOrderID)
+	return s.client.sendAuthenticatedRequest(ctx, "CancelOrder", params, nil)
+}
+
+// QueryOrders returns the orders of the transaction IDs, at most 50 orders can be queried at a time
+func (s *TradeService) QueryOrders(ctx context.Context, txIDs ...string) ([]Order, error) {
+	params := url.Values{}
+	params.Set("txid", strings.Join(txIDs, ","))
+
+	var resp map[string]Order
+	if err := s.client.sendAuthenticatedRequest(ctx, "QueryOrders", params, &resp); err != nil {
+		return nil, err
+	}
+
+	return ordersOf(resp), nil
+}
+
+// OpenOrders returns the open orders of all the pairs
+func (s *TradeService) OpenOrders(ctx context.Context) ([]Order, error) {
+	var resp struct {
+		Open map[string]Order `json:"open"`
+	}
+
+	if err := s.client.sendAuthenticatedRequest(ctx, "OpenOrders", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return ordersOf(resp.Open), nil
+}
+
+// ClosedOrders returns the closed orders of all the pairs in the time range, the pages are queried until all the orders are returned
+func (s *TradeService) ClosedOrders(ctx context.Context, startTime, endTime time.Time) ([]Order, error) {
+	var orders []Order
+	for offset := 0; ; offset += historyPageSize {
+		params := timeRangeParams(startTime, endTime)
+		params.Set("ofs", strconv.Itoa(offset))
+
+		var resp struct {
+			Closed map[string]Order `json:"closed"`
+			Count  int              `json:"count"`
+		}
+
+		if err := s.client.sendAuthenticatedRequest(ctx, "ClosedOrders", params, &resp); err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, ordersOf(resp.Closed)...)
+		if len(resp.Closed) == 0 || offset+historyPageSize >= resp.Count {
+			return orders, nil
+		}
+	}
+}
+
+type Trade struct {
+	// ID is the transaction ID of the trade
+	ID string `json:"-"`
+
+	OrderID   string           `json:"ordertxid"`
+	Pair      string           `json:"pair"`
+	Time      Timestamp        `json:"time"`
+	Type      string           `json:"type"`
+	OrderType OrderType        `json:"ordertype"`
+	Price     fixedpoint.Value `json:"price"`
+	Cost      fixedpoint.Value `json:"cost"`
+	Fee       fixedpoint.Value `json:"fee"`
+	Volume    fixedpoint.Value `json:"vol"`
+	Maker     bool             `json:"maker"`
+}
+
+// TradesHistory returns the trades of all the pairs in the time range, the pages are queried until all the trades are returned
+func (s *TradeService) TradesHistory(ctx context.Context, startTime, endTime time.Time) ([]Trade, error) {
+	var trades []Trade
+	for offset := 0; ; offset += historyPageSize {
+		params := timeRangeParams(startTime, endTime)
+		params.Set("ofs", strconv.Itoa(offset))
+
+		var resp struct {
+			Trades map[string]Trade `json:"trades"`
+			Count  int              `json:"count"`
+		}
+
+		if err := s.client.sendAuthenticatedRequest(ctx, "TradesHistory", params, &resp); err != nil {
+			return trades, err
+		}
+
+		for id, t := range resp.Trades {
+			t.ID = id
+			trades = append(trades, t)
+		}
+
+		if len(resp.Trades) == 0 || offset+historyPageSize >= resp.Count {
+			return trades, nil
+		}
+	}
+}
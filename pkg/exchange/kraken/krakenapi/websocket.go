@@ -0,0 +1,151 @@
+package krakenapi
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+const (
+	PublicWebSocketURL = "wss://ws.kraken.com/v2"
+
+	// PrivateWebSocketURL is the endpoint of the authenticated channels, the public channels are also available on it
+	PrivateWebSocketURL = "wss://ws-auth.kraken.com/v2"
+)
+
+const (
+	ChannelOHLC       = "ohlc"
+	ChannelExecutions = "executions"
+	ChannelBalances   = "balances"
+	ChannelHeartbeat  = "heartbeat"
+	ChannelStatus     = "status"
+)
+
+const (
+	MessageTypeSnapshot = "snapshot"
+	MessageTypeUpdate   = "update"
+)
+
+type SubscribeParams struct {
+	Channel string `json:"channel"`
+
+	// Symbol is the list of the normalized symbols, e.g., BTC/USD
+	Symbol   []string `json:"symbol,omitempty"`
+	Interval int      `json:"interval,omitempty"`
+	Token    string   `json:"token,omitempty"`
+
+	SnapOrders *bool `json:"snap_orders,omitempty"`
+	SnapTrades *bool `json:"snap_trades,omitempty"`
+}
+
+type WebSocketCommand struct {
+	Method string           `json:"method"`
+	Params *SubscribeParams `json:"params,omitempty"`
+}
+
+func NewOHLCSubscribeCommand(symbols []string, intervalMinutes int) WebSocketCommand {
+	return WebSocketCommand{
+		Method: "subscribe",
+		Params: &SubscribeParams{
+			Channel:  ChannelOHLC,
+			Symbol:   symbols,
+			Interval: intervalMinutes,
+		},
+	}
+}
+
+// NewExecutionsSubscribeCommand subscribes the order and the trade updates, the snapshot of the open orders is sent on subscribed
+func NewExecutionsSubscribeCommand(token string) WebSocketCommand {
+	snapOrders, snapTrades := true, false
+	return WebSocketCommand{
+		Method: "subscribe",
+		Params: &SubscribeParams{
+			Channel:    ChannelExecutions,
+			Token:      token,
+			SnapOrders: &snapOrders,
+			SnapTrades: &snapTrades,
+		},
+	}
+}
+
+func NewBalancesSubscribeCommand(token string) WebSocketCommand {
+	return WebSocketCommand{
+		Method: "subscribe",
+		Params: &SubscribeParams{
+			Channel: ChannelBalances,
+			Token:   token,
+		},
+	}
+}
+
+func NewPingCommand() WebSocketCommand {
+	return WebSocketCommand{Method: "ping"}
+}
+
+// WebSocketMessage is either the channel message (channel is set) or the method response (method is set)
+type WebSocketMessage struct {
+	Channel string          `json:"channel"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+
+	Method  string `json:"method"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+func ParseMessage(payload []byte) (*WebSocketMessage, error) {
+	var m WebSocketMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+type OHLC struct {
+	Symbol        string           `json:"symbol"`
+	Open          fixedpoint.Value `json:"open"`
+	High          fixedpoint.Value `json:"high"`
+	Low           fixedpoint.Value `json:"low"`
+	Close         fixedpoint.Value `json:"close"`
+	VWAP          fixedpoint.Value `json:"vwap"`
+	Volume        fixedpoint.Value `json:"volume"`
+	Trades        int64            `json:"trades"`
+	IntervalBegin time.Time        `json:"interval_begin"`
+	Interval      int              `json:"interval"`
+	Timestamp     time.Time        `json:"timestamp"`
+}
+
+type ExecutionFee struct {
+	Asset    string           `json:"asset"`
+	Quantity fixedpoint.Value `json:"qty"`
+}
+
+// Execution is the order status change or the trade of the order, the trade fields are set when the exec type is trade
+type Execution struct {
+	ExecType      string           `json:"exec_type"`
+	OrderID       string           `json:"order_id"`
+	ClientOrderID string           `json:"cl_ord_id"`
+	Symbol        string           `json:"symbol"`
+	Side          string           `json:"side"`
+	OrderType     string           `json:"order_type"`
+	OrderQty      fixedpoint.Value `json:"order_qty"`
+	CumQty        fixedpoint.Value `json:"cum_qty"`
+	LimitPrice    fixedpoint.Value `json:"limit_price"`
+	AvgPrice      fixedpoint.Value `json:"avg_price"`
+	OrderStatus   string           `json:"order_status"`
+	TimeInForce   string           `json:"time_in_force"`
+	Timestamp     time.Time        `json:"timestamp"`
+
+	Triggers *struct {
+		Price fixedpoint.Value `json:"price"`
+	} `json:"triggers,omitempty"`
+
+	ExecID       string           `json:"exec_id"`
+	LastQty      fixedpoint.Value `json:"last_qty"`
+	LastPrice    fixedpoint.Value `json:"last_price"`
+	Cost         fixedpoint.Value `json:"cost"`
+	LiquidityInd string           `json:"liquidity_ind"`
+	Fees         []ExecutionFee   `json:"fees"`
+}
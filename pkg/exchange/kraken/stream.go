@@ -0,0 +1,334 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/kraken/krakenapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	Conn     *websocket.Conn
+	connLock sync.Mutex
+
+	publicOnly bool
+
+	// candles is the last candle of each symbol and interval,
+	// kraken doesn't push the closed candle, so the last candle is closed when the candle of the next period is pushed.
+	candles map[string]types.KLine
+}
+
+func NewStream(exchange *Exchange) *Stream {
+	return &Stream{
+		exchange: exchange,
+		candles:  make(map[string]types.KLine),
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	go s.read(ctx)
+	return nil
+}
+
+func (s *Stream) connect(ctx context.Context) error {
+	url := krakenapi.PrivateWebSocketURL
+
+	var token string
+	if s.publicOnly {
+		log.Infof("stream is set to public only mode")
+		url = krakenapi.PublicWebSocketURL
+	} else {
+		// the token is only used for establishing the subscriptions, a new token is requested on every connect
+		t, err := s.exchange.client.AccountService.WebSocketToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		token = t.Token
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected")
+
+	s.connLock.Lock()
+	s.Conn = conn
+	s.connLock.Unlock()
+
+	if err := s.subscribe(ctx, token); err != nil {
+		return err
+	}
+
+	s.EmitConnect()
+	return nil
+}
+
+func (s *Stream) writeCommand(command krakenapi.WebSocketCommand) error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.Conn.WriteJSON(command)
+}
+
+// subscribe sends the kline subscriptions grouped by the interval, and the execution and the balance subscriptions for the user data
+func (s *Stream) subscribe(ctx context.Context, token string) error {
+	pairs := s.exchange.loadPairs(ctx)
+
+	var symbols = map[int][]string{}
+	for _, subscription := range s.Subscriptions {
+		if subscription.Channel != types.KLineChannel {
+			return fmt.Errorf("kraken stream does not support the channel %s", subscription.Channel)
+		}
+
+		minutes, err := toLocalInterval(types.Interval(subscription.Options.Interval))
+		if err != nil {
+			return err
+		}
+
+		symbols[minutes] = append(symbols[minutes], pairs.WebSocketSymbol(subscription.Symbol))
+	}
+
+	for minutes, intervalSymbols := range symbols {
+		if err := s.writeCommand(krakenapi.NewOHLCSubscribeCommand(intervalSymbols, minutes)); err != nil {
+			return err
+		}
+	}
+
+	if len(token) == 0 {
+		return nil
+	}
+
+	if err := s.writeCommand(krakenapi.NewExecutionsSubscribeCommand(token)); err != nil {
+		return err
+	}
+
+	return s.writeCommand(krakenapi.NewBalancesSubscribeCommand(token))
+}
+
+func (s *Stream) read(ctx context.Context) {
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		default:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.SetReadDeadline(time.Now().Add(1 * time.Minute)); err != nil {
+				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+			}
+
+			mt, message, err := conn.ReadMessage()
+			receivedTime := time.Now()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+					log.WithError(err).Errorf("read error: %s", err.Error())
+				} else {
+					log.Info("websocket connection closed, going away")
+				}
+
+				s.EmitDisconnect()
+
+				// reconnect with the exponential backoff
+				backoff := types.NewReconnectBackoff()
+				for err != nil {
+					select {
+					case <-ctx.Done():
+						return
+
+					default:
+						err = s.connect(ctx)
+						if err != nil {
+							delay := backoff.Next()
+							log.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+							time.Sleep(delay)
+						}
+					}
+				}
+
+				continue
+			}
+
+			s.SetLastMessageTime(receivedTime)
+
+			// skip non-text messages
+			if mt != websocket.TextMessage {
+				continue
+			}
+
+			log.Debug(string(message))
+
+			m, err := krakenapi.ParseMessage(message)
+			if err != nil {
+				log.WithError(err).Errorf("[kraken] message parse error: %s", message)
+				continue
+			}
+
+			if len(m.Method) > 0 {
+				if !m.Success {
+					log.Errorf("[kraken] websocket %s error: %s", m.Method, m.Error)
+				}
+
+				continue
+			}
+
+			if eventTime, ok := s.dispatchMessage(ctx, m); ok {
+				s.Latency().Record(m.Channel, eventTime, receivedTime, time.Now())
+			}
+		}
+	}
+}
+
+// dispatchMessage emits the channel message and returns the event time
+func (s *Stream) dispatchMessage(ctx context.Context, m *krakenapi.WebSocketMessage) (time.Time, bool) {
+	switch m.Channel {
+
+	case krakenapi.ChannelOHLC:
+		var candles []krakenapi.OHLC
+		if err := json.Unmarshal(m.Data, &candles); err != nil {
+			log.WithError(err).Error("ohlc parse error")
+			return time.Time{}, false
+		}
+
+		var eventTime time.Time
+		for _, candle := range candles {
+			s.handleCandle(m.Type == krakenapi.MessageTypeSnapshot, candle)
+			eventTime = candle.Timestamp
+		}
+
+		return eventTime, m.Type == krakenapi.MessageTypeUpdate && len(candles) > 0
+
+	case krakenapi.ChannelExecutions:
+		// the snapshot carries the open orders, which are queried from the rest api on startup
+		if m.Type == krakenapi.MessageTypeSnapshot {
+			return time.Time{}, false
+		}
+
+		var executions []krakenapi.Execution
+		if err := json.Unmarshal(m.Data, &executions); err != nil {
+			log.WithError(err).Error("execution parse error")
+			return time.Time{}, false
+		}
+
+		var eventTime time.Time
+		for _, e := range executions {
+			s.handleExecution(ctx, e)
+			eventTime = e.Timestamp
+		}
+
+		return eventTime, len(executions) > 0
+
+	case krakenapi.ChannelBalances:
+		// the balance messages don't carry the amount locked by the orders, so the balances are queried from the rest api
+		balances, err := s.exchange.QueryAccountBalances(ctx)
+		if err != nil {
+			log.WithError(err).Error("balance query error")
+			return time.Time{}, false
+		}
+
+		if m.Type == krakenapi.MessageTypeSnapshot {
+			s.EmitBalanceSnapshot(balances)
+		} else {
+			s.EmitBalanceUpdate(balances)
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (s *Stream) handleCandle(snapshot bool, candle krakenapi.OHLC) {
+	kline := toGlobalOHLCKLine(candle)
+	key := kline.Symbol + ":" + string(kline.Interval)
+
+	// the snapshot carries the history candles, only the latest candle is kept
+	if snapshot {
+		if last, ok := s.candles[key]; !ok || kline.StartTime.After(last.StartTime) {
+			s.candles[key] = kline
+		}
+
+		return
+	}
+
+	if last, ok := s.candles[key]; ok && kline.StartTime.After(last.StartTime) {
+		last.Closed = true
+		s.EmitKLine(last)
+		s.EmitKLineClosed(last)
+	}
+
+	s.candles[key] = kline
+	s.EmitKLine(kline)
+}
+
+// handleExecution emits the trade of the trade execution and the order update,
+// the status executions only carry the changed fields, so the order is queried from the rest api in that case.
+func (s *Stream) handleExecution(ctx context.Context, e krakenapi.Execution) {
+	if e.ExecType == "trade" {
+		s.EmitTradeUpdate(toGlobalExecutionTrade(e))
+	}
+
+	if len(e.Symbol) > 0 && e.OrderQty > 0 {
+		s.EmitOrderUpdate(toGlobalExecutionOrder(e))
+		return
+	}
+
+	orders, err := s.exchange.client.TradeService.QueryOrders(ctx, e.OrderID)
+	if err != nil {
+		log.WithError(err).Errorf("order query error: %s", e.OrderID)
+		return
+	}
+
+	pairs := s.exchange.loadPairs(ctx)
+	for _, o := range orders {
+		s.EmitOrderUpdate(toGlobalOrder(o, pairs.Symbol(o.Description.Pair)))
+	}
+}
+
+func (s *Stream) Close() error {
+	log.Infof("closing kraken stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
+
+// Reconnect closes the current connection, the read loop reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	log.Infof("reconnecting kraken stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
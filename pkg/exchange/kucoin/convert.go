@@ -0,0 +1,285 @@
+package kucoin
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+func toGlobalCurrency(currency string) string {
+	return strings.ToUpper(currency)
+}
+
+func toLocalCurrency(currency string) string {
+	return strings.ToUpper(currency)
+}
+
+// toLocalSymbol converts the symbol to the dash separated format of KuCoin, e.g., BTCUSDT => BTC-USDT,
+// the market map is used for finding the base currency of the symbol.
+func toLocalSymbol(symbol string, markets types.MarketMap) string {
+	if market, ok := markets[symbol]; ok {
+		return market.BaseCurrency + "-" + market.QuoteCurrency
+	}
+
+	for _, quote := range []string{"USDT", "USDC", "BTC", "ETH", "KCS", "TUSD", "PAX", "DAI", "TRX"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return symbol[:len(symbol)-len(quote)] + "-" + quote
+		}
+	}
+
+	return symbol
+}
+
+func toGlobalSymbol(symbol string) string {
+	return strings.ToUpper(strings.Replace(symbol, "-", "", -1))
+}
+
+func toLocalSideType(side types.SideType) kucoinapi.SideType {
+	return kucoinapi.SideType(strings.ToLower(string(side)))
+}
+
+func toGlobalSideType(side kucoinapi.SideType) types.SideType {
+	switch side {
+	case kucoinapi.SideTypeBuy:
+		return types.SideTypeBuy
+
+	case kucoinapi.SideTypeSell:
+		return types.SideTypeSell
+	}
+
+	return types.SideType(side)
+}
+
+var localIntervals = map[types.Interval]string{
+	types.Interval1m:  "1min",
+	types.Interval5m:  "5min",
+	types.Interval15m: "15min",
+	types.Interval30m: "30min",
+	types.Interval1h:  "1hour",
+	types.Interval2h:  "2hour",
+	types.Interval4h:  "4hour",
+	types.Interval6h:  "6hour",
+	types.Interval12h: "12hour",
+	types.Interval1d:  "1day",
+}
+
+func toLocalInterval(interval types.Interval) (string, error) {
+	if s, ok := localIntervals[interval]; ok {
+		return s, nil
+	}
+
+	return "", fmt.Errorf("kucoin does not support the interval %s", interval)
+}
+
+func toGlobalInterval(interval string) types.Interval {
+	for i, s := range localIntervals {
+		if s == interval {
+			return i
+		}
+	}
+
+	return types.Interval(interval)
+}
+
+// hashID converts the hex string ID of KuCoin to the integer ID, the orders and the trades are linked by the hashed order ID.
+func hashID(id string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+
+	// keep it in the int64 range, so that it can be stored as the trade ID
+	return h.Sum64() & math.MaxInt64
+}
+
+func toGlobalMarket(s kucoinapi.Symbol) types.Market {
+	return types.Market{
+		Symbol:          toGlobalSymbol(s.Symbol),
+		PricePrecision:  util.StepPrecision(s.PriceIncrement.Float64()),
+		VolumePrecision: util.StepPrecision(s.BaseIncrement.Float64()),
+		QuoteCurrency:   toGlobalCurrency(s.QuoteCurrency),
+		BaseCurrency:    toGlobalCurrency(s.BaseCurrency),
+		MinNotional:     s.QuoteMinSize.Float64(),
+		MinAmount:       s.QuoteMinSize.Float64(),
+		MinLot:          s.BaseIncrement.Float64(),
+		StepSize:        s.BaseIncrement.Float64(),
+		MinQuantity:     s.BaseMinSize.Float64(),
+		MaxQuantity:     s.BaseMaxSize.Float64(),
+		MinPrice:        s.PriceIncrement.Float64(),
+		MaxPrice:        s.QuoteMaxSize.Float64(),
+		TickSize:        s.PriceIncrement.Float64(),
+	}
+}
+
+func toGlobalOrderType(orderType kucoinapi.OrderType, stop kucoinapi.StopType) types.OrderType {
+	switch orderType {
+	case kucoinapi.OrderTypeLimit:
+		if len(stop) > 0 {
+			return types.OrderTypeStopLimit
+		}
+
+		return types.OrderTypeLimit
+
+	case kucoinapi.OrderTypeMarket:
+		if len(stop) > 0 {
+			return types.OrderTypeStopMarket
+		}
+
+		return types.OrderTypeMarket
+
+	case kucoinapi.OrderTypeStopLimit:
+		return types.OrderTypeStopLimit
+
+	case kucoinapi.OrderTypeStopMarket:
+		return types.OrderTypeStopMarket
+	}
+
+	log.Errorf("unknown order type: %v", orderType)
+	return types.OrderType(orderType)
+}
+
+func toLocalOrderType(orderType types.OrderType) (kucoinapi.OrderType, error) {
+	switch orderType {
+	case types.OrderTypeLimit, types.OrderTypeStopLimit:
+		return kucoinapi.OrderTypeLimit, nil
+
+	case types.OrderTypeMarket, types.OrderTypeStopMarket:
+		return kucoinapi.OrderTypeMarket, nil
+	}
+
+	return "", fmt.Errorf("order type %s not supported", orderType)
+}
+
+// toLocalStopType returns the stop direction of the stop order, the buy stop is triggered when the price goes up,
+// and the sell stop is triggered when the price goes down.
+func toLocalStopType(side types.SideType) kucoinapi.StopType {
+	if side == types.SideTypeBuy {
+		return kucoinapi.StopTypeEntry
+	}
+
+	return kucoinapi.StopTypeLoss
+}
+
+func toGlobalOrderStatus(isActive, cancelExist bool, size, dealSize fixedpoint.Value) types.OrderStatus {
+	switch {
+	case isActive && dealSize > 0:
+		return types.OrderStatusPartiallyFilled
+
+	case isActive:
+		return types.OrderStatusNew
+
+	case cancelExist:
+		return types.OrderStatusCanceled
+
+	case dealSize >= size:
+		return types.OrderStatusFilled
+
+	case dealSize > 0:
+		return types.OrderStatusPartiallyFilled
+	}
+
+	return types.OrderStatusCanceled
+}
+
+func toGlobalOrder(o kucoinapi.Order) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        toGlobalSymbol(o.Symbol),
+			Side:          toGlobalSideType(o.Side),
+			Type:          toGlobalOrderType(o.Type, o.Stop),
+			Quantity:      o.Size.Float64(),
+			Price:         o.Price.Float64(),
+			StopPrice:     o.StopPrice.Float64(),
+			TimeInForce:   types.TimeInForce(o.TimeInForce),
+		},
+		Exchange:         types.ExchangeKucoin.String(),
+		OrderID:          hashID(o.ID),
+		Status:           toGlobalOrderStatus(o.IsActive, o.CancelExist, o.Size, o.DealSize),
+		ExecutedQuantity: o.DealSize.Float64(),
+		IsWorking:        o.IsActive,
+		CreationTime:     o.CreatedAt.Time(),
+		UpdateTime:       o.CreatedAt.Time(),
+	}
+}
+
+func toGlobalTrade(fill kucoinapi.Fill) types.Trade {
+	side := toGlobalSideType(fill.Side)
+	return types.Trade{
+		ID:            int64(hashID(fill.TradeID)),
+		OrderID:       hashID(fill.OrderID),
+		Exchange:      types.ExchangeKucoin.String(),
+		Price:         fill.Price.Float64(),
+		Quantity:      fill.Size.Float64(),
+		QuoteQuantity: fill.Funds.Float64(),
+		Symbol:        toGlobalSymbol(fill.Symbol),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       fill.Liquidity == "maker",
+		Time:          fill.CreatedAt.Time(),
+		Fee:           fill.Fee.Float64(),
+		FeeCurrency:   toGlobalCurrency(fill.FeeCurrency),
+	}
+}
+
+func toGlobalKLine(symbol string, interval types.Interval, candle kucoinapi.Candle) (types.KLine, error) {
+	if len(candle) < 7 {
+		return types.KLine{}, fmt.Errorf("invalid candle: %v", candle)
+	}
+
+	startTime, err := strconv.ParseInt(candle[0], 10, 64)
+	if err != nil {
+		return types.KLine{}, err
+	}
+
+	start := time.Unix(startTime, 0)
+	return types.KLine{
+		Exchange:    types.ExchangeKucoin.String(),
+		Symbol:      symbol,
+		StartTime:   start,
+		EndTime:     start.Add(interval.Duration() - time.Millisecond),
+		Interval:    interval,
+		Open:        util.MustParseFloat(candle[1]),
+		Close:       util.MustParseFloat(candle[2]),
+		High:        util.MustParseFloat(candle[3]),
+		Low:         util.MustParseFloat(candle[4]),
+		Volume:      util.MustParseFloat(candle[5]),
+		QuoteVolume: util.MustParseFloat(candle[6]),
+	}, nil
+}
+
+func toGlobalDepositStatus(status string) types.DepositStatus {
+	switch status {
+	case "SUCCESS":
+		return types.DepositSuccess
+
+	case "PROCESSING":
+		return types.DepositPending
+
+	case "FAILURE":
+		return types.DepositRejected
+	}
+
+	return types.DepositStatus(strings.ToLower(status))
+}
+
+func toGlobalWithdrawStatus(status string) string {
+	switch status {
+	case "SUCCESS":
+		return "completed" // make it compatible with binance
+
+	case "PROCESSING", "WALLET_PROCESSING":
+		return "processing"
+
+	case "FAILURE":
+		return "failed"
+	}
+
+	return strings.ToLower(status)
+}
@@ -0,0 +1,65 @@
+package kucoin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSymbolConversion(t *testing.T) {
+	markets := types.MarketMap{
+		"KCSBTC": {Symbol: "KCSBTC", BaseCurrency: "KCS", QuoteCurrency: "BTC"},
+	}
+
+	assert.Equal(t, "KCS-BTC", toLocalSymbol("KCSBTC", markets))
+	assert.Equal(t, "BTC-USDT", toLocalSymbol("BTCUSDT", nil))
+	assert.Equal(t, "ETH-BTC", toLocalSymbol("ETHBTC", nil))
+	assert.Equal(t, "BTCUSDT", toGlobalSymbol("BTC-USDT"))
+}
+
+func TestIntervalConversion(t *testing.T) {
+	s, err := toLocalInterval(types.Interval1h)
+	assert.NoError(t, err)
+	assert.Equal(t, "1hour", s)
+	assert.Equal(t, types.Interval1h, toGlobalInterval(s))
+
+	_, err = toLocalInterval(types.Interval3d)
+	assert.Error(t, err)
+}
+
+func TestHashID(t *testing.T) {
+	id := hashID("5c35c02703aa673ceec2a168")
+	assert.Equal(t, id, hashID("5c35c02703aa673ceec2a168"))
+	assert.NotEqual(t, id, hashID("5c35c02703aa673ceec2a169"))
+	assert.True(t, int64(id) >= 0)
+}
+
+func TestToGlobalKLine(t *testing.T) {
+	kline, err := toGlobalKLine("BTCUSDT", types.Interval1m, kucoinapi.Candle{
+		"1545904980", "0.058", "0.049", "0.058", "0.049", "0.018", "0.000945",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1545904980, 0), kline.StartTime)
+	assert.Equal(t, 0.058, kline.Open)
+	assert.Equal(t, 0.049, kline.Close)
+	assert.Equal(t, 0.058, kline.High)
+	assert.Equal(t, 0.049, kline.Low)
+	assert.Equal(t, 0.018, kline.Volume)
+	assert.Equal(t, 0.000945, kline.QuoteVolume)
+
+	_, err = toGlobalKLine("BTCUSDT", types.Interval1m, kucoinapi.Candle{"1545904980"})
+	assert.Error(t, err)
+}
+
+func TestToGlobalOrderStatus(t *testing.T) {
+	size := fixedpoint.NewFromFloat(1.0)
+	assert.Equal(t, types.OrderStatusNew, toGlobalOrderStatus(true, false, size, 0))
+	assert.Equal(t, types.OrderStatusPartiallyFilled, toGlobalOrderStatus(true, false, size, fixedpoint.NewFromFloat(0.5)))
+	assert.Equal(t, types.OrderStatusFilled, toGlobalOrderStatus(false, false, size, size))
+	assert.Equal(t, types.OrderStatusCanceled, toGlobalOrderStatus(false, true, size, fixedpoint.NewFromFloat(0.5)))
+}
@@ -0,0 +1,484 @@
+package kucoin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "kucoin")
+
+// queryWindow is the max time range of the kucoin history queries (orders, fills, deposits and withdrawals)
+const queryWindow = 7 * 24 * time.Hour
+
+// maxCandles is the max number of the candles returned by one candle request
+const maxCandles = 1500
+
+// orderIDMap maps the hashed order ID back to the kucoin order ID, so that the orders can be canceled by the global order ID
+type orderIDMap struct {
+	mu  sync.Mutex
+	ids map[uint64]string
+}
+
+func (m *orderIDMap) Add(id string) uint64 {
+	hashed := hashID(id)
+
+	m.mu.Lock()
+	m.ids[hashed] = id
+	m.mu.Unlock()
+	return hashed
+}
+
+func (m *orderIDMap) Get(hashed uint64) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := m.ids[hashed]
+	return id, ok
+}
+
+// Remove removes the order ID of the closed order
+func (m *orderIDMap) Remove(hashed uint64) {
+	m.mu.Lock()
+	delete(m.ids, hashed)
+	m.mu.Unlock()
+}
+
+type Exchange struct {
+	client                  *kucoinapi.RestClient
+	key, secret, passphrase string
+
+	orderIDs *orderIDMap
+
+	marketsMu sync.Mutex
+	markets   types.MarketMap
+}
+
+func New(key, secret, passphrase string) *Exchange {
+	baseURL := kucoinapi.ProductionAPIURL
+	if override := os.Getenv("KUCOIN_API_BASE_URL"); len(override) > 0 {
+		baseURL = override
+	}
+
+	client := kucoinapi.NewRestClient(baseURL)
+	client.Auth(key, secret, passphrase)
+	return &Exchange{
+		client:     client,
+		key:        key,
+		secret:     secret,
+		passphrase: passphrase,
+		orderIDs:   &orderIDMap{ids: make(map[uint64]string)},
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return types.ExchangeKucoin
+}
+
+func (e *Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(types.CapabilityUserDataStream, types.CapabilityTransfer, types.CapabilityStopOrder)
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return "KCS"
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	return NewStream(e)
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	log.Info("querying market info...")
+
+	symbols, err := e.client.MarketService.Symbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := types.MarketMap{}
+	for _, s := range symbols {
+		if !s.EnableTrading {
+			continue
+		}
+
+		market := toGlobalMarket(s)
+		markets[market.Symbol] = market
+	}
+
+	e.marketsMu.Lock()
+	e.markets = markets
+	e.marketsMu.Unlock()
+	return markets, nil
+}
+
+// localSymbol converts the global symbol with the queried markets, the markets are queried if they are not loaded yet
+func (e *Exchange) localSymbol(ctx context.Context, symbol string) string {
+	e.marketsMu.Lock()
+	markets := e.markets
+	e.marketsMu.Unlock()
+
+	if markets == nil {
+		var err error
+		markets, err = e.QueryMarkets(ctx)
+		if err != nil {
+			log.WithError(err).Error("market query error")
+		}
+	}
+
+	return toLocalSymbol(symbol, markets)
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	balances, err := e.QueryAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &types.Account{
+		MakerCommission: 10, // 0.1%
+		TakerCommission: 10, // 0.1%
+	}
+
+	a.UpdateBalances(balances)
+	return a, nil
+}
+
+// QueryAccountBalances returns the balances of the trade account, the main account can not be used for trading
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	accounts, err := e.client.AccountService.Accounts(ctx, kucoinapi.AccountTypeTrade)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = make(types.BalanceMap)
+	for _, a := range accounts {
+		currency := toGlobalCurrency(a.Currency)
+		balances[currency] = types.Balance{
+			Currency:  currency,
+			Available: a.Available,
+			Locked:    a.Holds,
+		}
+	}
+
+	return balances, nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	candleType, err := toLocalInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit = maxCandles
+	if options.Limit > 0 && options.Limit < limit {
+		limit = options.Limit
+	}
+
+	var endTime = time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	// the time range is cut by the limit, so that the klines are queried forward from the start time
+	var window = time.Duration(limit) * interval.Duration()
+	var startTime = endTime.Add(-window)
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+		if startTime.Add(window).Before(endTime) {
+			endTime = startTime.Add(window)
+		}
+	}
+
+	log.Infof("querying kline %s %s %+v", symbol, interval, options)
+
+	candles, err := e.client.MarketService.Candles(ctx, e.localSymbol(ctx, symbol), candleType, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var kLines []types.KLine
+	for _, candle := range candles {
+		kline, err := toGlobalKLine(symbol, interval, candle)
+		if err != nil {
+			return nil, err
+		}
+
+		kline.Closed = kline.EndTime.Before(time.Now())
+		kLines = append(kLines, kline)
+	}
+
+	// kucoin returns the latest candle first
+	sort.Slice(kLines, func(i, j int) bool {
+		return kLines[i].StartTime.Before(kLines[j].StartTime)
+	})
+
+	if len(kLines) > limit {
+		kLines = kLines[len(kLines)-limit:]
+	}
+
+	return kLines, nil
+}
+
+// QueryTrades queries the fills in the time range by the 7-day windows,
+// the last trade ID option is not supported since the trade ID of kucoin is not sequential.
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	var endTime = time.Now()
+	if options.EndTime != nil {
+		endTime = *options.EndTime
+	}
+
+	var startTime = endTime.Add(-queryWindow)
+	if options.StartTime != nil {
+		startTime = *options.StartTime
+	}
+
+	localSymbol := e.localSymbol(ctx, symbol)
+	err = forEachWindow(startTime, endTime, func(since, until time.Time) error {
+		fills, err := e.client.TradeService.ListFills(ctx, localSymbol, since, until)
+		if err != nil {
+			return err
+		}
+
+		for _, fill := range fills {
+			trades = append(trades, toGlobalTrade(fill))
+		}
+
+		return nil
+	})
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time.Before(trades[j].Time)
+	})
+
+	if options.Limit > 0 && int64(len(trades)) > options.Limit {
+		trades = trades[:options.Limit]
+	}
+
+	return trades, err
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, order := range orders {
+		orderType, err := toLocalOrderType(order.Type)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		req := kucoinapi.CreateOrderRequest{
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        e.localSymbol(ctx, order.Symbol),
+			Side:          toLocalSideType(order.Side),
+			Type:          orderType,
+			Size:          order.QuantityString,
+		}
+
+		if len(req.ClientOrderID) == 0 {
+			req.ClientOrderID = uuid.New().String()
+		}
+
+		switch order.Type {
+		case types.OrderTypeLimit, types.OrderTypeStopLimit:
+			req.Price = order.PriceString
+			req.TimeInForce = string(order.TimeInForce)
+		}
+
+		switch order.Type {
+		case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+			if len(order.StopPriceString) == 0 {
+				return createdOrders, fmt.Errorf("stop price string can not be empty")
+			}
+
+			req.Stop = toLocalStopType(order.Side)
+			req.StopPrice = order.StopPriceString
+		}
+
+		resp, err := e.client.TradeService.CreateOrder(ctx, req)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		if len(resp.OrderID) == 0 {
+			return createdOrders, errors.New("returned empty order id")
+		}
+
+		order.ClientOrderID = req.ClientOrderID
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder:  order,
+			Exchange:     types.ExchangeKucoin.String(),
+			OrderID:      e.orderIDs.Add(resp.OrderID),
+			Status:       types.OrderStatusNew,
+			IsWorking:    true,
+			CreationTime: time.Now(),
+			UpdateTime:   time.Now(),
+		})
+	}
+
+	return createdOrders, err
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	localOrders, err := e.client.TradeService.ListOrders(ctx, e.localSymbol(ctx, symbol), kucoinapi.OrderStatusActive, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range localOrders {
+		e.orderIDs.Add(o.ID)
+		orders = append(orders, toGlobalOrder(o))
+	}
+
+	return orders, nil
+}
+
+// QueryClosedOrders queries the done orders in the time range by the 7-day windows, lastOrderID is not supported on kucoin
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	localSymbol := e.localSymbol(ctx, symbol)
+	err = forEachWindow(since, until, func(since, until time.Time) error {
+		localOrders, err := e.client.TradeService.ListOrders(ctx, localSymbol, kucoinapi.OrderStatusDone, since, until)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range localOrders {
+			orders = append(orders, toGlobalOrder(o))
+		}
+
+		return nil
+	})
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].CreationTime.Before(orders[j].CreationTime)
+	})
+
+	return orders, err
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
+	for _, o := range orders {
+		var err error
+		if orderID, ok := e.orderIDs.Get(o.OrderID); ok {
+			_, err = e.client.TradeService.CancelOrder(ctx, orderID)
+		} else if len(o.ClientOrderID) > 0 {
+			_, err = e.client.TradeService.CancelOrderByClientOrderID(ctx, o.ClientOrderID)
+		} else {
+			return fmt.Errorf("order id or client order id is not defined, order=%+v", o)
+		}
+
+		if err != nil {
+			log.WithError(err).Errorf("order cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := e.client.TradeService.CancelAllOrders(ctx, e.localSymbol(ctx, symbol)); err != nil {
+		return nil, err
+	}
+
+	for _, o := range orders {
+		e.orderIDs.Remove(o.OrderID)
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	err = forEachWindow(since, until, func(since, until time.Time) error {
+		log.Infof("querying deposit history %s: %s <=> %s", asset, since, until)
+
+		deposits, err := e.client.AccountService.Deposits(ctx, toLocalCurrency(asset), since, until)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range deposits {
+			allDeposits = append(allDeposits, types.Deposit{
+				Time:          d.CreatedAt.Time(),
+				Amount:        d.Amount.Float64(),
+				Asset:         toGlobalCurrency(d.Currency),
+				Address:       d.Address,
+				AddressTag:    d.Memo,
+				TransactionID: d.WalletTxID,
+				Status:        toGlobalDepositStatus(d.Status),
+			})
+		}
+
+		return nil
+	})
+
+	return allDeposits, err
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	err = forEachWindow(since, until, func(since, until time.Time) error {
+		log.Infof("querying withdraw %s: %s <=> %s", asset, since, until)
+
+		withdrawals, err := e.client.AccountService.Withdrawals(ctx, toLocalCurrency(asset), since, until)
+		if err != nil {
+			return err
+		}
+
+		for _, w := range withdrawals {
+			allWithdraws = append(allWithdraws, types.Withdraw{
+				ID:             w.ID,
+				ApplyTime:      w.CreatedAt.Time(),
+				Asset:          toGlobalCurrency(w.Currency),
+				Amount:         w.Amount.Float64(),
+				Address:        w.Address,
+				AddressTag:     w.Memo,
+				TransactionID:  w.WalletTxID,
+				TransactionFee: w.Fee.Float64(),
+				Status:         toGlobalWithdrawStatus(w.Status),
+			})
+		}
+
+		return nil
+	})
+
+	return allWithdraws, err
+}
+
+func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
+	ticker, err := e.client.MarketService.Ticker(ctx, e.localSymbol(ctx, symbol))
+	if err != nil {
+		return 0, err
+	}
+
+	return (ticker.BestBid.Float64() + ticker.BestAsk.Float64()) / 2, nil
+}
+
+// forEachWindow splits the time range into the 7-day windows, since the kucoin history queries are limited to 7 days
+func forEachWindow(since, until time.Time, f func(since, until time.Time) error) error {
+	for startTime := since; startTime.Before(until); {
+		endTime := startTime.Add(queryWindow)
+		if endTime.After(until) {
+			endTime = until
+		}
+
+		if err := f(startTime, endTime); err != nil {
+			return err
+		}
+
+		startTime = endTime
+	}
+
+	return nil
+}
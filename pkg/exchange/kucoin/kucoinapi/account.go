@@ -0,0 +1,139 @@
+package kucoinapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type AccountType string
+
+const (
+	AccountTypeMain   AccountType = "main"
+	AccountTypeTrade  AccountType = "trade"
+	AccountTypeMargin AccountType = "margin"
+)
+
+type AccountService struct {
+	client *RestClient
+}
+
+type Account struct {
+	ID        string           `json:"id"`
+	Currency  string           `json:"currency"`
+	Type      AccountType      `json:"type"`
+	Balance   fixedpoint.Value `json:"balance"`
+	Available fixedpoint.Value `json:"available"`
+	Holds     fixedpoint.Value `json:"holds"`
+}
+
+// Accounts returns the accounts of the given type, all the accounts are returned if the type is empty
+func (s *AccountService) Accounts(ctx context.Context, accountType AccountType) ([]Account, error) {
+	params := url.Values{}
+	if len(accountType) > 0 {
+		params.Set("type", string(accountType))
+	}
+
+	var accounts []Account
+	err := s.client.sendAuthenticatedRequest(ctx, "GET", "/api/v1/accounts", params, nil, &accounts)
+	return accounts, err
+}
+
+type Deposit struct {
+	Address    string               `json:"address"`
+	Memo       string               `json:"memo"`
+	Amount     fixedpoint.Value     `json:"amount"`
+	Fee        fixedpoint.Value     `json:"fee"`
+	Currency   string               `json:"currency"`
+	IsInner    bool                 `json:"isInner"`
+	WalletTxID string               `json:"walletTxId"`
+	Status     string               `json:"status"`
+	Remark     string               `json:"remark"`
+	CreatedAt  MillisecondTimestamp `json:"createdAt"`
+	UpdatedAt  MillisecondTimestamp `json:"updatedAt"`
+}
+
+type Withdrawal struct {
+	ID         string               `json:"id"`
+	Address    string               `json:"address"`
+	Memo       string               `json:"memo"`
+	Currency   string               `json:"currency"`
+	Amount     fixedpoint.Value     `json:"amount"`
+	Fee        fixedpoint.Value     `json:"fee"`
+	WalletTxID string               `json:"walletTxId"`
+	IsInner    bool                 `json:"isInner"`
+	Status     string               `json:"status"`
+	Remark     string               `json:"remark"`
+	CreatedAt  MillisecondTimestamp `json:"createdAt"`
+	UpdatedAt  MillisecondTimestamp `json:"updatedAt"`
+}
+
+func timeRangeParams(currency string, startTime, endTime time.Time) url.Values {
+	params := url.Values{}
+	if len(currency) > 0 {
+		params.Set("currency", currency)
+	}
+
+	if !startTime.IsZero() {
+		params.Set("startAt", strconv.FormatInt(startTime.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	if !endTime.IsZero() {
+		params.Set("endAt", strconv.FormatInt(endTime.UnixNano()/int64(time.Millisecond), 10))
+	}
+
+	return params
+}
+
+// Deposits returns the deposits in the time range, the time range can not be longer than 7 days
+func (s *AccountService) Deposits(ctx context.Context, currency string, startTime, endTime time.Time) (deposits []Deposit, err error) {
+	err = s.client.queryPages(ctx, "/api/v1/deposits", timeRangeParams(currency, startTime, endTime), func(items json.RawMessage) error {
+		var page []Deposit
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+
+		deposits = append(deposits, page...)
+		return nil
+	})
+
+	return deposits, err
+}
+
+// Withdrawals returns the withdrawals in the time range, the time range can not be longer than 7 days
+func (s *AccountService) Withdrawals(ctx context.Context, currency string, startTime, endTime time.Time) (withdrawals []Withdrawal, err error) {
+	err = s.client.queryPages(ctx, "/api/v1/withdrawals", timeRangeParams(currency, startTime, endTime), func(items json.RawMessage) error {
+		var page []Withdrawal
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+
+		withdrawals = append(withdrawals, page...)
+		return nil
+	})
+
+	return withdrawals, err
+}
+
+// InnerTransferRequest is the payload of the transfer between the main, trade and margin accounts
+type InnerTransferRequest struct {
+	ClientOID string      `json:"clientOid"`
+	Currency  string      `json:"currency"`
+	From      AccountType `json:"from"`
+	To        AccountType `json:"to"`
+	Amount    string      `json:"amount"`
+}
+
+type InnerTransferResponse struct {
+	OrderID string `json:"orderId"`
+}
+
+func (s *AccountService) InnerTransfer(ctx context.Context, req InnerTransferRequest) (*InnerTransferResponse, error) {
+	var resp InnerTransferResponse
+	err := s.client.sendAuthenticatedRequest(ctx, "POST", "/api/v2/accounts/inner-transfer", nil, req, &resp)
+	return &resp, err
+}
@@ -0,0 +1,71 @@
+package kucoinapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+)
+
+type BulletService struct {
+	client *RestClient
+}
+
+type InstanceServer struct {
+	Endpoint     string `json:"endpoint"`
+	Protocol     string `json:"protocol"`
+	Encrypt      bool   `json:"encrypt"`
+	PingInterval int64  `json:"pingInterval"`
+	PingTimeout  int64  `json:"pingTimeout"`
+}
+
+// Bullet is the websocket token and the server list, the token is required for connecting the websocket server
+type Bullet struct {
+	Token           string           `json:"token"`
+	InstanceServers []InstanceServer `json:"instanceServers"`
+}
+
+// URL returns the websocket url of the first instance server with the token and the connect id
+func (b *Bullet) URL(connectID string) (string, error) {
+	if len(b.InstanceServers) == 0 {
+		return "", errors.New("no instance server is available")
+	}
+
+	u, err := url.Parse(b.InstanceServers[0].Endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("token", b.Token)
+	params.Set("connectId", connectID)
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+func (b *Bullet) PingInterval() time.Duration {
+	if len(b.InstanceServers) == 0 || b.InstanceServers[0].PingInterval == 0 {
+		return 18 * time.Second
+	}
+
+	return time.Duration(b.InstanceServers[0].PingInterval) * time.Millisecond
+}
+
+// PublicBullet requests the token of the public channels
+func (s *BulletService) PublicBullet(ctx context.Context) (*Bullet, error) {
+	req, err := s.client.newRequest(ctx, "POST", "/api/v1/bullet-public", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var bullet Bullet
+	err = s.client.sendRequest(req, &bullet)
+	return &bullet, err
+}
+
+// PrivateBullet requests the token of both the public and the private channels
+func (s *BulletService) PrivateBullet(ctx context.Context) (*Bullet, error) {
+	var bullet Bullet
+	err := s.client.sendAuthenticatedRequest(ctx, "POST", "/api/v1/bullet-private", nil, nil, &bullet)
+	return &bullet, err
+}
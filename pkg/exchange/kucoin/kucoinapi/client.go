@@ -0,0 +1,270 @@
+package kucoinapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProductionAPIURL is the official KuCoin spot API endpoint
+	ProductionAPIURL = "https://api.kucoin.com"
+
+	// SuccessCode is the response code of the successful requests
+	SuccessCode = "200000"
+
+	UserAgent = "bbgo/1.0"
+
+	defaultHTTPTimeout = time.Second * 15
+)
+
+var log = logrus.WithField("exchange", "kucoin")
+
+type RestClient struct {
+	client *http.Client
+
+	BaseURL *url.URL
+
+	// Authentication
+	Key        string
+	Secret     string
+	Passphrase string
+
+	AccountService *AccountService
+	MarketService  *MarketService
+	TradeService   *TradeService
+	BulletService  *BulletService
+}
+
+func NewRestClient(baseURL string) *RestClient {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic(err)
+	}
+
+	client := &RestClient{
+		client:  &http.Client{Timeout: defaultHTTPTimeout},
+		BaseURL: u,
+	}
+
+	client.AccountService = &AccountService{client}
+	client.MarketService = &MarketService{client}
+	client.TradeService = &TradeService{client}
+	client.BulletService = &BulletService{client}
+	return client
+}
+
+// Auth sets the api key, secret and passphrase for the private endpoints.
+func (c *RestClient) Auth(key, secret, passphrase string) *RestClient {
+	c.Key = key
+	c.Secret = secret
+	c.Passphrase = passphrase
+	return c
+}
+
+// Response is the standard response wrapper of KuCoin, the payload is in the data field.
+type Response struct {
+	Code    string          `json:"code"`
+	Message string          `json:"msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ErrorResponse is returned when the response code is not the success code
+type ErrorResponse struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (r *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s %s: %d %s %s", r.Method, r.URL, r.StatusCode, r.Code, r.Message)
+}
+
+func (c *RestClient) newRequest(ctx context.Context, method, refURL string, params url.Values, body []byte) (*http.Request, error) {
+	rel, err := url.Parse(refURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		rel.RawQuery = params.Encode()
+	}
+
+	u := c.BaseURL.ResolveReference(rel)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("User-Agent", UserAgent)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Accept", "application/json")
+	return req, nil
+}
+
+// newAuthenticatedRequest signs the request with the api key v2 scheme:
+// the signature is base64(hmac_sha256(timestamp + method + request path with the query + body)),
+// and the passphrase is signed with the secret as well.
+func (c *RestClient) newAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}) (*http.Request, error) {
+	if len(c.Key) == 0 || len(c.Secret) == 0 || len(c.Passphrase) == 0 {
+		return nil, errors.New("empty api key, secret or passphrase")
+	}
+
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := c.newRequest(ctx, method, refURL, params, body)
+	if err != nil {
+		return nil, err
+	}
+
+	path := req.URL.Path
+	if len(req.URL.RawQuery) > 0 {
+		path += "?" + req.URL.RawQuery
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+
+	req.Header.Add("KC-API-KEY", c.Key)
+	req.Header.Add("KC-API-SIGN", sign(c.Secret, timestamp+method+path+string(body)))
+	req.Header.Add("KC-API-TIMESTAMP", timestamp)
+	req.Header.Add("KC-API-PASSPHRASE", sign(c.Secret, c.Passphrase))
+	req.Header.Add("KC-API-KEY-VERSION", "2")
+	return req, nil
+}
+
+func sign(secret, payload string) string {
+	var sig = hmac.New(sha256.New, []byte(secret))
+	_, err := sig.Write([]byte(payload))
+	if err != nil {
+		return ""
+	}
+
+	return base64.StdEncoding.EncodeToString(sig.Sum(nil))
+}
+
+// sendRequest sends the request and decodes the data field of the response into the given object
+func (c *RestClient) sendRequest(req *http.Request, data interface{}) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var apiResponse Response
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return errors.Wrapf(err, "failed to decode the response: %s", body)
+	}
+
+	if apiResponse.Code != SuccessCode {
+		return &ErrorResponse{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Code:       apiResponse.Code,
+			Message:    apiResponse.Message,
+		}
+	}
+
+	if data == nil || len(apiResponse.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(apiResponse.Data, data)
+}
+
+func (c *RestClient) get(ctx context.Context, refURL string, params url.Values, data interface{}) error {
+	req, err := c.newRequest(ctx, "GET", refURL, params, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+func (c *RestClient) sendAuthenticatedRequest(ctx context.Context, method, refURL string, params url.Values, payload interface{}, data interface{}) error {
+	req, err := c.newAuthenticatedRequest(ctx, method, refURL, params, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.sendRequest(req, data)
+}
+
+// Page is the pagination wrapper of the list endpoints
+type Page struct {
+	CurrentPage int             `json:"currentPage"`
+	PageSize    int             `json:"pageSize"`
+	TotalNum    int             `json:"totalNum"`
+	TotalPage   int             `json:"totalPage"`
+	Items       json.RawMessage `json:"items"`
+}
+
+// queryPages queries all the pages of a paginated endpoint, each page of items is passed to the handler
+func (c *RestClient) queryPages(ctx context.Context, refURL string, params url.Values, handler func(items json.RawMessage) error) error {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	params.Set("pageSize", "500")
+
+	for currentPage := 1; ; currentPage++ {
+		params.Set("currentPage", strconv.Itoa(currentPage))
+
+		var page Page
+		if err := c.sendAuthenticatedRequest(ctx, "GET", refURL, params, nil, &page); err != nil {
+			return err
+		}
+
+		if err := handler(page.Items); err != nil {
+			return err
+		}
+
+		if currentPage >= page.TotalPage {
+			return nil
+		}
+	}
+}
+
+// MillisecondTimestamp parses the millisecond timestamp of the responses
+type MillisecondTimestamp time.Time
+
+func (t *MillisecondTimestamp) UnmarshalJSON(data []byte) error {
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+
+	*t = MillisecondTimestamp(time.Unix(0, ms*int64(time.Millisecond)))
+	return nil
+}
+
+func (t MillisecondTimestamp) Time() time.Time {
+	return time.Time(t)
+}
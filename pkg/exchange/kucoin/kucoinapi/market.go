@@ -0,0 +1,75 @@
+package kucoinapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type MarketService struct {
+	client *RestClient
+}
+
+type Symbol struct {
+	Symbol          string           `json:"symbol"`
+	Name            string           `json:"name"`
+	BaseCurrency    string           `json:"baseCurrency"`
+	QuoteCurrency   string           `json:"quoteCurrency"`
+	FeeCurrency     string           `json:"feeCurrency"`
+	BaseMinSize     fixedpoint.Value `json:"baseMinSize"`
+	QuoteMinSize    fixedpoint.Value `json:"quoteMinSize"`
+	BaseMaxSize     fixedpoint.Value `json:"baseMaxSize"`
+	QuoteMaxSize    fixedpoint.Value `json:"quoteMaxSize"`
+	BaseIncrement   fixedpoint.Value `json:"baseIncrement"`
+	QuoteIncrement  fixedpoint.Value `json:"quoteIncrement"`
+	PriceIncrement  fixedpoint.Value `json:"priceIncrement"`
+	EnableTrading   bool             `json:"enableTrading"`
+	IsMarginEnabled bool             `json:"isMarginEnabled"`
+}
+
+func (s *MarketService) Symbols(ctx context.Context) ([]Symbol, error) {
+	var symbols []Symbol
+	err := s.client.get(ctx, "/api/v1/symbols", nil, &symbols)
+	return symbols, err
+}
+
+type Ticker struct {
+	Sequence    string           `json:"sequence"`
+	Price       fixedpoint.Value `json:"price"`
+	Size        fixedpoint.Value `json:"size"`
+	BestBid     fixedpoint.Value `json:"bestBid"`
+	BestBidSize fixedpoint.Value `json:"bestBidSize"`
+	BestAsk     fixedpoint.Value `json:"bestAsk"`
+	BestAskSize fixedpoint.Value `json:"bestAskSize"`
+}
+
+func (s *MarketService) Ticker(ctx context.Context, symbol string) (*Ticker, error) {
+	var ticker Ticker
+	err := s.client.get(ctx, "/api/v1/market/orderbook/level1", url.Values{"symbol": []string{symbol}}, &ticker)
+	return &ticker, err
+}
+
+// Candle is the kline row of KuCoin: [start time in seconds, open, close, high, low, volume, turnover]
+type Candle []string
+
+// Candles returns the klines in the time range, KuCoin returns the latest kline first, and at most 1500 klines per request.
+func (s *MarketService) Candles(ctx context.Context, symbol, candleType string, startTime, endTime time.Time) ([]Candle, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("type", candleType)
+
+	if !startTime.IsZero() {
+		params.Set("startAt", strconv.FormatInt(startTime.Unix(), 10))
+	}
+
+	if !endTime.IsZero() {
+		params.Set("endAt", strconv.FormatInt(endTime.Unix(), 10))
+	}
+
+	var candles []Candle
+	err := s.client.get(ctx, "/api/v1/market/candles", params, &candles)
+	return candles, err
+}
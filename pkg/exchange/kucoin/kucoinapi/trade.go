@@ -0,0 +1,188 @@
+package kucoinapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type SideType string
+
+const (
+	SideTypeBuy  SideType = "buy"
+	SideTypeSell SideType = "sell"
+)
+
+type OrderType string
+
+const (
+	OrderTypeLimit      OrderType = "limit"
+	OrderTypeMarket     OrderType = "market"
+	OrderTypeStopLimit  OrderType = "limit_stop"
+	OrderTypeStopMarket OrderType = "market_stop"
+)
+
+type StopType string
+
+const (
+	// StopTypeLoss triggers when the last price <= the stop price
+	StopTypeLoss StopType = "loss"
+
+	// StopTypeEntry triggers when the last price >= the stop price
+	StopTypeEntry StopType = "entry"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusActive OrderStatus = "active"
+	OrderStatusDone   OrderStatus = "done"
+)
+
+type TradeService struct {
+	client *RestClient
+}
+
+// CreateOrderRequest is the payload of the order creation, the numbers are sent as strings.
+type CreateOrderRequest struct {
+	ClientOrderID string    `json:"clientOid"`
+	Symbol        string    `json:"symbol"`
+	Side          SideType  `json:"side"`
+	Type          OrderType `json:"type"`
+	Size          string    `json:"size,omitempty"`
+	Price         string    `json:"price,omitempty"`
+	TimeInForce   string    `json:"timeInForce,omitempty"`
+	Stop          StopType  `json:"stop,omitempty"`
+	StopPrice     string    `json:"stopPrice,omitempty"`
+}
+
+type CreateOrderResponse struct {
+	OrderID string `json:"orderId"`
+}
+
+func (s *TradeService) CreateOrder(ctx context.Context, req CreateOrderRequest) (*CreateOrderResponse, error) {
+	var resp CreateOrderResponse
+	err := s.client.sendAuthenticatedRequest(ctx, "POST", "/api/v1/orders", nil, req, &resp)
+	return &resp, err
+}
+
+type CancelOrderResponse struct {
+	CancelledOrderIDs []string `json:"cancelledOrderIds"`
+	CancelledOrderID  string   `json:"cancelledOrderId"`
+	ClientOrderID     string   `json:"clientOid"`
+}
+
+func (s *TradeService) CancelOrder(ctx context.Context, orderID string) (*CancelOrderResponse, error) {
+	var resp CancelOrderResponse
+	err := s.client.sendAuthenticatedRequest(ctx, "DELETE", "/api/v1/orders/"+url.PathEscape(orderID), nil, nil, &resp)
+	return &resp, err
+}
+
+func (s *TradeService) CancelOrderByClientOrderID(ctx context.Context, clientOrderID string) (*CancelOrderResponse, error) {
+	var resp CancelOrderResponse
+	err := s.client.sendAuthenticatedRequest(ctx, "DELETE", "/api/v1/order/client-order/"+url.PathEscape(clientOrderID), nil, nil, &resp)
+	return &resp, err
+}
+
+// CancelAllOrders cancels all the open orders of the symbol, or all the open orders if the symbol is empty
+func (s *TradeService) CancelAllOrders(ctx context.Context, symbol string) (*CancelOrderResponse, error) {
+	params := url.Values{}
+	if len(symbol) > 0 {
+		params.Set("symbol", symbol)
+	}
+
+	var resp CancelOrderResponse
+	err := s.client.sendAuthenticatedRequest(ctx, "DELETE", "/api/v1/orders", params, nil, &resp)
+	return &resp, err
+}
+
+type Order struct {
+	ID            string               `json:"id"`
+	ClientOrderID string               `json:"clientOid"`
+	Symbol        string               `json:"symbol"`
+	Type          OrderType            `json:"type"`
+	Side          SideType             `json:"side"`
+	Price         fixedpoint.Value     `json:"price"`
+	Size          fixedpoint.Value     `json:"size"`
+	Funds         fixedpoint.Value     `json:"funds"`
+	DealFunds     fixedpoint.Value     `json:"dealFunds"`
+	DealSize      fixedpoint.Value     `json:"dealSize"`
+	Fee           fixedpoint.Value     `json:"fee"`
+	FeeCurrency   string               `json:"feeCurrency"`
+	Stop          StopType             `json:"stop"`
+	StopTriggered bool                 `json:"stopTriggered"`
+	StopPrice     fixedpoint.Value     `json:"stopPrice"`
+	TimeInForce   string               `json:"timeInForce"`
+	IsActive      bool                 `json:"isActive"`
+	CancelExist   bool                 `json:"cancelExist"`
+	CreatedAt     MillisecondTimestamp `json:"createdAt"`
+	TradeType     string               `json:"tradeType"`
+}
+
+func (s *TradeService) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	var order Order
+	err := s.client.sendAuthenticatedRequest(ctx, "GET", "/api/v1/orders/"+url.PathEscape(orderID), nil, nil, &order)
+	return &order, err
+}
+
+// ListOrders queries the orders of the symbol with the given status, the time range of the done orders can not be longer than 7 days
+func (s *TradeService) ListOrders(ctx context.Context, symbol string, status OrderStatus, startTime, endTime time.Time) (orders []Order, err error) {
+	params := timeRangeParams("", startTime, endTime)
+	params.Set("symbol", symbol)
+	params.Set("status", string(status))
+	params.Set("tradeType", "TRADE")
+
+	err = s.client.queryPages(ctx, "/api/v1/orders", params, func(items json.RawMessage) error {
+		var page []Order
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+
+		orders = append(orders, page...)
+		return nil
+	})
+
+	return orders, err
+}
+
+type Fill struct {
+	Symbol         string               `json:"symbol"`
+	TradeID        string               `json:"tradeId"`
+	OrderID        string               `json:"orderId"`
+	CounterOrderID string               `json:"counterOrderId"`
+	Side           SideType             `json:"side"`
+	Liquidity      string               `json:"liquidity"`
+	ForceTaker     bool                 `json:"forceTaker"`
+	Price          fixedpoint.Value     `json:"price"`
+	Size           fixedpoint.Value     `json:"size"`
+	Funds          fixedpoint.Value     `json:"funds"`
+	Fee            fixedpoint.Value     `json:"fee"`
+	FeeRate        fixedpoint.Value     `json:"feeRate"`
+	FeeCurrency    string               `json:"feeCurrency"`
+	Stop           string               `json:"stop"`
+	Type           OrderType            `json:"type"`
+	CreatedAt      MillisecondTimestamp `json:"createdAt"`
+	TradeType      string               `json:"tradeType"`
+}
+
+// ListFills queries the fills of the symbol in the time range, the time range can not be longer than 7 days
+func (s *TradeService) ListFills(ctx context.Context, symbol string, startTime, endTime time.Time) (fills []Fill, err error) {
+	params := timeRangeParams("", startTime, endTime)
+	params.Set("symbol", symbol)
+	params.Set("tradeType", "TRADE")
+
+	err = s.client.queryPages(ctx, "/api/v1/fills", params, func(items json.RawMessage) error {
+		var page []Fill
+		if err := json.Unmarshal(items, &page); err != nil {
+			return err
+		}
+
+		fills = append(fills, page...)
+		return nil
+	})
+
+	return fills, err
+}
@@ -0,0 +1,160 @@
+package kucoinapi
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type WebSocketMessageType string
+
+const (
+	WebSocketMessageTypeWelcome     WebSocketMessageType = "welcome"
+	WebSocketMessageTypeAck         WebSocketMessageType = "ack"
+	WebSocketMessageTypePing        WebSocketMessageType = "ping"
+	WebSocketMessageTypePong        WebSocketMessageType = "pong"
+	WebSocketMessageTypeSubscribe   WebSocketMessageType = "subscribe"
+	WebSocketMessageTypeMessage     WebSocketMessageType = "message"
+	WebSocketMessageTypeError       WebSocketMessageType = "error"
+	WebSocketMessageTypeUnsubscribe WebSocketMessageType = "unsubscribe"
+)
+
+const (
+	TopicCandles     = "/market/candles"
+	TopicTradeOrders = "/spotMarket/tradeOrders"
+	TopicBalance     = "/account/balance"
+)
+
+type WebSocketCommand struct {
+	ID             string               `json:"id"`
+	Type           WebSocketMessageType `json:"type"`
+	Topic          string               `json:"topic,omitempty"`
+	PrivateChannel bool                 `json:"privateChannel,omitempty"`
+	Response       bool                 `json:"response,omitempty"`
+}
+
+type WebSocketMessage struct {
+	ID      string               `json:"id"`
+	Type    WebSocketMessageType `json:"type"`
+	Topic   string               `json:"topic"`
+	Subject string               `json:"subject"`
+	Data    json.RawMessage      `json:"data"`
+
+	// Code and Data are set in the error message
+	Code json.Number `json:"code"`
+}
+
+// NanosecondTimestamp parses the nanosecond timestamp of the websocket events
+type NanosecondTimestamp time.Time
+
+func (t *NanosecondTimestamp) UnmarshalJSON(data []byte) error {
+	var ns int64
+	if err := json.Unmarshal(data, &ns); err != nil {
+		return err
+	}
+
+	*t = NanosecondTimestamp(time.Unix(0, ns))
+	return nil
+}
+
+func (t NanosecondTimestamp) Time() time.Time {
+	return time.Time(t)
+}
+
+// CandleEvent is pushed on every trade of the candle, KuCoin doesn't mark the candle as closed,
+// the candle is closed when the candle of the next period is pushed.
+type CandleEvent struct {
+	Symbol  string              `json:"symbol"`
+	Candles Candle              `json:"candles"`
+	Time    NanosecondTimestamp `json:"time"`
+}
+
+type OrderChangeType string
+
+const (
+	OrderChangeTypeOpen     OrderChangeType = "open"
+	OrderChangeTypeMatch    OrderChangeType = "match"
+	OrderChangeTypeFilled   OrderChangeType = "filled"
+	OrderChangeTypeCanceled OrderChangeType = "canceled"
+	OrderChangeTypeUpdate   OrderChangeType = "update"
+)
+
+// OrderChangeEvent is pushed on the private order channel, the match event carries the trade of the order.
+type OrderChangeEvent struct {
+	Symbol        string              `json:"symbol"`
+	OrderType     OrderType           `json:"orderType"`
+	Side          SideType            `json:"side"`
+	OrderID       string              `json:"orderId"`
+	Type          OrderChangeType     `json:"type"`
+	OrderTime     NanosecondTimestamp `json:"orderTime"`
+	Size          fixedpoint.Value    `json:"size"`
+	FilledSize    fixedpoint.Value    `json:"filledSize"`
+	Price         fixedpoint.Value    `json:"price"`
+	ClientOrderID string              `json:"clientOid"`
+	RemainSize    fixedpoint.Value    `json:"remainSize"`
+	Status        string              `json:"status"`
+	Timestamp     NanosecondTimestamp `json:"ts"`
+
+	// the fields of the match event
+	Liquidity  string           `json:"liquidity"`
+	MatchPrice fixedpoint.Value `json:"matchPrice"`
+	MatchSize  fixedpoint.Value `json:"matchSize"`
+	TradeID    string           `json:"tradeId"`
+}
+
+type BalanceEvent struct {
+	AccountID       string           `json:"accountId"`
+	Currency        string           `json:"currency"`
+	Total           fixedpoint.Value `json:"total"`
+	Available       fixedpoint.Value `json:"available"`
+	AvailableChange fixedpoint.Value `json:"availableChange"`
+	Hold            fixedpoint.Value `json:"hold"`
+	HoldChange      fixedpoint.Value `json:"holdChange"`
+	RelationEvent   string           `json:"relationEvent"`
+	Time            json.Number      `json:"time"`
+}
+
+// EventTime returns the time of the balance event, the time is in milliseconds
+func (e *BalanceEvent) EventTime() time.Time {
+	ms, err := e.Time.Int64()
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// ParseEvent parses the data of the message by the topic, nil is returned for the unsupported topics
+func ParseEvent(m *WebSocketMessage) (interface{}, error) {
+	switch TopicChannel(m.Topic) {
+
+	case TopicCandles:
+		var e CandleEvent
+		err := json.Unmarshal(m.Data, &e)
+		return &e, err
+
+	case TopicTradeOrders:
+		var e OrderChangeEvent
+		err := json.Unmarshal(m.Data, &e)
+		return &e, err
+
+	case TopicBalance:
+		var e BalanceEvent
+		err := json.Unmarshal(m.Data, &e)
+		return &e, err
+
+	}
+
+	return nil, nil
+}
+
+// TopicChannel removes the arguments of the topic, e.g., /market/candles:BTC-USDT_1min => /market/candles
+func TopicChannel(topic string) string {
+	if idx := strings.Index(topic, ":"); idx >= 0 {
+		return topic[:idx]
+	}
+
+	return topic
+}
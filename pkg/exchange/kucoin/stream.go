@@ -0,0 +1,395 @@
+package kucoin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	Conn     *websocket.Conn
+	connLock sync.Mutex
+
+	bullet *kucoinapi.Bullet
+
+	publicOnly bool
+
+	// candles is the last candle of each candle topic,
+	// kucoin doesn't push the closed candle, so the last candle is closed when the candle of the next period is pushed.
+	candles map[string]types.KLine
+}
+
+func NewStream(exchange *Exchange) *Stream {
+	return &Stream{
+		exchange: exchange,
+		candles:  make(map[string]types.KLine),
+	}
+}
+
+func (s *Stream) SetPublicOnly() {
+	s.publicOnly = true
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	go s.read(ctx)
+	return nil
+}
+
+func (s *Stream) connect(ctx context.Context) error {
+	var bullet *kucoinapi.Bullet
+	var err error
+	if s.publicOnly {
+		log.Infof("stream is set to public only mode")
+		bullet, err = s.exchange.client.BulletService.PublicBullet(ctx)
+	} else {
+		log.Infof("request bullet token for creating user data stream...")
+		bullet, err = s.exchange.client.BulletService.PrivateBullet(ctx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	url, err := bullet.URL(uuid.New().String())
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("websocket connected")
+
+	s.connLock.Lock()
+	s.Conn = conn
+	s.bullet = bullet
+	s.connLock.Unlock()
+
+	s.EmitConnect()
+	return nil
+}
+
+func (s *Stream) writeCommand(command kucoinapi.WebSocketCommand) error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.Conn.WriteJSON(command)
+}
+
+// subscribe sends the subscriptions, it's called when the welcome message is received
+func (s *Stream) subscribe(ctx context.Context) {
+	for _, subscription := range s.Subscriptions {
+		topic, err := s.convertSubscription(ctx, subscription)
+		if err != nil {
+			log.WithError(err).Errorf("subscription error: %+v", subscription)
+			continue
+		}
+
+		if err := s.writeCommand(kucoinapi.WebSocketCommand{
+			ID:       uuid.New().String(),
+			Type:     kucoinapi.WebSocketMessageTypeSubscribe,
+			Topic:    topic,
+			Response: true,
+		}); err != nil {
+			log.WithError(err).Errorf("subscribe error: %s", topic)
+		}
+	}
+
+	if s.publicOnly {
+		return
+	}
+
+	for _, topic := range []string{kucoinapi.TopicTradeOrders, kucoinapi.TopicBalance} {
+		if err := s.writeCommand(kucoinapi.WebSocketCommand{
+			ID:             uuid.New().String(),
+			Type:           kucoinapi.WebSocketMessageTypeSubscribe,
+			Topic:          topic,
+			PrivateChannel: true,
+			Response:       true,
+		}); err != nil {
+			log.WithError(err).Errorf("subscribe error: %s", topic)
+		}
+	}
+}
+
+// convertSubscription converts the subscription to the kucoin topic,
+// for kline, it's "/market/candles:<symbol>_<type>", e.g., /market/candles:BTC-USDT_1min
+func (s *Stream) convertSubscription(ctx context.Context, subscription types.Subscription) (string, error) {
+	switch subscription.Channel {
+	case types.KLineChannel:
+		candleType, err := toLocalInterval(types.Interval(subscription.Options.Interval))
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s:%s_%s", kucoinapi.TopicCandles, s.exchange.localSymbol(ctx, subscription.Symbol), candleType), nil
+	}
+
+	return "", fmt.Errorf("kucoin stream does not support the channel %s", subscription.Channel)
+}
+
+func (s *Stream) read(ctx context.Context) {
+	go func() {
+		s.connLock.Lock()
+		pingInterval := s.bullet.PingInterval()
+		s.connLock.Unlock()
+
+		pingTicker := time.NewTicker(pingInterval)
+		defer pingTicker.Stop()
+
+		for {
+			select {
+
+			case <-ctx.Done():
+				return
+
+			case <-pingTicker.C:
+				if err := s.writeCommand(kucoinapi.WebSocketCommand{
+					ID:   uuid.New().String(),
+					Type: kucoinapi.WebSocketMessageTypePing,
+				}); err != nil {
+					log.WithError(err).Error("ping error")
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+
+		case <-ctx.Done():
+			return
+
+		default:
+			s.connLock.Lock()
+			conn := s.Conn
+			s.connLock.Unlock()
+
+			if err := conn.SetReadDeadline(time.Now().Add(1 * time.Minute)); err != nil {
+				log.WithError(err).Errorf("set read deadline error: %s", err.Error())
+			}
+
+			mt, message, err := conn.ReadMessage()
+			receivedTime := time.Now()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway) {
+					log.WithError(err).Errorf("read error: %s", err.Error())
+				} else {
+					log.Info("websocket connection closed, going away")
+				}
+
+				s.EmitDisconnect()
+
+				// reconnect with the exponential backoff
+				backoff := types.NewReconnectBackoff()
+				for err != nil {
+					select {
+					case <-ctx.Done():
+						return
+
+					default:
+						err = s.connect(ctx)
+						if err != nil {
+							delay := backoff.Next()
+							log.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+							time.Sleep(delay)
+						}
+					}
+				}
+
+				continue
+			}
+
+			s.SetLastMessageTime(receivedTime)
+
+			// skip non-text messages
+			if mt != websocket.TextMessage {
+				continue
+			}
+
+			log.Debug(string(message))
+
+			var m kucoinapi.WebSocketMessage
+			if err := json.Unmarshal(message, &m); err != nil {
+				log.WithError(err).Errorf("[kucoin] message parse error: %s", message)
+				continue
+			}
+
+			switch m.Type {
+
+			case kucoinapi.WebSocketMessageTypeWelcome:
+				s.subscribe(ctx)
+
+			case kucoinapi.WebSocketMessageTypeError:
+				log.Errorf("[kucoin] websocket error: %s %s", m.Code, m.Data)
+
+			case kucoinapi.WebSocketMessageTypeMessage:
+				e, err := kucoinapi.ParseEvent(&m)
+				if err != nil {
+					log.WithError(err).Errorf("[kucoin] event parse error: %s", message)
+					continue
+				}
+
+				if eventTime, ok := s.dispatchEvent(m.Topic, e); ok {
+					s.Latency().Record(strings.TrimPrefix(kucoinapi.TopicChannel(m.Topic), "/"), eventTime, receivedTime, time.Now())
+				}
+			}
+		}
+	}
+}
+
+// dispatchEvent emits the event and returns the event time
+func (s *Stream) dispatchEvent(topic string, e interface{}) (time.Time, bool) {
+	switch e := e.(type) {
+
+	case *kucoinapi.CandleEvent:
+		s.handleCandleEvent(topic, e)
+		return e.Time.Time(), true
+
+	case *kucoinapi.OrderChangeEvent:
+		s.handleOrderChangeEvent(e)
+		return e.Timestamp.Time(), true
+
+	case *kucoinapi.BalanceEvent:
+		currency := toGlobalCurrency(e.Currency)
+		s.EmitBalanceUpdate(types.BalanceMap{
+			currency: {
+				Currency:  currency,
+				Available: e.Available,
+				Locked:    e.Hold,
+			},
+		})
+		return e.EventTime(), true
+	}
+
+	return time.Time{}, false
+}
+
+func (s *Stream) handleCandleEvent(topic string, e *kucoinapi.CandleEvent) {
+	idx := strings.LastIndex(topic, "_")
+	if idx < 0 {
+		return
+	}
+
+	interval := toGlobalInterval(topic[idx+1:])
+	kline, err := toGlobalKLine(toGlobalSymbol(e.Symbol), interval, e.Candles)
+	if err != nil {
+		log.WithError(err).Error("candle convert error")
+		return
+	}
+
+	if last, ok := s.candles[topic]; ok && kline.StartTime.After(last.StartTime) {
+		last.Closed = true
+		s.EmitKLine(last)
+		s.EmitKLineClosed(last)
+	}
+
+	s.candles[topic] = kline
+	s.EmitKLine(kline)
+}
+
+func (s *Stream) handleOrderChangeEvent(e *kucoinapi.OrderChangeEvent) {
+	orderID := s.exchange.orderIDs.Add(e.OrderID)
+	symbol := toGlobalSymbol(e.Symbol)
+
+	var status types.OrderStatus
+	switch e.Type {
+	case kucoinapi.OrderChangeTypeFilled:
+		status = types.OrderStatusFilled
+
+	case kucoinapi.OrderChangeTypeCanceled:
+		status = types.OrderStatusCanceled
+
+	default:
+		status = toGlobalOrderStatus(e.Status != "done", false, e.Size, e.FilledSize)
+	}
+
+	s.EmitOrderUpdate(types.Order{
+		SubmitOrder: types.SubmitOrder{
+			ClientOrderID: e.ClientOrderID,
+			Symbol:        symbol,
+			Side:          toGlobalSideType(e.Side),
+			Type:          toGlobalOrderType(e.OrderType, ""),
+			Quantity:      e.Size.Float64(),
+			Price:         e.Price.Float64(),
+		},
+		Exchange:         types.ExchangeKucoin.String(),
+		OrderID:          orderID,
+		Status:           status,
+		ExecutedQuantity: e.FilledSize.Float64(),
+		IsWorking:        e.Status != "done",
+		CreationTime:     e.OrderTime.Time(),
+		UpdateTime:       e.Timestamp.Time(),
+	})
+
+	// the closed orders can not be canceled, their order IDs are not needed anymore
+	switch status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		s.exchange.orderIDs.Remove(orderID)
+	}
+
+	if e.Type != kucoinapi.OrderChangeTypeMatch {
+		return
+	}
+
+	// the match event doesn't carry the fee, the fee is only available from the fills api
+	side := toGlobalSideType(e.Side)
+	s.EmitTradeUpdate(types.Trade{
+		ID:            int64(hashID(e.TradeID)),
+		OrderID:       orderID,
+		Exchange:      types.ExchangeKucoin.String(),
+		Price:         e.MatchPrice.Float64(),
+		Quantity:      e.MatchSize.Float64(),
+		QuoteQuantity: e.MatchPrice.Float64() * e.MatchSize.Float64(),
+		Symbol:        symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		IsMaker:       e.Liquidity == "maker",
+		Time:          e.Timestamp.Time(),
+	})
+}
+
+func (s *Stream) Close() error {
+	log.Infof("closing kucoin stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
+
+// Reconnect closes the current connection, the read loop reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	log.Infof("reconnecting kucoin stream...")
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+
+	if s.Conn == nil {
+		return nil
+	}
+
+	return s.Conn.Close()
+}
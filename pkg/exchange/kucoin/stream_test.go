@@ -0,0 +1,49 @@
+package kucoin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStream_handleOrderChangeEvent_removeClosedOrderID(t *testing.T) {
+	exchange := New("", "", "")
+	stream := NewStream(exchange)
+
+	var updates []types.Order
+	stream.OnOrderUpdate(func(order types.Order) {
+		updates = append(updates, order)
+	})
+
+	event := &kucoinapi.OrderChangeEvent{
+		Symbol:    "BTC-USDT",
+		Side:      kucoinapi.SideTypeBuy,
+		OrderType: kucoinapi.OrderTypeLimit,
+		OrderID:   "5c35c02703aa673ceec2a168",
+		Type:      kucoinapi.OrderChangeTypeOpen,
+		Size:      fixedpoint.NewFromFloat(0.01),
+		Price:     fixedpoint.NewFromFloat(10000.0),
+		Status:    "open",
+	}
+
+	stream.handleOrderChangeEvent(event)
+	orderID := hashID(event.OrderID)
+	_, ok := exchange.orderIDs.Get(orderID)
+	assert.True(t, ok)
+
+	event.Type = kucoinapi.OrderChangeTypeCanceled
+	event.Status = "done"
+	stream.handleOrderChangeEvent(event)
+	_, ok = exchange.orderIDs.Get(orderID)
+	assert.False(t, ok)
+
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, types.OrderStatusNew, updates[0].Status)
+		assert.Equal(t, types.OrderStatusCanceled, updates[1].Status)
+		assert.Equal(t, orderID, updates[1].OrderID)
+	}
+}
@@ -0,0 +1,63 @@
+package kucoin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// localAccountTypes maps the wallets to the kucoin accounts, the main account is the funding account for the deposits and the withdrawals
+var localAccountTypes = map[types.WalletType]kucoinapi.AccountType{
+	types.WalletTypeSpot:    kucoinapi.AccountTypeTrade,
+	types.WalletTypeMargin:  kucoinapi.AccountTypeMargin,
+	types.WalletTypeFunding: kucoinapi.AccountTypeMain,
+}
+
+func toLocalAccountType(wallet types.WalletType) (kucoinapi.AccountType, error) {
+	if local, ok := localAccountTypes[wallet]; ok {
+		return local, nil
+	}
+
+	return "", fmt.Errorf("kucoin does not support the %s wallet", wallet)
+}
+
+// TransferInternal moves the currency between the main, trade and margin accounts
+func (e *Exchange) TransferInternal(ctx context.Context, from, to types.WalletType, asset string, amount fixedpoint.Value) (*types.InternalTransfer, error) {
+	localFrom, err := toLocalAccountType(from)
+	if err != nil {
+		return nil, err
+	}
+
+	localTo, err := toLocalAccountType(to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.AccountService.InnerTransfer(ctx, kucoinapi.InnerTransferRequest{
+		ClientOID: uuid.New().String(),
+		Currency:  asset,
+		From:      localFrom,
+		To:        localTo,
+		Amount:    strconv.FormatFloat(amount.Float64(), 'f', -1, 64),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InternalTransfer{
+		Exchange:   types.ExchangeKucoin,
+		TransferID: resp.OrderID,
+		From:       from,
+		To:         to,
+		Asset:      asset,
+		Amount:     amount,
+		Time:       time.Now(),
+	}, nil
+}
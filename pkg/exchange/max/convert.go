@@ -89,7 +89,7 @@ func toGlobalOrderStatus(orderStatus max.OrderState, executedVolume, remainingVo
 
 func toGlobalOrderType(orderType max.OrderType) types.OrderType {
 	switch orderType {
-	case max.OrderTypeLimit:
+	case max.OrderTypeLimit, max.OrderTypePostOnly, max.OrderTypeIOCLimit:
 		return types.OrderTypeLimit
 
 	case max.OrderTypeMarket:
@@ -126,6 +126,45 @@ func toLocalOrderType(orderType types.OrderType) (max.OrderType, error) {
 	return "", fmt.Errorf("order type %s not supported", orderType)
 }
 
+// toLocalTimeInForceOrderType maps the post only flag and the time in force of the limit order to the MAX order types,
+// the market orders are always immediate-or-cancel, and the other orders only support GTC.
+func toLocalTimeInForceOrderType(orderType max.OrderType, order types.SubmitOrder) (max.OrderType, error) {
+	if order.PostOnly {
+		if orderType != max.OrderTypeLimit {
+			return "", fmt.Errorf("post only is not supported by the %s order", order.Type)
+		}
+
+		return max.OrderTypePostOnly, nil
+	}
+
+	switch order.TimeInForce {
+	case "", types.TimeInForceGTC:
+		return orderType, nil
+
+	case types.TimeInForceIOC:
+		switch orderType {
+		case max.OrderTypeLimit:
+			return max.OrderTypeIOCLimit, nil
+		case max.OrderTypeMarket:
+			return orderType, nil
+		}
+	}
+
+	return "", fmt.Errorf("time in force %s of the %s order is not supported by max", order.TimeInForce, order.Type)
+}
+
+// toGlobalTimeInForce returns the time in force and the post only flag of the MAX order type
+func toGlobalTimeInForce(orderType max.OrderType) (types.TimeInForce, bool) {
+	switch orderType {
+	case max.OrderTypePostOnly:
+		return types.TimeInForceGTC, true
+	case max.OrderTypeIOCLimit:
+		return types.TimeInForceIOC, false
+	}
+
+	return types.TimeInForceGTC, false
+}
+
 func toGlobalOrders(maxOrders []max.Order) (orders []types.Order, err error) {
 	for _, localOrder := range maxOrders {
 		o, err := toGlobalOrder(localOrder)
@@ -150,6 +189,8 @@ func toGlobalOrder(maxOrder max.Order) (*types.Order, error) {
 		return nil, err
 	}
 
+	timeInForce, postOnly := toGlobalTimeInForce(maxOrder.OrderType)
+
 	return &types.Order{
 		SubmitOrder: types.SubmitOrder{
 			ClientOrderID: maxOrder.ClientOID,
@@ -158,7 +199,9 @@ func toGlobalOrder(maxOrder max.Order) (*types.Order, error) {
 			Type:          toGlobalOrderType(maxOrder.OrderType),
 			Quantity:      util.MustParseFloat(maxOrder.Volume),
 			Price:         util.MustParseFloat(maxOrder.Price),
-			TimeInForce:   "GTC", // MAX only supports GTC
+			StopPrice:     util.MustParseFloat(maxOrder.StopPrice),
+			TimeInForce:   timeInForce,
+			PostOnly:      postOnly,
 			GroupID:       maxOrder.GroupID,
 		},
 		Exchange:         types.ExchangeMax.String(),
@@ -20,6 +20,8 @@ import (
 var log = logrus.WithField("exchange", "max")
 
 type Exchange struct {
+	types.MarginSettings
+
 	client      *maxapi.RestClient
 	key, secret string
 }
@@ -43,10 +45,20 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeMax
 }
 
+func (e *Exchange) Capabilities() types.CapabilitySet {
+	return types.NewCapabilitySet(
+		types.CapabilityUserDataStream,
+		types.CapabilityMargin,
+		types.CapabilityMarginBorrowRepay,
+		types.CapabilityPostOnly,
+		types.CapabilityStopOrder,
+	)
+}
+
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	log.Info("querying market info...")
 
-	remoteMarkets, err := e.client.PublicService.Markets()
+	remoteMarkets, err := e.client.PublicService.Markets(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +76,7 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 			MinNotional:     m.MinQuoteAmount,
 			MinAmount:       m.MinQuoteAmount,
 			MinLot:          1.0 / math.Pow10(m.BaseUnitPrecision), // make it like 0.0001
+			StepSize:        1.0 / math.Pow10(m.BaseUnitPrecision), // max uses the base unit precision as the quantity step
 			MinQuantity:     m.MinBaseAmount,
 			MaxQuantity:     10000.0,
 			MinPrice:        1.0 / math.Pow10(m.QuoteUnitPrecision), // used in the price formatter
@@ -78,11 +91,19 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 }
 
 func (e *Exchange) NewStream() types.Stream {
-	return NewStream(e.key, e.secret)
+	stream := NewStream(e.key, e.secret)
+	stream.MarginSettings = e.MarginSettings
+	return stream
 }
 
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
-	maxOrders, err := e.client.OrderService.Open(toLocalSymbol(symbol), maxapi.QueryOrderOptions{})
+	var maxOrders []maxapi.Order
+	if e.IsMargin {
+		maxOrders, err = e.client.MarginService.OpenOrders(ctx, toLocalSymbol(symbol))
+	} else {
+		maxOrders, err = e.client.OrderService.Open(ctx, toLocalSymbol(symbol), maxapi.QueryOrderOptions{})
+	}
+
 	if err != nil {
 		return orders, err
 	}
@@ -109,7 +130,7 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 	for ; offset > 0; offset -= limit {
 		log.Infof("querying %s closed orders offset %d ~ ", symbol, offset)
 
-		maxOrders, err := e.client.OrderService.Closed(toLocalSymbol(symbol), maxapi.QueryOrderOptions{
+		maxOrders, err := e.client.OrderService.Closed(ctx, toLocalSymbol(symbol), maxapi.QueryOrderOptions{
 			Offset: offset,
 			Limit:  limit,
 		})
@@ -170,10 +191,29 @@ func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]t
 }
 
 func (e *Exchange) CancelOrdersByGroupID(ctx context.Context, groupID int64) ([]types.Order, error) {
-	var req = e.client.OrderService.NewOrderCancelAllRequest()
-	req.GroupID(groupID)
+	maxOrders, err := e.client.OrderService.CancelByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
 
-	var maxOrders, err = req.Do(ctx)
+	return toGlobalOrders(maxOrders)
+}
+
+// CancelOrdersByClientOrderID cancels the orders of the client order ids,
+// the orders canceled before the error are returned with the error.
+func (e *Exchange) CancelOrdersByClientOrderID(ctx context.Context, clientOrderIDs ...string) ([]types.Order, error) {
+	maxOrders, err := e.client.OrderService.CancelByClientOrderIDs(ctx, clientOrderIDs...)
+	orders, convertErr := toGlobalOrders(maxOrders)
+	if err != nil {
+		return orders, err
+	}
+
+	return orders, convertErr
+}
+
+// QueryOrdersByGroupID returns both the open and the closed orders of the group
+func (e *Exchange) QueryOrdersByGroupID(ctx context.Context, symbol string, groupID int64) ([]types.Order, error) {
+	maxOrders, err := e.client.OrderService.QueryByGroupID(ctx, toLocalSymbol(symbol), groupID)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +254,7 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 			return fmt.Errorf("order id or client order id is not defined, order=%+v", o)
 		}
 
-		if err := req.Do(ctx); err != nil {
+		if _, err := req.Do(ctx); err != nil {
 			log.WithError(err).Errorf("order cancel error")
 			err2 = err
 		}
@@ -230,6 +270,11 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			return createdOrders, err
 		}
 
+		orderType, err = toLocalTimeInForceOrderType(orderType, order)
+		if err != nil {
+			return createdOrders, err
+		}
+
 		req := e.client.OrderService.NewCreateOrderRequest().
 			Market(toLocalSymbol(order.Symbol)).
 			OrderType(string(orderType)).
@@ -243,6 +288,14 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			req.ClientOrderID(clientOrderID)
 		}
 
+		if order.GroupID > 0 {
+			req.GroupID(order.GroupID)
+		}
+
+		if e.IsMargin {
+			req.Margin()
+		}
+
 		switch order.Type {
 		case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
 			if len(order.StopPriceString) == 0 {
@@ -252,7 +305,8 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 			req.StopPrice(order.StopPriceString)
 		}
 
-		if len(order.PriceString) > 0 {
+		// the stop market order is filled at the market price once triggered, the price is not accepted
+		if len(order.PriceString) > 0 && order.Type != types.OrderTypeStopMarket {
 			req.Price(order.PriceString)
 		}
 
@@ -275,13 +329,142 @@ func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder
 	return createdOrders, err
 }
 
+// BatchSubmitOrders creates the orders of each market in one multi-order request, the margin orders are submitted one by one
+// since the multi-order api only creates the spot orders.
+func (e *Exchange) BatchSubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders []types.Order, errs []error, err error) {
+	createdOrders = make([]types.Order, len(orders))
+	errs = make([]error, len(orders))
+
+	if e.IsMargin {
+		for i, order := range orders {
+			created, err := e.SubmitOrders(ctx, order)
+			if err != nil {
+				errs[i] = err
+			} else if len(created) == 0 {
+				errs[i] = errors.New("returned nil order")
+			} else {
+				createdOrders[i] = created[0]
+			}
+		}
+
+		return createdOrders, errs, nil
+	}
+
+	// the multi-order request accepts the orders of one market, so the orders are grouped by the market
+	var markets []string
+	var indexes = make(map[string][]int)
+	var localOrders = make(map[string][]maxapi.Order)
+	for i, order := range orders {
+		localOrder, err := toLocalSubmitOrder(order)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		market := toLocalSymbol(order.Symbol)
+		if _, ok := indexes[market]; !ok {
+			markets = append(markets, market)
+		}
+
+		indexes[market] = append(indexes[market], i)
+		localOrders[market] = append(localOrders[market], localOrder)
+	}
+
+	for _, market := range markets {
+		resp, err := e.client.OrderService.CreateMulti(ctx, market, localOrders[market])
+		if err != nil {
+			return createdOrders, errs, err
+		}
+
+		for j, i := range indexes[market] {
+			if j >= len(*resp) {
+				errs[i] = fmt.Errorf("no order is returned for %s", orders[i].String())
+				continue
+			}
+
+			r := (*resp)[j]
+			if len(r.Error) > 0 {
+				errs[i] = errors.New(r.Error)
+				continue
+			}
+
+			createdOrder, err := toGlobalOrder(r.Order)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+
+			createdOrders[i] = *createdOrder
+		}
+	}
+
+	return createdOrders, errs, nil
+}
+
+// toLocalSubmitOrder converts the submit order to the order of the multi-order request
+func toLocalSubmitOrder(order types.SubmitOrder) (maxapi.Order, error) {
+	orderType, err := toLocalOrderType(order.Type)
+	if err != nil {
+		return maxapi.Order{}, err
+	}
+
+	orderType, err = toLocalTimeInForceOrderType(orderType, order)
+	if err != nil {
+		return maxapi.Order{}, err
+	}
+
+	localOrder := maxapi.Order{
+		Market:    toLocalSymbol(order.Symbol),
+		Side:      toLocalSideType(order.Side),
+		OrderType: orderType,
+		Volume:    order.QuantityString,
+		GroupID:   order.GroupID,
+		ClientOID: order.ClientOrderID,
+	}
+
+	if len(localOrder.ClientOID) == 0 {
+		localOrder.ClientOID = uuid.New().String()
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if len(order.StopPriceString) == 0 {
+			return maxapi.Order{}, fmt.Errorf("stop price string can not be empty")
+		}
+
+		localOrder.StopPrice = order.StopPriceString
+	}
+
+	// the stop market order is filled at the market price once triggered, the price is not accepted
+	if len(order.PriceString) > 0 && order.Type != types.OrderTypeStopMarket {
+		localOrder.Price = order.PriceString
+	}
+
+	return localOrder, nil
+}
+
 // PlatformFeeCurrency
 func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("max")
 }
 
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
-	userInfo, err := e.client.AccountService.Me()
+	if e.IsMargin {
+		balances, err := e.QueryMarginBalances(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		a := &types.Account{
+			MakerCommission: 15, // 0.15%
+			TakerCommission: 15, // 0.15%
+		}
+
+		a.UpdateBalances(balances)
+		return a, nil
+	}
+
+	userInfo, err := e.client.AccountService.Me(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -356,6 +539,7 @@ func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since
 
 			txIDs[d.TxID] = struct{}{}
 			allWithdraws = append(allWithdraws, types.Withdraw{
+				Exchange:       types.ExchangeMax,
 				ApplyTime:      time.Unix(d.CreatedAt, 0),
 				Asset:          toGlobalCurrency(d.Currency),
 				Amount:         util.MustParseFloat(d.Amount),
@@ -404,7 +588,9 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 				continue
 			}
 
+			txIDs[d.TxID] = struct{}{}
 			allDeposits = append(allDeposits, types.Deposit{
+				Exchange:      types.ExchangeMax,
 				Time:          time.Unix(d.CreatedAt, 0),
 				Amount:        util.MustParseFloat(d.Amount),
 				Asset:         toGlobalCurrency(d.Currency),
@@ -422,7 +608,11 @@ func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since,
 }
 
 func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
-	accounts, err := e.client.AccountService.Accounts()
+	if e.IsMargin {
+		return e.QueryMarginBalances(ctx)
+	}
+
+	accounts, err := e.client.AccountService.Accounts(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -498,7 +688,7 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 	// avoid rate limit
 	time.Sleep(100 * time.Millisecond)
 
-	localKLines, err := e.client.PublicService.KLines(toLocalSymbol(symbol), string(interval), *options.StartTime, limit)
+	localKLines, err := e.client.PublicService.KLines(ctx, toLocalSymbol(symbol), string(interval), *options.StartTime, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -512,7 +702,7 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func (e *Exchange) QueryAveragePrice(ctx context.Context, symbol string) (float64, error) {
-	ticker, err := e.client.PublicService.Ticker(toLocalSymbol(symbol))
+	ticker, err := e.client.PublicService.Ticker(ctx, toLocalSymbol(symbol))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,57 @@
+package max
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	_ = types.ExchangeFeeService(&Exchange{})
+}
+
+// QueryAccountFees returns the fee rates of the current vip level of the member
+func (e *Exchange) QueryAccountFees(ctx context.Context) (*types.AccountFees, error) {
+	vipLevel, err := e.client.AccountService.VipLevel(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AccountFees{
+		Tier:         fmt.Sprintf("VIP %d", vipLevel.Current.Level),
+		MakerFeeRate: fixedpoint.NewFromFloat(vipLevel.Current.MakerFee),
+		TakerFeeRate: fixedpoint.NewFromFloat(vipLevel.Current.TakerFee),
+	}, nil
+}
+
+// QueryTradingFeeRates returns the fee rates of the symbols, the fee rates of the vip level apply to all markets on MAX
+func (e *Exchange) QueryTradingFeeRates(ctx context.Context, symbols ...string) (types.TradingFeeRateMap, error) {
+	fees, err := e.QueryAccountFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(symbols) == 0 {
+		markets, err := e.QueryMarkets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for symbol := range markets {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	rates := make(types.TradingFeeRateMap, len(symbols))
+	for _, symbol := range symbols {
+		rates[symbol] = types.TradingFeeRate{
+			Symbol:       symbol,
+			MakerFeeRate: fees.MakerFeeRate,
+			TakerFeeRate: fees.TakerFeeRate,
+		}
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,84 @@
+package max
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	_ = types.MarginExchange(&Exchange{})
+	_ = types.MarginBorrowRepayService(&Exchange{})
+	_ = types.MarginBalanceService(&Exchange{})
+}
+
+// UseIsolatedMargin is not supported on MAX, the margin wallet of MAX is a cross margin account
+func (e *Exchange) UseIsolatedMargin(symbol string) {
+	log.Warnf("isolated margin is not supported on max, using the cross margin wallet for %s", symbol)
+	e.UseMargin()
+}
+
+// QueryMarginBalances queries the balances of the margin wallet, the borrowed amount includes the interest
+func (e *Exchange) QueryMarginBalances(ctx context.Context) (types.BalanceMap, error) {
+	accounts, err := e.client.MarginService.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances = make(types.BalanceMap)
+	for _, a := range accounts {
+		principal := fixedpoint.Must(fixedpoint.NewFromString(a.Principal))
+		interest := fixedpoint.Must(fixedpoint.NewFromString(a.Interest))
+		balances[toGlobalCurrency(a.Currency)] = types.Balance{
+			Currency:  toGlobalCurrency(a.Currency),
+			Available: fixedpoint.Must(fixedpoint.NewFromString(a.Balance)),
+			Locked:    fixedpoint.Must(fixedpoint.NewFromString(a.Locked)),
+			Borrowed:  principal + interest,
+		}
+	}
+
+	return balances, nil
+}
+
+func (e *Exchange) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	record, err := e.client.MarginService.NewLoanRequest().
+		Currency(toLocalCurrency(asset)).
+		Amount(strconv.FormatFloat(amount.Float64(), 'f', -1, 64)).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("borrowed %f %s, loan sn %s, state %s", amount.Float64(), asset, record.SN, record.State)
+	return nil
+}
+
+func (e *Exchange) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	record, err := e.client.MarginService.NewRepayRequest().
+		Currency(toLocalCurrency(asset)).
+		Amount(strconv.FormatFloat(amount.Float64(), 'f', -1, 64)).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("repaid %f %s, repayment sn %s, state %s", amount.Float64(), asset, record.SN, record.State)
+	return nil
+}
+
+func (e *Exchange) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	limits, err := e.client.MarginService.BorrowingLimits(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	limit, ok := limits[toLocalCurrency(asset)]
+	if !ok {
+		return 0, fmt.Errorf("borrowing limit of %s is not found", asset)
+	}
+
+	return fixedpoint.NewFromString(limit)
+}
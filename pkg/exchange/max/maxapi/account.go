@@ -50,13 +50,8 @@ type UserInfo struct {
 	ReferralCode    string    `json:"referral_code"`
 }
 
-func (s *AccountService) Account(currency string) (*Account, error) {
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/members/accounts/"+currency, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := s.client.sendRequest(req)
+func (s *AccountService) Account(ctx context.Context, currency string) (*Account, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v2/members/accounts/"+currency, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,13 +65,8 @@ func (s *AccountService) Account(currency string) (*Account, error) {
 	return &account, nil
 }
 
-func (s *AccountService) Accounts() ([]Account, error) {
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/members/accounts", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := s.client.sendRequest(req)
+func (s *AccountService) Accounts(ctx context.Context) ([]Account, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v2/members/accounts", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -90,14 +80,38 @@ func (s *AccountService) Accounts() ([]Account, error) {
 	return accounts, nil
 }
 
-// Me returns the current user info by the current used MAX key and secret
-func (s *AccountService) Me() (*UserInfo, error) {
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/members/me", nil)
+// VipLevelSettings is the trading volume requirement and the fee rates of a vip level
+type VipLevelSettings struct {
+	Level                int     `json:"level"`
+	MinimumTradingVolume float64 `json:"minimum_trading_volume"`
+	MinimumStakingVolume float64 `json:"minimum_staking_volume"`
+	MakerFee             float64 `json:"maker_fee"`
+	TakerFee             float64 `json:"taker_fee"`
+}
+
+type VipLevel struct {
+	Current VipLevelSettings `json:"current_vip_level"`
+	Next    VipLevelSettings `json:"next_vip_level"`
+}
+
+// VipLevel returns the current vip level and the next vip level of the member, the fee rates apply to all markets
+func (s *AccountService) VipLevel(ctx context.Context) (*VipLevel, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v2/members/vip_level", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := s.client.sendRequest(req)
+	var vipLevel VipLevel
+	if err := response.DecodeJSON(&vipLevel); err != nil {
+		return nil, err
+	}
+
+	return &vipLevel, nil
+}
+
+// Me returns the current user info by the current used MAX key and secret
+func (s *AccountService) Me(ctx context.Context) (*UserInfo, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v2/members/me", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -164,12 +178,7 @@ func (r *GetDepositHistoryRequest) To(to int64) *GetDepositHistoryRequest {
 }
 
 func (r *GetDepositHistoryRequest) Do(ctx context.Context) (deposits []Deposit, err error) {
-	req, err := r.client.newAuthenticatedRequest("GET", "v2/deposits", &r.params)
-	if err != nil {
-		return deposits, err
-	}
-
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "GET", "v2/deposits", &r.params)
 	if err != nil {
 		return deposits, err
 	}
@@ -250,12 +259,7 @@ func (r *GetWithdrawHistoryRequest) To(to int64) *GetWithdrawHistoryRequest {
 }
 
 func (r *GetWithdrawHistoryRequest) Do(ctx context.Context) (withdraws []Withdraw, err error) {
-	req, err := r.client.newAuthenticatedRequest("GET", "v2/withdrawals", &r.params)
-	if err != nil {
-		return withdraws, err
-	}
-
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "GET", "v2/withdrawals", &r.params)
 	if err != nil {
 		return withdraws, err
 	}
@@ -1,11 +1,29 @@
 package max
 
+import "time"
+
+// The private channels of the auth filters, the margin wallet (m-wallet) channels push the events of the margin account
+const (
+	AuthFilterOrder   = "order"
+	AuthFilterTrade   = "trade"
+	AuthFilterAccount = "account"
+
+	AuthFilterMarginOrder   = "mwallet_order"
+	AuthFilterMarginTrade   = "mwallet_trade"
+	AuthFilterMarginAccount = "mwallet_account"
+)
+
+var SpotAuthFilters = []string{AuthFilterOrder, AuthFilterTrade, AuthFilterAccount}
+
+var MarginAuthFilters = []string{AuthFilterMarginOrder, AuthFilterMarginTrade, AuthFilterMarginAccount}
+
 type AuthMessage struct {
-	Action    string `json:"action"`
-	APIKey    string `json:"apiKey"`
-	Nonce     int64  `json:"nonce"`
-	Signature string `json:"signature"`
-	ID        string `json:"id"`
+	Action    string   `json:"action"`
+	APIKey    string   `json:"apiKey"`
+	Nonce     int64    `json:"nonce"`
+	Signature string   `json:"signature"`
+	ID        string   `json:"id"`
+	Filters   []string `json:"filters,omitempty"`
 }
 
 type AuthEvent struct {
@@ -13,3 +31,7 @@ type AuthEvent struct {
 	ID        string
 	Timestamp int64
 }
+
+func (e AuthEvent) Time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond))
+}
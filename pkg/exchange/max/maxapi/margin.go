@@ -0,0 +1,137 @@
+package max
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// MarginService is the service of the MAX margin wallet (the v3 wallet/m endpoints),
+// the margin orders are submitted by CreateOrderRequest.Margin()
+type MarginService struct {
+	client *RestClient
+}
+
+// MarginAccount is the balance of a currency in the margin wallet,
+// Principal is the borrowed amount and Interest is the accrued interest of the loan.
+type MarginAccount struct {
+	Currency  string `json:"currency"`
+	Balance   string `json:"balance"`
+	Locked    string `json:"locked"`
+	Principal string `json:"principal"`
+	Interest  string `json:"interest"`
+}
+
+func (s *MarginService) Accounts(ctx context.Context) ([]MarginAccount, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v3/wallet/m/accounts", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []MarginAccount
+	if err := response.DecodeJSON(&accounts); err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// BorrowingLimits returns the max borrowable amount of the currencies
+func (s *MarginService) BorrowingLimits(ctx context.Context) (map[string]string, error) {
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v3/wallet/m/limits", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var limits = make(map[string]string)
+	if err := response.DecodeJSON(&limits); err != nil {
+		return nil, err
+	}
+
+	return limits, nil
+}
+
+type GetMarginOpenOrdersRequestParams struct {
+	*PrivateRequestParams
+
+	Market string `json:"market"`
+}
+
+// OpenOrders returns the open orders of the market in the margin wallet
+func (s *MarginService) OpenOrders(ctx context.Context, market string) ([]Order, error) {
+	params := GetMarginOpenOrdersRequestParams{Market: market}
+	response, err := s.client.sendAuthenticatedRequest(ctx, "GET", "v3/wallet/m/orders/open", &params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []Order
+	if err := response.DecodeJSON(&orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// MarginLoanRecord is the record of a loan or a repayment
+type MarginLoanRecord struct {
+	SN        string `json:"sn"`
+	Currency  string `json:"currency"`
+	Amount    string `json:"amount"`
+	State     string `json:"state"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type MarginLoanRequestParams struct {
+	*PrivateRequestParams
+
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+// MarginLoanRequest borrows or repays the currency, depending on the endpoint it's created with
+type MarginLoanRequest struct {
+	client *RestClient
+	path   string
+	params MarginLoanRequestParams
+}
+
+func (r *MarginLoanRequest) Currency(currency string) *MarginLoanRequest {
+	r.params.Currency = currency
+	return r
+}
+
+func (r *MarginLoanRequest) Amount(amount string) *MarginLoanRequest {
+	r.params.Amount = amount
+	return r
+}
+
+func (r *MarginLoanRequest) Do(ctx context.Context) (*MarginLoanRecord, error) {
+	if len(r.params.Currency) == 0 {
+		return nil, errors.New("currency is required")
+	}
+
+	if len(r.params.Amount) == 0 {
+		return nil, errors.New("amount is required")
+	}
+
+	response, err := r.client.sendAuthenticatedRequest(ctx, "POST", r.path, &r.params)
+	if err != nil {
+		return nil, err
+	}
+
+	var record MarginLoanRecord
+	if err := response.DecodeJSON(&record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+func (s *MarginService) NewLoanRequest() *MarginLoanRequest {
+	return &MarginLoanRequest{client: s.client, path: "v3/wallet/m/loan"}
+}
+
+func (s *MarginService) NewRepayRequest() *MarginLoanRequest {
+	return &MarginLoanRequest{client: s.client, path: "v3/wallet/m/repayment"}
+}
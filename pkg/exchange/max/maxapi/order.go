@@ -35,6 +35,12 @@ const (
 	OrderTypeLimit      = OrderType("limit")
 	OrderTypeStopLimit  = OrderType("stop_limit")
 	OrderTypeStopMarket = OrderType("stop_market")
+
+	// OrderTypePostOnly is the limit order that is canceled if it would take the liquidity
+	OrderTypePostOnly = OrderType("post_only")
+
+	// OrderTypeIOCLimit is the immediate-or-cancel limit order
+	OrderTypeIOCLimit = OrderType("ioc_limit")
 )
 
 type QueryOrderOptions struct {
@@ -54,6 +60,7 @@ type Order struct {
 	Side            string     `json:"side" db:"side"`
 	OrderType       OrderType  `json:"ord_type,omitempty" db:"order_type"`
 	Price           string     `json:"price" db:"price"`
+	StopPrice       string     `json:"stop_price,omitempty" db:"stop_price"`
 	AveragePrice    string     `json:"avg_price,omitempty" db:"average_price"`
 	State           OrderState `json:"state,omitempty" db:"state"`
 	Market          string     `json:"market,omitempty" db:"market"`
@@ -68,158 +75,76 @@ type Order struct {
 	InsertedAt      time.Time  `json:"-" db:"inserted_at"`
 }
 
-// Open returns open orders
-func (s *OrderService) Closed(market string, options QueryOrderOptions) ([]Order, error) {
-	payload := map[string]interface{}{
-		"market":     market,
-		"state":      []OrderState{OrderStateFinalizing, OrderStateDone, OrderStateCancel, OrderStateFailed},
-		"order_by":   "desc",
-		"pagination": false,
-	}
+// Closed returns the closed orders of the market
+func (s *OrderService) Closed(ctx context.Context, market string, options QueryOrderOptions) ([]Order, error) {
+	req := s.NewGetOrdersRequest().
+		Market(market).
+		State(OrderStateFinalizing, OrderStateDone, OrderStateCancel, OrderStateFailed).
+		OrderBy("desc").
+		Pagination(false)
 
 	if options.GroupID > 0 {
-		payload["group_id"] = options.GroupID
+		req.GroupID(int64(options.GroupID))
 	}
 	if options.Offset > 0 {
-		payload["offset"] = options.Offset
+		req.Offset(options.Offset)
 	}
 	if options.Limit > 0 {
-		payload["limit"] = options.Limit
-	}
-
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/orders", payload)
-	if err != nil {
-		return nil, err
+		req.Limit(options.Limit)
 	}
 
-	response, err := s.client.sendRequest(req)
-	if err != nil {
-		return nil, err
-	}
-
-	var orders []Order
-	if err := response.DecodeJSON(&orders); err != nil {
-		return nil, err
-	}
-
-	return orders, nil
+	return req.Do(ctx)
 }
 
 // Open returns open orders
-func (s *OrderService) Open(market string, options QueryOrderOptions) ([]Order, error) {
-	payload := map[string]interface{}{
-		"market": market,
-		// "state":    []OrderState{OrderStateWait, OrderStateConvert},
-		"order_by":   "desc",
-		"pagination": false,
-	}
+func (s *OrderService) Open(ctx context.Context, market string, options QueryOrderOptions) ([]Order, error) {
+	req := s.NewGetOrdersRequest().
+		Market(market).
+		OrderBy("desc").
+		Pagination(false)
 
 	if options.GroupID > 0 {
-		payload["group_id"] = options.GroupID
-	}
-
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/orders", payload)
-	if err != nil {
-		return nil, err
+		req.GroupID(int64(options.GroupID))
 	}
 
-	response, err := s.client.sendRequest(req)
-	if err != nil {
-		return nil, err
-	}
-
-	var orders []Order
-	if err := response.DecodeJSON(&orders); err != nil {
-		return nil, err
-	}
-
-	return orders, nil
+	return req.Do(ctx)
 }
 
 // All returns all orders for the authenticated account.
-func (s *OrderService) All(market string, limit, page int, states ...OrderState) ([]Order, error) {
-	payload := map[string]interface{}{
-		"market":   market,
-		"limit":    limit,
-		"page":     page,
-		"state":    states,
-		"order_by": "desc",
-	}
-
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/orders", payload)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := s.client.sendRequest(req)
-	if err != nil {
-		return nil, err
-	}
-
-	var orders []Order
-	if err := response.DecodeJSON(&orders); err != nil {
-		return nil, err
-	}
-
-	return orders, nil
+func (s *OrderService) All(ctx context.Context, market string, limit, page int, states ...OrderState) ([]Order, error) {
+	return s.NewGetOrdersRequest().
+		Market(market).
+		Limit(limit).
+		Page(page).
+		State(states...).
+		OrderBy("desc").
+		Do(ctx)
 }
 
 // CancelAll active orders for the authenticated account.
-func (s *OrderService) CancelAll(side string, market string) error {
-	payload := map[string]interface{}{}
+func (s *OrderService) CancelAll(ctx context.Context, side string, market string) error {
+	req := s.NewOrderCancelAllRequest()
 	if side == "buy" || side == "sell" {
-		payload["side"] = side
+		req.Side(side)
 	}
 	if market != "all" {
-		payload["market"] = market
-	}
-
-	req, err := s.client.newAuthenticatedRequest("POST", "v2/orders/clear", payload)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.client.sendRequest(req)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// Options carry the option fields for REST API
-type Options map[string]interface{}
-
-// Create a new order.
-func (s *OrderService) Create(market string, side string, volume float64, price float64, orderType string, options Options) (*Order, error) {
-	options["market"] = market
-	options["volume"] = strconv.FormatFloat(volume, 'f', -1, 64)
-	options["price"] = strconv.FormatFloat(price, 'f', -1, 64)
-	options["side"] = side
-	options["ord_type"] = orderType
-	response, err := s.client.sendAuthenticatedRequest("POST", "v2/orders", options)
-	if err != nil {
-		return nil, err
-	}
-
-	var order = Order{}
-	if err := response.DecodeJSON(&order); err != nil {
-		return nil, err
+		req.Market(market)
 	}
 
-	return &order, nil
+	_, err := req.Do(ctx)
+	return err
 }
 
 // Create multiple order in a single request
-func (s *OrderService) CreateMulti(market string, orders []Order) (*MultiOrderResponse, error) {
+func (s *OrderService) CreateMulti(ctx context.Context, market string, orders []Order) (*MultiOrderResponse, error) {
 	req := s.NewCreateMultiOrderRequest()
 	req.Market(market)
 	req.AddOrders(orders...)
-	return req.Do(context.Background())
+	return req.Do(ctx)
 }
 
 // Cancel the order with id `orderID`.
-func (s *OrderService) Cancel(orderID uint64, clientOrderID string) error {
+func (s *OrderService) Cancel(ctx context.Context, orderID uint64, clientOrderID string) error {
 	req := s.NewOrderCancelRequest()
 
 	if orderID > 0 {
@@ -228,7 +153,32 @@ func (s *OrderService) Cancel(orderID uint64, clientOrderID string) error {
 		req.ClientOrderID(clientOrderID)
 	}
 
-	return req.Do(context.Background())
+	_, err := req.Do(ctx)
+	return err
+}
+
+// CancelByClientOrderID cancels the order of the client order id, the canceled order is returned.
+func (s *OrderService) CancelByClientOrderID(ctx context.Context, clientOrderID string) (*Order, error) {
+	if len(clientOrderID) == 0 {
+		return nil, errors.New("client order id is required")
+	}
+
+	return s.NewOrderCancelRequest().ClientOrderID(clientOrderID).Do(ctx)
+}
+
+// CancelByClientOrderIDs cancels the orders of the client order ids one by one since MAX doesn't provide the batch endpoint,
+// the orders that are canceled before the error are returned with the error.
+func (s *OrderService) CancelByClientOrderIDs(ctx context.Context, clientOrderIDs ...string) (orders []Order, err error) {
+	for _, clientOrderID := range clientOrderIDs {
+		order, err := s.CancelByClientOrderID(ctx, clientOrderID)
+		if err != nil {
+			return orders, errors.Wrapf(err, "failed to cancel order %s", clientOrderID)
+		}
+
+		orders = append(orders, *order)
+	}
+
+	return orders, nil
 }
 
 type OrderCancelAllRequestParams struct {
@@ -236,7 +186,7 @@ type OrderCancelAllRequestParams struct {
 
 	Side    string `json:"side,omitempty"`
 	Market  string `json:"market,omitempty"`
-	GroupID int64 `json:"groupID,omitempty"`
+	GroupID int64  `json:"group_id,omitempty"`
 }
 
 type OrderCancelAllRequest struct {
@@ -260,13 +210,22 @@ func (r *OrderCancelAllRequest) GroupID(groupID int64) *OrderCancelAllRequest {
 	return r
 }
 
+// Validate checks the side, the empty side cancels the orders of both sides
+func (r *OrderCancelAllRequest) Validate() error {
+	switch r.params.Side {
+	case "", "buy", "sell":
+		return nil
+	}
+
+	return errors.Errorf("invalid order side %q", r.params.Side)
+}
+
 func (r *OrderCancelAllRequest) Do(ctx context.Context) (orders []Order, err error) {
-	req, err := r.client.newAuthenticatedRequest("POST", "v2/orders/clear", &r.params)
-	if err != nil {
+	if err = r.Validate(); err != nil {
 		return
 	}
 
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "POST", "v2/orders/clear", &r.params)
 	if err != nil {
 		return
 	}
@@ -279,6 +238,30 @@ func (s *OrderService) NewOrderCancelAllRequest() *OrderCancelAllRequest {
 	return &OrderCancelAllRequest{client: s.client}
 }
 
+// CancelByGroupID cancels all the active orders of the group in one request, the canceled orders are returned.
+func (s *OrderService) CancelByGroupID(ctx context.Context, groupID int64) ([]Order, error) {
+	if groupID <= 0 {
+		return nil, errors.New("group id is required")
+	}
+
+	return s.NewOrderCancelAllRequest().GroupID(groupID).Do(ctx)
+}
+
+// QueryByGroupID returns both the active and the closed orders of the group in the market.
+func (s *OrderService) QueryByGroupID(ctx context.Context, market string, groupID int64) ([]Order, error) {
+	if groupID <= 0 {
+		return nil, errors.New("group id is required")
+	}
+
+	return s.NewGetOrdersRequest().
+		Market(market).
+		GroupID(groupID).
+		State(OrderStateWait, OrderStateConvert, OrderStateFinalizing, OrderStateDone, OrderStateCancel, OrderStateFailed).
+		OrderBy("desc").
+		Pagination(false).
+		Do(ctx)
+}
+
 type OrderCancelRequestParams struct {
 	*PrivateRequestParams
 
@@ -302,48 +285,86 @@ func (r *OrderCancelRequest) ClientOrderID(id string) *OrderCancelRequest {
 	return r
 }
 
-func (r *OrderCancelRequest) Do(ctx context.Context) error {
-	req, err := r.client.newAuthenticatedRequest("POST", "v2/order/delete", &r.params)
-	if err != nil {
-		return err
+// Validate checks that either the order id or the client order id is set
+func (r *OrderCancelRequest) Validate() error {
+	if r.params.ID == 0 && len(r.params.ClientOrderID) == 0 {
+		return errors.New("order id or client order id is required")
+	}
+
+	return nil
+}
+
+// Do cancels the order and returns the canceled order
+func (r *OrderCancelRequest) Do(ctx context.Context) (*Order, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
 	}
 
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "POST", "v2/order/delete", &r.params)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var order = Order{}
 	if err := response.DecodeJSON(&order); err != nil {
-		return err
+		return nil, err
 	}
 
-	return err
+	return &order, nil
 }
 
 func (s *OrderService) NewOrderCancelRequest() *OrderCancelRequest {
 	return &OrderCancelRequest{client: s.client}
 }
 
-// Status retrieves the given order from the API.
-func (s *OrderService) Get(orderID uint64) (*Order, error) {
-	payload := map[string]interface{}{
-		"id": orderID,
+// Get retrieves the given order from the API.
+func (s *OrderService) Get(ctx context.Context, orderID uint64) (*Order, error) {
+	return s.NewGetOrderRequest().ID(orderID).Do(ctx)
+}
+
+type GetOrderRequestParams struct {
+	*PrivateRequestParams
+
+	ID            uint64 `json:"id,omitempty"`
+	ClientOrderID string `json:"client_oid,omitempty"`
+}
+
+type GetOrderRequest struct {
+	client *RestClient
+
+	params GetOrderRequestParams
+}
+
+func (r *GetOrderRequest) ID(id uint64) *GetOrderRequest {
+	r.params.ID = id
+	return r
+}
+
+func (r *GetOrderRequest) ClientOrderID(id string) *GetOrderRequest {
+	r.params.ClientOrderID = id
+	return r
+}
+
+// Validate checks that either the order id or the client order id is set
+func (r *GetOrderRequest) Validate() error {
+	if r.params.ID == 0 && len(r.params.ClientOrderID) == 0 {
+		return errors.New("order id or client order id is required")
 	}
 
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/order", payload)
+	return nil
+}
 
-	if err != nil {
-		return &Order{}, err
+func (r *GetOrderRequest) Do(ctx context.Context) (*Order, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
 	}
 
-	response, err := s.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "GET", "v2/order", &r.params)
 	if err != nil {
 		return nil, err
 	}
 
 	var order = Order{}
-
 	if err := response.DecodeJSON(&order); err != nil {
 		return nil, err
 	}
@@ -351,6 +372,105 @@ func (s *OrderService) Get(orderID uint64) (*Order, error) {
 	return &order, nil
 }
 
+func (s *OrderService) NewGetOrderRequest() *GetOrderRequest {
+	return &GetOrderRequest{client: s.client}
+}
+
+type GetOrdersRequestParams struct {
+	*PrivateRequestParams
+
+	Market  string       `json:"market"`
+	State   []OrderState `json:"state,omitempty"`
+	OrderBy string       `json:"order_by,omitempty"`
+	GroupID int64        `json:"group_id,omitempty"`
+	Offset  int          `json:"offset,omitempty"`
+	Limit   int          `json:"limit,omitempty"`
+	Page    int          `json:"page,omitempty"`
+
+	// Pagination is not sent when it's not set, the server paginates the result by default
+	Pagination *bool `json:"pagination,omitempty"`
+}
+
+type GetOrdersRequest struct {
+	client *RestClient
+
+	params GetOrdersRequestParams
+}
+
+func (r *GetOrdersRequest) Market(market string) *GetOrdersRequest {
+	r.params.Market = market
+	return r
+}
+
+// State filters the orders by the states, the active orders (wait and convert) are returned if no state is given
+func (r *GetOrdersRequest) State(states ...OrderState) *GetOrdersRequest {
+	r.params.State = states
+	return r
+}
+
+// OrderBy sets the sorting order by the creation time, could be "asc" or "desc"
+func (r *GetOrdersRequest) OrderBy(orderBy string) *GetOrdersRequest {
+	r.params.OrderBy = orderBy
+	return r
+}
+
+func (r *GetOrdersRequest) GroupID(groupID int64) *GetOrdersRequest {
+	r.params.GroupID = groupID
+	return r
+}
+
+func (r *GetOrdersRequest) Offset(offset int) *GetOrdersRequest {
+	r.params.Offset = offset
+	return r
+}
+
+func (r *GetOrdersRequest) Limit(limit int) *GetOrdersRequest {
+	r.params.Limit = limit
+	return r
+}
+
+func (r *GetOrdersRequest) Page(page int) *GetOrdersRequest {
+	r.params.Page = page
+	return r
+}
+
+func (r *GetOrdersRequest) Pagination(p bool) *GetOrdersRequest {
+	r.params.Pagination = &p
+	return r
+}
+
+func (r *GetOrdersRequest) Validate() error {
+	if len(r.params.Market) == 0 {
+		return errors.New("market is required")
+	}
+
+	switch r.params.OrderBy {
+	case "", "asc", "desc":
+	default:
+		return errors.Errorf("invalid order_by %q", r.params.OrderBy)
+	}
+
+	return nil
+}
+
+func (r *GetOrdersRequest) Do(ctx context.Context) (orders []Order, err error) {
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	response, err := r.client.sendAuthenticatedRequest(ctx, "GET", "v2/orders", &r.params)
+	if err != nil {
+		return
+	}
+
+	err = response.DecodeJSON(&orders)
+	return
+}
+
+func (s *OrderService) NewGetOrdersRequest() *GetOrdersRequest {
+	return &GetOrdersRequest{client: s.client}
+}
+
 type MultiOrderRequestParams struct {
 	*PrivateRequestParams
 
@@ -379,13 +499,24 @@ func (r *CreateMultiOrderRequest) AddOrders(orders ...Order) *CreateMultiOrderRe
 	return r
 }
 
+func (r *CreateMultiOrderRequest) Validate() error {
+	if len(r.params.Market) == 0 {
+		return errors.New("market is required")
+	}
+
+	if len(r.params.Orders) == 0 {
+		return errors.New("no order to create")
+	}
+
+	return nil
+}
+
 func (r *CreateMultiOrderRequest) Do(ctx context.Context) (multiOrderResponse *MultiOrderResponse, err error) {
-	req, err := r.client.newAuthenticatedRequest("POST", "v2/orders/multi/onebyone", r.params)
-	if err != nil {
-		return multiOrderResponse, errors.Wrapf(err, "order create error")
+	if err = r.Validate(); err != nil {
+		return
 	}
 
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "POST", "v2/orders/multi/onebyone", &r.params)
 	if err != nil {
 		return multiOrderResponse, err
 	}
@@ -419,6 +550,15 @@ type CreateOrderRequest struct {
 	client *RestClient
 
 	params CreateOrderRequestParams
+
+	// margin submits the order to the margin wallet
+	margin bool
+}
+
+// Margin submits the order to the margin wallet through the v3 api
+func (r *CreateOrderRequest) Margin() *CreateOrderRequest {
+	r.margin = true
+	return r
 }
 
 func (r *CreateOrderRequest) Market(market string) *CreateOrderRequest {
@@ -456,13 +596,62 @@ func (r *CreateOrderRequest) ClientOrderID(clientOrderID string) *CreateOrderReq
 	return r
 }
 
+// GroupID sets the order group, the orders of the same group can be canceled or queried together
+func (r *CreateOrderRequest) GroupID(groupID int64) *CreateOrderRequest {
+	r.params.GroupID = strconv.FormatInt(groupID, 10)
+	return r
+}
+
+// Validate checks the required fields of the order type, the stop orders (stop_limit and stop_market)
+// require the stop price, and the price is not accepted by the stop market order.
+func (r *CreateOrderRequest) Validate() error {
+	if len(r.params.Market) == 0 {
+		return errors.New("market is required")
+	}
+
+	if len(r.params.Volume) == 0 {
+		return errors.New("volume is required")
+	}
+
+	if r.params.Side != "buy" && r.params.Side != "sell" {
+		return errors.Errorf("invalid order side %q", r.params.Side)
+	}
+
+	switch OrderType(r.params.OrderType) {
+	case OrderTypeMarket:
+	case OrderTypeLimit, OrderTypePostOnly, OrderTypeIOCLimit:
+		if len(r.params.Price) == 0 {
+			return errors.Errorf("price is required for the %s order", r.params.OrderType)
+		}
+	case OrderTypeStopLimit:
+		if len(r.params.Price) == 0 || len(r.params.StopPrice) == 0 {
+			return errors.New("price and stop price are required for the stop_limit order")
+		}
+	case OrderTypeStopMarket:
+		if len(r.params.StopPrice) == 0 {
+			return errors.New("stop price is required for the stop_market order")
+		}
+		if len(r.params.Price) > 0 {
+			return errors.New("price is not accepted by the stop_market order")
+		}
+	default:
+		return errors.Errorf("invalid order type %q", r.params.OrderType)
+	}
+
+	return nil
+}
+
 func (r *CreateOrderRequest) Do(ctx context.Context) (order *Order, err error) {
-	req, err := r.client.newAuthenticatedRequest("POST", "v2/orders", &r.params)
-	if err != nil {
-		return order, errors.Wrapf(err, "order create error")
+	if err = r.Validate(); err != nil {
+		return
+	}
+
+	path := "v2/orders"
+	if r.margin {
+		path = "v3/wallet/m/order"
 	}
 
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "POST", path, &r.params)
 	if err != nil {
 		return order, err
 	}
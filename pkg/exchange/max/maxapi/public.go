@@ -1,8 +1,8 @@
 package max
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
 	"net/url"
 	"strconv"
 	"strings"
@@ -43,9 +43,9 @@ type Ticker struct {
 	VolumeInBTC string `json:"vol_in_btc"`
 }
 
-func (s *PublicService) Timestamp() (serverTimestamp int64, err error) {
+func (s *PublicService) Timestamp(ctx context.Context) (serverTimestamp int64, err error) {
 	// sync timestamp with server
-	req, err := s.client.newRequest("GET", "v2/timestamp", nil, nil)
+	req, err := s.client.newRequest(ctx, "GET", "v2/timestamp", nil, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -63,8 +63,8 @@ func (s *PublicService) Timestamp() (serverTimestamp int64, err error) {
 	return serverTimestamp, nil
 }
 
-func (s *PublicService) Markets() ([]Market, error) {
-	req, err := s.client.newRequest("GET", "v2/markets", url.Values{}, nil)
+func (s *PublicService) Markets(ctx context.Context) ([]Market, error) {
+	req, err := s.client.newRequest(ctx, "GET", "v2/markets", url.Values{}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -82,9 +82,9 @@ func (s *PublicService) Markets() ([]Market, error) {
 	return m, nil
 }
 
-func (s *PublicService) Tickers() (map[string]Ticker, error) {
+func (s *PublicService) Tickers(ctx context.Context) (map[string]Ticker, error) {
 	var endPoint = "v2/tickers"
-	req, err := s.client.newRequest("GET", endPoint, url.Values{}, nil)
+	req, err := s.client.newRequest(ctx, "GET", endPoint, url.Values{}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +113,9 @@ func (s *PublicService) Tickers() (map[string]Ticker, error) {
 	return tickers, nil
 }
 
-func (s *PublicService) Ticker(market string) (*Ticker, error) {
+func (s *PublicService) Ticker(ctx context.Context, market string) (*Ticker, error) {
 	var endPoint = "v2/tickers/" + market
-	req, err := s.client.newRequest("GET", endPoint, url.Values{}, nil)
+	req, err := s.client.newRequest(ctx, "GET", endPoint, url.Values{}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +230,7 @@ func (k KLine) KLine() types.KLine {
 	}
 }
 
-func (s *PublicService) KLines(symbol string, resolution string, start time.Time, limit int) ([]KLine, error) {
+func (s *PublicService) KLines(ctx context.Context, symbol string, resolution string, start time.Time, limit int) ([]KLine, error) {
 	queries := url.Values{}
 	queries.Set("market", symbol)
 
@@ -249,28 +249,17 @@ func (s *PublicService) KLines(symbol string, resolution string, start time.Time
 		queries.Set("limit", strconv.Itoa(limit)) // default to 30, max limit = 10,000
 	}
 
-	req, err := s.client.newRequest("GET", fmt.Sprintf("%s/k", s.client.BaseURL), queries, nil)
+	req, err := s.client.newRequest(ctx, "GET", fmt.Sprintf("%s/k", s.client.BaseURL), queries, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request build error: %s", err.Error())
 	}
 
-	resp, err := s.client.Do(req)
+	response, err := s.client.sendRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %s", err.Error())
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			logger.WithError(err).Error("failed to close resp body")
-		}
-	}()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return parseKLines(body, symbol, resolution, interval)
+	return parseKLines(response.Body, symbol, resolution, interval)
 }
 
 func parseKLines(payload []byte, symbol, resolution string, interval Interval) (klines []KLine, err error) {
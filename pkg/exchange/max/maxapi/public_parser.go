@@ -18,7 +18,7 @@ const Buy = 1
 const Sell = -1
 
 // ParseMessage accepts the raw messages from max public websocket channels and parses them into market data
-// Return types: *BookEvent, *PublicTradeEvent, *SubscriptionEvent, *ErrorEvent
+// Return types: *BookEvent, *PublicTradeEvent, *KLineEvent, *SubscriptionEvent, *AuthEvent, *ErrorEvent and the user events
 func ParseMessage(payload []byte) (interface{}, error) {
 	parser := fastjson.Parser{}
 	val, err := parser.ParseBytes(payload)
@@ -42,7 +42,7 @@ func ParseMessage(payload []byte) (interface{}, error) {
 	eventType := string(val.GetStringBytes("e"))
 	switch eventType {
 	case "authenticated":
-		return nil, nil
+		return parseAuthEvent(val), nil
 	case "error":
 		return parseErrorEvent(val)
 	case "subscribed", "unsubscribed":
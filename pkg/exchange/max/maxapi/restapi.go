@@ -2,6 +2,7 @@ package max
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -15,11 +16,13 @@ import (
 	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -29,8 +32,26 @@ const (
 	UserAgent = "bbgo/1.0"
 
 	defaultHTTPTimeout = time.Second * 15
+
+	defaultMaxRetries = 3
+
+	defaultRetryDelay = 500 * time.Millisecond
 )
 
+// endpointWeights is the rate limit weight of the endpoints, the bulk endpoints consume more tokens
+// from the rate limiter, the endpoints not listed here have the weight 1.
+var endpointWeights = map[string]int{
+	"v2/orders/clear":          5,
+	"v2/orders/multi/onebyone": 5,
+	"v2/trades/my":             2,
+}
+
+// idempotentEndpoints are the non-GET endpoints that are safe to retry on the server errors
+var idempotentEndpoints = map[string]bool{
+	"v2/order/delete": true,
+	"v2/orders/clear": true,
+}
+
 var logger = log.WithField("exchange", "max")
 
 var htmlTagPattern = regexp.MustCompile("<[/]?[a-zA-Z-]+.*?>")
@@ -86,10 +107,23 @@ type RestClient struct {
 	APIKey    string
 	APISecret string
 
+	// RequestTimeout is the timeout of each request, it's applied on top of the deadline of the given context
+	RequestTimeout time.Duration
+
+	// RateLimiter throttles the requests by the endpoint weight, set it to nil to disable the rate limit
+	RateLimiter *rate.Limiter
+
+	// MaxRetries is the max number of the retries when the request is rate limited or failed with the server error
+	MaxRetries int
+
+	// RetryDelay is the initial backoff delay of the retries, the delay is doubled on every retry
+	RetryDelay time.Duration
+
 	AccountService *AccountService
 	PublicService  *PublicService
 	TradeService   *TradeService
 	OrderService   *OrderService
+	MarginService  *MarginService
 	// OrderBookService *OrderBookService
 	// MaxTokenService  *MaxTokenService
 	// MaxKLineService  *KLineService
@@ -103,14 +137,21 @@ func NewRestClientWithHttpClient(baseURL string, httpClient *http.Client) *RestC
 	}
 
 	var client = &RestClient{
-		client:  httpClient,
-		BaseURL: u,
+		client:         httpClient,
+		BaseURL:        u,
+		RequestTimeout: defaultHTTPTimeout,
+
+		// kept below the api limit so that the bulk operations don't hit the 429 responses
+		RateLimiter: rate.NewLimiter(rate.Limit(10), 20),
+		MaxRetries:  defaultMaxRetries,
+		RetryDelay:  defaultRetryDelay,
 	}
 
 	client.AccountService = &AccountService{client}
 	client.TradeService = &TradeService{client}
 	client.PublicService = &PublicService{client}
 	client.OrderService = &OrderService{client}
+	client.MarginService = &MarginService{client}
 	// client.OrderBookService = &OrderBookService{client}
 	// client.MaxTokenService = &MaxTokenService{client}
 	// client.MaxKLineService = &KLineService{client}
@@ -135,7 +176,7 @@ func (c *RestClient) Auth(key string, secret string) *RestClient {
 func (c *RestClient) initNonce() {
 	var clientTime = time.Now()
 	var err error
-	serverTimestamp, err = c.PublicService.Timestamp()
+	serverTimestamp, err = c.PublicService.Timestamp(context.Background())
 	if err != nil {
 		logger.WithError(err).Panic("failed to sync timestamp with Max")
 	}
@@ -153,7 +194,7 @@ func (c *RestClient) getNonce() int64 {
 }
 
 // NewRequest create new API request. Relative url can be provided in refURL.
-func (c *RestClient) newRequest(method string, refURL string, params url.Values, body []byte) (*http.Request, error) {
+func (c *RestClient) newRequest(ctx context.Context, method string, refURL string, params url.Values, body []byte) (*http.Request, error) {
 	rel, err := url.Parse(refURL)
 	if err != nil {
 		return nil, err
@@ -164,7 +205,7 @@ func (c *RestClient) newRequest(method string, refURL string, params url.Values,
 	var req *http.Request
 	u := c.BaseURL.ResolveReference(rel)
 
-	req, err = http.NewRequest(method, u.String(), bytes.NewReader(body))
+	req, err = http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +215,7 @@ func (c *RestClient) newRequest(method string, refURL string, params url.Values,
 }
 
 // newAuthenticatedRequest creates new http request for authenticated routes.
-func (c *RestClient) newAuthenticatedRequest(m string, refURL string, data interface{}) (*http.Request, error) {
+func (c *RestClient) newAuthenticatedRequest(ctx context.Context, m string, refURL string, data interface{}) (*http.Request, error) {
 	rel, err := url.Parse(refURL)
 	if err != nil {
 		return nil, err
@@ -185,23 +226,10 @@ func (c *RestClient) newAuthenticatedRequest(m string, refURL string, data inter
 	switch d := data.(type) {
 
 	case nil:
-		payload := map[string]interface{}{
-			"nonce": c.getNonce(),
-			"path":  c.BaseURL.ResolveReference(rel).Path,
-		}
-		p, err = json.Marshal(payload)
-
-	case map[string]interface{}:
-		payload := map[string]interface{}{
-			"nonce": c.getNonce(),
-			"path":  c.BaseURL.ResolveReference(rel).Path,
-		}
-
-		for k, v := range d {
-			payload[k] = v
-		}
-
-		p, err = json.Marshal(payload)
+		p, err = json.Marshal(PrivateRequestParams{
+			Nonce: c.getNonce(),
+			Path:  c.BaseURL.ResolveReference(rel).Path,
+		})
 
 	default:
 		params, err := getPrivateRequestParamsObject(data)
@@ -227,7 +255,7 @@ func (c *RestClient) newAuthenticatedRequest(m string, refURL string, data inter
 		return nil, errors.New("empty api secret")
 	}
 
-	req, err := c.newRequest(m, refURL, nil, p)
+	req, err := c.newRequest(ctx, m, refURL, nil, p)
 	if err != nil {
 		return nil, err
 	}
@@ -289,8 +317,87 @@ func (c *RestClient) Do(req *http.Request) (resp *http.Response, err error) {
 	return c.client.Do(req)
 }
 
-// sendRequest sends the request to the API server and handle the response
+// sendRequest sends the request to the API server and handle the response,
+// the request is retried with the same body when it's rate limited or failed with the server error.
 func (c *RestClient) sendRequest(req *http.Request) (*Response, error) {
+	var sent = false
+	return c.sendWithRetry(req.Context(), func() (*http.Request, error) {
+		if !sent {
+			sent = true
+			return req, nil
+		}
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			retry.Body = body
+		}
+
+		return retry, nil
+	})
+}
+
+// sendAuthenticatedRequest builds the authenticated request and sends it to the API server,
+// the request is re-built and re-signed on every retry since the nonce can not be reused.
+func (c *RestClient) sendAuthenticatedRequest(ctx context.Context, m string, refURL string, data interface{}) (*Response, error) {
+	return c.sendWithRetry(ctx, func() (*http.Request, error) {
+		return c.newAuthenticatedRequest(ctx, m, refURL, data)
+	})
+}
+
+// sendWithRetry sends the request built by newRequest, and retries with the exponential backoff when the request
+// is rate limited (429). The server errors (5xx) and the connection errors are only retried for the idempotent requests,
+// because retrying the order creation may submit the duplicated orders.
+func (c *RestClient) sendWithRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*Response, error) {
+	var delay = c.RetryDelay
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := c.send(req)
+		if err == nil || attempt >= c.MaxRetries || ctx.Err() != nil || !isRetryable(req, response) {
+			return response, err
+		}
+
+		wait := delay
+		if response != nil {
+			if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				wait = time.Duration(seconds) * time.Second
+			}
+		}
+
+		logger.WithError(err).Warnf("%s %s failed, retrying in %s (%d/%d)", req.Method, req.URL.Path, wait, attempt+1, c.MaxRetries)
+
+		select {
+		case <-ctx.Done():
+			return response, err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+	}
+}
+
+// send waits for the rate limiter and sends the request, the request is canceled when the request timeout
+// is reached or the request context is done.
+func (c *RestClient) send(req *http.Request) (*Response, error) {
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.WaitN(req.Context(), endpointWeight(req)); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.RequestTimeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), c.RequestTimeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, err
@@ -314,35 +421,65 @@ func (c *RestClient) sendRequest(req *http.Request) (*Response, error) {
 	return response, nil
 }
 
-func (c *RestClient) sendAuthenticatedRequest(m string, refURL string, data map[string]interface{}) (*Response, error) {
-	req, err := c.newAuthenticatedRequest(m, refURL, data)
-	if err != nil {
-		return nil, err
+// endpointName returns the endpoint path relative to the api root, e.g., v2/orders/clear
+func endpointName(req *http.Request) string {
+	path := req.URL.Path
+	if i := strings.Index(path, "v2/"); i >= 0 {
+		return path[i:]
 	}
-	response, err := c.sendRequest(req)
-	if err != nil {
-		return nil, err
+
+	return strings.TrimPrefix(path, "/")
+}
+
+func endpointWeight(req *http.Request) int {
+	if weight, ok := endpointWeights[endpointName(req)]; ok {
+		return weight
 	}
-	return response, err
+
+	return 1
+}
+
+// isRetryable checks if the failed request can be retried, the response is nil when the request is not sent or
+// the connection is failed.
+func isRetryable(req *http.Request, response *Response) bool {
+	idempotent := req.Method == http.MethodGet || idempotentEndpoints[endpointName(req)]
+	if response == nil {
+		return idempotent
+	}
+
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return true
+	case response.StatusCode >= 500:
+		return idempotent
+	}
+
+	return false
 }
 
 // FIXME: should deprecate the polling usage from the websocket struct
-func (c *RestClient) GetTrades(market string, lastTradeID int64) ([]byte, error) {
+func (c *RestClient) GetTrades(ctx context.Context, market string, lastTradeID int64) ([]byte, error) {
 	params := url.Values{}
 	params.Add("market", market)
 	if lastTradeID > 0 {
 		params.Add("from", strconv.Itoa(int(lastTradeID)))
 	}
 
-	return c.get("/trades", params)
+	return c.get(ctx, "/trades", params)
 }
 
 // get sends GET http request to the api endpoint, the urlPath must start with a slash '/'
-func (c *RestClient) get(urlPath string, values url.Values) ([]byte, error) {
+func (c *RestClient) get(ctx context.Context, urlPath string, values url.Values) ([]byte, error) {
 	var reqURL = c.BaseURL.String() + urlPath
 
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
+	}
+
 	// Create request
-	req, err := http.NewRequest("GET", reqURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not init request: %s", err.Error())
 	}
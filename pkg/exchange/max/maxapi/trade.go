@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/url"
 	"strconv"
+
+	"github.com/pkg/errors"
 )
 
 type MarkerInfo struct {
@@ -59,33 +61,6 @@ type TradeService struct {
 	client *RestClient
 }
 
-func (options *QueryTradeOptions) Map() map[string]interface{} {
-	var data = map[string]interface{}{}
-	data["market"] = options.Market
-
-	if options.Limit > 0 {
-		data["limit"] = options.Limit
-	}
-
-	if options.Timestamp > 0 {
-		data["timestamp"] = options.Timestamp
-	}
-
-	if options.From >= 0 {
-		data["from"] = options.From
-	}
-
-	if options.To > options.From {
-		data["to"] = options.To
-	}
-	if len(options.OrderBy) > 0 {
-		// could be "asc" or "desc"
-		data["order_by"] = options.OrderBy
-	}
-
-	return data
-}
-
 func (options *QueryTradeOptions) Params() url.Values {
 	var params = url.Values{}
 	params.Add("market", options.Market)
@@ -109,23 +84,19 @@ func (options *QueryTradeOptions) Params() url.Values {
 	return params
 }
 
-func (s *TradeService) MyTrades(options QueryTradeOptions) ([]Trade, error) {
-	req, err := s.client.newAuthenticatedRequest("GET", "v2/trades/my", options.Map())
-	if err != nil {
-		return nil, err
-	}
+func (s *TradeService) MyTrades(ctx context.Context, options QueryTradeOptions) ([]Trade, error) {
+	req := s.NewPrivateTradeRequest().
+		Market(options.Market).
+		Limit(options.Limit).
+		Timestamp(options.Timestamp).
+		From(options.From).
+		OrderBy(options.OrderBy)
 
-	response, err := s.client.sendRequest(req)
-	if err != nil {
-		return nil, err
-	}
-
-	var v []Trade
-	if err := response.DecodeJSON(&v); err != nil {
-		return nil, err
+	if options.To > options.From {
+		req.To(options.To)
 	}
 
-	return v, nil
+	return req.Do(ctx)
 }
 
 func (s *TradeService) NewPrivateTradeRequest() *PrivateTradeRequest {
@@ -143,7 +114,7 @@ type PrivateTradeRequestParams struct {
 	Market string `json:"market"`
 
 	// Timestamp is the seconds elapsed since Unix epoch, set to return trades executed before the time only
-	Timestamp int `json:"timestamp,omitempty"`
+	Timestamp int64 `json:"timestamp,omitempty"`
 
 	// From field is a trade id, set ot return trades created after the trade
 	From int64 `json:"from,omitempty"`
@@ -171,6 +142,11 @@ func (r *PrivateTradeRequest) Market(market string) *PrivateTradeRequest {
 	return r
 }
 
+func (r *PrivateTradeRequest) Timestamp(t int64) *PrivateTradeRequest {
+	r.params.Timestamp = t
+	return r
+}
+
 func (r *PrivateTradeRequest) From(from int64) *PrivateTradeRequest {
 	r.params.From = from
 	return r
@@ -201,13 +177,26 @@ func (r *PrivateTradeRequest) OrderBy(orderBy string) *PrivateTradeRequest {
 	return r
 }
 
+func (r *PrivateTradeRequest) Validate() error {
+	if len(r.params.Market) == 0 {
+		return errors.New("market is required")
+	}
+
+	switch r.params.OrderBy {
+	case "", "asc", "desc":
+	default:
+		return errors.Errorf("invalid order_by %q", r.params.OrderBy)
+	}
+
+	return nil
+}
+
 func (r *PrivateTradeRequest) Do(ctx context.Context) (trades []Trade, err error) {
-	req, err := r.client.newAuthenticatedRequest("GET", "v2/trades/my", &r.params)
-	if err != nil {
-		return trades, err
+	if err = r.Validate(); err != nil {
+		return
 	}
 
-	response, err := r.client.sendRequest(req)
+	response, err := r.client.sendAuthenticatedRequest(ctx, "GET", "v2/trades/my", &r.params)
 	if err != nil {
 		return trades, err
 	}
@@ -219,10 +208,10 @@ func (r *PrivateTradeRequest) Do(ctx context.Context) (trades []Trade, err error
 	return trades, err
 }
 
-func (s *TradeService) Trades(options QueryTradeOptions) ([]Trade, error) {
+func (s *TradeService) Trades(ctx context.Context, options QueryTradeOptions) ([]Trade, error) {
 	var params = options.Params()
 
-	req, err := s.client.newRequest("GET", "v2/trades", params, nil)
+	req, err := s.client.newRequest(ctx, "GET", "v2/trades", params, nil)
 	if err != nil {
 		return nil, err
 	}
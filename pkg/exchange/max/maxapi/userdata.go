@@ -2,6 +2,7 @@ package max
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/valyala/fastjson"
@@ -15,6 +16,10 @@ type BaseEvent struct {
 	Timestamp int64  `json:"T"`
 }
 
+func (e *BaseEvent) Time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond))
+}
+
 type OrderUpdate struct {
 	Event     string    `json:"e"`
 	ID        uint64    `json:"i"`
@@ -242,7 +247,8 @@ func parseAuthEvent(v *fastjson.Value) *AuthEvent {
 }
 
 func ParseUserEvent(v *fastjson.Value) (interface{}, error) {
-	eventType := string(v.GetStringBytes("e"))
+	// the events of the margin wallet have the same payloads as the spot wallet events
+	eventType := strings.TrimPrefix(string(v.GetStringBytes("e")), "mwallet_")
 	switch eventType {
 	case "order_snapshot":
 		return parserOrderSnapshotEvent(v), nil
@@ -0,0 +1,44 @@
+package max
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMessage_userEvents(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"e":"authenticated","i":"client1","T":1637990400000}`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, &AuthEvent{Event: "authenticated", ID: "client1", Timestamp: 1637990400000}, msg)
+	}
+
+	msg, err = ParseMessage([]byte(`{"c":"user","e":"order_update","o":[{"i":87,"sd":"bid","ot":"limit","p":"21499.0","v":"0.2658","ev":"0","rv":"0.2658","S":"wait","M":"ethtwd","tc":0,"T":1637990400000,"ci":"my-order"}],"T":1637990400012}`))
+	if assert.NoError(t, err) {
+		e, ok := msg.(*OrderUpdateEvent)
+		if assert.True(t, ok) && assert.Len(t, e.Orders, 1) {
+			assert.Equal(t, uint64(87), e.Orders[0].ID)
+			assert.Equal(t, "my-order", e.Orders[0].ClientOID)
+			assert.Equal(t, OrderStateWait, e.Orders[0].State)
+		}
+	}
+
+	// the margin wallet events are parsed as the spot wallet events
+	msg, err = ParseMessage([]byte(`{"c":"user","e":"mwallet_trade_update","t":[{"i":68444,"p":"21499.0","v":"0.2658","M":"ethtwd","T":1637990400000,"sd":"bid","f":"3.2","fc":"twd","m":true,"oi":87}],"T":1637990400012}`))
+	if assert.NoError(t, err) {
+		e, ok := msg.(*TradeUpdateEvent)
+		if assert.True(t, ok) && assert.Len(t, e.Trades, 1) {
+			assert.Equal(t, "mwallet_trade_update", e.Event)
+			assert.Equal(t, uint64(87), e.Trades[0].OrderID)
+			assert.True(t, e.Trades[0].Maker)
+		}
+	}
+
+	msg, err = ParseMessage([]byte(`{"c":"user","e":"mwallet_account_update","B":[{"cu":"btc","av":"0.5","l":"0.1"}],"T":1637990400012}`))
+	if assert.NoError(t, err) {
+		e, ok := msg.(*AccountUpdateEvent)
+		if assert.True(t, ok) && assert.Len(t, e.Balances, 1) {
+			assert.Equal(t, "btc", e.Balances[0].Currency)
+			assert.Equal(t, "0.5", e.Balances[0].Available)
+		}
+	}
+}
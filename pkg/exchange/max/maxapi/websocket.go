@@ -3,11 +3,15 @@ package max
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 var WebSocketURL = "wss://max-stream.maicoin.com/ws"
@@ -41,9 +45,18 @@ type WebSocketService struct {
 
 	reconnectC chan struct{}
 
+	// backoff is the exponential backoff of the reconnect attempts, it's reset after a successful connect
+	backoff *types.Backoff
+
 	// Subscriptions is the subscription request payloads that will be used for sending subscription request
 	Subscriptions []Subscription
 
+	// AuthFilters are the private channels subscribed by the auth request, all the spot wallet channels are subscribed if it's empty
+	AuthFilters []string
+
+	// Latency records the event latencies by the event type if it's set
+	Latency *types.LatencyRecorder
+
 	connectCallbacks    []func(conn *websocket.Conn)
 	disconnectCallbacks []func(conn *websocket.Conn)
 
@@ -54,6 +67,7 @@ type WebSocketService struct {
 	kLineEventCallbacks        []func(e KLineEvent)
 	errorEventCallbacks        []func(e ErrorEvent)
 	subscriptionEventCallbacks []func(e SubscriptionEvent)
+	authEventCallbacks         []func(e AuthEvent)
 
 	tradeUpdateEventCallbacks   []func(e TradeUpdateEvent)
 	tradeSnapshotEventCallbacks []func(e TradeSnapshotEvent)
@@ -69,6 +83,7 @@ func NewWebSocketService(wsURL string, key, secret string) *WebSocketService {
 		key:        key,
 		secret:     secret,
 		reconnectC: make(chan struct{}, 1),
+		backoff:    types.NewReconnectBackoff(),
 		baseURL:    wsURL,
 	}
 }
@@ -97,6 +112,7 @@ func (s *WebSocketService) Auth() error {
 		Nonce:     nonce,
 		Signature: signPayload(fmt.Sprintf("%d", nonce), s.secret),
 		ID:        uuid.New().String(),
+		Filters:   s.AuthFilters,
 	}
 	return s.conn.WriteJSON(auth)
 }
@@ -128,15 +144,21 @@ func (s *WebSocketService) read(ctx context.Context) {
 			return
 
 		case <-s.reconnectC:
-			time.Sleep(3 * time.Second)
 			if err := s.connect(ctx); err != nil {
+				delay := s.backoff.Next()
+				logger.WithError(err).Warnf("reconnect failed, retrying in %s", delay)
+				time.Sleep(delay)
 				s.emitReconnect()
+			} else {
+				s.backoff.Reset()
 			}
 
 		default:
 			mt, msg, err := s.conn.ReadMessage()
+			receivedTime := time.Now()
 
 			if err != nil {
+				s.EmitDisconnect(s.conn)
 				s.emitReconnect()
 				continue
 			}
@@ -155,6 +177,7 @@ func (s *WebSocketService) read(ctx context.Context) {
 
 			if m != nil {
 				s.dispatch(m)
+				s.recordLatency(m, receivedTime)
 			}
 		}
 	}
@@ -178,6 +201,9 @@ func (s *WebSocketService) dispatch(msg interface{}) {
 	case *SubscriptionEvent:
 		s.EmitSubscriptionEvent(*e)
 
+	case *AuthEvent:
+		s.EmitAuthEvent(*e)
+
 	case *TradeSnapshotEvent:
 		s.EmitTradeSnapshotEvent(*e)
 
@@ -201,12 +227,34 @@ func (s *WebSocketService) dispatch(msg interface{}) {
 	}
 }
 
+// recordLatency records the latency of the dispatched event, the channel name is derived from the event type,
+// e.g., *max.BookEvent => book
+func (s *WebSocketService) recordLatency(msg interface{}, receivedTime time.Time) {
+	if s.Latency == nil {
+		return
+	}
+
+	var eventTime time.Time
+	if e, ok := msg.(interface{ Time() time.Time }); ok {
+		eventTime = e.Time()
+	}
+
+	name := reflect.TypeOf(msg).Elem().Name()
+	channel := strings.ToLower(name[:1]) + strings.TrimSuffix(name[1:], "Event")
+	s.Latency.Record(channel, eventTime, receivedTime, time.Now())
+}
+
 func (s *WebSocketService) ClearSubscriptions() {
 	s.Subscriptions = nil
 }
 
+// Reconnect closes the current connection so that the blocking read returns, and the read loop reconnects
 func (s *WebSocketService) Reconnect() {
 	logger.Info("reconnecting...")
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+
 	s.emitReconnect()
 }
 
@@ -96,6 +96,16 @@ func (s *WebSocketService) EmitSubscriptionEvent(e SubscriptionEvent) {
 	}
 }
 
+func (s *WebSocketService) OnAuthEvent(cb func(e AuthEvent)) {
+	s.authEventCallbacks = append(s.authEventCallbacks, cb)
+}
+
+func (s *WebSocketService) EmitAuthEvent(e AuthEvent) {
+	for _, cb := range s.authEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *WebSocketService) OnTradeUpdateEvent(cb func(e TradeUpdateEvent)) {
 	s.tradeUpdateEventCallbacks = append(s.tradeUpdateEventCallbacks, cb)
 }
@@ -2,6 +2,7 @@ package max
 
 import (
 	"context"
+	"os"
 	"strconv"
 	"time"
 
@@ -17,6 +18,7 @@ var logger = log.WithField("exchange", "max")
 
 type Stream struct {
 	types.StandardStream
+	types.MarginSettings
 
 	websocketService *max.WebSocketService
 
@@ -24,24 +26,50 @@ type Stream struct {
 }
 
 func NewStream(key, secret string) *Stream {
-	wss := max.NewWebSocketService(max.WebSocketURL, key, secret)
+	wsURL := max.WebSocketURL
+	if override := os.Getenv("MAX_API_WS_URL"); len(override) > 0 {
+		wsURL = override
+	}
+
+	wss := max.NewWebSocketService(wsURL, key, secret)
 
 	stream := &Stream{
 		websocketService: wss,
 	}
+	wss.Latency = stream.Latency()
 
 	wss.OnConnect(func(conn *websocket.Conn) {
+		if stream.publicOnly {
+			return
+		}
+
 		if key == "" || secret == "" {
 			log.Warn("MAX API key or secret is empty, will not send authentication command")
-		} else {
-			if err := wss.Auth(); err != nil {
-				wss.EmitError(err)
-				logger.WithError(err).Error("failed to send auth request")
-			}
+			return
+		}
+
+		// the order, trade and account updates are pushed through the private channels of the authenticated connection
+		wss.AuthFilters = max.SpotAuthFilters
+		if stream.IsMargin {
+			wss.AuthFilters = max.MarginAuthFilters
 		}
+
+		if err := wss.Auth(); err != nil {
+			wss.EmitError(err)
+			logger.WithError(err).Error("failed to send auth request")
+		}
+	})
+
+	wss.OnAuthEvent(func(e max.AuthEvent) {
+		logger.Infof("websocket authenticated, private channels: %v", wss.AuthFilters)
+	})
+
+	wss.OnErrorEvent(func(e max.ErrorEvent) {
+		logger.Errorf("websocket error event: %v (command id %s)", e.Errors, e.CommandID)
 	})
 
 	wss.OnMessage(func(message []byte) {
+		stream.SetLastMessageTime(time.Now())
 		logger.Debugf("M: %s", message)
 	})
 
@@ -53,6 +81,18 @@ func NewStream(key, secret string) *Stream {
 		}
 	})
 
+	wss.OnTradeEvent(func(e max.PublicTradeEvent) {
+		for _, t := range e.Trades {
+			trade, err := convertWebSocketPublicTrade(e.Market, t)
+			if err != nil {
+				log.WithError(err).Error("websocket public trade convert error")
+				continue
+			}
+
+			stream.EmitMarketTrade(*trade)
+		}
+	})
+
 	wss.OnOrderSnapshotEvent(func(e max.OrderSnapshotEvent) {
 		for _, o := range e.Orders {
 			globalOrder, err := toGlobalOrderUpdate(o)
@@ -82,7 +122,7 @@ func NewStream(key, secret string) *Stream {
 			trade, err := convertWebSocketTrade(tradeUpdate)
 			if err != nil {
 				log.WithError(err).Error("websocket trade update convert error")
-				return
+				continue
 			}
 
 			stream.EmitTradeUpdate(*trade)
@@ -110,6 +150,10 @@ func NewStream(key, secret string) *Stream {
 		stream.EmitConnect()
 	})
 
+	wss.OnDisconnect(func(conn *websocket.Conn) {
+		stream.EmitDisconnect()
+	})
+
 	wss.OnAccountSnapshotEvent(func(e max.AccountSnapshotEvent) {
 		snapshot := map[string]types.Balance{}
 		for _, bm := range e.Balances {
@@ -161,6 +205,12 @@ func (s *Stream) Close() error {
 	return s.websocketService.Close()
 }
 
+// Reconnect drops the current connection, the websocket service reconnects with the backoff
+func (s *Stream) Reconnect() error {
+	s.websocketService.Reconnect()
+	return nil
+}
+
 func convertWebSocketTrade(t max.TradeUpdate) (*types.Trade, error) {
 	// skip trade ID that is the same. however this should not happen
 	var side = toGlobalSideType(t.Side)
@@ -213,6 +263,8 @@ func toGlobalOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 		return nil, err
 	}
 
+	timeInForce, postOnly := toGlobalTimeInForce(u.OrderType)
+
 	return &types.Order{
 		SubmitOrder: types.SubmitOrder{
 			ClientOrderID: u.ClientOID,
@@ -222,7 +274,9 @@ func toGlobalOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 			Quantity:      util.MustParseFloat(u.Volume),
 			Price:         util.MustParseFloat(u.Price),
 			StopPrice:     util.MustParseFloat(u.StopPrice),
-			TimeInForce:   "GTC", // MAX only supports GTC
+			TimeInForce:   timeInForce,
+			PostOnly:      postOnly,
+			GroupID:       u.GroupID,
 		},
 		Exchange:         "max",
 		OrderID:          u.ID,
@@ -231,3 +285,33 @@ func toGlobalOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 		CreationTime:     time.Unix(0, u.CreatedAtMs*int64(time.Millisecond)),
 	}, nil
 }
+
+// convertWebSocketPublicTrade converts the public trade entry of the market, the taker side is not provided by MAX,
+// so the trend of the trade ("up" or "down") is used as the side of the taker.
+func convertWebSocketPublicTrade(market string, t max.TradeEntry) (*types.Trade, error) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := strconv.ParseFloat(t.Volume, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	side := types.SideTypeSell
+	if t.Trend == "up" {
+		side = types.SideTypeBuy
+	}
+
+	return &types.Trade{
+		Symbol:        toGlobalSymbol(market),
+		Exchange:      "max",
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price * quantity,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          t.Time(),
+	}, nil
+}
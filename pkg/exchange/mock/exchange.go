@@ -0,0 +1,602 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "mock")
+
+// Exchange is the exchange simulator for the strategy unit tests, nothing is sent to the real exchange.
+// The test script drives the exchange: the klines are injected by PushKLine, the orders are filled by Fill
+// (or automatically by the injected klines when AutoFill is enabled), and the balances are mutated by SetBalance
+// and AddBalance. All the order, trade and balance updates are emitted synchronously to the streams, so the
+// strategy callbacks are done when the script call returns.
+type Exchange struct {
+	name types.ExchangeName
+
+	// FeeRate is the fee rate of the simulated trades, the fee is not charged by default
+	FeeRate float64
+
+	// AutoFill fills the open limit orders crossed by the high or the low price of the injected klines
+	AutoFill bool
+
+	// SubmitOrderError is returned by SubmitOrders if it's set, it's used for testing the order submission failures
+	SubmitOrderError error
+
+	mu sync.Mutex
+
+	markets  types.MarketMap
+	account  *types.Account
+	klines   map[string][]types.KLine
+	lastTime time.Time
+
+	lastPrices map[string]float64
+
+	submittedOrders []types.SubmitOrder
+	openOrders      map[uint64]types.Order
+	closedOrders    map[string][]types.Order
+	trades          map[string][]types.Trade
+	deposits        []types.Deposit
+	withdraws       []types.Withdraw
+
+	streams []*Stream
+
+	lastOrderID uint64
+	lastTradeID int64
+}
+
+func New(name types.ExchangeName, markets types.MarketMap, balances types.BalanceMap) *Exchange {
+	account := types.NewAccount()
+	account.UpdateBalances(balances)
+
+	return &Exchange{
+		name:         name,
+		markets:      markets,
+		account:      account,
+		klines:       make(map[string][]types.KLine),
+		lastPrices:   make(map[string]float64),
+		openOrders:   make(map[uint64]types.Order),
+		closedOrders: make(map[string][]types.Order),
+		trades:       make(map[string][]types.Trade),
+	}
+}
+
+func (e *Exchange) Name() types.ExchangeName {
+	return e.name
+}
+
+func (e *Exchange) PlatformFeeCurrency() string {
+	return ""
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	stream := &Stream{exchange: e}
+
+	e.mu.Lock()
+	e.streams = append(e.streams, stream)
+	e.mu.Unlock()
+
+	return stream
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return e.markets, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	account := &types.Account{AccountType: "MOCK"}
+	account.UpdateBalances(e.account.Balances())
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.account.Balances(), nil
+}
+
+func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var kLines []types.KLine
+	for _, k := range e.klines[symbol+"."+string(interval)] {
+		if options.StartTime != nil && k.StartTime.Before(*options.StartTime) {
+			continue
+		}
+
+		if options.EndTime != nil && k.EndTime.After(*options.EndTime) {
+			continue
+		}
+
+		kLines = append(kLines, k)
+	}
+
+	if options.Limit > 0 && len(kLines) > options.Limit {
+		if options.StartTime != nil {
+			kLines = kLines[:options.Limit]
+		} else {
+			kLines = kLines[len(kLines)-options.Limit:]
+		}
+	}
+
+	return kLines, nil
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var trades []types.Trade
+	for _, t := range e.trades[symbol] {
+		if options.LastTradeID > 0 && t.ID <= options.LastTradeID {
+			continue
+		}
+
+		if options.StartTime != nil && t.Time.Before(*options.StartTime) {
+			continue
+		}
+
+		if options.EndTime != nil && t.Time.After(*options.EndTime) {
+			continue
+		}
+
+		trades = append(trades, t)
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, d := range e.deposits {
+		if (asset == "" || d.Asset == asset) && !d.Time.Before(since) && !d.Time.After(until) {
+			allDeposits = append(allDeposits, d)
+		}
+	}
+
+	return allDeposits, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, w := range e.withdraws {
+		if (asset == "" || w.Asset == asset) && !w.ApplyTime.Before(since) && !w.ApplyTime.After(until) {
+			allWithdraws = append(allWithdraws, w)
+		}
+	}
+
+	return allWithdraws, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	return e.OpenOrders(symbol), nil
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.closedOrders[symbol] {
+		if o.OrderID > lastOrderID && !o.CreationTime.Before(since) && !o.CreationTime.After(until) {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	if e.SubmitOrderError != nil {
+		return nil, e.SubmitOrderError
+	}
+
+	for _, o := range orders {
+		createdOrder, err := e.submitOrder(o)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, *createdOrder)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	for _, o := range orders {
+		e.mu.Lock()
+		order, ok := e.openOrders[o.OrderID]
+		delete(e.openOrders, o.OrderID)
+		e.mu.Unlock()
+
+		if !ok {
+			return fmt.Errorf("mock order %d not found", o.OrderID)
+		}
+
+		if err := e.closeOrder(order, types.OrderStatusCanceled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SubmittedOrders returns all the orders submitted to the exchange in order, including the rejected ones
+func (e *Exchange) SubmittedOrders() []types.SubmitOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.SubmitOrder(nil), e.submittedOrders...)
+}
+
+// OpenOrders returns the open orders of the symbol sorted by the order id
+func (e *Exchange) OpenOrders(symbol string) []types.Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var orders []types.Order
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OrderID < orders[j].OrderID
+	})
+	return orders
+}
+
+// Trades returns the simulated trades of the symbol
+func (e *Exchange) Trades(symbol string) []types.Trade {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.Trade(nil), e.trades[symbol]...)
+}
+
+// SetBalance overwrites the available balance of the currency and emits the balance update
+func (e *Exchange) SetBalance(currency string, available float64) {
+	balance, _ := e.account.Balance(currency)
+	balance.Currency = currency
+	balance.Available = fixedpoint.NewFromFloat(available)
+	e.account.UpdateBalances(types.BalanceMap{currency: balance})
+	e.emitBalanceUpdate()
+}
+
+// AddBalance adds the delta to the available balance of the currency and emits the balance update
+func (e *Exchange) AddBalance(currency string, delta float64) {
+	_ = e.account.AddBalance(currency, fixedpoint.NewFromFloat(delta))
+	e.emitBalanceUpdate()
+}
+
+// AddDeposit adds the deposit to the deposit history and credits the balance
+func (e *Exchange) AddDeposit(deposit types.Deposit) {
+	e.mu.Lock()
+	e.deposits = append(e.deposits, deposit)
+	e.mu.Unlock()
+
+	e.AddBalance(deposit.Asset, deposit.Amount)
+}
+
+// AddWithdraw adds the withdraw to the withdraw history and debits the balance with the fee
+func (e *Exchange) AddWithdraw(withdraw types.Withdraw) {
+	e.mu.Lock()
+	e.withdraws = append(e.withdraws, withdraw)
+	e.mu.Unlock()
+
+	e.AddBalance(withdraw.Asset, -(withdraw.Amount + withdraw.TransactionFee))
+}
+
+// PushKLine stores the kline for QueryKLines and emits it to the streams, the closed kline is emitted by OnKLineClosed as well.
+// The close price is the last price for filling the market orders, and the open limit orders crossed by the kline are
+// filled if AutoFill is enabled.
+func (e *Exchange) PushKLine(kline types.KLine) {
+	e.mu.Lock()
+	key := kline.Symbol + "." + string(kline.Interval)
+	e.klines[key] = append(e.klines[key], kline)
+	e.lastPrices[kline.Symbol] = kline.Close
+	if kline.EndTime.After(e.lastTime) {
+		e.lastTime = kline.EndTime
+	}
+	e.mu.Unlock()
+
+	if e.AutoFill {
+		e.fillCrossedOrders(kline)
+	}
+
+	for _, stream := range e.getStreams() {
+		stream.EmitKLine(kline)
+		if kline.Closed {
+			stream.EmitKLineClosed(kline)
+		}
+	}
+}
+
+// PushBook emits the order book snapshot to the streams
+func (e *Exchange) PushBook(book types.OrderBook) {
+	for _, stream := range e.getStreams() {
+		stream.EmitBookSnapshot(book)
+	}
+}
+
+// PushMarketTrade emits the public trade to the streams and updates the last price
+func (e *Exchange) PushMarketTrade(trade types.Trade) {
+	e.mu.Lock()
+	e.lastPrices[trade.Symbol] = trade.Price
+	e.mu.Unlock()
+
+	for _, stream := range e.getStreams() {
+		stream.EmitMarketTrade(trade)
+	}
+}
+
+// Fill fills the open order with the given quantity at the order price as the maker,
+// the order is partially filled if the quantity is less than the remaining quantity.
+func (e *Exchange) Fill(orderID uint64, quantity float64) (*types.Trade, error) {
+	e.mu.Lock()
+	order, ok := e.openOrders[orderID]
+	e.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("mock order %d not found", orderID)
+	}
+
+	return e.fill(order, order.Price, quantity, true)
+}
+
+// fillCrossedOrders fills the open limit orders of the kline symbol crossed by the high or the low price
+func (e *Exchange) fillCrossedOrders(kline types.KLine) {
+	for _, o := range e.OpenOrders(kline.Symbol) {
+		switch o.Side {
+		case types.SideTypeBuy:
+			if kline.Low > o.Price {
+				continue
+			}
+		case types.SideTypeSell:
+			if kline.High < o.Price {
+				continue
+			}
+		}
+
+		if _, err := e.fill(o, o.Price, o.Quantity-o.ExecutedQuantity, true); err != nil {
+			log.WithError(err).Errorf("mock order fill error: %+v", o)
+		}
+	}
+}
+
+func (e *Exchange) now() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.lastTime.IsZero() {
+		return time.Now()
+	}
+
+	return e.lastTime
+}
+
+func (e *Exchange) submitOrder(o types.SubmitOrder) (*types.Order, error) {
+	e.mu.Lock()
+	e.submittedOrders = append(e.submittedOrders, o)
+	market, ok := e.markets[o.Symbol]
+	lastPrice, hasLastPrice := e.lastPrices[o.Symbol]
+	e.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("market %s is not defined", o.Symbol)
+	}
+
+	price := o.Price
+	switch o.Type {
+	case types.OrderTypeMarket:
+		if !hasLastPrice {
+			return nil, fmt.Errorf("no last price of %s, push a kline before submitting the market order", o.Symbol)
+		}
+
+		price = lastPrice
+
+	case types.OrderTypeLimit:
+
+	default:
+		return nil, fmt.Errorf("order type %s is not supported by the mock exchange", o.Type)
+	}
+
+	// the post only order crossing the last price is rejected
+	if o.PostOnly && hasLastPrice &&
+		((o.Side == types.SideTypeBuy && price >= lastPrice) || (o.Side == types.SideTypeSell && price <= lastPrice)) {
+		return nil, fmt.Errorf("post only order %s %s @ %f would take the liquidity", o.Symbol, o.Side, price)
+	}
+
+	if err := lockBalance(e.account, market, o.Side, price, o.Quantity); err != nil {
+		return nil, err
+	}
+
+	now := e.now()
+
+	e.mu.Lock()
+	e.lastOrderID++
+	order := types.Order{
+		SubmitOrder:  o,
+		Exchange:     e.name.String(),
+		OrderID:      e.lastOrderID,
+		Status:       types.OrderStatusNew,
+		IsWorking:    true,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+	order.Price = price
+	order.Market = market
+	if o.Type == types.OrderTypeLimit {
+		e.openOrders[order.OrderID] = order
+	}
+	e.mu.Unlock()
+
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+
+	// the market order is filled immediately as the taker
+	if o.Type == types.OrderTypeMarket {
+		if _, err := e.fill(order, price, order.Quantity, false); err != nil {
+			return nil, err
+		}
+
+		order.Status = types.OrderStatusFilled
+		order.ExecutedQuantity = order.Quantity
+		order.IsWorking = false
+	}
+
+	return &order, nil
+}
+
+func (e *Exchange) fill(order types.Order, price, quantity float64, isMaker bool) (*types.Trade, error) {
+	remaining := order.Quantity - order.ExecutedQuantity
+	if quantity <= 0 || quantity > remaining {
+		return nil, fmt.Errorf("invalid fill quantity %f, the remaining quantity of order %d is %f", quantity, order.OrderID, remaining)
+	}
+
+	market := order.Market
+	quoteQuantity := price * quantity
+
+	var fee float64
+	var feeCurrency string
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		fee = quantity * e.FeeRate
+		feeCurrency = market.BaseCurrency
+
+		if err := e.account.UseLockedBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(order.Price*quantity)); err != nil {
+			return nil, err
+		}
+
+		_ = e.account.AddBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity-fee))
+
+	case types.SideTypeSell:
+		fee = quoteQuantity * e.FeeRate
+		feeCurrency = market.QuoteCurrency
+
+		if err := e.account.UseLockedBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity)); err != nil {
+			return nil, err
+		}
+
+		_ = e.account.AddBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(quoteQuantity-fee))
+	}
+
+	now := e.now()
+
+	e.mu.Lock()
+	e.lastTradeID++
+	trade := types.Trade{
+		ID:            e.lastTradeID,
+		OrderID:       order.OrderID,
+		Exchange:      e.name.String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          now,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+	e.trades[order.Symbol] = append(e.trades[order.Symbol], trade)
+
+	order.ExecutedQuantity += quantity
+	order.UpdateTime = now
+	if order.ExecutedQuantity >= order.Quantity {
+		order.Status = types.OrderStatusFilled
+		order.IsWorking = false
+		delete(e.openOrders, order.OrderID)
+		e.closedOrders[order.Symbol] = append(e.closedOrders[order.Symbol], order)
+	} else {
+		order.Status = types.OrderStatusPartiallyFilled
+		e.openOrders[order.OrderID] = order
+	}
+	e.mu.Unlock()
+
+	e.emitTradeUpdate(trade)
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+	return &trade, nil
+}
+
+// closeOrder closes the order and unlocks the balance of the unfilled quantity
+func (e *Exchange) closeOrder(order types.Order, status types.OrderStatus) error {
+	if err := unlockBalance(e.account, order.Market, order.Side, order.Price, order.Quantity-order.ExecutedQuantity); err != nil {
+		return err
+	}
+
+	order.Status = status
+	order.IsWorking = false
+	order.UpdateTime = e.now()
+
+	e.mu.Lock()
+	e.closedOrders[order.Symbol] = append(e.closedOrders[order.Symbol], order)
+	e.mu.Unlock()
+
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+	return nil
+}
+
+func lockBalance(account *types.Account, market types.Market, side types.SideType, price, quantity float64) error {
+	switch side {
+	case types.SideTypeBuy:
+		return account.LockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(price*quantity))
+
+	case types.SideTypeSell:
+		return account.LockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity))
+	}
+
+	return fmt.Errorf("unknown order side: %s", side)
+}
+
+func unlockBalance(account *types.Account, market types.Market, side types.SideType, price, quantity float64) error {
+	switch side {
+	case types.SideTypeBuy:
+		return account.UnlockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(price*quantity))
+
+	case types.SideTypeSell:
+		return account.UnlockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity))
+	}
+
+	return fmt.Errorf("unknown order side: %s", side)
+}
+
+func (e *Exchange) emitOrderUpdate(order types.Order) {
+	for _, stream := range e.getStreams() {
+		stream.EmitOrderUpdate(order)
+	}
+}
+
+func (e *Exchange) emitTradeUpdate(trade types.Trade) {
+	for _, stream := range e.getStreams() {
+		stream.EmitTradeUpdate(trade)
+	}
+}
+
+func (e *Exchange) emitBalanceUpdate() {
+	balances := e.account.Balances()
+	for _, stream := range e.getStreams() {
+		stream.EmitBalanceUpdate(balances)
+	}
+}
+
+func (e *Exchange) getStreams() []*Stream {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Stream(nil), e.streams...)
+}
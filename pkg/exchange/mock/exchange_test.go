@@ -0,0 +1,92 @@
+package mock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestExchange() *Exchange {
+	return New(types.ExchangeBinance, types.MarketMap{
+		"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)},
+	})
+}
+
+func TestExchange_FillOrders(t *testing.T) {
+	ctx := context.Background()
+	exchange := newTestExchange()
+
+	stream := exchange.NewStream()
+	var trades []types.Trade
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	createdOrders, err := exchange.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 1000.0, Quantity: 2.0})
+	assert.NoError(t, err)
+	if !assert.Len(t, createdOrders, 1) {
+		return
+	}
+
+	balances, _ := exchange.QueryAccountBalances(ctx)
+	assert.Equal(t, fixedpoint.NewFromFloat(2000.0), balances["USDT"].Locked)
+
+	// partially filled
+	_, err = exchange.Fill(createdOrders[0].OrderID, 0.5)
+	assert.NoError(t, err)
+	if openOrders := exchange.OpenOrders("BTCUSDT"); assert.Len(t, openOrders, 1) {
+		assert.Equal(t, types.OrderStatusPartiallyFilled, openOrders[0].Status)
+	}
+
+	// the rest is filled by the kline crossing the order price
+	exchange.AutoFill = true
+	exchange.PushKLine(types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m, High: 1100.0, Low: 990.0, Close: 1050.0, EndTime: time.Now(), Closed: true})
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 0)
+	assert.Len(t, trades, 2)
+
+	balances, _ = exchange.QueryAccountBalances(ctx)
+	assert.Equal(t, fixedpoint.NewFromFloat(2.0), balances["BTC"].Available)
+	assert.Equal(t, fixedpoint.NewFromFloat(8000.0), balances["USDT"].Available)
+	assert.Equal(t, fixedpoint.Value(0), balances["USDT"].Locked)
+
+	// the market order is filled at the last close price
+	_, err = exchange.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 3) {
+		assert.Equal(t, 1050.0, trades[2].Price)
+	}
+
+	kLines, err := exchange.QueryKLines(ctx, "BTCUSDT", types.Interval1m, types.KLineQueryOptions{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 1)
+	assert.Len(t, exchange.SubmittedOrders(), 2)
+}
+
+func TestExchange_CancelOrders(t *testing.T) {
+	ctx := context.Background()
+	exchange := newTestExchange()
+
+	createdOrders, err := exchange.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 1000.0, Quantity: 1.0})
+	assert.NoError(t, err)
+
+	assert.NoError(t, exchange.CancelOrders(ctx, createdOrders...))
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 0)
+
+	balances, _ := exchange.QueryAccountBalances(ctx)
+	assert.Equal(t, fixedpoint.NewFromFloat(10000.0), balances["USDT"].Available)
+
+	// the selling order without the base balance is rejected
+	_, err = exchange.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 1000.0, Quantity: 1.0})
+	assert.Error(t, err)
+
+	exchange.SetBalance("BTC", 1.0)
+	_, err = exchange.SubmitOrders(ctx, types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeLimit, Price: 1000.0, Quantity: 1.0})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Stream receives the events scripted through the mock exchange, there is no connection to close.
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+}
+
+func (s *Stream) SetPublicOnly() {}
+
+// Connect emits the connect event and the current balances as the balance snapshot
+func (s *Stream) Connect(ctx context.Context) error {
+	s.EmitConnect()
+	s.EmitBalanceSnapshot(s.exchange.account.Balances())
+	return nil
+}
+
+func (s *Stream) Close() error {
+	return nil
+}
@@ -0,0 +1,545 @@
+package paper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var log = logrus.WithField("exchange", "paper")
+
+// DefaultFeeRate is the fee rate of the simulated trades, for both maker and taker
+const DefaultFeeRate = 0.001
+
+// Exchange wraps the real exchange for paper trading (dry-run),
+// the market data are queried from the real exchange and the orders are matched locally against the realtime order book.
+// No order is sent to the real exchange.
+type Exchange struct {
+	// the real exchange is only used for the public market data
+	types.Exchange
+
+	// FeeRate is the fee rate of the simulated trades, defaults to DefaultFeeRate
+	FeeRate float64
+
+	account         *types.Account
+	initialBalances types.BalanceMap
+
+	mu           sync.Mutex
+	initialized  bool
+	markets      types.MarketMap
+	books        map[string]*types.StreamOrderBook
+	openOrders   map[uint64]types.Order
+	closedOrders map[string][]types.Order
+	trades       map[string][]types.Trade
+	streams      []*Stream
+
+	lastOrderID uint64
+	lastTradeID int64
+}
+
+// New creates the paper exchange, the real account balances are used as the initial balances if no balance is given
+func New(exchange types.Exchange, balances types.BalanceMap) *Exchange {
+	return &Exchange{
+		Exchange:        exchange,
+		FeeRate:         DefaultFeeRate,
+		account:         types.NewAccount(),
+		initialBalances: balances,
+		books:           make(map[string]*types.StreamOrderBook),
+		openOrders:      make(map[uint64]types.Order),
+		closedOrders:    make(map[string][]types.Order),
+		trades:          make(map[string][]types.Trade),
+	}
+}
+
+func (e *Exchange) init(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.initialized {
+		return nil
+	}
+
+	markets, err := e.Exchange.QueryMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	balances := e.initialBalances
+	if len(balances) == 0 {
+		log.Infof("no paper trade balance is configured, using the %s account balances", e.Exchange.Name())
+		balances, err = e.Exchange.QueryAccountBalances(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the locked balances of the real account are not used by the simulated orders
+	var initialBalances = types.BalanceMap{}
+	for currency, b := range balances {
+		initialBalances[currency] = types.Balance{
+			Currency:  currency,
+			Available: b.Available + b.Locked,
+		}
+	}
+
+	e.markets = markets
+	e.account.UpdateBalances(initialBalances)
+	e.initialized = true
+	return nil
+}
+
+func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	return e.markets, nil
+}
+
+func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	account := &types.Account{AccountType: "PAPER"}
+	account.UpdateBalances(e.account.Balances())
+	return account, nil
+}
+
+func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	return e.account.Balances(), nil
+}
+
+// the deposits and withdrawals are not simulated
+func (e *Exchange) QueryDepositHistory(ctx context.Context, asset string, since, until time.Time) (allDeposits []types.Deposit, err error) {
+	return nil, nil
+}
+
+func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []types.Withdraw, err error) {
+	return nil, nil
+}
+
+func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) ([]types.Trade, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var trades []types.Trade
+	for _, t := range e.trades[symbol] {
+		if options.LastTradeID > 0 && t.ID <= options.LastTradeID {
+			continue
+		}
+
+		if options.StartTime != nil && t.Time.Before(*options.StartTime) {
+			continue
+		}
+
+		if options.EndTime != nil && t.Time.After(*options.EndTime) {
+			continue
+		}
+
+		trades = append(trades, t)
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, o := range e.closedOrders[symbol] {
+		if o.OrderID > lastOrderID && !o.CreationTime.Before(since) && !o.CreationTime.After(until) {
+			orders = append(orders, o)
+		}
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	if err := e.init(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, o := range orders {
+		createdOrder, err := e.submitOrder(o)
+		if err != nil {
+			return createdOrders, err
+		}
+
+		createdOrders = append(createdOrders, *createdOrder)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var err2 error
+	for _, o := range orders {
+		if err := e.cancelOrder(o); err != nil {
+			log.WithError(err).Errorf("paper order cancel error")
+			err2 = err
+		}
+	}
+
+	return err2
+}
+
+func (e *Exchange) NewStream() types.Stream {
+	stream := NewStream(e, e.Exchange.NewStream())
+
+	e.mu.Lock()
+	e.streams = append(e.streams, stream)
+	e.mu.Unlock()
+
+	return stream
+}
+
+// bindBook binds the order book of the symbol to the market data stream,
+// it's called before the stream is connected so that the callbacks are not registered while the stream is emitting events.
+func (e *Exchange) bindBook(symbol string, stream types.Stream) {
+	book := e.book(symbol)
+	book.BindStream(stream)
+
+	// the callbacks are registered after the book binding, so the open orders are matched with the updated book
+	stream.OnBookSnapshot(func(b types.OrderBook) {
+		if b.Symbol == symbol {
+			e.matchOpenOrders(symbol)
+		}
+	})
+	stream.OnBookUpdate(func(b types.OrderBook) {
+		if b.Symbol == symbol {
+			e.matchOpenOrders(symbol)
+		}
+	})
+}
+
+// book returns the order book of the symbol, the book of the symbol not subscribed by the stream is always empty
+func (e *Exchange) book(symbol string) *types.StreamOrderBook {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	book, ok := e.books[symbol]
+	if !ok {
+		book = types.NewStreamBook(symbol)
+		e.books[symbol] = book
+	}
+
+	return book
+}
+
+func (e *Exchange) submitOrder(o types.SubmitOrder) (*types.Order, error) {
+	market, ok := e.markets[o.Symbol]
+	if !ok {
+		return nil, fmt.Errorf("market %s is not defined", o.Symbol)
+	}
+
+	book := e.book(o.Symbol).Get()
+
+	// the market order is locked with the best price of the order book
+	price := o.Price
+	if o.Type == types.OrderTypeMarket {
+		pv, ok := bestPrice(book, o.Side)
+		if !ok {
+			return nil, fmt.Errorf("order book of %s is not ready, can not fill the market order", o.Symbol)
+		}
+
+		price = pv.Price.Float64()
+	}
+
+	switch o.Type {
+	case types.OrderTypeMarket, types.OrderTypeLimit:
+	default:
+		return nil, fmt.Errorf("order type %s is not supported by paper trading", o.Type)
+	}
+
+	if err := e.lockBalance(market, o.Side, price, o.Quantity); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.lastOrderID++
+	order := types.Order{
+		SubmitOrder:  o,
+		Exchange:     e.Exchange.Name().String(),
+		OrderID:      e.lastOrderID,
+		Status:       types.OrderStatusNew,
+		IsWorking:    true,
+		CreationTime: now,
+		UpdateTime:   now,
+	}
+	order.Price = price
+	e.mu.Unlock()
+
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+
+	// the order crossing the book is filled immediately as the taker
+	if fillPrice, ok := takerPrice(book, order); ok {
+		return e.fillOrder(order, fillPrice, false, market)
+	}
+
+	if order.Type == types.OrderTypeMarket {
+		return e.closeOrder(order, types.OrderStatusRejected, market)
+	}
+
+	e.mu.Lock()
+	e.openOrders[order.OrderID] = order
+	e.mu.Unlock()
+
+	return &order, nil
+}
+
+func (e *Exchange) cancelOrder(o types.Order) error {
+	e.mu.Lock()
+	order, ok := e.openOrders[o.OrderID]
+	if ok {
+		delete(e.openOrders, o.OrderID)
+	}
+	market := e.markets[order.Symbol]
+	e.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("paper order %d not found", o.OrderID)
+	}
+
+	_, err := e.closeOrder(order, types.OrderStatusCanceled, market)
+	return err
+}
+
+// matchOpenOrders fills the resting orders that are crossed by the order book as the maker
+func (e *Exchange) matchOpenOrders(symbol string) {
+	e.mu.Lock()
+	book, ok := e.books[symbol]
+	var orders []types.Order
+	for _, o := range e.openOrders {
+		if o.Symbol == symbol {
+			orders = append(orders, o)
+		}
+	}
+	market := e.markets[symbol]
+	e.mu.Unlock()
+
+	if !ok || len(orders) == 0 {
+		return
+	}
+
+	snapshot := book.Get()
+	for _, o := range orders {
+		if _, crossed := takerPrice(snapshot, o); !crossed {
+			continue
+		}
+
+		e.mu.Lock()
+		_, stillOpen := e.openOrders[o.OrderID]
+		delete(e.openOrders, o.OrderID)
+		e.mu.Unlock()
+
+		if !stillOpen {
+			continue
+		}
+
+		if _, err := e.fillOrder(o, o.Price, true, market); err != nil {
+			log.WithError(err).Errorf("paper order fill error: %+v", o)
+		}
+	}
+}
+
+func (e *Exchange) fillOrder(order types.Order, price float64, isMaker bool, market types.Market) (*types.Order, error) {
+	quantity := order.Quantity
+	quoteQuantity := price * quantity
+	lockedQuote := order.Price * quantity
+
+	var fee float64
+	var feeCurrency string
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		fee = quantity * e.FeeRate
+		feeCurrency = market.BaseCurrency
+
+		if err := e.account.UseLockedBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(lockedQuote)); err != nil {
+			return nil, err
+		}
+
+		// the taker order could be filled at a better price than the locked price
+		if lockedQuote > quoteQuantity {
+			_ = e.account.AddBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(lockedQuote-quoteQuantity))
+		}
+
+		_ = e.account.AddBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity-fee))
+
+	case types.SideTypeSell:
+		fee = quoteQuantity * e.FeeRate
+		feeCurrency = market.QuoteCurrency
+
+		if err := e.account.UseLockedBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity)); err != nil {
+			return nil, err
+		}
+
+		_ = e.account.AddBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(quoteQuantity-fee))
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.lastTradeID++
+	trade := types.Trade{
+		ID:            e.lastTradeID,
+		OrderID:       order.OrderID,
+		Exchange:      e.Exchange.Name().String(),
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Time:          now,
+		Fee:           fee,
+		FeeCurrency:   feeCurrency,
+	}
+	e.trades[order.Symbol] = append(e.trades[order.Symbol], trade)
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = quantity
+	order.IsWorking = false
+	order.UpdateTime = now
+	e.closedOrders[order.Symbol] = append(e.closedOrders[order.Symbol], order)
+	e.mu.Unlock()
+
+	log.Infof("paper trade: %s %s %f @ %f", trade.Symbol, trade.Side, trade.Quantity, trade.Price)
+
+	e.emitTradeUpdate(trade)
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+	return &order, nil
+}
+
+// closeOrder closes the unfilled order and unlocks the locked balance
+func (e *Exchange) closeOrder(order types.Order, status types.OrderStatus, market types.Market) (*types.Order, error) {
+	if err := e.unlockBalance(market, order.Side, order.Price, order.Quantity); err != nil {
+		return nil, err
+	}
+
+	order.Status = status
+	order.IsWorking = false
+	order.UpdateTime = time.Now()
+
+	e.mu.Lock()
+	e.closedOrders[order.Symbol] = append(e.closedOrders[order.Symbol], order)
+	e.mu.Unlock()
+
+	e.emitOrderUpdate(order)
+	e.emitBalanceUpdate()
+	return &order, nil
+}
+
+func (e *Exchange) lockBalance(market types.Market, side types.SideType, price, quantity float64) error {
+	switch side {
+	case types.SideTypeBuy:
+		return e.account.LockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(price*quantity))
+
+	case types.SideTypeSell:
+		return e.account.LockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity))
+	}
+
+	return fmt.Errorf("unknown order side: %s", side)
+}
+
+func (e *Exchange) unlockBalance(market types.Market, side types.SideType, price, quantity float64) error {
+	switch side {
+	case types.SideTypeBuy:
+		return e.account.UnlockBalance(market.QuoteCurrency, fixedpoint.NewFromFloat(price*quantity))
+
+	case types.SideTypeSell:
+		return e.account.UnlockBalance(market.BaseCurrency, fixedpoint.NewFromFloat(quantity))
+	}
+
+	return fmt.Errorf("unknown order side: %s", side)
+}
+
+func (e *Exchange) emitOrderUpdate(order types.Order) {
+	for _, stream := range e.getStreams() {
+		stream.EmitOrderUpdate(order)
+	}
+}
+
+func (e *Exchange) emitTradeUpdate(trade types.Trade) {
+	for _, stream := range e.getStreams() {
+		stream.EmitTradeUpdate(trade)
+	}
+}
+
+func (e *Exchange) emitBalanceUpdate() {
+	balances := e.account.Balances()
+	for _, stream := range e.getStreams() {
+		stream.EmitBalanceUpdate(balances)
+	}
+}
+
+func (e *Exchange) getStreams() []*Stream {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Stream(nil), e.streams...)
+}
+
+// bestPrice returns the best price level of the opposite side of the order side
+func bestPrice(book types.OrderBook, side types.SideType) (types.PriceVolume, bool) {
+	switch side {
+	case types.SideTypeBuy:
+		return book.BestAsk()
+	case types.SideTypeSell:
+		return book.BestBid()
+	}
+
+	return types.PriceVolume{}, false
+}
+
+// takerPrice returns the fill price if the order crosses the order book,
+// the order is filled at the best price of the opposite side without considering the depth.
+func takerPrice(book types.OrderBook, order types.Order) (float64, bool) {
+	pv, ok := bestPrice(book, order.Side)
+	if !ok {
+		return 0, false
+	}
+
+	price := pv.Price.Float64()
+	if order.Type == types.OrderTypeMarket {
+		return price, true
+	}
+
+	switch order.Side {
+	case types.SideTypeBuy:
+		return price, price <= order.Price
+	case types.SideTypeSell:
+		return price, price >= order.Price
+	}
+
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package paper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testStream struct {
+	types.StandardStream
+}
+
+func (s *testStream) SetPublicOnly()                    {}
+func (s *testStream) Connect(ctx context.Context) error { return nil }
+func (s *testStream) Close() error                      { return nil }
+
+type testExchange struct {
+	types.Exchange
+
+	stream *testStream
+}
+
+func (e *testExchange) Name() types.ExchangeName {
+	return types.ExchangeBinance
+}
+
+func (e *testExchange) NewStream() types.Stream {
+	return e.stream
+}
+
+func (e *testExchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	return types.MarketMap{
+		"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}, nil
+}
+
+func newTestBook(bid, ask float64) types.OrderBook {
+	return types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(bid), Volume: fixedpoint.NewFromFloat(1.0)}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(ask), Volume: fixedpoint.NewFromFloat(1.0)}},
+	}
+}
+
+func TestExchange_MatchOrders(t *testing.T) {
+	ctx := context.Background()
+	realStream := &testStream{}
+	exchange := New(&testExchange{stream: realStream}, types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(20000.0)},
+	})
+	exchange.FeeRate = 0
+
+	stream := exchange.NewStream()
+	stream.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: "1m"})
+	assert.NoError(t, stream.Connect(ctx))
+
+	var trades []types.Trade
+	stream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	realStream.EmitBookSnapshot(newTestBook(10000.0, 10010.0))
+
+	// the limit buy below the best ask rests on the book
+	orders, err := exchange.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit, Price: 9990.0, Quantity: 1.0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusNew, orders[0].Status)
+	assert.Empty(t, trades)
+
+	balances, _ := exchange.QueryAccountBalances(ctx)
+	assert.Equal(t, 9990.0, balances["USDT"].Locked.Float64())
+
+	// the ask moves to the order price, the resting order is filled as the maker
+	realStream.EmitBookSnapshot(newTestBook(9980.0, 9990.0))
+	if assert.Len(t, trades, 1) {
+		assert.True(t, trades[0].IsMaker)
+		assert.Equal(t, 9990.0, trades[0].Price)
+	}
+
+	openOrders, _ := exchange.QueryOpenOrders(ctx, "BTCUSDT")
+	assert.Empty(t, openOrders)
+
+	// the market sell is filled at the best bid as the taker
+	orders, err = exchange.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket, Quantity: 1.0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, types.OrderStatusFilled, orders[0].Status)
+	if assert.Len(t, trades, 2) {
+		assert.False(t, trades[1].IsMaker)
+		assert.Equal(t, 9980.0, trades[1].Price)
+	}
+
+	balances, _ = exchange.QueryAccountBalances(ctx)
+	assert.Equal(t, 20000.0-10.0, balances["USDT"].Available.Float64())
+	assert.Equal(t, 0.0, balances["BTC"].Available.Float64())
+}
@@ -0,0 +1,100 @@
+package paper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Stream forwards the market data of the real exchange stream,
+// the order updates, the trade updates and the balance updates are emitted by the paper exchange.
+type Stream struct {
+	types.StandardStream
+
+	exchange *Exchange
+
+	// stream is the public market data stream of the real exchange
+	stream types.Stream
+}
+
+func NewStream(exchange *Exchange, stream types.Stream) *Stream {
+	stream.SetPublicOnly()
+
+	s := &Stream{
+		exchange: exchange,
+		stream:   stream,
+	}
+
+	stream.OnConnect(s.EmitConnect)
+	stream.OnDisconnect(s.EmitDisconnect)
+	stream.OnKLine(s.EmitKLine)
+	stream.OnKLineClosed(s.EmitKLineClosed)
+	stream.OnBookSnapshot(s.EmitBookSnapshot)
+	stream.OnBookUpdate(s.EmitBookUpdate)
+	stream.OnMarketTrade(s.EmitMarketTrade)
+	stream.OnFundingRate(s.EmitFundingRate)
+	return s
+}
+
+// SetPublicOnly is a no-op, the real exchange stream is always public only
+func (s *Stream) SetPublicOnly() {}
+
+// ResyncBook reloads the book snapshot from the real exchange stream if it supports it
+func (s *Stream) ResyncBook(symbol string) error {
+	if resyncer, ok := s.stream.(types.BookResyncer); ok {
+		return resyncer.ResyncBook(symbol)
+	}
+
+	return fmt.Errorf("stream %T does not support the book resync", s.stream)
+}
+
+// LastMessageTime returns the time of the last message received by the real exchange stream
+func (s *Stream) LastMessageTime() time.Time {
+	if provider, ok := s.stream.(types.LivenessProvider); ok {
+		return provider.LastMessageTime()
+	}
+
+	return s.StandardStream.LastMessageTime()
+}
+
+// Reconnect drops the connection of the real exchange stream if it supports it
+func (s *Stream) Reconnect() error {
+	if reconnector, ok := s.stream.(types.Reconnector); ok {
+		return reconnector.Reconnect()
+	}
+
+	return fmt.Errorf("stream %T does not support the reconnect", s.stream)
+}
+
+func (s *Stream) Connect(ctx context.Context) error {
+	var bookSymbols = map[string]struct{}{}
+	for _, sub := range s.Subscriptions {
+		s.stream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+
+		if sub.Channel == types.BookChannel {
+			bookSymbols[sub.Symbol] = struct{}{}
+		}
+	}
+
+	// the order book is required for matching the orders
+	for _, sub := range s.Subscriptions {
+		if _, ok := bookSymbols[sub.Symbol]; ok {
+			continue
+		}
+
+		s.stream.Subscribe(types.BookChannel, sub.Symbol, types.SubscribeOptions{})
+		bookSymbols[sub.Symbol] = struct{}{}
+	}
+
+	for symbol := range bookSymbols {
+		s.exchange.bindBook(symbol, s.stream)
+	}
+
+	return s.stream.Connect(ctx)
+}
+
+func (s *Stream) Close() error {
+	return s.stream.Close()
+}
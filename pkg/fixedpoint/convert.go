@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/bits"
 	"strconv"
 	"sync/atomic"
 )
@@ -22,16 +23,86 @@ func (v Value) Int64() int64 {
 	return int64(v)
 }
 
+// Mul multiplies the values with the integer arithmetic, the result is rounded half away from zero.
+// It falls back to the float arithmetic only if the result overflows.
 func (v Value) Mul(v2 Value) Value {
-	return NewFromFloat(v.Float64() * v2.Float64())
+	a, negA := abs(int64(v))
+	b, negB := abs(int64(v2))
+	neg := negA != negB
+
+	// fast path: the product fits in int64, the division by the constant is compiled to the multiplication
+	if a < 1<<31 && b < 1<<32 {
+		return signed((a*b+DefaultPow/2)/DefaultPow, neg)
+	}
+
+	hi, lo := bits.Mul64(a, b)
+	lo, carry := bits.Add64(lo, DefaultPow/2, 0)
+	hi += carry
+	if hi >= DefaultPow {
+		return NewFromFloat(v.Float64() * v2.Float64())
+	}
+
+	q, _ := bits.Div64(hi, lo, DefaultPow)
+	if q > math.MaxInt64 {
+		return NewFromFloat(v.Float64() * v2.Float64())
+	}
+
+	return signed(q, neg)
 }
 
 func (v Value) MulFloat64(v2 float64) Value {
 	return NewFromFloat(v.Float64() * v2)
 }
 
+// Div divides the values with the integer arithmetic, the result is rounded half away from zero.
+// It falls back to the float arithmetic if the divisor is zero or the result overflows.
 func (v Value) Div(v2 Value) Value {
-	return NewFromFloat(v.Float64() / v2.Float64())
+	if v2 == 0 {
+		return NewFromFloat(v.Float64() / v2.Float64())
+	}
+
+	a, negA := abs(int64(v))
+	b, negB := abs(int64(v2))
+	neg := negA != negB
+
+	var q uint64
+
+	// fast path: the scaled dividend fits in uint64
+	if a <= (math.MaxUint64-b/2)/DefaultPow {
+		q = (a*DefaultPow + b/2) / b
+	} else {
+		hi, lo := bits.Mul64(a, DefaultPow)
+		lo, carry := bits.Add64(lo, b/2, 0)
+		hi += carry
+		if hi >= b {
+			return NewFromFloat(v.Float64() / v2.Float64())
+		}
+
+		q, _ = bits.Div64(hi, lo, b)
+	}
+
+	if q > math.MaxInt64 {
+		return NewFromFloat(v.Float64() / v2.Float64())
+	}
+
+	return signed(q, neg)
+}
+
+// abs returns the absolute value of the int64 as uint64, math.MinInt64 is handled by the two's complement
+func abs(i int64) (uint64, bool) {
+	if i < 0 {
+		return uint64(-i), true
+	}
+
+	return uint64(i), false
+}
+
+func signed(u uint64, neg bool) Value {
+	if neg {
+		return Value(-int64(u))
+	}
+
+	return Value(int64(u))
 }
 
 func (v Value) Sub(v2 Value) Value {
@@ -77,9 +148,7 @@ func (v *Value) UnmarshalYAML(unmarshal func(a interface{}) error) (err error) {
 }
 
 func (v Value) MarshalJSON() ([]byte, error) {
-	f := float64(v) / DefaultPow
-	o := strconv.FormatFloat(f, 'f', 8, 64)
-	return []byte(o), nil
+	return v.AppendFormat(make([]byte, 0, maxFormatLength), DefaultPrecision), nil
 }
 
 func (v *Value) UnmarshalJSON(data []byte) error {
@@ -101,6 +170,23 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 	case int64:
 		*v = NewFromInt64(d)
 
+	// some exchanges (e.g., kucoin) encode the numbers as strings, and null for the absent fields
+	case string:
+		if len(d) == 0 {
+			*v = 0
+			return nil
+		}
+
+		nv, err := NewFromString(d)
+		if err != nil {
+			return err
+		}
+
+		*v = nv
+
+	case nil:
+		*v = 0
+
 	default:
 		return fmt.Errorf("unsupported type: %T %v", d, d)
 
@@ -117,7 +203,13 @@ func Must(v Value, err error) Value {
 	return v
 }
 
+// NewFromString parses the decimal string without the float conversion,
+// the other formats supported by strconv.ParseFloat (e.g., the exponent notation) fall back to the float parsing.
 func NewFromString(input string) (Value, error) {
+	if v, ok := parseDecimal(input); ok {
+		return v, nil
+	}
+
 	v, err := strconv.ParseFloat(input, 64)
 	if err != nil {
 		return 0, err
@@ -141,3 +233,64 @@ func NewFromInt(val int) Value {
 func NewFromInt64(val int64) Value {
 	return Value(val * DefaultPow)
 }
+
+// maxIntPart is the max integer part of the value
+const maxIntPart = uint64(math.MaxInt64 / int64(DefaultPow))
+
+// parseDecimal parses the plain decimal string like "-123.456", the digits after the 8th decimal place are rounded,
+// it returns false if the string is not a plain decimal or it overflows.
+func parseDecimal(s string) (Value, bool) {
+	var i int
+	var neg bool
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		i++
+	}
+
+	var digits int
+	var intPart uint64
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		intPart = intPart*10 + uint64(s[i]-'0')
+		if intPart > maxIntPart {
+			return 0, false
+		}
+
+		digits++
+	}
+
+	var frac uint64
+	var fracDigits int
+	var roundUp bool
+	if i < len(s) && s[i] == '.' {
+		i++
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			if fracDigits < DefaultPrecision {
+				frac = frac*10 + uint64(s[i]-'0')
+			} else if fracDigits == DefaultPrecision {
+				roundUp = s[i] >= '5'
+			}
+
+			fracDigits++
+			digits++
+		}
+	}
+
+	if i != len(s) || digits == 0 {
+		return 0, false
+	}
+
+	if fracDigits < DefaultPrecision {
+		frac *= pow10[DefaultPrecision-fracDigits]
+	}
+
+	u := intPart*DefaultPow + frac
+	if roundUp {
+		u++
+	}
+
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+
+	return signed(u, neg), true
+}
@@ -0,0 +1,158 @@
+package fixedpoint
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_Mul(t *testing.T) {
+	assert.Equal(t, NewFromFloat(6.0), NewFromFloat(2.0).Mul(NewFromFloat(3.0)))
+	assert.Equal(t, NewFromFloat(-0.75), NewFromFloat(-1.5).Mul(NewFromFloat(0.5)))
+	assert.Equal(t, NewFromFloat(0.0001), NewFromFloat(0.01).Mul(NewFromFloat(0.01)))
+
+	// rounded half away from zero at the 8th decimal place
+	assert.Equal(t, Value(1), Value(5000).Mul(Value(10000)))
+	assert.Equal(t, Value(-1), Value(-5000).Mul(Value(10000)))
+
+	// the price and the quantity that don't fit in the fast path
+	assert.Equal(t, NewFromFloat(1234567.0*0.12345678), NewFromFloat(1234567.0).Mul(NewFromFloat(0.12345678)))
+	assert.Equal(t, NewFromFloat(45000.5*120.25), NewFromFloat(45000.5).Mul(NewFromFloat(120.25)))
+	assert.Equal(t, NewFromFloat(-45000.5*120.25), NewFromFloat(45000.5).Mul(NewFromFloat(-120.25)))
+}
+
+func TestValue_Div(t *testing.T) {
+	assert.Equal(t, NewFromFloat(2.5), NewFromFloat(5.0).Div(NewFromFloat(2.0)))
+	assert.Equal(t, NewFromFloat(-0.33333333), NewFromFloat(1.0).Div(NewFromFloat(-3.0)))
+	assert.Equal(t, NewFromFloat(0.66666667), NewFromFloat(2.0).Div(NewFromFloat(3.0)))
+	assert.Equal(t, NewFromFloat(45000.5), NewFromFloat(45000.5*120.25).Div(NewFromFloat(120.25)))
+	assert.Equal(t, NewFromFloat(90000000.0), NewFromFloat(90000.0).Div(NewFromFloat(0.001)))
+}
+
+func TestNewFromString(t *testing.T) {
+	for input, expected := range map[string]Value{
+		"0":                Value(0),
+		"1":                NewFromInt(1),
+		"-12.5":            NewFromFloat(-12.5),
+		"+0.001":           NewFromFloat(0.001),
+		".5":               NewFromFloat(0.5),
+		"1.":               NewFromInt(1),
+		"0.123456785":      Value(12345679),
+		"0.1234567849":     Value(12345678),
+		"45000.12345678":   Value(4500012345678),
+		"92233720368.5477": Value(9223372036854770000),
+		"1e-3":             NewFromFloat(0.001),
+	} {
+		v, err := NewFromString(input)
+		if assert.NoError(t, err, input) {
+			assert.Equal(t, expected, v, input)
+		}
+	}
+
+	for _, input := range []string{"", "-", ".", "1.2.3", "abc", "1,000"} {
+		_, err := NewFromString(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestValue_FormatString(t *testing.T) {
+	assert.Equal(t, "0", Value(0).FormatString(0))
+	assert.Equal(t, "1.50", NewFromFloat(1.5).FormatString(2))
+	assert.Equal(t, "-1.50", NewFromFloat(-1.5).FormatString(2))
+	assert.Equal(t, "0.12345679", NewFromFloat(0.123456789).FormatString(8))
+	assert.Equal(t, "0.1235", NewFromFloat(0.12345).FormatString(4))
+	assert.Equal(t, "-0.1235", NewFromFloat(-0.12345).FormatString(4))
+	assert.Equal(t, "0", NewFromFloat(-0.4).FormatString(0))
+	assert.Equal(t, "45001", NewFromFloat(45000.5).FormatString(0))
+	assert.Equal(t, "-92233720368.54775808", Value(math.MinInt64).FormatString(8))
+	assert.Equal(t, "92233720368.54775807", Value(math.MaxInt64).FormatString(10))
+}
+
+func TestValue_MarshalJSON(t *testing.T) {
+	for _, f := range []float64{0.0, 1.5, -1.5, 0.00000001, -0.00000001, 45000.12345678} {
+		data, err := json.Marshal(NewFromFloat(f))
+		assert.NoError(t, err)
+		assert.Equal(t, strconv.FormatFloat(f, 'f', 8, 64), string(data))
+
+		var v Value
+		assert.NoError(t, json.Unmarshal(data, &v))
+		assert.Equal(t, NewFromFloat(f), v)
+	}
+}
+
+var benchmarkValue Value
+
+func BenchmarkValue_Mul(b *testing.B) {
+	price, quantity := NewFromFloat(45000.12), NewFromFloat(0.0123)
+	b.Run("int64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchmarkValue = quantity.Mul(quantity)
+		}
+	})
+
+	b.Run("int128", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchmarkValue = price.Mul(quantity)
+		}
+	})
+
+	b.Run("float64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchmarkValue = NewFromFloat(price.Float64() * quantity.Float64())
+		}
+	})
+}
+
+func BenchmarkValue_Div(b *testing.B) {
+	amount, price := NewFromFloat(553.5), NewFromFloat(45000.12)
+	b.Run("int64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchmarkValue = amount.Div(price)
+		}
+	})
+
+	b.Run("float64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchmarkValue = NewFromFloat(amount.Float64() / price.Float64())
+		}
+	})
+}
+
+func BenchmarkNewFromString(b *testing.B) {
+	b.Run("decimal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkValue, _ = NewFromString("45000.12345678")
+		}
+	})
+
+	b.Run("float64", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f, _ := strconv.ParseFloat("45000.12345678", 64)
+			benchmarkValue = NewFromFloat(f)
+		}
+	})
+}
+
+var benchmarkString string
+
+func BenchmarkValue_FormatString(b *testing.B) {
+	v := NewFromFloat(45000.12345678)
+	b.Run("stack", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkString = v.FormatString(8)
+		}
+	})
+
+	b.Run("float64", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchmarkString = strconv.FormatFloat(v.Float64(), 'f', 8, 64)
+		}
+	})
+}
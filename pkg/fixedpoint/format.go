@@ -0,0 +1,57 @@
+package fixedpoint
+
+// maxFormatLength is the max length of the formatted value: the sign, 11 integer digits, the dot and 8 decimal places
+const maxFormatLength = 24
+
+var pow10 = [DefaultPrecision + 1]uint64{1, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8}
+
+// FormatString formats the value with the given decimal places (0 ~ 8) without the float conversion,
+// the last decimal place is rounded half away from zero.
+// The digits are formatted in the stack buffer, which is faster than a sync.Pool buffer (see BenchmarkValue_FormatString),
+// so the string is the only allocation.
+func (v Value) FormatString(prec int) string {
+	var buf [maxFormatLength]byte
+	return string(v.AppendFormat(buf[:0], prec))
+}
+
+// AppendFormat appends the formatted value to dst, see FormatString
+func (v Value) AppendFormat(dst []byte, prec int) []byte {
+	if prec < 0 {
+		prec = 0
+	} else if prec > DefaultPrecision {
+		prec = DefaultPrecision
+	}
+
+	u, neg := abs(int64(v))
+	if unit := pow10[DefaultPrecision-prec]; unit > 1 {
+		u = (u + unit/2) / unit
+	}
+
+	if neg && u != 0 {
+		dst = append(dst, '-')
+	}
+
+	dst = appendUint(dst, u/pow10[prec], 0)
+	if prec > 0 {
+		dst = append(dst, '.')
+		dst = appendUint(dst, u%pow10[prec], prec)
+	}
+
+	return dst
+}
+
+// appendUint appends the decimal digits of u, it's padded with the leading zeros to the width
+func appendUint(dst []byte, u uint64, width int) []byte {
+	var buf [20]byte
+	i := len(buf)
+	for u >= 10 || width > 1 {
+		i--
+		buf[i] = byte('0' + u%10)
+		u /= 10
+		width--
+	}
+
+	i--
+	buf[i] = byte('0' + u)
+	return append(dst, buf[i:]...)
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"strconv"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func transStrategy(info bbgo.StrategyInfo) (*pb.Strategy, error) {
+	parameters, err := info.Parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	strategy := &pb.Strategy{
+		Name:       info.Name,
+		Id:         info.ID,
+		State:      string(info.State),
+		DependsOn:  info.DependsOn,
+		Parameters: string(parameters),
+	}
+
+	if info.Error != nil {
+		strategy.Error = info.Error.Error()
+	}
+
+	return strategy, nil
+}
+
+func transOrder(order types.Order) *pb.Order {
+	return &pb.Order{
+		Exchange:         order.Exchange,
+		Symbol:           order.Symbol,
+		Id:               order.OrderID,
+		ClientOrderId:    order.ClientOrderID,
+		Side:             string(order.Side),
+		OrderType:        string(order.Type),
+		Price:            formatFloat(order.Price),
+		Quantity:         formatFloat(order.Quantity),
+		ExecutedQuantity: formatFloat(order.ExecutedQuantity),
+		Status:           string(order.Status),
+		CreationTime:     order.CreationTime.UnixNano() / int64(1e6),
+		UpdateTime:       order.UpdateTime.UnixNano() / int64(1e6),
+	}
+}
+
+func transTrade(trade types.Trade) *pb.Trade {
+	return &pb.Trade{
+		Exchange:    trade.Exchange,
+		Symbol:      trade.Symbol,
+		Id:          trade.ID,
+		OrderId:     trade.OrderID,
+		Side:        string(trade.Side),
+		Price:       formatFloat(trade.Price),
+		Quantity:    formatFloat(trade.Quantity),
+		Fee:         formatFloat(trade.Fee),
+		FeeCurrency: trade.FeeCurrency,
+		IsMaker:     trade.IsMaker,
+		TradedAt:    trade.Time.UnixNano() / int64(1e6),
+	}
+}
+
+// formatFloat formats the price and the quantity without losing the precision
+func formatFloat(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
@@ -0,0 +1,209 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/pb"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const DefaultBind = "localhost:50051"
+
+// userDataBufferSize is the event buffer of each subscriber, the events are dropped when the subscriber is too slow
+const userDataBufferSize = 256
+
+var log = logrus.WithField("component", "grpc")
+
+// Server exposes the strategy control service and the user data stream of the running trader
+type Server struct {
+	Environ *bbgo.Environment
+	Trader  *bbgo.Trader
+
+	mu          sync.Mutex
+	subscribers map[chan *pb.UserData]string
+}
+
+func (s *Server) Run(ctx context.Context, bind string) error {
+	if len(bind) == 0 {
+		bind = DefaultBind
+	}
+
+	conn, err := net.Listen("tcp", bind)
+	if err != nil {
+		return err
+	}
+
+	s.subscribers = make(map[chan *pb.UserData]string)
+	s.bindStreams()
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterStrategyServiceServer(grpcServer, &StrategyService{lifecycle: s.Trader.Lifecycle()})
+	pb.RegisterUserDataServiceServer(grpcServer, &UserDataService{server: s})
+
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
+
+	log.Infof("grpc server listening on %s", bind)
+	return grpcServer.Serve(conn)
+}
+
+// bindStreams forwards the order updates and the trades of the private sessions to the subscribers
+func (s *Server) bindStreams() {
+	for _, session := range s.Environ.Sessions() {
+		if session.PublicOnly {
+			continue
+		}
+
+		sessionName := session.Name
+
+		session.Stream.OnOrderUpdate(func(order types.Order) {
+			s.publish(&pb.UserData{Session: sessionName, Channel: "order", Order: transOrder(order)})
+		})
+
+		session.Stream.OnTradeUpdate(func(trade types.Trade) {
+			s.publish(&pb.UserData{Session: sessionName, Channel: "trade", Trade: transTrade(trade)})
+		})
+	}
+}
+
+func (s *Server) publish(data *pb.UserData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch, session := range s.subscribers {
+		if len(session) > 0 && session != data.Session {
+			continue
+		}
+
+		select {
+		case ch <- data:
+		default:
+			log.Warnf("user data subscriber is too slow, dropping the %s event of session %s", data.Channel, data.Session)
+		}
+	}
+}
+
+func (s *Server) subscribe(session string) chan *pb.UserData {
+	ch := make(chan *pb.UserData, userDataBufferSize)
+
+	s.mu.Lock()
+	s.subscribers[ch] = session
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan *pb.UserData) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+// StrategyService lists, suspends, resumes and updates the strategies through the lifecycle manager of the trader
+type StrategyService struct {
+	lifecycle *bbgo.LifecycleManager
+}
+
+func (s *StrategyService) ListStrategies(ctx context.Context, request *pb.ListStrategiesRequest) (*pb.ListStrategiesResponse, error) {
+	var response pb.ListStrategiesResponse
+	for _, info := range s.lifecycle.Strategies() {
+		strategy, err := transStrategy(info)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		response.Strategies = append(response.Strategies, strategy)
+	}
+
+	return &response, nil
+}
+
+func (s *StrategyService) SuspendStrategy(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	if _, err := s.lifecycle.Lookup(request.Name); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	info, err := s.lifecycle.Suspend(ctx, request.Name)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return strategyResponse(info)
+}
+
+func (s *StrategyService) ResumeStrategy(ctx context.Context, request *pb.StrategyRequest) (*pb.StrategyResponse, error) {
+	if _, err := s.lifecycle.Lookup(request.Name); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	info, err := s.lifecycle.Resume(ctx, request.Name)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return strategyResponse(info)
+}
+
+func (s *StrategyService) UpdateStrategyParameters(ctx context.Context, request *pb.UpdateStrategyParametersRequest) (*pb.StrategyResponse, error) {
+	info, err := s.lifecycle.Lookup(request.Name)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if _, ok := info.Strategy.(bbgo.RuntimeParameterUpdater); !ok {
+		return nil, status.Errorf(codes.Unimplemented, "strategy %s does not support updating the parameters at runtime", request.Name)
+	}
+
+	info, err = s.lifecycle.UpdateParameters(ctx, request.Name, []byte(request.Parameters))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return strategyResponse(info)
+}
+
+// UserDataService streams the order updates and the trades, filtered by the session name if it's given
+type UserDataService struct {
+	server *Server
+}
+
+func (s *UserDataService) Subscribe(request *pb.UserDataRequest, stream pb.UserDataService_SubscribeServer) error {
+	if len(request.Session) > 0 {
+		if _, ok := s.server.Environ.Session(request.Session); !ok {
+			return status.Errorf(codes.NotFound, "session %s is not found", request.Session)
+		}
+	}
+
+	ch := s.server.subscribe(request.Session)
+	defer s.server.unsubscribe(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+
+		case data := <-ch:
+			if err := stream.Send(data); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func strategyResponse(info bbgo.StrategyInfo) (*pb.StrategyResponse, error) {
+	strategy, err := transStrategy(info)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.StrategyResponse{Strategy: strategy}, nil
+}
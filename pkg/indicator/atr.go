@@ -0,0 +1,91 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+atr implements the average true range with the Wilder's smoothing:
+
+Average True Range (ATR)
+- https://www.investopedia.com/terms/a/atr.asp
+*/
+
+//go:generate callbackgen -type ATR
+type ATR struct {
+	types.IntervalWindow
+	Values  Float64Slice
+	EndTime time.Time
+
+	prevClose float64
+	value     float64
+	count     int
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Last returns the latest ATR value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *ATR) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+func (inc *ATR) update(k types.KLine) {
+	inc.count++
+
+	// the true range of the first kline is its high-low range since there is no previous close price
+	trueRange := k.High - k.Low
+	if inc.count > 1 {
+		trueRange = math.Max(trueRange, math.Max(math.Abs(k.High-inc.prevClose), math.Abs(k.Low-inc.prevClose)))
+	}
+	inc.prevClose = k.Close
+
+	var window = float64(inc.Window)
+	if inc.count <= inc.Window {
+		// the first ATR is the simple average of the true ranges
+		inc.value += trueRange
+		if inc.count < inc.Window {
+			return
+		}
+
+		inc.value /= window
+	} else {
+		inc.value = (inc.value*(window-1) + trueRange) / window
+	}
+
+	inc.Values.Push(inc.value)
+	inc.EmitUpdate(inc.value)
+}
+
+func (inc *ATR) calculateAndUpdate(kLines []types.KLine) {
+	if inc.Window <= 0 {
+		return
+	}
+
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.update(k)
+		inc.EndTime = k.EndTime
+	}
+}
+
+func (inc *ATR) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *ATR) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
@@ -39,32 +39,45 @@ type BOLL struct {
 	updateCallbacks []func(sma, upBand, downBand float64)
 }
 
-func (inc *BOLL) LastUpBand() float64 {
+// LastUpBand returns the latest up band, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *BOLL) LastUpBand() (float64, bool) {
 	if len(inc.UpBand) == 0 {
-		return 0.0
+		return 0.0, false
 	}
 
-	return inc.UpBand[len(inc.UpBand)-1]
+	return inc.UpBand[len(inc.UpBand)-1], true
 }
 
-func (inc *BOLL) LastDownBand() float64 {
+// LastDownBand returns the latest down band, ok is false if the indicator is not ready yet
+func (inc *BOLL) LastDownBand() (float64, bool) {
 	if len(inc.DownBand) == 0 {
-		return 0.0
+		return 0.0, false
 	}
 
-	return inc.DownBand[len(inc.DownBand)-1]
+	return inc.DownBand[len(inc.DownBand)-1], true
 }
 
-func (inc *BOLL) LastStdDev() float64 {
+// LastStdDev returns the latest standard deviation, ok is false if the indicator is not ready yet
+func (inc *BOLL) LastStdDev() (float64, bool) {
 	if len(inc.StdDev) == 0 {
-		return 0.0
+		return 0.0, false
 	}
 
-	return inc.StdDev[len(inc.StdDev)-1]
+	return inc.StdDev[len(inc.StdDev)-1], true
 }
 
-func (inc *BOLL) LastSMA() float64 {
-	return inc.SMA[len(inc.SMA)-1]
+// LastSMA returns the latest middle band, ok is false if the indicator is not ready yet
+func (inc *BOLL) LastSMA() (float64, bool) {
+	if len(inc.SMA) == 0 {
+		return 0.0, false
+	}
+
+	return inc.SMA[len(inc.SMA)-1], true
+}
+
+// Last returns the latest middle band, it implements the Indicator interface
+func (inc *BOLL) Last() (float64, bool) {
+	return inc.LastSMA()
 }
 
 func (inc *BOLL) calculateAndUpdate(kLines []types.KLine) {
@@ -79,8 +92,9 @@ func (inc *BOLL) calculateAndUpdate(kLines []types.KLine) {
 		return
 	}
 
+	var priceF = PriceSourceMapper(inc.PriceSource)
 	var recentK = kLines[index-(inc.Window-1) : index+1]
-	sma, err := calculateSMA(recentK, inc.Window, KLineClosePriceMapper)
+	sma, err := calculateSMA(recentK, inc.Window, priceF)
 	if err != nil {
 		log.WithError(err).Error("SMA error")
 		return
@@ -88,10 +102,7 @@ func (inc *BOLL) calculateAndUpdate(kLines []types.KLine) {
 
 	inc.SMA.Push(sma)
 
-	var prices []float64
-	for _, k := range recentK {
-		prices = append(prices, k.Close)
-	}
+	var prices = MapKLinePrice(recentK, priceF)
 
 	var std = stat.StdDev(prices, nil)
 	inc.StdDev.Push(std)
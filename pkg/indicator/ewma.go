@@ -18,12 +18,13 @@ type EWMA struct {
 	UpdateCallbacks []func(value float64)
 }
 
-func (inc *EWMA) Last() float64 {
+// Last returns the latest EWMA value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *EWMA) Last() (float64, bool) {
 	if len(inc.Values) == 0 {
-		return 0
+		return 0.0, false
 	}
 
-	return inc.Values[len(inc.Values)-1]
+	return inc.Values[len(inc.Values)-1], true
 }
 
 func (inc *EWMA) calculateAndUpdate(allKLines []types.KLine) {
@@ -32,7 +33,7 @@ func (inc *EWMA) calculateAndUpdate(allKLines []types.KLine) {
 		return
 	}
 
-	var priceF = KLineClosePriceMapper
+	var priceF = PriceSourceMapper(inc.PriceSource)
 	var dataLen = len(allKLines)
 	var multiplier = 2.0 / (float64(inc.Window) + 1)
 
@@ -99,6 +100,40 @@ func KLineClosePriceMapper(k types.KLine) float64 {
 	return k.Close
 }
 
+// KLineHL2PriceMapper maps the kline to the median price
+func KLineHL2PriceMapper(k types.KLine) float64 {
+	return (k.High + k.Low) / 2.0
+}
+
+// KLineHLC3PriceMapper maps the kline to the typical price
+func KLineHLC3PriceMapper(k types.KLine) float64 {
+	return (k.High + k.Low + k.Close) / 3.0
+}
+
+// KLineVWAPPriceMapper maps the kline to the volume weighted average price,
+// it falls back to the typical price if the kline has no volume.
+func KLineVWAPPriceMapper(k types.KLine) float64 {
+	if k.Volume == 0 || k.QuoteVolume == 0 {
+		return KLineHLC3PriceMapper(k)
+	}
+
+	return k.QuoteVolume / k.Volume
+}
+
+// PriceSourceMapper returns the kline price mapper of the price source, the close price mapper is returned by default
+func PriceSourceMapper(source types.PriceSource) KLinePriceMapper {
+	switch source {
+	case types.PriceSourceHL2:
+		return KLineHL2PriceMapper
+	case types.PriceSourceHLC3:
+		return KLineHLC3PriceMapper
+	case types.PriceSourceVWAP:
+		return KLineVWAPPriceMapper
+	}
+
+	return KLineClosePriceMapper
+}
+
 func MapKLinePrice(kLines []types.KLine, f KLinePriceMapper) (prices []float64) {
 	for _, k := range kLines {
 		prices = append(prices, f(k))
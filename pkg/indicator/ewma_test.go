@@ -1071,3 +1071,58 @@ func Test_calculateEWMA(t *testing.T) {
 		})
 	}
 }
+
+func TestPriceSourceMapper(t *testing.T) {
+	k := types.KLine{Open: 10.0, High: 14.0, Low: 8.0, Close: 11.0, Volume: 2.0, QuoteVolume: 21.0}
+	tests := []struct {
+		source types.PriceSource
+		want   float64
+	}{
+		{source: "", want: 11.0},
+		{source: types.PriceSourceClose, want: 11.0},
+		{source: types.PriceSourceHL2, want: 11.0},
+		{source: types.PriceSourceHLC3, want: 11.0},
+		{source: types.PriceSourceVWAP, want: 10.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source.String(), func(t *testing.T) {
+			if got := PriceSourceMapper(tt.source)(k); got != tt.want {
+				t.Errorf("PriceSourceMapper(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+
+	// the typical price is used when the kline has no volume
+	if got := KLineVWAPPriceMapper(types.KLine{High: 15.0, Low: 9.0, Close: 12.0}); got != 12.0 {
+		t.Errorf("KLineVWAPPriceMapper() = %v, want %v", got, 12.0)
+	}
+}
+
+func TestIndicatorLast_NotReady(t *testing.T) {
+	iw := types.IntervalWindow{Interval: types.Interval5m, Window: 99}
+	kLines := buildKLines(ethusdt5m[:10])
+
+	ewma := &EWMA{IntervalWindow: iw}
+	ewma.calculateAndUpdate(kLines)
+	if v, ok := ewma.Last(); ok {
+		t.Errorf("EWMA.Last() = %v, want not ready", v)
+	}
+
+	sma := &SMA{IntervalWindow: iw}
+	sma.calculateAndUpdate(kLines)
+	if v, ok := sma.Last(); ok {
+		t.Errorf("SMA.Last() = %v, want not ready", v)
+	}
+
+	boll := &BOLL{IntervalWindow: iw, K: 2.0}
+	boll.calculateAndUpdate(kLines)
+	if v, ok := boll.LastDownBand(); ok {
+		t.Errorf("BOLL.LastDownBand() = %v, want not ready", v)
+	}
+
+	sma = &SMA{IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 7}}
+	sma.calculateAndUpdate(kLines)
+	if _, ok := sma.Last(); !ok {
+		t.Errorf("SMA.Last() is not ready, want ready")
+	}
+}
@@ -0,0 +1,62 @@
+package indicator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	// make sure the built-in indicators implement the Indicator interface
+	_ = Indicator(&SMA{})
+	_ = Indicator(&EWMA{})
+	_ = Indicator(&BOLL{})
+	_ = Indicator(&RSI{})
+	_ = Indicator(&MACD{})
+	_ = Indicator(&ATR{})
+	_ = Indicator(&VWAP{})
+}
+
+// Indicator is the interface of the indicators fed by the kline windows of the market data store,
+// the custom indicators implementing it can be registered and shared through the standard indicator set.
+type Indicator interface {
+	// Bind subscribes the kline window updates, the indicator should only handle the interval of its own
+	Bind(updater KLineWindowUpdater)
+
+	// Last returns the latest value, ok is false if the indicator is not ready yet
+	Last() (float64, bool)
+}
+
+// Factory creates the indicator of the interval window
+type Factory func(iw types.IntervalWindow) Indicator
+
+var factoriesMutex sync.Mutex
+
+var factories = make(map[string]Factory)
+
+// Register registers the factory of the custom indicator, it's usually called in the init function of the package
+// that implements the indicator. It panics if the name is already registered.
+func Register(name string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+
+	if _, exists := factories[name]; exists {
+		panic(fmt.Errorf("indicator %s is already registered", name))
+	}
+
+	factories[name] = factory
+}
+
+// New creates the registered custom indicator of the given name
+func New(name string, iw types.IntervalWindow) (Indicator, error) {
+	factoriesMutex.Lock()
+	factory, ok := factories[name]
+	factoriesMutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("indicator %s is not registered", name)
+	}
+
+	return factory(iw), nil
+}
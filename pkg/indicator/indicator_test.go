@@ -0,0 +1,69 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// highest is a custom indicator of the highest high price of the window
+type highest struct {
+	types.IntervalWindow
+	Values Float64Slice
+}
+
+func (inc *highest) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+func (inc *highest) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(func(interval types.Interval, window types.KLineWindow) {
+		if interval != inc.Interval || len(window) < inc.Window {
+			return
+		}
+
+		inc.Values.Push(window[len(window)-inc.Window:].GetHigh())
+	})
+}
+
+type fakeKLineWindowUpdater struct {
+	callbacks []func(interval types.Interval, window types.KLineWindow)
+}
+
+func (u *fakeKLineWindowUpdater) OnKLineWindowUpdate(cb func(interval types.Interval, window types.KLineWindow)) {
+	u.callbacks = append(u.callbacks, cb)
+}
+
+func TestRegister(t *testing.T) {
+	Register("highest", func(iw types.IntervalWindow) Indicator {
+		return &highest{IntervalWindow: iw}
+	})
+
+	inc, err := New("highest", types.IntervalWindow{Interval: types.Interval1m, Window: 2})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	updater := &fakeKLineWindowUpdater{}
+	inc.Bind(updater)
+
+	var window types.KLineWindow
+	for _, high := range []float64{3.0, 5.0, 4.0, 2.0} {
+		window.Add(types.KLine{Interval: types.Interval1m, High: high})
+		for _, cb := range updater.callbacks {
+			cb(types.Interval1m, window)
+		}
+	}
+
+	if v, ok := inc.Last(); !ok || v != 4.0 {
+		t.Errorf("Last() = %v, %v, want 4", v, ok)
+	}
+
+	if _, err := New("lowest", types.IntervalWindow{Interval: types.Interval1m, Window: 2}); err == nil {
+		t.Errorf("New() of the unregistered indicator should return an error")
+	}
+}
@@ -0,0 +1,109 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRSI(t *testing.T) {
+	iw := types.IntervalWindow{Interval: types.Interval5m, Window: 14}
+
+	rsi := &RSI{IntervalWindow: iw}
+	rsi.calculateAndUpdate(buildKLines([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}))
+	if v, ok := rsi.Last(); !ok || v != 100.0 {
+		t.Errorf("RSI.Last() = %v, %v, want 100", v, ok)
+	}
+
+	// the same gains and losses
+	var prices []float64
+	for i := 0; i < 30; i++ {
+		prices = append(prices, 10.0+float64(i%2))
+	}
+
+	rsi = &RSI{IntervalWindow: iw}
+	rsi.calculateAndUpdate(buildKLines(prices))
+	if v, ok := rsi.Last(); !ok || math.Abs(v-50.0) > 5.0 {
+		t.Errorf("RSI.Last() = %v, %v, want about 50", v, ok)
+	}
+
+	rsi = &RSI{IntervalWindow: iw}
+	rsi.calculateAndUpdate(buildKLines(prices[:14]))
+	if v, ok := rsi.Last(); ok {
+		t.Errorf("RSI.Last() = %v, want not ready", v)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	kLines := buildKLines(ethusdt5m)
+
+	macd := &MACD{
+		IntervalWindow: types.IntervalWindow{Interval: types.Interval5m, Window: 9},
+		ShortPeriod:    12,
+		LongPeriod:     26,
+	}
+	macd.calculateAndUpdate(kLines)
+
+	got, ok := macd.Last()
+	if !ok {
+		t.Fatalf("MACD.Last() is not ready, want ready")
+	}
+
+	want := CalculateKLinesEMA(kLines, KLineClosePriceMapper, 12) - CalculateKLinesEMA(kLines, KLineClosePriceMapper, 26)
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("MACD.Last() = %v, want %v", got, want)
+	}
+
+	signal, _ := macd.LastSignal()
+	histogram, _ := macd.LastHistogram()
+	if math.Abs(histogram-(got-signal)) > 1e-9 {
+		t.Errorf("MACD.LastHistogram() = %v, want %v", histogram, got-signal)
+	}
+
+	if len(macd.Values) != len(kLines)-25 {
+		t.Errorf("MACD values length = %d, want %d", len(macd.Values), len(kLines)-25)
+	}
+}
+
+func TestATR(t *testing.T) {
+	var kLines []types.KLine
+	for i := 0; i < 20; i++ {
+		kLines = append(kLines, types.KLine{High: 11.0, Low: 9.0, Close: 10.0})
+	}
+
+	// the gap from the previous close price is included in the true range
+	kLines = append(kLines, types.KLine{High: 16.0, Low: 15.0, Close: 15.5})
+
+	atr := &ATR{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 14}}
+	atr.calculateAndUpdate(kLines[:20])
+	if v, ok := atr.Last(); !ok || v != 2.0 {
+		t.Errorf("ATR.Last() = %v, %v, want 2", v, ok)
+	}
+
+	atr = &ATR{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 14}}
+	atr.calculateAndUpdate(kLines)
+	if v, _ := atr.Last(); math.Abs(v-(2.0*13+6.0)/14) > 1e-9 {
+		t.Errorf("ATR.Last() = %v, want %v", v, (2.0*13+6.0)/14)
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	kLines := []types.KLine{
+		{High: 12.0, Low: 8.0, Close: 10.0, Volume: 100.0},
+		{High: 22.0, Low: 18.0, Close: 20.0, Volume: 300.0},
+		{High: 33.0, Low: 27.0, Close: 30.0, Volume: 0.0},
+	}
+
+	vwap := &VWAP{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 3}}
+	vwap.calculateAndUpdate(kLines)
+	if v, ok := vwap.Last(); !ok || v != 17.5 {
+		t.Errorf("VWAP.Last() = %v, %v, want 17.5", v, ok)
+	}
+
+	vwap = &VWAP{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 1}}
+	vwap.calculateAndUpdate(kLines)
+	if v, ok := vwap.Last(); ok {
+		t.Errorf("VWAP.Last() = %v, want not ready without volume", v)
+	}
+}
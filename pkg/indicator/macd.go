@@ -0,0 +1,129 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+macd implements the moving average convergence divergence:
+
+Moving Average Convergence Divergence (MACD)
+- https://www.investopedia.com/terms/m/macd.asp
+
+The window of the interval window is the signal period, generally it's 9.
+*/
+
+//go:generate callbackgen -type MACD
+type MACD struct {
+	types.IntervalWindow
+
+	// ShortPeriod and LongPeriod are the EMA periods of the MACD line, generally they're 12 and 26
+	ShortPeriod int
+	LongPeriod  int
+
+	Values     Float64Slice
+	Signals    Float64Slice
+	Histograms Float64Slice
+
+	EndTime time.Time
+
+	fastEMA   float64
+	slowEMA   float64
+	signalEMA float64
+	count     int
+
+	updateCallbacks []func(macd, signal, histogram float64)
+}
+
+// Last returns the latest MACD line value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *MACD) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+// LastSignal returns the latest signal line value, ok is false if the indicator is not ready yet
+func (inc *MACD) LastSignal() (float64, bool) {
+	if len(inc.Signals) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Signals[len(inc.Signals)-1], true
+}
+
+// LastHistogram returns the latest histogram value (MACD - signal), ok is false if the indicator is not ready yet
+func (inc *MACD) LastHistogram() (float64, bool) {
+	if len(inc.Histograms) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Histograms[len(inc.Histograms)-1], true
+}
+
+func (inc *MACD) update(price float64) {
+	inc.count++
+	if inc.count == 1 {
+		// the EMAs are initialized with the first price, the same as EWMA
+		inc.fastEMA = price
+		inc.slowEMA = price
+	} else {
+		inc.fastEMA = nextEMA(inc.fastEMA, price, inc.ShortPeriod)
+		inc.slowEMA = nextEMA(inc.slowEMA, price, inc.LongPeriod)
+	}
+
+	// the MACD line is emitted after the slow EMA has received enough prices
+	if inc.count < inc.LongPeriod {
+		return
+	}
+
+	macd := inc.fastEMA - inc.slowEMA
+	if inc.count == inc.LongPeriod {
+		inc.signalEMA = macd
+	} else {
+		inc.signalEMA = nextEMA(inc.signalEMA, macd, inc.Window)
+	}
+
+	histogram := macd - inc.signalEMA
+
+	inc.Values.Push(macd)
+	inc.Signals.Push(inc.signalEMA)
+	inc.Histograms.Push(histogram)
+	inc.EmitUpdate(macd, inc.signalEMA, histogram)
+}
+
+func nextEMA(prev, price float64, period int) float64 {
+	multiplier := 2.0 / (float64(period) + 1)
+	return price*multiplier + (1-multiplier)*prev
+}
+
+func (inc *MACD) calculateAndUpdate(kLines []types.KLine) {
+	if inc.Window <= 0 || inc.ShortPeriod <= 0 || inc.LongPeriod <= 0 {
+		return
+	}
+
+	var priceF = PriceSourceMapper(inc.PriceSource)
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.update(priceF(k))
+		inc.EndTime = k.EndTime
+	}
+}
+
+func (inc *MACD) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *MACD) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
@@ -0,0 +1,41 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MarketTradeUpdater is the source of the public market trades, e.g., the market data store
+type MarketTradeUpdater interface {
+	OnMarketTrade(cb func(trade types.Trade))
+}
+
+// marketTradeWindow keeps the public trades within the time window of the latest trade
+type marketTradeWindow struct {
+	Window time.Duration
+	Trades []types.Trade
+}
+
+// push appends the trade and drops the trades out of the time window, the out-of-order trades are ignored
+func (w *marketTradeWindow) push(trade types.Trade) bool {
+	if n := len(w.Trades); n > 0 && trade.Time.Before(w.Trades[n-1].Time) {
+		return false
+	}
+
+	w.Trades = append(w.Trades, trade)
+
+	since := trade.Time.Add(-w.Window)
+	var i = 0
+	for ; i < len(w.Trades); i++ {
+		if w.Trades[i].Time.After(since) {
+			break
+		}
+	}
+
+	if i > 0 {
+		w.Trades = append([]types.Trade{}, w.Trades[i:]...)
+	}
+
+	return true
+}
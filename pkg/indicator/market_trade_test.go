@@ -0,0 +1,51 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTradeVelocity_VolumeImbalance(t *testing.T) {
+	now := time.Now()
+	trades := []types.Trade{
+		{Symbol: "BTCUSDT", Quantity: 1.0, Side: types.SideTypeBuy, Time: now},
+		{Symbol: "BTCUSDT", Quantity: 3.0, Side: types.SideTypeSell, Time: now.Add(2 * time.Second)},
+		{Symbol: "BTCUSDT", Quantity: 2.0, Side: types.SideTypeBuy, Time: now.Add(5 * time.Second)},
+		// the first trade is out of the window
+		{Symbol: "BTCUSDT", Quantity: 4.0, Side: types.SideTypeBuy, Time: now.Add(10 * time.Second)},
+		// the out-of-order trade is ignored
+		{Symbol: "BTCUSDT", Quantity: 1.0, Side: types.SideTypeSell, Time: now.Add(9 * time.Second)},
+	}
+
+	velocity := &TradeVelocity{Window: 10 * time.Second}
+	imbalance := &VolumeImbalance{Window: 10 * time.Second}
+
+	_, ok := velocity.Last()
+	assert.False(t, ok)
+
+	for _, trade := range trades {
+		velocity.Update(trade)
+		imbalance.Update(trade)
+	}
+
+	assert.Len(t, velocity.Values, 4)
+
+	value, ok := velocity.Last()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.3, value, 1e-9)
+
+	volume, ok := velocity.LastVolume()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.9, volume, 1e-9)
+
+	assert.InDelta(t, 1.0, imbalance.Values[0], 1e-9)
+	assert.InDelta(t, -0.5, imbalance.Values[1], 1e-9)
+
+	value, ok = imbalance.Last()
+	assert.True(t, ok)
+	assert.InDelta(t, (6.0-3.0)/9.0, value, 1e-9)
+}
@@ -0,0 +1,108 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+rsi implements the relative strength index with the Wilder's smoothing:
+
+Relative Strength Index (RSI)
+- https://www.investopedia.com/terms/r/rsi.asp
+*/
+
+//go:generate callbackgen -type RSI
+type RSI struct {
+	types.IntervalWindow
+	Values  Float64Slice
+	EndTime time.Time
+
+	prevPrice float64
+	avgGain   float64
+	avgLoss   float64
+	count     int
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Last returns the latest RSI value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *RSI) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+func (inc *RSI) update(price float64) {
+	inc.count++
+	if inc.count == 1 {
+		inc.prevPrice = price
+		return
+	}
+
+	change := price - inc.prevPrice
+	inc.prevPrice = price
+
+	var gain, loss float64
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	var window = float64(inc.Window)
+	var changes = inc.count - 1
+	if changes <= inc.Window {
+		// the first average is the simple average of the changes
+		inc.avgGain += gain
+		inc.avgLoss += loss
+		if changes < inc.Window {
+			return
+		}
+
+		inc.avgGain /= window
+		inc.avgLoss /= window
+	} else {
+		inc.avgGain = (inc.avgGain*(window-1) + gain) / window
+		inc.avgLoss = (inc.avgLoss*(window-1) + loss) / window
+	}
+
+	var rsi = 100.0
+	if inc.avgLoss > 0 {
+		rsi = 100.0 - 100.0/(1.0+inc.avgGain/inc.avgLoss)
+	}
+
+	inc.Values.Push(rsi)
+	inc.EmitUpdate(rsi)
+}
+
+func (inc *RSI) calculateAndUpdate(kLines []types.KLine) {
+	if inc.Window <= 0 {
+		return
+	}
+
+	var priceF = PriceSourceMapper(inc.PriceSource)
+	for _, k := range kLines {
+		if inc.EndTime != zeroTime && !k.EndTime.After(inc.EndTime) {
+			continue
+		}
+
+		inc.update(priceF(k))
+		inc.EndTime = k.EndTime
+	}
+}
+
+func (inc *RSI) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *RSI) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
@@ -26,8 +26,13 @@ type SMA struct {
 	UpdateCallbacks []func(value float64)
 }
 
-func (inc *SMA) Last() float64 {
-	return inc.Values[len(inc.Values)-1]
+// Last returns the latest SMA value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *SMA) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
 }
 
 func (inc *SMA) calculateAndUpdate(kLines []types.KLine) {
@@ -44,7 +49,7 @@ func (inc *SMA) calculateAndUpdate(kLines []types.KLine) {
 
 	var recentK = kLines[index-(inc.Window-1) : index+1]
 
-	sma, err := calculateSMA(recentK, inc.Window, KLineClosePriceMapper)
+	sma, err := calculateSMA(recentK, inc.Window, PriceSourceMapper(inc.PriceSource))
 	if err != nil {
 		log.WithError(err).Error("SMA error")
 		return
@@ -69,7 +74,7 @@ func (inc *SMA) Bind(updater KLineWindowUpdater) {
 
 func calculateSMA(kLines []types.KLine, window int, priceF KLinePriceMapper) (float64, error) {
 	length := len(kLines)
-	if length == 0 || length < window {
+	if window <= 0 || length == 0 || length < window {
 		return 0.0, fmt.Errorf("insufficient elements for calculating SMA with window = %d", window)
 	}
 
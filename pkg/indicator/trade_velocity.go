@@ -0,0 +1,72 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+trade_velocity implements the trade velocity of the public trades in the rolling time window,
+the velocity is the number of trades per second, and the volume velocity is the traded base volume per second.
+*/
+
+//go:generate callbackgen -type TradeVelocity
+type TradeVelocity struct {
+	// Window is the time window of the recent trades, e.g., 10 seconds
+	Window time.Duration
+
+	Values       Float64Slice
+	VolumeValues Float64Slice
+
+	trades marketTradeWindow
+
+	UpdateCallbacks []func(velocity, volumeVelocity float64)
+}
+
+// Last returns the latest number of trades per second, ok is false if no trade is received
+func (inc *TradeVelocity) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+// LastVolume returns the latest traded volume per second, ok is false if no trade is received
+func (inc *TradeVelocity) LastVolume() (float64, bool) {
+	if len(inc.VolumeValues) == 0 {
+		return 0.0, false
+	}
+
+	return inc.VolumeValues[len(inc.VolumeValues)-1], true
+}
+
+func (inc *TradeVelocity) Update(trade types.Trade) {
+	if inc.Window <= 0 {
+		return
+	}
+
+	inc.trades.Window = inc.Window
+	if !inc.trades.push(trade) {
+		return
+	}
+
+	var volume float64
+	for _, t := range inc.trades.Trades {
+		volume += t.Quantity
+	}
+
+	seconds := inc.Window.Seconds()
+	velocity := float64(len(inc.trades.Trades)) / seconds
+	volumeVelocity := volume / seconds
+
+	inc.Values.Push(velocity)
+	inc.VolumeValues.Push(volumeVelocity)
+
+	inc.EmitUpdate(velocity, volumeVelocity)
+}
+
+func (inc *TradeVelocity) Bind(updater MarketTradeUpdater) {
+	updater.OnMarketTrade(inc.Update)
+}
@@ -0,0 +1,70 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+volume_imbalance implements the taker volume imbalance of the public trades in the rolling time window:
+
+	imbalance = (taker buy volume - taker sell volume) / (taker buy volume + taker sell volume)
+
+the value ranges from -1 (all the takers sell) to 1 (all the takers buy).
+*/
+
+//go:generate callbackgen -type VolumeImbalance
+type VolumeImbalance struct {
+	// Window is the time window of the recent trades, e.g., 1 minute
+	Window time.Duration
+
+	Values Float64Slice
+
+	trades marketTradeWindow
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Last returns the latest volume imbalance, ok is false if no trade is received
+func (inc *VolumeImbalance) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+func (inc *VolumeImbalance) Update(trade types.Trade) {
+	if inc.Window <= 0 {
+		return
+	}
+
+	inc.trades.Window = inc.Window
+	if !inc.trades.push(trade) {
+		return
+	}
+
+	var buyVolume, sellVolume float64
+	for _, t := range inc.trades.Trades {
+		switch t.Side {
+		case types.SideTypeBuy:
+			buyVolume += t.Quantity
+		case types.SideTypeSell:
+			sellVolume += t.Quantity
+		}
+	}
+
+	total := buyVolume + sellVolume
+	if total == 0 {
+		return
+	}
+
+	imbalance := (buyVolume - sellVolume) / total
+	inc.Values.Push(imbalance)
+	inc.EmitUpdate(imbalance)
+}
+
+func (inc *VolumeImbalance) Bind(updater MarketTradeUpdater) {
+	updater.OnMarketTrade(inc.Update)
+}
@@ -0,0 +1,83 @@
+package indicator
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+/*
+vwap implements the rolling volume weighted average price of the window:
+
+Volume-Weighted Average Price (VWAP)
+- https://www.investopedia.com/terms/v/vwap.asp
+*/
+
+//go:generate callbackgen -type VWAP
+type VWAP struct {
+	types.IntervalWindow
+	Values  Float64Slice
+	EndTime time.Time
+
+	UpdateCallbacks []func(value float64)
+}
+
+// Last returns the latest VWAP value, ok is false if the indicator is not ready yet (not enough klines are received)
+func (inc *VWAP) Last() (float64, bool) {
+	if len(inc.Values) == 0 {
+		return 0.0, false
+	}
+
+	return inc.Values[len(inc.Values)-1], true
+}
+
+func (inc *VWAP) calculateAndUpdate(kLines []types.KLine) {
+	if inc.Window <= 0 || len(kLines) < inc.Window {
+		return
+	}
+
+	var index = len(kLines) - 1
+	var kline = kLines[index]
+
+	if inc.EndTime != zeroTime && kline.EndTime.Before(inc.EndTime) {
+		return
+	}
+
+	var recentK = kLines[index-(inc.Window-1) : index+1]
+	vwap, ok := calculateVWAP(recentK)
+	if !ok {
+		return
+	}
+
+	inc.Values.Push(vwap)
+	inc.EndTime = kline.EndTime
+
+	inc.EmitUpdate(vwap)
+}
+
+// calculateVWAP weights the typical price of the klines by the volume, ok is false if there is no volume
+func calculateVWAP(kLines []types.KLine) (float64, bool) {
+	var pv, volume float64
+	for _, k := range kLines {
+		pv += KLineHLC3PriceMapper(k) * k.Volume
+		volume += k.Volume
+	}
+
+	if volume == 0 {
+		return 0.0, false
+	}
+
+	return pv / volume, true
+}
+
+func (inc *VWAP) handleKLineWindowUpdate(interval types.Interval, window types.KLineWindow) {
+	if inc.Interval != interval {
+		return
+	}
+
+	inc.calculateAndUpdate(window)
+}
+
+func (inc *VWAP) Bind(updater KLineWindowUpdater) {
+	updater.OnKLineWindowUpdate(inc.handleKLineWindowUpdate)
+}
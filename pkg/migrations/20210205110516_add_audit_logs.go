@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	rockhopper.AddMigration(upAddAuditLogs, downAddAuditLogs)
+}
+
+func upAddAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `audit_logs`\n(\n    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `exchange` VARCHAR(24)     NOT NULL DEFAULT '',\n    `session`  VARCHAR(32)     NOT NULL DEFAULT '',\n    `action`   VARCHAR(32)     NOT NULL,\n    `params`   TEXT            NOT NULL,\n    `result`   TEXT            NOT NULL,\n    `error`    TEXT            NOT NULL,\n    `time`     DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `audit_logs_time` (`time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
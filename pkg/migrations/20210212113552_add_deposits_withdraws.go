@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	rockhopper.AddMigration(upAddDepositsWithdraws, downAddDepositsWithdraws)
+}
+
+func upAddDepositsWithdraws(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `deposits`\n(\n    `gid`         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `exchange`    VARCHAR(24)     NOT NULL,\n    `asset`       VARCHAR(10)     NOT NULL,\n    `address`     VARCHAR(128)    NOT NULL DEFAULT '',\n    `address_tag` VARCHAR(128)    NOT NULL DEFAULT '',\n    `amount`      DECIMAL(16, 8)  NOT NULL,\n    `txn_id`      VARCHAR(256)    NOT NULL DEFAULT '',\n    `status`      VARCHAR(32)     NOT NULL DEFAULT '',\n    `time`        DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `deposits_txn_id` (`exchange`, `asset`, `txn_id`, `time`),\n    INDEX `deposits_time` (`exchange`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `withdraws`\n(\n    `gid`               BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `exchange`          VARCHAR(24)     NOT NULL,\n    `asset`             VARCHAR(10)     NOT NULL,\n    `address`           VARCHAR(128)    NOT NULL DEFAULT '',\n    `address_tag`       VARCHAR(128)    NOT NULL DEFAULT '',\n    `network`           VARCHAR(32)     NOT NULL DEFAULT '',\n    `amount`            DECIMAL(16, 8)  NOT NULL,\n    `txn_fee`           DECIMAL(16, 8)  NOT NULL DEFAULT 0,\n    `txn_id`            VARCHAR(256)    NOT NULL DEFAULT '',\n    `withdraw_order_id` VARCHAR(64)     NOT NULL DEFAULT '',\n    `status`            VARCHAR(32)     NOT NULL DEFAULT '',\n    `time`              DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `withdraws_txn_id` (`exchange`, `asset`, `txn_id`, `time`),\n    INDEX `withdraws_time` (`exchange`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddDepositsWithdraws(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `deposits`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `withdraws`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
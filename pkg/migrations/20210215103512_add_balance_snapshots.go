@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	rockhopper.AddMigration(upAddBalanceSnapshots, downAddBalanceSnapshots)
+}
+
+func upAddBalanceSnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `account_value_snapshots`\n(\n    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `session`        VARCHAR(32)     NOT NULL,\n    `exchange`       VARCHAR(24)     NOT NULL,\n    `quote_currency` VARCHAR(10)     NOT NULL,\n    `net_value`      DECIMAL(20, 8)  NOT NULL,\n    `time`           DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `account_value_snapshots_time` (`session`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `balance_snapshots`\n(\n    `gid`            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `session`        VARCHAR(32)     NOT NULL,\n    `exchange`       VARCHAR(24)     NOT NULL,\n    `currency`       VARCHAR(10)     NOT NULL,\n    `total`          DECIMAL(20, 8)  NOT NULL,\n    `borrowed`       DECIMAL(20, 8)  NOT NULL DEFAULT 0,\n    `price`          DECIMAL(20, 8)  NOT NULL,\n    `value`          DECIMAL(20, 8)  NOT NULL,\n    `quote_currency` VARCHAR(10)     NOT NULL,\n    `time`           DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    INDEX `balance_snapshots_time` (`session`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddBalanceSnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `account_value_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `balance_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
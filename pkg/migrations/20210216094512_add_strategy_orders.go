@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	rockhopper.AddMigration(upAddStrategyOrders, downAddStrategyOrders)
+}
+
+func upAddStrategyOrders(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `strategy_orders`\n(\n    `gid`             BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n    `session`         VARCHAR(32)     NOT NULL,\n    `exchange`        VARCHAR(24)     NOT NULL,\n    `local_id`        BIGINT UNSIGNED NOT NULL,\n    `strategy`        VARCHAR(64)     NOT NULL,\n    `order_id`        BIGINT UNSIGNED NOT NULL,\n    `client_order_id` VARCHAR(64)     NOT NULL DEFAULT '',\n    `symbol`          VARCHAR(20)     NOT NULL,\n    `side`            VARCHAR(4)      NOT NULL,\n    `order_type`      VARCHAR(16)     NOT NULL,\n    `price`           DECIMAL(16, 8)  NOT NULL DEFAULT 0,\n    `quantity`        DECIMAL(16, 8)  NOT NULL,\n    `created_at`      DATETIME(3)     NOT NULL,\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `strategy_orders_local_id` (`session`, `local_id`),\n    INDEX `strategy_orders_strategy` (`strategy`, `created_at`),\n    INDEX `strategy_orders_order_id` (`exchange`, `order_id`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddStrategyOrders(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `strategy_orders`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,33 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upTradesAddOrderId, downTradesAddOrderId)
+}
+
+func upTradesAddOrderId(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `trades` ADD COLUMN `order_id` INTEGER NOT NULL DEFAULT 0;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downTradesAddOrderId(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `trades` DROP COLUMN `order_id`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
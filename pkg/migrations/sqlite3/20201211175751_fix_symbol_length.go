@@ -0,0 +1,23 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upFixSymbolLength, downFixSymbolLength)
+}
+
+func upFixSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	return err
+}
+
+func downFixSymbolLength(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	return err
+}
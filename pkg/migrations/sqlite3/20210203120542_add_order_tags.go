@@ -0,0 +1,43 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddOrderTags, downAddOrderTags)
+}
+
+func upAddOrderTags(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `orders` ADD COLUMN `tags` VARCHAR(255) NOT NULL DEFAULT '';")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `trades` ADD COLUMN `tags` VARCHAR(255) NOT NULL DEFAULT '';")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddOrderTags(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `orders` DROP COLUMN `tags`;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE `trades` DROP COLUMN `tags`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
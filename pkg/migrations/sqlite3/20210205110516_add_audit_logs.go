@@ -0,0 +1,38 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddAuditLogs, downAddAuditLogs)
+}
+
+func upAddAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `audit_logs`\n(\n    `gid`      INTEGER PRIMARY KEY AUTOINCREMENT,\n\n    `exchange` VARCHAR(24) NOT NULL DEFAULT '',\n    `session`  VARCHAR(32) NOT NULL DEFAULT '',\n    `action`   VARCHAR(32) NOT NULL,\n    `params`   TEXT        NOT NULL,\n    `result`   TEXT        NOT NULL,\n    `error`    TEXT        NOT NULL,\n    `time`     DATETIME    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `audit_logs_time` ON `audit_logs` (`time`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
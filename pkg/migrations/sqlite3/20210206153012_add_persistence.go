@@ -0,0 +1,33 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddPersistence, downAddPersistence)
+}
+
+func upAddPersistence(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `persistence`\n(\n    `id`         VARCHAR(255) NOT NULL PRIMARY KEY,\n    `data`       TEXT         NOT NULL,\n    `updated_at` DATETIME     NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddPersistence(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `persistence`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,33 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddTradesOrderIndex, downAddTradesOrderIndex)
+}
+
+func upAddTradesOrderIndex(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_exchange_order_id ON trades (exchange, order_id);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddTradesOrderIndex(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP INDEX trades_exchange_order_id;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,48 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upAddStrategyOrders, downAddStrategyOrders)
+}
+
+func upAddStrategyOrders(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `strategy_orders`\n(\n    `gid`             INTEGER PRIMARY KEY AUTOINCREMENT,\n    `session`         VARCHAR(32)    NOT NULL,\n    `exchange`        VARCHAR(24)    NOT NULL,\n    `local_id`        INTEGER        NOT NULL,\n    `strategy`        VARCHAR(64)    NOT NULL,\n    `order_id`        INTEGER        NOT NULL,\n    `client_order_id` VARCHAR(64)    NOT NULL DEFAULT '',\n    `symbol`          VARCHAR(20)    NOT NULL,\n    `side`            VARCHAR(4)     NOT NULL,\n    `order_type`      VARCHAR(16)    NOT NULL,\n    `price`           DECIMAL(16, 8) NOT NULL DEFAULT 0,\n    `quantity`        DECIMAL(16, 8) NOT NULL,\n    `created_at`      DATETIME       NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX `strategy_orders_local_id` ON `strategy_orders` (`session`, `local_id`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `strategy_orders_strategy` ON `strategy_orders` (`strategy`, `created_at`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `strategy_orders_order_id` ON `strategy_orders` (`exchange`, `order_id`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downAddStrategyOrders(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE `strategy_orders`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
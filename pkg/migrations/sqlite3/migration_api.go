@@ -0,0 +1,66 @@
+// Package sqlite3 contains the SQLite migrations compiled from migrations/sqlite3.
+//
+// The migrations share the versions of the MySQL migrations, so they are registered into the registry of this package
+// instead of the global rockhopper registry, which is used by the MySQL migrations.
+package sqlite3
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/c9s/rockhopper"
+)
+
+var registeredGoMigrations = map[int64]*rockhopper.Migration{}
+
+// Migrations returns the registered migrations sorted by the version
+func Migrations() rockhopper.MigrationSlice {
+	var migrations = rockhopper.MigrationSlice{}
+	for _, m := range registeredGoMigrations {
+		migrations = append(migrations, m)
+	}
+
+	return migrations.SortAndConnect()
+}
+
+// AddMigration registers the migration, the version is parsed from the file name of the caller, e.g., 20210216094512_add_strategy_orders.go
+func AddMigration(up, down func(ctx context.Context, tx rockhopper.SQLExecutor) error) {
+	_, filename, _, _ := runtime.Caller(1)
+
+	version, err := parseVersion(filename)
+	if err != nil {
+		panic(err)
+	}
+
+	if existing, ok := registeredGoMigrations[version]; ok {
+		panic(fmt.Errorf("failed to add migration %s: version conflicts with %s", filename, existing.Source))
+	}
+
+	registeredGoMigrations[version] = &rockhopper.Migration{
+		Version:    version,
+		Source:     filename,
+		Registered: true,
+		UseTx:      true,
+		UpFn:       up,
+		DownFn:     down,
+	}
+}
+
+func parseVersion(filename string) (int64, error) {
+	base := filepath.Base(filename)
+	idx := strings.Index(base, "_")
+	if idx < 0 {
+		return 0, fmt.Errorf("no version separator found in the migration file name %s", base)
+	}
+
+	version, err := strconv.ParseInt(base[:idx], 10, 64)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid migration version in the file name %s", base)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,49 @@
+package mockex
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// MarketConfig defines the market served by the mock exchange, the market ID is the lower case symbol, e.g., btcusdt
+type MarketConfig struct {
+	ID                 string  `json:"id" yaml:"id"`
+	BaseUnit           string  `json:"baseUnit" yaml:"baseUnit"`
+	BaseUnitPrecision  int     `json:"baseUnitPrecision" yaml:"baseUnitPrecision"`
+	QuoteUnit          string  `json:"quoteUnit" yaml:"quoteUnit"`
+	QuoteUnitPrecision int     `json:"quoteUnitPrecision" yaml:"quoteUnitPrecision"`
+	MinBaseAmount      float64 `json:"minBaseAmount" yaml:"minBaseAmount"`
+	MinQuoteAmount     float64 `json:"minQuoteAmount" yaml:"minQuoteAmount"`
+}
+
+// MemberConfig defines the API key, the API secret and the initial balances of a mock exchange member
+type MemberConfig struct {
+	Key      string                      `json:"key" yaml:"key"`
+	Secret   string                      `json:"secret" yaml:"secret"`
+	Balances map[string]fixedpoint.Value `json:"balances" yaml:"balances"`
+}
+
+type Config struct {
+	// FeeRate is the trading fee rate of both the maker and the taker, the fee is paid in the received currency
+	FeeRate fixedpoint.Value `json:"feeRate" yaml:"feeRate"`
+
+	Markets []MarketConfig `json:"markets" yaml:"markets"`
+	Members []MemberConfig `json:"members" yaml:"members"`
+}
+
+func LoadConfig(configFile string) (*Config, error) {
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
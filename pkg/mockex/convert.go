@@ -0,0 +1,149 @@
+package mockex
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func formatValue(v fixedpoint.Value) string {
+	return strconv.FormatFloat(v.Float64(), 'f', -1, 64)
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func averagePrice(o Order) fixedpoint.Value {
+	if o.Executed == 0 {
+		return 0
+	}
+
+	return o.Funds.Div(o.Executed)
+}
+
+func toMaxOrder(o Order) max.Order {
+	return max.Order{
+		ID:              o.ID,
+		Side:            o.Side,
+		OrderType:       o.Type,
+		Price:           formatValue(o.Price),
+		AveragePrice:    formatValue(averagePrice(o)),
+		State:           o.State,
+		Market:          o.Market,
+		Volume:          formatValue(o.Volume),
+		RemainingVolume: formatValue(o.Remaining()),
+		ExecutedVolume:  formatValue(o.Executed),
+		TradesCount:     o.TradesCount,
+		GroupID:         o.GroupID,
+		ClientOID:       o.ClientOID,
+		CreatedAtMs:     toMillis(o.CreatedAt),
+	}
+}
+
+func toMaxOrders(orders []Order) []max.Order {
+	var maxOrders = make([]max.Order, 0, len(orders))
+	for _, o := range orders {
+		maxOrders = append(maxOrders, toMaxOrder(o))
+	}
+
+	return maxOrders
+}
+
+func toOrderUpdate(o Order) max.OrderUpdate {
+	return max.OrderUpdate{
+		ID:              o.ID,
+		Side:            o.Side,
+		OrderType:       o.Type,
+		Price:           formatValue(o.Price),
+		StopPrice:       "0",
+		Volume:          formatValue(o.Volume),
+		AveragePrice:    formatValue(averagePrice(o)),
+		State:           o.State,
+		Market:          o.Market,
+		RemainingVolume: formatValue(o.Remaining()),
+		ExecutedVolume:  formatValue(o.Executed),
+		TradesCount:     o.TradesCount,
+		GroupID:         o.GroupID,
+		ClientOID:       o.ClientOID,
+		CreatedAtMs:     toMillis(o.CreatedAt),
+	}
+}
+
+func toMaxTrade(f Fill) max.Trade {
+	info := max.TradeInfo{Maker: "ask"}
+	if (f.Side == "bid") == f.Maker {
+		info.Maker = "bid"
+	}
+
+	return max.Trade{
+		ID:                    f.TradeID,
+		Price:                 formatValue(f.Price),
+		Volume:                formatValue(f.Volume),
+		Funds:                 formatValue(f.Funds),
+		Market:                f.Market,
+		MarketName:            f.Market,
+		CreatedAt:             f.CreatedAt.Unix(),
+		CreatedAtMilliSeconds: toMillis(f.CreatedAt),
+		Side:                  f.Side,
+		OrderID:               f.OrderID,
+		Fee:                   formatValue(f.Fee),
+		FeeCurrency:           f.FeeCurrency,
+		Info:                  info,
+	}
+}
+
+func toTradeUpdate(f Fill) max.TradeUpdate {
+	return max.TradeUpdate{
+		ID:          f.TradeID,
+		Side:        f.Side,
+		Price:       formatValue(f.Price),
+		Volume:      formatValue(f.Volume),
+		Market:      f.Market,
+		Fee:         formatValue(f.Fee),
+		FeeCurrency: f.FeeCurrency,
+		Timestamp:   toMillis(f.CreatedAt),
+		OrderID:     f.OrderID,
+		Maker:       f.Maker,
+	}
+}
+
+func toMaxAccounts(balances map[string]Balance) []max.Account {
+	var accounts = make([]max.Account, 0, len(balances))
+	for currency, b := range balances {
+		accounts = append(accounts, max.Account{
+			Currency: currency,
+			Balance:  formatValue(b.Available),
+			Locked:   formatValue(b.Locked),
+			Type:     "exchange",
+		})
+	}
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Currency < accounts[j].Currency })
+	return accounts
+}
+
+func toBalanceMessages(balances map[string]Balance) []max.BalanceMessage {
+	var messages = make([]max.BalanceMessage, 0, len(balances))
+	for _, a := range toMaxAccounts(balances) {
+		messages = append(messages, max.BalanceMessage{
+			Currency:  a.Currency,
+			Available: a.Balance,
+			Locked:    a.Locked,
+		})
+	}
+
+	return messages
+}
+
+func toBookEntries(levels []PriceVolume) [][]string {
+	var entries = make([][]string, 0, len(levels))
+	for _, pv := range levels {
+		entries = append(entries, []string{formatValue(pv.Price), formatValue(pv.Volume)})
+	}
+
+	return entries
+}
@@ -0,0 +1,700 @@
+package mockex
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+var (
+	ErrMemberNotFound      = errors.New("member not found")
+	ErrMarketNotFound      = errors.New("market not found")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+type Balance struct {
+	Available fixedpoint.Value
+	Locked    fixedpoint.Value
+}
+
+// Order is the order kept in the matching engine, the sides are "buy" and "sell"
+type Order struct {
+	ID          uint64
+	Member      string
+	Market      string
+	Side        string
+	Type        max.OrderType
+	Price       fixedpoint.Value
+	Volume      fixedpoint.Value
+	Executed    fixedpoint.Value
+	Funds       fixedpoint.Value
+	State       max.OrderState
+	ClientOID   string
+	GroupID     int64
+	TradesCount int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// locked is the balance still locked by the order, it's the quote currency for buy orders and the base currency for sell orders
+	locked fixedpoint.Value
+}
+
+func (o Order) Remaining() fixedpoint.Value {
+	return o.Volume.Sub(o.Executed)
+}
+
+func (o Order) IsActive() bool {
+	return o.State == max.OrderStateWait
+}
+
+// Fill is one side of the trade from the view of the member, the sides are "bid" and "ask"
+type Fill struct {
+	TradeID     uint64
+	Market      string
+	Side        string
+	OrderID     uint64
+	Price       fixedpoint.Value
+	Volume      fixedpoint.Value
+	Funds       fixedpoint.Value
+	Fee         fixedpoint.Value
+	FeeCurrency string
+	Maker       bool
+	CreatedAt   time.Time
+}
+
+// PublicTrade is the trade published to the public trade channel, TakerSide is "buy" or "sell"
+type PublicTrade struct {
+	ID        uint64
+	Market    string
+	Price     fixedpoint.Value
+	Volume    fixedpoint.Value
+	TakerSide string
+	CreatedAt time.Time
+}
+
+type member struct {
+	key, secret string
+	balances    map[string]*Balance
+}
+
+func (m *member) balance(currency string) *Balance {
+	b, ok := m.balances[currency]
+	if !ok {
+		b = &Balance{}
+		m.balances[currency] = b
+	}
+
+	return b
+}
+
+type orderBook struct {
+	// bids are sorted by the price descending, asks are sorted by the price ascending, the earlier orders go first
+	bids, asks []*Order
+}
+
+func (b *orderBook) insert(o *Order) {
+	if o.Side == SideBuy {
+		i := sort.Search(len(b.bids), func(i int) bool { return b.bids[i].Price < o.Price })
+		b.bids = append(b.bids, nil)
+		copy(b.bids[i+1:], b.bids[i:])
+		b.bids[i] = o
+		return
+	}
+
+	i := sort.Search(len(b.asks), func(i int) bool { return b.asks[i].Price > o.Price })
+	b.asks = append(b.asks, nil)
+	copy(b.asks[i+1:], b.asks[i:])
+	b.asks[i] = o
+}
+
+func (b *orderBook) remove(o *Order) {
+	var side = &b.asks
+	if o.Side == SideBuy {
+		side = &b.bids
+	}
+
+	for i, bo := range *side {
+		if bo.ID == o.ID {
+			*side = append((*side)[:i], (*side)[i+1:]...)
+			return
+		}
+	}
+}
+
+// PriceVolume is the aggregated price level of the order book
+type PriceVolume struct {
+	Price  fixedpoint.Value
+	Volume fixedpoint.Value
+}
+
+func aggregate(orders []*Order) (levels []PriceVolume) {
+	for _, o := range orders {
+		if n := len(levels); n > 0 && levels[n-1].Price == o.Price {
+			levels[n-1].Volume += o.Remaining()
+			continue
+		}
+
+		levels = append(levels, PriceVolume{Price: o.Price, Volume: o.Remaining()})
+	}
+
+	return levels
+}
+
+// Engine is the in-memory matching engine of the mock exchange.
+// The limit orders are matched by the price-time priority and the trades are executed at the maker price.
+//
+//go:generate callbackgen -type Engine
+type Engine struct {
+	FeeRate fixedpoint.Value
+
+	mu sync.Mutex
+
+	markets map[string]MarketConfig
+	members map[string]*member
+	books   map[string]*orderBook
+	orders  map[uint64]*Order
+	fills   map[string][]Fill
+	trades  map[string][]PublicTrade
+
+	lastOrderID, lastTradeID uint64
+
+	// events are the callbacks to be emitted after the engine is unlocked
+	events []func()
+
+	orderUpdateCallbacks   []func(member string, order Order)
+	fillCallbacks          []func(member string, fill Fill)
+	balanceUpdateCallbacks []func(member string, balances map[string]Balance)
+	publicTradeCallbacks   []func(trade PublicTrade)
+	bookUpdateCallbacks    []func(market string)
+}
+
+func NewEngine(config *Config) *Engine {
+	e := &Engine{
+		FeeRate: config.FeeRate,
+		markets: make(map[string]MarketConfig),
+		members: make(map[string]*member),
+		books:   make(map[string]*orderBook),
+		orders:  make(map[uint64]*Order),
+		fills:   make(map[string][]Fill),
+		trades:  make(map[string][]PublicTrade),
+	}
+
+	for _, m := range config.Markets {
+		e.markets[m.ID] = m
+		e.books[m.ID] = &orderBook{}
+	}
+
+	for _, mc := range config.Members {
+		m := &member{key: mc.Key, secret: mc.Secret, balances: make(map[string]*Balance)}
+		for currency, amount := range mc.Balances {
+			m.balance(currency).Available = amount
+		}
+
+		e.members[mc.Key] = m
+	}
+
+	return e
+}
+
+func (e *Engine) unlock() {
+	events := e.events
+	e.events = nil
+	e.mu.Unlock()
+
+	for _, event := range events {
+		event()
+	}
+}
+
+func (e *Engine) emitOrder(o *Order) {
+	order := *o
+	e.events = append(e.events, func() { e.EmitOrderUpdate(order.Member, order) })
+}
+
+func (e *Engine) emitBalances(m *member, currencies ...string) {
+	balances := make(map[string]Balance, len(currencies))
+	for _, currency := range currencies {
+		balances[currency] = *m.balance(currency)
+	}
+
+	e.events = append(e.events, func() { e.EmitBalanceUpdate(m.key, balances) })
+}
+
+func (e *Engine) emitBook(market string) {
+	e.events = append(e.events, func() { e.EmitBookUpdate(market) })
+}
+
+// Secret returns the API secret of the member
+func (e *Engine) Secret(key string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.members[key]
+	if !ok {
+		return "", false
+	}
+
+	return m.secret, true
+}
+
+func (e *Engine) Markets() (markets []MarketConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, m := range e.markets {
+		markets = append(markets, m)
+	}
+
+	sort.Slice(markets, func(i, j int) bool { return markets[i].ID < markets[j].ID })
+	return markets
+}
+
+func (e *Engine) Balances(key string) (map[string]Balance, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.members[key]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+
+	balances := make(map[string]Balance, len(m.balances))
+	for currency, b := range m.balances {
+		balances[currency] = *b
+	}
+
+	return balances, nil
+}
+
+// SubmitOrder validates the order, locks the balance of the limit order and matches the order against the order book.
+func (e *Engine) SubmitOrder(key string, order Order) (*Order, error) {
+	e.mu.Lock()
+	defer e.unlock()
+
+	m, ok := e.members[key]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+
+	market, ok := e.markets[order.Market]
+	if !ok {
+		return nil, ErrMarketNotFound
+	}
+
+	if order.Side != SideBuy && order.Side != SideSell {
+		return nil, fmt.Errorf("invalid side %q", order.Side)
+	}
+
+	if order.Volume <= 0 {
+		return nil, fmt.Errorf("invalid volume %s", formatValue(order.Volume))
+	}
+
+	if order.Volume.Float64() < market.MinBaseAmount {
+		return nil, fmt.Errorf("volume %s is less than the min base amount %f", formatValue(order.Volume), market.MinBaseAmount)
+	}
+
+	switch order.Type {
+	case max.OrderTypeLimit:
+		if order.Price <= 0 {
+			return nil, fmt.Errorf("invalid price %s", formatValue(order.Price))
+		}
+
+		if order.Price.Mul(order.Volume).Float64() < market.MinQuoteAmount {
+			return nil, fmt.Errorf("order amount is less than the min quote amount %f", market.MinQuoteAmount)
+		}
+
+	case max.OrderTypeMarket:
+		order.Price = 0
+
+	default:
+		return nil, fmt.Errorf("unsupported order type %q", order.Type)
+	}
+
+	// lock the balance for the limit order, the market order uses the available balance directly
+	switch {
+	case order.Side == SideSell:
+		base := m.balance(market.BaseUnit)
+		if base.Available < order.Volume {
+			return nil, ErrInsufficientBalance
+		}
+
+		if order.Type == max.OrderTypeLimit {
+			base.Available -= order.Volume
+			base.Locked += order.Volume
+			order.locked = order.Volume
+		}
+
+	case order.Type == max.OrderTypeLimit:
+		amount := order.Price.Mul(order.Volume)
+		quote := m.balance(market.QuoteUnit)
+		if quote.Available < amount {
+			return nil, ErrInsufficientBalance
+		}
+
+		quote.Available -= amount
+		quote.Locked += amount
+		order.locked = amount
+	}
+
+	e.lastOrderID++
+	now := time.Now()
+	o := &order
+	o.ID = e.lastOrderID
+	o.Member = key
+	o.Executed = 0
+	o.Funds = 0
+	o.TradesCount = 0
+	o.State = max.OrderStateWait
+	o.CreatedAt = now
+	o.UpdatedAt = now
+	e.orders[o.ID] = o
+
+	e.emitOrder(o)
+	e.emitBalances(m, market.BaseUnit, market.QuoteUnit)
+
+	e.match(market, o)
+
+	if o.Remaining() > 0 {
+		if o.Type == max.OrderTypeLimit {
+			e.books[market.ID].insert(o)
+		} else {
+			// the remaining volume of the market order is canceled
+			o.State = max.OrderStateCancel
+			o.UpdatedAt = time.Now()
+			e.emitOrder(o)
+		}
+	}
+
+	e.emitBook(market.ID)
+
+	ret := *o
+	return &ret, nil
+}
+
+func (e *Engine) match(market MarketConfig, taker *Order) {
+	book := e.books[market.ID]
+	makers := &book.asks
+	if taker.Side == SideSell {
+		makers = &book.bids
+	}
+
+	for len(*makers) > 0 && taker.Remaining() > 0 {
+		maker := (*makers)[0]
+
+		if taker.Type == max.OrderTypeLimit {
+			if taker.Side == SideBuy && maker.Price > taker.Price {
+				break
+			}
+
+			if taker.Side == SideSell && maker.Price < taker.Price {
+				break
+			}
+		}
+
+		volume := taker.Remaining()
+		if r := maker.Remaining(); r < volume {
+			volume = r
+		}
+
+		// the market buy order is limited by the available quote balance
+		if taker.Type == max.OrderTypeMarket && taker.Side == SideBuy {
+			available := e.members[taker.Member].balance(market.QuoteUnit).Available
+			if affordable := available.Div(maker.Price); affordable < volume {
+				volume = affordable
+			}
+
+			// avoid overdrawing the balance by the rounding of the division
+			if volume > 0 && maker.Price.Mul(volume) > available {
+				volume--
+			}
+
+			if volume <= 0 {
+				break
+			}
+		}
+
+		e.fill(market, maker, taker, maker.Price, volume)
+
+		if maker.Remaining() <= 0 {
+			*makers = (*makers)[1:]
+		}
+	}
+}
+
+func (e *Engine) fill(market MarketConfig, maker, taker *Order, price, volume fixedpoint.Value) {
+	now := time.Now()
+	funds := price.Mul(volume)
+
+	e.lastTradeID++
+	tradeID := e.lastTradeID
+
+	buyOrder, sellOrder := taker, maker
+	if taker.Side == SideSell {
+		buyOrder, sellOrder = maker, taker
+	}
+
+	buyer := e.members[buyOrder.Member]
+	seller := e.members[sellOrder.Member]
+
+	buyFee := volume.Mul(e.FeeRate)
+	sellFee := funds.Mul(e.FeeRate)
+
+	// settle the buyer, the price difference of the limit buy order is released
+	buyerQuote := buyer.balance(market.QuoteUnit)
+	if buyOrder.Type == max.OrderTypeLimit {
+		unlocked := buyOrder.Price.Mul(volume)
+		if unlocked > buyOrder.locked {
+			unlocked = buyOrder.locked
+		}
+
+		buyOrder.locked -= unlocked
+		buyerQuote.Locked -= unlocked
+		buyerQuote.Available += unlocked - funds
+	} else {
+		buyerQuote.Available -= funds
+	}
+	buyer.balance(market.BaseUnit).Available += volume - buyFee
+
+	// settle the seller
+	sellerBase := seller.balance(market.BaseUnit)
+	if sellOrder.Type == max.OrderTypeLimit {
+		sellOrder.locked -= volume
+		sellerBase.Locked -= volume
+	} else {
+		sellerBase.Available -= volume
+	}
+	seller.balance(market.QuoteUnit).Available += funds - sellFee
+
+	for _, o := range []*Order{maker, taker} {
+		o.Executed += volume
+		o.Funds += funds
+		o.TradesCount++
+		o.UpdatedAt = now
+
+		if o.Remaining() <= 0 {
+			o.State = max.OrderStateDone
+			e.releaseLocked(market, o)
+		}
+
+		e.emitOrder(o)
+	}
+
+	fills := []Fill{
+		{
+			TradeID: tradeID, Market: market.ID, Side: "bid", OrderID: buyOrder.ID,
+			Price: price, Volume: volume, Funds: funds,
+			Fee: buyFee, FeeCurrency: market.BaseUnit,
+			Maker: buyOrder == maker, CreatedAt: now,
+		},
+		{
+			TradeID: tradeID, Market: market.ID, Side: "ask", OrderID: sellOrder.ID,
+			Price: price, Volume: volume, Funds: funds,
+			Fee: sellFee, FeeCurrency: market.QuoteUnit,
+			Maker: sellOrder == maker, CreatedAt: now,
+		},
+	}
+
+	for i, f := range fills {
+		key := buyer.key
+		if i == 1 {
+			key = seller.key
+		}
+
+		e.fills[key] = append(e.fills[key], f)
+		f := f
+		e.events = append(e.events, func() { e.EmitFill(key, f) })
+	}
+
+	trade := PublicTrade{ID: tradeID, Market: market.ID, Price: price, Volume: volume, TakerSide: taker.Side, CreatedAt: now}
+	e.trades[market.ID] = append(e.trades[market.ID], trade)
+	e.events = append(e.events, func() { e.EmitPublicTrade(trade) })
+
+	e.emitBalances(buyer, market.BaseUnit, market.QuoteUnit)
+	if seller != buyer {
+		e.emitBalances(seller, market.BaseUnit, market.QuoteUnit)
+	}
+}
+
+// releaseLocked moves the remaining locked balance of the closed order back to the available balance
+func (e *Engine) releaseLocked(market MarketConfig, o *Order) {
+	if o.locked == 0 {
+		return
+	}
+
+	currency := market.BaseUnit
+	if o.Side == SideBuy {
+		currency = market.QuoteUnit
+	}
+
+	b := e.members[o.Member].balance(currency)
+	b.Locked -= o.locked
+	b.Available += o.locked
+	o.locked = 0
+}
+
+func (e *Engine) cancel(o *Order) {
+	market := e.markets[o.Market]
+	e.books[o.Market].remove(o)
+
+	o.State = max.OrderStateCancel
+	o.UpdatedAt = time.Now()
+	e.releaseLocked(market, o)
+
+	e.emitOrder(o)
+	e.emitBalances(e.members[o.Member], market.BaseUnit, market.QuoteUnit)
+	e.emitBook(o.Market)
+}
+
+// CancelOrder cancels the active order of the member by the order ID or the client order ID
+func (e *Engine) CancelOrder(key string, id uint64, clientOID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.unlock()
+
+	o := e.findOrder(key, id, clientOID)
+	if o == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	if o.IsActive() {
+		e.cancel(o)
+	}
+
+	ret := *o
+	return &ret, nil
+}
+
+// CancelOrders cancels the active orders of the member, the empty market, side or group ID matches all orders
+func (e *Engine) CancelOrders(key, market, side string, groupID int64) (canceled []Order) {
+	e.mu.Lock()
+	defer e.unlock()
+
+	for _, o := range e.sortedOrders() {
+		if o.Member != key || !o.IsActive() {
+			continue
+		}
+
+		if (market != "" && o.Market != market) || (side != "" && o.Side != side) || (groupID > 0 && o.GroupID != groupID) {
+			continue
+		}
+
+		e.cancel(o)
+		canceled = append(canceled, *o)
+	}
+
+	return canceled
+}
+
+func (e *Engine) findOrder(key string, id uint64, clientOID string) *Order {
+	if id > 0 {
+		if o, ok := e.orders[id]; ok && o.Member == key {
+			return o
+		}
+
+		return nil
+	}
+
+	if clientOID == "" {
+		return nil
+	}
+
+	for _, o := range e.orders {
+		if o.Member == key && o.ClientOID == clientOID {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) sortedOrders() (orders []*Order) {
+	for _, o := range e.orders {
+		orders = append(orders, o)
+	}
+
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+func (e *Engine) Order(key string, id uint64, clientOID string) (*Order, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	o := e.findOrder(key, id, clientOID)
+	if o == nil {
+		return nil, ErrOrderNotFound
+	}
+
+	ret := *o
+	return &ret, nil
+}
+
+// Orders returns the orders of the member in the market by the states, the active orders are returned if no state is given
+func (e *Engine) Orders(key, market string, groupID int64, states ...max.OrderState) (orders []Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(states) == 0 {
+		states = []max.OrderState{max.OrderStateWait}
+	}
+
+	for _, o := range e.sortedOrders() {
+		if o.Member != key || (market != "" && o.Market != market) || (groupID > 0 && o.GroupID != groupID) {
+			continue
+		}
+
+		for _, state := range states {
+			if o.State == state {
+				orders = append(orders, *o)
+				break
+			}
+		}
+	}
+
+	return orders
+}
+
+// Fills returns the fills of the member in the market, sorted by the trade ID ascending
+func (e *Engine) Fills(key, market string) (fills []Fill) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, f := range e.fills[key] {
+		if market == "" || f.Market == market {
+			fills = append(fills, f)
+		}
+	}
+
+	return fills
+}
+
+func (e *Engine) PublicTrades(market string) []PublicTrade {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]PublicTrade(nil), e.trades[market]...)
+}
+
+// Depth returns the aggregated price levels of the order book
+func (e *Engine) Depth(market string) (bids, asks []PriceVolume, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	book, ok := e.books[market]
+	if !ok {
+		return nil, nil, ErrMarketNotFound
+	}
+
+	return aggregate(book.bids), aggregate(book.asks), nil
+}
@@ -0,0 +1,175 @@
+package mockex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func newTestEngine() *Engine {
+	return NewEngine(&Config{
+		FeeRate: fixedpoint.NewFromFloat(0.001),
+		Markets: []MarketConfig{
+			{ID: "btcusdt", BaseUnit: "btc", QuoteUnit: "usdt", MinBaseAmount: 0.0001, MinQuoteAmount: 10},
+		},
+		Members: []MemberConfig{
+			{Key: "alice", Secret: "alice-secret", Balances: map[string]fixedpoint.Value{
+				"btc":  fixedpoint.NewFromFloat(1.0),
+				"usdt": fixedpoint.NewFromFloat(10000.0),
+			}},
+			{Key: "bob", Secret: "bob-secret", Balances: map[string]fixedpoint.Value{
+				"btc":  fixedpoint.NewFromFloat(1.0),
+				"usdt": fixedpoint.NewFromFloat(10000.0),
+			}},
+		},
+	})
+}
+
+func limitOrder(side string, price, volume float64) Order {
+	return Order{
+		Market: "btcusdt",
+		Side:   side,
+		Type:   max.OrderTypeLimit,
+		Price:  fixedpoint.NewFromFloat(price),
+		Volume: fixedpoint.NewFromFloat(volume),
+	}
+}
+
+func TestEngine_LimitOrderLocksBalance(t *testing.T) {
+	e := newTestEngine()
+
+	order, err := e.SubmitOrder("alice", limitOrder(SideBuy, 5000, 0.5))
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateWait, order.State)
+
+	balances, err := e.Balances("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 7500.0, balances["usdt"].Available.Float64())
+	assert.Equal(t, 2500.0, balances["usdt"].Locked.Float64())
+
+	_, err = e.SubmitOrder("alice", limitOrder(SideBuy, 5000, 2.0))
+	assert.Equal(t, ErrInsufficientBalance, err)
+
+	canceled, err := e.CancelOrder("alice", order.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateCancel, canceled.State)
+
+	balances, err = e.Balances("alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 10000.0, balances["usdt"].Available.Float64())
+	assert.Equal(t, 0.0, balances["usdt"].Locked.Float64())
+}
+
+func TestEngine_Matching(t *testing.T) {
+	e := newTestEngine()
+
+	var fills []Fill
+	e.OnFill(func(member string, fill Fill) { fills = append(fills, fill) })
+
+	var publicTrades []PublicTrade
+	e.OnPublicTrade(func(trade PublicTrade) { publicTrades = append(publicTrades, trade) })
+
+	ask1, err := e.SubmitOrder("alice", limitOrder(SideSell, 5100, 0.2))
+	assert.NoError(t, err)
+	ask2, err := e.SubmitOrder("alice", limitOrder(SideSell, 5000, 0.2))
+	assert.NoError(t, err)
+
+	// the bid crosses the best ask and is filled at the maker price
+	bid, err := e.SubmitOrder("bob", limitOrder(SideBuy, 5100, 0.3))
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateDone, bid.State)
+	assert.Equal(t, 0.3, bid.Executed.Float64())
+	assert.Equal(t, 1510.0, bid.Funds.Float64())
+
+	if assert.Len(t, publicTrades, 2) {
+		assert.Equal(t, 5000.0, publicTrades[0].Price.Float64())
+		assert.Equal(t, 5100.0, publicTrades[1].Price.Float64())
+		assert.Equal(t, SideBuy, publicTrades[0].TakerSide)
+	}
+	assert.Len(t, fills, 4)
+
+	filledAsk2, err := e.Order("alice", ask2.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateDone, filledAsk2.State)
+
+	partialAsk1, err := e.Order("alice", ask1.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateWait, partialAsk1.State)
+	assert.Equal(t, 0.1, partialAsk1.Remaining().Float64())
+
+	// bob receives 0.3 btc minus the fee in btc, the unused quote of the higher limit price is released
+	bobBalances, err := e.Balances("bob")
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.2997, bobBalances["btc"].Available.Float64(), 1e-8)
+	assert.InDelta(t, 8490.0, bobBalances["usdt"].Available.Float64(), 1e-8)
+	assert.Equal(t, 0.0, bobBalances["usdt"].Locked.Float64())
+
+	// alice receives the quote minus the fee in usdt
+	aliceBalances, err := e.Balances("alice")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.6, aliceBalances["btc"].Available.Float64(), 1e-8)
+	assert.InDelta(t, 0.1, aliceBalances["btc"].Locked.Float64(), 1e-8)
+	assert.InDelta(t, 10000.0+1510.0*0.999, aliceBalances["usdt"].Available.Float64(), 1e-6)
+
+	bids, asks, err := e.Depth("btcusdt")
+	assert.NoError(t, err)
+	assert.Empty(t, bids)
+	assert.Equal(t, []PriceVolume{{Price: fixedpoint.NewFromFloat(5100), Volume: fixedpoint.NewFromFloat(0.1)}}, asks)
+}
+
+func TestEngine_MarketOrder(t *testing.T) {
+	e := newTestEngine()
+
+	_, err := e.SubmitOrder("alice", limitOrder(SideBuy, 4900, 0.1))
+	assert.NoError(t, err)
+
+	// the remaining volume of the market order is canceled when the book is exhausted
+	order, err := e.SubmitOrder("bob", Order{
+		Market: "btcusdt",
+		Side:   SideSell,
+		Type:   max.OrderTypeMarket,
+		Volume: fixedpoint.NewFromFloat(0.5),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateCancel, order.State)
+	assert.Equal(t, 0.1, order.Executed.Float64())
+
+	bobBalances, err := e.Balances("bob")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.9, bobBalances["btc"].Available.Float64(), 1e-8)
+}
+
+func TestEngine_CancelOrders(t *testing.T) {
+	e := newTestEngine()
+
+	o1 := limitOrder(SideBuy, 4000, 0.1)
+	o1.GroupID = 1
+	o2 := limitOrder(SideBuy, 4100, 0.1)
+	o2.GroupID = 2
+	o3 := limitOrder(SideSell, 6000, 0.1)
+	o3.ClientOID = "my-order"
+
+	for _, o := range []Order{o1, o2, o3} {
+		_, err := e.SubmitOrder("alice", o)
+		assert.NoError(t, err)
+	}
+
+	canceled := e.CancelOrders("alice", "btcusdt", "", 1)
+	if assert.Len(t, canceled, 1) {
+		assert.Equal(t, int64(1), canceled[0].GroupID)
+	}
+
+	order, err := e.CancelOrder("alice", 0, "my-order")
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateCancel, order.State)
+
+	// bob can not cancel the orders of alice
+	_, err = e.CancelOrder("bob", 2, "")
+	assert.Equal(t, ErrOrderNotFound, err)
+
+	assert.Len(t, e.Orders("alice", "btcusdt", 0), 1)
+	assert.Len(t, e.Orders("alice", "btcusdt", 0, max.OrderStateCancel), 2)
+}
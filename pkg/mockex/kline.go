@@ -0,0 +1,68 @@
+package mockex
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type KLine struct {
+	StartTime              time.Time
+	Open, High, Low, Close fixedpoint.Value
+	Volume                 fixedpoint.Value
+	LastTradeID            uint64
+}
+
+// KLines aggregates the public trades of the market into the klines of the period starting from the start time,
+// the klines without trades carry the close price of the previous kline. The klines start from the first trade
+// if there is no trade before the start time.
+func (e *Engine) KLines(market string, period time.Duration, start, end time.Time, limit int) (kLines []KLine) {
+	trades := e.PublicTrades(market)
+	if len(trades) == 0 || period <= 0 {
+		return nil
+	}
+
+	start = start.Truncate(period)
+	if first := trades[0].CreatedAt.Truncate(period); start.Before(first) {
+		start = first
+	}
+
+	// find the close price before the start time
+	var lastClose fixedpoint.Value
+	var i = 0
+	for ; i < len(trades) && trades[i].CreatedAt.Before(start); i++ {
+		lastClose = trades[i].Price
+	}
+
+	for t := start; t.Before(end); t = t.Add(period) {
+		if limit > 0 && len(kLines) >= limit {
+			break
+		}
+
+		k := KLine{StartTime: t, Open: lastClose, High: lastClose, Low: lastClose, Close: lastClose}
+
+		for ; i < len(trades) && trades[i].CreatedAt.Before(t.Add(period)); i++ {
+			trade := trades[i]
+			if k.Volume == 0 {
+				k.Open, k.High, k.Low = trade.Price, trade.Price, trade.Price
+			}
+
+			if trade.Price > k.High {
+				k.High = trade.Price
+			}
+
+			if trade.Price < k.Low {
+				k.Low = trade.Price
+			}
+
+			k.Close = trade.Price
+			k.Volume += trade.Volume
+			k.LastTradeID = trade.ID
+		}
+
+		lastClose = k.Close
+		kLines = append(kLines, k)
+	}
+
+	return kLines
+}
@@ -0,0 +1,566 @@
+package mockex
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+const apiPrefix = "/api/v2/"
+
+type errorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Server serves the MAX compatible REST API under /api/v2/ and the websocket API under /ws
+type Server struct {
+	Engine *Engine
+
+	mux *http.ServeMux
+	ws  *WebSocketHub
+}
+
+func NewServer(engine *Engine) *Server {
+	s := &Server{
+		Engine: engine,
+		mux:    http.NewServeMux(),
+		ws:     NewWebSocketHub(engine),
+	}
+
+	// public api
+	s.mux.HandleFunc(apiPrefix+"timestamp", s.handleTimestamp)
+	s.mux.HandleFunc(apiPrefix+"markets", s.handleMarkets)
+	s.mux.HandleFunc(apiPrefix+"tickers", s.handleTickers)
+	s.mux.HandleFunc(apiPrefix+"tickers/", s.handleTicker)
+	s.mux.HandleFunc(apiPrefix+"k", s.handleKLines)
+	s.mux.HandleFunc(apiPrefix+"trades", s.handlePublicTrades)
+
+	// private api
+	s.mux.HandleFunc(apiPrefix+"members/me", s.authenticated(s.handleMe))
+	s.mux.HandleFunc(apiPrefix+"members/accounts", s.authenticated(s.handleAccounts))
+	s.mux.HandleFunc(apiPrefix+"members/accounts/", s.authenticated(s.handleAccount))
+	s.mux.HandleFunc(apiPrefix+"deposits", s.authenticated(s.handleEmptyList))
+	s.mux.HandleFunc(apiPrefix+"withdrawals", s.authenticated(s.handleEmptyList))
+	s.mux.HandleFunc(apiPrefix+"orders", s.authenticated(s.handleOrders))
+	s.mux.HandleFunc(apiPrefix+"orders/clear", s.authenticated(s.handleCancelOrders))
+	s.mux.HandleFunc(apiPrefix+"orders/multi/onebyone", s.authenticated(s.handleCreateMultiOrders))
+	s.mux.HandleFunc(apiPrefix+"order", s.authenticated(s.handleOrder))
+	s.mux.HandleFunc(apiPrefix+"order/delete", s.authenticated(s.handleCancelOrder))
+	s.mux.HandleFunc(apiPrefix+"trades/my", s.authenticated(s.handleMyTrades))
+
+	s.mux.Handle("/ws", s.ws)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("mockex: %s %s", r.Method, r.URL.Path)
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Error("mockex: failed to write response")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	var resp errorResponse
+	resp.Error.Code = status
+	resp.Error.Message = err.Error()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func errorStatus(err error) int {
+	switch errors.Cause(err) {
+	case ErrMemberNotFound:
+		return http.StatusUnauthorized
+	case ErrMarketNotFound, ErrOrderNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// signPayload returns the hex encoded HMAC-SHA256 signature of the payload, the same as the MAX client signs the requests
+func signPayload(payload, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifySignature(payload, signature, secret string) bool {
+	return hmac.Equal([]byte(signPayload(payload, secret)), []byte(signature))
+}
+
+type authenticatedHandler func(w http.ResponseWriter, r *http.Request, key string, payload []byte)
+
+// authenticated verifies the X-MAX-* headers and passes the decoded payload, which carries the request parameters, to the handler
+func (s *Server) authenticated(handler authenticatedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-MAX-ACCESSKEY")
+		encoded := r.Header.Get("X-MAX-PAYLOAD")
+		signature := r.Header.Get("X-MAX-SIGNATURE")
+
+		secret, ok := s.Engine.Secret(key)
+		if !ok || !verifySignature(encoded, signature, secret) {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid api key or signature"))
+			return
+		}
+
+		payload, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid payload"))
+			return
+		}
+
+		var params max.PrivateRequestParams
+		if err := json.Unmarshal(payload, &params); err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "invalid payload"))
+			return
+		}
+
+		if params.Path != r.URL.Path {
+			writeError(w, http.StatusUnauthorized, errors.Errorf("payload path %q does not match the request path", params.Path))
+			return
+		}
+
+		handler(w, r, key, payload)
+	}
+}
+
+func (s *Server) handleTimestamp(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, time.Now().Unix())
+}
+
+func (s *Server) handleMarkets(w http.ResponseWriter, r *http.Request) {
+	var markets = make([]max.Market, 0)
+	for _, m := range s.Engine.Markets() {
+		markets = append(markets, max.Market{
+			ID:                 m.ID,
+			Name:               strings.ToUpper(m.BaseUnit + "/" + m.QuoteUnit),
+			BaseUnit:           m.BaseUnit,
+			BaseUnitPrecision:  m.BaseUnitPrecision,
+			QuoteUnit:          m.QuoteUnit,
+			QuoteUnitPrecision: m.QuoteUnitPrecision,
+			MinBaseAmount:      m.MinBaseAmount,
+			MinQuoteAmount:     m.MinQuoteAmount,
+		})
+	}
+
+	writeJSON(w, markets)
+}
+
+// ticker builds the 24 hours ticker of the market from the order book and the public trades
+func (s *Server) ticker(market string) (*max.Ticker, error) {
+	bids, asks, err := s.Engine.Depth(market)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ticker := &max.Ticker{At: now.Unix()}
+
+	var open, high, low, last, volume fixedpoint.Value
+	for _, trade := range s.Engine.PublicTrades(market) {
+		last = trade.Price
+		if trade.CreatedAt.Before(now.Add(-24 * time.Hour)) {
+			continue
+		}
+
+		if volume == 0 {
+			open, high, low = trade.Price, trade.Price, trade.Price
+		}
+
+		if trade.Price > high {
+			high = trade.Price
+		}
+
+		if trade.Price < low {
+			low = trade.Price
+		}
+
+		volume += trade.Volume
+	}
+
+	if len(bids) > 0 {
+		ticker.Buy = formatValue(bids[0].Price)
+	}
+
+	if len(asks) > 0 {
+		ticker.Sell = formatValue(asks[0].Price)
+	}
+
+	ticker.Open = formatValue(open)
+	ticker.High = formatValue(high)
+	ticker.Low = formatValue(low)
+	ticker.Last = formatValue(last)
+	ticker.Volume = formatValue(volume)
+	ticker.VolumeInBTC = "0"
+	return ticker, nil
+}
+
+func (s *Server) handleTickers(w http.ResponseWriter, r *http.Request) {
+	var tickers = make(map[string]*max.Ticker)
+	for _, m := range s.Engine.Markets() {
+		ticker, err := s.ticker(m.ID)
+		if err != nil {
+			writeError(w, errorStatus(err), err)
+			return
+		}
+
+		tickers[m.ID] = ticker
+	}
+
+	writeJSON(w, tickers)
+}
+
+func (s *Server) handleTicker(w http.ResponseWriter, r *http.Request) {
+	ticker, err := s.ticker(strings.TrimPrefix(r.URL.Path, apiPrefix+"tickers/"))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, ticker)
+}
+
+func (s *Server) handleKLines(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	period, err := strconv.Atoi(query.Get("period"))
+	if err != nil || period <= 0 {
+		period = 1
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 30
+	}
+
+	now := time.Now()
+	duration := time.Duration(period) * time.Minute
+	start := now.Add(-duration * time.Duration(limit))
+	if ts, err := strconv.ParseInt(query.Get("timestamp"), 10, 64); err == nil && ts > 0 {
+		start = time.Unix(ts, 0)
+	}
+
+	var rows = make([][]interface{}, 0)
+	for _, k := range s.Engine.KLines(query.Get("market"), duration, start, now, limit) {
+		rows = append(rows, []interface{}{
+			k.StartTime.Unix(),
+			k.Open.Float64(),
+			k.High.Float64(),
+			k.Low.Float64(),
+			k.Close.Float64(),
+			k.Volume.Float64(),
+		})
+	}
+
+	writeJSON(w, rows)
+}
+
+func (s *Server) handlePublicTrades(w http.ResponseWriter, r *http.Request) {
+	market := r.URL.Query().Get("market")
+	trades := s.Engine.PublicTrades(market)
+
+	// the latest trades come first
+	var maxTrades = make([]max.Trade, 0, len(trades))
+	for i := len(trades) - 1; i >= 0; i-- {
+		trade := trades[i]
+		side := "bid"
+		if trade.TakerSide == SideSell {
+			side = "ask"
+		}
+
+		maxTrades = append(maxTrades, max.Trade{
+			ID:                    trade.ID,
+			Price:                 formatValue(trade.Price),
+			Volume:                formatValue(trade.Volume),
+			Funds:                 formatValue(trade.Price.Mul(trade.Volume)),
+			Market:                trade.Market,
+			MarketName:            trade.Market,
+			CreatedAt:             trade.CreatedAt.Unix(),
+			CreatedAtMilliSeconds: toMillis(trade.CreatedAt),
+			Side:                  side,
+		})
+	}
+
+	writeJSON(w, maxTrades)
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	balances, err := s.Engine.Balances(key)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, max.UserInfo{
+		Sn:       key,
+		Name:     key,
+		Email:    key + "@mockex",
+		Accounts: toMaxAccounts(balances),
+	})
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	balances, err := s.Engine.Balances(key)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, toMaxAccounts(balances))
+}
+
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	balances, err := s.Engine.Balances(key)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	currency := strings.TrimPrefix(r.URL.Path, apiPrefix+"members/accounts/")
+	for _, account := range toMaxAccounts(balances) {
+		if account.Currency == currency {
+			writeJSON(w, account)
+			return
+		}
+	}
+
+	writeJSON(w, max.Account{Currency: currency, Balance: "0", Locked: "0", Type: "exchange"})
+}
+
+func (s *Server) handleEmptyList(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	writeJSON(w, []interface{}{})
+}
+
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		s.handleGetOrders(w, r, key, payload)
+	case http.MethodPost:
+		s.handleCreateOrder(w, r, key, payload)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+func (s *Server) handleGetOrders(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.GetOrdersRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	orders := s.Engine.Orders(key, params.Market, params.GroupID, params.State...)
+
+	// the latest orders come first by default
+	if params.OrderBy != "asc" {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+
+	if params.Limit > 0 {
+		offset := params.Offset
+		if params.Page > 1 {
+			offset = (params.Page - 1) * params.Limit
+		}
+
+		if offset > len(orders) {
+			offset = len(orders)
+		}
+
+		end := offset + params.Limit
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		orders = orders[offset:end]
+	}
+
+	writeJSON(w, toMaxOrders(orders))
+}
+
+// newOrder converts the order parameters of the create order request into the engine order
+func newOrder(market, side, orderType, volume, price, clientOID, groupID string) (order Order, err error) {
+	order = Order{
+		Market:    market,
+		Side:      side,
+		Type:      max.OrderType(orderType),
+		ClientOID: clientOID,
+	}
+
+	if order.Volume, err = fixedpoint.NewFromString(volume); err != nil {
+		return order, errors.Wrapf(err, "invalid volume %q", volume)
+	}
+
+	if len(price) > 0 {
+		if order.Price, err = fixedpoint.NewFromString(price); err != nil {
+			return order, errors.Wrapf(err, "invalid price %q", price)
+		}
+	}
+
+	if len(groupID) > 0 {
+		if order.GroupID, err = strconv.ParseInt(groupID, 10, 64); err != nil {
+			return order, errors.Wrapf(err, "invalid group id %q", groupID)
+		}
+	}
+
+	return order, nil
+}
+
+func (s *Server) handleCreateOrder(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.CreateOrderRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	order, err := newOrder(params.Market, params.Side, params.OrderType, params.Volume, params.Price, params.ClientOrderID, params.GroupID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	created, err := s.Engine.SubmitOrder(key, order)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, toMaxOrder(*created))
+}
+
+func (s *Server) handleCreateMultiOrders(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.MultiOrderRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var responses = make(max.MultiOrderResponse, len(params.Orders))
+	for i, o := range params.Orders {
+		var groupID string
+		if o.GroupID > 0 {
+			groupID = strconv.FormatInt(o.GroupID, 10)
+		}
+
+		order, err := newOrder(params.Market, o.Side, string(o.OrderType), o.Volume, o.Price, o.ClientOID, groupID)
+		if err != nil {
+			responses[i].Error = err.Error()
+			continue
+		}
+
+		created, err := s.Engine.SubmitOrder(key, order)
+		if err != nil {
+			responses[i].Error = err.Error()
+			continue
+		}
+
+		responses[i].Order = toMaxOrder(*created)
+	}
+
+	writeJSON(w, responses)
+}
+
+func (s *Server) handleOrder(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.GetOrderRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	order, err := s.Engine.Order(key, params.ID, params.ClientOrderID)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, toMaxOrder(*order))
+}
+
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.OrderCancelRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	order, err := s.Engine.CancelOrder(key, params.ID, params.ClientOrderID)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, toMaxOrder(*order))
+}
+
+func (s *Server) handleCancelOrders(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.OrderCancelAllRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, toMaxOrders(s.Engine.CancelOrders(key, params.Market, params.Side, params.GroupID)))
+}
+
+func (s *Server) handleMyTrades(w http.ResponseWriter, r *http.Request, key string, payload []byte) {
+	var params max.PrivateTradeRequestParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var trades = make([]max.Trade, 0)
+	for _, f := range s.Engine.Fills(key, params.Market) {
+		// from and to are the exclusive trade id bounds, timestamp returns the trades executed before the time
+		if params.From > 0 && f.TradeID <= uint64(params.From) {
+			continue
+		}
+
+		if params.To > 0 && f.TradeID >= uint64(params.To) {
+			continue
+		}
+
+		if params.Timestamp > 0 && f.CreatedAt.Unix() >= params.Timestamp {
+			continue
+		}
+
+		trades = append(trades, toMaxTrade(f))
+	}
+
+	// the latest trades come first by default
+	if params.OrderBy != "asc" {
+		for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+			trades[i], trades[j] = trades[j], trades[i]
+		}
+	}
+
+	if params.Limit > 0 && int64(len(trades)) > params.Limit {
+		trades = trades[:params.Limit]
+	}
+
+	writeJSON(w, trades)
+}
@@ -0,0 +1,59 @@
+package mockex
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+)
+
+func TestServer_RestAPI(t *testing.T) {
+	server := httptest.NewServer(NewServer(newTestEngine()))
+	defer server.Close()
+
+	ctx := context.Background()
+	alice := max.NewRestClient(server.URL+"/api/v2").Auth("alice", "alice-secret")
+	bob := max.NewRestClient(server.URL+"/api/v2").Auth("bob", "bob-secret")
+
+	markets, err := alice.PublicService.Markets(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, markets, 1) {
+		assert.Equal(t, "btcusdt", markets[0].ID)
+	}
+
+	ask, err := alice.OrderService.NewCreateOrderRequest().
+		Market("btcusdt").Side("sell").OrderType(string(max.OrderTypeLimit)).
+		Price("5000").Volume("0.1").ClientOrderID("ask-1").
+		Do(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, max.OrderStateWait, ask.State)
+	assert.Equal(t, "ask-1", ask.ClientOID)
+
+	openOrders, err := alice.OrderService.Open(ctx, "btcusdt", max.QueryOrderOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, openOrders, 1)
+
+	_, err = bob.OrderService.NewCreateOrderRequest().
+		Market("btcusdt").Side("buy").OrderType(string(max.OrderTypeMarket)).
+		Volume("0.1").
+		Do(ctx)
+	assert.NoError(t, err)
+
+	trades, err := alice.TradeService.MyTrades(ctx, max.QueryTradeOptions{Market: "btcusdt"})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "ask", trades[0].Side)
+		assert.True(t, trades[0].IsMaker())
+	}
+
+	accounts, err := alice.AccountService.Accounts(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 2)
+
+	// the signature signed by another secret is rejected
+	_, err = max.NewRestClient(server.URL+"/api/v2").Auth("alice", "wrong-secret").AccountService.Accounts(ctx)
+	assert.Error(t, err)
+}
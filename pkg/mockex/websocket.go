@@ -0,0 +1,371 @@
+package mockex
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+)
+
+// klineResolutions are the supported kline resolutions of the kline channel
+var klineResolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+type websocketRequest struct {
+	Action        string             `json:"action"`
+	Subscriptions []max.Subscription `json:"subscriptions,omitempty"`
+	APIKey        string             `json:"apiKey"`
+	Nonce         int64              `json:"nonce"`
+	Signature     string             `json:"signature"`
+	ID            string             `json:"id"`
+}
+
+type wsConn struct {
+	conn *websocket.Conn
+
+	// writeMu guards the writes since the engine events are pushed from other goroutines
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	member        string
+	subscriptions map[string]max.Subscription
+	done          chan struct{}
+}
+
+func (c *wsConn) writeJSON(v interface{}) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if err := c.conn.WriteJSON(v); err != nil {
+		log.WithError(err).Debug("mockex: websocket write error")
+	}
+}
+
+func (c *wsConn) isMember(member string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.member == member
+}
+
+func (c *wsConn) subscribed(channel, market string) (max.Subscription, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.subscriptions[channel+":"+market]
+	return s, ok
+}
+
+// WebSocketHub serves the MAX compatible websocket API, it pushes the book, trade and kline channels
+// and the user events of the authenticated member.
+type WebSocketHub struct {
+	Engine *Engine
+
+	upgrader websocket.Upgrader
+
+	mu    sync.Mutex
+	conns map[*wsConn]struct{}
+}
+
+func NewWebSocketHub(engine *Engine) *WebSocketHub {
+	h := &WebSocketHub{
+		Engine:   engine,
+		upgrader: websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		conns:    make(map[*wsConn]struct{}),
+	}
+
+	engine.OnOrderUpdate(func(member string, order Order) {
+		h.pushUser(member, "order_update", "o", []max.OrderUpdate{toOrderUpdate(order)})
+	})
+
+	engine.OnFill(func(member string, fill Fill) {
+		h.pushUser(member, "trade_update", "t", []max.TradeUpdate{toTradeUpdate(fill)})
+	})
+
+	engine.OnBalanceUpdate(func(member string, balances map[string]Balance) {
+		h.pushUser(member, "account_update", "B", toBalanceMessages(balances))
+	})
+
+	engine.OnPublicTrade(h.pushPublicTrade)
+	engine.OnBookUpdate(func(market string) {
+		for _, c := range h.connections() {
+			if _, ok := c.subscribed("book", market); ok {
+				h.sendBook(c, market, "update")
+			}
+		}
+	})
+
+	return h
+}
+
+func (h *WebSocketHub) connections() (conns []*wsConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+
+	return conns
+}
+
+func (h *WebSocketHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.WithError(err).Error("mockex: websocket upgrade error")
+		return
+	}
+
+	c := &wsConn{
+		conn:          conn,
+		subscriptions: make(map[string]max.Subscription),
+		done:          make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.conns[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, c)
+		h.mu.Unlock()
+
+		close(c.done)
+		_ = conn.Close()
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req websocketRequest
+		if err := json.Unmarshal(message, &req); err != nil {
+			h.sendError(c, req.ID, "invalid message: "+err.Error())
+			continue
+		}
+
+		switch req.Action {
+		case "auth":
+			h.handleAuth(c, req)
+		case max.SubscribeAction:
+			h.handleSubscribe(c, req)
+		case max.UnsubscribeAction:
+			h.handleUnsubscribe(c, req)
+		default:
+			h.sendError(c, req.ID, "unsupported action: "+req.Action)
+		}
+	}
+}
+
+func (h *WebSocketHub) sendError(c *wsConn, id, message string) {
+	c.writeJSON(map[string]interface{}{
+		"e": "error",
+		"E": []string{message},
+		"i": id,
+		"T": toMillis(time.Now()),
+	})
+}
+
+// handleAuth verifies the signature of the nonce and sends the snapshots of the member
+func (h *WebSocketHub) handleAuth(c *wsConn, req websocketRequest) {
+	secret, ok := h.Engine.Secret(req.APIKey)
+	if !ok || !verifySignature(strconv.FormatInt(req.Nonce, 10), req.Signature, secret) {
+		h.sendError(c, req.ID, "invalid api key or signature")
+		return
+	}
+
+	c.mu.Lock()
+	c.member = req.APIKey
+	c.mu.Unlock()
+
+	now := toMillis(time.Now())
+	c.writeJSON(map[string]interface{}{"e": "authenticated", "i": req.ID, "T": now})
+
+	balances, err := h.Engine.Balances(req.APIKey)
+	if err != nil {
+		h.sendError(c, req.ID, err.Error())
+		return
+	}
+
+	var orders []max.OrderUpdate
+	for _, o := range h.Engine.Orders(req.APIKey, "", 0) {
+		orders = append(orders, toOrderUpdate(o))
+	}
+
+	var trades []max.TradeUpdate
+	for _, f := range h.Engine.Fills(req.APIKey, "") {
+		trades = append(trades, toTradeUpdate(f))
+	}
+
+	c.writeJSON(map[string]interface{}{"c": "user", "e": "account_snapshot", "B": toBalanceMessages(balances), "T": now})
+	c.writeJSON(map[string]interface{}{"c": "user", "e": "order_snapshot", "o": orders, "T": now})
+	c.writeJSON(map[string]interface{}{"c": "user", "e": "trade_snapshot", "t": trades, "T": now})
+}
+
+func (h *WebSocketHub) handleSubscribe(c *wsConn, req websocketRequest) {
+	for _, s := range req.Subscriptions {
+		switch s.Channel {
+		case "book", "trade":
+		case "kline":
+			if s.Resolution == "" {
+				s.Resolution = "1m"
+			}
+
+			if _, ok := klineResolutions[s.Resolution]; !ok {
+				h.sendError(c, req.ID, "unsupported kline resolution: "+s.Resolution)
+				continue
+			}
+		default:
+			h.sendError(c, req.ID, "unsupported channel: "+s.Channel)
+			continue
+		}
+
+		c.mu.Lock()
+		c.subscriptions[s.Channel+":"+s.Market] = s
+		c.mu.Unlock()
+
+		switch s.Channel {
+		case "book":
+			h.sendBook(c, s.Market, "snapshot")
+		case "kline":
+			go h.pushKLines(c, s)
+		}
+	}
+
+	c.writeJSON(map[string]interface{}{"e": "subscribed", "i": req.ID, "s": req.Subscriptions, "T": toMillis(time.Now())})
+}
+
+func (h *WebSocketHub) handleUnsubscribe(c *wsConn, req websocketRequest) {
+	c.mu.Lock()
+	for _, s := range req.Subscriptions {
+		delete(c.subscriptions, s.Channel+":"+s.Market)
+	}
+	c.mu.Unlock()
+
+	c.writeJSON(map[string]interface{}{"e": "unsubscribed", "i": req.ID, "s": req.Subscriptions, "T": toMillis(time.Now())})
+}
+
+func (h *WebSocketHub) pushUser(member, event, field string, payload interface{}) {
+	for _, c := range h.connections() {
+		if c.isMember(member) {
+			c.writeJSON(map[string]interface{}{"c": "user", "e": event, field: payload, "T": toMillis(time.Now())})
+		}
+	}
+}
+
+func (h *WebSocketHub) pushPublicTrade(trade PublicTrade) {
+	trend := "up"
+	if trade.TakerSide == SideSell {
+		trend = "down"
+	}
+
+	entry := max.TradeEntry{
+		Trend:     trend,
+		Price:     formatValue(trade.Price),
+		Volume:    formatValue(trade.Volume),
+		Timestamp: toMillis(trade.CreatedAt),
+	}
+
+	for _, c := range h.connections() {
+		if _, ok := c.subscribed("trade", trade.Market); ok {
+			c.writeJSON(map[string]interface{}{
+				"c": "trade",
+				"e": "update",
+				"M": trade.Market,
+				"t": []max.TradeEntry{entry},
+				"T": toMillis(time.Now()),
+			})
+		}
+	}
+}
+
+// sendBook sends the whole order book of the market since the book of the mock exchange is small
+func (h *WebSocketHub) sendBook(c *wsConn, market, event string) {
+	bids, asks, err := h.Engine.Depth(market)
+	if err != nil {
+		h.sendError(c, "", err.Error())
+		return
+	}
+
+	c.writeJSON(map[string]interface{}{
+		"c": "book",
+		"e": event,
+		"M": market,
+		"a": toBookEntries(asks),
+		"b": toBookEntries(bids),
+		"T": toMillis(time.Now()),
+	})
+}
+
+// pushKLines pushes the kline update of the subscription every second and the closed kline when the kline ends
+func (h *WebSocketHub) pushKLines(c *wsConn, s max.Subscription) {
+	period := klineResolutions[s.Resolution]
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var current time.Time
+	for {
+		select {
+		case <-c.done:
+			return
+
+		case now := <-ticker.C:
+			if active, ok := c.subscribed(s.Channel, s.Market); !ok || active != s {
+				return
+			}
+
+			startTime := now.Truncate(period)
+			if !current.IsZero() && startTime.After(current) {
+				h.sendKLine(c, s, current, period, true)
+			}
+
+			current = startTime
+			h.sendKLine(c, s, current, period, false)
+		}
+	}
+}
+
+func (h *WebSocketHub) sendKLine(c *wsConn, s max.Subscription, startTime time.Time, period time.Duration, closed bool) {
+	kLines := h.Engine.KLines(s.Market, period, startTime, startTime.Add(period), 1)
+	if len(kLines) == 0 {
+		return
+	}
+
+	k := kLines[0]
+	c.writeJSON(map[string]interface{}{
+		"c": "kline",
+		"e": "update",
+		"M": s.Market,
+		"T": toMillis(time.Now()),
+		"k": max.KLinePayload{
+			StartTime:   toMillis(k.StartTime),
+			EndTime:     toMillis(k.StartTime.Add(period)) - 1,
+			Market:      s.Market,
+			Resolution:  s.Resolution,
+			Open:        formatValue(k.Open),
+			High:        formatValue(k.High),
+			Low:         formatValue(k.Low),
+			Close:       formatValue(k.Close),
+			Volume:      formatValue(k.Volume),
+			LastTradeID: int(k.LastTradeID),
+			Closed:      closed,
+		},
+	})
+}
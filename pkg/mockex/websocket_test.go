@@ -0,0 +1,59 @@
+package mockex
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
+)
+
+func readEvent(t *testing.T, conn *websocket.Conn) interface{} {
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, message, err := conn.ReadMessage()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	event, err := max.ParseMessage(message)
+	assert.NoError(t, err)
+	return event
+}
+
+func TestWebSocketHub_UserEvents(t *testing.T) {
+	engine := newTestEngine()
+	server := httptest.NewServer(NewServer(engine))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	nonce := time.Now().UnixNano() / int64(time.Millisecond)
+	err = conn.WriteJSON(max.AuthMessage{
+		Action:    "auth",
+		APIKey:    "alice",
+		Nonce:     nonce,
+		Signature: signPayload(strconv.FormatInt(nonce, 10), "alice-secret"),
+		ID:        "auth-1",
+	})
+	assert.NoError(t, err)
+
+	assert.IsType(t, &max.AuthEvent{}, readEvent(t, conn))
+	assert.IsType(t, &max.AccountSnapshotEvent{}, readEvent(t, conn))
+	assert.IsType(t, &max.OrderSnapshotEvent{}, readEvent(t, conn))
+	assert.IsType(t, &max.TradeSnapshotEvent{}, readEvent(t, conn))
+
+	_, err = engine.SubmitOrder("alice", limitOrder(SideBuy, 5000, 0.1))
+	assert.NoError(t, err)
+
+	if event, ok := readEvent(t, conn).(*max.OrderUpdateEvent); assert.True(t, ok) {
+		assert.Equal(t, max.OrderStateWait, event.Orders[0].State)
+	}
+	assert.IsType(t, &max.AccountUpdateEvent{}, readEvent(t, conn))
+}
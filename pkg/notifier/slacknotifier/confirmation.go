@@ -0,0 +1,190 @@
+package slacknotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	confirmActionName = "confirm"
+	approveValue      = "approve"
+	rejectValue       = "reject"
+)
+
+type pendingConfirmation struct {
+	answerC chan bool
+}
+
+// OrderConfirmer sends the order confirmation request with the Approve and Reject buttons to the slack channel,
+// the button clicks are sent by slack to the interactivity request URL, which should be routed to ServeHTTP.
+type OrderConfirmer struct {
+	client        *slack.Client
+	channel       string
+	signingSecret string
+
+	mu      sync.Mutex
+	pending map[string]*pendingConfirmation
+	seq     int64
+}
+
+func NewOrderConfirmer(token, channel, signingSecret string) *OrderConfirmer {
+	return &OrderConfirmer{
+		client:        slack.New(token),
+		channel:       channel,
+		signingSecret: signingSecret,
+		pending:       make(map[string]*pendingConfirmation),
+	}
+}
+
+func (c *OrderConfirmer) RequestConfirmation(ctx context.Context, orders []types.SubmitOrder) (bool, error) {
+	callbackID := fmt.Sprintf("bbgo-confirm-%d-%d", time.Now().UnixNano(), atomic.AddInt64(&c.seq, 1))
+
+	p := &pendingConfirmation{answerC: make(chan bool, 1)}
+	c.mu.Lock()
+	c.pending[callbackID] = p
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, callbackID)
+		c.mu.Unlock()
+	}()
+
+	var attachments []slack.Attachment
+	for _, order := range orders {
+		attachments = append(attachments, order.SlackAttachment())
+	}
+
+	attachments = append(attachments, slack.Attachment{
+		Text:       "Submit these orders?",
+		CallbackID: callbackID,
+		Actions: []slack.AttachmentAction{
+			{Name: confirmActionName, Text: "Approve", Type: "button", Style: "primary", Value: approveValue},
+			{Name: confirmActionName, Text: "Reject", Type: "button", Style: "danger", Value: rejectValue},
+		},
+	})
+
+	_, messageTs, err := c.client.PostMessageContext(ctx, c.channel,
+		slack.MsgOptionText(fmt.Sprintf(":question: %d orders are waiting for your confirmation", len(orders)), true),
+		slack.MsgOptionAttachments(attachments...))
+	if err != nil {
+		return false, err
+	}
+
+	select {
+	case approved := <-p.answerC:
+		return approved, nil
+
+	case <-ctx.Done():
+		// the request is expired, replace the buttons so that it can't be answered anymore
+		if _, _, _, err := c.client.UpdateMessage(c.channel, messageTs,
+			slack.MsgOptionText(fmt.Sprintf(":hourglass: the confirmation of %d orders is expired", len(orders)), true),
+			slack.MsgOptionAttachments()); err != nil {
+			log.WithError(err).Error("can not update the expired confirmation message")
+		}
+
+		return false, ctx.Err()
+	}
+}
+
+// answer delivers the answer of the pending confirmation, it returns false if the confirmation is not pending
+func (c *OrderConfirmer) answer(callbackID string, approved bool) bool {
+	c.mu.Lock()
+	p, ok := c.pending[callbackID]
+	if ok {
+		delete(c.pending, callbackID)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	p.answerC <- approved
+	return true
+}
+
+// ServeHTTP handles the interactive message callbacks of the confirmation buttons
+func (c *OrderConfirmer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, c.signingSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if _, err := verifier.Write(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := verifier.Ensure(); err != nil {
+		log.WithError(err).Warn("slack interaction signature verification failed")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// restore the body for parsing the form payload
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &callback); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if callback.Type != slack.InteractionTypeInteractionMessage || len(callback.ActionCallback.AttachmentActions) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	action := callback.ActionCallback.AttachmentActions[0]
+	if action.Name != confirmActionName {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	approved := action.Value == approveValue
+
+	var text string
+	switch {
+	case !c.answer(callback.CallbackID, approved):
+		text = ":hourglass: this confirmation is expired"
+	case approved:
+		text = fmt.Sprintf(":white_check_mark: the orders are approved by <@%s>", callback.User.ID)
+	default:
+		text = fmt.Sprintf(":x: the orders are rejected by <@%s>", callback.User.ID)
+	}
+
+	log.Infof("order confirmation %s answered by %s: approved = %v", callback.CallbackID, callback.User.Name, approved)
+
+	// replace the original message, so that the buttons are removed
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"replace_original": true,
+		"text":             text,
+	}); err != nil {
+		log.WithError(err).Error("can not write the slack interaction response")
+	}
+}
@@ -40,6 +40,15 @@ func (n *Notifier) Notify(format string, args ...interface{}) {
 }
 
 func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	if err := n.TryNotifyTo(channel, format, args...); err != nil {
+		log.WithError(err).
+			WithField("channel", channel).
+			Errorf("slack error: %s", err.Error())
+	}
+}
+
+// TryNotifyTo posts the message to the channel and returns the delivery error
+func (n *Notifier) TryNotifyTo(channel, format string, args ...interface{}) error {
 	if len(channel) == 0 {
 		channel = n.channel
 	}
@@ -76,13 +85,7 @@ func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
 	_, _, err := n.client.PostMessageContext(context.Background(), channel,
 		slack.MsgOptionText(fmt.Sprintf(format, nonSlackArgs...), true),
 		slack.MsgOptionAttachments(slackAttachments...))
-	if err != nil {
-		log.WithError(err).
-			WithField("channel", channel).
-			Errorf("slack error: %s", err.Error())
-	}
-
-	return
+	return err
 }
 
 /*
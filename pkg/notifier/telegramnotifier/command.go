@@ -0,0 +1,422 @@
+package telegramnotifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/tucnak/telebot.v2"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// closeOrdersTimeout is the timeout of querying and canceling the open orders of the /closeorders command
+const closeOrdersTimeout = 30 * time.Second
+
+// SetTrader binds the running trader, so that the control commands can access the sessions and the strategies
+func (it *Interaction) SetTrader(trader *bbgo.Trader) {
+	it.trader = trader
+}
+
+// authorized checks if the sender is the owner of the bot
+func (it *Interaction) authorized(m *telebot.Message) bool {
+	if it.session == nil || it.session.Owner == nil {
+		return false
+	}
+
+	if m.Sender.ID != it.session.Owner.ID {
+		log.Warningf("incorrect user tried to access bot! sender: %+v", m.Sender)
+		return false
+	}
+
+	return true
+}
+
+func (it *Interaction) reply(m *telebot.Message, message string) {
+	if _, err := it.bot.Send(m.Sender, message); err != nil {
+		log.WithError(err).Error("failed to send telegram message")
+	}
+}
+
+// runningTrader returns the bound trader, it replies the sender if the trader is not running yet
+func (it *Interaction) runningTrader(m *telebot.Message) (*bbgo.Trader, bool) {
+	if it.trader == nil {
+		it.reply(m, "bbgo is not running yet")
+		return nil, false
+	}
+
+	return it.trader, true
+}
+
+// sortedSessions returns the sessions sorted by the name, the public only sessions are skipped since they have no account
+func sortedSessions(environ *bbgo.Environment) (sessions []*bbgo.ExchangeSession) {
+	for _, session := range environ.Sessions() {
+		if session.PublicOnly {
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+
+	return sessions
+}
+
+// HandleBalance replies the balances of all the sessions
+func (it *Interaction) HandleBalance(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	for _, session := range sortedSessions(trader.Environment()) {
+		sb.WriteString(fmt.Sprintf("%s balances:\n", session.Name))
+
+		balances := session.Account.Balances()
+		var currencies []string
+		for currency, b := range balances {
+			if b.Available == 0 && b.Locked == 0 {
+				continue
+			}
+
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+
+		if len(currencies) == 0 {
+			sb.WriteString("  (empty)\n")
+		}
+
+		for _, currency := range currencies {
+			sb.WriteString("  " + balances[currency].String() + "\n")
+		}
+	}
+
+	if sb.Len() == 0 {
+		it.reply(m, "no session is available")
+		return
+	}
+
+	it.reply(m, sb.String())
+}
+
+// HandlePosition replies the positions of the symbols traded in the sessions
+func (it *Interaction) HandlePosition(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	for _, session := range sortedSessions(trader.Environment()) {
+		positions := session.Positions()
+
+		var symbols []string
+		for symbol := range positions {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		for _, symbol := range symbols {
+			p := positions[symbol]
+			sb.WriteString(fmt.Sprintf("%s %s: base %f %s, quote %f %s, average cost %f",
+				session.Name, symbol,
+				p.Base.Float64(), p.BaseCurrency,
+				p.Quote.Float64(), p.QuoteCurrency,
+				p.AverageCost.Float64()))
+
+			if price, ok := session.LastPrice(symbol); ok && p.Base != 0 {
+				sb.WriteString(fmt.Sprintf(", unrealized profit %f %s",
+					p.UnrealizedProfit(fixedpoint.NewFromFloat(price)).Float64(), p.QuoteCurrency))
+			}
+
+			sb.WriteString("\n")
+		}
+	}
+
+	if sb.Len() == 0 {
+		it.reply(m, "no position")
+		return
+	}
+
+	it.reply(m, sb.String())
+}
+
+// HandleCloseOrders cancels the open orders of the traded symbols, the session name and the symbol are optional.
+// ex. /closeorders binance BTCUSDT
+func (it *Interaction) HandleCloseOrders(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	var sessionName, symbol string
+	args := strings.Fields(m.Payload)
+	if len(args) > 0 {
+		sessionName = args[0]
+	}
+	if len(args) > 1 {
+		symbol = strings.ToUpper(args[1])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeOrdersTimeout)
+	defer cancel()
+
+	var sb strings.Builder
+	for _, session := range sortedSessions(trader.Environment()) {
+		if len(sessionName) > 0 && session.Name != sessionName {
+			continue
+		}
+
+		var symbols []string
+		if len(symbol) > 0 {
+			symbols = []string{symbol}
+		} else {
+			for s := range session.OrderStores() {
+				symbols = append(symbols, s)
+			}
+			sort.Strings(symbols)
+		}
+
+		for _, s := range symbols {
+			openOrders, err := session.Exchange.QueryOpenOrders(ctx, s)
+			if err != nil {
+				sb.WriteString(fmt.Sprintf("%s %s: can not query the open orders: %v\n", session.Name, s, err))
+				continue
+			}
+
+			if len(openOrders) == 0 {
+				continue
+			}
+
+			if err := session.Exchange.CancelOrders(ctx, openOrders...); err != nil {
+				sb.WriteString(fmt.Sprintf("%s %s: can not cancel the open orders: %v\n", session.Name, s, err))
+				continue
+			}
+
+			sb.WriteString(fmt.Sprintf("%s %s: %d orders canceled\n", session.Name, s, len(openOrders)))
+		}
+	}
+
+	if sb.Len() == 0 {
+		it.reply(m, "no open order")
+		return
+	}
+
+	it.reply(m, sb.String())
+}
+
+// HandleStatus replies the status of each strategy, the strategies implementing bbgo.StatusReporter report their own status
+func (it *Interaction) HandleStatus(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	var sb strings.Builder
+	exchangeStrategies := trader.ExchangeStrategies()
+
+	var sessionNames []string
+	for name := range exchangeStrategies {
+		sessionNames = append(sessionNames, name)
+	}
+	sort.Strings(sessionNames)
+
+	for _, name := range sessionNames {
+		for _, strategy := range exchangeStrategies[name] {
+			sb.WriteString(fmt.Sprintf("%s on %s: %s\n", strategy.ID(), name, strategyStatus(strategy)))
+		}
+	}
+
+	for _, strategy := range trader.CrossExchangeStrategies() {
+		sb.WriteString(fmt.Sprintf("%s (cross exchange): %s\n", strategy.ID(), strategyStatus(strategy)))
+	}
+
+	if sb.Len() == 0 {
+		it.reply(m, "no strategy is running")
+		return
+	}
+
+	it.reply(m, sb.String())
+}
+
+func strategyStatus(strategy interface{}) string {
+	if reporter, ok := strategy.(bbgo.StatusReporter); ok {
+		return reporter.Status()
+	}
+
+	return "running"
+}
+
+// HandlePause blocks the orders of the strategy, the strategy keeps running with its state. ex. /pause grid
+func (it *Interaction) HandlePause(m *telebot.Message) {
+	it.setStrategyPaused(m, true)
+}
+
+// HandleUnpause clears the pause flag of the strategy. ex. /unpause grid
+func (it *Interaction) HandleUnpause(m *telebot.Message) {
+	it.setStrategyPaused(m, false)
+}
+
+func (it *Interaction) setStrategyPaused(m *telebot.Message, paused bool) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(m.Payload)
+	if len(name) == 0 {
+		it.replyStrategyNames(m, trader)
+		return
+	}
+
+	var err error
+	if paused {
+		_, err = trader.Lifecycle().Pause(name)
+	} else {
+		_, err = trader.Lifecycle().Unpause(name)
+	}
+
+	if err != nil {
+		it.reply(m, err.Error())
+		return
+	}
+
+	if paused {
+		it.reply(m, fmt.Sprintf("strategy %s is paused", name))
+	} else {
+		it.reply(m, fmt.Sprintf("strategy %s is unpaused", name))
+	}
+}
+
+// replyStrategyNames asks for the strategy name with the names of the declared strategies
+func (it *Interaction) replyStrategyNames(m *telebot.Message, trader *bbgo.Trader) {
+	var names []string
+	for _, info := range trader.Lifecycle().Strategies() {
+		names = append(names, info.Name)
+	}
+
+	it.reply(m, fmt.Sprintf("please specify the strategy name, available strategies: %s", strings.Join(names, ", ")))
+}
+
+// HandleSuspend suspends the strategy, the second argument overrides the open orders policy of the strategy. ex. /suspend grid keep
+func (it *Interaction) HandleSuspend(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	args := strings.Fields(m.Payload)
+	if len(args) == 0 {
+		it.replyStrategyNames(m, trader)
+		return
+	}
+
+	var policy bbgo.SuspendOrderPolicy
+	if len(args) > 1 {
+		policy = bbgo.SuspendOrderPolicy(args[1])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bbgo.DefaultSuspendTimeout)
+	defer cancel()
+
+	info, err := trader.Lifecycle().SuspendWithPolicy(ctx, args[0], policy)
+	if err != nil {
+		it.reply(m, err.Error())
+		return
+	}
+
+	if len(policy) == 0 {
+		policy = info.SuspendOrderPolicy
+	}
+
+	it.reply(m, fmt.Sprintf("strategy %s is suspended, the open orders policy: %s", args[0], policy))
+}
+
+// HandleResume resumes the suspended strategy. ex. /resume grid
+func (it *Interaction) HandleResume(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimSpace(m.Payload)
+	if len(name) == 0 {
+		it.replyStrategyNames(m, trader)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bbgo.DefaultSuspendTimeout)
+	defer cancel()
+
+	if _, err := trader.Lifecycle().Resume(ctx, name); err != nil {
+		it.reply(m, err.Error())
+		return
+	}
+
+	it.reply(m, fmt.Sprintf("strategy %s is resumed", name))
+}
+
+// HandleHalt is the emergency kill switch, it suspends all the strategies and cancels all the open orders,
+// the positions are closed if the first argument is "close", the rest of the arguments is the reason. ex. /halt close api key leaked
+func (it *Interaction) HandleHalt(m *telebot.Message) {
+	if !it.authorized(m) {
+		return
+	}
+
+	trader, ok := it.runningTrader(m)
+	if !ok {
+		return
+	}
+
+	options := bbgo.HaltOptions{Source: "telegram"}
+	args := strings.Fields(m.Payload)
+	if len(args) > 0 && strings.EqualFold(args[0], "close") {
+		options.ClosePositions = true
+		args = args[1:]
+	}
+	options.Reason = strings.Join(args, " ")
+
+	it.reply(m, "halting bbgo...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), bbgo.DefaultHaltTimeout)
+	defer cancel()
+
+	report := trader.Halt(ctx, options)
+	it.reply(m, "bbgo is halted\n"+report.String())
+}
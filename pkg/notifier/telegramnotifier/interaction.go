@@ -31,6 +31,9 @@ type Interaction struct {
 
 	bot *telebot.Bot
 
+	// trader is used by the control commands, it's bound when the strategies are running
+	trader *bbgo.Trader
+
 	AuthToken string
 
 	session *Session
@@ -48,6 +51,15 @@ func NewInteraction(bot *telebot.Bot, store bbgo.Store) *Interaction {
 	bot.Handle("/help", interaction.HandleHelp)
 	bot.Handle("/auth", interaction.HandleAuth)
 	bot.Handle("/info", interaction.HandleInfo)
+	bot.Handle("/balance", interaction.HandleBalance)
+	bot.Handle("/position", interaction.HandlePosition)
+	bot.Handle("/closeorders", interaction.HandleCloseOrders)
+	bot.Handle("/status", interaction.HandleStatus)
+	bot.Handle("/pause", interaction.HandlePause)
+	bot.Handle("/unpause", interaction.HandleUnpause)
+	bot.Handle("/suspend", interaction.HandleSuspend)
+	bot.Handle("/resume", interaction.HandleResume)
+	bot.Handle("/halt", interaction.HandleHalt)
 	return interaction
 }
 
@@ -78,13 +90,19 @@ func (it *Interaction) HandleInfo(m *telebot.Message) {
 }
 
 func (it *Interaction) SendToOwner(message string) {
-	if it.session.Owner == nil {
-		return
+	if err := it.TrySendToOwner(message); err != nil {
+		log.WithError(err).Error("failed to send message to the owner")
 	}
+}
 
-	if _, err := it.bot.Send(it.session.Owner, message); err != nil {
-		log.WithError(err).Error("failed to send message to the owner")
+// TrySendToOwner sends the message to the owner and returns the delivery error, the message is dropped if the owner is not authorized yet
+func (it *Interaction) TrySendToOwner(message string) error {
+	if it.session.Owner == nil {
+		return nil
 	}
+
+	_, err := it.bot.Send(it.session.Owner, message)
+	return err
 }
 
 func (it *Interaction) HandleHelp(m *telebot.Message) {
@@ -92,6 +110,15 @@ func (it *Interaction) HandleHelp(m *telebot.Message) {
 help	- show this help message
 auth	- authorize current telegram user to access telegram bot with authentication token or one-time password. ex. /auth my-token
 info	- show information about current chat
+balance	- show the balances of the sessions
+position	- show the positions of the traded symbols
+closeorders	- cancel the open orders of the traded symbols, the session and the symbol are optional. ex. /closeorders binance BTCUSDT
+status	- show the status of the running strategies
+pause	- block the orders of the strategy, the pause flag is kept after restart. ex. /pause grid
+unpause	- unblock the orders of the paused strategy. ex. /unpause grid
+suspend	- suspend the strategy, the open orders policy (keep, cancel or cancelAndRestore) is optional. ex. /suspend grid keep
+resume	- resume the suspended strategy. ex. /resume grid
+halt	- suspend all the strategies and cancel all the open orders, add "close" to close the positions. ex. /halt close api key leaked
 `
 	if _, err := it.bot.Send(m.Sender, message); err != nil {
 		log.WithError(err).Error("failed to send help message")
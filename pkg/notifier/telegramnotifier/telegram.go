@@ -29,7 +29,14 @@ func (n *Notifier) Notify(format string, args ...interface{}) {
 	n.NotifyTo("", format, args...)
 }
 
-func (n *Notifier) NotifyTo(_, format string, args ...interface{}) {
+func (n *Notifier) NotifyTo(channel, format string, args ...interface{}) {
+	if err := n.TryNotifyTo(channel, format, args...); err != nil {
+		log.WithError(err).Error("failed to send message to the owner")
+	}
+}
+
+// TryNotifyTo sends the message to the owner and returns the delivery error
+func (n *Notifier) TryNotifyTo(_, format string, args ...interface{}) error {
 	var textArgsOffset = -1
 	var texts []string
 
@@ -51,10 +58,15 @@ func (n *Notifier) NotifyTo(_, format string, args ...interface{}) {
 	log.Infof(format, simpleArgs...)
 
 	message := fmt.Sprintf(format, simpleArgs...)
-	n.interaction.SendToOwner(message)
+	if err := n.interaction.TrySendToOwner(message); err != nil {
+		return err
+	}
 
 	for _, text := range texts {
-		n.interaction.SendToOwner(text)
+		if err := n.interaction.TrySendToOwner(text); err != nil {
+			return err
+		}
 	}
 
+	return nil
 }
@@ -0,0 +1,154 @@
+package optimizer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RankBySharpe      = "sharpe"
+	RankByProfit      = "profit"
+	RankByMaxDrawdown = "drawdown"
+)
+
+// SelectorConfig is a parameter of the search matrix, the values are either listed in Values,
+// or generated from Min to Max (inclusive) by Step.
+type SelectorConfig struct {
+	// Label is the name of the parameter in the results, defaults to the path
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+
+	// Path is the slash-separated path of the parameter in the bbgo config, e.g., /exchangeStrategies/0/grid/gridNumber
+	Path string `json:"path" yaml:"path"`
+
+	Values []interface{} `json:"values,omitempty" yaml:"values,omitempty"`
+
+	Min  float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max  float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	Step float64 `json:"step,omitempty" yaml:"step,omitempty"`
+}
+
+func (s SelectorConfig) label() string {
+	if len(s.Label) > 0 {
+		return s.Label
+	}
+
+	return s.Path
+}
+
+// values returns the listed values, or the values of the range
+func (s SelectorConfig) values() ([]interface{}, error) {
+	if len(s.Values) > 0 {
+		return s.Values, nil
+	}
+
+	if s.Step <= 0 || s.Max < s.Min {
+		return nil, fmt.Errorf("optimizer parameter %s should have the values or a range with a positive step", s.label())
+	}
+
+	var values []interface{}
+	for i := 0; ; i++ {
+		v := s.Min + float64(i)*s.Step
+
+		// tolerate the float error of the last step
+		if v > s.Max+s.Step*1e-9 {
+			break
+		}
+
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// WalkForwardConfig splits the backtest period into the rolling windows, the parameters are searched in the train period
+// of each window, and the best parameters are verified in the following test period. The windows move forward by TestDays.
+type WalkForwardConfig struct {
+	TrainDays int `json:"trainDays" yaml:"trainDays"`
+	TestDays  int `json:"testDays" yaml:"testDays"`
+}
+
+type Config struct {
+	// MaxThread is the number of the backtests running in parallel, defaults to the number of the CPUs
+	MaxThread int `json:"maxThread,omitempty" yaml:"maxThread,omitempty"`
+
+	// RankBy is the metric of ranking the results: sharpe, profit or drawdown, defaults to sharpe
+	RankBy string `json:"rankBy,omitempty" yaml:"rankBy,omitempty"`
+
+	// Top is the number of the best results in the output, defaults to 5
+	Top int `json:"top,omitempty" yaml:"top,omitempty"`
+
+	Matrix []SelectorConfig `json:"matrix" yaml:"matrix"`
+
+	WalkForward *WalkForwardConfig `json:"walkForward,omitempty" yaml:"walkForward,omitempty"`
+}
+
+func (c *Config) Validate() error {
+	if len(c.Matrix) == 0 {
+		return fmt.Errorf("optimizer matrix can not be empty")
+	}
+
+	for _, selector := range c.Matrix {
+		if len(selector.Path) == 0 {
+			return fmt.Errorf("optimizer parameter %s should have the path", selector.label())
+		}
+
+		if _, err := selector.values(); err != nil {
+			return err
+		}
+	}
+
+	switch c.RankBy {
+	case "", RankBySharpe, RankByProfit, RankByMaxDrawdown:
+	default:
+		return fmt.Errorf("invalid optimizer rankBy %q, should be one of sharpe, profit and drawdown", c.RankBy)
+	}
+
+	if c.WalkForward != nil && (c.WalkForward.TrainDays <= 0 || c.WalkForward.TestDays <= 0) {
+		return fmt.Errorf("optimizer walkForward trainDays and testDays should be positive")
+	}
+
+	return nil
+}
+
+func (c *Config) maxThread() int {
+	if c.MaxThread > 0 {
+		return c.MaxThread
+	}
+
+	return runtime.NumCPU()
+}
+
+// TopResults returns the first Top results of the ranked results
+func (c *Config) TopResults(ranked []Result) []Result {
+	top := c.Top
+	if top <= 0 {
+		top = 5
+	}
+
+	if len(ranked) > top {
+		return ranked[:top]
+	}
+
+	return ranked
+}
+
+func LoadConfig(configFile string) (*Config, error) {
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
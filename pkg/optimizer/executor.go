@@ -0,0 +1,83 @@
+package optimizer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+)
+
+// Executor runs the backtest of the config content and returns the summary report
+type Executor interface {
+	Execute(ctx context.Context, configContent []byte) (*backtest.SummaryReport, error)
+}
+
+// LocalProcessExecutor runs each backtest in a bbgo process, so that the runs do not share the global states,
+// e.g., the loaded strategies, the random seed and the logger.
+type LocalProcessExecutor struct {
+	// Bin is the bbgo binary, defaults to the current executable
+	Bin string
+
+	// ConfigDir is the directory of the temporary config files, it should be the directory of the original config,
+	// so that the relative paths in the config are resolved in the same way
+	ConfigDir string
+
+	// Args are the extra arguments of the backtest command, e.g., --exchange binance
+	Args []string
+}
+
+func (e *LocalProcessExecutor) Execute(ctx context.Context, configContent []byte) (*backtest.SummaryReport, error) {
+	bin := e.Bin
+	if len(bin) == 0 {
+		var err error
+		bin, err = os.Executable()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configFile, err := ioutil.TempFile(e.ConfigDir, ".optimizer-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(configFile.Name())
+
+	if _, err := configFile.Write(configContent); err != nil {
+		configFile.Close()
+		return nil, err
+	}
+
+	if err := configFile.Close(); err != nil {
+		return nil, err
+	}
+
+	reportFile := strings.TrimSuffix(configFile.Name(), ".yaml") + ".json"
+	defer os.Remove(reportFile)
+
+	args := append([]string{"backtest", "--config", configFile.Name(), "--report", reportFile}, e.Args...)
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("backtest process error: %w, output: %s", err, lastLines(output.String(), 10))
+	}
+
+	return backtest.ReadSummaryReport(reportFile)
+}
+
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,219 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// Result is the backtest result of a parameter combination
+type Result struct {
+	Params []ParamValue            `json:"params"`
+	Report *backtest.SummaryReport `json:"report,omitempty"`
+	Error  error                   `json:"-"`
+}
+
+// Metric returns the ranking metric of the result, the larger is the better
+func (r Result) Metric(rankBy string) float64 {
+	switch rankBy {
+	case RankByProfit:
+		return r.Report.TotalProfit()
+	case RankByMaxDrawdown:
+		return -r.Report.MaxDrawdown()
+	default:
+		return r.Report.Sharpe()
+	}
+}
+
+// RankResults sorts the succeeded results by the metric in the descending order, the total profit breaks the ties,
+// the failed results are dropped.
+func RankResults(results []Result, rankBy string) []Result {
+	var ranked []Result
+	for _, result := range results {
+		if result.Error == nil && result.Report != nil {
+			ranked = append(ranked, result)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Metric(rankBy), ranked[j].Metric(rankBy)
+		if a != b {
+			return a > b
+		}
+
+		return ranked[i].Report.TotalProfit() > ranked[j].Report.TotalProfit()
+	})
+
+	return ranked
+}
+
+// GridOptimizer runs the backtests of all the parameter combinations of the matrix
+type GridOptimizer struct {
+	Config   *Config
+	Executor Executor
+}
+
+// Run runs the backtests of the combinations in parallel and returns the ranked results,
+// the fixed params are applied to all the runs, e.g., the backtest period of the walk-forward window.
+func (o *GridOptimizer) Run(ctx context.Context, configContent []byte, fixed ...ParamValue) ([]Result, error) {
+	combinations, err := o.Config.Combinations()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(combinations))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var done int
+
+	for w := 0; w < o.Config.maxThread(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = o.execute(ctx, configContent, combinations[i], fixed)
+
+				mu.Lock()
+				done++
+				if results[i].Error != nil {
+					log.WithError(results[i].Error).Errorf("backtest %d/%d failed: %v", done, len(combinations), results[i].Params)
+				} else {
+					log.Infof("backtest %d/%d done: %v", done, len(combinations), results[i].Params)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range combinations {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ranked := RankResults(results, o.Config.RankBy)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("all the %d backtests failed", len(combinations))
+	}
+
+	return ranked, nil
+}
+
+func (o *GridOptimizer) execute(ctx context.Context, configContent []byte, params, fixed []ParamValue) Result {
+	result := Result{Params: params}
+
+	content, err := ApplyParams(configContent, append(append([]ParamValue{}, fixed...), params...))
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.Report, result.Error = o.Executor.Execute(ctx, content)
+	return result
+}
+
+// WalkForwardWindow is the result of a walk-forward window, Train is the best result of the train period,
+// and Test is the result of the best parameters in the test period.
+type WalkForwardWindow struct {
+	TrainStart time.Time `json:"trainStart"`
+	TestStart  time.Time `json:"testStart"`
+	TestEnd    time.Time `json:"testEnd"`
+
+	Train Result `json:"train"`
+	Test  Result `json:"test"`
+}
+
+// WalkForward searches the parameters in the train period of each window and verifies the best parameters in the test period,
+// the backtest startTime and endTime of the config are split into the windows.
+func (o *GridOptimizer) WalkForward(ctx context.Context, configContent []byte) ([]WalkForwardWindow, error) {
+	conf := o.Config.WalkForward
+	if conf == nil {
+		return nil, fmt.Errorf("optimizer walkForward is not configured")
+	}
+
+	var userConfig struct {
+		Backtest *bbgo.Backtest `yaml:"backtest"`
+	}
+	if err := yaml.Unmarshal(configContent, &userConfig); err != nil {
+		return nil, err
+	}
+
+	if userConfig.Backtest == nil {
+		return nil, fmt.Errorf("backtest config is not defined")
+	}
+
+	startTime, err := userConfig.Backtest.ParseStartTime()
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := userConfig.Backtest.ParseEndTime()
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []WalkForwardWindow
+	for trainStart := startTime; ; trainStart = trainStart.AddDate(0, 0, conf.TestDays) {
+		testStart := trainStart.AddDate(0, 0, conf.TrainDays)
+		testEnd := testStart.AddDate(0, 0, conf.TestDays)
+		if testEnd.After(endTime) {
+			break
+		}
+
+		log.Infof("walk-forward window: train %s ~ %s, test %s ~ %s",
+			trainStart.Format("2006-01-02"), testStart.Format("2006-01-02"),
+			testStart.Format("2006-01-02"), testEnd.Format("2006-01-02"))
+
+		ranked, err := o.Run(ctx, configContent, periodParams(trainStart, testStart)...)
+		if err != nil {
+			return windows, err
+		}
+
+		best := ranked[0]
+		test := o.execute(ctx, configContent, best.Params, periodParams(testStart, testEnd))
+		if test.Error != nil {
+			return windows, test.Error
+		}
+
+		windows = append(windows, WalkForwardWindow{
+			TrainStart: trainStart,
+			TestStart:  testStart,
+			TestEnd:    testEnd,
+			Train:      best,
+			Test:       test,
+		})
+	}
+
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("the backtest period %s ~ %s is shorter than the walk-forward window of %d days",
+			startTime.Format("2006-01-02"), endTime.Format("2006-01-02"), conf.TrainDays+conf.TestDays)
+	}
+
+	return windows, nil
+}
+
+func periodParams(startTime, endTime time.Time) []ParamValue {
+	return []ParamValue{
+		{Label: "startTime", Path: "/backtest/startTime", Value: startTime.Format("2006-01-02")},
+		{Label: "endTime", Path: "/backtest/endTime", Value: endTime.Format("2006-01-02")},
+	}
+}
@@ -0,0 +1,146 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+)
+
+const testConfig = `
+backtest:
+  startTime: "2021-01-01"
+  endTime: "2021-03-01"
+exchangeStrategies:
+- on: binance
+  grid:
+    symbol: BTCUSDT
+    gridNumber: 10
+    profitSpread: 100
+`
+
+func TestConfig_Combinations(t *testing.T) {
+	config := &Config{
+		Matrix: []SelectorConfig{
+			{Label: "gridNumber", Path: "/exchangeStrategies/0/grid/gridNumber", Values: []interface{}{10, 20}},
+			{Label: "profitSpread", Path: "/exchangeStrategies/0/grid/profitSpread", Min: 100, Max: 130, Step: 10},
+		},
+	}
+	if !assert.NoError(t, config.Validate()) {
+		return
+	}
+
+	combinations, err := config.Combinations()
+	assert.NoError(t, err)
+	if assert.Len(t, combinations, 8) {
+		assert.Equal(t, []interface{}{10, 100.0}, []interface{}{combinations[0][0].Value, combinations[0][1].Value})
+		assert.Equal(t, []interface{}{20, 130.0}, []interface{}{combinations[7][0].Value, combinations[7][1].Value})
+	}
+
+	assert.Error(t, (&Config{}).Validate())
+	assert.Error(t, (&Config{Matrix: []SelectorConfig{{Path: "/a", Min: 1, Max: 2}}}).Validate())
+	assert.Error(t, (&Config{Matrix: config.Matrix, RankBy: "winRate"}).Validate())
+}
+
+func TestApplyParams(t *testing.T) {
+	content, err := ApplyParams([]byte(testConfig), []ParamValue{
+		{Path: "/exchangeStrategies/0/grid/gridNumber", Value: 20},
+		{Path: "/backtest/endTime", Value: "2021-02-01"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var config struct {
+		Backtest struct {
+			EndTime string `yaml:"endTime"`
+		} `yaml:"backtest"`
+		ExchangeStrategies []struct {
+			Grid struct {
+				GridNumber   int `yaml:"gridNumber"`
+				ProfitSpread int `yaml:"profitSpread"`
+			} `yaml:"grid"`
+		} `yaml:"exchangeStrategies"`
+	}
+	if assert.NoError(t, yaml.Unmarshal(content, &config)) {
+		assert.Equal(t, "2021-02-01", config.Backtest.EndTime)
+		assert.Equal(t, 20, config.ExchangeStrategies[0].Grid.GridNumber)
+		assert.Equal(t, 100, config.ExchangeStrategies[0].Grid.ProfitSpread)
+	}
+
+	_, err = ApplyParams([]byte(testConfig), []ParamValue{{Path: "/exchangeStrategies/1/grid/gridNumber", Value: 20}})
+	assert.Error(t, err)
+
+	_, err = ApplyParams([]byte(testConfig), []ParamValue{{Path: "/sessions/binance/margin", Value: true}})
+	assert.Error(t, err)
+}
+
+func TestRankResults(t *testing.T) {
+	results := []Result{
+		{Report: &backtest.SummaryReport{Symbols: []backtest.SymbolReport{{Profit: 100, MaxDrawdown: 0.2, Sharpe: 1.0}}}},
+		{Report: &backtest.SummaryReport{Symbols: []backtest.SymbolReport{{Profit: 50, MaxDrawdown: 0.05, Sharpe: 2.0}}}},
+		{Error: errors.New("backtest failed")},
+	}
+
+	ranked := RankResults(results, RankBySharpe)
+	if assert.Len(t, ranked, 2) {
+		assert.Equal(t, 2.0, ranked[0].Report.Sharpe())
+	}
+
+	ranked = RankResults(results, RankByProfit)
+	assert.Equal(t, 100.0, ranked[0].Report.TotalProfit())
+
+	ranked = RankResults(results, RankByMaxDrawdown)
+	assert.Equal(t, 0.05, ranked[0].Report.MaxDrawdown())
+}
+
+// profitExecutor reports the grid number as the profit of the backtest
+type profitExecutor struct{}
+
+func (e *profitExecutor) Execute(ctx context.Context, configContent []byte) (*backtest.SummaryReport, error) {
+	var config struct {
+		Backtest struct {
+			StartTime string `yaml:"startTime"`
+		} `yaml:"backtest"`
+		ExchangeStrategies []struct {
+			Grid struct {
+				GridNumber float64 `yaml:"gridNumber"`
+			} `yaml:"grid"`
+		} `yaml:"exchangeStrategies"`
+	}
+	if err := yaml.Unmarshal(configContent, &config); err != nil {
+		return nil, err
+	}
+
+	return &backtest.SummaryReport{Symbols: []backtest.SymbolReport{{Symbol: "BTCUSDT", Profit: config.ExchangeStrategies[0].Grid.GridNumber}}}, nil
+}
+
+func TestGridOptimizer(t *testing.T) {
+	optimizer := &GridOptimizer{
+		Config: &Config{
+			MaxThread:   2,
+			RankBy:      RankByProfit,
+			Matrix:      []SelectorConfig{{Label: "gridNumber", Path: "/exchangeStrategies/0/grid/gridNumber", Min: 10, Max: 50, Step: 10}},
+			WalkForward: &WalkForwardConfig{TrainDays: 14, TestDays: 14},
+		},
+		Executor: &profitExecutor{},
+	}
+
+	ranked, err := optimizer.Run(context.Background(), []byte(testConfig))
+	if assert.NoError(t, err) && assert.Len(t, ranked, 5) {
+		assert.Equal(t, 50.0, ranked[0].Params[0].Value)
+		assert.Equal(t, 10.0, ranked[4].Params[0].Value)
+	}
+
+	// 2021-01-01 ~ 2021-03-01 holds 3 windows of 14 + 14 days moving by 14 days
+	windows, err := optimizer.WalkForward(context.Background(), []byte(testConfig))
+	if assert.NoError(t, err) && assert.Len(t, windows, 3) {
+		assert.Equal(t, "2021-01-15", windows[0].TestStart.Format("2006-01-02"))
+		assert.Equal(t, "2021-02-26", windows[2].TestEnd.Format("2006-01-02"))
+		assert.Equal(t, 50.0, windows[2].Test.Report.TotalProfit())
+	}
+}
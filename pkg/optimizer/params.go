@@ -0,0 +1,101 @@
+package optimizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParamValue is a parameter value of a backtest run
+type ParamValue struct {
+	Label string      `json:"label" yaml:"label"`
+	Path  string      `json:"path" yaml:"path"`
+	Value interface{} `json:"value" yaml:"value"`
+}
+
+// Combinations returns the cartesian product of the matrix parameters
+func (c *Config) Combinations() ([][]ParamValue, error) {
+	combinations := [][]ParamValue{nil}
+	for _, selector := range c.Matrix {
+		values, err := selector.values()
+		if err != nil {
+			return nil, err
+		}
+
+		var next [][]ParamValue
+		for _, combination := range combinations {
+			for _, value := range values {
+				params := make([]ParamValue, len(combination), len(combination)+1)
+				copy(params, combination)
+				params = append(params, ParamValue{Label: selector.label(), Path: selector.Path, Value: value})
+				next = append(next, params)
+			}
+		}
+
+		combinations = next
+	}
+
+	return combinations, nil
+}
+
+// ApplyParams sets the parameter values on the YAML config content, and returns the new config content
+func ApplyParams(content []byte, params []ParamValue) ([]byte, error) {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	for _, param := range params {
+		if err := setPath(config, param.Path, param.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return yaml.Marshal(config)
+}
+
+// setPath sets the value at the slash-separated path, the map keys and the list indexes must exist except the last map key
+func setPath(doc interface{}, path string, value interface{}) error {
+	keys := strings.Split(strings.Trim(path, "/"), "/")
+	if len(keys) == 0 || len(keys[0]) == 0 {
+		return fmt.Errorf("invalid config path %q", path)
+	}
+
+	node := doc
+	for i, key := range keys {
+		last := i == len(keys)-1
+
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if last {
+				n[key] = value
+				return nil
+			}
+
+			child, ok := n[key]
+			if !ok {
+				return fmt.Errorf("config path %q: key %s not found", path, key)
+			}
+			node = child
+
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(n) {
+				return fmt.Errorf("config path %q: invalid list index %s", path, key)
+			}
+
+			if last {
+				n[index] = value
+				return nil
+			}
+			node = n[index]
+
+		default:
+			return fmt.Errorf("config path %q: %s is not a map or a list", path, key)
+		}
+	}
+
+	return nil
+}
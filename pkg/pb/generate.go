@@ -0,0 +1,3 @@
+package pb
+
+//go:generate protoc --go_out=plugins=grpc,paths=source_relative:. bbgo.proto
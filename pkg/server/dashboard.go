@@ -0,0 +1,361 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// dashboardRefreshInterval is the min interval between the snapshots pushed to the dashboard websocket clients
+const dashboardRefreshInterval = time.Second
+
+// dashboardIndicatorWindows are the standard indicator windows shown on the dashboard
+var dashboardIndicatorWindows = []int{7, 25, 99}
+
+type DashboardSession struct {
+	Name     string `json:"name"`
+	Exchange string `json:"exchange"`
+
+	Balances   types.BalanceMap         `json:"balances"`
+	OpenOrders map[string][]types.Order `json:"openOrders"`
+	LastPrices map[string]float64       `json:"lastPrices"`
+
+	Positions   map[string]*bbgo.Position    `json:"positions"`
+	ProfitStats map[string]*bbgo.ProfitStats `json:"profitStats"`
+}
+
+type DashboardStrategy struct {
+	ID      string `json:"id"`
+	Session string `json:"session,omitempty"`
+
+	// Status is reported by the strategies implementing bbgo.StatusReporter
+	Status string `json:"status,omitempty"`
+}
+
+type DashboardIndicator struct {
+	Session  string         `json:"session"`
+	Symbol   string         `json:"symbol"`
+	Interval types.Interval `json:"interval"`
+	Window   int            `json:"window"`
+	SMA      float64        `json:"sma"`
+	EWMA     float64        `json:"ewma"`
+}
+
+// DashboardSnapshot is the realtime view of the running trader
+type DashboardSnapshot struct {
+	Time       time.Time            `json:"time"`
+	Sessions   []DashboardSession   `json:"sessions"`
+	Strategies []DashboardStrategy  `json:"strategies"`
+	Indicators []DashboardIndicator `json:"indicators"`
+}
+
+// DashboardEvent is pushed to the websocket clients, the type is one of snapshot, kline, trade, order and balance
+type DashboardEvent struct {
+	Type    string      `json:"type"`
+	Session string      `json:"session,omitempty"`
+	Data    interface{} `json:"data"`
+}
+
+type dashboardClient struct {
+	conn *websocket.Conn
+
+	// writeMu guards the writes since the events are pushed from the stream goroutines
+	writeMu sync.Mutex
+}
+
+func (c *dashboardClient) writeJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// dashboardHub pushes the stream events and the throttled snapshots to the dashboard websocket clients
+type dashboardHub struct {
+	environ *bbgo.Environment
+	trader  *bbgo.Trader
+
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*dashboardClient]struct{}
+	dirty   bool
+}
+
+func newDashboardHub(environ *bbgo.Environment, trader *bbgo.Trader) *dashboardHub {
+	return &dashboardHub{
+		environ: environ,
+		trader:  trader,
+		upgrader: websocket.Upgrader{
+			// the api server allows all origins, see the cors config
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		clients: make(map[*dashboardClient]struct{}),
+	}
+}
+
+// bindStreams subscribes the user data and the market data of all sessions
+func (h *dashboardHub) bindStreams() {
+	for _, session := range h.environ.Sessions() {
+		session := session
+		sessionName := session.Name
+
+		session.Stream.OnKLineClosed(func(kline types.KLine) {
+			h.broadcast(DashboardEvent{Type: "kline", Session: sessionName, Data: kline})
+		})
+
+		if session.PublicOnly {
+			continue
+		}
+
+		session.Stream.OnTradeUpdate(func(trade types.Trade) {
+			h.broadcast(DashboardEvent{Type: "trade", Session: sessionName, Data: trade})
+		})
+
+		session.Stream.OnOrderUpdate(func(order types.Order) {
+			h.broadcast(DashboardEvent{Type: "order", Session: sessionName, Data: order})
+		})
+
+		session.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+			h.broadcast(DashboardEvent{Type: "balance", Session: sessionName, Data: balances})
+		})
+	}
+}
+
+// run pushes the snapshot to the clients after the events, at most once per refresh interval
+func (h *dashboardHub) run(ctx context.Context) {
+	ticker := time.NewTicker(dashboardRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.closeClients()
+			return
+
+		case <-ticker.C:
+			h.mu.Lock()
+			dirty := h.dirty && len(h.clients) > 0
+			h.dirty = false
+			h.mu.Unlock()
+
+			if dirty {
+				h.broadcast(DashboardEvent{Type: "snapshot", Data: h.Snapshot()})
+			}
+		}
+	}
+}
+
+func (h *dashboardHub) broadcast(event DashboardEvent) {
+	h.mu.Lock()
+	if event.Type != "snapshot" {
+		h.dirty = true
+	}
+
+	var clients []*dashboardClient
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		if err := c.writeJSON(event); err != nil {
+			logrus.WithError(err).Debug("dashboard websocket write error")
+			h.removeClient(c)
+		}
+	}
+}
+
+func (h *dashboardHub) removeClient(c *dashboardClient) {
+	h.mu.Lock()
+	delete(h.clients, c)
+	h.mu.Unlock()
+
+	_ = c.conn.Close()
+}
+
+func (h *dashboardHub) closeClients() {
+	h.mu.Lock()
+	clients := h.clients
+	h.clients = make(map[*dashboardClient]struct{})
+	h.mu.Unlock()
+
+	for c := range clients {
+		_ = c.conn.Close()
+	}
+}
+
+// serve upgrades the connection, sends the current snapshot and keeps the client until it disconnects
+func (h *dashboardHub) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.WithError(err).Error("dashboard websocket upgrade error")
+		return
+	}
+
+	client := &dashboardClient{conn: conn}
+	if err := client.writeJSON(DashboardEvent{Type: "snapshot", Data: h.Snapshot()}); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	// the dashboard doesn't send anything, read until the connection is closed
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			h.removeClient(client)
+			return
+		}
+	}
+}
+
+// Snapshot collects the balances, the open orders, the positions and the realized profits of each session,
+// the running strategies and the standard indicator values of the subscribed kline intervals.
+func (h *dashboardHub) Snapshot() DashboardSnapshot {
+	snapshot := DashboardSnapshot{
+		Time:       time.Now(),
+		Sessions:   []DashboardSession{},
+		Strategies: []DashboardStrategy{},
+		Indicators: []DashboardIndicator{},
+	}
+
+	var sessionNames []string
+	sessions := h.environ.Sessions()
+	for name := range sessions {
+		sessionNames = append(sessionNames, name)
+	}
+	sort.Strings(sessionNames)
+
+	for _, name := range sessionNames {
+		session := sessions[name]
+
+		dashboardSession := DashboardSession{
+			Name:        name,
+			Exchange:    session.ExchangeName,
+			Balances:    session.Account.Balances(),
+			OpenOrders:  make(map[string][]types.Order),
+			LastPrices:  session.LastPrices(),
+			Positions:   session.Positions(),
+			ProfitStats: make(map[string]*bbgo.ProfitStats),
+		}
+
+		for symbol, orderStore := range session.OrderStores() {
+			dashboardSession.OpenOrders[symbol] = orderStore.Orders()
+		}
+
+		for symbol := range session.Positions() {
+			if stats, ok := session.ProfitStats(symbol); ok {
+				dashboardSession.ProfitStats[symbol] = stats
+			}
+		}
+
+		snapshot.Sessions = append(snapshot.Sessions, dashboardSession)
+		snapshot.Indicators = append(snapshot.Indicators, sessionIndicators(session)...)
+	}
+
+	if h.trader != nil {
+		for sessionName, strategies := range h.trader.ExchangeStrategies() {
+			for _, strategy := range strategies {
+				snapshot.Strategies = append(snapshot.Strategies, DashboardStrategy{
+					ID:      strategy.ID(),
+					Session: sessionName,
+					Status:  strategyStatus(strategy),
+				})
+			}
+		}
+
+		for _, strategy := range h.trader.CrossExchangeStrategies() {
+			snapshot.Strategies = append(snapshot.Strategies, DashboardStrategy{
+				ID:     strategy.ID(),
+				Status: strategyStatus(strategy),
+			})
+		}
+
+		sort.SliceStable(snapshot.Strategies, func(i, j int) bool {
+			return snapshot.Strategies[i].Session < snapshot.Strategies[j].Session
+		})
+	}
+
+	return snapshot
+}
+
+func strategyStatus(strategy interface{}) string {
+	if reporter, ok := strategy.(bbgo.StatusReporter); ok {
+		return reporter.Status()
+	}
+
+	return ""
+}
+
+// sessionIndicators returns the standard SMA and EWMA values of the kline subscriptions of the session
+func sessionIndicators(session *bbgo.ExchangeSession) (indicators []DashboardIndicator) {
+	for sub := range session.Subscriptions {
+		if sub.Channel != types.KLineChannel {
+			continue
+		}
+
+		set, ok := session.StandardIndicatorSet(sub.Symbol)
+		if !ok {
+			continue
+		}
+
+		interval := types.Interval(sub.Options.Interval)
+		for _, window := range dashboardIndicatorWindows {
+			iw := types.IntervalWindow{Interval: interval, Window: window}
+			sma, _ := set.SMA(iw).Last()
+			ewma, _ := set.EWMA(iw).Last()
+			indicators = append(indicators, DashboardIndicator{
+				Session:  session.Name,
+				Symbol:   sub.Symbol,
+				Interval: interval,
+				Window:   window,
+				SMA:      sma,
+				EWMA:     ewma,
+			})
+		}
+	}
+
+	sort.Slice(indicators, func(i, j int) bool {
+		a, b := indicators[i], indicators[j]
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+
+		if a.Interval != b.Interval {
+			return a.Interval.Duration() < b.Interval.Duration()
+		}
+
+		return a.Window < b.Window
+	})
+
+	return indicators
+}
+
+func (s *Server) getDashboard(c *gin.Context) {
+	if s.dashboard == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dashboard is not enabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"dashboard": s.dashboard.Snapshot()})
+}
+
+func (s *Server) dashboardStream(c *gin.Context) {
+	if s.dashboard == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dashboard is not enabled"})
+		return
+	}
+
+	s.dashboard.serve(c.Writer, c.Request)
+}
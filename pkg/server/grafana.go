@@ -0,0 +1,185 @@
+package server
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// The strategy stats are served in the format of the Grafana JSON datasource (and the legacy SimpleJSON datasource),
+// the target name is "{strategyID}.{session}.{field}", e.g., "grid.binance.pnl".
+const (
+	grafanaFieldPnL      = "pnl"
+	grafanaFieldFills    = "fills"
+	grafanaFieldExposure = "exposure"
+)
+
+var grafanaFields = []string{grafanaFieldPnL, grafanaFieldFills, grafanaFieldExposure}
+
+const defaultGrafanaInterval = time.Minute
+
+type grafanaTimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type grafanaTarget struct {
+	RefID  string `json:"refId"`
+	Target string `json:"target"`
+}
+
+type grafanaQueryRequest struct {
+	Range         grafanaTimeRange `json:"range"`
+	IntervalMs    int64            `json:"intervalMs"`
+	MaxDataPoints int64            `json:"maxDataPoints"`
+	Targets       []grafanaTarget  `json:"targets"`
+}
+
+type grafanaTimeSeries struct {
+	Target string `json:"target"`
+
+	// Datapoints are the [value, unix timestamp in milliseconds] pairs
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaMetric struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// grafanaTargets returns all the target names of the running strategies
+func (s *Server) grafanaTargets() (targets []string) {
+	// the empty time range only lists the strategies without aggregating the trades
+	now := time.Now().Truncate(time.Minute)
+	stats, _ := s.Trader.StrategyStats(now, now, time.Minute)
+	for _, st := range stats {
+		for _, field := range grafanaFields {
+			targets = append(targets, grafanaTargetName(st, field))
+		}
+	}
+
+	sort.Strings(targets)
+	return targets
+}
+
+func grafanaTargetName(stats bbgo.StrategyStats, field string) string {
+	return stats.StrategyID + "." + stats.Session + "." + field
+}
+
+// grafanaHealth is the connection test of the datasource
+func (s *Server) grafanaHealth(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// grafanaSearch lists the target names for the SimpleJSON datasource
+func (s *Server) grafanaSearch(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	targets := s.grafanaTargets()
+	if targets == nil {
+		targets = []string{}
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// grafanaMetrics lists the target names for the JSON datasource
+func (s *Server) grafanaMetrics(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var metrics = []grafanaMetric{}
+	for _, target := range s.grafanaTargets() {
+		metrics = append(metrics, grafanaMetric{Label: target, Value: target})
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
+
+// grafanaQuery returns the time series of the requested targets
+func (s *Server) grafanaQuery(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var req grafanaQueryRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !req.Range.From.Before(req.Range.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time range"})
+		return
+	}
+
+	interval := time.Duration(req.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultGrafanaInterval
+	}
+
+	if req.MaxDataPoints > 0 {
+		if minInterval := req.Range.To.Sub(req.Range.From) / time.Duration(req.MaxDataPoints); interval < minInterval {
+			interval = minInterval
+		}
+	}
+
+	stats, err := s.Trader.StrategyStats(req.Range.From, req.Range.To, interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var series = []grafanaTimeSeries{}
+	for _, target := range req.Targets {
+		for _, st := range stats {
+			for _, field := range grafanaFields {
+				if !strings.EqualFold(target.Target, grafanaTargetName(st, field)) {
+					continue
+				}
+
+				series = append(series, grafanaTimeSeries{
+					Target:     grafanaTargetName(st, field),
+					Datapoints: grafanaDatapoints(st, field),
+				})
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func grafanaDatapoints(stats bbgo.StrategyStats, field string) [][2]float64 {
+	var datapoints = make([][2]float64, 0, len(stats.Buckets))
+	for _, bucket := range stats.Buckets {
+		var value float64
+		switch field {
+		case grafanaFieldPnL:
+			value = bucket.PnL
+		case grafanaFieldFills:
+			value = float64(bucket.NumFills)
+		case grafanaFieldExposure:
+			value = bucket.Exposure
+		}
+
+		datapoints = append(datapoints, [2]float64{value, float64(bucket.Time.UnixNano() / int64(time.Millisecond))})
+	}
+
+	return datapoints
+}
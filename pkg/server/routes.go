@@ -9,6 +9,7 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -44,6 +45,9 @@ type Server struct {
 	OpenInBrowser bool
 
 	srv *http.Server
+
+	// dashboard pushes the realtime trader status, it's only available when the trader is running (not in the setup mode)
+	dashboard *dashboardHub
 }
 
 func (s *Server) newEngine() *gin.Engine {
@@ -102,6 +106,7 @@ func (s *Server) newEngine() *gin.Engine {
 	})
 
 	r.GET("/api/orders/closed", s.listClosedOrders)
+	r.GET("/api/orders/execution", s.getOrderExecution)
 	r.GET("/api/trading-volume", s.tradingVolume)
 
 	r.POST("/api/sessions/test", func(c *gin.Context) {
@@ -194,6 +199,7 @@ func (s *Server) newEngine() *gin.Engine {
 	r.GET("/api/sessions/:session/account", s.getSessionAccount)
 	r.GET("/api/sessions/:session/account/balances", s.getSessionAccountBalance)
 	r.GET("/api/sessions/:session/symbols", s.listSessionSymbols)
+	r.GET("/api/sessions/:session/equity-curve", s.getSessionEquityCurve)
 
 	r.GET("/api/sessions/:session/pnl", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
@@ -212,12 +218,41 @@ func (s *Server) newEngine() *gin.Engine {
 	})
 
 	r.GET("/api/strategies/single", s.listStrategies)
+	r.POST("/api/strategies/:name/pause", s.pauseStrategy)
+	r.POST("/api/strategies/:name/unpause", s.unpauseStrategy)
+	r.POST("/api/strategies/:name/suspend", s.suspendStrategy)
+	r.POST("/api/strategies/:name/resume", s.resumeStrategy)
+	r.POST("/api/halt", s.halt)
+	r.GET("/api/risk/portfolio", s.getPortfolioRisk)
+	r.GET("/api/accounts/snapshot", s.getAccountSnapshot)
+
+	// Grafana JSON datasource of the strategy stats
+	r.GET("/api/grafana", s.grafanaHealth)
+	r.POST("/api/grafana/search", s.grafanaSearch)
+	r.POST("/api/grafana/metrics", s.grafanaMetrics)
+	r.POST("/api/grafana/query", s.grafanaQuery)
+
+	r.GET("/api/dashboard", s.getDashboard)
+	r.GET("/api/dashboard/ws", s.dashboardStream)
 	r.NoRoute(s.pkgerHandler)
 
 	return r
 }
 
+// startDashboard binds the session streams for the dashboard when the trader is running
+func (s *Server) startDashboard(ctx context.Context) {
+	if s.Setup != nil || s.Environ == nil {
+		return
+	}
+
+	s.dashboard = newDashboardHub(s.Environ, s.Trader)
+	s.dashboard.bindStreams()
+	go s.dashboard.run(ctx)
+}
+
 func (s *Server) RunWithListener(ctx context.Context, l net.Listener) error {
+	s.startDashboard(ctx)
+
 	r := s.newEngine()
 	bind := l.Addr().String()
 
@@ -230,6 +265,8 @@ func (s *Server) RunWithListener(ctx context.Context, l net.Listener) error {
 }
 
 func (s *Server) Run(ctx context.Context, bindArgs ...string) error {
+	s.startDashboard(ctx)
+
 	r := s.newEngine()
 	bind := resolveBind(bindArgs)
 	if s.OpenInBrowser {
@@ -278,6 +315,36 @@ func (s *Server) listClosedOrders(c *gin.Context) {
 	})
 }
 
+func (s *Server) getOrderExecution(c *gin.Context) {
+	if s.Environ.OrderService == nil || s.Environ.TradeService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	exchangeName, err := types.ValidExchangeName(c.Query("exchange"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orderID, err := strconv.ParseUint(c.Query("orderID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	execution, err := service.QueryOrderExecution(s.Environ.OrderService, s.Environ.TradeService, exchangeName, orderID)
+	if err != nil {
+		logrus.WithError(err).Error("order execution query error")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"execution": execution,
+	})
+}
+
 func (s *Server) listStrategies(c *gin.Context) {
 	var stashes []map[string]interface{}
 
@@ -299,6 +366,211 @@ func (s *Server) listStrategies(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"strategies": stashes})
 }
 
+// pauseStrategy blocks the orders of the running strategy, the pause flag is persisted across restarts
+func (s *Server) pauseStrategy(c *gin.Context) {
+	s.setStrategyPaused(c, true)
+}
+
+// unpauseStrategy clears the pause flag of the strategy
+func (s *Server) unpauseStrategy(c *gin.Context) {
+	s.setStrategyPaused(c, false)
+}
+
+func (s *Server) setStrategyPaused(c *gin.Context, paused bool) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	name := c.Param("name")
+	lifecycle := s.Trader.Lifecycle()
+	if _, err := lifecycle.Lookup(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var info bbgo.StrategyInfo
+	var err error
+	if paused {
+		info, err = lifecycle.Pause(name)
+	} else {
+		info, err = lifecycle.Unpause(name)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": info.Name, "state": info.State, "paused": info.Paused})
+}
+
+// suspendStrategy suspends the running strategy, the open orders are handled by the suspend order policy of the strategy,
+// the policy can be overridden by the JSON body, e.g., {"policy": "keep"}
+func (s *Server) suspendStrategy(c *gin.Context) {
+	var request struct {
+		Policy bbgo.SuspendOrderPolicy `json:"policy"`
+	}
+
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	s.controlStrategy(c, func(ctx context.Context, lifecycle *bbgo.LifecycleManager, name string) (bbgo.StrategyInfo, error) {
+		return lifecycle.SuspendWithPolicy(ctx, name, request.Policy)
+	})
+}
+
+// resumeStrategy resumes the suspended strategy
+func (s *Server) resumeStrategy(c *gin.Context) {
+	s.controlStrategy(c, func(ctx context.Context, lifecycle *bbgo.LifecycleManager, name string) (bbgo.StrategyInfo, error) {
+		return lifecycle.Resume(ctx, name)
+	})
+}
+
+func (s *Server) controlStrategy(c *gin.Context, control func(ctx context.Context, lifecycle *bbgo.LifecycleManager, name string) (bbgo.StrategyInfo, error)) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	name := c.Param("name")
+	lifecycle := s.Trader.Lifecycle()
+	if _, err := lifecycle.Lookup(name); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// the orders are canceled or restored even if the client disconnects
+	ctx, cancel := context.WithTimeout(context.Background(), bbgo.DefaultSuspendTimeout)
+	defer cancel()
+
+	info, err := control(ctx, lifecycle, name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "state": info.State})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": info.Name, "state": info.State, "suspendOrderPolicy": info.SuspendOrderPolicy})
+}
+
+// halt is the emergency kill switch, it suspends the strategies and cancels all the open orders,
+// the positions are closed if closePositions is set in the JSON body, e.g., {"closePositions": true, "reason": "..."}
+func (s *Server) halt(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	var options bbgo.HaltOptions
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	options.Source = "api"
+
+	// the halt is not aborted when the client disconnects
+	ctx, cancel := context.WithTimeout(context.Background(), bbgo.DefaultHaltTimeout)
+	defer cancel()
+
+	report := s.Trader.Halt(ctx, options)
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+func (s *Server) getPortfolioRisk(c *gin.Context) {
+	risk, err := s.Environ.PortfolioRisk(c, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"risk": risk})
+}
+
+// getAccountSnapshot queries the balances and the open orders of all sessions from the exchanges,
+// the optional query parameters are symbols (comma separated) and timeout (e.g., 5s).
+func (s *Server) getAccountSnapshot(c *gin.Context) {
+	var symbols []string
+	if val := c.Query("symbols"); len(val) > 0 {
+		symbols = strings.Split(val, ",")
+	}
+
+	var timeout = bbgo.DefaultAccountSnapshotTimeout
+	if val := c.Query("timeout"); len(val) > 0 {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		timeout = d
+	}
+
+	snapshot := bbgo.QueryAccountSnapshot(c, s.Environ.Sessions(), symbols, timeout)
+	c.JSON(http.StatusOK, gin.H{"snapshot": snapshot})
+}
+
+// getSessionEquityCurve returns the recorded net values of the session in [since, until), the last 30 days by default,
+// the points are written in the CSV format if format=csv is given.
+func (s *Server) getSessionEquityCurve(c *gin.Context) {
+	if s.Environ.BalanceSnapshotService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	sessionName := c.Param("session")
+	until := time.Now()
+	since := until.AddDate(0, 0, -30)
+
+	if val := c.Query("since"); len(val) > 0 {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		since = t
+	}
+
+	if val := c.Query("until"); len(val) > 0 {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		until = t
+	}
+
+	snapshots, err := s.Environ.BalanceSnapshotService.QueryEquityCurve(sessionName, since, until)
+	if err != nil {
+		logrus.WithError(err).Error("equity curve query error")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "csv" {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-equity-curve.csv", sessionName))
+		if err := service.WriteEquityCurveCSV(c.Writer, snapshots); err != nil {
+			logrus.WithError(err).Error("equity curve csv write error")
+		}
+		return
+	}
+
+	if snapshots == nil {
+		snapshots = []service.AccountValueSnapshot{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"equityCurve": snapshots})
+}
+
 func (s *Server) listSessions(c *gin.Context) {
 	sessionName := c.Param("session")
 	session, ok := s.Environ.Session(sessionName)
@@ -423,6 +695,9 @@ func (s *Server) setupSaveConfig(c *gin.Context) {
 
 	if len(s.Environ.MysqlURL) > 0 {
 		envVars["MYSQL_URL"] = s.Environ.MysqlURL
+	} else if len(s.Environ.DatabaseDSN) > 0 {
+		envVars["DB_DRIVER"] = s.Environ.DatabaseDriver
+		envVars["DB_DSN"] = s.Environ.DatabaseDSN
 	}
 
 	dotenvFile := ".env.local"
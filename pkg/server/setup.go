@@ -15,7 +15,9 @@ import (
 
 func (s *Server) setupTestDB(c *gin.Context) {
 	payload := struct {
-		DSN string `json:"dsn"`
+		// Driver is "mysql" (default) or "sqlite3"
+		Driver string `json:"driver"`
+		DSN    string `json:"dsn"`
 	}{}
 
 	if err := c.BindJSON(&payload); err != nil {
@@ -29,7 +31,7 @@ func (s *Server) setupTestDB(c *gin.Context) {
 		return
 	}
 
-	db, err := bbgo.ConnectMySQL(dsn)
+	db, err := bbgo.ConnectDB(payload.Driver, dsn)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -44,7 +46,9 @@ func (s *Server) setupTestDB(c *gin.Context) {
 
 func (s *Server) setupConfigureDB(c *gin.Context) {
 	payload := struct {
-		DSN string `json:"dsn"`
+		// Driver is "mysql" (default) or "sqlite3"
+		Driver string `json:"driver"`
+		DSN    string `json:"dsn"`
 	}{}
 
 	if err := c.BindJSON(&payload); err != nil {
@@ -58,7 +62,7 @@ func (s *Server) setupConfigureDB(c *gin.Context) {
 		return
 	}
 
-	if err := s.Environ.ConfigureDatabase(c, dsn); err != nil {
+	if err := s.Environ.ConfigureDatabaseDriver(c, payload.Driver, dsn); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	AuditActionSubmitOrders = "submitOrders"
+	AuditActionCancelOrders = "cancelOrders"
+
+	// AuditActionOrderCanceled is the order canceled on the exchange, it's recorded from the order update of the user data stream
+	AuditActionOrderCanceled = "orderCanceled"
+
+	AuditActionSubmitOCOOrder = "submitOCOOrder"
+	AuditActionAmendOrder     = "amendOrder"
+
+	AuditActionTransferInternal   = "transferInternal"
+	AuditActionTransferSubAccount = "transferSubAccount"
+
+	AuditActionBorrowMarginAsset = "borrowMarginAsset"
+	AuditActionRepayMarginAsset  = "repayMarginAsset"
+
+	// AuditActionWithdraw is the withdrawal found by the withdraw history sync, the session of the log is empty
+	AuditActionWithdraw = "withdraw"
+
+	// AuditActionHalt is the emergency halt of all the sessions, the exchange and the session of the log are empty
+	AuditActionHalt = "halt"
+)
+
+// AuditLog is a record of an authenticated API call that mutates the account state
+type AuditLog struct {
+	GID      int64              `json:"gid" db:"gid"`
+	Exchange types.ExchangeName `json:"exchange" db:"exchange"`
+	Session  string             `json:"session" db:"session"`
+	Action   string             `json:"action" db:"action"`
+
+	// Params is the JSON encoded parameters of the call
+	Params string `json:"params" db:"params"`
+
+	// Result is the JSON encoded result of the call
+	Result string `json:"result" db:"result"`
+
+	Error string    `json:"error" db:"error"`
+	Time  time.Time `json:"time" db:"time"`
+}
+
+type AuditService struct {
+	DB *sqlx.DB
+}
+
+func (s *AuditService) Insert(auditLog AuditLog) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO audit_logs (exchange, session, action, params, result, error, time)
+			VALUES (:exchange, :session, :action, :params, :result, :error, :time)`,
+		auditLog)
+	return err
+}
+
+// Query queries the audit logs between since and until in the ascending order of the time
+func (s *AuditService) Query(since, until time.Time) ([]AuditLog, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM audit_logs WHERE time >= :since AND time <= :until ORDER BY gid ASC`, map[string]interface{}{
+		"since": since,
+		"until": until,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var logs []AuditLog
+	for rows.Next() {
+		var auditLog AuditLog
+		if err := rows.StructScan(&auditLog); err != nil {
+			return logs, err
+		}
+
+		logs = append(logs, auditLog)
+	}
+
+	return logs, rows.Err()
+}
+
+// WriteAuditLogsCSV writes the audit logs to the writer in the CSV format with a header row
+func WriteAuditLogsCSV(w io.Writer, logs []AuditLog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"gid", "time", "exchange", "session", "action", "params", "result", "error"}); err != nil {
+		return err
+	}
+
+	for _, auditLog := range logs {
+		if err := writer.Write([]string{
+			strconv.FormatInt(auditLog.GID, 10),
+			auditLog.Time.Format(time.RFC3339Nano),
+			string(auditLog.Exchange),
+			auditLog.Session,
+			auditLog.Action,
+			auditLog.Params,
+			auditLog.Result,
+			auditLog.Error,
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAuditLogsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteAuditLogsCSV(&buf, []AuditLog{
+		{
+			GID:      1,
+			Exchange: "max",
+			Session:  "max",
+			Action:   AuditActionCancelOrders,
+			Params:   `[{"orderID":1}]`,
+			Error:    "order not found",
+			Time:     time.Date(2021, 2, 5, 11, 0, 0, 0, time.UTC),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "gid,time,exchange,session,action,params,result,error\n"+
+		`1,2021-02-05T11:00:00Z,max,max,cancelOrders,"[{""orderID"":1}]",,order not found`+"\n", buf.String())
+}
@@ -19,32 +19,121 @@ type BacktestService struct {
 func (s *BacktestService) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
 	now := time.Now()
 	for interval := range types.SupportedIntervals {
-		log.Infof("synchronizing lastKLine for interval %s from exchange %s", interval, exchange.Name())
-
-		lastKLine, err := s.QueryLast(exchange.Name(), symbol, interval)
-		if err != nil {
+		if err := s.SyncKLines(ctx, exchange, symbol, interval, startTime, now); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// TimeRange is the time range [From, To)
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// findMissingTimeRanges returns the time ranges between since and until that are not covered by the klines starting at
+// the given start times (in the ascending order), including the head before the first kline and the tail after the last kline.
+func findMissingTimeRanges(startTimes []time.Time, interval types.Interval, since, until time.Time) (ranges []TimeRange) {
+	var duration = interval.Duration()
+	var cursor = since
+	for _, startTime := range startTimes {
+		if startTime.Before(since) || !startTime.Before(until) {
+			continue
+		}
+
+		if startTime.Sub(cursor) >= duration {
+			ranges = append(ranges, TimeRange{From: cursor, To: startTime})
+		}
 
-		if lastKLine != nil {
-			log.Infof("found last checkpoint %s", lastKLine.EndTime)
-			startTime = lastKLine.StartTime.Add(time.Minute)
+		if next := startTime.Add(duration); next.After(cursor) {
+			cursor = next
 		}
+	}
+
+	if until.Sub(cursor) >= duration {
+		ranges = append(ranges, TimeRange{From: cursor, To: until})
+	}
 
-		batch := &types.ExchangeBatchProcessor{Exchange: exchange}
+	return ranges
+}
+
+// queryKLineStartTimes queries the start times of the stored klines between since and until in the ascending order
+func (s *BacktestService) queryKLineStartTimes(ex types.ExchangeName, symbol string, interval types.Interval, since, until time.Time) ([]time.Time, error) {
+	sql := "SELECT `start_time` FROM `binance_klines` WHERE `symbol` = :symbol AND `interval` = :interval AND `start_time` >= :since AND `start_time` < :until ORDER BY start_time ASC"
+	sql = strings.ReplaceAll(sql, "binance_klines", ex.String()+"_klines")
+
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"symbol":   symbol,
+		"interval": interval,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query kline start times error")
+	}
+
+	defer rows.Close()
+
+	var startTimes []time.Time
+	for rows.Next() {
+		var startTime time.Time
+		if err := rows.Scan(&startTime); err != nil {
+			return nil, err
+		}
+
+		startTimes = append(startTimes, startTime)
+	}
+
+	return startTimes, rows.Err()
+}
+
+// FindMissingTimeRanges returns the time ranges between since and until that have no kline stored in the database
+func (s *BacktestService) FindMissingTimeRanges(ex types.ExchangeName, symbol string, interval types.Interval, since, until time.Time) ([]TimeRange, error) {
+	startTimes, err := s.queryKLineStartTimes(ex, symbol, interval, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	return findMissingTimeRanges(startTimes, interval, since, until), nil
+}
+
+// SyncKLines downloads the klines between since and until from the exchange into the database,
+// only the missing time ranges (the gaps, the head and the tail) are downloaded, so that it can be called repeatedly
+// to keep the klines up to date incrementally.
+func (s *BacktestService) SyncKLines(ctx context.Context, exchange types.Exchange, symbol string, interval types.Interval, since, until time.Time) error {
+	ranges, err := s.FindMissingTimeRanges(exchange.Name(), symbol, interval, since, until)
+	if err != nil {
+		return err
+	}
 
-		// should use channel here
-		klineC, errC := batch.BatchQueryKLines(ctx, symbol, interval, startTime, now)
-		// var previousKLine types.KLine
+	batch := &types.ExchangeBatchProcessor{Exchange: exchange}
+	for _, r := range ranges {
+		log.Infof("synchronizing %s %s klines from %s to %s from exchange %s", symbol, interval, r.From, r.To, exchange.Name())
+
+		var count int
+		klineC, errC := batch.BatchQueryKLines(ctx, symbol, interval, r.From, r.To)
 		for k := range klineC {
+			// the klines of the next range are already stored
+			if !k.StartTime.Before(r.To) {
+				continue
+			}
+
 			if err := s.Insert(k); err != nil {
 				return err
 			}
+
+			count++
 		}
 
 		if err := <-errC; err != nil {
 			return err
 		}
+
+		if count == 0 {
+			log.Warnf("no %s %s kline is returned from %s to %s, the exchange may not have the data of this range", symbol, interval, r.From, r.To)
+		}
 	}
 
 	return nil
@@ -201,3 +290,74 @@ func (s *BacktestService) Insert(kline types.KLine) error {
 	_, err := s.DB.NamedExec(sql, kline)
 	return err
 }
+
+// QueryKLinesByTimeRange queries the klines starting in [since, until) in the ascending order of the start time
+func (s *BacktestService) QueryKLinesByTimeRange(ex types.ExchangeName, symbol string, interval types.Interval, since, until time.Time) ([]types.KLine, error) {
+	sql := "SELECT * FROM `binance_klines` WHERE `symbol` = :symbol AND `interval` = :interval AND `start_time` >= :since AND `start_time` < :until ORDER BY start_time ASC"
+	sql = strings.ReplaceAll(sql, "binance_klines", ex.String()+"_klines")
+
+	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
+		"symbol":   symbol,
+		"interval": interval,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query klines error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// Import inserts the klines of the same symbol and interval into the database, the klines that are already stored
+// (with the same start time) are skipped, so that the overlapped data dumps can be imported repeatedly.
+// It returns the number of the inserted klines.
+func (s *BacktestService) Import(klines []types.KLine) (int, error) {
+	if len(klines) == 0 {
+		return 0, nil
+	}
+
+	first := klines[0]
+	since, until := first.StartTime, first.StartTime
+	for _, k := range klines {
+		if k.Exchange != first.Exchange || k.Symbol != first.Symbol || k.Interval != first.Interval {
+			return 0, errors.New("the imported klines should have the same exchange, symbol and interval")
+		}
+
+		if k.StartTime.Before(since) {
+			since = k.StartTime
+		}
+
+		if k.StartTime.After(until) {
+			until = k.StartTime
+		}
+	}
+
+	startTimes, err := s.queryKLineStartTimes(types.ExchangeName(first.Exchange), first.Symbol, first.Interval, since, until.Add(time.Millisecond))
+	if err != nil {
+		return 0, err
+	}
+
+	var stored = map[int64]struct{}{}
+	for _, t := range startTimes {
+		stored[t.Unix()] = struct{}{}
+	}
+
+	var count int
+	for _, k := range klines {
+		if _, ok := stored[k.StartTime.Unix()]; ok {
+			continue
+		}
+
+		if err := s.Insert(k); err != nil {
+			return count, err
+		}
+
+		stored[k.StartTime.Unix()] = struct{}{}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_findMissingTimeRanges(t *testing.T) {
+	since := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(10 * time.Minute)
+	at := func(minutes int) time.Time {
+		return since.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	t.Run("empty database", func(t *testing.T) {
+		assert.Equal(t, []TimeRange{{From: since, To: until}}, findMissingTimeRanges(nil, types.Interval1m, since, until))
+	})
+
+	t.Run("fully covered", func(t *testing.T) {
+		var startTimes []time.Time
+		for i := 0; i < 10; i++ {
+			startTimes = append(startTimes, at(i))
+		}
+
+		assert.Empty(t, findMissingTimeRanges(startTimes, types.Interval1m, since, until))
+	})
+
+	t.Run("head, gaps and tail", func(t *testing.T) {
+		startTimes := []time.Time{at(2), at(3), at(6), at(7)}
+		assert.Equal(t, []TimeRange{
+			{From: at(0), To: at(2)},
+			{From: at(4), To: at(6)},
+			{From: at(8), To: until},
+		}, findMissingTimeRanges(startTimes, types.Interval1m, since, until))
+	})
+
+	t.Run("the unclosed tail is not missing", func(t *testing.T) {
+		startTimes := []time.Time{at(0), at(5)}
+		assert.Empty(t, findMissingTimeRanges(startTimes, types.Interval5m, since, at(14)))
+	})
+
+	t.Run("duplicated klines", func(t *testing.T) {
+		startTimes := []time.Time{at(0), at(0), at(1), at(3)}
+		assert.Equal(t, []TimeRange{
+			{From: at(2), To: at(3)},
+			{From: at(4), To: until},
+		}, findMissingTimeRanges(startTimes, types.Interval1m, since, until))
+	})
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// AccountValueSnapshot is a point of the equity curve, the net value of the session account at the time
+type AccountValueSnapshot struct {
+	GID           int64              `json:"gid" db:"gid"`
+	Session       string             `json:"session" db:"session"`
+	Exchange      types.ExchangeName `json:"exchange" db:"exchange"`
+	QuoteCurrency string             `json:"quoteCurrency" db:"quote_currency"`
+	NetValue      float64            `json:"netValue" db:"net_value"`
+	Time          time.Time          `json:"time" db:"time"`
+}
+
+// BalanceSnapshot is the balance of a currency valued in the quote currency at the snapshot time
+type BalanceSnapshot struct {
+	GID           int64              `json:"gid" db:"gid"`
+	Session       string             `json:"session" db:"session"`
+	Exchange      types.ExchangeName `json:"exchange" db:"exchange"`
+	Currency      string             `json:"currency" db:"currency"`
+	Total         float64            `json:"total" db:"total"`
+	Borrowed      float64            `json:"borrowed" db:"borrowed"`
+	Price         float64            `json:"price" db:"price"`
+	Value         float64            `json:"value" db:"value"`
+	QuoteCurrency string             `json:"quoteCurrency" db:"quote_currency"`
+	Time          time.Time          `json:"time" db:"time"`
+}
+
+type BalanceSnapshotService struct {
+	DB *sqlx.DB
+}
+
+// Insert records the net value and the priced balances of the session account in one transaction
+func (s *BalanceSnapshotService) Insert(session string, exchange types.ExchangeName, value types.AccountValue) error {
+	tx, err := s.DB.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin balance snapshot transaction error")
+	}
+
+	if _, err := tx.NamedExec(`
+			INSERT INTO account_value_snapshots (session, exchange, quote_currency, net_value, time)
+			VALUES (:session, :exchange, :quote_currency, :net_value, :time)`,
+		AccountValueSnapshot{
+			Session:       session,
+			Exchange:      exchange,
+			QuoteCurrency: value.QuoteCurrency,
+			NetValue:      value.NetValue,
+			Time:          value.Time,
+		}); err != nil {
+		_ = tx.Rollback()
+		return errors.Wrap(err, "insert account value snapshot error")
+	}
+
+	for _, asset := range value.Assets {
+		if _, err := tx.NamedExec(`
+			INSERT INTO balance_snapshots (session, exchange, currency, total, borrowed, price, value, quote_currency, time)
+			VALUES (:session, :exchange, :currency, :total, :borrowed, :price, :value, :quote_currency, :time)`,
+			BalanceSnapshot{
+				Session:       session,
+				Exchange:      exchange,
+				Currency:      asset.Currency,
+				Total:         asset.Total,
+				Borrowed:      asset.Borrowed,
+				Price:         asset.Price,
+				Value:         asset.Value,
+				QuoteCurrency: value.QuoteCurrency,
+				Time:          value.Time,
+			}); err != nil {
+			_ = tx.Rollback()
+			return errors.Wrap(err, "insert balance snapshot error")
+		}
+	}
+
+	return tx.Commit()
+}
+
+// QueryEquityCurve queries the net value snapshots of the session in [since, until) in the ascending order of the time
+func (s *BalanceSnapshotService) QueryEquityCurve(session string, since, until time.Time) ([]AccountValueSnapshot, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM account_value_snapshots WHERE session = :session AND time >= :since AND time < :until ORDER BY time ASC, gid ASC`, map[string]interface{}{
+		"session": session,
+		"since":   since,
+		"until":   until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query account value snapshots error")
+	}
+
+	defer rows.Close()
+
+	var snapshots []AccountValueSnapshot
+	for rows.Next() {
+		var snapshot AccountValueSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return snapshots, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// QueryBalances queries the balance snapshots of the session in [since, until) in the ascending order of the time
+func (s *BalanceSnapshotService) QueryBalances(session string, since, until time.Time) ([]BalanceSnapshot, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM balance_snapshots WHERE session = :session AND time >= :since AND time < :until ORDER BY time ASC, gid ASC`, map[string]interface{}{
+		"session": session,
+		"since":   since,
+		"until":   until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query balance snapshots error")
+	}
+
+	defer rows.Close()
+
+	var snapshots []BalanceSnapshot
+	for rows.Next() {
+		var snapshot BalanceSnapshot
+		if err := rows.StructScan(&snapshot); err != nil {
+			return snapshots, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// WriteEquityCurveCSV writes the net value snapshots to the writer in the CSV format with a header row
+func WriteEquityCurveCSV(w io.Writer, snapshots []AccountValueSnapshot) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"time", "session", "exchange", "quote_currency", "net_value"}); err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		if err := writer.Write([]string{
+			snapshot.Time.Format(time.RFC3339Nano),
+			snapshot.Session,
+			string(snapshot.Exchange),
+			snapshot.QuoteCurrency,
+			strconv.FormatFloat(snapshot.NetValue, 'f', -1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
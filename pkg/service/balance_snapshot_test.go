@@ -0,0 +1,21 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEquityCurveCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEquityCurveCSV(&buf, []AccountValueSnapshot{
+		{Session: "binance", Exchange: "binance", QuoteCurrency: "USDT", NetValue: 1000.5, Time: time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{Session: "binance", Exchange: "binance", QuoteCurrency: "USDT", NetValue: 1012.25, Time: time.Date(2021, 2, 15, 1, 0, 0, 0, time.UTC)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "time,session,exchange,quote_currency,net_value\n"+
+		"2021-02-15T00:00:00Z,binance,binance,USDT,1000.5\n"+
+		"2021-02-15T01:00:00Z,binance,binance,USDT,1012.25\n", buf.String())
+}
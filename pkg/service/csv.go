@@ -0,0 +1,348 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var klineCSVHeader = []string{"start_time", "end_time", "symbol", "interval", "open", "high", "low", "close", "volume", "quote_volume"}
+
+var tradeCSVHeader = []string{"id", "order_id", "symbol", "side", "price", "quantity", "quote_quantity", "fee", "fee_currency", "is_buyer", "is_maker", "traded_at"}
+
+// the column aliases of the common dumps, e.g., the binance public data dumps use open_time and close_time
+var csvColumnAliases = map[string]string{
+	"open_time":  "start_time",
+	"close_time": "end_time",
+	"timestamp":  "start_time",
+	"time":       "start_time",
+	"trade_id":   "id",
+	"qty":        "quantity",
+	"quote_qty":  "quote_quantity",
+	"amount":     "quantity",
+	"commission": "fee",
+}
+
+// csvRow maps the columns of a row by the header names
+type csvRow struct {
+	columns map[string]int
+	record  []string
+	line    int
+}
+
+func (r csvRow) has(name string) bool {
+	_, ok := r.columns[name]
+	return ok
+}
+
+func (r csvRow) String(name string) string {
+	if i, ok := r.columns[name]; ok && i < len(r.record) {
+		return strings.TrimSpace(r.record[i])
+	}
+
+	return ""
+}
+
+func (r csvRow) Float64(name string) (float64, error) {
+	s := r.String(name)
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid %s %q", r.line, name, s)
+	}
+
+	return v, nil
+}
+
+func (r csvRow) Bool(name string) (bool, error) {
+	s := r.String(name)
+	if len(s) == 0 {
+		return false, nil
+	}
+
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("line %d: invalid %s %q", r.line, name, s)
+	}
+
+	return v, nil
+}
+
+func (r csvRow) Time(name string) (time.Time, error) {
+	s := r.String(name)
+	t, err := parseCSVTime(s)
+	if err != nil {
+		return t, fmt.Errorf("line %d: invalid %s %q", r.line, name, s)
+	}
+
+	return t, nil
+}
+
+// parseCSVTime parses the unix timestamp in seconds, milliseconds or microseconds, or the time in RFC3339 or "2006-01-02 15:04:05" (UTC)
+func parseCSVTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		switch {
+		case n < 1e11:
+			return time.Unix(n, 0), nil
+		case n < 1e14:
+			return time.Unix(0, n*int64(time.Millisecond)), nil
+		default:
+			return time.Unix(0, n*int64(time.Microsecond)), nil
+		}
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02 15:04:05", s, time.UTC)
+}
+
+// readCSV calls the callback with the rows of the CSV, the first row is the header, the column names are case-insensitive.
+func readCSV(r io.Reader, required []string, cb func(row csvRow) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return errors.Wrap(err, "can not read the csv header")
+	}
+
+	var columns = map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := csvColumnAliases[name]; ok {
+			name = alias
+		}
+
+		// the first column wins, e.g., "time" and "timestamp" are both mapped to start_time
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("the csv column %s is required, the header is %v", name, header)
+		}
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		line++
+		if err := cb(csvRow{columns: columns, record: record, line: line}); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadKLinesCSV reads the klines from the CSV with a header row. The start time and the OHLCV columns are required,
+// the symbol and the interval columns are optional, the fields of the defaults kline are used for the missing columns.
+// The end time is derived from the interval if it's not given.
+func ReadKLinesCSV(r io.Reader, defaults types.KLine, cb func(kline types.KLine) error) error {
+	return readCSV(r, []string{"start_time", "open", "high", "low", "close", "volume"}, func(row csvRow) (err error) {
+		kline := defaults
+		kline.Closed = true
+
+		if row.has("symbol") {
+			kline.Symbol = strings.ToUpper(row.String("symbol"))
+		}
+
+		if row.has("interval") {
+			kline.Interval = types.Interval(row.String("interval"))
+		}
+
+		if len(kline.Symbol) == 0 || len(kline.Interval) == 0 {
+			return fmt.Errorf("line %d: the symbol and the interval of the kline are required", row.line)
+		}
+
+		if kline.StartTime, err = row.Time("start_time"); err != nil {
+			return err
+		}
+
+		if row.has("end_time") {
+			if kline.EndTime, err = row.Time("end_time"); err != nil {
+				return err
+			}
+		} else {
+			kline.EndTime = kline.StartTime.Add(kline.Interval.Duration() - time.Millisecond)
+		}
+
+		for name, field := range map[string]*float64{
+			"open":         &kline.Open,
+			"high":         &kline.High,
+			"low":          &kline.Low,
+			"close":        &kline.Close,
+			"volume":       &kline.Volume,
+			"quote_volume": &kline.QuoteVolume,
+		} {
+			if *field, err = row.Float64(name); err != nil {
+				return err
+			}
+		}
+
+		return cb(kline)
+	})
+}
+
+// WriteKLinesCSV writes the klines in the CSV format that can be read by ReadKLinesCSV
+func WriteKLinesCSV(w io.Writer, klines []types.KLine) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(klineCSVHeader); err != nil {
+		return err
+	}
+
+	for _, k := range klines {
+		if err := writer.Write([]string{
+			k.StartTime.UTC().Format(time.RFC3339Nano),
+			k.EndTime.UTC().Format(time.RFC3339Nano),
+			k.Symbol,
+			string(k.Interval),
+			formatCSVFloat(k.Open),
+			formatCSVFloat(k.High),
+			formatCSVFloat(k.Low),
+			formatCSVFloat(k.Close),
+			formatCSVFloat(k.Volume),
+			formatCSVFloat(k.QuoteVolume),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadTradesCSV reads the trades from the CSV with a header row. The trade ID is required to de-duplicate the imported trades,
+// the side is derived from is_buyer if it's not given. The fields of the defaults trade are used for the missing columns.
+func ReadTradesCSV(r io.Reader, defaults types.Trade, cb func(trade types.Trade) error) error {
+	return readCSV(r, []string{"id", "price", "quantity"}, func(row csvRow) (err error) {
+		trade := defaults
+
+		if trade.ID, err = strconv.ParseInt(row.String("id"), 10, 64); err != nil {
+			return fmt.Errorf("line %d: invalid id %q", row.line, row.String("id"))
+		}
+
+		if s := row.String("order_id"); len(s) > 0 {
+			if trade.OrderID, err = strconv.ParseUint(s, 10, 64); err != nil {
+				return fmt.Errorf("line %d: invalid order_id %q", row.line, s)
+			}
+		}
+
+		if row.has("symbol") {
+			trade.Symbol = strings.ToUpper(row.String("symbol"))
+		}
+
+		if len(trade.Symbol) == 0 {
+			return fmt.Errorf("line %d: the symbol of the trade is required", row.line)
+		}
+
+		for name, field := range map[string]*float64{
+			"price":          &trade.Price,
+			"quantity":       &trade.Quantity,
+			"quote_quantity": &trade.QuoteQuantity,
+			"fee":            &trade.Fee,
+		} {
+			if *field, err = row.Float64(name); err != nil {
+				return err
+			}
+		}
+
+		if trade.QuoteQuantity == 0 {
+			trade.QuoteQuantity = trade.Price * trade.Quantity
+		}
+
+		if row.has("fee_currency") {
+			trade.FeeCurrency = row.String("fee_currency")
+		}
+
+		if trade.IsBuyer, err = row.Bool("is_buyer"); err != nil {
+			return err
+		}
+
+		if trade.IsMaker, err = row.Bool("is_maker"); err != nil {
+			return err
+		}
+
+		switch side := strings.ToUpper(row.String("side")); side {
+		case "BUY", "SELL":
+			trade.Side = types.SideType(side)
+			trade.IsBuyer = side == "BUY"
+		case "":
+			trade.Side = types.SideTypeSell
+			if trade.IsBuyer {
+				trade.Side = types.SideTypeBuy
+			}
+		default:
+			return fmt.Errorf("line %d: invalid side %q", row.line, side)
+		}
+
+		// the "time" and "timestamp" columns are mapped to start_time by the aliases
+		timeColumn := "traded_at"
+		if !row.has(timeColumn) {
+			timeColumn = "start_time"
+		}
+
+		if !row.has(timeColumn) {
+			return fmt.Errorf("line %d: the traded_at column of the trade is required", row.line)
+		}
+
+		if trade.Time, err = row.Time(timeColumn); err != nil {
+			return err
+		}
+
+		return cb(trade)
+	})
+}
+
+// WriteTradesCSV writes the trades in the CSV format that can be read by ReadTradesCSV
+func WriteTradesCSV(w io.Writer, trades []types.Trade) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(tradeCSVHeader); err != nil {
+		return err
+	}
+
+	for _, trade := range trades {
+		if err := writer.Write([]string{
+			strconv.FormatInt(trade.ID, 10),
+			strconv.FormatUint(trade.OrderID, 10),
+			trade.Symbol,
+			string(trade.Side),
+			formatCSVFloat(trade.Price),
+			formatCSVFloat(trade.Quantity),
+			formatCSVFloat(trade.QuoteQuantity),
+			formatCSVFloat(trade.Fee),
+			trade.FeeCurrency,
+			strconv.FormatBool(trade.IsBuyer),
+			strconv.FormatBool(trade.IsMaker),
+			trade.Time.UTC().Format(time.RFC3339Nano),
+		}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCSVFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestReadKLinesCSV(t *testing.T) {
+	data := `open_time,open,high,low,close,volume
+1609459200000,29000.5,29100,28950,29050,12.5
+2021-01-01T00:01:00Z,29050,29060,29000,29010,3
+`
+
+	var klines []types.KLine
+	err := ReadKLinesCSV(strings.NewReader(data), types.KLine{Exchange: "binance", Symbol: "BTCUSDT", Interval: types.Interval1m}, func(kline types.KLine) error {
+		klines = append(klines, kline)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, klines, 2) {
+		assert.Equal(t, "binance", klines[0].Exchange)
+		assert.Equal(t, "BTCUSDT", klines[0].Symbol)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), klines[0].StartTime.UTC())
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 59, 999000000, time.UTC), klines[0].EndTime.UTC())
+		assert.Equal(t, 29000.5, klines[0].Open)
+		assert.Equal(t, 12.5, klines[0].Volume)
+		assert.True(t, klines[0].Closed)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 1, 0, 0, time.UTC), klines[1].StartTime.UTC())
+	}
+
+	err = ReadKLinesCSV(strings.NewReader("open_time,open,high,low,close\n"), types.KLine{Symbol: "BTCUSDT", Interval: types.Interval1m}, nil)
+	assert.Error(t, err, "the volume column is required")
+}
+
+func TestKLinesCSV_RoundTrip(t *testing.T) {
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	klines := []types.KLine{
+		{
+			Symbol:      "ETHUSDT",
+			Interval:    types.Interval1h,
+			StartTime:   startTime,
+			EndTime:     startTime.Add(time.Hour - time.Millisecond),
+			Open:        730.1,
+			High:        740,
+			Low:         725.25,
+			Close:       735,
+			Volume:      1000,
+			QuoteVolume: 735000,
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteKLinesCSV(&buf, klines))
+
+	var read []types.KLine
+	err := ReadKLinesCSV(&buf, types.KLine{Exchange: "binance"}, func(kline types.KLine) error {
+		read = append(read, kline)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, read, 1) {
+		assert.Equal(t, "binance", read[0].Exchange)
+		assert.Equal(t, types.Interval1h, read[0].Interval)
+		assert.Equal(t, klines[0].EndTime, read[0].EndTime.UTC())
+		assert.Equal(t, klines[0].Low, read[0].Low)
+		assert.Equal(t, klines[0].QuoteVolume, read[0].QuoteVolume)
+	}
+}
+
+func TestReadTradesCSV(t *testing.T) {
+	data := `trade_id,price,qty,is_buyer,time
+100,29000,0.5,true,1609459200
+101,29010,0.25,false,2021-01-01 00:00:01
+`
+
+	var trades []types.Trade
+	err := ReadTradesCSV(strings.NewReader(data), types.Trade{Exchange: "binance", Symbol: "BTCUSDT"}, func(trade types.Trade) error {
+		trades = append(trades, trade)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, int64(100), trades[0].ID)
+		assert.Equal(t, types.SideTypeBuy, trades[0].Side)
+		assert.Equal(t, 14500.0, trades[0].QuoteQuantity)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), trades[0].Time.UTC())
+		assert.Equal(t, types.SideTypeSell, trades[1].Side)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 1, 0, time.UTC), trades[1].Time.UTC())
+	}
+
+	err = ReadTradesCSV(strings.NewReader("trade_id,price,qty,time\n102,29000,0.5,1609459200\n"), types.Trade{}, func(trade types.Trade) error {
+		return nil
+	})
+	assert.Error(t, err, "the symbol is required")
+}
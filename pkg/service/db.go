@@ -0,0 +1,17 @@
+package service
+
+import "github.com/jmoiron/sqlx"
+
+const (
+	// DriverMySQL is the database driver name of MySQL
+	DriverMySQL = "mysql"
+
+	// DriverSQLite3 is the database driver name of SQLite, the database is stored in a single file
+	DriverSQLite3 = "sqlite3"
+)
+
+// isSQLite3 returns true if the database is connected with the SQLite driver,
+// SQLite uses the ON CONFLICT clause and the strftime function instead of the MySQL ones.
+func isSQLite3(db *sqlx.DB) bool {
+	return db.DriverName() == DriverSQLite3
+}
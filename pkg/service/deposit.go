@@ -0,0 +1,75 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type DepositService struct {
+	DB *sqlx.DB
+}
+
+// QueryLast queries the last deposit of the exchange from the database
+func (s *DepositService) QueryLast(ex types.ExchangeName) (*types.Deposit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM deposits WHERE exchange = :exchange ORDER BY time DESC LIMIT 1`, map[string]interface{}{
+		"exchange": ex,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query last deposit error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var deposit types.Deposit
+		err = rows.StructScan(&deposit)
+		return &deposit, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryByTimeRange queries the deposits of the exchange in [since, until) in the ascending order of the time
+func (s *DepositService) QueryByTimeRange(ex types.ExchangeName, since, until time.Time) ([]types.Deposit, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM deposits WHERE exchange = :exchange AND time >= :since AND time < :until ORDER BY time ASC, gid ASC`, map[string]interface{}{
+		"exchange": ex,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query deposits error")
+	}
+
+	defer rows.Close()
+
+	var deposits []types.Deposit
+	for rows.Next() {
+		var deposit types.Deposit
+		if err := rows.StructScan(&deposit); err != nil {
+			return deposits, err
+		}
+
+		deposits = append(deposits, deposit)
+	}
+
+	return deposits, rows.Err()
+}
+
+// Insert inserts the deposit, the status of the stored deposit is updated if the deposit is synchronized again
+func (s *DepositService) Insert(deposit types.Deposit) error {
+	upsert := `ON DUPLICATE KEY UPDATE status=:status`
+	if isSQLite3(s.DB) {
+		upsert = `ON CONFLICT (exchange, asset, txn_id, time) DO UPDATE SET status=excluded.status`
+	}
+
+	_, err := s.DB.NamedExec(`
+			INSERT INTO deposits (exchange, asset, address, address_tag, amount, txn_id, status, time)
+			VALUES (:exchange, :asset, :address, :address_tag, :amount, :txn_id, :status, :time)
+			`+upsert,
+		deposit)
+	return err
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// OrderExecution is the grouped execution view of an order and its trades (fills)
+type OrderExecution struct {
+	Order  types.Order   `json:"order"`
+	Trades []types.Trade `json:"trades"`
+
+	FilledQuantity float64 `json:"filledQuantity"`
+	QuoteQuantity  float64 `json:"quoteQuantity"`
+
+	// AveragePrice is the exact quantity weighted average price of the fills
+	AveragePrice float64 `json:"averagePrice"`
+
+	// Fees is the total fee amount grouped by the fee currency
+	Fees map[string]float64 `json:"fees"`
+}
+
+// NewOrderExecution aggregates the trades of the order,
+// the average price is computed from the trades instead of the average price string returned by the exchange.
+func NewOrderExecution(order types.Order, trades []types.Trade) *OrderExecution {
+	execution := &OrderExecution{
+		Order:  order,
+		Trades: trades,
+		Fees:   make(map[string]float64),
+	}
+
+	var notional float64
+	for _, trade := range trades {
+		execution.FilledQuantity += trade.Quantity
+		execution.QuoteQuantity += trade.QuoteQuantity
+		notional += trade.Price * trade.Quantity
+
+		if trade.Fee > 0 {
+			execution.Fees[trade.FeeCurrency] += trade.Fee
+		}
+	}
+
+	if execution.FilledQuantity > 0 {
+		execution.AveragePrice = notional / execution.FilledQuantity
+	}
+
+	return execution
+}
+
+// GroupTradesByOrder groups the trades by the exchange and the order ID,
+// the order of the trades in each group is kept.
+func GroupTradesByOrder(trades []types.Trade) map[string]map[uint64][]types.Trade {
+	groups := make(map[string]map[uint64][]types.Trade)
+	for _, trade := range trades {
+		orders, ok := groups[trade.Exchange]
+		if !ok {
+			orders = make(map[uint64][]types.Trade)
+			groups[trade.Exchange] = orders
+		}
+
+		orders[trade.OrderID] = append(orders[trade.OrderID], trade)
+	}
+
+	return groups
+}
+
+// QueryOrderExecution queries the order and all its trades, and returns the grouped execution view
+func QueryOrderExecution(orderService *OrderService, tradeService *TradeService, ex types.ExchangeName, orderID uint64) (*OrderExecution, error) {
+	order, err := orderService.QueryByOrderID(ex, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	trades, err := tradeService.QueryByOrderID(ex, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		// the order is not synced yet, use the trades to fill the order info
+		order = &types.Order{OrderID: orderID, Exchange: string(ex)}
+		if len(trades) > 0 {
+			order.Symbol = trades[0].Symbol
+			order.Side = trades[0].Side
+		}
+	}
+
+	return NewOrderExecution(*order, trades), nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestNewOrderExecution(t *testing.T) {
+	order := types.Order{Exchange: "binance", OrderID: 1}
+	trades := []types.Trade{
+		{Exchange: "binance", OrderID: 1, Price: 100.0, Quantity: 1.0, QuoteQuantity: 100.0, Fee: 0.001, FeeCurrency: "BTC"},
+		{Exchange: "binance", OrderID: 1, Price: 103.0, Quantity: 2.0, QuoteQuantity: 206.0, Fee: 0.1, FeeCurrency: "BNB"},
+		{Exchange: "binance", OrderID: 1, Price: 104.0, Quantity: 1.0, QuoteQuantity: 104.0, Fee: 0.001, FeeCurrency: "BTC"},
+	}
+
+	execution := NewOrderExecution(order, trades)
+	assert.Equal(t, 4.0, execution.FilledQuantity)
+	assert.Equal(t, 410.0, execution.QuoteQuantity)
+	assert.InDelta(t, 102.5, execution.AveragePrice, 1e-9)
+	assert.InDelta(t, 0.002, execution.Fees["BTC"], 1e-9)
+	assert.InDelta(t, 0.1, execution.Fees["BNB"], 1e-9)
+
+	empty := NewOrderExecution(order, nil)
+	assert.Equal(t, 0.0, empty.AveragePrice)
+}
+
+func TestGroupTradesByOrder(t *testing.T) {
+	trades := []types.Trade{
+		{Exchange: "binance", OrderID: 1, ID: 1},
+		{Exchange: "max", OrderID: 1, ID: 2},
+		{Exchange: "binance", OrderID: 1, ID: 3},
+		{Exchange: "binance", OrderID: 2, ID: 4},
+	}
+
+	groups := GroupTradesByOrder(trades)
+	assert.Len(t, groups["binance"][1], 2)
+	assert.Equal(t, int64(3), groups["binance"][1][1].ID)
+	assert.Len(t, groups["binance"][2], 1)
+	assert.Len(t, groups["max"][1], 1)
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxDBService writes points into the InfluxDB v2 write API with the line protocol format.
+type InfluxDBService struct {
+	URL    string
+	Org    string
+	Bucket string
+	Token  string
+
+	client *http.Client
+}
+
+func NewInfluxDBService(url, org, bucket, token string) *InfluxDBService {
+	return &InfluxDBService{
+		URL:    url,
+		Org:    org,
+		Bucket: bucket,
+		Token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Point is a single data point of the line protocol
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Time        time.Time
+}
+
+var measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+var tagEscaper = strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+var stringFieldEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`)
+
+// LineProtocol encodes the point in the influxdb line protocol, for example:
+//
+//	kline,symbol=BTCUSDT,interval=1m close=19000.5,volume=10.2 1611111111000000000
+func (p Point) LineProtocol() string {
+	var sb strings.Builder
+	sb.WriteString(measurementEscaper.Replace(p.Measurement))
+
+	// sort the keys so that the output is stable
+	var tagKeys []string
+	for k := range p.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	for _, k := range tagKeys {
+		v := p.Tags[k]
+		if len(v) == 0 {
+			continue
+		}
+
+		sb.WriteString(",")
+		sb.WriteString(tagEscaper.Replace(k))
+		sb.WriteString("=")
+		sb.WriteString(tagEscaper.Replace(v))
+	}
+
+	var fieldKeys []string
+	for k := range p.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+
+	var fields []string
+	for _, k := range fieldKeys {
+		var val string
+		switch v := p.Fields[k].(type) {
+		case float64:
+			val = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			val = strconv.Itoa(v) + "i"
+		case int64:
+			val = strconv.FormatInt(v, 10) + "i"
+		case bool:
+			val = strconv.FormatBool(v)
+		case string:
+			val = `"` + stringFieldEscaper.Replace(v) + `"`
+		default:
+			val = `"` + stringFieldEscaper.Replace(fmt.Sprintf("%v", v)) + `"`
+		}
+
+		fields = append(fields, tagEscaper.Replace(k)+"="+val)
+	}
+
+	sb.WriteString(" ")
+	sb.WriteString(strings.Join(fields, ","))
+
+	if !p.Time.IsZero() {
+		sb.WriteString(" ")
+		sb.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+	}
+
+	return sb.String()
+}
+
+// Write sends the given points to the InfluxDB write API in a single request
+func (s *InfluxDBService) Write(ctx context.Context, points ...Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, p := range points {
+		buf.WriteString(p.LineProtocol())
+		buf.WriteString("\n")
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return err
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v2/write"
+
+	params := url.Values{}
+	params.Set("org", s.Org)
+	params.Set("bucket", s.Bucket)
+	params.Set("precision", "ns")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), &buf)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if len(s.Token) > 0 {
+		req.Header.Set("Authorization", "Token "+s.Token)
+	}
+
+	client := s.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("influxdb write error: %d %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
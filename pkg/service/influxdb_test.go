@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoint_LineProtocol(t *testing.T) {
+	t.Run("tags and fields are sorted", func(t *testing.T) {
+		p := Point{
+			Measurement: "kline",
+			Tags:        map[string]string{"symbol": "BTCUSDT", "interval": "1m"},
+			Fields:      map[string]interface{}{"volume": 10.5, "close": 19000.0},
+			Time:        time.Unix(0, 1611111111000000000),
+		}
+		assert.Equal(t, "kline,interval=1m,symbol=BTCUSDT close=19000,volume=10.5 1611111111000000000", p.LineProtocol())
+	})
+
+	t.Run("escape and field types", func(t *testing.T) {
+		p := Point{
+			Measurement: "my position",
+			Tags:        map[string]string{"session": "max,1", "empty": ""},
+			Fields:      map[string]interface{}{"count": 3, "label": `a "b"`, "ok": true},
+		}
+		assert.Equal(t, `my\ position,session=max\,1 count=3i,label="a \"b\"",ok=true`, p.LineProtocol())
+	})
+}
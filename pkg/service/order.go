@@ -3,6 +3,7 @@ package service
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
@@ -45,9 +46,75 @@ func (s *OrderService) QueryLast(ex types.ExchangeName, symbol string, isMargin
 	return nil, rows.Err()
 }
 
+// QueryByOrderID queries the order by the exchange order ID
+func (s *OrderService) QueryByOrderID(ex types.ExchangeName, orderID uint64) (*types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND order_id = :order_id LIMIT 1`, map[string]interface{}{
+		"exchange": ex,
+		"order_id": orderID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query order error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var order types.Order
+		err = rows.StructScan(&order)
+		return &order, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryFirstWorking queries the earliest order that was still working when it was stored,
+// the orders after it should be synchronized again to update their status.
+func (s *OrderService) QueryFirstWorking(ex types.ExchangeName, symbol string, isMargin bool, isIsolated bool) (*types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND is_margin = :is_margin AND is_isolated = :is_isolated AND is_working = TRUE ORDER BY created_at ASC LIMIT 1`, map[string]interface{}{
+		"exchange":    ex,
+		"symbol":      symbol,
+		"is_margin":   isMargin,
+		"is_isolated": isIsolated,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query first working order error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var order types.Order
+		err = rows.StructScan(&order)
+		return &order, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryByTimeRange queries the orders of the symbol created in [since, until) in the ascending order of the creation time
+func (s *OrderService) QueryByTimeRange(ex types.ExchangeName, symbol string, since, until time.Time) ([]types.Order, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM orders WHERE exchange = :exchange AND symbol = :symbol AND created_at >= :since AND created_at < :until ORDER BY created_at ASC`, map[string]interface{}{
+		"exchange": ex,
+		"symbol":   symbol,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query orders error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
 type AggOrder struct {
 	types.Order
 	AveragePrice *float64 `json:"averagePrice" db:"average_price"`
+
+	// NumTrades and FilledQuantity are aggregated from the trades linked to the order
+	NumTrades      int     `json:"numTrades" db:"num_trades"`
+	FilledQuantity float64 `json:"filledQuantity" db:"filled_quantity"`
 }
 
 type QueryOrdersOptions struct {
@@ -100,8 +167,9 @@ func genOrderSQL(options QueryOrdersOptions) string {
 		where = append(where, "symbol = :symbol")
 	}
 
-	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price FROM orders` +
-		` LEFT JOIN trades AS t ON (t.order_id = orders.order_id)`
+	sql := `SELECT orders.*, IFNULL(SUM(t.price * t.quantity)/SUM(t.quantity), orders.price) AS average_price,` +
+		` COUNT(t.gid) AS num_trades, IFNULL(SUM(t.quantity), 0) AS filled_quantity FROM orders` +
+		` LEFT JOIN trades AS t ON (t.order_id = orders.order_id AND t.exchange = orders.exchange)`
 	if len(where) > 0 {
 		sql += ` WHERE ` + strings.Join(where, " AND ")
 	}
@@ -140,9 +208,14 @@ func (s *OrderService) scanRows(rows *sqlx.Rows) (orders []types.Order, err erro
 }
 
 func (s *OrderService) Insert(order types.Order) error {
+	upsert := `ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at, tags=IF(:tags = '', tags, :tags)`
+	if isSQLite3(s.DB) {
+		upsert = `ON CONFLICT (order_id, exchange) DO UPDATE SET status=excluded.status, executed_quantity=excluded.executed_quantity, is_working=excluded.is_working, updated_at=excluded.updated_at, tags=CASE WHEN excluded.tags = '' THEN orders.tags ELSE excluded.tags END`
+	}
+
 	_, err := s.DB.NamedExec(`
-			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_isolated)
-			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_isolated)
-			ON DUPLICATE KEY UPDATE status=:status, executed_quantity=:executed_quantity, is_working=:is_working, updated_at=:updated_at`, order)
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_isolated, tags)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_isolated, :tags)
+			`+upsert, order)
 	return err
 }
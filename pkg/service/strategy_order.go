@@ -0,0 +1,104 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StrategyOrder links the exchange order to the strategy that submitted it,
+// LocalID is the serial ID of the order in the session, it increases monotonically.
+type StrategyOrder struct {
+	GID           int64              `json:"gid" db:"gid"`
+	Session       string             `json:"session" db:"session"`
+	Exchange      types.ExchangeName `json:"exchange" db:"exchange"`
+	LocalID       uint64             `json:"localID" db:"local_id"`
+	Strategy      string             `json:"strategy" db:"strategy"`
+	OrderID       uint64             `json:"orderID" db:"order_id"`
+	ClientOrderID string             `json:"clientOrderID" db:"client_order_id"`
+	Symbol        string             `json:"symbol" db:"symbol"`
+	Side          types.SideType     `json:"side" db:"side"`
+	Type          types.OrderType    `json:"orderType" db:"order_type"`
+	Price         float64            `json:"price" db:"price"`
+	Quantity      float64            `json:"quantity" db:"quantity"`
+	CreatedAt     time.Time          `json:"createdAt" db:"created_at"`
+
+	// Status is the last status of the order in the orders table, it's empty if the order is not stored or synced
+	Status types.OrderStatus `json:"status" db:"status"`
+}
+
+type QueryStrategyOrdersOptions struct {
+	Strategy string
+	Session  string
+	Since    time.Time
+}
+
+type StrategyOrderService struct {
+	DB *sqlx.DB
+}
+
+func (s *StrategyOrderService) Insert(order StrategyOrder) error {
+	_, err := s.DB.NamedExec(`
+			INSERT INTO strategy_orders (session, exchange, local_id, strategy, order_id, client_order_id, symbol, side, order_type, price, quantity, created_at)
+			VALUES (:session, :exchange, :local_id, :strategy, :order_id, :client_order_id, :symbol, :side, :order_type, :price, :quantity, :created_at)`,
+		order)
+	return err
+}
+
+// LastLocalID returns the last local ID of the session, it's zero if there is no order of the session
+func (s *StrategyOrderService) LastLocalID(session string) (uint64, error) {
+	var lastID sql.NullInt64
+	if err := s.DB.Get(&lastID, `SELECT MAX(local_id) FROM strategy_orders WHERE session = ?`, session); err != nil {
+		return 0, errors.Wrap(err, "query last local order id error")
+	}
+
+	return uint64(lastID.Int64), nil
+}
+
+// Query queries the strategy orders in the ascending order of the local ID, the last status of the orders is joined from the orders table
+func (s *StrategyOrderService) Query(options QueryStrategyOrdersOptions) ([]StrategyOrder, error) {
+	query := genStrategyOrderSQL(options)
+	rows, err := s.DB.NamedQuery(query, map[string]interface{}{
+		"strategy": options.Strategy,
+		"session":  options.Session,
+		"since":    options.Since,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query strategy orders error")
+	}
+
+	defer rows.Close()
+
+	var orders []StrategyOrder
+	for rows.Next() {
+		var order StrategyOrder
+		if err := rows.StructScan(&order); err != nil {
+			return orders, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+func genStrategyOrderSQL(options QueryStrategyOrdersOptions) string {
+	var conditions = []string{"so.created_at >= :since"}
+	if len(options.Strategy) > 0 {
+		conditions = append(conditions, "so.strategy = :strategy")
+	}
+
+	if len(options.Session) > 0 {
+		conditions = append(conditions, "so.session = :session")
+	}
+
+	return "SELECT so.*, COALESCE(o.status, '') AS status FROM strategy_orders AS so" +
+		" LEFT JOIN orders AS o ON (o.exchange = so.exchange AND o.order_id = so.order_id)" +
+		" WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY so.session ASC, so.local_id ASC"
+}
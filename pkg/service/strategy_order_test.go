@@ -0,0 +1,19 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_genStrategyOrderSQL(t *testing.T) {
+	assert.Equal(t, "SELECT so.*, COALESCE(o.status, '') AS status FROM strategy_orders AS so"+
+		" LEFT JOIN orders AS o ON (o.exchange = so.exchange AND o.order_id = so.order_id)"+
+		" WHERE so.created_at >= :since ORDER BY so.session ASC, so.local_id ASC",
+		genStrategyOrderSQL(QueryStrategyOrdersOptions{}))
+
+	assert.Equal(t, "SELECT so.*, COALESCE(o.status, '') AS status FROM strategy_orders AS so"+
+		" LEFT JOIN orders AS o ON (o.exchange = so.exchange AND o.order_id = so.order_id)"+
+		" WHERE so.created_at >= :since AND so.strategy = :strategy AND so.session = :session ORDER BY so.session ASC, so.local_id ASC",
+		genStrategyOrderSQL(QueryStrategyOrdersOptions{Strategy: "grid", Session: "binance"}))
+}
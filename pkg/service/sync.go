@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -10,8 +11,13 @@ import (
 )
 
 type SyncService struct {
-	TradeService *TradeService
-	OrderService *OrderService
+	TradeService    *TradeService
+	OrderService    *OrderService
+	DepositService  *DepositService
+	WithdrawService *WithdrawService
+
+	// AuditService records the new withdrawals into the audit log if it's set
+	AuditService *AuditService
 }
 
 func (s *SyncService) SyncOrders(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
@@ -39,6 +45,19 @@ func (s *SyncService) SyncOrders(ctx context.Context, exchange types.Exchange, s
 		logrus.Infof("found last order, start from lastID = %d since %s", lastID, startTime)
 	}
 
+	// the orders working at the last sync are synchronized again, the stored orders are updated by the order id
+	firstWorkingOrder, err := s.OrderService.QueryFirstWorking(exchange.Name(), symbol, isMargin, isIsolated)
+	if err != nil {
+		return err
+	}
+
+	if firstWorkingOrder != nil && firstWorkingOrder.CreationTime.Before(startTime) {
+		lastID = 0
+		startTime = firstWorkingOrder.CreationTime
+
+		logrus.Infof("found working order %d, start from %s", firstWorkingOrder.OrderID, startTime)
+	}
+
 	batch := &types.ExchangeBatchProcessor{Exchange: exchange}
 	ordersC, errC := batch.BatchQueryClosedOrders(ctx, symbol, startTime, time.Now(), lastID)
 	for order := range ordersC {
@@ -64,6 +83,15 @@ func (s *SyncService) SyncOrders(ctx context.Context, exchange types.Exchange, s
 	return <-errC
 }
 
+// Sync synchronizes the trades and the orders of the symbol since the start time, or since the last stored records
+func (s *SyncService) Sync(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
+	if err := s.SyncTrades(ctx, exchange, symbol, startTime); err != nil {
+		return err
+	}
+
+	return s.SyncOrders(ctx, exchange, symbol, startTime)
+}
+
 func (s *SyncService) SyncTrades(ctx context.Context, exchange types.Exchange, symbol string, startTime time.Time) error {
 	isMargin := false
 	isIsolated := false
@@ -117,3 +145,90 @@ func (s *SyncService) SyncTrades(ctx context.Context, exchange types.Exchange, s
 
 	return <-errC
 }
+
+// SyncTransfers synchronizes the deposits and the withdraws of all the assets since the start time,
+// or since the last stored records. The records synchronized again update the stored status.
+func (s *SyncService) SyncTransfers(ctx context.Context, exchange types.Exchange, startTime time.Time) error {
+	if err := s.SyncDeposits(ctx, exchange, startTime); err != nil {
+		return err
+	}
+
+	return s.SyncWithdraws(ctx, exchange, startTime)
+}
+
+func (s *SyncService) SyncDeposits(ctx context.Context, exchange types.Exchange, startTime time.Time) error {
+	lastDeposit, err := s.DepositService.QueryLast(exchange.Name())
+	if err != nil {
+		return err
+	}
+
+	if lastDeposit != nil {
+		startTime = lastDeposit.Time
+
+		logrus.Infof("found last deposit, start from %s", startTime)
+	}
+
+	deposits, err := exchange.QueryDepositHistory(ctx, "", startTime, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, deposit := range deposits {
+		deposit.Exchange = exchange.Name()
+		if err := s.DepositService.Insert(deposit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SyncService) SyncWithdraws(ctx context.Context, exchange types.Exchange, startTime time.Time) error {
+	lastWithdraw, err := s.WithdrawService.QueryLast(exchange.Name())
+	if err != nil {
+		return err
+	}
+
+	if lastWithdraw != nil {
+		startTime = lastWithdraw.ApplyTime
+
+		logrus.Infof("found last withdraw, start from %s", startTime)
+	}
+
+	withdraws, err := exchange.QueryWithdrawHistory(ctx, "", startTime, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, withdraw := range withdraws {
+		withdraw.Exchange = exchange.Name()
+		if err := s.WithdrawService.Insert(withdraw); err != nil {
+			return err
+		}
+
+		// the last withdraw is queried again since the query starts from its time
+		if s.AuditService != nil && (lastWithdraw == nil || withdraw.ApplyTime.After(lastWithdraw.ApplyTime)) {
+			s.auditWithdraw(withdraw)
+		}
+	}
+
+	return nil
+}
+
+func (s *SyncService) auditWithdraw(withdraw types.Withdraw) {
+	params, err := json.Marshal(withdraw)
+	if err != nil {
+		logrus.WithError(err).Errorf("can not encode the withdraw: %+v", withdraw)
+		return
+	}
+
+	if err := s.AuditService.Insert(AuditLog{
+		Exchange: withdraw.Exchange,
+		Action:   AuditActionWithdraw,
+		Params:   string(params),
+		Result:   withdraw.Status,
+		Time:     withdraw.ApplyTime,
+	}); err != nil {
+		logrus.WithError(err).Errorf("can not insert the audit log of the withdraw: %+v", withdraw)
+	}
+}
@@ -44,7 +44,12 @@ func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVo
 		"start_time": startTime,
 	}
 
-	sql := queryTradingVolumeSQL(options)
+	driver := DriverMySQL
+	if isSQLite3(s.DB) {
+		driver = DriverSQLite3
+	}
+
+	sql := queryTradingVolumeSQL(driver, options)
 
 	rows, err := s.DB.NamedQuery(sql, args)
 	if err != nil {
@@ -72,29 +77,41 @@ func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVo
 	return records, rows.Err()
 }
 
-func queryTradingVolumeSQL(options TradingVolumeQueryOptions) string {
+// tradedAtPart returns the SQL expression of the year, month or day of traded_at for the database driver
+func tradedAtPart(driver, part string) string {
+	if driver == DriverSQLite3 {
+		format := map[string]string{"year": "%Y", "month": "%m", "day": "%d"}[part]
+		return "CAST(strftime('" + format + "', traded_at) AS INTEGER)"
+	}
+
+	return strings.ToUpper(part) + "(traded_at)"
+}
+
+func queryTradingVolumeSQL(driver string, options TradingVolumeQueryOptions) string {
 	var sel []string
 	var groupBys []string
 	var orderBys []string
 	where := []string{"traded_at > :start_time"}
+
+	year, month, day := tradedAtPart(driver, "year"), tradedAtPart(driver, "month"), tradedAtPart(driver, "day")
 	switch options.GroupByPeriod {
 
 	case "month":
-		sel = append(sel, "YEAR(traded_at) AS year", "MONTH(traded_at) AS month")
-		groupBys = append([]string{"MONTH(traded_at)", "YEAR(traded_at)"}, groupBys...)
+		sel = append(sel, year+" AS year", month+" AS month")
+		groupBys = append([]string{month, year}, groupBys...)
 		orderBys = append(orderBys, "year ASC", "month ASC")
 
 	case "year":
-		sel = append(sel, "YEAR(traded_at) AS year")
-		groupBys = append([]string{"YEAR(traded_at)"}, groupBys...)
+		sel = append(sel, year+" AS year")
+		groupBys = append([]string{year}, groupBys...)
 		orderBys = append(orderBys, "year ASC")
 
 	case "day":
 		fallthrough
 
 	default:
-		sel = append(sel, "YEAR(traded_at) AS year", "MONTH(traded_at) AS month", "DAY(traded_at) AS day")
-		groupBys = append([]string{"DAY(traded_at)", "MONTH(traded_at)", "YEAR(traded_at)"}, groupBys...)
+		sel = append(sel, year+" AS year", month+" AS month", day+" AS day")
+		groupBys = append([]string{day, month, year}, groupBys...)
 		orderBys = append(orderBys, "year ASC", "month ASC", "day ASC")
 	}
 
@@ -163,6 +180,25 @@ func (s *TradeService) QueryForTradingFeeCurrency(ex types.ExchangeName, symbol
 	return s.scanRows(rows)
 }
 
+// QueryForTradingFeeCurrencyByTimeRange queries the trades of the symbol and the trades paying the fee in the fee currency,
+// traded in [since, until) in the ascending order of the trade time
+func (s *TradeService) QueryForTradingFeeCurrencyByTimeRange(ex types.ExchangeName, symbol string, feeCurrency string, since, until time.Time) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND (symbol = :symbol OR fee_currency = :fee_currency) AND traded_at >= :since AND traded_at < :until ORDER BY traded_at ASC, gid ASC`, map[string]interface{}{
+		"exchange":     ex,
+		"symbol":       symbol,
+		"fee_currency": feeCurrency,
+		"since":        since,
+		"until":        until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query trades error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
 // Only return 500 items.
 type QueryTradesOptions struct {
 	Exchange types.ExchangeName
@@ -229,6 +265,54 @@ func queryTradesSQL(options QueryTradesOptions) string {
 	return sql
 }
 
+// QueryByOrderID queries all the trades (fills) of the order
+func (s *TradeService) QueryByOrderID(ex types.ExchangeName, orderID uint64) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND order_id = :order_id ORDER BY gid ASC`, map[string]interface{}{
+		"exchange": ex,
+		"order_id": orderID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QueryByTimeRange queries the trades of the symbol traded in [since, until) in the ascending order of the trade time
+func (s *TradeService) QueryByTimeRange(ex types.ExchangeName, symbol string, since, until time.Time) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND symbol = :symbol AND traded_at >= :since AND traded_at < :until ORDER BY traded_at ASC, gid ASC`, map[string]interface{}{
+		"exchange": ex,
+		"symbol":   symbol,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query trades error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
+// QueryAllByTimeRange queries the trades of all the symbols traded in [since, until) in the ascending order of the trade time
+func (s *TradeService) QueryAllByTimeRange(ex types.ExchangeName, since, until time.Time) ([]types.Trade, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM trades WHERE exchange = :exchange AND traded_at >= :since AND traded_at < :until ORDER BY traded_at ASC, gid ASC`, map[string]interface{}{
+		"exchange": ex,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query trades error")
+	}
+
+	defer rows.Close()
+
+	return s.scanRows(rows)
+}
+
 func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err error) {
 	for rows.Next() {
 		var trade types.Trade
@@ -243,9 +327,13 @@ func (s *TradeService) scanRows(rows *sqlx.Rows) (trades []types.Trade, err erro
 }
 
 func (s *TradeService) Insert(trade types.Trade) error {
-	_, err := s.DB.NamedExec(`
-			INSERT IGNORE INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_isolated)
-			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_isolated)`,
+	ignore := "INSERT IGNORE"
+	if isSQLite3(s.DB) {
+		ignore = "INSERT OR IGNORE"
+	}
+
+	_, err := s.DB.NamedExec(ignore+` INTO trades (id, exchange, order_id, symbol, price, quantity, quote_quantity, side, is_buyer, is_maker, fee, fee_currency, traded_at, is_margin, is_isolated, tags)
+			VALUES (:id, :exchange, :order_id, :symbol, :price, :quantity, :quote_quantity, :side, :is_buyer, :is_maker, :fee, :fee_currency, :traded_at, :is_margin, :is_isolated, :tags)`,
 		trade)
 	return err
 }
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type WithdrawService struct {
+	DB *sqlx.DB
+}
+
+// QueryLast queries the last withdraw of the exchange from the database
+func (s *WithdrawService) QueryLast(ex types.ExchangeName) (*types.Withdraw, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM withdraws WHERE exchange = :exchange ORDER BY time DESC LIMIT 1`, map[string]interface{}{
+		"exchange": ex,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query last withdraw error")
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		var withdraw types.Withdraw
+		err = rows.StructScan(&withdraw)
+		return &withdraw, err
+	}
+
+	return nil, rows.Err()
+}
+
+// QueryByTimeRange queries the withdraws of the exchange in [since, until) in the ascending order of the time
+func (s *WithdrawService) QueryByTimeRange(ex types.ExchangeName, since, until time.Time) ([]types.Withdraw, error) {
+	rows, err := s.DB.NamedQuery(`SELECT * FROM withdraws WHERE exchange = :exchange AND time >= :since AND time < :until ORDER BY time ASC, gid ASC`, map[string]interface{}{
+		"exchange": ex,
+		"since":    since,
+		"until":    until,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query withdraws error")
+	}
+
+	defer rows.Close()
+
+	var withdraws []types.Withdraw
+	for rows.Next() {
+		var withdraw types.Withdraw
+		if err := rows.StructScan(&withdraw); err != nil {
+			return withdraws, err
+		}
+
+		withdraws = append(withdraws, withdraw)
+	}
+
+	return withdraws, rows.Err()
+}
+
+// Insert inserts the withdraw, the status of the stored withdraw is updated if the withdraw is synchronized again
+func (s *WithdrawService) Insert(withdraw types.Withdraw) error {
+	upsert := `ON DUPLICATE KEY UPDATE status=:status`
+	if isSQLite3(s.DB) {
+		upsert = `ON CONFLICT (exchange, asset, txn_id, time) DO UPDATE SET status=excluded.status`
+	}
+
+	_, err := s.DB.NamedExec(`
+			INSERT INTO withdraws (exchange, asset, address, address_tag, network, amount, txn_fee, txn_id, withdraw_order_id, status, time)
+			VALUES (:exchange, :asset, :address, :address_tag, :network, :amount, :txn_fee, :txn_id, :withdraw_order_id, :status, :time)
+			`+upsert,
+		withdraw)
+	return err
+}
@@ -0,0 +1,162 @@
+package bollgrid
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SpacingMethod is the volatility measure of the dynamic grid spacing
+type SpacingMethod string
+
+const (
+	// SpacingMethodATR uses the average true range of the interval klines
+	SpacingMethodATR = SpacingMethod("atr")
+
+	// SpacingMethodStdDev uses the standard deviation of the close price changes of the interval klines
+	SpacingMethodStdDev = SpacingMethod("stddev")
+)
+
+const defaultSpacingWindow = 14
+
+// DynamicSpacing recomputes GridPips from the recent volatility every time the grid is re-posted, so that the grid
+// levels are wider in the volatile regimes and tighter in the quiet regimes. The levels are placed around the current
+// price with the computed pips, up to the grid number of levels within the price range.
+type DynamicSpacing struct {
+	// Method is "atr" or "stddev", defaults to "atr"
+	Method SpacingMethod `json:"method"`
+
+	// Window is the number of the interval klines used for measuring the volatility, defaults to 14
+	Window int `json:"window"`
+
+	// Multiplier scales the volatility to the grid pips, defaults to 1.0
+	Multiplier fixedpoint.Value `json:"multiplier"`
+
+	// MinPips and MaxPips clamp the computed grid pips, zero means no clamp
+	MinPips fixedpoint.Value `json:"minPips"`
+	MaxPips fixedpoint.Value `json:"maxPips"`
+}
+
+func (d *DynamicSpacing) Validate() error {
+	switch d.Method {
+	case "", SpacingMethodATR, SpacingMethodStdDev:
+	default:
+		return fmt.Errorf("unsupported dynamic spacing method: %s", d.Method)
+	}
+
+	if d.Window < 0 {
+		return fmt.Errorf("dynamic spacing window should not be negative, got %d", d.Window)
+	}
+
+	if d.Multiplier < 0 {
+		return fmt.Errorf("dynamic spacing multiplier should not be negative, got %f", d.Multiplier.Float64())
+	}
+
+	if d.MinPips < 0 || d.MaxPips < 0 {
+		return fmt.Errorf("dynamic spacing pips clamps should not be negative")
+	}
+
+	if d.MinPips > 0 && d.MaxPips > 0 && d.MinPips > d.MaxPips {
+		return fmt.Errorf("dynamic spacing minPips %f should not be greater than maxPips %f", d.MinPips.Float64(), d.MaxPips.Float64())
+	}
+
+	return nil
+}
+
+func (d *DynamicSpacing) window() int {
+	if d.Window == 0 {
+		return defaultSpacingWindow
+	}
+	return d.Window
+}
+
+// clamp scales the volatility to the grid pips and clamps the pips by MinPips and MaxPips
+func (d *DynamicSpacing) clamp(volatility float64) float64 {
+	multiplier := 1.0
+	if d.Multiplier > 0 {
+		multiplier = d.Multiplier.Float64()
+	}
+
+	pips := volatility * multiplier
+	if d.MinPips > 0 {
+		pips = math.Max(pips, d.MinPips.Float64())
+	}
+
+	if d.MaxPips > 0 {
+		pips = math.Min(pips, d.MaxPips.Float64())
+	}
+
+	return pips
+}
+
+// volatility returns the volatility of the interval klines in the price unit, ok is false if there are not enough klines
+func (s *Strategy) volatility() (float64, bool) {
+	iw := types.IntervalWindow{Interval: s.Interval, Window: s.DynamicSpacing.window()}
+
+	switch s.DynamicSpacing.Method {
+	case SpacingMethodStdDev:
+		klines, ok := s.MarketDataStore.KLinesOfInterval(s.Interval)
+		if !ok || len(klines) <= iw.Window {
+			return 0, false
+		}
+
+		// the changes of the last window closes, which need one more kline for the first change
+		recent := klines[len(klines)-iw.Window-1:]
+		changes := make([]float64, 0, iw.Window)
+		for i := 1; i < len(recent); i++ {
+			changes = append(changes, recent[i].Close-recent[i-1].Close)
+		}
+
+		return stat.StdDev(changes, nil), true
+
+	default:
+		return s.StandardIndicatorSet.ATR(iw).Last()
+	}
+}
+
+// updateGridPips recomputes GridPips from the volatility, the previous GridPips is kept if the volatility is not ready
+func (s *Strategy) updateGridPips() {
+	volatility, ok := s.volatility()
+	if !ok || volatility <= 0 {
+		s.Log.Infof("the volatility of %s %s is not ready, keeping the grid pips %f", s.Symbol, s.Interval, s.GridPips.Float64())
+		return
+	}
+
+	pips := s.DynamicSpacing.clamp(volatility)
+
+	// the grid levels can not be closer than the tick size
+	if s.Market.TickSize > 0 {
+		pips = math.Max(s.Market.TruncatePrice(pips), s.Market.TickSize)
+	}
+
+	if pips != s.GridPips.Float64() {
+		s.Log.Infof("%s grid pips is updated from %f to %f by the %s volatility %f", s.Symbol, s.GridPips.Float64(), pips, s.Interval, volatility)
+		s.GridPips = fixedpoint.NewFromFloat(pips)
+	}
+}
+
+// dynamicGridLevels returns the grid prices around the current price with GridPips, the nearest levels are taken first
+func (s *Strategy) dynamicGridLevels(upBand, downBand, currentPrice float64) (prices []float64) {
+	pips := s.GridPips.Float64()
+	for i := 1; len(prices) < s.GridNum; i++ {
+		buyPrice := currentPrice - float64(i)*pips
+		sellPrice := currentPrice + float64(i)*pips
+		if buyPrice < downBand && sellPrice > upBand {
+			break
+		}
+
+		if buyPrice >= downBand {
+			prices = append(prices, buyPrice)
+		}
+
+		if sellPrice <= upBand && len(prices) < s.GridNum {
+			prices = append(prices, sellPrice)
+		}
+	}
+
+	return prices
+}
@@ -2,10 +2,11 @@ package bollgrid
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
 	"sync"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
@@ -14,7 +15,16 @@ import (
 
 const ID = "bollgrid"
 
-var log = logrus.WithField("strategy", ID)
+// GridMode defines how the price range of the grid is derived
+type GridMode string
+
+const (
+	// GridModeBollinger uses the up band and the down band of the BOLLINGER indicator as the price range
+	GridModeBollinger = GridMode("boll")
+
+	// GridModeStatic uses the static upper price and lower price as the price range
+	GridModeStatic = GridMode("static")
+)
 
 func init() {
 	// Register the pointer of the strategy struct,
@@ -24,6 +34,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	// The notification system will be injected into the strategy automatically.
 	// This field will be injected automatically since it's a single exchange strategy.
 	*bbgo.Notifiability
@@ -51,24 +63,47 @@ type Strategy struct {
 	// This field will be injected automatically since we defined the Symbol field.
 	types.Market
 
+	// WarmUp gates the order submission until the indicators are formed by enough market data
+	bbgo.WarmUp
+
 	// These fields will be filled from the config file (it translates YAML to JSON)
 	Symbol string `json:"symbol"`
 
 	// Interval is the interval used by the BOLLINGER indicator (which uses K-Line as its source price)
 	Interval types.Interval `json:"interval"`
 
+	// PriceSource is the kline price used by the BOLLINGER indicator: "close", "hl2", "hlc3" or "vwap", defaults to "close"
+	PriceSource types.PriceSource `json:"priceSource"`
+
 	// RepostInterval is the interval for re-posting maker orders
 	RepostInterval types.Interval `json:"repostInterval"`
 
+	// RepriceOnBandChange re-posts the grid orders as soon as the up band or the down band moves by more than the ratio,
+	// e.g., 0.005 for 0.5%, instead of re-posting on every kline of the interval. It's only used in the "boll" mode.
+	RepriceOnBandChange fixedpoint.Value `json:"repriceOnBandChange"`
+
 	// GridPips is the pips of grid
 	// e.g., 0.001, so that your orders will be submitted at price like 0.127, 0.128, 0.129, 0.130
 	GridPips fixedpoint.Value `json:"gridPips"`
 
+	// DynamicSpacing recomputes GridPips from the ATR or the standard deviation of the interval klines on every re-post,
+	// the grid levels are then placed around the current price with GridPips instead of splitting the price range evenly.
+	DynamicSpacing *DynamicSpacing `json:"dynamicSpacing,omitempty"`
+
 	ProfitSpread fixedpoint.Value `json:"profitSpread"`
 
 	// GridNum is the grid number, how many orders you want to post on the orderbook.
 	GridNum int `json:"gridNumber"`
 
+	// Mode is the grid mode, "boll" or "static", defaults to "static" if both upperPrice and lowerPrice are set,
+	// otherwise "boll".
+	Mode GridMode `json:"mode"`
+
+	// UpperPrice and LowerPrice define the price range of the static grid,
+	// the grid size is computed from the price range and the grid number.
+	UpperPrice fixedpoint.Value `json:"upperPrice"`
+	LowerPrice fixedpoint.Value `json:"lowerPrice"`
+
 	// Quantity is the quantity you want to submit for each order.
 	Quantity float64 `json:"quantity"`
 
@@ -81,6 +116,9 @@ type Strategy struct {
 
 	// boll is the BOLLINGER indicator we used for predicting the price.
 	boll *indicator.BOLL
+
+	// gridUpBand and gridDownBand are the bands of the placed grid, for detecting the band changes
+	gridUpBand, gridDownBand float64
 }
 
 func (s *Strategy) ID() string {
@@ -101,8 +139,8 @@ func (s *Strategy) updateBidOrders(orderExecutor bbgo.OrderExecutor, session *bb
 		return
 	}
 
-	var downBand = s.boll.LastDownBand()
-	if downBand <= 0.0 {
+	downBand, ok := s.boll.LastDownBand()
+	if !ok || downBand <= 0.0 {
 		return
 	}
 
@@ -125,7 +163,7 @@ func (s *Strategy) updateBidOrders(orderExecutor bbgo.OrderExecutor, session *bb
 
 	orders, err := orderExecutor.SubmitOrders(context.Background(), submitOrders...)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
+		s.Log.WithError(err).Errorf("can not place orders")
 		return
 	}
 
@@ -142,8 +180,8 @@ func (s *Strategy) updateAskOrders(orderExecutor bbgo.OrderExecutor, session *bb
 		return
 	}
 
-	var upBand = s.boll.LastUpBand()
-	if upBand <= 0.0 {
+	upBand, ok := s.boll.LastUpBand()
+	if !ok || upBand <= 0.0 {
 		return
 	}
 
@@ -166,7 +204,7 @@ func (s *Strategy) updateAskOrders(orderExecutor bbgo.OrderExecutor, session *bb
 
 	orders, err := orderExecutor.SubmitOrders(context.Background(), submitOrders...)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
+		s.Log.WithError(err).Errorf("can not place orders")
 		return
 	}
 
@@ -174,47 +212,43 @@ func (s *Strategy) updateAskOrders(orderExecutor bbgo.OrderExecutor, session *bb
 	s.activeOrders.Add(orders...)
 }
 
-func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
+// gridOrders returns the orders of the grid levels in the price range, the levels above the current price are the sell orders
+func (s *Strategy) gridOrders(session *bbgo.ExchangeSession) []types.SubmitOrder {
 	quoteCurrency := s.Market.QuoteCurrency
 	balances := session.Account.Balances()
 
 	balance, ok := balances[quoteCurrency]
 	if !ok || balance.Available <= 0 {
-		return
-	}
-
-	var upBand = s.boll.LastUpBand()
-	if upBand <= 0.0 {
-		log.Warnf("up band == 0")
-		return
+		return nil
 	}
 
-	var downBand = s.boll.LastDownBand()
-	if downBand <= 0.0 {
-		log.Warnf("down band == 0")
-		return
+	upBand, downBand, ok := s.priceRange()
+	if !ok {
+		return nil
 	}
 
 	currentPrice, ok := session.LastPrice(s.Symbol)
 	if !ok {
-		log.Warnf("last price not found")
-		return
+		s.Log.Warnf("last price not found")
+		return nil
 	}
 
 	if currentPrice > upBand || currentPrice < downBand {
-		log.Warnf("current price exceed the bollinger band")
-		return
+		s.Log.Warnf("current price exceed the grid price range %f ~ %f", downBand, upBand)
+		return nil
 	}
 
-	ema99 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 99})
-	ema25 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 25})
-	ema7 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 7})
+	ema99, ok99 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 99}).Last()
+	ema25, ok25 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 25}).Last()
+	ema7, ok7 := s.StandardIndicatorSet.EWMA(types.IntervalWindow{Interval: s.Interval, Window: 7}).Last()
 
-	priceRange := upBand - downBand
-	gridSize := priceRange / float64(s.GridNum)
+	// the trend filter is only applied when all the ema lines are ready
+	trendReady := ok99 && ok25 && ok7
+
+	prices := s.gridLevels(upBand, downBand, currentPrice)
 
 	var orders []types.SubmitOrder
-	for price := downBand; price <= upBand; price += gridSize {
+	for _, price := range prices {
 		var side types.SideType
 		if price > currentPrice {
 			side = types.SideTypeSell
@@ -226,14 +260,14 @@ func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, session *bb
 		switch side {
 
 		case types.SideTypeBuy:
-			if ema7.Last() > ema25.Last()*1.001 && ema25.Last() > ema99.Last()*1.0005 {
-				log.Infof("all ema lines trend up, skip buy")
+			if trendReady && ema7 > ema25*1.001 && ema25 > ema99*1.0005 {
+				s.Log.Infof("all ema lines trend up, skip buy")
 				continue
 			}
 
 		case types.SideTypeSell:
-			if ema7.Last() < ema25.Last()*(1-0.004) && ema25.Last() < ema99.Last()*(1-0.0005) {
-				log.Infof("all ema lines trend down, skip sell")
+			if trendReady && ema7 < ema25*(1-0.004) && ema25 < ema99*(1-0.0005) {
+				s.Log.Infof("all ema lines trend down, skip sell")
 				continue
 			}
 		}
@@ -247,13 +281,50 @@ func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, session *bb
 			Price:       price,
 			TimeInForce: "GTC",
 		}
-		log.Infof("submitting order: %s", order.String())
 		orders = append(orders, order)
 	}
 
-	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), orders...)
+	return orders
+}
+
+// gridLevels returns the prices of the grid levels, the price range is split evenly by the grid number unless the
+// dynamic spacing is enabled and GridPips is computed
+func (s *Strategy) gridLevels(upBand, downBand, currentPrice float64) (prices []float64) {
+	if s.DynamicSpacing != nil {
+		s.updateGridPips()
+		if s.GridPips > 0 {
+			return s.dynamicGridLevels(upBand, downBand, currentPrice)
+		}
+	}
+
+	priceRange := upBand - downBand
+	gridSize := priceRange / float64(s.GridNum)
+
+	// the grid can not be placed on a flat price range, which also avoids looping forever
+	if gridSize <= 0 {
+		s.Log.Warnf("invalid grid size %f from the grid price range %f ~ %f", gridSize, downBand, upBand)
+		return nil
+	}
+
+	for price := downBand; price <= upBand; price += gridSize {
+		prices = append(prices, price)
+	}
+
+	return prices
+}
+
+func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, submitOrders []types.SubmitOrder) {
+	if len(submitOrders) == 0 {
+		return
+	}
+
+	for _, order := range submitOrders {
+		s.Log.Infof("submitting order: %s", order.String())
+	}
+
+	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), submitOrders...)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
+		s.Log.WithError(err).Errorf("can not place orders")
 		return
 	}
 
@@ -261,22 +332,129 @@ func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, session *bb
 	s.orders.Add(createdOrders...)
 }
 
-func (s *Strategy) updateOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
-	if err := session.Exchange.CancelOrders(context.Background(), s.activeOrders.Orders()...); err != nil {
-		log.WithError(err).Errorf("cancel order error")
+// repriceGridOrders moves the active grid orders to the new grid levels of the same side, the nearest order to the nearest level.
+// The orders are amended in place if the exchange supports it, so that the grid keeps its liquidity on the book while it's
+// repriced, otherwise each order is canceled and replaced one by one. The extra orders are canceled and the levels without
+// an order to move are submitted.
+func (s *Strategy) repriceGridOrders(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, submitOrders []types.SubmitOrder) {
+	var newOrders []types.SubmitOrder
+	var staleOrders []types.Order
+
+	for _, side := range []types.SideType{types.SideTypeBuy, types.SideTypeSell} {
+		var activeOrders []types.Order
+		for _, order := range s.activeOrders.Orders() {
+			if order.Side == side {
+				activeOrders = append(activeOrders, order)
+			}
+		}
+
+		var levels []types.SubmitOrder
+		for _, order := range submitOrders {
+			if order.Side == side {
+				levels = append(levels, order)
+			}
+		}
+
+		// the bids are sorted from the highest price and the asks from the lowest price, the far orders are canceled first
+		sort.Slice(activeOrders, func(i, j int) bool {
+			return (activeOrders[i].Price > activeOrders[j].Price) == (side == types.SideTypeBuy)
+		})
+		sort.Slice(levels, func(i, j int) bool {
+			return (levels[i].Price > levels[j].Price) == (side == types.SideTypeBuy)
+		})
+
+		for i, level := range levels {
+			if i >= len(activeOrders) {
+				newOrders = append(newOrders, level)
+				continue
+			}
+
+			order := activeOrders[i]
+			if s.Market.FormatPrice(order.Price) == s.Market.FormatPrice(level.Price) {
+				continue
+			}
+
+			amended, err := session.AmendOrder(ctx, orderExecutor, order, level.Price, level.Quantity)
+			if err != nil {
+				s.Log.WithError(err).Errorf("can not reprice order %d to %f", order.OrderID, level.Price)
+				continue
+			}
+
+			if amended.OrderID != order.OrderID {
+				s.activeOrders.Remove(order)
+				s.activeOrders.Add(*amended)
+				s.orders.Add(*amended)
+			} else {
+				s.activeOrders.Update(*amended)
+			}
+		}
+
+		if len(activeOrders) > len(levels) {
+			staleOrders = append(staleOrders, activeOrders[len(levels):]...)
+		}
+	}
+
+	if len(staleOrders) > 0 {
+		if err := session.Exchange.CancelOrders(ctx, staleOrders...); err != nil {
+			s.Log.WithError(err).Errorf("cancel order error")
+		}
+
+		for _, order := range staleOrders {
+			s.activeOrders.Remove(order)
+		}
+	}
+
+	s.placeGridOrders(orderExecutor, newOrders)
+}
+
+// priceRange returns the upper price and the lower price of the grid by the grid mode
+func (s *Strategy) priceRange() (upper, lower float64, ok bool) {
+	if s.Mode == GridModeStatic {
+		return s.UpperPrice.Float64(), s.LowerPrice.Float64(), true
+	}
+
+	upper, ok = s.boll.LastUpBand()
+	if !ok || upper <= 0.0 {
+		s.Log.Warnf("up band is not ready: %f", upper)
+		return upper, lower, false
+	}
+
+	lower, ok = s.boll.LastDownBand()
+	if !ok || lower <= 0.0 {
+		s.Log.Warnf("down band is not ready: %f", lower)
+		return upper, lower, false
 	}
 
-	// skip order updates if up-band - down-band < min profit spread
-	if (s.boll.LastUpBand() - s.boll.LastDownBand()) <= s.ProfitSpread.Float64() {
-		log.Infof("boll: down band price == up band price, skipping...")
+	return upper, lower, true
+}
+
+func (s *Strategy) updateOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
+	// skip order updates if up-band - down-band < min profit spread, the active orders are canceled
+	if upper, lower, ok := s.priceRange(); ok && (upper-lower) <= s.ProfitSpread.Float64() {
+		s.Log.Infof("the grid price range %f ~ %f is less than the profit spread, skipping...", lower, upper)
+		s.repriceGridOrders(context.Background(), orderExecutor, session, nil)
 		return
 	}
 
-	s.placeGridOrders(orderExecutor, session)
+	s.repriceGridOrders(context.Background(), orderExecutor, session, s.gridOrders(session))
+
+	if s.Mode == GridModeBollinger {
+		s.gridUpBand, s.gridDownBand, _ = s.priceRange()
+	}
 
 	s.activeOrders.Print()
 }
 
+// bandChanged checks if the up band or the down band moves by more than RepriceOnBandChange since the grid was placed
+func (s *Strategy) bandChanged(upBand, downBand float64) bool {
+	if s.gridUpBand <= 0 || s.gridDownBand <= 0 {
+		return true
+	}
+
+	ratio := s.RepriceOnBandChange.Float64()
+	return math.Abs(upBand-s.gridUpBand)/s.gridUpBand > ratio || math.Abs(downBand-s.gridDownBand)/s.gridDownBand > ratio
+}
+
 func (s *Strategy) submitReverseOrder(order types.Order) {
 	var side = order.Side.Reverse()
 	var price = order.Price
@@ -299,11 +477,11 @@ func (s *Strategy) submitReverseOrder(order types.Order) {
 		TimeInForce: "GTC",
 	}
 
-	log.Infof("submitting reverse order: %s against %s", submitOrder.String(), order.String())
+	s.Log.Infof("submitting reverse order: %s against %s", submitOrder.String(), order.String())
 
 	createdOrders, err := s.OrderExecutor.SubmitOrders(context.Background(), submitOrder)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
+		s.Log.WithError(err).Errorf("can not place orders")
 		return
 	}
 
@@ -312,14 +490,51 @@ func (s *Strategy) submitReverseOrder(order types.Order) {
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	if s.GridNum == 0 {
 		s.GridNum = 2
 	}
 
-	s.boll = s.StandardIndicatorSet.BOLL(types.IntervalWindow{
-		Interval: s.Interval,
-		Window:   21,
-	}, 2.0)
+	if s.Mode == "" {
+		if s.UpperPrice > 0 && s.LowerPrice > 0 {
+			s.Mode = GridModeStatic
+		} else {
+			s.Mode = GridModeBollinger
+		}
+	}
+
+	switch s.Mode {
+	case GridModeStatic:
+		if s.UpperPrice <= s.LowerPrice {
+			return fmt.Errorf("upper price (%f) should not be less than lower price (%f)", s.UpperPrice.Float64(), s.LowerPrice.Float64())
+		}
+
+	case GridModeBollinger:
+
+	default:
+		return fmt.Errorf("unsupported grid mode: %s", s.Mode)
+	}
+
+	if s.DynamicSpacing != nil {
+		if err := s.DynamicSpacing.Validate(); err != nil {
+			return err
+		}
+
+		// bind the ATR indicator before the klines are received
+		if s.DynamicSpacing.Method != SpacingMethodStdDev {
+			s.StandardIndicatorSet.ATR(types.IntervalWindow{Interval: s.Interval, Window: s.DynamicSpacing.window()})
+		}
+	}
+
+	bollWindow := types.IntervalWindow{
+		Interval:    s.Interval,
+		Window:      21,
+		PriceSource: s.PriceSource,
+	}
+	s.boll = s.StandardIndicatorSet.BOLL(bollWindow, 2.0)
+
+	repriceOnBandChange := s.Mode == GridModeBollinger && s.RepriceOnBandChange > 0
 
 	s.orders = bbgo.NewOrderStore(s.Symbol)
 	s.orders.BindStream(session.Stream)
@@ -341,19 +556,19 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
 		// call Done to notify the main process.
 		defer wg.Done()
-		log.Infof("canceling active orders...")
+		s.Log.Infof("canceling active orders...")
 
 		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
-			log.WithError(err).Errorf("cancel order error")
+			s.Log.WithError(err).Errorf("cancel order error")
 		}
 
 		if err := session.Exchange.CancelOrders(ctx, s.profitOrders.Orders()...); err != nil {
-			log.WithError(err).Errorf("cancel order error")
+			s.Log.WithError(err).Errorf("cancel order error")
 		}
 	})
 
 	session.Stream.OnConnect(func() {
-		log.Infof("connected, submitting the first round of the orders")
+		s.Log.Infof("connected, submitting the first round of the orders")
 		s.updateOrders(orderExecutor, session)
 	})
 
@@ -361,7 +576,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	session.Stream.OnKLineClosed(func(kline types.KLine) {
 		// skip kline events that does not belong to this symbol
 		if kline.Symbol != s.Symbol {
-			log.Infof("%s != %s", kline.Symbol, s.Symbol)
+			s.Log.Infof("%s != %s", kline.Symbol, s.Symbol)
 			return
 		}
 
@@ -371,10 +586,22 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 				s.updateOrders(orderExecutor, session)
 			}
 
-		} else if s.Interval == kline.Interval {
+		} else if s.Interval == kline.Interval && !repriceOnBandChange {
 			s.updateOrders(orderExecutor, session)
 		}
 	})
 
+	if repriceOnBandChange {
+		// the bands are updated by the kline closed event, re-post the grid immediately when they move
+		s.StandardIndicatorSet.OnBOLLUpdate(bollWindow, 2.0, func(sma, upBand, downBand float64) {
+			if !s.bandChanged(upBand, downBand) {
+				return
+			}
+
+			s.Log.Infof("bollinger bands moved to %f ~ %f, re-posting the grid orders", downBand, upBand)
+			s.updateOrders(orderExecutor, session)
+		})
+	}
+
 	return nil
 }
@@ -0,0 +1,301 @@
+package bollmaker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "bollmaker"
+
+const defaultUpdateInterval = 5 * time.Second
+
+func init() {
+	// Register the pointer of the strategy struct,
+	// so that bbgo knows what struct to be used to unmarshal the configs (YAML or JSON)
+	// Note: built-in strategies need to imported manually in the bbgo cmd package.
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy is a market maker that quotes a bid and an ask around the mid price of the order book,
+// the quotes are skewed by the inventory (the base position) and filtered by the bollinger bands.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	// Graceful let you define the graceful shutdown handler
+	*bbgo.Graceful
+
+	// MarketDataStore maintains the order book of the symbol, the depth updates are streamed from it.
+	*bbgo.MarketDataStore
+
+	// StandardIndicatorSet contains the standard indicators of a market (symbol)
+	*bbgo.StandardIndicatorSet
+
+	// Market stores the configuration of the market, for example, VolumePrecision, PricePrecision, MinLotSize... etc
+	types.Market
+
+	// Position is the session position of the symbol, the quotes are skewed by the base position
+	Position *bbgo.Position `json:"-" yaml:"-"`
+
+	Symbol string `json:"symbol"`
+
+	// Interval is the interval of the BOLLINGER indicator used by the band filter, defaults to 5m
+	Interval types.Interval `json:"interval"`
+
+	// BandWidth is the band width of the BOLLINGER indicator, defaults to 2.0
+	BandWidth float64 `json:"bandWidth"`
+
+	// DisableBandFilter disables the band filter, by default, the bid is not placed above the up band
+	// and the ask is not placed below the down band.
+	DisableBandFilter bool `json:"disableBandFilter"`
+
+	// Quantity is the quantity of each quote
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Spread is the ratio of the quote price from the mid price,
+	// e.g., 0.001 places the bid at mid * (1 - 0.001) and the ask at mid * (1 + 0.001)
+	Spread fixedpoint.Value `json:"spread"`
+
+	// DepthLevels is the number of the book levels aggregated for the weighted mid price, defaults to 5
+	DepthLevels int `json:"depthLevels"`
+
+	// UseWeightedMidPrice quotes around the depth weighted mid price instead of the mid price
+	UseWeightedMidPrice bool `json:"useWeightedMidPrice"`
+
+	// MaxInventory is the max base position, the bid (or the ask) is not placed when the long (or the short)
+	// position reaches it. Zero disables the inventory skew and the inventory limit.
+	MaxInventory fixedpoint.Value `json:"maxInventory"`
+
+	// InventorySkew is the price skew ratio at the max inventory, the quotes are shifted by
+	// -InventorySkew * (position / MaxInventory), so that the long position is more likely to be sold.
+	InventorySkew fixedpoint.Value `json:"inventorySkew"`
+
+	// UpdateInterval is the min interval between the quote updates, defaults to 5s
+	UpdateInterval types.Duration `json:"updateInterval"`
+
+	// MinPriceChange re-quotes only when the mid price moves by more than the ratio, e.g., 0.0005 for 0.05%
+	MinPriceChange fixedpoint.Value `json:"minPriceChange"`
+
+	activeOrders *bbgo.LocalActiveOrderBook
+
+	boll *indicator.BOLL
+
+	// lastUpdateTime and lastMidPrice are the time and the mid price of the last quote update
+	lastUpdateTime time.Time
+	lastMidPrice   float64
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval.String()})
+}
+
+func (s *Strategy) Validate() error {
+	if s.Quantity <= 0 {
+		return fmt.Errorf("quantity should be greater than 0")
+	}
+
+	if s.Spread <= 0 {
+		return fmt.Errorf("spread should be greater than 0")
+	}
+
+	if s.MaxInventory < 0 {
+		return fmt.Errorf("maxInventory should not be less than 0")
+	}
+
+	return nil
+}
+
+// inventoryRatio returns position / max inventory in [-1, 1]
+func (s *Strategy) inventoryRatio() float64 {
+	if s.Position == nil || s.MaxInventory <= 0 {
+		return 0.0
+	}
+
+	ratio := s.Position.Base.Float64() / s.MaxInventory.Float64()
+	return math.Max(-1.0, math.Min(1.0, ratio))
+}
+
+// shouldUpdate throttles the quote updates by the update interval and the min price change
+func (s *Strategy) shouldUpdate(midPrice float64, now time.Time) bool {
+	if s.lastMidPrice == 0 {
+		return true
+	}
+
+	if now.Sub(s.lastUpdateTime) < s.UpdateInterval.Duration() {
+		return false
+	}
+
+	return math.Abs(midPrice-s.lastMidPrice)/s.lastMidPrice > s.MinPriceChange.Float64()
+}
+
+// quotePrices returns the bid price and the ask price around the mid price, skewed by the inventory,
+// the prices never cross the best prices of the book, so that the quotes are always makers.
+func (s *Strategy) quotePrices(depth types.BookDepth, midPrice float64) (bidPrice, askPrice float64) {
+	center := midPrice * (1.0 - s.InventorySkew.Float64()*s.inventoryRatio())
+	spread := s.Spread.Float64()
+
+	bidPrice = s.Market.RoundDownPrice(center * (1.0 - spread))
+	askPrice = s.Market.RoundUpPrice(center * (1.0 + spread))
+
+	tickSize := s.Market.PriceStep()
+	bidPrice = math.Min(bidPrice, depth.BestAsk.Price.Float64()-tickSize)
+	askPrice = math.Max(askPrice, depth.BestBid.Price.Float64()+tickSize)
+	return bidPrice, askPrice
+}
+
+func (s *Strategy) updateQuotes(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, depth types.BookDepth) {
+	midPrice := depth.MidPrice.Float64()
+	if s.UseWeightedMidPrice {
+		midPrice = depth.WeightedMidPrice().Float64()
+	}
+
+	now := time.Now()
+	if !s.shouldUpdate(midPrice, now) {
+		return
+	}
+
+	s.lastUpdateTime = now
+	s.lastMidPrice = midPrice
+
+	if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
+		s.Log.WithError(err).Errorf("cancel order error")
+	}
+
+	bidPrice, askPrice := s.quotePrices(depth, midPrice)
+	quantity := s.Quantity.Float64()
+
+	canBuy, canSell := true, true
+
+	if s.MaxInventory > 0 && s.Position != nil {
+		canBuy = s.Position.Base < s.MaxInventory
+		canSell = s.Position.Base > -s.MaxInventory
+	}
+
+	if !s.DisableBandFilter {
+		if upBand, ok := s.boll.LastUpBand(); ok && upBand > 0 && bidPrice > upBand {
+			s.Log.Infof("bid price %f is above the up band %f, skip buy", bidPrice, upBand)
+			canBuy = false
+		}
+
+		if downBand, ok := s.boll.LastDownBand(); ok && downBand > 0 && askPrice < downBand {
+			s.Log.Infof("ask price %f is below the down band %f, skip sell", askPrice, downBand)
+			canSell = false
+		}
+	}
+
+	balances := session.Account.Balances()
+	if balance, ok := balances[s.Market.QuoteCurrency]; !ok || balance.Available.Float64() < bidPrice*quantity {
+		canBuy = false
+	}
+
+	if balance, ok := balances[s.Market.BaseCurrency]; !ok || balance.Available.Float64() < quantity {
+		canSell = false
+	}
+
+	var submitOrders []types.SubmitOrder
+	if canBuy {
+		submitOrders = append(submitOrders, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Side:        types.SideTypeBuy,
+			Type:        types.OrderTypeLimit,
+			Market:      s.Market,
+			Quantity:    quantity,
+			Price:       bidPrice,
+			TimeInForce: "GTC",
+		})
+	}
+
+	if canSell {
+		submitOrders = append(submitOrders, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Side:        types.SideTypeSell,
+			Type:        types.OrderTypeLimit,
+			Market:      s.Market,
+			Quantity:    quantity,
+			Price:       askPrice,
+			TimeInForce: "GTC",
+		})
+	}
+
+	if len(submitOrders) == 0 {
+		return
+	}
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, submitOrders...)
+	if err != nil {
+		s.Log.WithError(err).Errorf("can not place orders")
+		return
+	}
+
+	s.activeOrders.Add(createdOrders...)
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if s.Interval == "" {
+		s.Interval = types.Interval5m
+	}
+
+	if s.BandWidth == 0 {
+		s.BandWidth = 2.0
+	}
+
+	if s.DepthLevels == 0 {
+		s.DepthLevels = 5
+	}
+
+	if s.UpdateInterval == 0 {
+		s.UpdateInterval = types.Duration(defaultUpdateInterval)
+	}
+
+	if s.MarketDataStore == nil {
+		return fmt.Errorf("market data store of %s is not found", s.Symbol)
+	}
+
+	s.boll = s.StandardIndicatorSet.BOLL(types.IntervalWindow{Interval: s.Interval, Window: 21}, s.BandWidth)
+
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeOrders.BindStream(session.Stream)
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		s.Log.Infof("canceling active orders...")
+
+		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
+			s.Log.WithError(err).Errorf("cancel order error")
+		}
+	})
+
+	s.MarketDataStore.OnBookDepthUpdate(func(depth types.BookDepth) {
+		// the depth is streamed with the levels of the store, aggregate again with our levels if they differ
+		if depth.Levels != s.DepthLevels {
+			var ok bool
+			if depth, ok = s.MarketDataStore.Depth(s.DepthLevels); !ok {
+				return
+			}
+		}
+
+		s.updateQuotes(ctx, orderExecutor, session, depth)
+	})
+
+	return nil
+}
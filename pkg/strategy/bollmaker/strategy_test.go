@@ -0,0 +1,211 @@
+package bollmaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testOrderExecutor records the submitted orders
+type testOrderExecutor struct {
+	submitted []types.SubmitOrder
+}
+
+func (e *testOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		e.submitted = append(e.submitted, o)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, OrderID: uint64(len(e.submitted)), Status: types.OrderStatusNew})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *testOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *testOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+	MinQuantity:   0.001,
+	StepSize:      0.001,
+	TickSize:      0.01,
+	MinNotional:   10.0,
+}
+
+func testDepth(bid, ask float64) types.BookDepth {
+	return types.BookDepth{
+		Symbol:   "BTCUSDT",
+		Levels:   1,
+		BestBid:  types.PriceVolume{Price: fixedpoint.NewFromFloat(bid), Volume: fixedpoint.NewFromFloat(1.0)},
+		BestAsk:  types.PriceVolume{Price: fixedpoint.NewFromFloat(ask), Volume: fixedpoint.NewFromFloat(1.0)},
+		MidPrice: fixedpoint.NewFromFloat((bid + ask) / 2.0),
+	}
+}
+
+func newTestStrategy() *Strategy {
+	return &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Market:         testMarket,
+		Symbol:         "BTCUSDT",
+		Quantity:       fixedpoint.NewFromFloat(0.01),
+		Spread:         fixedpoint.NewFromFloat(0.001),
+		UpdateInterval: types.Duration(5 * time.Second),
+		MinPriceChange: fixedpoint.NewFromFloat(0.0005),
+		activeOrders:   bbgo.NewLocalActiveOrderBook(),
+		boll:           &indicator.BOLL{},
+	}
+}
+
+func newTestSession(usdt, btc float64) *bbgo.ExchangeSession {
+	session := bbgo.NewExchangeSession("binance", mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil))
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(btc)},
+	})
+	return session
+}
+
+func TestStrategy_inventoryRatio(t *testing.T) {
+	s := newTestStrategy()
+	assert.Equal(t, 0.0, s.inventoryRatio())
+
+	s.Position = &bbgo.Position{Base: fixedpoint.NewFromFloat(0.5)}
+	assert.Equal(t, 0.0, s.inventoryRatio())
+
+	s.MaxInventory = fixedpoint.NewFromFloat(2.0)
+	assert.InDelta(t, 0.25, s.inventoryRatio(), 1e-9)
+
+	s.Position.Base = fixedpoint.NewFromFloat(-3.0)
+	assert.Equal(t, -1.0, s.inventoryRatio())
+}
+
+func TestStrategy_quotePrices(t *testing.T) {
+	depth := testDepth(9999.0, 10001.0)
+
+	t.Run("spread", func(t *testing.T) {
+		s := newTestStrategy()
+
+		bidPrice, askPrice := s.quotePrices(depth, 10000.0)
+		assert.InDelta(t, 9990.0, bidPrice, 1e-9)
+		assert.InDelta(t, 10010.0, askPrice, 1e-9)
+	})
+
+	t.Run("long inventory skew", func(t *testing.T) {
+		s := newTestStrategy()
+		s.MaxInventory = fixedpoint.NewFromFloat(1.0)
+		s.InventorySkew = fixedpoint.NewFromFloat(0.001)
+		s.Position = &bbgo.Position{Base: fixedpoint.NewFromFloat(1.0)}
+
+		// the quotes are shifted down around 9990
+		bidPrice, askPrice := s.quotePrices(depth, 10000.0)
+		assert.InDelta(t, 9980.01, bidPrice, 1e-9)
+		assert.InDelta(t, 9999.99, askPrice, 1e-9)
+	})
+
+	t.Run("never cross the book", func(t *testing.T) {
+		s := newTestStrategy()
+		s.MaxInventory = fixedpoint.NewFromFloat(1.0)
+		s.InventorySkew = fixedpoint.NewFromFloat(0.01)
+		s.Position = &bbgo.Position{Base: fixedpoint.NewFromFloat(-1.0)}
+
+		// the bid around 10089.9 is capped below the best ask
+		bidPrice, askPrice := s.quotePrices(depth, 10000.0)
+		assert.InDelta(t, 10000.99, bidPrice, 1e-9)
+		assert.InDelta(t, 10110.1, askPrice, 1e-9)
+	})
+}
+
+func TestStrategy_shouldUpdate(t *testing.T) {
+	s := newTestStrategy()
+	now := time.Now()
+	assert.True(t, s.shouldUpdate(10000.0, now))
+
+	s.lastUpdateTime = now
+	s.lastMidPrice = 10000.0
+
+	assert.False(t, s.shouldUpdate(10100.0, now.Add(time.Second)))
+	assert.False(t, s.shouldUpdate(10004.0, now.Add(6*time.Second)))
+	assert.True(t, s.shouldUpdate(10006.0, now.Add(6*time.Second)))
+	assert.True(t, s.shouldUpdate(9994.0, now.Add(6*time.Second)))
+}
+
+func TestStrategy_updateQuotes(t *testing.T) {
+	depth := testDepth(9999.0, 10001.0)
+	ctx := context.Background()
+
+	t.Run("both sides", func(t *testing.T) {
+		s := newTestStrategy()
+		executor := &testOrderExecutor{}
+
+		s.updateQuotes(ctx, executor, newTestSession(1000.0, 1.0), depth)
+
+		if assert.Len(t, executor.submitted, 2) {
+			assert.Equal(t, types.SideTypeBuy, executor.submitted[0].Side)
+			assert.InDelta(t, 9990.0, executor.submitted[0].Price, 1e-9)
+			assert.Equal(t, 0.01, executor.submitted[0].Quantity)
+			assert.Equal(t, types.SideTypeSell, executor.submitted[1].Side)
+			assert.InDelta(t, 10010.0, executor.submitted[1].Price, 1e-9)
+		}
+
+		assert.Len(t, s.activeOrders.Orders(), 2)
+
+		// the quotes are not updated again in the update interval
+		s.updateQuotes(ctx, executor, newTestSession(1000.0, 1.0), testDepth(9899.0, 9901.0))
+		assert.Len(t, executor.submitted, 2)
+	})
+
+	t.Run("max inventory", func(t *testing.T) {
+		s := newTestStrategy()
+		s.MaxInventory = fixedpoint.NewFromFloat(0.5)
+		s.Position = &bbgo.Position{Base: fixedpoint.NewFromFloat(0.5)}
+		executor := &testOrderExecutor{}
+
+		s.updateQuotes(ctx, executor, newTestSession(1000.0, 1.0), depth)
+
+		if assert.Len(t, executor.submitted, 1) {
+			assert.Equal(t, types.SideTypeSell, executor.submitted[0].Side)
+		}
+	})
+
+	t.Run("band filter", func(t *testing.T) {
+		s := newTestStrategy()
+		s.boll.UpBand = indicator.Float64Slice{9980.0}
+		s.boll.DownBand = indicator.Float64Slice{9900.0}
+		executor := &testOrderExecutor{}
+
+		// the bid is above the up band
+		s.updateQuotes(ctx, executor, newTestSession(1000.0, 1.0), depth)
+
+		if assert.Len(t, executor.submitted, 1) {
+			assert.Equal(t, types.SideTypeSell, executor.submitted[0].Side)
+		}
+
+		s = newTestStrategy()
+		s.boll.UpBand = indicator.Float64Slice{10100.0}
+		s.boll.DownBand = indicator.Float64Slice{10020.0}
+		s.DisableBandFilter = true
+		executor = &testOrderExecutor{}
+
+		s.updateQuotes(ctx, executor, newTestSession(1000.0, 1.0), depth)
+		assert.Len(t, executor.submitted, 2)
+	})
+
+	t.Run("insufficient balances", func(t *testing.T) {
+		s := newTestStrategy()
+		executor := &testOrderExecutor{}
+
+		s.updateQuotes(ctx, executor, newTestSession(50.0, 0.001), depth)
+		assert.Empty(t, executor.submitted)
+	})
+}
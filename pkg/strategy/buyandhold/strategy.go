@@ -5,22 +5,26 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/bbgo/exit"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
 const ID = "buyandhold"
 
-var log = logrus.WithField("strategy", ID)
-
 func init() {
 	bbgo.RegisterStrategy(ID, &Strategy{})
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	// Position is the session position of the symbol, it's injected automatically
+	Position *bbgo.Position `json:"-" yaml:"-"`
+
 	Symbol string `json:"symbol"`
 
 	Interval          types.Interval   `json:"interval"`
@@ -29,6 +33,9 @@ type Strategy struct {
 	MinDropChange     fixedpoint.Value `json:"minDropChange"`
 
 	MovingAverageWindow int `json:"movingAverageWindow"`
+
+	// TrailingStop sells the position when the price retraces from the high-water mark after the entry
+	TrailingStop *exit.TrailingStop `json:"trailingStop,omitempty"`
 }
 
 func (s *Strategy) ID() string {
@@ -37,9 +44,15 @@ func (s *Strategy) ID() string {
 
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+
+	if s.TrailingStop != nil {
+		s.TrailingStop.Subscribe(session, s.Symbol)
+	}
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	if s.Interval == "" {
 		s.Interval = types.Interval5m
 	}
@@ -59,6 +72,16 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		return fmt.Errorf("standardIndicatorSet is nil, symbol %s", s.Symbol)
 	}
 
+	if s.TrailingStop != nil {
+		if err := s.TrailingStop.Bind(session, s.Position, orderExecutor); err != nil {
+			return err
+		}
+
+		s.TrailingStop.OnTriggered(func(price, highPrice float64) {
+			s.Notify("%s trailing stop sold the position at %f, the high price since the entry is %f", s.Symbol, price, highPrice)
+		})
+	}
+
 	var iw = types.IntervalWindow{Interval: s.Interval, Window: s.MovingAverageWindow}
 	var ema = standardIndicatorSet.EWMA(iw)
 
@@ -75,8 +98,14 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			return
 		}
 
-		if kline.Close > ema.Last() {
-			log.Warnf("kline close price %f is above EMA %s %f", kline.Close, ema.IntervalWindow, ema.Last())
+		emaPrice, ok := ema.Last()
+		if !ok {
+			s.Log.Warnf("EMA %s is not ready", ema.IntervalWindow)
+			return
+		}
+
+		if kline.Close > emaPrice {
+			s.Log.Warnf("kline close price %f is above EMA %s %f", kline.Close, ema.IntervalWindow, emaPrice)
 			return
 		}
 
@@ -92,7 +121,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			}
 		} else {
 			// not configured, we shall skip
-			log.Warnf("parameters are not configured, skipping action...")
+			s.Log.Warnf("parameters are not configured, skipping action...")
 			return
 		}
 
@@ -105,7 +134,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			Quantity: quantity,
 		})
 		if err != nil {
-			log.WithError(err).Error("submit order error")
+			s.Log.WithError(err).Error("submit order error")
 		}
 	})
 
@@ -0,0 +1,362 @@
+package dca
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "dca"
+
+const (
+	periodDaily  = "daily"
+	periodWeekly = "weekly"
+)
+
+const (
+	orderTypeMarket = "market"
+	orderTypeLimit  = "limit"
+)
+
+func init() {
+	// Register the pointer of the strategy struct,
+	// so that bbgo knows what struct to be used to unmarshal the configs (YAML or JSON)
+	// Note: built-in strategies need to imported manually in the bbgo cmd package.
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// State is the persisted cost basis of the purchases
+type State struct {
+	// TotalCost is the quote amount spent, including the fees paid in the quote currency
+	TotalCost float64 `json:"totalCost"`
+
+	// TotalQuantity is the base quantity bought, excluding the fees paid in the base currency
+	TotalQuantity float64 `json:"totalQuantity"`
+
+	// NumPurchases is the number of the filled purchase orders
+	NumPurchases int `json:"numPurchases"`
+}
+
+// AverageCost is the cost basis of one unit of the base currency
+func (s State) AverageCost() float64 {
+	if s.TotalQuantity == 0 {
+		return 0
+	}
+
+	return s.TotalCost / s.TotalQuantity
+}
+
+// Strategy buys a fixed quote amount of the base currency on a schedule (dollar-cost averaging),
+// the cumulative cost basis is persisted and notified on each purchase.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	// Persistence is used for persisting the cost basis across restarts
+	*bbgo.Persistence
+
+	// Market stores the configuration of the market, for example, VolumePrecision, PricePrecision, MinLotSize... etc
+	types.Market
+
+	Symbol string `json:"symbol"`
+
+	// Amount is the quote amount of each purchase, e.g., 100 USDT
+	Amount fixedpoint.Value `json:"amount"`
+
+	// Schedule is the cron spec of the purchases, e.g., "0 8 * * *" or "@weekly".
+	// When it's not set, the spec is built from Period, Weekday and Time.
+	Schedule string `json:"schedule"`
+
+	// Period is "daily" or "weekly", defaults to daily
+	Period string `json:"period"`
+
+	// Weekday is the day of the weekly purchase, e.g., "monday", defaults to monday
+	Weekday string `json:"weekday"`
+
+	// Time is the time of day of the purchase in the format of "15:04", defaults to "00:00"
+	Time string `json:"time"`
+
+	// Timezone is the location of the schedule, e.g., "Asia/Taipei", defaults to the local timezone
+	Timezone string `json:"timezone"`
+
+	// OrderType is "market" or "limit", defaults to market
+	OrderType string `json:"orderType"`
+
+	// PriceRatio places the limit order below the last price, e.g., 0.005 places it at last price * (1 - 0.005).
+	// The unfilled limit order is canceled at the next purchase.
+	PriceRatio fixedpoint.Value `json:"priceRatio"`
+
+	session *bbgo.ExchangeSession
+	cron    *cron.Cron
+
+	// orderStore keeps all the purchase orders for matching the trades, which could be received after the order is closed
+	orderStore *bbgo.OrderStore
+
+	// activeOrders are the unfilled purchase orders
+	activeOrders *bbgo.LocalActiveOrderBook
+
+	mu    sync.Mutex
+	state State
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	// the last price is updated by the kline
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(types.Interval1m)})
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if s.Amount <= 0 {
+		return fmt.Errorf("amount should be greater than 0")
+	}
+
+	switch strings.ToLower(s.OrderType) {
+	case "", orderTypeMarket, orderTypeLimit:
+	default:
+		return fmt.Errorf("unsupported order type %q, valid types are market and limit", s.OrderType)
+	}
+
+	if s.PriceRatio < 0 || s.PriceRatio >= fixedpoint.NewFromFloat(1.0) {
+		return fmt.Errorf("priceRatio should be in the range of [0, 1)")
+	}
+
+	_, err := s.scheduleSpec()
+	return err
+}
+
+// scheduleSpec returns the cron spec of the purchases
+func (s *Strategy) scheduleSpec() (string, error) {
+	if len(s.Schedule) > 0 {
+		return s.Schedule, nil
+	}
+
+	hour, minute := 0, 0
+	if len(s.Time) > 0 {
+		t, err := time.Parse("15:04", s.Time)
+		if err != nil {
+			return "", fmt.Errorf("invalid time %q, the format is 15:04: %w", s.Time, err)
+		}
+
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	switch strings.ToLower(s.Period) {
+	case "", periodDaily:
+		return fmt.Sprintf("%d %d * * *", minute, hour), nil
+
+	case periodWeekly:
+		weekday := time.Monday
+		if len(s.Weekday) > 0 {
+			var ok bool
+			weekday, ok = parseWeekday(s.Weekday)
+			if !ok {
+				return "", fmt.Errorf("invalid weekday %q", s.Weekday)
+			}
+		}
+
+		return fmt.Sprintf("%d %d * * %d", minute, hour, weekday), nil
+	}
+
+	return "", fmt.Errorf("unsupported period %q, valid periods are daily and weekly", s.Period)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(name, d.String()) || strings.EqualFold(name, d.String()[:3]) {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
+
+func (s *Strategy) loadState() error {
+	if s.Persistence == nil {
+		return nil
+	}
+
+	if err := s.Persistence.LoadState(&s.state, "state"); err != nil && err != bbgo.ErrPersistenceNotExists {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Strategy) saveState() {
+	if s.Persistence == nil {
+		return
+	}
+
+	if err := s.Persistence.SaveState(&s.state, "state"); err != nil {
+		s.Log.WithError(err).Errorf("can not save dca state")
+	}
+}
+
+// purchase places the purchase order of the quote amount
+func (s *Strategy) purchase(ctx context.Context, orderExecutor bbgo.OrderExecutor) {
+	// the unfilled limit orders of the previous purchase are replaced
+	if orders := s.activeOrders.Orders(); len(orders) > 0 {
+		if err := s.activeOrders.Cancel(ctx, s.session.Exchange, orders...); err != nil {
+			s.Log.WithError(err).Errorf("can not cancel the previous purchase orders")
+		}
+	}
+
+	lastPrice, ok := s.session.LastPrice(s.Symbol)
+	if !ok || lastPrice <= 0 {
+		s.Notify("%s dca purchase is skipped, the last price is not available", s.Symbol)
+		return
+	}
+
+	amount := s.Amount.Float64()
+	if balance, ok := s.session.Account.Balance(s.Market.QuoteCurrency); ok && balance.Available.Float64() < amount {
+		s.Notify("%s dca purchase is skipped, the available %s balance %f is less than the amount %f",
+			s.Symbol, s.Market.QuoteCurrency, balance.Available.Float64(), amount)
+		return
+	}
+
+	order := types.SubmitOrder{
+		Symbol: s.Symbol,
+		Side:   types.SideTypeBuy,
+		Type:   types.OrderTypeMarket,
+		Market: s.Market,
+	}
+
+	price := lastPrice
+	if strings.ToLower(s.OrderType) == orderTypeLimit {
+		price = s.Market.RoundDownPrice(lastPrice * (1.0 - s.PriceRatio.Float64()))
+		order.Type = types.OrderTypeLimit
+		order.Price = price
+		order.TimeInForce = "GTC"
+	}
+
+	order.Quantity = s.Market.RoundDownQuantity(amount / price)
+	if order.Quantity < s.Market.MinQuantity || order.Quantity*price < s.Market.MinNotional {
+		s.Notify("%s dca purchase is skipped, the quantity %f is below the market filters", s.Symbol, order.Quantity)
+		return
+	}
+
+	s.Log.Infof("placing dca purchase order: %+v", order)
+
+	createdOrders, err := orderExecutor.SubmitOrders(ctx, order)
+	if err != nil {
+		s.Log.WithError(err).Errorf("can not place the dca purchase order")
+		s.Notify("%s dca purchase order can not be placed: %v", s.Symbol, err)
+		return
+	}
+
+	s.orderStore.Add(createdOrders...)
+	s.activeOrders.Add(createdOrders...)
+}
+
+func (s *Strategy) handleTradeUpdate(trade types.Trade) {
+	if trade.Symbol != s.Symbol || !s.orderStore.Exists(trade.OrderID) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.TotalCost += trade.QuoteQuantity
+	s.state.TotalQuantity += trade.Quantity
+
+	switch trade.FeeCurrency {
+	case s.Market.QuoteCurrency:
+		s.state.TotalCost += trade.Fee
+	case s.Market.BaseCurrency:
+		s.state.TotalQuantity -= trade.Fee
+	}
+
+	s.saveState()
+}
+
+// handleClosedOrder notifies the purchase when the order is filled, or canceled with the partial fills
+func (s *Strategy) handleClosedOrder(order types.Order) {
+	if order.ExecutedQuantity <= 0 {
+		s.Log.Infof("dca purchase order %d is closed without fills", order.OrderID)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.NumPurchases++
+	s.saveState()
+
+	s.Notify("%s dca purchase #%d bought %s %s, total %s %s at the average cost %s",
+		s.Symbol, s.state.NumPurchases,
+		s.Market.FormatQuantity(order.ExecutedQuantity), s.Market.BaseCurrency,
+		s.Market.FormatQuantity(s.state.TotalQuantity), s.Market.BaseCurrency,
+		s.Market.FormatPriceCurrency(s.state.AverageCost()))
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	spec, err := s.scheduleSpec()
+	if err != nil {
+		return err
+	}
+
+	location := time.Local
+	if len(s.Timezone) > 0 {
+		location, err = time.LoadLocation(s.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+		}
+	}
+
+	if err := s.loadState(); err != nil {
+		return err
+	}
+
+	if s.state.NumPurchases > 0 {
+		s.Log.Infof("loaded dca state: %d purchases, total cost %f, total quantity %f",
+			s.state.NumPurchases, s.state.TotalCost, s.state.TotalQuantity)
+	}
+
+	s.session = session
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.orderStore.BindStream(session.Stream)
+
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeOrders.OnFilled(s.handleClosedOrder)
+	s.activeOrders.OnCanceled(s.handleClosedOrder)
+	s.activeOrders.BindStream(session.Stream)
+
+	session.Stream.OnTradeUpdate(s.handleTradeUpdate)
+
+	s.cron = cron.New(cron.WithLocation(location))
+	if _, err := s.cron.AddFunc(spec, func() { s.purchase(ctx, orderExecutor) }); err != nil {
+		return fmt.Errorf("invalid dca schedule %q: %w", spec, err)
+	}
+
+	s.Log.Infof("scheduled %s dca purchases of %f %s by %q", s.Symbol, s.Amount.Float64(), s.Market.QuoteCurrency, spec)
+
+	s.cron.Start()
+	go func() {
+		<-ctx.Done()
+		s.cron.Stop()
+	}()
+
+	return nil
+}
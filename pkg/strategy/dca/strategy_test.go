@@ -0,0 +1,207 @@
+package dca
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testOrderExecutor creates the submitted orders with the sequential order IDs
+type testOrderExecutor struct {
+	orderID   uint64
+	submitted []types.SubmitOrder
+}
+
+func (e *testOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		e.orderID++
+		e.submitted = append(e.submitted, o)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, OrderID: e.orderID, Status: types.OrderStatusNew})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *testOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *testOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+	MinNotional:   10.0,
+	MinQuantity:   0.0001,
+	StepSize:      0.0001,
+	TickSize:      0.01,
+}
+
+// newTestStrategy creates the strategy on the session of the given USDT balance and the last price of BTCUSDT
+func newTestStrategy(t *testing.T, usdt, lastPrice float64) *Strategy {
+	exchange := mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil)
+	session := bbgo.NewExchangeSession("binance", exchange)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+		"BTC":  {Currency: "BTC"},
+	})
+
+	now := time.Now()
+	exchange.PushKLine(types.KLine{
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1m,
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now,
+		Close:     lastPrice,
+		Closed:    true,
+	})
+
+	if !assert.NoError(t, session.UpdatePrices(context.Background())) {
+		t.FailNow()
+	}
+
+	s := &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:  &bbgo.Notifiability{},
+		Market:         testMarket,
+		Symbol:         "BTCUSDT",
+		Amount:         fixedpoint.NewFromFloat(100.0),
+	}
+
+	s.session = session
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	return s
+}
+
+func TestStrategy_scheduleSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		expected string
+		err      bool
+	}{
+		{name: "default", expected: "0 0 * * *"},
+		{name: "schedule", strategy: Strategy{Schedule: "@weekly", Period: "weekly"}, expected: "@weekly"},
+		{name: "daily", strategy: Strategy{Period: "Daily", Time: "08:30"}, expected: "30 8 * * *"},
+		{name: "weekly", strategy: Strategy{Period: "weekly"}, expected: "0 0 * * 1"},
+		{name: "weekday", strategy: Strategy{Period: "weekly", Weekday: "Fri", Time: "23:05"}, expected: "5 23 * * 5"},
+		{name: "sunday", strategy: Strategy{Period: "weekly", Weekday: "sunday"}, expected: "0 0 * * 0"},
+		{name: "invalid time", strategy: Strategy{Time: "8am"}, err: true},
+		{name: "invalid weekday", strategy: Strategy{Period: "weekly", Weekday: "someday"}, err: true},
+		{name: "invalid period", strategy: Strategy{Period: "monthly"}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := tt.strategy.scheduleSpec()
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, spec)
+			}
+		})
+	}
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{Symbol: "BTCUSDT", Amount: fixedpoint.NewFromFloat(100.0), OrderType: "Limit", PriceRatio: fixedpoint.NewFromFloat(0.01)}
+	assert.NoError(t, s.Validate())
+
+	s.OrderType = "stop"
+	assert.Error(t, s.Validate())
+
+	s.OrderType = orderTypeMarket
+	s.PriceRatio = fixedpoint.NewFromFloat(1.0)
+	assert.Error(t, s.Validate())
+
+	s.PriceRatio = 0
+	s.Amount = 0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_purchase(t *testing.T) {
+	t.Run("market order", func(t *testing.T) {
+		s := newTestStrategy(t, 1000.0, 10000.0)
+		executor := &testOrderExecutor{}
+
+		s.purchase(context.Background(), executor)
+
+		if assert.Len(t, executor.submitted, 1) {
+			order := executor.submitted[0]
+			assert.Equal(t, types.SideTypeBuy, order.Side)
+			assert.Equal(t, types.OrderTypeMarket, order.Type)
+			assert.InDelta(t, 0.01, order.Quantity, 1e-9)
+		}
+
+		assert.True(t, s.orderStore.Exists(1))
+		assert.Len(t, s.activeOrders.Orders(), 1)
+	})
+
+	t.Run("limit order below the last price", func(t *testing.T) {
+		s := newTestStrategy(t, 1000.0, 10000.0)
+		s.OrderType = orderTypeLimit
+		s.PriceRatio = fixedpoint.NewFromFloat(0.005)
+		executor := &testOrderExecutor{}
+
+		s.purchase(context.Background(), executor)
+
+		if assert.Len(t, executor.submitted, 1) {
+			order := executor.submitted[0]
+			assert.Equal(t, types.OrderTypeLimit, order.Type)
+			assert.Equal(t, 9950.0, order.Price)
+			// 100 / 9950 is rounded down to the step size
+			assert.InDelta(t, 0.01, order.Quantity, 1e-9)
+			assert.Equal(t, types.TimeInForce("GTC"), order.TimeInForce)
+		}
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		s := newTestStrategy(t, 50.0, 10000.0)
+		executor := &testOrderExecutor{}
+
+		s.purchase(context.Background(), executor)
+		assert.Empty(t, executor.submitted)
+	})
+
+	t.Run("below the min notional", func(t *testing.T) {
+		s := newTestStrategy(t, 1000.0, 10000.0)
+		s.Amount = fixedpoint.NewFromFloat(5.0)
+		executor := &testOrderExecutor{}
+
+		s.purchase(context.Background(), executor)
+		assert.Empty(t, executor.submitted)
+	})
+}
+
+func TestStrategy_handleTradeUpdate(t *testing.T) {
+	s := newTestStrategy(t, 1000.0, 10000.0)
+	s.orderStore.Add(types.Order{SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT"}, OrderID: 1})
+
+	// the fee in the quote currency is added to the cost
+	s.handleTradeUpdate(types.Trade{OrderID: 1, Symbol: "BTCUSDT", Quantity: 0.01, QuoteQuantity: 100.0, Fee: 0.1, FeeCurrency: "USDT"})
+
+	// the fee in the base currency is deducted from the quantity
+	s.handleTradeUpdate(types.Trade{OrderID: 1, Symbol: "BTCUSDT", Quantity: 0.02, QuoteQuantity: 180.0, Fee: 0.0001, FeeCurrency: "BTC"})
+
+	// the trades of the other orders are ignored
+	s.handleTradeUpdate(types.Trade{OrderID: 2, Symbol: "BTCUSDT", Quantity: 1.0, QuoteQuantity: 10000.0})
+
+	assert.InDelta(t, 280.1, s.state.TotalCost, 1e-9)
+	assert.InDelta(t, 0.0299, s.state.TotalQuantity, 1e-9)
+	assert.InDelta(t, 280.1/0.0299, s.state.AverageCost(), 1e-6)
+
+	s.handleClosedOrder(types.Order{OrderID: 1, Status: types.OrderStatusFilled, ExecutedQuantity: 0.03})
+	s.handleClosedOrder(types.Order{OrderID: 3, Status: types.OrderStatusCanceled})
+	assert.Equal(t, 1, s.state.NumPurchases)
+}
@@ -0,0 +1,70 @@
+package external
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The external process talks to bbgo with the JSON lines, one message per line.
+// bbgo writes the events to the stdin of the process, and reads the commands from the stdout of the process,
+// the stderr of the process is forwarded to the log.
+
+const (
+	// EventInit is the first event with the symbol, the market and the params of the strategy config
+	EventInit = "init"
+
+	// EventKLineClosed is sent when the kline of the subscribed interval is closed
+	EventKLineClosed = "kline"
+
+	// EventTrade is sent when the order of the symbol is filled
+	EventTrade = "trade"
+
+	// EventOrder is sent when the order of the symbol is updated
+	EventOrder = "order"
+
+	// EventBalances is sent when the account balances are updated
+	EventBalances = "balances"
+
+	// EventOrdersCreated is the response of the submitOrders command
+	EventOrdersCreated = "ordersCreated"
+
+	// EventError is the error response of the command
+	EventError = "error"
+)
+
+const (
+	// CommandSubmitOrders submits the orders through the order executor of the strategy
+	CommandSubmitOrders = "submitOrders"
+
+	// CommandCancelOrders cancels the active orders of the strategy by the order ids, all the active orders are
+	// canceled if no order id is given
+	CommandCancelOrders = "cancelOrders"
+
+	// CommandNotify sends the message through the notifiers
+	CommandNotify = "notify"
+)
+
+// Event is the message sent to the external process
+type Event struct {
+	Event string `json:"event"`
+
+	Symbol string                 `json:"symbol,omitempty"`
+	Market *types.Market          `json:"market,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	KLine    *types.KLine     `json:"kline,omitempty"`
+	Trade    *types.Trade     `json:"trade,omitempty"`
+	Order    *types.Order     `json:"order,omitempty"`
+	Orders   []types.Order    `json:"orders,omitempty"`
+	Balances types.BalanceMap `json:"balances,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Command is the message received from the external process
+type Command struct {
+	Action string `json:"action"`
+
+	Orders   []types.SubmitOrder `json:"orders,omitempty"`
+	OrderIDs []uint64            `json:"orderIDs,omitempty"`
+	Message  string              `json:"message,omitempty"`
+}
@@ -0,0 +1,298 @@
+package external
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "external"
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy runs the strategy logic in an external process, so the strategy can be written in any language
+// without recompiling bbgo. The events of the symbol are written to the stdin of the process, and the order
+// commands are read from its stdout, see protocol.go for the message format.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	Symbol   string         `json:"symbol"`
+	Interval types.Interval `json:"interval"`
+
+	// Command is the command line of the external process, e.g., ["python3", "strategy.py"]
+	Command []string `json:"command"`
+
+	// Env is the additional environment variables of the external process
+	Env map[string]string `json:"env,omitempty"`
+
+	// Params is passed to the external process by the init event
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor bbgo.OrderExecutor
+	activeOrders  *bbgo.LocalActiveOrderBook
+
+	cmd  *exec.Cmd
+	done chan struct{}
+
+	mu      sync.Mutex
+	stdin   io.WriteCloser
+	encoder *json.Encoder
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.Command) == 0 {
+		return fmt.Errorf("command of the external strategy is required")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if s.Interval == "" {
+		s.Interval = types.Interval1m
+	}
+
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	market, ok := session.Market(s.Symbol)
+	if !ok {
+		return fmt.Errorf("market %s is not defined", s.Symbol)
+	}
+
+	s.session = session
+	s.orderExecutor = orderExecutor
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeOrders.BindStream(session.Stream)
+
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+
+	if err := s.send(Event{Event: EventInit, Symbol: s.Symbol, Market: &market, Params: s.Params}); err != nil {
+		return err
+	}
+
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != s.Interval {
+			return
+		}
+
+		s.sendEvent(Event{Event: EventKLineClosed, KLine: &kline})
+	})
+
+	session.Stream.OnTradeUpdate(func(trade types.Trade) {
+		if trade.Symbol != s.Symbol {
+			return
+		}
+
+		s.sendEvent(Event{Event: EventTrade, Trade: &trade})
+	})
+
+	session.Stream.OnOrderUpdate(func(order types.Order) {
+		if order.Symbol != s.Symbol {
+			return
+		}
+
+		s.sendEvent(Event{Event: EventOrder, Order: &order})
+	})
+
+	session.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		s.sendEvent(Event{Event: EventBalances, Balances: balances})
+	})
+
+	return nil
+}
+
+// start starts the external process and the goroutines reading its stdout and stderr
+func (s *Strategy) start(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("can not start the external strategy %v: %w", s.Command, err)
+	}
+
+	s.Log.Infof("external strategy %v is started, pid %d", s.Command, cmd.Process.Pid)
+
+	s.cmd = cmd
+	s.done = make(chan struct{})
+	s.stdin = stdin
+	s.encoder = json.NewEncoder(stdin)
+
+	go s.forwardLog(stderr)
+	go s.readCommands(ctx, stdout)
+	go func() {
+		defer close(s.done)
+		if err := cmd.Wait(); err != nil {
+			s.Log.WithError(err).Errorf("external strategy %v exited", s.Command)
+		}
+	}()
+
+	return nil
+}
+
+func (s *Strategy) send(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(event)
+}
+
+func (s *Strategy) sendEvent(event Event) {
+	if err := s.send(event); err != nil {
+		s.Log.WithError(err).Errorf("can not send the %s event to the external strategy", event.Event)
+	}
+}
+
+func (s *Strategy) forwardLog(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		s.Log.Info(scanner.Text())
+	}
+}
+
+func (s *Strategy) readCommands(ctx context.Context, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		var command Command
+		if err := json.Unmarshal(scanner.Bytes(), &command); err != nil {
+			s.Log.WithError(err).Errorf("invalid command from the external strategy: %s", scanner.Text())
+			s.sendEvent(Event{Event: EventError, Error: err.Error()})
+			continue
+		}
+
+		if err := s.handleCommand(ctx, command); err != nil {
+			s.Log.WithError(err).Errorf("external strategy command %s error", command.Action)
+			s.sendEvent(Event{Event: EventError, Error: err.Error()})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		s.Log.WithError(err).Error("external strategy stdout read error")
+	}
+}
+
+func (s *Strategy) handleCommand(ctx context.Context, command Command) error {
+	switch command.Action {
+	case CommandSubmitOrders:
+		for i := range command.Orders {
+			if command.Orders[i].Symbol != s.Symbol {
+				return fmt.Errorf("the external strategy can only submit the orders of %s, got %s", s.Symbol, command.Orders[i].Symbol)
+			}
+		}
+
+		createdOrders, err := s.orderExecutor.SubmitOrders(ctx, command.Orders...)
+		s.activeOrders.Add(createdOrders...)
+		if err != nil {
+			return err
+		}
+
+		return s.send(Event{Event: EventOrdersCreated, Orders: createdOrders})
+
+	case CommandCancelOrders:
+		var orders []types.Order
+		for _, o := range s.activeOrders.Orders() {
+			if len(command.OrderIDs) == 0 || containsOrderID(command.OrderIDs, o.OrderID) {
+				orders = append(orders, o)
+			}
+		}
+
+		return s.activeOrders.Cancel(ctx, s.session.Exchange, orders...)
+
+	case CommandNotify:
+		s.Notify("%s", command.Message)
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q", command.Action)
+}
+
+func containsOrderID(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Shutdown cancels the active orders and stops the external process, it's called by the trader with the shutdown deadline.
+func (s *Strategy) Shutdown(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if s.session == nil {
+		return
+	}
+
+	if err := s.activeOrders.Cancel(ctx, s.session.Exchange, s.activeOrders.Orders()...); err != nil {
+		s.Log.WithError(err).Error("can not cancel the active orders of the external strategy")
+	}
+
+	if s.cmd == nil {
+		return
+	}
+
+	// close the stdin so that the process can exit gracefully, and kill it if it doesn't exit in time
+	s.mu.Lock()
+	_ = s.stdin.Close()
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+	}
+
+	_ = s.cmd.Process.Kill()
+}
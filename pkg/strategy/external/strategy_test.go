@@ -0,0 +1,183 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// exchangeOrderExecutor submits the orders to the mock exchange directly
+type exchangeOrderExecutor struct {
+	*mock.Exchange
+}
+
+func (e *exchangeOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *exchangeOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+var testMarkets = types.MarketMap{
+	"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.0001, StepSize: 0.0001, TickSize: 0.01},
+	"ETHUSDT": {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, TickSize: 0.01},
+}
+
+// newTestStrategy creates the strategy without the external process, the events are written to the returned buffer
+func newTestStrategy() (*Strategy, *mock.Exchange, *bytes.Buffer) {
+	exchange := mock.New(types.ExchangeBinance, testMarkets, nil)
+	exchange.SetBalance("USDT", 10000.0)
+
+	var events bytes.Buffer
+	s := &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:  &bbgo.Notifiability{},
+		Symbol:         "BTCUSDT",
+		Command:        []string{"python3", "strategy.py"},
+		session:        bbgo.NewExchangeSession("binance", exchange),
+		orderExecutor:  &exchangeOrderExecutor{exchange},
+		activeOrders:   bbgo.NewLocalActiveOrderBook(),
+		encoder:        json.NewEncoder(&events),
+	}
+
+	s.activeOrders.BindStream(exchange.NewStream())
+	return s, exchange, &events
+}
+
+func decodeEvents(t *testing.T, events *bytes.Buffer) (decoded []Event) {
+	decoder := json.NewDecoder(events)
+	for decoder.More() {
+		var event Event
+		if !assert.NoError(t, decoder.Decode(&event)) {
+			break
+		}
+
+		decoded = append(decoded, event)
+	}
+
+	return decoded
+}
+
+func limitOrder(symbol string, side types.SideType, price, quantity float64) types.SubmitOrder {
+	return types.SubmitOrder{Symbol: symbol, Side: side, Type: types.OrderTypeLimit, Price: price, Quantity: quantity}
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s, _, _ := newTestStrategy()
+	assert.NoError(t, s.Validate())
+
+	s.Command = nil
+	assert.Error(t, s.Validate())
+
+	s, _, _ = newTestStrategy()
+	s.Symbol = ""
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_handleCommand_submitOrders(t *testing.T) {
+	s, exchange, events := newTestStrategy()
+	ctx := context.Background()
+
+	err := s.handleCommand(ctx, Command{
+		Action: CommandSubmitOrders,
+		Orders: []types.SubmitOrder{
+			limitOrder("BTCUSDT", types.SideTypeBuy, 9000.0, 0.01),
+			limitOrder("BTCUSDT", types.SideTypeBuy, 8000.0, 0.02),
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 2)
+	assert.Len(t, s.activeOrders.Orders(), 2)
+
+	// the created orders are sent back to the external process
+	decoded := decodeEvents(t, events)
+	if assert.Len(t, decoded, 1) {
+		assert.Equal(t, EventOrdersCreated, decoded[0].Event)
+		if assert.Len(t, decoded[0].Orders, 2) {
+			assert.Equal(t, 9000.0, decoded[0].Orders[0].Price)
+			assert.Equal(t, 0.02, decoded[0].Orders[1].Quantity)
+		}
+	}
+
+	// the orders of the other symbols are rejected as a whole
+	err = s.handleCommand(ctx, Command{
+		Action: CommandSubmitOrders,
+		Orders: []types.SubmitOrder{
+			limitOrder("BTCUSDT", types.SideTypeBuy, 9000.0, 0.01),
+			limitOrder("ETHUSDT", types.SideTypeBuy, 500.0, 1.0),
+		},
+	})
+	assert.Error(t, err)
+	assert.Len(t, exchange.SubmittedOrders(), 2)
+}
+
+func TestStrategy_handleCommand_cancelOrders(t *testing.T) {
+	s, exchange, _ := newTestStrategy()
+	ctx := context.Background()
+
+	err := s.handleCommand(ctx, Command{
+		Action: CommandSubmitOrders,
+		Orders: []types.SubmitOrder{
+			limitOrder("BTCUSDT", types.SideTypeBuy, 9000.0, 0.01),
+			limitOrder("BTCUSDT", types.SideTypeBuy, 8000.0, 0.01),
+			limitOrder("BTCUSDT", types.SideTypeBuy, 7000.0, 0.01),
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	orders := exchange.OpenOrders("BTCUSDT")
+	if !assert.Len(t, orders, 3) {
+		return
+	}
+
+	assert.NoError(t, s.handleCommand(ctx, Command{Action: CommandCancelOrders, OrderIDs: []uint64{orders[1].OrderID}}))
+
+	openOrders := exchange.OpenOrders("BTCUSDT")
+	if assert.Len(t, openOrders, 2) {
+		assert.Equal(t, orders[0].OrderID, openOrders[0].OrderID)
+		assert.Equal(t, orders[2].OrderID, openOrders[1].OrderID)
+	}
+
+	// the canceled order is removed by the order update
+	assert.Len(t, s.activeOrders.Orders(), 2)
+
+	// all the active orders are canceled without the order ids
+	assert.NoError(t, s.handleCommand(ctx, Command{Action: CommandCancelOrders}))
+	assert.Empty(t, exchange.OpenOrders("BTCUSDT"))
+	assert.Empty(t, s.activeOrders.Orders())
+}
+
+func TestStrategy_readCommands(t *testing.T) {
+	s, exchange, events := newTestStrategy()
+
+	commands := strings.Join([]string{
+		`{"action":"submitOrders","orders":[{"symbol":"BTCUSDT","side":"BUY","orderType":"LIMIT","price":9000,"quantity":0.01}]}`,
+		`not a json`,
+		`{"action":"transfer"}`,
+		`{"action":"notify","message":"hello"}`,
+	}, "\n")
+
+	s.readCommands(context.Background(), strings.NewReader(commands))
+
+	assert.Len(t, exchange.OpenOrders("BTCUSDT"), 1)
+
+	// the invalid and the unknown commands are responded with the error events
+	decoded := decodeEvents(t, events)
+	if assert.Len(t, decoded, 3) {
+		assert.Equal(t, EventOrdersCreated, decoded[0].Event)
+		assert.Equal(t, EventError, decoded[1].Event)
+		assert.Equal(t, EventError, decoded[2].Event)
+		assert.Contains(t, decoded[2].Error, "transfer")
+	}
+}
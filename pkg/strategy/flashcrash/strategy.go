@@ -6,8 +6,6 @@ import (
 	"context"
 	"sync"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/types"
@@ -20,6 +18,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	// These fields will be filled from the config file (it translates YAML to JSON)
 	// Symbol is the symbol of market you want to run this strategy
 	Symbol string `json:"symbol"`
@@ -62,7 +62,7 @@ func (s *Strategy) ID() string {
 
 func (s *Strategy) updateOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
 	if err := session.Exchange.CancelOrders(context.Background(), s.activeOrders.Bids.Orders()...); err != nil {
-		log.WithError(err).Errorf("cancel order error")
+		s.Log.WithError(err).Errorf("cancel order error")
 	}
 
 	s.updateBidOrders(orderExecutor, session)
@@ -74,11 +74,17 @@ func (s *Strategy) updateBidOrders(orderExecutor bbgo.OrderExecutor, session *bb
 
 	balance, ok := balances[quoteCurrency]
 	if !ok || balance.Available <= 0 {
-		log.Infof("insufficient balance of %s: %f", quoteCurrency, balance.Available.Float64())
+		s.Log.Infof("insufficient balance of %s: %f", quoteCurrency, balance.Available.Float64())
+		return
+	}
+
+	ewmaPrice, ok := s.ewma.Last()
+	if !ok || ewmaPrice <= 0 {
+		s.Log.Warnf("EWMA %s is not ready, skip placing orders", s.ewma.IntervalWindow)
 		return
 	}
 
-	var startPrice = s.ewma.Last() * s.Percentage
+	var startPrice = ewmaPrice * s.Percentage
 
 	var submitOrders []types.SubmitOrder
 	for i := 0; i < s.GridNum; i++ {
@@ -97,7 +103,7 @@ func (s *Strategy) updateBidOrders(orderExecutor bbgo.OrderExecutor, session *bb
 
 	orders, err := orderExecutor.SubmitOrders(context.Background(), submitOrders...)
 	if err != nil {
-		log.WithError(err).Error("submit bid order error")
+		s.Log.WithError(err).Error("submit bid order error")
 		return
 	}
 
@@ -109,6 +115,8 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	// we don't persist orders so that we can not clear the previous orders for now. just need time to support this.
 	s.activeOrders = bbgo.NewLocalActiveOrderBook()
 	s.activeOrders.BindStream(session.Stream)
@@ -116,10 +124,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
 
-		log.Infof("canceling active orders...")
+		s.Log.Infof("canceling active orders...")
 
 		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
-			log.WithError(err).Errorf("cancel order error")
+			s.Log.WithError(err).Errorf("cancel order error")
 		}
 	})
 
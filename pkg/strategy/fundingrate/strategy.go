@@ -0,0 +1,312 @@
+package fundingrate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "fundingrate"
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// State is the hedged position of the strategy, the spot long quantity and the perpetual short quantity
+type State struct {
+	SpotQuantity    fixedpoint.Value `json:"spotQuantity"`
+	FuturesQuantity fixedpoint.Value `json:"futuresQuantity"`
+}
+
+func (s State) IsOpen() bool {
+	return s.SpotQuantity > 0 || s.FuturesQuantity > 0
+}
+
+// Strategy earns the funding fee of the perpetual contract with the delta-neutral position: when the predicted funding
+// rate is above the open threshold, it buys the spot and shorts the same quantity of the perpetual contract, so that
+// the short position receives the funding fee paid by the longs. The position is unwound when the funding rate decays
+// below the exit threshold.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+	*bbgo.Graceful
+	*bbgo.Persistence
+
+	Symbol string `json:"symbol"`
+
+	// SpotSession is the session of the spot market, FuturesSession is the futures session of the perpetual contract
+	SpotSession    string `json:"spotSession"`
+	FuturesSession string `json:"futuresSession"`
+
+	// Quantity is the base quantity of the hedged position
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// MinFundingRate is the predicted funding rate opening the position, e.g., 0.0005 for 0.05% per funding period
+	MinFundingRate fixedpoint.Value `json:"minFundingRate"`
+
+	// ExitFundingRate is the predicted funding rate unwinding the position, it should be below the min funding rate
+	ExitFundingRate fixedpoint.Value `json:"exitFundingRate"`
+
+	// Leverage is the leverage of the perpetual contract, the leverage of the exchange is kept if it's zero
+	Leverage int `json:"leverage"`
+
+	State *State `json:"-"`
+
+	router bbgo.OrderExecutionRouter
+
+	spotMarket    types.Market
+	futuresMarket types.Market
+
+	executing int32
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	session, ok := sessions[s.FuturesSession]
+	if !ok {
+		panic(fmt.Errorf("session %s is not defined", s.FuturesSession))
+	}
+
+	session.Subscribe(types.FundingRateChannel, s.Symbol, types.SubscribeOptions{})
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.SpotSession) == 0 || len(s.FuturesSession) == 0 {
+		return fmt.Errorf("spotSession and futuresSession are required")
+	}
+
+	if s.Quantity <= 0 {
+		return fmt.Errorf("quantity should be greater than 0")
+	}
+
+	if s.MinFundingRate <= 0 {
+		return fmt.Errorf("minFundingRate should be greater than 0")
+	}
+
+	if s.ExitFundingRate >= s.MinFundingRate {
+		return fmt.Errorf("exitFundingRate %f should be below minFundingRate %f", s.ExitFundingRate.Float64(), s.MinFundingRate.Float64())
+	}
+
+	return nil
+}
+
+func (s *Strategy) handleFundingRate(ctx context.Context, rate types.FundingRate) {
+	if rate.Symbol != s.Symbol {
+		return
+	}
+
+	var open bool
+	switch {
+	case !s.State.IsOpen() && rate.FundingRate >= s.MinFundingRate:
+		open = true
+
+	case s.State.IsOpen() && rate.FundingRate <= s.ExitFundingRate:
+		open = false
+
+	default:
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.executing, 0, 1) {
+		return
+	}
+
+	// the funding rates are received from the stream, execute in another goroutine to avoid blocking it
+	go func() {
+		defer atomic.StoreInt32(&s.executing, 0)
+
+		if open {
+			s.open(ctx, rate)
+		} else {
+			s.unwind(ctx, rate)
+		}
+	}()
+}
+
+// quantity returns the hedge quantity rounded by the coarser step of both markets
+func (s *Strategy) quantity(price float64) (float64, error) {
+	quantity := s.futuresMarket.RoundDownQuantity(s.spotMarket.RoundDownQuantity(s.Quantity.Float64()))
+	for _, market := range []types.Market{s.spotMarket, s.futuresMarket} {
+		if quantity <= 0 || quantity < market.MinQuantity || quantity*price < market.MinNotional {
+			return 0, fmt.Errorf("%s quantity %f is below the min quantity or the min notional of %s", s.Symbol, quantity, market.Symbol)
+		}
+	}
+
+	return quantity, nil
+}
+
+func (s *Strategy) open(ctx context.Context, rate types.FundingRate) {
+	quantity, err := s.quantity(rate.MarkPrice.Float64())
+	if err != nil {
+		s.Log.WithError(err).Error("can not open the hedged position")
+		return
+	}
+
+	s.Log.Infof("%s predicted funding rate %.4f%% is above %.4f%%, opening the hedged position of %f",
+		s.Symbol, rate.FundingRate.Float64()*100.0, s.MinFundingRate.Float64()*100.0, quantity)
+
+	spotQuantity, futuresQuantity := s.submitHedge(ctx, types.SideTypeBuy, types.SideTypeSell, quantity, quantity, false)
+	s.State.SpotQuantity += fixedpoint.NewFromFloat(spotQuantity)
+	s.State.FuturesQuantity += fixedpoint.NewFromFloat(futuresQuantity)
+	s.saveState()
+
+	s.Notify("%s funding rate %.4f%%: bought %f on %s, shorted %f on %s", s.Symbol, rate.FundingRate.Float64()*100.0,
+		spotQuantity, s.SpotSession, futuresQuantity, s.FuturesSession)
+}
+
+func (s *Strategy) unwind(ctx context.Context, rate types.FundingRate) {
+	s.Log.Infof("%s predicted funding rate %.4f%% decays below %.4f%%, unwinding the hedged position",
+		s.Symbol, rate.FundingRate.Float64()*100.0, s.ExitFundingRate.Float64()*100.0)
+
+	spotQuantity, futuresQuantity := s.submitHedge(ctx, types.SideTypeSell, types.SideTypeBuy,
+		s.State.SpotQuantity.Float64(), s.State.FuturesQuantity.Float64(), true)
+	s.State.SpotQuantity -= fixedpoint.NewFromFloat(spotQuantity)
+	s.State.FuturesQuantity -= fixedpoint.NewFromFloat(futuresQuantity)
+	s.saveState()
+
+	s.Notify("%s funding rate %.4f%%: sold %f on %s, bought back %f on %s", s.Symbol, rate.FundingRate.Float64()*100.0,
+		spotQuantity, s.SpotSession, futuresQuantity, s.FuturesSession)
+}
+
+// submitHedge submits the spot and the perpetual market orders at the same time, and returns the submitted quantities,
+// the quantity of the leg failed to submit is zero, so the failed leg is retried on the next funding rate update.
+func (s *Strategy) submitHedge(ctx context.Context, spotSide, futuresSide types.SideType, spotQuantity, futuresQuantity float64, reduceOnly bool) (float64, float64) {
+	var wg sync.WaitGroup
+	var spotSubmitted, futuresSubmitted float64
+
+	submit := func(session string, order types.SubmitOrder, submitted *float64) {
+		defer wg.Done()
+
+		if order.Quantity <= 0 {
+			return
+		}
+
+		if _, err := s.router.SubmitOrdersTo(ctx, session, order); err != nil {
+			s.Log.WithError(err).Errorf("can not submit the %s %s order to %s", order.Symbol, order.Side, session)
+			return
+		}
+
+		*submitted = order.Quantity
+	}
+
+	wg.Add(2)
+	go submit(s.SpotSession, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     spotSide,
+		Type:     types.OrderTypeMarket,
+		Market:   s.spotMarket,
+		Quantity: spotQuantity,
+	}, &spotSubmitted)
+
+	go submit(s.FuturesSession, types.SubmitOrder{
+		Symbol:     s.Symbol,
+		Side:       futuresSide,
+		Type:       types.OrderTypeMarket,
+		Market:     s.futuresMarket,
+		Quantity:   futuresQuantity,
+		ReduceOnly: reduceOnly,
+	}, &futuresSubmitted)
+
+	wg.Wait()
+
+	if spotSubmitted != futuresSubmitted {
+		s.Log.Warnf("%s hedge is unbalanced: spot %s %f, futures %s %f", s.Symbol, spotSide, spotSubmitted, futuresSide, futuresSubmitted)
+	}
+
+	return spotSubmitted, futuresSubmitted
+}
+
+func (s *Strategy) saveState() {
+	if s.Persistence == nil {
+		return
+	}
+
+	if err := s.Persistence.SaveState(s.State, "state"); err != nil {
+		s.Log.WithError(err).Errorf("can not save fundingrate state")
+	}
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, router bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	spotSession, ok := sessions[s.SpotSession]
+	if !ok {
+		return fmt.Errorf("session %s is not defined", s.SpotSession)
+	}
+
+	futuresSession, ok := sessions[s.FuturesSession]
+	if !ok {
+		return fmt.Errorf("session %s is not defined", s.FuturesSession)
+	}
+
+	if !futuresSession.Futures {
+		return fmt.Errorf("session %s is not a futures session", s.FuturesSession)
+	}
+
+	if s.spotMarket, ok = spotSession.Market(s.Symbol); !ok {
+		return fmt.Errorf("market %s is not defined on session %s", s.Symbol, s.SpotSession)
+	}
+
+	if s.futuresMarket, ok = futuresSession.Market(s.Symbol); !ok {
+		return fmt.Errorf("market %s is not defined on session %s", s.Symbol, s.FuturesSession)
+	}
+
+	futuresExchange, ok := futuresSession.Exchange.(types.FuturesExchange)
+	if !ok {
+		return fmt.Errorf("exchange %s of session %s does not support futures", futuresSession.ExchangeName, s.FuturesSession)
+	}
+
+	if s.Leverage > 0 {
+		if err := futuresExchange.SetLeverage(ctx, s.Symbol, s.Leverage); err != nil {
+			return err
+		}
+	}
+
+	s.router = router
+	s.State = &State{}
+
+	if s.Persistence != nil {
+		if err := s.Persistence.LoadState(s.State, "state"); err != nil && err != bbgo.ErrPersistenceNotExists {
+			return err
+		} else if s.State.IsOpen() {
+			s.Log.Infof("hedged position is loaded: spot %f, futures %f", s.State.SpotQuantity.Float64(), s.State.FuturesQuantity.Float64())
+		}
+	}
+
+	futuresSession.Stream.OnFundingRate(func(rate types.FundingRate) {
+		s.handleFundingRate(ctx, rate)
+	})
+
+	// check the current funding rate, the stream only pushes the updates after connected
+	rate, err := futuresExchange.QueryFundingRate(ctx, s.Symbol)
+	if err != nil {
+		s.Log.WithError(err).Warnf("can not query the funding rate of %s", s.Symbol)
+	} else {
+		s.Log.Infof("%s funding rate %.4f%%, next funding time %s", s.Symbol, rate.FundingRate.Float64()*100.0, rate.NextFundingTime)
+		s.handleFundingRate(ctx, *rate)
+	}
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		s.saveState()
+	})
+
+	return nil
+}
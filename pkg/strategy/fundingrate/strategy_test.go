@@ -0,0 +1,188 @@
+package fundingrate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testRouter records the submitted orders of each session, the orders to the sessions in errors are rejected
+type testRouter struct {
+	mu        sync.Mutex
+	submitted map[string][]types.SubmitOrder
+	errors    map[string]error
+}
+
+func (r *testRouter) SubmitOrdersTo(ctx context.Context, session string, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.errors[session]; err != nil {
+		return nil, err
+	}
+
+	for _, o := range orders {
+		r.submitted[session] = append(r.submitted[session], o)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, Status: types.OrderStatusFilled, ExecutedQuantity: o.Quantity})
+	}
+
+	return createdOrders, nil
+}
+
+func (r *testRouter) Submitted(session string) []types.SubmitOrder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]types.SubmitOrder(nil), r.submitted[session]...)
+}
+
+func newTestStrategy() (*Strategy, *testRouter) {
+	router := &testRouter{submitted: make(map[string][]types.SubmitOrder)}
+	s := &Strategy{
+		StrategyLogger:  bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:   &bbgo.Notifiability{},
+		Symbol:          "BTCUSDT",
+		SpotSession:     "spot",
+		FuturesSession:  "futures",
+		Quantity:        fixedpoint.NewFromFloat(0.12345),
+		MinFundingRate:  fixedpoint.NewFromFloat(0.0005),
+		ExitFundingRate: fixedpoint.NewFromFloat(0.0001),
+		State:           &State{},
+		router:          router,
+		spotMarket:      types.Market{Symbol: "BTCUSDT", MinQuantity: 0.0001, StepSize: 0.0001, MinNotional: 10.0},
+		futuresMarket:   types.Market{Symbol: "BTCUSDT", MinQuantity: 0.001, StepSize: 0.001, MinNotional: 5.0},
+	}
+
+	return s, router
+}
+
+func fundingRate(rate float64) types.FundingRate {
+	return types.FundingRate{
+		Symbol:      "BTCUSDT",
+		FundingRate: fixedpoint.NewFromFloat(rate),
+		MarkPrice:   fixedpoint.NewFromFloat(10000.0),
+	}
+}
+
+// waitExecution waits for the execution triggered by the funding rate to be done
+func waitExecution(t *testing.T, s *Strategy) {
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&s.executing) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s, _ := newTestStrategy()
+	assert.NoError(t, s.Validate())
+
+	s.ExitFundingRate = s.MinFundingRate
+	assert.Error(t, s.Validate())
+
+	s, _ = newTestStrategy()
+	s.MinFundingRate = 0
+	assert.Error(t, s.Validate())
+
+	s, _ = newTestStrategy()
+	s.FuturesSession = ""
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_quantity(t *testing.T) {
+	s, _ := newTestStrategy()
+
+	// rounded down by the coarser step of the futures market
+	quantity, err := s.quantity(10000.0)
+	if assert.NoError(t, err) {
+		assert.InDelta(t, 0.123, quantity, 1e-9)
+	}
+
+	// below the min notional of the spot market
+	_, err = s.quantity(50.0)
+	assert.Error(t, err)
+
+	s.Quantity = fixedpoint.NewFromFloat(0.0005)
+	_, err = s.quantity(10000.0)
+	assert.Error(t, err)
+}
+
+func TestStrategy_handleFundingRate(t *testing.T) {
+	s, router := newTestStrategy()
+	ctx := context.Background()
+
+	// below the min funding rate
+	s.handleFundingRate(ctx, fundingRate(0.0004))
+	waitExecution(t, s)
+	assert.Empty(t, router.Submitted("spot"))
+
+	// the funding rates of the other symbols are ignored
+	other := fundingRate(0.001)
+	other.Symbol = "ETHUSDT"
+	s.handleFundingRate(ctx, other)
+	waitExecution(t, s)
+	assert.Empty(t, router.Submitted("spot"))
+
+	s.handleFundingRate(ctx, fundingRate(0.0005))
+	waitExecution(t, s)
+
+	if assert.Len(t, router.Submitted("spot"), 1) && assert.Len(t, router.Submitted("futures"), 1) {
+		spotOrder, futuresOrder := router.Submitted("spot")[0], router.Submitted("futures")[0]
+		assert.Equal(t, types.SideTypeBuy, spotOrder.Side)
+		assert.Equal(t, types.OrderTypeMarket, spotOrder.Type)
+		assert.InDelta(t, 0.123, spotOrder.Quantity, 1e-9)
+		assert.Equal(t, types.SideTypeSell, futuresOrder.Side)
+		assert.InDelta(t, 0.123, futuresOrder.Quantity, 1e-9)
+		assert.False(t, futuresOrder.ReduceOnly)
+	}
+
+	assert.InDelta(t, 0.123, s.State.SpotQuantity.Float64(), 1e-9)
+	assert.InDelta(t, 0.123, s.State.FuturesQuantity.Float64(), 1e-9)
+
+	// the position is kept until the funding rate decays below the exit funding rate
+	s.handleFundingRate(ctx, fundingRate(0.0003))
+	waitExecution(t, s)
+	assert.Len(t, router.Submitted("spot"), 1)
+
+	s.handleFundingRate(ctx, fundingRate(0.0001))
+	waitExecution(t, s)
+
+	if assert.Len(t, router.Submitted("spot"), 2) && assert.Len(t, router.Submitted("futures"), 2) {
+		spotOrder, futuresOrder := router.Submitted("spot")[1], router.Submitted("futures")[1]
+		assert.Equal(t, types.SideTypeSell, spotOrder.Side)
+		assert.InDelta(t, 0.123, spotOrder.Quantity, 1e-9)
+		assert.Equal(t, types.SideTypeBuy, futuresOrder.Side)
+		assert.InDelta(t, 0.123, futuresOrder.Quantity, 1e-9)
+		assert.True(t, futuresOrder.ReduceOnly)
+	}
+
+	assert.False(t, s.State.IsOpen())
+}
+
+func TestStrategy_unwind_failedLeg(t *testing.T) {
+	s, router := newTestStrategy()
+	s.State = &State{SpotQuantity: fixedpoint.NewFromFloat(0.123), FuturesQuantity: fixedpoint.NewFromFloat(0.123)}
+	router.errors = map[string]error{"futures": errors.New("rejected")}
+
+	s.unwind(context.Background(), fundingRate(0.0))
+
+	// the failed futures leg is kept in the state and retried on the next funding rate update
+	assert.Equal(t, fixedpoint.Value(0), s.State.SpotQuantity)
+	assert.InDelta(t, 0.123, s.State.FuturesQuantity.Float64(), 1e-9)
+	assert.True(t, s.State.IsOpen())
+
+	router.errors = nil
+	s.unwind(context.Background(), fundingRate(0.0))
+
+	// the spot leg of zero quantity is not submitted again
+	assert.Len(t, router.Submitted("spot"), 1)
+	assert.Len(t, router.Submitted("futures"), 1)
+	assert.False(t, s.State.IsOpen())
+}
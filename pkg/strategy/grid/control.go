@@ -0,0 +1,242 @@
+package grid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// runtimeParameters are the grid parameters that can be updated at runtime, the keys are the same as the strategy config
+type runtimeParameters struct {
+	GridNum      *int              `json:"gridNumber"`
+	Quantity     *float64          `json:"quantity"`
+	FixedAmount  *fixedpoint.Value `json:"amount"`
+	ProfitSpread *fixedpoint.Value `json:"profitSpread"`
+}
+
+func (s *Strategy) isSuspended() bool {
+	return atomic.LoadInt32(&s.suspended) == 1
+}
+
+// Suspend stops placing the counter orders until the strategy is resumed, the grid orders are kept or canceled by the policy
+func (s *Strategy) Suspend(ctx context.Context, policy bbgo.SuspendOrderPolicy) error {
+	atomic.StoreInt32(&s.suspended, 1)
+
+	if s.isMultiSymbol() {
+		return s.eachGrid(func(grid *Strategy) error {
+			return grid.Suspend(ctx, policy)
+		})
+	}
+
+	s.suspendMutex.Lock()
+	s.suspendPolicy = policy
+	s.suspendedOrders = nil
+	s.suspendedFills = nil
+	s.suspendMutex.Unlock()
+
+	if s.session == nil {
+		return nil
+	}
+
+	switch policy {
+	case bbgo.SuspendOrderPolicyKeep:
+		s.Log.Infof("grid is suspended, keeping %d active orders", len(s.activeOrders.Orders()))
+		return nil
+
+	case bbgo.SuspendOrderPolicyCancelAndRestore:
+		s.suspendMutex.Lock()
+		s.suspendedOrders = s.activeOrders.Orders()
+		s.suspendMutex.Unlock()
+	}
+
+	s.Log.Infof("grid is suspended, canceling active orders...")
+	if err := s.cancelGridOrders(ctx, s.session); err != nil {
+		return err
+	}
+
+	s.resetState()
+	return nil
+}
+
+// addSuspendedFill records the grid order filled during the suspension, e.g., the kept orders or
+// the orders filled before they are canceled, the filled order is not restored on resume.
+func (s *Strategy) addSuspendedFill(order types.Order) {
+	s.suspendMutex.Lock()
+	defer s.suspendMutex.Unlock()
+
+	s.suspendedFills = append(s.suspendedFills, order)
+	for i, o := range s.suspendedOrders {
+		if o.OrderID == order.OrderID {
+			s.suspendedOrders = append(s.suspendedOrders[:i], s.suspendedOrders[i+1:]...)
+			break
+		}
+	}
+}
+
+// Resume places the grid orders again by the suspend policy: the kept orders stay as they are, the canceled orders are
+// restored with the cancelAndRestore policy, otherwise the grid orders are placed from the current price.
+// The counter orders of the orders filled during the suspension are placed unless the grid is placed again.
+func (s *Strategy) Resume(ctx context.Context) error {
+	atomic.StoreInt32(&s.suspended, 0)
+
+	if s.isMultiSymbol() {
+		return s.eachGrid(func(grid *Strategy) error {
+			return grid.Resume(ctx)
+		})
+	}
+
+	s.suspendMutex.Lock()
+	policy := s.suspendPolicy
+	restoreOrders := s.suspendedOrders
+	fills := s.suspendedFills
+	s.suspendedOrders = nil
+	s.suspendedFills = nil
+	s.suspendMutex.Unlock()
+
+	if s.session == nil {
+		return nil
+	}
+
+	switch {
+	case policy == bbgo.SuspendOrderPolicyKeep:
+		s.Log.Infof("grid is resumed, keeping %d active orders", len(s.activeOrders.Orders()))
+
+	case policy == bbgo.SuspendOrderPolicyCancelAndRestore && len(restoreOrders) > 0:
+		s.Log.Infof("grid is resumed, restoring %d grid orders...", len(restoreOrders))
+		s.restoreGridOrders(ctx, restoreOrders)
+
+	default:
+		s.Log.Infof("grid is resumed, placing grid orders...")
+		s.placeGridOrders(s.OrderExecutor, s.session)
+		return nil
+	}
+
+	for _, o := range fills {
+		if err := s.submitReverseOrder(o); err == bbgo.ErrWarmingUp || err == errEntryFiltered {
+			s.pendingCounterOrders = append(s.pendingCounterOrders, o)
+		}
+	}
+
+	s.saveState()
+	return nil
+}
+
+// restoreGridOrders submits the grid orders canceled by the suspension again with the same prices and quantities
+func (s *Strategy) restoreGridOrders(ctx context.Context, orders []types.Order) {
+	var submitOrders []types.SubmitOrder
+	for _, o := range orders {
+		submitOrder := o.SubmitOrder
+		submitOrder.ClientOrderID = ""
+		submitOrder.Market = s.Market
+		submitOrders = append(submitOrders, submitOrder)
+	}
+
+	createdOrders, err := s.OrderExecutor.SubmitOrders(ctx, submitOrders...)
+	if err != nil {
+		var batchErr *bbgo.BatchSubmitError
+		if !errors.As(err, &batchErr) {
+			s.Log.WithError(err).Errorf("can not restore the grid orders")
+			return
+		}
+
+		for _, r := range batchErr.Failed() {
+			s.Log.WithError(r.Error).Errorf("can not restore the grid order %s", r.SubmitOrder.String())
+		}
+	}
+
+	s.orderStore.Add(createdOrders...)
+	s.activeOrders.Add(createdOrders...)
+}
+
+// UpdateParameters updates the grid number, the order quantity (or amount) and the profit spread,
+// the grid orders are replaced with the new parameters unless the grid is suspended.
+// In the multi-symbol mode, the parameters are applied to the grids of all symbols.
+func (s *Strategy) UpdateParameters(ctx context.Context, data []byte) error {
+	var params runtimeParameters
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+
+	if s.isMultiSymbol() {
+		return s.eachGrid(func(grid *Strategy) error {
+			return grid.updateParameters(ctx, params)
+		})
+	}
+
+	return s.updateParameters(ctx, params)
+}
+
+// ReloadConfig applies the runtime parameters present in the changed strategy config, the omitted parameters are kept
+// as they are. The other fields require a restart.
+func (s *Strategy) ReloadConfig(ctx context.Context, config []byte) error {
+	if s.isMultiSymbol() {
+		return s.reloadGrids(ctx, config)
+	}
+
+	var params runtimeParameters
+	if err := json.Unmarshal(config, &params); err != nil {
+		return err
+	}
+
+	return s.updateParameters(ctx, params)
+}
+
+func (s *Strategy) updateParameters(ctx context.Context, params runtimeParameters) error {
+	s.parameterMutex.Lock()
+	defer s.parameterMutex.Unlock()
+
+	if params.GridNum != nil && *params.GridNum <= 0 {
+		return fmt.Errorf("gridNumber should be greater than 0, got %d", *params.GridNum)
+	}
+
+	if params.Quantity != nil && *params.Quantity < 0 {
+		return fmt.Errorf("quantity should not be negative, got %f", *params.Quantity)
+	}
+
+	if params.FixedAmount != nil && *params.FixedAmount < 0 {
+		return fmt.Errorf("amount should not be negative, got %f", params.FixedAmount.Float64())
+	}
+
+	changed := false
+
+	if params.GridNum != nil && *params.GridNum != s.GridNum {
+		s.GridNum = *params.GridNum
+		changed = true
+	}
+
+	if params.Quantity != nil && *params.Quantity != s.Quantity {
+		s.Quantity = *params.Quantity
+		changed = true
+	}
+
+	if params.FixedAmount != nil && *params.FixedAmount != s.FixedAmount {
+		s.FixedAmount = *params.FixedAmount
+		changed = true
+	}
+
+	if params.ProfitSpread != nil && *params.ProfitSpread != s.ProfitSpread {
+		s.ProfitSpread = *params.ProfitSpread
+		changed = true
+	}
+
+	if !changed || s.session == nil || s.isSuspended() {
+		return nil
+	}
+
+	s.Log.Infof("grid parameters are updated, replacing grid orders...")
+	s.checkFeeSpread()
+
+	if err := s.cancelGridOrders(ctx, s.session); err != nil {
+		return err
+	}
+
+	s.resetState()
+	s.placeGridOrders(s.OrderExecutor, s.session)
+	return nil
+}
@@ -0,0 +1,58 @@
+package grid
+
+// minProfitSpread returns the min spread of the counter order to cover the maker fees of the grid order and the counter order,
+// buying at price and selling at price + spread is net-positive when spread > 2 * price * feeRate / (1 - feeRate),
+// which also covers selling at price and buying back at price - spread.
+func minProfitSpread(price, feeRate float64) float64 {
+	if feeRate <= 0 || feeRate >= 1 {
+		return 0
+	}
+
+	return 2 * price * feeRate / (1 - feeRate)
+}
+
+// configuredSpread returns the profit spread, or the grid size if the profit spread is not set
+func (s *Strategy) configuredSpread() float64 {
+	if s.ProfitSpread > 0 {
+		return s.ProfitSpread.Float64()
+	}
+
+	return s.gridSize().Float64()
+}
+
+// makerFeeRate returns the maker fee rate of the symbol, e.g., the VIP tier rate queried from the exchange
+func (s *Strategy) makerFeeRate() float64 {
+	return s.session.TradingFeeRate(s.Symbol).MakerFeeRate.Float64()
+}
+
+// profitSpread returns the spread of the counter order of the order price,
+// the spread is widened to cover the round trip fees in the fee-aware mode.
+func (s *Strategy) profitSpread(price float64) float64 {
+	spread := s.configuredSpread()
+	if s.FeeAware {
+		if minSpread := minProfitSpread(price, s.makerFeeRate()); spread < minSpread {
+			spread = minSpread
+		}
+	}
+
+	return spread
+}
+
+// checkFeeSpread warns when the spread of the grid round trips at the upper price can not cover the round trip fees
+func (s *Strategy) checkFeeSpread() {
+	spread := s.configuredSpread()
+	feeRate := s.makerFeeRate()
+	minSpread := minProfitSpread(s.UpperPrice.Float64(), feeRate)
+	if spread > minSpread {
+		return
+	}
+
+	if s.FeeAware {
+		s.Log.Infof("%s grid spread %f is widened to cover the round trip fee (maker fee rate %f), the min spread at the upper price is %f",
+			s.Symbol, spread, feeRate, minSpread)
+		return
+	}
+
+	s.Log.Warnf("%s grid spread %f can not cover the round trip fee (maker fee rate %f), the min spread at the upper price is %f, the grid round trips lose money after the fees, please enable feeAware or widen the spread",
+		s.Symbol, spread, feeRate, minSpread)
+}
@@ -0,0 +1,255 @@
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// errEntryFiltered is returned when the counter order is blocked by the entry filters, the order is retried on the next kline
+var errEntryFiltered = errors.New("the order is blocked by the entry filters")
+
+// EntryFilterType is the trend signal of the entry filter
+type EntryFilterType string
+
+const (
+	// EntryFilterEMA compares the price with the exponential moving average
+	EntryFilterEMA = EntryFilterType("ema")
+
+	// EntryFilterSMA compares the price with the simple moving average
+	EntryFilterSMA = EntryFilterType("sma")
+
+	// EntryFilterIndicator compares the price with the custom indicator registered by indicator.Register
+	EntryFilterIndicator = EntryFilterType("indicator")
+
+	// EntryFilterSignal matches the last signal published on the message bus
+	EntryFilterSignal = EntryFilterType("signal")
+)
+
+// EntryFilter allows the grid orders of the side only when the trend condition holds, e.g., place the bids only when
+// the price is above EMA200. An order is placed only if all the filters of its side allow it, the resting orders are kept.
+type EntryFilter struct {
+	Type EntryFilterType `json:"type"`
+
+	// Side is the grid side gated by the filter, "buy", "sell" or "both", defaults to both
+	Side string `json:"side,omitempty"`
+
+	// Interval and Window are the interval window of the moving average or the custom indicator
+	Interval types.Interval `json:"interval,omitempty"`
+	Window   int            `json:"window,omitempty"`
+
+	// Indicator is the name of the custom indicator of the indicator filter
+	Indicator string `json:"indicator,omitempty"`
+
+	// When is "above" or "below", the orders are allowed when the price is above or below the indicator value
+	When string `json:"when,omitempty"`
+
+	// Offset shifts the indicator value by the ratio, e.g., "above" with -0.05 allows the orders until the price
+	// drops 5% below the indicator, so only the strong downtrend is filtered
+	Offset fixedpoint.Value `json:"offset,omitempty"`
+
+	// Signal is the condition of the signal filter, the orders are allowed while the last signal of the topic matches
+	Signal *SignalCondition `json:"signal,omitempty"`
+}
+
+func (f *EntryFilter) Validate() error {
+	switch f.Type {
+	case EntryFilterEMA, EntryFilterSMA, EntryFilterIndicator:
+		if len(f.Interval) == 0 || f.Window <= 0 {
+			return fmt.Errorf("%s entry filter requires the interval and the window", f.Type)
+		}
+
+		if f.Type == EntryFilterIndicator && len(f.Indicator) == 0 {
+			return errors.New("indicator entry filter requires the indicator name")
+		}
+
+		if f.When != "above" && f.When != "below" {
+			return fmt.Errorf("%s entry filter condition should be above or below, got %q", f.Type, f.When)
+		}
+
+	case EntryFilterSignal:
+		if f.Signal == nil || len(f.Signal.Topic) == 0 {
+			return errors.New("signal entry filter requires the signal topic")
+		}
+
+	default:
+		return fmt.Errorf("unsupported entry filter type %q", f.Type)
+	}
+
+	switch strings.ToLower(f.Side) {
+	case "", "both", "buy", "sell":
+	default:
+		return fmt.Errorf("entry filter side should be buy, sell or both, got %q", f.Side)
+	}
+
+	return nil
+}
+
+// Gates returns true if the filter gates the orders of the side
+func (f *EntryFilter) Gates(side types.SideType) bool {
+	switch strings.ToLower(f.Side) {
+	case "buy":
+		return side == types.SideTypeBuy
+	case "sell":
+		return side == types.SideTypeSell
+	}
+
+	return true
+}
+
+// String describes the trend condition of the filter, e.g., "above ema(1h, 200)"
+func (f *EntryFilter) String() string {
+	switch f.Type {
+	case EntryFilterSignal:
+		return fmt.Sprintf("signal %s=%s", f.Signal.Topic, f.Signal.Value)
+	case EntryFilterIndicator:
+		return fmt.Sprintf("%s %s(%s, %d)", f.When, f.Indicator, f.Interval, f.Window)
+	}
+
+	return fmt.Sprintf("%s %s(%s, %d)", f.When, f.Type, f.Interval, f.Window)
+}
+
+// subscribeEntryFilters subscribes the klines of the intervals used by the entry filters
+func (s *Strategy) subscribeEntryFilters(session *bbgo.ExchangeSession) {
+	for _, f := range s.EntryFilters {
+		if len(f.Interval) > 0 {
+			session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: string(f.Interval)})
+		}
+	}
+}
+
+// entryFilterValue returns the indicator value of the filter, ok is false if the indicator is not ready
+func (s *Strategy) entryFilterValue(f *EntryFilter) (float64, bool) {
+	set, ok := s.session.StandardIndicatorSet(s.Symbol)
+	if !ok {
+		return 0, false
+	}
+
+	iw := types.IntervalWindow{Interval: f.Interval, Window: f.Window}
+	switch f.Type {
+	case EntryFilterSMA:
+		return set.SMA(iw).Last()
+
+	case EntryFilterIndicator:
+		inc, err := set.Indicator(f.Indicator, iw)
+		if err != nil {
+			s.Log.WithError(err).Errorf("can not create the indicator of the entry filter")
+			return 0, false
+		}
+
+		return inc.Last()
+	}
+
+	return set.EWMA(iw).Last()
+}
+
+// entryBlockReason evaluates the entry filters of the side with the price,
+// it returns the reason if the orders of the side are blocked, or an empty string if they are allowed.
+// The orders are blocked while the indicator is not ready, since the trend is unknown.
+func (s *Strategy) entryBlockReason(side types.SideType, price float64) string {
+	for i := range s.EntryFilters {
+		f := &s.EntryFilters[i]
+		if !f.Gates(side) {
+			continue
+		}
+
+		if f.Type == EntryFilterSignal {
+			if s.MessageBus == nil {
+				return "the message bus is not available"
+			}
+
+			signal, ok := s.MessageBus.Last(f.Signal.Topic)
+			if !ok || !f.Signal.Match(signal) {
+				return fmt.Sprintf("the last signal does not match %s", f.String())
+			}
+
+			continue
+		}
+
+		value, ok := s.entryFilterValue(f)
+		if !ok {
+			return fmt.Sprintf("the indicator of %s is not ready", f.String())
+		}
+
+		threshold := value * (1.0 + f.Offset.Float64())
+		if (f.When == "above" && price <= threshold) || (f.When == "below" && price >= threshold) {
+			return fmt.Sprintf("price %f is not %s (%f)", price, f.String(), threshold)
+		}
+	}
+
+	return ""
+}
+
+// allowEntry returns false if the orders of the side are blocked by the entry filters at the last price,
+// the blocked side is recorded, so that its grid orders are placed when the filters allow it again.
+func (s *Strategy) allowEntry(side types.SideType) bool {
+	if len(s.EntryFilters) == 0 {
+		return true
+	}
+
+	price, ok := s.session.LastPrice(s.Symbol)
+	if !ok {
+		return false
+	}
+
+	reason := s.entryBlockReason(side, price)
+
+	s.entryMutex.Lock()
+	s.entryBlocked[side] = len(reason) > 0
+	s.entryMutex.Unlock()
+
+	if len(reason) > 0 {
+		s.Log.Infof("%s %s orders are blocked by the entry filters: %s", s.Symbol, side, reason)
+		return false
+	}
+
+	return true
+}
+
+// updateEntryFilters evaluates the entry filters on the closed kline, the grid orders of the side are placed when
+// the blocked side is allowed again and there is no active order of the side,
+// e.g., the bids blocked at the start are placed after the price crosses above the EMA.
+func (s *Strategy) updateEntryFilters(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
+	if len(s.EntryFilters) == 0 || s.isSuspended() || s.isPaused() {
+		return
+	}
+
+	price, ok := session.LastPrice(s.Symbol)
+	if !ok {
+		return
+	}
+
+	for _, side := range []types.SideType{types.SideTypeBuy, types.SideTypeSell} {
+		reason := s.entryBlockReason(side, price)
+		blocked := len(reason) > 0
+
+		s.entryMutex.Lock()
+		changed := s.entryBlocked[side] != blocked
+		s.entryBlocked[side] = blocked
+		s.entryMutex.Unlock()
+
+		if !changed {
+			continue
+		}
+
+		if blocked {
+			s.Log.Infof("%s %s orders are blocked by the entry filters: %s", s.Symbol, side, reason)
+			continue
+		}
+
+		s.Log.Infof("%s %s orders are allowed by the entry filters", s.Symbol, side)
+
+		numOfOrders := s.activeOrders.NumOfBids()
+		if side == types.SideTypeSell {
+			numOfOrders = s.activeOrders.NumOfAsks()
+		}
+
+		if numOfOrders == 0 {
+			s.placeGridOrdersOf(orderExecutor, session, side == types.SideTypeBuy, side == types.SideTypeSell, nil)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package grid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEntryFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  EntryFilter
+		wantErr bool
+	}{
+		{
+			name:   "ema",
+			filter: EntryFilter{Type: EntryFilterEMA, Interval: types.Interval1h, Window: 200, When: "above"},
+		},
+		{
+			name:    "ema without window",
+			filter:  EntryFilter{Type: EntryFilterEMA, Interval: types.Interval1h, When: "above"},
+			wantErr: true,
+		},
+		{
+			name:    "sma without condition",
+			filter:  EntryFilter{Type: EntryFilterSMA, Interval: types.Interval1h, Window: 99},
+			wantErr: true,
+		},
+		{
+			name:    "indicator without name",
+			filter:  EntryFilter{Type: EntryFilterIndicator, Interval: types.Interval1h, Window: 14, When: "below"},
+			wantErr: true,
+		},
+		{
+			name:   "signal",
+			filter: EntryFilter{Type: EntryFilterSignal, Side: "buy", Signal: &SignalCondition{Topic: "trend", Value: "up"}},
+		},
+		{
+			name:    "signal without topic",
+			filter:  EntryFilter{Type: EntryFilterSignal, Signal: &SignalCondition{}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid side",
+			filter:  EntryFilter{Type: EntryFilterEMA, Interval: types.Interval1h, Window: 200, When: "above", Side: "long"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			filter:  EntryFilter{Type: "macd"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEntryFilter_Gates(t *testing.T) {
+	both := EntryFilter{}
+	assert.True(t, both.Gates(types.SideTypeBuy))
+	assert.True(t, both.Gates(types.SideTypeSell))
+
+	buy := EntryFilter{Side: "BUY"}
+	assert.True(t, buy.Gates(types.SideTypeBuy))
+	assert.False(t, buy.Gates(types.SideTypeSell))
+
+	sell := EntryFilter{Side: "sell"}
+	assert.False(t, sell.Gates(types.SideTypeBuy))
+	assert.True(t, sell.Gates(types.SideTypeSell))
+}
+
+func TestStrategy_entryBlockReason(t *testing.T) {
+	trendFilter := EntryFilter{Type: EntryFilterSignal, Side: "buy", Signal: &SignalCondition{Topic: "trend", Value: "up"}}
+
+	t.Run("no message bus", func(t *testing.T) {
+		s := newTestStrategy(&testOrderExecutor{})
+		s.EntryFilters = []EntryFilter{trendFilter}
+
+		assert.NotEmpty(t, s.entryBlockReason(types.SideTypeBuy, 9500.0))
+		assert.Empty(t, s.entryBlockReason(types.SideTypeSell, 9500.0))
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		s := newTestStrategy(&testOrderExecutor{})
+		s.EntryFilters = []EntryFilter{trendFilter}
+		s.MessageBus = bbgo.NewMessageBus()
+
+		// no signal is published yet
+		assert.NotEmpty(t, s.entryBlockReason(types.SideTypeBuy, 9500.0))
+
+		s.MessageBus.Publish("trend", "test", "down")
+		assert.NotEmpty(t, s.entryBlockReason(types.SideTypeBuy, 9500.0))
+
+		s.MessageBus.Publish("trend", "test", "up")
+		assert.Empty(t, s.entryBlockReason(types.SideTypeBuy, 9500.0))
+	})
+
+	t.Run("indicator not ready", func(t *testing.T) {
+		s := newTestStrategy(&testOrderExecutor{})
+		s.EntryFilters = []EntryFilter{{Type: EntryFilterEMA, Interval: types.Interval1h, Window: 200, When: "above"}}
+		s.session = newTestSession(t, 9500.0)
+
+		// the trend is unknown before the indicator is ready, so both sides are blocked
+		assert.NotEmpty(t, s.entryBlockReason(types.SideTypeBuy, 9500.0))
+		assert.NotEmpty(t, s.entryBlockReason(types.SideTypeSell, 9500.0))
+	})
+}
+
+func TestStrategy_allowEntry(t *testing.T) {
+	s := newTestStrategy(&testOrderExecutor{})
+	s.session = newTestSession(t, 9500.0)
+	s.MessageBus = bbgo.NewMessageBus()
+	s.EntryFilters = []EntryFilter{
+		{Type: EntryFilterSignal, Side: "buy", Signal: &SignalCondition{Topic: "trend", Value: "up"}},
+	}
+
+	assert.False(t, s.allowEntry(types.SideTypeBuy))
+	assert.True(t, s.entryBlocked[types.SideTypeBuy])
+	assert.True(t, s.allowEntry(types.SideTypeSell))
+
+	// the blocked side is recorded as allowed once the signal matches
+	s.MessageBus.Publish("trend", "test", "up")
+	assert.True(t, s.allowEntry(types.SideTypeBuy))
+	assert.False(t, s.entryBlocked[types.SideTypeBuy])
+}
@@ -0,0 +1,46 @@
+package grid
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// generateGroupID generates the order group ID of the grid from the strategy ID and the symbol,
+// so that the same grid gets the same group ID after restarting.
+// The group ID is kept in the uint32 range since MAX uses 32-bit group IDs.
+func generateGroupID(s string) int64 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return int64(h.Sum32())
+}
+
+// cancelGridOrders cancels the whole grid with the group ID if the exchange supports it, otherwise the active orders are canceled one by one
+func (s *Strategy) cancelGridOrders(ctx context.Context, session *bbgo.ExchangeSession) error {
+	if canceler, ok := session.Exchange.(types.GroupOrderCanceler); ok && s.groupID > 0 {
+		canceledOrders, err := canceler.CancelOrdersByGroupID(ctx, s.groupID)
+		if err == nil {
+			s.Log.Infof("canceled %d grid orders of group %d", len(canceledOrders), s.groupID)
+
+			// the orders not in the group, e.g., the orders resumed from the state saved before the group ID was introduced
+			var rest []types.Order
+			for _, o := range s.activeOrders.Orders() {
+				if o.GroupID != s.groupID {
+					rest = append(rest, o)
+				}
+			}
+
+			if len(rest) == 0 {
+				return nil
+			}
+
+			return session.Exchange.CancelOrders(ctx, rest...)
+		}
+
+		s.Log.WithError(err).Errorf("group order cancel error, canceling the orders one by one")
+	}
+
+	return session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...)
+}
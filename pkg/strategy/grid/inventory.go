@@ -0,0 +1,98 @@
+package grid
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// InventoryTarget keeps the base inventory around the target quantity,
+// the grid order quantities are biased to mean-revert the inventory when the fills push it away from the target.
+type InventoryTarget struct {
+	// Quantity is the target base asset quantity
+	Quantity fixedpoint.Value `json:"quantity" yaml:"quantity"`
+
+	// Skew is the sensitivity of the quantity bias, with skew 1.0 and 50% inventory drift,
+	// the buy quantity is reduced by 50% and the sell quantity is increased by 50%. defaults to 1.0
+	Skew fixedpoint.Value `json:"skew,omitempty" yaml:"skew,omitempty"`
+
+	// ReportInterval is the interval of the inventory drift report, defaults to 1 hour
+	ReportInterval types.Duration `json:"reportInterval,omitempty" yaml:"reportInterval,omitempty"`
+}
+
+// inventory returns the current base inventory (available + locked) of the session account
+func (s *Strategy) inventory(session *bbgo.ExchangeSession) fixedpoint.Value {
+	balance, ok := session.Account.Balance(s.Market.BaseCurrency)
+	if !ok {
+		return 0
+	}
+
+	return balance.Available + balance.Locked
+}
+
+// inventoryDrift returns the ratio of the inventory difference to the target,
+// positive drift means we hold more base asset than the target.
+func (s *Strategy) inventoryDrift(session *bbgo.ExchangeSession) float64 {
+	if s.InventoryTarget == nil || s.InventoryTarget.Quantity <= 0 {
+		return 0
+	}
+
+	target := s.InventoryTarget.Quantity.Float64()
+	return (s.inventory(session).Float64() - target) / target
+}
+
+// adjustQuantityByInventory biases the order quantity to mean-revert the inventory to the target
+func (s *Strategy) adjustQuantityByInventory(session *bbgo.ExchangeSession, side types.SideType, quantity float64) float64 {
+	if s.InventorySkew != nil {
+		if price, ok := session.LastPrice(s.Symbol); ok {
+			quantity = s.InventorySkew.QuoteOf(s.Market, session.Account.Balances(), price).Quantity(side, quantity)
+		}
+	}
+
+	if s.InventoryTarget == nil {
+		return quantity
+	}
+
+	skew := 1.0
+	if s.InventoryTarget.Skew > 0 {
+		skew = s.InventoryTarget.Skew.Float64()
+	}
+
+	// keep the adjusted quantity within [0, 2] of the original quantity
+	bias := math.Max(-1.0, math.Min(1.0, skew*s.inventoryDrift(session)))
+	switch side {
+	case types.SideTypeBuy:
+		quantity *= 1.0 - bias
+	case types.SideTypeSell:
+		quantity *= 1.0 + bias
+	}
+
+	return quantity
+}
+
+func (s *Strategy) reportInventoryDrift(ctx context.Context, session *bbgo.ExchangeSession) {
+	interval := time.Hour
+	if s.InventoryTarget.ReportInterval > 0 {
+		interval = s.InventoryTarget.ReportInterval.Duration()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			inventory := s.inventory(session)
+			drift := s.inventoryDrift(session)
+			s.Log.Infof("%s inventory %f, target %f, drift %.2f%%", s.Symbol, inventory.Float64(), s.InventoryTarget.Quantity.Float64(), drift*100.0)
+			s.Notify("%s grid inventory %f %s, target %f, drift %.2f%%", s.Symbol, inventory.Float64(), s.Market.BaseCurrency, s.InventoryTarget.Quantity.Float64(), drift*100.0)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package grid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStrategy_adjustQuantityByInventory(t *testing.T) {
+	session := bbgo.NewExchangeSession("binance", mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil))
+
+	s := newTestStrategy(&testOrderExecutor{})
+	s.InventoryTarget = &InventoryTarget{Quantity: fixedpoint.NewFromFloat(1.0)}
+
+	tests := []struct {
+		name      string
+		available float64
+		locked    float64
+		skew      float64
+		drift     float64
+		buy       float64
+		sell      float64
+	}{
+		{name: "on target", available: 0.8, locked: 0.2, drift: 0.0, buy: 0.1, sell: 0.1},
+		{name: "above target", available: 1.2, locked: 0.3, drift: 0.5, buy: 0.05, sell: 0.15},
+		{name: "below target", available: 0.5, drift: -0.5, buy: 0.15, sell: 0.05},
+		{name: "bias is capped", available: 1.5, skew: 4.0, drift: 0.5, buy: 0.0, sell: 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session.Account.UpdateBalances(types.BalanceMap{
+				"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(tt.available), Locked: fixedpoint.NewFromFloat(tt.locked)},
+			})
+			s.InventoryTarget.Skew = fixedpoint.NewFromFloat(tt.skew)
+
+			assert.InDelta(t, tt.drift, s.inventoryDrift(session), 1e-8)
+			assert.InDelta(t, tt.buy, s.adjustQuantityByInventory(session, types.SideTypeBuy, 0.1), 1e-8)
+			assert.InDelta(t, tt.sell, s.adjustQuantityByInventory(session, types.SideTypeSell, 0.1), 1e-8)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package grid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// GridSymbol is the grid settings of a symbol in the multi-symbol mode, the zero settings inherit the settings of the strategy
+type GridSymbol struct {
+	Symbol string `json:"symbol" yaml:"symbol"`
+
+	UpperPrice   fixedpoint.Value `json:"upperPrice,omitempty" yaml:"upperPrice,omitempty"`
+	LowerPrice   fixedpoint.Value `json:"lowerPrice,omitempty" yaml:"lowerPrice,omitempty"`
+	GridNum      int              `json:"gridNumber,omitempty" yaml:"gridNumber,omitempty"`
+	Quantity     float64          `json:"quantity,omitempty" yaml:"quantity,omitempty"`
+	FixedAmount  fixedpoint.Value `json:"amount,omitempty" yaml:"amount,omitempty"`
+	ProfitSpread fixedpoint.Value `json:"profitSpread,omitempty" yaml:"profitSpread,omitempty"`
+
+	// Weight is the share of the quote currency budget allocated to the grid of the symbol, defaults to 1
+	Weight float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+func (s *Strategy) isMultiSymbol() bool {
+	return len(s.Symbols) > 0
+}
+
+// newGrid creates the grid strategy of the symbol from the settings of this strategy and the per-symbol settings
+func (s *Strategy) newGrid(gs GridSymbol) (*Strategy, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var grid Strategy
+	if err := json.Unmarshal(data, &grid); err != nil {
+		return nil, err
+	}
+
+	grid.Symbols = nil
+	grid.Budget = nil
+	grid.Symbol = gs.Symbol
+
+	// the logger is not serialized, the grid logs with the logger of the parent instance
+	if s.Log != nil {
+		grid.Log = s.Log.WithField("symbol", gs.Symbol)
+	}
+
+	if gs.UpperPrice > 0 {
+		grid.UpperPrice = gs.UpperPrice
+	}
+
+	if gs.LowerPrice > 0 {
+		grid.LowerPrice = gs.LowerPrice
+	}
+
+	if gs.GridNum > 0 {
+		grid.GridNum = gs.GridNum
+	}
+
+	// the quantity and the amount are exclusive, the per-symbol setting overrides both
+	if gs.Quantity > 0 {
+		grid.Quantity = gs.Quantity
+		grid.FixedAmount = 0
+	} else if gs.FixedAmount > 0 {
+		grid.FixedAmount = gs.FixedAmount
+		grid.Quantity = 0
+	}
+
+	if gs.ProfitSpread > 0 {
+		grid.ProfitSpread = gs.ProfitSpread
+	}
+
+	return &grid, nil
+}
+
+// initGrids creates the grids of the symbols, it's called by both Subscribe and Run
+func (s *Strategy) initGrids() error {
+	if s.grids != nil {
+		return nil
+	}
+
+	var grids []*Strategy
+	var symbols = make(map[string]struct{})
+	for _, gs := range s.Symbols {
+		if _, ok := symbols[gs.Symbol]; ok {
+			return fmt.Errorf("duplicated grid symbol %s", gs.Symbol)
+		}
+		symbols[gs.Symbol] = struct{}{}
+
+		grid, err := s.newGrid(gs)
+		if err != nil {
+			return err
+		}
+
+		grids = append(grids, grid)
+	}
+
+	s.grids = grids
+	return nil
+}
+
+// runGrids runs the grid of each symbol with the budget allocated by the symbol weight,
+// the budget of a quote currency defaults to the available balance of the session.
+func (s *Strategy) runGrids(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	if err := s.initGrids(); err != nil {
+		return err
+	}
+
+	s.session = session
+
+	allocator := bbgo.NewCapitalAllocator()
+	for i, grid := range s.grids {
+		market, ok := session.Market(grid.Symbol)
+		if !ok {
+			return fmt.Errorf("market %s not found", grid.Symbol)
+		}
+
+		grid.Market = market
+		allocator.Register(market.QuoteCurrency, grid.Symbol, s.Symbols[i].Weight)
+
+		if _, ok := allocator.Budget(market.QuoteCurrency); ok {
+			continue
+		}
+
+		budget, ok := s.Budget[market.QuoteCurrency]
+		if !ok {
+			if balance, ok := session.Account.Balance(market.QuoteCurrency); ok {
+				budget = balance.Available
+			}
+		}
+
+		allocator.SetBudget(market.QuoteCurrency, budget)
+	}
+
+	for _, grid := range s.grids {
+		grid.Notifiability = s.Notifiability
+		grid.MessageBus = s.MessageBus
+		grid.OrderExecutor = orderExecutor
+		grid.quoteBudget = allocator.Allocation(grid.Market.QuoteCurrency, grid.Symbol)
+
+		if s.Persistence != nil {
+			grid.Persistence = s.Persistence.WithSymbol(grid.Symbol)
+		}
+
+		if position, ok := session.Position(grid.Symbol); ok {
+			grid.Position = position
+		}
+
+		if profitStats, ok := session.ProfitStats(grid.Symbol); ok {
+			grid.ProfitStats = profitStats
+		}
+
+		s.Log.Infof("running %s grid with the budget %f %s", grid.Symbol, grid.quoteBudget.Float64(), grid.Market.QuoteCurrency)
+
+		if err := grid.Run(ctx, orderExecutor, session); err != nil {
+			return fmt.Errorf("%s grid error: %w", grid.Symbol, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) gridsStatus() string {
+	var lines []string
+	for _, grid := range s.grids {
+		lines = append(lines, grid.Status())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (s *Strategy) shutdownGrids(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(len(s.grids))
+
+	for _, grid := range s.grids {
+		grid.Shutdown(ctx, &wg)
+	}
+
+	wg.Wait()
+}
+
+// eachGrid calls the function with the grid of each symbol, the errors are combined
+func (s *Strategy) eachGrid(fn func(grid *Strategy) error) error {
+	var errs []string
+	for _, grid := range s.grids {
+		if err := fn(grid); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", grid.Symbol, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("grid errors: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// reloadGrids applies the runtime parameters present in the new config to the grids, the changed strategy parameters
+// are applied to the grids inheriting them and the changed per-symbol parameters are applied to the grid of the symbol.
+// Adding or removing the symbols requires a restart.
+func (s *Strategy) reloadGrids(ctx context.Context, config []byte) error {
+	var params runtimeParameters
+	if err := json.Unmarshal(config, &params); err != nil {
+		return err
+	}
+
+	var conf struct {
+		Symbols []GridSymbol `json:"symbols"`
+	}
+	if err := json.Unmarshal(config, &conf); err != nil {
+		return err
+	}
+
+	s.parameterMutex.Lock()
+	oldSymbols := s.Symbols
+	newSymbols := s.Symbols
+	if conf.Symbols != nil {
+		newSymbols = conf.Symbols
+	}
+
+	if len(newSymbols) != len(s.grids) {
+		s.parameterMutex.Unlock()
+		return fmt.Errorf("the grid symbols are changed, please restart bbgo to apply it")
+	}
+
+	if params.GridNum != nil {
+		s.GridNum = *params.GridNum
+	}
+
+	if params.Quantity != nil {
+		s.Quantity = *params.Quantity
+	}
+
+	if params.FixedAmount != nil {
+		s.FixedAmount = *params.FixedAmount
+	}
+
+	if params.ProfitSpread != nil {
+		s.ProfitSpread = *params.ProfitSpread
+	}
+
+	s.Symbols = newSymbols
+	s.parameterMutex.Unlock()
+
+	return s.eachGrid(func(grid *Strategy) error {
+		oldSymbol, ok := findGridSymbol(oldSymbols, grid.Symbol)
+		if !ok {
+			return fmt.Errorf("the grid symbols are changed, please restart bbgo to apply it")
+		}
+
+		newSymbol, ok := findGridSymbol(newSymbols, grid.Symbol)
+		if !ok {
+			return fmt.Errorf("the grid symbols are changed, please restart bbgo to apply it")
+		}
+
+		return grid.updateParameters(ctx, s.symbolParameters(params, oldSymbol, newSymbol))
+	})
+}
+
+func findGridSymbol(symbols []GridSymbol, symbol string) (GridSymbol, bool) {
+	for _, gs := range symbols {
+		if gs.Symbol == symbol {
+			return gs, true
+		}
+	}
+
+	return GridSymbol{}, false
+}
+
+// symbolParameters returns the runtime parameters to apply to the grid of the symbol, a parameter is included
+// when its per-symbol setting is changed, or when the grid inherits the parameter and the strategy parameter is present.
+func (s *Strategy) symbolParameters(params runtimeParameters, oldSymbol, newSymbol GridSymbol) runtimeParameters {
+	var p runtimeParameters
+
+	switch {
+	case newSymbol.GridNum > 0:
+		if newSymbol.GridNum != oldSymbol.GridNum {
+			p.GridNum = &newSymbol.GridNum
+		}
+
+	case params.GridNum != nil || oldSymbol.GridNum > 0:
+		gridNum := s.GridNum
+		p.GridNum = &gridNum
+	}
+
+	// the quantity and the amount are exclusive, the per-symbol setting overrides both
+	switch {
+	case newSymbol.Quantity > 0 || newSymbol.FixedAmount > 0:
+		if newSymbol.Quantity != oldSymbol.Quantity || newSymbol.FixedAmount != oldSymbol.FixedAmount {
+			quantity, amount := newSymbol.Quantity, fixedpoint.Value(0)
+			if quantity == 0 {
+				amount = newSymbol.FixedAmount
+			}
+
+			p.Quantity = &quantity
+			p.FixedAmount = &amount
+		}
+
+	case params.Quantity != nil || params.FixedAmount != nil || oldSymbol.Quantity > 0 || oldSymbol.FixedAmount > 0:
+		quantity, amount := s.Quantity, s.FixedAmount
+		p.Quantity = &quantity
+		p.FixedAmount = &amount
+	}
+
+	switch {
+	case newSymbol.ProfitSpread > 0:
+		if newSymbol.ProfitSpread != oldSymbol.ProfitSpread {
+			p.ProfitSpread = &newSymbol.ProfitSpread
+		}
+
+	case params.ProfitSpread != nil || oldSymbol.ProfitSpread > 0:
+		profitSpread := s.ProfitSpread
+		p.ProfitSpread = &profitSpread
+	}
+
+	return p
+}
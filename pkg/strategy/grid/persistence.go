@@ -0,0 +1,151 @@
+package grid
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// State is the persisted state of the grid strategy
+type State struct {
+	// Orders are the active orders placed by the grid
+	Orders []types.Order `json:"orders"`
+
+	// AccumulationOrders are the active buy orders placed by the accumulated profit
+	AccumulationOrders []types.Order `json:"accumulationOrders,omitempty"`
+
+	// RoundTripPrices are the buy prices of the active counter sell orders in the base accumulation mode
+	RoundTripPrices map[uint64]float64 `json:"roundTripPrices,omitempty"`
+
+	// Position is the position of the grid orders
+	Position *bbgo.Position `json:"position,omitempty"`
+
+	// UpdateTime is the time the state was saved, the trades executed after it are checked for the missed fills
+	UpdateTime time.Time `json:"updateTime,omitempty"`
+
+	// UpperPrice and LowerPrice are the band of the orders, they differ from the config after the grid is re-centered
+	UpperPrice fixedpoint.Value `json:"upperPrice,omitempty"`
+	LowerPrice fixedpoint.Value `json:"lowerPrice,omitempty"`
+
+	// PendingCounterOrders are the filled orders whose counter orders are not placed yet,
+	// they are rejected by the warm-up gate or blocked by the entry filters
+	PendingCounterOrders []types.Order `json:"pendingCounterOrders,omitempty"`
+}
+
+func (s *Strategy) saveState() {
+	if s.Persistence == nil {
+		return
+	}
+
+	state := State{
+		Orders:               s.activeOrders.Orders(),
+		AccumulationOrders:   s.accumulationOrders.Orders(),
+		RoundTripPrices:      s.copyRoundTripPrices(),
+		Position:             s.gridPosition,
+		UpdateTime:           time.Now(),
+		UpperPrice:           s.UpperPrice,
+		LowerPrice:           s.LowerPrice,
+		PendingCounterOrders: s.pendingCounterOrders,
+	}
+
+	if err := s.Persistence.SaveState(&state, "state"); err != nil {
+		s.Log.WithError(err).Errorf("can not save grid state")
+	}
+}
+
+// resetState clears the persisted state after the active orders are canceled
+func (s *Strategy) resetState() {
+	if s.Persistence == nil {
+		return
+	}
+
+	if err := s.Persistence.SaveState(&State{}, "state"); err != nil {
+		s.Log.WithError(err).Errorf("can not reset grid state")
+	}
+}
+
+// loadState reloads the previously placed orders and reconciles them with the trade history and the exchange open orders,
+// the orders filled during the downtime trigger the reverse orders as usual.
+// it returns true if there are orders resumed.
+func (s *Strategy) loadState(ctx context.Context, session *bbgo.ExchangeSession) (bool, error) {
+	if s.Persistence == nil {
+		return false, nil
+	}
+
+	var state State
+	if err := s.Persistence.LoadState(&state, "state"); err != nil {
+		if err == bbgo.ErrPersistenceNotExists {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if len(state.Orders) == 0 {
+		return false, nil
+	}
+
+	s.Log.Infof("loaded %d grid orders from the previous state, reconciling...", len(state.Orders))
+
+	// the resumed orders are placed in the re-centered band
+	if s.Recenter != nil && state.UpperPrice > state.LowerPrice && state.LowerPrice > 0 {
+		s.UpperPrice = state.UpperPrice
+		s.LowerPrice = state.LowerPrice
+	}
+
+	// the counter orders not placed before the restart are retried after the warm-up
+	s.pendingCounterOrders = state.PendingCounterOrders
+
+	// the risk controller holds the position pointer, the loaded position is copied into it
+	if state.Position != nil && s.gridPosition != nil {
+		*s.gridPosition = *state.Position
+	}
+
+	for _, o := range state.AccumulationOrders {
+		s.accumulationOrders.Add(o)
+	}
+
+	s.roundTripMutex.Lock()
+	s.roundTripPrices = state.RoundTripPrices
+	s.roundTripMutex.Unlock()
+
+	// the orders filled during the downtime are found from the trade history, since the closed order query
+	// of some exchanges only covers a short period. Their counter orders are placed once the stream is connected.
+	filledOrders, activeOrders, err := s.recoverMissedFills(ctx, session, state)
+	if err != nil {
+		s.Log.WithError(err).Errorf("can not query the trades executed during the downtime")
+	}
+
+	s.recoveredOrders = filledOrders
+
+	s.orderStore.Add(state.Orders...)
+	s.activeOrders.Add(activeOrders...)
+
+	detector := bbgo.NewStuckOrderDetector(s.Symbol, session.Exchange, s.activeOrders)
+	detector.Notifiability = s.Notifiability
+	if err := detector.Check(ctx); err != nil {
+		return false, err
+	}
+
+	s.Log.Infof("resumed %d grid orders", len(s.activeOrders.Orders()))
+	return true, nil
+}
+
+func (s *Strategy) copyRoundTripPrices() map[uint64]float64 {
+	s.roundTripMutex.Lock()
+	defer s.roundTripMutex.Unlock()
+
+	if len(s.roundTripPrices) == 0 {
+		return nil
+	}
+
+	prices := make(map[uint64]float64, len(s.roundTripPrices))
+	for orderID, price := range s.roundTripPrices {
+		prices[orderID] = price
+	}
+
+	return prices
+}
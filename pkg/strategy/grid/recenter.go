@@ -0,0 +1,127 @@
+package grid
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultRecenterConfirmations = 3
+	defaultRecenterMinInterval   = 30 * time.Minute
+)
+
+// Recenter cancels the grid and places it again around the current price when the price trends away from the band,
+// instead of leaving a one-sided grid that no longer trades. The band width is kept.
+type Recenter struct {
+	// Margin is the distance beyond the upper or the lower price that arms the re-centering, as a ratio of the band width,
+	// e.g., 0.1 arms the re-centering when the price is 10% of the band width above the upper price.
+	Margin fixedpoint.Value `json:"margin"`
+
+	// Confirmations is the number of the consecutive closed 1m klines outside the band required by the re-centering,
+	// defaults to 3. Once armed, the count is only reset when the price moves back into the band, not into the margin.
+	Confirmations int `json:"confirmations,omitempty"`
+
+	// MinInterval is the min interval between the re-centerings, defaults to 30m
+	MinInterval types.Duration `json:"minInterval,omitempty"`
+
+	// outside is the number of the consecutive klines outside the band since the re-centering is armed
+	outside int
+
+	lastTime time.Time
+}
+
+func (r *Recenter) Validate() error {
+	if r.Margin < 0 {
+		return fmt.Errorf("recenter margin should not be negative, got %f", r.Margin.Float64())
+	}
+
+	if r.Confirmations < 0 {
+		return fmt.Errorf("recenter confirmations should not be negative, got %d", r.Confirmations)
+	}
+
+	return nil
+}
+
+// update checks the closed price against the band, it returns true if the grid should be re-centered
+func (r *Recenter) update(price, lowerPrice, upperPrice float64, now time.Time) bool {
+	if price >= lowerPrice && price <= upperPrice {
+		r.outside = 0
+		return false
+	}
+
+	margin := r.Margin.Float64() * (upperPrice - lowerPrice)
+	if r.outside == 0 && price > lowerPrice-margin && price < upperPrice+margin {
+		return false
+	}
+
+	r.outside++
+
+	confirmations := r.Confirmations
+	if confirmations == 0 {
+		confirmations = defaultRecenterConfirmations
+	}
+
+	if r.outside < confirmations {
+		return false
+	}
+
+	minInterval := r.MinInterval.Duration()
+	if minInterval == 0 {
+		minInterval = defaultRecenterMinInterval
+	}
+
+	return r.lastTime.IsZero() || now.Sub(r.lastTime) >= minInterval
+}
+
+func (r *Recenter) recentered(now time.Time) {
+	r.outside = 0
+	r.lastTime = now
+}
+
+// checkRecenter re-centers the grid if the closed price stays outside the band
+func (s *Strategy) checkRecenter(ctx context.Context, kline types.KLine) {
+	if s.Recenter == nil || s.isSuspended() || s.isPaused() {
+		return
+	}
+
+	now := kline.EndTime
+	if !s.Recenter.update(kline.Close, s.LowerPrice.Float64(), s.UpperPrice.Float64(), now) {
+		return
+	}
+
+	if err := s.recenter(ctx, kline.Close); err != nil {
+		s.Log.WithError(err).Errorf("can not re-center the grid")
+		return
+	}
+
+	s.Recenter.recentered(now)
+}
+
+// recenter moves the band to the given price and replaces the grid orders
+func (s *Strategy) recenter(ctx context.Context, price float64) error {
+	halfWidth := (s.UpperPrice - s.LowerPrice).Div(fixedpoint.NewFromInt(2))
+	lowerPrice := fixedpoint.NewFromFloat(s.Market.TruncatePrice(price - halfWidth.Float64()))
+	upperPrice := lowerPrice + halfWidth + halfWidth
+	if lowerPrice <= 0 {
+		return fmt.Errorf("the re-centered lower price %f should be positive", lowerPrice.Float64())
+	}
+
+	s.Notify("%s grid is re-centered at %f, the band %f ~ %f is moved to %f ~ %f", s.Symbol, price,
+		s.LowerPrice.Float64(), s.UpperPrice.Float64(), lowerPrice.Float64(), upperPrice.Float64())
+
+	if err := s.cancelGridOrders(ctx, s.session); err != nil {
+		return err
+	}
+
+	s.LowerPrice = lowerPrice
+	s.UpperPrice = upperPrice
+	s.checkFeeSpread()
+
+	s.resetState()
+	s.placeGridOrdersOf(s.OrderExecutor, s.session, true, true, types.OrderTags{"reason": "re-center"})
+	return nil
+}
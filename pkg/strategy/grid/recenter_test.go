@@ -0,0 +1,103 @@
+package grid
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRecenter_update(t *testing.T) {
+	const lowerPrice, upperPrice = 9000.0, 10000.0
+
+	tests := []struct {
+		name     string
+		recenter Recenter
+		prices   []float64
+		expected []bool
+	}{
+		{
+			name:     "confirmed outside the margin",
+			recenter: Recenter{Margin: fixedpoint.NewFromFloat(0.1)},
+			prices:   []float64{10200.0, 10200.0, 10200.0},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:     "below the band",
+			recenter: Recenter{Margin: fixedpoint.NewFromFloat(0.1)},
+			prices:   []float64{8800.0, 8700.0, 8600.0},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:     "within the margin",
+			recenter: Recenter{Margin: fixedpoint.NewFromFloat(0.1)},
+			prices:   []float64{10050.0, 10050.0, 10050.0, 10050.0},
+			expected: []bool{false, false, false, false},
+		},
+		{
+			name:     "armed and back into the margin",
+			recenter: Recenter{Margin: fixedpoint.NewFromFloat(0.1)},
+			prices:   []float64{10200.0, 10050.0, 10050.0},
+			expected: []bool{false, false, true},
+		},
+		{
+			name:     "back into the band",
+			recenter: Recenter{Margin: fixedpoint.NewFromFloat(0.1)},
+			prices:   []float64{10200.0, 10200.0, 9500.0, 10200.0, 10200.0},
+			expected: []bool{false, false, false, false, false},
+		},
+		{
+			name:     "custom confirmations",
+			recenter: Recenter{Confirmations: 1},
+			prices:   []float64{10001.0},
+			expected: []bool{true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			for i, price := range tt.prices {
+				now = now.Add(time.Minute)
+				assert.Equal(t, tt.expected[i], tt.recenter.update(price, lowerPrice, upperPrice, now), "price #%d %f", i, price)
+			}
+		})
+	}
+}
+
+func TestRecenter_update_minInterval(t *testing.T) {
+	r := Recenter{Confirmations: 1, MinInterval: types.Duration(time.Hour)}
+
+	now := time.Now()
+	assert.True(t, r.update(10100.0, 9000.0, 10000.0, now))
+	r.recentered(now)
+
+	// the band is moved, but the price trends away again before the min interval
+	assert.False(t, r.update(11200.0, 10000.0, 11000.0, now.Add(30*time.Minute)))
+	assert.True(t, r.update(11200.0, 10000.0, 11000.0, now.Add(time.Hour)))
+}
+
+func TestStrategy_recenter(t *testing.T) {
+	executor := &testOrderExecutor{}
+	s := newTestStrategy(executor)
+	s.Quantity = 0.01
+	s.session = newTestSession(t, 10500.0)
+
+	assert.NoError(t, s.recenter(context.Background(), 10500.0))
+	assert.Equal(t, 10000.0, s.LowerPrice.Float64())
+	assert.Equal(t, 11000.0, s.UpperPrice.Float64())
+
+	// the re-centered grid orders are tagged with the reason
+	submitted := executor.Submitted()
+	if assert.Len(t, submitted, 5) {
+		for i, o := range submitted {
+			assert.Equal(t, 10500.0+float64(i+1)*100.0, o.Price)
+			assert.Equal(t, types.OrderTags{"grid_level": strconv.Itoa(i + 1), "reason": "re-center"}, o.Tags)
+		}
+	}
+}
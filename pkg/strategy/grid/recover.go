@@ -0,0 +1,103 @@
+package grid
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// recoverTimeMargin extends the trade query range, since the state could be saved slightly after the trade
+const recoverTimeMargin = time.Minute
+
+// recoverMissedFills queries the trades executed since the state was saved, and returns the persisted grid orders
+// that are fully filled by these trades, so that their counter orders can be placed retroactively.
+// The rest of the orders are returned as the active orders.
+func (s *Strategy) recoverMissedFills(ctx context.Context, session *bbgo.ExchangeSession, state State) (filledOrders, activeOrders []types.Order, err error) {
+	since := state.UpdateTime
+	for _, o := range state.Orders {
+		// the state saved by the previous version has no update time
+		if since.IsZero() || (!o.CreationTime.IsZero() && o.CreationTime.Before(since)) {
+			since = o.CreationTime
+		}
+	}
+
+	if since.IsZero() {
+		return nil, state.Orders, nil
+	}
+
+	since = since.Add(-recoverTimeMargin)
+
+	// the trades could be more than one page if the strategy was stopped for a long time
+	batch := &types.ExchangeBatchProcessor{Exchange: session.Exchange}
+	tradeC, errC := batch.BatchQueryTrades(ctx, s.Symbol, &types.TradeQueryOptions{StartTime: &since})
+
+	var seen = make(map[int64]struct{})
+	var executedQuantities = make(map[uint64]float64)
+	var lastTradeTimes = make(map[uint64]time.Time)
+	for trade := range tradeC {
+		if _, ok := seen[trade.ID]; ok {
+			continue
+		}
+
+		seen[trade.ID] = struct{}{}
+		executedQuantities[trade.OrderID] += trade.Quantity
+		if trade.Time.After(lastTradeTimes[trade.OrderID]) {
+			lastTradeTimes[trade.OrderID] = trade.Time
+		}
+	}
+
+	if err := <-errC; err != nil {
+		return nil, state.Orders, err
+	}
+
+	// the executed quantity could be slightly less than the order quantity by the float rounding
+	tolerance := s.Market.QuantityStep() / 2.0
+	for _, o := range state.Orders {
+		executedQuantity := executedQuantities[o.OrderID]
+		if executedQuantity <= 0 || executedQuantity < o.Quantity-tolerance {
+			activeOrders = append(activeOrders, o)
+			continue
+		}
+
+		o.Status = types.OrderStatusFilled
+		o.ExecutedQuantity = executedQuantity
+		o.UpdateTime = lastTradeTimes[o.OrderID]
+		filledOrders = append(filledOrders, o)
+	}
+
+	return filledOrders, activeOrders, nil
+}
+
+// placeRecoveredCounterOrders handles the grid orders filled during the downtime as they are filled just now
+func (s *Strategy) placeRecoveredCounterOrders() {
+	orders := s.recoveredOrders
+	s.recoveredOrders = nil
+
+	if len(orders) == 0 {
+		return
+	}
+
+	s.Notify("%s grid recovered %d fills during the downtime, placing the counter orders", s.Symbol, len(orders))
+	for _, o := range orders {
+		s.Log.Infof("recovered filled grid order: %s", o.String())
+		s.handleFilledOrder(o)
+	}
+}
+
+// retryPendingCounterOrders submits the counter orders rejected by the warm-up gate or the entry filters again
+func (s *Strategy) retryPendingCounterOrders() {
+	orders := s.pendingCounterOrders
+	s.pendingCounterOrders = nil
+
+	for _, o := range orders {
+		if err := s.submitReverseOrder(o); err == bbgo.ErrWarmingUp || err == errEntryFiltered {
+			s.pendingCounterOrders = append(s.pendingCounterOrders, o)
+		}
+	}
+
+	if len(orders) > len(s.pendingCounterOrders) {
+		s.saveState()
+	}
+}
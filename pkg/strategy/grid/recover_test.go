@@ -0,0 +1,139 @@
+package grid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// pagedTradeExchange returns at most pageSize trades for each query like the exchange APIs do
+type pagedTradeExchange struct {
+	*mock.Exchange
+
+	trades   []types.Trade
+	pageSize int
+	queries  int
+}
+
+func (e *pagedTradeExchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	e.queries++
+	for _, t := range e.trades {
+		if t.Symbol != symbol || t.ID <= options.LastTradeID {
+			continue
+		}
+
+		if options.StartTime != nil && t.Time.Before(*options.StartTime) {
+			continue
+		}
+
+		trades = append(trades, t)
+		if len(trades) == e.pageSize {
+			break
+		}
+	}
+
+	return trades, nil
+}
+
+func TestStrategy_recoverMissedFills(t *testing.T) {
+	saveTime := time.Now().Add(-time.Hour)
+
+	order := func(orderID uint64, quantity float64) types.Order {
+		o := filledOrder(orderID, types.SideTypeBuy, 9500.0, quantity)
+		o.Status = types.OrderStatusNew
+		o.ExecutedQuantity = 0
+		o.CreationTime = saveTime.Add(-time.Hour)
+		return o
+	}
+
+	trade := func(id int64, orderID uint64, quantity float64, minutes int) types.Trade {
+		return types.Trade{
+			ID:       id,
+			OrderID:  orderID,
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Price:    9500.0,
+			Quantity: quantity,
+			Time:     saveTime.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name         string
+		trades       []types.Trade
+		filledOrders []uint64
+		activeOrders []uint64
+	}{
+		{
+			name:         "no fills",
+			filledOrders: nil,
+			activeOrders: []uint64{1, 2},
+		},
+		{
+			name: "partial fills",
+			trades: []types.Trade{
+				trade(1, 1, 0.1, 1),
+				trade(2, 2, 0.05, 2),
+			},
+			filledOrders: []uint64{1},
+			activeOrders: []uint64{2},
+		},
+		{
+			name: "more fills than one page",
+			trades: []types.Trade{
+				trade(1, 1, 0.02, 1),
+				trade(2, 1, 0.03, 2),
+				trade(3, 2, 0.04, 3),
+				trade(4, 1, 0.05, 4),
+				trade(5, 2, 0.06, 5),
+			},
+			filledOrders: []uint64{1, 2},
+			activeOrders: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange := &pagedTradeExchange{
+				Exchange: mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil),
+				trades:   tt.trades,
+				pageSize: 3,
+			}
+			session := bbgo.NewExchangeSession("binance", exchange)
+
+			s := newTestStrategy(&testOrderExecutor{})
+			state := State{
+				Orders:     []types.Order{order(1, 0.1), order(2, 0.1)},
+				UpdateTime: saveTime,
+			}
+
+			filledOrders, activeOrders, err := s.recoverMissedFills(context.Background(), session, state)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.filledOrders, orderIDs(filledOrders))
+			assert.Equal(t, tt.activeOrders, orderIDs(activeOrders))
+
+			for _, o := range filledOrders {
+				assert.Equal(t, types.OrderStatusFilled, o.Status)
+				assert.InDelta(t, o.Quantity, o.ExecutedQuantity, 1e-9)
+			}
+
+			if len(tt.trades) > exchange.pageSize {
+				assert.Greater(t, exchange.queries, 1)
+			}
+		})
+	}
+}
+
+func orderIDs(orders []types.Order) (ids []uint64) {
+	for _, o := range orders {
+		ids = append(ids, o.OrderID)
+	}
+
+	return ids
+}
@@ -0,0 +1,57 @@
+package grid
+
+import (
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// GridScale scales the order quantity and the grid spacing by the grid level,
+// the grid level starts from 1, which is the level right next to the current price.
+type GridScale struct {
+	// Quantity maps the grid level to the order quantity
+	Quantity *bbgo.SlideRule `json:"quantity,omitempty" yaml:"quantity,omitempty"`
+
+	// Spacing maps the grid level to the multiplier of the grid size,
+	// the price gap between the level and the previous level is gridSize * spacing(level)
+	Spacing *bbgo.SlideRule `json:"spacing,omitempty" yaml:"spacing,omitempty"`
+}
+
+func (s *GridScale) Solve() error {
+	if s.Quantity != nil {
+		if err := s.Quantity.Solve(); err != nil {
+			return err
+		}
+	}
+
+	if s.Spacing != nil {
+		if err := s.Spacing.Solve(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// levelQuantity returns the order quantity of the grid level
+func (s *Strategy) levelQuantity(level int) float64 {
+	if s.Scale == nil || s.Scale.Quantity == nil {
+		return s.Quantity
+	}
+
+	return s.Scale.Quantity.Call(float64(level))
+}
+
+// levelSpacing returns the price gap between the grid level and the previous level
+func (s *Strategy) levelSpacing(level int, gridSize fixedpoint.Value) fixedpoint.Value {
+	if s.Scale == nil || s.Scale.Spacing == nil {
+		return gridSize
+	}
+
+	spacing := gridSize.MulFloat64(s.Scale.Spacing.Call(float64(level)))
+	if spacing <= 0 {
+		// avoid the infinite loop of the grid levels
+		return gridSize
+	}
+
+	return spacing
+}
@@ -0,0 +1,60 @@
+package grid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestStrategy_levelSpacing(t *testing.T) {
+	gridSize := fixedpoint.NewFromFloat(100.0)
+
+	s := newTestStrategy(&testOrderExecutor{})
+	assert.Equal(t, gridSize, s.levelSpacing(3, gridSize))
+
+	s.Scale = &GridScale{
+		Spacing: &bbgo.SlideRule{
+			LinearScale: &bbgo.LinearScale{Domain: [2]float64{1, 5}, Range: [2]float64{1, -1}},
+		},
+	}
+	if !assert.NoError(t, s.Scale.Solve()) {
+		return
+	}
+
+	tests := []struct {
+		level   int
+		spacing float64
+	}{
+		{level: 1, spacing: 100.0},
+		{level: 2, spacing: 50.0},
+		// the non-positive spacing falls back to the grid size
+		{level: 3, spacing: 100.0},
+		{level: 5, spacing: 100.0},
+	}
+
+	for _, tt := range tests {
+		assert.InDelta(t, tt.spacing, s.levelSpacing(tt.level, gridSize).Float64(), 1e-8, "level %d", tt.level)
+	}
+}
+
+func TestStrategy_levelQuantity(t *testing.T) {
+	s := newTestStrategy(&testOrderExecutor{})
+	s.Quantity = 0.01
+	assert.Equal(t, 0.01, s.levelQuantity(3))
+
+	s.Scale = &GridScale{
+		Quantity: &bbgo.SlideRule{
+			ExponentialScale: &bbgo.ExponentialScale{Domain: [2]float64{1, 3}, Range: [2]float64{0.01, 0.04}},
+		},
+	}
+	if !assert.NoError(t, s.Scale.Solve()) {
+		return
+	}
+
+	assert.InDelta(t, 0.01, s.levelQuantity(1), 1e-8)
+	assert.InDelta(t, 0.02, s.levelQuantity(2), 1e-8)
+	assert.InDelta(t, 0.04, s.levelQuantity(3), 1e-8)
+}
@@ -0,0 +1,46 @@
+package grid
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// SignalCondition matches the signal published on the message bus
+type SignalCondition struct {
+	Topic string `json:"topic"`
+	Value string `json:"value"`
+}
+
+func (c *SignalCondition) Match(signal bbgo.Signal) bool {
+	return signal.Topic == c.Topic && fmt.Sprint(signal.Value) == c.Value
+}
+
+func (s *Strategy) isPaused() bool {
+	return atomic.LoadInt32(&s.paused) == 1
+}
+
+func (s *Strategy) updatePaused(signal bbgo.Signal) {
+	var paused int32
+	if s.PauseOn.Match(signal) {
+		paused = 1
+	}
+
+	if atomic.SwapInt32(&s.paused, paused) != paused {
+		s.Log.Infof("grid paused = %v by the signal %s=%v from %s", paused == 1, signal.Topic, signal.Value, signal.Source)
+	}
+}
+
+// subscribeSignals pauses the counter orders when the signal matches the PauseOn condition
+func (s *Strategy) subscribeSignals() {
+	if s.PauseOn == nil || s.MessageBus == nil {
+		return
+	}
+
+	if signal, ok := s.MessageBus.Last(s.PauseOn.Topic); ok {
+		s.updatePaused(signal)
+	}
+
+	s.MessageBus.Subscribe(s.PauseOn.Topic, s.updatePaused)
+}
@@ -0,0 +1,52 @@
+package grid
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// countLevels returns the number of the grid levels of the side between the current price and the grid boundary
+func (s *Strategy) countLevels(side types.SideType, currentPrice, gridSize fixedpoint.Value) (count int) {
+	switch side {
+	case types.SideTypeSell:
+		for level, price := 1, currentPrice+s.levelSpacing(1, gridSize); price <= s.UpperPrice; level, price = level+1, price+s.levelSpacing(level+1, gridSize) {
+			count++
+		}
+
+	case types.SideTypeBuy:
+		for level, price := 1, currentPrice-s.levelSpacing(1, gridSize); price >= s.LowerPrice; level, price = level+1, price-s.levelSpacing(level+1, gridSize) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// sizingBalances returns the balances used by the order sizing, the quote balance is capped by the allocated budget in the multi-symbol mode
+func (s *Strategy) sizingBalances(balances types.BalanceMap) types.BalanceMap {
+	if s.quoteBudget <= 0 {
+		return balances
+	}
+
+	sizingBalances := make(types.BalanceMap, len(balances))
+	for currency, balance := range balances {
+		sizingBalances[currency] = balance
+	}
+
+	if quoteBalance, ok := sizingBalances[s.Market.QuoteCurrency]; ok && quoteBalance.Available > s.quoteBudget {
+		quoteBalance.Available = s.quoteBudget
+		sizingBalances[s.Market.QuoteCurrency] = quoteBalance
+	}
+
+	return sizingBalances
+}
+
+// gridQuantity returns the order quantity of the grid level, the quantity is computed from the balances if the sizing is configured,
+// the numOfLevels is the number of the grid levels of the side that share the budget.
+func (s *Strategy) gridQuantity(balances types.BalanceMap, side types.SideType, level int, price fixedpoint.Value, numOfLevels int) (float64, error) {
+	if s.Sizing == nil {
+		return s.levelQuantity(level), nil
+	}
+
+	return s.Sizing.Quantity(s.Market, balances, side, price.Float64(), numOfLevels)
+}
@@ -2,20 +2,21 @@ package grid
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"sync"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/bbgo/risk"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
 const ID = "grid"
 
-var log = logrus.WithField("strategy", ID)
-
 func init() {
 	// Register the pointer of the strategy struct,
 	// so that bbgo knows what struct to be used to unmarshal the configs (YAML or JSON)
@@ -24,11 +25,18 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	// The notification system will be injected into the strategy automatically.
 	// This field will be injected automatically since it's a single exchange strategy.
 	*bbgo.Notifiability `json:"-" yaml:"-"`
 
-	*bbgo.Graceful `json:"-" yaml:"-"`
+	// Persistence is used for persisting the active grid orders, so that the orders can be resumed after restarting.
+	*bbgo.Persistence
+
+	// MessageBus is used for receiving the signals from the other strategies.
+	// This field will be injected automatically.
+	MessageBus *bbgo.MessageBus `json:"-" yaml:"-"`
 
 	// OrderExecutor is an interface for submitting order.
 	// This field will be injected automatically since it's a single exchange strategy.
@@ -38,12 +46,38 @@ type Strategy struct {
 	// This field will be injected automatically since we defined the Symbol field.
 	types.Market `json:"-" yaml:"-"`
 
+	// Position and ProfitStats track the average cost and the realized profit of the symbol,
+	// these fields will be injected automatically since we defined the Symbol field.
+	Position    *bbgo.Position    `json:"-" yaml:"-"`
+	ProfitStats *bbgo.ProfitStats `json:"-" yaml:"-"`
+
+	// WarmUp gates the order submission until enough market data is received
+	bbgo.WarmUp
+
 	// These fields will be filled from the config file (it translates YAML to JSON)
 	Symbol string `json:"symbol" yaml:"symbol"`
 
-	// ProfitSpread is the fixed profit spread you want to submit the sell order
+	// Symbols runs a grid for each symbol with the per-symbol grid settings instead of the single Symbol,
+	// the other settings of the strategy are shared by the grids.
+	Symbols []GridSymbol `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+
+	// Budget maps the quote currency to the budget shared by the grids of the symbols, the budget is allocated by the symbol weights.
+	// The budget of a quote currency defaults to the available balance.
+	Budget map[string]fixedpoint.Value `json:"budget,omitempty" yaml:"budget,omitempty"`
+
+	// ProfitSpread is the fixed profit spread you want to submit the counter order,
+	// when a buy order is filled, a sell order is placed at the buy price + profit spread, and vice versa.
+	// If it's not set, the counter order is placed one grid level away.
 	ProfitSpread fixedpoint.Value `json:"profitSpread" yaml:"profitSpread"`
 
+	// FeeAware widens the spread of the counter orders to cover the round trip maker fees of the session,
+	// so that each grid round trip is net-positive after the fees.
+	FeeAware bool `json:"feeAware,omitempty" yaml:"feeAware,omitempty"`
+
+	// CatchUp moves the counter order to the next grid level beyond the current price when the price has already
+	// moved past the counter order price, so that the counter order is placed as a maker order instead of being filled immediately.
+	CatchUp bool `json:"catchUp,omitempty" yaml:"catchUp,omitempty"`
+
 	// GridNum is the grid number, how many orders you want to post on the orderbook.
 	GridNum int `json:"gridNumber" yaml:"gridNumber"`
 
@@ -51,95 +85,318 @@ type Strategy struct {
 
 	LowerPrice fixedpoint.Value `json:"lowerPrice" yaml:"lowerPrice"`
 
+	// Recenter cancels the grid and places it again around the current price when the price moves outside the band
+	// by the margin, the re-centering is guarded by the confirmation klines and the min interval to avoid the churn.
+	Recenter *Recenter `json:"recenter,omitempty" yaml:"recenter,omitempty"`
+
 	// Quantity is the quantity you want to submit for each order.
 	Quantity float64 `json:"quantity,omitempty"`
 
 	// FixedAmount is used for fixed amount (dynamic quantity) if you don't want to use fixed quantity.
 	FixedAmount fixedpoint.Value `json:"amount,omitempty" yaml:"amount"`
 
+	// Sizing computes the grid order quantity from a percentage of the available balance instead of the fixed quantity,
+	// the budget of each side is split evenly among the grid levels of the side.
+	Sizing *bbgo.QuantityCalculator `json:"sizing,omitempty" yaml:"sizing,omitempty"`
+
+	// Scale scales the order quantity (and optionally the grid spacing) by the grid level,
+	// with the linear, exponential or logarithmic scale.
+	Scale *GridScale `json:"scale,omitempty" yaml:"scale,omitempty"`
+
 	// Long means you want to hold more base asset than the quote asset.
 	Long bool `json:"long,omitempty" yaml:"long,omitempty"`
 
+	// AccumulateBase is the base currency accumulation mode, the profit from the sell fills is used to place
+	// additional buy orders at the lower prices instead of being held as the quote currency.
+	// The base asset bought by these orders is kept, no reverse sell order will be placed for them.
+	AccumulateBase bool `json:"accumulateBase,omitempty" yaml:"accumulateBase,omitempty"`
+
+	// InventoryTarget enables the inventory targeting mode, the grid order quantities are biased to
+	// mean-revert the base inventory to the target quantity.
+	InventoryTarget *InventoryTarget `json:"inventoryTarget,omitempty" yaml:"inventoryTarget,omitempty"`
+
+	// InventorySkew scales the grid order quantities by the base/quote value imbalance against the target ratio,
+	// it works with the balances only so it can be used without a target quantity.
+	InventorySkew *bbgo.InventorySkew `json:"inventorySkew,omitempty" yaml:"inventorySkew,omitempty"`
+
+	// SkipLevelWallNotional skips placing a grid level when the order book shows a wall between the level and the mid price,
+	// i.e., the notional of the bids between a buy level and the mid price or the asks between the mid price and a sell level
+	// exceeds this threshold. The grid orders are not placed until the first order book snapshot is received.
+	SkipLevelWallNotional fixedpoint.Value `json:"skipLevelWallNotional,omitempty" yaml:"skipLevelWallNotional,omitempty"`
+
+	// KeepOrdersOnShutdown keeps the grid orders on the exchange when shutting down,
+	// the orders will be resumed from the persistence on the next start.
+	KeepOrdersOnShutdown bool `json:"keepOrdersOnShutdown,omitempty" yaml:"keepOrdersOnShutdown,omitempty"`
+
+	// PauseOn stops placing the counter orders while the signal published by the other strategy matches,
+	// e.g., pause the grid when the trend strategy publishes "long" on the "trend" topic.
+	PauseOn *SignalCondition `json:"pauseOn,omitempty" yaml:"pauseOn,omitempty"`
+
+	// EntryFilters gate the grid orders of each side by the trend signals, e.g., place the bids only when the price
+	// is above EMA200, the filters are evaluated before placing the orders and on every closed 1m kline.
+	EntryFilters []EntryFilter `json:"entryFilters,omitempty" yaml:"entryFilters,omitempty"`
+
+	// Risk enables the stop-loss, trailing stop and take-profit controls of the position,
+	// the grid orders are canceled and no more orders are placed after the controls are triggered.
+	Risk *risk.Config `json:"risk,omitempty" yaml:"risk,omitempty"`
+
+	// OrderCheckInterval enables the stuck order detector, which reconciles the active orders with the exchange periodically.
+	OrderCheckInterval types.Duration `json:"orderCheckInterval,omitempty" yaml:"orderCheckInterval,omitempty"`
+
+	// MaxOrderAge notifies the orders that have been resting longer than the given duration.
+	MaxOrderAge types.Duration `json:"maxOrderAge,omitempty" yaml:"maxOrderAge,omitempty"`
+
 	orderStore *bbgo.OrderStore
 
 	// activeOrders is the locally maintained active order book of the maker orders.
 	activeOrders *bbgo.LocalActiveOrderBook
 
+	// tradeCollector matches the buy trades against the sell trades of the grid orders for the round trip profits
+	tradeCollector *types.TradeCollector
+
 	position fixedpoint.Value
 
 	// any created orders for tracking trades
 	orders map[uint64]types.Order
+
+	// accumulatedProfit is the quote profit collected from the round trips in the base accumulation mode
+	accumulatedProfit fixedpoint.Value
+
+	// roundTripMutex protects roundTripPrices
+	roundTripMutex sync.Mutex
+
+	// roundTripPrices maps the counter sell orders to the prices of the filled buy orders they close,
+	// only these sell orders complete a round trip in the base accumulation mode.
+	roundTripPrices map[uint64]float64
+
+	// accumulationOrders are the buy orders placed by the accumulated profit
+	accumulationOrders *types.SyncOrderMap
+
+	// gridPosition is the position of the grid orders, the risk controls close only this quantity
+	// instead of the session position shared by the strategies of the same symbol.
+	gridPosition *bbgo.Position
+
+	session *bbgo.ExchangeSession
+
+	// paused is set to 1 when the PauseOn signal matches
+	paused int32
+
+	// suspended is set to 1 when the strategy is suspended by the strategy control API
+	suspended int32
+
+	// suspendMutex protects the suspend policy and the orders kept for resuming the suspended grid
+	suspendMutex sync.Mutex
+
+	// suspendPolicy is the open orders policy of the current suspension
+	suspendPolicy bbgo.SuspendOrderPolicy
+
+	// suspendedOrders are the grid orders canceled by the cancelAndRestore suspension, they are submitted again on resume
+	suspendedOrders []types.Order
+
+	// suspendedFills are the grid orders filled during the suspension, their counter orders are placed on resume
+	suspendedFills []types.Order
+
+	// parameterMutex serializes the runtime parameter updates of the strategy control API and the config reload
+	parameterMutex sync.Mutex
+
+	// pendingPlacement is set when the grid orders are rejected by the warm-up gate
+	pendingPlacement bool
+
+	// recoveredOrders are the grid orders filled during the downtime, their counter orders are placed after the stream is connected
+	recoveredOrders []types.Order
+
+	// pendingCounterOrders are the filled orders whose counter orders are rejected by the warm-up gate or blocked by the entry filters
+	pendingCounterOrders []types.Order
+
+	// entryMutex protects entryBlocked
+	entryMutex sync.Mutex
+
+	// entryBlocked records the sides blocked by the entry filters in the last evaluation
+	entryBlocked map[types.SideType]bool
+
+	// groupID is the order group of the grid orders, the exchanges supporting the order group can clear the whole grid in one request
+	groupID int64
+
+	// grids are the grids of the symbols in the multi-symbol mode
+	grids []*Strategy
+
+	// quoteBudget is the quote amount allocated to this grid in the multi-symbol mode, zero means no limit
+	quoteBudget fixedpoint.Value
 }
 
 func (s *Strategy) ID() string {
 	return ID
 }
 
+// InstanceID identifies the grid instance by its symbols, so the grids of different symbols can be reloaded separately
+func (s *Strategy) InstanceID() string {
+	if s.isMultiSymbol() {
+		var symbols []string
+		for _, gs := range s.Symbols {
+			symbols = append(symbols, gs.Symbol)
+		}
+
+		return ID + ":" + strings.Join(symbols, ",")
+	}
+
+	return ID + ":" + s.Symbol
+}
+
+// RequiredCapabilities declares the user data stream since the grid orders are maintained by the order updates
+func (s *Strategy) RequiredCapabilities() []types.Capability {
+	return []types.Capability{types.CapabilityUserDataStream}
+}
+
 func (s *Strategy) placeGridOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
-	log.Infof("placing grid orders...")
+	s.placeGridOrdersOf(orderExecutor, session, true, true, nil)
+}
+
+// gridLevelTags returns the tags of the grid order at the level, the given tags (e.g., the placement reason) are merged
+func gridLevelTags(level int, tags types.OrderTags) types.OrderTags {
+	return types.OrderTags{"grid_level": strconv.Itoa(level)}.Merge(tags)
+}
+
+// placeGridOrdersOf places the grid orders of the given sides from the current price,
+// the side blocked by the entry filters is skipped. The orders are tagged with their grid levels and the given tags.
+func (s *Strategy) placeGridOrdersOf(orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, placeBids, placeAsks bool, tags types.OrderTags) {
+	s.Log.Infof("placing grid orders...")
 
 	quoteCurrency := s.Market.QuoteCurrency
 	balances := session.Account.Balances()
 
 	currentPrice, ok := session.LastPrice(s.Symbol)
 	if !ok {
-		log.Warn("last price not found, skipping")
+		s.Log.Warn("last price not found, skipping")
 		return
 	}
 
 	currentPriceF := fixedpoint.NewFromFloat(currentPrice)
-	priceRange := s.UpperPrice - s.LowerPrice
-	gridSize := priceRange.Div(fixedpoint.NewFromInt(s.GridNum))
+	gridSize := s.gridSize()
+
+	var book *types.OrderBook
+	if s.SkipLevelWallNotional > 0 {
+		store, ok := session.MarketDataStore(s.Symbol)
+		if !ok {
+			s.Log.Warnf("market data store of %s is not found, skipping the wall check", s.Symbol)
+		} else if orderBook, ok := store.OrderBook(); ok {
+			book = &orderBook
+		} else {
+			// the grid orders are placed on the next kline after the first book snapshot is received
+			s.Log.Infof("waiting for the order book snapshot of %s, the grid orders will be placed after the book is synced", s.Symbol)
+			s.pendingPlacement = true
+			return
+		}
+	}
 
 	var bidOrders []types.SubmitOrder
 	var askOrders []types.SubmitOrder
 
+	sizingBalances := s.sizingBalances(balances)
+
+	placeBids = placeBids && s.allowEntry(types.SideTypeBuy)
+	placeAsks = placeAsks && s.allowEntry(types.SideTypeSell)
+
 	baseBalance, ok := balances[s.Market.BaseCurrency]
-	if ok && baseBalance.Available > 0 {
-		log.Infof("placing sell order from %f ~ %f per grid %f", (currentPriceF + gridSize).Float64(), s.UpperPrice.Float64(), gridSize.Float64())
-		for price := currentPriceF + gridSize; price <= s.UpperPrice; price += gridSize {
+	if !placeAsks {
+		// the ask orders are not requested or blocked by the entry filters
+	} else if ok && baseBalance.Available > 0 {
+		s.Log.Infof("placing sell order from %f ~ %f per grid %f", (currentPriceF + gridSize).Float64(), s.UpperPrice.Float64(), gridSize.Float64())
+		numOfLevels := s.countLevels(types.SideTypeSell, currentPriceF, gridSize)
+		for level, price := 1, currentPriceF+s.levelSpacing(1, gridSize); price <= s.UpperPrice; level, price = level+1, price+s.levelSpacing(level+1, gridSize) {
+			if s.shouldSkipLevel(book, types.SideTypeSell, price) {
+				continue
+			}
+
+			quantity, err := s.gridQuantity(sizingBalances, types.SideTypeSell, level, price, numOfLevels)
+			if err != nil {
+				s.Log.WithError(err).Warnf("can not size the sell orders, skipping the sell orders above %f", price.Float64())
+				break
+			}
+
 			order := types.SubmitOrder{
 				Symbol:      s.Symbol,
 				Side:        types.SideTypeSell,
 				Type:        types.OrderTypeLimit,
 				Market:      s.Market,
-				Quantity:    s.Quantity,
+				Quantity:    s.adjustQuantityByInventory(session, types.SideTypeSell, quantity),
 				Price:       price.Float64(),
 				TimeInForce: "GTC",
+				GroupID:     s.groupID,
+				Tags:        gridLevelTags(level, tags),
 			}
 			askOrders = append(askOrders, order)
 		}
 	} else {
-		log.Warnf("base balance is not enough, we can't place ask orders")
+		s.Log.Warnf("base balance is not enough, we can't place ask orders")
 	}
 
 	quoteBalance, ok := balances[quoteCurrency]
-	if ok && quoteBalance.Available > 0 {
-		log.Infof("placing buy order from %f ~ %f per grid %f", (currentPriceF - gridSize).Float64(), s.LowerPrice.Float64(), gridSize.Float64())
+	if !placeBids {
+		// the bid orders are not requested or blocked by the entry filters
+	} else if ok && quoteBalance.Available > 0 {
+		s.Log.Infof("placing buy order from %f ~ %f per grid %f", (currentPriceF - gridSize).Float64(), s.LowerPrice.Float64(), gridSize.Float64())
+
+		var quoteQuantity fixedpoint.Value
+		numOfLevels := s.countLevels(types.SideTypeBuy, currentPriceF, gridSize)
+		for level, price := 1, currentPriceF-s.levelSpacing(1, gridSize); price >= s.LowerPrice; level, price = level+1, price-s.levelSpacing(level+1, gridSize) {
+			if s.shouldSkipLevel(book, types.SideTypeBuy, price) {
+				continue
+			}
+
+			quantity, err := s.gridQuantity(sizingBalances, types.SideTypeBuy, level, price, numOfLevels)
+			if err != nil {
+				s.Log.WithError(err).Warnf("can not size the buy orders, skipping the buy orders below %f", price.Float64())
+				break
+			}
 
-		for price := currentPriceF - gridSize; price >= s.LowerPrice; price -= gridSize {
 			order := types.SubmitOrder{
 				Symbol:      s.Symbol,
 				Side:        types.SideTypeBuy,
 				Type:        types.OrderTypeLimit,
 				Market:      s.Market,
-				Quantity:    s.Quantity,
+				Quantity:    s.adjustQuantityByInventory(session, types.SideTypeBuy, quantity),
 				Price:       price.Float64(),
 				TimeInForce: "GTC",
+				GroupID:     s.groupID,
+				Tags:        gridLevelTags(level, tags),
 			}
+
+			// the buy orders are placed within the allocated budget
+			quoteQuantity += price.MulFloat64(order.Quantity)
+			if s.quoteBudget > 0 && quoteQuantity > s.quoteBudget {
+				s.Log.Infof("%s grid budget %f %s is used up, skipping the buy orders below %f", s.Symbol, s.quoteBudget.Float64(), quoteCurrency, price.Float64())
+				break
+			}
+
 			bidOrders = append(bidOrders, order)
 		}
 	} else {
-		log.Warnf("quote balance is not enough, we can't place bid orders")
+		s.Log.Warnf("quote balance is not enough, we can't place bid orders")
 	}
 
 	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), append(bidOrders, askOrders...)...)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
-		return
+		if err == bbgo.ErrWarmingUp {
+			s.Log.Infof("warming up, the grid orders will be placed after the warm-up")
+			s.pendingPlacement = true
+			return
+		}
+
+		// the orders accepted by the exchange are still tracked when some of the grid orders are rejected
+		var batchErr *bbgo.BatchSubmitError
+		if !errors.As(err, &batchErr) {
+			s.Log.WithError(err).Errorf("can not place orders")
+			return
+		}
+
+		for _, r := range batchErr.Failed() {
+			s.Log.WithError(r.Error).Errorf("can not place the grid order %s", r.SubmitOrder.String())
+		}
 	}
 
+	s.pendingPlacement = false
 	s.activeOrders.Add(createdOrders...)
+	s.saveState()
 }
 
 func (s *Strategy) tradeUpdateHandler(trade types.Trade) {
@@ -148,26 +405,255 @@ func (s *Strategy) tradeUpdateHandler(trade types.Trade) {
 	}
 
 	if s.orderStore.Exists(trade.OrderID) {
-		log.Infof("received trade update of order %d: %+v", trade.OrderID, trade)
+		s.Log.Infof("received trade update of order %d: %+v", trade.OrderID, trade)
 		switch trade.Side {
 		case types.SideTypeBuy:
 			s.position.AtomicAdd(fixedpoint.NewFromFloat(trade.Quantity))
 		case types.SideTypeSell:
 			s.position.AtomicAdd(-fixedpoint.NewFromFloat(trade.Quantity))
 		}
+
+		s.tradeCollector.AddTrade(trade)
+		s.gridPosition.AddTrade(trade)
+	}
+}
+
+// handleRoundTripProfit announces the realized profit of the round trip matched by the trade collector
+func (s *Strategy) handleRoundTripProfit(trade types.Trade, profit types.Profit) {
+	if profit.Profit < 0 {
+		s.Log.Warnf("grid round trip lost money: %s", profit.PlainText())
+	} else {
+		s.Log.Infof("grid round trip profit: %s", profit.PlainText())
+	}
+
+	s.Notify("%s grid round trip %+f %s", s.Symbol, profit.Profit, profit.QuoteCurrency, profit)
+
+	if s.ProfitStats != nil {
+		s.Log.Info(s.ProfitStats.PlainText())
+	}
+}
+
+// Status reports the active grid orders and the position, it's used by the telegram /status command
+func (s *Strategy) Status() string {
+	if s.isMultiSymbol() {
+		return s.gridsStatus()
+	}
+
+	if s.activeOrders == nil {
+		return "not started"
+	}
+
+	status := fmt.Sprintf("%s %d bids, %d asks", s.Symbol, s.activeOrders.NumOfBids(), s.activeOrders.NumOfAsks())
+	if s.isSuspended() {
+		status += " (suspended)"
+	} else if s.isPaused() {
+		status += " (paused)"
+	}
+
+	if s.tradeCollector != nil {
+		quantity, averagePrice := s.tradeCollector.Position()
+		status += fmt.Sprintf(", position %f %s at %f", quantity, s.Market.BaseCurrency, averagePrice)
+	}
+
+	return status
+}
+
+// Stop cancels the grid orders when the strategy is stopped by the lifecycle manager, e.g., the hedger it depends on fails,
+// the orders submitted after the stop are rejected by the lifecycle order executor.
+func (s *Strategy) Stop(ctx context.Context) error {
+	if s.isMultiSymbol() {
+		return s.eachGrid(func(grid *Strategy) error {
+			return grid.Stop(ctx)
+		})
+	}
+
+	if s.session == nil {
+		return nil
+	}
+
+	s.Log.Infof("grid is stopped, canceling active orders...")
+	if err := s.cancelGridOrders(ctx, s.session); err != nil {
+		return err
+	}
+
+	s.resetState()
+	return nil
+}
+
+// Shutdown cancels the grid orders, or saves them for the next start if KeepOrdersOnShutdown is set,
+// it's called by the trader with the shutdown deadline.
+func (s *Strategy) Shutdown(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	if s.isMultiSymbol() {
+		s.shutdownGrids(ctx)
+		return
+	}
+
+	if s.session == nil {
+		return
+	}
+
+	if s.ProfitStats != nil {
+		s.Log.Info(s.ProfitStats.PlainText())
+	}
+
+	if s.Position != nil {
+		if lastPrice, ok := s.session.LastPrice(s.Symbol); ok {
+			s.Log.Infof("unrealized profit: %f %s", s.Position.UnrealizedProfit(fixedpoint.NewFromFloat(lastPrice)).Float64(), s.Market.QuoteCurrency)
+		}
+	}
+
+	if s.KeepOrdersOnShutdown {
+		s.Log.Infof("keeping %d active orders", len(s.activeOrders.Orders()))
+		s.saveState()
+		return
+	}
+
+	s.Log.Infof("canceling active orders...")
+
+	if err := s.cancelGridOrders(ctx, s.session); err != nil {
+		s.Log.WithError(err).Errorf("cancel order error")
+	}
+
+	s.resetState()
+}
+
+func (s *Strategy) gridSize() fixedpoint.Value {
+	return (s.UpperPrice - s.LowerPrice).Div(fixedpoint.NewFromInt(s.GridNum))
+}
+
+// addRoundTrip records the counter sell orders of the filled buy order in the base accumulation mode
+func (s *Strategy) addRoundTrip(buyOrder types.Order, sellOrders ...types.Order) {
+	if !s.AccumulateBase || buyOrder.Side != types.SideTypeBuy {
+		return
+	}
+
+	s.roundTripMutex.Lock()
+	defer s.roundTripMutex.Unlock()
+
+	if s.roundTripPrices == nil {
+		s.roundTripPrices = make(map[uint64]float64)
+	}
+
+	for _, o := range sellOrders {
+		s.roundTripPrices[o.OrderID] = buyOrder.Price
+	}
+}
+
+// removeRoundTrip returns the buy price of the round trip closed by the given sell order
+func (s *Strategy) removeRoundTrip(orderID uint64) (buyPrice float64, ok bool) {
+	s.roundTripMutex.Lock()
+	defer s.roundTripMutex.Unlock()
+
+	buyPrice, ok = s.roundTripPrices[orderID]
+	delete(s.roundTripPrices, orderID)
+	return buyPrice, ok
+}
+
+// accumulateProfit collects the profit of the round trip closed by the filled sell order and places an additional buy order
+// at a lower price once the collected profit is enough for an order.
+func (s *Strategy) accumulateProfit(order types.Order, buyPrice float64) {
+	profit := (order.Price - buyPrice) * order.Quantity
+	if profit <= 0 {
+		return
+	}
+
+	s.accumulatedProfit += fixedpoint.NewFromFloat(profit)
+
+	price := s.Market.TruncatePrice(order.Price - s.ProfitSpread.Float64() - s.gridSize().Float64())
+	if price < s.LowerPrice.Float64() {
+		price = s.LowerPrice.Float64()
+	}
+
+	amount := s.accumulatedProfit.Float64()
+	if amount < s.Market.MinNotional || amount < s.Market.MinAmount {
+		s.Log.Infof("accumulated profit %f %s is not enough for an accumulation order", amount, s.Market.QuoteCurrency)
+		return
+	}
+
+	quantity := s.Market.TruncateQuantity(amount / price)
+	if quantity <= 0 || quantity < s.Market.MinQuantity {
+		return
+	}
+
+	submitOrder := types.SubmitOrder{
+		Symbol:      s.Symbol,
+		Side:        types.SideTypeBuy,
+		Type:        types.OrderTypeLimit,
+		Market:      s.Market,
+		Quantity:    quantity,
+		Price:       price,
+		TimeInForce: "GTC",
+		Tags:        types.OrderTags{"reason": "accumulate"},
+	}
+
+	s.Log.Infof("submitting accumulation order with profit %f %s: %s", amount, s.Market.QuoteCurrency, submitOrder.String())
+
+	createdOrders, err := s.OrderExecutor.SubmitOrders(context.Background(), submitOrder)
+	if err != nil {
+		s.Log.WithError(err).Errorf("can not place accumulation order")
+		return
+	}
+
+	s.accumulatedProfit = 0
+	for _, o := range createdOrders {
+		s.accumulationOrders.Add(o)
+	}
+
+	s.orderStore.Add(createdOrders...)
+	s.activeOrders.Add(createdOrders...)
+}
+
+func (s *Strategy) handleFilledOrder(order types.Order) {
+	if s.AccumulateBase {
+		// the base asset bought by the accumulated profit is kept
+		if s.accumulationOrders.Remove(order.OrderID) {
+			s.Log.Infof("accumulation order filled, base %s accumulated: %f", s.Market.BaseCurrency, order.Quantity)
+			return
+		}
+
+		// only the counter sell orders of the filled buy orders close a round trip,
+		// the initial sell orders are placed from the base balance
+		if order.Side == types.SideTypeSell {
+			if buyPrice, ok := s.removeRoundTrip(order.OrderID); ok {
+				s.accumulateProfit(order, buyPrice)
+			}
+		}
 	}
+
+	if s.isSuspended() {
+		s.Log.Infof("grid is suspended, the counter order of %s is placed on resume", order.String())
+		s.addSuspendedFill(order)
+	} else if s.isPaused() {
+		s.Log.Infof("grid is paused, skipping the counter order of %s", order.String())
+	} else if err := s.submitReverseOrder(order); err == bbgo.ErrWarmingUp {
+		s.Log.Infof("warming up, the counter order of %s will be placed after the warm-up", order.String())
+		s.pendingCounterOrders = append(s.pendingCounterOrders, order)
+	} else if err == errEntryFiltered {
+		s.Log.Infof("the counter order of %s is blocked by the entry filters, it will be placed when the filters allow it", order.String())
+		s.pendingCounterOrders = append(s.pendingCounterOrders, order)
+	}
+
+	s.saveState()
 }
 
-func (s *Strategy) submitReverseOrder(order types.Order) {
+func (s *Strategy) submitReverseOrder(order types.Order) error {
 	var side = order.Side.Reverse()
 	var price = order.Price
 	var quantity = order.Quantity
 
+	var spread = s.profitSpread(order.Price)
+
 	switch side {
 	case types.SideTypeSell:
-		price += s.ProfitSpread.Float64()
+		price += spread
 	case types.SideTypeBuy:
-		price -= s.ProfitSpread.Float64()
+		price -= spread
+	}
+
+	if s.CatchUp {
+		price = s.catchUpPrice(side, price)
 	}
 
 	if s.FixedAmount > 0 {
@@ -179,32 +665,111 @@ func (s *Strategy) submitReverseOrder(order types.Order) {
 		quantity = amount / price
 	}
 
+	if len(s.EntryFilters) > 0 {
+		if lastPrice, ok := s.session.LastPrice(s.Symbol); ok && len(s.entryBlockReason(side, lastPrice)) > 0 {
+			return errEntryFiltered
+		}
+	}
+
+	quantity = s.adjustQuantityByInventory(s.session, side, quantity)
+	if quantity < s.Market.MinQuantity {
+		s.Log.Infof("reverse order quantity %f is less than the min quantity %f after the inventory adjustment, skipping", quantity, s.Market.MinQuantity)
+		return nil
+	}
+
 	submitOrder := types.SubmitOrder{
 		Symbol:      s.Symbol,
 		Side:        side,
 		Type:        types.OrderTypeLimit,
+		Market:      s.Market,
 		Quantity:    quantity,
 		Price:       price,
 		TimeInForce: "GTC",
+		GroupID:     s.groupID,
+	}
+
+	// the counter order stays at the grid level of the filled order
+	if level, ok := order.Tags["grid_level"]; ok {
+		submitOrder.Tags = types.OrderTags{"grid_level": level}
 	}
 
-	log.Infof("submitting reverse order: %s against %s", submitOrder.String(), order.String())
+	s.Log.Infof("submitting reverse order: %s against %s", submitOrder.String(), order.String())
 
 	createdOrders, err := s.OrderExecutor.SubmitOrders(context.Background(), submitOrder)
 	if err != nil {
-		log.WithError(err).Errorf("can not place orders")
-		return
+		s.Log.WithError(err).Errorf("can not place orders")
+		return err
 	}
 
+	s.addRoundTrip(order, createdOrders...)
 	s.orderStore.Add(createdOrders...)
 	s.activeOrders.Add(createdOrders...)
+	return nil
+}
+
+// catchUpPrice moves the counter order price by grid levels until it's beyond the last price,
+// e.g., the price jumps over the counter sell price right after the buy order is filled.
+func (s *Strategy) catchUpPrice(side types.SideType, price float64) float64 {
+	lastPrice, ok := s.session.LastPrice(s.Symbol)
+	if !ok {
+		return price
+	}
+
+	gridSize := s.gridSize().Float64()
+	if gridSize <= 0 {
+		return price
+	}
+
+	switch side {
+	case types.SideTypeSell:
+		if price <= lastPrice {
+			levels := math.Floor((lastPrice-price)/gridSize) + 1
+			s.Log.Infof("counter sell price %f is below the last price %f, catching up %.0f grid levels", price, lastPrice, levels)
+			price += levels * gridSize
+		}
+
+	case types.SideTypeBuy:
+		if price >= lastPrice {
+			levels := math.Floor((price-lastPrice)/gridSize) + 1
+			s.Log.Infof("counter buy price %f is above the last price %f, catching up %.0f grid levels", price, lastPrice, levels)
+			price -= levels * gridSize
+		}
+	}
+
+	return price
 }
 
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if s.isMultiSymbol() {
+		if err := s.initGrids(); err != nil {
+			s.Log.WithError(err).Errorf("can not create the grids")
+			return
+		}
+
+		for _, grid := range s.grids {
+			grid.Subscribe(session)
+		}
+		return
+	}
+
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: "1m"})
+
+	if s.SkipLevelWallNotional > 0 {
+		session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+	}
+
+	s.subscribeEntryFilters(session)
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if s.isMultiSymbol() {
+		return s.runGrids(ctx, orderExecutor, session)
+	}
+
 	if s.GridNum == 0 {
 		s.GridNum = 10
 	}
@@ -213,28 +778,124 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		return fmt.Errorf("upper price (%f) should not be less than lower price (%f)", s.UpperPrice.Float64(), s.LowerPrice.Float64())
 	}
 
+	if s.Scale != nil {
+		if err := s.Scale.Solve(); err != nil {
+			return err
+		}
+	}
+
+	if s.Sizing != nil {
+		if err := s.Sizing.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.Recenter != nil {
+		if err := s.Recenter.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.InventorySkew != nil {
+		if err := s.InventorySkew.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i := range s.EntryFilters {
+		if err := s.EntryFilters[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	s.session = session
+	s.checkFeeSpread()
+
+	s.groupID = generateGroupID(ID + ":" + s.Symbol)
 	s.orderStore = bbgo.NewOrderStore(s.Symbol)
 	s.orderStore.BindStream(session.Stream)
 
-	// we don't persist orders so that we can not clear the previous orders for now. just need time to support this.
 	s.activeOrders = bbgo.NewLocalActiveOrderBook()
-	s.activeOrders.OnFilled(s.submitReverseOrder)
+	s.entryBlocked = make(map[types.SideType]bool)
+	s.accumulationOrders = types.NewSyncOrderMap()
+	s.activeOrders.OnFilled(s.handleFilledOrder)
 	s.activeOrders.BindStream(session.Stream)
 
-	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
-		defer wg.Done()
+	s.tradeCollector = types.NewTradeCollector(s.Market, types.ProfitMatchingFIFO)
+	s.tradeCollector.OnProfit(s.handleRoundTripProfit)
 
-		log.Infof("canceling active orders...")
+	s.gridPosition = &bbgo.Position{
+		Symbol:        s.Symbol,
+		BaseCurrency:  s.Market.BaseCurrency,
+		QuoteCurrency: s.Market.QuoteCurrency,
+	}
 
-		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
-			log.WithError(err).Errorf("cancel order error")
-		}
-	})
+	if s.Risk != nil {
+		controller := risk.NewController(s.Risk, s.Market, s.gridPosition, orderExecutor)
+		controller.OrderStore = s.orderStore
+		controller.OnTriggered(func(reason risk.Reason, price float64) {
+			s.Notify("%s grid %s triggered at %f, canceling the grid orders", s.Symbol, reason, price)
+
+			if err := s.cancelGridOrders(context.Background(), session); err != nil {
+				s.Log.WithError(err).Errorf("cancel order error")
+			}
+
+			s.resetState()
+		})
+		controller.BindStream(session.Stream)
+
+		// the orders are rejected after the controls are triggered
+		orderExecutor = controller.Wrap(orderExecutor)
+		s.OrderExecutor = orderExecutor
+	}
+
+	resumed, err := s.loadState(ctx, session)
+	if err != nil {
+		return err
+	}
+
+	if s.OrderCheckInterval > 0 {
+		detector := bbgo.NewStuckOrderDetector(s.Symbol, session.Exchange, s.activeOrders)
+		detector.Notifiability = s.Notifiability
+		detector.Interval = s.OrderCheckInterval.Duration()
+		detector.MaxAge = s.MaxOrderAge.Duration()
+		go detector.Run(ctx)
+	}
+
+	if s.InventoryTarget != nil {
+		go s.reportInventoryDrift(ctx, session)
+	}
+
+	s.subscribeSignals()
 
 	session.Stream.OnTradeUpdate(s.tradeUpdateHandler)
 	session.Stream.OnConnect(func() {
+		// the grid orders are resumed from the previous state, no need to place the grid orders again
+		if resumed || s.isSuspended() {
+			s.placeRecoveredCounterOrders()
+			return
+		}
+
 		s.placeGridOrders(orderExecutor, session)
 	})
 
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != types.Interval1m {
+			return
+		}
+
+		// retry the grid placement after the warm-up
+		if s.pendingPlacement {
+			s.placeGridOrders(orderExecutor, session)
+		}
+
+		if len(s.pendingCounterOrders) > 0 {
+			s.retryPendingCounterOrders()
+		}
+
+		s.updateEntryFilters(orderExecutor, session)
+		s.checkRecenter(ctx, kline)
+	})
+
 	return nil
 }
@@ -0,0 +1,296 @@
+package grid
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testOrderExecutor creates the submitted orders with the sequential order IDs
+type testOrderExecutor struct {
+	mu          sync.Mutex
+	orderID     uint64
+	submitted   []types.SubmitOrder
+	submitError error
+}
+
+func (e *testOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.submitError != nil {
+		return nil, e.submitError
+	}
+
+	for _, o := range orders {
+		e.orderID++
+		e.submitted = append(e.submitted, o)
+		createdOrders = append(createdOrders, types.Order{
+			SubmitOrder: o,
+			OrderID:     e.orderID,
+			Status:      types.OrderStatusNew,
+			IsWorking:   true,
+		})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *testOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *testOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+func (e *testOrderExecutor) Submitted() []types.SubmitOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]types.SubmitOrder(nil), e.submitted...)
+}
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+	MinNotional:   1.0,
+	MinQuantity:   0.0001,
+	StepSize:      0.0001,
+	TickSize:      0.01,
+}
+
+func newTestStrategy(executor bbgo.OrderExecutor) *Strategy {
+	s := &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		OrderExecutor:  executor,
+		Market:         testMarket,
+		Symbol:         "BTCUSDT",
+		ProfitSpread:   fixedpoint.NewFromFloat(100.0),
+		GridNum:        10,
+		UpperPrice:     fixedpoint.NewFromFloat(10000.0),
+		LowerPrice:     fixedpoint.NewFromFloat(9000.0),
+	}
+
+	s.orderStore = bbgo.NewOrderStore(s.Symbol)
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	s.accumulationOrders = types.NewSyncOrderMap()
+	s.entryBlocked = make(map[types.SideType]bool)
+	return s
+}
+
+// newTestSession creates a session of the mock exchange with the given last price of BTCUSDT
+func newTestSession(t *testing.T, lastPrice float64) *bbgo.ExchangeSession {
+	exchange := mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil)
+	session := bbgo.NewExchangeSession("binance", exchange)
+	session.Account.UpdateBalances(types.BalanceMap{
+		"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+	})
+
+	now := time.Now()
+	exchange.PushKLine(types.KLine{
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1m,
+		StartTime: now.Add(-time.Minute),
+		EndTime:   now,
+		Close:     lastPrice,
+		Closed:    true,
+	})
+
+	if !assert.NoError(t, session.UpdatePrices(context.Background())) {
+		t.FailNow()
+	}
+
+	return session
+}
+
+func filledOrder(orderID uint64, side types.SideType, price, quantity float64) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     side,
+			Type:     types.OrderTypeLimit,
+			Price:    price,
+			Quantity: quantity,
+		},
+		OrderID:          orderID,
+		Status:           types.OrderStatusFilled,
+		ExecutedQuantity: quantity,
+	}
+}
+
+func TestStrategy_accumulateProfit(t *testing.T) {
+	executor := &testOrderExecutor{orderID: 100}
+	s := newTestStrategy(executor)
+	s.AccumulateBase = true
+
+	// the initial sell order is placed from the base balance, it doesn't close a round trip
+	s.handleFilledOrder(filledOrder(1, types.SideTypeSell, 9600.0, 0.01))
+	assert.Equal(t, fixedpoint.Value(0), s.accumulatedProfit)
+	if submitted := executor.Submitted(); assert.Len(t, submitted, 1) {
+		assert.Equal(t, types.SideTypeBuy, submitted[0].Side)
+		assert.Equal(t, 9500.0, submitted[0].Price)
+	}
+
+	// the counter sell order of the filled buy order closes the round trip
+	s.handleFilledOrder(filledOrder(2, types.SideTypeBuy, 9500.0, 0.01))
+	submitted := executor.Submitted()
+	if !assert.Len(t, submitted, 2) {
+		return
+	}
+	assert.Equal(t, types.SideTypeSell, submitted[1].Side)
+	assert.Equal(t, 9600.0, submitted[1].Price)
+
+	s.handleFilledOrder(filledOrder(executor.orderID, types.SideTypeSell, 9600.0, 0.01))
+
+	// profit = (9600 - 9500) * 0.01 = 1.0, the accumulation order is placed at 9600 - 100 - 100
+	submitted = executor.Submitted()
+	if !assert.Len(t, submitted, 4) {
+		return
+	}
+
+	accumulation := submitted[2]
+	assert.Equal(t, types.SideTypeBuy, accumulation.Side)
+	assert.Equal(t, 9400.0, accumulation.Price)
+	assert.Equal(t, testMarket.TruncateQuantity(1.0/9400.0), accumulation.Quantity)
+	assert.Equal(t, types.OrderTags{"reason": "accumulate"}, accumulation.Tags)
+	assert.Equal(t, fixedpoint.Value(0), s.accumulatedProfit)
+	assert.Empty(t, s.roundTripPrices)
+
+	// the accumulation order is kept without the counter order
+	s.handleFilledOrder(filledOrder(103, types.SideTypeBuy, 9400.0, accumulation.Quantity))
+	assert.Len(t, executor.Submitted(), 4)
+}
+
+func TestStrategy_submitReverseOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		order       types.Order
+		lastPrice   float64
+		catchUp     bool
+		long        bool
+		fixedAmount float64
+		side        types.SideType
+		price       float64
+		quantity    float64
+	}{
+		{
+			name:      "filled buy places the sell order one spread up",
+			order:     filledOrder(1, types.SideTypeBuy, 9500.0, 0.01),
+			lastPrice: 9500.0,
+			side:      types.SideTypeSell,
+			price:     9600.0,
+			quantity:  0.01,
+		},
+		{
+			name:      "filled sell places the buy order one spread down",
+			order:     filledOrder(1, types.SideTypeSell, 9600.0, 0.01),
+			lastPrice: 9600.0,
+			side:      types.SideTypeBuy,
+			price:     9500.0,
+			quantity:  0.01,
+		},
+		{
+			name:      "sell order catches up with the price above the counter price",
+			order:     filledOrder(1, types.SideTypeBuy, 9500.0, 0.01),
+			lastPrice: 9750.0,
+			catchUp:   true,
+			side:      types.SideTypeSell,
+			price:     9800.0,
+			quantity:  0.01,
+		},
+		{
+			name:      "buy order catches up with the price below the counter price",
+			order:     filledOrder(1, types.SideTypeSell, 9600.0, 0.01),
+			lastPrice: 9500.0,
+			catchUp:   true,
+			side:      types.SideTypeBuy,
+			price:     9400.0,
+			quantity:  0.01,
+		},
+		{
+			name:      "counter price is kept without catching up",
+			order:     filledOrder(1, types.SideTypeBuy, 9500.0, 0.01),
+			lastPrice: 9750.0,
+			side:      types.SideTypeSell,
+			price:     9600.0,
+			quantity:  0.01,
+		},
+		{
+			name:      "long mode buys back with the same amount",
+			order:     filledOrder(1, types.SideTypeSell, 9600.0, 0.01),
+			lastPrice: 9600.0,
+			long:      true,
+			side:      types.SideTypeBuy,
+			price:     9500.0,
+			quantity:  9600.0 * 0.01 / 9500.0,
+		},
+		{
+			name:        "fixed amount",
+			order:       filledOrder(1, types.SideTypeBuy, 9500.0, 0.01),
+			lastPrice:   9500.0,
+			fixedAmount: 192.0,
+			side:        types.SideTypeSell,
+			price:       9600.0,
+			quantity:    0.02,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &testOrderExecutor{}
+			s := newTestStrategy(executor)
+			s.session = newTestSession(t, tt.lastPrice)
+			s.CatchUp = tt.catchUp
+			s.Long = tt.long
+			s.FixedAmount = fixedpoint.NewFromFloat(tt.fixedAmount)
+
+			assert.NoError(t, s.submitReverseOrder(tt.order))
+
+			submitted := executor.Submitted()
+			if !assert.Len(t, submitted, 1) {
+				return
+			}
+
+			assert.Equal(t, tt.side, submitted[0].Side)
+			assert.Equal(t, types.OrderTypeLimit, submitted[0].Type)
+			assert.InDelta(t, tt.price, submitted[0].Price, 1e-8)
+			assert.InDelta(t, tt.quantity, submitted[0].Quantity, 1e-8)
+			assert.Len(t, s.activeOrders.Orders(), 1)
+		})
+	}
+}
+
+func TestStrategy_placeGridOrders_tags(t *testing.T) {
+	executor := &testOrderExecutor{}
+	s := newTestStrategy(executor)
+	s.Quantity = 0.01
+	s.session = newTestSession(t, 9500.0)
+
+	s.placeGridOrders(executor, s.session)
+
+	// the sell orders from 9600 to 10000, the bids are not placed without the quote balance
+	submitted := executor.Submitted()
+	if assert.Len(t, submitted, 5) {
+		for i, o := range submitted {
+			assert.Equal(t, types.OrderTags{"grid_level": strconv.Itoa(i + 1)}, o.Tags)
+		}
+	}
+
+	// the counter order stays at the level of the filled order
+	filled := filledOrder(3, types.SideTypeSell, submitted[2].Price, submitted[2].Quantity)
+	filled.Tags = submitted[2].Tags
+	assert.NoError(t, s.submitReverseOrder(filled))
+
+	submitted = executor.Submitted()
+	if assert.Len(t, submitted, 6) {
+		assert.Equal(t, types.SideTypeBuy, submitted[5].Side)
+		assert.Equal(t, types.OrderTags{"grid_level": "3"}, submitted[5].Tags)
+	}
+}
@@ -0,0 +1,54 @@
+package grid
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// wallNotional sums the notional of the same side of the book between the grid level and the mid price,
+// for a buy level, it's the bids between the level price and the mid price,
+// for a sell level, it's the asks between the mid price and the level price.
+// It returns zero if either side of the book is empty.
+func wallNotional(book types.OrderBook, side types.SideType, price fixedpoint.Value) fixedpoint.Value {
+	bestBid, hasBid := book.BestBid()
+	bestAsk, hasAsk := book.BestAsk()
+	if !hasBid || !hasAsk {
+		return 0
+	}
+
+	mid := (bestBid.Price + bestAsk.Price).Div(fixedpoint.NewFromInt(2))
+
+	var notional fixedpoint.Value
+	switch side {
+	case types.SideTypeBuy:
+		for _, pv := range book.Bids {
+			if pv.Price >= price && pv.Price <= mid {
+				notional += pv.Price.Mul(pv.Volume)
+			}
+		}
+
+	case types.SideTypeSell:
+		for _, pv := range book.Asks {
+			if pv.Price <= price && pv.Price >= mid {
+				notional += pv.Price.Mul(pv.Volume)
+			}
+		}
+	}
+
+	return notional
+}
+
+// shouldSkipLevel checks if the grid level should be skipped because of a large wall between the level and the mid price
+func (s *Strategy) shouldSkipLevel(book *types.OrderBook, side types.SideType, price fixedpoint.Value) bool {
+	if s.SkipLevelWallNotional <= 0 || book == nil {
+		return false
+	}
+
+	notional := wallNotional(*book, side, price)
+	if notional < s.SkipLevelWallNotional {
+		return false
+	}
+
+	s.Log.Infof("skipping %s level at %f, wall notional %f >= %f", side, price.Float64(), notional.Float64(), s.SkipLevelWallNotional.Float64())
+	return true
+}
@@ -0,0 +1,62 @@
+package grid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func pv(price, volume float64) types.PriceVolume {
+	return types.PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}
+}
+
+func TestWallNotional(t *testing.T) {
+	book := types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{pv(100.0, 1.0), pv(99.0, 2.0), pv(98.0, 50.0), pv(97.0, 1.0)},
+		Asks:   types.PriceVolumeSlice{pv(101.0, 1.0), pv(102.0, 2.0), pv(103.0, 50.0), pv(104.0, 1.0)},
+	}
+
+	tests := []struct {
+		name     string
+		side     types.SideType
+		price    float64
+		expected float64
+	}{
+		{name: "buy level below the best bid", side: types.SideTypeBuy, price: 99.0, expected: 100.0 + 198.0},
+		{name: "buy level at the wall", side: types.SideTypeBuy, price: 98.0, expected: 100.0 + 198.0 + 4900.0},
+		{name: "buy level above the mid price", side: types.SideTypeBuy, price: 100.8, expected: 0.0},
+		{name: "sell level above the best ask", side: types.SideTypeSell, price: 102.0, expected: 101.0 + 204.0},
+		{name: "sell level at the wall", side: types.SideTypeSell, price: 103.0, expected: 101.0 + 204.0 + 5150.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notional := wallNotional(book, tt.side, fixedpoint.NewFromFloat(tt.price))
+			assert.InDelta(t, tt.expected, notional.Float64(), 1e-6)
+		})
+	}
+
+	// the mid price is unknown without the asks
+	assert.Equal(t, fixedpoint.Value(0), wallNotional(types.OrderBook{Bids: book.Bids}, types.SideTypeBuy, fixedpoint.NewFromFloat(98.0)))
+}
+
+func TestStrategy_shouldSkipLevel(t *testing.T) {
+	book := &types.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{pv(100.0, 1.0), pv(99.0, 2.0), pv(98.0, 50.0)},
+		Asks:   types.PriceVolumeSlice{pv(101.0, 1.0), pv(102.0, 2.0)},
+	}
+
+	s := newTestStrategy(&testOrderExecutor{})
+	assert.False(t, s.shouldSkipLevel(book, types.SideTypeBuy, fixedpoint.NewFromFloat(98.0)), "the wall check is disabled")
+
+	s.SkipLevelWallNotional = fixedpoint.NewFromFloat(1000.0)
+	assert.False(t, s.shouldSkipLevel(book, types.SideTypeBuy, fixedpoint.NewFromFloat(99.0)))
+	assert.True(t, s.shouldSkipLevel(book, types.SideTypeBuy, fixedpoint.NewFromFloat(98.0)))
+	assert.False(t, s.shouldSkipLevel(book, types.SideTypeSell, fixedpoint.NewFromFloat(102.0)))
+	assert.False(t, s.shouldSkipLevel(nil, types.SideTypeBuy, fixedpoint.NewFromFloat(98.0)))
+}
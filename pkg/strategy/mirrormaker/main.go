@@ -6,8 +6,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -19,13 +17,13 @@ var defaultMargin = fixedpoint.NewFromFloat(0.01)
 
 var defaultQuantity = fixedpoint.NewFromFloat(0.001)
 
-var log = logrus.WithField("strategy", ID)
-
 func init() {
 	bbgo.RegisterStrategy(ID, &Strategy{})
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	*bbgo.Graceful
 	*bbgo.Persistence
 
@@ -65,18 +63,20 @@ func (s *Strategy) ID() string {
 }
 
 func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	sourceSession, ok := sessions[s.SourceExchange]
 	if !ok {
 		panic(fmt.Errorf("source exchange %s is not defined", s.SourceExchange))
 	}
 
-	log.Infof("subscribing %s from %s", s.Symbol, s.SourceExchange)
+	s.Log.Infof("subscribing %s from %s", s.Symbol, s.SourceExchange)
 	sourceSession.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
 }
 
 func (s *Strategy) updateQuote(ctx context.Context) {
 	if err := s.makerSession.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
-		log.WithError(err).Errorf("can not cancel orders")
+		s.Log.WithError(err).Errorf("can not cancel orders")
 		return
 	}
 
@@ -90,7 +90,7 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 
 	bestBidPrice := sourceBook.Bids[0].Price
 	bestAskPrice := sourceBook.Asks[0].Price
-	log.Infof("best bid price %f, best ask price: %f", bestBidPrice.Float64(), bestAskPrice.Float64())
+	s.Log.Infof("best bid price %f, best ask price: %f", bestBidPrice.Float64(), bestAskPrice.Float64())
 
 	bidQuantity := s.Quantity
 	bidPrice := bestBidPrice.MulFloat64(1.0 - s.BidMargin.Float64())
@@ -98,7 +98,7 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 	askQuantity := s.Quantity
 	askPrice := bestAskPrice.MulFloat64(1.0 + s.AskMargin.Float64())
 
-	log.Infof("quote bid price: %f ask price: %f", bidPrice.Float64(), askPrice.Float64())
+	s.Log.Infof("quote bid price: %f ask price: %f", bidPrice.Float64(), askPrice.Float64())
 
 	var submitOrders []types.SubmitOrder
 
@@ -120,8 +120,8 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 		hedgeQuota.QuoteAsset.Add(b.Available)
 	}
 
-	log.Infof("maker quota: %+v", makerQuota)
-	log.Infof("hedge quota: %+v", hedgeQuota)
+	s.Log.Infof("maker quota: %+v", makerQuota)
+	s.Log.Infof("hedge quota: %+v", hedgeQuota)
 
 	for i := 0; i < s.NumLayers; i++ {
 		// bid orders
@@ -175,7 +175,7 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 	makerOrderExecutor := &bbgo.ExchangeOrderExecutor{Session: s.makerSession}
 	makerOrders, err := makerOrderExecutor.SubmitOrders(ctx, submitOrders...)
 	if err != nil {
-		log.WithError(err).Errorf("order submit error")
+		s.Log.WithError(err).Errorf("order submit error")
 		return
 	}
 
@@ -184,9 +184,9 @@ func (s *Strategy) updateQuote(ctx context.Context) {
 }
 
 func (s *Strategy) handleTradeUpdate(trade types.Trade) {
-	log.Infof("received trade %+v", trade)
+	s.Log.Infof("received trade %+v", trade)
 	if s.orderStore.Exists(trade.OrderID) {
-		log.Infof("identified trade %d with an existing order: %d", trade.ID, trade.OrderID)
+		s.Log.Infof("identified trade %d with an existing order: %d", trade.ID, trade.OrderID)
 
 		q := fixedpoint.NewFromFloat(trade.Quantity)
 		if trade.Side == types.SideTypeSell {
@@ -196,13 +196,15 @@ func (s *Strategy) handleTradeUpdate(trade types.Trade) {
 		s.Position.AtomicAdd(q)
 
 		pos := s.Position.AtomicLoad()
-		log.Warnf("position changed: %f", pos.Float64())
+		s.Log.Warnf("position changed: %f", pos.Float64())
 
 		s.lastPrice = trade.Price
 	}
 }
 
 func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	if s.UpdateInterval == 0 {
 		s.UpdateInterval = time.Second
 	}
@@ -269,9 +271,9 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 	s.stopC = make(chan struct{})
 
 	if err := s.Persistence.Load(&s.Position, "position"); err != nil {
-		log.WithError(err).Warnf("can not load position")
+		s.Log.WithError(err).Warnf("can not load position")
 	} else {
-		log.Infof("position is loaded successfully, position=%f", s.Position.Float64())
+		s.Log.Infof("position is loaded successfully, position=%f", s.Position.Float64())
 	}
 
 	go func() {
@@ -298,11 +300,11 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 		close(s.stopC)
 
 		if err := s.Persistence.Save(&s.Position, "position"); err != nil {
-			log.WithError(err).Error("persistence save error")
+			s.Log.WithError(err).Error("persistence save error")
 		}
 
 		if err := s.makerSession.Exchange.CancelOrders(ctx, s.activeMakerOrders.Orders()...); err != nil {
-			log.WithError(err).Errorf("can not cancel orders")
+			s.Log.WithError(err).Errorf("can not cancel orders")
 		}
 	})
 
@@ -0,0 +1,527 @@
+package pricealert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// The alert conditions are the expressions evaluated on the closed klines, for example:
+//
+//   close crossAbove 60000
+//   change(24) >= 5%
+//   sma(7) crossAbove sma(25) and volume > 100
+//   not (close > lowest(20)) or close(1) < 30000
+//
+// The fields (open, high, low, close, volume) are the values of the last kline, field(n) is the value of n klines ago.
+// The functions are sma(n), ewma(n), change(n) (the close price change ratio over n klines), highest(n) and lowest(n).
+// The comparisons and the logical operators return 1 or 0, the values are NaN if the klines are not enough.
+
+// node is the node of the parsed expression, the offset is the number of the klines before the last kline
+type node interface {
+	eval(window types.KLineWindow, offset int) float64
+
+	// lookback is the number of the klines required to evaluate the node
+	lookback() int
+}
+
+type numberNode float64
+
+func (n numberNode) eval(window types.KLineWindow, offset int) float64 {
+	return float64(n)
+}
+
+func (n numberNode) lookback() int {
+	return 0
+}
+
+var fieldNames = map[string]struct{}{
+	"open":   {},
+	"high":   {},
+	"low":    {},
+	"close":  {},
+	"volume": {},
+}
+
+type fieldNode struct {
+	field string
+	shift int
+}
+
+func (n fieldNode) eval(window types.KLineWindow, offset int) float64 {
+	i := len(window) - 1 - offset - n.shift
+	if i < 0 {
+		return math.NaN()
+	}
+
+	k := window[i]
+	switch n.field {
+	case "open":
+		return k.Open
+	case "high":
+		return k.High
+	case "low":
+		return k.Low
+	case "volume":
+		return k.Volume
+	}
+
+	return k.Close
+}
+
+func (n fieldNode) lookback() int {
+	return n.shift + 1
+}
+
+var functionNames = map[string]struct{}{
+	"sma":     {},
+	"ewma":    {},
+	"change":  {},
+	"highest": {},
+	"lowest":  {},
+}
+
+type functionNode struct {
+	name   string
+	period int
+}
+
+func (n functionNode) eval(window types.KLineWindow, offset int) float64 {
+	end := len(window) - offset
+	start := end - n.lookback()
+	if start < 0 {
+		return math.NaN()
+	}
+
+	klines := window[start:end]
+	switch n.name {
+	case "sma":
+		var sum float64
+		for _, k := range klines {
+			sum += k.Close
+		}
+		return sum / float64(len(klines))
+
+	case "ewma":
+		// the average is seeded with the first close price of the window
+		alpha := 2.0 / float64(n.period+1)
+		avg := klines[0].Close
+		for _, k := range klines[1:] {
+			avg = alpha*k.Close + (1-alpha)*avg
+		}
+		return avg
+
+	case "change":
+		first := klines[0].Close
+		if first == 0 {
+			return math.NaN()
+		}
+		return klines[len(klines)-1].Close/first - 1.0
+
+	case "highest":
+		highest := klines[0].High
+		for _, k := range klines[1:] {
+			highest = math.Max(highest, k.High)
+		}
+		return highest
+
+	case "lowest":
+		lowest := klines[0].Low
+		for _, k := range klines[1:] {
+			lowest = math.Min(lowest, k.Low)
+		}
+		return lowest
+	}
+
+	return math.NaN()
+}
+
+func (n functionNode) lookback() int {
+	switch n.name {
+	case "change":
+		return n.period + 1
+	case "ewma":
+		// the ewma needs more klines than the period to converge
+		return n.period * 3
+	}
+
+	return n.period
+}
+
+type unaryNode struct {
+	op string
+	x  node
+}
+
+func (n unaryNode) eval(window types.KLineWindow, offset int) float64 {
+	x := n.x.eval(window, offset)
+	if math.IsNaN(x) {
+		// the condition is unknown without enough klines, "not" should not make it true
+		return x
+	}
+
+	if n.op == "not" {
+		return boolValue(!truthy(x))
+	}
+
+	return -x
+}
+
+func (n unaryNode) lookback() int {
+	return n.x.lookback()
+}
+
+type binaryNode struct {
+	op   string
+	x, y node
+}
+
+func (n binaryNode) eval(window types.KLineWindow, offset int) float64 {
+	switch n.op {
+	case "and":
+		return boolValue(truthy(n.x.eval(window, offset)) && truthy(n.y.eval(window, offset)))
+	case "or":
+		return boolValue(truthy(n.x.eval(window, offset)) || truthy(n.y.eval(window, offset)))
+	case "crossabove", "crossunder":
+		prev := binaryNode{op: "<=", x: n.x, y: n.y}
+		cur := binaryNode{op: ">", x: n.x, y: n.y}
+		if n.op == "crossunder" {
+			prev.op, cur.op = ">=", "<"
+		}
+		return boolValue(truthy(prev.eval(window, offset+1)) && truthy(cur.eval(window, offset)))
+	}
+
+	x, y := n.x.eval(window, offset), n.y.eval(window, offset)
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return math.NaN()
+	}
+
+	switch n.op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		return x / y
+	case ">":
+		return boolValue(x > y)
+	case ">=":
+		return boolValue(x >= y)
+	case "<":
+		return boolValue(x < y)
+	case "<=":
+		return boolValue(x <= y)
+	case "==":
+		return boolValue(x == y)
+	case "!=":
+		return boolValue(x != y)
+	}
+
+	return math.NaN()
+}
+
+func (n binaryNode) lookback() int {
+	lookback := n.x.lookback()
+	if l := n.y.lookback(); l > lookback {
+		lookback = l
+	}
+
+	if n.op == "crossabove" || n.op == "crossunder" {
+		lookback++
+	}
+
+	return lookback
+}
+
+func truthy(v float64) bool {
+	return !math.IsNaN(v) && v != 0
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// parseCondition parses the condition expression, the keywords and the names are case-insensitive
+func parseCondition(s string) (node, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.tokens[p.pos], s)
+	}
+
+	return n, nil
+}
+
+func tokenize(s string) (tokens []string, err error) {
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || r == '.':
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			if j < len(runes) && runes[j] == '%' {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, strings.ToLower(string(runes[i:j])))
+			i = j
+
+		case strings.ContainsRune("<>=!", r):
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else if r == '=' || r == '!' {
+				return nil, fmt.Errorf("unexpected %q in %q", r, s)
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+
+		case strings.ContainsRune("+-*/(),", r):
+			tokens = append(tokens, string(r))
+			i++
+
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", r, s)
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) expect(t string) error {
+	if got := p.next(); got != t {
+		if len(got) == 0 {
+			return fmt.Errorf("expect %q, got the end of the expression", t)
+		}
+		return fmt.Errorf("expect %q, got %q", t, got)
+	}
+
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "or")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseNot, "and")
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek() == "not" {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return unaryNode{op: "not", x: x}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	x, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op := p.peek(); op {
+	case ">", ">=", "<", "<=", "==", "!=", "crossabove", "crossunder", "crossbelow":
+		p.next()
+		if op == "crossbelow" {
+			op = "crossunder"
+		}
+
+		y, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+
+		return binaryNode{op: op, x: x, y: y}, nil
+	}
+
+	return x, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	return p.parseBinary(p.parseProduct, "+", "-")
+}
+
+func (p *parser) parseProduct() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+// parseBinary parses the left-associative binary operators of the same precedence
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		matched := false
+		for _, o := range ops {
+			if op == o {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return x, nil
+		}
+
+		p.next()
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		x = binaryNode{op: op, x: x, y: y}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.peek() == "-" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return unaryNode{op: "-", x: x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch {
+	case len(t) == 0:
+		return nil, fmt.Errorf("unexpected end of the expression")
+
+	case t == "(":
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		return x, p.expect(")")
+
+	case unicode.IsDigit(rune(t[0])) || t[0] == '.':
+		return parseNumber(t)
+	}
+
+	if _, ok := fieldNames[t]; ok {
+		if p.peek() != "(" {
+			return fieldNode{field: t}, nil
+		}
+
+		shift, err := p.parsePeriod(t, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return fieldNode{field: t, shift: shift}, nil
+	}
+
+	if _, ok := functionNames[t]; ok {
+		if p.peek() != "(" {
+			return nil, fmt.Errorf("%s requires the period, e.g., %s(20)", t, t)
+		}
+
+		period, err := p.parsePeriod(t, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return functionNode{name: t, period: period}, nil
+	}
+
+	return nil, fmt.Errorf("unknown name %q", t)
+}
+
+// parsePeriod parses the integer argument of the field or the function in the parentheses
+func (p *parser) parsePeriod(name string, min int) (int, error) {
+	if err := p.expect("("); err != nil {
+		return 0, err
+	}
+
+	t := p.next()
+	period, err := strconv.Atoi(t)
+	if err != nil || period < min {
+		return 0, fmt.Errorf("invalid argument %q of %s, it should be an integer >= %d", t, name, min)
+	}
+
+	return period, p.expect(")")
+}
+
+func parseNumber(t string) (node, error) {
+	percent := strings.HasSuffix(t, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(t, "%"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", t)
+	}
+
+	if percent {
+		v /= 100.0
+	}
+
+	return numberNode(v), nil
+}
@@ -0,0 +1,143 @@
+package pricealert
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// closeWindow creates the kline window of the close prices, the high and the low are 1 away from the close
+func closeWindow(closes ...float64) (window types.KLineWindow) {
+	for _, c := range closes {
+		window.Add(types.KLine{Open: c, High: c + 1, Low: c - 1, Close: c, Volume: 10})
+	}
+
+	return window
+}
+
+func TestParseCondition_errors(t *testing.T) {
+	tests := []string{
+		"",
+		"close >",
+		"close = 100",
+		"close ! 100",
+		"close # 100",
+		"(close > 100",
+		"close > 100)",
+		"close 100",
+		"price > 100",
+		"sma > 100",
+		"sma(0) > 100",
+		"sma(x) > 100",
+		"close(-1) > 100",
+		"close(1.5) > 100",
+		"1..2 > 1",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := parseCondition(tt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseCondition_eval(t *testing.T) {
+	tests := []struct {
+		when     string
+		window   types.KLineWindow
+		expected float64
+	}{
+		// precedence
+		{when: "1 + 2 * 3", expected: 7},
+		{when: "(1 + 2) * 3", expected: 9},
+		{when: "10 - 4 - 3", expected: 3},
+		{when: "12 / 3 / 2", expected: 2},
+		{when: "-2 * 3", expected: -6},
+		{when: "1 + 1 > 1", expected: 1},
+		{when: "1 > 2 or 2 > 1 and 0", expected: 0},
+		{when: "not 1 > 2", expected: 1},
+		{when: "not not 1", expected: 1},
+
+		// percent
+		{when: "5%", expected: 0.05},
+		{when: "change(1) >= 5%", window: closeWindow(100, 105), expected: 1},
+		{when: "change(1) >= 5%", window: closeWindow(100, 104), expected: 0},
+
+		// fields and functions
+		{when: "close", window: closeWindow(1, 2, 3), expected: 3},
+		{when: "CLOSE(1)", window: closeWindow(1, 2, 3), expected: 2},
+		{when: "high(2)", window: closeWindow(1, 2, 3), expected: 2},
+		{when: "volume", window: closeWindow(1, 2, 3), expected: 10},
+		{when: "SMA(3)", window: closeWindow(1, 2, 3), expected: 2},
+		{when: "highest(2)", window: closeWindow(1, 5, 3), expected: 6},
+		{when: "lowest(3)", window: closeWindow(1, 5, 3), expected: 0},
+		{when: "ewma(1)", window: closeWindow(1, 2, 3), expected: 3},
+
+		// crosses
+		{when: "close crossAbove 100", window: closeWindow(99, 101), expected: 1},
+		{when: "close crossAbove 100", window: closeWindow(100, 101), expected: 1},
+		{when: "close crossAbove 100", window: closeWindow(101, 102), expected: 0},
+		{when: "close crossUnder 100", window: closeWindow(101, 99), expected: 1},
+		{when: "close crossUnder 100", window: closeWindow(99, 98), expected: 0},
+		{when: "close crossBelow 100", window: closeWindow(101, 99), expected: 1},
+		{when: "sma(2) crossAbove sma(3)", window: closeWindow(3, 2, 1, 4), expected: 1},
+		{when: "close crossAbove 100", window: closeWindow(101), expected: 0},
+
+		// the values are NaN without enough klines
+		{when: "sma(5)", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "close(3)", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "change(3)", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "sma(5) > 1", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "-sma(5)", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "not (close > lowest(20))", window: closeWindow(1, 2, 3), expected: math.NaN()},
+		{when: "not (close > lowest(20)) or close < 2", window: closeWindow(1, 2, 3), expected: 0},
+		{when: "not (close > lowest(20)) or close < 5", window: closeWindow(1, 2, 3), expected: 1},
+		{when: "sma(5) > 1 and close > 1", window: closeWindow(1, 2, 3), expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			n, err := parseCondition(tt.when)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			v := n.eval(tt.window, 0)
+			if math.IsNaN(tt.expected) {
+				assert.True(t, math.IsNaN(v), "expected NaN, got %f", v)
+				return
+			}
+
+			assert.InDelta(t, tt.expected, v, 1e-9)
+		})
+	}
+}
+
+func TestParseCondition_lookback(t *testing.T) {
+	tests := []struct {
+		when     string
+		expected int
+	}{
+		{when: "1 > 2", expected: 0},
+		{when: "close > 100", expected: 1},
+		{when: "close(2) > 100", expected: 3},
+		{when: "change(24) > 5%", expected: 25},
+		{when: "ewma(10) > 100", expected: 30},
+		{when: "close crossAbove 100", expected: 2},
+		{when: "sma(20) crossAbove sma(50)", expected: 51},
+		{when: "close > 100 and not (lowest(20) < 90)", expected: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			n, err := parseCondition(tt.when)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.expected, n.lookback())
+			}
+		})
+	}
+}
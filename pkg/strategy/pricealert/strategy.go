@@ -2,7 +2,9 @@ package pricealert
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"sync"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/types"
@@ -14,6 +16,18 @@ func init() {
 	bbgo.RegisterStrategy(ID, &Strategy{})
 }
 
+// Alert is the alert condition evaluated on the closed klines, the alert is notified when the condition
+// changes from false to true, so that it won't be notified again until the condition becomes false.
+type Alert struct {
+	Name string `json:"name"`
+
+	// When is the condition expression, e.g., "close crossAbove 60000" or "change(24) > 5%"
+	When string `json:"when"`
+
+	condition node
+	triggered bool
+}
+
 type Strategy struct {
 	// The notification system will be injected into the strategy automatically.
 	bbgo.Notifiability
@@ -22,30 +36,121 @@ type Strategy struct {
 	Symbol    string  `json:"symbol"`
 	Interval  string  `json:"interval"`
 	MinChange float64 `json:"minChange"`
+
+	// Alerts are the conditions evaluated on the closed klines of the interval, no order is submitted
+	Alerts []*Alert `json:"alerts"`
+
+	mu sync.Mutex
 }
 
 func (s *Strategy) ID() string {
 	return ID
 }
 
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	for i, alert := range s.Alerts {
+		condition, err := parseCondition(alert.When)
+		if err != nil {
+			return fmt.Errorf("invalid condition of the alert #%d: %w", i, err)
+		}
+
+		alert.condition = condition
+		if len(alert.Name) == 0 {
+			alert.Name = alert.When
+		}
+	}
+
+	return nil
+}
+
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
 }
 
+func (s *Strategy) notify(format string, args ...interface{}) {
+	if channel, ok := s.RouteSymbol(s.Symbol); ok {
+		s.NotifyTo(channel, format, args...)
+	} else {
+		s.Notify(format, args...)
+	}
+}
+
+// evaluateAlerts evaluates the alert conditions on the kline window, it returns the alerts that are just triggered
+func (s *Strategy) evaluateAlerts(window types.KLineWindow) (triggered []*Alert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, alert := range s.Alerts {
+		active := truthy(alert.condition.eval(window, 0))
+		if active && !alert.triggered {
+			triggered = append(triggered, alert)
+		}
+
+		alert.triggered = active
+	}
+
+	return triggered
+}
+
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
-	session.Stream.OnKLine(func(kline types.KLine) {
-		market, ok := session.Market(kline.Symbol)
-		if !ok {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	market, ok := session.Market(s.Symbol)
+	if !ok {
+		return fmt.Errorf("market %s is not defined", s.Symbol)
+	}
+
+	// the kline change alert is kept for the configs without the alerts
+	if s.MinChange > 0 || len(s.Alerts) == 0 {
+		session.Stream.OnKLine(func(kline types.KLine) {
+			if kline.Symbol != s.Symbol {
+				return
+			}
+
+			if math.Abs(kline.GetChange()) > s.MinChange {
+				s.notify("%s hit price %s, change %f", s.Symbol, market.FormatPrice(kline.Close), kline.GetChange())
+			}
+		})
+	}
+
+	if len(s.Alerts) == 0 {
+		return nil
+	}
+
+	var window types.KLineWindow
+	var windowSize = 1
+	for _, alert := range s.Alerts {
+		if l := alert.condition.lookback(); l > windowSize {
+			windowSize = l
+		}
+	}
+
+	// the alerts are armed with the warm-up klines, so that the conditions that are already true are not notified on restart
+	if store, ok := session.MarketDataStore(s.Symbol); ok {
+		if klines, ok := store.KLinesOfInterval(types.Interval(s.Interval)); ok {
+			window = klines.Tail(windowSize)
+			s.evaluateAlerts(window)
+		}
+	}
+
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != s.Symbol || kline.Interval != types.Interval(s.Interval) {
 			return
 		}
 
-		if math.Abs(kline.GetChange()) > s.MinChange {
-			if channel, ok := s.RouteSymbol(s.Symbol); ok {
-				s.NotifyTo(channel, "%s hit price %s, change %f", s.Symbol, market.FormatPrice(kline.Close), kline.GetChange())
-			} else {
-				s.Notify("%s hit price %s, change %f", s.Symbol, market.FormatPrice(kline.Close), kline.GetChange())
-			}
+		window.Add(kline)
+		window.Truncate(windowSize)
+
+		for _, alert := range s.evaluateAlerts(window) {
+			s.notify("%s alert %s: price %s, condition %s", s.Symbol, alert.Name, market.FormatPrice(kline.Close), alert.When)
 		}
 	})
+
 	return nil
 }
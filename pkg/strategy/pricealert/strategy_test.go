@@ -0,0 +1,94 @@
+package pricealert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func alertNames(alerts []*Alert) (names []string) {
+	for _, alert := range alerts {
+		names = append(names, alert.Name)
+	}
+
+	return names
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{
+		Symbol: "BTCUSDT",
+		Alerts: []*Alert{
+			{Name: "breakout", When: "close crossAbove 100"},
+			{When: "close > 100"},
+		},
+	}
+
+	assert.NoError(t, s.Validate())
+	assert.Equal(t, "breakout", s.Alerts[0].Name)
+	assert.Equal(t, "close > 100", s.Alerts[1].Name)
+
+	s.Alerts = append(s.Alerts, &Alert{When: "close >"})
+	assert.Error(t, s.Validate())
+
+	assert.Error(t, (&Strategy{}).Validate())
+}
+
+func TestStrategy_evaluateAlerts(t *testing.T) {
+	s := &Strategy{
+		Symbol: "BTCUSDT",
+		Alerts: []*Alert{
+			{Name: "breakout", When: "close crossAbove 100"},
+			{Name: "above", When: "close > 100"},
+		},
+	}
+
+	if !assert.NoError(t, s.Validate()) {
+		return
+	}
+
+	window := closeWindow(99)
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	window.Add(types.KLine{Close: 101})
+	assert.Equal(t, []string{"breakout", "above"}, alertNames(s.evaluateAlerts(window)))
+
+	// the alert that stays true is not notified again
+	window.Add(types.KLine{Close: 102})
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	window.Add(types.KLine{Close: 99})
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	// the alerts are re-armed after the conditions become false
+	window.Add(types.KLine{Close: 101})
+	assert.Equal(t, []string{"breakout", "above"}, alertNames(s.evaluateAlerts(window)))
+}
+
+func TestStrategy_evaluateAlerts_warmUp(t *testing.T) {
+	s := &Strategy{
+		Symbol: "BTCUSDT",
+		Alerts: []*Alert{
+			{Name: "new low", When: "not (close > lowest(3))"},
+		},
+	}
+
+	if !assert.NoError(t, s.Validate()) {
+		return
+	}
+
+	// the condition is unknown before the klines are enough
+	window := closeWindow(100)
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	window.Add(types.KLine{Close: 101, High: 102, Low: 100})
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	window.Add(types.KLine{Close: 102, High: 103, Low: 101})
+	assert.Empty(t, s.evaluateAlerts(window))
+
+	// the close is not above the lowest low of the last 3 klines
+	window.Add(types.KLine{Close: 96, High: 101, Low: 96})
+	assert.Equal(t, []string{"new low"}, alertNames(s.evaluateAlerts(window)))
+}
@@ -0,0 +1,321 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "rebalance"
+
+const defaultThreshold = 0.01
+
+func init() {
+	// Register the pointer of the strategy struct,
+	// so that bbgo knows what struct to be used to unmarshal the configs (YAML or JSON)
+	// Note: built-in strategies need to imported manually in the bbgo cmd package.
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy keeps the portfolio of the session at the target weights, e.g., BTC 40%, ETH 30% and USDT 30%.
+// The currencies are valued in the quote currency, and each of them is traded against the quote currency
+// only when its weight drifts out of the threshold.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	// Interval is the interval of checking the drift, defaults to 1h
+	Interval types.Interval `json:"interval"`
+
+	// QuoteCurrency is the currency used for valuing the portfolio and trading the other currencies, e.g., USDT
+	QuoteCurrency string `json:"quoteCurrency"`
+
+	// TargetWeights are the target weights of the currencies including the quote currency, the weights should sum up to 1
+	TargetWeights map[string]fixedpoint.Value `json:"targetWeights"`
+
+	// Threshold is the tolerance band of the weight drift, e.g., 0.01 rebalances the currency drifting more than 1%, defaults to 0.01
+	Threshold fixedpoint.Value `json:"threshold"`
+
+	// DryRun only notifies the rebalance orders without submitting them
+	DryRun bool `json:"dryRun"`
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+// symbols returns the symbols of the currencies traded against the quote currency
+func (s *Strategy) symbols() map[string]string {
+	var symbols = make(map[string]string)
+	for currency := range s.TargetWeights {
+		if currency != s.QuoteCurrency {
+			symbols[currency] = currency + s.QuoteCurrency
+		}
+	}
+
+	return symbols
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if s.Interval == "" {
+		s.Interval = types.Interval1h
+	}
+
+	// the last prices are updated by the klines
+	for _, symbol := range s.symbols() {
+		session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: string(s.Interval)})
+	}
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.QuoteCurrency) == 0 {
+		return fmt.Errorf("quoteCurrency is required")
+	}
+
+	if len(s.TargetWeights) < 2 {
+		return fmt.Errorf("targetWeights should contain at least 2 currencies")
+	}
+
+	var sum float64
+	for currency, weight := range s.TargetWeights {
+		if weight < 0 {
+			return fmt.Errorf("the target weight of %s should not be negative", currency)
+		}
+
+		sum += weight.Float64()
+	}
+
+	if math.Abs(sum-1.0) > 1e-6 {
+		return fmt.Errorf("the target weights should sum up to 1, got %f", sum)
+	}
+
+	if _, ok := s.TargetWeights[s.QuoteCurrency]; !ok {
+		return fmt.Errorf("the target weight of the quote currency %s is not defined", s.QuoteCurrency)
+	}
+
+	// the balances of the equivalent currencies are counted as the quote currency
+	for currency := range s.TargetWeights {
+		if currency != s.QuoteCurrency && types.EquivalentCurrencies(currency, s.QuoteCurrency) {
+			return fmt.Errorf("the target weight of %s is counted in the equivalent quote currency %s", currency, s.QuoteCurrency)
+		}
+	}
+
+	return nil
+}
+
+// allocation is the current allocation of a currency
+type allocation struct {
+	Currency string
+	Value    float64
+	Price    float64
+	Weight   float64
+	Target   float64
+}
+
+// Drift is the weight above (positive) or below (negative) the target weight
+func (a allocation) Drift() float64 {
+	return a.Weight - a.Target
+}
+
+// allocations values the balances in the quote currency
+func (s *Strategy) allocations(session *bbgo.ExchangeSession) (allocations []allocation, total float64, err error) {
+	balances := session.Account.Balances()
+	for currency, weight := range s.TargetWeights {
+		price := 1.0
+		if currency != s.QuoteCurrency {
+			var ok bool
+			price, ok = session.LastPrice(currency + s.QuoteCurrency)
+			if !ok || price <= 0 {
+				return nil, 0, fmt.Errorf("the last price of %s is not available", currency+s.QuoteCurrency)
+			}
+		}
+
+		var quantity float64
+		if currency == s.QuoteCurrency {
+			balance := balances.EquivalentBalance(currency)
+			quantity = balance.Available.Float64() + balance.Locked.Float64()
+		} else if balance, ok := balances[currency]; ok {
+			quantity = balance.Available.Float64() + balance.Locked.Float64()
+		}
+
+		allocations = append(allocations, allocation{
+			Currency: currency,
+			Value:    quantity * price,
+			Price:    price,
+			Target:   weight.Float64(),
+		})
+		total += quantity * price
+	}
+
+	if total <= 0 {
+		return nil, 0, fmt.Errorf("the portfolio value is zero")
+	}
+
+	for i := range allocations {
+		allocations[i].Weight = allocations[i].Value / total
+	}
+
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Currency < allocations[j].Currency
+	})
+
+	return allocations, total, nil
+}
+
+// selectRebalances returns the currencies traded to the target weights. The currencies drifting out of the threshold
+// are selected first, then the currencies drifting the most are added until the quote currency is within the threshold.
+func selectRebalances(allocations []allocation, quoteCurrency string, threshold float64) (selected []allocation) {
+	var quoteDrift float64
+	var rest []allocation
+	for _, a := range allocations {
+		if a.Currency == quoteCurrency {
+			quoteDrift += a.Drift()
+			continue
+		}
+
+		if math.Abs(a.Drift()) > threshold {
+			selected = append(selected, a)
+		} else {
+			rest = append(rest, a)
+		}
+	}
+
+	// trading a currency to the target weight moves its drift to the quote currency
+	for _, a := range selected {
+		quoteDrift += a.Drift()
+	}
+
+	sort.Slice(rest, func(i, j int) bool {
+		return math.Abs(rest[i].Drift()) > math.Abs(rest[j].Drift())
+	})
+
+	for _, a := range rest {
+		if math.Abs(quoteDrift) <= threshold {
+			break
+		}
+
+		// only the currencies drifting in the opposite direction reduce the quote drift
+		if a.Drift()*quoteDrift >= 0 {
+			continue
+		}
+
+		selected = append(selected, a)
+		quoteDrift += a.Drift()
+	}
+
+	return selected
+}
+
+func (s *Strategy) rebalance(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
+	allocations, total, err := s.allocations(session)
+	if err != nil {
+		s.Log.WithError(err).Errorf("can not value the portfolio")
+		return
+	}
+
+	for _, a := range allocations {
+		s.Log.Infof("%s weight %.2f%%, target %.2f%%, value %f %s", a.Currency, a.Weight*100.0, a.Target*100.0, a.Value, s.QuoteCurrency)
+	}
+
+	selected := selectRebalances(allocations, s.QuoteCurrency, s.Threshold.Float64())
+	if len(selected) == 0 {
+		return
+	}
+
+	var orders []types.SubmitOrder
+	var descriptions []string
+	for _, a := range selected {
+		symbol := a.Currency + s.QuoteCurrency
+		market, ok := session.Market(symbol)
+		if !ok {
+			s.Log.Errorf("market %s is not defined", symbol)
+			continue
+		}
+
+		side := types.SideTypeBuy
+		if a.Drift() > 0 {
+			side = types.SideTypeSell
+		}
+
+		quantity := market.RoundDownQuantity(math.Abs(a.Drift()) * total / a.Price)
+		if quantity < market.MinQuantity || quantity*a.Price < market.MinNotional {
+			s.Log.Infof("%s rebalance quantity %f is below the market filters, skipping", symbol, quantity)
+			continue
+		}
+
+		orders = append(orders, types.SubmitOrder{
+			Symbol:   symbol,
+			Side:     side,
+			Type:     types.OrderTypeMarket,
+			Market:   market,
+			Quantity: quantity,
+		})
+
+		descriptions = append(descriptions, fmt.Sprintf("%s %s %s (%.2f%% -> %.2f%%)",
+			side, market.FormatQuantity(quantity), a.Currency, a.Weight*100.0, a.Target*100.0))
+	}
+
+	if len(orders) == 0 {
+		return
+	}
+
+	// the sell orders are submitted first, so that the quote currency is available for the buy orders
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].Side == types.SideTypeSell && orders[j].Side != types.SideTypeSell
+	})
+
+	if s.DryRun {
+		s.Notify("rebalance (dry run): %s", strings.Join(descriptions, ", "))
+		return
+	}
+
+	s.Notify("rebalancing: %s", strings.Join(descriptions, ", "))
+
+	if _, err := orderExecutor.SubmitOrders(ctx, orders...); err != nil {
+		s.Log.WithError(err).Errorf("can not submit the rebalance orders")
+	}
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, "")
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if s.Threshold == 0 {
+		s.Threshold = fixedpoint.NewFromFloat(defaultThreshold)
+	}
+
+	symbols := s.symbols()
+	for _, symbol := range symbols {
+		if _, ok := session.Market(symbol); !ok {
+			return fmt.Errorf("market %s is not defined", symbol)
+		}
+	}
+
+	// all the symbols close the kline at the same time, only one of them triggers the rebalance
+	var triggerSymbol string
+	for _, symbol := range symbols {
+		if len(triggerSymbol) == 0 || symbol < triggerSymbol {
+			triggerSymbol = symbol
+		}
+	}
+
+	session.Stream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Symbol != triggerSymbol || kline.Interval != s.Interval {
+			return
+		}
+
+		s.rebalance(ctx, orderExecutor, session)
+	})
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package rebalance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// testOrderExecutor records the submitted orders
+type testOrderExecutor struct {
+	submitted []types.SubmitOrder
+}
+
+func (e *testOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		e.submitted = append(e.submitted, o)
+		createdOrders = append(createdOrders, types.Order{SubmitOrder: o, OrderID: uint64(len(e.submitted)), Status: types.OrderStatusFilled})
+	}
+
+	return createdOrders, nil
+}
+
+func (e *testOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *testOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+var testMarkets = types.MarketMap{
+	"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.0001, StepSize: 0.0001, MinNotional: 10.0},
+	"ETHUSDT": {Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, MinNotional: 10.0},
+}
+
+func newTestStrategy() *Strategy {
+	return &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:  &bbgo.Notifiability{},
+		QuoteCurrency:  "USDT",
+		TargetWeights: map[string]fixedpoint.Value{
+			"BTC":  fixedpoint.NewFromFloat(0.4),
+			"ETH":  fixedpoint.NewFromFloat(0.3),
+			"USDT": fixedpoint.NewFromFloat(0.3),
+		},
+		Threshold: fixedpoint.NewFromFloat(0.01),
+	}
+}
+
+// newTestSession creates a session of the mock exchange with the balances and the last prices of the symbols
+func newTestSession(t *testing.T, balances map[string]float64, lastPrices map[string]float64) *bbgo.ExchangeSession {
+	exchange := mock.New(types.ExchangeBinance, testMarkets, nil)
+	session := bbgo.NewExchangeSession("binance", exchange)
+
+	balanceMap := types.BalanceMap{}
+	for currency, available := range balances {
+		balanceMap[currency] = types.Balance{Currency: currency, Available: fixedpoint.NewFromFloat(available)}
+	}
+	session.Account.UpdateBalances(balanceMap)
+
+	now := time.Now()
+	for symbol, price := range lastPrices {
+		exchange.PushKLine(types.KLine{
+			Symbol:    symbol,
+			Interval:  types.Interval1m,
+			StartTime: now.Add(-time.Minute),
+			EndTime:   now,
+			Close:     price,
+			Closed:    true,
+		})
+	}
+
+	if !assert.NoError(t, session.UpdatePrices(context.Background())) {
+		t.FailNow()
+	}
+
+	return session
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s := newTestStrategy()
+	assert.NoError(t, s.Validate())
+
+	s.TargetWeights["ETH"] = fixedpoint.NewFromFloat(0.2)
+	assert.Error(t, s.Validate())
+
+	s = newTestStrategy()
+	s.QuoteCurrency = "BUSD"
+	assert.Error(t, s.Validate())
+
+	// USDC is counted in the USDT balance
+	s = newTestStrategy()
+	s.TargetWeights["ETH"] = fixedpoint.NewFromFloat(0.2)
+	s.TargetWeights["USDC"] = fixedpoint.NewFromFloat(0.1)
+	assert.Error(t, s.Validate())
+}
+
+func TestSelectRebalances(t *testing.T) {
+	currencies := func(allocations []allocation) (names []string) {
+		for _, a := range allocations {
+			names = append(names, a.Currency)
+		}
+		return names
+	}
+
+	t.Run("within the threshold", func(t *testing.T) {
+		selected := selectRebalances([]allocation{
+			{Currency: "BTC", Weight: 0.405, Target: 0.4},
+			{Currency: "ETH", Weight: 0.295, Target: 0.3},
+			{Currency: "USDT", Weight: 0.3, Target: 0.3},
+		}, "USDT", 0.01)
+		assert.Empty(t, selected)
+	})
+
+	t.Run("out of the threshold", func(t *testing.T) {
+		selected := selectRebalances([]allocation{
+			{Currency: "BTC", Weight: 0.43, Target: 0.4},
+			{Currency: "ETH", Weight: 0.305, Target: 0.3},
+			{Currency: "USDT", Weight: 0.265, Target: 0.3},
+		}, "USDT", 0.01)
+
+		// the quote currency is within the threshold after trading BTC
+		assert.Equal(t, []string{"BTC"}, currencies(selected))
+	})
+
+	t.Run("quote currency out of the threshold", func(t *testing.T) {
+		selected := selectRebalances([]allocation{
+			{Currency: "BTC", Weight: 0.409, Target: 0.4},
+			{Currency: "ETH", Weight: 0.307, Target: 0.3},
+			{Currency: "USDT", Weight: 0.284, Target: 0.3},
+		}, "USDT", 0.01)
+
+		// the currency drifting the most is traded to move the quote currency back to the threshold
+		assert.Equal(t, []string{"BTC"}, currencies(selected))
+	})
+}
+
+func TestStrategy_rebalance(t *testing.T) {
+	lastPrices := map[string]float64{"BTCUSDT": 10000.0, "ETHUSDT": 1000.0}
+
+	t.Run("drifted", func(t *testing.T) {
+		// BTC 50%, ETH 20% and USDT 30% including USDC
+		session := newTestSession(t, map[string]float64{"BTC": 0.5, "ETH": 2.0, "USDT": 2000.0, "USDC": 1000.0}, lastPrices)
+		executor := &testOrderExecutor{}
+
+		newTestStrategy().rebalance(context.Background(), executor, session)
+
+		if assert.Len(t, executor.submitted, 2) {
+			// the sell order is submitted first
+			assert.Equal(t, "BTCUSDT", executor.submitted[0].Symbol)
+			assert.Equal(t, types.SideTypeSell, executor.submitted[0].Side)
+			assert.Equal(t, types.OrderTypeMarket, executor.submitted[0].Type)
+			assert.InDelta(t, 0.1, executor.submitted[0].Quantity, 1e-9)
+
+			assert.Equal(t, "ETHUSDT", executor.submitted[1].Symbol)
+			assert.Equal(t, types.SideTypeBuy, executor.submitted[1].Side)
+			assert.InDelta(t, 1.0, executor.submitted[1].Quantity, 1e-9)
+		}
+	})
+
+	t.Run("balanced", func(t *testing.T) {
+		session := newTestSession(t, map[string]float64{"BTC": 0.4, "ETH": 3.0, "USDT": 3000.0}, lastPrices)
+		executor := &testOrderExecutor{}
+
+		newTestStrategy().rebalance(context.Background(), executor, session)
+		assert.Empty(t, executor.submitted)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		session := newTestSession(t, map[string]float64{"BTC": 0.5, "ETH": 2.0, "USDT": 3000.0}, lastPrices)
+		executor := &testOrderExecutor{}
+
+		s := newTestStrategy()
+		s.DryRun = true
+		s.rebalance(context.Background(), executor, session)
+		assert.Empty(t, executor.submitted)
+	})
+
+	t.Run("below the min notional", func(t *testing.T) {
+		// the ETH drift is 0.5% of 1000 USDT, which is below the min notional
+		session := newTestSession(t, map[string]float64{"BTC": 0.0445, "ETH": 0.305, "USDT": 250.0}, lastPrices)
+		executor := &testOrderExecutor{}
+
+		s := newTestStrategy()
+		s.Threshold = fixedpoint.NewFromFloat(0.001)
+		s.rebalance(context.Background(), executor, session)
+
+		if assert.Len(t, executor.submitted, 1) {
+			assert.Equal(t, "BTCUSDT", executor.submitted[0].Symbol)
+			assert.Equal(t, types.SideTypeSell, executor.submitted[0].Side)
+			assert.InDelta(t, 0.0045, executor.submitted[0].Quantity, 1e-9)
+		}
+	})
+
+	t.Run("missing last price", func(t *testing.T) {
+		session := newTestSession(t, map[string]float64{"BTC": 0.5, "ETH": 2.0, "USDT": 3000.0}, map[string]float64{"BTCUSDT": 10000.0})
+		executor := &testOrderExecutor{}
+
+		newTestStrategy().rebalance(context.Background(), executor, session)
+		assert.Empty(t, executor.submitted)
+	})
+}
@@ -3,8 +3,6 @@ package skeleton
 import (
 	"context"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -16,6 +14,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	Symbol string `json:"symbol"`
 
 	types.Market
@@ -31,6 +31,8 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	session.Stream.OnKLineClosed(func(kline types.KLine) {
 		quoteBalance, ok := session.Account.Balance(s.Market.QuoteCurrency)
 		if !ok {
@@ -46,7 +48,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		})
 
 		if err != nil {
-			log.WithError(err).Error("submit order error")
+			s.Log.WithError(err).Error("submit order error")
 		}
 	})
 
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"math"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -15,7 +13,7 @@ const ID = "swing"
 
 // The indicators (SMA and EWMA) that we want to use are returning float64 data.
 type Float64Indicator interface {
-	Last() float64
+	Last() (float64, bool)
 }
 
 func init() {
@@ -26,6 +24,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	// The notification system will be injected into the strategy automatically.
 	// This field will be injected automatically since it's a single exchange strategy.
 	*bbgo.Notifiability
@@ -87,6 +87,8 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	var inc Float64Indicator
 	var iw = types.IntervalWindow{Interval: s.MovingAverageInterval, Window: s.MovingAverageWindow}
 
@@ -109,10 +111,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			return
 		}
 
-		movingAveragePrice := inc.Last()
+		movingAveragePrice, ok := inc.Last()
 
-		// skip it if it's near zero
-		if movingAveragePrice < 0.0001 {
+		// skip it if it's not loaded yet
+		if !ok || movingAveragePrice <= 0 {
 			return
 		}
 
@@ -140,7 +142,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 					Quantity: quantity,
 				})
 				if err != nil {
-					log.WithError(err).Error("submit order error")
+					s.Log.WithError(err).Error("submit order error")
 				}
 			}
 		case types.DirectionDown:
@@ -156,7 +158,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 					Quantity: quantity,
 				})
 				if err != nil {
-					log.WithError(err).Error("submit order error")
+					s.Log.WithError(err).Error("submit order error")
 				}
 			}
 		}
@@ -6,8 +6,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -15,11 +13,9 @@ import (
 
 const ID = "trailingstop"
 
-var log = logrus.WithField("strategy", ID)
-
 // The indicators (SMA and EWMA) that we want to use are returning float64 data.
 type Float64Indicator interface {
-	Last() float64
+	Last() (float64, bool)
 }
 
 func init() {
@@ -30,6 +26,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	*bbgo.Graceful
 
 	// The notification system will be injected into the strategy automatically.
@@ -94,7 +92,7 @@ func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
 func (s *Strategy) clear(ctx context.Context, session *bbgo.ExchangeSession) {
 	if s.order.OrderID > 0 {
 		if err := session.Exchange.CancelOrders(ctx, s.order); err != nil {
-			log.WithError(err).Errorf("can not cancel trailingstop order: %+v", s.order)
+			s.Log.WithError(err).Errorf("can not cancel trailingstop order: %+v", s.order)
 		}
 
 		// clear out the existing order
@@ -103,17 +101,17 @@ func (s *Strategy) clear(ctx context.Context, session *bbgo.ExchangeSession) {
 }
 
 func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, indicator Float64Indicator, closePrice float64) {
-	movingAveragePrice := indicator.Last()
+	movingAveragePrice, ok := indicator.Last()
 
-	// skip it if it's near zero because it's not loaded yet
-	if movingAveragePrice < 0.0001 {
-		log.Warnf("moving average price is near 0: %f", movingAveragePrice)
+	// skip it because it's not loaded yet
+	if !ok || movingAveragePrice <= 0 {
+		s.Log.Warnf("moving average price is not ready: %f", movingAveragePrice)
 		return
 	}
 
 	// place stop limit order only when the closed price is greater than the moving average price
 	if closePrice <= movingAveragePrice {
-		log.Warnf("close price %f is less than moving average price %f", closePrice, movingAveragePrice)
+		s.Log.Warnf("close price %f is less than moving average price %f", closePrice, movingAveragePrice)
 		return
 	}
 
@@ -133,7 +131,7 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 
 	market, ok := session.Market(s.Symbol)
 	if !ok {
-		log.Errorf("market not found, symbol %s", s.Symbol)
+		s.Log.Errorf("market not found, symbol %s", s.Symbol)
 		return
 	}
 
@@ -146,7 +144,7 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 	}
 
 	if quantity.Float64()*closePrice < market.MinNotional {
-		log.Errorf("the amount of stop order (%f) is less than min notional %f", quantity.Float64()*closePrice, market.MinNotional)
+		s.Log.Errorf("the amount of stop order (%f) is less than min notional %f", quantity.Float64()*closePrice, market.MinNotional)
 		return
 	}
 
@@ -155,7 +153,7 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 		stopPrice = stopPrice * s.StopPriceRatio.Float64()
 	}
 
-	log.Infof("placing trailingstop order %s at stop price %f, quantity %f", s.Symbol, stopPrice, quantity.Float64())
+	s.Log.Infof("placing trailingstop order %s at stop price %f, quantity %f", s.Symbol, stopPrice, quantity.Float64())
 
 	retOrders, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
 		Symbol:    s.Symbol,
@@ -166,7 +164,7 @@ func (s *Strategy) place(ctx context.Context, orderExecutor bbgo.OrderExecutor,
 		Quantity:  quantity.Float64(),
 	})
 	if err != nil {
-		log.WithError(err).Error("submit order error")
+		s.Log.WithError(err).Error("submit order error")
 	}
 
 	if len(retOrders) > 0 {
@@ -201,6 +199,8 @@ func (s *Strategy) loadIndicator(sourceSession *bbgo.ExchangeSession) (Float64In
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	indicator, err := s.loadIndicator(session)
 	if err != nil {
 		return err
@@ -224,7 +224,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 
 	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
-		log.Infof("canceling trailingstop order...")
+		s.Log.Infof("canceling trailingstop order...")
 		s.clear(ctx, session)
 	})
 
@@ -236,6 +236,8 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 }
 
 func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
 	// source session
 	sourceSession := sessions[s.SourceExchangeName]
 
@@ -268,7 +270,7 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 
 	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
-		log.Infof("canceling trailingstop order...")
+		s.Log.Infof("canceling trailingstop order...")
 		s.clear(ctx, session)
 	})
 
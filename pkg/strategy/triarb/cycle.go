@@ -0,0 +1,160 @@
+package triarb
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// leg is one conversion of the arbitrage cycle, it converts the From currency into the To currency on the market
+type leg struct {
+	Market types.Market
+	Side   types.SideType
+	From   string
+	To     string
+}
+
+func newLeg(market types.Market, from string) (leg, bool) {
+	switch from {
+	case market.QuoteCurrency:
+		return leg{Market: market, Side: types.SideTypeBuy, From: from, To: market.BaseCurrency}, true
+
+	case market.BaseCurrency:
+		return leg{Market: market, Side: types.SideTypeSell, From: from, To: market.QuoteCurrency}, true
+
+	}
+
+	return leg{}, false
+}
+
+// bestLevel returns the price level taken by the leg, the best ask for buying and the best bid for selling
+func (l leg) bestLevel(depth types.BookDepth) types.PriceVolume {
+	if l.Side == types.SideTypeBuy {
+		return depth.BestAsk
+	}
+
+	return depth.BestBid
+}
+
+// quantity returns the order quantity (in the base currency) that spends the amount of the From currency
+func (l leg) quantity(amount, price float64) float64 {
+	if l.Side == types.SideTypeBuy {
+		return amount / price
+	}
+
+	return amount
+}
+
+// output returns the amount of the To currency received from the executed quantity, after the fee
+func (l leg) output(quantity, price, feeRate float64) float64 {
+	if l.Side == types.SideTypeBuy {
+		return quantity * (1.0 - feeRate)
+	}
+
+	return quantity * price * (1.0 - feeRate)
+}
+
+func (l leg) String() string {
+	return fmt.Sprintf("%s %s (%s -> %s)", l.Side, l.Market.Symbol, l.From, l.To)
+}
+
+// cycle is the triangular path that starts and ends with the same currency
+type cycle [3]leg
+
+func (c cycle) String() string {
+	return fmt.Sprintf("%s -> %s -> %s -> %s", c[0].From, c[1].From, c[2].From, c[2].To)
+}
+
+// findCycles returns both directions of the triangular cycle of the markets that start from the currency
+func findCycles(markets [3]types.Market, start string) (cycles []cycle, err error) {
+	var permutations = [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+
+	for _, p := range permutations {
+		var c cycle
+		var from = start
+		var ok = true
+
+		for i, idx := range p {
+			if c[i], ok = newLeg(markets[idx], from); !ok {
+				break
+			}
+
+			from = c[i].To
+		}
+
+		if ok && from == start {
+			cycles = append(cycles, c)
+		}
+	}
+
+	if len(cycles) == 0 {
+		return nil, fmt.Errorf("markets %s, %s and %s do not form a triangular cycle of %s",
+			markets[0].Symbol, markets[1].Symbol, markets[2].Symbol, start)
+	}
+
+	return cycles, nil
+}
+
+// opportunity is the evaluated cycle with the top of the books
+type opportunity struct {
+	Cycle cycle
+
+	// Prices are the limit prices of the legs
+	Prices [3]float64
+
+	// StartAmount and FinalAmount are the amounts of the start currency before and after the cycle
+	StartAmount float64
+	FinalAmount float64
+}
+
+func (o opportunity) ProfitRatio() float64 {
+	return o.FinalAmount/o.StartAmount - 1.0
+}
+
+// evaluate computes the cycle with the best levels of the books, the start amount is limited by maxAmount
+// and scaled down to fit the volumes of the best levels. ok is false if any book is missing, or any leg
+// is below the min quantity or the min notional of its market.
+func (c cycle) evaluate(depths map[string]types.BookDepth, maxAmount, feeRate float64) (o opportunity, ok bool) {
+	o.Cycle = c
+	o.StartAmount = maxAmount
+
+	var quantities [3]float64
+	var amount = maxAmount
+	for i, l := range c {
+		depth, found := depths[l.Market.Symbol]
+		if !found {
+			return o, false
+		}
+
+		level := l.bestLevel(depth)
+		price, volume := level.Price.Float64(), level.Volume.Float64()
+		if price <= 0 || volume <= 0 {
+			return o, false
+		}
+
+		quantity := l.quantity(amount, price)
+		if quantity > volume {
+			// the cycle is linear, scale down the previous legs to the volume of the best level
+			ratio := volume / quantity
+			o.StartAmount *= ratio
+			for j := 0; j < i; j++ {
+				quantities[j] *= ratio
+			}
+
+			quantity = volume
+		}
+
+		o.Prices[i] = price
+		quantities[i] = quantity
+		amount = l.output(quantity, price, feeRate)
+	}
+
+	for i, l := range c {
+		if quantities[i] < l.Market.MinQuantity || quantities[i]*o.Prices[i] < l.Market.MinNotional {
+			return o, false
+		}
+	}
+
+	o.FinalAmount = amount
+	return o, true
+}
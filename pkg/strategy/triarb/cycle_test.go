@@ -0,0 +1,118 @@
+package triarb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarkets = [3]types.Market{
+	{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", MinQuantity: 0.0001, StepSize: 0.0001, MinNotional: 10.0},
+	{Symbol: "ETHBTC", BaseCurrency: "ETH", QuoteCurrency: "BTC", MinQuantity: 0.001, StepSize: 0.001, MinNotional: 0.0001},
+	{Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT", MinQuantity: 0.001, StepSize: 0.001, MinNotional: 10.0},
+}
+
+func testDepth(symbol string, bid, ask, volume float64) types.BookDepth {
+	return types.BookDepth{
+		Symbol:  symbol,
+		BestBid: types.PriceVolume{Price: fixedpoint.NewFromFloat(bid), Volume: fixedpoint.NewFromFloat(volume)},
+		BestAsk: types.PriceVolume{Price: fixedpoint.NewFromFloat(ask), Volume: fixedpoint.NewFromFloat(volume)},
+	}
+}
+
+// testDepths are the books of a profitable USDT -> BTC -> ETH -> USDT cycle, 1000 USDT is converted to 1020 USDT before the fees
+func testDepths() map[string]types.BookDepth {
+	return map[string]types.BookDepth{
+		"BTCUSDT": testDepth("BTCUSDT", 9990.0, 10000.0, 1.0),
+		"ETHBTC":  testDepth("ETHBTC", 0.0499, 0.05, 10.0),
+		"ETHUSDT": testDepth("ETHUSDT", 510.0, 511.0, 10.0),
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	cycles, err := findCycles(testMarkets, "USDT")
+	if !assert.NoError(t, err) || !assert.Len(t, cycles, 2) {
+		return
+	}
+
+	assert.Equal(t, "USDT -> BTC -> ETH -> USDT", cycles[0].String())
+	assert.Equal(t, []types.SideType{types.SideTypeBuy, types.SideTypeBuy, types.SideTypeSell},
+		[]types.SideType{cycles[0][0].Side, cycles[0][1].Side, cycles[0][2].Side})
+
+	assert.Equal(t, "USDT -> ETH -> BTC -> USDT", cycles[1].String())
+	assert.Equal(t, []types.SideType{types.SideTypeBuy, types.SideTypeSell, types.SideTypeSell},
+		[]types.SideType{cycles[1][0].Side, cycles[1][1].Side, cycles[1][2].Side})
+
+	_, err = findCycles(testMarkets, "BNB")
+	assert.Error(t, err)
+
+	_, err = findCycles([3]types.Market{testMarkets[0], testMarkets[1], {Symbol: "BNBUSDT", BaseCurrency: "BNB", QuoteCurrency: "USDT"}}, "USDT")
+	assert.Error(t, err)
+}
+
+func TestCycle_evaluate(t *testing.T) {
+	cycles, err := findCycles(testMarkets, "USDT")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("profitable", func(t *testing.T) {
+		o, ok := cycles[0].evaluate(testDepths(), 1000.0, 0.0)
+		if assert.True(t, ok) {
+			assert.Equal(t, [3]float64{10000.0, 0.05, 510.0}, o.Prices)
+			assert.InDelta(t, 1000.0, o.StartAmount, 1e-9)
+			assert.InDelta(t, 1020.0, o.FinalAmount, 1e-9)
+			assert.InDelta(t, 0.02, o.ProfitRatio(), 1e-9)
+		}
+	})
+
+	t.Run("fees", func(t *testing.T) {
+		o, ok := cycles[0].evaluate(testDepths(), 1000.0, 0.001)
+		if assert.True(t, ok) {
+			assert.InDelta(t, 1020.0*0.999*0.999*0.999, o.FinalAmount, 1e-9)
+		}
+	})
+
+	t.Run("losing direction", func(t *testing.T) {
+		o, ok := cycles[1].evaluate(testDepths(), 1000.0, 0.0)
+		if assert.True(t, ok) {
+			assert.Equal(t, [3]float64{511.0, 0.0499, 9990.0}, o.Prices)
+			assert.True(t, o.ProfitRatio() < 0)
+		}
+	})
+
+	t.Run("scaled down to the best level volume", func(t *testing.T) {
+		depths := testDepths()
+		depths["ETHBTC"] = testDepth("ETHBTC", 0.0499, 0.05, 1.0)
+
+		o, ok := cycles[0].evaluate(depths, 1000.0, 0.0)
+		if assert.True(t, ok) {
+			assert.InDelta(t, 500.0, o.StartAmount, 1e-9)
+			assert.InDelta(t, 510.0, o.FinalAmount, 1e-9)
+		}
+	})
+
+	t.Run("below the min notional", func(t *testing.T) {
+		_, ok := cycles[0].evaluate(testDepths(), 5.0, 0.0)
+		assert.False(t, ok)
+	})
+
+	t.Run("missing book", func(t *testing.T) {
+		depths := testDepths()
+		delete(depths, "ETHBTC")
+
+		_, ok := cycles[0].evaluate(depths, 1000.0, 0.0)
+		assert.False(t, ok)
+	})
+
+	t.Run("empty level", func(t *testing.T) {
+		depths := testDepths()
+		depths["ETHUSDT"] = testDepth("ETHUSDT", 510.0, 511.0, 0.0)
+
+		_, ok := cycles[0].evaluate(depths, 1000.0, 0.0)
+		assert.False(t, ok)
+	})
+}
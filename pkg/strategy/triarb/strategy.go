@@ -0,0 +1,368 @@
+package triarb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "triarb"
+
+const (
+	defaultFeeRate      = 0.001
+	defaultOrderTimeout = 10 * time.Second
+	defaultCooldown     = 5 * time.Second
+)
+
+func init() {
+	// Register the pointer of the strategy struct,
+	// so that bbgo knows what struct to be used to unmarshal the configs (YAML or JSON)
+	// Note: built-in strategies need to imported manually in the bbgo cmd package.
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy monitors three markets of one exchange, e.g., BTCUSDT, ETHBTC and ETHUSDT, and executes the triangular
+// arbitrage cycle (USDT -> BTC -> ETH -> USDT or the reversed one) when the profit after the fees is above the threshold.
+// The legs are executed one by one with the IOC limit orders at the prices of the opportunity, each leg continues
+// with the filled quantity of the previous leg, so the partial fills only leave the unconverted residual.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+
+	*bbgo.Graceful
+
+	// Symbols are the three markets of the triangular cycle
+	Symbols []string `json:"symbols"`
+
+	// StartCurrency is the currency the cycle starts from and ends with, e.g., USDT
+	StartCurrency string `json:"startCurrency"`
+
+	// MaxAmount is the max amount of the start currency spent by one cycle
+	MaxAmount fixedpoint.Value `json:"maxAmount"`
+
+	// FeeRate is the taker fee rate of each leg, defaults to 0.001
+	FeeRate fixedpoint.Value `json:"feeRate"`
+
+	// MinProfitRatio is the min profit ratio of the cycle after the fees, e.g., 0.001 for 0.1%
+	MinProfitRatio fixedpoint.Value `json:"minProfitRatio"`
+
+	// OrderTimeout is the time waiting for the leg order to be closed, the order is canceled after the timeout.
+	// It's for the exchanges that don't support IOC, defaults to 10s
+	OrderTimeout types.Duration `json:"orderTimeout"`
+
+	// Cooldown is the min interval between the cycles, defaults to 5s
+	Cooldown types.Duration `json:"cooldown"`
+
+	cycles []cycle
+	stores map[string]*bbgo.MarketDataStore
+
+	activeOrders *bbgo.LocalActiveOrderBook
+
+	// closedOrders receives the closed orders of the markets while a cycle is executing
+	closedOrders chan types.Order
+
+	// executing is set while a cycle is executing, only one cycle is executed at a time
+	executing int32
+
+	mu                sync.Mutex
+	lastExecutionTime time.Time
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	for _, symbol := range s.Symbols {
+		session.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+	}
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbols) != 3 {
+		return fmt.Errorf("triarb requires 3 symbols, got %d", len(s.Symbols))
+	}
+
+	if len(s.StartCurrency) == 0 {
+		return fmt.Errorf("startCurrency is not defined")
+	}
+
+	if s.MaxAmount <= 0 {
+		return fmt.Errorf("maxAmount should be greater than 0")
+	}
+
+	return nil
+}
+
+func (s *Strategy) isCycleSymbol(symbol string) bool {
+	_, ok := s.stores[symbol]
+	return ok
+}
+
+// depths returns the latest top of the books of the markets
+func (s *Strategy) depths() map[string]types.BookDepth {
+	var depths = make(map[string]types.BookDepth, len(s.stores))
+	for symbol, store := range s.stores {
+		if depth, ok := store.Depth(1); ok {
+			depths[symbol] = depth
+		}
+	}
+
+	return depths
+}
+
+// findOpportunity returns the most profitable cycle above the min profit ratio
+func (s *Strategy) findOpportunity() (best opportunity, found bool) {
+	depths := s.depths()
+	for _, c := range s.cycles {
+		o, ok := c.evaluate(depths, s.MaxAmount.Float64(), s.FeeRate.Float64())
+		if !ok || o.ProfitRatio() < s.MinProfitRatio.Float64() {
+			continue
+		}
+
+		if !found || o.ProfitRatio() > best.ProfitRatio() {
+			best, found = o, true
+		}
+	}
+
+	return best, found
+}
+
+func (s *Strategy) check(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) {
+	s.mu.Lock()
+	inCooldown := time.Since(s.lastExecutionTime) < s.Cooldown.Duration()
+	s.mu.Unlock()
+
+	if inCooldown || atomic.LoadInt32(&s.executing) == 1 {
+		return
+	}
+
+	o, ok := s.findOpportunity()
+	if !ok {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.executing, 0, 1) {
+		return
+	}
+
+	// the order updates are received from the same stream, execute the cycle in another goroutine to avoid blocking it
+	go func() {
+		defer atomic.StoreInt32(&s.executing, 0)
+		defer func() {
+			s.mu.Lock()
+			s.lastExecutionTime = time.Now()
+			s.mu.Unlock()
+		}()
+
+		s.execute(ctx, orderExecutor, session, o)
+	}()
+}
+
+func (s *Strategy) execute(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession, o opportunity) {
+	amount := o.StartAmount
+	if balance, ok := session.Account.Balance(s.StartCurrency); ok {
+		amount = math.Min(amount, balance.Available.Float64())
+	}
+
+	s.Log.Infof("found triangular arbitrage opportunity %s, expected profit ratio %.4f%%, start amount %f %s",
+		o.Cycle, o.ProfitRatio()*100.0, amount, s.StartCurrency)
+
+	startAmount := amount
+	for i, l := range o.Cycle {
+		price := o.Prices[i]
+		quantity := l.Market.RoundDownQuantity(l.quantity(amount, price))
+		if quantity <= 0 || quantity < l.Market.MinQuantity || quantity*price < l.Market.MinNotional {
+			s.reportResidual(o.Cycle, i, amount, fmt.Sprintf("the quantity %f is below the market filters", quantity))
+			return
+		}
+
+		createdOrders, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+			Symbol:      l.Market.Symbol,
+			Side:        l.Side,
+			Type:        types.OrderTypeLimit,
+			Market:      l.Market,
+			Quantity:    quantity,
+			Price:       price,
+			TimeInForce: "IOC",
+		})
+		if err != nil || len(createdOrders) == 0 {
+			s.Log.WithError(err).Errorf("can not place the leg order %s", l)
+			s.reportResidual(o.Cycle, i, amount, "the leg order can not be placed")
+			return
+		}
+
+		s.activeOrders.Add(createdOrders...)
+
+		executedQuantity, err := s.waitOrder(ctx, session, createdOrders[0])
+		if err != nil {
+			s.Log.WithError(err).Errorf("leg order %s is not closed", l)
+			s.reportResidual(o.Cycle, i, amount, "the leg order is not closed")
+			return
+		}
+
+		if executedQuantity <= 0 {
+			s.reportResidual(o.Cycle, i, amount, "the leg order is not filled")
+			return
+		}
+
+		if executedQuantity < quantity {
+			s.Log.Warnf("leg %s is partially filled %f/%f, continuing with the filled quantity", l, executedQuantity, quantity)
+
+			// the unfilled part of the first leg is still the start currency
+			if i == 0 {
+				startAmount = amount * executedQuantity / quantity
+			}
+		}
+
+		amount = l.output(executedQuantity, price, s.FeeRate.Float64())
+	}
+
+	profit := amount - startAmount
+	s.Notify("triangular arbitrage %s completed: %f %s -> %f %s, profit %f %s",
+		o.Cycle, startAmount, s.StartCurrency, amount, s.StartCurrency, profit, s.StartCurrency)
+}
+
+// reportResidual notifies the cycle stopped at the leg, the amount of the From currency of the leg is left unconverted
+func (s *Strategy) reportResidual(c cycle, legIndex int, amount float64, reason string) {
+	l := c[legIndex]
+	if legIndex == 0 {
+		s.Log.Infof("triangular arbitrage %s is aborted at the leg %s: %s", c, l, reason)
+		return
+	}
+
+	s.Notify("triangular arbitrage %s stopped at the leg %s: %s, %f %s is left unconverted",
+		c, l, reason, amount, l.From)
+}
+
+// waitOrder waits for the order to be closed and returns the executed quantity,
+// the order is canceled if it's not closed in the order timeout.
+func (s *Strategy) waitOrder(ctx context.Context, session *bbgo.ExchangeSession, order types.Order) (float64, error) {
+	if isClosed(order) {
+		return order.ExecutedQuantity, nil
+	}
+
+	timeout := time.After(s.OrderTimeout.Duration())
+	canceled := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+
+		case o := <-s.closedOrders:
+			if o.OrderID == order.OrderID {
+				return o.ExecutedQuantity, nil
+			}
+
+		case <-timeout:
+			if canceled {
+				return 0, fmt.Errorf("order %d is not closed after canceling", order.OrderID)
+			}
+
+			s.Log.Warnf("order %d is not closed in %s, canceling...", order.OrderID, s.OrderTimeout.Duration())
+			if err := session.Exchange.CancelOrders(ctx, order); err != nil {
+				s.Log.WithError(err).Errorf("cancel order error")
+			}
+
+			canceled = true
+			timeout = time.After(s.OrderTimeout.Duration())
+		}
+	}
+}
+
+func isClosed(order types.Order) bool {
+	switch order.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled, "":
+		return false
+	}
+
+	return true
+}
+
+func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, "")
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if s.FeeRate == 0 {
+		s.FeeRate = fixedpoint.NewFromFloat(defaultFeeRate)
+	}
+
+	if s.OrderTimeout == 0 {
+		s.OrderTimeout = types.Duration(defaultOrderTimeout)
+	}
+
+	if s.Cooldown == 0 {
+		s.Cooldown = types.Duration(defaultCooldown)
+	}
+
+	var markets [3]types.Market
+	s.stores = make(map[string]*bbgo.MarketDataStore)
+	for i, symbol := range s.Symbols {
+		market, ok := session.Market(symbol)
+		if !ok {
+			return fmt.Errorf("market %s is not found", symbol)
+		}
+
+		store, ok := session.MarketDataStore(symbol)
+		if !ok {
+			return fmt.Errorf("market data store of %s is not found", symbol)
+		}
+
+		markets[i] = market
+		s.stores[symbol] = store
+	}
+
+	cycles, err := findCycles(markets, s.StartCurrency)
+	if err != nil {
+		return err
+	}
+
+	s.cycles = cycles
+	for _, c := range cycles {
+		s.Log.Infof("monitoring triangular cycle %s", c)
+	}
+
+	s.closedOrders = make(chan types.Order, 100)
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+	s.activeOrders.BindStream(session.Stream)
+
+	session.Stream.OnOrderUpdate(func(order types.Order) {
+		if !s.isCycleSymbol(order.Symbol) || !isClosed(order) || atomic.LoadInt32(&s.executing) == 0 {
+			return
+		}
+
+		select {
+		case s.closedOrders <- order:
+		default:
+			s.Log.Warnf("closed order channel is full, dropping order %d", order.OrderID)
+		}
+	})
+
+	for _, store := range s.stores {
+		store.OnBookDepthUpdate(func(depth types.BookDepth) {
+			s.check(ctx, orderExecutor, session)
+		})
+	}
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := session.Exchange.CancelOrders(ctx, s.activeOrders.Orders()...); err != nil {
+			s.Log.WithError(err).Errorf("cancel order error")
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package triarb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// fillingOrderExecutor fills the IOC orders right away, the orders of the symbols in fillRatios are partially filled
+type fillingOrderExecutor struct {
+	orderID    uint64
+	submitted  []types.SubmitOrder
+	fillRatios map[string]float64
+}
+
+func (e *fillingOrderExecutor) SubmitOrders(ctx context.Context, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	for _, o := range orders {
+		e.orderID++
+		e.submitted = append(e.submitted, o)
+
+		order := types.Order{SubmitOrder: o, OrderID: e.orderID, Status: types.OrderStatusFilled, ExecutedQuantity: o.Quantity}
+		if ratio, ok := e.fillRatios[o.Symbol]; ok {
+			// the unfilled part of the IOC order is canceled
+			order.Status = types.OrderStatusCanceled
+			order.ExecutedQuantity = o.Quantity * ratio
+		}
+
+		createdOrders = append(createdOrders, order)
+	}
+
+	return createdOrders, nil
+}
+
+func (e *fillingOrderExecutor) OnTradeUpdate(cb func(trade types.Trade)) {}
+
+func (e *fillingOrderExecutor) OnOrderUpdate(cb func(order types.Order)) {}
+
+func newTestStrategy(t *testing.T, usdt float64) (*Strategy, *bbgo.ExchangeSession, opportunity) {
+	s := &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:  &bbgo.Notifiability{},
+		Symbols:        []string{"BTCUSDT", "ETHBTC", "ETHUSDT"},
+		StartCurrency:  "USDT",
+		MaxAmount:      fixedpoint.NewFromFloat(1000.0),
+	}
+	s.activeOrders = bbgo.NewLocalActiveOrderBook()
+
+	markets := types.MarketMap{}
+	for _, m := range testMarkets {
+		markets[m.Symbol] = m
+	}
+
+	session := bbgo.NewExchangeSession("binance", mock.New(types.ExchangeBinance, markets, nil))
+	session.Account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(usdt)},
+	})
+
+	cycles, err := findCycles(testMarkets, "USDT")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	o, ok := cycles[0].evaluate(testDepths(), s.MaxAmount.Float64(), 0.0)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+
+	return s, session, o
+}
+
+func TestStrategy_Validate(t *testing.T) {
+	s, _, _ := newTestStrategy(t, 1000.0)
+	assert.NoError(t, s.Validate())
+
+	s.Symbols = s.Symbols[:2]
+	assert.Error(t, s.Validate())
+
+	s, _, _ = newTestStrategy(t, 1000.0)
+	s.MaxAmount = 0
+	assert.Error(t, s.Validate())
+}
+
+func TestStrategy_execute(t *testing.T) {
+	// the start amount is limited by the available balance
+	s, session, o := newTestStrategy(t, 600.0)
+	executor := &fillingOrderExecutor{}
+
+	s.execute(context.Background(), executor, session, o)
+
+	if assert.Len(t, executor.submitted, 3) {
+		expected := []struct {
+			symbol   string
+			side     types.SideType
+			price    float64
+			quantity float64
+		}{
+			{"BTCUSDT", types.SideTypeBuy, 10000.0, 0.06},
+			{"ETHBTC", types.SideTypeBuy, 0.05, 1.2},
+			{"ETHUSDT", types.SideTypeSell, 510.0, 1.2},
+		}
+
+		for i, e := range expected {
+			order := executor.submitted[i]
+			assert.Equal(t, e.symbol, order.Symbol)
+			assert.Equal(t, e.side, order.Side)
+			assert.Equal(t, e.price, order.Price)
+			assert.InDelta(t, e.quantity, order.Quantity, 1e-9)
+			assert.Equal(t, types.TimeInForce("IOC"), order.TimeInForce)
+		}
+	}
+}
+
+func TestStrategy_execute_partialFill(t *testing.T) {
+	s, session, o := newTestStrategy(t, 1000.0)
+	executor := &fillingOrderExecutor{fillRatios: map[string]float64{"ETHBTC": 0.5}}
+
+	s.execute(context.Background(), executor, session, o)
+
+	// the last leg continues with the filled quantity of the partially filled leg
+	if assert.Len(t, executor.submitted, 3) {
+		assert.InDelta(t, 2.0, executor.submitted[1].Quantity, 1e-9)
+		assert.InDelta(t, 1.0, executor.submitted[2].Quantity, 1e-9)
+	}
+}
+
+func TestStrategy_execute_notFilled(t *testing.T) {
+	s, session, o := newTestStrategy(t, 1000.0)
+	executor := &fillingOrderExecutor{fillRatios: map[string]float64{"BTCUSDT": 0.0}}
+
+	s.execute(context.Background(), executor, session, o)
+
+	// the cycle is aborted at the first leg
+	assert.Len(t, executor.submitted, 1)
+}
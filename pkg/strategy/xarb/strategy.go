@@ -0,0 +1,519 @@
+package xarb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "xarb"
+
+const (
+	defaultFeeRate      = 0.001
+	defaultOrderTimeout = 10 * time.Second
+	defaultCooldown     = 3 * time.Second
+)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy compares the order books of the same symbol on two sessions, buys on the session with the lower ask
+// and sells on the session with the higher bid at the same time when the spread after the fees is above the threshold.
+//
+// Each arbitrage moves the base asset from the selling session to the buying session, the inventory imbalance
+// between the sessions is rebalanced by skewing the required spread: the direction that makes the imbalance worse
+// requires the larger spread, and the direction that reduces the imbalance is accepted with the smaller spread.
+type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
+	*bbgo.Notifiability
+	*bbgo.Graceful
+	*bbgo.Persistence
+
+	Symbol string `json:"symbol"`
+
+	// Sessions are the two sessions to arbitrage between
+	Sessions []string `json:"sessions"`
+
+	// Quantity is the max base quantity of one arbitrage
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// FeeRate is the taker fee rate of both sessions, defaults to 0.001
+	FeeRate fixedpoint.Value `json:"feeRate"`
+
+	// MinSpreadRatio is the min spread ratio after the fees, e.g., 0.001 for 0.1%
+	MinSpreadRatio fixedpoint.Value `json:"minSpreadRatio"`
+
+	// InventorySkew is the spread ratio added to the required spread at the max imbalance,
+	// the imbalance is (the base of the buying session - the base of the selling session) / the total base in [-1, 1].
+	InventorySkew fixedpoint.Value `json:"inventorySkew"`
+
+	// MaxImbalance stops the direction that moves the imbalance over it, e.g., 0.8, zero disables the limit
+	MaxImbalance fixedpoint.Value `json:"maxImbalance"`
+
+	// OrderTimeout is the time waiting for the IOC orders to be closed, the orders are canceled after the timeout.
+	OrderTimeout types.Duration `json:"orderTimeout"`
+
+	// Cooldown is the min interval between the arbitrages, defaults to 3s
+	Cooldown types.Duration `json:"cooldown"`
+
+	// Uncovered is the base quantity that is bought but not sold yet (negative if sold more than bought),
+	// it's covered by the market order on the next arbitrage check.
+	Uncovered fixedpoint.Value `json:"-"`
+
+	router   bbgo.OrderExecutionRouter
+	sessions map[string]*bbgo.ExchangeSession
+	markets  map[string]types.Market
+	stores   map[string]*bbgo.MarketDataStore
+
+	activeOrders map[string]*bbgo.LocalActiveOrderBook
+
+	// closedOrders receives the closed orders of each session while an arbitrage is executing
+	closedOrders map[string]chan types.Order
+
+	executing int32
+
+	mu                sync.Mutex
+	lastExecutionTime time.Time
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	for _, name := range s.Sessions {
+		session, ok := sessions[name]
+		if !ok {
+			panic(fmt.Errorf("session %s is not defined", name))
+		}
+
+		session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
+	}
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return fmt.Errorf("symbol is required")
+	}
+
+	if len(s.Sessions) != 2 || s.Sessions[0] == s.Sessions[1] {
+		return fmt.Errorf("xarb requires 2 different sessions, got %v", s.Sessions)
+	}
+
+	if s.Quantity <= 0 {
+		return fmt.Errorf("quantity should be greater than 0")
+	}
+
+	return nil
+}
+
+// arbitrage buys on the buy session and sells on the sell session
+type arbitrage struct {
+	BuySession  string
+	SellSession string
+	BuyPrice    float64
+	SellPrice   float64
+	Quantity    float64
+}
+
+// SpreadRatio returns the spread ratio after the fees of both sides
+func (a arbitrage) SpreadRatio(feeRate float64) float64 {
+	return (a.SellPrice*(1.0-feeRate) - a.BuyPrice*(1.0+feeRate)) / a.BuyPrice
+}
+
+func (s *Strategy) baseBalance(sessionName string) float64 {
+	session := s.sessions[sessionName]
+	if b, ok := session.Account.Balance(s.markets[sessionName].BaseCurrency); ok {
+		return b.Available.Float64() + b.Locked.Float64()
+	}
+
+	return 0.0
+}
+
+// imbalance returns (the base of the session - the base of the other session) / the total base in [-1, 1]
+func (s *Strategy) imbalance(sessionName, otherSessionName string) float64 {
+	base, otherBase := s.baseBalance(sessionName), s.baseBalance(otherSessionName)
+	total := base + otherBase
+	if total <= 0 {
+		return 0.0
+	}
+
+	return (base - otherBase) / total
+}
+
+// requiredSpreadRatio returns the min spread ratio of buying on the buy session, skewed by the inventory imbalance
+func (s *Strategy) requiredSpreadRatio(buySession, sellSession string) float64 {
+	return s.MinSpreadRatio.Float64() + s.InventorySkew.Float64()*s.imbalance(buySession, sellSession)
+}
+
+// evaluate returns the arbitrage of buying on the buy session and selling on the sell session with the top of the books,
+// the quantity is limited by the volumes of the best levels and the available balances.
+func (s *Strategy) evaluate(buySession, sellSession string) (a arbitrage, ok bool) {
+	buyDepth, ok := s.stores[buySession].Depth(1)
+	if !ok {
+		return a, false
+	}
+
+	sellDepth, ok := s.stores[sellSession].Depth(1)
+	if !ok {
+		return a, false
+	}
+
+	a = arbitrage{
+		BuySession:  buySession,
+		SellSession: sellSession,
+		BuyPrice:    buyDepth.BestAsk.Price.Float64(),
+		SellPrice:   sellDepth.BestBid.Price.Float64(),
+	}
+
+	if a.BuyPrice <= 0 || a.SellPrice <= a.BuyPrice {
+		return a, false
+	}
+
+	if a.SpreadRatio(s.FeeRate.Float64()) < s.requiredSpreadRatio(buySession, sellSession) {
+		return a, false
+	}
+
+	if s.MaxImbalance > 0 && s.imbalance(buySession, sellSession) >= s.MaxImbalance.Float64() {
+		s.Log.Infof("%s base imbalance of %s over %s reaches the max imbalance, skip buying on %s",
+			s.Symbol, buySession, sellSession, buySession)
+		return a, false
+	}
+
+	quantity := math.Min(s.Quantity.Float64(), math.Min(buyDepth.BestAsk.Volume.Float64(), sellDepth.BestBid.Volume.Float64()))
+
+	if b, ok := s.sessions[buySession].Account.Balance(s.markets[buySession].QuoteCurrency); ok {
+		quantity = math.Min(quantity, b.Available.Float64()/(a.BuyPrice*(1.0+s.FeeRate.Float64())))
+	} else {
+		return a, false
+	}
+
+	if b, ok := s.sessions[sellSession].Account.Balance(s.markets[sellSession].BaseCurrency); ok {
+		quantity = math.Min(quantity, b.Available.Float64())
+	} else {
+		return a, false
+	}
+
+	// use the coarser step of both markets
+	buyMarket, sellMarket := s.markets[buySession], s.markets[sellSession]
+	quantity = sellMarket.RoundDownQuantity(buyMarket.RoundDownQuantity(quantity))
+
+	for _, m := range []struct {
+		market types.Market
+		price  float64
+	}{{buyMarket, a.BuyPrice}, {sellMarket, a.SellPrice}} {
+		if quantity <= 0 || quantity < m.market.MinQuantity || quantity*m.price < m.market.MinNotional {
+			return a, false
+		}
+	}
+
+	a.Quantity = quantity
+	return a, true
+}
+
+func (s *Strategy) check(ctx context.Context) {
+	s.mu.Lock()
+	inCooldown := time.Since(s.lastExecutionTime) < s.Cooldown.Duration()
+	s.mu.Unlock()
+
+	if inCooldown || atomic.LoadInt32(&s.executing) == 1 {
+		return
+	}
+
+	var best arbitrage
+	var found bool
+	for _, pair := range [][2]string{{s.Sessions[0], s.Sessions[1]}, {s.Sessions[1], s.Sessions[0]}} {
+		if a, ok := s.evaluate(pair[0], pair[1]); ok {
+			if !found || a.SpreadRatio(0) > best.SpreadRatio(0) {
+				best, found = a, true
+			}
+		}
+	}
+
+	if !found && s.Uncovered == 0 {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.executing, 0, 1) {
+		return
+	}
+
+	// the order updates are received from the same streams, execute in another goroutine to avoid blocking them
+	go func() {
+		defer atomic.StoreInt32(&s.executing, 0)
+		defer func() {
+			s.mu.Lock()
+			s.lastExecutionTime = time.Now()
+			s.mu.Unlock()
+		}()
+
+		if s.Uncovered != 0 {
+			s.cover(ctx)
+			return
+		}
+
+		s.execute(ctx, best)
+	}()
+}
+
+func (s *Strategy) execute(ctx context.Context, a arbitrage) {
+	s.Log.Infof("%s arbitrage: buy %f @ %f on %s, sell @ %f on %s, spread ratio %.4f%%",
+		s.Symbol, a.Quantity, a.BuyPrice, a.BuySession, a.SellPrice, a.SellSession, a.SpreadRatio(s.FeeRate.Float64())*100.0)
+
+	var wg sync.WaitGroup
+	var buyQuantity, sellQuantity float64
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		buyQuantity = s.submitIOC(ctx, a.BuySession, types.SideTypeBuy, a.BuyPrice, a.Quantity)
+	}()
+
+	go func() {
+		defer wg.Done()
+		sellQuantity = s.submitIOC(ctx, a.SellSession, types.SideTypeSell, a.SellPrice, a.Quantity)
+	}()
+
+	wg.Wait()
+
+	uncovered := buyQuantity - sellQuantity
+	s.Uncovered = fixedpoint.NewFromFloat(uncovered)
+
+	if buyQuantity > 0 || sellQuantity > 0 {
+		s.Notify("%s arbitrage: bought %f @ %f on %s, sold %f @ %f on %s, imbalance %.2f (%s over %s)",
+			s.Symbol, buyQuantity, a.BuyPrice, a.BuySession, sellQuantity, a.SellPrice, a.SellSession,
+			s.imbalance(a.BuySession, a.SellSession), a.BuySession, a.SellSession)
+	}
+
+	if uncovered != 0 {
+		s.Log.Warnf("%s arbitrage is partially filled, covering the uncovered quantity %f", s.Symbol, uncovered)
+		s.cover(ctx)
+	}
+}
+
+// cover sells the uncovered long quantity (or buys back the uncovered short quantity) with the market order
+// on the session that holds the most of the asset to sell (or the quote to spend).
+func (s *Strategy) cover(ctx context.Context) {
+	uncovered := s.Uncovered.Float64()
+
+	side := types.SideTypeSell
+	currency := func(m types.Market) string { return m.BaseCurrency }
+	if uncovered < 0 {
+		side = types.SideTypeBuy
+		currency = func(m types.Market) string { return m.QuoteCurrency }
+	}
+
+	var sessionName string
+	var maxAvailable float64
+	for _, name := range s.Sessions {
+		if b, ok := s.sessions[name].Account.Balance(currency(s.markets[name])); ok && b.Available.Float64() > maxAvailable {
+			sessionName, maxAvailable = name, b.Available.Float64()
+		}
+	}
+
+	if sessionName == "" {
+		s.Log.Errorf("no balance for covering the uncovered %s quantity %f", s.Symbol, uncovered)
+		return
+	}
+
+	market := s.markets[sessionName]
+	quantity := market.RoundDownQuantity(math.Abs(uncovered))
+	if quantity <= 0 || quantity < market.MinQuantity {
+		// the dust quantity can not be covered
+		s.Log.Warnf("uncovered %s quantity %f is below the min quantity, dropping", s.Symbol, uncovered)
+		s.Uncovered = 0
+		return
+	}
+
+	createdOrders, err := s.router.SubmitOrdersTo(ctx, sessionName, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Market:   market,
+		Quantity: quantity,
+	})
+	if err != nil || len(createdOrders) == 0 {
+		s.Log.WithError(err).Errorf("can not submit the cover order to %s", sessionName)
+		return
+	}
+
+	s.Notify("%s uncovered quantity %f is covered by the %s market order on %s", s.Symbol, uncovered, side, sessionName)
+	s.Uncovered = 0
+}
+
+// submitIOC submits the IOC limit order to the session and returns the executed quantity
+func (s *Strategy) submitIOC(ctx context.Context, sessionName string, side types.SideType, price, quantity float64) float64 {
+	createdOrders, err := s.router.SubmitOrdersTo(ctx, sessionName, types.SubmitOrder{
+		Symbol:      s.Symbol,
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Market:      s.markets[sessionName],
+		Quantity:    quantity,
+		Price:       price,
+		TimeInForce: "IOC",
+	})
+	if err != nil || len(createdOrders) == 0 {
+		s.Log.WithError(err).Errorf("can not submit the %s order to %s", side, sessionName)
+		return 0.0
+	}
+
+	s.activeOrders[sessionName].Add(createdOrders...)
+
+	executedQuantity, err := s.waitOrder(ctx, sessionName, createdOrders[0])
+	if err != nil {
+		s.Log.WithError(err).Errorf("%s order %d on %s is not closed", side, createdOrders[0].OrderID, sessionName)
+	}
+
+	return executedQuantity
+}
+
+// waitOrder waits for the order to be closed and returns the executed quantity,
+// the order is canceled if it's not closed in the order timeout, e.g., the exchange does not support IOC.
+func (s *Strategy) waitOrder(ctx context.Context, sessionName string, order types.Order) (float64, error) {
+	if isClosed(order) {
+		return order.ExecutedQuantity, nil
+	}
+
+	executedQuantity := order.ExecutedQuantity
+	timeout := time.After(s.OrderTimeout.Duration())
+	canceled := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return executedQuantity, ctx.Err()
+
+		case o := <-s.closedOrders[sessionName]:
+			if o.OrderID == order.OrderID {
+				return o.ExecutedQuantity, nil
+			}
+
+		case <-timeout:
+			if canceled {
+				return executedQuantity, fmt.Errorf("order %d is not closed after canceling", order.OrderID)
+			}
+
+			s.Log.Warnf("order %d is not closed in %s, canceling...", order.OrderID, s.OrderTimeout.Duration())
+			if err := s.sessions[sessionName].Exchange.CancelOrders(ctx, order); err != nil {
+				s.Log.WithError(err).Errorf("cancel order error")
+			}
+
+			canceled = true
+			timeout = time.After(s.OrderTimeout.Duration())
+		}
+	}
+}
+
+func isClosed(order types.Order) bool {
+	switch order.Status {
+	case types.OrderStatusNew, types.OrderStatusPartiallyFilled, "":
+		return false
+	}
+
+	return true
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, router bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if s.FeeRate == 0 {
+		s.FeeRate = fixedpoint.NewFromFloat(defaultFeeRate)
+	}
+
+	if s.OrderTimeout == 0 {
+		s.OrderTimeout = types.Duration(defaultOrderTimeout)
+	}
+
+	if s.Cooldown == 0 {
+		s.Cooldown = types.Duration(defaultCooldown)
+	}
+
+	s.router = router
+	s.sessions = make(map[string]*bbgo.ExchangeSession)
+	s.markets = make(map[string]types.Market)
+	s.stores = make(map[string]*bbgo.MarketDataStore)
+	s.activeOrders = make(map[string]*bbgo.LocalActiveOrderBook)
+	s.closedOrders = make(map[string]chan types.Order)
+
+	for _, name := range s.Sessions {
+		session, ok := sessions[name]
+		if !ok {
+			return fmt.Errorf("session %s is not defined", name)
+		}
+
+		market, ok := session.Market(s.Symbol)
+		if !ok {
+			return fmt.Errorf("market %s is not defined on session %s", s.Symbol, name)
+		}
+
+		store, ok := session.MarketDataStore(s.Symbol)
+		if !ok {
+			return fmt.Errorf("market data store of %s is not found on session %s", s.Symbol, name)
+		}
+
+		activeOrders := bbgo.NewLocalActiveOrderBook()
+		activeOrders.BindStream(session.Stream)
+
+		closedOrders := make(chan types.Order, 100)
+		session.Stream.OnOrderUpdate(func(order types.Order) {
+			if order.Symbol != s.Symbol || !isClosed(order) || atomic.LoadInt32(&s.executing) == 0 {
+				return
+			}
+
+			select {
+			case closedOrders <- order:
+			default:
+				s.Log.Warnf("closed order channel is full, dropping order %d", order.OrderID)
+			}
+		})
+
+		store.OnBookDepthUpdate(func(depth types.BookDepth) {
+			s.check(ctx)
+		})
+
+		s.sessions[name] = session
+		s.markets[name] = market
+		s.stores[name] = store
+		s.activeOrders[name] = activeOrders
+		s.closedOrders[name] = closedOrders
+	}
+
+	if s.Persistence != nil {
+		if err := s.Persistence.Load(&s.Uncovered, "uncovered"); err != nil {
+			s.Log.WithError(err).Warnf("can not load the uncovered quantity")
+		} else if s.Uncovered != 0 {
+			s.Log.Infof("uncovered quantity is loaded: %f", s.Uncovered.Float64())
+		}
+	}
+
+	s.Graceful.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		for name, activeOrders := range s.activeOrders {
+			if err := s.sessions[name].Exchange.CancelOrders(ctx, activeOrders.Orders()...); err != nil {
+				s.Log.WithError(err).Errorf("can not cancel orders on %s", name)
+			}
+		}
+
+		if s.Persistence != nil {
+			if err := s.Persistence.Save(&s.Uncovered, "uncovered"); err != nil {
+				s.Log.WithError(err).Error("persistence save error")
+			}
+		}
+	})
+
+	return nil
+}
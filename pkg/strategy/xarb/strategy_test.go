@@ -0,0 +1,268 @@
+package xarb
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/exchange/mock"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+	MinQuantity:   0.0001,
+	StepSize:      0.0001,
+	MinNotional:   10.0,
+}
+
+// fillingRouter fills the IOC orders right away, the IOC orders of the sessions in fillRatios are partially filled
+type fillingRouter struct {
+	mu         sync.Mutex
+	orderID    uint64
+	submitted  map[string][]types.SubmitOrder
+	fillRatios map[string]float64
+}
+
+func (r *fillingRouter) SubmitOrdersTo(ctx context.Context, session string, orders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, o := range orders {
+		r.orderID++
+		r.submitted[session] = append(r.submitted[session], o)
+
+		order := types.Order{SubmitOrder: o, OrderID: r.orderID, Status: types.OrderStatusFilled, ExecutedQuantity: o.Quantity}
+		if ratio, ok := r.fillRatios[session]; ok && o.Type == types.OrderTypeLimit {
+			// the unfilled part of the IOC order is canceled
+			order.Status = types.OrderStatusCanceled
+			order.ExecutedQuantity = o.Quantity * ratio
+		}
+
+		createdOrders = append(createdOrders, order)
+	}
+
+	return createdOrders, nil
+}
+
+type testSession struct {
+	name     string
+	bid, ask float64
+	volume   float64
+	usdt     float64
+	btc      float64
+}
+
+// newTestStrategy creates the strategy of the sessions with the top of the books and the balances
+func newTestStrategy(t *testing.T, sessions ...testSession) *Strategy {
+	s := &Strategy{
+		StrategyLogger: bbgo.StrategyLogger{Log: logrus.New()},
+		Notifiability:  &bbgo.Notifiability{},
+		Symbol:         "BTCUSDT",
+		Quantity:       fixedpoint.NewFromFloat(0.3),
+		FeeRate:        fixedpoint.NewFromFloat(0.001),
+		MinSpreadRatio: fixedpoint.NewFromFloat(0.001),
+		sessions:       make(map[string]*bbgo.ExchangeSession),
+		markets:        make(map[string]types.Market),
+		stores:         make(map[string]*bbgo.MarketDataStore),
+		activeOrders:   make(map[string]*bbgo.LocalActiveOrderBook),
+		closedOrders:   make(map[string]chan types.Order),
+	}
+
+	for _, ts := range sessions {
+		exchange := mock.New(types.ExchangeBinance, types.MarketMap{"BTCUSDT": testMarket}, nil)
+		session := bbgo.NewExchangeSession(ts.name, exchange)
+		session.Account.UpdateBalances(types.BalanceMap{
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(ts.usdt)},
+			"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(ts.btc)},
+		})
+
+		store := bbgo.NewMarketDataStore("BTCUSDT")
+		store.BindStream(exchange.NewStream())
+		exchange.PushBook(types.OrderBook{
+			Symbol: "BTCUSDT",
+			Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(ts.bid), Volume: fixedpoint.NewFromFloat(ts.volume)}},
+			Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(ts.ask), Volume: fixedpoint.NewFromFloat(ts.volume)}},
+		})
+
+		if _, ok := store.Depth(1); !assert.True(t, ok) {
+			t.FailNow()
+		}
+
+		s.Sessions = append(s.Sessions, ts.name)
+		s.sessions[ts.name] = session
+		s.markets[ts.name] = testMarket
+		s.stores[ts.name] = store
+		s.activeOrders[ts.name] = bbgo.NewLocalActiveOrderBook()
+		s.closedOrders[ts.name] = make(chan types.Order, 1)
+	}
+
+	return s
+}
+
+func TestArbitrage_SpreadRatio(t *testing.T) {
+	a := arbitrage{BuyPrice: 10000.0, SellPrice: 10100.0}
+	assert.InDelta(t, 0.01, a.SpreadRatio(0), 1e-9)
+	assert.InDelta(t, (10100.0*0.999-10000.0*1.001)/10000.0, a.SpreadRatio(0.001), 1e-9)
+}
+
+func TestStrategy_evaluate(t *testing.T) {
+	binanceSession := testSession{name: "binance", bid: 9990.0, ask: 10000.0, volume: 1.0, usdt: 10000.0, btc: 1.0}
+	maxSession := testSession{name: "max", bid: 10100.0, ask: 10110.0, volume: 0.5, usdt: 10000.0, btc: 1.0}
+
+	t.Run("buy low and sell high", func(t *testing.T) {
+		s := newTestStrategy(t, binanceSession, maxSession)
+
+		a, ok := s.evaluate("binance", "max")
+		if assert.True(t, ok) {
+			assert.Equal(t, 10000.0, a.BuyPrice)
+			assert.Equal(t, 10100.0, a.SellPrice)
+			assert.InDelta(t, 0.3, a.Quantity, 1e-9)
+		}
+
+		_, ok = s.evaluate("max", "binance")
+		assert.False(t, ok)
+	})
+
+	t.Run("below the min spread", func(t *testing.T) {
+		s := newTestStrategy(t, binanceSession, maxSession)
+		s.MinSpreadRatio = fixedpoint.NewFromFloat(0.01)
+
+		_, ok := s.evaluate("binance", "max")
+		assert.False(t, ok)
+	})
+
+	t.Run("limited by the best level volume", func(t *testing.T) {
+		s := newTestStrategy(t, binanceSession, maxSession)
+		s.Quantity = fixedpoint.NewFromFloat(2.0)
+
+		a, ok := s.evaluate("binance", "max")
+		if assert.True(t, ok) {
+			assert.InDelta(t, 0.5, a.Quantity, 1e-9)
+		}
+	})
+
+	t.Run("limited by the quote balance", func(t *testing.T) {
+		poor := binanceSession
+		poor.usdt = 1001.0
+		s := newTestStrategy(t, poor, maxSession)
+
+		a, ok := s.evaluate("binance", "max")
+		if assert.True(t, ok) {
+			assert.InDelta(t, 0.1, a.Quantity, 1e-9)
+		}
+	})
+
+	t.Run("limited by the base balance", func(t *testing.T) {
+		poor := maxSession
+		poor.btc = 0.2
+		s := newTestStrategy(t, binanceSession, poor)
+
+		a, ok := s.evaluate("binance", "max")
+		if assert.True(t, ok) {
+			assert.InDelta(t, 0.2, a.Quantity, 1e-9)
+		}
+	})
+
+	t.Run("below the min notional", func(t *testing.T) {
+		poor := maxSession
+		poor.btc = 0.0005
+		s := newTestStrategy(t, binanceSession, poor)
+
+		_, ok := s.evaluate("binance", "max")
+		assert.False(t, ok)
+	})
+
+	t.Run("inventory skew", func(t *testing.T) {
+		rich := binanceSession
+		rich.btc = 3.0
+		s := newTestStrategy(t, rich, maxSession)
+		s.InventorySkew = fixedpoint.NewFromFloat(0.01)
+
+		// the imbalance 0.5 requires the spread ratio 0.001 + 0.01 * 0.5
+		assert.InDelta(t, 0.5, s.imbalance("binance", "max"), 1e-9)
+		assert.InDelta(t, 0.006, s.requiredSpreadRatio("binance", "max"), 1e-9)
+		_, ok := s.evaluate("binance", "max")
+		assert.True(t, ok)
+
+		rich.btc = 9.0
+		s = newTestStrategy(t, rich, maxSession)
+		s.InventorySkew = fixedpoint.NewFromFloat(0.01)
+
+		_, ok = s.evaluate("binance", "max")
+		assert.False(t, ok)
+	})
+
+	t.Run("max imbalance", func(t *testing.T) {
+		rich := binanceSession
+		rich.btc = 3.0
+		s := newTestStrategy(t, rich, maxSession)
+		s.MaxImbalance = fixedpoint.NewFromFloat(0.5)
+
+		_, ok := s.evaluate("binance", "max")
+		assert.False(t, ok)
+	})
+}
+
+func TestStrategy_execute(t *testing.T) {
+	binanceSession := testSession{name: "binance", bid: 9990.0, ask: 10000.0, volume: 1.0, usdt: 10000.0, btc: 1.0}
+	maxSession := testSession{name: "max", bid: 10100.0, ask: 10110.0, volume: 0.5, usdt: 10000.0, btc: 1.0}
+
+	t.Run("filled", func(t *testing.T) {
+		s := newTestStrategy(t, binanceSession, maxSession)
+		router := &fillingRouter{submitted: make(map[string][]types.SubmitOrder)}
+		s.router = router
+
+		a, ok := s.evaluate("binance", "max")
+		if !assert.True(t, ok) {
+			return
+		}
+
+		s.execute(context.Background(), a)
+
+		if assert.Len(t, router.submitted["binance"], 1) {
+			order := router.submitted["binance"][0]
+			assert.Equal(t, types.SideTypeBuy, order.Side)
+			assert.Equal(t, 10000.0, order.Price)
+			assert.Equal(t, types.TimeInForce("IOC"), order.TimeInForce)
+		}
+
+		if assert.Len(t, router.submitted["max"], 1) {
+			order := router.submitted["max"][0]
+			assert.Equal(t, types.SideTypeSell, order.Side)
+			assert.Equal(t, 10100.0, order.Price)
+		}
+
+		assert.Equal(t, fixedpoint.Value(0), s.Uncovered)
+	})
+
+	t.Run("partially filled", func(t *testing.T) {
+		s := newTestStrategy(t, binanceSession, maxSession)
+		router := &fillingRouter{submitted: make(map[string][]types.SubmitOrder), fillRatios: map[string]float64{"max": 0.5}}
+		s.router = router
+
+		a, ok := s.evaluate("binance", "max")
+		if !assert.True(t, ok) {
+			return
+		}
+
+		s.execute(context.Background(), a)
+
+		// the bought quantity that is not sold is covered by the market order
+		if assert.Len(t, router.submitted["binance"], 2) {
+			order := router.submitted["binance"][1]
+			assert.Equal(t, types.OrderTypeMarket, order.Type)
+			assert.Equal(t, types.SideTypeSell, order.Side)
+			assert.InDelta(t, 0.15, order.Quantity, 1e-9)
+		}
+
+		assert.Equal(t, fixedpoint.Value(0), s.Uncovered)
+	})
+}
@@ -5,8 +5,6 @@ import (
 	"math"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -19,6 +17,8 @@ func init() {
 }
 
 type Strategy struct {
+	bbgo.StrategyLogger `json:"-" yaml:"-"`
+
 	Symbol       string           `json:"symbol"`
 	Side         string           `json:"side"`
 	NumOrders    int              `json:"numOrders"`
@@ -40,6 +40,7 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	s.SetDefaultLogger(ID, s.Symbol)
 
 	s.book = types.NewStreamBook(s.Symbol)
 	s.book.BindStream(session.Stream)
@@ -73,10 +74,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 func (s *Strategy) cancelOrders(session *bbgo.ExchangeSession) {
 	var deletedIDs []string
 	for clientOrderID, o := range s.activeOrders {
-		log.Infof("canceling order: %+v", o)
+		s.Log.Infof("canceling order: %+v", o)
 
 		if err := session.Exchange.CancelOrders(context.Background(), o); err != nil {
-			log.WithError(err).Error("cancel order error")
+			s.Log.WithError(err).Error("cancel order error")
 			continue
 		}
 
@@ -102,7 +103,7 @@ func (s *Strategy) update(orderExecutor bbgo.OrderExecutor, session *bbgo.Exchan
 		s.updateOrders(orderExecutor, session, types.SideTypeSell)
 
 	default:
-		log.Panicf("undefined side: %s", s.Side)
+		s.Log.Panicf("undefined side: %s", s.Side)
 	}
 
 	s.book.C.Drain(1*time.Second, 3*time.Second)
@@ -112,29 +113,29 @@ func (s *Strategy) updateOrders(orderExecutor bbgo.OrderExecutor, session *bbgo.
 	var book = s.book.Copy()
 	var pvs = book.PriceVolumesBySide(side)
 	if pvs == nil || len(pvs) == 0 {
-		log.Warnf("empty side: %s", side)
+		s.Log.Warnf("empty side: %s", side)
 		return
 	}
 
-	log.Infof("placing order behind volume: %f", s.BehindVolume.Float64())
+	s.Log.Infof("placing order behind volume: %f", s.BehindVolume.Float64())
 
 	idx := pvs.IndexByVolumeDepth(s.BehindVolume)
 	if idx == -1 || idx > len(pvs)-1 {
 		// do not place orders
-		log.Warn("depth is not enough")
+		s.Log.Warn("depth is not enough")
 		return
 	}
 
 	var depthPrice = pvs[idx].Price
 	var orders = s.generateOrders(s.Symbol, side, depthPrice, s.PriceTick, s.BaseQuantity, s.NumOrders)
 	if len(orders) == 0 {
-		log.Warn("empty orders")
+		s.Log.Warn("empty orders")
 		return
 	}
 
 	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), orders...)
 	if err != nil {
-		log.WithError(err).Errorf("order submit error")
+		s.Log.WithError(err).Errorf("order submit error")
 		return
 	}
 
@@ -164,7 +165,7 @@ func (s *Strategy) generateOrders(symbol string, side types.SideType, price, pri
 
 		// skip order less than 10usd
 		if volume*price.Float64() < 10.0 {
-			log.Warnf("amount too small (< 10usd). price=%f volume=%f amount=%f", price.Float64(), volume, volume*price.Float64())
+			s.Log.Warnf("amount too small (< 10usd). price=%f volume=%f amount=%f", price.Float64(), volume, volume*price.Float64())
 			continue
 		}
 
@@ -176,7 +177,7 @@ func (s *Strategy) generateOrders(symbol string, side types.SideType, price, pri
 			Quantity: volume,
 		})
 
-		log.Infof("%s order: %.2f @ %f", side, volume, price.Float64())
+		s.Log.Infof("%s order: %.2f @ %f", side, volume, price.Float64())
 
 		if len(orders) >= numOrders {
 			break
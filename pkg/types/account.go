@@ -14,6 +14,9 @@ type Balance struct {
 	Currency  string           `json:"currency"`
 	Available fixedpoint.Value `json:"available"`
 	Locked    fixedpoint.Value `json:"locked"`
+
+	// Borrowed is the borrowed amount (including the interest) of the margin account
+	Borrowed fixedpoint.Value `json:"borrowed,omitempty"`
 }
 
 func (b Balance) String() string {
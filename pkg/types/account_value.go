@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AssetValue is the value of an asset balance in the quote currency
+type AssetValue struct {
+	Currency string  `json:"currency"`
+	Total    float64 `json:"total"`
+	Borrowed float64 `json:"borrowed"`
+
+	// Price is the price of the asset in the quote currency
+	Price float64 `json:"price"`
+
+	// Value is the net value of the asset (total - borrowed) in the quote currency
+	Value float64 `json:"value"`
+}
+
+// AccountValue is the net value of all the account balances converted to the quote currency
+type AccountValue struct {
+	QuoteCurrency string                `json:"quoteCurrency"`
+	NetValue      float64               `json:"netValue"`
+	Assets        map[string]AssetValue `json:"assets"`
+
+	// Unpriced are the currencies without the price to the quote currency, they are not counted in the net value
+	Unpriced []string `json:"unpriced,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// QuotePrice looks up the price of the currency in the quote currency from the price map keyed by the market symbol,
+// the direct market, the inverse market and the USD price of both currencies are tried in order.
+// The equivalent currencies are priced 1:1, and the markets of the currencies equivalent to the quote currency are tried as well.
+func QuotePrice(currency, quoteCurrency string, prices map[string]float64) (float64, bool) {
+	if EquivalentCurrencies(currency, quoteCurrency) {
+		return 1.0, true
+	}
+
+	for _, quote := range append([]string{quoteCurrency}, EquivalentCurrenciesOf(quoteCurrency)...) {
+		if val, ok := prices[currency+quote]; ok && val > 0 {
+			return val, true
+		}
+
+		if val, ok := prices[quote+currency]; ok && val > 0 {
+			return 1.0 / val, true
+		}
+	}
+
+	usdPrice, ok := USDPrice(currency, prices)
+	if !ok {
+		return 0, false
+	}
+
+	quoteUSDPrice, ok := USDPrice(quoteCurrency, prices)
+	if !ok {
+		return 0, false
+	}
+
+	return usdPrice / quoteUSDPrice, true
+}
+
+// Value converts the balances (including the locked and the borrowed amount) to the quote currency
+// with the given prices, the prices map is keyed by the market symbol, e.g., BTCUSDT.
+func (m BalanceMap) Value(quoteCurrency string, prices map[string]float64) AccountValue {
+	value := AccountValue{
+		QuoteCurrency: quoteCurrency,
+		Assets:        make(map[string]AssetValue),
+		Time:          time.Now(),
+	}
+
+	for currency, b := range m {
+		total := (b.Available + b.Locked).Float64()
+		borrowed := b.Borrowed.Float64()
+		if total == 0 && borrowed == 0 {
+			continue
+		}
+
+		price, ok := QuotePrice(currency, quoteCurrency, prices)
+		if !ok {
+			value.Unpriced = append(value.Unpriced, currency)
+			continue
+		}
+
+		asset := AssetValue{
+			Currency: currency,
+			Total:    total,
+			Borrowed: borrowed,
+			Price:    price,
+			Value:    (total - borrowed) * price,
+		}
+
+		value.Assets[currency] = asset
+		value.NetValue += asset.Value
+	}
+
+	sort.Strings(value.Unpriced)
+	return value
+}
+
+// Currencies returns the priced currencies in the descending order of the asset value
+func (v AccountValue) Currencies() []string {
+	var currencies []string
+	for currency := range v.Assets {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return v.Assets[currencies[i]].Value > v.Assets[currencies[j]].Value
+	})
+
+	return currencies
+}
+
+func (v AccountValue) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("account net value: %.2f %s", v.NetValue, v.QuoteCurrency))
+
+	for _, currency := range v.Currencies() {
+		asset := v.Assets[currency]
+		sb.WriteString(fmt.Sprintf("\n%s: %f @ %f = %.2f %s", currency, asset.Total-asset.Borrowed, asset.Price, asset.Value, v.QuoteCurrency))
+	}
+
+	if len(v.Unpriced) > 0 {
+		sb.WriteString(fmt.Sprintf("\nunpriced: %s", strings.Join(v.Unpriced, ", ")))
+	}
+
+	return sb.String()
+}
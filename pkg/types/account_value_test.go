@@ -0,0 +1,65 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestQuotePrice(t *testing.T) {
+	prices := map[string]float64{
+		"BTCUSDT": 30000.0,
+		"ETHBTC":  0.05,
+		"MAXTWD":  5.0,
+		"USDTTWD": 28.0,
+	}
+
+	price, ok := QuotePrice("TWD", "TWD", prices)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, price)
+
+	price, ok = QuotePrice("ETH", "BTC", prices)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.05, price, 1e-9)
+
+	// the inverse market
+	price, ok = QuotePrice("BTC", "ETH", prices)
+	assert.True(t, ok)
+	assert.InDelta(t, 20.0, price, 1e-9)
+
+	// bridged by the USD prices
+	price, ok = QuotePrice("BTC", "TWD", prices)
+	assert.True(t, ok)
+	assert.InDelta(t, 30000.0*28.0, price, 1e-6)
+
+	_, ok = QuotePrice("MAX", "USDT", prices)
+	assert.False(t, ok)
+}
+
+func TestBalanceMap_Value(t *testing.T) {
+	balances := BalanceMap{
+		"BTC": {
+			Currency:  "BTC",
+			Available: fixedpoint.NewFromFloat(0.5),
+			Locked:    fixedpoint.NewFromFloat(0.5),
+		},
+		"USDT": {
+			Currency:  "USDT",
+			Available: fixedpoint.NewFromFloat(5000.0),
+			Borrowed:  fixedpoint.NewFromFloat(1000.0),
+		},
+		"XYZ": {
+			Currency:  "XYZ",
+			Available: fixedpoint.NewFromFloat(100.0),
+		},
+	}
+
+	value := balances.Value("USDT", map[string]float64{"BTCUSDT": 30000.0})
+	assert.Equal(t, "USDT", value.QuoteCurrency)
+	assert.InDelta(t, 30000.0+4000.0, value.NetValue, 1e-6)
+	assert.InDelta(t, 30000.0, value.Assets["BTC"].Value, 1e-6)
+	assert.Equal(t, []string{"BTC", "USDT"}, value.Currencies())
+	assert.Equal(t, []string{"XYZ"}, value.Unpriced)
+}
@@ -0,0 +1,10 @@
+package types
+
+import "context"
+
+// AmendOrderExchange is implemented by the exchanges that can modify the price and the quantity of the resting limit order
+// in place, the order keeps its order id and is never off the order book during the modification.
+// The quantity is the new unfilled quantity of the order, the zero price or quantity keeps the current one.
+type AmendOrderExchange interface {
+	AmendOrder(ctx context.Context, order Order, price, quantity float64) (*Order, error)
+}
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AnnouncementType string
+
+const (
+	AnnouncementTypeListing     = AnnouncementType("listing")
+	AnnouncementTypeDelisting   = AnnouncementType("delisting")
+	AnnouncementTypeMaintenance = AnnouncementType("maintenance")
+	AnnouncementTypeOther       = AnnouncementType("other")
+)
+
+// Announcement is the structured exchange announcement
+type Announcement struct {
+	ID       string           `json:"id"`
+	Exchange ExchangeName     `json:"exchange"`
+	Type     AnnouncementType `json:"type"`
+	Title    string           `json:"title"`
+	URL      string           `json:"url,omitempty"`
+
+	// Currencies are the currencies mentioned in the announcement, e.g., the listed or the delisted currencies
+	Currencies []string `json:"currencies,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+func (a Announcement) String() string {
+	return fmt.Sprintf("[%s] %s %s: %s", a.Exchange, a.Type, strings.Join(a.Currencies, ","), a.Title)
+}
+
+func (a Announcement) PlainText() string {
+	if len(a.URL) > 0 {
+		return a.String() + " " + a.URL
+	}
+
+	return a.String()
+}
+
+// AnnouncementProvider is implemented by the exchanges that publish the announcement feeds
+type AnnouncementProvider interface {
+	QueryAnnouncements(ctx context.Context, since time.Time) ([]Announcement, error)
+}
+
+// ParseAnnouncementType classifies the announcement by the keywords of the title
+func ParseAnnouncementType(title string) AnnouncementType {
+	lower := strings.ToLower(title)
+	switch {
+	case strings.Contains(lower, "delist") || strings.Contains(lower, "remove") || strings.Contains(lower, "cease trading"):
+		return AnnouncementTypeDelisting
+
+	case strings.Contains(lower, "maintenance") || strings.Contains(lower, "suspend"):
+		return AnnouncementTypeMaintenance
+
+	case strings.Contains(lower, "list") || strings.Contains(lower, "launch"):
+		return AnnouncementTypeListing
+	}
+
+	return AnnouncementTypeOther
+}
+
+// announcementQuoteCurrencies are the quote currencies of the trading pairs in the announcements,
+// e.g., "Binance Will Delist (FRONT/BTC, FRONT/USDT)", they are not the listed or delisted currencies.
+var announcementQuoteCurrencies = map[string]struct{}{
+	"USDT": {}, "BUSD": {}, "USDC": {}, "TUSD": {}, "PAX": {}, "USD": {},
+	"BTC": {}, "ETH": {}, "BNB": {}, "TWD": {},
+}
+
+// ParseAnnouncementCurrencies extracts the currencies in the parentheses of the title,
+// e.g., "Binance Will List Frontier (FRONT) and Mask Network (MASK)" => [FRONT MASK]
+// The quote currencies of the trading pairs are excluded, e.g., "Binance Will Delist (FRONT/BTC)" => [FRONT]
+func ParseAnnouncementCurrencies(title string) (currencies []string) {
+	seen := make(map[string]struct{})
+	for {
+		start := strings.IndexByte(title, '(')
+		if start < 0 {
+			return currencies
+		}
+
+		end := strings.IndexByte(title[start:], ')')
+		if end < 0 {
+			return currencies
+		}
+
+		for _, token := range strings.FieldsFunc(title[start+1:start+end], func(r rune) bool {
+			return r == ',' || r == '/' || r == ' '
+		}) {
+			if _, ok := announcementQuoteCurrencies[token]; ok {
+				continue
+			}
+
+			if _, ok := seen[token]; ok || !isCurrencyToken(token) {
+				continue
+			}
+
+			seen[token] = struct{}{}
+			currencies = append(currencies, token)
+		}
+
+		title = title[start+end+1:]
+	}
+}
+
+func isCurrencyToken(token string) bool {
+	if len(token) < 2 || len(token) > 10 {
+		return false
+	}
+
+	for _, c := range token {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnouncementType(t *testing.T) {
+	assert.Equal(t, AnnouncementTypeListing, ParseAnnouncementType("Binance Will List Frontier (FRONT)"))
+	assert.Equal(t, AnnouncementTypeDelisting, ParseAnnouncementType("Binance Will Delist BCPT, BTCST and HC"))
+	assert.Equal(t, AnnouncementTypeMaintenance, ParseAnnouncementType("Scheduled System Maintenance on 2021-02-10"))
+	assert.Equal(t, AnnouncementTypeOther, ParseAnnouncementType("Binance Rewards Distribution Completed"))
+}
+
+func TestParseAnnouncementCurrencies(t *testing.T) {
+	assert.Equal(t, []string{"FRONT", "MASK"}, ParseAnnouncementCurrencies("Binance Will List Frontier (FRONT) and Mask Network (MASK)"))
+	assert.Equal(t, []string{"BCPT", "HC"}, ParseAnnouncementCurrencies("Binance Will Delist (BCPT, HC) (Innovation Zone)"))
+	assert.Equal(t, []string{"FRONT", "MASK"}, ParseAnnouncementCurrencies("Binance Will Delist (FRONT/BTC, FRONT/USDT, MASK/BUSD)"))
+	assert.Nil(t, ParseAnnouncementCurrencies("Binance Will Delist BCPT, BTCST and HC"))
+	assert.Nil(t, ParseAnnouncementCurrencies("Scheduled System Maintenance"))
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"math/rand"
+	"time"
+)
+
+// LivenessProvider is implemented by the streams that track the time of the last received websocket message
+type LivenessProvider interface {
+	LastMessageTime() time.Time
+}
+
+// Reconnector is implemented by the streams that can drop the current connection,
+// the stream reconnects itself (with the backoff) after the connection is dropped.
+type Reconnector interface {
+	Reconnect() error
+}
+
+const (
+	DefaultReconnectMinBackoff = time.Second
+	DefaultReconnectMaxBackoff = time.Minute
+)
+
+// Backoff computes the exponential backoff delays with jitter of the reconnect attempts,
+// the delay of the n-th attempt is Min * Factor^n capped by Max, then randomized by +/- Jitter.
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+
+	// Jitter is the randomization ratio of the delays, e.g., 0.2 for +/- 20%
+	Jitter float64
+
+	attempts int
+}
+
+// NewReconnectBackoff returns the default backoff of the stream reconnects: 1s, 2s, 4s, ... up to 1 minute with 20% jitter
+func NewReconnectBackoff() *Backoff {
+	return &Backoff{
+		Min:    DefaultReconnectMinBackoff,
+		Max:    DefaultReconnectMaxBackoff,
+		Factor: 2.0,
+		Jitter: 0.2,
+	}
+}
+
+// Next returns the delay of the next attempt and increases the attempt count
+func (b *Backoff) Next() time.Duration {
+	delay := float64(b.Min)
+	for i := 0; i < b.attempts && delay < float64(b.Max); i++ {
+		delay *= b.Factor
+	}
+
+	if delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+
+	b.attempts++
+
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// Attempts returns the number of the attempts since the last reset
+func (b *Backoff) Attempts() int {
+	return b.attempts
+}
+
+// Reset resets the delay to Min, it should be called after a successful connect
+func (b *Backoff) Reset() {
+	b.attempts = 0
+}
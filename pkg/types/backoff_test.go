@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Next(t *testing.T) {
+	b := &Backoff{Min: time.Second, Max: 10 * time.Second, Factor: 2.0}
+	assert.Equal(t, time.Second, b.Next())
+	assert.Equal(t, 2*time.Second, b.Next())
+	assert.Equal(t, 4*time.Second, b.Next())
+	assert.Equal(t, 8*time.Second, b.Next())
+	assert.Equal(t, 10*time.Second, b.Next())
+	assert.Equal(t, 10*time.Second, b.Next())
+	assert.Equal(t, 6, b.Attempts())
+
+	b.Reset()
+	assert.Equal(t, time.Second, b.Next())
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	b := &Backoff{Min: time.Second, Max: time.Minute, Factor: 2.0, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		b.Reset()
+		b.Next()
+		delay := b.Next()
+		assert.True(t, delay >= 1600*time.Millisecond && delay <= 2400*time.Millisecond, "delay %s is out of the jitter range", delay)
+	}
+}
@@ -62,52 +62,9 @@ func (e ExchangeBatchProcessor) BatchQueryClosedOrders(ctx context.Context, symb
 	return c, errC
 }
 
-func (e ExchangeBatchProcessor) BatchQueryKLines(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time) (c chan KLine, errC chan error) {
-	c = make(chan KLine, 1000)
-	errC = make(chan error, 1)
-
-	go func() {
-		limiter := rate.NewLimiter(rate.Every(5*time.Second), 2) // from binance (original 1200, use 1000 for safety)
-
-		defer close(c)
-		defer close(errC)
-
-		for startTime.Before(endTime) {
-			if err := limiter.Wait(ctx); err != nil {
-				logrus.WithError(err).Error("rate limit error")
-			}
-
-			kLines, err := e.QueryKLines(ctx, symbol, interval, KLineQueryOptions{
-				StartTime: &startTime,
-				Limit:     1000,
-			})
-
-			if err != nil {
-				errC <- err
-				return
-			}
-
-			if len(kLines) == 0 {
-				return
-			}
-
-			for _, kline := range kLines {
-				// ignore any kline before the given start time
-				if kline.StartTime.Before(startTime) {
-					continue
-				}
-
-				if kline.EndTime.After(endTime) {
-					return
-				}
-
-				c <- kline
-				startTime = kline.EndTime
-			}
-		}
-	}()
-
-	return c, errC
+// BatchQueryKLines streams the klines of the time range, the range is split by the kline query limit of the exchange
+func (e ExchangeBatchProcessor) BatchQueryKLines(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time) (c <-chan KLine, errC <-chan error) {
+	return NewKLineFetcher(e.Exchange).Fetch(ctx, symbol, interval, startTime, endTime)
 }
 
 func (e ExchangeBatchProcessor) BatchQueryTrades(ctx context.Context, symbol string, options *TradeQueryOptions) (c chan Trade, errC chan error) {
@@ -170,3 +127,26 @@ func (e ExchangeBatchProcessor) BatchQueryTrades(ctx context.Context, symbol str
 
 	return c, errC
 }
+
+// BatchCancelOrders cancels the orders in the batches of the max orders per batch of the exchange,
+// the remaining batches are still canceled when a batch fails, and the last error is returned.
+func (e ExchangeBatchProcessor) BatchCancelOrders(ctx context.Context, orders ...Order) (err error) {
+	size := MaxOrdersPerBatch(e.Exchange)
+	if size <= 0 {
+		size = len(orders)
+	}
+
+	for start := 0; start < len(orders); start += size {
+		end := start + size
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		if err2 := e.CancelOrders(ctx, orders[start:end]...); err2 != nil {
+			logrus.WithError(err2).Errorf("can not cancel the orders of the batch %d ~ %d", start, end-1)
+			err = err2
+		}
+	}
+
+	return err
+}
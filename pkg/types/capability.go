@@ -0,0 +1,144 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// Capability is a feature that an exchange adapter may or may not support
+type Capability string
+
+const (
+	CapabilityMargin         = Capability("margin")
+	CapabilityIsolatedMargin = Capability("isolatedMargin")
+	CapabilityFutures        = Capability("futures")
+	CapabilityOCO            = Capability("oco")
+	CapabilityUserDataStream = Capability("userDataStream")
+	CapabilityLending        = Capability("lending")
+	CapabilityTransfer       = Capability("transfer")
+
+	// CapabilityAmendOrder means the price and the quantity of the resting orders can be modified in place
+	CapabilityAmendOrder = Capability("amendOrder")
+
+	// CapabilityMarginBorrowRepay means the assets of the margin account can be borrowed and repaid through the api
+	CapabilityMarginBorrowRepay = Capability("marginBorrowRepay")
+
+	// CapabilitySubAccountTransfer means the assets can be moved between the master account and the sub-accounts through the api
+	CapabilitySubAccountTransfer = Capability("subAccountTransfer")
+
+	// CapabilityMultiAssetCollateral means all the assets in the margin account can be used as the collateral
+	CapabilityMultiAssetCollateral = Capability("multiAssetCollateral")
+
+	// CapabilityPostOnly means the post only (maker only) limit orders are rejected instead of taking the liquidity
+	CapabilityPostOnly = Capability("postOnly")
+
+	// CapabilityStopOrder means the stop market and the stop limit orders are supported
+	CapabilityStopOrder = Capability("stopOrder")
+
+	// CapabilityBatchCancel means the orders passed to CancelOrders are canceled in batch requests instead of one by one
+	CapabilityBatchCancel = Capability("batchCancel")
+
+	// CapabilityWebsocketOrder means the orders are placed through the websocket connection instead of the REST api
+	CapabilityWebsocketOrder = Capability("websocketOrder")
+)
+
+type CapabilitySet map[Capability]struct{}
+
+func NewCapabilitySet(capabilities ...Capability) CapabilitySet {
+	set := make(CapabilitySet, len(capabilities))
+	for _, c := range capabilities {
+		set[c] = struct{}{}
+	}
+
+	return set
+}
+
+func (s CapabilitySet) Has(c Capability) bool {
+	_, ok := s[c]
+	return ok
+}
+
+// Missing returns the required capabilities that are not in the set
+func (s CapabilitySet) Missing(required ...Capability) (missing []Capability) {
+	for _, c := range required {
+		if !s.Has(c) {
+			missing = append(missing, c)
+		}
+	}
+
+	return missing
+}
+
+func (s CapabilitySet) String() string {
+	var names []string
+	for c := range s {
+		names = append(names, string(c))
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ExchangeCapabilityProvider is implemented by the exchange adapters to declare the supported capabilities
+type ExchangeCapabilityProvider interface {
+	Capabilities() CapabilitySet
+}
+
+// OrderBatchLimiter is implemented by the exchange adapters that limit the number of the orders in a batch request
+type OrderBatchLimiter interface {
+	MaxOrdersPerBatch() int
+}
+
+// MaxOrdersPerBatch returns the max number of the orders in a batch request of the exchange, zero means no limit
+func MaxOrdersPerBatch(exchange Exchange) int {
+	if limiter, ok := exchange.(OrderBatchLimiter); ok {
+		return limiter.MaxOrdersPerBatch()
+	}
+
+	return 0
+}
+
+// ExchangeCapabilities returns the declared capability set of the exchange,
+// for the exchanges that do not declare the capabilities, the capabilities are detected from the implemented interfaces.
+// The post only and the stop orders can not be detected, they are assumed to be supported by the undeclared exchanges.
+func ExchangeCapabilities(exchange Exchange) CapabilitySet {
+	if provider, ok := exchange.(ExchangeCapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+
+	set := NewCapabilitySet(CapabilityPostOnly, CapabilityStopOrder)
+	if _, ok := exchange.(MarginExchange); ok {
+		set[CapabilityMargin] = struct{}{}
+		set[CapabilityIsolatedMargin] = struct{}{}
+	}
+
+	if _, ok := exchange.(MarginBorrowRepayService); ok {
+		set[CapabilityMarginBorrowRepay] = struct{}{}
+	}
+
+	if _, ok := exchange.(FuturesExchange); ok {
+		set[CapabilityFutures] = struct{}{}
+	}
+
+	if _, ok := exchange.(LendingExchange); ok {
+		set[CapabilityLending] = struct{}{}
+	}
+
+	if _, ok := exchange.(TransferExchange); ok {
+		set[CapabilityTransfer] = struct{}{}
+	}
+
+	if _, ok := exchange.(SubAccountTransferExchange); ok {
+		set[CapabilitySubAccountTransfer] = struct{}{}
+	}
+
+	if _, ok := exchange.(OCOExchange); ok {
+		set[CapabilityOCO] = struct{}{}
+	}
+
+	if _, ok := exchange.(AmendOrderExchange); ok {
+		set[CapabilityAmendOrder] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilitySet_Missing(t *testing.T) {
+	set := NewCapabilitySet(CapabilityMargin, CapabilityUserDataStream)
+
+	assert.True(t, set.Has(CapabilityMargin))
+	assert.False(t, set.Has(CapabilityFutures))
+	assert.Empty(t, set.Missing(CapabilityUserDataStream))
+	assert.Equal(t, []Capability{CapabilityFutures, CapabilityOCO}, set.Missing(CapabilityMargin, CapabilityFutures, CapabilityOCO))
+	assert.Equal(t, "margin, userDataStream", set.String())
+}
+
+type undeclaredExchange struct {
+	Exchange
+}
+
+type batchCancelExchange struct {
+	Exchange
+
+	batches [][]uint64
+}
+
+func (e *batchCancelExchange) Capabilities() CapabilitySet {
+	return NewCapabilitySet(CapabilityBatchCancel)
+}
+
+func (e *batchCancelExchange) MaxOrdersPerBatch() int {
+	return 2
+}
+
+func (e *batchCancelExchange) CancelOrders(ctx context.Context, orders ...Order) error {
+	var ids []uint64
+	for _, o := range orders {
+		ids = append(ids, o.OrderID)
+	}
+
+	e.batches = append(e.batches, ids)
+	return nil
+}
+
+func TestExchangeCapabilities(t *testing.T) {
+	exchange := &batchCancelExchange{}
+
+	set := ExchangeCapabilities(exchange)
+	assert.True(t, set.Has(CapabilityBatchCancel))
+	assert.False(t, set.Has(CapabilityPostOnly))
+	assert.Equal(t, 2, MaxOrdersPerBatch(exchange))
+
+	// the undeclared exchange is assumed to support the post only and the stop orders
+	undeclared := &undeclaredExchange{}
+	set = ExchangeCapabilities(undeclared)
+	assert.True(t, set.Has(CapabilityPostOnly))
+	assert.True(t, set.Has(CapabilityStopOrder))
+	assert.False(t, set.Has(CapabilityBatchCancel))
+	assert.Equal(t, 0, MaxOrdersPerBatch(undeclared))
+}
+
+func TestExchangeBatchProcessor_BatchCancelOrders(t *testing.T) {
+	exchange := &batchCancelExchange{}
+
+	err := ExchangeBatchProcessor{Exchange: exchange}.BatchCancelOrders(context.Background(),
+		Order{OrderID: 1}, Order{OrderID: 2}, Order{OrderID: 3}, Order{OrderID: 4}, Order{OrderID: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]uint64{{1, 2}, {3, 4}, {5}}, exchange.batches)
+}
@@ -0,0 +1,163 @@
+package types
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// DefaultCollateralRatio is the collateral ratio of the assets that are not listed in the collateral ratio table
+const DefaultCollateralRatio = 1.0
+
+// CollateralRatios maps the currency to its collateral ratio (1 - haircut),
+// e.g., BTC: 0.95 means only 95% of the BTC value can be used as the margin.
+type CollateralRatios map[string]float64
+
+func (r CollateralRatios) Ratio(currency string) float64 {
+	if ratio, ok := r[currency]; ok {
+		return ratio
+	}
+
+	return DefaultCollateralRatio
+}
+
+// CollateralExchange is implemented by the exchanges supporting multi-asset collateral,
+// the returned balances include the borrowed amount and the interest of each asset.
+type CollateralExchange interface {
+	QueryCollateralBalances(ctx context.Context) (BalanceMap, error)
+
+	// QueryIndexPrices queries the index prices of the given symbols, the returned map is keyed by the symbol
+	QueryIndexPrices(ctx context.Context, symbols ...string) (map[string]float64, error)
+}
+
+// CollateralIndexSymbols returns the USDT symbols of the index prices that are needed to value the balances,
+// the USD stable coins and the empty balances are skipped.
+func CollateralIndexSymbols(balances BalanceMap) (symbols []string) {
+	for currency, b := range balances {
+		if isUSDCurrency(currency) {
+			continue
+		}
+
+		if b.Available == 0 && b.Locked == 0 && b.Borrowed == 0 {
+			continue
+		}
+
+		symbols = append(symbols, currency+"USDT")
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
+
+type CollateralAsset struct {
+	Currency string  `json:"currency"`
+	Total    float64 `json:"total"`
+	Borrowed float64 `json:"borrowed"`
+
+	// IndexPrice is the USD price of the asset
+	IndexPrice float64 `json:"indexPrice"`
+
+	// Value is the USD value of the asset after applying the collateral ratio
+	Value float64 `json:"value"`
+
+	// Debt is the USD value of the borrowed amount
+	Debt float64 `json:"debt"`
+}
+
+// Collateral is the valuation of the collateral across the assets in USD
+type Collateral struct {
+	Assets map[string]CollateralAsset `json:"assets"`
+
+	// TotalValue is the total collateral value after applying the collateral ratios
+	TotalValue float64 `json:"totalValue"`
+
+	TotalDebt float64 `json:"totalDebt"`
+
+	// AvailableMargin is the collateral value that can still be used to open new positions
+	AvailableMargin float64 `json:"availableMargin"`
+
+	// Unpriced is the assets that have balances but the index prices are not found, they are not counted as collateral.
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// MarginLevel returns the ratio of the total collateral value to the total debt,
+// returns 0 if there is no debt.
+func (c Collateral) MarginLevel() float64 {
+	if c.TotalDebt == 0 {
+		return 0
+	}
+
+	return c.TotalValue / c.TotalDebt
+}
+
+func isUSDCurrency(currency string) bool {
+	switch CanonicalCurrency(currency) {
+	case "USD", "USDT", "USDC", "BUSD", "TUSD", "PAX":
+		return true
+	}
+
+	return false
+}
+
+// USDPrice looks up the USD price of the currency from the price map keyed by the market symbol
+func USDPrice(currency string, prices map[string]float64) (float64, bool) {
+	if isUSDCurrency(currency) {
+		return 1.0, true
+	}
+
+	for _, market := range []string{currency + "USDT", currency + "USDC", currency + "BUSD", currency + "USD", "USDT" + currency} {
+		if val, ok := prices[market]; ok && val > 0 {
+			if strings.HasPrefix(market, "USD") {
+				return 1.0 / val, true
+			}
+
+			return val, true
+		}
+	}
+
+	return 0, false
+}
+
+// Collateral computes the collateral value and the available margin across all the assets with the given index prices,
+// the prices map is keyed by the market symbol, e.g., BTCUSDT.
+func (m BalanceMap) Collateral(prices map[string]float64, ratios CollateralRatios) Collateral {
+	collateral := Collateral{
+		Assets: make(map[string]CollateralAsset),
+	}
+
+	for currency, b := range m {
+		total := (b.Available + b.Locked).Float64()
+		borrowed := b.Borrowed.Float64()
+		if total == 0 && borrowed == 0 {
+			continue
+		}
+
+		price, ok := USDPrice(currency, prices)
+		if !ok {
+			collateral.Unpriced = append(collateral.Unpriced, currency)
+			continue
+		}
+
+		asset := CollateralAsset{
+			Currency:   currency,
+			Total:      total,
+			Borrowed:   borrowed,
+			IndexPrice: price,
+			Value:      total * price * ratios.Ratio(currency),
+			Debt:       borrowed * price,
+		}
+
+		collateral.Assets[currency] = asset
+		collateral.TotalValue += asset.Value
+		collateral.TotalDebt += asset.Debt
+	}
+
+	sort.Strings(collateral.Unpriced)
+
+	collateral.AvailableMargin = collateral.TotalValue - collateral.TotalDebt
+	if collateral.AvailableMargin < 0 {
+		collateral.AvailableMargin = 0
+	}
+
+	return collateral
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestBalanceMap_Collateral(t *testing.T) {
+	balances := BalanceMap{
+		"BTC": {
+			Currency:  "BTC",
+			Available: fixedpoint.NewFromFloat(1.0),
+		},
+		"ETH": {
+			Currency:  "ETH",
+			Available: fixedpoint.NewFromFloat(5.0),
+			Locked:    fixedpoint.NewFromFloat(5.0),
+		},
+		"USDT": {
+			Currency: "USDT",
+			Borrowed: fixedpoint.NewFromFloat(10000.0),
+		},
+		"XYZ": {
+			Currency:  "XYZ",
+			Available: fixedpoint.NewFromFloat(100.0),
+		},
+	}
+
+	prices := map[string]float64{
+		"BTCUSDT": 30000.0,
+		"ETHUSDT": 1000.0,
+	}
+
+	collateral := balances.Collateral(prices, CollateralRatios{"ETH": 0.9})
+	assert.InDelta(t, 30000.0+9000.0, collateral.TotalValue, 1e-6)
+	assert.InDelta(t, 10000.0, collateral.TotalDebt, 1e-6)
+	assert.InDelta(t, 29000.0, collateral.AvailableMargin, 1e-6)
+	assert.InDelta(t, 3.9, collateral.MarginLevel(), 1e-6)
+	assert.Equal(t, []string{"XYZ"}, collateral.Unpriced)
+
+	// the debt exceeds the collateral value
+	balances["USDT"] = Balance{Currency: "USDT", Borrowed: fixedpoint.NewFromFloat(50000.0)}
+	collateral = balances.Collateral(prices, nil)
+	assert.Equal(t, 0.0, collateral.AvailableMargin)
+}
+
+func TestUSDPrice(t *testing.T) {
+	price, ok := USDPrice("USDC", nil)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, price)
+
+	price, ok = USDPrice("TWD", map[string]float64{"USDTTWD": 28.0})
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0/28.0, price, 1e-9)
+
+	_, ok = USDPrice("BTC", nil)
+	assert.False(t, ok)
+}
+
+func TestCollateralIndexSymbols(t *testing.T) {
+	balances := BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+		"ETH":  {Currency: "ETH", Borrowed: fixedpoint.NewFromFloat(2.0)},
+		"BNB":  {Currency: "BNB"},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.0)},
+	}
+
+	assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, CollateralIndexSymbols(balances))
+}
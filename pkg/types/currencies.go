@@ -1,7 +1,130 @@
 package types
 
-import "github.com/leekchan/accounting"
+import (
+	"strings"
+	"sync"
+
+	"github.com/leekchan/accounting"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
 
 var USD = accounting.Accounting{Symbol: "$ ", Precision: 2}
 var BTC = accounting.Accounting{Symbol: "BTC ", Precision: 2}
 var BNB = accounting.Accounting{Symbol: "BNB ", Precision: 4}
+
+// CurrencyFormat defines how the amounts of the currency are displayed in the notifications
+type CurrencyFormat struct {
+	// Symbol is the prefix of the amount, e.g., "$ " or "NT$ "
+	Symbol string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+
+	// Precision is the number of the decimal places
+	Precision int `json:"precision" yaml:"precision"`
+
+	// Thousand is the thousand separator, defaults to ","
+	Thousand string `json:"thousand,omitempty" yaml:"thousand,omitempty"`
+
+	// Decimal is the decimal separator, defaults to "."
+	Decimal string `json:"decimal,omitempty" yaml:"decimal,omitempty"`
+}
+
+func (f CurrencyFormat) Format(val float64) string {
+	ac := accounting.Accounting{Symbol: f.Symbol, Precision: f.Precision, Thousand: f.Thousand, Decimal: f.Decimal}
+	return ac.FormatMoneyFloat64(val)
+}
+
+var currencyFormatsMutex sync.RWMutex
+
+var currencyFormats = map[string]CurrencyFormat{
+	"USD":  {Symbol: "$ ", Precision: 2},
+	"USDT": {Symbol: "$ ", Precision: 2},
+	"USDC": {Symbol: "$ ", Precision: 2},
+	"BUSD": {Symbol: "$ ", Precision: 2},
+	"TWD":  {Symbol: "NT$ ", Precision: 2},
+	"JPY":  {Symbol: "¥ ", Precision: 0},
+	"BTC":  {Symbol: "BTC ", Precision: 8},
+	"ETH":  {Symbol: "ETH ", Precision: 6},
+	"BNB":  {Symbol: "BNB ", Precision: 4},
+}
+
+// quoteCurrencies are the quote currencies used for splitting the symbols, the longer ones are matched first
+var quoteCurrencies = []string{"USDT", "USDC", "BUSD", "USD", "TWD", "JPY", "BTC", "ETH", "BNB"}
+
+// SetCurrencyFormat overrides the display format of the currency
+func SetCurrencyFormat(currency string, format CurrencyFormat) {
+	currencyFormatsMutex.Lock()
+	currencyFormats[strings.ToUpper(currency)] = format
+	currencyFormatsMutex.Unlock()
+}
+
+// GetCurrencyFormat returns the display format of the currency
+func GetCurrencyFormat(currency string) (CurrencyFormat, bool) {
+	currencyFormatsMutex.RLock()
+	format, ok := currencyFormats[strings.ToUpper(currency)]
+	currencyFormatsMutex.RUnlock()
+	return format, ok
+}
+
+// FormatCurrencyAmount formats the amount with the thousand separators and the decimal places of the currency,
+// the amount of the unknown currency is formatted with 8 decimal places and suffixed with the currency.
+func FormatCurrencyAmount(currency string, val float64) string {
+	if format, ok := GetCurrencyFormat(currency); ok {
+		return format.Format(val)
+	}
+
+	format := CurrencyFormat{Precision: 8}
+	s := format.Format(val)
+	if currency != "" {
+		s += " " + currency
+	}
+	return s
+}
+
+// QuoteCurrencyOf returns the quote currency of the symbol by matching the known quote currencies,
+// an empty string is returned if the symbol doesn't end with any of them.
+func QuoteCurrencyOf(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, currency := range quoteCurrencies {
+		if len(symbol) > len(currency) && strings.HasSuffix(symbol, currency) {
+			return currency
+		}
+	}
+
+	return ""
+}
+
+// formatQuoteAmount formats the price or the quote amount of the symbol with the quote currency format,
+// it falls back to the given decimal places if the quote currency is unknown.
+func formatQuoteAmount(symbol string, val float64, prec int) string {
+	if format, ok := GetCurrencyFormat(QuoteCurrencyOf(symbol)); ok {
+		return format.Format(val)
+	}
+
+	return util.FormatFloat(val, prec)
+}
+
+var translationsMutex sync.RWMutex
+
+var translations = map[string]string{}
+
+// SetTranslations registers the localized notification messages, the keys are the original English messages
+func SetTranslations(messages map[string]string) {
+	translationsMutex.Lock()
+	for key, message := range messages {
+		translations[key] = message
+	}
+	translationsMutex.Unlock()
+}
+
+// Translate returns the localized message of the notification text, the text itself is returned if it's not translated
+func Translate(text string) string {
+	translationsMutex.RLock()
+	message, ok := translations[text]
+	translationsMutex.RUnlock()
+
+	if ok {
+		return message
+	}
+
+	return text
+}
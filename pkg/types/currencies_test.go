@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCurrencyAmount(t *testing.T) {
+	assert.Equal(t, "¥ 4,567,890", FormatCurrencyAmount("JPY", 4567890.4))
+	assert.Equal(t, "NT$ 1,234,567.89", FormatCurrencyAmount("TWD", 1234567.891))
+	assert.Equal(t, "$ 12,345.68", FormatCurrencyAmount("USDT", 12345.678))
+	assert.Equal(t, "0.12345679 XYZ", FormatCurrencyAmount("XYZ", 0.123456789))
+
+	SetCurrencyFormat("EUR", CurrencyFormat{Symbol: "€ ", Precision: 2, Thousand: ".", Decimal: ","})
+	assert.Equal(t, "€ 1.234,50", FormatCurrencyAmount("eur", 1234.5))
+}
+
+func TestQuoteCurrencyOf(t *testing.T) {
+	assert.Equal(t, "USDT", QuoteCurrencyOf("BTCUSDT"))
+	assert.Equal(t, "TWD", QuoteCurrencyOf("btctwd"))
+	assert.Equal(t, "BTC", QuoteCurrencyOf("ETHBTC"))
+	assert.Equal(t, "", QuoteCurrencyOf("USDT"))
+	assert.Equal(t, "", QuoteCurrencyOf("FOOBAR"))
+}
+
+func TestTrade_PlainText(t *testing.T) {
+	trade := Trade{
+		Exchange:      "max",
+		Symbol:        "BTCTWD",
+		Side:          SideTypeBuy,
+		Price:         1567890.0,
+		Quantity:      0.01,
+		QuoteQuantity: 15678.9,
+	}
+
+	assert.Equal(t, "max Trade BTCTWD BUY price NT$ 1,567,890.00, quantity 0.0100, amount NT$ 15,678.90", trade.PlainText())
+
+	SetTranslations(map[string]string{"Trade": "成交", "price": "價格"})
+	defer func() {
+		translationsMutex.Lock()
+		translations = map[string]string{}
+		translationsMutex.Unlock()
+	}()
+
+	assert.Equal(t, "max 成交 BTCTWD BUY 價格 NT$ 1,567,890.00, quantity 0.0100, amount NT$ 15,678.90", trade.PlainText())
+}
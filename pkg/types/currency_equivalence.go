@@ -0,0 +1,104 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// CurrencyEquivalence groups the currencies valued 1:1 with each other by the canonical currency of the group,
+// e.g., {"USDT": ["BUSD", "USDC"]}. The prices, the budgets and the profits in the member currencies
+// are counted as the canonical currency, so the strategies on the pairs of the different stablecoins are comparable.
+type CurrencyEquivalence map[string][]string
+
+func (e CurrencyEquivalence) Validate() error {
+	groups := make(map[string]string)
+	for canonical, members := range e {
+		for _, currency := range append([]string{canonical}, members...) {
+			currency = strings.ToUpper(currency)
+			if group, ok := groups[currency]; ok && group != canonical {
+				return fmt.Errorf("currency %s can not be in both of the equivalence groups %s and %s", currency, group, canonical)
+			}
+
+			groups[currency] = canonical
+		}
+	}
+
+	return nil
+}
+
+var currencyEquivalenceMutex sync.RWMutex
+
+// canonicalCurrencies maps the member currencies to the canonical currency of the group
+var canonicalCurrencies = map[string]string{}
+
+// SetCurrencyEquivalence replaces the currency equivalence groups
+func SetCurrencyEquivalence(e CurrencyEquivalence) {
+	canonicals := make(map[string]string)
+	for canonical, members := range e {
+		canonical = strings.ToUpper(canonical)
+		for _, currency := range members {
+			if currency = strings.ToUpper(currency); currency != canonical {
+				canonicals[currency] = canonical
+			}
+		}
+	}
+
+	currencyEquivalenceMutex.Lock()
+	canonicalCurrencies = canonicals
+	currencyEquivalenceMutex.Unlock()
+}
+
+// CanonicalCurrency returns the canonical currency of the equivalence group, or the currency itself if it's not in any group
+func CanonicalCurrency(currency string) string {
+	currencyEquivalenceMutex.RLock()
+	canonical, ok := canonicalCurrencies[strings.ToUpper(currency)]
+	currencyEquivalenceMutex.RUnlock()
+
+	if ok {
+		return canonical
+	}
+
+	return currency
+}
+
+// EquivalentCurrencies returns true if the currencies are the same or in the same equivalence group
+func EquivalentCurrencies(a, b string) bool {
+	return a == b || strings.EqualFold(CanonicalCurrency(a), CanonicalCurrency(b))
+}
+
+// EquivalentCurrenciesOf returns the other currencies in the equivalence group of the currency
+func EquivalentCurrenciesOf(currency string) (currencies []string) {
+	canonical := CanonicalCurrency(currency)
+
+	currencyEquivalenceMutex.RLock()
+	defer currencyEquivalenceMutex.RUnlock()
+
+	if !strings.EqualFold(canonical, currency) {
+		currencies = append(currencies, canonical)
+	}
+
+	for member, c := range canonicalCurrencies {
+		if c == strings.ToUpper(canonical) && !strings.EqualFold(member, currency) {
+			currencies = append(currencies, member)
+		}
+	}
+
+	return currencies
+}
+
+// EquivalentBalance sums the balances of the currency and its equivalent currencies
+func (m BalanceMap) EquivalentBalance(currency string) Balance {
+	total := Balance{Currency: currency}
+	for c, b := range m {
+		if !EquivalentCurrencies(c, currency) {
+			continue
+		}
+
+		total.Available += b.Available
+		total.Locked += b.Locked
+		total.Borrowed += b.Borrowed
+	}
+
+	return total
+}
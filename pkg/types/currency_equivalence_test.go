@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestCurrencyEquivalence(t *testing.T) {
+	assert.Error(t, CurrencyEquivalence{"USDT": {"BUSD"}, "USDC": {"BUSD"}}.Validate())
+	assert.NoError(t, CurrencyEquivalence{"USDT": {"BUSD", "USDC"}}.Validate())
+
+	SetCurrencyEquivalence(CurrencyEquivalence{"USDT": {"BUSD", "USDC"}})
+	defer SetCurrencyEquivalence(nil)
+
+	assert.Equal(t, "USDT", CanonicalCurrency("BUSD"))
+	assert.Equal(t, "USDT", CanonicalCurrency("USDT"))
+	assert.Equal(t, "BTC", CanonicalCurrency("BTC"))
+	assert.True(t, EquivalentCurrencies("BUSD", "USDC"))
+	assert.False(t, EquivalentCurrencies("BTC", "USDT"))
+	assert.ElementsMatch(t, []string{"USDT", "USDC"}, EquivalentCurrenciesOf("BUSD"))
+
+	balances := BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(100.0)},
+		"BUSD": {Currency: "BUSD", Available: fixedpoint.NewFromFloat(50.0), Locked: fixedpoint.NewFromFloat(10.0)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)},
+	}
+
+	balance := balances.EquivalentBalance("USDT")
+	assert.Equal(t, 150.0, balance.Available.Float64())
+	assert.Equal(t, 10.0, balance.Locked.Float64())
+
+	// the BTC price is looked up from the market of the equivalent quote currency
+	price, ok := QuotePrice("BTC", "USDT", map[string]float64{"BTCBUSD": 20000.0})
+	assert.True(t, ok)
+	assert.Equal(t, 20000.0, price)
+
+	price, ok = QuotePrice("BUSD", "USDT", nil)
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, price)
+}
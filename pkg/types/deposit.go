@@ -20,13 +20,15 @@ const (
 )
 
 type Deposit struct {
-	Time          time.Time     `json:"time"`
-	Amount        float64       `json:"amount"`
-	Asset         string        `json:"asset"`
-	Address       string        `json:"address"`
-	AddressTag    string        `json:"addressTag"`
-	TransactionID string        `json:"txId"`
-	Status        DepositStatus `json:"status"`
+	GID           int64         `json:"gid" db:"gid"`
+	Exchange      ExchangeName  `json:"exchange" db:"exchange"`
+	Time          time.Time     `json:"time" db:"time"`
+	Amount        float64       `json:"amount" db:"amount"`
+	Asset         string        `json:"asset" db:"asset"`
+	Address       string        `json:"address" db:"address"`
+	AddressTag    string        `json:"addressTag" db:"address_tag"`
+	TransactionID string        `json:"txId" db:"txn_id"`
+	Status        DepositStatus `json:"status" db:"status"`
 }
 
 func (d Deposit) EffectiveTime() time.Time {
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// BookDepth is the aggregated depth of the top levels of the order book
+type BookDepth struct {
+	Symbol string `json:"symbol"`
+
+	// Levels is the number of the aggregated price levels of each side
+	Levels int `json:"levels"`
+
+	BestBid PriceVolume `json:"bestBid"`
+	BestAsk PriceVolume `json:"bestAsk"`
+
+	// MidPrice is the average of the best bid price and the best ask price
+	MidPrice fixedpoint.Value `json:"midPrice"`
+
+	// BidVolume and AskVolume are the total volumes of the aggregated levels
+	BidVolume fixedpoint.Value `json:"bidVolume"`
+	AskVolume fixedpoint.Value `json:"askVolume"`
+
+	// WeightedBidPrice and WeightedAskPrice are the volume weighted average prices of the aggregated levels
+	WeightedBidPrice fixedpoint.Value `json:"weightedBidPrice"`
+	WeightedAskPrice fixedpoint.Value `json:"weightedAskPrice"`
+}
+
+// Spread returns the spread between the best ask price and the best bid price
+func (d BookDepth) Spread() fixedpoint.Value {
+	return d.BestAsk.Price - d.BestBid.Price
+}
+
+// WeightedMidPrice returns the mid price weighted by the volumes of the opposite side,
+// the price leans toward the side with less volume, which is more likely to be taken.
+func (d BookDepth) WeightedMidPrice() fixedpoint.Value {
+	total := d.BidVolume + d.AskVolume
+	if total == 0 {
+		return d.MidPrice
+	}
+
+	return d.BestBid.Price.Mul(d.AskVolume).Add(d.BestAsk.Price.Mul(d.BidVolume)).Div(total)
+}
+
+// Imbalance returns (bid volume - ask volume) / (bid volume + ask volume) in [-1, 1],
+// the positive imbalance means the buy side is heavier.
+func (d BookDepth) Imbalance() float64 {
+	total := d.BidVolume + d.AskVolume
+	if total == 0 {
+		return 0.0
+	}
+
+	return (d.BidVolume - d.AskVolume).Float64() / total.Float64()
+}
+
+// Depth aggregates the top levels of both sides, ok is false if either side is empty
+func (b *OrderBook) Depth(levels int) (depth BookDepth, ok bool) {
+	bestBid, hasBid := b.BestBid()
+	bestAsk, hasAsk := b.BestAsk()
+	if !hasBid || !hasAsk {
+		return depth, false
+	}
+
+	depth = BookDepth{
+		Symbol:   b.Symbol,
+		Levels:   levels,
+		BestBid:  bestBid,
+		BestAsk:  bestAsk,
+		MidPrice: (bestBid.Price + bestAsk.Price) / 2,
+	}
+
+	depth.BidVolume, depth.WeightedBidPrice = aggregatePriceVolumes(b.Bids, levels)
+	depth.AskVolume, depth.WeightedAskPrice = aggregatePriceVolumes(b.Asks, levels)
+	return depth, true
+}
+
+func aggregatePriceVolumes(pvs PriceVolumeSlice, levels int) (volume, weightedPrice fixedpoint.Value) {
+	if levels > 0 && len(pvs) > levels {
+		pvs = pvs[:levels]
+	}
+
+	var amount fixedpoint.Value
+	for _, pv := range pvs {
+		volume += pv.Volume
+		amount += pv.Price.Mul(pv.Volume)
+	}
+
+	if volume > 0 {
+		weightedPrice = amount.Div(volume)
+	}
+
+	return volume, weightedPrice
+}
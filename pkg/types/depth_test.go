@@ -0,0 +1,35 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestOrderBook_Depth(t *testing.T) {
+	book := testBook(map[float64]float64{100: 1, 99: 3, 98: 10}, map[float64]float64{102: 1, 103: 1})
+
+	depth, ok := book.Depth(2)
+	if assert.True(t, ok) {
+		assert.Equal(t, fixedpoint.NewFromFloat(100), depth.BestBid.Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(102), depth.BestAsk.Price)
+		assert.Equal(t, fixedpoint.NewFromFloat(101), depth.MidPrice)
+		assert.Equal(t, fixedpoint.NewFromFloat(2), depth.Spread())
+
+		// the third bid level is not aggregated
+		assert.Equal(t, fixedpoint.NewFromFloat(4), depth.BidVolume)
+		assert.Equal(t, fixedpoint.NewFromFloat(99.25), depth.WeightedBidPrice)
+		assert.Equal(t, fixedpoint.NewFromFloat(2), depth.AskVolume)
+		assert.Equal(t, fixedpoint.NewFromFloat(102.5), depth.WeightedAskPrice)
+
+		// the heavier bid side pushes the weighted mid price toward the ask
+		assert.Equal(t, fixedpoint.NewFromFloat(101.3333333333), depth.WeightedMidPrice())
+		assert.InDelta(t, 1.0/3.0, depth.Imbalance(), 1e-9)
+	}
+
+	oneSided := testBook(map[float64]float64{100: 1}, nil)
+	_, ok = oneSided.Depth(2)
+	assert.False(t, ok)
+}
@@ -0,0 +1,313 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultDispatchWorkers   = 4
+	DefaultDispatchQueueSize = 1024
+)
+
+// DispatcherOptions is the event dispatcher config of the session stream
+type DispatcherOptions struct {
+	// Workers is the number of the worker goroutines running the callbacks, defaults to 4
+	Workers int `json:"workers,omitempty" yaml:"workers,omitempty"`
+
+	// QueueSize is the buffer size of the dispatcher queue and of each worker queue, defaults to 1024
+	QueueSize int `json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+}
+
+// DispatcherStats is the back-pressure statistics of the dispatcher
+type DispatcherStats struct {
+	// Dispatched is the number of the events passed to the callbacks by the workers
+	Dispatched int64 `json:"dispatched"`
+
+	// Blocked is the number of the events that waited for a full queue,
+	// it's either the stream read loop waiting for the dispatcher or the dispatcher waiting for a worker.
+	Blocked int64 `json:"blocked"`
+
+	// BlockedTime is the total time spent on waiting for the full queues
+	BlockedTime time.Duration `json:"blockedTime"`
+
+	// QueueLength is the number of the events in the dispatcher queue and the worker queues
+	QueueLength int `json:"queueLength"`
+}
+
+type dispatchEvent struct {
+	key string
+	fn  func()
+}
+
+// DispatchStream wraps the exchange stream and runs the callbacks in a bounded worker pool instead of the stream read loop.
+//
+// A single dispatcher goroutine routes the events to the workers by the symbol, so the events of the same symbol
+// are handled in order by the same worker, and the events without a symbol (connect, balances) are handled by the same worker as well.
+// When the queues are full, the stream read loop is blocked until the workers catch up, the waits are counted in the stats.
+type DispatchStream struct {
+	StandardStream
+
+	upstream Stream
+
+	queue   chan dispatchEvent
+	workers []chan func()
+
+	startOnce sync.Once
+	started   int32
+	done      <-chan struct{}
+
+	dispatched  int64
+	blocked     int64
+	blockedTime int64
+}
+
+func NewDispatchStream(upstream Stream, options DispatcherOptions) *DispatchStream {
+	if options.Workers <= 0 {
+		options.Workers = DefaultDispatchWorkers
+	}
+
+	if options.QueueSize <= 0 {
+		options.QueueSize = DefaultDispatchQueueSize
+	}
+
+	stream := &DispatchStream{
+		upstream: upstream,
+		queue:    make(chan dispatchEvent, options.QueueSize),
+	}
+
+	for i := 0; i < options.Workers; i++ {
+		stream.workers = append(stream.workers, make(chan func(), options.QueueSize))
+	}
+
+	upstream.OnConnect(stream.EmitConnect)
+	upstream.OnDisconnect(stream.EmitDisconnect)
+	upstream.OnTradeUpdate(stream.EmitTradeUpdate)
+	upstream.OnOrderUpdate(stream.EmitOrderUpdate)
+	upstream.OnBalanceSnapshot(stream.EmitBalanceSnapshot)
+	upstream.OnBalanceUpdate(stream.EmitBalanceUpdate)
+	upstream.OnKLineClosed(stream.EmitKLineClosed)
+	upstream.OnKLine(stream.EmitKLine)
+	upstream.OnBookUpdate(stream.EmitBookUpdate)
+	upstream.OnBookSnapshot(stream.EmitBookSnapshot)
+	upstream.OnMarketTrade(stream.EmitMarketTrade)
+	upstream.OnFundingRate(stream.EmitFundingRate)
+	upstream.OnPositionUpdate(stream.EmitPositionUpdate)
+	upstream.OnMarginCall(stream.EmitMarginCall)
+	return stream
+}
+
+func (s *DispatchStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
+	s.upstream.Subscribe(channel, symbol, options)
+}
+
+func (s *DispatchStream) SetPublicOnly() {
+	s.upstream.SetPublicOnly()
+}
+
+// Connect starts the dispatcher and the workers before connecting the upstream, they are stopped when the context is done
+func (s *DispatchStream) Connect(ctx context.Context) error {
+	s.start(ctx)
+	return s.upstream.Connect(ctx)
+}
+
+func (s *DispatchStream) Close() error {
+	return s.upstream.Close()
+}
+
+// Latency returns the latency recorder of the upstream if it records the latencies
+func (s *DispatchStream) Latency() *LatencyRecorder {
+	if provider, ok := s.upstream.(LatencyProvider); ok {
+		return provider.Latency()
+	}
+
+	return s.StandardStream.Latency()
+}
+
+// LastMessageTime returns the time of the last message received by the upstream
+func (s *DispatchStream) LastMessageTime() time.Time {
+	if provider, ok := s.upstream.(LivenessProvider); ok {
+		return provider.LastMessageTime()
+	}
+
+	return s.StandardStream.LastMessageTime()
+}
+
+// Reconnect drops the upstream connection if the upstream supports it
+func (s *DispatchStream) Reconnect() error {
+	if reconnector, ok := s.upstream.(Reconnector); ok {
+		return reconnector.Reconnect()
+	}
+
+	return fmt.Errorf("stream %T does not support the reconnect", s.upstream)
+}
+
+// ResyncBook reloads the book snapshot of the symbol if the upstream supports it
+func (s *DispatchStream) ResyncBook(symbol string) error {
+	if resyncer, ok := s.upstream.(BookResyncer); ok {
+		return resyncer.ResyncBook(symbol)
+	}
+
+	return fmt.Errorf("stream %T does not support the book resync", s.upstream)
+}
+
+func (s *DispatchStream) Stats() DispatcherStats {
+	queueLength := len(s.queue)
+	for _, worker := range s.workers {
+		queueLength += len(worker)
+	}
+
+	return DispatcherStats{
+		Dispatched:  atomic.LoadInt64(&s.dispatched),
+		Blocked:     atomic.LoadInt64(&s.blocked),
+		BlockedTime: time.Duration(atomic.LoadInt64(&s.blockedTime)),
+		QueueLength: queueLength,
+	}
+}
+
+func (s *DispatchStream) start(ctx context.Context) {
+	s.startOnce.Do(func() {
+		s.done = ctx.Done()
+
+		for _, worker := range s.workers {
+			go s.runWorker(ctx, worker)
+		}
+
+		go s.runDispatcher(ctx)
+
+		atomic.StoreInt32(&s.started, 1)
+	})
+}
+
+func (s *DispatchStream) runDispatcher(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event := <-s.queue:
+			worker := s.workers[s.workerIndex(event.key)]
+
+			select {
+			case worker <- event.fn:
+			default:
+				startTime := time.Now()
+				select {
+				case worker <- event.fn:
+					s.countBlocked(startTime)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *DispatchStream) runWorker(ctx context.Context, worker chan func()) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case fn := <-worker:
+			atomic.AddInt64(&s.dispatched, 1)
+			fn()
+		}
+	}
+}
+
+func (s *DispatchStream) workerIndex(key string) int {
+	if len(s.workers) == 1 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.workers)))
+}
+
+func (s *DispatchStream) countBlocked(startTime time.Time) {
+	atomic.AddInt64(&s.blocked, 1)
+	atomic.AddInt64(&s.blockedTime, int64(time.Since(startTime)))
+}
+
+// dispatch queues the event, the callbacks are called directly if the dispatcher is not started
+func (s *DispatchStream) dispatch(key string, fn func()) {
+	if atomic.LoadInt32(&s.started) == 0 {
+		fn()
+		return
+	}
+
+	event := dispatchEvent{key: key, fn: fn}
+
+	select {
+	case s.queue <- event:
+	default:
+		startTime := time.Now()
+		select {
+		case s.queue <- event:
+			s.countBlocked(startTime)
+		case <-s.done:
+		}
+	}
+}
+
+func (s *DispatchStream) EmitConnect() {
+	s.dispatch("", s.StandardStream.EmitConnect)
+}
+
+func (s *DispatchStream) EmitDisconnect() {
+	s.dispatch("", s.StandardStream.EmitDisconnect)
+}
+
+func (s *DispatchStream) EmitTradeUpdate(trade Trade) {
+	s.dispatch(trade.Symbol, func() { s.StandardStream.EmitTradeUpdate(trade) })
+}
+
+func (s *DispatchStream) EmitOrderUpdate(order Order) {
+	s.dispatch(order.Symbol, func() { s.StandardStream.EmitOrderUpdate(order) })
+}
+
+func (s *DispatchStream) EmitBalanceSnapshot(balances BalanceMap) {
+	s.dispatch("", func() { s.StandardStream.EmitBalanceSnapshot(balances) })
+}
+
+func (s *DispatchStream) EmitBalanceUpdate(balances BalanceMap) {
+	s.dispatch("", func() { s.StandardStream.EmitBalanceUpdate(balances) })
+}
+
+func (s *DispatchStream) EmitKLineClosed(kline KLine) {
+	s.dispatch(kline.Symbol, func() { s.StandardStream.EmitKLineClosed(kline) })
+}
+
+func (s *DispatchStream) EmitKLine(kline KLine) {
+	s.dispatch(kline.Symbol, func() { s.StandardStream.EmitKLine(kline) })
+}
+
+func (s *DispatchStream) EmitBookUpdate(book OrderBook) {
+	s.dispatch(book.Symbol, func() { s.StandardStream.EmitBookUpdate(book) })
+}
+
+func (s *DispatchStream) EmitBookSnapshot(book OrderBook) {
+	s.dispatch(book.Symbol, func() { s.StandardStream.EmitBookSnapshot(book) })
+}
+
+func (s *DispatchStream) EmitMarketTrade(trade Trade) {
+	s.dispatch(trade.Symbol, func() { s.StandardStream.EmitMarketTrade(trade) })
+}
+
+func (s *DispatchStream) EmitFundingRate(rate FundingRate) {
+	s.dispatch(rate.Symbol, func() { s.StandardStream.EmitFundingRate(rate) })
+}
+
+func (s *DispatchStream) EmitPositionUpdate(position FuturesPosition) {
+	s.dispatch(position.Symbol, func() { s.StandardStream.EmitPositionUpdate(position) })
+}
+
+func (s *DispatchStream) EmitMarginCall(marginCall MarginCall) {
+	s.dispatch(marginCall.Symbol, func() { s.StandardStream.EmitMarginCall(marginCall) })
+}
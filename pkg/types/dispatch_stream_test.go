@@ -0,0 +1,99 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUpstream struct {
+	StandardStream
+}
+
+func (s *testUpstream) SetPublicOnly() {}
+
+func (s *testUpstream) Connect(ctx context.Context) error {
+	s.EmitConnect()
+	return nil
+}
+
+func (s *testUpstream) Close() error {
+	return nil
+}
+
+func TestDispatchStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	upstream := &testUpstream{}
+	stream := NewDispatchStream(upstream, DispatcherOptions{Workers: 2, QueueSize: 2})
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var connected bool
+	var klines = map[string][]float64{}
+
+	wg.Add(1)
+	stream.OnConnect(func() {
+		defer wg.Done()
+		mu.Lock()
+		connected = true
+		mu.Unlock()
+	})
+
+	stream.OnKLineClosed(func(kline KLine) {
+		defer wg.Done()
+
+		// slow callbacks make the queues full
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		klines[kline.Symbol] = append(klines[kline.Symbol], kline.Close)
+		mu.Unlock()
+	})
+
+	assert.NoError(t, stream.Connect(ctx))
+
+	for i := 0; i < 20; i++ {
+		for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "MAXUSDT"} {
+			wg.Add(1)
+			upstream.EmitKLineClosed(KLine{Symbol: symbol, Close: float64(i)})
+		}
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.True(t, connected)
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "MAXUSDT"} {
+		if assert.Len(t, klines[symbol], 20) {
+			for i, c := range klines[symbol] {
+				assert.Equal(t, float64(i), c, "the klines of the same symbol should be handled in order")
+			}
+		}
+	}
+
+	stats := stream.Stats()
+	assert.Equal(t, int64(61), stats.Dispatched)
+	assert.Greater(t, stats.Blocked, int64(0))
+	assert.Equal(t, 0, stats.QueueLength)
+}
+
+func TestDispatchStream_NotStarted(t *testing.T) {
+	upstream := &testUpstream{}
+	stream := NewDispatchStream(upstream, DispatcherOptions{})
+
+	var called bool
+	stream.OnTradeUpdate(func(trade Trade) {
+		called = true
+	})
+
+	// the callbacks are called directly before the stream is connected
+	upstream.EmitTradeUpdate(Trade{Symbol: "BTCUSDT"})
+	assert.True(t, called)
+}
@@ -17,8 +17,12 @@ func (n ExchangeName) String() string {
 }
 
 const (
-	ExchangeMax     = ExchangeName("max")
-	ExchangeBinance = ExchangeName("binance")
+	ExchangeMax      = ExchangeName("max")
+	ExchangeBinance  = ExchangeName("binance")
+	ExchangeKucoin   = ExchangeName("kucoin")
+	ExchangeCoinbase = ExchangeName("coinbase")
+	ExchangeBitfinex = ExchangeName("bitfinex")
+	ExchangeKraken   = ExchangeName("kraken")
 )
 
 func ValidExchangeName(a string) (ExchangeName, error) {
@@ -27,6 +31,14 @@ func ValidExchangeName(a string) (ExchangeName, error) {
 		return ExchangeMax, nil
 	case "binance", "bn":
 		return ExchangeBinance, nil
+	case "kucoin", "kc":
+		return ExchangeKucoin, nil
+	case "coinbase", "cb":
+		return ExchangeCoinbase, nil
+	case "bitfinex", "bfx":
+		return ExchangeBitfinex, nil
+	case "kraken", "kr":
+		return ExchangeKraken, nil
 	}
 
 	return "", errors.New("invalid exchange name")
@@ -62,6 +74,18 @@ type Exchange interface {
 	CancelOrders(ctx context.Context, orders ...Order) error
 }
 
+// BatchOrderSubmitter is implemented by the exchanges that create multiple orders in one request (e.g., MAX).
+// The created orders and the errors are aligned with the given orders, either the order or the error of an index is set,
+// and the returned error means the whole request failed.
+type BatchOrderSubmitter interface {
+	BatchSubmitOrders(ctx context.Context, orders ...SubmitOrder) (createdOrders []Order, errs []error, err error)
+}
+
+// GroupOrderCanceler is implemented by the exchanges that support canceling the order group in one request (e.g., MAX)
+type GroupOrderCanceler interface {
+	CancelOrdersByGroupID(ctx context.Context, groupID int64) ([]Order, error)
+}
+
 type TradeQueryOptions struct {
 	StartTime   *time.Time
 	EndTime     *time.Time
@@ -0,0 +1,61 @@
+package types
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// TradingFeeRate is the maker and the taker fee rates of a symbol, e.g., 0.001 for 0.1%
+type TradingFeeRate struct {
+	Symbol       string           `json:"symbol"`
+	MakerFeeRate fixedpoint.Value `json:"makerFeeRate"`
+	TakerFeeRate fixedpoint.Value `json:"takerFeeRate"`
+}
+
+// TradingFeeRateMap is the trading fee rates keyed by the symbol
+type TradingFeeRateMap map[string]TradingFeeRate
+
+// AccountFees is the fee tier of the account and the fee discount of paying the fees with the platform currency
+type AccountFees struct {
+	// Tier is the fee tier of the account, e.g., "VIP 1", it's empty if the exchange does not report the tier
+	Tier string `json:"tier,omitempty"`
+
+	// MakerFeeRate and TakerFeeRate are the default fee rates of the tier before the discount
+	MakerFeeRate fixedpoint.Value `json:"makerFeeRate"`
+	TakerFeeRate fixedpoint.Value `json:"takerFeeRate"`
+
+	// DiscountCurrency is the currency paying the fees with the discount, e.g., BNB
+	DiscountCurrency string `json:"discountCurrency,omitempty"`
+
+	// DiscountRate is the ratio of the fee discounted, e.g., 0.25 means the fee is 75% of the fee rate
+	DiscountRate fixedpoint.Value `json:"discountRate,omitempty"`
+
+	// DiscountEnabled is true if the fees are paid with the discount currency
+	DiscountEnabled bool `json:"discountEnabled"`
+}
+
+// Discount returns the fee rate after the discount if the discount is enabled
+func (f AccountFees) Discount(rate fixedpoint.Value) fixedpoint.Value {
+	if !f.DiscountEnabled || f.DiscountRate <= 0 {
+		return rate
+	}
+
+	return rate.MulFloat64(1.0 - f.DiscountRate.Float64())
+}
+
+// DiscountTradingFeeRate returns the trading fee rate after the discount if the discount is enabled
+func (f AccountFees) DiscountTradingFeeRate(rate TradingFeeRate) TradingFeeRate {
+	rate.MakerFeeRate = f.Discount(rate.MakerFeeRate)
+	rate.TakerFeeRate = f.Discount(rate.TakerFeeRate)
+	return rate
+}
+
+// ExchangeFeeService is implemented by the exchanges that can query the fee tier and the trading fee rates of the account,
+// the returned fee rates are before the discount of AccountFees.
+type ExchangeFeeService interface {
+	QueryAccountFees(ctx context.Context) (*AccountFees, error)
+
+	// QueryTradingFeeRates queries the fee rates of the given symbols, or all the symbols if no symbol is given
+	QueryTradingFeeRates(ctx context.Context, symbols ...string) (TradingFeeRateMap, error)
+}
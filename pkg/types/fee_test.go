@@ -0,0 +1,33 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestAccountFees_Discount(t *testing.T) {
+	fees := AccountFees{
+		MakerFeeRate:     fixedpoint.NewFromFloat(0.001),
+		TakerFeeRate:     fixedpoint.NewFromFloat(0.001),
+		DiscountCurrency: "BNB",
+		DiscountRate:     fixedpoint.NewFromFloat(0.25),
+	}
+
+	// the discount is not applied if the fees are not paid with the discount currency
+	assert.Equal(t, 0.001, fees.Discount(fees.MakerFeeRate).Float64())
+
+	fees.DiscountEnabled = true
+	assert.InDelta(t, 0.00075, fees.Discount(fees.MakerFeeRate).Float64(), 1e-9)
+
+	rate := fees.DiscountTradingFeeRate(TradingFeeRate{
+		Symbol:       "BTCUSDT",
+		MakerFeeRate: fixedpoint.NewFromFloat(0.0009),
+		TakerFeeRate: fixedpoint.NewFromFloat(0.001),
+	})
+	assert.Equal(t, "BTCUSDT", rate.Symbol)
+	assert.InDelta(t, 0.000675, rate.MakerFeeRate.Float64(), 1e-9)
+	assert.InDelta(t, 0.00075, rate.TakerFeeRate.Float64(), 1e-9)
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// MarginType is the margin mode of the futures position
+type MarginType string
+
+const (
+	MarginTypeIsolated = MarginType("ISOLATED")
+	MarginTypeCrossed  = MarginType("CROSSED")
+)
+
+// PositionSide is the side of the futures position, BOTH is used in the one-way position mode
+type PositionSide string
+
+const (
+	PositionSideBoth  = PositionSide("BOTH")
+	PositionSideLong  = PositionSide("LONG")
+	PositionSideShort = PositionSide("SHORT")
+)
+
+type FuturesExchange interface {
+	UseFutures()
+	GetFuturesSettings() FuturesSettings
+
+	// SetLeverage sets the initial leverage of the symbol
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+
+	// SetMarginType switches the margin mode of the symbol, it's a no-op if the symbol is already in the margin type
+	SetMarginType(ctx context.Context, symbol string, marginType MarginType) error
+
+	// QueryPositions queries the open positions, all the positions are returned if no symbol is given
+	QueryPositions(ctx context.Context, symbols ...string) ([]FuturesPosition, error)
+
+	// QueryFundingRate queries the current funding rate and the next funding time of the perpetual contract
+	QueryFundingRate(ctx context.Context, symbol string) (*FundingRate, error)
+}
+
+type FuturesSettings struct {
+	IsFutures bool
+}
+
+func (s FuturesSettings) GetFuturesSettings() FuturesSettings {
+	return s
+}
+
+func (s *FuturesSettings) UseFutures() {
+	s.IsFutures = true
+}
+
+// FuturesPosition is the position held on the exchange,
+// the quantity is negative for the short position in the one-way position mode.
+type FuturesPosition struct {
+	Symbol       string       `json:"symbol"`
+	PositionSide PositionSide `json:"positionSide"`
+	MarginType   MarginType   `json:"marginType"`
+	Leverage     int          `json:"leverage"`
+
+	Quantity         fixedpoint.Value `json:"quantity"`
+	EntryPrice       fixedpoint.Value `json:"entryPrice"`
+	MarkPrice        fixedpoint.Value `json:"markPrice"`
+	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+	UnrealizedProfit fixedpoint.Value `json:"unrealizedProfit"`
+	IsolatedMargin   fixedpoint.Value `json:"isolatedMargin"`
+}
+
+func (p FuturesPosition) IsLong() bool {
+	return p.PositionSide == PositionSideLong || (p.PositionSide == PositionSideBoth && p.Quantity > 0)
+}
+
+func (p FuturesPosition) IsShort() bool {
+	return p.PositionSide == PositionSideShort || (p.PositionSide == PositionSideBoth && p.Quantity < 0)
+}
+
+// FundingRate is the funding rate of the perpetual contract,
+// the long position pays the short position when the rate is positive.
+// The rate emitted by the stream is the predicted rate of the next funding time.
+type FundingRate struct {
+	Symbol          string           `json:"symbol"`
+	FundingRate     fixedpoint.Value `json:"fundingRate"`
+	MarkPrice       fixedpoint.Value `json:"markPrice"`
+	NextFundingTime time.Time        `json:"nextFundingTime"`
+	Time            time.Time        `json:"time"`
+}
+
+// MarginCall is the warning of the position that is close to the liquidation,
+// the maintenance margin is the margin required to keep the position open.
+type MarginCall struct {
+	Symbol       string       `json:"symbol"`
+	PositionSide PositionSide `json:"positionSide"`
+	MarginType   MarginType   `json:"marginType"`
+
+	Quantity           fixedpoint.Value `json:"quantity"`
+	MarkPrice          fixedpoint.Value `json:"markPrice"`
+	UnrealizedProfit   fixedpoint.Value `json:"unrealizedProfit"`
+	IsolatedMargin     fixedpoint.Value `json:"isolatedMargin"`
+	MaintenanceMargin  fixedpoint.Value `json:"maintenanceMargin"`
+	CrossWalletBalance fixedpoint.Value `json:"crossWalletBalance"`
+	Time               time.Time        `json:"time"`
+}
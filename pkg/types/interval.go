@@ -73,8 +73,15 @@ type IntervalWindow struct {
 
 	// The windows size of the indicator (EWMA and SMA)
 	Window int
+
+	// PriceSource is the kline price used as the indicator input, defaults to the close price
+	PriceSource PriceSource
 }
 
 func (iw IntervalWindow) String() string {
+	if iw.PriceSource != "" && iw.PriceSource != PriceSourceClose {
+		return fmt.Sprintf("%s (%d, %s)", iw.Interval, iw.Window, iw.PriceSource)
+	}
+
 	return fmt.Sprintf("%s (%d)", iw.Interval, iw.Window)
 }
@@ -0,0 +1,177 @@
+package types
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// KLineQueryLimits is the max number of klines returned by one kline request of each exchange
+var KLineQueryLimits = map[ExchangeName]int{
+	ExchangeBinance:  1000,
+	ExchangeMax:      5000,
+	ExchangeKucoin:   1500,
+	ExchangeCoinbase: 300,
+	ExchangeBitfinex: 10000,
+	ExchangeKraken:   720,
+}
+
+// KLineQueryRateLimits is the request rate of the kline requests of each exchange, kept below the public api limits
+var KLineQueryRateLimits = map[ExchangeName]rate.Limit{
+	ExchangeBinance:  rate.Every(200 * time.Millisecond),
+	ExchangeMax:      rate.Every(500 * time.Millisecond),
+	ExchangeKucoin:   rate.Every(500 * time.Millisecond),
+	ExchangeCoinbase: rate.Every(200 * time.Millisecond),
+	ExchangeBitfinex: rate.Every(2 * time.Second),
+	ExchangeKraken:   rate.Every(time.Second),
+}
+
+const defaultKLineQueryLimit = 500
+
+const defaultKLineQueryMaxRetries = 3
+
+// KLineFetcher queries the klines of a large time range by splitting the range into the requests of the exchange limit size,
+// the requests are rate limited and the overlapping klines of the adjacent requests are removed.
+type KLineFetcher struct {
+	Exchange Exchange
+
+	// Limit is the number of klines per request, defaults to the exchange limit
+	Limit int
+
+	// Limiter limits the request rate, defaults to the exchange kline rate limit
+	Limiter *rate.Limiter
+
+	// MaxRetries is the number of the retries of a failed request, the retries are delayed exponentially
+	MaxRetries int
+}
+
+func NewKLineFetcher(exchange Exchange) *KLineFetcher {
+	limit, ok := KLineQueryLimits[exchange.Name()]
+	if !ok {
+		limit = defaultKLineQueryLimit
+	}
+
+	r, ok := KLineQueryRateLimits[exchange.Name()]
+	if !ok {
+		r = rate.Every(time.Second)
+	}
+
+	return &KLineFetcher{
+		Exchange:   exchange,
+		Limit:      limit,
+		Limiter:    rate.NewLimiter(r, 1),
+		MaxRetries: defaultKLineQueryMaxRetries,
+	}
+}
+
+// Fetch streams the closed klines between the start time and the end time in the ascending order,
+// the kline channel is closed when all the klines are sent or an error is sent to the error channel.
+func (f *KLineFetcher) Fetch(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time) (<-chan KLine, <-chan error) {
+	c := make(chan KLine, f.Limit)
+	errC := make(chan error, 1)
+
+	go func() {
+		defer close(c)
+		defer close(errC)
+
+		if err := f.fetch(ctx, symbol, interval, startTime, endTime, c); err != nil {
+			errC <- err
+		}
+	}()
+
+	return c, errC
+}
+
+// FetchAll collects all the klines between the start time and the end time
+func (f *KLineFetcher) FetchAll(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time) ([]KLine, error) {
+	var kLines []KLine
+
+	c, errC := f.Fetch(ctx, symbol, interval, startTime, endTime)
+	for k := range c {
+		kLines = append(kLines, k)
+	}
+
+	return kLines, <-errC
+}
+
+func (f *KLineFetcher) fetch(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time, c chan<- KLine) error {
+	var window = time.Duration(f.Limit) * interval.Duration()
+	var lastStartTime time.Time
+
+	for startTime.Before(endTime) {
+		windowEndTime := startTime.Add(window)
+		if windowEndTime.After(endTime) {
+			windowEndTime = endTime
+		}
+
+		kLines, err := f.query(ctx, symbol, interval, startTime, windowEndTime)
+		if err != nil {
+			return err
+		}
+
+		// some exchanges return the klines in the descending order
+		sort.Slice(kLines, func(i, j int) bool {
+			return kLines[i].StartTime.Before(kLines[j].StartTime)
+		})
+
+		var nextStartTime = windowEndTime
+		for _, k := range kLines {
+			// ignore any kline before the given start time and the klines sent by the previous request
+			if k.StartTime.Before(startTime) || (!lastStartTime.IsZero() && !k.StartTime.After(lastStartTime)) {
+				continue
+			}
+
+			// ignore the unclosed kline
+			if k.EndTime.After(endTime) {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case c <- k:
+			}
+
+			lastStartTime = k.StartTime
+			nextStartTime = k.StartTime.Add(interval.Duration())
+		}
+
+		// when no kline is returned, there is no kline in the window (e.g., exchange maintenance), move to the next window
+		startTime = nextStartTime
+	}
+
+	return nil
+}
+
+func (f *KLineFetcher) query(ctx context.Context, symbol string, interval Interval, startTime, endTime time.Time) (kLines []KLine, err error) {
+	var delay = time.Second
+	for retry := 0; ; retry++ {
+		if f.Limiter != nil {
+			if err := f.Limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		kLines, err = f.Exchange.QueryKLines(ctx, symbol, interval, KLineQueryOptions{
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Limit:     f.Limit,
+		})
+		if err == nil || retry >= f.MaxRetries {
+			return kLines, err
+		}
+
+		logrus.WithError(err).Warnf("%s %s kline query error, retrying in %s", symbol, interval, delay)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// klineExchange returns the 1m klines of the query range, the kline right before the start time is returned as well
+// to simulate the overlapping klines of the adjacent requests.
+type klineExchange struct {
+	Exchange
+
+	queries int
+}
+
+func (e *klineExchange) Name() ExchangeName {
+	return ExchangeBinance
+}
+
+func (e *klineExchange) QueryKLines(ctx context.Context, symbol string, interval Interval, options KLineQueryOptions) ([]KLine, error) {
+	e.queries++
+
+	var kLines []KLine
+	for t := options.StartTime.Add(-interval.Duration()); !t.After(*options.EndTime) && len(kLines) < options.Limit; t = t.Add(interval.Duration()) {
+		kLines = append(kLines, KLine{
+			Symbol:    symbol,
+			Interval:  interval,
+			StartTime: t,
+			EndTime:   t.Add(interval.Duration() - time.Millisecond),
+		})
+	}
+
+	return kLines, nil
+}
+
+func TestKLineFetcher_FetchAll(t *testing.T) {
+	exchange := &klineExchange{}
+	fetcher := NewKLineFetcher(exchange)
+	fetcher.Limit = 100
+	fetcher.Limiter = nil
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.Add(250 * time.Minute)
+
+	kLines, err := fetcher.FetchAll(context.Background(), "BTCUSDT", Interval1m, startTime, endTime)
+	assert.NoError(t, err)
+	assert.Len(t, kLines, 250)
+	assert.Equal(t, startTime, kLines[0].StartTime)
+
+	for i := 1; i < len(kLines); i++ {
+		assert.Equal(t, kLines[i-1].StartTime.Add(time.Minute), kLines[i].StartTime)
+	}
+
+	assert.True(t, exchange.queries >= 3)
+}
@@ -9,17 +9,21 @@ import (
 func TestKLineWindow_Tail(t *testing.T) {
 	var win = KLineWindow{
 		{Open: 11600.0, Close: 11600.0, High: 11600.0, Low: 11600.0},
-		{Open: 11600.0, Close: 11600.0, High: 11600.0, Low: 11600.0},
+		{Open: 11601.0, Close: 11600.0, High: 11600.0, Low: 11600.0},
+		{Open: 11602.0, Close: 11600.0, High: 11600.0, Low: 11600.0},
 	}
 
 	var win2 = win.Tail(1)
 	assert.Len(t, win2, 1)
+	assert.Equal(t, 11602.0, win2[0].Open)
 
 	var win3 = win.Tail(2)
 	assert.Len(t, win3, 2)
+	assert.Equal(t, 11601.0, win3[0].Open)
+	assert.Equal(t, 11602.0, win3[1].Open)
 
-	var win4 = win.Tail(3)
-	assert.Len(t, win4, 2)
+	var win4 = win.Tail(4)
+	assert.Len(t, win4, 3)
 }
 
 func TestKLineWindow_Truncate(t *testing.T) {
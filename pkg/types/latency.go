@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencySamples is the number of the latest samples kept for each channel
+const DefaultLatencySamples = 1000
+
+// LatencyProvider is implemented by the streams that record the event latencies
+type LatencyProvider interface {
+	Latency() *LatencyRecorder
+}
+
+type LatencyPercentiles struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+func (p LatencyPercentiles) String() string {
+	return fmt.Sprintf("p50=%s p90=%s p99=%s max=%s", p.P50, p.P90, p.P99, p.Max)
+}
+
+// LatencyReport is the latency statistics of a channel
+type LatencyReport struct {
+	Channel string `json:"channel"`
+	Count   int    `json:"count"`
+
+	// Network is the latency from the exchange event time to the local receive time,
+	// note that the clock skew between the exchange and the local machine is included.
+	Network LatencyPercentiles `json:"network"`
+
+	// Processing is the latency from the local receive time to the time all the callbacks are done
+	Processing LatencyPercentiles `json:"processing"`
+}
+
+func (r LatencyReport) String() string {
+	return fmt.Sprintf("%s (%d events): network %s, processing %s", r.Channel, r.Count, r.Network.String(), r.Processing.String())
+}
+
+// latencySamples is a ring buffer of the latest samples
+type latencySamples struct {
+	samples []time.Duration
+	next    int
+}
+
+func (s *latencySamples) add(d time.Duration, maxSamples int) {
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % maxSamples
+}
+
+func (s *latencySamples) percentiles() (p LatencyPercentiles) {
+	if len(s.samples) == 0 {
+		return p
+	}
+
+	sorted := make([]time.Duration, len(s.samples))
+	copy(sorted, s.samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	at := func(q float64) time.Duration {
+		return sorted[int(q*float64(len(sorted)-1))]
+	}
+
+	p.P50 = at(0.5)
+	p.P90 = at(0.9)
+	p.P99 = at(0.99)
+	p.Max = sorted[len(sorted)-1]
+	return p
+}
+
+type channelLatency struct {
+	count      int
+	network    latencySamples
+	processing latencySamples
+}
+
+// LatencyRecorder records the event latencies of the stream by channel
+type LatencyRecorder struct {
+	MaxSamples int
+
+	mu       sync.Mutex
+	channels map[string]*channelLatency
+}
+
+func NewLatencyRecorder(maxSamples int) *LatencyRecorder {
+	return &LatencyRecorder{
+		MaxSamples: maxSamples,
+		channels:   make(map[string]*channelLatency),
+	}
+}
+
+// Record records the latencies of an event, the eventTime is the event time reported by the exchange,
+// it's ignored if it's zero.
+func (r *LatencyRecorder) Record(channel string, eventTime, receiveTime, processedTime time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.channels[channel]
+	if !ok {
+		c = &channelLatency{}
+		r.channels[channel] = c
+	}
+
+	c.count++
+	if !eventTime.IsZero() {
+		c.network.add(receiveTime.Sub(eventTime), r.MaxSamples)
+	}
+
+	c.processing.add(processedTime.Sub(receiveTime), r.MaxSamples)
+}
+
+// Report returns the latency reports of the channels sorted by the channel name
+func (r *LatencyRecorder) Report() (reports []LatencyReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for channel, c := range r.channels {
+		reports = append(reports, LatencyReport{
+			Channel:    channel,
+			Count:      c.count,
+			Network:    c.network.percentiles(),
+			Processing: c.processing.percentiles(),
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Channel < reports[j].Channel
+	})
+
+	return reports
+}
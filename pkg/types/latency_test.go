@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+	recorder := NewLatencyRecorder(100)
+
+	now := time.Now()
+	for i := 1; i <= 200; i++ {
+		eventTime := now.Add(-time.Duration(i) * time.Millisecond)
+		recorder.Record("kline", eventTime, now, now.Add(time.Duration(i)*time.Microsecond))
+	}
+
+	recorder.Record("book", time.Time{}, now, now.Add(time.Millisecond))
+
+	reports := recorder.Report()
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "book", reports[0].Channel)
+		assert.Equal(t, time.Duration(0), reports[0].Network.Max)
+		assert.Equal(t, time.Millisecond, reports[0].Processing.P50)
+
+		// only the latest 100 samples are kept
+		kline := reports[1]
+		assert.Equal(t, 200, kline.Count)
+		assert.Equal(t, 200*time.Millisecond, kline.Network.Max)
+		assert.Equal(t, 150*time.Millisecond, kline.Network.P50)
+		assert.Equal(t, 200*time.Microsecond, kline.Processing.Max)
+	}
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// LendingExchange is implemented by the exchanges that run a margin funding market, where the funds are lent to the margin traders,
+// the rates of the lending market are daily rates.
+type LendingExchange interface {
+	// QueryLendingTicker queries the current rates of the lending market of the currency
+	QueryLendingTicker(ctx context.Context, currency string) (*LendingTicker, error)
+
+	// QueryLendingBook queries the offers (asks) and the bids of the lending market of the currency
+	QueryLendingBook(ctx context.Context, currency string) (*LendingBook, error)
+
+	// SubmitLendingOffer places the offer of lending the currency at the rate for the period
+	SubmitLendingOffer(ctx context.Context, offer SubmitLendingOffer) (*LendingOffer, error)
+
+	// CancelLendingOffers cancels the active offers, the lent funds (credits) can not be canceled
+	CancelLendingOffers(ctx context.Context, offers ...LendingOffer) error
+
+	// QueryLendingOffers queries the active offers that are not taken yet
+	QueryLendingOffers(ctx context.Context, currency string) ([]LendingOffer, error)
+
+	// QueryLendingCredits queries the funds that are lent out and earning the interest
+	QueryLendingCredits(ctx context.Context, currency string) ([]LendingCredit, error)
+}
+
+// LendingTicker is the snapshot of the lending market, FlashReturnRate is the average rate of all the active credits
+type LendingTicker struct {
+	Currency        string           `json:"currency"`
+	FlashReturnRate fixedpoint.Value `json:"flashReturnRate"`
+	BidRate         fixedpoint.Value `json:"bidRate"`
+	BidPeriod       int              `json:"bidPeriod"`
+	BidAmount       fixedpoint.Value `json:"bidAmount"`
+	AskRate         fixedpoint.Value `json:"askRate"`
+	AskPeriod       int              `json:"askPeriod"`
+	AskAmount       fixedpoint.Value `json:"askAmount"`
+	LastRate        fixedpoint.Value `json:"lastRate"`
+	Volume          fixedpoint.Value `json:"volume"`
+	HighRate        fixedpoint.Value `json:"highRate"`
+	LowRate         fixedpoint.Value `json:"lowRate"`
+}
+
+// LendingBookEntry is the aggregated offers of the same rate and period
+type LendingBookEntry struct {
+	Rate   fixedpoint.Value `json:"rate"`
+	Period int              `json:"period"`
+	Count  int              `json:"count"`
+	Amount fixedpoint.Value `json:"amount"`
+}
+
+// LendingBook is the order book of the lending market, the asks are the lending offers sorted by the lowest rate first,
+// and the bids are the borrowing requests sorted by the highest rate first.
+type LendingBook struct {
+	Currency string             `json:"currency"`
+	Bids     []LendingBookEntry `json:"bids"`
+	Asks     []LendingBookEntry `json:"asks"`
+}
+
+type SubmitLendingOffer struct {
+	Currency string           `json:"currency"`
+	Amount   fixedpoint.Value `json:"amount"`
+
+	// Rate is the daily rate of the offer
+	Rate fixedpoint.Value `json:"rate"`
+
+	// Period is the lending period in days
+	Period int `json:"period"`
+}
+
+type LendingOfferStatus string
+
+const (
+	LendingOfferStatusActive          = LendingOfferStatus("ACTIVE")
+	LendingOfferStatusPartiallyFilled = LendingOfferStatus("PARTIALLY_FILLED")
+	LendingOfferStatusExecuted        = LendingOfferStatus("EXECUTED")
+	LendingOfferStatusCanceled        = LendingOfferStatus("CANCELED")
+)
+
+type LendingOffer struct {
+	SubmitLendingOffer
+
+	Exchange ExchangeName       `json:"exchange"`
+	OfferID  uint64             `json:"offerID"`
+	Status   LendingOfferStatus `json:"status"`
+
+	// RemainingAmount is the amount that is not taken yet
+	RemainingAmount fixedpoint.Value `json:"remainingAmount"`
+
+	CreationTime time.Time `json:"creationTime"`
+	UpdateTime   time.Time `json:"updateTime"`
+}
+
+// LendingCredit is the lent funds taken by the borrower, the interest is paid daily until the credit is closed or expired
+type LendingCredit struct {
+	Exchange ExchangeName     `json:"exchange"`
+	CreditID uint64           `json:"creditID"`
+	Currency string           `json:"currency"`
+	Amount   fixedpoint.Value `json:"amount"`
+	Rate     fixedpoint.Value `json:"rate"`
+	Period   int              `json:"period"`
+
+	OpeningTime    time.Time `json:"openingTime"`
+	LastPayoutTime time.Time `json:"lastPayoutTime"`
+}
+
+// ExpiryTime returns the time that the credit is returned to the lender
+func (c LendingCredit) ExpiryTime() time.Time {
+	return c.OpeningTime.Add(time.Duration(c.Period) * 24 * time.Hour)
+}
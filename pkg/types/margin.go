@@ -1,5 +1,11 @@
 package types
 
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
 type MarginExchange interface {
 	UseMargin()
 	UseIsolatedMargin(symbol string)
@@ -7,6 +13,18 @@ type MarginExchange interface {
 	// QueryMarginAccount(ctx context.Context) (*binance.MarginAccount, error)
 }
 
+// MarginBorrowRepayService is implemented by the exchanges supporting borrowing and repaying the assets of the margin account
+type MarginBorrowRepayService interface {
+	BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error
+	RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error
+	QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error)
+}
+
+// MarginBalanceService queries the margin account balances, the Borrowed field includes the accrued interest
+type MarginBalanceService interface {
+	QueryMarginBalances(ctx context.Context) (BalanceMap, error)
+}
+
 type MarginSettings struct {
 	IsMargin             bool
 	IsIsolatedMargin     bool
@@ -6,6 +6,8 @@ import (
 	"math"
 	"strconv"
 	"time"
+
+	"github.com/c9s/bbgo/pkg/util"
 )
 
 type Duration time.Duration
@@ -62,23 +64,17 @@ type Market struct {
 	MinQuantity float64
 	MaxQuantity float64
 
+	// StepSize is the quantity increment, the order quantity must be a multiple of the step size
+	StepSize float64
+
 	MinPrice float64
 	MaxPrice float64
 	TickSize float64
 }
 
 func (m Market) FormatPriceCurrency(val float64) string {
-	switch m.QuoteCurrency {
-
-	case "USD", "USDT":
-		return USD.FormatMoneyFloat64(val)
-
-	case "BTC":
-		return BTC.FormatMoneyFloat64(val)
-
-	case "BNB":
-		return BNB.FormatMoneyFloat64(val)
-
+	if format, ok := GetCurrencyFormat(m.QuoteCurrency); ok {
+		return format.Format(val)
 	}
 
 	return m.FormatPrice(val)
@@ -86,19 +82,33 @@ func (m Market) FormatPriceCurrency(val float64) string {
 
 func (m Market) FormatPrice(val float64) string {
 	// p := math.Pow10(m.PricePrecision)
-	prec := int(math.Abs(math.Log10(m.MinPrice)))
-	p := math.Pow10(prec)
-	val = math.Trunc(val*p) / p
+	prec := stepPrecision(m.MinPrice)
+	// truncate with the step tolerance, so that the truncated price like 9999.99 is not truncated again by the float error
+	val = util.FloorToStep(val, math.Pow10(-prec))
 	return strconv.FormatFloat(val, 'f', prec, 64)
 }
 
 func (m Market) FormatQuantity(val float64) string {
-	prec := int(math.Abs(math.Log10(m.MinLot)))
-	p := math.Pow10(prec)
-	val = math.Trunc(val*p) / p
+	prec := stepPrecision(m.MinLot)
+	val = util.FloorToStep(val, math.Pow10(-prec))
 	return strconv.FormatFloat(val, 'f', prec, 64)
 }
 
+// stepPrecision returns the number of the decimals of the step, the log is rounded since
+// the log of the step like 0.0001 is -3.9999999999999996 in the float arithmetic.
+func stepPrecision(step float64) int {
+	if step <= 0 {
+		return 0
+	}
+
+	prec := int(math.Round(-math.Log10(step)))
+	if prec < 0 {
+		return 0
+	}
+
+	return prec
+}
+
 func (m Market) FormatVolume(val float64) string {
 	p := math.Pow10(m.VolumePrecision)
 	val = math.Trunc(val*p) / p
@@ -110,4 +120,97 @@ func (m Market) CanonicalizeVolume(val float64) float64 {
 	return math.Trunc(p*val) / p
 }
 
+// QuantityStep returns the step size of the quantity, falls back to the min lot and the volume precision
+// if the exchange adapter does not provide the step size.
+func (m Market) QuantityStep() float64 {
+	if m.StepSize > 0 {
+		return m.StepSize
+	}
+
+	if m.MinLot > 0 {
+		return m.MinLot
+	}
+
+	return 1.0 / math.Pow10(m.VolumePrecision)
+}
+
+// PriceStep returns the tick size of the price, falls back to the price precision
+func (m Market) PriceStep() float64 {
+	if m.TickSize > 0 {
+		return m.TickSize
+	}
+
+	return 1.0 / math.Pow10(m.PricePrecision)
+}
+
+// RoundDownQuantity rounds the quantity down to the step size, it's used for the quantity that can not exceed the balance
+func (m Market) RoundDownQuantity(quantity float64) float64 {
+	return util.FloorToStep(quantity, m.QuantityStep())
+}
+
+// RoundUpQuantity rounds the quantity up to the step size, it's used for meeting the minimal quantity
+func (m Market) RoundUpQuantity(quantity float64) float64 {
+	return util.CeilToStep(quantity, m.QuantityStep())
+}
+
+// RoundQuantity rounds the quantity to the nearest step with the half-even rounding
+func (m Market) RoundQuantity(quantity float64) float64 {
+	return util.RoundHalfEvenToStep(quantity, m.QuantityStep())
+}
+
+// RoundDownPrice rounds the price down to the tick size
+func (m Market) RoundDownPrice(price float64) float64 {
+	return util.FloorToStep(price, m.PriceStep())
+}
+
+// RoundUpPrice rounds the price up to the tick size
+func (m Market) RoundUpPrice(price float64) float64 {
+	return util.CeilToStep(price, m.PriceStep())
+}
+
+// TruncateQuantity truncates the quantity to the step size and caps it at the max quantity,
+// the quantity is kept as it is if the market has no lot size information.
+func (m Market) TruncateQuantity(quantity float64) float64 {
+	if m.MaxQuantity > 0 && quantity > m.MaxQuantity {
+		quantity = m.MaxQuantity
+	}
+
+	if m.StepSize <= 0 && m.MinLot <= 0 && m.VolumePrecision <= 0 {
+		return quantity
+	}
+
+	return m.RoundDownQuantity(quantity)
+}
+
+// TruncatePrice truncates the price to the tick size, the price is kept as it is if the market has no tick size information.
+func (m Market) TruncatePrice(price float64) float64 {
+	if m.TickSize <= 0 && m.PricePrecision <= 0 {
+		return price
+	}
+
+	return m.RoundDownPrice(price)
+}
+
+// MinNotionalValue returns the min quote value of an order, it's the greater one of the min notional and the min amount
+func (m Market) MinNotionalValue() float64 {
+	return math.Max(m.MinNotional, m.MinAmount)
+}
+
+// GreaterThanMinNotional checks if the quote value of the quantity at the price meets the min notional filter
+func (m Market) GreaterThanMinNotional(quantity, price float64) bool {
+	minNotional := m.MinNotionalValue()
+	return minNotional <= 0 || quantity*price >= minNotional
+}
+
+// IsDustQuantity checks if the quantity is too small to be submitted after the truncation, i.e., it's below the min quantity,
+// or the quote value at the price is below the min notional. The min notional is not checked if the price is zero.
+func (m Market) IsDustQuantity(quantity, price float64) bool {
+	quantity = m.TruncateQuantity(quantity)
+	if quantity <= 0 || quantity < m.MinQuantity {
+		return true
+	}
+
+	return price > 0 && !m.GreaterThanMinNotional(quantity, price)
+}
+
 type MarketMap map[string]Market
@@ -50,3 +50,77 @@ func TestDurationParse(t *testing.T) {
 		})
 	}
 }
+
+func TestMarket_RoundQuantity(t *testing.T) {
+	// binance publishes minQty and stepSize separately
+	binanceMarket := Market{
+		Symbol:          "BTCUSDT",
+		VolumePrecision: 8,
+		MinLot:          0.000100,
+		MinQuantity:     0.000100,
+		StepSize:        0.000001,
+		TickSize:        0.01,
+	}
+
+	assert.Equal(t, 0.001234, binanceMarket.RoundDownQuantity(0.0012345))
+	assert.Equal(t, 0.001235, binanceMarket.RoundUpQuantity(0.0012341))
+	assert.Equal(t, 0.001234, binanceMarket.RoundQuantity(0.0012345))
+	assert.Equal(t, 19999.99, binanceMarket.RoundDownPrice(19999.999))
+	assert.Equal(t, 20000.0, binanceMarket.RoundUpPrice(19999.991))
+
+	// fall back to the min lot if the step size is not defined
+	maxMarket := Market{
+		Symbol:          "BTCTWD",
+		VolumePrecision: 4,
+		MinLot:          0.0001,
+		PricePrecision:  1,
+	}
+
+	assert.Equal(t, 0.0012, maxMarket.RoundDownQuantity(0.00129))
+	assert.Equal(t, 560000.1, maxMarket.RoundUpPrice(560000.01))
+}
+
+func TestMarket_Truncate(t *testing.T) {
+	market := Market{
+		Symbol:      "BTCUSDT",
+		MinLot:      0.0001,
+		MinQuantity: 0.0001,
+		MaxQuantity: 100.0,
+		StepSize:    0.0001,
+		TickSize:    0.01,
+		MinNotional: 10.0,
+	}
+
+	assert.Equal(t, 0.1234, market.TruncateQuantity(0.12349))
+	assert.Equal(t, 100.0, market.TruncateQuantity(120.0))
+	assert.Equal(t, 19999.99, market.TruncatePrice(19999.999))
+
+	// the market without the filters keeps the values
+	assert.Equal(t, 0.12349, Market{}.TruncateQuantity(0.12349))
+	assert.Equal(t, 19999.999, Market{}.TruncatePrice(19999.999))
+
+	assert.True(t, market.GreaterThanMinNotional(0.001, 10000.0))
+	assert.False(t, market.GreaterThanMinNotional(0.0009, 10000.0))
+
+	assert.True(t, market.IsDustQuantity(0.00009, 10000.0))
+	assert.True(t, market.IsDustQuantity(0.0009, 10000.0))
+	assert.False(t, market.IsDustQuantity(0.0009, 0))
+	assert.False(t, market.IsDustQuantity(0.001, 10000.0))
+}
+
+func TestMarket_Format(t *testing.T) {
+	market := Market{
+		Symbol:   "BTCUSDT",
+		MinLot:   0.0001,
+		MinPrice: 0.01,
+	}
+
+	assert.Equal(t, "0.1234", market.FormatQuantity(0.12345))
+	assert.Equal(t, "0.1234", market.FormatQuantity(0.1234))
+	assert.Equal(t, "9999.99", market.FormatPrice(9999.999))
+	assert.Equal(t, "9999.99", market.FormatPrice(9999.99))
+
+	market = Market{MinLot: 0.00000001, MinPrice: 1.0}
+	assert.Equal(t, "0.00012345", market.FormatQuantity(0.000123456))
+	assert.Equal(t, "560000", market.FormatPrice(560000.5))
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// SubmitOCOOrder is a one-cancels-other order pair of the same side, the take-profit limit order and the stop-loss order,
+// when either of them is filled or partially filled, the other one is canceled.
+type SubmitOCOOrder struct {
+	// ClientOrderID is the client id of the order list
+	ClientOrderID string `json:"clientOrderID,omitempty"`
+
+	Symbol   string   `json:"symbol"`
+	Side     SideType `json:"side"`
+	Quantity float64  `json:"quantity"`
+
+	// Price is the price of the take-profit limit order
+	Price float64 `json:"price"`
+
+	// StopPrice is the trigger price of the stop-loss order
+	StopPrice float64 `json:"stopPrice"`
+
+	// StopLimitPrice is the limit price of the stop-loss order, the stop-loss order is executed at the market price if it's zero
+	StopLimitPrice float64 `json:"stopLimitPrice,omitempty"`
+
+	Market Market `json:"-"`
+}
+
+// Validate checks the prices of the pair, the take-profit price should be on the profitable side of the stop price,
+// i.e., above the stop price for selling, and below the stop price for buying.
+func (o SubmitOCOOrder) Validate() error {
+	if o.Quantity <= 0 {
+		return fmt.Errorf("oco order quantity %f should be positive", o.Quantity)
+	}
+
+	if o.Price <= 0 || o.StopPrice <= 0 {
+		return fmt.Errorf("oco order price %f and stop price %f should be positive", o.Price, o.StopPrice)
+	}
+
+	switch o.Side {
+	case SideTypeSell:
+		if o.Price <= o.StopPrice {
+			return fmt.Errorf("the take-profit price %f of the selling oco order should be above the stop price %f", o.Price, o.StopPrice)
+		}
+
+	case SideTypeBuy:
+		if o.Price >= o.StopPrice {
+			return fmt.Errorf("the take-profit price %f of the buying oco order should be below the stop price %f", o.Price, o.StopPrice)
+		}
+
+	default:
+		return fmt.Errorf("invalid oco order side %q", o.Side)
+	}
+
+	return nil
+}
+
+// LimitOrder returns the take-profit limit order of the pair
+func (o SubmitOCOOrder) LimitOrder() SubmitOrder {
+	return SubmitOrder{
+		Symbol:   o.Symbol,
+		Side:     o.Side,
+		Type:     OrderTypeLimit,
+		Quantity: o.Quantity,
+		Price:    o.Price,
+		Market:   o.Market,
+	}
+}
+
+// StopOrder returns the stop-loss order of the pair, it's a stop limit order if the stop limit price is set
+func (o SubmitOCOOrder) StopOrder() SubmitOrder {
+	if o.StopLimitPrice > 0 {
+		return SubmitOrder{
+			Symbol:    o.Symbol,
+			Side:      o.Side,
+			Type:      OrderTypeStopLimit,
+			Quantity:  o.Quantity,
+			Price:     o.StopLimitPrice,
+			StopPrice: o.StopPrice,
+			Market:    o.Market,
+		}
+	}
+
+	return SubmitOrder{
+		Symbol:    o.Symbol,
+		Side:      o.Side,
+		Type:      OrderTypeStopMarket,
+		Quantity:  o.Quantity,
+		StopPrice: o.StopPrice,
+		Market:    o.Market,
+	}
+}
+
+// OCOOrder is the created order pair, Orders contains the created legs of the pair
+type OCOOrder struct {
+	ClientOrderID string `json:"clientOrderID"`
+
+	// OrderListID is the id of the native oco order, it's zero for the emulated pair
+	OrderListID uint64 `json:"orderListID,omitempty"`
+
+	Symbol string `json:"symbol"`
+
+	Orders []Order `json:"orders"`
+}
+
+// OCOExchange is implemented by the exchanges supporting the native oco orders
+type OCOExchange interface {
+	SubmitOCOOrder(ctx context.Context, order SubmitOCOOrder) (*OCOOrder, error)
+}
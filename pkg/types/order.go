@@ -48,6 +48,20 @@ func (t *OrderType) Scan(v interface{}) error {
 }
 */
 
+// TimeInForce defines how long the order remains active before it's executed or expired
+type TimeInForce string
+
+const (
+	// TimeInForceGTC keeps the order on the book until it's filled or canceled
+	TimeInForceGTC TimeInForce = "GTC"
+
+	// TimeInForceIOC fills the order immediately as much as possible, and cancels the unfilled quantity
+	TimeInForceIOC TimeInForce = "IOC"
+
+	// TimeInForceFOK fills the whole order immediately or cancels it
+	TimeInForceFOK TimeInForce = "FOK"
+)
+
 type OrderStatus string
 
 const (
@@ -76,11 +90,44 @@ type SubmitOrder struct {
 	PriceString     string `json:"-"`
 	QuantityString  string `json:"-"`
 
-	TimeInForce string `json:"timeInForce" db:"time_in_force"` // GTC, IOC, FOK
+	TimeInForce TimeInForce `json:"timeInForce" db:"time_in_force"` // GTC, IOC, FOK
+
+	// PostOnly makes the limit order maker only, the order is rejected instead of taking the liquidity
+	PostOnly bool `json:"postOnly,omitempty" db:"-"`
 
 	GroupID int64 `json:"groupID"`
 
 	MarginSideEffect MarginOrderSideEffectType `json:"marginSideEffect"` // AUTO_REPAY = repay, MARGIN_BUY = borrow, defaults to  NO_SIDE_EFFECT
+
+	// ReduceOnly makes the futures order only reduce the current position, it's ignored by the spot and margin orders
+	ReduceOnly bool `json:"reduceOnly,omitempty" db:"-"`
+
+	// Tags is the free-form metadata of the order, it's kept locally and stored with the order and its trades.
+	Tags OrderTags `json:"tags,omitempty" db:"tags"`
+}
+
+// ValidateTimeInForce checks the time in force and the post only flag against the order type,
+// the post only order must be a limit order that stays on the book, i.e., it can not be IOC or FOK.
+func (o *SubmitOrder) ValidateTimeInForce() error {
+	switch o.TimeInForce {
+	case "", TimeInForceGTC, TimeInForceIOC, TimeInForceFOK:
+	default:
+		return fmt.Errorf("unsupported time in force %q", o.TimeInForce)
+	}
+
+	if !o.PostOnly {
+		return nil
+	}
+
+	if o.Type != OrderTypeLimit {
+		return fmt.Errorf("post only is only supported by the limit order, got %s", o.Type)
+	}
+
+	if o.TimeInForce == TimeInForceIOC || o.TimeInForce == TimeInForceFOK {
+		return fmt.Errorf("post only order can not be %s", o.TimeInForce)
+	}
+
+	return nil
 }
 
 func (o *SubmitOrder) String() string {
@@ -93,18 +140,18 @@ func (o *SubmitOrder) PlainText() string {
 
 func (o *SubmitOrder) SlackAttachment() slack.Attachment {
 	var fields = []slack.AttachmentField{
-		{Title: "Symbol", Value: o.Symbol, Short: true},
-		{Title: "Side", Value: string(o.Side), Short: true},
-		{Title: "Volume", Value: o.QuantityString, Short: true},
+		{Title: Translate("Symbol"), Value: o.Symbol, Short: true},
+		{Title: Translate("Side"), Value: string(o.Side), Short: true},
+		{Title: Translate("Volume"), Value: o.QuantityString, Short: true},
 	}
 
 	if len(o.PriceString) > 0 {
-		fields = append(fields, slack.AttachmentField{Title: "Price", Value: o.PriceString, Short: true})
+		fields = append(fields, slack.AttachmentField{Title: Translate("Price"), Value: o.PriceString, Short: true})
 	}
 
 	return slack.Attachment{
 		Color: SideToColorName(o.Side),
-		Title: string(o.Type) + " Order " + string(o.Side),
+		Title: string(o.Type) + " " + Translate("Order") + " " + string(o.Side),
 		// Text:   "",
 		Fields: fields,
 	}
@@ -131,12 +178,17 @@ func (o Order) String() string {
 }
 
 func (o Order) PlainText() string {
-	return fmt.Sprintf("%s %s Order %s %s price %s, quantity %s/%s status %s",
+	return fmt.Sprintf("%s %s %s %s %s %s %s, %s %s/%s %s %s",
 		o.Exchange,
 		o.Type,
+		Translate("Order"),
 		o.Symbol,
 		o.Side,
-		util.FormatFloat(o.Price, 2),
+		Translate("price"),
+		formatQuoteAmount(o.Symbol, o.Price, 2),
+		Translate("quantity"),
 		util.FormatFloat(o.ExecutedQuantity, 2),
-		util.FormatFloat(o.Quantity, 4), o.Status)
+		util.FormatFloat(o.Quantity, 4),
+		Translate("status"),
+		o.Status)
 }
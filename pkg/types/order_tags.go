@@ -0,0 +1,88 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OrderTags is the free-form metadata attached to the submitted order, e.g., grid_level=7, reason=re-center
+// The tags are stored as a "key=value,key2=value2" string in the database,
+// the '%', ',' and '=' characters of the keys and the values are percent-encoded.
+type OrderTags map[string]string
+
+var orderTagEscaper = strings.NewReplacer("%", "%25", ",", "%2C", "=", "%3D")
+var orderTagUnescaper = strings.NewReplacer("%25", "%", "%2C", ",", "%3D", "=")
+
+func ParseOrderTags(s string) (OrderTags, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	tags := make(OrderTags)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid order tag %q", pair)
+		}
+
+		tags[orderTagUnescaper.Replace(kv[0])] = orderTagUnescaper.Replace(kv[1])
+	}
+
+	return tags, nil
+}
+
+// Merge returns a new tag map with the given tags merged, the given tags override the existing keys.
+func (t OrderTags) Merge(o OrderTags) OrderTags {
+	merged := make(OrderTags, len(t)+len(o))
+	for k, v := range t {
+		merged[k] = v
+	}
+	for k, v := range o {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (t OrderTags) String() string {
+	var keys []string
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, orderTagEscaper.Replace(k)+"="+orderTagEscaper.Replace(t[k]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (t OrderTags) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+func (t *OrderTags) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("order tags scan error, unsupported type %T", src)
+	}
+
+	tags, err := ParseOrderTags(s)
+	if err != nil {
+		return err
+	}
+
+	*t = tags
+	return nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderTags_String(t *testing.T) {
+	tags := OrderTags{
+		"grid_level": "7",
+		"reason":     "re-center, a=b",
+		"rate=":      "100%",
+	}
+
+	s := tags.String()
+	assert.Equal(t, "grid_level=7,rate%3D=100%25,reason=re-center%2C a%3Db", s)
+
+	parsed, err := ParseOrderTags(s)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tags, parsed)
+	}
+}
+
+func TestParseOrderTags(t *testing.T) {
+	tags, err := ParseOrderTags("")
+	assert.NoError(t, err)
+	assert.Nil(t, tags)
+
+	tags, err = ParseOrderTags("strategy=grid,grid_level=1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, OrderTags{"strategy": "grid", "grid_level": "1"}, tags)
+	}
+
+	_, err = ParseOrderTags("strategy")
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitOrder_ValidateTimeInForce(t *testing.T) {
+	order := SubmitOrder{Symbol: "BTCUSDT", Type: OrderTypeLimit, TimeInForce: TimeInForceIOC}
+	assert.NoError(t, order.ValidateTimeInForce())
+
+	order.TimeInForce = "GTD"
+	assert.Error(t, order.ValidateTimeInForce())
+
+	order.TimeInForce = ""
+	order.PostOnly = true
+	assert.NoError(t, order.ValidateTimeInForce())
+
+	// the post only order can not take the liquidity immediately
+	order.TimeInForce = TimeInForceFOK
+	assert.Error(t, order.ValidateTimeInForce())
+
+	order.TimeInForce = TimeInForceGTC
+	order.Type = OrderTypeMarket
+	assert.Error(t, order.ValidateTimeInForce())
+}
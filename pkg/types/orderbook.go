@@ -120,6 +120,13 @@ type OrderBook struct {
 	Bids   PriceVolumeSlice
 	Asks   PriceVolumeSlice
 
+	// FirstUpdateID and FinalUpdateID are the update ID range of the depth update, the snapshot only has the final update ID.
+	// PreviousUpdateID is the final update ID of the previous update if the exchange chains the updates by it.
+	// They are zero if the exchange doesn't sequence the book events.
+	FirstUpdateID    int64 `json:"firstUpdateID,omitempty"`
+	FinalUpdateID    int64 `json:"finalUpdateID,omitempty"`
+	PreviousUpdateID int64 `json:"previousUpdateID,omitempty"`
+
 	loadCallbacks       []func(book *OrderBook)
 	updateCallbacks     []func(book *OrderBook)
 	bidsChangeCallbacks []func(pvs PriceVolumeSlice)
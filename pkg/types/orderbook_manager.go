@@ -0,0 +1,224 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrStaleBookUpdate is returned when the update is older than the book, the update is skipped
+	ErrStaleBookUpdate = errors.New("stale book update")
+
+	// ErrBookOutOfSync is returned when the update is received before the book is re-synced by a snapshot
+	ErrBookOutOfSync = errors.New("order book is out of sync")
+)
+
+// BookResyncer is implemented by the streams that can reload the order book snapshot of a symbol,
+// the reloaded snapshot is emitted through the book snapshot callbacks.
+type BookResyncer interface {
+	ResyncBook(symbol string) error
+}
+
+// OrderBookStats are the consistency metrics of the order book maintained from the stream
+type OrderBookStats struct {
+	Symbol string `json:"symbol"`
+
+	// Synced is false before the first snapshot and from a sequence gap until the next snapshot
+	Synced bool `json:"synced"`
+
+	// LastUpdateID is the final update ID of the last applied snapshot or update, it's zero if the exchange doesn't sequence the book
+	LastUpdateID   int64     `json:"lastUpdateID"`
+	LastUpdateTime time.Time `json:"lastUpdateTime"`
+
+	Snapshots int64 `json:"snapshots"`
+	Updates   int64 `json:"updates"`
+
+	// StaleUpdates are the updates older than the book, they are skipped
+	StaleUpdates int64 `json:"staleUpdates"`
+
+	// DroppedUpdates are the updates received while the book is out of sync
+	DroppedUpdates int64 `json:"droppedUpdates"`
+
+	SequenceGaps int64 `json:"sequenceGaps"`
+
+	// CrossedBooks is the number of the updates that left the best bid at or above the best ask
+	CrossedBooks int64 `json:"crossedBooks"`
+
+	// Resyncs is the number of the snapshot reloads requested from the stream
+	Resyncs int64 `json:"resyncs"`
+}
+
+// OrderBookManager maintains the order book of a symbol from the book snapshots and the depth updates of the stream.
+// The update IDs of the depth updates are validated against the book, the book is marked as out of sync on a gap and a
+// new snapshot is requested if the stream supports it, so that the readers never see the book with the missing updates.
+//
+//go:generate callbackgen -type OrderBookManager
+type OrderBookManager struct {
+	Symbol string
+
+	mu    sync.Mutex
+	book  OrderBook
+	stats OrderBookStats
+
+	resyncer BookResyncer
+
+	outOfSyncCallbacks []func(err error)
+}
+
+func NewOrderBookManager(symbol string) *OrderBookManager {
+	return &OrderBookManager{
+		Symbol: symbol,
+		book:   OrderBook{Symbol: symbol},
+		stats:  OrderBookStats{Symbol: symbol},
+	}
+}
+
+// SetResyncer sets the stream used for reloading the snapshot on the sequence gaps
+func (m *OrderBookManager) SetResyncer(resyncer BookResyncer) {
+	m.mu.Lock()
+	m.resyncer = resyncer
+	m.mu.Unlock()
+}
+
+// Load replaces the book with the snapshot, the book is synced after the snapshot is loaded
+func (m *OrderBookManager) Load(snapshot OrderBook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.book.Reset()
+	m.book.update(snapshot)
+	m.book.FinalUpdateID = snapshot.FinalUpdateID
+
+	m.stats.Synced = true
+	m.stats.Snapshots++
+	m.stats.LastUpdateID = snapshot.FinalUpdateID
+	m.stats.LastUpdateTime = time.Now()
+}
+
+// Update applies the depth update to the book, an error is returned if the update is not applied
+func (m *OrderBookManager) Update(update OrderBook) error {
+	m.mu.Lock()
+
+	if !m.stats.Synced {
+		m.stats.DroppedUpdates++
+		m.mu.Unlock()
+		return ErrBookOutOfSync
+	}
+
+	// the exchanges without the update IDs are not validated
+	if update.FinalUpdateID > 0 {
+		if update.FinalUpdateID <= m.stats.LastUpdateID {
+			m.stats.StaleUpdates++
+			m.mu.Unlock()
+			return ErrStaleBookUpdate
+		}
+
+		if isBookSequenceGap(m.stats.LastUpdateID, update) {
+			m.stats.SequenceGaps++
+			err := fmt.Errorf("%s book sequence gap: last update id %d, update id range %d ~ %d",
+				m.Symbol, m.stats.LastUpdateID, update.FirstUpdateID, update.FinalUpdateID)
+			m.outOfSync(err)
+			return err
+		}
+	}
+
+	m.book.update(update)
+	m.stats.Updates++
+	m.stats.LastUpdateTime = time.Now()
+	if update.FinalUpdateID > 0 {
+		m.book.FinalUpdateID = update.FinalUpdateID
+		m.stats.LastUpdateID = update.FinalUpdateID
+	}
+
+	if m.isCrossed() {
+		m.stats.CrossedBooks++
+
+		// the crossed book can only be repaired by a snapshot of the sequenced book, the other books are
+		// corrected by the following updates
+		if update.FinalUpdateID > 0 {
+			err := fmt.Errorf("%s book is crossed after the update id %d", m.Symbol, update.FinalUpdateID)
+			m.outOfSync(err)
+			return err
+		}
+	}
+
+	m.mu.Unlock()
+	return nil
+}
+
+// outOfSync marks the book as out of sync and requests a new snapshot, the caller should hold the lock and it's released here
+func (m *OrderBookManager) outOfSync(err error) {
+	m.stats.Synced = false
+
+	resyncer := m.resyncer
+	if resyncer != nil {
+		m.stats.Resyncs++
+	}
+
+	m.mu.Unlock()
+
+	log.WithError(err).Warnf("%s order book is out of sync", m.Symbol)
+
+	if resyncer != nil {
+		if err := resyncer.ResyncBook(m.Symbol); err != nil {
+			log.WithError(err).Errorf("can not resync the %s order book", m.Symbol)
+		}
+	}
+
+	m.EmitOutOfSync(err)
+}
+
+// isCrossed checks if the best bid is at or above the best ask, the caller should hold the lock
+func (m *OrderBookManager) isCrossed() bool {
+	bid, hasBid := m.book.BestBid()
+	ask, hasAsk := m.book.BestAsk()
+	return hasBid && hasAsk && bid.Price >= ask.Price
+}
+
+// isBookSequenceGap checks if the update doesn't continue the book of the last update ID. The update can overlap the
+// book (the first update after the snapshot usually does), and the updates chained by the previous update ID (e.g.,
+// the futures depth of binance) don't have the continuous update ID ranges.
+func isBookSequenceGap(lastUpdateID int64, update OrderBook) bool {
+	if update.FirstUpdateID <= lastUpdateID+1 {
+		return false
+	}
+
+	return update.PreviousUpdateID == 0 || update.PreviousUpdateID != lastUpdateID
+}
+
+// Get returns a copy of the book, ok is false if the book is out of sync
+func (m *OrderBookManager) Get() (book OrderBook, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.book.Copy(), m.stats.Synced
+}
+
+// Depth returns the aggregated depth of the top levels of the book, ok is false if the book is out of sync or either side is empty
+func (m *OrderBookManager) Depth(levels int) (BookDepth, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.stats.Synced {
+		return BookDepth{}, false
+	}
+
+	return m.book.Depth(levels)
+}
+
+// IsSynced returns true if the book is loaded from a snapshot and no gap is found after it
+func (m *OrderBookManager) IsSynced() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats.Synced
+}
+
+// Stats returns the consistency metrics of the book
+func (m *OrderBookManager) Stats() OrderBookStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type testBookResyncer struct {
+	symbols []string
+}
+
+func (r *testBookResyncer) ResyncBook(symbol string) error {
+	r.symbols = append(r.symbols, symbol)
+	return nil
+}
+
+func testBookUpdate(firstUpdateID, finalUpdateID int64, bids, asks map[float64]float64) OrderBook {
+	book := testBook(bids, asks)
+	book.FirstUpdateID = firstUpdateID
+	book.FinalUpdateID = finalUpdateID
+	return book
+}
+
+func TestOrderBookManager_Update(t *testing.T) {
+	resyncer := &testBookResyncer{}
+	manager := NewOrderBookManager("BTCUSDT")
+	manager.SetResyncer(resyncer)
+
+	// the updates before the snapshot are dropped
+	assert.Equal(t, ErrBookOutOfSync, manager.Update(testBookUpdate(1, 5, map[float64]float64{100: 1}, nil)))
+
+	_, ok := manager.Get()
+	assert.False(t, ok)
+
+	snapshot := testBook(map[float64]float64{100: 1, 99: 2}, map[float64]float64{101: 1, 102: 3})
+	snapshot.FinalUpdateID = 10
+	manager.Load(snapshot)
+
+	// stale
+	assert.Equal(t, ErrStaleBookUpdate, manager.Update(testBookUpdate(8, 10, map[float64]float64{100: 5}, nil)))
+
+	// the first update overlaps the snapshot
+	assert.NoError(t, manager.Update(testBookUpdate(9, 12, map[float64]float64{100: 2}, nil)))
+	assert.NoError(t, manager.Update(testBookUpdate(13, 15, nil, map[float64]float64{101: 0})))
+
+	book, ok := manager.Get()
+	if assert.True(t, ok) {
+		assert.Equal(t, fixedpoint.NewFromFloat(2), book.Bids[0].Volume)
+		assert.Equal(t, fixedpoint.NewFromFloat(102), book.Asks[0].Price)
+		assert.Equal(t, int64(15), book.FinalUpdateID)
+	}
+
+	// update 16 ~ 17 is missing
+	var outOfSyncErr error
+	manager.OnOutOfSync(func(err error) {
+		outOfSyncErr = err
+	})
+
+	assert.Error(t, manager.Update(testBookUpdate(18, 20, map[float64]float64{100: 3}, nil)))
+	assert.Error(t, outOfSyncErr)
+	assert.Equal(t, []string{"BTCUSDT"}, resyncer.symbols)
+	assert.False(t, manager.IsSynced())
+
+	_, ok = manager.Depth(5)
+	assert.False(t, ok)
+
+	assert.Equal(t, ErrBookOutOfSync, manager.Update(testBookUpdate(21, 22, map[float64]float64{100: 4}, nil)))
+
+	snapshot.FinalUpdateID = 30
+	manager.Load(snapshot)
+	assert.True(t, manager.IsSynced())
+
+	stats := manager.Stats()
+	assert.Equal(t, int64(2), stats.Snapshots)
+	assert.Equal(t, int64(2), stats.Updates)
+	assert.Equal(t, int64(1), stats.StaleUpdates)
+	assert.Equal(t, int64(2), stats.DroppedUpdates)
+	assert.Equal(t, int64(1), stats.SequenceGaps)
+	assert.Equal(t, int64(1), stats.Resyncs)
+	assert.Equal(t, int64(30), stats.LastUpdateID)
+}
+
+func TestOrderBookManager_PreviousUpdateID(t *testing.T) {
+	manager := NewOrderBookManager("BTCUSDT")
+
+	snapshot := testBook(map[float64]float64{100: 1}, map[float64]float64{101: 1})
+	snapshot.FinalUpdateID = 10
+	manager.Load(snapshot)
+
+	// the futures updates are chained by the previous update id instead of the continuous ranges
+	update := testBookUpdate(8, 12, map[float64]float64{100: 2}, nil)
+	update.PreviousUpdateID = 7
+	assert.NoError(t, manager.Update(update))
+
+	update = testBookUpdate(20, 25, map[float64]float64{100: 3}, nil)
+	update.PreviousUpdateID = 12
+	assert.NoError(t, manager.Update(update))
+
+	update = testBookUpdate(30, 35, map[float64]float64{100: 4}, nil)
+	update.PreviousUpdateID = 27
+	assert.Error(t, manager.Update(update))
+	assert.False(t, manager.IsSynced())
+}
+
+func TestOrderBookManager_CrossedBook(t *testing.T) {
+	manager := NewOrderBookManager("BTCUSDT")
+
+	snapshot := testBook(map[float64]float64{100: 1}, map[float64]float64{101: 1})
+	manager.Load(snapshot)
+
+	// the book without the update ids is kept in sync, the crossed levels are expected to be removed by the next updates
+	assert.NoError(t, manager.Update(testBook(map[float64]float64{101.5: 1}, nil)))
+	assert.True(t, manager.IsSynced())
+
+	snapshot.FinalUpdateID = 10
+	manager.Load(snapshot)
+	assert.Error(t, manager.Update(testBookUpdate(11, 12, map[float64]float64{101.5: 1}, nil)))
+	assert.False(t, manager.IsSynced())
+	assert.Equal(t, int64(2), manager.Stats().CrossedBooks)
+}
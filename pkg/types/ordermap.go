@@ -33,6 +33,21 @@ func (m OrderMap) Exists(orderID uint64) bool {
 	return ok
 }
 
+// FindByClientOrderID finds the order by the client order ID, which is kept across the reconnects
+func (m OrderMap) FindByClientOrderID(clientOrderID string) (order Order, ok bool) {
+	if len(clientOrderID) == 0 {
+		return order, false
+	}
+
+	for _, o := range m {
+		if o.ClientOrderID == clientOrderID {
+			return o, true
+		}
+	}
+
+	return order, false
+}
+
 func (m OrderMap) FindByStatus(status OrderStatus) (orders OrderSlice) {
 	for _, o := range m {
 		if o.Status == status {
@@ -128,6 +143,13 @@ func (m *SyncOrderMap) IDs() []uint64 {
 	return m.orders.IDs()
 }
 
+func (m *SyncOrderMap) FindByClientOrderID(clientOrderID string) (Order, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	return m.orders.FindByClientOrderID(clientOrderID)
+}
+
 func (m *SyncOrderMap) FindByStatus(status OrderStatus) OrderSlice {
 	m.RLock()
 	defer m.RUnlock()
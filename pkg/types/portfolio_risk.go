@@ -0,0 +1,177 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRiskShocks are the price moves of the default risk scenarios
+var DefaultRiskShocks = []float64{-0.1, -0.05, 0.05, 0.1}
+
+// DefaultMaintenanceMarginRate is the maintenance margin rate used for the positions when the rate is not configured
+const DefaultMaintenanceMarginRate = 0.01
+
+// PortfolioPosition is the futures position held by a session
+type PortfolioPosition struct {
+	Session string `json:"session"`
+	FuturesPosition
+
+	// Notional is the signed USD value of the position with the mark price, it's negative for the short position
+	Notional float64 `json:"notional"`
+}
+
+// SignedQuantity returns the position quantity that is negative for the short position in both the one-way and the hedge mode
+func (p FuturesPosition) SignedQuantity() float64 {
+	q := p.Quantity.Float64()
+	if p.IsShort() && q > 0 {
+		return -q
+	}
+
+	return q
+}
+
+// RiskScenario is the projected account state after all the prices move by the shock ratio
+type RiskScenario struct {
+	Shock float64 `json:"shock"`
+
+	UnrealizedProfit  float64 `json:"unrealizedProfit"`
+	Equity            float64 `json:"equity"`
+	MaintenanceMargin float64 `json:"maintenanceMargin"`
+
+	// MarginRatio is the maintenance margin divided by the equity, the positions are liquidated when it reaches 1
+	MarginRatio float64 `json:"marginRatio"`
+	Liquidated  bool    `json:"liquidated"`
+}
+
+// PortfolioRisk is the risk view of the futures positions and the collateral across the sessions
+type PortfolioRisk struct {
+	Time      time.Time           `json:"time"`
+	Positions []PortfolioPosition `json:"positions"`
+
+	// Collateral is the collateral assets merged across the sessions by currency
+	Collateral Collateral `json:"collateral"`
+
+	GrossExposure float64 `json:"grossExposure"`
+	NetExposure   float64 `json:"netExposure"`
+
+	// Current is the account state without the price shock
+	Current   RiskScenario   `json:"current"`
+	Scenarios []RiskScenario `json:"scenarios"`
+}
+
+// MergeCollaterals sums up the collateral valuations of multiple accounts by currency
+func MergeCollaterals(collaterals ...Collateral) Collateral {
+	merged := Collateral{Assets: make(map[string]CollateralAsset)}
+	unpriced := make(map[string]struct{})
+
+	for _, c := range collaterals {
+		for currency, asset := range c.Assets {
+			m := merged.Assets[currency]
+			m.Currency = currency
+			m.IndexPrice = asset.IndexPrice
+			m.Total += asset.Total
+			m.Borrowed += asset.Borrowed
+			m.Value += asset.Value
+			m.Debt += asset.Debt
+			merged.Assets[currency] = m
+		}
+
+		merged.TotalValue += c.TotalValue
+		merged.TotalDebt += c.TotalDebt
+		merged.AvailableMargin += c.AvailableMargin
+
+		for _, currency := range c.Unpriced {
+			unpriced[currency] = struct{}{}
+		}
+	}
+
+	for currency := range unpriced {
+		merged.Unpriced = append(merged.Unpriced, currency)
+	}
+	sort.Strings(merged.Unpriced)
+
+	return merged
+}
+
+// NewPortfolioRisk builds the risk view and projects the margin ratios of the shock scenarios.
+// The shock moves the mark prices of all the positions and the prices of the non-USD collateral assets together.
+func NewPortfolioRisk(positions []PortfolioPosition, collateral Collateral, maintenanceMarginRate float64, shocks []float64) PortfolioRisk {
+	if maintenanceMarginRate <= 0 {
+		maintenanceMarginRate = DefaultMaintenanceMarginRate
+	}
+
+	if len(shocks) == 0 {
+		shocks = DefaultRiskShocks
+	}
+
+	risk := PortfolioRisk{
+		Time:       time.Now(),
+		Positions:  positions,
+		Collateral: collateral,
+	}
+
+	for i, p := range risk.Positions {
+		risk.Positions[i].Notional = p.SignedQuantity() * p.MarkPrice.Float64()
+		risk.GrossExposure += math.Abs(risk.Positions[i].Notional)
+		risk.NetExposure += risk.Positions[i].Notional
+	}
+
+	risk.Current = risk.scenario(0, maintenanceMarginRate)
+	for _, shock := range shocks {
+		risk.Scenarios = append(risk.Scenarios, risk.scenario(shock, maintenanceMarginRate))
+	}
+
+	return risk
+}
+
+func (r PortfolioRisk) scenario(shock, maintenanceMarginRate float64) RiskScenario {
+	s := RiskScenario{Shock: shock}
+
+	for _, p := range r.Positions {
+		s.UnrealizedProfit += p.UnrealizedProfit.Float64() + p.Notional*shock
+		s.MaintenanceMargin += math.Abs(p.Notional) * (1 + shock) * maintenanceMarginRate
+	}
+
+	var collateralValue, debt float64
+	for currency, asset := range r.Collateral.Assets {
+		if isUSDCurrency(currency) {
+			collateralValue += asset.Value
+			debt += asset.Debt
+		} else {
+			collateralValue += asset.Value * (1 + shock)
+			debt += asset.Debt * (1 + shock)
+		}
+	}
+
+	s.Equity = collateralValue - debt + s.UnrealizedProfit
+
+	switch {
+	case s.MaintenanceMargin == 0:
+		s.MarginRatio = 0
+	case s.Equity <= 0:
+		s.MarginRatio = 1
+	default:
+		s.MarginRatio = s.MaintenanceMargin / s.Equity
+	}
+
+	s.Liquidated = s.MaintenanceMargin > 0 && s.MarginRatio >= 1
+	return s
+}
+
+func (r PortfolioRisk) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("portfolio risk: %d positions, gross exposure %.2f USD, net exposure %.2f USD, equity %.2f USD, margin ratio %.2f%%",
+		len(r.Positions), r.GrossExposure, r.NetExposure, r.Current.Equity, r.Current.MarginRatio*100.0))
+
+	for _, s := range r.Scenarios {
+		sb.WriteString(fmt.Sprintf("\n%+.0f%%: equity %.2f USD, margin ratio %.2f%%", s.Shock*100.0, s.Equity, s.MarginRatio*100.0))
+		if s.Liquidated {
+			sb.WriteString(" (liquidated)")
+		}
+	}
+
+	return sb.String()
+}
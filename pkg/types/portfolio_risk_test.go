@@ -0,0 +1,81 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestNewPortfolioRisk(t *testing.T) {
+	prices := map[string]float64{"BTCUSDT": 30000.0}
+	collateral := MergeCollaterals(
+		BalanceMap{"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(10000.0)}}.Collateral(prices, nil),
+		BalanceMap{"BTC": {Currency: "BTC", Available: fixedpoint.NewFromFloat(1.0)}}.Collateral(prices, nil),
+	)
+	assert.InDelta(t, 40000.0, collateral.TotalValue, 1e-6)
+
+	positions := []PortfolioPosition{
+		{
+			Session: "binance-futures",
+			FuturesPosition: FuturesPosition{
+				Symbol:       "BTCUSDT",
+				PositionSide: PositionSideBoth,
+				Quantity:     fixedpoint.NewFromFloat(1.0),
+				MarkPrice:    fixedpoint.NewFromFloat(30000.0),
+			},
+		},
+		{
+			Session: "ftx-futures",
+			FuturesPosition: FuturesPosition{
+				Symbol:       "ETHUSDT",
+				PositionSide: PositionSideShort,
+				Quantity:     fixedpoint.NewFromFloat(10.0),
+				MarkPrice:    fixedpoint.NewFromFloat(1000.0),
+			},
+		},
+	}
+
+	risk := NewPortfolioRisk(positions, collateral, 0.01, []float64{-0.1, 0.1})
+	assert.InDelta(t, 40000.0, risk.GrossExposure, 1e-6)
+	assert.InDelta(t, 20000.0, risk.NetExposure, 1e-6)
+	assert.InDelta(t, -10000.0, risk.Positions[1].Notional, 1e-6)
+
+	assert.InDelta(t, 40000.0, risk.Current.Equity, 1e-6)
+	assert.InDelta(t, 0.01, risk.Current.MarginRatio, 1e-9)
+
+	if assert.Len(t, risk.Scenarios, 2) {
+		down := risk.Scenarios[0]
+		assert.InDelta(t, -2000.0, down.UnrealizedProfit, 1e-6)
+		assert.InDelta(t, 35000.0, down.Equity, 1e-6)
+		assert.InDelta(t, 360.0/35000.0, down.MarginRatio, 1e-9)
+		assert.False(t, down.Liquidated)
+
+		up := risk.Scenarios[1]
+		assert.InDelta(t, 2000.0, up.UnrealizedProfit, 1e-6)
+		assert.InDelta(t, 45000.0, up.Equity, 1e-6)
+		assert.InDelta(t, 440.0/45000.0, up.MarginRatio, 1e-9)
+	}
+}
+
+func TestNewPortfolioRisk_Liquidated(t *testing.T) {
+	collateral := BalanceMap{"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(1000.0)}}.Collateral(nil, nil)
+	positions := []PortfolioPosition{
+		{
+			Session: "binance-futures",
+			FuturesPosition: FuturesPosition{
+				Symbol:       "BTCUSDT",
+				PositionSide: PositionSideBoth,
+				Quantity:     fixedpoint.NewFromFloat(1.0),
+				MarkPrice:    fixedpoint.NewFromFloat(30000.0),
+			},
+		},
+	}
+
+	risk := NewPortfolioRisk(positions, collateral, 0, nil)
+	assert.Len(t, risk.Scenarios, len(DefaultRiskShocks))
+	assert.False(t, risk.Current.Liquidated)
+	assert.True(t, risk.Scenarios[0].Liquidated)
+	assert.Equal(t, 1.0, risk.Scenarios[0].MarginRatio)
+}
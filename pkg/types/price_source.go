@@ -0,0 +1,54 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// PriceSource defines which price of the kline is used as the input of the indicators
+type PriceSource string
+
+const (
+	// PriceSourceClose uses the close price, this is the default price source
+	PriceSourceClose = PriceSource("close")
+
+	// PriceSourceHL2 uses the median price (high + low) / 2
+	PriceSourceHL2 = PriceSource("hl2")
+
+	// PriceSourceHLC3 uses the typical price (high + low + close) / 3
+	PriceSourceHLC3 = PriceSource("hlc3")
+
+	// PriceSourceVWAP uses the volume weighted average price of the kline (quote volume / volume)
+	PriceSourceVWAP = PriceSource("vwap")
+)
+
+var SupportedPriceSources = map[PriceSource]struct{}{
+	PriceSourceClose: {},
+	PriceSourceHL2:   {},
+	PriceSourceHLC3:  {},
+	PriceSourceVWAP:  {},
+}
+
+func (s *PriceSource) UnmarshalJSON(b []byte) error {
+	var a string
+	if err := json.Unmarshal(b, &a); err != nil {
+		return err
+	}
+
+	source := PriceSource(strings.ToLower(a))
+	if _, ok := SupportedPriceSources[source]; source != "" && !ok {
+		return fmt.Errorf("unsupported price source: %q", a)
+	}
+
+	*s = source
+	return nil
+}
+
+func (s PriceSource) String() string {
+	if s == "" {
+		return string(PriceSourceClose)
+	}
+
+	return string(s)
+}
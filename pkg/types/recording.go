@@ -0,0 +1,155 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type RecordEventType string
+
+const (
+	// RecordEventKLine is the closed kline of the market data stream
+	RecordEventKLine = RecordEventType("kline")
+
+	// RecordEventBook is the order book snapshot of the market data stream
+	RecordEventBook = RecordEventType("book")
+
+	// RecordEventBookDelta is the changed price levels since the previous book event of the symbol,
+	// the removed price levels are recorded with zero volume
+	RecordEventBookDelta = RecordEventType("bookDelta")
+
+	// RecordEventTrade is the private trade (fill) of the user data stream
+	RecordEventTrade = RecordEventType("trade")
+)
+
+// RecordedEvent is a stream event captured by the stream recorder, each event is written as one JSON line
+type RecordedEvent struct {
+	// Time is the local time when the event is received
+	Time time.Time `json:"time"`
+
+	Session  string          `json:"session"`
+	Exchange string          `json:"exchange"`
+	Symbol   string          `json:"symbol"`
+	Type     RecordEventType `json:"type"`
+
+	KLine *KLine     `json:"kline,omitempty"`
+	Book  *OrderBook `json:"book,omitempty"`
+	Trade *Trade     `json:"trade,omitempty"`
+}
+
+// EventTime returns the exchange time of the event, the closed kline is available at its end time and the trade at its trade time,
+// the local receive time is used for the book snapshots since the book events don't carry the exchange time.
+func (e RecordedEvent) EventTime() time.Time {
+	switch {
+	case e.KLine != nil && !e.KLine.EndTime.IsZero():
+		return e.KLine.EndTime
+	case e.Trade != nil && !e.Trade.Time.IsZero():
+		return e.Trade.Time
+	}
+
+	return e.Time
+}
+
+// ReadRecordedEvents reads the JSON line events written by the stream recorder
+func ReadRecordedEvents(r io.Reader) (events []RecordedEvent, err error) {
+	decoder := json.NewDecoder(r)
+	for {
+		var event RecordedEvent
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return events, nil
+			}
+
+			return events, err
+		}
+
+		events = append(events, event)
+	}
+}
+
+// WriteRecordedEvent writes the event as one JSON line
+func WriteRecordedEvent(w io.Writer, event RecordedEvent) error {
+	out, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// DiffOrderBook returns the changed price levels from the previous book to the current book,
+// the removed price levels are included with zero volume, so that the diff can be applied by OrderBook.Update.
+func DiffOrderBook(prev, curr OrderBook) OrderBook {
+	return OrderBook{
+		Symbol: curr.Symbol,
+		Bids:   diffPriceVolumes(prev.Bids, curr.Bids),
+		Asks:   diffPriceVolumes(prev.Asks, curr.Asks),
+	}
+}
+
+func diffPriceVolumes(prev, curr PriceVolumeSlice) (diff PriceVolumeSlice) {
+	var volumes = make(map[fixedpoint.Value]fixedpoint.Value, len(prev))
+	for _, pv := range prev {
+		volumes[pv.Price] = pv.Volume
+	}
+
+	for _, pv := range curr {
+		if volume, ok := volumes[pv.Price]; !ok || volume != pv.Volume {
+			diff = append(diff, pv)
+		}
+
+		delete(volumes, pv.Price)
+	}
+
+	for _, pv := range prev {
+		if _, removed := volumes[pv.Price]; removed {
+			diff = append(diff, PriceVolume{Price: pv.Price})
+		}
+	}
+
+	return diff
+}
+
+// ExpandBookDeltas converts the book delta events into the book snapshot events by applying the deltas to the book of the
+// previous snapshot, the deltas without the previous snapshot are dropped. The events received before the since time
+// are only used for building the books and are not returned.
+func ExpandBookDeltas(events []RecordedEvent, since time.Time) (expanded []RecordedEvent) {
+	var books = make(map[string]*OrderBook)
+	for _, event := range events {
+		if event.Book != nil && (event.Type == RecordEventBook || event.Type == RecordEventBookDelta) {
+			key := event.Session + ":" + event.Symbol
+			book, ok := books[key]
+			switch {
+			case event.Type == RecordEventBook:
+				if !ok {
+					book = &OrderBook{Symbol: event.Symbol}
+					books[key] = book
+				}
+
+				book.Load(*event.Book)
+
+			case ok:
+				book.Update(*event.Book)
+
+			default:
+				continue
+			}
+
+			snapshot := book.Copy()
+			event.Type = RecordEventBook
+			event.Book = &snapshot
+		}
+
+		if event.Time.Before(since) {
+			continue
+		}
+
+		expanded = append(expanded, event)
+	}
+
+	return expanded
+}
@@ -0,0 +1,234 @@
+package types
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// RecordingIndexEntry is the entry of the recording index, it points to the compressed block that starts at the time
+type RecordingIndexEntry struct {
+	Time   time.Time `json:"time"`
+	Offset int64     `json:"offset"`
+}
+
+// RecordingIndexFile returns the index file of the compressed recording file
+func RecordingIndexFile(filename string) string {
+	return filename + ".idx"
+}
+
+// RecordingWriter writes the recorded events into the gzip compressed JSON lines file.
+// The events are compressed in blocks by the block interval, each block is an independent gzip member,
+// so that the file is still readable by gzip, and the offsets of the blocks are written into the index file for seeking.
+// Each block starts with the key frame events, e.g., the full book snapshots, so that the reader can start from any block.
+type RecordingWriter struct {
+	BlockInterval time.Duration
+
+	keyFrames func(t time.Time) []RecordedEvent
+
+	file  *os.File
+	index *os.File
+
+	gz             *gzip.Writer
+	blockStartTime time.Time
+}
+
+// NewRecordingWriter opens the compressed recording file and its index file, the new blocks are appended if the file exists.
+// keyFrames returns the events written at the beginning of each block, it can be nil.
+func NewRecordingWriter(filename string, blockInterval time.Duration, keyFrames func(t time.Time) []RecordedEvent) (*RecordingWriter, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := os.OpenFile(RecordingIndexFile(filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &RecordingWriter{
+		BlockInterval: blockInterval,
+		keyFrames:     keyFrames,
+		file:          file,
+		index:         index,
+	}, nil
+}
+
+func (w *RecordingWriter) Name() string {
+	return w.file.Name()
+}
+
+// StartsBlock returns true if the event of the time will be written into a new block
+func (w *RecordingWriter) StartsBlock(t time.Time) bool {
+	return w.gz == nil || t.Sub(w.blockStartTime) >= w.BlockInterval
+}
+
+func (w *RecordingWriter) Write(event RecordedEvent) error {
+	if w.StartsBlock(event.Time) {
+		if err := w.startBlock(event.Time); err != nil {
+			return err
+		}
+	}
+
+	return WriteRecordedEvent(w.gz, event)
+}
+
+func (w *RecordingWriter) startBlock(t time.Time) error {
+	if err := w.endBlock(); err != nil {
+		return err
+	}
+
+	// the file is opened in the append mode, the block is written at the end of the file
+	offset, err := w.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(RecordingIndexEntry{Time: t, Offset: offset})
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.index.Write(append(out, '\n')); err != nil {
+		return err
+	}
+
+	w.gz = gzip.NewWriter(w.file)
+	w.blockStartTime = t
+
+	if w.keyFrames == nil {
+		return nil
+	}
+
+	for _, event := range w.keyFrames(t) {
+		if err := WriteRecordedEvent(w.gz, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *RecordingWriter) endBlock() error {
+	if w.gz == nil {
+		return nil
+	}
+
+	err := w.gz.Close()
+	w.gz = nil
+	return err
+}
+
+// Close writes the current block and closes the files
+func (w *RecordingWriter) Close() error {
+	if err := w.endBlock(); err != nil {
+		return err
+	}
+
+	if err := w.index.Close(); err != nil {
+		return err
+	}
+
+	return w.file.Close()
+}
+
+// ReadRecordingIndex reads the index entries of the compressed recording
+func ReadRecordingIndex(filename string) (entries []RecordingIndexEntry, err error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	for {
+		var entry RecordingIndexEntry
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				return entries, nil
+			}
+
+			return entries, err
+		}
+
+		entries = append(entries, entry)
+	}
+}
+
+// ReadRecordingFile reads the events received since the given time from the recording file, the zero time reads all the events.
+// Both the JSON lines file and the compressed recording (.gz) are supported, the index of the compressed recording is used
+// for seeking to the block of the since time. The book deltas are expanded into the book snapshots.
+func ReadRecordingFile(filename string, since time.Time) ([]RecordedEvent, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var events []RecordedEvent
+	if strings.HasSuffix(filename, ".gz") {
+		events, err = readCompressedRecording(f, RecordingIndexFile(filename), since)
+	} else {
+		events, err = ReadRecordedEvents(f)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ExpandBookDeltas(events, since), nil
+}
+
+func readCompressedRecording(f *os.File, indexFile string, since time.Time) ([]RecordedEvent, error) {
+	entries, err := ReadRecordingIndex(indexFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		// without the index, all the blocks are read as one multi-member gzip stream
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return ReadRecordedEvents(gz)
+	}
+
+	// start from the last block that begins at or before the since time
+	var start = 0
+	for i, entry := range entries {
+		if entry.Time.After(since) {
+			break
+		}
+
+		start = i
+	}
+
+	var events []RecordedEvent
+	for _, entry := range entries[start:] {
+		if _, err := f.Seek(entry.Offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		// the block can be damaged if the recorder was not closed properly, e.g., truncated by the crash,
+		// since the blocks are independent, the events read from the damaged block are kept and the following blocks are still read.
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			continue
+		}
+
+		gz.Multistream(false)
+
+		blockEvents, _ := ReadRecordedEvents(gz)
+		events = append(events, blockEvents...)
+	}
+
+	return events, nil
+}
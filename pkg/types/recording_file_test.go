@@ -0,0 +1,123 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func testBook(bids, asks map[float64]float64) OrderBook {
+	book := OrderBook{Symbol: "BTCUSDT"}
+	for price, volume := range bids {
+		book.Bids = book.Bids.Upsert(PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}, true)
+	}
+
+	for price, volume := range asks {
+		book.Asks = book.Asks.Upsert(PriceVolume{Price: fixedpoint.NewFromFloat(price), Volume: fixedpoint.NewFromFloat(volume)}, false)
+	}
+
+	return book
+}
+
+func TestDiffOrderBook(t *testing.T) {
+	prev := testBook(map[float64]float64{100: 1, 99: 2}, map[float64]float64{101: 1, 102: 3})
+	curr := testBook(map[float64]float64{100: 1, 99: 5, 98: 1}, map[float64]float64{102: 3})
+
+	delta := DiffOrderBook(prev, curr)
+	assert.Len(t, delta.Bids, 2)
+	assert.Equal(t, PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(101)}}, delta.Asks)
+
+	book := prev.Copy()
+	book.Update(delta)
+	assert.Equal(t, curr.Bids, book.Bids)
+	assert.Equal(t, curr.Asks, book.Asks)
+}
+
+func TestRecordingWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl.gz")
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var lastBook OrderBook
+	w, err := NewRecordingWriter(filename, time.Minute, func(t time.Time) []RecordedEvent {
+		book := lastBook
+		return []RecordedEvent{{Time: t, Symbol: "BTCUSDT", Type: RecordEventBook, Book: &book}}
+	})
+	assert.NoError(t, err)
+
+	// one book update per 20 seconds, 3 blocks in total
+	var books []OrderBook
+	for i := 0; i < 9; i++ {
+		book := testBook(map[float64]float64{100: float64(i + 1)}, map[float64]float64{101: 1})
+		books = append(books, book)
+
+		event := RecordedEvent{Time: startTime.Add(time.Duration(i) * 20 * time.Second), Symbol: "BTCUSDT", Type: RecordEventBook, Book: &book}
+		if i > 0 {
+			delta := DiffOrderBook(lastBook, book)
+			event.Type = RecordEventBookDelta
+			event.Book = &delta
+		}
+
+		lastBook = book
+		assert.NoError(t, w.Write(event))
+	}
+
+	assert.NoError(t, w.Close())
+
+	entries, err := ReadRecordingIndex(RecordingIndexFile(filename))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	events, err := ReadRecordingFile(filename, time.Time{})
+	assert.NoError(t, err)
+
+	// 9 book events and 3 key frames
+	if assert.Len(t, events, 12) {
+		for _, event := range events {
+			assert.Equal(t, RecordEventBook, event.Type)
+		}
+
+		assert.Equal(t, books[8].Bids, events[11].Book.Bids)
+	}
+
+	// seek to the middle of the second block, the book is rebuilt from the key frame of the block
+	since := startTime.Add(80 * time.Second)
+	events, err = ReadRecordingFile(filename, since)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, events) {
+		assert.False(t, events[0].Time.Before(since))
+		assert.Equal(t, books[4].Bids, events[0].Book.Bids)
+		assert.Equal(t, books[8].Bids, events[len(events)-1].Book.Bids)
+	}
+}
+
+func TestRecordingWriter_Truncated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.jsonl.gz")
+	startTime := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	w, err := NewRecordingWriter(filename, time.Minute, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(RecordedEvent{Time: startTime, Symbol: "BTCUSDT", Type: RecordEventKLine, KLine: &KLine{Close: 1}}))
+	assert.NoError(t, w.Close())
+
+	// the block of the crashed recorder is never closed
+	info, err := os.Stat(filename)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(filename, info.Size()-4))
+
+	// the recorder is restarted
+	w, err = NewRecordingWriter(filename, time.Minute, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(RecordedEvent{Time: startTime.Add(time.Hour), Symbol: "BTCUSDT", Type: RecordEventKLine, KLine: &KLine{Close: 2}}))
+	assert.NoError(t, w.Close())
+
+	events, err := ReadRecordingFile(filename, time.Time{})
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, events) {
+		assert.Equal(t, 2.0, events[len(events)-1].KLine.Close)
+	}
+}
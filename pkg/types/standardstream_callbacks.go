@@ -14,6 +14,16 @@ func (stream *StandardStream) EmitConnect() {
 	}
 }
 
+func (stream *StandardStream) OnDisconnect(cb func()) {
+	stream.disconnectCallbacks = append(stream.disconnectCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitDisconnect() {
+	for _, cb := range stream.disconnectCallbacks {
+		cb()
+	}
+}
+
 func (stream *StandardStream) OnTradeUpdate(cb func(trade Trade)) {
 	stream.tradeUpdateCallbacks = append(stream.tradeUpdateCallbacks, cb)
 }
@@ -94,9 +104,51 @@ func (stream *StandardStream) EmitBookSnapshot(book OrderBook) {
 	}
 }
 
+func (stream *StandardStream) OnMarketTrade(cb func(trade Trade)) {
+	stream.marketTradeCallbacks = append(stream.marketTradeCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitMarketTrade(trade Trade) {
+	for _, cb := range stream.marketTradeCallbacks {
+		cb(trade)
+	}
+}
+
+func (stream *StandardStream) OnFundingRate(cb func(rate FundingRate)) {
+	stream.fundingRateCallbacks = append(stream.fundingRateCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitFundingRate(rate FundingRate) {
+	for _, cb := range stream.fundingRateCallbacks {
+		cb(rate)
+	}
+}
+
+func (stream *StandardStream) OnPositionUpdate(cb func(position FuturesPosition)) {
+	stream.positionUpdateCallbacks = append(stream.positionUpdateCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitPositionUpdate(position FuturesPosition) {
+	for _, cb := range stream.positionUpdateCallbacks {
+		cb(position)
+	}
+}
+
+func (stream *StandardStream) OnMarginCall(cb func(marginCall MarginCall)) {
+	stream.marginCallCallbacks = append(stream.marginCallCallbacks, cb)
+}
+
+func (stream *StandardStream) EmitMarginCall(marginCall MarginCall) {
+	for _, cb := range stream.marginCallCallbacks {
+		cb(marginCall)
+	}
+}
+
 type StandardStreamEventHub interface {
 	OnConnect(cb func())
 
+	OnDisconnect(cb func())
+
 	OnTradeUpdate(cb func(trade Trade))
 
 	OnOrderUpdate(cb func(order Order))
@@ -112,4 +164,12 @@ type StandardStreamEventHub interface {
 	OnBookUpdate(cb func(book OrderBook))
 
 	OnBookSnapshot(cb func(book OrderBook))
+
+	OnMarketTrade(cb func(trade Trade))
+
+	OnFundingRate(cb func(rate FundingRate))
+
+	OnPositionUpdate(cb func(position FuturesPosition))
+
+	OnMarginCall(cb func(marginCall MarginCall))
 }
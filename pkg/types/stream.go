@@ -2,6 +2,9 @@ package types
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Stream interface {
@@ -19,12 +22,22 @@ var BookChannel = Channel("book")
 
 var KLineChannel = Channel("kline")
 
+// MarketTradeChannel is the public (aggregated) trade channel of the symbol, the trades are emitted by OnMarketTrade
+var MarketTradeChannel = Channel("trade")
+
+// FundingRateChannel is the funding rate channel of the perpetual contract, it's only available on the futures stream,
+// the predicted funding rates of the next funding time are emitted by OnFundingRate
+var FundingRateChannel = Channel("fundingRate")
+
 //go:generate callbackgen -type StandardStream -interface
 type StandardStream struct {
 	Subscriptions []Subscription
 
 	connectCallbacks []func()
 
+	// disconnectCallbacks are called when the connection is lost, before the stream starts reconnecting
+	disconnectCallbacks []func()
+
 	// private trade update callbacks
 	tradeUpdateCallbacks []func(trade Trade)
 
@@ -43,6 +56,47 @@ type StandardStream struct {
 	bookUpdateCallbacks []func(book OrderBook)
 
 	bookSnapshotCallbacks []func(book OrderBook)
+
+	// public market trade callbacks, the trade side is the taker side
+	marketTradeCallbacks []func(trade Trade)
+
+	// public funding rate callbacks of the perpetual contracts
+	fundingRateCallbacks []func(rate FundingRate)
+
+	// futures position update callbacks, the position is the latest state of the symbol and the position side
+	positionUpdateCallbacks []func(position FuturesPosition)
+
+	marginCallCallbacks []func(marginCall MarginCall)
+
+	latencyOnce sync.Once
+	latency     *LatencyRecorder
+
+	// lastMessageTime is the unix nano time of the last websocket message (including the heartbeats and the pongs)
+	lastMessageTime int64
+}
+
+// SetLastMessageTime is called by the exchange streams on every received websocket message
+func (stream *StandardStream) SetLastMessageTime(t time.Time) {
+	atomic.StoreInt64(&stream.lastMessageTime, t.UnixNano())
+}
+
+// LastMessageTime returns the time of the last received websocket message, zero if no message is received
+func (stream *StandardStream) LastMessageTime() time.Time {
+	t := atomic.LoadInt64(&stream.lastMessageTime)
+	if t == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, t)
+}
+
+// Latency returns the latency recorder of the stream, the exchange streams record the event latencies at the read layer
+func (stream *StandardStream) Latency() *LatencyRecorder {
+	stream.latencyOnce.Do(func() {
+		stream.latency = NewLatencyRecorder(DefaultLatencySamples)
+	})
+
+	return stream.latency
 }
 
 func (stream *StandardStream) Subscribe(channel Channel, symbol string, options SubscribeOptions) {
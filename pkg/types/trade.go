@@ -58,16 +58,23 @@ type Trade struct {
 
 	IsMargin   bool `json:"isMargin" db:"is_margin"`
 	IsIsolated bool `json:"isIsolated" db:"is_isolated"`
+
+	// Tags is copied from the tags of the submitted order
+	Tags OrderTags `json:"tags,omitempty" db:"tags"`
 }
 
 func (trade Trade) PlainText() string {
-	return fmt.Sprintf("%s Trade %s %s price %s, quantity %s, amount %s",
+	return fmt.Sprintf("%s %s %s %s %s %s, %s %s, %s %s",
 		trade.Exchange,
+		Translate("Trade"),
 		trade.Symbol,
 		trade.Side,
-		util.FormatFloat(trade.Price, 2),
+		Translate("price"),
+		formatQuoteAmount(trade.Symbol, trade.Price, 2),
+		Translate("quantity"),
 		util.FormatFloat(trade.Quantity, 4),
-		util.FormatFloat(trade.QuoteQuantity, 2))
+		Translate("amount"),
+		formatQuoteAmount(trade.Symbol, trade.QuoteQuantity, 2))
 }
 
 func (trade Trade) SlackAttachment() slack.Attachment {
@@ -78,17 +85,17 @@ func (trade Trade) SlackAttachment() slack.Attachment {
 	}
 
 	return slack.Attachment{
-		Text:  fmt.Sprintf("*%s* Trade %s", trade.Symbol, trade.Side),
+		Text:  fmt.Sprintf("*%s* %s %s", trade.Symbol, Translate("Trade"), trade.Side),
 		Color: color,
 		// Pretext:       "",
 		// Text:          "",
 		Fields: []slack.AttachmentField{
-			{Title: "Exchange", Value: trade.Exchange, Short: true},
-			{Title: "Price", Value: util.FormatFloat(trade.Price, 2), Short: true},
-			{Title: "Volume", Value: util.FormatFloat(trade.Quantity, 4), Short: true},
-			{Title: "Amount", Value: util.FormatFloat(trade.QuoteQuantity, 2)},
-			{Title: "Fee", Value: util.FormatFloat(trade.Fee, 4), Short: true},
-			{Title: "FeeCurrency", Value: trade.FeeCurrency, Short: true},
+			{Title: Translate("Exchange"), Value: trade.Exchange, Short: true},
+			{Title: Translate("Price"), Value: formatQuoteAmount(trade.Symbol, trade.Price, 2), Short: true},
+			{Title: Translate("Volume"), Value: util.FormatFloat(trade.Quantity, 4), Short: true},
+			{Title: Translate("Amount"), Value: formatQuoteAmount(trade.Symbol, trade.QuoteQuantity, 2)},
+			{Title: Translate("Fee"), Value: util.FormatFloat(trade.Fee, 4), Short: true},
+			{Title: Translate("FeeCurrency"), Value: trade.FeeCurrency, Short: true},
 		},
 		// Footer:     tradingCtx.TradeStartTime.Format(time.RFC822),
 		// FooterIcon: "",
@@ -0,0 +1,246 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// ProfitMatchingMethod is the method for matching the closing trade against the opening trades
+type ProfitMatchingMethod string
+
+const (
+	// ProfitMatchingFIFO matches the closing trade against the earliest opening trades first
+	ProfitMatchingFIFO = ProfitMatchingMethod("fifo")
+
+	// ProfitMatchingAverageCost matches the closing trade against the average cost of the opening trades
+	ProfitMatchingAverageCost = ProfitMatchingMethod("average")
+)
+
+func init() {
+	// make sure we can cast Profit to PlainText
+	_ = PlainText(Profit{})
+}
+
+// Profit is the realized profit of the round trip closed by a trade
+type Profit struct {
+	Symbol        string `json:"symbol"`
+	QuoteCurrency string `json:"quoteCurrency"`
+
+	// Side is the side of the closing trade, the sell trade closes the long position and the buy trade closes the short position
+	Side SideType `json:"side"`
+
+	// Quantity is the closed quantity
+	Quantity float64 `json:"quantity"`
+
+	// OpenPrice is the average price of the matched opening trades
+	OpenPrice  float64 `json:"openPrice"`
+	ClosePrice float64 `json:"closePrice"`
+
+	// Profit is the realized profit in the quote currency, the quote fees of the opening trades and the closing trade are deducted
+	Profit float64 `json:"profit"`
+
+	// Fee is the quote fee deducted from the profit
+	Fee float64 `json:"fee"`
+
+	TradeID int64     `json:"tradeID"`
+	OrderID uint64    `json:"orderID"`
+	Time    time.Time `json:"time"`
+}
+
+func (p Profit) PlainText() string {
+	return fmt.Sprintf("%s round trip %+f %s, quantity %s, %s -> %s",
+		p.Symbol, p.Profit, p.QuoteCurrency,
+		util.FormatFloat(p.Quantity, 4),
+		util.FormatFloat(p.OpenPrice, 2),
+		util.FormatFloat(p.ClosePrice, 2))
+}
+
+func (p Profit) SlackAttachment() slack.Attachment {
+	var color = "#228B22"
+	if p.Profit < 0 {
+		color = "#DC143C"
+	}
+
+	return slack.Attachment{
+		Text:  fmt.Sprintf("%s round trip %+f %s", p.Symbol, p.Profit, p.QuoteCurrency),
+		Color: color,
+		Fields: []slack.AttachmentField{
+			{Title: "Quantity", Value: util.FormatFloat(p.Quantity, 4), Short: true},
+			{Title: "Open Price", Value: util.FormatFloat(p.OpenPrice, 2), Short: true},
+			{Title: "Close Price", Value: util.FormatFloat(p.ClosePrice, 2), Short: true},
+			{Title: "Fee", Value: util.FormatFloat(p.Fee, 4), Short: true},
+		},
+	}
+}
+
+// tradeLot is the open quantity of the opening trades, the quantity is negative for the short lot
+type tradeLot struct {
+	price    float64
+	quantity float64
+
+	// feePerUnit is the quote fee of the opening trade per unit of the quantity
+	feePerUnit float64
+}
+
+// TradeCollector consumes the trades of a symbol and matches the buys against the sells,
+// the realized profit of each closing trade is emitted through the profit callbacks.
+//go:generate callbackgen -type TradeCollector
+type TradeCollector struct {
+	Symbol        string
+	BaseCurrency  string
+	QuoteCurrency string
+	Method        ProfitMatchingMethod
+
+	mu       sync.Mutex
+	lots     []tradeLot
+	tradeIDs map[int64]struct{}
+
+	profitCallbacks []func(trade Trade, profit Profit)
+}
+
+func NewTradeCollector(market Market, method ProfitMatchingMethod) *TradeCollector {
+	if method == "" {
+		method = ProfitMatchingFIFO
+	}
+
+	return &TradeCollector{
+		Symbol:        market.Symbol,
+		BaseCurrency:  market.BaseCurrency,
+		QuoteCurrency: market.QuoteCurrency,
+		Method:        method,
+		tradeIDs:      make(map[int64]struct{}),
+	}
+}
+
+// BindStream collects the trade updates of the symbol from the stream
+func (c *TradeCollector) BindStream(stream Stream) {
+	stream.OnTradeUpdate(func(trade Trade) {
+		if trade.Symbol == c.Symbol {
+			c.AddTrade(trade)
+		}
+	})
+}
+
+// Position returns the open quantity and its average price, the quantity is negative for the short position
+func (c *TradeCollector) Position() (quantity, averagePrice float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var cost float64
+	for _, lot := range c.lots {
+		quantity += lot.quantity
+		cost += lot.quantity * lot.price
+	}
+
+	if quantity == 0 {
+		return 0, 0
+	}
+
+	return quantity, cost / quantity
+}
+
+// AddTrade matches the trade against the open lots, it returns the profit if the trade closes any quantity.
+// The trade that is already collected is ignored.
+func (c *TradeCollector) AddTrade(trade Trade) (*Profit, bool) {
+	c.mu.Lock()
+	profit, ok := c.addTrade(trade)
+	c.mu.Unlock()
+
+	if ok {
+		c.EmitProfit(trade, *profit)
+	}
+
+	return profit, ok
+}
+
+func (c *TradeCollector) addTrade(trade Trade) (*Profit, bool) {
+	if _, exists := c.tradeIDs[trade.ID]; exists {
+		return nil, false
+	}
+	c.tradeIDs[trade.ID] = struct{}{}
+
+	quantity := trade.Quantity
+	var quoteFee float64
+	switch trade.FeeCurrency {
+	case c.BaseCurrency:
+		quantity -= trade.Fee
+	case c.QuoteCurrency:
+		quoteFee = trade.Fee
+	}
+
+	if quantity <= 0 {
+		return nil, false
+	}
+
+	// the sign of the lots opened by this trade
+	sign := 1.0
+	if trade.Side == SideTypeSell {
+		sign = -1.0
+	}
+
+	profit := Profit{
+		Symbol:        c.Symbol,
+		QuoteCurrency: c.QuoteCurrency,
+		Side:          trade.Side,
+		ClosePrice:    trade.Price,
+		TradeID:       trade.ID,
+		OrderID:       trade.OrderID,
+		Time:          trade.Time,
+	}
+
+	var openCost float64
+	remaining := quantity
+	for remaining > 0 && len(c.lots) > 0 && c.lots[0].quantity*sign < 0 {
+		lot := &c.lots[0]
+		matched := math.Min(remaining, math.Abs(lot.quantity))
+
+		// the sell trade closes the long lot, and the buy trade closes the short lot
+		profit.Profit += (trade.Price - lot.price) * matched * -sign
+		profit.Fee += lot.feePerUnit*matched + quoteFee*matched/quantity
+		profit.Quantity += matched
+		openCost += lot.price * matched
+
+		lot.quantity += matched * sign
+		remaining -= matched
+
+		if math.Abs(lot.quantity) < 1e-9 {
+			c.lots = c.lots[1:]
+		}
+	}
+
+	if remaining > 0 {
+		c.openLot(tradeLot{
+			price:      trade.Price,
+			quantity:   remaining * sign,
+			feePerUnit: quoteFee / quantity,
+		})
+	}
+
+	if profit.Quantity == 0 {
+		return nil, false
+	}
+
+	profit.OpenPrice = openCost / profit.Quantity
+	profit.Profit -= profit.Fee
+	return &profit, true
+}
+
+func (c *TradeCollector) openLot(lot tradeLot) {
+	if c.Method != ProfitMatchingAverageCost || len(c.lots) == 0 {
+		c.lots = append(c.lots, lot)
+		return
+	}
+
+	// merge the new lot into the average cost lot, the lots are always on the same side here
+	merged := &c.lots[0]
+	quantity := merged.quantity + lot.quantity
+	merged.price = (merged.price*merged.quantity + lot.price*lot.quantity) / quantity
+	merged.feePerUnit = (merged.feePerUnit*merged.quantity + lot.feePerUnit*lot.quantity) / quantity
+	merged.quantity = quantity
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var collectorTestMarket = Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+
+func TestTradeCollector_FIFO(t *testing.T) {
+	collector := NewTradeCollector(collectorTestMarket, ProfitMatchingFIFO)
+
+	var profits []Profit
+	collector.OnProfit(func(trade Trade, profit Profit) { profits = append(profits, profit) })
+
+	trades := []Trade{
+		{ID: 1, Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 100.0, Quantity: 1.0},
+		{ID: 2, Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 200.0, Quantity: 1.0},
+		{ID: 3, Symbol: "BTCUSDT", Side: SideTypeSell, Price: 150.0, Quantity: 1.5, Fee: 0.3, FeeCurrency: "USDT"},
+	}
+
+	for _, trade := range trades {
+		collector.AddTrade(trade)
+	}
+
+	// the duplicated trade is ignored
+	_, ok := collector.AddTrade(trades[2])
+	assert.False(t, ok)
+
+	if assert.Len(t, profits, 1) {
+		p := profits[0]
+		assert.Equal(t, SideTypeSell, p.Side)
+		assert.InDelta(t, 1.5, p.Quantity, 1e-9)
+		assert.InDelta(t, (100.0+100.0)/1.5, p.OpenPrice, 1e-9)
+
+		// +50 from the first lot, -25 from the half of the second lot, minus the fee
+		assert.InDelta(t, 25.0-0.3, p.Profit, 1e-9)
+		assert.InDelta(t, 0.3, p.Fee, 1e-9)
+	}
+
+	quantity, price := collector.Position()
+	assert.InDelta(t, 0.5, quantity, 1e-9)
+	assert.InDelta(t, 200.0, price, 1e-9)
+}
+
+func TestTradeCollector_AverageCost(t *testing.T) {
+	collector := NewTradeCollector(collectorTestMarket, ProfitMatchingAverageCost)
+
+	collector.AddTrade(Trade{ID: 1, Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 100.0, Quantity: 1.0})
+	collector.AddTrade(Trade{ID: 2, Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 200.0, Quantity: 1.0})
+
+	profit, ok := collector.AddTrade(Trade{ID: 3, Symbol: "BTCUSDT", Side: SideTypeSell, Price: 160.0, Quantity: 1.0})
+	if assert.True(t, ok) {
+		assert.InDelta(t, 150.0, profit.OpenPrice, 1e-9)
+		assert.InDelta(t, 10.0, profit.Profit, 1e-9)
+	}
+}
+
+func TestTradeCollector_ShortRoundTrip(t *testing.T) {
+	collector := NewTradeCollector(collectorTestMarket, ProfitMatchingFIFO)
+
+	_, ok := collector.AddTrade(Trade{ID: 1, Symbol: "BTCUSDT", Side: SideTypeSell, Price: 200.0, Quantity: 1.0})
+	assert.False(t, ok)
+
+	// the base fee reduces the received quantity of the buy trade
+	profit, ok := collector.AddTrade(Trade{ID: 2, Symbol: "BTCUSDT", Side: SideTypeBuy, Price: 180.0, Quantity: 1.5, Fee: 0.5, FeeCurrency: "BTC"})
+	if assert.True(t, ok) {
+		assert.Equal(t, SideTypeBuy, profit.Side)
+		assert.InDelta(t, 1.0, profit.Quantity, 1e-9)
+		assert.InDelta(t, 20.0, profit.Profit, 1e-9)
+	}
+
+	quantity, _ := collector.Position()
+	assert.InDelta(t, 0.0, quantity, 1e-9)
+}
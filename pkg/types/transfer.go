@@ -0,0 +1,54 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// WalletType is the wallet (sub-account) of the exchange account that holds the assets
+type WalletType string
+
+const (
+	WalletTypeSpot    = WalletType("spot")
+	WalletTypeMargin  = WalletType("margin")
+	WalletTypeFutures = WalletType("futures")
+
+	// WalletTypeFunding is the wallet that can not be used for trading,
+	// e.g., the funding wallet of bitfinex or the main account of kucoin.
+	WalletTypeFunding = WalletType("funding")
+)
+
+// TransferExchange is implemented by the exchanges that can move the assets between the wallets of the same account
+type TransferExchange interface {
+	// TransferInternal moves the amount of the asset from one wallet to another,
+	// an error is returned if the exchange doesn't support the transfer between the given wallets.
+	TransferInternal(ctx context.Context, from, to WalletType, asset string, amount fixedpoint.Value) (*InternalTransfer, error)
+}
+
+type InternalTransfer struct {
+	Exchange   ExchangeName     `json:"exchange"`
+	TransferID string           `json:"transferID"`
+	From       WalletType       `json:"from"`
+	To         WalletType       `json:"to"`
+	Asset      string           `json:"asset"`
+	Amount     fixedpoint.Value `json:"amount"`
+	Time       time.Time        `json:"time"`
+}
+
+// SubAccountTransferExchange is implemented by the exchanges that can move the assets between the master account and the sub-accounts,
+// the transfer is made with the api key of the master account, and the empty account means the master account.
+type SubAccountTransferExchange interface {
+	TransferSubAccount(ctx context.Context, fromAccount, toAccount string, asset string, amount fixedpoint.Value) (*SubAccountTransfer, error)
+}
+
+type SubAccountTransfer struct {
+	Exchange    ExchangeName     `json:"exchange"`
+	TransferID  string           `json:"transferID"`
+	FromAccount string           `json:"fromAccount"`
+	ToAccount   string           `json:"toAccount"`
+	Asset       string           `json:"asset"`
+	Amount      fixedpoint.Value `json:"amount"`
+	Time        time.Time        `json:"time"`
+}
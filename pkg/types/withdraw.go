@@ -3,20 +3,25 @@ package types
 import "time"
 
 type Withdraw struct {
-	ID         string  `json:"id"`
-	Asset      string  `json:"asset"`
-	Amount     float64 `json:"amount"`
-	Address    string  `json:"address"`
-	AddressTag string  `json:"addressTag"`
-	Status     string  `json:"status"`
+	GID        int64        `json:"gid" db:"gid"`
+	Exchange   ExchangeName `json:"exchange" db:"exchange"`
+	ID         string       `json:"id" db:"-"`
+	Asset      string       `json:"asset" db:"asset"`
+	Amount     float64      `json:"amount" db:"amount"`
+	Address    string       `json:"address" db:"address"`
+	AddressTag string       `json:"addressTag" db:"address_tag"`
+	Status     string       `json:"status" db:"status"`
 
-	TransactionID   string    `json:"txId"`
-	TransactionFee  float64   `json:"transactionFee"`
-	WithdrawOrderID string    `json:"withdrawOrderId"`
-	ApplyTime       time.Time `json:"applyTime"`
-	Network         string    `json:"network"`
+	TransactionID   string    `json:"txId" db:"txn_id"`
+	TransactionFee  float64   `json:"transactionFee" db:"txn_fee"`
+	WithdrawOrderID string    `json:"withdrawOrderId" db:"withdraw_order_id"`
+	ApplyTime       time.Time `json:"applyTime" db:"time"`
+	Network         string    `json:"network" db:"network"`
 }
 
 func (w Withdraw) EffectiveTime() time.Time {
 	return w.ApplyTime
 }
+
+// WithdrawCompleted is the status of the completed withdraws, the exchange statuses are converted to it by the adapters
+const WithdrawCompleted = "completed"
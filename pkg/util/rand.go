@@ -0,0 +1,33 @@
+package util
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var randLock sync.Mutex
+
+var randSource = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// SetRandomSeed resets the shared random source with the given seed,
+// the randomized components use the shared source so that a run can be reproduced with the same seed.
+func SetRandomSeed(seed int64) {
+	randLock.Lock()
+	randSource = rand.New(rand.NewSource(seed))
+	randLock.Unlock()
+}
+
+// RandIntn returns a random int in [0, n) from the shared random source
+func RandIntn(n int) int {
+	randLock.Lock()
+	defer randLock.Unlock()
+	return randSource.Intn(n)
+}
+
+// RandFloat64 returns a random float64 in [0.0, 1.0) from the shared random source
+func RandFloat64() float64 {
+	randLock.Lock()
+	defer randLock.Unlock()
+	return randSource.Float64()
+}
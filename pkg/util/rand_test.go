@@ -0,0 +1,17 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRandomSeed(t *testing.T) {
+	SetRandomSeed(42)
+	a := []int{RandIntn(1000), RandIntn(1000), RandIntn(1000)}
+	f := RandFloat64()
+
+	SetRandomSeed(42)
+	assert.Equal(t, a, []int{RandIntn(1000), RandIntn(1000), RandIntn(1000)})
+	assert.Equal(t, f, RandFloat64())
+}
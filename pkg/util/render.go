@@ -7,8 +7,13 @@ import (
 )
 
 func Render(tpl string, args interface{}) string {
+	return RenderWithFuncs(tpl, nil, args)
+}
+
+// RenderWithFuncs renders the template with the extra template functions
+func RenderWithFuncs(tpl string, funcs template.FuncMap, args interface{}) string {
 	var buf = bytes.NewBuffer(nil)
-	tmpl, err := template.New("tmp").Parse(tpl)
+	tmpl, err := template.New("tmp").Funcs(funcs).Parse(tpl)
 	if err != nil {
 		logrus.WithError(err).Error("template error")
 		return ""
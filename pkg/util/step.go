@@ -0,0 +1,73 @@
+package util
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// stepEpsilon is the tolerance of the floating point error when dividing the value by the step size,
+// e.g., 0.3 / 0.1 = 2.9999999999999996 should still be counted as 3 steps.
+const stepEpsilon = 1e-9
+
+// stepTolerance returns the tolerance of the step count, the floating point error grows with the step count
+func stepTolerance(units float64) float64 {
+	return math.Max(stepEpsilon, math.Abs(units)*1e-14)
+}
+
+// StepPrecision returns the number of the decimal places of the step size, e.g., 0.001 => 3, 5 => 0
+func StepPrecision(step float64) int {
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	idx := strings.IndexByte(s, '.')
+	if idx < 0 {
+		return 0
+	}
+
+	return len(s) - idx - 1
+}
+
+// roundToStepPrecision removes the floating point noise after multiplying the steps back
+func roundToStepPrecision(val float64, step float64) float64 {
+	p := math.Pow10(StepPrecision(step))
+	return math.Round(val*p) / p
+}
+
+// FloorToStep rounds the value down to the nearest multiple of the step size
+func FloorToStep(val, step float64) float64 {
+	if step <= 0 {
+		return val
+	}
+
+	units := val / step
+	return roundToStepPrecision(math.Floor(units+stepTolerance(units))*step, step)
+}
+
+// CeilToStep rounds the value up to the nearest multiple of the step size
+func CeilToStep(val, step float64) float64 {
+	if step <= 0 {
+		return val
+	}
+
+	units := val / step
+	return roundToStepPrecision(math.Ceil(units-stepTolerance(units))*step, step)
+}
+
+// RoundHalfEvenToStep rounds the value to the nearest multiple of the step size,
+// the value right in the middle of two steps is rounded to the even one (banker's rounding).
+func RoundHalfEvenToStep(val, step float64) float64 {
+	if step <= 0 {
+		return val
+	}
+
+	units := val / step
+	integer, frac := math.Modf(units)
+
+	// the floating point error could make the half value slightly off, e.g., 0.25 / 0.1 = 2.4999999999999996
+	if math.Abs(math.Abs(frac)-0.5) < stepTolerance(units) {
+		units = math.RoundToEven(integer + math.Copysign(0.5, frac))
+	} else {
+		units = math.Round(units)
+	}
+
+	return roundToStepPrecision(units*step, step)
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the step sizes published by the exchanges,
+// binance publishes the stepSize / tickSize in the LOT_SIZE / PRICE_FILTER filters,
+// max publishes the base unit precision and the quote unit precision.
+var publishedSteps = map[string][]float64{
+	"binance": {0.000001, 0.00001, 0.0001, 0.001, 0.01, 0.1, 1.0, 10.0},
+	"max":     {0.00000001, 0.000001, 0.0001, 0.01, 0.1},
+}
+
+func isMultipleOfStep(val, step float64) bool {
+	units := val / step
+	return math.Abs(units-math.Round(units)) < math.Max(1e-6, math.Abs(units)*1e-14)
+}
+
+func TestStepPrecision(t *testing.T) {
+	assert.Equal(t, 0, StepPrecision(1.0))
+	assert.Equal(t, 0, StepPrecision(10.0))
+	assert.Equal(t, 2, StepPrecision(0.01))
+	assert.Equal(t, 8, StepPrecision(0.00000001))
+	assert.Equal(t, 3, StepPrecision(0.005))
+}
+
+func TestFloorCeilToStep(t *testing.T) {
+	assert.Equal(t, 0.3, FloorToStep(0.3, 0.1))
+	assert.Equal(t, 0.3, CeilToStep(0.3, 0.1))
+	assert.Equal(t, 0.123, FloorToStep(0.12399, 0.001))
+	assert.Equal(t, 0.124, CeilToStep(0.12301, 0.001))
+	assert.Equal(t, 120.0, FloorToStep(129.0, 10.0))
+	assert.Equal(t, 1.5, FloorToStep(1.5, 0))
+}
+
+func TestRoundHalfEvenToStep(t *testing.T) {
+	assert.Equal(t, 0.2, RoundHalfEvenToStep(0.25, 0.1))
+	assert.Equal(t, 0.4, RoundHalfEvenToStep(0.35, 0.1))
+	assert.Equal(t, 0.4, RoundHalfEvenToStep(0.36, 0.1))
+	assert.Equal(t, 2.0, RoundHalfEvenToStep(2.5, 1.0))
+	assert.Equal(t, 4.0, RoundHalfEvenToStep(3.5, 1.0))
+	assert.Equal(t, -2.0, RoundHalfEvenToStep(-2.5, 1.0))
+}
+
+func TestStepRounding_Properties(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for exchange, steps := range publishedSteps {
+		for _, step := range steps {
+			for i := 0; i < 1000; i++ {
+				val := r.Float64() * 1000.0
+
+				floor := FloorToStep(val, step)
+				ceil := CeilToStep(val, step)
+				half := RoundHalfEvenToStep(val, step)
+
+				if !assert.True(t, isMultipleOfStep(floor, step), "%s: floor %v of %v is not a multiple of step %v", exchange, floor, val, step) ||
+					!assert.True(t, isMultipleOfStep(ceil, step), "%s: ceil %v of %v is not a multiple of step %v", exchange, ceil, val, step) ||
+					!assert.True(t, isMultipleOfStep(half, step), "%s: round %v of %v is not a multiple of step %v", exchange, half, val, step) {
+					return
+				}
+
+				// floor <= val <= ceil, and they are within one step
+				tolerance := math.Max(step*1e-6, val*1e-13)
+				assert.LessOrEqual(t, floor, val+tolerance)
+				assert.GreaterOrEqual(t, ceil, val-tolerance)
+				assert.LessOrEqual(t, ceil-floor, step+tolerance)
+
+				// the rounded value is one of the floor or the ceil
+				assert.True(t, math.Abs(half-floor) < tolerance || math.Abs(half-ceil) < tolerance)
+
+				// rounding is idempotent
+				assert.Equal(t, floor, FloorToStep(floor, step))
+				assert.Equal(t, ceil, CeilToStep(ceil, step))
+			}
+		}
+	}
+}